@@ -2,12 +2,15 @@ package calendar
 
 import (
 	"fmt"
+	"math"
+	"net/mail"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"tempus/internal/constants"
+	"tempus/internal/normalizer"
 	"tempus/internal/testutil"
 )
 
@@ -16,6 +19,20 @@ const (
 	defaultDescText = "Reminder"
 )
 
+// defaultAlarmDirection is the sign of an unsigned relative trigger: -1 fires
+// before the event, 1 after it.
+var defaultAlarmDirection = -1
+
+// SetDefaultAlarmDirection makes unsigned triggers such as "15m" fire after
+// the event instead of before it (config's alarm_direction). A direction=
+// or kind= in the spec still wins. Call it before parsing, not concurrently.
+func SetDefaultAlarmDirection(after bool) {
+	defaultAlarmDirection = -1
+	if after {
+		defaultAlarmDirection = 1
+	}
+}
+
 var (
 	alarmHHMMRe    = regexp.MustCompile(`^\s*(\d{1,2})\s*:\s*([0-5]?\d)\s*$`)
 	alarmHMRe      = regexp.MustCompile(`^\s*(?:(\d+)\s*h\s*)?(?:(\d+)\s*m\s*)?$`)
@@ -23,6 +40,18 @@ var (
 	icsDurationRe  = regexp.MustCompile(`(?i)^P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
 )
 
+// maxDuration bounds parsed durations at a century, well inside the ~292
+// years a time.Duration holds, so adding up their parts cannot overflow.
+const maxDuration = 100 * 365 * 24 * time.Hour
+
+// scaleDuration returns n units, or false when that is more than maxDuration.
+func scaleDuration(n int, unit time.Duration) (time.Duration, bool) {
+	if n < 0 || n > int(maxDuration/unit) {
+		return 0, false
+	}
+	return time.Duration(n) * unit, true
+}
+
 // ParseHumanDuration converts human-friendly strings (e.g., "1h30m", "90", "1:30", "1d", "1w") into time.Duration.
 func ParseHumanDuration(s string) (time.Duration, error) {
 	x := strings.ToLower(strings.TrimSpace(s))
@@ -49,18 +78,16 @@ func tryParseDaysOrWeeks(x string) (time.Duration, bool) {
 	// Try parsing days (1d, 2d, etc.)
 	if strings.HasSuffix(x, "d") && len(x) > 1 {
 		daysStr := strings.TrimSuffix(x, "d")
-		days := atoiSafe(daysStr)
-		if days > 0 {
-			return time.Duration(days) * 24 * time.Hour, true
+		if dur, ok := scaleDuration(atoiSafe(daysStr), 24*time.Hour); ok && dur > 0 {
+			return dur, true
 		}
 	}
 
 	// Try parsing weeks (1w, 2w, etc.)
 	if strings.HasSuffix(x, "w") && len(x) > 1 {
 		weeksStr := strings.TrimSuffix(x, "w")
-		weeks := atoiSafe(weeksStr)
-		if weeks > 0 {
-			return time.Duration(weeks) * 7 * 24 * time.Hour, true
+		if dur, ok := scaleDuration(atoiSafe(weeksStr), 7*24*time.Hour); ok && dur > 0 {
+			return dur, true
 		}
 	}
 
@@ -77,12 +104,12 @@ func tryParseTimeFormat(x string) (time.Duration, bool) {
 
 	// Try HhMm format (e.g., "1h30m")
 	if m := alarmHMRe.FindStringSubmatch(x); m != nil {
-		hh := atoiSafe(m[1])
-		mm := atoiSafe(m[2])
-		if hh == 0 && mm == 0 {
+		hours, okH := scaleDuration(atoiSafe(m[1]), time.Hour)
+		minutes, okM := scaleDuration(atoiSafe(m[2]), time.Minute)
+		if !okH || !okM || hours+minutes == 0 {
 			return 0, false
 		}
-		return time.Duration(hh)*time.Hour + time.Duration(mm)*time.Minute, true
+		return hours + minutes, true
 	}
 
 	return 0, false
@@ -90,11 +117,9 @@ func tryParseTimeFormat(x string) (time.Duration, bool) {
 
 func tryParseMinutes(x string) (time.Duration, bool) {
 	if alarmMinutesRe.MatchString(x) {
-		mins := atoiSafe(x)
-		if mins <= 0 {
-			return 0, false
+		if dur, ok := scaleDuration(atoiSafe(x), time.Minute); ok && dur > 0 {
+			return dur, true
 		}
-		return time.Duration(mins) * time.Minute, true
 	}
 	return 0, false
 }
@@ -195,13 +220,15 @@ func parseSimpleAlarmSpec(spec, defaultTZ string) (Alarm, error) {
 		return Alarm{}, fmt.Errorf("alarm trigger cannot be empty")
 	}
 
-	if dur, err := parseRelativeAlarmDuration(trigger, -1); err == nil {
-		return Alarm{
-			Action:            actionDisplay,
-			Description:       defaultDescText,
-			TriggerIsRelative: true,
-			TriggerDuration:   dur,
-		}, nil
+	if _, _, isTimestamp := normalizer.ParseTimestamp(trigger); !isTimestamp {
+		if dur, err := parseRelativeAlarmDuration(trigger, defaultAlarmDirection); err == nil {
+			return Alarm{
+				Action:            actionDisplay,
+				Description:       defaultDescText,
+				TriggerIsRelative: true,
+				TriggerDuration:   dur,
+			}, nil
+		}
 	}
 
 	ts, err := parseAlarmAbsolute(trigger, defaultTZ)
@@ -227,7 +254,10 @@ func parseKeyValueAlarmSpec(spec, defaultTZ string) (Alarm, error) {
 		return Alarm{}, fmt.Errorf("alarm %q is missing trigger= value", spec)
 	}
 
-	al := createAlarmFromParams(params)
+	al, err := createAlarmFromParams(params)
+	if err != nil {
+		return Alarm{}, fmt.Errorf("alarm %q: %w", spec, err)
+	}
 	triggerMode := determineAlarmTriggerMode(params)
 
 	repeat, repeatDur, err := parseAlarmRepeatParams(params, spec)
@@ -253,17 +283,25 @@ func parseKeyValueAlarmSpec(spec, defaultTZ string) (Alarm, error) {
 	return al, nil
 }
 
+// parseAlarmKeyValueParams splits "key=value" segments separated by commas or
+// semicolons. Segments without "=" after to= are further recipients, so
+// "to=ana@example.com;ben@example.com" keeps both addresses.
 func parseAlarmKeyValueParams(spec string) (map[string]string, error) {
 	parts := strings.FieldsFunc(spec, func(r rune) bool {
 		return r == ',' || r == ';'
 	})
 	params := make(map[string]string, len(parts))
+	last := ""
 	for _, part := range parts {
 		if strings.TrimSpace(part) == "" {
 			continue
 		}
 		kv := strings.SplitN(part, "=", 2)
 		if len(kv) != 2 {
+			if last == "to" {
+				params["to"] += ";" + strings.TrimSpace(part)
+				continue
+			}
 			return nil, fmt.Errorf("invalid alarm segment %q", part)
 		}
 		key := strings.ToLower(strings.TrimSpace(kv[0]))
@@ -271,14 +309,56 @@ func parseAlarmKeyValueParams(spec string) (map[string]string, error) {
 		if key != "" {
 			params[key] = val
 		}
+		last = key
 	}
 	return params, nil
 }
 
-func createAlarmFromParams(params map[string]string) Alarm {
+// parseAlarmRecipients reads the to= list of an EMAIL alarm: addresses
+// separated by ";" or "|", each as "ana@example.com", "mailto:..." or
+// "Ana <ana@example.com>".
+func parseAlarmRecipients(value string) ([]string, error) {
+	var out []string
+	for _, part := range strings.FieldsFunc(value, func(r rune) bool { return r == ';' || r == '|' }) {
+		v := strings.TrimSpace(part)
+		if v == "" {
+			continue
+		}
+		if len(v) >= 7 && strings.EqualFold(v[:7], "mailto:") {
+			v = v[7:]
+		}
+		addr, err := mail.ParseAddress(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient %q", strings.TrimSpace(part))
+		}
+		out = append(out, addr.Address)
+	}
+	return out, nil
+}
+
+func createAlarmFromParams(params map[string]string) (Alarm, error) {
+	sound := strings.TrimSpace(firstNonEmpty(params["sound"], params["attach"]))
+	recipients, err := parseAlarmRecipients(firstNonEmpty(params["to"], params["attendee"], params["attendees"]))
+	if err != nil {
+		return Alarm{}, err
+	}
 	action := strings.ToUpper(strings.TrimSpace(firstNonEmpty(params["action"], "")))
 	if action == "" {
 		action = actionDisplay
+		if sound != "" {
+			action = constants.AlarmActionAudio
+		} else if len(recipients) > 0 {
+			action = constants.AlarmActionEmail
+		}
+	}
+	if err := validateAlarmAction(action); err != nil {
+		return Alarm{}, err
+	}
+	if sound != "" && action != constants.AlarmActionAudio {
+		return Alarm{}, fmt.Errorf("sound is only supported with action=AUDIO (got %s)", action)
+	}
+	if len(recipients) > 0 && action != constants.AlarmActionEmail {
+		return Alarm{}, fmt.Errorf("to is only supported with action=EMAIL (got %s)", action)
 	}
 
 	description := strings.TrimSpace(firstNonEmpty(params["description"], params["message"], params["text"]))
@@ -288,11 +368,23 @@ func createAlarmFromParams(params map[string]string) Alarm {
 		Action:      action,
 		Summary:     summary,
 		Description: description,
+		Attach:      sound,
+		Attendees:   recipients,
 	}
 	if strings.TrimSpace(al.Description) == "" && al.Action == actionDisplay {
 		al.Description = defaultDescText
 	}
-	return al
+	return al, nil
+}
+
+// validateAlarmAction accepts the VALARM actions defined by RFC 5545.
+func validateAlarmAction(action string) error {
+	switch action {
+	case constants.AlarmActionDisplay, constants.AlarmActionEmail, constants.AlarmActionAudio:
+		return nil
+	default:
+		return fmt.Errorf("unsupported alarm action %q (use DISPLAY, EMAIL, or AUDIO)", action)
+	}
 }
 
 type alarmTriggerMode struct {
@@ -302,7 +394,7 @@ type alarmTriggerMode struct {
 }
 
 func determineAlarmTriggerMode(params map[string]string) alarmTriggerMode {
-	mode := alarmTriggerMode{defaultDirection: -1}
+	mode := alarmTriggerMode{defaultDirection: defaultAlarmDirection}
 
 	dirHint := strings.ToLower(strings.TrimSpace(firstNonEmpty(params["direction"], params["when"])))
 	switch dirHint {
@@ -371,6 +463,10 @@ func setAlarmTrigger(al *Alarm, trigger string, mode alarmTriggerMode, defaultTZ
 	var relDur time.Duration
 	var relErr error
 
+	if _, _, ok := normalizer.ParseTimestamp(trigger); ok && !mode.forceRelative {
+		// An epoch such as 1765875600 would otherwise read as minutes.
+		mode.forceAbsolute = true
+	}
 	if !mode.forceAbsolute {
 		relDur, relErr = parseRelativeAlarmDuration(trigger, mode.defaultDirection)
 		if relErr == nil {
@@ -405,7 +501,8 @@ func parseAlarmAbsolute(raw, defaultTZ string) (time.Time, error) {
 		return time.Time{}, fmt.Errorf("empty absolute trigger")
 	}
 
-	if t, ok := tryParseRFC3339(val); ok {
+	// RFC 3339, a date-time with an offset or a unix epoch carry their own zone.
+	if t, _, ok := normalizer.ParseTimestamp(val); ok {
 		return t, nil
 	}
 
@@ -413,21 +510,6 @@ func parseAlarmAbsolute(raw, defaultTZ string) (time.Time, error) {
 	return tryParseCommonLayouts(val, loc, raw)
 }
 
-func tryParseRFC3339(val string) (time.Time, bool) {
-	if t, err := time.Parse(time.RFC3339, val); err == nil {
-		return t, true
-	}
-
-	// Try RFC3339 with space instead of T
-	if strings.Count(val, " ") == 1 && strings.HasSuffix(strings.ToUpper(val), "Z") {
-		if t, err := time.Parse(time.RFC3339, strings.Replace(val, " ", "T", 1)); err == nil {
-			return t, true
-		}
-	}
-
-	return time.Time{}, false
-}
-
 func loadTimezoneLocation(defaultTZ string) *time.Location {
 	if tz := strings.TrimSpace(defaultTZ); tz != "" {
 		if l, err := time.LoadLocation(tz); err == nil {
@@ -518,6 +600,9 @@ func parseAlarmDurationValue(raw string) (time.Duration, error) {
 		if d < 0 {
 			return 0, fmt.Errorf(testutil.ErrMsgDurationMustBePositive)
 		}
+		if d > maxDuration {
+			return 0, fmt.Errorf("duration %q is too long", raw)
+		}
 		return d, nil
 	}
 	if strings.HasPrefix(strings.ToUpper(val), "P") {
@@ -547,20 +632,12 @@ func parseICSDuration(raw string) (time.Duration, error) {
 	}
 
 	var total time.Duration
-	if matches[1] != "" {
-		total += time.Duration(atoiSafe(matches[1])) * 7 * 24 * time.Hour
-	}
-	if matches[2] != "" {
-		total += time.Duration(atoiSafe(matches[2])) * 24 * time.Hour
-	}
-	if matches[3] != "" {
-		total += time.Duration(atoiSafe(matches[3])) * time.Hour
-	}
-	if matches[4] != "" {
-		total += time.Duration(atoiSafe(matches[4])) * time.Minute
-	}
-	if matches[5] != "" {
-		total += time.Duration(atoiSafe(matches[5])) * time.Second
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	for i, unit := range units {
+		part, ok := scaleDuration(atoiSafe(matches[i+1]), unit)
+		if total += part; !ok || total > maxDuration {
+			return 0, fmt.Errorf("duration %q is too long", raw)
+		}
 	}
 
 	if total == 0 {
@@ -569,6 +646,25 @@ func parseICSDuration(raw string) (time.Duration, error) {
 	return total, nil
 }
 
+// addICSDuration adds an RFC 5545 DURATION such as "P1DT2H" to start. Weeks
+// and days are nominal, keeping start's wall-clock time across a DST change;
+// the time part is exact. Unlike parseICSDuration it accepts "PT0S".
+func addICSDuration(start time.Time, raw string) (time.Time, error) {
+	val := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(raw)), "+")
+	m := icsDurationRe.FindStringSubmatch(val)
+	if m == nil || val == "P" || strings.HasSuffix(val, "T") {
+		return time.Time{}, fmt.Errorf(testutil.ErrMsgInvalidICSDuration, raw)
+	}
+	days := atoiSafe(m[1])*7 + atoiSafe(m[2])
+	hours, okH := scaleDuration(atoiSafe(m[3]), time.Hour)
+	minutes, okM := scaleDuration(atoiSafe(m[4]), time.Minute)
+	seconds, okS := scaleDuration(atoiSafe(m[5]), time.Second)
+	if !okH || !okM || !okS || days > int(maxDuration/(24*time.Hour)) {
+		return time.Time{}, fmt.Errorf("duration %q is too long", raw)
+	}
+	return start.AddDate(0, 0, days).Add(hours + minutes + seconds), nil
+}
+
 func parseBoolish(s string) bool {
 	switch strings.ToLower(strings.TrimSpace(s)) {
 	case "1", "true", "yes", "y", "on":
@@ -587,6 +683,8 @@ func firstNonEmpty(vals ...string) string {
 	return ""
 }
 
+// atoiSafe reads a plain decimal number, 0 when s is not one. Numbers past
+// math.MaxInt32 stay there instead of wrapping around.
 func atoiSafe(s string) int {
 	s = strings.TrimSpace(s)
 	if s == "" {
@@ -597,7 +695,7 @@ func atoiSafe(s string) int {
 		if r < '0' || r > '9' {
 			return 0
 		}
-		n = n*10 + int(r-'0')
+		n = min(n*10+int(r-'0'), math.MaxInt32)
 	}
 	return n
 }
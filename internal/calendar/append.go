@@ -0,0 +1,264 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"tempus/internal/constants"
+)
+
+// ParseICSEvents reads the VEVENTs of an .ics document as Events with absolute
+// start and end times, enough to check new events against an existing
+// calendar or list upcoming ones. Alarms keep only their trigger; attendees
+// are not read.
+func ParseICSEvents(data string) ([]Event, error) {
+	segments, err := splitICSEvents(data)
+	if err != nil {
+		return nil, err
+	}
+	var events []Event
+	for _, seg := range segments {
+		if !seg.event {
+			continue
+		}
+		ev, err := parseICSEvent(seg.lines)
+		if err != nil {
+			label := ev.Summary
+			if label == "" {
+				label = ev.UID
+			}
+			return nil, fmt.Errorf("event %q: %w", label, err)
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
+
+func parseICSEvent(lines []string) (Event, error) {
+	info := icsEventInfo(lines)
+	ev := Event{UID: strings.TrimSpace(info.UID), Summary: info.Summary, Location: info.Location, Categories: info.Categories}
+
+	var dtstart, dtend *icsLine
+	var duration string
+	var triggers []icsLine
+	inAlarm := false
+	depth := 0
+	for _, line := range lines[1 : len(lines)-1] {
+		p := parseICSLine(line)
+		switch {
+		case p.name == "BEGIN":
+			depth++
+			inAlarm = strings.EqualFold(p.value, "VALARM")
+		case p.name == "END":
+			depth--
+			inAlarm = false
+		case inAlarm && p.name == "TRIGGER":
+			triggers = append(triggers, p)
+		case depth > 0:
+		case p.name == "DTSTART":
+			dtstart = &p
+		case p.name == "DTEND":
+			dtend = &p
+		case p.name == "DURATION":
+			duration = p.value
+		case p.name == "RRULE":
+			ev.RRule = strings.TrimSpace(p.value)
+		case p.name == "DESCRIPTION":
+			ev.Description = unescapeText(p.value)
+		case p.name == "EXDATE":
+			ev.ExDates = append(ev.ExDates, icsInstantList(p)...)
+		case p.name == "RDATE":
+			ev.RDates = append(ev.RDates, icsInstantList(p)...)
+		case p.name == "RECURRENCE-ID":
+			ev.RecurrenceID, _ = icsInstant(p)
+		}
+	}
+	if dtstart == nil {
+		return ev, fmt.Errorf("missing DTSTART")
+	}
+
+	start, err := icsInstant(*dtstart)
+	if err != nil {
+		return ev, err
+	}
+	ev.StartTime, ev.StartTZ = start, dtstart.param("TZID")
+	ev.AllDay = len(strings.TrimSpace(dtstart.value)) == 8
+
+	switch {
+	case dtend != nil:
+		if ev.EndTime, err = icsInstant(*dtend); err != nil {
+			return ev, err
+		}
+		ev.EndTZ = dtend.param("TZID")
+	case duration != "":
+		if ev.EndTime, err = addICSDuration(start, duration); err != nil {
+			return ev, err
+		}
+		ev.EndTZ = ev.StartTZ
+	case ev.AllDay:
+		ev.EndTime = start.AddDate(0, 0, 1)
+	default:
+		ev.EndTime, ev.EndTZ = start, ev.StartTZ
+	}
+	for _, p := range triggers {
+		if al, ok := icsAlarmTrigger(p, ev.StartTime, ev.EndTime); ok {
+			ev.Alarms = append(ev.Alarms, al)
+		}
+	}
+	return ev, nil
+}
+
+// icsAlarmTrigger reads a VALARM TRIGGER as an Alarm. A trigger related to
+// the end becomes an offset from the start; unreadable ones are skipped.
+func icsAlarmTrigger(p icsLine, start, end time.Time) (Alarm, bool) {
+	if strings.EqualFold(p.param("VALUE"), "DATE-TIME") {
+		t, err := time.Parse(constants.ICSFormatUTC, strings.TrimSpace(p.value))
+		return Alarm{TriggerTime: t}, err == nil
+	}
+	val := strings.TrimSpace(p.value)
+	sign := time.Duration(1)
+	if strings.HasPrefix(val, "-") {
+		sign, val = -1, val[1:]
+	}
+	d, err := parseICSDuration(val)
+	if err != nil {
+		return Alarm{}, false
+	}
+	d *= sign
+	if strings.EqualFold(p.param("RELATED"), "END") {
+		d += end.Sub(start)
+	}
+	return Alarm{TriggerIsRelative: true, TriggerDuration: d}, true
+}
+
+// AppendICS returns existing with the VEVENTs of addition inserted before its
+// END:VCALENDAR, plus any VTIMEZONE blocks of addition whose TZID existing
+// lacks. Everything already in existing is kept verbatim. It fails, changing
+// nothing, when an added event's UID is already in existing.
+func AppendICS(existing, addition string) (string, int, error) {
+	current, err := splitICSEvents(existing)
+	if err != nil {
+		return "", 0, err
+	}
+	incoming, err := splitICSEvents(addition)
+	if err != nil {
+		return "", 0, err
+	}
+
+	uids := map[string]bool{}
+	end := -1
+	for i, seg := range current {
+		if seg.event {
+			uids[strings.TrimSpace(icsEventInfo(seg.lines).UID)] = true
+		} else if strings.EqualFold(strings.TrimSpace(seg.lines[0]), "END:VCALENDAR") {
+			end = i
+		}
+	}
+	if end < 0 {
+		return "", 0, fmt.Errorf("no END:VCALENDAR in the existing calendar")
+	}
+	haveTZ := vtimezoneIDs(current)
+
+	var (
+		added    []icsSegment
+		vtz      []icsSegment
+		inVTZ    bool
+		vtzID    string
+		collided []string
+	)
+	for _, seg := range incoming {
+		if seg.event {
+			if uid := strings.TrimSpace(icsEventInfo(seg.lines).UID); uids[uid] {
+				collided = append(collided, uid)
+			}
+			added = append(added, seg)
+			continue
+		}
+		// VTIMEZONE blocks are outside VEVENTs, so they arrive line by line.
+		line := strings.TrimSpace(seg.lines[0])
+		switch upper := strings.ToUpper(line); {
+		case upper == "BEGIN:VTIMEZONE":
+			inVTZ, vtzID = true, ""
+			vtz = append(vtz, seg)
+		case inVTZ:
+			vtz = append(vtz, seg)
+			if p := parseICSLine(line); p.name == "TZID" && vtzID == "" {
+				vtzID = strings.TrimSpace(p.value)
+			}
+			if upper == "END:VTIMEZONE" {
+				inVTZ = false
+				if haveTZ[vtzID] {
+					vtz = trimLastVTimezone(vtz)
+				}
+				haveTZ[vtzID] = true
+			}
+		}
+	}
+	if len(collided) > 0 {
+		return "", 0, fmt.Errorf("%d event(s) already in the calendar (UID %s)", len(collided), strings.Join(collided, ", "))
+	}
+
+	merged := make([]icsSegment, 0, len(current)+len(vtz)+len(added))
+	merged = append(merged, current[:end]...)
+	merged = append(merged, vtz...)
+	merged = append(merged, added...)
+	merged = append(merged, current[end:]...)
+	return writeICSSegments(merged), len(added), nil
+}
+
+// vtimezoneIDs lists the TZIDs of the VTIMEZONE blocks in segments.
+func vtimezoneIDs(segments []icsSegment) map[string]bool {
+	ids := map[string]bool{}
+	inVTZ := false
+	for _, seg := range segments {
+		if seg.event {
+			continue
+		}
+		line := strings.TrimSpace(seg.lines[0])
+		switch upper := strings.ToUpper(line); {
+		case upper == "BEGIN:VTIMEZONE":
+			inVTZ = true
+		case upper == "END:VTIMEZONE":
+			inVTZ = false
+		case inVTZ:
+			if p := parseICSLine(line); p.name == "TZID" {
+				ids[strings.TrimSpace(p.value)] = true
+			}
+		}
+	}
+	return ids
+}
+
+// trimLastVTimezone drops the trailing BEGIN..END:VTIMEZONE block from segs.
+func trimLastVTimezone(segs []icsSegment) []icsSegment {
+	for i := len(segs) - 1; i >= 0; i-- {
+		if strings.EqualFold(strings.TrimSpace(segs[i].lines[0]), "BEGIN:VTIMEZONE") {
+			return segs[:i]
+		}
+	}
+	return segs
+}
+
+// Instants returns the absolute start and end of e, reading StartTime and
+// EndTime as wall clock in StartTZ and EndTZ (as batch builds them).
+func (e Event) Instants() (start, end time.Time) {
+	start, end = e.StartTime, e.EndTime
+	if tz := strings.TrimSpace(e.StartTZ); tz != "" {
+		start = instant(start, tz)
+	}
+	if tz := strings.TrimSpace(e.EndTZ); tz != "" {
+		end = instant(end, tz)
+	}
+	return start, end
+}
+
+// Overlaps reports whether two timed events overlap (all-day events never do).
+func Overlaps(a, b Event) bool {
+	if a.AllDay || b.AllDay {
+		return false
+	}
+	aStart, aEnd := a.Instants()
+	bStart, bEnd := b.Instants()
+	return aEnd.After(bStart) && bEnd.After(aStart)
+}
@@ -0,0 +1,102 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func appendTestCalendar(uid, summary, tz string, start time.Time) string {
+	cal := NewCalendar()
+	cal.IncludeVTZ = true
+	ev := NewEvent(summary, start, start.Add(30*time.Minute))
+	ev.UID = uid
+	ev.SetTimezone(tz)
+	cal.AddEvent(ev)
+	return cal.ToICS()
+}
+
+func TestAppendICSAddsEventsAndMissingTimezones(t *testing.T) {
+	existing := appendTestCalendar("a@test", "Standup", "Europe/Madrid", time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC))
+	existing = strings.Replace(existing, "END:VCALENDAR\r\n", "X-KEEP:me\r\nEND:VCALENDAR\r\n", 1)
+	addition := appendTestCalendar("b@test", "Review", "Europe/London", time.Date(2025, 5, 1, 8, 10, 0, 0, time.UTC))
+
+	out, n, err := AppendICS(existing, addition)
+	if err != nil || n != 1 {
+		t.Fatalf("AppendICS() = %d, %v", n, err)
+	}
+	if !strings.Contains(out, "X-KEEP:me\r\n") || !strings.HasSuffix(out, "END:VEVENT\r\nEND:VCALENDAR\r\n") {
+		t.Errorf("existing content should be kept and events added before END:VCALENDAR:\n%s", out)
+	}
+	if strings.Index(out, "UID:a@test") > strings.Index(out, "UID:b@test") {
+		t.Error("appended event should come after the existing ones")
+	}
+	if !strings.Contains(out, "TZID:Europe/London\r\n") || strings.Count(out, "BEGIN:VCALENDAR") != 1 {
+		t.Errorf("expected one calendar with the London VTIMEZONE added:\n%s", out)
+	}
+
+	events, err := ParseICSEvents(out)
+	if err != nil || len(events) != 2 {
+		t.Fatalf("ParseICSEvents() = %d events, %v", len(events), err)
+	}
+	if !Overlaps(events[0], events[1]) {
+		t.Errorf("09:00 Madrid and 08:10 London should overlap: %v / %v", events[0].StartTime, events[1].StartTime)
+	}
+	if got := events[1].StartTime.UTC().Format(time.RFC3339); got != "2025-05-01T07:10:00Z" {
+		t.Errorf("start = %s, want 2025-05-01T07:10:00Z", got)
+	}
+
+	if _, _, err := AppendICS(out, addition); err == nil || !strings.Contains(err.Error(), "b@test") {
+		t.Errorf("expected UID collision error, got %v", err)
+	}
+}
+
+func TestParseICSEventsUsesDuration(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:x\r\nDTSTART:20250501T090000Z\r\nDURATION:PT45M\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	events, err := ParseICSEvents(ics)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("ParseICSEvents() = %v, %v", events, err)
+	}
+	if d := events[0].EndTime.Sub(events[0].StartTime); d != 45*time.Minute {
+		t.Errorf("duration = %v, want 45m", d)
+	}
+}
+
+func TestParseICSEventsDurationDaysAreNominal(t *testing.T) {
+	// Europe/Madrid moves to summer time on 2025-03-30: two days later is
+	// still 10:00 on the wall clock, 47 hours on.
+	ics := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:x\r\nDTSTART;TZID=Europe/Madrid:20250329T100000\r\nDURATION:P2DT1H\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	events, err := ParseICSEvents(ics)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("ParseICSEvents() = %v, %v", events, err)
+	}
+	madrid, _ := time.LoadLocation("Europe/Madrid")
+	if end := events[0].EndTime.In(madrid); end.Format("2006-01-02 15:04") != "2025-03-31 11:00" {
+		t.Errorf("end = %v, want 2025-03-31 11:00 Madrid time", end)
+	}
+
+	if _, err := addICSDuration(time.Now(), "-PT1H"); err == nil {
+		t.Error("expected an error for a negative DURATION")
+	}
+	if got, err := addICSDuration(time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC), "PT0S"); err != nil || got.Hour() != 9 {
+		t.Errorf("PT0S should be accepted for zero-length events: %v, %v", got, err)
+	}
+}
+
+func FuzzParseICSEvents(f *testing.F) {
+	f.Add(appendTestCalendar("a@example.com", "Standup", "Europe/Madrid", time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)))
+	f.Add("BEGIN:VEVENT\r\nUID:x\r\nDTSTART;VALUE=DATE:20300304\r\nDURATION:P1W\r\nRRULE:FREQ=DAILY;INTERVAL=999999999\r\nEND:VEVENT\r\n")
+	f.Add("BEGIN:VEVENT\r\nDTSTART:20300304T090000Z\r\nDTEND:20300304T080000Z\r\nEXDATE:2030\r\nRDATE:x\r\nEND:VEVENT\r\n")
+	f.Fuzz(func(t *testing.T, data string) {
+		events, err := ParseICSEvents(data)
+		if err != nil {
+			return
+		}
+		for i := range events {
+			_ = events[i].ToICS()
+			if occ, _, err := events[i].Materialize(20, 0, nil); err == nil && len(occ) > 20+len(events[i].RDates) {
+				t.Errorf("Materialize(20) gave %d occurrences", len(occ))
+			}
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package calendar
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// SourceBundleProperty is the calendar property that carries the batch input
+// a calendar was generated from.
+const SourceBundleProperty = "X-TEMPUS-SOURCE-BUNDLE"
+
+// EnergyProperty holds an event's energy cost (1-5) for daily energy budgets.
+const EnergyProperty = "X-TEMPUS-ENERGY"
+
+// SoundProperty names the client sound of an AUDIO alarm when it is not a URI.
+const SoundProperty = "X-TEMPUS-SOUND"
+
+// maxBundleSize caps the decompressed bundle so a hostile .ics can't balloon.
+const maxBundleSize = 32 << 20
+
+// SourceBundle is a "time capsule" of the inputs behind a generated calendar:
+// the original batch file plus the tempus version and flags that processed it.
+type SourceBundle struct {
+	Version string            `json:"version"`
+	Created time.Time         `json:"created"`
+	File    string            `json:"file"`             // base name of the input file
+	Format  string            `json:"format,omitempty"` // csv, json or yaml
+	Flags   map[string]string `json:"flags,omitempty"`  // batch flags set explicitly
+	Data    string            `json:"data"`
+}
+
+// Encode returns the bundle as gzip-compressed JSON in base64, the value
+// written to X-TEMPUS-SOURCE-BUNDLE.
+func (b SourceBundle) Encode() (string, error) {
+	raw, err := json.Marshal(b)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// DecodeSourceBundle reverses Encode.
+func DecodeSourceBundle(value string) (SourceBundle, error) {
+	var b SourceBundle
+	compressed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return b, fmt.Errorf("invalid source bundle: %w", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return b, fmt.Errorf("invalid source bundle: %w", err)
+	}
+	defer zr.Close()
+	raw, err := io.ReadAll(io.LimitReader(zr, maxBundleSize+1))
+	if err != nil {
+		return b, fmt.Errorf("invalid source bundle: %w", err)
+	}
+	if len(raw) > maxBundleSize {
+		return b, fmt.Errorf("source bundle exceeds %d MiB", maxBundleSize>>20)
+	}
+	if err := json.Unmarshal(raw, &b); err != nil {
+		return b, fmt.Errorf("invalid source bundle: %w", err)
+	}
+	return b, nil
+}
+
+// ExtractSourceBundle finds and decodes the calendar-level source bundle of
+// an .ics document; ok is false when the calendar has none.
+func ExtractSourceBundle(data string) (b SourceBundle, ok bool, err error) {
+	depth := 0
+	for _, line := range unfoldICS(data) {
+		p := parseICSLine(line)
+		switch p.name {
+		case "BEGIN":
+			depth++
+		case "END":
+			depth--
+		case SourceBundleProperty:
+			if depth == 1 {
+				b, err = DecodeSourceBundle(p.value)
+				return b, err == nil, err
+			}
+		}
+	}
+	return b, false, nil
+}
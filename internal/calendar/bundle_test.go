@@ -0,0 +1,46 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSourceBundleRoundTripsThroughICS(t *testing.T) {
+	bundle := SourceBundle{
+		Version: "v1.2.3",
+		Created: time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC),
+		File:    "events.csv",
+		Format:  "csv",
+		Flags:   map[string]string{"name": "Team"},
+		Data:    "summary,start\nStandup,2025-05-01 09:00\n" + strings.Repeat("padding row\n", 50),
+	}
+	encoded, err := bundle.Encode()
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+
+	cal := NewCalendar()
+	cal.SourceBundle = encoded
+	ics := cal.ToICS()
+	if !strings.Contains(ics, SourceBundleProperty+":"+encoded[:20]) || strings.Contains(ics, encoded) {
+		t.Fatalf("expected a folded %s line:\n%s", SourceBundleProperty, ics)
+	}
+
+	got, ok, err := ExtractSourceBundle(ics)
+	if err != nil || !ok {
+		t.Fatalf("ExtractSourceBundle() = %v, %v", ok, err)
+	}
+	if got.Data != bundle.Data || got.Version != bundle.Version || got.Flags["name"] != "Team" || !got.Created.Equal(bundle.Created) {
+		t.Errorf("bundle changed on round-trip: %+v", got)
+	}
+}
+
+func TestExtractSourceBundleWithoutBundle(t *testing.T) {
+	if _, ok, err := ExtractSourceBundle(NewCalendar().ToICS()); ok || err != nil {
+		t.Errorf("expected no bundle, got ok=%v err=%v", ok, err)
+	}
+	if _, err := DecodeSourceBundle("not base64!"); err == nil {
+		t.Error("expected error for invalid bundle")
+	}
+}
@@ -1,8 +1,14 @@
 package calendar
 
 import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"net/url"
 	"strings"
+	"tempus/internal/clock"
 	"tempus/internal/constants"
 	"time"
 	"unicode/utf8"
@@ -48,13 +54,36 @@ type Calendar struct {
 	// Optional extras (safe defaults)
 	// METHOD:PUBLISH is ideal for imported .ics files (not interactive invites)
 	Method string
-	// X-WR-CALNAME (many clients show this as calendar name)
+	// Calendar name, written as NAME (RFC 7986) and X-WR-CALNAME, which many
+	// clients still read instead
 	Name string
+	// Calendar description, written as DESCRIPTION and X-WR-CALDESC
+	Description string
+	// Persistent calendar UID (RFC 7986); "" omits it
+	UID string
+	// Where to find more about the calendar (URL); "" omits it
+	URL string
+	// How often subscribers should refresh, written as REFRESH-INTERVAL and
+	// X-PUBLISHED-TTL; 0 omits both
+	RefreshInterval time.Duration
+	// Where the calendar is published, so subscribers can refresh it (SOURCE)
+	Source string
 	// X-WR-TIMEZONE helps calendar imports (e.g., Google Calendar) infer the default TZ
 	DefaultTZ string
 	// If true, embed minimal VTIMEZONE blocks for a few known TZIDs
 	// (helps older Outlook variants). Modern clients do not require this.
 	IncludeVTZ bool
+	// If set, CATEGORIES are written through it (e.g. to translate "Work" to
+	// "Trabajo"); Event.Categories keeps the canonical names.
+	TranslateCategory func(string) string
+	// Encoded SourceBundle written as X-TEMPUS-SOURCE-BUNDLE ("" omits it).
+	SourceBundle string
+	// If true, every event is written with DURATION instead of DTEND (see
+	// Event.EmitDuration).
+	EmitDuration bool
+	// Color of the whole calendar as #rrggbb, written as COLOR (the nearest
+	// CSS3 name) and X-APPLE-CALENDAR-COLOR; "" omits both.
+	Color string
 }
 
 // Event represents an ICS calendar event
@@ -69,9 +98,15 @@ type Event struct {
 	EndTZ       string
 	AllDay      bool
 	Attendees   []string
+	Organizer   string // email of who sends the invitation (ORGANIZER); "" omits it
+	URL         string // a link for the event, such as the meeting page
 	Categories  []string
 	Priority    int
+	Energy      int // effort from 1 (light) to 5 (draining), written as X-TEMPUS-ENERGY; 0 omits it
 	Status      string
+	Class       string // PUBLIC, PRIVATE or CONFIDENTIAL (CLASS); "" omits it
+	Transparent bool   // TRANSP:TRANSPARENT, the event doesn't block free/busy time
+	Color       string // #rrggbb, written as COLOR with the nearest CSS3 name (RFC 7986); "" omits it
 	Created     time.Time
 	LastMod     time.Time
 
@@ -79,14 +114,24 @@ type Event struct {
 	Sequence int         // bump on updates (0 => omit)
 	RRule    string      // e.g. FREQ=WEEKLY;BYDAY=MO
 	ExDates  []time.Time // cancellations; must match DTSTART type/TZ
-	Alarms   []Alarm     // VALARM blocks
+	RDates   []time.Time // extra occurrences (RDATE); same type/TZ rules as ExDates
+	// RecurrenceID marks an override of one occurrence of the series with the
+	// same UID: the start the rule gives it. Zero for ordinary events.
+	RecurrenceID time.Time
+	Alarms       []Alarm // VALARM blocks
+
+	// EmitDuration writes DURATION instead of DTEND. Events whose end is in
+	// another timezone than their start, or before it, keep DTEND.
+	EmitDuration bool
 }
 
 // Alarm models a VALARM block (DISPLAY is most portable)
 type Alarm struct {
-	Action            string        // DISPLAY/EMAIL (prefer DISPLAY unless you implement EMAIL properly)
+	Action            string        // DISPLAY/EMAIL/AUDIO (DISPLAY is the most portable)
 	Summary           string        // optional (useful for EMAIL)
 	Description       string        // recommended for DISPLAY (Outlook prefers this)
+	Attach            string        // optional sound for AUDIO (URI or client sound name, e.g. "Basso")
+	Attendees         []string      // EMAIL recipients (addresses, written as ATTENDEE:mailto:)
 	TriggerIsRelative bool          // true => use TriggerDuration; false => use TriggerTime (absolute UTC)
 	TriggerDuration   time.Duration // negative for "before", positive for "after"
 	TriggerTime       time.Time     // absolute UTC trigger if not relative
@@ -109,11 +154,24 @@ func NewCalendar() *Calendar {
 	}
 }
 
+// wallClock stamps new and shifted events (CREATED, LAST-MODIFIED, DTSTAMP).
+var wallClock clock.Clock = clock.System
+
+// SetClock makes the package read the current time from c instead of the
+// system clock, so generated timestamps can be pinned; nil restores the
+// system clock. Call it before generating, not concurrently with it.
+func SetClock(c clock.Clock) {
+	if c == nil {
+		c = clock.System
+	}
+	wallClock = c
+}
+
 // NewEvent creates a new event with required fields
 func NewEvent(summary string, start, end time.Time) *Event {
-	now := time.Now().UTC()
+	now := wallClock.Now().UTC()
 	return &Event{
-		UID:       generateUID(),
+		UID:       NewUID(),
 		Summary:   summary,
 		StartTime: start,
 		EndTime:   end,
@@ -174,21 +232,71 @@ func (e *Event) AddCategory(category string) {
 // ToICS (Calendar)
 //
 
+// icsWriter is what the serializer writes to: a strings.Builder for ToICS,
+// a bufio.Writer for WriteICS.
+type icsWriter interface {
+	io.StringWriter
+	io.ByteWriter
+}
+
+// ToICS renders the whole calendar as one string. Prefer WriteICS for large
+// calendars that go straight to a file or a network connection.
 func (c *Calendar) ToICS() string {
 	var b strings.Builder
+	b.Grow(1024 + 640*len(c.Events))
+	c.writeICS(&b)
+	return b.String()
+}
 
-	writeLine(&b, "BEGIN:VCALENDAR")
-	writeProp(&b, "PRODID", c.ProdID)
-	writeProp(&b, "VERSION", c.Version)
-	writeProp(&b, "CALSCALE", c.CalScale)
+// WriteICS streams the calendar to w one event at a time, so memory stays
+// flat however many events there are. It returns the first write error.
+func (c *Calendar) WriteICS(w io.Writer) error {
+	bw, ok := w.(*bufio.Writer)
+	if !ok {
+		bw = bufio.NewWriterSize(w, 32<<10)
+	}
+	c.writeICS(bw)
+	return bw.Flush()
+}
+
+func (c *Calendar) writeICS(b icsWriter) {
+	writeLine(b, "BEGIN:VCALENDAR")
+	writeProp(b, "PRODID", c.ProdID)
+	writeProp(b, "VERSION", c.Version)
+	writeProp(b, "CALSCALE", c.CalScale)
 	if strings.TrimSpace(c.Method) != "" {
-		writeProp(&b, "METHOD", c.Method)
+		writeProp(b, "METHOD", c.Method)
+	}
+	if strings.TrimSpace(c.UID) != "" {
+		writeTextProp(b, "UID", c.UID)
 	}
 	if strings.TrimSpace(c.Name) != "" {
-		writeProp(&b, "X-WR-CALNAME", escapeText(c.Name))
+		writeTextProp(b, "NAME", c.Name)
+		writeTextProp(b, "X-WR-CALNAME", c.Name)
+	}
+	if strings.TrimSpace(c.Description) != "" {
+		writeTextProp(b, "DESCRIPTION", c.Description)
+		writeTextProp(b, "X-WR-CALDESC", c.Description)
 	}
 	if strings.TrimSpace(c.DefaultTZ) != "" {
-		writeProp(&b, "X-WR-TIMEZONE", c.DefaultTZ)
+		writeProp(b, "X-WR-TIMEZONE", c.DefaultTZ)
+	}
+	if strings.TrimSpace(c.URL) != "" {
+		writeProp(b, "URL", c.URL)
+	}
+	if strings.TrimSpace(c.Source) != "" {
+		writeProp(b, "SOURCE;VALUE=URI", c.Source)
+	}
+	if c.RefreshInterval > 0 {
+		writeProp(b, "REFRESH-INTERVAL;VALUE=DURATION", formatICSDuration(c.RefreshInterval))
+		writeProp(b, "X-PUBLISHED-TTL", formatICSDuration(c.RefreshInterval))
+	}
+	if name := ColorName(c.Color); name != "" {
+		writeProp(b, "COLOR", name)
+		writeProp(b, "X-APPLE-CALENDAR-COLOR", strings.ToUpper(c.Color))
+	}
+	if c.SourceBundle != "" {
+		writeProp(b, SourceBundleProperty, c.SourceBundle)
 	}
 
 	// Optional VTIMEZONE blocks for common TZIDs (only if requested)
@@ -200,12 +308,25 @@ func (c *Calendar) ToICS() string {
 		}
 	}
 
-	for _, event := range c.Events {
-		b.WriteString(event.ToICS())
+	var translated []string // reused: each event is written before the next one
+	for i := range c.Events {
+		event := &c.Events[i]
+		if (c.TranslateCategory != nil && len(event.Categories) > 0) || (c.EmitDuration && !event.EmitDuration) {
+			copied := *event
+			if c.TranslateCategory != nil && len(event.Categories) > 0 {
+				translated = translated[:0]
+				for _, cat := range event.Categories {
+					translated = append(translated, c.TranslateCategory(cat))
+				}
+				copied.Categories = translated
+			}
+			copied.EmitDuration = copied.EmitDuration || c.EmitDuration
+			event = &copied
+		}
+		event.writeICS(b)
 	}
 
-	writeLine(&b, "END:VCALENDAR")
-	return b.String()
+	writeLine(b, "END:VCALENDAR")
 }
 
 //
@@ -214,46 +335,54 @@ func (c *Calendar) ToICS() string {
 
 func (e *Event) ToICS() string {
 	var b strings.Builder
-	writeLine(&b, "BEGIN:VEVENT")
+	e.writeICS(&b)
+	return b.String()
+}
 
-	e.writeBasicProperties(&b)
-	e.writeDateTimeProperties(&b)
-	e.writeRecurrenceProperties(&b)
-	e.writeOptionalProperties(&b)
-	e.writeAlarms(&b)
-	e.writeTimestamps(&b)
+func (e *Event) writeICS(b icsWriter) {
+	writeLine(b, "BEGIN:VEVENT")
 
-	writeLine(&b, "END:VEVENT")
-	return b.String()
+	e.writeBasicProperties(b)
+	e.writeDateTimeProperties(b)
+	e.writeRecurrenceProperties(b)
+	e.writeOptionalProperties(b)
+	e.writeAlarms(b)
+	e.writeTimestamps(b)
+
+	writeLine(b, "END:VEVENT")
 }
 
-func (e *Event) writeBasicProperties(b *strings.Builder) {
+func (e *Event) writeBasicProperties(b icsWriter) {
 	writeProp(b, "UID", e.UID)
 
 	// DTSTAMP (UTC); use Created if available, else now
 	dtstamp := e.Created
 	if dtstamp.IsZero() {
-		dtstamp = time.Now().UTC()
+		dtstamp = wallClock.Now().UTC()
 	}
 	writeProp(b, "DTSTAMP", dtstamp.UTC().Format(constants.ICSFormatUTC))
 
 	if s := strings.TrimSpace(e.Summary); s != "" {
-		writeProp(b, "SUMMARY", escapeText(s))
+		writeTextProp(b, "SUMMARY", s)
 	}
 
 	if d := strings.TrimSpace(e.Description); d != "" {
-		writeProp(b, "DESCRIPTION", escapeText(normalizeUserNewlines(d)))
+		writeTextProp(b, "DESCRIPTION", normalizeUserNewlines(d))
 	}
 
 	if l := strings.TrimSpace(e.Location); l != "" {
-		writeProp(b, "LOCATION", escapeText(normalizeUserNewlines(l)))
+		writeTextProp(b, "LOCATION", normalizeUserNewlines(l))
 	}
 }
 
-func (e *Event) writeDateTimeProperties(b *strings.Builder) {
+func (e *Event) writeDateTimeProperties(b icsWriter) {
 	if e.AllDay {
 		writeProp(b, "DTSTART;VALUE=DATE", e.StartTime.Format(constants.ICSFormatDateOnly))
-		writeProp(b, "DTEND;VALUE=DATE", e.EndTime.Format(constants.ICSFormatDateOnly))
+		if e.writesDuration() {
+			writeProp(b, "DURATION", eventDuration(e.StartTime, e.EndTime))
+		} else {
+			writeProp(b, "DTEND;VALUE=DATE", e.EndTime.Format(constants.ICSFormatDateOnly))
+		}
 		return
 	}
 
@@ -263,6 +392,10 @@ func (e *Event) writeDateTimeProperties(b *strings.Builder) {
 		writeProp(b, "DTSTART", e.StartTime.UTC().Format(constants.ICSFormatUTC))
 	}
 
+	if e.writesDuration() {
+		writeProp(b, "DURATION", eventDuration(e.StartTime, e.EndTime))
+		return
+	}
 	if tz := strings.TrimSpace(e.EndTZ); tz != "" {
 		writeProp(b, "DTEND;TZID="+tz, e.EndTime.Format(constants.ICSFormatLocal))
 	} else {
@@ -270,43 +403,95 @@ func (e *Event) writeDateTimeProperties(b *strings.Builder) {
 	}
 }
 
-func (e *Event) writeRecurrenceProperties(b *strings.Builder) {
+// writesDuration reports whether the event is written with DURATION: only
+// when asked to, and when DURATION can say where it ends.
+func (e *Event) writesDuration() bool {
+	if !e.EmitDuration || e.EndTime.Before(e.StartTime) {
+		return false
+	}
+	endTZ := strings.TrimSpace(e.EndTZ)
+	return endTZ == "" || endTZ == strings.TrimSpace(e.StartTZ)
+}
+
+// eventDuration is the RFC 5545 DURATION from start to end. Whole days are
+// nominal (they keep the wall-clock time across a DST change, as readers add
+// them) and the rest is exact, so "P2DT3H" ends where DTEND would.
+func eventDuration(start, end time.Time) string {
+	days := int(end.Sub(start).Hours()/24) - 1
+	if days < 0 {
+		days = 0
+	}
+	for !start.AddDate(0, 0, days+1).After(end) {
+		days++
+	}
+	for days > 0 && start.AddDate(0, 0, days).After(end) {
+		days--
+	}
+	rest := end.Sub(start.AddDate(0, 0, days))
+	if days == 0 && rest == 0 {
+		return "PT0S"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("P")
+	if days > 0 {
+		fmt.Fprintf(&sb, "%dD", days)
+	}
+	if rest > 0 {
+		sb.WriteByte('T')
+		if h := int(rest / time.Hour); h > 0 {
+			fmt.Fprintf(&sb, "%dH", h)
+		}
+		if m := int(rest % time.Hour / time.Minute); m > 0 {
+			fmt.Fprintf(&sb, "%dM", m)
+		}
+		if sec := int(rest % time.Minute / time.Second); sec > 0 {
+			fmt.Fprintf(&sb, "%dS", sec)
+		}
+	}
+	return sb.String()
+}
+
+func (e *Event) writeRecurrenceProperties(b icsWriter) {
+	if !e.RecurrenceID.IsZero() {
+		e.writeDateList(b, "RECURRENCE-ID", []time.Time{e.RecurrenceID})
+	}
 	if strings.TrimSpace(e.RRule) != "" {
 		writeProp(b, "RRULE", e.RRule)
 	}
 
 	if len(e.ExDates) > 0 {
-		e.writeExDates(b)
+		e.writeDateList(b, "EXDATE", e.ExDates)
+	}
+	if len(e.RDates) > 0 {
+		e.writeDateList(b, "RDATE", e.RDates)
 	}
 }
 
-func (e *Event) writeExDates(b *strings.Builder) {
-	if e.AllDay {
-		var parts []string
-		for _, x := range e.ExDates {
+// writeDateList writes an EXDATE, RDATE or RECURRENCE-ID property, as dates for all-day
+// events and otherwise in the start's timezone (or UTC), like DTSTART.
+func (e *Event) writeDateList(b icsWriter, name string, dates []time.Time) {
+	parts := make([]string, 0, len(dates))
+	switch tz := strings.TrimSpace(e.StartTZ); {
+	case e.AllDay:
+		for _, x := range dates {
 			parts = append(parts, x.Format(constants.ICSFormatDateOnly))
 		}
-		writeProp(b, "EXDATE;VALUE=DATE", strings.Join(parts, ","))
-		return
-	}
-
-	if strings.TrimSpace(e.StartTZ) != "" {
-		var parts []string
-		for _, x := range e.ExDates {
+		writeProp(b, name+";VALUE=DATE", strings.Join(parts, ","))
+	case tz != "":
+		for _, x := range dates {
 			parts = append(parts, x.Format(constants.ICSFormatLocal))
 		}
-		writeProp(b, "EXDATE;TZID="+e.StartTZ, strings.Join(parts, ","))
-		return
-	}
-
-	var parts []string
-	for _, x := range e.ExDates {
-		parts = append(parts, x.UTC().Format(constants.ICSFormatUTC))
+		writeProp(b, name+";TZID="+tz, strings.Join(parts, ","))
+	default:
+		for _, x := range dates {
+			parts = append(parts, x.UTC().Format(constants.ICSFormatUTC))
+		}
+		writeProp(b, name, strings.Join(parts, ","))
 	}
-	writeProp(b, "EXDATE", strings.Join(parts, ","))
 }
 
-func (e *Event) writeOptionalProperties(b *strings.Builder) {
+func (e *Event) writeOptionalProperties(b icsWriter) {
 	if len(e.Attendees) > 0 {
 		for _, a := range e.Attendees {
 			a = strings.TrimSpace(a)
@@ -316,14 +501,26 @@ func (e *Event) writeOptionalProperties(b *strings.Builder) {
 			writeProp(b, "ATTENDEE", "mailto:"+a)
 		}
 	}
+	if o := strings.TrimSpace(e.Organizer); o != "" {
+		writeProp(b, "ORGANIZER", "mailto:"+o)
+	}
+	if u := strings.TrimSpace(e.URL); u != "" {
+		writeProp(b, "URL", u)
+	}
 
 	if len(e.Categories) > 0 {
 		writeProp(b, "CATEGORIES", strings.Join(e.Categories, ","))
 	}
+	if name := ColorName(e.Color); name != "" {
+		writeProp(b, "COLOR", name)
+	}
 
 	if e.Priority > 0 {
 		writeProp(b, "PRIORITY", fmt.Sprintf("%d", e.Priority))
 	}
+	if e.Energy > 0 {
+		writeProp(b, EnergyProperty, fmt.Sprintf("%d", e.Energy))
+	}
 
 	// STATUS (default to CONFIRMED if empty for consistency)
 	if s := strings.TrimSpace(e.Status); s == "" {
@@ -331,9 +528,15 @@ func (e *Event) writeOptionalProperties(b *strings.Builder) {
 	} else {
 		writeProp(b, "STATUS", s)
 	}
+	if c := strings.TrimSpace(e.Class); c != "" {
+		writeProp(b, "CLASS", c)
+	}
+	if e.Transparent {
+		writeProp(b, "TRANSP", "TRANSPARENT")
+	}
 }
 
-func (e *Event) writeAlarms(b *strings.Builder) {
+func (e *Event) writeAlarms(b icsWriter) {
 	for _, al := range e.Alarms {
 		writeLine(b, "BEGIN:VALARM")
 
@@ -350,7 +553,7 @@ func (e *Event) writeAlarms(b *strings.Builder) {
 	}
 }
 
-func (e *Event) writeAlarmTrigger(b *strings.Builder, al Alarm) {
+func (e *Event) writeAlarmTrigger(b icsWriter, al Alarm) {
 	if al.TriggerIsRelative {
 		writeProp(b, "TRIGGER", formatICSDuration(al.TriggerDuration))
 	} else {
@@ -358,17 +561,30 @@ func (e *Event) writeAlarmTrigger(b *strings.Builder, al Alarm) {
 	}
 }
 
-func (e *Event) writeAlarmDetails(b *strings.Builder, al Alarm, action string) {
-	if action == "DISPLAY" {
-		desc := strings.TrimSpace(al.Description)
-		if desc == "" {
-			desc = "Reminder"
+func (e *Event) writeAlarmDetails(b icsWriter, al Alarm, action string) {
+	switch action {
+	case constants.AlarmActionAudio:
+		// AUDIO alarms carry no text; ATTACH is optional (clients fall back to a default sound).
+		// A bare client sound name like "Basso" is not a URI, so it goes in SoundProperty.
+		if attach := strings.TrimSpace(al.Attach); isURI(attach) {
+			writeProp(b, "ATTACH;VALUE=URI", attach)
+		} else if attach != "" {
+			writeTextProp(b, SoundProperty, attach)
+		}
+	case constants.AlarmActionEmail:
+		// RFC 5545 requires both DESCRIPTION (body) and SUMMARY (subject) for EMAIL.
+		writeTextProp(b, "DESCRIPTION", alarmTextOrDefault(al.Description))
+		writeTextProp(b, "SUMMARY", alarmTextOrDefault(al.Summary))
+		for _, a := range al.Attendees {
+			if a = strings.TrimSpace(a); a != "" {
+				writeProp(b, "ATTENDEE", "mailto:"+a)
+			}
+		}
+	default:
+		writeTextProp(b, "DESCRIPTION", alarmTextOrDefault(al.Description))
+		if strings.TrimSpace(al.Summary) != "" {
+			writeTextProp(b, "SUMMARY", al.Summary)
 		}
-		writeProp(b, "DESCRIPTION", escapeText(desc))
-	}
-
-	if strings.TrimSpace(al.Summary) != "" {
-		writeProp(b, "SUMMARY", escapeText(al.Summary))
 	}
 
 	if al.Repeat > 0 && al.RepeatDuration > 0 {
@@ -377,7 +593,20 @@ func (e *Event) writeAlarmDetails(b *strings.Builder, al Alarm, action string) {
 	}
 }
 
-func (e *Event) writeTimestamps(b *strings.Builder) {
+// isURI reports whether s is an absolute URI (it has a scheme), as VALUE=URI requires.
+func isURI(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != ""
+}
+
+func alarmTextOrDefault(s string) string {
+	if t := strings.TrimSpace(s); t != "" {
+		return t
+	}
+	return "Reminder"
+}
+
+func (e *Event) writeTimestamps(b icsWriter) {
 	if e.Sequence > 0 {
 		writeProp(b, "SEQUENCE", fmt.Sprintf("%d", e.Sequence))
 	}
@@ -397,16 +626,39 @@ func (e *Event) writeTimestamps(b *strings.Builder) {
 //
 // Also strips CR and normalizes CRLF to LF first.
 func escapeText(text string) string {
-	if text == "" {
-		return ""
+	i, esc := nextEscape(text)
+	if i < 0 {
+		return text
 	}
-	text = strings.ReplaceAll(text, "\r\n", "\n")
-	text = strings.ReplaceAll(text, "\r", "")
-	text = strings.ReplaceAll(text, `\`, `\\`)
-	text = strings.ReplaceAll(text, ";", `\;`)
-	text = strings.ReplaceAll(text, ",", `\,`)
-	text = strings.ReplaceAll(text, "\n", `\n`)
-	return text
+	var b strings.Builder
+	b.Grow(len(text) + 16)
+	for ; i >= 0; i, esc = nextEscape(text) {
+		b.WriteString(text[:i])
+		b.WriteString(esc)
+		text = text[i+1:]
+	}
+	b.WriteString(text)
+	return b.String()
+}
+
+// nextEscape finds the next byte of text that escapeText rewrites and what it
+// becomes, or -1. Dropping every CR turns CRLF into a plain LF for free.
+func nextEscape(text string) (int, string) {
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			return i, `\\`
+		case ';':
+			return i, `\;`
+		case ',':
+			return i, `\,`
+		case '\n':
+			return i, `\n`
+		case '\r':
+			return i, ""
+		}
+	}
+	return -1, ""
 }
 
 // normalizeUserNewlines converts user-typed "\n" sequences into real newlines
@@ -419,21 +671,64 @@ func normalizeUserNewlines(s string) string {
 }
 
 // writeProp writes "KEY:VALUE" with folding and CRLF.
-func writeProp(b *strings.Builder, key, value string) {
-	writeLine(b, key+":"+value)
+func writeProp(b icsWriter, key, value string) {
+	f := lineFolder{b: b}
+	f.write(key)
+	f.write(":")
+	f.write(value)
+	b.WriteString("\r\n")
+}
+
+// writeTextProp writes a TEXT property, escaping value as it is folded so no
+// escaped copy of it is built.
+func writeTextProp(b icsWriter, key, value string) {
+	f := lineFolder{b: b}
+	f.write(key)
+	f.write(":")
+	for i, esc := nextEscape(value); i >= 0; i, esc = nextEscape(value) {
+		f.write(value[:i])
+		f.write(esc)
+		value = value[i+1:]
+	}
+	f.write(value)
+	b.WriteString("\r\n")
 }
 
 // writeLine writes a single logical iCalendar line applying RFC 5545 folding.
 // Lines longer than 75 octets are folded by inserting CRLF + space.
-func writeLine(b *strings.Builder, line string) {
-	folded := foldICalLine(line, 75)
-	for i, seg := range folded {
-		if i == 0 {
-			b.WriteString(seg + "\r\n")
-		} else {
-			b.WriteString(" " + seg + "\r\n")
+func writeLine(b icsWriter, line string) {
+	f := lineFolder{b: b}
+	f.write(line)
+	b.WriteString("\r\n")
+}
+
+// lineFolder writes one logical line piece by piece, folding before any rune
+// that would take it past 75 octets, the same way foldICalLine splits it, but
+// without building the line or its segments first.
+type lineFolder struct {
+	b icsWriter
+	n int // octets on the current physical line, not counting the fold's space
+}
+
+func (f *lineFolder) write(s string) {
+	const limit = 75
+	if f.n+len(s) <= limit {
+		f.b.WriteString(s)
+		f.n += len(s)
+		return
+	}
+	start := 0
+	for i := 0; i < len(s); {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		if f.n+size > limit && f.n > 0 {
+			f.b.WriteString(s[start:i])
+			f.b.WriteString("\r\n ")
+			f.n, start = 0, i
 		}
+		f.n += size
+		i += size
 	}
+	f.b.WriteString(s[start:])
 }
 
 // foldICalLine splits a string into segments of at most limit octets.
@@ -466,10 +761,42 @@ func foldICalLine(s string, limit int) []string {
 	return segments
 }
 
-// generateUID generates a unique identifier for events
-func generateUID() string {
+// uidTemplate is the UID template with its domain filled in; formatUID puts
+// the unique part in place of {{id}}.
+var uidTemplate = strings.ReplaceAll(constants.DefaultUIDTemplate, "{{domain}}", constants.DefaultUIDDomain)
+
+// SetUIDFormat makes new UIDs follow template, where {{id}} stands for the
+// unique part and {{domain}} for domain (config's uid_template and
+// uid_domain; a leading "@" on domain is dropped). Empty values restore the
+// defaults, "{{id}}@tempus". The caller checks the values; call it before
+// generating, not concurrently with it.
+func SetUIDFormat(template, domain string) {
+	if strings.TrimSpace(template) == "" {
+		template = constants.DefaultUIDTemplate
+	}
+	domain = strings.TrimPrefix(strings.TrimSpace(domain), "@")
+	if domain == "" {
+		domain = constants.DefaultUIDDomain
+	}
+	uidTemplate = strings.ReplaceAll(strings.TrimSpace(template), "{{domain}}", domain)
+}
+
+func formatUID(id string) string {
+	return strings.ReplaceAll(uidTemplate, "{{id}}", id)
+}
+
+// NewUID generates a unique identifier for events.
+func NewUID() string {
 	// Use UUID v4 to ensure uniqueness even when generating events in parallel
-	return fmt.Sprintf("%s@tempus", uuid.New().String())
+	return formatUID(uuid.New().String())
+}
+
+// StableUID derives a deterministic UID from identifying parts (e.g. summary,
+// start and timezone), so regenerating the same input yields the same UIDs and
+// calendar apps update events instead of duplicating them.
+func StableUID(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x1f")))
+	return formatUID(hex.EncodeToString(sum[:16]))
 }
 
 // formatICSDuration converts a Go duration to an RFC 5545 DURATION (e.g., -PT15M, PT1H30M).
@@ -2,10 +2,14 @@ package calendar
 
 import (
 	"fmt"
+	"io"
+	"runtime"
 	"strings"
+	"tempus/internal/clock"
 	"tempus/internal/testutil"
 	"testing"
 	"time"
+	"unicode/utf8"
 )
 
 func TestNewCalendar(t *testing.T) {
@@ -407,6 +411,27 @@ func TestCategories(t *testing.T) {
 	}
 }
 
+func TestTranslateCategoryOnlyAffectsOutput(t *testing.T) {
+	cal := NewCalendar()
+	cal.TranslateCategory = func(c string) string {
+		if c == "Work" {
+			return "Trabajo"
+		}
+		return c
+	}
+	event := NewEvent("Test", time.Now(), time.Now().Add(1*time.Hour))
+	event.AddCategory("Work")
+	event.AddCategory("Custom")
+	cal.AddEvent(event)
+
+	if ics := cal.ToICS(); !strings.Contains(ics, "CATEGORIES:Trabajo,Custom") {
+		t.Errorf("expected translated categories:\n%s", ics)
+	}
+	if got := cal.Events[0].Categories[0]; got != "Work" {
+		t.Errorf("event should keep canonical category, got %q", got)
+	}
+}
+
 func TestAttendees(t *testing.T) {
 	cal := NewCalendar()
 	event := NewEvent("Meeting", time.Now(), time.Now().Add(1*time.Hour))
@@ -971,6 +996,106 @@ func TestEventWithExDatesUTC(t *testing.T) {
 	}
 }
 
+func TestEventWithRDates(t *testing.T) {
+	loc, _ := time.LoadLocation(testutil.TZAmericaNewYork)
+	start := time.Date(2025, 11, 17, 10, 0, 0, 0, loc)
+	event := NewEvent("Class", start, start.Add(time.Hour))
+	event.SetTimezone(testutil.TZAmericaNewYork)
+	event.RRule = testutil.ICSRRuleWeeklyMonday
+	event.RDates = []time.Time{time.Date(2025, 11, 22, 10, 0, 0, 0, loc), time.Date(2025, 11, 29, 9, 0, 0, 0, loc)}
+
+	cal := NewCalendar()
+	cal.AddEvent(event)
+	if ics := cal.ToICS(); !strings.Contains(ics, "RDATE;TZID=America/New_York:20251122T100000,20251129T090000") {
+		t.Errorf("RDATE should be written in the start timezone:\n%s", ics)
+	}
+
+	allDay := NewEvent("Makeup day", start, start.Add(24*time.Hour))
+	allDay.AllDay = true
+	allDay.RDates = []time.Time{time.Date(2025, 11, 22, 0, 0, 0, 0, time.UTC)}
+	cal = NewCalendar()
+	cal.AddEvent(allDay)
+	if ics := cal.ToICS(); !strings.Contains(ics, "RDATE;VALUE=DATE:20251122") {
+		t.Errorf("all-day RDATE should use VALUE=DATE:\n%s", ics)
+	}
+}
+
+func TestEventWithOrganizerAndURL(t *testing.T) {
+	start := time.Date(2025, 11, 17, 10, 0, 0, 0, time.UTC)
+	event := NewEvent("Review", start, start.Add(time.Hour))
+	event.Organizer = "lead@example.com"
+	event.URL = "https://meet.example.com/review"
+
+	ics := event.ToICS()
+	for _, want := range []string{"ORGANIZER:mailto:lead@example.com", "URL:https://meet.example.com/review"} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("event missing %q:\n%s", want, ics)
+		}
+	}
+	if plain := NewEvent("Plain", start, start.Add(time.Hour)).ToICS(); strings.Contains(plain, "ORGANIZER") || strings.Contains(plain, "URL:") {
+		t.Errorf("unset organizer and URL should be omitted:\n%s", plain)
+	}
+}
+
+func TestEventEmitDuration(t *testing.T) {
+	madrid, _ := time.LoadLocation("Europe/Madrid")
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       string
+	}{
+		{"minutes", time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC), time.Date(2025, 5, 1, 9, 45, 0, 0, time.UTC), "PT45M"},
+		{"multi-day", time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC), time.Date(2025, 5, 3, 12, 30, 0, 0, time.UTC), "P2DT3H30M"},
+		{"exact days", time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC), time.Date(2025, 5, 8, 9, 0, 0, 0, time.UTC), "P7D"},
+		{"across DST", time.Date(2025, 3, 29, 10, 0, 0, 0, madrid), time.Date(2025, 3, 31, 10, 0, 0, 0, madrid), "P2D"},
+		{"zero", time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC), time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC), "PT0S"},
+	}
+	for _, tt := range tests {
+		if got := eventDuration(tt.start, tt.end); got != tt.want {
+			t.Errorf("%s: eventDuration() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	start := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+	cal := NewCalendar()
+	cal.EmitDuration = true
+	cal.AddEvent(NewEvent("Workshop", start, start.Add(26*time.Hour)))
+	flight := NewEvent("Flight", start, start.Add(8*time.Hour))
+	flight.SetStartTimezone("Europe/Madrid")
+	flight.SetEndTimezone("America/New_York")
+	cal.AddEvent(flight)
+	holiday := NewEvent("Holiday", start, start.AddDate(0, 0, 3))
+	holiday.AllDay = true
+	cal.AddEvent(holiday)
+
+	ics := cal.ToICS()
+	for _, want := range []string{"DURATION:P1DT2H\r\n", "DTEND;TZID=America/New_York:", "DURATION:P3D\r\n"} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("calendar missing %q:\n%s", want, ics)
+		}
+	}
+	if n := strings.Count(ics, "DTEND"); n != 1 {
+		t.Errorf("only the event ending in another timezone should keep DTEND, got %d", n)
+	}
+}
+
+func TestEventStatusAndClass(t *testing.T) {
+	start := time.Date(2025, 11, 17, 10, 0, 0, 0, time.UTC)
+	event := NewEvent("1:1", start, start.Add(time.Hour))
+	event.Status = "TENTATIVE"
+	event.Class = "PRIVATE"
+
+	ics := event.ToICS()
+	for _, want := range []string{"STATUS:TENTATIVE", "CLASS:PRIVATE"} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("event missing %q:\n%s", want, ics)
+		}
+	}
+	if plain := NewEvent("Plain", start, start.Add(time.Hour)).ToICS(); strings.Contains(plain, "CLASS:") {
+		t.Errorf("an unset class should be omitted:\n%s", plain)
+	}
+}
+
 // ========================================
 // Test multi-timezone events (flights)
 // ========================================
@@ -1062,6 +1187,19 @@ func TestEventStatusVariations(t *testing.T) {
 	}
 }
 
+func TestEventTransparent(t *testing.T) {
+	cal := NewCalendar()
+	event := NewEvent("Buffer", time.Now(), time.Now().Add(15*time.Minute))
+	cal.AddEvent(event)
+	if strings.Contains(cal.ToICS(), "TRANSP:") {
+		t.Error("expected no TRANSP for an opaque event")
+	}
+	cal.Events[0].Transparent = true
+	if ics := cal.ToICS(); !strings.Contains(ics, "TRANSP:TRANSPARENT\r\n") {
+		t.Errorf("expected TRANSP:TRANSPARENT, got:\n%s", ics)
+	}
+}
+
 // ========================================
 // Test DTSTAMP handling when Created is zero
 // ========================================
@@ -1607,15 +1745,15 @@ func TestICSUsesProperCRLFLineEndings(t *testing.T) {
 }
 
 // ========================================
-// Test generateUID uniqueness
+// Test NewUID uniqueness
 // ========================================
 
-func TestGenerateUIDUniqueness(t *testing.T) {
+func TestNewUIDUniqueness(t *testing.T) {
 	uids := make(map[string]bool)
 	iterations := 100
 
 	for i := 0; i < iterations; i++ {
-		uid := generateUID()
+		uid := NewUID()
 		if uids[uid] {
 			t.Errorf("Duplicate UID generated: %s", uid)
 		}
@@ -1630,6 +1768,30 @@ func TestGenerateUIDUniqueness(t *testing.T) {
 	}
 }
 
+func TestSetUIDFormat(t *testing.T) {
+	t.Cleanup(func() { SetUIDFormat("", "") })
+
+	SetUIDFormat("evt-{{id}}@{{domain}}", "@calendar.example.com")
+	uid := NewUID()
+	if !strings.HasPrefix(uid, "evt-") || !strings.HasSuffix(uid, "@calendar.example.com") || len(uid) != len("evt-@calendar.example.com")+36 {
+		t.Errorf("NewUID() = %q, want evt-<uuid>@calendar.example.com", uid)
+	}
+	stable := StableUID("Standup", "2030-03-04 09:00")
+	if stable != StableUID("Standup", "2030-03-04 09:00") || !strings.HasSuffix(stable, "@calendar.example.com") {
+		t.Errorf("StableUID() = %q, want a stable UID in the configured domain", stable)
+	}
+
+	SetUIDFormat("{{id}}", "")
+	if uid := NewUID(); strings.Contains(uid, "@") {
+		t.Errorf("a template without a domain should give a bare id, got %q", uid)
+	}
+
+	SetUIDFormat("", "")
+	if want := strings.TrimPrefix(strings.TrimSuffix(stable, "@calendar.example.com"), "evt-") + "@tempus"; StableUID("Standup", "2030-03-04 09:00") != want {
+		t.Errorf("defaults should restore @tempus UIDs with the same id, got %q", StableUID("Standup", "2030-03-04 09:00"))
+	}
+}
+
 // ========================================
 // Test escaping in calendar name
 // ========================================
@@ -2366,6 +2528,19 @@ func TestAlarmsParserParseKeyValueAlarmSpec(t *testing.T) {
 	}
 }
 
+func TestParseAlarmSpecsEpochTrigger(t *testing.T) {
+	alarms, err := ParseAlarmSpecs([]string{"1763197200", "trigger=2025-11-15T10:00:00+01:00"}, "")
+	if err != nil {
+		t.Fatalf("ParseAlarmSpecs() error = %v", err)
+	}
+	want := time.Date(2025, 11, 15, 9, 0, 0, 0, time.UTC)
+	for i, al := range alarms {
+		if al.TriggerIsRelative || !al.TriggerTime.Equal(want) {
+			t.Errorf("alarm %d: trigger = %v (relative %v), want absolute %v", i, al.TriggerTime, al.TriggerIsRelative, want)
+		}
+	}
+}
+
 // ========================================
 // Test parseAlarmAbsolute function
 // ========================================
@@ -2389,6 +2564,18 @@ func TestAlarmsParserParseAlarmAbsolute(t *testing.T) {
 			defaultTZ: "",
 			wantErr:   false,
 		},
+		{
+			name:      "offset without seconds",
+			input:     "2025-11-15T10:00+01:00",
+			defaultTZ: "",
+			wantErr:   false,
+		},
+		{
+			name:      "unix epoch",
+			input:     "@1763200800",
+			defaultTZ: "",
+			wantErr:   false,
+		},
 		{
 			name:      "date time with seconds",
 			input:     testutil.DateTime20251115_1000,
@@ -3144,3 +3331,384 @@ func TestAlarmsParserEdgeCaseSplitAlarmInputComplexNested(t *testing.T) {
 		t.Errorf("Expected 3 items, got %d: %v", len(result), result)
 	}
 }
+
+// ========================================
+// Test VALARM action serializations
+// ========================================
+
+func TestAlarmActionSerialization(t *testing.T) {
+	tests := []struct {
+		name      string
+		alarm     Alarm
+		contains  []string
+		forbidden []string
+	}{
+		{
+			name:      "display",
+			alarm:     Alarm{Action: "DISPLAY", Description: "Stand up", TriggerIsRelative: true, TriggerDuration: -5 * time.Minute},
+			contains:  []string{"ACTION:DISPLAY", "DESCRIPTION:Stand up", "TRIGGER:-PT5M"},
+			forbidden: []string{"ATTACH"},
+		},
+		{
+			name:     "email",
+			alarm:    Alarm{Action: "EMAIL", Description: "Meeting soon", TriggerIsRelative: true, TriggerDuration: -time.Hour},
+			contains: []string{"ACTION:EMAIL", "DESCRIPTION:Meeting soon", "SUMMARY:Reminder", "TRIGGER:-PT1H"},
+		},
+		{
+			name:      "audio with sound",
+			alarm:     Alarm{Action: "AUDIO", Attach: "Basso", TriggerIsRelative: true, TriggerDuration: -5 * time.Minute},
+			contains:  []string{"ACTION:AUDIO", "X-TEMPUS-SOUND:Basso", "TRIGGER:-PT5M"},
+			forbidden: []string{"ATTACH", "DESCRIPTION:Reminder"},
+		},
+		{
+			name:     "audio with sound URI",
+			alarm:    Alarm{Action: "AUDIO", Attach: "file:///System/Library/Sounds/Basso.aiff", TriggerIsRelative: true, TriggerDuration: -5 * time.Minute},
+			contains: []string{"ACTION:AUDIO", "ATTACH;VALUE=URI:file:///System/Library/Sounds/Basso.aiff"},
+		},
+		{
+			name:      "audio without sound",
+			alarm:     Alarm{Action: "audio", TriggerIsRelative: true, TriggerDuration: -time.Minute},
+			contains:  []string{"ACTION:AUDIO"},
+			forbidden: []string{"ATTACH", "DESCRIPTION:Reminder"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Date(2025, 12, 16, 9, 0, 0, 0, time.UTC)
+			event := NewEvent("Alarm test", start, start.Add(time.Hour))
+			event.Alarms = []Alarm{tt.alarm}
+			ics := event.ToICS()
+
+			for _, want := range tt.contains {
+				if !strings.Contains(ics, want) {
+					t.Errorf("expected %q in:\n%s", want, ics)
+				}
+			}
+			for _, bad := range tt.forbidden {
+				if strings.Contains(ics, bad) {
+					t.Errorf("did not expect %q in:\n%s", bad, ics)
+				}
+			}
+		})
+	}
+}
+
+func TestParseAlarmSpecsAudioSound(t *testing.T) {
+	alarms, err := ParseAlarmSpecs([]string{"trigger=-5m,action=AUDIO,sound=Basso"}, "")
+	if err != nil {
+		t.Fatalf("ParseAlarmSpecs returned error: %v", err)
+	}
+	if alarms[0].Action != "AUDIO" || alarms[0].Attach != "Basso" {
+		t.Fatalf("unexpected alarm: %+v", alarms[0])
+	}
+	if alarms[0].Description != "" {
+		t.Errorf("AUDIO alarm should not get a default description, got %q", alarms[0].Description)
+	}
+}
+
+func TestParseAlarmSpecsSoundImpliesAudio(t *testing.T) {
+	alarms, err := ParseAlarmSpecs([]string{"trigger=-5m,sound=file:///sounds/chime.aiff"}, "")
+	if err != nil {
+		t.Fatalf("ParseAlarmSpecs returned error: %v", err)
+	}
+	if alarms[0].Action != "AUDIO" {
+		t.Fatalf("expected AUDIO action, got %q", alarms[0].Action)
+	}
+}
+
+func TestParseAlarmSpecsRejectsInvalidActions(t *testing.T) {
+	specs := []string{
+		"trigger=-5m,action=PROCEDURE",
+		"trigger=-5m,action=BEEP",
+		"trigger=-5m,action=DISPLAY,sound=Basso",
+	}
+	for _, spec := range specs {
+		if _, err := ParseAlarmSpecs([]string{spec}, ""); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+}
+
+func TestParseAlarmSpecsEmailRecipients(t *testing.T) {
+	alarms, err := ParseAlarmSpecs([]string{
+		"trigger=-1h,to=ana@example.com;Ben <ben@example.com>,summary=Prep",
+		"trigger=-1d,action=EMAIL,to=mailto:cat@example.com|dan@example.com",
+	}, "")
+	if err != nil {
+		t.Fatalf("ParseAlarmSpecs returned error: %v", err)
+	}
+	if alarms[0].Action != "EMAIL" || alarms[0].Summary != "Prep" {
+		t.Errorf("to= should imply EMAIL and keep later keys, got %+v", alarms[0])
+	}
+	if got := strings.Join(alarms[0].Attendees, " "); got != "ana@example.com ben@example.com" {
+		t.Errorf("recipients = %q", got)
+	}
+	if got := strings.Join(alarms[1].Attendees, " "); got != "cat@example.com dan@example.com" {
+		t.Errorf("recipients = %q", got)
+	}
+
+	ev := NewEvent("Review", time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC), time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC))
+	ev.Alarms = alarms[:1]
+	cal := NewCalendar()
+	cal.AddEvent(ev)
+	ics := cal.ToICS()
+	alarm := ics[strings.Index(ics, "BEGIN:VALARM"):strings.Index(ics, "END:VALARM")]
+	for _, want := range []string{"ACTION:EMAIL", "ATTENDEE:mailto:ana@example.com", "ATTENDEE:mailto:ben@example.com"} {
+		if !strings.Contains(alarm, want) {
+			t.Errorf("VALARM lacks %s:\n%s", want, alarm)
+		}
+	}
+
+	for _, spec := range []string{
+		"trigger=-5m,action=DISPLAY,to=ana@example.com",
+		"trigger=-5m,to=not an address",
+	} {
+		if _, err := ParseAlarmSpecs([]string{spec}, ""); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+}
+
+func TestSetDefaultAlarmDirection(t *testing.T) {
+	SetDefaultAlarmDirection(true)
+	t.Cleanup(func() { SetDefaultAlarmDirection(false) })
+
+	alarms, err := ParseAlarmSpecs([]string{"15m", "trigger=10m", "trigger=10m,direction=before", "trigger=-5m"}, "")
+	if err != nil {
+		t.Fatalf("ParseAlarmSpecs returned error: %v", err)
+	}
+	want := []time.Duration{15 * time.Minute, 10 * time.Minute, -10 * time.Minute, -5 * time.Minute}
+	for i, al := range alarms {
+		if al.TriggerDuration != want[i] {
+			t.Errorf("alarm %d: trigger = %v, want %v", i, al.TriggerDuration, want[i])
+		}
+	}
+}
+
+func TestStableUIDIsDeterministic(t *testing.T) {
+	a := StableUID("standup", "2025-03-01 09:00", "Europe/Madrid")
+	if a != StableUID("standup", "2025-03-01 09:00", "Europe/Madrid") {
+		t.Error("StableUID should return the same value for the same parts")
+	}
+	if !strings.HasSuffix(a, "@tempus") {
+		t.Errorf("StableUID() = %q, want @tempus suffix", a)
+	}
+	if a == StableUID("standup", "2025-03-01 09:00Europe/Madrid") {
+		t.Error("StableUID should separate parts")
+	}
+}
+
+// largeCalendar builds n events with long, escaped, multi-byte descriptions so
+// folding and escaping are exercised on every event.
+func largeCalendar(n int) *Calendar {
+	cal := NewCalendar()
+	cal.Name = "Load test"
+	cal.TranslateCategory = strings.ToUpper
+	loc, _ := time.LoadLocation("Europe/Madrid")
+	start := time.Date(2025, 1, 6, 9, 0, 0, 0, loc)
+	desc := strings.TrimSuffix(strings.Repeat("Ñandú; café, naïve \\ día\n", 8), "\n")
+	for i := 0; i < n; i++ {
+		s := start.Add(time.Duration(i) * time.Hour)
+		ev := NewEvent(fmt.Sprintf("Event %d", i), s, s.Add(45*time.Minute))
+		ev.UID = StableUID("load", fmt.Sprint(i))
+		ev.StartTZ, ev.EndTZ = "Europe/Madrid", "Europe/Madrid"
+		ev.Description = desc
+		ev.Location = "Sala 3, planta 2"
+		ev.Categories = []string{"work", "load"}
+		ev.Alarms = []Alarm{{Action: "DISPLAY", Description: "Reminder", TriggerIsRelative: true, TriggerDuration: -15 * time.Minute}}
+		cal.AddEvent(ev)
+	}
+	return cal
+}
+
+func TestWriteICSMatchesToICSOnLargeCalendar(t *testing.T) {
+	cal := largeCalendar(20000)
+	want := cal.ToICS()
+
+	var buf strings.Builder
+	if err := cal.WriteICS(&buf); err != nil {
+		t.Fatalf("WriteICS() failed: %v", err)
+	}
+	if buf.String() != want {
+		t.Fatal("WriteICS() output differs from ToICS()")
+	}
+	if n := strings.Count(want, "BEGIN:VEVENT"); n != 20000 {
+		t.Errorf("got %d events, want 20000", n)
+	}
+	if !strings.Contains(want, "CATEGORIES:WORK,LOAD") {
+		t.Error("TranslateCategory should apply to every event")
+	}
+
+	// Every physical line stays within 75 octets plus the fold's leading space,
+	// and unfolding gives back the escaped description untouched.
+	for i, line := range strings.Split(strings.TrimSuffix(want, "\r\n"), "\r\n") {
+		if len(line) > 76 || (len(line) == 76 && line[0] != ' ') {
+			t.Fatalf("line %d is %d octets: %q", i, len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Fatalf("line %d splits a multi-byte rune: %q", i, line)
+		}
+	}
+	unfolded := strings.ReplaceAll(want, "\r\n ", "")
+	if !strings.Contains(unfolded, "DESCRIPTION:"+escapeText(largeCalendar(1).Events[0].Description)+"\r\n") {
+		t.Error("unfolded DESCRIPTION does not round-trip")
+	}
+	if got := strings.Join(foldICalLine("DESCRIPTION:"+escapeText(cal.Events[0].Description), 75), "\r\n "); !strings.Contains(want, got) {
+		t.Error("streamed folding should match foldICalLine")
+	}
+}
+
+type failingWriter struct{ err error }
+
+func (w failingWriter) Write([]byte) (int, error) { return 0, w.err }
+
+func TestWriteICSReportsWriteErrors(t *testing.T) {
+	boom := fmt.Errorf("disk full")
+	if err := largeCalendar(200).WriteICS(failingWriter{boom}); err != boom {
+		t.Errorf("WriteICS() = %v, want %v", err, boom)
+	}
+}
+
+func TestWriteICSDoesNotBufferTheWholeCalendar(t *testing.T) {
+	cal := largeCalendar(5000)
+	size := len(cal.ToICS())
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	if err := cal.WriteICS(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	runtime.ReadMemStats(&after)
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > uint64(size) {
+		t.Errorf("WriteICS allocated %d bytes for a %d-byte calendar", alloc, size)
+	}
+}
+
+func BenchmarkCalendarToICS(b *testing.B) {
+	cal := largeCalendar(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = cal.ToICS()
+	}
+}
+
+func BenchmarkCalendarWriteICS(b *testing.B) {
+	cal := largeCalendar(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = cal.WriteICS(io.Discard)
+	}
+}
+
+func BenchmarkEscapeText(b *testing.B) {
+	plain := strings.Repeat("plain words ", 20)
+	escaped := strings.Repeat("a; b, c\\ d\n", 20)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = escapeText(plain)
+		_ = escapeText(escaped)
+	}
+}
+
+func TestSetClockPinsTimestamps(t *testing.T) {
+	pinned := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+	SetClock(clock.Fixed(pinned))
+	t.Cleanup(func() { SetClock(nil) })
+
+	ev := NewEvent("Standup", pinned, pinned.Add(15*time.Minute))
+	if !ev.Created.Equal(pinned) || !ev.LastMod.Equal(pinned) {
+		t.Errorf("NewEvent() stamps = %v / %v, want %v", ev.Created, ev.LastMod, pinned)
+	}
+	ev.Created = time.Time{}
+	if ics := ev.ToICS(); !strings.Contains(ics, "DTSTAMP:20250501T090000Z") {
+		t.Errorf("DTSTAMP should come from the clock:\n%s", ics)
+	}
+
+	SetClock(nil)
+	if ev := NewEvent("Later", pinned, pinned); ev.Created.Equal(pinned) {
+		t.Error("SetClock(nil) should restore the system clock")
+	}
+}
+
+func TestToICSWritesCalendarMetadata(t *testing.T) {
+	cal := NewCalendar()
+	cal.Name = "Team; Ops"
+	cal.Description = "On-call rota, holidays"
+	cal.UID = "ops-rota"
+	cal.URL = "https://example.com/ops"
+	cal.Source = "https://cal.example.com/ops.ics"
+	cal.RefreshInterval = 7 * 24 * time.Hour
+
+	ics := cal.ToICS()
+	want := "METHOD:PUBLISH\r\n" +
+		"UID:ops-rota\r\n" +
+		"NAME:Team\\; Ops\r\nX-WR-CALNAME:Team\\; Ops\r\n" +
+		"DESCRIPTION:On-call rota\\, holidays\r\nX-WR-CALDESC:On-call rota\\, holidays\r\n" +
+		"URL:https://example.com/ops\r\n" +
+		"SOURCE;VALUE=URI:https://cal.example.com/ops.ics\r\n" +
+		"REFRESH-INTERVAL;VALUE=DURATION:P7D\r\nX-PUBLISHED-TTL:P7D\r\n"
+	if !strings.Contains(ics, want) {
+		t.Errorf("expected calendar properties\n%s\nin:\n%s", want, ics)
+	}
+	if issues := CheckGrammar(ics); len(issues) > 0 {
+		t.Errorf("calendar metadata fails the grammar check: %v", issues)
+	}
+
+	js, err := cal.ToJCal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compact := strings.Join(strings.Fields(string(js)), ""); !strings.Contains(compact, `["refresh-interval",{},"duration","P7D"]`) ||
+		!strings.Contains(compact, `["source",{},"uri","https://cal.example.com/ops.ics"]`) {
+		t.Errorf("unexpected jCal metadata:\n%s", js)
+	}
+
+	if ics := NewCalendar().ToICS(); strings.Contains(ics, "REFRESH-INTERVAL") || strings.Contains(ics, "NAME:") || strings.Contains(ics, "SOURCE") {
+		t.Errorf("empty metadata should be omitted:\n%s", ics)
+	}
+}
+
+func FuzzParseHumanDuration(f *testing.F) {
+	for _, seed := range []string{"15m", "1h30m", "90", "1:30", "2d", "1w", " 45 ", "0", "0:00", "99999999999d", "1h 2m"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		d, err := ParseHumanDuration(s)
+		if err == nil && (d < 0 || d > maxDuration) {
+			t.Errorf("ParseHumanDuration(%q) = %v, out of range", s, d)
+		}
+	})
+}
+
+func FuzzParseAlarmSpecs(f *testing.F) {
+	for _, seed := range []string{
+		"15m", "-1h,+2d", "0", "2030-03-04 09:00", "1765875600",
+		"trigger=-PT15M,repeat=3,repeat_duration=5m,description=Stretch",
+		"trigger=1h;action=EMAIL;to=ana@example.com;ben@example.com",
+		"trigger=30m,sound=Glass,kind=after || 1w",
+		"trigger=P99999999999W", "2000000h", "trigger=15m,repeat=99999999999999999999,repeat_duration=1m",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, raw string) {
+		alarms, err := ParseAlarmsFromString(raw, "Europe/Madrid")
+		if err != nil {
+			return
+		}
+		ev := NewEvent("Fuzz", time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC), time.Date(2030, 3, 4, 10, 0, 0, 0, time.UTC))
+		for _, al := range alarms {
+			if d := al.TriggerDuration; d > maxDuration || d < -maxDuration {
+				t.Errorf("%q gave a trigger of %v", raw, d)
+			}
+			if al.Repeat < 0 || al.RepeatDuration < 0 {
+				t.Errorf("%q gave a negative repeat: %+v", raw, al)
+			}
+			ev.Alarms = append(ev.Alarms, al)
+		}
+		_ = ev.ToICS()
+	})
+}
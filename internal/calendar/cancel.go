@@ -0,0 +1,162 @@
+package calendar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tempus/internal/constants"
+)
+
+// CancelledEvent records one VEVENT written by CancelICS.
+type CancelledEvent struct {
+	UID          string
+	Summary      string
+	Start        string // DTSTART as written, with its TZID
+	RecurrenceID string // set for a moved or changed occurrence of a series
+	Organizer    bool   // whether the event names an ORGANIZER
+}
+
+// CancelICS turns the VEVENTs of an .ics document accepted by match into a
+// METHOD:CANCEL calendar that removes them from the calendars they were sent
+// to. A cancelled event keeps its UID, times and RRULE, gets STATUS:CANCELLED,
+// a bumped SEQUENCE and a fresh DTSTAMP, and loses its alarms. An event that
+// matches takes every other VEVENT with its UID along, so the overrides of a
+// series go with it. VTIMEZONEs and calendar properties are kept; other
+// events, to-dos and journals are left out.
+func CancelICS(data string, match func(ICSEventInfo) bool) (string, []CancelledEvent, error) {
+	segments, err := splitICSEvents(data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	uids := map[string]bool{}
+	for _, seg := range segments {
+		if seg.event {
+			if info := icsEventInfo(seg.lines); match == nil || match(info) {
+				uids[info.UID] = true
+			}
+		}
+	}
+
+	now := wallClock.Now().UTC().Format(constants.ICSFormatUTC)
+	var (
+		out       []icsSegment
+		cancelled []CancelledEvent
+		method    bool
+		depth     int // nesting below VCALENDAR of the line being read
+		skipFrom  = -1
+	)
+	for _, seg := range segments {
+		if seg.event {
+			if skipFrom < 0 && uids[icsEventInfo(seg.lines).UID] {
+				lines, ev := cancelICSEvent(seg.lines, now)
+				out = append(out, icsSegment{lines: lines, event: true})
+				cancelled = append(cancelled, ev)
+			}
+			continue
+		}
+		p := parseICSLine(seg.lines[0])
+		component := strings.ToUpper(strings.TrimSpace(p.value))
+		switch {
+		case p.name == "BEGIN":
+			depth++
+			if depth == 2 && skipFrom < 0 && component != "VTIMEZONE" {
+				skipFrom = depth // VTODO, VJOURNAL, VFREEBUSY ...
+			}
+		case p.name == "END":
+			depth--
+			if depth < skipFrom {
+				skipFrom = -1
+				continue
+			}
+		case depth == 1 && p.name == "METHOD":
+			seg.lines, method = []string{"METHOD:CANCEL"}, true
+		}
+		if skipFrom < 0 {
+			out = append(out, seg)
+		}
+	}
+	if len(cancelled) == 0 {
+		return "", nil, nil
+	}
+	if !method {
+		out = insertCalendarProp(out, "METHOD:CANCEL")
+	}
+	return writeICSSegments(out), cancelled, nil
+}
+
+// insertCalendarProp adds a VCALENDAR property ahead of its first component.
+func insertCalendarProp(segments []icsSegment, line string) []icsSegment {
+	at := len(segments)
+	for i, seg := range segments {
+		p := parseICSLine(seg.lines[0])
+		if seg.event || p.name == "BEGIN" && !strings.EqualFold(p.value, "VCALENDAR") {
+			at = i
+			break
+		}
+	}
+	return append(segments[:at], append([]icsSegment{{lines: []string{line}}}, segments[at:]...)...)
+}
+
+func cancelICSEvent(event []string, now string) ([]string, CancelledEvent) {
+	var ev CancelledEvent
+	out := []string{event[0]}
+	depth := 0
+	sawStatus, sawSequence, sawStamp := false, false, false
+	for _, line := range event[1 : len(event)-1] {
+		p := parseICSLine(line)
+		switch {
+		case p.name == "BEGIN":
+			depth++
+		case p.name == "END":
+			depth--
+			continue
+		}
+		if depth > 0 {
+			continue // alarms would fire for an event that is gone
+		}
+		switch p.name {
+		case "UID":
+			ev.UID = p.value
+		case "SUMMARY":
+			ev.Summary = unescapeText(p.value)
+		case "DTSTART":
+			ev.Start = p.describe()
+		case "RECURRENCE-ID":
+			ev.RecurrenceID = p.describe()
+		case "ORGANIZER":
+			ev.Organizer = true
+		case "STATUS":
+			line, sawStatus = "STATUS:CANCELLED", true
+		case "SEQUENCE":
+			n, _ := strconv.Atoi(strings.TrimSpace(p.value))
+			line, sawSequence = "SEQUENCE:"+strconv.Itoa(n+1), true
+		case "DTSTAMP":
+			line, sawStamp = "DTSTAMP:"+now, true
+		}
+		out = append(out, line)
+	}
+	if !sawStamp {
+		out = append(out, "DTSTAMP:"+now)
+	}
+	if !sawStatus {
+		out = append(out, "STATUS:CANCELLED")
+	}
+	if !sawSequence {
+		out = append(out, "SEQUENCE:1")
+	}
+	return append(out, event[len(event)-1]), ev
+}
+
+// String names the event for reports: its summary (or UID) and start.
+func (c CancelledEvent) String() string {
+	label := c.Summary
+	if label == "" {
+		label = c.UID
+	}
+	if c.RecurrenceID != "" {
+		return fmt.Sprintf("%s (occurrence %s)", label, c.RecurrenceID)
+	}
+	return fmt.Sprintf("%s (%s)", label, c.Start)
+}
@@ -0,0 +1,120 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"tempus/internal/clock"
+)
+
+func TestCancelICS(t *testing.T) {
+	SetClock(clock.Fixed(time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)))
+	t.Cleanup(func() { SetClock(nil) })
+
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//test//EN",
+		"METHOD:PUBLISH",
+		"BEGIN:VTIMEZONE",
+		"TZID:Europe/Madrid",
+		"BEGIN:STANDARD",
+		"DTSTART:19701025T030000",
+		"TZOFFSETFROM:+0200",
+		"TZOFFSETTO:+0100",
+		"END:STANDARD",
+		"END:VTIMEZONE",
+		"BEGIN:VEVENT",
+		"UID:standup@example.com",
+		"DTSTAMP:20260101T000000Z",
+		"SUMMARY:Standup",
+		"ORGANIZER:mailto:ana@example.com",
+		"DTSTART;TZID=Europe/Madrid:20261102T090000",
+		"DTEND;TZID=Europe/Madrid:20261102T091500",
+		"RRULE:FREQ=DAILY;COUNT=5",
+		"SEQUENCE:2",
+		"STATUS:CONFIRMED",
+		"BEGIN:VALARM",
+		"TRIGGER:-PT5M",
+		"ACTION:DISPLAY",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:standup@example.com",
+		"DTSTAMP:20260101T000000Z",
+		"SUMMARY:Standup (late)",
+		"RECURRENCE-ID;TZID=Europe/Madrid:20261103T090000",
+		"DTSTART;TZID=Europe/Madrid:20261103T100000",
+		"DTEND;TZID=Europe/Madrid:20261103T101500",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:lunch@example.com",
+		"DTSTAMP:20260101T000000Z",
+		"SUMMARY:Lunch",
+		"DTSTART:20261102T120000Z",
+		"END:VEVENT",
+		"BEGIN:VTODO",
+		"UID:todo@example.com",
+		"SUMMARY:Book room",
+		"END:VTODO",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	out, cancelled, err := CancelICS(ics, func(info ICSEventInfo) bool { return info.Summary == "Standup" })
+	if err != nil {
+		t.Fatalf("CancelICS: %v", err)
+	}
+	if len(cancelled) != 2 {
+		t.Fatalf("cancelled %d events, want the series and its override: %+v", len(cancelled), cancelled)
+	}
+	if !cancelled[0].Organizer || cancelled[1].Organizer || cancelled[1].RecurrenceID != "20261103T090000 Europe/Madrid" {
+		t.Errorf("unexpected report: %+v", cancelled)
+	}
+	if got := cancelled[0].String(); got != "Standup (20261102T090000 Europe/Madrid)" {
+		t.Errorf("String() = %q", got)
+	}
+
+	for _, want := range []string{
+		"METHOD:CANCEL\r\n",
+		"TZID:Europe/Madrid\r\n",
+		"RRULE:FREQ=DAILY;COUNT=5\r\n",
+		"SEQUENCE:3\r\n",
+		"STATUS:CANCELLED\r\n",
+		"DTSTAMP:20261001T080000Z\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"METHOD:PUBLISH", "STATUS:CONFIRMED", "SEQUENCE:2", "VALARM", "Lunch", "VTODO", "20260101T000000Z"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("output still has %q:\n%s", unwanted, out)
+		}
+	}
+	if n := strings.Count(out, "STATUS:CANCELLED"); n != 2 {
+		t.Errorf("STATUS:CANCELLED %d times, want 2", n)
+	}
+	if n := strings.Count(out, "SEQUENCE:1\r\n"); n != 1 {
+		t.Errorf("override should get SEQUENCE:1, got %d", n)
+	}
+	if events, err := ParseICSEvents(out); err != nil || len(events) != 2 {
+		t.Errorf("cancellation does not parse back: %d events, %v", len(events), err)
+	}
+}
+
+func TestCancelICSAddsMethod(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nUID:a\r\nDTSTART:20261102T120000Z\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	out, cancelled, err := CancelICS(ics, nil)
+	if err != nil || len(cancelled) != 1 {
+		t.Fatalf("CancelICS: %d events, %v", len(cancelled), err)
+	}
+	if !strings.Contains(out, "VERSION:2.0\r\nMETHOD:CANCEL\r\nBEGIN:VEVENT") {
+		t.Errorf("METHOD:CANCEL not placed before the first component:\n%s", out)
+	}
+
+	out, cancelled, err = CancelICS(ics, func(ICSEventInfo) bool { return false })
+	if err != nil || out != "" || cancelled != nil {
+		t.Errorf("no match should give nothing, got %q %v %v", out, cancelled, err)
+	}
+}
@@ -0,0 +1,136 @@
+package calendar
+
+import (
+	"sort"
+	"strings"
+
+	"tempus/internal/utils"
+)
+
+// CategorySeparator separates the levels of a hierarchical category such as
+// Work/ClientA or Health/Medication.
+const CategorySeparator = "/"
+
+// CategoryLevels splits category into its trimmed, non-empty levels:
+// " Work / ClientA " gives [Work ClientA].
+func CategoryLevels(category string) []string {
+	var levels []string
+	for _, level := range strings.Split(category, CategorySeparator) {
+		if level = strings.TrimSpace(level); level != "" {
+			levels = append(levels, level)
+		}
+	}
+	return levels
+}
+
+// CleanCategory joins the levels of category again without stray spaces
+// or separators, so "Work / ClientA/" becomes "Work/ClientA".
+func CleanCategory(category string) string {
+	return strings.Join(CategoryLevels(category), CategorySeparator)
+}
+
+// CategoryUnder reports whether category is prefix or one of its
+// descendants. Whole levels are compared case-insensitively, so Work matches
+// Work and Work/ClientA but not Workout.
+func CategoryUnder(category, prefix string) bool {
+	have, want := CategoryLevels(category), CategoryLevels(prefix)
+	if len(want) == 0 || len(want) > len(have) {
+		return false
+	}
+	for i := range want {
+		if !strings.EqualFold(have[i], want[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// AnyCategoryUnder reports whether one of categories is under one of
+// prefixes. No prefixes matches everything, even events without categories.
+func AnyCategoryUnder(categories, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, c := range categories {
+		for _, p := range prefixes {
+			if CategoryUnder(c, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Taxonomy is the set of categories a user expects, such as config's
+// category_taxonomy. Listing Work/ClientA makes Work known as well.
+type Taxonomy struct {
+	known map[string]string // lower-case path → spelling from the taxonomy
+}
+
+// NewTaxonomy builds a taxonomy from category paths; blank ones are ignored.
+func NewTaxonomy(paths []string) *Taxonomy {
+	t := &Taxonomy{known: make(map[string]string)}
+	for _, p := range paths {
+		levels := CategoryLevels(p)
+		for n := 1; n <= len(levels); n++ {
+			path := strings.Join(levels[:n], CategorySeparator)
+			if _, ok := t.known[strings.ToLower(path)]; !ok {
+				t.known[strings.ToLower(path)] = path
+			}
+		}
+	}
+	return t
+}
+
+// Empty reports whether the taxonomy lists no categories; with none, every
+// category is accepted.
+func (t *Taxonomy) Empty() bool {
+	return t == nil || len(t.known) == 0
+}
+
+// Lookup returns the taxonomy's spelling of category, matched level by
+// level and case-insensitively, and whether it is known.
+func (t *Taxonomy) Lookup(category string) (string, bool) {
+	if t.Empty() {
+		return "", false
+	}
+	spelling, ok := t.known[strings.ToLower(CleanCategory(category))]
+	return spelling, ok
+}
+
+// Suggest returns the known category closest to an unknown one: a sibling
+// (same parent) at most two edits away from its last level, or "".
+func (t *Taxonomy) Suggest(category string) string {
+	levels := CategoryLevels(category)
+	if t.Empty() || len(levels) == 0 {
+		return ""
+	}
+	parent := strings.ToLower(strings.Join(levels[:len(levels)-1], CategorySeparator))
+	leaf := strings.ToLower(levels[len(levels)-1])
+
+	var candidates []string
+	for lower := range t.known {
+		dir, name := "", lower
+		if i := strings.LastIndex(lower, CategorySeparator); i >= 0 {
+			dir, name = lower[:i], lower[i+1:]
+		}
+		if dir == parent && utils.Levenshtein(leaf, name) <= 2 {
+			candidates = append(candidates, lower)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		di, dj := utils.Levenshtein(leaf, lastLevel(candidates[i])), utils.Levenshtein(leaf, lastLevel(candidates[j]))
+		if di != dj {
+			return di < dj
+		}
+		return candidates[i] < candidates[j]
+	})
+	return t.known[candidates[0]]
+}
+
+func lastLevel(path string) string {
+	return path[strings.LastIndex(path, CategorySeparator)+1:]
+}
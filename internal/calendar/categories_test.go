@@ -0,0 +1,81 @@
+package calendar
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCategoryLevelsAndClean(t *testing.T) {
+	if got := CategoryLevels(" Work / ClientA/ "); !reflect.DeepEqual(got, []string{"Work", "ClientA"}) {
+		t.Errorf("CategoryLevels = %q", got)
+	}
+	if got := CleanCategory("Health //Medication"); got != "Health/Medication" {
+		t.Errorf("CleanCategory = %q", got)
+	}
+	if got := CleanCategory(" / "); got != "" {
+		t.Errorf("CleanCategory of separators only = %q", got)
+	}
+}
+
+func TestCategoryUnder(t *testing.T) {
+	tests := []struct {
+		category, prefix string
+		want             bool
+	}{
+		{"Work", "Work", true},
+		{"Work/ClientA", "work", true},
+		{"Work/ClientA/Invoices", "Work/ClientA", true},
+		{"Workout", "Work", false},
+		{"Work", "Work/ClientA", false},
+		{"Health/Medication", "Work", false},
+		{"Work", "", false},
+	}
+	for _, tt := range tests {
+		if got := CategoryUnder(tt.category, tt.prefix); got != tt.want {
+			t.Errorf("CategoryUnder(%q, %q) = %v, want %v", tt.category, tt.prefix, got, tt.want)
+		}
+	}
+	if !AnyCategoryUnder(nil, nil) {
+		t.Error("no prefixes should match everything")
+	}
+	if !AnyCategoryUnder([]string{"Family", "Health/Medication"}, []string{"Work", "Health"}) {
+		t.Error("any category under any prefix should match")
+	}
+	if AnyCategoryUnder(nil, []string{"Work"}) {
+		t.Error("events without categories should not match a prefix")
+	}
+}
+
+func TestTaxonomy(t *testing.T) {
+	tax := NewTaxonomy([]string{"Work/ClientA", "Work/ClientB", "Health/Medication", "  "})
+	for in, want := range map[string]string{
+		"work":              "Work",
+		"work / clienta":    "Work/ClientA",
+		"HEALTH/medication": "Health/Medication",
+	} {
+		if got, ok := tax.Lookup(in); !ok || got != want {
+			t.Errorf("Lookup(%q) = %q, %v; want %q", in, got, ok, want)
+		}
+	}
+	if _, ok := tax.Lookup("Work/ClientC/Extra"); ok {
+		t.Error("an unlisted leaf should not be known")
+	}
+
+	if got := tax.Suggest("Work/Clientb"); got != "Work/ClientB" {
+		t.Errorf("Suggest(Work/Clientb) = %q", got)
+	}
+	if got := tax.Suggest("Health/ClientA"); got != "" {
+		t.Errorf("suggestions should stay under the same parent, got %q", got)
+	}
+	if got := tax.Suggest("Helth"); got != "Health" {
+		t.Errorf("Suggest(Helth) = %q", got)
+	}
+
+	var none *Taxonomy
+	if !none.Empty() || !NewTaxonomy(nil).Empty() {
+		t.Error("nil and empty taxonomies should be empty")
+	}
+	if _, ok := none.Lookup("Work"); ok {
+		t.Error("an empty taxonomy knows no categories")
+	}
+}
@@ -0,0 +1,78 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{in: "#2ECC71", want: "#2ecc71"},
+		{in: " #2ecc71 ", want: "#2ecc71"},
+		{in: "#0f8", want: "#00ff88"},
+		{in: "SeaGreen", want: "#2e8b57"},
+		{in: "2ecc71", wantErr: true},
+		{in: "#2ecc7", wantErr: true},
+		{in: "#gggggg", wantErr: true},
+		{in: "greenish", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseColor(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseColor(%q) = %q, %v; want %q (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestColorName(t *testing.T) {
+	if len(cssColors) != 147 {
+		t.Errorf("expected the 147 CSS3 color names, got %d", len(cssColors))
+	}
+	tests := map[string]string{
+		"#2e8b57": "seagreen",
+		"#00ffff": "aqua",
+		"#808080": "gray",
+		"#2ecc71": "mediumseagreen",
+		"#fe0101": "red",
+		"nope":    "",
+		"":        "",
+	}
+	for in, want := range tests {
+		if got := ColorName(in); got != want {
+			t.Errorf("ColorName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestToICSWritesColors(t *testing.T) {
+	start := time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+	cal := NewCalendar()
+	cal.Color = "#2ecc71"
+	ev := NewEvent("Physio", start, start.Add(time.Hour))
+	ev.Categories = []string{"Health"}
+	ev.Color = "#2ecc71"
+	cal.AddEvent(ev)
+	plain := NewEvent("Lunch", start, start.Add(time.Hour))
+	cal.AddEvent(plain)
+
+	out := cal.ToICS()
+	for _, want := range []string{
+		"COLOR:mediumseagreen\r\nX-APPLE-CALENDAR-COLOR:#2ECC71\r\n",
+		"CATEGORIES:Health\r\nCOLOR:mediumseagreen\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "\r\nCOLOR:"); n != 2 {
+		t.Errorf("expected COLOR on the calendar and one event, got %d", n)
+	}
+	if issues := CheckGrammar(out); len(issues) > 0 {
+		t.Errorf("colored calendar fails the grammar check: %v", issues)
+	}
+}
@@ -0,0 +1,54 @@
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Milestone is a day of a countdown: DaysLeft days before the target.
+type Milestone struct {
+	DaysLeft int
+	Day      time.Time // midnight UTC
+}
+
+// ParseMilestones parses how long before a target to remind of it, as
+// "90d,60d,30d,2w,1d". The result is in days, furthest first, without
+// duplicates.
+func ParseMilestones(spec string) ([]int, error) {
+	seen := map[int]bool{}
+	var days []int
+	for _, part := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == ' ' }) {
+		d, err := ParseHumanDuration(part)
+		if err != nil || d <= 0 || d%(24*time.Hour) != 0 {
+			return nil, fmt.Errorf("invalid milestone %q (use days or weeks, e.g. 30d or 2w)", part)
+		}
+		n := int(d / (24 * time.Hour))
+		if !seen[n] {
+			seen[n] = true
+			days = append(days, n)
+		}
+	}
+	if len(days) == 0 {
+		return nil, fmt.Errorf("no milestones")
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(days)))
+	return days, nil
+}
+
+// CountdownMilestones lays the milestones out before target (only its date
+// counts). Milestones before from are returned apart in skipped.
+func CountdownMilestones(target time.Time, days []int, from time.Time) (milestones, skipped []Milestone) {
+	day := dateOnly(target)
+	first := dateOnly(from)
+	for _, n := range days {
+		m := Milestone{DaysLeft: n, Day: day.AddDate(0, 0, -n)}
+		if m.Day.Before(first) {
+			skipped = append(skipped, m)
+			continue
+		}
+		milestones = append(milestones, m)
+	}
+	return milestones, skipped
+}
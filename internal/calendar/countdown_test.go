@@ -0,0 +1,45 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMilestones(t *testing.T) {
+	got, err := ParseMilestones("1d, 2w,90d,14d 30d")
+	if err != nil {
+		t.Fatalf("ParseMilestones returned error: %v", err)
+	}
+	want := []int{90, 30, 14, 1}
+	if len(got) != len(want) {
+		t.Fatalf("ParseMilestones = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("ParseMilestones = %v, want %v", got, want)
+		}
+	}
+
+	for _, spec := range []string{"", "soon", "12h", "-3d", "0d"} {
+		if _, err := ParseMilestones(spec); err == nil {
+			t.Errorf("ParseMilestones(%q): expected an error", spec)
+		}
+	}
+}
+
+func TestCountdownMilestones(t *testing.T) {
+	target := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	from := time.Date(2026, 4, 1, 15, 0, 0, 0, time.UTC)
+	milestones, skipped := CountdownMilestones(target, []int{90, 60, 7, 1}, from)
+	if len(milestones) != 3 || len(skipped) != 1 {
+		t.Fatalf("expected 3 milestones and 1 skipped, got %+v and %+v", milestones, skipped)
+	}
+	if skipped[0].DaysLeft != 90 || skipped[0].Day.Format("2006-01-02") != "2026-03-03" {
+		t.Errorf("skipped = %+v", skipped[0])
+	}
+	for i, want := range []string{"2026-04-02", "2026-05-25", "2026-05-31"} {
+		if got := milestones[i].Day.Format("2006-01-02"); got != want {
+			t.Errorf("milestone %d = %s, want %s", i, got, want)
+		}
+	}
+}
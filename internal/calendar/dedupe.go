@@ -0,0 +1,133 @@
+package calendar
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Duplicate describes one event removed by DedupeICS.
+type Duplicate struct {
+	UID     string `json:"uid,omitempty"`
+	Summary string `json:"summary"`
+	Start   string `json:"start"`
+	Reason  string `json:"reason"` // "uid" or "content"
+	KeptUID string `json:"kept_uid,omitempty"`
+}
+
+// DedupeOptions selects how DedupeICS recognises duplicates.
+type DedupeOptions struct {
+	// ByUID drops repeated UID (+ RECURRENCE-ID) entries, keeping the highest SEQUENCE.
+	ByUID bool
+	// ByContent drops events whose fields (ignoring UID and timestamps) match an earlier one.
+	ByContent bool
+}
+
+// DedupeICS removes duplicate VEVENTs from an .ics document. Everything else,
+// including the order of the events that are kept, is preserved.
+func DedupeICS(data string, opts DedupeOptions) (string, []Duplicate, error) {
+	segments, err := splitICSEvents(data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	type candidate struct {
+		segment  int
+		event    diffEvent
+		sequence int
+	}
+	var events []candidate
+	for i, seg := range segments {
+		if seg.event {
+			events = append(events, candidate{segment: i, event: newDiffEvent(seg.lines), sequence: icsSequence(seg.lines)})
+		}
+	}
+
+	removed := map[int]bool{}
+	var dups []Duplicate
+	drop := func(c candidate, reason, kept string) {
+		removed[c.segment] = true
+		ref := c.event.ref()
+		dups = append(dups, Duplicate{UID: ref.UID, Summary: ref.Summary, Start: ref.Start, Reason: reason, KeptUID: kept})
+	}
+
+	if opts.ByUID {
+		groups := map[string][]candidate{}
+		var order []string
+		for _, c := range events {
+			if c.event.uid == "" {
+				continue
+			}
+			key := c.event.uid + "\x00" + c.event.fields["RECURRENCE-ID"]
+			if _, ok := groups[key]; !ok {
+				order = append(order, key)
+			}
+			groups[key] = append(groups[key], c)
+		}
+		for _, key := range order {
+			group := groups[key]
+			if len(group) < 2 {
+				continue
+			}
+			// Highest SEQUENCE wins; on a tie the first one in the file is kept.
+			sort.SliceStable(group, func(i, j int) bool { return group[i].sequence > group[j].sequence })
+			for _, c := range group[1:] {
+				drop(c, "uid", group[0].event.uid)
+			}
+		}
+	}
+
+	if opts.ByContent {
+		first := map[string]candidate{}
+		for _, c := range events {
+			if removed[c.segment] {
+				continue
+			}
+			fp := c.event.fingerprint()
+			if kept, ok := first[fp]; ok {
+				drop(c, "content", kept.event.uid)
+				continue
+			}
+			first[fp] = c
+		}
+	}
+
+	kept := make([]icsSegment, 0, len(segments))
+	for i, seg := range segments {
+		if !removed[i] {
+			kept = append(kept, seg)
+		}
+	}
+	return writeICSSegments(kept), dups, nil
+}
+
+// fingerprint identifies an event by content (UID and timestamps excluded).
+func (e diffEvent) fingerprint() string {
+	keys := make([]string, 0, len(e.fields))
+	for k := range e.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k + "\x1f" + e.fields[k] + "\x1e")
+	}
+	return b.String()
+}
+
+func icsSequence(lines []string) int {
+	depth := 0
+	for _, line := range lines[1 : len(lines)-1] {
+		p := parseICSLine(line)
+		switch {
+		case p.name == "BEGIN":
+			depth++
+		case p.name == "END":
+			depth--
+		case depth == 0 && p.name == "SEQUENCE":
+			n, _ := strconv.Atoi(strings.TrimSpace(p.value))
+			return n
+		}
+	}
+	return 0
+}
@@ -0,0 +1,59 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDedupeICSByUIDKeepsHighestSequence(t *testing.T) {
+	data := diffTestCalendar(
+		diffTestEvent("a", "Standup", "20250301T090000", "SEQUENCE:1", "LOCATION:Room 1"),
+		diffTestEvent("b", "Review", "20250302T090000"),
+		diffTestEvent("a", "Standup", "20250301T093000", "SEQUENCE:3", "LOCATION:Room 2"),
+		diffTestEvent("a", "Standup", "20250308T090000", "RECURRENCE-ID;TZID=Europe/Madrid:20250308T090000"),
+	)
+
+	out, dups, err := DedupeICS(data, DedupeOptions{ByUID: true})
+	if err != nil {
+		t.Fatalf("DedupeICS() failed: %v", err)
+	}
+	if len(dups) != 1 || dups[0].Reason != "uid" || dups[0].Start != "20250301T090000 Europe/Madrid" {
+		t.Fatalf("duplicates = %+v", dups)
+	}
+	if strings.Contains(out, "Room 1") || !strings.Contains(out, "Room 2") {
+		t.Errorf("expected the SEQUENCE:3 copy to be kept:\n%s", out)
+	}
+	if got := strings.Count(out, "BEGIN:VEVENT"); got != 3 {
+		t.Errorf("kept %d events, want 3 (RECURRENCE-ID instance is not a duplicate)", got)
+	}
+	if !strings.HasPrefix(out, "BEGIN:VCALENDAR\r\n") || !strings.HasSuffix(out, "END:VCALENDAR\r\n") {
+		t.Errorf("calendar wrapper not preserved:\n%s", out)
+	}
+}
+
+func TestDedupeICSByContentIgnoresUID(t *testing.T) {
+	data := diffTestCalendar(
+		diffTestEvent("first", "Gym", "20250301T070000", "LOCATION:Club"),
+		diffTestEvent("second", "Gym", "20250301T070000", "LOCATION:Club", "DTSTAMP:20250202T000000Z"),
+		diffTestEvent("third", "Gym", "20250302T070000", "LOCATION:Club"),
+	)
+
+	out, dups, err := DedupeICS(data, DedupeOptions{ByUID: true, ByContent: true})
+	if err != nil {
+		t.Fatalf("DedupeICS() failed: %v", err)
+	}
+	if len(dups) != 1 || dups[0].UID != "second" || dups[0].KeptUID != "first" || dups[0].Reason != "content" {
+		t.Fatalf("duplicates = %+v", dups)
+	}
+	if strings.Contains(out, "UID:second") {
+		t.Errorf("content duplicate still present:\n%s", out)
+	}
+
+	_, dups, err = DedupeICS(data, DedupeOptions{ByUID: true})
+	if err != nil {
+		t.Fatalf("DedupeICS() failed: %v", err)
+	}
+	if len(dups) != 0 {
+		t.Errorf("UID-only mode should not compare content, got %+v", dups)
+	}
+}
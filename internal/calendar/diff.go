@@ -0,0 +1,265 @@
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FieldChange is one property that differs between two versions of an event.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// DiffEvent identifies an event in a CalendarDiff.
+type DiffEvent struct {
+	UID     string        `json:"uid,omitempty"`
+	Summary string        `json:"summary"`
+	Start   string        `json:"start"`
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+// CalendarDiff is the result of comparing two .ics documents.
+type CalendarDiff struct {
+	Added     []DiffEvent `json:"added"`
+	Removed   []DiffEvent `json:"removed"`
+	Modified  []DiffEvent `json:"modified"`
+	Unchanged int         `json:"unchanged"`
+}
+
+// Empty reports whether both calendars contain the same events.
+func (d CalendarDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// diffIgnoredProps change on every regeneration and say nothing about the event.
+var diffIgnoredProps = map[string]bool{
+	"UID": true, "DTSTAMP": true, "CREATED": true, "LAST-MODIFIED": true, "SEQUENCE": true,
+}
+
+// diffEvent is a VEVENT reduced to comparable fields.
+type diffEvent struct {
+	uid     string
+	summary string
+	start   string
+	fields  map[string]string
+}
+
+func (e diffEvent) ref() DiffEvent {
+	return DiffEvent{UID: e.uid, Summary: e.summary, Start: e.start}
+}
+
+// contentKey is the fallback identity when UIDs differ (e.g. regenerated batches).
+func (e diffEvent) contentKey() string {
+	return summaryKey(e.summary) + "\x00" + e.start
+}
+
+// DiffICS compares two .ics documents. Events are matched by UID first, then by
+// summary + start, and finally by a summary that is unique on both sides (a moved
+// event). DTSTAMP/CREATED/LAST-MODIFIED/SEQUENCE are ignored.
+func DiffICS(oldData, newData string) (CalendarDiff, error) {
+	oldEvents, err := parseDiffEvents(oldData)
+	if err != nil {
+		return CalendarDiff{}, fmt.Errorf("old calendar: %w", err)
+	}
+	newEvents, err := parseDiffEvents(newData)
+	if err != nil {
+		return CalendarDiff{}, fmt.Errorf("new calendar: %w", err)
+	}
+
+	diff := CalendarDiff{Added: []DiffEvent{}, Removed: []DiffEvent{}, Modified: []DiffEvent{}}
+	matchedNew := make([]bool, len(newEvents))
+	var unmatchedOld []diffEvent
+
+	byUID := map[string]int{}
+	for i, e := range newEvents {
+		if e.uid != "" {
+			byUID[e.uid] = i
+		}
+	}
+	for _, o := range oldEvents {
+		if i, ok := byUID[o.uid]; ok && o.uid != "" && !matchedNew[i] {
+			matchedNew[i] = true
+			diff.compare(o, newEvents[i])
+			continue
+		}
+		unmatchedOld = append(unmatchedOld, o)
+	}
+
+	byContent := map[string][]int{}
+	for i, e := range newEvents {
+		if !matchedNew[i] {
+			byContent[e.contentKey()] = append(byContent[e.contentKey()], i)
+		}
+	}
+	var leftoverOld []diffEvent
+	for _, o := range unmatchedOld {
+		candidates := byContent[o.contentKey()]
+		if len(candidates) == 0 {
+			leftoverOld = append(leftoverOld, o)
+			continue
+		}
+		i := candidates[0]
+		byContent[o.contentKey()] = candidates[1:]
+		matchedNew[i] = true
+		diff.compare(o, newEvents[i])
+	}
+
+	// A summary that is unique on both sides is the same event, moved.
+	oldBySummary, newBySummary := map[string][]int{}, map[string][]int{}
+	for i, o := range leftoverOld {
+		oldBySummary[summaryKey(o.summary)] = append(oldBySummary[summaryKey(o.summary)], i)
+	}
+	for i, e := range newEvents {
+		if !matchedNew[i] {
+			newBySummary[summaryKey(e.summary)] = append(newBySummary[summaryKey(e.summary)], i)
+		}
+	}
+	for i, o := range leftoverOld {
+		key := summaryKey(o.summary)
+		if key != "" && len(oldBySummary[key]) == 1 && len(newBySummary[key]) == 1 {
+			j := newBySummary[key][0]
+			matchedNew[j] = true
+			diff.compare(o, newEvents[j])
+			continue
+		}
+		diff.Removed = append(diff.Removed, leftoverOld[i].ref())
+	}
+
+	for i, e := range newEvents {
+		if !matchedNew[i] {
+			diff.Added = append(diff.Added, e.ref())
+		}
+	}
+	return diff, nil
+}
+
+func summaryKey(summary string) string {
+	return strings.ToLower(strings.TrimSpace(summary))
+}
+
+func (d *CalendarDiff) compare(o, n diffEvent) {
+	names := map[string]bool{}
+	for k := range o.fields {
+		names[k] = true
+	}
+	for k := range n.fields {
+		names[k] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for k := range names {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var changes []FieldChange
+	for _, k := range sorted {
+		if o.fields[k] != n.fields[k] {
+			changes = append(changes, FieldChange{Field: k, Old: o.fields[k], New: n.fields[k]})
+		}
+	}
+	if len(changes) == 0 {
+		d.Unchanged++
+		return
+	}
+	ref := n.ref()
+	ref.Changes = changes
+	d.Modified = append(d.Modified, ref)
+}
+
+func parseDiffEvents(data string) ([]diffEvent, error) {
+	segments, err := splitICSEvents(data)
+	if err != nil {
+		return nil, err
+	}
+	var events []diffEvent
+	for _, seg := range segments {
+		if seg.event {
+			events = append(events, newDiffEvent(seg.lines))
+		}
+	}
+	return events, nil
+}
+
+// newDiffEvent reduces the lines of one VEVENT (BEGIN/END included) to comparable fields.
+func newDiffEvent(lines []string) diffEvent {
+	var (
+		ev      = diffEvent{fields: map[string]string{}}
+		multi   = map[string][]string{}
+		alarm   []string
+		inAlarm bool
+		depth   int
+	)
+	for _, line := range lines[1 : len(lines)-1] {
+		p := parseICSLine(line)
+		value := strings.ToUpper(strings.TrimSpace(p.value))
+		switch {
+		case p.name == "BEGIN":
+			depth++
+			inAlarm = value == "VALARM"
+			alarm = nil
+			continue
+		case p.name == "END":
+			depth--
+			if inAlarm {
+				sort.Strings(alarm)
+				multi["VALARM"] = append(multi["VALARM"], strings.Join(alarm, " "))
+				inAlarm = false
+			}
+			continue
+		case depth > 0:
+			if inAlarm {
+				alarm = append(alarm, p.displayValue(p.name))
+			}
+			continue
+		}
+
+		switch p.name {
+		case "UID":
+			ev.uid = strings.TrimSpace(p.value)
+		case "SUMMARY":
+			ev.summary = unescapeText(p.value)
+		case "DTSTART":
+			ev.start = p.describe()
+		}
+		if diffIgnoredProps[p.name] {
+			continue
+		}
+		switch p.name {
+		case "ATTENDEE", "EXDATE", "RDATE", "CATEGORIES", "COMMENT", "ATTACH":
+			multi[p.name] = append(multi[p.name], p.displayValue(""))
+		default:
+			ev.fields[p.name] = p.displayValue("")
+		}
+	}
+	for name, values := range multi {
+		sort.Strings(values)
+		ev.fields[name] = strings.Join(values, " | ")
+	}
+	return ev
+}
+
+// displayValue renders a property for diff output: text is unescaped and
+// parameters other than VALUE are kept, e.g. "20250301T090000 (TZID=Europe/Madrid)".
+func (p icsLine) displayValue(prefix string) string {
+	value := p.value
+	switch p.name {
+	case "SUMMARY", "DESCRIPTION", "LOCATION", "COMMENT":
+		value = unescapeText(value)
+	}
+	var params []string
+	for _, kv := range p.params {
+		if k, _, _ := strings.Cut(kv, "="); !strings.EqualFold(k, "VALUE") {
+			params = append(params, kv)
+		}
+	}
+	if len(params) > 0 {
+		value += " (" + strings.Join(params, ";") + ")"
+	}
+	if prefix != "" {
+		value = prefix + "=" + value
+	}
+	return value
+}
@@ -0,0 +1,91 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+)
+
+func diffTestCalendar(events ...string) string {
+	return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" + strings.Join(events, "") + "END:VCALENDAR\r\n"
+}
+
+func diffTestEvent(uid, summary, start string, extra ...string) string {
+	var b strings.Builder
+	b.WriteString("BEGIN:VEVENT\r\nUID:" + uid + "\r\nDTSTAMP:20250101T000000Z\r\nSUMMARY:" + summary + "\r\n")
+	b.WriteString("DTSTART;TZID=Europe/Madrid:" + start + "\r\n")
+	for _, line := range extra {
+		b.WriteString(line + "\r\n")
+	}
+	b.WriteString("END:VEVENT\r\n")
+	return b.String()
+}
+
+func TestDiffICSMatchesByUIDAndReportsFields(t *testing.T) {
+	oldCal := diffTestCalendar(
+		diffTestEvent("a", "Standup", "20250301T090000", "LOCATION:Room 1", "BEGIN:VALARM", "TRIGGER:-PT10M", "END:VALARM"),
+		diffTestEvent("b", "Review", "20250302T090000"),
+		diffTestEvent("c", "Retro", "20250303T090000"),
+	)
+	newCal := diffTestCalendar(
+		diffTestEvent("a", "Standup", "20250301T093000", "LOCATION:Room 2", "SEQUENCE:4", "BEGIN:VALARM", "TRIGGER:-PT10M", "END:VALARM"),
+		diffTestEvent("c", "Retro", "20250303T090000", "LAST-MODIFIED:20250301T000000Z"),
+		diffTestEvent("d", "Planning", "20250304T090000"),
+	)
+
+	diff, err := DiffICS(oldCal, newCal)
+	if err != nil {
+		t.Fatalf("DiffICS() failed: %v", err)
+	}
+	if len(diff.Added) != 1 || diff.Added[0].Summary != "Planning" {
+		t.Errorf("added = %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Summary != "Review" {
+		t.Errorf("removed = %+v", diff.Removed)
+	}
+	if diff.Unchanged != 1 {
+		t.Errorf("unchanged = %d, want 1 (timestamps and SEQUENCE are ignored)", diff.Unchanged)
+	}
+	if len(diff.Modified) != 1 {
+		t.Fatalf("modified = %+v", diff.Modified)
+	}
+	fields := map[string]FieldChange{}
+	for _, c := range diff.Modified[0].Changes {
+		fields[c.Field] = c
+	}
+	if len(fields) != 2 {
+		t.Errorf("expected DTSTART and LOCATION changes only, got %+v", diff.Modified[0].Changes)
+	}
+	if c := fields["LOCATION"]; c.Old != "Room 1" || c.New != "Room 2" {
+		t.Errorf("LOCATION change = %+v", c)
+	}
+	if c := fields["DTSTART"]; !strings.Contains(c.New, "20250301T093000") {
+		t.Errorf("DTSTART change = %+v", c)
+	}
+}
+
+func TestDiffICSFallsBackToContentWhenUIDsChange(t *testing.T) {
+	oldCal := diffTestCalendar(
+		diffTestEvent("old-1", "Standup", "20250301T090000"),
+		diffTestEvent("old-2", "Standup", "20250302T090000"),
+		diffTestEvent("old-3", "Dentist", "20250303T100000"),
+	)
+	newCal := diffTestCalendar(
+		diffTestEvent("new-1", "Standup", "20250301T090000"),
+		diffTestEvent("new-2", "Standup", "20250302T090000"),
+		diffTestEvent("new-3", "Dentist", "20250303T110000"),
+	)
+
+	diff, err := DiffICS(oldCal, newCal)
+	if err != nil {
+		t.Fatalf("DiffICS() failed: %v", err)
+	}
+	if len(diff.Added) != 0 || len(diff.Removed) != 0 {
+		t.Errorf("regenerated UIDs should not produce adds/removes: %+v", diff)
+	}
+	if diff.Unchanged != 2 || len(diff.Modified) != 1 || diff.Modified[0].Summary != "Dentist" {
+		t.Errorf("expected the moved dentist as the only modification: %+v", diff)
+	}
+	if diff.Empty() {
+		t.Error("Empty() should be false")
+	}
+}
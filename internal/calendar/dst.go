@@ -0,0 +1,57 @@
+package calendar
+
+import (
+	"strings"
+	"time"
+)
+
+// Ways a wall-clock time can be odd in its zone.
+const (
+	DSTGap  = "gap"  // skipped when the clocks go forward
+	DSTFold = "fold" // happens twice when the clocks go back
+)
+
+// CheckWallClock reports whether wall, a wall-clock time read in tz (as
+// Event keeps StartTime and EndTime), falls in a DST gap or fold, and the
+// instant calendar apps will use for it: the time after the jump for a gap,
+// the first of the two for a fold. It returns "" for ordinary times, floating
+// (empty tz) and unknown zones.
+func CheckWallClock(wall time.Time, tz string) (string, time.Time) {
+	tz = strings.TrimSpace(tz)
+	if tz == "" {
+		return "", wall
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return "", wall
+	}
+	t := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, loc)
+	if t.Hour() != wall.Hour() || t.Minute() != wall.Minute() {
+		// time.Date may resolve either way; RFC 5545 reads a skipped time
+		// with the offset from before the jump.
+		_, before := t.Add(-3 * time.Hour).Zone()
+		naive := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, time.UTC)
+		return DSTGap, naive.Add(-time.Duration(before) * time.Second).In(loc)
+	}
+
+	// Around a transition the offsets before and after differ; the same wall
+	// clock one difference away means the time is repeated.
+	_, before := t.Add(-3 * time.Hour).Zone()
+	_, after := t.Add(3 * time.Hour).Zone()
+	if before == after {
+		return "", t
+	}
+	diff := time.Duration(before-after) * time.Second
+	if diff < 0 {
+		diff = -diff
+	}
+	for _, other := range []time.Time{t.Add(-diff), t.Add(diff)} {
+		if o := other.In(loc); o.Hour() == t.Hour() && o.Minute() == t.Minute() && o.Day() == t.Day() {
+			if other.Before(t) {
+				t = other
+			}
+			return DSTFold, t
+		}
+	}
+	return "", t
+}
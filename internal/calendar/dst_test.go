@@ -0,0 +1,38 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckWallClock(t *testing.T) {
+	wall := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2025, month, day, hour, min, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name     string
+		wall     time.Time
+		tz       string
+		kind     string
+		resolved string // RFC 3339 of the instant used
+	}{
+		{"spring forward", wall(3, 30, 2, 30), "Europe/Madrid", DSTGap, "2025-03-30T03:30:00+02:00"},
+		{"fall back", wall(10, 26, 2, 30), "Europe/Madrid", DSTFold, "2025-10-26T02:30:00+02:00"},
+		{"ordinary", wall(5, 1, 9, 0), "Europe/Madrid", "", "2025-05-01T09:00:00+02:00"},
+		{"just after the jump", wall(3, 30, 3, 0), "Europe/Madrid", "", "2025-03-30T03:00:00+02:00"},
+		{"half-hour shift", wall(4, 6, 1, 45), "Australia/Lord_Howe", DSTFold, "2025-04-06T01:45:00+11:00"},
+		{"new york gap", wall(3, 9, 2, 15), "America/New_York", DSTGap, "2025-03-09T03:15:00-04:00"},
+	}
+	for _, tt := range tests {
+		kind, at := CheckWallClock(tt.wall, tt.tz)
+		if kind != tt.kind || at.Format(time.RFC3339) != tt.resolved {
+			t.Errorf("%s: CheckWallClock = %q, %s; want %q, %s", tt.name, kind, at.Format(time.RFC3339), tt.kind, tt.resolved)
+		}
+	}
+
+	for _, tz := range []string{"", "Mars/Olympus"} {
+		if kind, _ := CheckWallClock(wall(3, 30, 2, 30), tz); kind != "" {
+			t.Errorf("tz %q should not be checked, got %q", tz, kind)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package calendar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"tempus/internal/constants"
+)
+
+// EventRecord is one VEVENT flattened into the batch input schema (the columns
+// `tempus batch` reads), so an exported calendar can be edited in a spreadsheet
+// and converted back.
+type EventRecord struct {
+	UID         string   `json:"uid,omitempty"`
+	Summary     string   `json:"summary"`
+	Start       string   `json:"start"`
+	End         string   `json:"end,omitempty"`
+	Duration    string   `json:"duration,omitempty"`
+	StartTZ     string   `json:"start_tz,omitempty"`
+	EndTZ       string   `json:"end_tz,omitempty"`
+	Location    string   `json:"location,omitempty"`
+	Description string   `json:"description,omitempty"`
+	AllDay      bool     `json:"all_day,omitempty"`
+	RRule       string   `json:"rrule,omitempty"`
+	ExDates     []string `json:"exdate,omitempty"`
+	RDates      []string `json:"rdate,omitempty"`
+	Categories  []string `json:"categories,omitempty"`
+	Alarms      []string `json:"alarms,omitempty"`
+	Priority    int      `json:"priority,omitempty"`
+	Energy      int      `json:"energy,omitempty"`
+	Attendees   []string `json:"attendees,omitempty"`
+	Organizer   string   `json:"organizer,omitempty"`
+	Status      string   `json:"status,omitempty"` // omitted for CONFIRMED, batch's default
+	URL         string   `json:"url,omitempty"`
+	Class       string   `json:"class,omitempty"`
+}
+
+// ExportColumns are the batch CSV columns, in the order CSVRow writes them.
+var ExportColumns = []string{
+	"uid", "summary", "start", "end", "duration", "start_tz", "end_tz",
+	"location", "description", "all_day", "rrule", "exdate", "rdate", "categories", "alarms",
+	"priority", "energy", "attendees", "organizer", "status", "url", "class",
+}
+
+const (
+	batchDateLayout     = "2006-01-02"
+	batchDateTimeLayout = "2006-01-02 15:04"
+)
+
+// CSVRow renders the record as batch CSV cells matching ExportColumns. Lists
+// use the separators batch splits on ("|" and "||" for alarms).
+func (r EventRecord) CSVRow() []string {
+	allDay := ""
+	if r.AllDay {
+		allDay = "true"
+	}
+	return []string{
+		r.UID, r.Summary, r.Start, r.End, r.Duration, r.StartTZ, r.EndTZ,
+		r.Location, r.Description, allDay, r.RRule,
+		strings.Join(r.ExDates, "|"), strings.Join(r.RDates, "|"), strings.Join(r.Categories, "|"), strings.Join(r.Alarms, "||"),
+		optionalInt(r.Priority), optionalInt(r.Energy),
+		strings.Join(r.Attendees, "|"), r.Organizer, r.Status, r.URL, r.Class,
+	}
+}
+
+// optionalInt writes n as a CSV cell, leaving it empty for 0 (unset).
+func optionalInt(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// ExportEvents reads the VEVENTs of an .ics document as batch records, in file
+// order. Timed events keep their wall-clock time and TZID (UTC times get
+// start_tz UTC); all-day end dates become inclusive, as batch expects.
+func ExportEvents(data string) ([]EventRecord, error) {
+	segments, err := splitICSEvents(data)
+	if err != nil {
+		return nil, err
+	}
+	var records []EventRecord
+	for _, seg := range segments {
+		if !seg.event {
+			continue
+		}
+		rec, err := exportEvent(seg.lines)
+		if err != nil {
+			label := rec.Summary
+			if label == "" {
+				label = rec.UID
+			}
+			return nil, fmt.Errorf("event %q: %w", label, err)
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+func exportEvent(event []string) (EventRecord, error) {
+	info := icsEventInfo(event)
+	rec := EventRecord{UID: info.UID, Summary: info.Summary, Location: info.Location, Categories: info.Categories}
+
+	var dtstart, dtend *icsLine
+	var exdates, rdates []icsLine
+	var alarm *exportAlarm
+	depth := 0
+	for _, line := range event[1 : len(event)-1] {
+		p := parseICSLine(line)
+		switch {
+		case p.name == "BEGIN":
+			depth++
+			if strings.EqualFold(p.value, "VALARM") {
+				alarm = &exportAlarm{}
+			}
+		case p.name == "END":
+			depth--
+			if alarm != nil && strings.EqualFold(p.value, "VALARM") {
+				if spec := alarm.spec(); spec != "" {
+					rec.Alarms = append(rec.Alarms, spec)
+				}
+				alarm = nil
+			}
+		case alarm != nil:
+			alarm.add(p)
+		case depth > 0:
+		case p.name == "DTSTART":
+			dtstart = &p
+		case p.name == "DTEND":
+			dtend = &p
+		case p.name == "DURATION":
+			rec.Duration = strings.TrimSpace(p.value)
+		case p.name == "DESCRIPTION":
+			rec.Description = unescapeText(p.value)
+		case p.name == "RRULE":
+			rec.RRule = strings.TrimSpace(p.value)
+		case p.name == "PRIORITY":
+			rec.Priority, _ = strconv.Atoi(strings.TrimSpace(p.value))
+		case p.name == EnergyProperty:
+			rec.Energy, _ = strconv.Atoi(strings.TrimSpace(p.value))
+		case p.name == "ATTENDEE":
+			if a := mailtoAddress(p.value); a != "" {
+				rec.Attendees = append(rec.Attendees, a)
+			}
+		case p.name == "ORGANIZER":
+			rec.Organizer = mailtoAddress(p.value)
+		case p.name == "STATUS":
+			if s := strings.ToLower(strings.TrimSpace(p.value)); s != "confirmed" {
+				rec.Status = s
+			}
+		case p.name == "URL":
+			rec.URL = strings.TrimSpace(p.value)
+		case p.name == "CLASS":
+			rec.Class = strings.ToLower(strings.TrimSpace(p.value))
+		case p.name == "EXDATE":
+			exdates = append(exdates, p)
+		case p.name == "RDATE":
+			rdates = append(rdates, p)
+		}
+	}
+	if dtstart == nil {
+		return rec, fmt.Errorf("missing DTSTART")
+	}
+
+	start, startTZ, allDay, err := exportDate(*dtstart)
+	if err != nil {
+		return rec, err
+	}
+	rec.AllDay = allDay
+	rec.Start = start.Format(batchDateTimeLayout)
+	if allDay {
+		rec.Start = start.Format(batchDateLayout)
+	}
+	rec.StartTZ = startTZ
+
+	if dtend != nil {
+		end, endTZ, _, err := exportDate(*dtend)
+		if err != nil {
+			return rec, err
+		}
+		switch {
+		case allDay:
+			// ICS all-day ends are exclusive; batch's are inclusive.
+			if last := end.AddDate(0, 0, -1); last.After(start) {
+				rec.End = last.Format(batchDateLayout)
+			}
+		default:
+			rec.End = end.Format(batchDateTimeLayout)
+			if endTZ != startTZ {
+				rec.EndTZ = endTZ
+			}
+		}
+	}
+
+	if rec.ExDates, err = exportDateList(exdates, startTZ, allDay); err != nil {
+		return rec, err
+	}
+	if rec.RDates, err = exportDateList(rdates, startTZ, allDay); err != nil {
+		return rec, err
+	}
+	return rec, nil
+}
+
+// mailtoAddress returns the email of an ATTENDEE or ORGANIZER value
+// ("mailto:ana@example.com").
+func mailtoAddress(value string) string {
+	v := strings.TrimSpace(value)
+	if len(v) >= 7 && strings.EqualFold(v[:7], "mailto:") {
+		v = v[7:]
+	}
+	return strings.TrimSpace(v)
+}
+
+// exportDateList renders the values of EXDATE or RDATE properties as batch
+// dates, or date-times in the start's zone.
+func exportDateList(props []icsLine, startTZ string, allDay bool) ([]string, error) {
+	var out []string
+	for _, p := range props {
+		for _, raw := range strings.Split(p.value, ",") {
+			if strings.Contains(raw, "/") {
+				continue // an RDATE period has no batch form
+			}
+			d, err := exportDateValue(strings.TrimSpace(raw), p.param("TZID"), startTZ)
+			if err != nil {
+				return nil, err
+			}
+			if allDay {
+				out = append(out, d.Format(batchDateLayout))
+			} else {
+				out = append(out, d.Format(batchDateTimeLayout))
+			}
+		}
+	}
+	return out, nil
+}
+
+// exportDate returns the wall-clock time of a DTSTART/DTEND, its zone ("UTC"
+// for Z times, "" for floating times) and whether it is a DATE value.
+func exportDate(p icsLine) (time.Time, string, bool, error) {
+	raw := strings.TrimSpace(p.value)
+	if len(raw) == 8 {
+		d, err := time.Parse(constants.ICSFormatDateOnly, raw)
+		if err != nil {
+			return time.Time{}, "", false, fmt.Errorf("invalid date %q", raw)
+		}
+		return d, "", true, nil
+	}
+	tz := p.param("TZID")
+	if strings.HasSuffix(raw, "Z") {
+		tz = "UTC"
+	}
+	t, err := exportDateValue(raw, p.param("TZID"), tz)
+	return t, tz, false, err
+}
+
+// exportDateValue parses a DATE or DATE-TIME value and expresses it as wall
+// clock in targetTZ (UTC values are converted; TZID/floating values are kept).
+func exportDateValue(raw, tzid, targetTZ string) (time.Time, error) {
+	if len(raw) == 8 {
+		d, err := time.Parse(constants.ICSFormatDateOnly, raw)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q", raw)
+		}
+		return d, nil
+	}
+	if strings.HasSuffix(raw, "Z") {
+		t, err := time.Parse(constants.ICSFormatUTC, raw)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date-time %q", raw)
+		}
+		if loc, err := time.LoadLocation(targetTZ); err == nil && targetTZ != "" {
+			t = t.In(loc)
+		}
+		return t, nil
+	}
+	wall, err := time.Parse(constants.ICSFormatLocal, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date-time %q", raw)
+	}
+	if tzid != "" && targetTZ != "" && tzid != targetTZ {
+		if loc, err := time.LoadLocation(targetTZ); err == nil {
+			return instant(wall, tzid).In(loc), nil
+		}
+	}
+	return wall, nil
+}
+
+// exportAlarm collects the VALARM properties batch alarm specs can express.
+type exportAlarm struct {
+	trigger     string
+	absolute    bool
+	description string
+	repeat      int
+	interval    string
+}
+
+func (a *exportAlarm) add(p icsLine) {
+	switch p.name {
+	case "TRIGGER":
+		a.trigger = strings.TrimSpace(p.value)
+		a.absolute = strings.EqualFold(p.param("VALUE"), "DATE-TIME")
+	case "DESCRIPTION":
+		a.description = unescapeText(p.value)
+	case "REPEAT":
+		a.repeat, _ = strconv.Atoi(strings.TrimSpace(p.value))
+	case "DURATION":
+		a.interval = strings.TrimSpace(p.value)
+	}
+}
+
+// spec renders the alarm as a batch alarm spec ("-PT15M" or
+// "trigger=...;description=..."), or "" when it has no trigger.
+func (a *exportAlarm) spec() string {
+	trigger := a.trigger
+	if trigger == "" {
+		return ""
+	}
+	if a.absolute {
+		t, err := time.Parse(constants.ICSFormatUTC, trigger)
+		if err != nil {
+			return ""
+		}
+		trigger = t.Format(time.RFC3339)
+	} else if !strings.HasPrefix(trigger, "-") && !strings.HasPrefix(trigger, "+") {
+		trigger = "+" + trigger // batch reads unsigned offsets as "before"
+	}
+
+	var params []string
+	// Descriptions with spec separators can't round-trip; batch falls back to its default.
+	if d := strings.TrimSpace(a.description); d != "" && d != defaultDescText && !strings.ContainsAny(d, ",;|=\n") {
+		params = append(params, "description="+d)
+	}
+	if a.repeat > 0 && a.interval != "" {
+		params = append(params, "repeat="+strconv.Itoa(a.repeat), "repeat_duration="+a.interval)
+	}
+	if len(params) == 0 {
+		return trigger
+	}
+	return strings.Join(append([]string{"trigger=" + trigger}, params...), ";")
+}
@@ -0,0 +1,89 @@
+package calendar
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExportEventsFlattensToBatchSchema(t *testing.T) {
+	records, err := ExportEvents(shiftTestICS)
+	if err != nil {
+		t.Fatalf("ExportEvents() failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+
+	standup := records[0]
+	want := EventRecord{
+		UID:        "standup@test",
+		Summary:    "Standup",
+		Start:      "2025-03-28 09:00",
+		End:        "2025-03-28 09:15",
+		StartTZ:    "Europe/Madrid",
+		RRule:      "FREQ=DAILY;UNTIL=20250410T070000Z",
+		ExDates:    []string{"2025-04-01 09:00"},
+		Categories: []string{"Work"},
+		Alarms:     []string{"2025-03-28T07:30:00Z"},
+	}
+	if !reflect.DeepEqual(standup, want) {
+		t.Errorf("standup = %+v\nwant      %+v", standup, want)
+	}
+
+	// ICS all-day DTEND is exclusive: a one-day event has no batch end.
+	holiday := records[1]
+	if !holiday.AllDay || holiday.Start != "2025-03-31" || holiday.End != "" {
+		t.Errorf("unexpected all-day record: %+v", holiday)
+	}
+}
+
+func TestExportEventsUTCAndAlarmSpecs(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:1\r\nSUMMARY:Call\r\n" +
+		"DTSTART:20250101T090000Z\r\nDURATION:PT45M\r\n" +
+		"BEGIN:VALARM\r\nACTION:DISPLAY\r\nDESCRIPTION:Reminder\r\nTRIGGER:PT5M\r\nEND:VALARM\r\n" +
+		"BEGIN:VALARM\r\nACTION:DISPLAY\r\nDESCRIPTION:Dial in\r\nTRIGGER:-PT15M\r\nREPEAT:2\r\nDURATION:PT5M\r\nEND:VALARM\r\n" +
+		"END:VEVENT\r\nEND:VCALENDAR\r\n"
+	records, err := ExportEvents(ics)
+	if err != nil {
+		t.Fatalf("ExportEvents() failed: %v", err)
+	}
+	rec := records[0]
+	if rec.Start != "2025-01-01 09:00" || rec.StartTZ != "UTC" || rec.Duration != "PT45M" || rec.End != "" {
+		t.Errorf("unexpected times: %+v", rec)
+	}
+	wantAlarms := []string{"+PT5M", "trigger=-PT15M;description=Dial in;repeat=2;repeat_duration=PT5M"}
+	if !reflect.DeepEqual(rec.Alarms, wantAlarms) {
+		t.Errorf("alarms = %q, want %q", rec.Alarms, wantAlarms)
+	}
+	if row := rec.CSVRow(); len(row) != len(ExportColumns) {
+		t.Errorf("CSVRow has %d cells, want %d", len(row), len(ExportColumns))
+	}
+}
+
+func TestExportEventsKeepsRDates(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:1\r\nSUMMARY:Class\r\n" +
+		"DTSTART;TZID=Europe/Madrid:20250901T100000\r\nDTEND;TZID=Europe/Madrid:20250901T110000\r\n" +
+		"RRULE:FREQ=WEEKLY;COUNT=4\r\nRDATE;TZID=Europe/Madrid:20250906T100000\r\n" +
+		"RDATE;VALUE=PERIOD:20250920T080000Z/PT1H\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	records, err := ExportEvents(ics)
+	if err != nil {
+		t.Fatalf("ExportEvents() failed: %v", err)
+	}
+	if want := []string{"2025-09-06 10:00"}; !reflect.DeepEqual(records[0].RDates, want) {
+		t.Errorf("rdates = %q, want %q", records[0].RDates, want)
+	}
+
+	events, err := ParseICSEvents(ics)
+	if err != nil {
+		t.Fatalf("ParseICSEvents() failed: %v", err)
+	}
+	if len(events[0].RDates) != 1 || events[0].RDates[0].Day() != 6 {
+		t.Errorf("parsed RDATEs = %v", events[0].RDates)
+	}
+}
+
+func TestExportEventsRequiresDTSTART(t *testing.T) {
+	if _, err := ExportEvents("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:1\r\nSUMMARY:x\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"); err == nil {
+		t.Error("expected error for event without DTSTART")
+	}
+}
@@ -0,0 +1,64 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// FocusPlan describes a pomodoro-style run of focus sessions separated by
+// breaks. Every LongBreakEvery-th break lasts LongBreak instead of Break; no
+// break follows the last session.
+type FocusPlan struct {
+	Start          time.Time
+	Work           time.Duration
+	Break          time.Duration
+	LongBreak      time.Duration // 0 keeps every break short
+	LongBreakEvery int           // sessions between long breaks, e.g. 4
+	Cycles         int
+}
+
+// FocusBlock is one focus session or break of a FocusPlan. Cycle is the
+// 1-based session it belongs to; a break belongs to the session before it.
+type FocusBlock struct {
+	Start time.Time
+	End   time.Time
+	Cycle int
+	Break bool
+	Long  bool
+}
+
+// Blocks lays out the plan: session, break, session, ... ending with the last
+// session.
+func (p FocusPlan) Blocks() ([]FocusBlock, error) {
+	switch {
+	case p.Cycles <= 0:
+		return nil, fmt.Errorf("cycles must be positive")
+	case p.Work <= 0:
+		return nil, fmt.Errorf("work duration must be positive")
+	case p.Break < 0 || p.LongBreak < 0:
+		return nil, fmt.Errorf("break durations cannot be negative")
+	case p.LongBreak > 0 && p.LongBreakEvery <= 0:
+		return nil, fmt.Errorf("a long break needs a positive interval")
+	}
+
+	blocks := make([]FocusBlock, 0, 2*p.Cycles-1)
+	at := p.Start
+	for cycle := 1; cycle <= p.Cycles; cycle++ {
+		blocks = append(blocks, FocusBlock{Start: at, End: at.Add(p.Work), Cycle: cycle})
+		at = at.Add(p.Work)
+		if cycle == p.Cycles {
+			break
+		}
+		long := p.LongBreak > 0 && cycle%p.LongBreakEvery == 0
+		rest := p.Break
+		if long {
+			rest = p.LongBreak
+		}
+		if rest == 0 {
+			continue
+		}
+		blocks = append(blocks, FocusBlock{Start: at, End: at.Add(rest), Cycle: cycle, Break: true, Long: long})
+		at = at.Add(rest)
+	}
+	return blocks, nil
+}
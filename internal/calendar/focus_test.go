@@ -0,0 +1,63 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFocusPlanBlocksWithLongBreak(t *testing.T) {
+	start := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+	blocks, err := FocusPlan{
+		Start: start, Work: 25 * time.Minute, Break: 5 * time.Minute,
+		LongBreak: 15 * time.Minute, LongBreakEvery: 2, Cycles: 3,
+	}.Blocks()
+	if err != nil {
+		t.Fatalf("Blocks returned error: %v", err)
+	}
+
+	want := []struct {
+		start, end string
+		cycle      int
+		rest, long bool
+	}{
+		{"09:00", "09:25", 1, false, false},
+		{"09:25", "09:30", 1, true, false},
+		{"09:30", "09:55", 2, false, false},
+		{"09:55", "10:10", 2, true, true},
+		{"10:10", "10:35", 3, false, false},
+	}
+	if len(blocks) != len(want) {
+		t.Fatalf("got %d blocks, want %d: %+v", len(blocks), len(want), blocks)
+	}
+	for i, w := range want {
+		b := blocks[i]
+		if b.Start.Format("15:04") != w.start || b.End.Format("15:04") != w.end || b.Cycle != w.cycle || b.Break != w.rest || b.Long != w.long {
+			t.Errorf("block %d = %s-%s cycle %d break %v long %v, want %+v",
+				i, b.Start.Format("15:04"), b.End.Format("15:04"), b.Cycle, b.Break, b.Long, w)
+		}
+	}
+}
+
+func TestFocusPlanWithoutBreaks(t *testing.T) {
+	start := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+	blocks, err := FocusPlan{Start: start, Work: time.Hour, Cycles: 2}.Blocks()
+	if err != nil {
+		t.Fatalf("Blocks returned error: %v", err)
+	}
+	if len(blocks) != 2 || !blocks[1].Start.Equal(start.Add(time.Hour)) {
+		t.Errorf("expected two back-to-back sessions, got %+v", blocks)
+	}
+}
+
+func TestFocusPlanRejectsInvalidPlans(t *testing.T) {
+	for name, plan := range map[string]FocusPlan{
+		"no cycles":          {Work: time.Hour},
+		"no work":            {Cycles: 2},
+		"negative break":     {Work: time.Hour, Break: -time.Minute, Cycles: 2},
+		"long break no rate": {Work: time.Hour, LongBreak: time.Minute, Cycles: 2},
+	} {
+		if _, err := plan.Blocks(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
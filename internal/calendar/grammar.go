@@ -0,0 +1,435 @@
+package calendar
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// GrammarIssue is a place where an .ics document breaks the RFC 5545 grammar.
+type GrammarIssue struct {
+	Line     int    // 1-based line in the file; a folded property reports its first line
+	Property string // the property, empty for problems with the file itself
+	Message  string
+}
+
+var (
+	icsDateRe        = regexp.MustCompile(`^\d{8}$`)
+	icsDateTimeRe    = regexp.MustCompile(`^\d{8}T\d{6}Z?$`)
+	strictDurationRe = regexp.MustCompile(`^[+-]?P(?:\d+W|\d+D(?:T(?:\d+H)?(?:\d+M)?(?:\d+S)?)?|T(?:\d+H)?(?:\d+M)?(?:\d+S)?)$`)
+)
+
+// dateProps hold a DATE-TIME, or a DATE with VALUE=DATE; the list ones may
+// hold several, comma separated.
+var dateProps = map[string]bool{
+	"DTSTART": true, "DTEND": true, "DUE": true, "RECURRENCE-ID": true, "EXDATE": true, "RDATE": true,
+}
+
+// utcProps are DATE-TIMEs that must be in UTC.
+var utcProps = map[string]bool{"DTSTAMP": true, "CREATED": true, "LAST-MODIFIED": true, "COMPLETED": true}
+
+// textProps are single TEXT values, where ';' and ',' must be escaped;
+// textListProps separate their values with commas.
+var (
+	textProps = map[string]bool{
+		"SUMMARY": true, "DESCRIPTION": true, "LOCATION": true, "COMMENT": true, "CONTACT": true,
+		"NAME": true, "X-WR-CALNAME": true, "X-WR-CALDESC": true,
+	}
+	textListProps = map[string]bool{"CATEGORIES": true, "RESOURCES": true}
+)
+
+// rruleRanges bounds the numeric BY* parts of an RRULE; parts that may be
+// negative may not be zero.
+var rruleRanges = map[string][2]int{
+	"BYSECOND": {0, 60}, "BYMINUTE": {0, 59}, "BYHOUR": {0, 23}, "BYMONTH": {1, 12},
+	"BYMONTHDAY": {-31, 31}, "BYYEARDAY": {-366, 366}, "BYWEEKNO": {-53, 53}, "BYSETPOS": {-366, 366},
+}
+
+var rruleFreqs = map[string]bool{
+	"SECONDLY": true, "MINUTELY": true, "HOURLY": true, "DAILY": true, "WEEKLY": true, "MONTHLY": true, "YEARLY": true,
+}
+
+// CheckGrammar reads an .ics document the way a strict importer does: every
+// line must be UTF-8 and end in CRLF, every content line must be a name,
+// well-formed parameters and a value, and the values of the properties it
+// knows must follow their grammar: dates and date-times (with DTEND and
+// RECURRENCE-ID of the same type as DTSTART), RRULE parts, durations and
+// escaped text. It returns the issues in file order.
+func CheckGrammar(data string) []GrammarIssue {
+	var issues []GrammarIssue
+	add := func(line int, prop, format string, args ...any) {
+		issues = append(issues, GrammarIssue{Line: line, Property: prop, Message: fmt.Sprintf(format, args...)})
+	}
+
+	raw := strings.Split(data, "\n")
+	bareLF, firstLF := 0, 0
+	for i, line := range raw {
+		if !utf8.ValidString(line) {
+			add(i+1, "", "line is not valid UTF-8")
+		}
+		last := i == len(raw)-1
+		switch {
+		case last && line != "":
+			add(i+1, "", "last line does not end with CRLF")
+		case !last && !strings.HasSuffix(line, "\r"):
+			if bareLF++; firstLF == 0 {
+				firstLF = i + 1
+			}
+		}
+	}
+	if bareLF == 1 {
+		add(firstLF, "", "line ends with LF instead of CRLF")
+	} else if bareLF > 1 {
+		add(firstLF, "", "line ends with LF instead of CRLF (%d lines do)", bareLF)
+	}
+
+	stack := []*grammarComponent{{}}
+	for _, cl := range unfoldICSNumbered(raw) {
+		name, params, value, problem := scanContentLine(cl.text)
+		if problem != "" {
+			add(cl.line, name, "%s", problem)
+			continue
+		}
+		switch name {
+		case "BEGIN":
+			stack = append(stack, &grammarComponent{})
+			continue
+		case "END":
+			if len(stack) > 1 {
+				g := stack[len(stack)-1]
+				for _, o := range g.mismatches() {
+					add(o.line, o.prop, "%s is a %s but DTSTART (line %d) is a %s", o.part, o.valueType, g.startLine, g.startType)
+				}
+				stack = stack[:len(stack)-1]
+			}
+			continue
+		}
+		if msg := checkPropertyValue(name, params, value, stack[len(stack)-1], cl.line); msg != "" {
+			add(cl.line, name, "%s", msg)
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Line < issues[j].Line })
+	return issues
+}
+
+// grammarComponent remembers the value types of the component being read,
+// to check that DTEND, RECURRENCE-ID and UNTIL agree with DTSTART.
+type grammarComponent struct {
+	startType string
+	startLine int
+	others    []grammarTyped
+}
+
+type grammarTyped struct {
+	prop, part, valueType string // part is "value" or the RRULE's "UNTIL"
+	line                  int
+}
+
+func (g grammarComponent) mismatches() []grammarTyped {
+	if g.startType == "" {
+		return nil
+	}
+	var out []grammarTyped
+	for _, o := range g.others {
+		if o.valueType != g.startType {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+type numberedLine struct {
+	line int
+	text string
+}
+
+// unfoldICSNumbered joins folded lines, dropping the one space or tab that
+// starts each continuation, and remembers where each content line starts.
+func unfoldICSNumbered(raw []string) []numberedLine {
+	var lines []numberedLine
+	var text strings.Builder // the last line, growing with its continuations
+	flush := func() {
+		if n := len(lines); n > 0 && text.Len() > 0 {
+			lines[n-1].text = text.String()
+			text.Reset()
+		}
+	}
+	for i, line := range raw {
+		line = strings.TrimSuffix(line, "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			text.WriteString(line[1:])
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		flush()
+		lines = append(lines, numberedLine{line: i + 1})
+		text.WriteString(line)
+	}
+	flush()
+	return lines
+}
+
+func isICSNameChar(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-'
+}
+
+// scanContentLine splits "NAME;PARAM=value,"quoted":VALUE", returning the
+// upper-cased name, the parameters by upper-cased name (unquoted, multiple
+// values joined by commas) and the value, or what is wrong with the line.
+func scanContentLine(line string) (name string, params map[string]string, value, problem string) {
+	i := 0
+	for i < len(line) && isICSNameChar(line[i]) {
+		i++
+	}
+	name = strings.ToUpper(line[:i])
+	if name == "" {
+		return "", nil, "", "line does not start with a property name"
+	}
+	params = map[string]string{}
+	for i < len(line) && line[i] == ';' {
+		j := i + 1
+		for j < len(line) && isICSNameChar(line[j]) {
+			j++
+		}
+		if j == i+1 || j >= len(line) || line[j] != '=' {
+			return name, nil, "", fmt.Sprintf("malformed parameter %q", strings.SplitN(line[i+1:], ":", 2)[0])
+		}
+		pname := strings.ToUpper(line[i+1 : j])
+		i = j + 1
+		var values []string
+		for {
+			if i < len(line) && line[i] == '"' {
+				end := strings.IndexByte(line[i+1:], '"')
+				if end < 0 {
+					return name, nil, "", fmt.Sprintf("parameter %s has an unterminated quoted value", pname)
+				}
+				values = append(values, line[i+1:i+1+end])
+				i += end + 2
+			} else {
+				j := i
+				for j < len(line) && !strings.ContainsRune(`;:,"`, rune(line[j])) {
+					j++
+				}
+				values = append(values, line[i:j])
+				i = j
+			}
+			if i < len(line) && line[i] == ',' {
+				i++
+				continue
+			}
+			break
+		}
+		params[pname] = strings.Join(values, ",")
+	}
+	if i >= len(line) || line[i] != ':' {
+		return name, nil, "", "missing ':' between the property name and its value"
+	}
+	value = line[i+1:]
+	for _, r := range value {
+		if r < 0x20 && r != '\t' || r == 0x7f {
+			return name, nil, "", fmt.Sprintf("value contains control character %U", r)
+		}
+	}
+	return name, params, value, ""
+}
+
+// checkPropertyValue checks the value of the properties CheckGrammar knows,
+// recording date types in g.
+func checkPropertyValue(name string, params map[string]string, value string, g *grammarComponent, line int) string {
+	valueType := strings.ToUpper(params["VALUE"])
+	switch {
+	case dateProps[name]:
+		typ := ""
+		for _, v := range strings.Split(value, ",") {
+			t, msg := checkDateValue(v, valueType, params["TZID"] != "", name == "RDATE")
+			if msg != "" {
+				return msg
+			}
+			typ = t
+		}
+		switch name {
+		case "DTSTART":
+			g.startType, g.startLine = typ, line
+		case "DTEND", "RECURRENCE-ID":
+			g.others = append(g.others, grammarTyped{prop: name, part: "value", valueType: typ, line: line})
+		}
+	case utcProps[name]:
+		if !icsDateTimeRe.MatchString(value) || !strings.HasSuffix(value, "Z") || !validICSTime(value) {
+			return fmt.Sprintf("%q is not a UTC date-time (YYYYMMDDTHHMMSSZ)", value)
+		}
+	case name == "RRULE" || name == "EXRULE":
+		until, msg := checkRRuleValue(value)
+		if msg != "" {
+			return msg
+		}
+		if until != "" {
+			g.others = append(g.others, grammarTyped{prop: name, part: "UNTIL", valueType: until, line: line})
+		}
+	case name == "DURATION" || name == "REFRESH-INTERVAL" || name == "TRIGGER" && valueType != "DATE-TIME":
+		if !validICSDuration(value) {
+			return fmt.Sprintf("%q is not a duration (such as PT1H30M, P1D or -PT15M)", value)
+		}
+	case name == "TRIGGER":
+		if !icsDateTimeRe.MatchString(value) || !strings.HasSuffix(value, "Z") || !validICSTime(value) {
+			return fmt.Sprintf("%q is not a UTC date-time (YYYYMMDDTHHMMSSZ)", value)
+		}
+	case textProps[name] || textListProps[name]:
+		return checkTextValue(value, textListProps[name])
+	}
+	return ""
+}
+
+// checkDateValue checks one DATE, DATE-TIME or (for RDATE) PERIOD value and
+// returns its type, "date" or "date-time".
+func checkDateValue(value, valueType string, tzid, period bool) (string, string) {
+	switch valueType {
+	case "DATE":
+		if !icsDateRe.MatchString(value) {
+			return "", fmt.Sprintf("%q is not a date (YYYYMMDD) as VALUE=DATE says", value)
+		}
+		if _, err := time.Parse("20060102", value); err != nil {
+			return "", fmt.Sprintf("%q is not a valid date", value)
+		}
+		return "date", ""
+	case "", "DATE-TIME":
+		if icsDateRe.MatchString(value) {
+			return "", fmt.Sprintf("%q is a date; add VALUE=DATE", value)
+		}
+		if !icsDateTimeRe.MatchString(value) || !validICSTime(value) {
+			return "", fmt.Sprintf("%q is not a date-time (YYYYMMDDTHHMMSS, with Z for UTC)", value)
+		}
+		if tzid && strings.HasSuffix(value, "Z") {
+			return "", fmt.Sprintf("%q is in UTC but has a TZID", value)
+		}
+		return "date-time", ""
+	case "PERIOD":
+		if !period {
+			break
+		}
+		start, end, ok := strings.Cut(value, "/")
+		if _, msg := checkDateValue(start, "", tzid, false); !ok || msg != "" {
+			return "", fmt.Sprintf("%q is not a period (start/end or start/duration)", value)
+		}
+		if _, msg := checkDateValue(end, "", tzid, false); msg != "" && !validICSDuration(end) {
+			return "", fmt.Sprintf("%q is not a period (start/end or start/duration)", value)
+		}
+		return "date-time", ""
+	}
+	return "", fmt.Sprintf("VALUE=%s is not allowed here", valueType)
+}
+
+// validICSDuration reports whether value is a DURATION; "PT" alone is not.
+func validICSDuration(value string) bool {
+	return strictDurationRe.MatchString(value) && !strings.HasSuffix(value, "T")
+}
+
+func validICSTime(value string) bool {
+	_, err := time.Parse("20060102T150405", strings.TrimSuffix(value, "Z"))
+	return err == nil
+}
+
+// checkRRuleValue checks the parts of a recurrence rule and returns the type
+// of its UNTIL, if any.
+func checkRRuleValue(value string) (until, problem string) {
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		key, val = strings.ToUpper(key), strings.ToUpper(val)
+		if !ok || val == "" {
+			return "", fmt.Sprintf("part %q is not NAME=VALUE", part)
+		}
+		if seen[key] {
+			return "", fmt.Sprintf("%s appears twice", key)
+		}
+		seen[key] = true
+		switch key {
+		case "FREQ":
+			if !rruleFreqs[val] {
+				return "", fmt.Sprintf("FREQ=%s is not a frequency", val)
+			}
+		case "UNTIL":
+			typ, msg := checkDateValue(val, "", false, false)
+			if msg != "" {
+				if typ, msg = checkDateValue(val, "DATE", false, false); msg != "" {
+					return "", fmt.Sprintf("UNTIL=%s is not a date or date-time", val)
+				}
+			}
+			until = typ
+		case "COUNT", "INTERVAL":
+			if n, err := strconv.Atoi(val); err != nil || n < 1 || strings.ContainsAny(val, "+-") {
+				return "", fmt.Sprintf("%s=%s is not a positive number", key, val)
+			}
+		case "WKST":
+			if _, ok := rruleWeekdays[val]; !ok {
+				return "", fmt.Sprintf("WKST=%s is not a weekday (MO to SU)", val)
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(val, ",") {
+				if !validRRuleDay(day) {
+					return "", fmt.Sprintf("BYDAY=%s: %q is not a weekday such as MO, 2TU or -1FR", val, day)
+				}
+			}
+		default:
+			bounds, ok := rruleRanges[key]
+			if !ok {
+				return "", fmt.Sprintf("unknown part %s", key)
+			}
+			for _, v := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(v)
+				if err != nil || n < bounds[0] || n > bounds[1] || bounds[0] < 0 && n == 0 || bounds[0] >= 0 && strings.ContainsAny(v, "+-") {
+					return "", fmt.Sprintf("%s=%s: %q is outside %d..%d", key, val, v, bounds[0], bounds[1])
+				}
+			}
+		}
+	}
+	switch {
+	case !seen["FREQ"]:
+		return "", "no FREQ"
+	case seen["UNTIL"] && seen["COUNT"]:
+		return "", "both UNTIL and COUNT"
+	}
+	return until, ""
+}
+
+func validRRuleDay(day string) bool {
+	if len(day) < 2 {
+		return false
+	}
+	if _, ok := rruleWeekdays[day[len(day)-2:]]; !ok {
+		return false
+	}
+	ord := day[:len(day)-2]
+	if ord == "" {
+		return true
+	}
+	n, err := strconv.Atoi(ord)
+	return err == nil && n != 0 && n >= -53 && n <= 53
+}
+
+// checkTextValue reports a backslash that escapes nothing RFC 5545 knows, or
+// a ';' (and, in single values, a ',') left unescaped.
+func checkTextValue(value string, list bool) string {
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			if i+1 == len(value) || !strings.ContainsRune(`\;,nN`, rune(value[i+1])) {
+				return fmt.Sprintf(`backslash at column %d escapes nothing (use \\, \;, \, or \n)`, i+1)
+			}
+			i++
+		case ';':
+			return fmt.Sprintf(`unescaped ';' at column %d (write \;)`, i+1)
+		case ',':
+			if !list {
+				return fmt.Sprintf(`unescaped ',' at column %d (write \,)`, i+1)
+			}
+		}
+	}
+	return ""
+}
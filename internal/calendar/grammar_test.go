@@ -0,0 +1,103 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+)
+
+func grammarDoc(lines ...string) string {
+	return strings.Join(append(append([]string{"BEGIN:VCALENDAR", "VERSION:2.0", "BEGIN:VEVENT", "UID:1"}, lines...),
+		"END:VEVENT", "END:VCALENDAR"), "\r\n") + "\r\n"
+}
+
+func TestCheckGrammarAcceptsValidDocument(t *testing.T) {
+	data := grammarDoc(
+		"DTSTAMP:20300101T000000Z",
+		`SUMMARY:Review\, then lunch\; maybe`,
+		`DESCRIPTION:line one\nline two \\ done`,
+		"CATEGORIES:Work,Team",
+		`ATTENDEE;CN="Doe, Jane";ROLE=REQ-PARTICIPANT:mailto:jane@example.com`,
+		"DTSTART;TZID=Europe/Madrid:20300110T100000",
+		"DTEND;TZID=Europe/Madrid:20300110T110000",
+		"RRULE:FREQ=MONTHLY;BYDAY=2TU,-1FR;BYMONTH=1,6;UNTIL=20301231T230000Z;WKST=MO",
+		"EXDATE;TZID=Europe/Madrid:20300210T100000,20300310T100000",
+		"RDATE;VALUE=PERIOD:20300120T100000Z/PT1H",
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		"TRIGGER:-PT15M",
+		"END:VALARM",
+	)
+	if issues := CheckGrammar(data); len(issues) > 0 {
+		t.Errorf("CheckGrammar() = %+v, want none", issues)
+	}
+
+	allDay := grammarDoc("DTSTART;VALUE=DATE:20300110", "DTEND;VALUE=DATE:20300111", "RRULE:FREQ=YEARLY;UNTIL=20350110", "DURATION:P1D")
+	if issues := CheckGrammar(allDay); len(issues) > 0 {
+		t.Errorf("CheckGrammar(all-day) = %+v, want none", issues)
+	}
+}
+
+func TestCheckGrammarReportsIssues(t *testing.T) {
+	tests := []struct {
+		name, line, want string
+	}{
+		{"date without VALUE=DATE", "DTSTART:20300110", "add VALUE=DATE"},
+		{"VALUE=DATE with a time", "DTSTART;VALUE=DATE:20300110T100000", "is not a date"},
+		{"impossible date", "DTSTART:20300231T100000", "is not a date-time"},
+		{"UTC with TZID", "DTSTART;TZID=Europe/Madrid:20300110T100000Z", "in UTC but has a TZID"},
+		{"local DTSTAMP", "DTSTAMP:20300101T000000", "not a UTC date-time"},
+		{"unknown RRULE part", "RRULE:FREQ=DAILY;BYDAYS=MO", "unknown part BYDAYS"},
+		{"RRULE without FREQ", "RRULE:COUNT=3", "no FREQ"},
+		{"UNTIL and COUNT", "RRULE:FREQ=DAILY;COUNT=3;UNTIL=20300201T000000Z", "both UNTIL and COUNT"},
+		{"BYMONTHDAY zero", "RRULE:FREQ=MONTHLY;BYMONTHDAY=0", "outside -31..31"},
+		{"bad BYDAY", "RRULE:FREQ=WEEKLY;BYDAY=MON", "not a weekday"},
+		{"bad duration", "DURATION:1H", "not a duration"},
+		{"empty time part", "DURATION:PT", "not a duration"},
+		{"stray backslash", `SUMMARY:C:\temp`, "escapes nothing"},
+		{"unescaped semicolon", "LOCATION:Room 1; floor 2", `unescaped ';'`},
+		{"unescaped comma", "SUMMARY:Plan, review", `unescaped ','`},
+		{"bad parameter", "DTSTART;TZID:20300110T100000", "malformed parameter"},
+		{"unterminated quote", `ATTENDEE;CN="Jane:mailto:j@example.com`, "unterminated"},
+		{"no colon", "SUMMARY", "missing ':'"},
+	}
+	for _, tt := range tests {
+		issues := CheckGrammar(grammarDoc("DTSTART;TZID=UTC:20300110T100000", tt.line))
+		if len(issues) != 1 || issues[0].Line != 6 || !strings.Contains(issues[0].Message, tt.want) {
+			t.Errorf("%s: CheckGrammar() = %+v, want one issue on line 6 with %q", tt.name, issues, tt.want)
+		}
+	}
+}
+
+func TestCheckGrammarValueTypesMustMatch(t *testing.T) {
+	issues := CheckGrammar(grammarDoc(
+		"DTSTART;VALUE=DATE:20300110",
+		"DTEND:20300111T100000",
+		"RRULE:FREQ=DAILY;UNTIL=20300120T000000Z",
+	))
+	if len(issues) != 2 || issues[0].Property != "DTEND" || issues[1].Property != "RRULE" ||
+		!strings.Contains(issues[1].Message, "UNTIL is a date-time but DTSTART (line 5) is a date") {
+		t.Errorf("CheckGrammar() = %+v", issues)
+	}
+}
+
+func TestCheckGrammarLineEndingsAndEncoding(t *testing.T) {
+	data := "BEGIN:VCALENDAR\nVERSION:2.0\r\nSUMMARY:caf\xe9\r\nEND:VCALENDAR"
+	issues := CheckGrammar(data)
+	want := []struct {
+		line int
+		msg  string
+	}{{1, "LF instead of CRLF"}, {3, "not valid UTF-8"}, {4, "does not end with CRLF"}}
+	if len(issues) != len(want) {
+		t.Fatalf("CheckGrammar() = %+v", issues)
+	}
+	for i, w := range want {
+		if issues[i].Line != w.line || !strings.Contains(issues[i].Message, w.msg) {
+			t.Errorf("issue %d = %+v, want %q on line %d", i, issues[i], w.msg, w.line)
+		}
+	}
+
+	folded := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nSUMMARY:a very long\r\n  summary\\, folded\r\nX-BAD\r\nEND:VCALENDAR\r\n"
+	if issues := CheckGrammar(folded); len(issues) != 1 || issues[0].Line != 5 {
+		t.Errorf("folded lines should keep their numbers: %+v", issues)
+	}
+}
@@ -0,0 +1,137 @@
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"tempus/internal/constants"
+)
+
+// HolidaySet maps ISO dates (YYYY-MM-DD) to holiday names.
+type HolidaySet map[string]string
+
+// NewHolidaySet builds a set from date → name entries, validating every date.
+func NewHolidaySet(entries map[string]string) (HolidaySet, error) {
+	set := HolidaySet{}
+	for date, name := range entries {
+		if err := set.add(date, name); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+func (h HolidaySet) add(date, name string) error {
+	date = strings.TrimSpace(date)
+	if _, err := time.Parse(constants.DateFormatISO, date); err != nil {
+		return fmt.Errorf("invalid holiday date %q (want YYYY-MM-DD)", date)
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "Holiday"
+	}
+	h[date] = name
+	return nil
+}
+
+// Merge copies all entries of other into h.
+func (h HolidaySet) Merge(other HolidaySet) {
+	for date, name := range other {
+		h[date] = name
+	}
+}
+
+// Lookup returns the holiday name for the calendar date of t.
+func (h HolidaySet) Lookup(t time.Time) (string, bool) {
+	name, ok := h[t.Format(constants.DateFormatISO)]
+	return name, ok
+}
+
+// IsWeekend reports whether t falls on a Saturday or Sunday.
+func IsWeekend(t time.Time) bool {
+	wd := t.Weekday()
+	return wd == time.Saturday || wd == time.Sunday
+}
+
+// holidayRepeatLimit caps how often an endless RRULE repeats a holiday:
+// a century of yearly ones.
+const holidayRepeatLimit = 100
+
+// LoadHolidaysFile reads holidays from an .ics file (every day each VEVENT
+// covers, from DTSTART up to DTEND and repeated by its RRULE, named by its
+// SUMMARY, as published by most public-holiday calendars) or from a plain
+// text file with one "YYYY-MM-DD Name" entry per line ("#" starts a comment).
+func LoadHolidaysFile(path string) (HolidaySet, error) {
+	if strings.EqualFold(filepath.Ext(path), ".ics") {
+		data, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return nil, err
+		}
+		return parseHolidaysICS(string(data))
+	}
+
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseHolidaysText(bufio.NewScanner(f))
+}
+
+func parseHolidaysText(scanner *bufio.Scanner) (HolidaySet, error) {
+	set := HolidaySet{}
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		date, name, _ := strings.Cut(text, " ")
+		if err := set.add(date, name); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return set, scanner.Err()
+}
+
+// parseHolidaysICS skips events it cannot read; a rule it cannot expand
+// (such as BYMONTH) keeps just the first occurrence.
+func parseHolidaysICS(data string) (HolidaySet, error) {
+	segments, err := splitICSEvents(data)
+	if err != nil {
+		return nil, err
+	}
+	set := HolidaySet{}
+	for _, seg := range segments {
+		if !seg.event {
+			continue
+		}
+		ev, err := parseICSEvent(seg.lines)
+		if err != nil {
+			continue
+		}
+		occurrences, _, err := ev.Materialize(holidayRepeatLimit, 0, nil)
+		if err != nil {
+			occurrences = []Event{ev}
+		}
+		for _, occ := range occurrences {
+			set.addDays(occ.StartTime, occ.EndTime, occ.Summary)
+		}
+	}
+	return set, nil
+}
+
+// addDays names every day the span [start, end) touches, and at least the
+// day start falls on.
+func (h HolidaySet) addDays(start, end time.Time, name string) {
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	for {
+		_ = h.add(day.Format(constants.DateFormatISO), name)
+		if day = day.AddDate(0, 0, 1); !day.Before(end) {
+			return
+		}
+	}
+}
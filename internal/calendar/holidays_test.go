@@ -0,0 +1,98 @@
+package calendar
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadHolidaysFileText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "holidays.txt")
+	content := "# Irish bank holidays\n2025-12-25 Christmas Day\n2025-12-26 St. Stephen's Day\n\n2026-01-01\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	set, err := LoadHolidaysFile(path)
+	if err != nil {
+		t.Fatalf("LoadHolidaysFile returned error: %v", err)
+	}
+	if len(set) != 3 {
+		t.Fatalf("expected 3 holidays, got %d: %v", len(set), set)
+	}
+	if name, ok := set.Lookup(time.Date(2025, 12, 26, 9, 0, 0, 0, time.UTC)); !ok || name != "St. Stephen's Day" {
+		t.Errorf("unexpected lookup result %q (ok=%v)", name, ok)
+	}
+	if name, _ := set.Lookup(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); name != "Holiday" {
+		t.Errorf("expected default name for unnamed holiday, got %q", name)
+	}
+}
+
+func TestLoadHolidaysFileICS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "holidays.ics")
+	content := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20251225\r\nSUMMARY:Christmas\r\n  Day\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20260317\r\nSUMMARY:St. Patrick's Day\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	set, err := LoadHolidaysFile(path)
+	if err != nil {
+		t.Fatalf("LoadHolidaysFile returned error: %v", err)
+	}
+	if set["2025-12-25"] != "Christmas Day" || set["2026-03-17"] != "St. Patrick's Day" {
+		t.Fatalf("unexpected holidays: %v", set)
+	}
+}
+
+func TestNewHolidaySetRejectsInvalidDates(t *testing.T) {
+	if _, err := NewHolidaySet(map[string]string{"25/12/2025": "Christmas"}); err == nil {
+		t.Fatal("expected error for non-ISO holiday date")
+	}
+}
+
+func TestIsWeekend(t *testing.T) {
+	if !IsWeekend(time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expected Saturday to be a weekend")
+	}
+	if IsWeekend(time.Date(2025, 12, 22, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expected Monday not to be a weekend")
+	}
+}
+
+func TestLoadHolidaysFileICSExpandsSpansAndRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "holidays.ics")
+	content := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20301224\r\nDTEND;VALUE=DATE:20301227\r\nSUMMARY:Christmas break\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20300317\r\nRRULE:FREQ=YEARLY;UNTIL=20320317\r\nSUMMARY:St. Patrick's Day\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20300501\r\nRRULE:FREQ=YEARLY\r\nSUMMARY:May Day\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	set, err := LoadHolidaysFile(path)
+	if err != nil {
+		t.Fatalf("LoadHolidaysFile returned error: %v", err)
+	}
+	for _, date := range []string{"2030-12-24", "2030-12-25", "2030-12-26"} {
+		if set[date] != "Christmas break" {
+			t.Errorf("expected %s in the multi-day holiday, got %q", date, set[date])
+		}
+	}
+	if _, ok := set["2030-12-27"]; ok {
+		t.Error("DTEND is exclusive, 2030-12-27 should not be a holiday")
+	}
+	for _, date := range []string{"2030-03-17", "2031-03-17", "2032-03-17"} {
+		if set[date] != "St. Patrick's Day" {
+			t.Errorf("expected the yearly rule to cover %s, got %q", date, set[date])
+		}
+	}
+	if _, ok := set["2033-03-17"]; ok {
+		t.Error("the yearly rule should stop at its UNTIL")
+	}
+	if set["2079-05-01"] != "May Day" || set["2130-05-01"] != "" {
+		t.Errorf("expected an endless yearly rule to run for %d years", holidayRepeatLimit)
+	}
+}
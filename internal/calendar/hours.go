@@ -0,0 +1,185 @@
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ClockWindow is a daily time range in minutes since midnight.
+// A window whose End is before its Start wraps past midnight (e.g. 22:00-07:00).
+type ClockWindow struct {
+	Start int
+	End   int
+}
+
+// WeeklyHours maps each weekday to the windows that apply on that day.
+// Weekdays without an entry have no windows.
+type WeeklyHours map[time.Weekday][]ClockWindow
+
+var hoursDayGroups = map[string][]time.Weekday{
+	"daily":    {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday},
+	"default":  {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday},
+	"weekdays": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"weekend":  {time.Saturday, time.Sunday},
+}
+
+var hoursDayNames = map[string]time.Weekday{
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+	"sun": time.Sunday, "sunday": time.Sunday,
+}
+
+// ParseWeeklyHours parses a config map such as
+//
+//	weekdays: "09:00-17:00"
+//	fri: "09:00-13:00"
+//	weekend: off
+//
+// Keys are weekday names (mon, tuesday, ...) or the groups daily, default,
+// weekdays and weekend; a single weekday always overrides a group. Values are
+// comma-separated HH:MM-HH:MM windows, or "off"/"none" for no window that day.
+func ParseWeeklyHours(spec map[string]string) (WeeklyHours, error) {
+	hours := WeeklyHours{}
+	if len(spec) == 0 {
+		return hours, nil
+	}
+
+	// Apply groups first (in a stable order) so single days can override them.
+	keys := make([]string, 0, len(spec))
+	for k := range spec {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		_, gi := hoursDayGroups[strings.ToLower(strings.TrimSpace(keys[i]))]
+		_, gj := hoursDayGroups[strings.ToLower(strings.TrimSpace(keys[j]))]
+		if gi != gj {
+			return gi
+		}
+		return keys[i] < keys[j]
+	})
+
+	for _, key := range keys {
+		name := strings.ToLower(strings.TrimSpace(key))
+		days, ok := hoursDayGroups[name]
+		if !ok {
+			wd, ok := hoursDayNames[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown weekday %q", key)
+			}
+			days = []time.Weekday{wd}
+		}
+		windows, err := ParseClockWindows(spec[key])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		for _, wd := range days {
+			hours[wd] = windows
+		}
+	}
+	return hours, nil
+}
+
+// ParseClockWindows parses comma-separated HH:MM-HH:MM windows such as
+// "09:00-13:00, 14:00-17:00"; "off" or "none" gives no windows.
+func ParseClockWindows(value string) ([]ClockWindow, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" || value == "off" || value == "none" {
+		return nil, nil
+	}
+	var windows []ClockWindow
+	for _, part := range strings.Split(value, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(part), "-", 2)
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("invalid window %q (want HH:MM-HH:MM)", part)
+		}
+		start, err := parseClockMinutes(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClockMinutes(bounds[1])
+		if err != nil {
+			return nil, err
+		}
+		if start == end {
+			return nil, fmt.Errorf("empty window %q", part)
+		}
+		windows = append(windows, ClockWindow{Start: start, End: end})
+	}
+	return windows, nil
+}
+
+func parseClockMinutes(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	hm := strings.SplitN(s, ":", 2)
+	if len(hm) != 2 {
+		return 0, fmt.Errorf("invalid time %q (want HH:MM)", s)
+	}
+	h, herr := strconv.Atoi(hm[0])
+	m, merr := strconv.Atoi(hm[1])
+	if herr != nil || merr != nil || h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time %q (want HH:MM)", s)
+	}
+	return h*60 + m, nil
+}
+
+// Configured reports whether any window is defined for any day.
+func (w WeeklyHours) Configured() bool {
+	return len(w) > 0
+}
+
+// Contains reports whether the wall-clock time of t (in its own location)
+// falls inside one of the windows for its weekday. Overnight windows cover
+// both the late evening and the early morning of the same weekday.
+func (w WeeklyHours) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	for _, win := range w[t.Weekday()] {
+		if win.contains(minute) {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains reports whether the wall-clock time of t falls inside the window.
+func (cw ClockWindow) Contains(t time.Time) bool {
+	return cw.contains(t.Hour()*60 + t.Minute())
+}
+
+// Minutes returns the length of the window.
+func (cw ClockWindow) Minutes() int {
+	if cw.Start < cw.End {
+		return cw.End - cw.Start
+	}
+	return 24*60 - cw.Start + cw.End
+}
+
+func (cw ClockWindow) contains(minute int) bool {
+	if cw.Start < cw.End {
+		return minute >= cw.Start && minute < cw.End
+	}
+	return minute >= cw.Start || minute < cw.End
+}
+
+// AlarmFireTime returns when al fires for e, expressed in the event's start timezone.
+func (e *Event) AlarmFireTime(al Alarm) time.Time {
+	if al.TriggerIsRelative {
+		return e.StartTime.Add(al.TriggerDuration)
+	}
+	if tz := strings.TrimSpace(e.StartTZ); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return al.TriggerTime
+		}
+		// StartTime carries the wall clock of StartTZ, so express the trigger the same way.
+		local := al.TriggerTime.In(loc)
+		return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), 0, e.StartTime.Location())
+	}
+	return al.TriggerTime
+}
@@ -0,0 +1,89 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWeeklyHoursDayOverridesGroup(t *testing.T) {
+	hours, err := ParseWeeklyHours(map[string]string{
+		"weekdays": "09:00-17:00",
+		"fri":      "09:00-13:00",
+		"weekend":  "off",
+	})
+	if err != nil {
+		t.Fatalf("ParseWeeklyHours returned error: %v", err)
+	}
+
+	thu := time.Date(2025, 12, 18, 15, 0, 0, 0, time.UTC)
+	fri := time.Date(2025, 12, 19, 15, 0, 0, 0, time.UTC)
+	sat := time.Date(2025, 12, 20, 10, 0, 0, 0, time.UTC)
+	if !hours.Contains(thu) {
+		t.Error("expected Thursday 15:00 inside working hours")
+	}
+	if hours.Contains(fri) {
+		t.Error("expected Friday 15:00 outside the shorter Friday window")
+	}
+	if hours.Contains(sat) {
+		t.Error("expected weekend to have no window")
+	}
+}
+
+func TestWeeklyHoursOvernightWindow(t *testing.T) {
+	hours, err := ParseWeeklyHours(map[string]string{"daily": "22:00-07:00"})
+	if err != nil {
+		t.Fatalf("ParseWeeklyHours returned error: %v", err)
+	}
+	for _, tc := range []struct {
+		clock string
+		want  bool
+	}{{"03:00", true}, {"23:30", true}, {"07:00", false}, {"12:00", false}} {
+		at, _ := time.Parse("2006-01-02 15:04", "2025-12-16 "+tc.clock)
+		if got := hours.Contains(at); got != tc.want {
+			t.Errorf("Contains(%s) = %v, want %v", tc.clock, got, tc.want)
+		}
+	}
+}
+
+func TestClockWindowContainsAndMinutes(t *testing.T) {
+	windows, err := ParseClockWindows("12:00-14:00, 21:00-06:00")
+	if err != nil || len(windows) != 2 {
+		t.Fatalf("ParseClockWindows() = %v, %v", windows, err)
+	}
+	if windows[0].Minutes() != 120 || windows[1].Minutes() != 540 {
+		t.Errorf("Minutes() = %d, %d; want 120, 540", windows[0].Minutes(), windows[1].Minutes())
+	}
+	at := time.Date(2025, 12, 16, 5, 30, 0, 0, time.UTC)
+	if windows[0].Contains(at) || !windows[1].Contains(at) {
+		t.Error("expected 05:30 only inside the overnight window")
+	}
+}
+
+func TestParseWeeklyHoursRejectsInvalidSpecs(t *testing.T) {
+	for _, spec := range []map[string]string{
+		{"funday": "09:00-17:00"},
+		{"mon": "9-17"},
+		{"mon": "09:00-25:00"},
+		{"mon": "09:00-09:00"},
+	} {
+		if _, err := ParseWeeklyHours(spec); err == nil {
+			t.Errorf("expected error for %v", spec)
+		}
+	}
+}
+
+func TestAlarmFireTimeUsesEventTimezone(t *testing.T) {
+	start := time.Date(2025, 12, 16, 9, 0, 0, 0, time.UTC)
+	ev := NewEvent("Standup", start, start.Add(15*time.Minute))
+	ev.StartTZ = "Europe/Madrid"
+
+	relative := ev.AlarmFireTime(Alarm{TriggerIsRelative: true, TriggerDuration: -10 * time.Minute})
+	if relative.Format("15:04") != "08:50" {
+		t.Errorf("relative alarm fired at %s, want 08:50", relative.Format("15:04"))
+	}
+
+	absolute := ev.AlarmFireTime(Alarm{TriggerTime: time.Date(2025, 12, 16, 2, 0, 0, 0, time.UTC)})
+	if absolute.Format("15:04") != "03:00" {
+		t.Errorf("absolute alarm fired at %s, want 03:00 Madrid time", absolute.Format("15:04"))
+	}
+}
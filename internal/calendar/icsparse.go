@@ -0,0 +1,155 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Minimal content-line handling (RFC 5545 §3.1) for reading existing .ics files.
+
+type icsLine struct {
+	name   string
+	params []string // raw "KEY=VALUE" parameters, in order
+	value  string
+}
+
+// parseICSLine splits "NAME;P=V;...:VALUE", honouring quoted parameter values.
+func parseICSLine(line string) icsLine {
+	inQuote := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuote = !inQuote
+		} else if r == ':' && !inQuote {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return icsLine{name: strings.ToUpper(line)}
+	}
+	head := strings.Split(line[:colon], ";")
+	return icsLine{name: strings.ToUpper(head[0]), params: head[1:], value: line[colon+1:]}
+}
+
+func (p icsLine) param(key string) string {
+	for _, kv := range p.params {
+		k, v, _ := strings.Cut(kv, "=")
+		if strings.EqualFold(k, key) {
+			return strings.Trim(v, `"`)
+		}
+	}
+	return ""
+}
+
+func (p *icsLine) setParam(key, value string) {
+	for i, kv := range p.params {
+		if k, _, _ := strings.Cut(kv, "="); strings.EqualFold(k, key) {
+			p.params[i] = key + "=" + value
+			return
+		}
+	}
+	p.params = append(p.params, key+"="+value)
+}
+
+func (p icsLine) String() string {
+	head := append([]string{p.name}, p.params...)
+	return strings.Join(head, ";") + ":" + p.value
+}
+
+// describe renders a date property for humans, e.g. "20250301T090000 Europe/Madrid".
+func (p icsLine) describe() string {
+	if tz := p.param("TZID"); tz != "" {
+		return p.value + " " + tz
+	}
+	return p.value
+}
+
+// unfoldICS joins folded lines. The line being unfolded grows in a
+// builder, so a value folded thousands of times is not copied each time.
+func unfoldICS(data string) []string {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	var lines []string
+	var line strings.Builder
+	for _, raw := range strings.Split(data, "\n") {
+		if (strings.HasPrefix(raw, " ") || strings.HasPrefix(raw, "\t")) && line.Len() > 0 {
+			line.WriteString(raw[1:])
+			continue
+		}
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		if line.Len() > 0 {
+			lines = append(lines, line.String())
+			line.Reset()
+		}
+		line.WriteString(strings.TrimRight(raw, "\r"))
+	}
+	if line.Len() > 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+func unescapeText(s string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
+
+// icsSegment is either one whole VEVENT (its unfolded lines) or a single line
+// outside any VEVENT, so documents can be filtered and written back in order.
+type icsSegment struct {
+	lines []string
+	event bool
+}
+
+func splitICSEvents(data string) ([]icsSegment, error) {
+	var segments []icsSegment
+	var event []string
+	for _, line := range unfoldICS(data) {
+		upper := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case upper == "BEGIN:VEVENT":
+			event = []string{line}
+		case event != nil:
+			event = append(event, line)
+			if upper == "END:VEVENT" {
+				segments = append(segments, icsSegment{lines: event, event: true})
+				event = nil
+			}
+		default:
+			segments = append(segments, icsSegment{lines: []string{line}})
+		}
+	}
+	if event != nil {
+		return nil, fmt.Errorf("unterminated VEVENT")
+	}
+	return segments, nil
+}
+
+func writeICSSegments(segments []icsSegment) string {
+	var b strings.Builder
+	for _, seg := range segments {
+		for _, line := range seg.lines {
+			writeLine(&b, line)
+		}
+	}
+	return b.String()
+}
+
+// addEventProps adds property lines to a VEVENT (its unfolded lines, BEGIN to
+// END) after its own properties, ahead of its first VALARM or other
+// subcomponent as RFC 5545 orders them. Every line already there is kept as
+// it was, including the properties and components tempus does not model.
+func addEventProps(event []string, props ...string) []string {
+	at := len(event) - 1
+	for i, line := range event[1:at] {
+		if parseICSLine(line).name == "BEGIN" {
+			at = i + 1
+			break
+		}
+	}
+	out := make([]string, 0, len(event)+len(props))
+	out = append(out, event[:at]...)
+	out = append(out, props...)
+	return append(out, event[at:]...)
+}
@@ -0,0 +1,208 @@
+package calendar
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"tempus/internal/clock"
+)
+
+// foreignDoc holds what tempus does not model: a VTODO and a VJOURNAL from
+// other tools, vendor X- properties and parameters, VALARM extensions, a
+// nested VLOCATION and a custom X- component.
+var foreignDoc = strings.Join([]string{
+	"BEGIN:VCALENDAR",
+	"VERSION:2.0",
+	"PRODID:-//Other Tool//EN",
+	"X-WR-CALNAME:Mine",
+	"X-APPLE-CALENDAR-COLOR:#FF0000",
+	"BEGIN:VTODO",
+	"UID:todo-1",
+	"SUMMARY:Buy milk",
+	"DUE:20300105T120000Z",
+	"X-OTHER-TOOL;X-P=1:keep me",
+	"END:VTODO",
+	"BEGIN:VEVENT",
+	"UID:ev-1",
+	"DTSTAMP:20300101T000000Z",
+	"SUMMARY:Standup",
+	"DTSTART;TZID=Europe/Madrid:20300110T100000",
+	"DTEND;TZID=Europe/Madrid:20300110T103000",
+	"RRULE:FREQ=DAILY;COUNT=10",
+	"X-MICROSOFT-CDO-BUSYSTATUS:BUSY",
+	`ATTENDEE;X-NUM-GUESTS=0;CN="Doe, J":mailto:j@example.com`,
+	"BEGIN:VALARM",
+	"ACTION:DISPLAY",
+	"TRIGGER:-PT15M",
+	"X-WR-ALARMUID:alarm-1",
+	"ACKNOWLEDGED:20300101T000000Z",
+	"BEGIN:X-SNOOZE",
+	"X-DEEP:1",
+	"END:X-SNOOZE",
+	"END:VALARM",
+	"BEGIN:VLOCATION",
+	"UID:loc-1",
+	"NAME:Room 4",
+	"END:VLOCATION",
+	"END:VEVENT",
+	"BEGIN:VJOURNAL",
+	"UID:journal-1",
+	"SUMMARY:Notes",
+	"END:VJOURNAL",
+	"BEGIN:X-CUSTOM",
+	"X-A:b",
+	"END:X-CUSTOM",
+	"END:VCALENDAR",
+}, "\r\n") + "\r\n"
+
+// foreignBlocks are runs of foreignDoc that every rewrite must keep, in order.
+var foreignBlocks = [][]string{
+	{"X-WR-CALNAME:Mine", "X-APPLE-CALENDAR-COLOR:#FF0000"},
+	{"BEGIN:VTODO", "UID:todo-1", "SUMMARY:Buy milk", "DUE:20300105T120000Z", "X-OTHER-TOOL;X-P=1:keep me", "END:VTODO"},
+	{"X-MICROSOFT-CDO-BUSYSTATUS:BUSY", `ATTENDEE;X-NUM-GUESTS=0;CN="Doe, J":mailto:j@example.com`},
+	{"X-WR-ALARMUID:alarm-1", "ACKNOWLEDGED:20300101T000000Z", "BEGIN:X-SNOOZE", "X-DEEP:1", "END:X-SNOOZE", "END:VALARM"},
+	{"BEGIN:VLOCATION", "UID:loc-1", "NAME:Room 4", "END:VLOCATION"},
+	{"BEGIN:VJOURNAL", "UID:journal-1", "SUMMARY:Notes", "END:VJOURNAL"},
+	{"BEGIN:X-CUSTOM", "X-A:b", "END:X-CUSTOM"},
+}
+
+func TestRewritesKeepUnmodelledContent(t *testing.T) {
+	SetClock(clock.Fixed(time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)))
+	t.Cleanup(func() { SetClock(nil) })
+
+	rewrites := map[string]func(string) (string, error){
+		"shift": func(data string) (string, error) {
+			out, _, err := ShiftICS(data, Shift{By: time.Hour}, nil)
+			return out, err
+		},
+		"shift to-tz": func(data string) (string, error) {
+			out, _, err := ShiftICS(data, Shift{ToTZ: "America/New_York", KeepInstant: true}, nil)
+			return out, err
+		},
+		"split": func(data string) (string, error) {
+			out, _, err := SplitICS(data, SeriesSplit{UID: "ev-1", At: time.Date(2030, 1, 15, 0, 0, 0, 0, time.UTC)})
+			return out, err
+		},
+		"transform": func(data string) (string, error) {
+			out, _, err := TransformICS(data, Transform{Summary: func(s string, _ []string) string { return "📅 " + s }})
+			return out, err
+		},
+		"dedupe": func(data string) (string, error) {
+			out, _, err := DedupeICS(data, DedupeOptions{ByUID: true, ByContent: true})
+			return out, err
+		},
+		"append": func(data string) (string, error) {
+			out, _, err := AppendICS(data, "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:new-1\r\nDTSTART:20300201T100000Z\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n")
+			return out, err
+		},
+	}
+	for name, rewrite := range rewrites {
+		out, err := rewrite(foreignDoc)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		lines := unfoldICS(out)
+		from := 0
+		for _, block := range foreignBlocks {
+			at := indexLines(lines[from:], block)
+			if at < 0 {
+				t.Errorf("%s lost or reordered %q:\n%s", name, block, out)
+				break
+			}
+			from += at + len(block)
+		}
+	}
+}
+
+func TestRewritesWithNothingToDoReturnTheInput(t *testing.T) {
+	long := "X-LONG-NOTE:" + strings.Repeat("0123456789", 12)
+	data := strings.Replace(foreignDoc, "X-A:b\r\n", "X-A:b\r\n"+long[:75]+"\r\n "+long[75:]+"\r\n", 1)
+
+	transformed, _, err := TransformICS(data, Transform{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deduped, _, err := DedupeICS(data, DedupeOptions{ByUID: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, out := range map[string]string{"transform": transformed, "dedupe": deduped} {
+		if out != data {
+			t.Errorf("%s changed a document it had nothing to do to:\n%s", name, out)
+		}
+	}
+}
+
+func TestAddEventPropsGoesBeforeComponents(t *testing.T) {
+	event := []string{"BEGIN:VEVENT", "UID:1", "BEGIN:VALARM", "TRIGGER:-PT5M", "END:VALARM", "X-AFTER:kept", "END:VEVENT"}
+	got := addEventProps(event, "SEQUENCE:1", "STATUS:CONFIRMED")
+	want := []string{"BEGIN:VEVENT", "UID:1", "SEQUENCE:1", "STATUS:CONFIRMED", "BEGIN:VALARM", "TRIGGER:-PT5M", "END:VALARM", "X-AFTER:kept", "END:VEVENT"}
+	if !slices.Equal(got, want) {
+		t.Errorf("addEventProps() = %q, want %q", got, want)
+	}
+
+	plain := addEventProps([]string{"BEGIN:VEVENT", "UID:1", "END:VEVENT"}, "SEQUENCE:1")
+	if !slices.Equal(plain, []string{"BEGIN:VEVENT", "UID:1", "SEQUENCE:1", "END:VEVENT"}) {
+		t.Errorf("addEventProps() = %q", plain)
+	}
+}
+
+func TestShiftAddsSequenceBeforeAlarms(t *testing.T) {
+	data := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:1\r\nDTSTART:20300110T100000Z\r\n" +
+		"BEGIN:VALARM\r\nTRIGGER:-PT5M\r\nEND:VALARM\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	out, _, err := ShiftICS(data, Shift{Days: 1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "SEQUENCE:1\r\nBEGIN:VALARM") {
+		t.Errorf("SEQUENCE should come before the alarm:\n%s", out)
+	}
+}
+
+func indexLines(lines, block []string) int {
+	for i := 0; i+len(block) <= len(lines); i++ {
+		if slices.Equal(lines[i:i+len(block)], block) {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestUnfoldICSLongFold(t *testing.T) {
+	const parts = 100000
+	data := "DESCRIPTION:x" + strings.Repeat("\r\n y", parts) + "\r\nSUMMARY:s\r\n"
+	lines := unfoldICS(data)
+	if len(lines) != 2 || len(lines[0]) != len("DESCRIPTION:x")+parts || lines[1] != "SUMMARY:s" {
+		t.Fatalf("unfoldICS gave %d lines, first of length %d", len(lines), len(lines[0]))
+	}
+}
+
+func FuzzSplitICSEvents(f *testing.F) {
+	f.Add(foreignDoc)
+	f.Add("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nSUMMARY;LANGUAGE=\"e:n\":a\r\n b\r\n\tc\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n")
+	f.Add("BEGIN:VEVENT\n")
+	f.Add(":\n \n\t\r\n;;;:\"")
+	f.Fuzz(func(t *testing.T, data string) {
+		for _, line := range unfoldICS(data) {
+			if strings.ContainsAny(line, "\n") {
+				t.Fatalf("unfolded line %q holds a line break", line)
+			}
+			p := parseICSLine(line)
+			_ = p.param("TZID")
+			_ = p.describe()
+		}
+		segments, err := splitICSEvents(data)
+		if err != nil {
+			return
+		}
+		for _, seg := range segments {
+			if len(seg.lines) == 0 {
+				t.Fatal("empty segment")
+			}
+		}
+		_ = writeICSSegments(segments)
+	})
+}
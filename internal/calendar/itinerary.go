@@ -0,0 +1,389 @@
+package calendar
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Itinerary item kinds.
+const (
+	ItineraryFlight = "flight"
+	ItineraryTrain  = "train"
+	ItineraryHotel  = "hotel"
+)
+
+// ItineraryItem is one booking found in confirmation text. Times are wall
+// clock times in the UTC location: Depart is local to From and Arrive local
+// to To, so the caller places them in the right zones. For hotels Depart is
+// the check-in and Arrive the check-out.
+type ItineraryItem struct {
+	Kind      string
+	Number    string // flight or train number, e.g. FR1234
+	From      string // airport code or station
+	To        string
+	Name      string // hotel name
+	Address   string
+	Depart    time.Time
+	Arrive    time.Time
+	NextDay   int // days to add to Arrive given as +1 in the text
+	Reference string
+}
+
+var (
+	itineraryFlightRe = regexp.MustCompile(`\b([A-Z][A-Z0-9]|[0-9][A-Z])\s?(\d{1,4})\b`)
+	itineraryTrainRe  = regexp.MustCompile(`(?i)\b(AVE|Avlo|Alvia|Iryo|Ouigo|TGV|ICE|IC|EC|RE|Eurostar|Frecciarossa|Italo|Amtrak|Acela|Railjet|RJ|Thalys|Train)\s*(?:no\.?|number|#)?\s*(\d{1,5})\b`)
+	itineraryHotelRe  = regexp.MustCompile(`(?i)\b(hotel|hostel|accommodation|apartment|lodging)\b`)
+	itineraryAirport  = regexp.MustCompile(`\b[A-Z]{3}\b`)
+	itineraryClockRe  = regexp.MustCompile(`(?i)\b([01]?\d|2[0-3])[:h]([0-5]\d)\s*(a\.?m\.?|p\.?m\.?)?(\s*\+\s*[12])?`)
+	itineraryRefRe    = regexp.MustCompile(`(?i)\b(?:booking|confirmation|reservation)\s*(?:ref(?:erence)?|code|number|no\.?)?\s*[:#]?\s*([A-Z0-9]{5,8})\b|\bPNR\s*[:#]?\s*([A-Z0-9]{5,8})\b`)
+	itineraryLabelRe  = regexp.MustCompile(`(?i)^\s*(hotel|property|address|check-?in|check-?out|from|to|departure|departs|depart|arrival|arrives|arrive)\s*[:\-]\s*(.+)$`)
+	itineraryRouteRe  = regexp.MustCompile(`(?im)\bfrom\s+(.+?)\s+to\s+(.+?)\s*(?:$|[,;(]|\bat\b|\bon\b|\d)`)
+	itineraryArrowRe  = regexp.MustCompile(`([^→>\d:]+?)\s*(?:→|->|⇒|>)\s*([^→>\d:]+)`)
+
+	itineraryMonths = map[string]time.Month{
+		"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+		"may": time.May, "jun": time.June, "jul": time.July, "aug": time.August,
+		"sep": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+	}
+	itineraryDateRes = []*regexp.Regexp{
+		regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})\b`),
+		regexp.MustCompile(`\b(\d{1,2})[/.](\d{1,2})[/.](\d{4})\b`),
+		regexp.MustCompile(`(?i)\b(\d{1,2})\s?(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?,?(?:\s?(\d{4})\b|(\d{2})\b)?`),
+		regexp.MustCompile(`(?i)\b(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?\s+(\d{1,2})(?:st|nd|rd|th)?\b,?(?:\s*(\d{4}))?`),
+	}
+	// itineraryNotAirports are three-letter capitals that are not airports.
+	itineraryNotAirports = map[string]bool{
+		"JAN": true, "FEB": true, "MAR": true, "APR": true, "MAY": true, "JUN": true, "JUL": true,
+		"AUG": true, "SEP": true, "OCT": true, "NOV": true, "DEC": true, "MON": true, "TUE": true,
+		"WED": true, "THU": true, "FRI": true, "SAT": true, "SUN": true, "PNR": true, "REF": true,
+		"UTC": true, "GMT": true, "THE": true, "AND": true, "FOR": true, "VIA": true, "AVE": true,
+		"ICE": true, "TGV": true, "ROW": true, "DEP": true, "ARR": true,
+	}
+)
+
+// itineraryDate is a date found in the text and where it starts.
+type itineraryDate struct {
+	pos  int
+	date time.Time
+}
+
+// ParseItinerary finds flights, trains and hotel stays in pasted booking
+// confirmations. It reads lines such as
+//
+//	FR1234 25DEC MAD DUB 08:30 10:00
+//	Flight IB 3166, Thu 25 Dec 2025, Madrid (MAD) 08:30 → New York (JFK) 11:05
+//	Train AVE 3071 from Madrid Atocha to Barcelona Sants, 2025-05-01 08:30-11:05
+//	Hotel: Dublin City Hotel / Check-in: 25 Dec 2025 14:00 / Check-out: 28 Dec 2025
+//
+// Dates are day first (25/12/2025); dates without a year fall on or after
+// ref. Bookings missing a date or time are left out and described in notes.
+func ParseItinerary(text string, ref time.Time) (items []ItineraryItem, notes []string) {
+	reference := ""
+	if m := itineraryRefRe.FindStringSubmatch(text); m != nil {
+		reference = firstNonBlank(m[1], m[2])
+	}
+
+	var segments [][]string
+	var kinds []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		kind := itineraryLineKind(line)
+		current := ""
+		if len(kinds) > 0 {
+			current = kinds[len(kinds)-1]
+		}
+		// Hotel details (check-in, address) continue the stay they belong to.
+		if kind == ItineraryHotel && current == ItineraryHotel && !hotelSegmentDone(segments[len(segments)-1]) {
+			kind = ""
+		}
+		if kind != "" {
+			segments = append(segments, nil)
+			kinds = append(kinds, kind)
+		}
+		if len(segments) > 0 {
+			segments[len(segments)-1] = append(segments[len(segments)-1], line)
+		}
+	}
+
+	for i, lines := range segments {
+		item, err := parseItinerarySegment(kinds[i], lines, ref)
+		if err != nil {
+			notes = append(notes, err.Error())
+			continue
+		}
+		if item.Reference == "" {
+			item.Reference = reference
+		}
+		items = append(items, item)
+	}
+	return items, notes
+}
+
+// itineraryLineKind tells whether a line starts a booking, and which.
+func itineraryLineKind(line string) string {
+	lower := strings.ToLower(line)
+	if m := itineraryTrainRe.FindStringIndex(line); m != nil && (strings.Contains(lower, "train") || strings.TrimSpace(line[:m[0]]) == "") {
+		return ItineraryTrain
+	}
+	if m := itineraryFlightRe.FindStringSubmatchIndex(line); m != nil && !strings.HasPrefix(line[m[1]:], ":") {
+		if strings.Contains(lower, "flight") || strings.TrimSpace(line[:m[0]]) == "" {
+			return ItineraryFlight
+		}
+	}
+	if itineraryHotelRe.MatchString(line) {
+		return ItineraryHotel
+	}
+	// A dated check-in line starts a stay too; "online check-in opens" in a
+	// flight booking does not.
+	if strings.Contains(strings.ReplaceAll(lower, "-", ""), "checkin") {
+		for _, re := range itineraryDateRes {
+			if re.MatchString(line) {
+				return ItineraryHotel
+			}
+		}
+	}
+	return ""
+}
+
+func hotelSegmentDone(lines []string) bool {
+	for _, line := range lines {
+		if strings.Contains(strings.ReplaceAll(strings.ToLower(line), "-", ""), "checkout") {
+			return true
+		}
+	}
+	return false
+}
+
+func parseItinerarySegment(kind string, lines []string, ref time.Time) (ItineraryItem, error) {
+	text := strings.Join(lines, "\n")
+	item := ItineraryItem{Kind: kind}
+	if m := itineraryRefRe.FindStringSubmatch(text); m != nil {
+		item.Reference = firstNonBlank(m[1], m[2])
+	}
+	labels := map[string]string{}
+	for _, line := range lines {
+		if m := itineraryLabelRe.FindStringSubmatch(line); m != nil {
+			key := strings.ReplaceAll(strings.ToLower(m[1]), "-", "")
+			if _, seen := labels[key]; !seen {
+				labels[key] = strings.TrimSpace(m[2])
+			}
+		}
+	}
+
+	if kind == ItineraryHotel {
+		return parseHotelSegment(item, lines, labels, ref)
+	}
+
+	switch kind {
+	case ItineraryFlight:
+		for _, line := range lines {
+			if m := itineraryFlightRe.FindStringSubmatch(line); m != nil {
+				item.Number = m[1] + m[2]
+				break
+			}
+		}
+		var codes []string
+		for _, code := range itineraryAirport.FindAllString(text, -1) {
+			if !itineraryNotAirports[code] && (len(codes) == 0 || codes[len(codes)-1] != code) {
+				codes = append(codes, code)
+			}
+		}
+		if len(codes) < 2 {
+			return item, fmt.Errorf("flight %s: could not find the departure and arrival airport codes", item.Number)
+		}
+		item.From, item.To = codes[0], codes[1]
+	case ItineraryTrain:
+		if m := itineraryTrainRe.FindStringSubmatch(text); m != nil {
+			item.Number = strings.TrimSpace(m[1] + " " + m[2])
+			if strings.EqualFold(m[1], "train") {
+				item.Number = m[2]
+			}
+		}
+		item.From, item.To = trainStations(text, labels)
+	}
+
+	dates := findItineraryDates(text, ref)
+	clocks := itineraryClockRe.FindAllStringSubmatch(text, -1)
+	label := strings.TrimSpace(kind + " " + item.Number)
+	if len(dates) == 0 {
+		return item, fmt.Errorf("%s: no travel date found", label)
+	}
+	if len(clocks) < 2 {
+		return item, fmt.Errorf("%s: needs departure and arrival times", label)
+	}
+	depart, arrive := itineraryClock(clocks[0]), itineraryClock(clocks[1])
+	item.Depart = dates[0].date.Add(depart)
+	arriveDay := dates[0].date
+	if len(dates) > 1 {
+		arriveDay = dates[1].date
+	}
+	item.Arrive = arriveDay.Add(arrive)
+	if plus := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(clocks[1][4]), "+")); plus != "" {
+		item.NextDay, _ = strconv.Atoi(plus)
+	}
+	return item, nil
+}
+
+func parseHotelSegment(item ItineraryItem, lines []string, labels map[string]string, ref time.Time) (ItineraryItem, error) {
+	item.Name = firstNonBlank(labels["hotel"], labels["property"])
+	if item.Name == "" {
+		for _, line := range lines {
+			if itineraryHotelRe.MatchString(line) {
+				item.Name = strings.Trim(strings.TrimSpace(line), ":-")
+				break
+			}
+		}
+	}
+	item.Address = labels["address"]
+
+	stay := func(key string, defaultClock time.Duration) (time.Time, error) {
+		value := labels[key]
+		if value == "" {
+			for _, line := range lines {
+				if strings.Contains(strings.ReplaceAll(strings.ToLower(line), "-", ""), key) {
+					value = line
+					break
+				}
+			}
+		}
+		dates := findItineraryDates(value, ref)
+		if len(dates) == 0 {
+			return time.Time{}, fmt.Errorf("hotel %s: no %s date found", item.Name, key)
+		}
+		if m := itineraryClockRe.FindStringSubmatch(value); m != nil {
+			return dates[0].date.Add(itineraryClock(m)), nil
+		}
+		return dates[0].date.Add(defaultClock), nil
+	}
+	var err error
+	if item.Depart, err = stay("checkin", 15*time.Hour); err != nil {
+		return item, err
+	}
+	if item.Arrive, err = stay("checkout", 11*time.Hour); err != nil {
+		return item, err
+	}
+	if !item.Arrive.After(item.Depart) {
+		return item, fmt.Errorf("hotel %s: check-out is not after check-in", item.Name)
+	}
+	return item, nil
+}
+
+// trainStations finds where a train runs: "from X to Y", "X → Y" or
+// Departure:/Arrival: lines holding a time and a station.
+func trainStations(text string, labels map[string]string) (string, string) {
+	if m := itineraryRouteRe.FindStringSubmatch(text); m != nil {
+		return strings.TrimSpace(m[1]), strings.TrimSpace(m[2])
+	}
+	from := firstNonBlank(labels["departure"], labels["departs"], labels["depart"], labels["from"])
+	to := firstNonBlank(labels["arrival"], labels["arrives"], labels["arrive"], labels["to"])
+	if from != "" && to != "" {
+		clean := func(s string) string {
+			s = itineraryClockRe.ReplaceAllString(s, "")
+			for _, re := range itineraryDateRes {
+				s = re.ReplaceAllString(s, "")
+			}
+			return strings.Trim(strings.TrimSpace(s), ",-–")
+		}
+		return clean(from), clean(to)
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if m := itineraryArrowRe.FindStringSubmatch(line); m != nil {
+			return strings.Trim(strings.TrimSpace(m[1]), ",-–"), strings.Trim(strings.TrimSpace(m[2]), ",-–")
+		}
+	}
+	return "", ""
+}
+
+// findItineraryDates returns the dates in text in order of appearance, at
+// midnight UTC.
+func findItineraryDates(text string, ref time.Time) []itineraryDate {
+	var found []itineraryDate
+	taken := make([]bool, len(text)+1)
+	for i, re := range itineraryDateRes {
+		for _, m := range re.FindAllStringSubmatchIndex(text, -1) {
+			if taken[m[0]] {
+				continue
+			}
+			part := func(n int) string {
+				if m[2*n] < 0 {
+					return ""
+				}
+				return text[m[2*n]:m[2*n+1]]
+			}
+			var y, d int
+			var month time.Month
+			switch i {
+			case 0:
+				y, _ = strconv.Atoi(part(1))
+				mo, _ := strconv.Atoi(part(2))
+				month, d = time.Month(mo), atoiOrZero(part(3))
+			case 1:
+				d, y = atoiOrZero(part(1)), atoiOrZero(part(3))
+				month = time.Month(atoiOrZero(part(2)))
+			case 2:
+				d, month, y = atoiOrZero(part(1)), itineraryMonths[strings.ToLower(part(2))], atoiOrZero(firstNonBlank(part(3), part(4)))
+			case 3:
+				month, d, y = itineraryMonths[strings.ToLower(part(1))], atoiOrZero(part(2)), atoiOrZero(part(3))
+			}
+			if y > 0 && y < 100 {
+				y += 2000
+			}
+			if month < time.January || month > time.December || d < 1 || d > 31 {
+				continue
+			}
+			if y == 0 {
+				y = ref.Year()
+				if time.Date(y, month, d, 0, 0, 0, 0, time.UTC).Before(time.Date(ref.Year(), ref.Month(), ref.Day(), 0, 0, 0, 0, time.UTC)) {
+					y++
+				}
+			}
+			date := time.Date(y, month, d, 0, 0, 0, 0, time.UTC)
+			if date.Day() != d {
+				continue // e.g. 31/02
+			}
+			for p := m[0]; p < m[1]; p++ {
+				taken[p] = true
+			}
+			found = append(found, itineraryDate{pos: m[0], date: date})
+		}
+	}
+	sort.Slice(found, func(a, b int) bool { return found[a].pos < found[b].pos })
+	return found
+}
+
+// itineraryClock turns a clock match (hour, minute, am/pm) into a time of day.
+func itineraryClock(m []string) time.Duration {
+	h, _ := strconv.Atoi(m[1])
+	min, _ := strconv.Atoi(m[2])
+	switch strings.ToLower(strings.ReplaceAll(m[3], ".", "")) {
+	case "pm":
+		if h < 12 {
+			h += 12
+		}
+	case "am":
+		if h == 12 {
+			h = 0
+		}
+	}
+	return time.Duration(h)*time.Hour + time.Duration(min)*time.Minute
+}
+
+func atoiOrZero(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func firstNonBlank(values ...string) string {
+	for _, v := range values {
+		if s := strings.TrimSpace(v); s != "" {
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseItineraryFlights(t *testing.T) {
+	text := `Booking reference: ABC123
+FR1234 25DEC25 MAD DUB 08:30 10:00
+Flight IB 6251, Fri 26 Dec 2025, Madrid (MAD) 11:50 → New York (JFK) 14:35
+Flight UA960 Dec 30, 2025 JFK MAD 6:10pm 7:40am+1`
+	items, notes := ParseItinerary(text, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	if len(notes) > 0 {
+		t.Fatalf("unexpected notes: %v", notes)
+	}
+	if len(items) != 3 {
+		t.Fatalf("expected 3 flights, got %+v", items)
+	}
+	want := []struct {
+		number, from, to, depart, arrive string
+		nextDay                          int
+	}{
+		{"FR1234", "MAD", "DUB", "2025-12-25 08:30", "2025-12-25 10:00", 0},
+		{"IB6251", "MAD", "JFK", "2025-12-26 11:50", "2025-12-26 14:35", 0},
+		{"UA960", "JFK", "MAD", "2025-12-30 18:10", "2025-12-30 07:40", 1},
+	}
+	for i, w := range want {
+		got := items[i]
+		if got.Kind != ItineraryFlight || got.Number != w.number || got.From != w.from || got.To != w.to ||
+			got.Depart.Format("2006-01-02 15:04") != w.depart || got.Arrive.Format("2006-01-02 15:04") != w.arrive ||
+			got.NextDay != w.nextDay || got.Reference != "ABC123" {
+			t.Errorf("flight %d = %+v, want %+v", i, got, w)
+		}
+	}
+}
+
+func TestParseItineraryHotelAndTrain(t *testing.T) {
+	text := `Hotel: Dublin City Hotel
+Address: 123 O'Connell Street
+Check-in: 25/12/2025 14:00
+Check-out: 28/12/2025
+
+Train AVE 3071 from Madrid Atocha to Barcelona Sants, 2025-05-01 08:30-11:05`
+	items, notes := ParseItinerary(text, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	if len(notes) > 0 || len(items) != 2 {
+		t.Fatalf("expected a hotel and a train, got %+v (notes %v)", items, notes)
+	}
+	hotel := items[0]
+	if hotel.Kind != ItineraryHotel || hotel.Name != "Dublin City Hotel" || hotel.Address != "123 O'Connell Street" ||
+		hotel.Depart.Format("2006-01-02 15:04") != "2025-12-25 14:00" || hotel.Arrive.Format("2006-01-02 15:04") != "2025-12-28 11:00" {
+		t.Errorf("hotel = %+v", hotel)
+	}
+	train := items[1]
+	if train.Kind != ItineraryTrain || train.Number != "AVE 3071" || train.From != "Madrid Atocha" || train.To != "Barcelona Sants" ||
+		train.Depart.Format("2006-01-02 15:04") != "2025-05-01 08:30" || train.Arrive.Format("15:04") != "11:05" {
+		t.Errorf("train = %+v", train)
+	}
+}
+
+func TestParseItineraryNotes(t *testing.T) {
+	items, notes := ParseItinerary("Flight FR1234 MAD DUB 08:30 10:00\nOnline check-in opens 24h before", time.Now())
+	if len(items) != 0 || len(notes) != 1 {
+		t.Fatalf("expected a note for the undated flight, got items %+v notes %v", items, notes)
+	}
+}
+
+func TestFindItineraryDatesWithoutYear(t *testing.T) {
+	ref := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	dates := findItineraryDates("out 25 Dec, back 3 Jan", ref)
+	if len(dates) != 2 || dates[0].date.Format("2006-01-02") != "2025-12-25" || dates[1].date.Format("2006-01-02") != "2026-01-03" {
+		t.Errorf("findItineraryDates = %+v", dates)
+	}
+}
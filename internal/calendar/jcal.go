@@ -0,0 +1,432 @@
+package calendar
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jCal (RFC 7265) and xCal (RFC 6321) serializers. Both are built from the
+// same component tree as the .ics text, so every property tempus writes (and
+// any it reads from another producer, for export) keeps its value type.
+
+const xcalNamespace = "urn:ietf:params:xml:ns:icalendar-2.0"
+
+// ToJCal renders the calendar as jCal JSON.
+func (c *Calendar) ToJCal() ([]byte, error) {
+	return ICSToJCal(c.ToICS())
+}
+
+// ToXCal renders the calendar as an xCal XML document.
+func (c *Calendar) ToXCal() ([]byte, error) {
+	return ICSToXCal(c.ToICS())
+}
+
+// ICSToJCal converts an .ics document to jCal JSON.
+func ICSToJCal(data string) ([]byte, error) {
+	root, err := parseComponentTree(data)
+	if err != nil {
+		return nil, err
+	}
+	out, err := json.MarshalIndent(root.jcal(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// ICSToXCal converts an .ics document to an xCal XML document.
+func ICSToXCal(data string) ([]byte, error) {
+	root, err := parseComponentTree(data)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<icalendar xmlns="` + xcalNamespace + `">` + "\n")
+	root.writeXCal(&b, 1)
+	b.WriteString("</icalendar>\n")
+	return b.Bytes(), nil
+}
+
+type calComponent struct {
+	name  string
+	props []calProperty
+	comps []*calComponent
+}
+
+type calProperty struct {
+	name   string
+	params []icsLine // name + value, reusing the content-line type
+	typ    string
+	values []any // string, int, float64, []float64 or recurValue
+}
+
+func parseComponentTree(data string) (*calComponent, error) {
+	var root *calComponent
+	var stack []*calComponent
+	for _, line := range unfoldICS(data) {
+		p := parseICSLine(line)
+		switch p.name {
+		case "BEGIN":
+			comp := &calComponent{name: strings.ToLower(strings.TrimSpace(p.value))}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.comps = append(parent.comps, comp)
+			} else if root == nil {
+				root = comp
+			} else {
+				return nil, fmt.Errorf("unexpected BEGIN:%s after the calendar ended", p.value)
+			}
+			stack = append(stack, comp)
+		case "END":
+			name := strings.ToLower(strings.TrimSpace(p.value))
+			if len(stack) == 0 || stack[len(stack)-1].name != name {
+				return nil, fmt.Errorf("unexpected END:%s", p.value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				continue // stray lines outside VCALENDAR
+			}
+			comp := stack[len(stack)-1]
+			comp.props = append(comp.props, convertProperty(p))
+		}
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("unterminated %s", strings.ToUpper(stack[len(stack)-1].name))
+	}
+	if root == nil || root.name != "vcalendar" {
+		return nil, fmt.Errorf("no VCALENDAR found")
+	}
+	return root, nil
+}
+
+// propertyTypes are the RFC 5545 default value types tempus converts; other
+// IANA properties are text and unrecognised X- properties are "unknown".
+var propertyTypes = map[string]string{
+	"DTSTART": "date-time", "DTEND": "date-time", "DTSTAMP": "date-time",
+	"CREATED": "date-time", "LAST-MODIFIED": "date-time", "RECURRENCE-ID": "date-time",
+	"DUE": "date-time", "COMPLETED": "date-time", "EXDATE": "date-time", "RDATE": "date-time",
+	"DURATION": "duration", "TRIGGER": "duration", "REFRESH-INTERVAL": "duration",
+	"RRULE": "recur", "EXRULE": "recur",
+	"TZOFFSETFROM": "utc-offset", "TZOFFSETTO": "utc-offset",
+	"SEQUENCE": "integer", "PRIORITY": "integer", "REPEAT": "integer", "PERCENT-COMPLETE": "integer",
+	"URL": "uri", "TZURL": "uri", "ATTACH": "uri", "SOURCE": "uri",
+	"ORGANIZER": "cal-address", "ATTENDEE": "cal-address",
+	"GEO": "float",
+	// Widely used extensions whose values are plain text.
+	"X-WR-CALNAME": "text", "X-WR-CALDESC": "text", "X-WR-TIMEZONE": "text", "X-PUBLISHED-TTL": "duration",
+}
+
+// multiValued properties hold comma-separated lists.
+var multiValued = map[string]bool{
+	"CATEGORIES": true, "RESOURCES": true, "EXDATE": true, "RDATE": true,
+}
+
+func convertProperty(p icsLine) calProperty {
+	prop := calProperty{name: strings.ToLower(p.name)}
+	typ := propertyTypes[p.name]
+	if typ == "" {
+		typ = "text"
+		if strings.HasPrefix(p.name, "X-") {
+			typ = "unknown"
+		}
+	}
+	for _, kv := range p.params {
+		k, v, _ := strings.Cut(kv, "=")
+		if strings.EqualFold(k, "VALUE") {
+			typ = strings.ToLower(strings.Trim(v, `"`))
+			continue
+		}
+		prop.params = append(prop.params, icsLine{name: strings.ToLower(k), value: strings.Trim(v, `"`)})
+	}
+
+	raw := []string{p.value}
+	if multiValued[p.name] {
+		raw = splitUnescaped(p.value, ',')
+	}
+	if typ == "date-time" && len(strings.TrimSpace(raw[0])) == 8 {
+		typ = "date" // DATE values written without VALUE=DATE
+	}
+	prop.typ = typ
+	for _, r := range raw {
+		value, ok := convertValue(typ, strings.TrimSpace(r))
+		if !ok {
+			// Not what the type promises: keep the text rather than fail the export.
+			prop.typ, prop.values = "unknown", []any{p.value}
+			return prop
+		}
+		prop.values = append(prop.values, value)
+	}
+	return prop
+}
+
+func convertValue(typ, raw string) (any, bool) {
+	switch typ {
+	case "date":
+		return formatCalDate(raw)
+	case "date-time":
+		return formatCalDateTime(raw)
+	case "utc-offset":
+		return formatUTCOffset(raw)
+	case "integer":
+		n, err := strconv.Atoi(raw)
+		return n, err == nil
+	case "float":
+		var fs []float64
+		for _, part := range strings.Split(raw, ";") {
+			f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil {
+				return nil, false
+			}
+			fs = append(fs, f)
+		}
+		if len(fs) == 1 {
+			return fs[0], true
+		}
+		return fs, true
+	case "recur":
+		return parseRecurValue(raw)
+	case "text":
+		return unescapeText(raw), true
+	default:
+		return raw, true
+	}
+}
+
+// formatCalDate turns 20250301 into 2025-03-01.
+func formatCalDate(raw string) (string, bool) {
+	if len(raw) != 8 || !isDigits(raw) {
+		return "", false
+	}
+	return raw[:4] + "-" + raw[4:6] + "-" + raw[6:], true
+}
+
+// formatCalDateTime turns 20250301T090000[Z] into 2025-03-01T09:00:00[Z].
+func formatCalDateTime(raw string) (string, bool) {
+	utc := strings.HasSuffix(raw, "Z")
+	v := strings.TrimSuffix(raw, "Z")
+	if len(v) != 15 || v[8] != 'T' || !isDigits(v[:8]) || !isDigits(v[9:]) {
+		return "", false
+	}
+	date, _ := formatCalDate(v[:8])
+	out := date + "T" + v[9:11] + ":" + v[11:13] + ":" + v[13:15]
+	if utc {
+		out += "Z"
+	}
+	return out, true
+}
+
+// formatUTCOffset turns +0100 into +01:00 (and +013000 into +01:30:00).
+func formatUTCOffset(raw string) (string, bool) {
+	if len(raw) != 5 && len(raw) != 7 || (raw[0] != '+' && raw[0] != '-') || !isDigits(raw[1:]) {
+		return "", false
+	}
+	out := raw[:3] + ":" + raw[3:5]
+	if len(raw) == 7 {
+		out += ":" + raw[5:]
+	}
+	return out, true
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// splitUnescaped splits s on sep, ignoring backslash-escaped separators.
+func splitUnescaped(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// recurValue is an RRULE as ordered parts ("freq" first), so xCal can emit the
+// elements in schema order and jCal can render them as an object.
+type recurValue []recurPart
+
+type recurPart struct {
+	key    string
+	values []string
+}
+
+// recurOrder is the element order RFC 6321 requires inside <recur>.
+var recurOrder = []string{
+	"freq", "until", "count", "interval", "bysecond", "byminute", "byhour", "byday",
+	"bymonthday", "byyearday", "byweekno", "bymonth", "bysetpos", "wkst",
+}
+
+var recurNumeric = map[string]bool{
+	"count": true, "interval": true, "bysecond": true, "byminute": true, "byhour": true,
+	"bymonthday": true, "byyearday": true, "byweekno": true, "bymonth": true, "bysetpos": true,
+}
+
+func parseRecurValue(raw string) (recurValue, bool) {
+	var rv recurValue
+	for _, part := range strings.Split(raw, ";") {
+		k, v, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, false
+		}
+		key := strings.ToLower(strings.TrimSpace(k))
+		values := strings.Split(strings.TrimSpace(v), ",")
+		if key == "until" {
+			until, ok := formatCalDate(values[0])
+			if !ok {
+				if until, ok = formatCalDateTime(values[0]); !ok {
+					return nil, false
+				}
+			}
+			values = []string{until}
+		}
+		rv = append(rv, recurPart{key: key, values: values})
+	}
+	if len(rv) == 0 {
+		return nil, false
+	}
+	sort.SliceStable(rv, func(i, j int) bool { return recurRank(rv[i].key) < recurRank(rv[j].key) })
+	return rv, true
+}
+
+func recurRank(key string) int {
+	for i, k := range recurOrder {
+		if k == key {
+			return i
+		}
+	}
+	return len(recurOrder)
+}
+
+func recurScalar(key, v string) any {
+	if recurNumeric[key] {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return v
+}
+
+// MarshalJSON renders the rule as a jCal recur object; list parts become arrays.
+func (rv recurValue) MarshalJSON() ([]byte, error) {
+	obj := make(map[string]any, len(rv))
+	for _, part := range rv {
+		if len(part.values) == 1 {
+			obj[part.key] = recurScalar(part.key, part.values[0])
+			continue
+		}
+		list := make([]any, len(part.values))
+		for i, v := range part.values {
+			list[i] = recurScalar(part.key, v)
+		}
+		obj[part.key] = list
+	}
+	return json.Marshal(obj)
+}
+
+func (c *calComponent) jcal() []any {
+	props := make([]any, 0, len(c.props))
+	for _, p := range c.props {
+		params := make(map[string]any, len(p.params))
+		for _, param := range p.params {
+			params[param.name] = param.value
+		}
+		props = append(props, append([]any{p.name, params, p.typ}, p.values...))
+	}
+	comps := make([]any, 0, len(c.comps))
+	for _, sub := range c.comps {
+		comps = append(comps, sub.jcal())
+	}
+	return []any{c.name, props, comps}
+}
+
+// xcalParamTypes lists the parameters whose xCal value element is not <text>.
+var xcalParamTypes = map[string]string{
+	"altrep": "uri", "dir": "uri",
+	"delegated-from": "cal-address", "delegated-to": "cal-address",
+	"member": "cal-address", "sent-by": "cal-address",
+}
+
+func (c *calComponent) writeXCal(b *bytes.Buffer, depth int) {
+	indent := strings.Repeat("  ", depth)
+	b.WriteString(indent + "<" + c.name + ">\n")
+	if len(c.props) > 0 {
+		b.WriteString(indent + "  <properties>\n")
+		for _, p := range c.props {
+			p.writeXCal(b, indent+"    ")
+		}
+		b.WriteString(indent + "  </properties>\n")
+	}
+	if len(c.comps) > 0 {
+		b.WriteString(indent + "  <components>\n")
+		for _, sub := range c.comps {
+			sub.writeXCal(b, depth+2)
+		}
+		b.WriteString(indent + "  </components>\n")
+	}
+	b.WriteString(indent + "</" + c.name + ">\n")
+}
+
+func (p calProperty) writeXCal(b *bytes.Buffer, indent string) {
+	b.WriteString(indent + "<" + p.name + ">")
+	if len(p.params) > 0 {
+		b.WriteString("<parameters>")
+		for _, param := range p.params {
+			typ := xcalParamTypes[param.name]
+			if typ == "" {
+				typ = "text"
+			}
+			b.WriteString("<" + param.name + ">")
+			writeXMLElement(b, typ, param.value)
+			b.WriteString("</" + param.name + ">")
+		}
+		b.WriteString("</parameters>")
+	}
+	for _, v := range p.values {
+		switch v := v.(type) {
+		case recurValue:
+			b.WriteString("<recur>")
+			for _, part := range v {
+				for _, pv := range part.values {
+					writeXMLElement(b, part.key, pv)
+				}
+			}
+			b.WriteString("</recur>")
+		case []float64:
+			if p.name == "geo" && len(v) == 2 {
+				b.WriteString("<latitude>" + strconv.FormatFloat(v[0], 'f', -1, 64) + "</latitude>")
+				b.WriteString("<longitude>" + strconv.FormatFloat(v[1], 'f', -1, 64) + "</longitude>")
+				continue
+			}
+			for _, f := range v {
+				writeXMLElement(b, p.typ, strconv.FormatFloat(f, 'f', -1, 64))
+			}
+		default:
+			writeXMLElement(b, p.typ, fmt.Sprint(v))
+		}
+	}
+	b.WriteString("</" + p.name + ">\n")
+}
+
+func writeXMLElement(b *bytes.Buffer, name, value string) {
+	b.WriteString("<" + name + ">")
+	_ = xml.EscapeText(b, []byte(value))
+	b.WriteString("</" + name + ">")
+}
@@ -0,0 +1,120 @@
+package calendar
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func jcalTestCalendar() *Calendar {
+	cal := NewCalendar()
+	cal.Name = "Team"
+	start := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	ev := NewEvent("Standup, daily", start, start.Add(15*time.Minute))
+	ev.UID = "standup@test"
+	ev.Created = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	ev.LastMod = ev.Created
+	ev.SetTimezone("Europe/Madrid")
+	ev.RRule = "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10"
+	ev.Categories = []string{"Work", "Team"}
+	ev.Priority = 5
+	ev.Alarms = []Alarm{{Action: "DISPLAY", TriggerIsRelative: true, TriggerDuration: -10 * time.Minute}}
+	cal.AddEvent(ev)
+	return cal
+}
+
+func TestToJCal(t *testing.T) {
+	out, err := jcalTestCalendar().ToJCal()
+	if err != nil {
+		t.Fatalf("ToJCal() failed: %v", err)
+	}
+	var doc []any
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if doc[0] != "vcalendar" {
+		t.Fatalf("root = %v, want vcalendar", doc[0])
+	}
+	event := doc[2].([]any)[0].([]any)
+	if event[0] != "vevent" {
+		t.Fatalf("component = %v, want vevent", event[0])
+	}
+	props := map[string][]any{}
+	for _, p := range event[1].([]any) {
+		prop := p.([]any)
+		props[prop[0].(string)] = prop
+	}
+
+	tests := map[string][]any{
+		"summary":    {"summary", map[string]any{}, "text", "Standup, daily"},
+		"dtstart":    {"dtstart", map[string]any{"tzid": "Europe/Madrid"}, "date-time", "2025-03-03T09:00:00"},
+		"categories": {"categories", map[string]any{}, "text", "Work", "Team"},
+		"priority":   {"priority", map[string]any{}, "integer", float64(5)},
+		"rrule": {"rrule", map[string]any{}, "recur", map[string]any{
+			"freq": "WEEKLY", "byday": []any{"MO", "WE"}, "count": float64(10),
+		}},
+	}
+	for name, want := range tests {
+		if got := props[name]; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %#v, want %#v", name, got, want)
+		}
+	}
+	alarm := event[2].([]any)[0].([]any)
+	if alarm[0] != "valarm" || !strings.Contains(string(out), `"-PT10M"`) {
+		t.Errorf("expected VALARM with duration trigger:\n%s", out)
+	}
+}
+
+func TestToXCal(t *testing.T) {
+	out, err := jcalTestCalendar().ToXCal()
+	if err != nil {
+		t.Fatalf("ToXCal() failed: %v", err)
+	}
+	if err := xml.Unmarshal(out, new(any)); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, out)
+	}
+	for _, want := range []string{
+		`<icalendar xmlns="urn:ietf:params:xml:ns:icalendar-2.0">`,
+		"<summary><text>Standup, daily</text></summary>",
+		"<dtstart><parameters><tzid><text>Europe/Madrid</text></tzid></parameters><date-time>2025-03-03T09:00:00</date-time></dtstart>",
+		"<rrule><recur><freq>WEEKLY</freq><count>10</count><byday>MO</byday><byday>WE</byday></recur></rrule>",
+		"<categories><text>Work</text><text>Team</text></categories>",
+		"<x-wr-calname><text>Team</text></x-wr-calname>",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("xCal missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestICSToJCalConvertsTimezonesAndDates(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+		"BEGIN:VTIMEZONE\r\nTZID:Europe/Madrid\r\nBEGIN:STANDARD\r\nDTSTART:19701025T030000\r\n" +
+		"TZOFFSETFROM:+0200\r\nTZOFFSETTO:+0100\r\nEND:STANDARD\r\nEND:VTIMEZONE\r\n" +
+		"BEGIN:VEVENT\r\nUID:1\r\nDTSTART;VALUE=DATE:20250301\r\nEXDATE:20250308T090000Z,20250315T090000Z\r\n" +
+		"X-CUSTOM;FOO=bar:raw\\,value\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	out, err := ICSToJCal(ics)
+	if err != nil {
+		t.Fatalf("ICSToJCal() failed: %v", err)
+	}
+	for _, want := range []string{
+		`"utc-offset",` + "\n" + `              "+01:00"`,
+		`"date",` + "\n" + `          "2025-03-01"`,
+		`"2025-03-08T09:00:00Z",` + "\n" + `          "2025-03-15T09:00:00Z"`,
+		`"unknown",` + "\n" + `          "raw\\,value"`,
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("jCal missing %q:\n%s", want, out)
+		}
+	}
+
+	if _, err := ICSToJCal("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VCALENDAR\r\n"); err == nil {
+		t.Error("expected error for mismatched END")
+	}
+	if _, err := ICSToXCal("not a calendar"); err == nil {
+		t.Error("expected error without VCALENDAR")
+	}
+}
@@ -0,0 +1,162 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DoseStep is one step of a titration or taper: Dose taken every day for
+// Days days. Days is 0 for a last step that runs until the plan ends.
+type DoseStep struct {
+	Dose string
+	Days int
+}
+
+// ParseDoseSteps parses a dose schedule such as "10mg:1w, 20mg:2w, 30mg":
+// comma-separated DOSE[:LENGTH] steps taken one after another, with lengths
+// in days or weeks. Only the last step may leave its length out.
+func ParseDoseSteps(spec string) ([]DoseStep, error) {
+	var steps []DoseStep
+	parts := strings.Split(spec, ",")
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		dose, length, hasLength := strings.Cut(part, ":")
+		dose = strings.TrimSpace(dose)
+		if dose == "" {
+			return nil, fmt.Errorf("step %d has no dose", i+1)
+		}
+		step := DoseStep{Dose: dose}
+		if hasLength {
+			d, err := ParseHumanDuration(length)
+			if err != nil || d <= 0 || d%(24*time.Hour) != 0 {
+				return nil, fmt.Errorf("step %d (%s): invalid length %q (use days or weeks, e.g. 5d or 2w)", i+1, dose, strings.TrimSpace(length))
+			}
+			step.Days = int(d / (24 * time.Hour))
+		} else if i < len(parts)-1 {
+			return nil, fmt.Errorf("step %d (%s) needs a length, e.g. %s:1w; only the last step can run open-ended", i+1, dose, dose)
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// ParseWeekdays parses weekday names and groups as used by working_hours
+// ("sat,sun", "weekend", "mon/wed", "tue+thu").
+func ParseWeekdays(spec string) ([]time.Weekday, error) {
+	seen := map[time.Weekday]bool{}
+	var days []time.Weekday
+	for _, name := range strings.FieldsFunc(strings.ToLower(spec), func(r rune) bool { return r == ',' || r == ' ' || r == '|' || r == '/' || r == '+' }) {
+		group, ok := hoursDayGroups[name]
+		if !ok {
+			day, ok := hoursDayNames[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown weekday %q", name)
+			}
+			group = []time.Weekday{day}
+		}
+		for _, d := range group {
+			if !seen[d] {
+				seen[d] = true
+				days = append(days, d)
+			}
+		}
+	}
+	return days, nil
+}
+
+// MedicationPlan lays a dose schedule out day by day from Start.
+type MedicationPlan struct {
+	Start        time.Time // first day; only its date in Location counts
+	Location     *time.Location
+	Steps        []DoseStep
+	Days         int      // total length; 0 means the sum of the step lengths
+	Times        []string // HH:MM doses per day
+	SkipDates    []time.Time
+	SkipWeekdays []time.Weekday
+}
+
+// DoseSeries is one step taken at one time of day: a daily series of Count
+// doses from First, except on the Skipped days.
+type DoseSeries struct {
+	Step     int // 1-based index into the plan's steps
+	Dose     string
+	Time     string
+	First    time.Time
+	Count    int
+	FirstDay int // 1-based day of the plan the step starts on
+	Skipped  []time.Time
+}
+
+// Series lays the plan out as one daily series per step and time of day.
+// The last step runs until the plan ends, and steps past the end are dropped.
+func (p MedicationPlan) Series() ([]DoseSeries, error) {
+	if len(p.Steps) == 0 {
+		return nil, fmt.Errorf("no dose steps")
+	}
+	if len(p.Times) == 0 {
+		return nil, fmt.Errorf("no times of day")
+	}
+	loc := p.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	clocks := make([]int, len(p.Times))
+	for i, t := range p.Times {
+		m, err := parseClockMinutes(t)
+		if err != nil {
+			return nil, err
+		}
+		clocks[i] = m
+	}
+
+	total := p.Days
+	if total < 0 {
+		return nil, fmt.Errorf("the total duration cannot be negative")
+	}
+	if total == 0 {
+		if p.Steps[len(p.Steps)-1].Days == 0 {
+			return nil, fmt.Errorf("the schedule has no end; give the last step a length or set the total duration")
+		}
+		for _, s := range p.Steps {
+			total += s.Days
+		}
+	}
+
+	skipDay := map[string]bool{}
+	for _, d := range p.SkipDates {
+		skipDay[d.Format("2006-01-02")] = true
+	}
+	skipWeekday := map[time.Weekday]bool{}
+	for _, d := range p.SkipWeekdays {
+		skipWeekday[d] = true
+	}
+
+	y, m, d := p.Start.In(loc).Date()
+	var series []DoseSeries
+	day := 0
+	for i, step := range p.Steps {
+		if day >= total {
+			break
+		}
+		length := step.Days
+		if i == len(p.Steps)-1 || day+length > total {
+			length = total - day
+		}
+		for j, clock := range clocks {
+			s := DoseSeries{Step: i + 1, Dose: step.Dose, Time: p.Times[j], Count: length, FirstDay: day + 1}
+			s.First = time.Date(y, m, d+day, clock/60, clock%60, 0, 0, loc)
+			for k := 0; k < length; k++ {
+				at := time.Date(y, m, d+day+k, clock/60, clock%60, 0, 0, loc)
+				if skipDay[at.Format("2006-01-02")] || skipWeekday[at.Weekday()] {
+					s.Skipped = append(s.Skipped, at)
+				}
+			}
+			if len(s.Skipped) < s.Count {
+				series = append(series, s)
+			}
+		}
+		day += length
+	}
+	return series, nil
+}
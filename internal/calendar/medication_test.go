@@ -0,0 +1,106 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDoseSteps(t *testing.T) {
+	steps, err := ParseDoseSteps("10mg:1w, 20mg:10d, 30mg")
+	if err != nil {
+		t.Fatalf("ParseDoseSteps returned error: %v", err)
+	}
+	want := []DoseStep{{"10mg", 7}, {"20mg", 10}, {"30mg", 0}}
+	if len(steps) != len(want) {
+		t.Fatalf("got %+v, want %+v", steps, want)
+	}
+	for i := range want {
+		if steps[i] != want[i] {
+			t.Errorf("step %d = %+v, want %+v", i, steps[i], want[i])
+		}
+	}
+
+	for _, spec := range []string{"", "10mg, 20mg:1w", "10mg:2h", "10mg:soon", ":1w"} {
+		if _, err := ParseDoseSteps(spec); err == nil {
+			t.Errorf("ParseDoseSteps(%q): expected an error", spec)
+		}
+	}
+}
+
+func TestParseWeekdays(t *testing.T) {
+	days, err := ParseWeekdays("weekend, fri, sat")
+	if err != nil {
+		t.Fatalf("ParseWeekdays returned error: %v", err)
+	}
+	if len(days) != 3 || days[0] != time.Saturday || days[1] != time.Sunday || days[2] != time.Friday {
+		t.Errorf("ParseWeekdays = %v", days)
+	}
+	if _, err := ParseWeekdays("mon,funday"); err == nil || !strings.Contains(err.Error(), "funday") {
+		t.Errorf("expected unknown weekday error, got %v", err)
+	}
+}
+
+func TestMedicationPlanSeries(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Skip("Europe/Madrid not available")
+	}
+	plan := MedicationPlan{
+		Start:        time.Date(2025, 5, 1, 0, 0, 0, 0, madrid), // Thursday
+		Location:     madrid,
+		Steps:        []DoseStep{{"10mg", 7}, {"20mg", 0}},
+		Days:         14,
+		Times:        []string{"08:00", "20:00"},
+		SkipDates:    []time.Time{time.Date(2025, 5, 2, 0, 0, 0, 0, madrid)},
+		SkipWeekdays: []time.Weekday{time.Sunday},
+	}
+	series, err := plan.Series()
+	if err != nil {
+		t.Fatalf("Series returned error: %v", err)
+	}
+	if len(series) != 4 {
+		t.Fatalf("expected 2 steps x 2 times, got %+v", series)
+	}
+
+	first := series[0]
+	if first.Dose != "10mg" || first.Time != "08:00" || first.Count != 7 || first.FirstDay != 1 {
+		t.Errorf("unexpected first series %+v", first)
+	}
+	if got := first.First.Format("2006-01-02 15:04 MST"); got != "2025-05-01 08:00 CEST" {
+		t.Errorf("first dose at %s", got)
+	}
+	if len(first.Skipped) != 2 || first.Skipped[0].Format("01-02 15:04") != "05-02 08:00" || first.Skipped[1].Weekday() != time.Sunday {
+		t.Errorf("unexpected skipped days %v", first.Skipped)
+	}
+
+	last := series[3]
+	if last.Dose != "20mg" || last.Time != "20:00" || last.Count != 7 || last.FirstDay != 8 {
+		t.Errorf("expected the open-ended step to fill the plan, got %+v", last)
+	}
+	if got := last.First.Format("2006-01-02 15:04"); got != "2025-05-08 20:00" {
+		t.Errorf("second step starts at %s", got)
+	}
+}
+
+func TestMedicationPlanCutsStepsAtTheEnd(t *testing.T) {
+	plan := MedicationPlan{
+		Start: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+		Steps: []DoseStep{{"30mg", 5}, {"20mg", 5}, {"10mg", 5}},
+		Days:  7,
+		Times: []string{"09:00"},
+	}
+	series, err := plan.Series()
+	if err != nil {
+		t.Fatalf("Series returned error: %v", err)
+	}
+	if len(series) != 2 || series[1].Dose != "20mg" || series[1].Count != 2 {
+		t.Errorf("expected the taper cut after 7 days, got %+v", series)
+	}
+
+	plan.Days = 0
+	plan.Steps = []DoseStep{{"30mg", 5}, {"20mg", 0}}
+	if _, err := plan.Series(); err == nil {
+		t.Error("expected an error for a schedule without end")
+	}
+}
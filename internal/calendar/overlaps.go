@@ -0,0 +1,54 @@
+package calendar
+
+import (
+	"sort"
+	"time"
+)
+
+// EachOverlap calls fn(i, j), with i < j, for every pair of events that
+// Overlaps would report, without comparing every pair: events are sorted by
+// start and swept once, keeping only the ones still running. That is
+// O(n log n) plus the number of overlaps, instead of O(n²). Pairs come in
+// order of the later-starting event, so the pairs of one day arrive together.
+// Returning false from fn stops the sweep.
+func EachOverlap(events []Event, fn func(i, j int) bool) {
+	type span struct {
+		idx        int
+		start, end time.Time
+	}
+	spans := make([]span, 0, len(events))
+	for i := range events {
+		if events[i].AllDay {
+			continue
+		}
+		start, end := events[i].Instants()
+		spans = append(spans, span{idx: i, start: start, end: end})
+	}
+	sort.SliceStable(spans, func(a, b int) bool { return spans[a].start.Before(spans[b].start) })
+
+	var active []span
+	for _, cur := range spans {
+		// Events that ended by now can't overlap this one or any later one.
+		kept := active[:0]
+		for _, a := range active {
+			if a.end.After(cur.start) {
+				kept = append(kept, a)
+			}
+		}
+		active = kept
+
+		for _, a := range active {
+			if !cur.end.After(a.start) {
+				continue // zero-length event at a's start
+			}
+			i, j := a.idx, cur.idx
+			if i > j {
+				i, j = j, i
+			}
+			if !fn(i, j) {
+				return
+			}
+		}
+		active = append(active, cur)
+	}
+}
@@ -0,0 +1,84 @@
+package calendar
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+)
+
+func randomEvents(n int, seed int64) []Event {
+	r := rand.New(rand.NewSource(seed))
+	base := time.Date(2025, 9, 1, 8, 0, 0, 0, time.UTC)
+	events := make([]Event, n)
+	for i := range events {
+		start := base.Add(time.Duration(r.Intn(n*4)) * 15 * time.Minute)
+		events[i] = Event{
+			Summary:   fmt.Sprintf("E%d", i),
+			StartTime: start,
+			EndTime:   start.Add(time.Duration(r.Intn(8)) * 15 * time.Minute), // some zero-length
+			AllDay:    r.Intn(20) == 0,
+		}
+	}
+	return events
+}
+
+func TestEachOverlapMatchesPairwiseOverlaps(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		events := randomEvents(200, seed)
+		var want []string
+		for i := range events {
+			for j := i + 1; j < len(events); j++ {
+				if Overlaps(events[i], events[j]) {
+					want = append(want, fmt.Sprintf("%d-%d", i, j))
+				}
+			}
+		}
+		var got []string
+		EachOverlap(events, func(i, j int) bool {
+			if i >= j {
+				t.Fatalf("pair (%d, %d) is not ordered", i, j)
+			}
+			got = append(got, fmt.Sprintf("%d-%d", i, j))
+			return true
+		})
+		sort.Strings(want)
+		sort.Strings(got)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("seed %d: sweep found %d pairs, pairwise %d", seed, len(got), len(want))
+		}
+	}
+}
+
+func TestEachOverlapStopsEarlyAndUsesTZID(t *testing.T) {
+	at := func(h int) time.Time { return time.Date(2025, 5, 1, h, 0, 0, 0, time.UTC) }
+	events := []Event{
+		{Summary: "Madrid", StartTime: at(9), EndTime: at(10), StartTZ: "Europe/Madrid", EndTZ: "Europe/Madrid"},
+		{Summary: "UTC", StartTime: at(7), EndTime: at(8)},
+		{Summary: "Later", StartTime: at(7), EndTime: at(8)},
+	}
+	calls := 0
+	EachOverlap(events, func(i, j int) bool {
+		calls++
+		return false
+	})
+	if calls != 1 {
+		t.Errorf("returning false should stop the sweep, got %d calls", calls)
+	}
+
+	// 09:00 Madrid is 07:00 UTC, so all three overlap.
+	n := 0
+	EachOverlap(events, func(i, j int) bool { n++; return true })
+	if n != 3 {
+		t.Errorf("got %d overlapping pairs, want 3", n)
+	}
+}
+
+func BenchmarkEachOverlap(b *testing.B) {
+	events := randomEvents(5000, 1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		EachOverlap(events, func(i, j int) bool { return true })
+	}
+}
@@ -0,0 +1,422 @@
+package calendar
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"tempus/internal/constants"
+)
+
+// DefaultMaterializeLimit caps how many occurrences are generated when an
+// RRULE has neither COUNT nor UNTIL (roughly one year of daily events).
+const DefaultMaterializeLimit = 366
+
+// lastOccurrenceYear is the last year an ICS date can hold; expansion stops
+// there, so a huge INTERVAL ends the series instead of wrapping around.
+const lastOccurrenceYear = 9999
+
+// maxInterval is the largest INTERVAL expansion steps by: any larger step
+// leaves lastOccurrenceYear behind after the first occurrence anyway.
+const maxInterval = (lastOccurrenceYear + 1) * 366
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Recurrence is the subset of an RRULE that tempus can expand locally.
+type Recurrence struct {
+	Freq     string // DAILY, WEEKLY, MONTHLY or YEARLY
+	Interval int
+	Count    int
+	Until    time.Time
+	ByDay    []time.Weekday
+}
+
+// ParseRRule parses an RRULE value (without the "RRULE:" prefix).
+// Only FREQ, INTERVAL, COUNT, UNTIL, WKST and plain BYDAY codes are supported;
+// anything else is reported as an error so callers can fall back to emitting the raw rule.
+func ParseRRule(rrule string) (Recurrence, error) {
+	r := Recurrence{Interval: 1}
+	raw := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(rrule)), "RRULE:")
+	if raw == "" {
+		return r, fmt.Errorf("empty RRULE")
+	}
+
+	lastKey := ""
+	for _, part := range strings.Split(raw, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			// Tolerate "BYDAY=MO;TU;WE" (as written by the batch templates).
+			if wd, ok := rruleWeekdays[part]; ok && lastKey == "BYDAY" {
+				r.ByDay = append(r.ByDay, wd)
+				continue
+			}
+			return r, fmt.Errorf("invalid RRULE part %q", part)
+		}
+		key, val := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		lastKey = key
+		if err := r.applyPart(key, val); err != nil {
+			return r, err
+		}
+	}
+
+	switch r.Freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	case "":
+		return r, fmt.Errorf("RRULE is missing FREQ")
+	default:
+		return r, fmt.Errorf("unsupported RRULE frequency %q", r.Freq)
+	}
+	return r, nil
+}
+
+func (r *Recurrence) applyPart(key, val string) error {
+	switch key {
+	case "FREQ":
+		r.Freq = val
+	case "INTERVAL":
+		n, err := strconv.Atoi(val)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid RRULE INTERVAL %q", val)
+		}
+		r.Interval = n
+	case "COUNT":
+		n, err := strconv.Atoi(val)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid RRULE COUNT %q", val)
+		}
+		r.Count = n
+	case "UNTIL":
+		t, err := parseRRuleUntil(val)
+		if err != nil {
+			return err
+		}
+		r.Until = t
+	case "BYDAY":
+		for _, code := range strings.Split(val, ",") {
+			wd, ok := rruleWeekdays[strings.TrimSpace(code)]
+			if !ok {
+				return fmt.Errorf("unsupported RRULE BYDAY value %q", code)
+			}
+			r.ByDay = append(r.ByDay, wd)
+		}
+	case "WKST":
+		// Weeks always start on Monday for expansion purposes.
+	default:
+		return fmt.Errorf("unsupported RRULE part %s", key)
+	}
+	return nil
+}
+
+func parseRRuleUntil(val string) (time.Time, error) {
+	layouts := []string{constants.ICSFormatUTC, constants.ICSFormatLocal, constants.ICSFormatDateOnly}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, val); err == nil {
+			if layout == constants.ICSFormatDateOnly {
+				// A date-only UNTIL includes the whole day.
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid RRULE UNTIL %q", val)
+}
+
+// Occurrences returns the start times generated by the rule, beginning with start.
+// When the rule is unbounded, at most limit occurrences are returned.
+func (r Recurrence) Occurrences(start time.Time, limit int) []time.Time {
+	if limit <= 0 {
+		limit = DefaultMaterializeLimit
+	}
+	if r.Count > 0 && r.Count < limit {
+		limit = r.Count
+	}
+	interval := min(max(r.Interval, 1), maxInterval)
+
+	switch r.Freq {
+	case "DAILY", "WEEKLY":
+		return r.dayStepOccurrences(start, interval, limit)
+	case "MONTHLY":
+		return r.calendarStepOccurrences(start, limit, func(k int) (int, int) { return 0, k * interval })
+	case "YEARLY":
+		return r.calendarStepOccurrences(start, limit, func(k int) (int, int) { return k * interval, 0 })
+	default:
+		return nil
+	}
+}
+
+// dayStepOccurrences walks the days the rule selects, every interval-th day
+// or each day of every interval-th week (from the Monday of start's week),
+// and keeps those BYDAY allows, preserving the wall-clock time of start.
+func (r Recurrence) dayStepOccurrences(start time.Time, interval, limit int) []time.Time {
+	byDay := r.ByDay
+	if r.Freq == "WEEKLY" && len(byDay) == 0 {
+		byDay = []time.Weekday{start.Weekday()}
+	}
+	allowed := make(map[time.Weekday]bool, len(byDay))
+	for _, wd := range byDay {
+		allowed[wd] = true
+	}
+
+	// Every week after the first has a day BYDAY allows. Daily steps meet
+	// each weekday within seven periods, unless the interval is whole weeks
+	// and BYDAY never matches.
+	step, span, offset, periods := interval, 1, 0, 7*limit+7
+	if r.Freq == "WEEKLY" {
+		step, span, offset, periods = 7*interval, 7, (int(start.Weekday())+6)%7, limit+1
+	}
+	out := make([]time.Time, 0, min(limit, DefaultMaterializeLimit))
+	for k := 0; k < periods && len(out) < limit; k++ {
+		for j := 0; j < span && len(out) < limit; j++ {
+			d := k*step + j - offset
+			if d < 0 {
+				continue
+			}
+			day := start.AddDate(0, 0, d)
+			if r.pastUntil(day) || day.Year() > lastOccurrenceYear {
+				return out
+			}
+			if len(allowed) == 0 || allowed[day.Weekday()] {
+				out = append(out, day)
+			}
+		}
+	}
+	return out
+}
+
+func (r Recurrence) calendarStepOccurrences(start time.Time, limit int, step func(k int) (years, months int)) []time.Time {
+	out := make([]time.Time, 0, min(limit, DefaultMaterializeLimit))
+	for k := 0; len(out) < limit && k < limit*4; k++ {
+		years, months := step(k)
+		y, m := start.Year()+years, int(start.Month())+months
+		y += (m - 1) / 12
+		m = (m-1)%12 + 1
+		// Skip dates that do not exist (e.g. Feb 30, Feb 29 in non-leap years).
+		if start.Day() > daysIn(time.Month(m), y) {
+			continue
+		}
+		occ := time.Date(y, time.Month(m), start.Day(), start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+		if r.pastUntil(occ) || y > lastOccurrenceYear {
+			break
+		}
+		out = append(out, occ)
+	}
+	return out
+}
+
+func (r Recurrence) pastUntil(t time.Time) bool {
+	return !r.Until.IsZero() && t.After(r.Until)
+}
+
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func daysIn(m time.Month, year int) int {
+	return time.Date(year, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// Materialize expands a recurring event into standalone events, one per occurrence,
+// adding RDATEs and honouring EXDATE. When jitter > 0 each occurrence is shifted by a random whole-minute
+// offset within [-jitter, +jitter] while keeping its duration, so reminders do not fire
+// at exactly the same minute every day. All-day events are never jittered.
+// At most limit occurrences of the rule are expanded (DefaultMaterializeLimit
+// when limit <= 0); truncated reports that the rule goes on past them.
+func (e *Event) Materialize(limit int, jitter time.Duration, rnd *rand.Rand) ([]Event, bool, error) {
+	if limit <= 0 {
+		limit = DefaultMaterializeLimit
+	}
+	occurrences, truncated := []time.Time{e.StartTime}, false
+	if strings.TrimSpace(e.RRule) != "" {
+		rule, err := ParseRRule(e.RRule)
+		if err != nil {
+			return nil, false, fmt.Errorf("cannot expand RRULE %q: %w", e.RRule, err)
+		}
+		if loc := loadTimezoneLocation(e.StartTZ); loc != nil && !rule.Until.IsZero() {
+			// A UTC UNTIL is an instant; the rule steps through wall-clock
+			// starts, so compare it as a wall-clock time in the start's zone.
+			until, _ := ruleUntil(*e)
+			wall := until.In(loc)
+			rule.Until = time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, e.StartTime.Location())
+		}
+		occurrences = rule.Occurrences(e.StartTime, limit+1)
+		if truncated = len(occurrences) > limit; truncated {
+			occurrences = occurrences[:limit]
+		}
+	}
+	occurrences = withRDates(occurrences, e.RDates)
+	if jitter > 0 && rnd == nil {
+		rnd = rand.New(rand.NewSource(time.Now().UnixNano())) // #nosec G404 -- not security sensitive
+	}
+
+	excluded := make(map[string]bool, len(e.ExDates))
+	for _, x := range e.ExDates {
+		excluded[x.Format(constants.ICSFormatLocal)] = true
+	}
+
+	duration := e.EndTime.Sub(e.StartTime)
+	out := make([]Event, 0, len(occurrences))
+	for _, start := range occurrences {
+		if excluded[start.Format(constants.ICSFormatLocal)] {
+			continue
+		}
+		if jitter > 0 && !e.AllDay {
+			start = start.Add(randomMinuteOffset(rnd, jitter))
+		}
+
+		occ := *e
+		occ.UID = NewUID()
+		occ.RRule = ""
+		occ.ExDates = nil
+		occ.RDates = nil
+		occ.StartTime = start
+		occ.EndTime = start.Add(duration)
+		occ.Alarms = append([]Alarm(nil), e.Alarms...)
+		occ.Attendees = append([]string(nil), e.Attendees...)
+		occ.Categories = append([]string(nil), e.Categories...)
+		out = append(out, occ)
+	}
+	return out, truncated, nil
+}
+
+// OccurrenceOverride changes one occurrence of a recurring event.
+type OccurrenceOverride struct {
+	Occurrence  time.Time // the start the series gives the occurrence
+	Start       time.Time // its new start
+	End         time.Time // its new end; zero keeps the series' length
+	Summary     string    // "" keeps the series' summary, location and description
+	Location    string
+	Description string
+}
+
+// Override returns the VEVENT for one occurrence of the recurring event e as
+// changed by o: a copy with e's UID, RECURRENCE-ID set to the occurrence and
+// no recurrence of its own. The occurrence must be one the series produces,
+// unless tempus cannot expand its rule.
+func (e *Event) Override(o OccurrenceOverride) (*Event, error) {
+	if strings.TrimSpace(e.RRule) == "" && len(e.RDates) == 0 {
+		return nil, fmt.Errorf("only a recurring event can have overrides")
+	}
+	layout, shown := constants.ICSFormatLocal, constants.DateTimeFormatISO
+	if e.AllDay {
+		layout, shown = constants.ICSFormatDateOnly, constants.DateFormatISO
+	}
+	limit := DefaultMaterializeLimit
+	if days := int(o.Occurrence.Sub(e.StartTime).Hours() / 24); days > 0 {
+		limit += days
+	}
+	if occurrences, _, err := e.Materialize(limit, 0, nil); err == nil {
+		found := false
+		for _, occ := range occurrences {
+			found = found || occ.StartTime.Format(layout) == o.Occurrence.Format(layout)
+		}
+		if !found {
+			return nil, fmt.Errorf("%s is not an occurrence of %q", o.Occurrence.Format(shown), e.Summary)
+		}
+	}
+
+	end := o.End
+	if end.IsZero() {
+		end = o.Start.Add(e.EndTime.Sub(e.StartTime))
+	}
+	if !end.After(o.Start) {
+		return nil, fmt.Errorf("override of %s must end after it starts", o.Occurrence.Format(shown))
+	}
+	ov := *e
+	ov.RRule, ov.ExDates, ov.RDates = "", nil, nil
+	ov.RecurrenceID = o.Occurrence
+	ov.StartTime, ov.EndTime = o.Start, end
+	ov.Summary = firstNonBlank(o.Summary, e.Summary)
+	ov.Location = firstNonBlank(o.Location, e.Location)
+	ov.Description = firstNonBlank(o.Description, e.Description)
+	ov.Alarms = append([]Alarm(nil), e.Alarms...)
+	ov.Attendees = append([]string(nil), e.Attendees...)
+	ov.Categories = append([]string(nil), e.Categories...)
+	return &ov, nil
+}
+
+// withRDates adds the RDATE occurrences to those of the rule, in order and
+// without repeating a start the rule already produces.
+func withRDates(occurrences, rdates []time.Time) []time.Time {
+	if len(rdates) == 0 {
+		return occurrences
+	}
+	seen := make(map[string]bool, len(occurrences))
+	for _, o := range occurrences {
+		seen[o.Format(constants.ICSFormatLocal)] = true
+	}
+	for _, r := range rdates {
+		if key := r.Format(constants.ICSFormatLocal); !seen[key] {
+			seen[key] = true
+			occurrences = append(occurrences, r)
+		}
+	}
+	sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].Before(occurrences[j]) })
+	return occurrences
+}
+
+// Upcoming returns the occurrences of events that have not ended by from,
+// sorted by start, at most limit of them (0 for all). Recurring events are
+// expanded honouring RDATE, EXDATE and RECURRENCE-ID overrides; a rule tempus cannot expand counts as a single
+// event.
+func Upcoming(events []Event, from time.Time, limit int) []Event {
+	overridden := map[string]bool{}
+	for _, ev := range events {
+		if !ev.RecurrenceID.IsZero() {
+			overridden[ev.UID+"\x00"+ev.RecurrenceID.Format(constants.ICSFormatLocal)] = true
+		}
+	}
+	var out []Event
+	for i := range events {
+		ev := &events[i]
+		occurrences := []Event{*ev}
+		if strings.TrimSpace(ev.RRule) != "" || len(ev.RDates) > 0 {
+			// Enough occurrences to get past from, plus a year of future ones.
+			n := DefaultMaterializeLimit
+			if days := int(from.Sub(ev.StartTime).Hours() / 24); days > 0 {
+				n += days
+			}
+			if expanded, _, err := ev.Materialize(n, 0, nil); err == nil {
+				for j := range expanded {
+					expanded[j].UID = ev.UID
+				}
+				occurrences = expanded
+			}
+		}
+		for _, occ := range occurrences {
+			if occ.RecurrenceID.IsZero() && overridden[occ.UID+"\x00"+occ.StartTime.Format(constants.ICSFormatLocal)] {
+				continue // replaced by its override
+			}
+			if occ.EndTime.After(from) || (occ.EndTime.Equal(occ.StartTime) && !occ.StartTime.Before(from)) {
+				out = append(out, occ)
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].StartTime.Before(out[j].StartTime) })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+func randomMinuteOffset(rnd *rand.Rand, jitter time.Duration) time.Duration {
+	maxMin := int(jitter / time.Minute)
+	if maxMin <= 0 {
+		return 0
+	}
+	return time.Duration(rnd.Intn(2*maxMin+1)-maxMin) * time.Minute
+}
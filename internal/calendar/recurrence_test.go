@@ -0,0 +1,341 @@
+package calendar
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRRuleSupportedParts(t *testing.T) {
+	r, err := ParseRRule("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=6")
+	if err != nil {
+		t.Fatalf("ParseRRule returned error: %v", err)
+	}
+	if r.Freq != "WEEKLY" || r.Interval != 2 || r.Count != 6 || len(r.ByDay) != 2 {
+		t.Fatalf("unexpected recurrence: %+v", r)
+	}
+}
+
+func TestParseRRuleToleratesSemicolonSeparatedDays(t *testing.T) {
+	r, err := ParseRRule("FREQ=WEEKLY;BYDAY=MO;TU;WE;COUNT=3")
+	if err != nil {
+		t.Fatalf("ParseRRule returned error: %v", err)
+	}
+	if len(r.ByDay) != 3 || r.Count != 3 {
+		t.Fatalf("expected 3 weekdays and COUNT=3, got %+v", r)
+	}
+}
+
+func TestParseRRuleRejectsUnsupportedParts(t *testing.T) {
+	for _, rule := range []string{"", "COUNT=3", "FREQ=HOURLY", "FREQ=MONTHLY;BYMONTHDAY=15", "FREQ=MONTHLY;BYDAY=1MO"} {
+		if _, err := ParseRRule(rule); err == nil {
+			t.Errorf("expected error for %q", rule)
+		}
+	}
+}
+
+func TestRecurrenceOccurrences(t *testing.T) {
+	start := time.Date(2025, 12, 15, 9, 0, 0, 0, time.UTC) // Monday
+
+	tests := []struct {
+		name  string
+		rule  string
+		want  int
+		first time.Time
+		last  time.Time
+	}{
+		{"daily count", "FREQ=DAILY;COUNT=5", 5, start, start.AddDate(0, 0, 4)},
+		{"daily interval", "FREQ=DAILY;INTERVAL=2;COUNT=3", 3, start, start.AddDate(0, 0, 4)},
+		{"weekdays", "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;COUNT=6", 6, start, start.AddDate(0, 0, 7)},
+		{"biweekly", "FREQ=WEEKLY;INTERVAL=2;COUNT=3", 3, start, start.AddDate(0, 0, 28)},
+		{"until date", "FREQ=DAILY;UNTIL=20251217", 3, start, start.AddDate(0, 0, 2)},
+		{"monthly", "FREQ=MONTHLY;COUNT=3", 3, start, start.AddDate(0, 2, 0)},
+		{"yearly", "FREQ=YEARLY;COUNT=2", 2, start, start.AddDate(1, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseRRule(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRRule(%q) error: %v", tt.rule, err)
+			}
+			got := r.Occurrences(start, 0)
+			if len(got) != tt.want {
+				t.Fatalf("expected %d occurrences, got %d: %v", tt.want, len(got), got)
+			}
+			if !got[0].Equal(tt.first) || !got[len(got)-1].Equal(tt.last) {
+				t.Fatalf("unexpected range %v .. %v", got[0], got[len(got)-1])
+			}
+		})
+	}
+}
+
+func TestRecurrenceMonthlySkipsMissingDays(t *testing.T) {
+	start := time.Date(2025, 1, 31, 8, 0, 0, 0, time.UTC)
+	r, _ := ParseRRule("FREQ=MONTHLY;COUNT=3")
+	got := r.Occurrences(start, 0)
+	if len(got) != 3 || got[1].Month() != time.March || got[2].Month() != time.May {
+		t.Fatalf("expected Jan/Mar/May 31st, got %v", got)
+	}
+}
+
+func TestRecurrenceUnboundedIsCapped(t *testing.T) {
+	r, _ := ParseRRule("FREQ=DAILY")
+	got := r.Occurrences(time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC), 10)
+	if len(got) != 10 {
+		t.Fatalf("expected 10 occurrences, got %d", len(got))
+	}
+}
+
+func TestMaterializeWithoutJitter(t *testing.T) {
+	start := time.Date(2025, 12, 15, 21, 0, 0, 0, time.UTC)
+	ev := NewEvent("Evening meds", start, start.Add(5*time.Minute))
+	ev.SetTimezone("Europe/Madrid")
+	ev.RRule = "FREQ=DAILY;COUNT=4"
+	ev.ExDates = []time.Time{start.AddDate(0, 0, 2)}
+	ev.Alarms = []Alarm{{Action: "DISPLAY", TriggerIsRelative: true, TriggerDuration: -5 * time.Minute}}
+
+	occ, _, err := ev.Materialize(0, 0, nil)
+	if err != nil {
+		t.Fatalf("Materialize returned error: %v", err)
+	}
+	if len(occ) != 3 {
+		t.Fatalf("expected 3 occurrences after EXDATE, got %d", len(occ))
+	}
+	seen := map[string]bool{}
+	for _, o := range occ {
+		if o.RRule != "" || len(o.ExDates) != 0 {
+			t.Errorf("occurrence should not carry recurrence data: %+v", o)
+		}
+		if o.StartTime.Hour() != 21 || o.StartTime.Minute() != 0 {
+			t.Errorf("expected exact 21:00 start, got %v", o.StartTime)
+		}
+		if o.EndTime.Sub(o.StartTime) != 5*time.Minute {
+			t.Errorf("expected duration preserved, got %v", o.EndTime.Sub(o.StartTime))
+		}
+		if len(o.Alarms) != 1 || o.StartTZ != "Europe/Madrid" {
+			t.Errorf("expected alarms and timezone copied, got %+v", o)
+		}
+		if seen[o.UID] {
+			t.Errorf("duplicate UID %s", o.UID)
+		}
+		seen[o.UID] = true
+	}
+}
+
+func TestMaterializeReportsTruncation(t *testing.T) {
+	start := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	ev := NewEvent("Standup", start, start.Add(15*time.Minute))
+	for _, tt := range []struct {
+		rule      string
+		limit     int
+		want      int
+		truncated bool
+	}{
+		{"FREQ=DAILY", 0, DefaultMaterializeLimit, true},
+		{"FREQ=DAILY", 10, 10, true},
+		{"FREQ=DAILY;COUNT=10", 10, 10, false},
+		{"FREQ=DAILY;COUNT=11", 10, 10, true},
+		{"FREQ=DAILY;UNTIL=20300105T235959Z", 10, 5, false},
+	} {
+		ev.RRule = tt.rule
+		occ, truncated, err := ev.Materialize(tt.limit, 0, nil)
+		if err != nil || len(occ) != tt.want || truncated != tt.truncated {
+			t.Errorf("Materialize(%s, %d) = %d occurrences, truncated %v, %v; want %d, %v", tt.rule, tt.limit, len(occ), truncated, err, tt.want, tt.truncated)
+		}
+	}
+}
+
+func TestMaterializeAddsRDates(t *testing.T) {
+	start := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC) // a Monday
+	ev := NewEvent("Class", start, start.Add(time.Hour))
+	ev.RRule = "FREQ=WEEKLY;COUNT=3"
+	ev.RDates = []time.Time{
+		time.Date(2025, 9, 6, 11, 0, 0, 0, time.UTC), // Saturday makeup
+		start.AddDate(0, 0, 7),                       // already in the rule
+	}
+	ev.ExDates = []time.Time{start.AddDate(0, 0, 14)}
+
+	occ, _, err := ev.Materialize(0, 0, nil)
+	if err != nil {
+		t.Fatalf("Materialize returned error: %v", err)
+	}
+	var got []string
+	for _, o := range occ {
+		got = append(got, o.StartTime.Format("01-02 15:04"))
+		if len(o.RDates) != 0 {
+			t.Errorf("occurrence should not carry RDATEs: %+v", o)
+		}
+	}
+	want := []string{"09-01 10:00", "09-06 11:00", "09-08 10:00"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("occurrences = %v, want %v", got, want)
+	}
+
+	// RDATEs alone make an event recurring too.
+	single := NewEvent("Talk", start, start.Add(time.Hour))
+	single.RDates = []time.Time{start.AddDate(0, 0, 3)}
+	if occ, _, err := single.Materialize(0, 0, nil); err != nil || len(occ) != 2 {
+		t.Errorf("expected DTSTART plus one RDATE, got %d (%v)", len(occ), err)
+	}
+}
+
+func TestMaterializeWithJitterStaysInWindow(t *testing.T) {
+	start := time.Date(2025, 12, 15, 21, 0, 0, 0, time.UTC)
+	ev := NewEvent("Wind down", start, start.Add(30*time.Minute))
+	ev.RRule = "FREQ=DAILY;COUNT=50"
+
+	occ, _, err := ev.Materialize(0, 15*time.Minute, rand.New(rand.NewSource(42)))
+	if err != nil {
+		t.Fatalf("Materialize returned error: %v", err)
+	}
+	if len(occ) != 50 {
+		t.Fatalf("expected 50 occurrences, got %d", len(occ))
+	}
+	moved := false
+	for i, o := range occ {
+		nominal := start.AddDate(0, 0, i)
+		offset := o.StartTime.Sub(nominal)
+		if offset < -15*time.Minute || offset > 15*time.Minute {
+			t.Fatalf("occurrence %d offset %v outside ±15m", i, offset)
+		}
+		if offset%time.Minute != 0 {
+			t.Fatalf("occurrence %d offset %v is not whole minutes", i, offset)
+		}
+		if offset != 0 {
+			moved = true
+		}
+		if o.EndTime.Sub(o.StartTime) != 30*time.Minute {
+			t.Fatalf("occurrence %d duration changed", i)
+		}
+	}
+	if !moved {
+		t.Fatal("expected at least one occurrence to be shifted")
+	}
+}
+
+func TestMaterializeRejectsUnsupportedRule(t *testing.T) {
+	start := time.Date(2025, 12, 15, 9, 0, 0, 0, time.UTC)
+	ev := NewEvent("Rent", start, start.Add(time.Hour))
+	ev.RRule = "FREQ=MONTHLY;BYMONTHDAY=1"
+	if _, _, err := ev.Materialize(0, 10*time.Minute, nil); err == nil {
+		t.Fatal("expected error for unsupported RRULE")
+	}
+}
+
+func TestUpcomingExpandsRecurrencesAndSkipsPast(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\nUID:gym\r\nSUMMARY:Gym\r\nDTSTART:20240101T070000Z\r\nDTEND:20240101T080000Z\r\nRRULE:FREQ=DAILY\r\nEXDATE:20250502T070000Z,20250503T070000Z\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:call\r\nSUMMARY:Call\r\nDTSTART:20250501T083000Z\r\nDTEND:20250501T093000Z\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:old\r\nSUMMARY:Old\r\nDTSTART:20250430T090000Z\r\nDTEND:20250430T100000Z\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	events, err := ParseICSEvents(ics)
+	if err != nil {
+		t.Fatalf("ParseICSEvents() failed: %v", err)
+	}
+	from := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+	got := Upcoming(events, from, 3)
+	want := []string{"Call 2025-05-01 08:30", "Gym 2025-05-04 07:00", "Gym 2025-05-05 07:00"}
+	if len(got) != len(want) {
+		t.Fatalf("Upcoming() returned %d events, want %d", len(got), len(want))
+	}
+	for i, ev := range got {
+		if label := ev.Summary + " " + ev.StartTime.Format("2006-01-02 15:04"); label != want[i] {
+			t.Errorf("event %d = %q, want %q", i, label, want[i])
+		}
+		if ev.Summary == "Gym" && ev.UID != "gym" {
+			t.Errorf("occurrences should keep the event UID, got %q", ev.UID)
+		}
+	}
+	if all := Upcoming(events, from, 0); len(all) < 300 {
+		t.Errorf("expected a year of daily occurrences without a limit, got %d", len(all))
+	}
+}
+
+func TestOverrideMovesOneOccurrence(t *testing.T) {
+	start := time.Date(2025, 12, 16, 14, 0, 0, 0, time.UTC)
+	ev := NewEvent("1:1", start, start.Add(30*time.Minute))
+	ev.UID = "one-on-one"
+	ev.StartTZ = "Europe/Madrid"
+	ev.RRule = "FREQ=WEEKLY;COUNT=4"
+
+	moved, err := ev.Override(OccurrenceOverride{
+		Occurrence: start.AddDate(0, 0, 7),
+		Start:      time.Date(2025, 12, 23, 16, 0, 0, 0, time.UTC),
+		Location:   "Room 2",
+	})
+	if err != nil {
+		t.Fatalf("Override() failed: %v", err)
+	}
+	if moved.UID != ev.UID || moved.RRule != "" || moved.Location != "Room 2" || moved.Summary != "1:1" {
+		t.Errorf("unexpected override: %+v", moved)
+	}
+	if got := moved.EndTime.Sub(moved.StartTime); got != 30*time.Minute {
+		t.Errorf("override should keep the series length, got %v", got)
+	}
+	ics := moved.ToICS()
+	for _, want := range []string{"RECURRENCE-ID;TZID=Europe/Madrid:20251223T140000", "DTSTART;TZID=Europe/Madrid:20251223T160000"} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("override missing %q:\n%s", want, ics)
+		}
+	}
+	if strings.Contains(ics, "RRULE") {
+		t.Errorf("override should not repeat the rule:\n%s", ics)
+	}
+
+	if _, err := ev.Override(OccurrenceOverride{Occurrence: start.AddDate(0, 0, 1), Start: start}); err == nil {
+		t.Error("expected error for a day the series does not fall on")
+	}
+	single := NewEvent("Once", start, start.Add(time.Hour))
+	if _, err := single.Override(OccurrenceOverride{Occurrence: start, Start: start}); err == nil {
+		t.Error("expected error for an event that does not repeat")
+	}
+}
+
+func TestUpcomingReplacesOverriddenOccurrences(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\nUID:sync\r\nSUMMARY:Sync\r\nDTSTART:20250505T090000Z\r\nDTEND:20250505T093000Z\r\nRRULE:FREQ=DAILY;COUNT=3\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:sync\r\nSUMMARY:Sync (moved)\r\nRECURRENCE-ID:20250506T090000Z\r\nDTSTART:20250506T150000Z\r\nDTEND:20250506T153000Z\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	events, err := ParseICSEvents(ics)
+	if err != nil {
+		t.Fatalf("ParseICSEvents() failed: %v", err)
+	}
+	got := Upcoming(events, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), 0)
+	want := []string{"Sync 2025-05-05 09:00", "Sync (moved) 2025-05-06 15:00", "Sync 2025-05-07 09:00"}
+	if len(got) != len(want) {
+		t.Fatalf("Upcoming() returned %d events, want %d", len(got), len(want))
+	}
+	for i, ev := range got {
+		if label := ev.Summary + " " + ev.StartTime.Format("2006-01-02 15:04"); label != want[i] {
+			t.Errorf("event %d = %q, want %q", i, label, want[i])
+		}
+	}
+}
+
+func FuzzParseRRule(f *testing.F) {
+	for _, seed := range []string{
+		"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=6", "FREQ=DAILY;UNTIL=20300401T000000Z",
+		"FREQ=MONTHLY;COUNT=99999999999", "FREQ=DAILY;INTERVAL=999999999", "FREQ=WEEKLY;BYDAY=MO;TU;WE",
+		"FREQ=YEARLY;INTERVAL=2147483647", "RRULE:FREQ=DAILY;UNTIL=20300101",
+	} {
+		f.Add(seed)
+	}
+	start := time.Date(2030, 1, 31, 9, 0, 0, 0, time.UTC)
+	f.Fuzz(func(t *testing.T, rrule string) {
+		r, err := ParseRRule(rrule)
+		if err != nil {
+			return
+		}
+		occ := r.Occurrences(start, 20)
+		if len(occ) > 20 {
+			t.Fatalf("Occurrences(20) of %q gave %d", rrule, len(occ))
+		}
+		for i := 1; i < len(occ); i++ {
+			if !occ[i].After(occ[i-1]) {
+				t.Fatalf("occurrences of %q out of order: %v then %v", rrule, occ[i-1], occ[i])
+			}
+		}
+	})
+}
@@ -0,0 +1,177 @@
+package calendar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RotaShift is a kind of shift in a rota: from Start to End minutes after
+// midnight (an End at or before Start runs past midnight), or all day.
+type RotaShift struct {
+	Name   string
+	Start  int
+	End    int
+	AllDay bool
+}
+
+// DefaultRotaShifts are the shift names a rota pattern can use without defining
+// them first.
+var DefaultRotaShifts = map[string]RotaShift{
+	"early": {Name: "early", Start: 7 * 60, End: 15 * 60},
+	"late":  {Name: "late", Start: 15 * 60, End: 23 * 60},
+	"night": {Name: "night", Start: 23 * 60, End: 7 * 60},
+	"day":   {Name: "day", Start: 8 * 60, End: 20 * 60},
+	"on":    {Name: "on", AllDay: true},
+}
+
+// rotaOffNames mark a day off in a rota pattern.
+var rotaOffNames = map[string]bool{"off": true, "rest": true, "free": true, "-": true}
+
+// ParseRotaShift parses a shift definition: "NAME=HH:MM-HH:MM" (overnight
+// shifts such as "night=22:00-06:00" are fine) or "NAME=all-day".
+func ParseRotaShift(spec string) (RotaShift, error) {
+	name, hours, ok := strings.Cut(spec, "=")
+	name = strings.ToLower(strings.TrimSpace(name))
+	hours = strings.ToLower(strings.TrimSpace(hours))
+	if !ok || name == "" || hours == "" {
+		return RotaShift{}, fmt.Errorf("invalid shift %q (want NAME=HH:MM-HH:MM or NAME=all-day)", spec)
+	}
+	if rotaOffNames[name] {
+		return RotaShift{}, fmt.Errorf("%q is a day off and cannot be a shift", name)
+	}
+	if hours == "all-day" || hours == "allday" {
+		return RotaShift{Name: name, AllDay: true}, nil
+	}
+	windows, err := ParseClockWindows(hours)
+	if err != nil || len(windows) != 1 {
+		return RotaShift{}, fmt.Errorf("invalid shift %q (want NAME=HH:MM-HH:MM or NAME=all-day)", spec)
+	}
+	return RotaShift{Name: name, Start: windows[0].Start, End: windows[0].End}, nil
+}
+
+// ParseRotaPattern expands a rotation into one shift name per day of the
+// cycle. Steps are separated by commas, slashes or spaces and may carry a
+// count, as "4-on/4-off" or "early*2,late*2,night*2,off*4"; off, rest, free
+// and "-" are days off.
+func ParseRotaPattern(spec string) ([]string, error) {
+	var days []string
+	worked := false
+	for _, step := range strings.FieldsFunc(strings.ToLower(spec), func(r rune) bool { return r == ',' || r == '/' || r == ' ' }) {
+		name, count := step, 1
+		if n, rest, ok := strings.Cut(step, "-"); ok && rest != "" {
+			if c, err := strconv.Atoi(n); err == nil {
+				name, count = rest, c
+			}
+		}
+		if rest, n, ok := strings.Cut(name, "*"); ok {
+			c, err := strconv.Atoi(n)
+			if err != nil {
+				return nil, fmt.Errorf("invalid count in %q", step)
+			}
+			name, count = rest, c
+		}
+		if count < 1 || count > 366 {
+			return nil, fmt.Errorf("invalid count in %q (use 1 to 366 days)", step)
+		}
+		if name == "" {
+			return nil, fmt.Errorf("step %q has no shift name", step)
+		}
+		if !rotaOffNames[name] {
+			worked = true
+		}
+		for i := 0; i < count; i++ {
+			days = append(days, name)
+		}
+	}
+	if len(days) == 0 {
+		return nil, fmt.Errorf("empty rota pattern")
+	}
+	if len(days) > 366 {
+		return nil, fmt.Errorf("rota cycle of %d days is too long (at most 366)", len(days))
+	}
+	if !worked {
+		return nil, fmt.Errorf("rota pattern %q has no shifts, only days off", spec)
+	}
+	return days, nil
+}
+
+// Rota repeats a pattern of shifts, one entry per day, from an anchor day.
+type Rota struct {
+	Anchor   time.Time // the day the cycle's first entry falls on
+	Pattern  []string
+	Shifts   map[string]RotaShift // shift definitions; nil means DefaultRotaShifts
+	Location *time.Location
+}
+
+// RotaBlock is one stretch of work: a timed shift on Day, or consecutive
+// days of the same all-day shift. End is exclusive (the next day for
+// all-day blocks).
+type RotaBlock struct {
+	Shift RotaShift
+	Day   int // 1-based position of the first day in the cycle
+	Start time.Time
+	End   time.Time
+	Days  int
+}
+
+// Blocks lays the rota out over days days from from (only the date counts).
+// The cycle keeps its place relative to the anchor, so from can be any day,
+// before or after it.
+func (r Rota) Blocks(from time.Time, days int) ([]RotaBlock, error) {
+	if len(r.Pattern) == 0 {
+		return nil, fmt.Errorf("empty rota pattern")
+	}
+	if days < 1 {
+		return nil, fmt.Errorf("the rota needs at least one day")
+	}
+	loc := r.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	shifts := r.Shifts
+	if shifts == nil {
+		shifts = DefaultRotaShifts
+	}
+	for _, name := range r.Pattern {
+		if _, ok := shifts[name]; !ok && !rotaOffNames[name] {
+			return nil, fmt.Errorf("unknown shift %q; define it as %s=HH:MM-HH:MM or %s=all-day", name, name, name)
+		}
+	}
+
+	dateOf := func(t time.Time) time.Time {
+		y, m, d := t.In(loc).Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	anchor, first := dateOf(r.Anchor), dateOf(from)
+	offset := int(first.Sub(anchor).Hours() / 24)
+	cycle := len(r.Pattern)
+
+	var blocks []RotaBlock
+	for i := 0; i < days; i++ {
+		pos := ((offset+i)%cycle + cycle) % cycle
+		shift, ok := shifts[r.Pattern[pos]]
+		if !ok {
+			continue // day off
+		}
+		y, m, d := first.AddDate(0, 0, i).Date()
+		if shift.AllDay {
+			start := time.Date(y, m, d, 0, 0, 0, 0, loc)
+			if n := len(blocks); n > 0 && blocks[n-1].Shift.AllDay && blocks[n-1].Shift.Name == shift.Name && blocks[n-1].End.Equal(start) {
+				blocks[n-1].End = start.AddDate(0, 0, 1)
+				blocks[n-1].Days++
+				continue
+			}
+			blocks = append(blocks, RotaBlock{Shift: shift, Day: pos + 1, Start: start, End: start.AddDate(0, 0, 1), Days: 1})
+			continue
+		}
+		start := time.Date(y, m, d, shift.Start/60, shift.Start%60, 0, 0, loc)
+		end := time.Date(y, m, d, shift.End/60, shift.End%60, 0, 0, loc)
+		if shift.End <= shift.Start {
+			end = time.Date(y, m, d+1, shift.End/60, shift.End%60, 0, 0, loc)
+		}
+		blocks = append(blocks, RotaBlock{Shift: shift, Day: pos + 1, Start: start, End: end, Days: 1})
+	}
+	return blocks, nil
+}
@@ -0,0 +1,100 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRotaPattern(t *testing.T) {
+	tests := map[string]string{
+		"4-on/4-off":                   "on on on on off off off off",
+		"early*2,late*2,night*2,off*4": "early early late late night night off off off off",
+		"Day Day - Night":              "day day - night",
+	}
+	for spec, want := range tests {
+		got, err := ParseRotaPattern(spec)
+		if err != nil {
+			t.Errorf("ParseRotaPattern(%q) returned error: %v", spec, err)
+			continue
+		}
+		if strings.Join(got, " ") != want {
+			t.Errorf("ParseRotaPattern(%q) = %v, want %s", spec, got, want)
+		}
+	}
+
+	for _, spec := range []string{"", "off*4", "0-on", "on*x", "on*400", "*3"} {
+		if _, err := ParseRotaPattern(spec); err == nil {
+			t.Errorf("ParseRotaPattern(%q): expected an error", spec)
+		}
+	}
+}
+
+func TestParseRotaShift(t *testing.T) {
+	night, err := ParseRotaShift("Night=22:00-06:30")
+	if err != nil || night != (RotaShift{Name: "night", Start: 22 * 60, End: 6*60 + 30}) {
+		t.Errorf("ParseRotaShift = %+v, %v", night, err)
+	}
+	if standby, err := ParseRotaShift("standby=all-day"); err != nil || !standby.AllDay {
+		t.Errorf("ParseRotaShift all-day = %+v, %v", standby, err)
+	}
+	for _, spec := range []string{"night", "=08:00-16:00", "off=08:00-16:00", "x=8am-4pm", "x=08:00-08:00"} {
+		if _, err := ParseRotaShift(spec); err == nil {
+			t.Errorf("ParseRotaShift(%q): expected an error", spec)
+		}
+	}
+}
+
+func TestRotaBlocks(t *testing.T) {
+	dublin, err := time.LoadLocation("Europe/Dublin")
+	if err != nil {
+		t.Skip("Europe/Dublin not available")
+	}
+	pattern, _ := ParseRotaPattern("early,night,off,off")
+	rota := Rota{Anchor: time.Date(2025, 3, 27, 0, 0, 0, 0, dublin), Pattern: pattern, Location: dublin}
+
+	// Generating from before the anchor keeps the cycle in step.
+	blocks, err := rota.Blocks(time.Date(2025, 3, 25, 12, 0, 0, 0, dublin), 8)
+	if err != nil {
+		t.Fatalf("Blocks returned error: %v", err)
+	}
+	var got []string
+	for _, b := range blocks {
+		got = append(got, b.Shift.Name+" "+b.Start.Format("01-02 15:04")+"-"+b.End.Format("01-02 15:04"))
+	}
+	want := []string{
+		"early 03-27 07:00-03-27 15:00",
+		"night 03-28 23:00-03-29 07:00",
+		"early 03-31 07:00-03-31 15:00",
+		"night 04-01 23:00-04-02 07:00",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Blocks = %v, want %v", got, want)
+	}
+	if blocks[1].Day != 2 {
+		t.Errorf("night shift is day %d of the cycle, want 2", blocks[1].Day)
+	}
+
+	rota.Pattern = []string{"nights"}
+	if _, err := rota.Blocks(rota.Anchor, 1); err == nil || !strings.Contains(err.Error(), "nights=HH:MM-HH:MM") {
+		t.Errorf("expected an unknown shift error, got %v", err)
+	}
+}
+
+func TestRotaBlocksMergesAllDayShifts(t *testing.T) {
+	pattern, _ := ParseRotaPattern("3-on/2-off")
+	rota := Rota{Anchor: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), Pattern: pattern, Location: time.UTC}
+	blocks, err := rota.Blocks(time.Date(2025, 5, 2, 0, 0, 0, 0, time.UTC), 7)
+	if err != nil {
+		t.Fatalf("Blocks returned error: %v", err)
+	}
+	if len(blocks) != 2 {
+		t.Fatalf("expected 2 stretches on, got %+v", blocks)
+	}
+	if blocks[0].Days != 2 || blocks[0].Day != 2 || blocks[0].End.Format("2006-01-02") != "2025-05-04" {
+		t.Errorf("unexpected first block %+v", blocks[0])
+	}
+	if blocks[1].Days != 3 || blocks[1].Start.Format("2006-01-02") != "2025-05-06" {
+		t.Errorf("unexpected second block %+v", blocks[1])
+	}
+}
@@ -0,0 +1,101 @@
+package calendar
+
+import (
+	"strings"
+	"time"
+)
+
+// Sanity issue kinds reported by CheckSanity. Each flags something valid in
+// iCalendar that is almost always a typo.
+const (
+	SanityPast  = "past"  // the event (or its whole series) ended long ago, e.g. 2015 for 2025
+	SanityAlarm = "alarm" // an alarm fires after the event ends, or long before an absolute start
+	SanityLong  = "long"  // a timed event lasts more than a day
+	SanityUntil = "until" // the RRULE's UNTIL is before DTSTART, so the rule never repeats
+)
+
+// SanityLimits are the thresholds CheckSanity applies.
+type SanityLimits struct {
+	Past        time.Duration // how long ago an event may have ended; 0 turns the check off
+	AlarmLead   time.Duration // how long before the start an absolute alarm may fire
+	MaxDuration time.Duration // the longest a timed event may last
+}
+
+// DefaultSanityLimits flags events that ended over 30 days ago, absolute
+// alarms more than 30 days ahead of the start and timed events over 24h.
+var DefaultSanityLimits = SanityLimits{
+	Past:        30 * 24 * time.Hour,
+	AlarmLead:   30 * 24 * time.Hour,
+	MaxDuration: 24 * time.Hour,
+}
+
+// SanityIssue is one finding of CheckSanity.
+type SanityIssue struct {
+	Kind  string
+	Alarm int           // index into Event.Alarms, for SanityAlarm
+	At    time.Time     // the end (past), trigger (alarm) or UNTIL (until)
+	Span  time.Duration // the event's length, for SanityLong
+}
+
+// CheckSanity looks for likely mistakes in ev as of now. Times are compared as
+// instants, so ev may hold wall-clock times with StartTZ/EndTZ (as batch and
+// create build them) or absolute ones (as ParseICSEvents returns).
+func CheckSanity(ev Event, now time.Time, limits SanityLimits) []SanityIssue {
+	var issues []SanityIssue
+	start, end := ev.Instants()
+	until, hasUntil := ruleUntil(ev)
+
+	if limits.Past > 0 {
+		// A series counts by its UNTIL; one that repeats forever (or by
+		// COUNT) is not checked.
+		last, bounded := end, true
+		if strings.TrimSpace(ev.RRule) != "" && ev.RecurrenceID.IsZero() && !(hasUntil && until.Before(start)) {
+			last, bounded = until, hasUntil
+		}
+		if bounded && last.Before(now.Add(-limits.Past)) {
+			issues = append(issues, SanityIssue{Kind: SanityPast, At: last})
+		}
+	}
+
+	for i, al := range ev.Alarms {
+		fires := al.TriggerTime
+		if al.TriggerIsRelative {
+			fires = start.Add(al.TriggerDuration)
+		}
+		early := !al.TriggerIsRelative && limits.AlarmLead > 0 && fires.Before(start.Add(-limits.AlarmLead))
+		if fires.After(end) || early {
+			issues = append(issues, SanityIssue{Kind: SanityAlarm, Alarm: i, At: fires})
+		}
+	}
+
+	if span := end.Sub(start); !ev.AllDay && limits.MaxDuration > 0 && span > limits.MaxDuration {
+		issues = append(issues, SanityIssue{Kind: SanityLong, Span: span})
+	}
+
+	if hasUntil && until.Before(start) {
+		issues = append(issues, SanityIssue{Kind: SanityUntil, At: until})
+	}
+	return issues
+}
+
+// ruleUntil returns the instant of ev's RRULE UNTIL, if it has one. A local
+// or date-only UNTIL is read in the start's timezone; a date covers the whole
+// day.
+func ruleUntil(ev Event) (time.Time, bool) {
+	for _, part := range strings.Split(ev.RRule, ";") {
+		key, val, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || !strings.EqualFold(strings.TrimPrefix(strings.ToUpper(key), "RRULE:"), "UNTIL") {
+			continue
+		}
+		val = strings.ToUpper(strings.TrimSpace(val))
+		t, err := parseRRuleUntil(val)
+		if err != nil {
+			return time.Time{}, false
+		}
+		if strings.HasSuffix(val, "Z") {
+			return t, true
+		}
+		return instant(t, strings.TrimSpace(ev.StartTZ)), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,80 @@
+package calendar
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func sanityKinds(issues []SanityIssue) string {
+	var kinds []string
+	for _, issue := range issues {
+		kinds = append(kinds, issue.Kind)
+	}
+	return fmt.Sprint(kinds)
+}
+
+func TestCheckSanity(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := func(y int, m time.Month, d, h int) time.Time { return time.Date(y, m, d, h, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name string
+		ev   Event
+		want string
+	}{
+		{"fine", Event{StartTime: at(2025, 6, 2, 9), EndTime: at(2025, 6, 2, 10),
+			Alarms: []Alarm{{TriggerIsRelative: true, TriggerDuration: -15 * time.Minute}}}, "[]"},
+		{"recently over", Event{StartTime: at(2025, 5, 20, 9), EndTime: at(2025, 5, 20, 10)}, "[]"},
+		{"wrong year", Event{StartTime: at(2015, 6, 2, 9), EndTime: at(2015, 6, 2, 10)}, "[past]"},
+		{"open-ended series", Event{StartTime: at(2015, 6, 2, 9), EndTime: at(2015, 6, 2, 10), RRule: "FREQ=WEEKLY"}, "[]"},
+		{"series over long ago", Event{StartTime: at(2015, 6, 2, 9), EndTime: at(2015, 6, 2, 10), RRule: "FREQ=WEEKLY;UNTIL=20150701T000000Z"}, "[past]"},
+		{"series still running", Event{StartTime: at(2015, 6, 2, 9), EndTime: at(2015, 6, 2, 10), RRule: "FREQ=WEEKLY;UNTIL=20251231"}, "[]"},
+		{"alarm after the end", Event{StartTime: at(2025, 6, 2, 9), EndTime: at(2025, 6, 2, 10),
+			Alarms: []Alarm{{TriggerIsRelative: true, TriggerDuration: 2 * time.Hour}}}, "[alarm]"},
+		{"absolute alarm a year early", Event{StartTime: at(2025, 6, 2, 9), EndTime: at(2025, 6, 2, 10),
+			Alarms: []Alarm{{TriggerTime: at(2024, 6, 2, 9)}}}, "[alarm]"},
+		{"absolute alarm the day before", Event{StartTime: at(2025, 6, 2, 9), EndTime: at(2025, 6, 2, 10),
+			Alarms: []Alarm{{TriggerTime: at(2025, 6, 1, 9)}}}, "[]"},
+		{"three-day meeting", Event{StartTime: at(2025, 6, 2, 9), EndTime: at(2025, 6, 5, 10)}, "[long]"},
+		{"three-day all-day event", Event{StartTime: at(2025, 6, 2, 0), EndTime: at(2025, 6, 5, 0), AllDay: true}, "[]"},
+		{"UNTIL before the start", Event{StartTime: at(2025, 6, 2, 9), EndTime: at(2025, 6, 2, 10), RRule: "FREQ=DAILY;UNTIL=20240602T090000Z"}, "[until]"},
+	}
+	for _, tt := range tests {
+		if got := sanityKinds(CheckSanity(tt.ev, now, DefaultSanityLimits)); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	// Past 0 turns the past check off.
+	limits := DefaultSanityLimits
+	limits.Past = 0
+	if issues := CheckSanity(tests[2].ev, now, limits); len(issues) != 0 {
+		t.Errorf("Past 0 should disable the check, got %v", sanityKinds(issues))
+	}
+}
+
+func TestCheckSanityUsesTheStartTimezone(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	// A local UNTIL at the same wall-clock time as a Tokyo start is not before it.
+	ev := Event{
+		StartTime: time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC), EndTime: time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC),
+		StartTZ: "Asia/Tokyo", EndTZ: "Asia/Tokyo", RRule: "FREQ=DAILY;UNTIL=20250602T090000",
+	}
+	if issues := CheckSanity(ev, now, DefaultSanityLimits); len(issues) != 0 {
+		t.Errorf("got %v, want none", sanityKinds(issues))
+	}
+
+	data := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:a\r\nSUMMARY:Exam\r\n" +
+		"DTSTART;TZID=Europe/Madrid:20250602T090000\r\nDTEND;TZID=Europe/Madrid:20250602T100000\r\n" +
+		"BEGIN:VALARM\r\nACTION:DISPLAY\r\nTRIGGER;RELATED=END:PT30M\r\nEND:VALARM\r\n" +
+		"BEGIN:VALARM\r\nACTION:DISPLAY\r\nTRIGGER:-PT1H\r\nEND:VALARM\r\n" +
+		"END:VEVENT\r\nEND:VCALENDAR\r\n"
+	events, err := ParseICSEvents(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues := CheckSanity(events[0], now, DefaultSanityLimits)
+	if sanityKinds(issues) != "[alarm]" || issues[0].Alarm != 0 || !issues[0].At.Equal(time.Date(2025, 6, 2, 8, 30, 0, 0, time.UTC)) {
+		t.Errorf("the RELATED=END alarm fires 30 min after the end, got %+v", issues)
+	}
+}
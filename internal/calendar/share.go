@@ -0,0 +1,41 @@
+package calendar
+
+import (
+	"strings"
+
+	"tempus/internal/constants"
+)
+
+// ShareText is ev as a bare VEVENT, the form phone cameras and scanner apps
+// turn into "add to calendar" when they read it from a QR code. Alarms,
+// attendees and VTIMEZONE blocks are left out to keep the code small. Times
+// are UTC, except that a recurring event keeps its TZID so it repeats at the
+// same local time across DST changes.
+func ShareText(ev Event) string {
+	lines := []string{"BEGIN:VEVENT", "SUMMARY:" + escapeText(ev.Summary)}
+	start, end := ev.Instants()
+	switch tz := strings.TrimSpace(ev.StartTZ); {
+	case ev.AllDay:
+		lines = append(lines,
+			"DTSTART;VALUE=DATE:"+ev.StartTime.Format(constants.ICSFormatDateOnly),
+			"DTEND;VALUE=DATE:"+ev.EndTime.Format(constants.ICSFormatDateOnly))
+	case ev.RRule != "" && tz != "" && tz != "UTC":
+		lines = append(lines,
+			"DTSTART;TZID="+tz+":"+ev.StartTime.Format(constants.ICSFormatLocal),
+			"DTEND;TZID="+tz+":"+ev.StartTime.Add(end.Sub(start)).Format(constants.ICSFormatLocal))
+	default:
+		lines = append(lines,
+			"DTSTART:"+start.UTC().Format(constants.ICSFormatUTC),
+			"DTEND:"+end.UTC().Format(constants.ICSFormatUTC))
+	}
+	if rrule := strings.TrimSpace(ev.RRule); rrule != "" {
+		lines = append(lines, "RRULE:"+rrule)
+	}
+	if loc := strings.TrimSpace(ev.Location); loc != "" {
+		lines = append(lines, "LOCATION:"+escapeText(loc))
+	}
+	if desc := strings.TrimSpace(ev.Description); desc != "" {
+		lines = append(lines, "DESCRIPTION:"+escapeText(desc))
+	}
+	return strings.Join(append(lines, "END:VEVENT"), "\r\n")
+}
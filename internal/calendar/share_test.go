@@ -0,0 +1,81 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShareText(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:a",
+		"SUMMARY:Dentist, check-up",
+		"DTSTART;TZID=Europe/Madrid:20261103T100000",
+		"DTEND;TZID=Europe/Madrid:20261103T104500",
+		"LOCATION:Calle Mayor 5",
+		"BEGIN:VALARM",
+		"TRIGGER:-PT1H",
+		"ACTION:DISPLAY",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:b",
+		"SUMMARY:Yoga",
+		"DTSTART;TZID=Europe/Madrid:20261020T190000",
+		"DTEND;TZID=Europe/Madrid:20261020T200000",
+		"RRULE:FREQ=WEEKLY;COUNT=4",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:c",
+		"SUMMARY:Holiday",
+		"DTSTART;VALUE=DATE:20261208",
+		"DTEND;VALUE=DATE:20261209",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	events, err := ParseICSEvents(ics)
+	if err != nil {
+		t.Fatalf("ParseICSEvents: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3", len(events))
+	}
+
+	cases := []struct {
+		name string
+		want []string
+	}{
+		{"timed", []string{
+			"BEGIN:VEVENT",
+			`SUMMARY:Dentist\, check-up`,
+			"DTSTART:20261103T090000Z",
+			"DTEND:20261103T094500Z",
+			"LOCATION:Calle Mayor 5",
+			"END:VEVENT",
+		}},
+		{"recurring keeps TZID", []string{
+			"BEGIN:VEVENT",
+			"SUMMARY:Yoga",
+			"DTSTART;TZID=Europe/Madrid:20261020T190000",
+			"DTEND;TZID=Europe/Madrid:20261020T200000",
+			"RRULE:FREQ=WEEKLY;COUNT=4",
+			"END:VEVENT",
+		}},
+		{"all-day", []string{
+			"BEGIN:VEVENT",
+			"SUMMARY:Holiday",
+			"DTSTART;VALUE=DATE:20261208",
+			"DTEND;VALUE=DATE:20261209",
+			"END:VEVENT",
+		}},
+	}
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ShareText(events[i])
+			if want := strings.Join(tc.want, "\r\n"); got != want {
+				t.Errorf("ShareText =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
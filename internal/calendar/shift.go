@@ -0,0 +1,469 @@
+package calendar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"tempus/internal/constants"
+)
+
+// Shift describes a bulk reschedule: move events by Days calendar days plus By,
+// then optionally re-express them in ToTZ.
+//
+// By default a timezone remap keeps the wall clock ("09:00 Madrid" becomes
+// "09:00 New York"), which is what you want when a trip moves. With KeepInstant
+// the moment in time is kept instead ("09:00 Madrid" becomes "03:00 New York").
+// Times pinned to UTC always keep their instant.
+type Shift struct {
+	Days        int
+	By          time.Duration
+	ToTZ        string
+	KeepInstant bool
+}
+
+// IsZero reports whether s would change nothing.
+func (s Shift) IsZero() bool {
+	return s.Days == 0 && s.By == 0 && strings.TrimSpace(s.ToTZ) == ""
+}
+
+// wholeDays returns the day offset applied to all-day (DATE) values.
+func (s Shift) wholeDays() (int, error) {
+	if s.By%(24*time.Hour) != 0 {
+		return 0, fmt.Errorf("all-day events can only move by whole days (got %s)", s.By)
+	}
+	return s.Days + int(s.By/(24*time.Hour)), nil
+}
+
+// moveWallClock applies s to a wall-clock time in tz ("" = floating or UTC,
+// see utc). It returns the new wall clock (in wall's location) and its TZID.
+func (s Shift) moveWallClock(wall time.Time, tz string, utc bool) (time.Time, string, error) {
+	loc := time.UTC
+	if tz != "" {
+		l, err := time.LoadLocation(tz)
+		switch {
+		case err == nil:
+			loc = l
+		case s.ToTZ != "" && s.KeepInstant:
+			return time.Time{}, "", fmt.Errorf("unknown timezone %q: %w", tz, err)
+		}
+	}
+
+	t := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, loc)
+	t = t.AddDate(0, 0, s.Days).Add(s.By)
+
+	newTZ := tz
+	if target := strings.TrimSpace(s.ToTZ); target != "" {
+		targetLoc, err := time.LoadLocation(target)
+		if err != nil {
+			return time.Time{}, "", fmt.Errorf("invalid timezone %q: %w", target, err)
+		}
+		if utc || s.KeepInstant {
+			t = t.In(targetLoc)
+		}
+		newTZ = target
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, wall.Location()), newTZ, nil
+}
+
+// instant returns the absolute time of a wall clock in tz (UTC if unknown/empty).
+func instant(wall time.Time, tz string) time.Time {
+	loc := time.UTC
+	if tz != "" {
+		if l, err := time.LoadLocation(tz); err == nil {
+			loc = l
+		}
+	}
+	return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, loc)
+}
+
+// ApplyShift moves e (start, end, EXDATEs, RDATEs, RECURRENCE-ID and absolute alarms) according to s
+// and bumps its SEQUENCE.
+func (e *Event) ApplyShift(s Shift) error {
+	if s.IsZero() {
+		return nil
+	}
+
+	if e.AllDay {
+		days, err := s.wholeDays()
+		if err != nil {
+			return err
+		}
+		e.StartTime = e.StartTime.AddDate(0, 0, days)
+		e.EndTime = e.EndTime.AddDate(0, 0, days)
+		for _, dates := range [][]time.Time{e.ExDates, e.RDates} {
+			for i := range dates {
+				dates[i] = dates[i].AddDate(0, 0, days)
+			}
+		}
+		if !e.RecurrenceID.IsZero() {
+			e.RecurrenceID = e.RecurrenceID.AddDate(0, 0, days)
+		}
+		e.shiftAbsoluteAlarms(time.Duration(days) * 24 * time.Hour)
+		e.touchAfterShift()
+		return nil
+	}
+
+	oldStart := instant(e.StartTime, e.StartTZ)
+	startTZ, endTZ := e.StartTZ, e.EndTZ
+	start, newStartTZ, err := s.moveWallClock(e.StartTime, startTZ, startTZ == "")
+	if err != nil {
+		return err
+	}
+	end, newEndTZ, err := s.moveWallClock(e.EndTime, endTZ, endTZ == "")
+	if err != nil {
+		return err
+	}
+	for _, dates := range [][]time.Time{e.ExDates, e.RDates} {
+		for i, x := range dates {
+			moved, _, err := s.moveWallClock(x, startTZ, startTZ == "")
+			if err != nil {
+				return err
+			}
+			dates[i] = moved
+		}
+	}
+	if !e.RecurrenceID.IsZero() {
+		if e.RecurrenceID, _, err = s.moveWallClock(e.RecurrenceID, startTZ, startTZ == ""); err != nil {
+			return err
+		}
+	}
+
+	e.StartTime, e.EndTime = start, end
+	e.StartTZ, e.EndTZ = newStartTZ, newEndTZ
+	e.shiftAbsoluteAlarms(instant(e.StartTime, e.StartTZ).Sub(oldStart))
+	e.touchAfterShift()
+	return nil
+}
+
+func (e *Event) shiftAbsoluteAlarms(delta time.Duration) {
+	for i := range e.Alarms {
+		if !e.Alarms[i].TriggerIsRelative && !e.Alarms[i].TriggerTime.IsZero() {
+			e.Alarms[i].TriggerTime = e.Alarms[i].TriggerTime.Add(delta)
+		}
+	}
+}
+
+func (e *Event) touchAfterShift() {
+	e.Sequence++
+	e.LastMod = wallClock.Now().UTC()
+}
+
+//
+// Shifting existing .ics files
+//
+
+// ShiftedEvent records one event changed by ShiftICS.
+type ShiftedEvent struct {
+	UID      string
+	Summary  string
+	OldStart string
+	NewStart string
+}
+
+// ICSEventInfo exposes the properties ShiftICS filters on.
+type ICSEventInfo struct {
+	UID        string
+	Summary    string
+	Location   string
+	Categories []string
+}
+
+// ShiftICS applies s to every VEVENT of an .ics document accepted by match
+// (nil matches all). DTSTART, DTEND, EXDATE, RDATE, RECURRENCE-ID, RRULE UNTIL
+// and absolute alarm triggers move together; SEQUENCE is bumped and
+// DTSTAMP/LAST-MODIFIED refreshed. Everything else is copied verbatim.
+func ShiftICS(data string, s Shift, match func(ICSEventInfo) bool) (string, []ShiftedEvent, error) {
+	lines := unfoldICS(data)
+	now := wallClock.Now().UTC().Format(constants.ICSFormatUTC)
+
+	var (
+		event    []string
+		inEvent  bool
+		body     []string
+		shifted  []ShiftedEvent
+		hasVTZ   bool
+		vtzIDs   = map[string]bool{}
+		firstEvt = -1
+	)
+	for _, line := range lines {
+		upper := strings.ToUpper(line)
+		switch {
+		case upper == "BEGIN:VEVENT":
+			inEvent, event = true, []string{line}
+			if firstEvt < 0 {
+				firstEvt = len(body)
+			}
+			continue
+		case upper == "END:VEVENT" && inEvent:
+			inEvent = false
+			event = append(event, line)
+			info := icsEventInfo(event)
+			if match == nil || match(info) {
+				moved, change, err := shiftICSEvent(event, s, now)
+				if err != nil {
+					label := info.Summary
+					if label == "" {
+						label = info.UID
+					}
+					return "", nil, fmt.Errorf("event %q: %w", label, err)
+				}
+				change.UID, change.Summary = info.UID, info.Summary
+				shifted = append(shifted, change)
+				event = moved
+			}
+			body = append(body, event...)
+			continue
+		case inEvent:
+			event = append(event, line)
+			continue
+		case upper == "BEGIN:VTIMEZONE":
+			hasVTZ = true
+		case strings.HasPrefix(upper, "TZID:"):
+			vtzIDs[strings.TrimSpace(line[len("TZID:"):])] = true
+		}
+		body = append(body, line)
+	}
+	if inEvent {
+		return "", nil, fmt.Errorf("unterminated VEVENT")
+	}
+
+	// Keep VTIMEZONE-using files self-contained when remapping to a new zone.
+	target := strings.TrimSpace(s.ToTZ)
+	if hasVTZ && target != "" && !vtzIDs[target] && len(shifted) > 0 && firstEvt >= 0 {
+		if vtz := knownVTZ(target); vtz != "" {
+			vtzLines := strings.Split(strings.TrimSuffix(vtz, "\r\n"), "\r\n")
+			body = append(body[:firstEvt], append(vtzLines, body[firstEvt:]...)...)
+		}
+	}
+
+	var out strings.Builder
+	for _, line := range body {
+		writeLine(&out, line)
+	}
+	return out.String(), shifted, nil
+}
+
+func icsEventInfo(event []string) ICSEventInfo {
+	var info ICSEventInfo
+	depth := 0
+	for _, line := range event[1 : len(event)-1] {
+		p := parseICSLine(line)
+		switch {
+		case p.name == "BEGIN":
+			depth++
+		case p.name == "END":
+			depth--
+		case depth > 0:
+		case p.name == "UID":
+			info.UID = p.value
+		case p.name == "SUMMARY":
+			info.Summary = unescapeText(p.value)
+		case p.name == "LOCATION":
+			info.Location = unescapeText(p.value)
+		case p.name == "CATEGORIES":
+			for _, c := range strings.Split(p.value, ",") {
+				if c = strings.TrimSpace(unescapeText(c)); c != "" {
+					info.Categories = append(info.Categories, c)
+				}
+			}
+		}
+	}
+	return info
+}
+
+func shiftICSEvent(event []string, s Shift, now string) ([]string, ShiftedEvent, error) {
+	var change ShiftedEvent
+
+	// DTSTART's zone and the instant delta drive RRULE UNTIL and absolute triggers.
+	var startTZ string
+	var delta time.Duration
+	for _, line := range event {
+		p := parseICSLine(line)
+		if p.name != "DTSTART" {
+			continue
+		}
+		startTZ = p.param("TZID")
+		moved, err := shiftICSDateList(p, s)
+		if err != nil {
+			return nil, change, err
+		}
+		oldAt, err := icsInstant(p)
+		if err != nil {
+			return nil, change, err
+		}
+		newAt, err := icsInstant(moved)
+		if err != nil {
+			return nil, change, err
+		}
+		delta = newAt.Sub(oldAt)
+		change.OldStart, change.NewStart = p.describe(), moved.describe()
+		break
+	}
+
+	out := []string{event[0]}
+	depth, sawSequence := 0, false
+	for _, line := range event[1 : len(event)-1] {
+		p := parseICSLine(line)
+		switch {
+		case p.name == "BEGIN":
+			depth++
+		case p.name == "END":
+			depth--
+		case depth > 0:
+			if p.name == "TRIGGER" && strings.EqualFold(p.param("VALUE"), "DATE-TIME") {
+				at, err := time.Parse(constants.ICSFormatUTC, p.value)
+				if err != nil {
+					return nil, change, fmt.Errorf("invalid TRIGGER %q", p.value)
+				}
+				p.value = at.Add(delta).Format(constants.ICSFormatUTC)
+				line = p.String()
+			}
+		case p.name == "DTSTART" || p.name == "DTEND" || p.name == "EXDATE" || p.name == "RDATE" || p.name == "RECURRENCE-ID":
+			moved, err := shiftICSDateList(p, s)
+			if err != nil {
+				return nil, change, fmt.Errorf("%s: %w", p.name, err)
+			}
+			line = moved.String()
+		case p.name == "RRULE":
+			rule, err := shiftRRuleUntil(p.value, startTZ, s, delta)
+			if err != nil {
+				return nil, change, err
+			}
+			p.value = rule
+			line = p.String()
+		case p.name == "SEQUENCE":
+			n, _ := strconv.Atoi(strings.TrimSpace(p.value))
+			p.value = strconv.Itoa(n + 1)
+			line = p.String()
+			sawSequence = true
+		case p.name == "DTSTAMP" || p.name == "LAST-MODIFIED":
+			p.params, p.value = nil, now
+			line = p.String()
+		}
+		out = append(out, line)
+	}
+	out = append(out, event[len(event)-1])
+	if !sawSequence {
+		out = addEventProps(out, "SEQUENCE:1")
+	}
+	return out, change, nil
+}
+
+// shiftICSDateList moves every value of a DATE / DATE-TIME (list) property.
+func shiftICSDateList(p icsLine, s Shift) (icsLine, error) {
+	if strings.EqualFold(p.param("VALUE"), "PERIOD") {
+		return p, nil // periods are rare; leave them untouched
+	}
+	p.params = append([]string(nil), p.params...) // don't alias the caller's line
+	tz := p.param("TZID")
+	values := strings.Split(p.value, ",")
+	newTZ := tz
+	for i, raw := range values {
+		raw = strings.TrimSpace(raw)
+		if len(raw) == 8 { // DATE
+			days, err := s.wholeDays()
+			if err != nil {
+				return p, err
+			}
+			d, err := time.Parse(constants.ICSFormatDateOnly, raw)
+			if err != nil {
+				return p, fmt.Errorf("invalid date %q", raw)
+			}
+			values[i] = d.AddDate(0, 0, days).Format(constants.ICSFormatDateOnly)
+			continue
+		}
+
+		utc := strings.HasSuffix(raw, "Z")
+		wall, err := time.Parse(constants.ICSFormatLocal, strings.TrimSuffix(raw, "Z"))
+		if err != nil {
+			return p, fmt.Errorf("invalid date-time %q", raw)
+		}
+		moved, movedTZ, err := s.moveWallClock(wall, tz, utc)
+		if err != nil {
+			return p, err
+		}
+		newTZ = movedTZ
+		if movedTZ == "" && utc {
+			values[i] = moved.Format(constants.ICSFormatUTC)
+		} else {
+			values[i] = moved.Format(constants.ICSFormatLocal)
+		}
+	}
+	p.value = strings.Join(values, ",")
+	if newTZ != "" {
+		p.setParam("TZID", newTZ)
+	}
+	return p, nil
+}
+
+// icsInstant returns the absolute time of the first value of a DATE/DATE-TIME property.
+func icsInstant(p icsLine) (time.Time, error) {
+	raw := strings.TrimSpace(strings.SplitN(p.value, ",", 2)[0])
+	switch {
+	case len(raw) == 8:
+		return time.Parse(constants.ICSFormatDateOnly, raw)
+	case strings.HasSuffix(raw, "Z"):
+		return time.Parse(constants.ICSFormatUTC, raw)
+	}
+	wall, err := time.Parse(constants.ICSFormatLocal, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date-time %q", raw)
+	}
+	return instant(wall, p.param("TZID")), nil
+}
+
+// icsInstantList returns the absolute times of every value of an EXDATE or
+// RDATE property, skipping the ones it cannot read (such as RDATE periods).
+func icsInstantList(p icsLine) []time.Time {
+	var out []time.Time
+	for _, value := range strings.Split(p.value, ",") {
+		x := p
+		x.value = value
+		if t, err := icsInstant(x); err == nil {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func shiftRRuleUntil(rule, startTZ string, s Shift, delta time.Duration) (string, error) {
+	parts := strings.Split(rule, ";")
+	for i, part := range parts {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || !strings.EqualFold(key, "UNTIL") {
+			continue
+		}
+		switch {
+		case len(val) == 8:
+			days, err := s.wholeDays()
+			if err != nil {
+				return "", err
+			}
+			d, err := time.Parse(constants.ICSFormatDateOnly, val)
+			if err != nil {
+				return "", fmt.Errorf("invalid RRULE UNTIL %q", val)
+			}
+			val = d.AddDate(0, 0, days).Format(constants.ICSFormatDateOnly)
+		case strings.HasSuffix(val, "Z"):
+			t, err := time.Parse(constants.ICSFormatUTC, val)
+			if err != nil {
+				return "", fmt.Errorf("invalid RRULE UNTIL %q", val)
+			}
+			val = t.Add(delta).Format(constants.ICSFormatUTC)
+		default:
+			wall, err := time.Parse(constants.ICSFormatLocal, val)
+			if err != nil {
+				return "", fmt.Errorf("invalid RRULE UNTIL %q", val)
+			}
+			moved, _, err := s.moveWallClock(wall, startTZ, false)
+			if err != nil {
+				return "", err
+			}
+			val = moved.Format(constants.ICSFormatLocal)
+		}
+		parts[i] = key + "=" + val
+	}
+	return strings.Join(parts, ";"), nil
+}
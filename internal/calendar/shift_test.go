@@ -0,0 +1,129 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const shiftTestICS = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup@test\r\n" +
+	"SUMMARY:Standup\r\n" +
+	"CATEGORIES:Work\r\n" +
+	"DTSTART;TZID=Europe/Madrid:20250328T090000\r\n" +
+	"DTEND;TZID=Europe/Madrid:20250328T091500\r\n" +
+	"RRULE:FREQ=DAILY;UNTIL=20250410T070000Z\r\n" +
+	"EXDATE;TZID=Europe/Madrid:20250401T090000\r\n" +
+	"SEQUENCE:2\r\n" +
+	"X-CUSTOM;FOO=\"a:b\":kept\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"TRIGGER;VALUE=DATE-TIME:20250328T073000Z\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:holiday@test\r\n" +
+	"SUMMARY:Day off\r\n" +
+	"CATEGORIES:Personal\r\n" +
+	"DTSTART;VALUE=DATE:20250331\r\n" +
+	"DTEND;VALUE=DATE:20250401\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestShiftICSMovesAllDateProperties(t *testing.T) {
+	out, changes, err := ShiftICS(shiftTestICS, Shift{Days: 7}, nil)
+	if err != nil {
+		t.Fatalf("ShiftICS() failed: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 shifted events, got %d", len(changes))
+	}
+
+	for _, want := range []string{
+		"DTSTART;TZID=Europe/Madrid:20250404T090000",
+		"DTEND;TZID=Europe/Madrid:20250404T091500",
+		"EXDATE;TZID=Europe/Madrid:20250408T090000",
+		"UNTIL=20250417T060000Z",
+		"TRIGGER;VALUE=DATE-TIME:20250404T063000Z", // same 30m lead; DST moved the instant
+		"SEQUENCE:3",
+		"DTSTART;VALUE=DATE:20250407",
+		"DTEND;VALUE=DATE:20250408",
+		"SEQUENCE:1",
+		"X-CUSTOM;FOO=\"a:b\":kept",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestShiftICSFilterAndTimezoneRemap(t *testing.T) {
+	onlyWork := func(info ICSEventInfo) bool {
+		return len(info.Categories) > 0 && info.Categories[0] == "Work"
+	}
+
+	out, changes, err := ShiftICS(shiftTestICS, Shift{ToTZ: "America/New_York"}, onlyWork)
+	if err != nil {
+		t.Fatalf("ShiftICS() failed: %v", err)
+	}
+	if len(changes) != 1 || changes[0].UID != "standup@test" {
+		t.Fatalf("expected only the standup to move, got %+v", changes)
+	}
+	if changes[0].OldStart != "20250328T090000 Europe/Madrid" || changes[0].NewStart != "20250328T090000 America/New_York" {
+		t.Errorf("unexpected change description: %+v", changes[0])
+	}
+	// Wall clock kept: 09:00 Madrid (08:00Z) becomes 09:00 New York (13:00Z), so the absolute alarm moves +5h.
+	if !strings.Contains(out, "TRIGGER;VALUE=DATE-TIME:20250328T123000Z") {
+		t.Errorf("absolute alarm not moved with the event:\n%s", out)
+	}
+	if !strings.Contains(out, "DTSTART;VALUE=DATE:20250331") {
+		t.Errorf("filtered-out event should be untouched:\n%s", out)
+	}
+
+	out, _, err = ShiftICS(shiftTestICS, Shift{ToTZ: "America/New_York", KeepInstant: true}, onlyWork)
+	if err != nil {
+		t.Fatalf("ShiftICS() failed: %v", err)
+	}
+	if !strings.Contains(out, "DTSTART;TZID=America/New_York:20250328T040000") {
+		t.Errorf("expected instant-preserving conversion:\n%s", out)
+	}
+}
+
+func TestShiftICSRejectsPartialDayMoveOfAllDayEvent(t *testing.T) {
+	if _, _, err := ShiftICS(shiftTestICS, Shift{By: 90 * time.Minute}, nil); err == nil {
+		t.Fatal("expected error when moving an all-day event by 90m")
+	}
+}
+
+func TestEventApplyShift(t *testing.T) {
+	start := time.Date(2025, 3, 29, 10, 0, 0, 0, time.UTC)
+	ev := NewEvent("Dentist", start, start.Add(time.Hour))
+	ev.SetTimezone("Europe/Madrid")
+	ev.ExDates = []time.Time{start.AddDate(0, 0, 1)}
+	ev.Alarms = []Alarm{
+		{TriggerIsRelative: true, TriggerDuration: -15 * time.Minute},
+		{TriggerTime: time.Date(2025, 3, 29, 8, 30, 0, 0, time.UTC)},
+	}
+
+	// Crossing the DST change keeps the 10:00 wall clock; the instant moves 23h.
+	if err := ev.ApplyShift(Shift{Days: 1}); err != nil {
+		t.Fatalf("ApplyShift() failed: %v", err)
+	}
+	if got := ev.StartTime.Format("2006-01-02 15:04"); got != "2025-03-30 10:00" {
+		t.Errorf("start = %s", got)
+	}
+	if got := ev.ExDates[0].Format("2006-01-02 15:04"); got != "2025-03-31 10:00" {
+		t.Errorf("exdate = %s", got)
+	}
+	if ev.Alarms[0].TriggerDuration != -15*time.Minute {
+		t.Errorf("relative alarm changed: %v", ev.Alarms[0].TriggerDuration)
+	}
+	if want := time.Date(2025, 3, 30, 7, 30, 0, 0, time.UTC); !ev.Alarms[1].TriggerTime.Equal(want) {
+		t.Errorf("absolute alarm = %v, want %v", ev.Alarms[1].TriggerTime, want)
+	}
+	if ev.Sequence != 1 {
+		t.Errorf("sequence = %d, want 1", ev.Sequence)
+	}
+}
@@ -0,0 +1,182 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoSolarEvent is returned by SunTime when the sun never reaches the
+// anchor's position that day, as with sunrise during the polar night.
+var ErrNoSolarEvent = errors.New("the sun does not reach that position on this day")
+
+// solarAnchor is a position of the sun: the altitude it crosses, rising or
+// setting. Noon is the sun's highest point; Asr is where shadows grow to
+// their length at noon plus the object's height.
+type solarAnchor struct {
+	altitude float64 // degrees
+	rising   bool
+	noon     bool
+	asr      bool
+}
+
+// solarAnchors are the names events can be anchored to. Prayer times follow
+// the Muslim World League convention: Fajr at 18° and Isha at 17° below the
+// horizon, standard (shadow length 1) Asr.
+var solarAnchors = map[string]solarAnchor{
+	"dawn":    {altitude: -6, rising: true},
+	"sunrise": {altitude: -0.833, rising: true},
+	"noon":    {noon: true},
+	"sunset":  {altitude: -0.833},
+	"dusk":    {altitude: -6},
+	"fajr":    {altitude: -18, rising: true},
+	"dhuhr":   {noon: true},
+	"asr":     {asr: true},
+	"maghrib": {altitude: -0.833},
+	"isha":    {altitude: -17},
+}
+
+// SolarAnchorNames lists the names accepted by ParseSolarTime, sorted.
+func SolarAnchorNames() []string {
+	names := make([]string, 0, len(solarAnchors))
+	for name := range solarAnchors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SolarTime is a time relative to the sun, such as "sunrise+30m".
+type SolarTime struct {
+	Date   string // YYYY-MM-DD, empty for none
+	Anchor string
+	Offset time.Duration
+}
+
+var solarTimeRe = regexp.MustCompile(`^(?:(\d{4}-\d{2}-\d{2})\s+)?([a-z]+)\s*(?:([+-])\s*(\S+))?$`)
+
+// ParseSolarTime parses "[YYYY-MM-DD ]ANCHOR[±OFFSET]", e.g. "sunset-1h" or
+// "2025-05-01 sunrise+30m". ok is false when s is not a solar time at all;
+// err reports a solar time with a bad offset.
+func ParseSolarTime(s string) (st SolarTime, ok bool, err error) {
+	m := solarTimeRe.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if m == nil {
+		return SolarTime{}, false, nil
+	}
+	if _, known := solarAnchors[m[2]]; !known {
+		return SolarTime{}, false, nil
+	}
+	st = SolarTime{Date: m[1], Anchor: m[2]}
+	if m[3] != "" {
+		if st.Offset, err = ParseHumanDuration(m[4]); err != nil || st.Offset < 0 {
+			return SolarTime{}, true, fmt.Errorf("invalid offset %q in %q (e.g. sunrise+30m)", m[4], s)
+		}
+		if m[3] == "-" {
+			st.Offset = -st.Offset
+		}
+	}
+	if st.Date != "" {
+		if _, err := time.Parse("2006-01-02", st.Date); err != nil {
+			return SolarTime{}, true, fmt.Errorf("invalid date in %q", s)
+		}
+	}
+	return st, true, nil
+}
+
+// On returns the solar time on the given day (its date in loc) at lat/lon.
+func (st SolarTime) On(day time.Time, lat, lon float64, loc *time.Location) (time.Time, error) {
+	t, err := SunTime(st.Anchor, day, lat, lon, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.Add(st.Offset), nil
+}
+
+// ParseGeo parses "LAT,LON" in decimal degrees, e.g. "40.41,-3.70".
+func ParseGeo(s string) (lat, lon float64, err error) {
+	latS, lonS, ok := strings.Cut(s, ",")
+	if ok {
+		lat, err = strconv.ParseFloat(strings.TrimSpace(latS), 64)
+	}
+	if ok && err == nil {
+		lon, err = strconv.ParseFloat(strings.TrimSpace(lonS), 64)
+	}
+	if !ok || err != nil || math.Abs(lat) > 90 || math.Abs(lon) > 180 {
+		return 0, 0, fmt.Errorf("invalid coordinates %q (want LAT,LON in degrees, e.g. 40.41,-3.70)", s)
+	}
+	return lat, lon, nil
+}
+
+// SunTime computes when the named solar anchor (see SolarAnchorNames) happens
+// on the given day (its date in loc) at latitude lat and longitude lon,
+// positive north and east. Times are accurate to about a minute.
+func SunTime(anchor string, day time.Time, lat, lon float64, loc *time.Location) (time.Time, error) {
+	a, ok := solarAnchors[strings.ToLower(anchor)]
+	if !ok {
+		return time.Time{}, fmt.Errorf("unknown solar time %q (use %s)", anchor, strings.Join(SolarAnchorNames(), ", "))
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	y, m, d := day.In(loc).Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	jd0 := float64(midnight.Unix())/86400 + 2440587.5
+
+	// Start from the local solar noon and refine: the sun's declination and
+	// the equation of time change a little during the day.
+	minutes := 720 - 4*lon
+	for i := 0; i < 3; i++ {
+		decl, eqTime := sunPosition(jd0 + minutes/1440)
+		if a.noon {
+			minutes = 720 - 4*lon - eqTime
+			continue
+		}
+		altitude := a.altitude
+		if a.asr {
+			altitude = degrees(math.Atan(1 / (1 + math.Tan(radians(math.Abs(lat-decl))))))
+		}
+		cosH := (math.Sin(radians(altitude)) - math.Sin(radians(lat))*math.Sin(radians(decl))) /
+			(math.Cos(radians(lat)) * math.Cos(radians(decl)))
+		if cosH < -1 || cosH > 1 {
+			return time.Time{}, ErrNoSolarEvent
+		}
+		hourAngle := degrees(math.Acos(cosH))
+		if a.rising {
+			hourAngle = -hourAngle
+		}
+		minutes = 720 - 4*(lon-hourAngle) - eqTime
+	}
+	return midnight.Add(time.Duration(minutes * float64(time.Minute))).Round(time.Minute).In(loc), nil
+}
+
+// sunPosition returns the sun's declination (degrees) and the equation of
+// time (minutes) at a Julian day, following the NOAA solar calculator.
+func sunPosition(jd float64) (decl, eqTime float64) {
+	t := (jd - 2451545) / 36525
+	meanLong := math.Mod(280.46646+t*(36000.76983+t*0.0003032), 360)
+	meanAnomaly := 357.52911 + t*(35999.05029-0.0001537*t)
+	eccentricity := 0.016708634 - t*(0.000042037+0.0000001267*t)
+	center := math.Sin(radians(meanAnomaly))*(1.914602-t*(0.004817+0.000014*t)) +
+		math.Sin(radians(2*meanAnomaly))*(0.019993-0.000101*t) +
+		math.Sin(radians(3*meanAnomaly))*0.000289
+	omega := 125.04 - 1934.136*t
+	apparentLong := meanLong + center - 0.00569 - 0.00478*math.Sin(radians(omega))
+	obliquity := 23 + (26+(21.448-t*(46.815+t*(0.00059-t*0.001813)))/60)/60 + 0.00256*math.Cos(radians(omega))
+
+	decl = degrees(math.Asin(math.Sin(radians(obliquity)) * math.Sin(radians(apparentLong))))
+	y := math.Pow(math.Tan(radians(obliquity/2)), 2)
+	l0, mA := radians(meanLong), radians(meanAnomaly)
+	eqTime = 4 * degrees(y*math.Sin(2*l0)-2*eccentricity*math.Sin(mA)+
+		4*eccentricity*y*math.Sin(mA)*math.Cos(2*l0)-
+		0.5*y*y*math.Sin(4*l0)-1.25*eccentricity*eccentricity*math.Sin(2*mA))
+	return decl, eqTime
+}
+
+func radians(deg float64) float64 { return deg * math.Pi / 180 }
+
+func degrees(rad float64) float64 { return rad * 180 / math.Pi }
@@ -0,0 +1,94 @@
+package calendar
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSunTime(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Skip("Europe/Madrid not available")
+	}
+	day := time.Date(2025, 6, 21, 0, 0, 0, 0, madrid)
+	// Reference times for Madrid (40.4168, -3.7038) on the June solstice,
+	// within the calculation's one-minute accuracy.
+	for anchor, want := range map[string]string{
+		"sunrise": "06:45",
+		"noon":    "14:17",
+		"sunset":  "21:49",
+		"dawn":    "06:12",
+		"dusk":    "22:22",
+		"fajr":    "04:40",
+		"asr":     "18:16",
+		"isha":    "23:45",
+	} {
+		got, err := SunTime(anchor, day, 40.4168, -3.7038, madrid)
+		if err != nil {
+			t.Errorf("SunTime(%s) returned error: %v", anchor, err)
+			continue
+		}
+		if got.Format("15:04") != want || got.Format("2006-01-02") != "2025-06-21" {
+			t.Errorf("SunTime(%s) = %s, want %s", anchor, got.Format("2006-01-02 15:04"), want)
+		}
+	}
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("America/New_York not available")
+	}
+	if got, _ := SunTime("sunrise", time.Date(2025, 1, 15, 0, 0, 0, 0, newYork), 40.7128, -74.006, newYork); got.Format("15:04") != "07:18" {
+		t.Errorf("New York winter sunrise at %s, want 07:18", got.Format("15:04"))
+	}
+}
+
+func TestSunTimeMidnightSun(t *testing.T) {
+	day := time.Date(2025, 6, 21, 0, 0, 0, 0, time.UTC)
+	if _, err := SunTime("sunset", day, 69.65, 18.96, time.UTC); !errors.Is(err, ErrNoSolarEvent) {
+		t.Errorf("expected no sunset in Tromsø at midsummer, got %v", err)
+	}
+	if _, err := SunTime("noon", day, 69.65, 18.96, time.UTC); err != nil {
+		t.Errorf("noon always exists, got %v", err)
+	}
+	if _, err := SunTime("moonrise", day, 0, 0, time.UTC); err == nil {
+		t.Error("expected an unknown anchor error")
+	}
+}
+
+func TestParseSolarTime(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    SolarTime
+		isSolar bool
+	}{
+		{"sunrise+30m", SolarTime{Anchor: "sunrise", Offset: 30 * time.Minute}, true},
+		{"2025-05-01 Sunset - 1h", SolarTime{Date: "2025-05-01", Anchor: "sunset", Offset: -time.Hour}, true},
+		{"fajr", SolarTime{Anchor: "fajr"}, true},
+		{"2025-05-01 09:00", SolarTime{}, false},
+		{"tomorrow", SolarTime{}, false},
+	}
+	for _, tt := range tests {
+		got, ok, err := ParseSolarTime(tt.in)
+		if err != nil || ok != tt.isSolar || got != tt.want {
+			t.Errorf("ParseSolarTime(%q) = %+v, %v, %v", tt.in, got, ok, err)
+		}
+	}
+	for _, in := range []string{"sunrise+soon", "2025-13-01 sunrise"} {
+		if _, ok, err := ParseSolarTime(in); !ok || err == nil {
+			t.Errorf("ParseSolarTime(%q): expected an error", in)
+		}
+	}
+}
+
+func TestParseGeo(t *testing.T) {
+	lat, lon, err := ParseGeo("40.41, -3.70")
+	if err != nil || lat != 40.41 || lon != -3.70 {
+		t.Errorf("ParseGeo = %v, %v, %v", lat, lon, err)
+	}
+	for _, s := range []string{"", "40.41", "north,west", "91,0", "0,181"} {
+		if _, _, err := ParseGeo(s); err == nil {
+			t.Errorf("ParseGeo(%q): expected an error", s)
+		}
+	}
+}
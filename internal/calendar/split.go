@@ -0,0 +1,456 @@
+package calendar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"tempus/internal/constants"
+)
+
+// SeriesSplit describes a "this and following" edit of a recurring event: the
+// series stops before At and a new series takes over from the first
+// occurrence on or after At, with the changes below applied.
+type SeriesSplit struct {
+	UID         string        // the series to split; empty picks the file's only recurring event
+	At          time.Time     // first day of the new series; only the date counts
+	Clock       string        // "HH:MM" start of the new series in the series' timezone; empty keeps it
+	Duration    time.Duration // length of the new series' events; 0 keeps it
+	RRule       string        // rule of the new series; empty keeps the old one (and what is left of its COUNT)
+	Summary     string        // empty values keep the old ones
+	Location    string
+	Description string
+}
+
+// SplitResult reports what SplitICS did.
+type SplitResult struct {
+	UID       string // the original series
+	NewUID    string // the series that takes over
+	Summary   string
+	Kept      int    // occurrences the rule still gives the original series
+	NewStart  string // DTSTART of the new series, with its TZID
+	Overrides int    // moved occurrences that now belong to the new series
+}
+
+// SplitICS splits a recurring event of an .ics document in two. The original
+// keeps its UID and ends the day before At (with COUNT when it had one, else
+// UNTIL); its SEQUENCE is bumped so calendar apps apply the change. The new
+// series gets a UID derived from the original and At, starts at the first
+// occurrence on or after At, and takes over the EXDATEs, RDATEs and moved
+// occurrences from that day on. Everything else is copied verbatim.
+func SplitICS(data string, s SeriesSplit) (string, SplitResult, error) {
+	var res SplitResult
+	segments, err := splitICSEvents(data)
+	if err != nil {
+		return "", res, err
+	}
+
+	master := -1
+	uids := map[string]bool{}
+	for i, seg := range segments {
+		if !seg.event {
+			continue
+		}
+		uid := icsEventInfo(seg.lines).UID
+		uids[uid] = true
+		_, recurring := topLevelProp(seg.lines, "RRULE")
+		_, override := topLevelProp(seg.lines, "RECURRENCE-ID")
+		if !recurring || override || (s.UID != "" && uid != s.UID) {
+			continue
+		}
+		if master >= 0 {
+			return "", res, fmt.Errorf("several recurring events in the file; pick one by UID")
+		}
+		master = i
+	}
+	if master < 0 {
+		if s.UID != "" {
+			return "", res, fmt.Errorf("no recurring event with UID %q", s.UID)
+		}
+		return "", res, fmt.Errorf("no recurring event found")
+	}
+	lines := segments[master].lines
+	info := icsEventInfo(lines)
+	res.UID, res.Summary = info.UID, info.Summary
+
+	dtstart, _ := topLevelProp(lines, "DTSTART")
+	style := icsTimeStyleOf(dtstart)
+	start, err := style.parse(dtstart.value)
+	if err != nil {
+		return "", res, fmt.Errorf("DTSTART: %w", err)
+	}
+	rrule, _ := topLevelProp(lines, "RRULE")
+	rule, err := ParseRRule(rrule.value)
+	if err != nil {
+		return "", res, fmt.Errorf("cannot split RRULE %q: %w", rrule.value, err)
+	}
+
+	at := time.Date(s.At.Year(), s.At.Month(), s.At.Day(), 0, 0, 0, 0, style.loc)
+	kept, first, err := splitPoint(rule, start, at)
+	if err != nil {
+		return "", res, err
+	}
+	res.Kept = kept
+
+	from := first
+	if s.Clock != "" {
+		if style.date {
+			return "", res, fmt.Errorf("an all-day series has no start time to change")
+		}
+		clock, err := time.Parse("15:04", strings.TrimSpace(s.Clock))
+		if err != nil {
+			return "", res, fmt.Errorf("invalid start time %q (use HH:MM)", s.Clock)
+		}
+		first = time.Date(first.Year(), first.Month(), first.Day(), clock.Hour(), clock.Minute(), 0, 0, first.Location())
+	}
+	if style.date && s.Duration%(24*time.Hour) != 0 {
+		return "", res, fmt.Errorf("all-day events can only last whole days (got %s)", s.Duration)
+	}
+
+	oldRule, newRule := rrule.value, rrule.value
+	if rule.Count > 0 {
+		oldRule = setRRuleEnd(rrule.value, "COUNT", strconv.Itoa(kept))
+		newRule = setRRuleEnd(rrule.value, "COUNT", strconv.Itoa(rule.Count-kept))
+	} else {
+		oldRule = setRRuleEnd(rrule.value, "UNTIL", UntilValue(at.AddDate(0, 0, -1), style.date, style.tz))
+		if newRule, err = moveSplitUntil(newRule, style.tz, first.Sub(from)); err != nil {
+			return "", res, err
+		}
+	}
+	if r := strings.TrimPrefix(strings.TrimSpace(s.RRule), "RRULE:"); r != "" {
+		newRule = r
+	}
+
+	res.NewUID = splitUID(info.UID, at)
+	if uids[res.NewUID] {
+		return "", res, fmt.Errorf("the file already has an event with UID %s; was the series split here before?", res.NewUID)
+	}
+	res.NewStart = icsLine{name: "DTSTART", params: dtstart.params, value: style.format(first)}.describe()
+
+	now := wallClock.Now().UTC().Format(constants.ICSFormatUTC)
+	oldLines, newLines, err := splitMaster(lines, s, at, first, start, oldRule, newRule, res.NewUID, now)
+	if err != nil {
+		return "", res, err
+	}
+
+	var out []icsSegment
+	for i, seg := range segments {
+		switch {
+		case i == master:
+			out = append(out, icsSegment{lines: oldLines, event: true}, icsSegment{lines: newLines, event: true})
+			continue
+		case seg.event && icsEventInfo(seg.lines).UID == info.UID:
+			moved, ok, err := moveSplitOverride(seg.lines, at, first, s.Clock != "", res.NewUID)
+			if err != nil {
+				return "", res, err
+			}
+			if ok {
+				seg.lines = moved
+				res.Overrides++
+			}
+		}
+		out = append(out, seg)
+	}
+	return writeICSSegments(out), res, nil
+}
+
+// splitPoint expands rule from start and returns how many occurrences fall
+// before at and the first one on or after it.
+func splitPoint(rule Recurrence, start, at time.Time) (int, time.Time, error) {
+	// Expanding a little past at is enough to find the first new occurrence.
+	horizon := at.AddDate(rule.Interval+1, 0, 0)
+	expand := rule
+	if expand.Until.IsZero() || expand.Until.After(horizon) {
+		expand.Until = horizon
+	}
+	limit := int(horizon.Sub(start).Hours()/24) + 2
+	if limit < 1 {
+		limit = 1
+	}
+	kept := 0
+	for _, occ := range expand.Occurrences(start, limit) {
+		if !dateOnly(occ).Before(dateOnly(at)) {
+			if kept == 0 {
+				return 0, time.Time{}, fmt.Errorf("the series starts on or after %s; edit the whole series instead", at.Format(constants.DateFormatISO))
+			}
+			return kept, occ, nil
+		}
+		kept++
+	}
+	return 0, time.Time{}, fmt.Errorf("the series has no occurrences on or after %s", at.Format(constants.DateFormatISO))
+}
+
+// moveSplitUntil moves a DATE-TIME UNTIL of rule by delta, the change to the
+// new series' start time, so the series keeps its last occurrence (or does
+// not gain one). A DATE UNTIL takes in the whole day and stays.
+func moveSplitUntil(rule, startTZ string, delta time.Duration) (string, error) {
+	if delta == 0 {
+		return rule, nil
+	}
+	for _, part := range strings.Split(rule, ";") {
+		if key, val, _ := strings.Cut(part, "="); strings.EqualFold(key, "UNTIL") && len(val) > 8 {
+			return shiftRRuleUntil(rule, startTZ, Shift{By: delta}, delta)
+		}
+	}
+	return rule, nil
+}
+
+// splitMaster writes the two halves of the series' main VEVENT.
+func splitMaster(lines []string, s SeriesSplit, at, first, start time.Time, oldRule, newRule, newUID, now string) ([]string, []string, error) {
+	oldLines, newLines := []string{lines[0]}, []string{lines[0]}
+	replace := map[string]string{"SUMMARY": s.Summary, "LOCATION": s.Location, "DESCRIPTION": s.Description}
+	seen := map[string]bool{}
+	depth, sawSequence := 0, false
+	for _, line := range lines[1 : len(lines)-1] {
+		p := parseICSLine(line)
+		switch {
+		case p.name == "BEGIN":
+			depth++
+		case p.name == "END":
+			depth--
+		}
+		if depth > 0 || p.name == "END" {
+			oldLines, newLines = append(oldLines, line), append(newLines, line)
+			continue
+		}
+		oldLine, newLine := line, line
+		switch p.name {
+		case "UID":
+			newLine = "UID:" + newUID
+		case "DTSTART":
+			p.value = icsTimeStyleOf(p).format(first)
+			newLine = p.String()
+		case "DTEND":
+			end, err := splitEnd(p, start, first, s.Duration)
+			if err != nil {
+				return nil, nil, err
+			}
+			newLine = end.String()
+		case "DURATION":
+			if s.Duration != 0 {
+				newLine = "DURATION:" + formatICSDuration(s.Duration)
+			}
+		case "RRULE":
+			oldLine, newLine = "RRULE:"+oldRule, "RRULE:"+newRule
+		case "EXDATE", "RDATE":
+			before, after := splitDateList(p, at, first, s.Clock != "")
+			oldLine, newLine = before, after
+		case "SUMMARY", "LOCATION", "DESCRIPTION":
+			seen[p.name] = true
+			if v := strings.TrimSpace(replace[p.name]); v != "" {
+				newLine = p.name + ":" + escapeText(v)
+			}
+		case "SEQUENCE":
+			n, _ := strconv.Atoi(strings.TrimSpace(p.value))
+			oldLine, newLine, sawSequence = "SEQUENCE:"+strconv.Itoa(n+1), "", true
+		case "DTSTAMP", "LAST-MODIFIED":
+			oldLine, newLine = p.name+":"+now, p.name+":"+now
+		case "CREATED":
+			newLine = "CREATED:" + now
+		}
+		if oldLine != "" {
+			oldLines = append(oldLines, oldLine)
+		}
+		if newLine != "" {
+			newLines = append(newLines, newLine)
+		}
+	}
+	oldLines = append(oldLines, lines[len(lines)-1])
+	if !sawSequence {
+		oldLines = addEventProps(oldLines, "SEQUENCE:1")
+	}
+	// Properties the old series lacked are added to the new one.
+	var added []string
+	for _, name := range []string{"SUMMARY", "LOCATION", "DESCRIPTION"} {
+		if v := strings.TrimSpace(replace[name]); v != "" && !seen[name] {
+			added = append(added, name+":"+escapeText(v))
+		}
+	}
+	newLines = addEventProps(append(newLines, lines[len(lines)-1]), added...)
+	return oldLines, newLines, nil
+}
+
+// splitEnd gives the new series' DTEND: the old length, or d, after first.
+func splitEnd(p icsLine, start, first time.Time, d time.Duration) (icsLine, error) {
+	style := icsTimeStyleOf(p)
+	end, err := style.parse(p.value)
+	if err != nil {
+		return p, fmt.Errorf("DTEND: %w", err)
+	}
+	if style.date {
+		days := int(dateOnly(end).Sub(dateOnly(start)).Hours() / 24)
+		if d != 0 {
+			days = int(d / (24 * time.Hour))
+		}
+		p.value = style.format(first.AddDate(0, 0, days))
+		return p, nil
+	}
+	if d == 0 {
+		d = end.Sub(start)
+	}
+	p.value = style.format(first.Add(d).In(style.loc))
+	return p, nil
+}
+
+// splitDateList divides an EXDATE or RDATE line at the split day; the values
+// for the new series move to its start time when reclock is set. Either half
+// is "" when it gets no values.
+func splitDateList(p icsLine, at, first time.Time, reclock bool) (string, string) {
+	if strings.EqualFold(p.param("VALUE"), "PERIOD") {
+		return p.String(), "" // periods stay with the original series
+	}
+	style := icsTimeStyleOf(p)
+	var before, after []string
+	for _, raw := range strings.Split(p.value, ",") {
+		raw = strings.TrimSpace(raw)
+		t, err := style.parse(raw)
+		switch {
+		case err != nil || dateOnly(t.In(at.Location())).Before(dateOnly(at)):
+			before = append(before, raw)
+		case reclock && !style.date:
+			t = t.In(first.Location())
+			after = append(after, style.format(time.Date(t.Year(), t.Month(), t.Day(), first.Hour(), first.Minute(), first.Second(), 0, first.Location())))
+		default:
+			after = append(after, raw)
+		}
+	}
+	join := func(values []string) string {
+		if len(values) == 0 {
+			return ""
+		}
+		q := p
+		q.value = strings.Join(values, ",")
+		return q.String()
+	}
+	return join(before), join(after)
+}
+
+// moveSplitOverride hands a moved occurrence on or after the split day to the
+// new series, reporting whether it did.
+func moveSplitOverride(lines []string, at, first time.Time, reclock bool, newUID string) ([]string, bool, error) {
+	rid, ok := topLevelProp(lines, "RECURRENCE-ID")
+	if !ok {
+		return lines, false, nil
+	}
+	style := icsTimeStyleOf(rid)
+	t, err := style.parse(rid.value)
+	if err != nil {
+		return nil, false, fmt.Errorf("RECURRENCE-ID: %w", err)
+	}
+	if dateOnly(t.In(at.Location())).Before(dateOnly(at)) {
+		return lines, false, nil
+	}
+	if reclock && !style.date {
+		t = t.In(first.Location())
+		rid.value = style.format(time.Date(t.Year(), t.Month(), t.Day(), first.Hour(), first.Minute(), first.Second(), 0, first.Location()))
+	}
+	out := make([]string, 0, len(lines))
+	depth := 0
+	for _, line := range lines {
+		p := parseICSLine(line)
+		switch {
+		case p.name == "BEGIN":
+			depth++
+		case p.name == "END":
+			depth--
+		case depth == 1 && p.name == "UID":
+			line = "UID:" + newUID
+		case depth == 1 && p.name == "RECURRENCE-ID":
+			line = rid.String()
+		}
+		out = append(out, line)
+	}
+	return out, true, nil
+}
+
+// splitUID derives the new series' UID from the original and the split day.
+func splitUID(uid string, at time.Time) string {
+	suffix := "-" + at.Format(constants.ICSFormatDateOnly)
+	if i := strings.LastIndex(uid, "@"); i >= 0 {
+		return uid[:i] + suffix + uid[i:]
+	}
+	return uid + suffix
+}
+
+// setRRuleEnd replaces the COUNT or UNTIL of rule with key=value.
+func setRRuleEnd(rule, key, value string) string {
+	var parts []string
+	for _, part := range strings.Split(strings.TrimSuffix(strings.TrimSpace(rule), ";"), ";") {
+		k, _, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(strings.TrimSpace(k)) {
+		case "COUNT", "UNTIL":
+			continue
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(append(parts, key+"="+value), ";")
+}
+
+// topLevelProp returns the first property name of a VEVENT's own lines,
+// ignoring its alarms.
+func topLevelProp(event []string, name string) (icsLine, bool) {
+	depth := 0
+	for _, line := range event[1 : len(event)-1] {
+		p := parseICSLine(line)
+		switch {
+		case p.name == "BEGIN":
+			depth++
+		case p.name == "END":
+			depth--
+		case depth == 0 && p.name == name:
+			return p, true
+		}
+	}
+	return icsLine{}, false
+}
+
+// icsTimeStyle is how a DATE / DATE-TIME property writes its values: a date,
+// UTC ("Z"), a wall clock in a TZID, or a floating wall clock (loc UTC).
+type icsTimeStyle struct {
+	date, utc bool
+	tz        string
+	loc       *time.Location
+}
+
+func icsTimeStyleOf(p icsLine) icsTimeStyle {
+	s := icsTimeStyle{
+		date: strings.EqualFold(p.param("VALUE"), "DATE") || len(strings.TrimSpace(strings.SplitN(p.value, ",", 2)[0])) == 8,
+		utc:  strings.HasSuffix(strings.TrimSpace(strings.SplitN(p.value, ",", 2)[0]), "Z"),
+		tz:   p.param("TZID"),
+		loc:  time.UTC,
+	}
+	if s.tz != "" {
+		if loc, err := time.LoadLocation(s.tz); err == nil {
+			s.loc = loc
+		}
+	}
+	return s
+}
+
+// parse reads one value as a time in s.loc.
+func (s icsTimeStyle) parse(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case len(raw) == 8:
+		return time.ParseInLocation(constants.ICSFormatDateOnly, raw, s.loc)
+	case strings.HasSuffix(raw, "Z"):
+		t, err := time.Parse(constants.ICSFormatUTC, raw)
+		return t.In(s.loc), err
+	}
+	t, err := time.ParseInLocation(constants.ICSFormatLocal, raw, s.loc)
+	if err != nil {
+		return t, fmt.Errorf("invalid date-time %q", raw)
+	}
+	return t, nil
+}
+
+func (s icsTimeStyle) format(t time.Time) string {
+	switch {
+	case s.date:
+		return t.Format(constants.ICSFormatDateOnly)
+	case s.utc:
+		return t.UTC().Format(constants.ICSFormatUTC)
+	}
+	return t.In(s.loc).Format(constants.ICSFormatLocal)
+}
@@ -0,0 +1,182 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"tempus/internal/clock"
+)
+
+func TestSplitICS(t *testing.T) {
+	SetClock(clock.Fixed(time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)))
+	t.Cleanup(func() { SetClock(nil) })
+
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:yoga@tempus",
+		"DTSTAMP:20260101T000000Z",
+		"SUMMARY:Yoga",
+		"DTSTART;TZID=Europe/Madrid:20261005T190000",
+		"DTEND;TZID=Europe/Madrid:20261005T200000",
+		"RRULE:FREQ=WEEKLY;COUNT=10",
+		"EXDATE;TZID=Europe/Madrid:20261012T190000,20261109T190000",
+		"SEQUENCE:3",
+		"BEGIN:VALARM",
+		"TRIGGER:-PT30M",
+		"ACTION:DISPLAY",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:yoga@tempus",
+		"SUMMARY:Yoga (outdoors)",
+		"RECURRENCE-ID;TZID=Europe/Madrid:20261116T190000",
+		"DTSTART;TZID=Europe/Madrid:20261116T180000",
+		"DTEND;TZID=Europe/Madrid:20261116T190000",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:yoga@tempus",
+		"SUMMARY:Yoga (late)",
+		"RECURRENCE-ID;TZID=Europe/Madrid:20261019T190000",
+		"DTSTART;TZID=Europe/Madrid:20261019T200000",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	out, res, err := SplitICS(ics, SeriesSplit{
+		At:       time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		Clock:    "18:30",
+		Duration: 90 * time.Minute,
+		Location: "Studio B",
+	})
+	if err != nil {
+		t.Fatalf("SplitICS: %v", err)
+	}
+	want := SplitResult{UID: "yoga@tempus", NewUID: "yoga-20261101@tempus", Summary: "Yoga", Kept: 4,
+		NewStart: "20261102T183000 Europe/Madrid", Overrides: 1}
+	if res != want {
+		t.Errorf("result = %+v, want %+v", res, want)
+	}
+
+	segments, err := splitICSEvents(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events [][]string
+	for _, seg := range segments {
+		if seg.event {
+			events = append(events, seg.lines)
+		}
+	}
+	if len(events) != 4 {
+		t.Fatalf("got %d VEVENTs, want 4:\n%s", len(events), out)
+	}
+	old, next := strings.Join(events[0], "\n"), strings.Join(events[1], "\n")
+	for _, line := range []string{
+		"UID:yoga@tempus",
+		"RRULE:FREQ=WEEKLY;COUNT=4",
+		"EXDATE;TZID=Europe/Madrid:20261012T190000",
+		"SEQUENCE:4",
+		"DTSTAMP:20261001T080000Z",
+	} {
+		if !strings.Contains(old, line) {
+			t.Errorf("original series lacks %q:\n%s", line, old)
+		}
+	}
+	for _, line := range []string{
+		"UID:yoga-20261101@tempus",
+		"DTSTART;TZID=Europe/Madrid:20261102T183000",
+		"DTEND;TZID=Europe/Madrid:20261102T200000",
+		"RRULE:FREQ=WEEKLY;COUNT=6",
+		"EXDATE;TZID=Europe/Madrid:20261109T183000",
+		"SUMMARY:Yoga",
+		"LOCATION:Studio B\nBEGIN:VALARM",
+		"TRIGGER:-PT30M",
+	} {
+		if !strings.Contains(next, line) {
+			t.Errorf("new series lacks %q:\n%s", line, next)
+		}
+	}
+	if strings.Contains(next, "SEQUENCE") || strings.Contains(old, "20261109") {
+		t.Errorf("SEQUENCE or EXDATE in the wrong half:\n%s\n\n%s", old, next)
+	}
+	if moved := strings.Join(events[2], "\n"); !strings.Contains(moved, "UID:yoga-20261101@tempus") ||
+		!strings.Contains(moved, "RECURRENCE-ID;TZID=Europe/Madrid:20261116T183000") {
+		t.Errorf("override after the split not moved to the new series:\n%s", moved)
+	}
+	if kept := strings.Join(events[3], "\n"); !strings.Contains(kept, "UID:yoga@tempus") {
+		t.Errorf("override before the split left the original series:\n%s", kept)
+	}
+}
+
+func TestSplitICSUntil(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:a\r\nDTSTART;VALUE=DATE:20260105\r\nDTEND;VALUE=DATE:20260106\r\n" +
+		"RRULE:FREQ=MONTHLY\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	out, res, err := SplitICS(ics, SeriesSplit{At: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Summary: "Rent"})
+	if err != nil {
+		t.Fatalf("SplitICS: %v", err)
+	}
+	if res.Kept != 2 || res.NewStart != "20260305" {
+		t.Errorf("result = %+v", res)
+	}
+	for _, want := range []string{"RRULE:FREQ=MONTHLY;UNTIL=20260228\r\n", "UID:a-20260301\r\nDTSTART;VALUE=DATE:20260305\r\nDTEND;VALUE=DATE:20260306\r\nRRULE:FREQ=MONTHLY\r\n", "SUMMARY:Rent\r\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+
+	cases := []struct {
+		split SeriesSplit
+		want  string
+	}{
+		{SeriesSplit{At: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)}, "edit the whole series"},
+		{SeriesSplit{At: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Clock: "10:00"}, "no start time"},
+		{SeriesSplit{At: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Duration: time.Hour}, "whole days"},
+		{SeriesSplit{UID: "b", At: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)}, `no recurring event with UID "b"`},
+	}
+	for _, tc := range cases {
+		if _, _, err := SplitICS(ics, tc.split); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("SplitICS(%+v) = %v, want %q", tc.split, err, tc.want)
+		}
+	}
+	ended := strings.Replace(ics, "FREQ=MONTHLY", "FREQ=MONTHLY;COUNT=2", 1)
+	if _, _, err := SplitICS(ended, SeriesSplit{At: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)}); err == nil || !strings.Contains(err.Error(), "no occurrences on or after") {
+		t.Errorf("split after the last occurrence: %v", err)
+	}
+}
+
+func TestSplitICSLaterTimeKeepsTheLastOccurrence(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:a\r\nDTSTART;TZID=Europe/Madrid:20300107T090000\r\n" +
+		"DTEND;TZID=Europe/Madrid:20300107T100000\r\nRRULE:FREQ=WEEKLY;UNTIL=20300325T080000Z\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	out, res, err := SplitICS(ics, SeriesSplit{At: time.Date(2030, 2, 1, 0, 0, 0, 0, time.UTC), Clock: "18:30"})
+	if err != nil {
+		t.Fatalf("SplitICS: %v", err)
+	}
+	if res.NewStart != "20300204T183000 Europe/Madrid" {
+		t.Errorf("result = %+v", res)
+	}
+	if !strings.Contains(out, "RRULE:FREQ=WEEKLY;UNTIL=20300325T173000Z\r\n") {
+		t.Fatalf("the new series should end at its own last start:\n%s", out)
+	}
+
+	events, err := ParseICSEvents(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last time.Time
+	for _, ev := range events {
+		if ev.UID != res.NewUID {
+			continue
+		}
+		occ, _, err := ev.Materialize(0, 0, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		last = occ[len(occ)-1].StartTime
+	}
+	if last.Format("2006-01-02 15:04") != "2030-03-25 18:30" {
+		t.Errorf("last occurrence of the new series = %s, want 2030-03-25 18:30", last)
+	}
+}
@@ -0,0 +1,166 @@
+package calendar
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"tempus/internal/constants"
+)
+
+// maxContentLine is the longest content line RFC 5545 allows before folding,
+// in octets.
+const maxContentLine = 75
+
+// Stats describes what an .ics document holds and how big it is, to check a
+// large calendar before importing it on a phone.
+type Stats struct {
+	Events        int            `json:"events"`                       // VEVENTs, overrides included
+	Series        int            `json:"series"`                       // events that repeat (RRULE or RDATE)
+	Overrides     int            `json:"overrides"`                    // moved or changed occurrences (RECURRENCE-ID)
+	AllDay        int            `json:"all_day"`                      // all-day events
+	Alarms        int            `json:"alarms"`                       // VALARMs over all events
+	Categories    map[string]int `json:"categories"`                   // events per category; an event counts once per category
+	Uncategorized int            `json:"uncategorized"`                // events without categories
+	Months        map[string]int `json:"months"`                       // events per YYYY-MM of their start
+	Days          map[string]int `json:"days"`                         // events per YYYY-MM-DD of their start
+	First         *StatsEvent    `json:"first,omitempty"`              // the event that starts first
+	Last          *StatsEvent    `json:"last,omitempty"`               // the event that starts last
+	Timezones     []string       `json:"timezones"`                    // TZIDs the events use, sorted
+	MissingTZ     []string       `json:"missing_vtimezones,omitempty"` // of those, the ones without a VTIMEZONE
+	Bytes         int            `json:"bytes"`                        // size of the document
+	Lines         int            `json:"lines"`                        // content lines, after unfolding
+	LongLines     int            `json:"long_lines"`                   // physical lines over 75 octets, i.e. not folded
+}
+
+// StatsEvent names an event in Stats; Start is "YYYY-MM-DD[ HH:MM]" in the
+// event's own timezone.
+type StatsEvent struct {
+	Summary string `json:"summary"`
+	Start   string `json:"start"`
+}
+
+// ComputeStats reads an .ics document. Events are placed on days and months
+// by their start in their own timezone; a series counts once, on its first
+// occurrence.
+func ComputeStats(data string) (Stats, error) {
+	events, err := ParseICSEvents(data)
+	if err != nil {
+		return Stats{}, err
+	}
+	segments, err := splitICSEvents(data)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	s := Stats{
+		Events:     len(events),
+		Categories: map[string]int{},
+		Months:     map[string]int{},
+		Days:       map[string]int{},
+		Bytes:      len(data),
+		Lines:      len(unfoldICS(data)),
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		if len(line) > maxContentLine {
+			s.LongLines++
+		}
+	}
+
+	zones := map[string]bool{}
+	var first, last *Event
+	for i := range events {
+		ev := &events[i]
+		switch {
+		case !ev.RecurrenceID.IsZero():
+			s.Overrides++
+		case strings.TrimSpace(ev.RRule) != "" || len(ev.RDates) > 0:
+			s.Series++
+		}
+		if ev.AllDay {
+			s.AllDay++
+		}
+		s.Alarms += len(ev.Alarms)
+
+		counted := map[string]bool{}
+		for _, c := range ev.Categories {
+			if c = CleanCategory(c); c != "" && !counted[strings.ToLower(c)] {
+				counted[strings.ToLower(c)] = true
+				s.Categories[c]++
+			}
+		}
+		if len(counted) == 0 {
+			s.Uncategorized++
+		}
+
+		start := statsWallClock(ev.StartTime, ev.StartTZ)
+		s.Months[start.Format("2006-01")]++
+		s.Days[start.Format(constants.DateFormatISO)]++
+
+		for _, tz := range []string{ev.StartTZ, ev.EndTZ} {
+			if tz = strings.TrimSpace(tz); tz != "" {
+				zones[tz] = true
+			}
+		}
+		if first == nil || ev.StartTime.Before(first.StartTime) {
+			first = ev
+		}
+		if last == nil || !ev.StartTime.Before(last.StartTime) {
+			last = ev
+		}
+	}
+	if first != nil {
+		s.First, s.Last = statsEvent(*first), statsEvent(*last)
+	}
+
+	have := vtimezoneIDs(segments)
+	s.Timezones = []string{}
+	for tz := range zones {
+		s.Timezones = append(s.Timezones, tz)
+		if !have[tz] {
+			s.MissingTZ = append(s.MissingTZ, tz)
+		}
+	}
+	sort.Strings(s.Timezones)
+	sort.Strings(s.MissingTZ)
+	return s, nil
+}
+
+// statsWallClock shows an instant as the clock in tz reads it, or in UTC when
+// tz is empty or unknown.
+func statsWallClock(t time.Time, tz string) time.Time {
+	if tz = strings.TrimSpace(tz); tz != "" {
+		if loc, err := time.LoadLocation(tz); err == nil {
+			return t.In(loc)
+		}
+	}
+	return t.UTC()
+}
+
+func statsEvent(ev Event) *StatsEvent {
+	start := statsWallClock(ev.StartTime, ev.StartTZ)
+	layout := constants.DateTimeFormatISO
+	if ev.AllDay {
+		layout = constants.DateFormatISO
+	}
+	return &StatsEvent{Summary: ev.Summary, Start: start.Format(layout)}
+}
+
+// BusiestDays returns up to n days with the most events, busiest first and
+// earliest first among equals.
+func (s Stats) BusiestDays(n int) []string {
+	days := make([]string, 0, len(s.Days))
+	for d := range s.Days {
+		days = append(days, d)
+	}
+	sort.Slice(days, func(i, j int) bool {
+		if s.Days[days[i]] != s.Days[days[j]] {
+			return s.Days[days[i]] > s.Days[days[j]]
+		}
+		return days[i] < days[j]
+	})
+	if len(days) > n {
+		days = days[:n]
+	}
+	return days
+}
@@ -0,0 +1,79 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComputeStats(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VTIMEZONE",
+		"TZID:Europe/Madrid",
+		"END:VTIMEZONE",
+		"BEGIN:VEVENT",
+		"UID:a",
+		"SUMMARY:Standup",
+		"CATEGORIES:Work,Meeting",
+		"DTSTART;TZID=Europe/Madrid:20260105T003000",
+		"DTEND;TZID=Europe/Madrid:20260105T010000",
+		"RRULE:FREQ=DAILY;COUNT=5",
+		"BEGIN:VALARM",
+		"TRIGGER:-PT10M",
+		"ACTION:DISPLAY",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:a",
+		"SUMMARY:Standup (moved)",
+		"CATEGORIES:work",
+		"RECURRENCE-ID;TZID=Europe/Madrid:20260106T003000",
+		"DTSTART;TZID=America/New_York:20260106T090000",
+		"DTEND;TZID=America/New_York:20260106T093000",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:b",
+		"SUMMARY:Holiday",
+		"DTSTART;VALUE=DATE:20260201",
+		"DESCRIPTION:" + strings.Repeat("x", 80),
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	s, err := ComputeStats(ics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Events != 3 || s.Series != 1 || s.Overrides != 1 || s.AllDay != 1 || s.Alarms != 1 {
+		t.Errorf("counts = %+v", s)
+	}
+	if s.Categories["Work"] != 1 || s.Categories["work"] != 1 || s.Categories["Meeting"] != 1 || s.Uncategorized != 1 {
+		t.Errorf("categories = %v, uncategorized %d", s.Categories, s.Uncategorized)
+	}
+	// 00:30 in Madrid is still 4 January in UTC; days follow the event's zone.
+	if s.Days["2026-01-05"] != 1 || s.Days["2026-01-06"] != 1 || s.Months["2026-01"] != 2 || s.Months["2026-02"] != 1 {
+		t.Errorf("days = %v, months = %v", s.Days, s.Months)
+	}
+	if s.First == nil || s.First.Start != "2026-01-05 00:30" || s.Last.Summary != "Holiday" || s.Last.Start != "2026-02-01" {
+		t.Errorf("first = %+v, last = %+v", s.First, s.Last)
+	}
+	if strings.Join(s.Timezones, ",") != "America/New_York,Europe/Madrid" || strings.Join(s.MissingTZ, ",") != "America/New_York" {
+		t.Errorf("timezones = %v, missing %v", s.Timezones, s.MissingTZ)
+	}
+	if s.Bytes != len(ics) || s.LongLines != 1 {
+		t.Errorf("bytes = %d, long lines = %d", s.Bytes, s.LongLines)
+	}
+	if got := s.BusiestDays(1); len(got) != 1 || got[0] != "2026-01-05" {
+		t.Errorf("BusiestDays(1) = %v", got)
+	}
+}
+
+func TestComputeStatsEmpty(t *testing.T) {
+	s, err := ComputeStats("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Events != 0 || s.First != nil || s.Timezones == nil {
+		t.Errorf("empty calendar stats = %+v", s)
+	}
+}
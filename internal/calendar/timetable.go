@@ -0,0 +1,158 @@
+package calendar
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TimetableClass is one weekly slot of a timetable, such as
+// "Mon,Wed 09:00-10:30 Algebra Room B2".
+type TimetableClass struct {
+	Days     []time.Weekday
+	Start    int // minutes since midnight
+	End      int
+	Summary  string
+	Location string
+}
+
+// timetableLocationRe finds where a location starts in a compact timetable
+// line when no "@" or "|" separates it: the last room-like word.
+var timetableLocationRe = regexp.MustCompile(`(?i)\b(?:room|rm|classroom|lab|laboratory|hall|building|bldg|aula|sala|seomra|online)\b`)
+
+// ParseTimetableLine parses a compact timetable line: weekdays, an
+// HH:MM-HH:MM time range, the class name and an optional location after
+// "@" or "|", or starting at a word such as Room, Lab or Aula:
+//
+//	Mon 09:00-10:30 Algebra Room B2
+//	Tue,Thu 14:00-15:30 History @ Main Building 1.04
+func ParseTimetableLine(line string) (TimetableClass, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return TimetableClass{}, fmt.Errorf("timetable line %q needs days, a time range and a name, e.g. \"Mon 09:00-10:30 Algebra Room B2\"", line)
+	}
+	days, err := ParseWeekdays(fields[0])
+	if err != nil {
+		return TimetableClass{}, fmt.Errorf("timetable line %q: %w", line, err)
+	}
+	class := TimetableClass{Days: days}
+	if class.Start, class.End, err = parseClassTime(fields[1]); err != nil {
+		return TimetableClass{}, fmt.Errorf("timetable line %q: %w", line, err)
+	}
+
+	rest := strings.Join(fields[2:], " ")
+	if i := strings.IndexAny(rest, "@|"); i >= 0 {
+		class.Summary, class.Location = rest[:i], rest[i+1:]
+	} else if locs := timetableLocationRe.FindAllStringIndex(rest, -1); len(locs) > 0 && locs[len(locs)-1][0] > 0 {
+		at := locs[len(locs)-1][0]
+		class.Summary, class.Location = rest[:at], rest[at:]
+	} else {
+		class.Summary = rest
+	}
+	class.Summary = strings.TrimSpace(class.Summary)
+	class.Location = strings.TrimSpace(class.Location)
+	if class.Summary == "" {
+		return TimetableClass{}, fmt.Errorf("timetable line %q has no class name", line)
+	}
+	return class, nil
+}
+
+// ParseTimetableClass builds a class from separate days, time range, name and
+// location values.
+func ParseTimetableClass(days, timeRange, summary, location string) (TimetableClass, error) {
+	class := TimetableClass{Summary: strings.TrimSpace(summary), Location: strings.TrimSpace(location)}
+	if class.Summary == "" {
+		return TimetableClass{}, fmt.Errorf("class has no name")
+	}
+	var err error
+	if class.Days, err = ParseWeekdays(days); err != nil {
+		return TimetableClass{}, err
+	}
+	if len(class.Days) == 0 {
+		return TimetableClass{}, fmt.Errorf("class %q has no days", class.Summary)
+	}
+	if class.Start, class.End, err = parseClassTime(timeRange); err != nil {
+		return TimetableClass{}, fmt.Errorf("class %q: %w", class.Summary, err)
+	}
+	return class, nil
+}
+
+func parseClassTime(s string) (int, int, error) {
+	windows, err := ParseClockWindows(s)
+	if err != nil || len(windows) != 1 {
+		return 0, 0, fmt.Errorf("invalid time range %q (want HH:MM-HH:MM)", s)
+	}
+	if windows[0].End <= windows[0].Start {
+		return 0, 0, fmt.Errorf("time range %q ends before it starts", s)
+	}
+	return windows[0].Start, windows[0].End, nil
+}
+
+// DateRange is an inclusive range of days.
+type DateRange struct {
+	First time.Time
+	Last  time.Time
+}
+
+// ParseDateRange parses "2025-10-13" or "2025-12-22..2026-01-07".
+func ParseDateRange(s string) (DateRange, error) {
+	first, last, isRange := strings.Cut(strings.TrimSpace(s), "..")
+	r := DateRange{}
+	var err error
+	if r.First, err = time.Parse("2006-01-02", strings.TrimSpace(first)); err != nil {
+		return DateRange{}, fmt.Errorf("invalid date %q (use YYYY-MM-DD or YYYY-MM-DD..YYYY-MM-DD)", s)
+	}
+	r.Last = r.First
+	if isRange {
+		if r.Last, err = time.Parse("2006-01-02", strings.TrimSpace(last)); err != nil {
+			return DateRange{}, fmt.Errorf("invalid date range %q (use YYYY-MM-DD..YYYY-MM-DD)", s)
+		}
+		if r.Last.Before(r.First) {
+			return DateRange{}, fmt.Errorf("date range %q ends before it starts", s)
+		}
+	}
+	return r, nil
+}
+
+// Contains reports whether the day of t (by its date alone) is in the range.
+func (r DateRange) Contains(t time.Time) bool {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return !day.Before(r.First) && !day.After(r.Last)
+}
+
+// Term is a teaching period: classes repeat weekly from Start to End (both
+// days included) except during Breaks.
+type Term struct {
+	Start  time.Time
+	End    time.Time
+	Breaks []DateRange
+}
+
+// WeeklySeries is a weekday's occurrences in a term: Count weekly dates from
+// First, of which Skipped fall in a break.
+type WeeklySeries struct {
+	First   time.Time
+	Count   int
+	Skipped []time.Time
+}
+
+// Weekly lays out a weekday over the term, dates at midnight UTC. ok is false
+// when the weekday never occurs or every occurrence is in a break.
+func (t Term) Weekly(day time.Weekday) (WeeklySeries, bool) {
+	start := time.Date(t.Start.Year(), t.Start.Month(), t.Start.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(t.End.Year(), t.End.Month(), t.End.Day(), 0, 0, 0, 0, time.UTC)
+	first := start.AddDate(0, 0, (int(day)-int(start.Weekday())+7)%7)
+
+	s := WeeklySeries{First: first}
+	for at := first; !at.After(end); at = at.AddDate(0, 0, 7) {
+		s.Count++
+		for _, b := range t.Breaks {
+			if b.Contains(at) {
+				s.Skipped = append(s.Skipped, at)
+				break
+			}
+		}
+	}
+	return s, s.Count > len(s.Skipped)
+}
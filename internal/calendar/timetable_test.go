@@ -0,0 +1,88 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimetableLine(t *testing.T) {
+	tests := []struct {
+		line, summary, location string
+		days                    []time.Weekday
+		start, end              int
+	}{
+		{"Mon 09:00-10:30 Algebra Room B2", "Algebra", "Room B2", []time.Weekday{time.Monday}, 540, 630},
+		{"Tue,Thu 14:00-15:30 History @ Main Building 1.04", "History", "Main Building 1.04", []time.Weekday{time.Tuesday, time.Thursday}, 840, 930},
+		{"wed/fri 8:00-9:00 Physics Lab | North Hall", "Physics Lab", "North Hall", []time.Weekday{time.Wednesday, time.Friday}, 480, 540},
+		{"Fri 12:00-13:00 Organic Chemistry Lab 3", "Organic Chemistry", "Lab 3", []time.Weekday{time.Friday}, 720, 780},
+		{"Mon 16:00-17:00 Seminar", "Seminar", "", []time.Weekday{time.Monday}, 960, 1020},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimetableLine(tt.line)
+		if err != nil {
+			t.Errorf("ParseTimetableLine(%q) returned error: %v", tt.line, err)
+			continue
+		}
+		if got.Summary != tt.summary || got.Location != tt.location || got.Start != tt.start || got.End != tt.end || len(got.Days) != len(tt.days) {
+			t.Errorf("ParseTimetableLine(%q) = %+v", tt.line, got)
+			continue
+		}
+		for i := range tt.days {
+			if got.Days[i] != tt.days[i] {
+				t.Errorf("ParseTimetableLine(%q) days = %v, want %v", tt.line, got.Days, tt.days)
+			}
+		}
+	}
+
+	for _, line := range []string{"Mon 09:00-10:30", "Funday 09:00-10:30 Algebra", "Mon 9am Algebra", "Mon 10:30-09:00 Algebra", "Mon 09:00-10:00 @ Room 1"} {
+		if _, err := ParseTimetableLine(line); err == nil {
+			t.Errorf("ParseTimetableLine(%q): expected an error", line)
+		}
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	r, err := ParseDateRange("2025-12-22..2026-01-07")
+	if err != nil {
+		t.Fatalf("ParseDateRange returned error: %v", err)
+	}
+	if !r.Contains(time.Date(2026, 1, 7, 23, 0, 0, 0, time.Local)) || r.Contains(time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected range %+v", r)
+	}
+	if day, err := ParseDateRange("2025-10-13"); err != nil || !day.First.Equal(day.Last) {
+		t.Errorf("single day = %+v, %v", day, err)
+	}
+	for _, s := range []string{"", "13/10/2025", "2025-10-13..", "2025-10-13..2025-10-01"} {
+		if _, err := ParseDateRange(s); err == nil {
+			t.Errorf("ParseDateRange(%q): expected an error", s)
+		}
+	}
+}
+
+func TestTermWeekly(t *testing.T) {
+	term := Term{
+		Start:  time.Date(2025, 9, 10, 0, 0, 0, 0, time.UTC), // Wednesday
+		End:    time.Date(2025, 10, 6, 0, 0, 0, 0, time.UTC), // Monday
+		Breaks: []DateRange{{First: time.Date(2025, 9, 22, 0, 0, 0, 0, time.UTC), Last: time.Date(2025, 9, 26, 0, 0, 0, 0, time.UTC)}},
+	}
+
+	mon, ok := term.Weekly(time.Monday)
+	if !ok || mon.First.Format("2006-01-02") != "2025-09-15" || mon.Count != 4 {
+		t.Fatalf("Monday series = %+v", mon)
+	}
+	if len(mon.Skipped) != 1 || mon.Skipped[0].Format("2006-01-02") != "2025-09-22" {
+		t.Errorf("expected the break week skipped, got %v", mon.Skipped)
+	}
+	if wed, _ := term.Weekly(time.Wednesday); wed.First.Format("2006-01-02") != "2025-09-10" || wed.Count != 4 {
+		t.Errorf("Wednesday series = %+v", wed)
+	}
+
+	short := Term{Start: term.Start, End: term.Start.AddDate(0, 0, 2), Breaks: term.Breaks}
+	if _, ok := short.Weekly(time.Monday); ok {
+		t.Error("expected no Monday in a three-day term")
+	}
+	short.Breaks = []DateRange{{First: short.Start, Last: short.End}}
+	if _, ok := short.Weekly(time.Wednesday); ok {
+		t.Error("expected no classes when the whole term is a break")
+	}
+}
@@ -0,0 +1,161 @@
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Transform describes the rewrites TransformICS applies to an existing .ics
+// document. Nil funcs leave the corresponding properties alone.
+type Transform struct {
+	// TZID returns the TZID to write instead of tzid (tzid itself to keep it).
+	// It applies to TZID parameters, VTIMEZONE TZIDs and X-WR-TIMEZONE.
+	TZID func(tzid string) string
+	// Summary returns the SUMMARY to write for an event with the given CATEGORIES.
+	Summary func(summary string, categories []string) string
+}
+
+// TransformReport summarises what TransformICS changed.
+type TransformReport struct {
+	Events    int               `json:"events"`
+	TZIDs     map[string]string `json:"tzids,omitempty"` // old TZID -> new TZID
+	Summaries int               `json:"summaries"`       // events whose SUMMARY was rewritten
+}
+
+// TransformICS applies t to an .ics document, copying everything it does not
+// rewrite verbatim. Wall-clock times are kept: remapping a TZID relabels the
+// zone, it does not convert times. When two VTIMEZONE blocks end up with the
+// same TZID only the first is kept.
+func TransformICS(data string, t Transform) (string, TransformReport, error) {
+	report := TransformReport{TZIDs: map[string]string{}}
+	remap := func(tzid string) string {
+		if t.TZID == nil || tzid == "" {
+			return tzid
+		}
+		mapped := t.TZID(tzid)
+		if mapped != tzid {
+			report.TZIDs[tzid] = mapped
+		}
+		return mapped
+	}
+
+	var (
+		out      []string
+		block    []string
+		blockEnd string
+		vtzSeen  = map[string]bool{}
+	)
+	for _, line := range unfoldICS(data) {
+		upper := strings.ToUpper(strings.TrimSpace(line))
+		if block == nil {
+			switch upper {
+			case "BEGIN:VEVENT":
+				block, blockEnd = []string{line}, "END:VEVENT"
+				continue
+			case "BEGIN:VTIMEZONE":
+				block, blockEnd = []string{line}, "END:VTIMEZONE"
+				continue
+			}
+			out = append(out, remapLineTZ(line, remap))
+			continue
+		}
+		block = append(block, line)
+		if upper != blockEnd {
+			continue
+		}
+		if blockEnd == "END:VEVENT" {
+			report.Events++
+			event, rewritten := transformICSEvent(block, t, remap)
+			if rewritten {
+				report.Summaries++
+			}
+			out = append(out, event...)
+		} else if tzid, vtz := transformVTimezone(block, remap); !vtzSeen[tzid] {
+			vtzSeen[tzid] = true
+			out = append(out, vtz...)
+		}
+		block = nil
+	}
+	if block != nil {
+		return "", report, fmt.Errorf("unterminated %s", strings.TrimPrefix(blockEnd, "END:"))
+	}
+
+	var b strings.Builder
+	for _, line := range out {
+		writeLine(&b, line)
+	}
+	return b.String(), report, nil
+}
+
+// Remapped lists the TZID rewrites as "old → new", sorted.
+func (r TransformReport) Remapped() []string {
+	out := make([]string, 0, len(r.TZIDs))
+	for from, to := range r.TZIDs {
+		out = append(out, from+" → "+to)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// remapLineTZ rewrites the TZID parameter of any property, and the value of
+// X-WR-TIMEZONE.
+func remapLineTZ(line string, remap func(string) string) string {
+	p := parseICSLine(line)
+	if p.name == "X-WR-TIMEZONE" {
+		if tz := remap(strings.TrimSpace(p.value)); tz != strings.TrimSpace(p.value) {
+			p.value = tz
+			return p.String()
+		}
+		return line
+	}
+	if tz := p.param("TZID"); tz != "" {
+		if mapped := remap(tz); mapped != tz {
+			p.setParam("TZID", mapped)
+			return p.String()
+		}
+	}
+	return line
+}
+
+func transformICSEvent(event []string, t Transform, remap func(string) string) ([]string, bool) {
+	info := icsEventInfo(event)
+	out := make([]string, len(event))
+	rewritten := false
+	depth := 0
+	for i, line := range event {
+		out[i] = remapLineTZ(line, remap)
+		if i == 0 || i == len(event)-1 {
+			continue
+		}
+		p := parseICSLine(line)
+		switch {
+		case p.name == "BEGIN":
+			depth++
+		case p.name == "END":
+			depth--
+		case depth == 0 && p.name == "SUMMARY" && t.Summary != nil:
+			if summary := t.Summary(info.Summary, info.Categories); summary != info.Summary {
+				p.value = escapeText(summary)
+				out[i] = p.String()
+				rewritten = true
+			}
+		}
+	}
+	return out, rewritten
+}
+
+// transformVTimezone remaps a VTIMEZONE's TZID and returns it with the block.
+func transformVTimezone(block []string, remap func(string) string) (string, []string) {
+	out := make([]string, len(block))
+	tzid := ""
+	for i, line := range block {
+		out[i] = line
+		if p := parseICSLine(line); p.name == "TZID" && tzid == "" {
+			tzid = remap(strings.TrimSpace(p.value))
+			p.value = tzid
+			out[i] = p.String()
+		}
+	}
+	return tzid, out
+}
@@ -0,0 +1,92 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+)
+
+const transformTestICS = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"X-WR-TIMEZONE:Europe/Kiev\r\n" +
+	"BEGIN:VTIMEZONE\r\n" +
+	"TZID:Europe/Kiev\r\n" +
+	"END:VTIMEZONE\r\n" +
+	"BEGIN:VTIMEZONE\r\n" +
+	"TZID:Europe/Kyiv\r\n" +
+	"END:VTIMEZONE\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup@test\r\n" +
+	"SUMMARY:Standup\\, daily\r\n" +
+	"CATEGORIES:Work\r\n" +
+	"DTSTART;TZID=Europe/Kiev:20250328T090000\r\n" +
+	"DTEND;TZID=\"Europe/Kiev\":20250328T091500\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"SUMMARY:Reminder\r\n" +
+	"TRIGGER:-PT10M\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestTransformICSRemapsTZIDsAndSummaries(t *testing.T) {
+	out, report, err := TransformICS(transformTestICS, Transform{
+		TZID: func(tz string) string {
+			if tz == "Europe/Kiev" {
+				return "Europe/Kyiv"
+			}
+			return tz
+		},
+		Summary: func(summary string, categories []string) string {
+			if len(categories) == 1 && categories[0] == "Work" {
+				return "💼 " + summary
+			}
+			return summary
+		},
+	})
+	if err != nil {
+		t.Fatalf("TransformICS() failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"X-WR-TIMEZONE:Europe/Kyiv\r\n",
+		"DTSTART;TZID=Europe/Kyiv:20250328T090000\r\n",
+		"DTEND;TZID=Europe/Kyiv:20250328T091500\r\n",
+		"SUMMARY:💼 Standup\\, daily\r\n",
+		"SUMMARY:Reminder\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Kiev") {
+		t.Errorf("old TZID left in output:\n%s", out)
+	}
+	if got := strings.Count(out, "BEGIN:VTIMEZONE"); got != 1 {
+		t.Errorf("expected duplicate VTIMEZONE to collapse, got %d", got)
+	}
+	if report.Events != 1 || report.Summaries != 1 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if got := report.Remapped(); len(got) != 1 || got[0] != "Europe/Kiev → Europe/Kyiv" {
+		t.Errorf("Remapped() = %v", got)
+	}
+}
+
+func TestTransformICSWithoutRewritesKeepsContent(t *testing.T) {
+	out, report, err := TransformICS(shiftTestICS, Transform{})
+	if err != nil {
+		t.Fatalf("TransformICS() failed: %v", err)
+	}
+	if out != shiftTestICS {
+		t.Errorf("expected document to round-trip unchanged:\n%s", out)
+	}
+	if report.Events != 2 || len(report.TZIDs) != 0 || report.Summaries != 0 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
+
+func TestTransformICSRejectsUnterminatedEvent(t *testing.T) {
+	if _, _, err := TransformICS("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:x\r\n", Transform{}); err == nil {
+		t.Error("expected error for unterminated VEVENT")
+	}
+}
@@ -0,0 +1,105 @@
+package calendar
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"tempus/internal/constants"
+)
+
+// untilInRe matches "in 12 weeks" or "for 3 months".
+var untilInRe = regexp.MustCompile(`^(?:in|for)\s+(\d+)\s*(day|week|month|year)s?$`)
+
+// ResolveUntil turns a friendly end for a series that starts on start into
+// the last day the series may include (midnight UTC of that date):
+//
+//	2026-06-30         that day
+//	end-of-week        the Sunday of start's week
+//	end-of-month       the last day of start's month
+//	end-of-quarter     31 Mar, 30 Jun, 30 Sep or 31 Dec
+//	end-of-semester    30 Jun or 31 Dec
+//	end-of-year        31 Dec
+//	in 12 weeks        the day before start plus 12 weeks, so a weekly
+//	                   series has 12 occurrences (also days, months, years;
+//	                   "for 12 weeks" works too)
+//
+// Only start's date counts; its time and location are ignored.
+func ResolveUntil(spec string, start time.Time) (time.Time, error) {
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	s := strings.ToLower(strings.Join(strings.Fields(spec), " "))
+	s = strings.NewReplacer("_", "-", "end of ", "end-of-").Replace(s)
+
+	lastOfMonth := func(year int, month time.Month) time.Time {
+		return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+	}
+	switch s {
+	case "":
+		return time.Time{}, fmt.Errorf("empty end date")
+	case "end-of-week":
+		return day.AddDate(0, 0, (7-int(day.Weekday()))%7), nil
+	case "end-of-month":
+		return lastOfMonth(day.Year(), day.Month()), nil
+	case "end-of-quarter":
+		return lastOfMonth(day.Year(), time.Month((int(day.Month())+2)/3*3)), nil
+	case "end-of-semester":
+		if day.Month() <= time.June {
+			return lastOfMonth(day.Year(), time.June), nil
+		}
+		return lastOfMonth(day.Year(), time.December), nil
+	case "end-of-year":
+		return lastOfMonth(day.Year(), time.December), nil
+	}
+
+	if m := untilInRe.FindStringSubmatch(s); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n <= 0 {
+			return time.Time{}, fmt.Errorf("invalid end %q: the number must be positive", spec)
+		}
+		switch m[2] {
+		case "day":
+			day = day.AddDate(0, 0, n)
+		case "week":
+			day = day.AddDate(0, 0, 7*n)
+		case "month":
+			day = day.AddDate(0, n, 0)
+		case "year":
+			day = day.AddDate(n, 0, 0)
+		}
+		return day.AddDate(0, 0, -1), nil
+	}
+
+	t, err := time.Parse(constants.DateFormatISO, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid end %q (use YYYY-MM-DD, end-of-week, end-of-month, end-of-quarter, end-of-semester, end-of-year or \"in 12 weeks\")", spec)
+	}
+	return t, nil
+}
+
+// UntilValue formats the RRULE UNTIL that keeps a series up to and including
+// last. RFC 5545 wants UNTIL to match DTSTART: a DATE when the event is all
+// day, otherwise a UTC DATE-TIME, here the end of last in tz (UTC when empty).
+func UntilValue(last time.Time, allDay bool, tz string) string {
+	if allDay {
+		return last.Format(constants.ICSFormatDateOnly)
+	}
+	end := time.Date(last.Year(), last.Month(), last.Day(), 23, 59, 59, 0, time.UTC)
+	return instant(end, tz).UTC().Format(constants.ICSFormatUTC)
+}
+
+// SetRRuleUntil returns rrule ending at until. A rule that already has an
+// UNTIL or COUNT is an error: RFC 5545 allows only one of them, and quietly
+// replacing an explicit end would surprise.
+func SetRRuleUntil(rrule, until string) (string, error) {
+	rrule = strings.TrimSuffix(strings.TrimSpace(rrule), ";")
+	for _, part := range strings.Split(rrule, ";") {
+		key, _, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch strings.ToUpper(key) {
+		case "UNTIL", "COUNT":
+			return "", fmt.Errorf("the RRULE already ends with %s; drop it to use an end date", strings.ToUpper(key))
+		}
+	}
+	return rrule + ";UNTIL=" + until, nil
+}
@@ -0,0 +1,73 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveUntil(t *testing.T) {
+	start := time.Date(2026, 2, 11, 18, 30, 0, 0, time.UTC) // a Wednesday
+	tests := map[string]string{
+		"2026-06-30":      "2026-06-30",
+		"end-of-week":     "2026-02-15",
+		"End of Month":    "2026-02-28",
+		"end_of_quarter":  "2026-03-31",
+		"end-of-semester": "2026-06-30",
+		"end-of-year":     "2026-12-31",
+		"in 12 weeks":     "2026-05-05",
+		"for 1 month":     "2026-03-10",
+		"in 2 days":       "2026-02-12",
+		"in 1 year":       "2027-02-10",
+	}
+	for spec, want := range tests {
+		got, err := ResolveUntil(spec, start)
+		if err != nil || got.Format("2006-01-02") != want {
+			t.Errorf("ResolveUntil(%q) = %s, %v; want %s", spec, got.Format("2006-01-02"), err, want)
+		}
+	}
+	if got, _ := ResolveUntil("end-of-semester", time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)); got.Format("2006-01-02") != "2026-12-31" {
+		t.Errorf("autumn semester ends %s, want 2026-12-31", got.Format("2006-01-02"))
+	}
+	for _, bad := range []string{"", "soon", "in 0 weeks", "2026-02-30"} {
+		if _, err := ResolveUntil(bad, start); err == nil {
+			t.Errorf("ResolveUntil(%q) should fail", bad)
+		}
+	}
+}
+
+func TestUntilValueMatchesDTStart(t *testing.T) {
+	last := time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		allDay bool
+		tz     string
+		want   string
+	}{
+		{true, "Europe/Madrid", "20260630"},
+		{false, "", "20260630T235959Z"},
+		{false, "Europe/Madrid", "20260630T215959Z"},
+		{false, "America/Los_Angeles", "20260701T065959Z"},
+	}
+	for _, tt := range tests {
+		if got := UntilValue(last, tt.allDay, tt.tz); got != tt.want {
+			t.Errorf("UntilValue(allDay=%v, %q) = %s, want %s", tt.allDay, tt.tz, got, tt.want)
+		}
+	}
+
+	// The last day's occurrence is kept when the series is expanded, even
+	// late in the evening when that day has already ended in UTC.
+	ev := Event{StartTime: time.Date(2026, 6, 1, 23, 0, 0, 0, time.UTC), EndTime: time.Date(2026, 6, 1, 23, 30, 0, 0, time.UTC), StartTZ: "Europe/Madrid"}
+	ev.RRule, _ = SetRRuleUntil("FREQ=DAILY", UntilValue(last, false, ev.StartTZ))
+	occurrences, _, err := ev.Materialize(0, 0, nil)
+	if err != nil || len(occurrences) != 30 {
+		t.Errorf("got %d occurrences (%v), want 1-30 June", len(occurrences), err)
+	}
+
+	if got, err := SetRRuleUntil("FREQ=WEEKLY;BYDAY=MO;", "20260630"); err != nil || got != "FREQ=WEEKLY;BYDAY=MO;UNTIL=20260630" {
+		t.Errorf("SetRRuleUntil = %q, %v", got, err)
+	}
+	for _, rule := range []string{"FREQ=DAILY;COUNT=3", "FREQ=DAILY;UNTIL=20260101"} {
+		if _, err := SetRRuleUntil(rule, "20260630"); err == nil {
+			t.Errorf("SetRRuleUntil(%q) should refuse a second end", rule)
+		}
+	}
+}
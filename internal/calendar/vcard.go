@@ -0,0 +1,219 @@
+package calendar
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Contact date kinds.
+const (
+	ContactBirthday    = "birthday"
+	ContactAnniversary = "anniversary"
+)
+
+// Feb29 policies: the day a February 29th date is kept on in common years.
+const (
+	Feb29OnFeb28 = "feb28" // the last day of February
+	Feb29OnMar1  = "mar1"  // the day after February 28th
+	Feb29Leap    = "leap"  // leap years only
+)
+
+// ContactDate is a yearly date of a contact, read from a vCard.
+type ContactDate struct {
+	Name  string
+	Kind  string
+	Year  int // 0 when the card leaves the year out
+	Month time.Month
+	Day   int
+}
+
+// vcardDateRe matches the vCard date forms: 19850314, 1985-03-14, --0314 and
+// --03-14, optionally followed by a time.
+var vcardDateRe = regexp.MustCompile(`^(\d{4}|--)-?(\d{2})-?(\d{2})(?:T.*)?$`)
+
+// vcardNoYear is the year Apple Contacts writes when the year is unknown.
+const vcardNoYear = 1604
+
+// ParseVCards reads the birthdays (BDAY) and anniversaries (ANNIVERSARY,
+// X-ANNIVERSARY, or an X-ABDATE labelled Anniversary) of every card in a
+// vCard file. Cards with a date that cannot be read are described in notes.
+func ParseVCards(text string) (dates []ContactDate, notes []string) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	// Unfold continuation lines (RFC 6350 section 3.2).
+	text = strings.NewReplacer("\n ", "", "\n\t", "").Replace(text)
+
+	type property struct {
+		group, name, value string
+		params             map[string]string
+	}
+	var card []property
+	inCard := false
+	flush := func() {
+		name := ""
+		for _, p := range card {
+			if p.name == "FN" && p.value != "" {
+				name = p.value
+			}
+		}
+		if name == "" {
+			for _, p := range card {
+				if p.name == "N" {
+					parts := strings.Split(p.value, ";")
+					if len(parts) > 1 {
+						name = strings.TrimSpace(parts[1] + " " + parts[0])
+					} else {
+						name = strings.TrimSpace(parts[0])
+					}
+				}
+			}
+		}
+		labels := map[string]string{}
+		for _, p := range card {
+			if p.name == "X-ABLABEL" && p.group != "" {
+				labels[p.group] = strings.ToLower(p.value)
+			}
+		}
+		for _, p := range card {
+			kind := ""
+			switch p.name {
+			case "BDAY":
+				kind = ContactBirthday
+			case "ANNIVERSARY", "X-ANNIVERSARY":
+				kind = ContactAnniversary
+			case "X-ABDATE":
+				if strings.Contains(labels[p.group], "anniversary") {
+					kind = ContactAnniversary
+				}
+			}
+			if kind == "" {
+				continue
+			}
+			who := firstNonBlank(name, "unnamed contact")
+			d, err := parseVCardDate(p.value, p.params)
+			if err != nil {
+				notes = append(notes, fmt.Sprintf("%s: %s %v", who, kind, err))
+				continue
+			}
+			d.Name, d.Kind = who, kind
+			dates = append(dates, d)
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		parts := strings.Split(key, ";")
+		p := property{name: strings.ToUpper(parts[0]), value: unescapeVCard(value), params: map[string]string{}}
+		if group, name, grouped := strings.Cut(p.name, "."); grouped {
+			p.group, p.name = group, name
+		}
+		for _, param := range parts[1:] {
+			k, v, _ := strings.Cut(param, "=")
+			p.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VCARD"):
+			inCard, card = true, nil
+		case p.name == "END" && strings.EqualFold(p.value, "VCARD"):
+			if inCard {
+				flush()
+			}
+			inCard = false
+		case inCard:
+			card = append(card, p)
+		}
+	}
+	return dates, notes
+}
+
+func parseVCardDate(value string, params map[string]string) (ContactDate, error) {
+	value = strings.TrimSpace(value)
+	if strings.EqualFold(params["VALUE"], "text") {
+		return ContactDate{}, fmt.Errorf("%q is text, not a date", value)
+	}
+	m := vcardDateRe.FindStringSubmatch(value)
+	if m == nil {
+		return ContactDate{}, fmt.Errorf("has an unreadable date %q", value)
+	}
+	d := ContactDate{Month: time.Month(atoiOrZero(m[2])), Day: atoiOrZero(m[3])}
+	if m[1] != "--" {
+		d.Year = atoiOrZero(m[1])
+	}
+	if omit, err := strconv.Atoi(params["X-APPLE-OMIT-YEAR"]); (err == nil && omit == d.Year) || d.Year == vcardNoYear {
+		d.Year = 0
+	}
+	// Validate against a leap year so February 29th without a year passes.
+	year := d.Year
+	if year == 0 {
+		year = 2000
+	}
+	if d.Month < time.January || d.Month > time.December || d.Day < 1 || d.Day > daysIn(d.Month, year) {
+		return ContactDate{}, fmt.Errorf("has an invalid date %q", value)
+	}
+	return d, nil
+}
+
+func unescapeVCard(s string) string {
+	return strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\N`, " ", `\\`, `\`).Replace(strings.TrimSpace(s))
+}
+
+// IsFeb29 reports whether the date falls on February 29th.
+func (d ContactDate) IsFeb29() bool {
+	return d.Month == time.February && d.Day == 29
+}
+
+// Next returns the first time the date comes round on or after from (by its
+// date), following the Feb29 policy for February 29th.
+func (d ContactDate) Next(from time.Time, feb29 string) time.Time {
+	y, m, day := from.Date()
+	today := time.Date(y, m, day, 0, 0, 0, 0, time.UTC)
+	for year := y; ; year++ {
+		if at, ok := d.In(year, feb29); ok && !at.Before(today) {
+			return at
+		}
+	}
+}
+
+// In returns the date's day in the given year at midnight UTC. ok is false
+// when a February 29th date is kept to leap years and year is not one.
+func (d ContactDate) In(year int, feb29 string) (time.Time, bool) {
+	if d.IsFeb29() && daysIn(time.February, year) == 28 {
+		switch feb29 {
+		case Feb29Leap:
+			return time.Time{}, false
+		case Feb29OnMar1:
+			return time.Date(year, time.March, 1, 0, 0, 0, 0, time.UTC), true
+		default:
+			return time.Date(year, time.February, 28, 0, 0, 0, 0, time.UTC), true
+		}
+	}
+	return time.Date(year, d.Month, d.Day, 0, 0, 0, 0, time.UTC), true
+}
+
+// YearlyRule returns the RRULE repeating the date every year. February 29th
+// uses BYMONTHDAY=-1 (Feb 28 in common years), BYYEARDAY=60 (Mar 1) or
+// BYMONTHDAY=29 (leap years only), depending on feb29.
+func (d ContactDate) YearlyRule(feb29 string) string {
+	if !d.IsFeb29() {
+		return "FREQ=YEARLY"
+	}
+	switch feb29 {
+	case Feb29Leap:
+		return "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29"
+	case Feb29OnMar1:
+		return "FREQ=YEARLY;BYYEARDAY=60"
+	default:
+		return "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1"
+	}
+}
+
+// ValidFeb29Policy reports whether p is one of the Feb29 policies.
+func ValidFeb29Policy(p string) bool {
+	return p == Feb29OnFeb28 || p == Feb29OnMar1 || p == Feb29Leap
+}
@@ -0,0 +1,90 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testVCards = `BEGIN:VCARD
+VERSION:3.0
+FN:Ana García
+BDAY:1985-03-14
+item1.X-ABDATE;type=pref:2010-06-19
+item1.X-ABLabel:_$!<Anniversary>!$_
+END:VCARD
+BEGIN:VCARD
+VERSION:4.0
+FN:Leap
+  Year
+BDAY:--0229
+END:VCARD
+BEGIN:VCARD
+VERSION:3.0
+N:Murphy;Seán;;;
+BDAY;X-APPLE-OMIT-YEAR=1604:1604-11-02
+ANNIVERSARY:20120905T000000Z
+END:VCARD
+BEGIN:VCARD
+FN:Unknown
+BDAY;VALUE=text:circa 1800
+END:VCARD
+`
+
+func TestParseVCards(t *testing.T) {
+	dates, notes := ParseVCards(strings.ReplaceAll(testVCards, "\n", "\r\n"))
+	want := []ContactDate{
+		{Name: "Ana García", Kind: ContactBirthday, Year: 1985, Month: time.March, Day: 14},
+		{Name: "Ana García", Kind: ContactAnniversary, Year: 2010, Month: time.June, Day: 19},
+		{Name: "Leap Year", Kind: ContactBirthday, Month: time.February, Day: 29},
+		{Name: "Seán Murphy", Kind: ContactBirthday, Month: time.November, Day: 2},
+		{Name: "Seán Murphy", Kind: ContactAnniversary, Year: 2012, Month: time.September, Day: 5},
+	}
+	if len(dates) != len(want) {
+		t.Fatalf("ParseVCards = %+v, want %d dates", dates, len(want))
+	}
+	for i := range want {
+		if dates[i] != want[i] {
+			t.Errorf("date %d = %+v, want %+v", i, dates[i], want[i])
+		}
+	}
+	if len(notes) != 1 || !strings.Contains(notes[0], "Unknown") {
+		t.Errorf("expected a note for the text birthday, got %v", notes)
+	}
+}
+
+func TestContactDateFeb29(t *testing.T) {
+	leap := ContactDate{Month: time.February, Day: 29}
+	from := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		policy, next, rule string
+	}{
+		{Feb29OnFeb28, "2026-02-28", "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1"},
+		{Feb29OnMar1, "2026-03-01", "FREQ=YEARLY;BYYEARDAY=60"},
+		{Feb29Leap, "2028-02-29", "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29"},
+	}
+	for _, tt := range tests {
+		if got := leap.Next(from, tt.policy).Format("2006-01-02"); got != tt.next {
+			t.Errorf("Next(%s) = %s, want %s", tt.policy, got, tt.next)
+		}
+		if got := leap.YearlyRule(tt.policy); got != tt.rule {
+			t.Errorf("YearlyRule(%s) = %s, want %s", tt.policy, got, tt.rule)
+		}
+	}
+
+	plain := ContactDate{Month: time.March, Day: 10}
+	if got := plain.Next(from, Feb29OnFeb28).Format("2006-01-02"); got != "2025-03-10" {
+		t.Errorf("Next on the day itself = %s, want 2025-03-10", got)
+	}
+	if plain.YearlyRule(Feb29Leap) != "FREQ=YEARLY" {
+		t.Errorf("YearlyRule = %s, want FREQ=YEARLY", plain.YearlyRule(Feb29Leap))
+	}
+}
+
+func TestParseVCardDateRejectsInvalid(t *testing.T) {
+	for _, value := range []string{"1985-02-29", "--1301", "yesterday"} {
+		if _, err := parseVCardDate(value, map[string]string{}); err == nil {
+			t.Errorf("parseVCardDate(%q): expected an error", value)
+		}
+	}
+}
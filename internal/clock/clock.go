@@ -0,0 +1,32 @@
+// Package clock lets the calendar, batch and template code ask for the
+// current time through an interface, so tests and embedders can pin it.
+package clock
+
+import "time"
+
+// Clock tells the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// System is the real wall clock.
+var System Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// Fixed returns a clock that always reads t.
+func Fixed(t time.Time) Clock {
+	return fixedClock{t}
+}
+
+type fixedClock struct{ t time.Time }
+
+func (c fixedClock) Now() time.Time { return c.t }
+
+// Func adapts a function to a Clock, e.g. one that advances on every call.
+type Func func() time.Time
+
+// Now calls f.
+func (f Func) Now() time.Time { return f() }
@@ -0,0 +1,28 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClocks(t *testing.T) {
+	before := time.Now()
+	if now := System.Now(); now.Before(before) || time.Since(now) > time.Minute {
+		t.Errorf("System.Now() = %v, want about %v", now, before)
+	}
+
+	pinned := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+	c := Fixed(pinned)
+	if !c.Now().Equal(pinned) || !c.Now().Equal(pinned) {
+		t.Errorf("Fixed clock moved: %v", c.Now())
+	}
+
+	tick := pinned
+	var f Clock = Func(func() time.Time {
+		tick = tick.Add(time.Second)
+		return tick
+	})
+	if first, second := f.Now(), f.Now(); second.Sub(first) != time.Second {
+		t.Errorf("Func clock should call the function each time: %v, %v", first, second)
+	}
+}
@@ -0,0 +1,29 @@
+package config
+
+// builtinPrepBuffers are the keywords (or categories) that get a buffer before
+// the event with batch --add-prep-time: travel and arrival for medical
+// appointments, preparation for meetings.
+var builtinPrepBuffers = map[string]string{
+	"doctor": "20m", "médico": "20m", "medico": "20m", "dentist": "20m",
+	"therapy": "20m", "hospital": "20m", "clinic": "20m",
+	"meeting": "15m", "reunion": "15m", "reunión": "15m", "appointment": "15m",
+	"cita": "15m", "interview": "15m", "call": "15m",
+}
+
+// builtinTransitionBuffers get a buffer after the event, to wind down from
+// hyperfocus before the next thing.
+var builtinTransitionBuffers = map[string]string{
+	"focus": "5m", "deep work": "5m", "coding": "5m", "writing": "5m",
+}
+
+// ActivePrepBuffers returns the prep_buffers in effect, without the ones
+// turned off.
+func (c *Config) ActivePrepBuffers() map[string]string {
+	return activeEntries(c.PrepBuffers)
+}
+
+// ActiveTransitionBuffers returns the transition_buffers in effect, without
+// the ones turned off.
+func (c *Config) ActiveTransitionBuffers() map[string]string {
+	return activeEntries(c.TransitionBuffers)
+}
@@ -0,0 +1,46 @@
+package config
+
+import "sync"
+
+// The configuration Current hands out, keyed by the directory it was read
+// from so a changed XDG_CONFIG_HOME (as in tests) is not served stale.
+var cache struct {
+	mu     sync.Mutex
+	loaded bool
+	dir    string
+	cfg    *Config
+	err    error
+}
+
+// Current returns the configuration, reading it from disk on first use only.
+// Every later call gets the same *Config (or the same load error) until
+// Reload or Save, so callers may ask for it per row without re-parsing the
+// file. It is safe for concurrent use; callers must not modify the result,
+// and should use Load for a private copy they mean to change and Save.
+func Current() (*Config, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if !cache.loaded || cache.dir != dir {
+		cache.cfg, cache.err = Load()
+		cache.dir, cache.loaded = dir, true
+	}
+	return cache.cfg, cache.err
+}
+
+// Reload drops the cached configuration and reads it from disk again.
+func Reload() (*Config, error) {
+	forget()
+	return Current()
+}
+
+// forget drops the cached configuration; the next Current reads the file.
+func forget() {
+	cache.mu.Lock()
+	cache.loaded, cache.cfg, cache.err = false, nil, nil
+	cache.mu.Unlock()
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func writeTestConfig(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, "tempus"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tempus", "config.yaml"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCurrentCachesUntilReload(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	t.Cleanup(forget)
+	writeTestConfig(t, dir, "timezone: Europe/Madrid\n")
+
+	first, err := Reload()
+	if err != nil || first.Timezone != "Europe/Madrid" {
+		t.Fatalf("Reload() = %+v, %v", first, err)
+	}
+
+	writeTestConfig(t, dir, "timezone: Asia/Tokyo\n")
+	if again, _ := Current(); again != first {
+		t.Error("Current() should return the cached config without re-reading the file")
+	}
+	reloaded, err := Reload()
+	if err != nil || reloaded.Timezone != "Asia/Tokyo" {
+		t.Errorf("Reload() should read the changed file, got %+v, %v", reloaded, err)
+	}
+
+	// Another config dir is a different configuration.
+	other := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", other)
+	viper.Reset()
+	if cfg, _ := Current(); cfg == reloaded || cfg.Timezone != "UTC" {
+		t.Errorf("Current() after switching config dirs = %+v", cfg)
+	}
+}
+
+func TestSaveDropsTheCachedConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	t.Cleanup(forget)
+
+	cached, err := Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Set("timezone", "Europe/Lisbon"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Current()
+	if err != nil || got == cached || got.Timezone != "Europe/Lisbon" {
+		t.Errorf("Current() after Save = %+v, %v; want the saved timezone", got, err)
+	}
+}
+
+func TestCurrentIsSafeForConcurrentUse(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	t.Cleanup(forget)
+
+	var wg sync.WaitGroup
+	configs := make([]*Config, 16)
+	for i := range configs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			configs[i], _ = Current()
+		}(i)
+	}
+	wg.Wait()
+	for i, cfg := range configs {
+		if cfg == nil || cfg != configs[0] {
+			t.Fatalf("goroutine %d got %p, want the shared %p", i, cfg, configs[0])
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// CategoryColor returns the configured color of the first of categories that
+// has one. A subcategory without its own color takes its parent's, so a
+// color for Work also covers Work/ClientA.
+func (c *Config) CategoryColor(categories []string) (category, color string, ok bool) {
+	colors := make(map[string]string, len(c.CategoryColors))
+	for name, color := range c.CategoryColors {
+		if color = strings.TrimSpace(color); color != "" {
+			colors[strings.ToLower(strings.TrimSpace(name))] = color
+		}
+	}
+	for _, cat := range categories {
+		key := strings.ToLower(strings.TrimSpace(cat))
+		for key != "" {
+			if color, ok := colors[key]; ok {
+				return cat, color, true
+			}
+			i := strings.LastIndex(key, "/")
+			if i < 0 {
+				break
+			}
+			key = key[:i]
+		}
+	}
+	return "", "", false
+}
+
+// SetCategoryColor stores color for category and saves the config; the
+// caller checks that color is one.
+func (c *Config) SetCategoryColor(category, color string) error {
+	key, err := colorCategory(category)
+	if err != nil {
+		return err
+	}
+	if color = strings.TrimSpace(color); color == "" {
+		return fmt.Errorf("color for %q must not be empty", category)
+	}
+	return c.setCategoryColor(key, color)
+}
+
+// RemoveCategoryColor stops coloring category and saves the config.
+func (c *Config) RemoveCategoryColor(category string) error {
+	key, err := colorCategory(category)
+	if err != nil {
+		return err
+	}
+	found := false
+	for name, color := range c.CategoryColors {
+		if strings.EqualFold(strings.TrimSpace(name), key) && strings.TrimSpace(color) != "" {
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("no color for category %q", category)
+	}
+	return c.setCategoryColor(key, "")
+}
+
+func (c *Config) setCategoryColor(key, color string) error {
+	viper.Set("category_colors."+key, color)
+	if c.CategoryColors == nil {
+		c.CategoryColors = map[string]string{}
+	}
+	for name := range c.CategoryColors {
+		if strings.EqualFold(strings.TrimSpace(name), key) {
+			delete(c.CategoryColors, name)
+		}
+	}
+	if color != "" {
+		c.CategoryColors[key] = color
+	}
+	return c.Save()
+}
+
+// colorCategory normalizes a category_colors key: viper lowercases keys and
+// splits them at dots.
+func colorCategory(category string) (string, error) {
+	key := strings.ToLower(strings.TrimSpace(category))
+	switch {
+	case key == "":
+		return "", fmt.Errorf("category must not be empty")
+	case strings.Contains(key, "."):
+		return "", fmt.Errorf("category %q must not contain dots", category)
+	}
+	return key, nil
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestCategoryColorsSetRemovePersist(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, testConfigDir))
+	t.Cleanup(viper.Reset)
+
+	cfg := loadCorrectionsConfig(t)
+	if _, _, ok := cfg.CategoryColor([]string{"Health"}); ok {
+		t.Fatal("expected no category colors by default")
+	}
+	if err := cfg.SetCategoryColor("Health", "#2ecc71"); err != nil {
+		t.Fatalf("SetCategoryColor() failed: %v", err)
+	}
+	if err := cfg.SetCategoryColor("Work", "steelblue"); err != nil {
+		t.Fatalf("SetCategoryColor() failed: %v", err)
+	}
+
+	cfg = loadCorrectionsConfig(t)
+	if cat, color, ok := cfg.CategoryColor([]string{"Errands", "health"}); !ok || cat != "health" || color != "#2ecc71" {
+		t.Errorf("CategoryColor(Errands, health) = %q, %q, %v", cat, color, ok)
+	}
+	if cat, color, ok := cfg.CategoryColor([]string{"Work/ClientA"}); !ok || cat != "Work/ClientA" || color != "steelblue" {
+		t.Errorf("expected a subcategory to take its parent's color, got %q, %q, %v", cat, color, ok)
+	}
+
+	if err := cfg.RemoveCategoryColor("WORK"); err != nil {
+		t.Fatalf("RemoveCategoryColor() failed: %v", err)
+	}
+	cfg = loadCorrectionsConfig(t)
+	if _, _, ok := cfg.CategoryColor([]string{"Work"}); ok {
+		t.Error("expected removed color to stay removed after reload")
+	}
+	if err := cfg.RemoveCategoryColor("Work"); err == nil {
+		t.Error("expected an error removing a category without a color")
+	}
+	if err := cfg.SetCategoryColor("a.b", "red"); err == nil {
+		t.Error("expected an error for a category with a dot")
+	}
+	if err := cfg.SetCategoryColor(" ", "red"); err == nil {
+		t.Error("expected an error for an empty category")
+	}
+}
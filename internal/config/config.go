@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,23 +16,87 @@ import (
 )
 
 type Config struct {
+	// ConfigVersion is the schema version of the file; older files are migrated on Load.
+	ConfigVersion    int                 `mapstructure:"config_version" json:"config_version"`
 	Language         string              `mapstructure:"language" json:"language"`
 	Timezone         string              `mapstructure:"timezone" json:"timezone"`
 	DateFormat       string              `mapstructure:"date_format" json:"date_format"`
 	TimeFormat       string              `mapstructure:"time_format" json:"time_format"`
-	OutputDir        string              `mapstructure:"output_dir" json:"output_dir"`
+	OutputDir        string              `mapstructure:"output_dir" json:"output_dir"` // older spelling of output.dir
 	DefaultTitle     string              `mapstructure:"default_title" json:"default_title"`
 	AlarmProfiles    map[string][]string `mapstructure:"alarm_profiles" json:"alarm_profiles"`
 	SpellCorrections map[string]string   `mapstructure:"spell_corrections" json:"spell_corrections"`
+	// AlarmDirection is where an unsigned alarm trigger such as "15m" falls:
+	// "before" (the default) or "after" the event.
+	AlarmDirection string `mapstructure:"alarm_direction" json:"alarm_direction"`
+	// UIDDomain and UIDTemplate shape the UIDs of new events: {{id}} in the
+	// template is the unique part and {{domain}} the domain, so the default
+	// "{{id}}@{{domain}}" with "tempus" gives "…@tempus".
+	UIDDomain   string `mapstructure:"uid_domain" json:"uid_domain"`
+	UIDTemplate string `mapstructure:"uid_template" json:"uid_template"`
+	// CategoryDurations maps a category (case-insensitive) to a default duration
+	// such as "50m" or "1h30m". It wins over the keyword-based duration heuristic.
+	CategoryDurations map[string]string `mapstructure:"category_durations" json:"category_durations"`
+	// CategoryTaxonomy lists the categories in use, with "/" between levels
+	// (Work/ClientA, Health/Medication). Batch spells categories as listed here
+	// and warns about ones that are not; an empty taxonomy accepts anything.
+	CategoryTaxonomy []string `mapstructure:"category_taxonomy" json:"category_taxonomy"`
+	// CategoryColors maps a category (case-insensitive; Work also colors
+	// Work/ClientA) to a color, #rrggbb or a CSS color name, written into
+	// the events as COLOR.
+	CategoryColors map[string]string `mapstructure:"category_colors" json:"category_colors"`
+	// DurationKeywords maps a word in the summary (any language) to the default
+	// duration of rows without end, duration or category duration. They are added
+	// to the built-in keywords; an empty duration turns a built-in one off.
+	DurationKeywords map[string]string `mapstructure:"duration_keywords" json:"duration_keywords"`
+	// DurationTimeOfDay maps HH:MM-HH:MM windows of the start time to a duration,
+	// used when no keyword matches; DefaultDuration applies outside every window.
+	DurationTimeOfDay map[string]string `mapstructure:"duration_time_of_day" json:"duration_time_of_day"`
+	DefaultDuration   string            `mapstructure:"default_duration" json:"default_duration"`
+	// PrepBuffers and TransitionBuffers map a category or a word in the summary
+	// to the buffer batch --add-prep-time puts before or after the event. They
+	// are added to the built-in rules; an empty duration turns one off.
+	PrepBuffers       map[string]string `mapstructure:"prep_buffers" json:"prep_buffers"`
+	TransitionBuffers map[string]string `mapstructure:"transition_buffers" json:"transition_buffers"`
+	// TransparentBuffers writes buffers as TRANSP:TRANSPARENT so they don't
+	// block free/busy time.
+	TransparentBuffers bool `mapstructure:"transparent_buffers" json:"transparent_buffers"`
+	// TravelTimes maps "Place A -> Place B" to how long getting between them
+	// takes, both ways unless the reverse is listed too. DefaultTravelTime
+	// applies to other pairs of different locations; empty leaves them out.
+	TravelTimes       map[string]string `mapstructure:"travel_times" json:"travel_times"`
+	DefaultTravelTime string            `mapstructure:"default_travel_time" json:"default_travel_time"`
+	// WorkingHours and QuietHours map weekdays (mon..sun, weekdays, weekend, daily)
+	// to HH:MM-HH:MM windows. Events/alarms outside or inside them are flagged.
+	WorkingHours map[string]string `mapstructure:"working_hours" json:"working_hours"`
+	QuietHours   map[string]string `mapstructure:"quiet_hours" json:"quiet_hours"`
+	// EnergyBudget is the daily energy total (sum of the batch energy column,
+	// 1-5 per event) above which batch warns; 0 turns the check off.
+	EnergyBudget int `mapstructure:"energy_budget" json:"energy_budget"`
+	// PastWarningDays is how many days ago an event may have ended before
+	// create, batch and lint warn that it is in the past; 0 turns the check off.
+	PastWarningDays int `mapstructure:"past_warning_days" json:"past_warning_days"`
+	// Holidays maps ISO dates (YYYY-MM-DD) to holiday names for --skip-holidays.
+	Holidays map[string]string `mapstructure:"holidays" json:"holidays"`
+	// Experimental turns feature flags on or off, overriding the release-channel default.
+	Experimental map[string]bool `mapstructure:"experimental" json:"experimental"`
+	// ColumnAliases maps a batch CSV column (summary, start, ...) to other
+	// headers it may appear under, tried in order when a file lacks the column.
+	// "Start Date+Start Time" joins two columns with a space.
+	ColumnAliases map[string][]string `mapstructure:"column_aliases" json:"column_aliases"`
+	// Output controls the directory, permissions and overwrite policy of
+	// generated calendars.
+	Output OutputConfig `mapstructure:"output" json:"output"`
 }
 
 var defaultConfig = Config{
-	Language:     "en",
-	Timezone:     "UTC",
-	DateFormat:   constants.DateFormatISO,
-	TimeFormat:   constants.TimeFormatHHMM,
-	OutputDir:    ".",
-	DefaultTitle: "Event",
+	ConfigVersion: CurrentConfigVersion,
+	Language:      "en",
+	Timezone:      "UTC",
+	DateFormat:    constants.DateFormatISO,
+	TimeFormat:    constants.TimeFormatHHMM,
+	OutputDir:     ".",
+	DefaultTitle:  "Event",
 	AlarmProfiles: map[string][]string{
 		// Evidence-based ADHD profiles (neuroscience research 2024-2025)
 		// Spacing based on working memory & prospective memory studies
@@ -67,6 +133,25 @@ var defaultConfig = Config{
 		"excersize":    "exercise",
 		"excercise":    "exercise",
 	},
+	AlarmDirection:    "before",
+	UIDDomain:         constants.DefaultUIDDomain,
+	UIDTemplate:       constants.DefaultUIDTemplate,
+	CategoryDurations: map[string]string{},
+	CategoryTaxonomy:  []string{},
+	CategoryColors:    map[string]string{},
+	DurationKeywords:  map[string]string{},
+	DurationTimeOfDay: builtinDurationTimeOfDay,
+	DefaultDuration:   "1h",
+	PrepBuffers:       map[string]string{},
+	TransitionBuffers: map[string]string{},
+	TravelTimes:       map[string]string{},
+	WorkingHours:      map[string]string{},
+	QuietHours:        map[string]string{},
+	PastWarningDays:   30,
+	Holidays:          map[string]string{},
+	Experimental:      map[string]bool{},
+	ColumnAliases:     map[string][]string{},
+	Output:            defaultOutput,
 }
 
 // Load loads configuration from file or creates defaults in memory.
@@ -82,7 +167,17 @@ func Load() (*Config, error) {
 	viper.AddConfigPath(configDir)
 	viper.AddConfigPath(".")
 
+	// Upgrade an old config file in place (with a backup) before reading it.
+	// Only the file in the config dir is migrated, never ./config.yaml.
+	configFile := filepath.Join(configDir, "config.yaml")
+	if _, err := os.Stat(configFile); err == nil {
+		if report, err := MigrateFile(configFile); err == nil && report != nil {
+			fmt.Fprintf(os.Stderr, "tempus: migrated %s to config_version %d (backup: %s)\n", report.Path, report.ToVersion, report.BackupPath)
+		}
+	}
+
 	// Defaults
+	viper.SetDefault("config_version", defaultConfig.ConfigVersion)
 	viper.SetDefault("language", defaultConfig.Language)
 	viper.SetDefault("timezone", defaultConfig.Timezone)
 	viper.SetDefault("date_format", defaultConfig.DateFormat)
@@ -91,6 +186,32 @@ func Load() (*Config, error) {
 	viper.SetDefault("default_title", defaultConfig.DefaultTitle)
 	viper.SetDefault("alarm_profiles", defaultConfig.AlarmProfiles)
 	viper.SetDefault("spell_corrections", defaultConfig.SpellCorrections)
+	viper.SetDefault("alarm_direction", defaultConfig.AlarmDirection)
+	viper.SetDefault("uid_domain", defaultConfig.UIDDomain)
+	viper.SetDefault("uid_template", defaultConfig.UIDTemplate)
+	viper.SetDefault("category_durations", defaultConfig.CategoryDurations)
+	viper.SetDefault("category_taxonomy", defaultConfig.CategoryTaxonomy)
+	viper.SetDefault("category_colors", defaultConfig.CategoryColors)
+	viper.SetDefault("duration_keywords", defaultConfig.DurationKeywords)
+	viper.SetDefault("duration_time_of_day", defaultConfig.DurationTimeOfDay)
+	viper.SetDefault("default_duration", defaultConfig.DefaultDuration)
+	viper.SetDefault("prep_buffers", defaultConfig.PrepBuffers)
+	viper.SetDefault("transition_buffers", defaultConfig.TransitionBuffers)
+	viper.SetDefault("transparent_buffers", defaultConfig.TransparentBuffers)
+	viper.SetDefault("travel_times", defaultConfig.TravelTimes)
+	viper.SetDefault("default_travel_time", defaultConfig.DefaultTravelTime)
+	viper.SetDefault("working_hours", defaultConfig.WorkingHours)
+	viper.SetDefault("quiet_hours", defaultConfig.QuietHours)
+	viper.SetDefault("energy_budget", defaultConfig.EnergyBudget)
+	viper.SetDefault("past_warning_days", defaultConfig.PastWarningDays)
+	viper.SetDefault("holidays", defaultConfig.Holidays)
+	viper.SetDefault("experimental", defaultConfig.Experimental)
+	viper.SetDefault("column_aliases", defaultConfig.ColumnAliases)
+	viper.SetDefault("output.dir", defaultConfig.Output.Dir)
+	viper.SetDefault("output.file_mode", string(defaultConfig.Output.FileMode))
+	viper.SetDefault("output.dir_mode", string(defaultConfig.Output.DirMode))
+	viper.SetDefault("output.overwrite", defaultConfig.Output.Overwrite)
+	viper.SetDefault("output.name_max_length", defaultConfig.Output.NameMaxLength)
 
 	// Try to read config file
 	if err := viper.ReadInConfig(); err != nil {
@@ -101,14 +222,79 @@ func Load() (*Config, error) {
 	}
 
 	var cfg Config
-	if err := viper.Unmarshal(&cfg); err != nil {
+	if err := viper.Unmarshal(&cfg, viper.DecodeHook(decodeHook)); err != nil {
 		return nil, err
 	}
+	cfg.withBuiltinEntries()
 	return &cfg, nil
 }
 
+// Defaults returns the built-in configuration, as Load gives it without a
+// config file. Its maps are shared; callers must not modify them.
+func Defaults() *Config {
+	cfg := defaultConfig
+	cfg.withBuiltinEntries()
+	return &cfg
+}
+
+// withBuiltinEntries lays the user's spell_corrections, duration_keywords and
+// buffer rules over the built-in ones; viper replaces a map from the config
+// file wholesale instead of merging it. Empty values mark entries turned off.
+func (c *Config) withBuiltinEntries() {
+	c.SpellCorrections = overlayEntries(defaultConfig.SpellCorrections, c.SpellCorrections)
+	c.DurationKeywords = overlayEntries(builtinDurationKeywords, c.DurationKeywords)
+	c.PrepBuffers = overlayEntries(builtinPrepBuffers, c.PrepBuffers)
+	c.TransitionBuffers = overlayEntries(builtinTransitionBuffers, c.TransitionBuffers)
+}
+
+func overlayEntries(builtin, user map[string]string) map[string]string {
+	merged := make(map[string]string, len(builtin)+len(user))
+	for key, value := range builtin {
+		merged[key] = value
+	}
+	for key, value := range user {
+		merged[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+	return merged
+}
+
+// activeEntries drops the entries turned off with an empty value.
+func activeEntries(entries map[string]string) map[string]string {
+	active := make(map[string]string, len(entries))
+	for key, value := range entries {
+		if key != "" && value != "" {
+			active[key] = value
+		}
+	}
+	return active
+}
+
 // Set sets a configuration value and persists it to disk.
 func (c *Config) Set(key, value string) error {
+	if err := validateOutputValue(key, value); err != nil {
+		return err
+	}
+	if err := validateDisplayFormat(key, value); err != nil {
+		return err
+	}
+	if key == "alarm_direction" {
+		if err := ValidateAlarmDirection(value); err != nil {
+			return err
+		}
+		value = strings.ToLower(strings.TrimSpace(value))
+	}
+	if key == "uid_domain" || key == "uid_template" {
+		value = strings.TrimSpace(value)
+		domain, template := c.UIDDomain, c.UIDTemplate
+		if key == "uid_domain" {
+			domain = value
+		} else {
+			template = value
+		}
+		if err := ValidateUIDFormat(template, domain); err != nil {
+			return err
+		}
+	}
 	viper.Set(key, value)
 
 	// Update struct fields for the running process
@@ -125,6 +311,22 @@ func (c *Config) Set(key, value string) error {
 		c.OutputDir = value
 	case "default_title":
 		c.DefaultTitle = value
+	case "alarm_direction":
+		c.AlarmDirection = value
+	case "uid_domain":
+		c.UIDDomain = value
+	case "uid_template":
+		c.UIDTemplate = value
+	case "output.dir":
+		c.Output.Dir = value
+	case "output.file_mode":
+		c.Output.FileMode = Mode(value)
+	case "output.dir_mode":
+		c.Output.DirMode = Mode(value)
+	case "output.overwrite":
+		c.Output.Overwrite = value
+	case "output.name_max_length":
+		c.Output.NameMaxLength, _ = parseNameMaxLength(value)
 	default:
 		return fmt.Errorf("unknown configuration key: %s", key)
 	}
@@ -132,6 +334,22 @@ func (c *Config) Set(key, value string) error {
 	return c.Save()
 }
 
+// validateDisplayFormat rejects date_format and time_format values that
+// cannot be turned into a time layout.
+func validateDisplayFormat(key, value string) error {
+	var err error
+	switch key {
+	case "date_format":
+		_, err = i18n.DateLayout(value)
+	case "time_format":
+		_, err = i18n.TimeLayout(value)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return nil
+}
+
 // Get returns a configuration value by key.
 func (c *Config) Get(key string) (string, error) {
 	switch key {
@@ -147,6 +365,22 @@ func (c *Config) Get(key string) (string, error) {
 		return c.OutputDir, nil
 	case "default_title":
 		return c.DefaultTitle, nil
+	case "alarm_direction":
+		return c.AlarmDirection, nil
+	case "uid_domain":
+		return c.UIDDomain, nil
+	case "uid_template":
+		return c.UIDTemplate, nil
+	case "output.dir":
+		return c.Output.Dir, nil
+	case "output.file_mode":
+		return string(c.Output.FileMode), nil
+	case "output.dir_mode":
+		return string(c.Output.DirMode), nil
+	case "output.overwrite":
+		return c.Output.Overwrite, nil
+	case "output.name_max_length":
+		return strconv.Itoa(c.Output.NameMaxLength), nil
 	default:
 		return "", fmt.Errorf("unknown configuration key: %s", key)
 	}
@@ -169,6 +403,14 @@ func (c *Config) List() error {
 	fmt.Printf("time_format: %s\n", c.TimeFormat)
 	fmt.Printf("output_dir: %s\n", c.OutputDir)
 	fmt.Printf("default_title: %s\n", c.DefaultTitle)
+	fmt.Printf("alarm_direction: %s\n", c.AlarmDirection)
+	fmt.Printf("uid_domain: %s\n", c.UIDDomain)
+	fmt.Printf("uid_template: %s\n", c.UIDTemplate)
+	fmt.Printf("output.dir: %s\n", c.Output.Dir)
+	fmt.Printf("output.file_mode: %s\n", c.Output.FileMode)
+	fmt.Printf("output.dir_mode: %s\n", c.Output.DirMode)
+	fmt.Printf("output.overwrite: %s\n", c.Output.Overwrite)
+	fmt.Printf("output.name_max_length: %d\n", c.Output.NameMaxLength)
 	return nil
 }
 
@@ -182,6 +424,7 @@ func (c *Config) Save() error {
 		return err
 	}
 	configFile := filepath.Join(configDir, "config.yaml")
+	defer forget()
 	return viper.WriteConfigAs(configFile)
 }
 
@@ -239,6 +482,26 @@ func (c *Config) ListAlarmProfiles() []string {
 	return profiles
 }
 
+// GetCategoryDuration returns the configured default duration for the first of
+// categories that has one. Category names are matched case-insensitively.
+func (c *Config) GetCategoryDuration(categories []string) (category, duration string, ok bool) {
+	if len(c.CategoryDurations) == 0 {
+		return "", "", false
+	}
+	for _, cat := range categories {
+		key := strings.ToLower(strings.TrimSpace(cat))
+		if key == "" {
+			continue
+		}
+		for name, dur := range c.CategoryDurations {
+			if strings.ToLower(name) == key && strings.TrimSpace(dur) != "" {
+				return cat, strings.TrimSpace(dur), true
+			}
+		}
+	}
+	return "", "", false
+}
+
 // ValidateTimezone checks the TZ identifier using the system tz database.
 func ValidateTimezone(tz string) error {
 	if strings.TrimSpace(tz) == "" {
@@ -250,6 +513,47 @@ func ValidateTimezone(tz string) error {
 	return nil
 }
 
+// ValidateAlarmDirection checks an alarm_direction value: before or after.
+func ValidateAlarmDirection(direction string) error {
+	switch strings.ToLower(strings.TrimSpace(direction)) {
+	case "before", "after":
+		return nil
+	}
+	return fmt.Errorf("invalid alarm_direction %q (use before or after)", direction)
+}
+
+var (
+	uidDomainRe      = regexp.MustCompile(`^@?[A-Za-z0-9]([A-Za-z0-9._-]*[A-Za-z0-9])?$`)
+	uidPlaceholderRe = regexp.MustCompile(`\{\{[^}]*\}\}`)
+)
+
+// ValidateUIDFormat checks uid_template and uid_domain: the domain is a host
+// name such as calendar.example.com (a leading "@" is allowed), and the
+// template must contain {{id}}, may contain {{domain}} and nothing that needs
+// escaping in a UID (spaces, commas, semicolons, backslashes). Empty values
+// stand for the defaults.
+func ValidateUIDFormat(template, domain string) error {
+	if domain = strings.TrimSpace(domain); domain != "" && !uidDomainRe.MatchString(domain) {
+		return fmt.Errorf("invalid uid_domain %q (use a host name such as calendar.example.com)", domain)
+	}
+	template = strings.TrimSpace(template)
+	if template == "" {
+		return nil
+	}
+	for _, p := range uidPlaceholderRe.FindAllString(template, -1) {
+		if p != "{{id}}" && p != "{{domain}}" {
+			return fmt.Errorf("invalid uid_template %q: unknown placeholder %s (use {{id}} and {{domain}})", template, p)
+		}
+	}
+	if !strings.Contains(template, "{{id}}") {
+		return fmt.Errorf("invalid uid_template %q: it must contain {{id}}, or every event gets the same UID", template)
+	}
+	if i := strings.IndexFunc(template, func(r rune) bool { return r <= ' ' || r == 0x7f || strings.ContainsRune(`,;\"`, r) }); i >= 0 {
+		return fmt.Errorf("invalid uid_template %q: %q is not allowed in a UID", template, template[i:i+1])
+	}
+	return nil
+}
+
 // ValidateLanguage checks if a language code is supported.
 func ValidateLanguage(lang string) error {
 	normalized := strings.ToLower(strings.TrimSpace(lang))
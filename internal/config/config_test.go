@@ -96,6 +96,39 @@ default_title: "Mi Evento"
 	}
 }
 
+func TestGetCategoryDuration(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, testConfigDir, "tempus")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, testConfigDir))
+
+	configContent := `category_durations:
+  Therapy: 50m
+  Standup: 15m
+`
+	if err := os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(configContent), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	viper.Reset()
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	cat, dur, ok := cfg.GetCategoryDuration([]string{"Health", "THERAPY"})
+	if !ok || cat != "THERAPY" || dur != "50m" {
+		t.Errorf("expected THERAPY -> 50m, got %q -> %q (ok=%v)", cat, dur, ok)
+	}
+	if _, _, ok := cfg.GetCategoryDuration([]string{"Work"}); ok {
+		t.Error("expected no duration for unmapped category")
+	}
+}
+
 func TestSetValidKey(t *testing.T) {
 	tmpDir := t.TempDir()
 	t.Setenv("HOME", tmpDir)
@@ -148,6 +181,111 @@ func TestSetInvalidKey(t *testing.T) {
 	}
 }
 
+func TestSetInvalidDisplayFormat(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, testConfigDir))
+
+	viper.Reset()
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.Set("date_format", "someday"); err == nil || !strings.Contains(err.Error(), "date_format") {
+		t.Errorf("expected a date_format error, got %v", err)
+	}
+	if err := cfg.Set("time_format", "noon"); err == nil || !strings.Contains(err.Error(), "time_format") {
+		t.Errorf("expected a time_format error, got %v", err)
+	}
+	if err := cfg.Set("date_format", "DD/MM/YYYY"); err != nil {
+		t.Errorf("Set(date_format, DD/MM/YYYY) failed: %v", err)
+	}
+}
+
+func TestSetAlarmDirection(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, testConfigDir))
+
+	viper.Reset()
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AlarmDirection != "before" {
+		t.Errorf("default alarm_direction = %q, want before", cfg.AlarmDirection)
+	}
+	if err := cfg.Set("alarm_direction", "sideways"); err == nil || !strings.Contains(err.Error(), "alarm_direction") {
+		t.Errorf("expected an alarm_direction error, got %v", err)
+	}
+	if err := cfg.Set("alarm_direction", " After "); err != nil {
+		t.Fatalf("Set(alarm_direction, After) failed: %v", err)
+	}
+	if cfg.AlarmDirection != "after" {
+		t.Errorf("alarm_direction = %q, want after", cfg.AlarmDirection)
+	}
+}
+
+func TestSetUIDFormat(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, testConfigDir))
+	t.Cleanup(viper.Reset)
+
+	viper.Reset()
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.UIDDomain != "tempus" || cfg.UIDTemplate != "{{id}}@{{domain}}" {
+		t.Errorf("default UID format = %q, %q", cfg.UIDTemplate, cfg.UIDDomain)
+	}
+	if err := cfg.Set("uid_domain", "@calendar.example.com"); err != nil {
+		t.Fatalf("Set(uid_domain) failed: %v", err)
+	}
+	if err := cfg.Set("uid_template", "evt-{{id}}"); err != nil {
+		t.Fatalf("Set(uid_template) failed: %v", err)
+	}
+	if err := cfg.Set("uid_template", "{{domain}}"); err == nil || !strings.Contains(err.Error(), "{{id}}") {
+		t.Errorf("expected a template without {{id}} to be rejected, got %v", err)
+	}
+
+	viper.Reset()
+	cfg, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.UIDDomain != "@calendar.example.com" || cfg.UIDTemplate != "evt-{{id}}" {
+		t.Errorf("UID format after reload = %q, %q", cfg.UIDTemplate, cfg.UIDDomain)
+	}
+}
+
+func TestValidateUIDFormat(t *testing.T) {
+	tests := []struct {
+		template, domain string
+		wantErr          string
+	}{
+		{"", "", ""},
+		{"{{id}}@{{domain}}", "calendar.example.com", ""},
+		{"{{id}}@{{domain}}", "@calendar.example.com", ""},
+		{"team-{{id}}@{{domain}}", "corp_cal", ""},
+		{"", "calendar example.com", "uid_domain"},
+		{"", "-calendar.example.com", "uid_domain"},
+		{"", "user@example.com", "uid_domain"},
+		{"{{domain}}", "", "must contain {{id}}"},
+		{"{{id}}@{{host}}", "", "unknown placeholder {{host}}"},
+		{"{{id}} @{{domain}}", "", `" " is not allowed`},
+		{"{{id}};{{domain}}", "", `";" is not allowed`},
+	}
+	for _, tt := range tests {
+		err := ValidateUIDFormat(tt.template, tt.domain)
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("ValidateUIDFormat(%q, %q) = %v, want %q", tt.template, tt.domain, err, tt.wantErr)
+		}
+	}
+}
+
 func TestGetAllKeys(t *testing.T) {
 	tmpDir := t.TempDir()
 	t.Setenv("HOME", tmpDir)
@@ -159,7 +297,7 @@ func TestGetAllKeys(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	keys := []string{"language", "timezone", "date_format", "time_format", "output_dir", "default_title"}
+	keys := []string{"language", "timezone", "date_format", "time_format", "output_dir", "default_title", "alarm_direction", "uid_domain", "uid_template"}
 	for _, key := range keys {
 		_, err := cfg.Get(key)
 		if err != nil {
@@ -383,6 +521,7 @@ func TestSetAllFields(t *testing.T) {
 		{"time_format", "15:04:05", func(c *Config) string { return c.TimeFormat }},
 		{"output_dir", "/tmp", func(c *Config) string { return c.OutputDir }},
 		{"default_title", testutil.EventTitleTestEvent, func(c *Config) string { return c.DefaultTitle }},
+		{"alarm_direction", "after", func(c *Config) string { return c.AlarmDirection }},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Corrections returns the active spell_corrections dictionary: the built-in
+// words plus the user's own, minus the ones removed (stored with an empty
+// correction so the built-in default does not come back on the next Load).
+func (c *Config) Corrections() map[string]string {
+	return activeEntries(c.SpellCorrections)
+}
+
+// BuiltinCorrection returns the correction tempus ships for word, if any.
+func BuiltinCorrection(word string) (string, bool) {
+	correction, ok := defaultConfig.SpellCorrections[strings.ToLower(word)]
+	return correction, ok
+}
+
+// AddSpellCorrection makes batch summaries replace word (matched
+// case-insensitively) with correction, and saves the config.
+func (c *Config) AddSpellCorrection(word, correction string) error {
+	word, err := correctionWord(word)
+	if err != nil {
+		return err
+	}
+	correction = strings.TrimSpace(correction)
+	if correction == "" {
+		return fmt.Errorf("correction for %q must not be empty", word)
+	}
+	return c.setSpellCorrection(word, correction)
+}
+
+// RemoveSpellCorrection stops correcting word, built-in or custom, and saves
+// the config.
+func (c *Config) RemoveSpellCorrection(word string) error {
+	word, err := correctionWord(word)
+	if err != nil {
+		return err
+	}
+	if _, ok := c.Corrections()[word]; !ok {
+		return fmt.Errorf("no spelling correction for %q", word)
+	}
+	return c.setSpellCorrection(word, "")
+}
+
+func (c *Config) setSpellCorrection(word, correction string) error {
+	viper.Set("spell_corrections."+word, correction)
+	if c.SpellCorrections == nil {
+		c.SpellCorrections = map[string]string{}
+	}
+	c.SpellCorrections[word] = correction
+	return c.Save()
+}
+
+// correctionWord normalizes a dictionary key. Summaries are corrected word by
+// word, so keys are single lowercase words; dots would split the viper key.
+func correctionWord(word string) (string, error) {
+	word = strings.ToLower(strings.TrimSpace(word))
+	switch {
+	case word == "":
+		return "", fmt.Errorf("word must not be empty")
+	case strings.ContainsAny(word, " \t."):
+		return "", fmt.Errorf("word %q must be a single word without dots", word)
+	}
+	return word, nil
+}
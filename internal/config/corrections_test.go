@@ -0,0 +1,58 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func loadCorrectionsConfig(t *testing.T) *Config {
+	t.Helper()
+	viper.Reset()
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	return cfg
+}
+
+func TestSpellCorrectionsAddRemovePersist(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, testConfigDir))
+	t.Cleanup(viper.Reset)
+
+	cfg := loadCorrectionsConfig(t)
+	if got := cfg.Corrections()["meetng"]; got != "meeting" {
+		t.Fatalf("expected built-in correction for meetng, got %q", got)
+	}
+	if err := cfg.AddSpellCorrection("Physio", "physiotherapy"); err != nil {
+		t.Fatalf("AddSpellCorrection() failed: %v", err)
+	}
+	if err := cfg.RemoveSpellCorrection("diner"); err != nil {
+		t.Fatalf("RemoveSpellCorrection() failed: %v", err)
+	}
+
+	cfg = loadCorrectionsConfig(t)
+	active := cfg.Corrections()
+	if active["physio"] != "physiotherapy" {
+		t.Errorf("expected custom correction to persist, got %v", active["physio"])
+	}
+	if _, ok := active["diner"]; ok {
+		t.Error("expected removed built-in correction to stay removed after reload")
+	}
+	if active["meetng"] != "meeting" {
+		t.Error("expected other built-in corrections to remain")
+	}
+
+	if err := cfg.RemoveSpellCorrection("diner"); err == nil {
+		t.Error("expected an error removing a word that is not corrected")
+	}
+	if err := cfg.AddSpellCorrection("two words", "x"); err == nil {
+		t.Error("expected an error for a multi-word key")
+	}
+	if err := cfg.AddSpellCorrection("teh", " "); err == nil {
+		t.Error("expected an error for an empty correction")
+	}
+}
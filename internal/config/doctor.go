@@ -0,0 +1,318 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity of a doctor finding.
+const (
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+// Finding is one problem reported by Doctor.
+type Finding struct {
+	Severity string
+	Key      string
+	Message  string
+}
+
+// valueShape describes what a known config key must hold.
+type valueShape int
+
+const (
+	shapeScalar       valueShape = iota // string or number
+	shapeStringMap                      // key: scalar
+	shapeStringLists                    // key: [scalar, ...]
+	shapeIntegerValue                   // integer
+	shapeBoolMap                        // key: true|false
+	shapeStringList                     // [scalar, ...]
+)
+
+func (s valueShape) String() string {
+	switch s {
+	case shapeStringMap:
+		return "a mapping of text values"
+	case shapeStringLists:
+		return "a mapping of lists"
+	case shapeIntegerValue:
+		return "an integer"
+	case shapeBoolMap:
+		return "a mapping of true/false values"
+	case shapeStringList:
+		return "a list of text values"
+	default:
+		return "a single value"
+	}
+}
+
+// knownKeys lists every top-level key understood by this release.
+var knownKeys = map[string]valueShape{
+	"config_version":       shapeIntegerValue,
+	"language":             shapeScalar,
+	"timezone":             shapeScalar,
+	"date_format":          shapeScalar,
+	"time_format":          shapeScalar,
+	"output_dir":           shapeScalar,
+	"default_title":        shapeScalar,
+	"alarm_profiles":       shapeStringLists,
+	"alarm_direction":      shapeScalar,
+	"uid_domain":           shapeScalar,
+	"uid_template":         shapeScalar,
+	"spell_corrections":    shapeStringMap,
+	"category_durations":   shapeStringMap,
+	"category_taxonomy":    shapeStringList,
+	"category_colors":      shapeStringMap,
+	"duration_keywords":    shapeStringMap,
+	"duration_time_of_day": shapeStringMap,
+	"default_duration":     shapeScalar,
+	"prep_buffers":         shapeStringMap,
+	"transition_buffers":   shapeStringMap,
+	"transparent_buffers":  shapeScalar,
+	"travel_times":         shapeStringMap,
+	"default_travel_time":  shapeScalar,
+	"working_hours":        shapeStringMap,
+	"quiet_hours":          shapeStringMap,
+	"energy_budget":        shapeIntegerValue,
+	"past_warning_days":    shapeIntegerValue,
+	"holidays":             shapeStringMap,
+	"experimental":         shapeBoolMap,
+	"column_aliases":       shapeStringLists,
+	"output":               shapeStringMap,
+}
+
+// outputKeys lists the keys of the output section.
+var outputKeys = []string{"dir", "file_mode", "dir_mode", "overwrite", "name_max_length"}
+
+// ConfigFilePath returns the config file Load would read, or "" if none exists.
+func ConfigFilePath() (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	for _, candidate := range []string{filepath.Join(dir, "config.yaml"), "config.yaml"} {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", nil
+}
+
+// Doctor inspects the YAML config at path and reports deprecated keys, unknown
+// keys, type mismatches and invalid values. It never modifies the file.
+func Doctor(path string) ([]Finding, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return []Finding{{Severity: SeverityError, Message: fmt.Sprintf("invalid YAML: %v", err)}}, nil
+	}
+	root := documentMapping(&doc)
+	if root == nil {
+		return nil, nil
+	}
+
+	var findings []Finding
+	if v := configVersion(root); v < CurrentConfigVersion && mappingIndex(root, "config_version") >= 0 {
+		findings = append(findings, Finding{SeverityWarning, "config_version",
+			fmt.Sprintf("config_version %d is outdated (current %d); it will be migrated automatically on next run", v, CurrentConfigVersion)})
+	} else if v > CurrentConfigVersion {
+		findings = append(findings, Finding{SeverityError, "config_version",
+			fmt.Sprintf("config_version %d is newer than this version of tempus supports (%d)", v, CurrentConfigVersion)})
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i].Value, root.Content[i+1]
+		if newKey, ok := renamedKeys[key]; ok {
+			findings = append(findings, Finding{SeverityWarning, key, fmt.Sprintf("deprecated key; use %s instead", newKey)})
+			continue
+		}
+		shape, ok := knownKeys[key]
+		if !ok {
+			findings = append(findings, Finding{SeverityWarning, key, "unknown key (ignored)" + suggestKey(key)})
+			continue
+		}
+		findings = append(findings, checkShape(key, value, shape)...)
+	}
+
+	findings = append(findings, checkValues(root)...)
+	return findings, nil
+}
+
+func checkShape(key string, value *yaml.Node, shape valueShape) []Finding {
+	if value.Tag == "!!null" {
+		return nil
+	}
+	mismatch := []Finding{{SeverityError, key, fmt.Sprintf("expected %s, found %s", shape, describeNode(value))}}
+
+	switch shape {
+	case shapeScalar:
+		if value.Kind != yaml.ScalarNode {
+			return mismatch
+		}
+	case shapeIntegerValue:
+		if value.Kind != yaml.ScalarNode || value.ShortTag() != "!!int" {
+			return mismatch
+		}
+	case shapeStringList:
+		if value.Kind != yaml.SequenceNode {
+			return mismatch
+		}
+		var findings []Finding
+		for i, item := range value.Content {
+			if item.Kind != yaml.ScalarNode {
+				findings = append(findings, Finding{SeverityError, fmt.Sprintf("%s[%d]", key, i), "expected a single value, found " + describeNode(item)})
+			}
+		}
+		return findings
+	case shapeStringMap, shapeStringLists, shapeBoolMap:
+		if value.Kind != yaml.MappingNode {
+			return mismatch
+		}
+		var findings []Finding
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			k, v := value.Content[i], value.Content[i+1]
+			entry := key + "." + k.Value
+			if k.ShortTag() == "!!timestamp" {
+				findings = append(findings, Finding{SeverityError, entry, "date keys must be quoted (e.g. \"" + k.Value + "\")"})
+			}
+			if shape == shapeStringMap && v.Kind != yaml.ScalarNode {
+				findings = append(findings, Finding{SeverityError, entry, "expected a single value, found " + describeNode(v)})
+			}
+			if shape == shapeStringLists && v.Kind != yaml.SequenceNode && v.ShortTag() != "!!null" {
+				findings = append(findings, Finding{SeverityError, entry, "expected a list, found " + describeNode(v)})
+			}
+			if shape == shapeBoolMap {
+				if v.ShortTag() != "!!bool" {
+					findings = append(findings, Finding{SeverityError, entry, "expected true or false, found " + describeNode(v)})
+				}
+				if _, ok := LookupFeature(k.Value); !ok {
+					findings = append(findings, Finding{SeverityWarning, entry, "unknown experimental feature (ignored)"})
+				}
+			}
+		}
+		return findings
+	}
+	return nil
+}
+
+// checkValues validates the values of scalar keys that have a fixed vocabulary.
+func checkValues(root *yaml.Node) []Finding {
+	var findings []Finding
+	if idx := mappingIndex(root, "timezone"); idx >= 0 && root.Content[idx+1].Kind == yaml.ScalarNode {
+		if err := ValidateTimezone(root.Content[idx+1].Value); err != nil {
+			findings = append(findings, Finding{SeverityError, "timezone", err.Error()})
+		}
+	}
+	if idx := mappingIndex(root, "language"); idx >= 0 && root.Content[idx+1].Kind == yaml.ScalarNode {
+		if err := ValidateLanguage(root.Content[idx+1].Value); err != nil {
+			findings = append(findings, Finding{SeverityError, "language", err.Error()})
+		}
+	}
+	if idx := mappingIndex(root, "alarm_direction"); idx >= 0 && root.Content[idx+1].Kind == yaml.ScalarNode {
+		if err := ValidateAlarmDirection(root.Content[idx+1].Value); err != nil {
+			findings = append(findings, Finding{SeverityError, "alarm_direction", err.Error()})
+		}
+	}
+	if idx := mappingIndex(root, "uid_domain"); idx >= 0 && root.Content[idx+1].Kind == yaml.ScalarNode {
+		if err := ValidateUIDFormat("", root.Content[idx+1].Value); err != nil {
+			findings = append(findings, Finding{SeverityError, "uid_domain", err.Error()})
+		}
+	}
+	if idx := mappingIndex(root, "uid_template"); idx >= 0 && root.Content[idx+1].Kind == yaml.ScalarNode {
+		if err := ValidateUIDFormat(root.Content[idx+1].Value, ""); err != nil {
+			findings = append(findings, Finding{SeverityError, "uid_template", err.Error()})
+		}
+	}
+	if idx := mappingIndex(root, "output"); idx >= 0 && root.Content[idx+1].Kind == yaml.MappingNode {
+		section := root.Content[idx+1]
+		for i := 0; i+1 < len(section.Content); i += 2 {
+			k, v := section.Content[i].Value, section.Content[i+1]
+			key := "output." + k
+			if !slices.Contains(outputKeys, k) {
+				findings = append(findings, Finding{SeverityWarning, key, "unknown key (ignored); output has " + strings.Join(outputKeys, ", ")})
+				continue
+			}
+			if v.Kind != yaml.ScalarNode {
+				continue // reported by checkShape
+			}
+			value := v.Value
+			if v.ShortTag() == "!!int" && strings.HasSuffix(k, "_mode") {
+				// Unquoted 0640 is octal YAML; a bare 640 is decimal.
+				if n, err := strconv.ParseInt(value, 0, 64); err == nil && !strings.HasPrefix(value, "0") {
+					findings = append(findings, Finding{SeverityError, key, fmt.Sprintf("%s is a decimal number; write %q", value, "0"+value)})
+					continue
+				} else if err == nil {
+					value = fmt.Sprintf("%o", n)
+				}
+			}
+			if err := validateOutputValue(key, value); err != nil {
+				findings = append(findings, Finding{SeverityError, key, err.Error()})
+			}
+		}
+	}
+	return findings
+}
+
+func describeNode(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	default:
+		return fmt.Sprintf("%q", n.Value)
+	}
+}
+
+// suggestKey proposes the known key closest to an unknown one (typos, wrong case).
+func suggestKey(key string) string {
+	names := make([]string, 0, len(knownKeys))
+	for name := range knownKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lower := strings.ToLower(key)
+	best, bestDist := "", 3
+	for _, name := range names {
+		if d := editDistance(lower, name); d < bestDist {
+			best, bestDist = name, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return "; did you mean " + best + "?"
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
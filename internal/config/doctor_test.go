@@ -0,0 +1,89 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDoctorReportsProblems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `config_version: 2
+lang: es
+timezon: UTC
+timezone: Mars/Olympus
+default_title: [a, b]
+alarm_direction: sideways
+uid_template: "{{uuid}}@example.com"
+alarm_profiles:
+  focus: "-10m"
+holidays:
+  2025-12-25: Christmas
+category_taxonomy:
+  - Work/ClientA
+  - Health: [Medication]
+experimental:
+  quick_nlp_languages: yes please
+  time_travel: true
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err := Doctor(path)
+	if err != nil {
+		t.Fatalf("Doctor() failed: %v", err)
+	}
+	byKey := map[string]Finding{}
+	for _, f := range findings {
+		byKey[f.Key] = f
+	}
+
+	checks := []struct {
+		key, severity, contains string
+	}{
+		{"lang", SeverityWarning, "use language"},
+		{"timezon", SeverityWarning, "did you mean timezone"},
+		{"timezone", SeverityError, "Mars/Olympus"},
+		{"default_title", SeverityError, "expected a single value"},
+		{"alarm_direction", SeverityError, "use before or after"},
+		{"uid_template", SeverityError, "unknown placeholder {{uuid}}"},
+		{"alarm_profiles.focus", SeverityError, "expected a list"},
+		{"holidays.2025-12-25", SeverityError, "quoted"},
+		{"category_taxonomy[1]", SeverityError, "expected a single value"},
+		{"experimental.quick_nlp_languages", SeverityError, "expected true or false"},
+		{"experimental.time_travel", SeverityWarning, "unknown experimental feature"},
+	}
+	for _, c := range checks {
+		f, ok := byKey[c.key]
+		if !ok {
+			t.Errorf("no finding for %s in %+v", c.key, findings)
+			continue
+		}
+		if f.Severity != c.severity || !strings.Contains(f.Message, c.contains) {
+			t.Errorf("%s: got %+v, want %s containing %q", c.key, f, c.severity, c.contains)
+		}
+	}
+}
+
+func TestDoctorCleanAndOutdatedFiles(t *testing.T) {
+	dir := t.TempDir()
+	clean := filepath.Join(dir, "clean.yaml")
+	if err := os.WriteFile(clean, []byte("config_version: 2\nlanguage: en\nworking_hours:\n  weekdays: \"09:00-17:00\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	findings, err := Doctor(clean)
+	if err != nil || len(findings) != 0 {
+		t.Errorf("expected no findings, got %+v, %v", findings, err)
+	}
+
+	old := filepath.Join(dir, "old.yaml")
+	if err := os.WriteFile(old, []byte("config_version: 1\nlanguage: en\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	findings, _ = Doctor(old)
+	if len(findings) != 1 || findings[0].Key != "config_version" || findings[0].Severity != SeverityWarning {
+		t.Errorf("expected outdated config_version warning, got %+v", findings)
+	}
+}
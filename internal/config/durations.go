@@ -0,0 +1,60 @@
+package config
+
+// builtinDurationKeywords are the keywords batch looks for in summaries of rows
+// without end or duration, in every shipped language. Keys are lowercase
+// substrings; the longest one found in a summary wins, so "medico" beats "med".
+var builtinDurationKeywords = map[string]string{
+	// English
+	"med": "5m", "meds": "5m", "medication": "5m", "medicine": "5m", "pill": "5m",
+	"breakfast": "30m",
+	"lunch":     "45m",
+	"dinner":    "1h", "supper": "1h",
+	"standup": "15m", "stand-up": "15m",
+	"break": "15m", "transition": "15m",
+	"therapy": "1h", "therapist": "1h",
+	"doctor": "30m", "dentist": "30m",
+	"focus": "2h", "deep work": "2h",
+
+	// Spanish
+	"medicación": "5m", "medicina": "5m", "pastilla": "5m",
+	"desayuno": "30m",
+	"almuerzo": "45m", "comida": "45m",
+	"cena":     "1h",
+	"descanso": "15m", "pausa": "15m",
+	"terapia": "1h", "terapeuta": "1h",
+	"médico": "30m", "medico": "30m", "dentista": "30m",
+	"trabajo profundo": "2h", "concentración": "2h",
+
+	// Portuguese
+	"medicação": "5m", "remédio": "5m", "comprimido": "5m",
+	"pequeno-almoço": "30m", "café da manhã": "30m",
+	"almoço":    "45m",
+	"jantar":    "1h",
+	"intervalo": "15m",
+	"consulta":  "30m",
+	"foco":      "2h",
+
+	// Irish
+	"cógas": "5m", "piollaí": "5m",
+	"bricfeasta": "30m",
+	"lón":        "45m",
+	"dinnéar":    "1h",
+	"teiripe":    "1h",
+	"dochtúir":   "30m", "fiaclóir": "30m",
+	"fócas": "2h",
+}
+
+// builtinDurationTimeOfDay applies when no keyword matches; a config
+// duration_time_of_day table replaces it as a whole.
+var builtinDurationTimeOfDay = map[string]string{
+	"06:00-09:00": "30m",
+	"12:00-14:00": "1h",
+	"18:00-21:00": "1h30m",
+	"21:00-06:00": "30m",
+}
+
+// SmartDurationKeywords returns the duration_keywords in effect, without the
+// ones turned off.
+func (c *Config) SmartDurationKeywords() map[string]string {
+	return activeEntries(c.DurationKeywords)
+}
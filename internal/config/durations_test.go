@@ -0,0 +1,42 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestDurationKeywordsMergeWithBuiltins(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", tmpDir)
+	t.Cleanup(viper.Reset)
+	if err := os.MkdirAll(filepath.Join(tmpDir, "tempus"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	configContent := "duration_keywords:\n  Fisio: 45m\n  lunch: \"\"\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "tempus", "config.yaml"), []byte(configContent), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	viper.Reset()
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	keywords := cfg.SmartDurationKeywords()
+	if keywords["fisio"] != "45m" {
+		t.Errorf("expected custom keyword, got %q", keywords["fisio"])
+	}
+	if _, ok := keywords["lunch"]; ok {
+		t.Error("expected an empty duration to turn the built-in keyword off")
+	}
+	if keywords["terapia"] != "1h" {
+		t.Errorf("expected built-in keywords to remain, got %q", keywords["terapia"])
+	}
+	if cfg.DefaultDuration != "1h" || cfg.DurationTimeOfDay["12:00-14:00"] != "1h" {
+		t.Errorf("expected built-in time-of-day defaults, got %q %v", cfg.DefaultDuration, cfg.DurationTimeOfDay)
+	}
+}
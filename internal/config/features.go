@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Channel is the release channel a build belongs to.
+type Channel string
+
+// Release channels, from most to least stable.
+const (
+	ChannelStable  Channel = "stable"
+	ChannelBeta    Channel = "beta"
+	ChannelNightly Channel = "nightly"
+)
+
+func (c Channel) rank() int {
+	switch c {
+	case ChannelBeta:
+		return 1
+	case ChannelNightly:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// ChannelForVersion derives the release channel from a build version:
+// "dev" and snapshot/nightly builds are nightly, -rc/-beta/-alpha tags are beta,
+// anything else is stable.
+func ChannelForVersion(version string) Channel {
+	v := strings.ToLower(strings.TrimSpace(version))
+	switch {
+	case v == "" || v == "dev" || strings.Contains(v, "dev") || strings.Contains(v, "snapshot") || strings.Contains(v, "nightly"):
+		return ChannelNightly
+	case strings.Contains(v, "-rc") || strings.Contains(v, "-beta") || strings.Contains(v, "-alpha"):
+		return ChannelBeta
+	}
+	return ChannelStable
+}
+
+var releaseChannel = ChannelStable
+
+// SetReleaseChannel records the channel of the running build (set once from main).
+func SetReleaseChannel(c Channel) {
+	releaseChannel = c
+}
+
+// ReleaseChannel returns the channel of the running build.
+func ReleaseChannel() Channel {
+	return releaseChannel
+}
+
+// Feature is an experimental subsystem that ships behind a flag.
+type Feature struct {
+	Name        string
+	Description string
+	// DefaultOn is the most stable channel on which the feature is enabled
+	// without opting in ("" = opt-in everywhere). A nightly feature is on in
+	// nightly builds only; a beta feature is on in beta and nightly builds.
+	DefaultOn Channel
+}
+
+// Experimental feature names.
+const (
+	FeatureQuickNLPLanguages = "quick_nlp_languages"
+)
+
+// Features lists every known experimental flag.
+var Features = []Feature{
+	{
+		Name:        FeatureQuickNLPLanguages,
+		Description: "quick: understand dates in the configured language (pt) in addition to English",
+		DefaultOn:   ChannelNightly,
+	},
+}
+
+// LookupFeature returns the registered feature called name.
+func LookupFeature(name string) (Feature, bool) {
+	for _, f := range Features {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Feature{}, false
+}
+
+// enabledByDefault reports whether f is on for the running build without configuration.
+func (f Feature) enabledByDefault() bool {
+	return f.DefaultOn != "" && releaseChannel.rank() >= f.DefaultOn.rank()
+}
+
+// FeatureEnabled reports whether an experimental feature is on: an explicit
+// `experimental:` entry in the config wins, otherwise the channel default applies.
+func (c *Config) FeatureEnabled(name string) bool {
+	f, ok := LookupFeature(name)
+	if !ok {
+		return false
+	}
+	if on, set := c.Experimental[name]; set {
+		return on
+	}
+	return f.enabledByDefault()
+}
+
+// FeatureState describes one flag for `tempus config features`.
+type FeatureState struct {
+	Feature
+	Enabled bool
+	Source  string // "config" or "default (<channel>)"
+}
+
+// FeatureStates reports every known flag with its effective state, sorted by name.
+func (c *Config) FeatureStates() []FeatureState {
+	states := make([]FeatureState, 0, len(Features))
+	for _, f := range Features {
+		st := FeatureState{Feature: f, Enabled: c.FeatureEnabled(f.Name), Source: fmt.Sprintf("default (%s)", releaseChannel)}
+		if _, set := c.Experimental[f.Name]; set {
+			st.Source = "config"
+		}
+		states = append(states, st)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states
+}
+
+// UnknownFeatures returns configured experimental flags this build does not know.
+func (c *Config) UnknownFeatures() []string {
+	var unknown []string
+	for name := range c.Experimental {
+		if _, ok := LookupFeature(name); !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestChannelForVersion(t *testing.T) {
+	tests := map[string]Channel{
+		"dev":                ChannelNightly,
+		"":                   ChannelNightly,
+		"v1.4.0-SNAPSHOT-ab": ChannelNightly,
+		"v1.4.0-rc.1":        ChannelBeta,
+		"v1.4.0-beta2":       ChannelBeta,
+		"v1.4.0":             ChannelStable,
+		"1.3.2":              ChannelStable,
+	}
+	for version, want := range tests {
+		if got := ChannelForVersion(version); got != want {
+			t.Errorf("ChannelForVersion(%q) = %s, want %s", version, got, want)
+		}
+	}
+}
+
+func TestFeatureEnabledHonoursChannelAndConfig(t *testing.T) {
+	prev := ReleaseChannel()
+	t.Cleanup(func() { SetReleaseChannel(prev) })
+
+	cfg := &Config{Experimental: map[string]bool{}}
+
+	SetReleaseChannel(ChannelStable)
+	if cfg.FeatureEnabled(FeatureQuickNLPLanguages) {
+		t.Error("nightly feature should be off on stable by default")
+	}
+	SetReleaseChannel(ChannelNightly)
+	if !cfg.FeatureEnabled(FeatureQuickNLPLanguages) {
+		t.Error("nightly feature should be on in nightly builds")
+	}
+
+	cfg.Experimental[FeatureQuickNLPLanguages] = false
+	if cfg.FeatureEnabled(FeatureQuickNLPLanguages) {
+		t.Error("config should be able to turn a feature off")
+	}
+	SetReleaseChannel(ChannelStable)
+	cfg.Experimental[FeatureQuickNLPLanguages] = true
+	if !cfg.FeatureEnabled(FeatureQuickNLPLanguages) {
+		t.Error("config should be able to opt in on stable")
+	}
+
+	states := cfg.FeatureStates()
+	if len(states) != len(Features) || states[0].Source != "config" || !states[0].Enabled {
+		t.Errorf("unexpected feature states: %+v", states)
+	}
+
+	cfg.Experimental["caldav_push"] = true
+	if cfg.FeatureEnabled("caldav_push") {
+		t.Error("unknown features must never be enabled")
+	}
+	if got := cfg.UnknownFeatures(); len(got) != 1 || got[0] != "caldav_push" {
+		t.Errorf("UnknownFeatures() = %v", got)
+	}
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentConfigVersion is written as config_version by this release.
+// Files without config_version are version 1.
+const CurrentConfigVersion = 2
+
+// renamedKeys lists top-level keys renamed before config_version existed
+// (old name → current name). They are rewritten by the v1 → v2 migration and
+// reported as deprecated by Doctor.
+var renamedKeys = map[string]string{
+	"lang":             "language",
+	"default_timezone": "timezone",
+	"output_directory": "output_dir",
+	"alarm_presets":    "alarm_profiles",
+}
+
+// configMigrations[v] upgrades a parsed config document from version v to v+1.
+var configMigrations = map[int]func(doc *yaml.Node) []string{
+	1: migrateConfigV1ToV2,
+}
+
+// MigrationReport describes an automatic config upgrade.
+type MigrationReport struct {
+	Path        string
+	BackupPath  string
+	FromVersion int
+	ToVersion   int
+	Notes       []string
+}
+
+// MigrateFile upgrades the YAML config at path to CurrentConfigVersion, keeping a
+// backup of the original next to it (config.yaml.v<old>.bak). Comments and the
+// formatting of untouched values are preserved. It returns nil when no migration
+// had anything to change; such files are left alone.
+func MigrateFile(path string) (*MigrationReport, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	root := documentMapping(&doc)
+	if root == nil {
+		return nil, nil // empty file: nothing to migrate
+	}
+
+	from := configVersion(root)
+	if from >= CurrentConfigVersion {
+		return nil, nil
+	}
+
+	report := &MigrationReport{Path: path, FromVersion: from, ToVersion: CurrentConfigVersion}
+	for v := from; v < CurrentConfigVersion; v++ {
+		migrate, ok := configMigrations[v]
+		if !ok {
+			return nil, fmt.Errorf("no config migration from version %d", v)
+		}
+		report.Notes = append(report.Notes, migrate(root)...)
+	}
+	if len(report.Notes) == 0 {
+		return nil, nil // nothing to rewrite; avoid touching files that only lack config_version
+	}
+	setMappingValue(root, "config_version", strconv.Itoa(CurrentConfigVersion))
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	_ = enc.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	report.BackupPath = fmt.Sprintf("%s.v%d.bak", path, from)
+	if err := os.WriteFile(report.BackupPath, data, info.Mode().Perm()); err != nil {
+		return nil, fmt.Errorf("failed to back up config: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), info.Mode().Perm()); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func migrateConfigV1ToV2(root *yaml.Node) []string {
+	oldKeys := make([]string, 0, len(renamedKeys))
+	for oldKey := range renamedKeys {
+		oldKeys = append(oldKeys, oldKey)
+	}
+	sort.Strings(oldKeys)
+
+	var notes []string
+	for _, oldKey := range oldKeys {
+		newKey := renamedKeys[oldKey]
+		idx := mappingIndex(root, oldKey)
+		if idx < 0 {
+			continue
+		}
+		if mappingIndex(root, newKey) >= 0 {
+			root.Content = append(root.Content[:idx], root.Content[idx+2:]...)
+			notes = append(notes, fmt.Sprintf("removed %s (superseded by existing %s)", oldKey, newKey))
+			continue
+		}
+		root.Content[idx].Value = newKey
+		notes = append(notes, fmt.Sprintf("renamed %s → %s", oldKey, newKey))
+	}
+	return notes
+}
+
+// documentMapping returns the top-level mapping of a parsed YAML document.
+func documentMapping(doc *yaml.Node) *yaml.Node {
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	if doc.Kind != yaml.MappingNode {
+		return nil
+	}
+	return doc
+}
+
+func configVersion(root *yaml.Node) int {
+	idx := mappingIndex(root, "config_version")
+	if idx < 0 {
+		return 1
+	}
+	v, err := strconv.Atoi(root.Content[idx+1].Value)
+	if err != nil || v < 1 {
+		return 1
+	}
+	return v
+}
+
+// mappingIndex returns the index of key's key node in a mapping, or -1.
+func mappingIndex(m *yaml.Node, key string) int {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func setMappingValue(m *yaml.Node, key, value string) {
+	if idx := mappingIndex(m, key); idx >= 0 {
+		m.Content[idx+1] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: value}
+		return
+	}
+	m.Content = append([]*yaml.Node{
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		{Kind: yaml.ScalarNode, Tag: "!!int", Value: value},
+	}, m.Content...)
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestMigrateFileRenamesKeysAndKeepsBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	original := "# my settings\nlang: es\ndefault_timezone: Europe/Madrid\nholidays:\n  \"2025-12-25\": Navidad\n"
+	if err := os.WriteFile(path, []byte(original), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := MigrateFile(path)
+	if err != nil {
+		t.Fatalf("MigrateFile() failed: %v", err)
+	}
+	if report == nil || report.FromVersion != 1 || report.ToVersion != CurrentConfigVersion {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if len(report.Notes) != 2 {
+		t.Errorf("expected 2 notes, got %v", report.Notes)
+	}
+
+	backup, err := os.ReadFile(report.BackupPath)
+	if err != nil {
+		t.Fatalf("backup missing: %v", err)
+	}
+	if string(backup) != original {
+		t.Errorf("backup differs from original:\n%s", backup)
+	}
+
+	migrated, _ := os.ReadFile(path)
+	got := string(migrated)
+	for _, want := range []string{"config_version: 2", "language: es", "timezone: Europe/Madrid", "# my settings", "\"2025-12-25\": Navidad"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("migrated file missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "lang:") || strings.Contains(got, "default_timezone") {
+		t.Errorf("old keys left behind:\n%s", got)
+	}
+
+	again, err := MigrateFile(path)
+	if err != nil || again != nil {
+		t.Errorf("second migration should be a no-op, got %+v, %v", again, err)
+	}
+}
+
+func TestMigrateFileDropsOldKeyWhenNewKeyExists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("lang: es\nlanguage: en\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MigrateFile(path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "lang:") || !strings.Contains(string(data), "language: en") {
+		t.Errorf("unexpected migrated content:\n%s", data)
+	}
+}
+
+func TestLoadMigratesOldConfigFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, testConfigDir, "tempus")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, testConfigDir))
+	viper.Reset()
+
+	configFile := filepath.Join(configDir, "config.yaml")
+	if err := os.WriteFile(configFile, []byte("output_directory: /tmp/out\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.OutputDir != "/tmp/out" {
+		t.Errorf("expected migrated output_dir, got %q", cfg.OutputDir)
+	}
+	if cfg.ConfigVersion != CurrentConfigVersion {
+		t.Errorf("expected config_version %d, got %d", CurrentConfigVersion, cfg.ConfigVersion)
+	}
+	if _, err := os.Stat(configFile + ".v1.bak"); err != nil {
+		t.Errorf("expected backup file: %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// Overwrite policies for output.overwrite.
+const (
+	OverwriteReplace = "overwrite" // replace an existing file (the default)
+	OverwriteUnique  = "unique"    // write name-2.ics, name-3.ics, ... instead
+	OverwriteFail    = "fail"      // refuse to touch an existing file
+)
+
+// Mode is a permission mode written in octal, e.g. "0640".
+type Mode string
+
+// Perm parses m as an octal permission mode.
+func (m Mode) Perm() (os.FileMode, error) {
+	s := strings.TrimPrefix(strings.TrimSpace(string(m)), "0o")
+	v, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || v > 0o777 {
+		return 0, fmt.Errorf("invalid mode %q (use octal such as 0640)", string(m))
+	}
+	return os.FileMode(v), nil
+}
+
+// OutputConfig is the output section: where create, quick, batch and
+// template create put files and how they treat existing ones.
+type OutputConfig struct {
+	Dir       string `mapstructure:"dir" json:"dir"`             // directory for relative output paths
+	FileMode  Mode   `mapstructure:"file_mode" json:"file_mode"` // permissions of written files
+	DirMode   Mode   `mapstructure:"dir_mode" json:"dir_mode"`   // permissions of created directories
+	Overwrite string `mapstructure:"overwrite" json:"overwrite"` // unique, overwrite or fail
+	// NameMaxLength caps the part of default file names taken from a summary
+	// or person; 0 keeps the built-in 60.
+	NameMaxLength int `mapstructure:"name_max_length" json:"name_max_length"`
+}
+
+var defaultOutput = OutputConfig{FileMode: "0600", DirMode: "0750", Overwrite: OverwriteReplace}
+
+// OutputPolicy is the output section resolved for writing files.
+type OutputPolicy struct {
+	Dir       string
+	FileMode  os.FileMode
+	DirMode   os.FileMode
+	Overwrite string
+}
+
+// DefaultOutputPolicy is the policy without a config file.
+func DefaultOutputPolicy() OutputPolicy {
+	return OutputPolicy{FileMode: 0o600, DirMode: 0o750, Overwrite: OverwriteReplace}
+}
+
+// OutputPolicy resolves the output section. output.dir falls back to the
+// older output_dir key; "." means the current directory either way.
+func (c *Config) OutputPolicy() (OutputPolicy, error) {
+	p := DefaultOutputPolicy()
+	p.Dir = strings.TrimSpace(c.Output.Dir)
+	if p.Dir == "" {
+		p.Dir = strings.TrimSpace(c.OutputDir)
+	}
+	if p.Dir == "." {
+		p.Dir = ""
+	}
+	if p.Dir == "~" || strings.HasPrefix(p.Dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return p, fmt.Errorf("output.dir: %w", err)
+		}
+		p.Dir = filepath.Join(home, strings.TrimPrefix(p.Dir, "~"))
+	}
+	var err error
+	if strings.TrimSpace(string(c.Output.FileMode)) != "" {
+		if p.FileMode, err = c.Output.FileMode.Perm(); err != nil {
+			return p, fmt.Errorf("output.file_mode: %w", err)
+		}
+	}
+	if strings.TrimSpace(string(c.Output.DirMode)) != "" {
+		if p.DirMode, err = c.Output.DirMode.Perm(); err != nil {
+			return p, fmt.Errorf("output.dir_mode: %w", err)
+		}
+	}
+	if c.Output.Overwrite != "" {
+		if p.Overwrite, err = parseOverwrite(c.Output.Overwrite); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+func parseOverwrite(value string) (string, error) {
+	switch v := strings.ToLower(strings.TrimSpace(value)); v {
+	case OverwriteReplace, OverwriteUnique, OverwriteFail:
+		return v, nil
+	default:
+		return "", fmt.Errorf("output.overwrite must be unique, overwrite or fail, not %q", value)
+	}
+}
+
+// validateOutputValue checks a value for one of the output.* keys.
+func validateOutputValue(key, value string) error {
+	switch key {
+	case "output.file_mode", "output.dir_mode":
+		if _, err := Mode(value).Perm(); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	case "output.overwrite":
+		_, err := parseOverwrite(value)
+		return err
+	case "output.name_max_length":
+		_, err := parseNameMaxLength(value)
+		return err
+	}
+	return nil
+}
+
+// parseNameMaxLength reads output.name_max_length: 0 for the default, or
+// 10 to 200 characters.
+func parseNameMaxLength(value string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n != 0 && (n < 10 || n > 200) {
+		return 0, fmt.Errorf("output.name_max_length must be 0 (default) or 10 to 200, not %q", value)
+	}
+	return n, nil
+}
+
+// modeDecodeHook turns unquoted YAML numbers into Modes. YAML already reads
+// 0640 as octal, so the number is the mode itself.
+func modeDecodeHook(from, to reflect.Type, data any) (any, error) {
+	if to != reflect.TypeOf(Mode("")) {
+		return data, nil
+	}
+	switch from.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Mode(fmt.Sprintf("%04o", reflect.ValueOf(data).Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Mode(fmt.Sprintf("%04o", reflect.ValueOf(data).Uint())), nil
+	}
+	return data, nil
+}
+
+// decodeHook is viper's default decode hook plus modeDecodeHook.
+var decodeHook = mapstructure.ComposeDecodeHookFunc(
+	modeDecodeHook,
+	mapstructure.StringToTimeDurationHookFunc(),
+	mapstructure.StringToSliceHookFunc(","),
+)
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestOutputPolicyFromFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, testConfigDir, "tempus")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, testConfigDir))
+
+	load := func(content string) (OutputPolicy, error) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		viper.Reset()
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		return cfg.OutputPolicy()
+	}
+
+	p, err := load("language: en\n")
+	if err != nil || p != DefaultOutputPolicy() {
+		t.Errorf("defaults = %+v, %v", p, err)
+	}
+
+	p, err = load("output_dir: /srv/cal\n")
+	if err != nil || p.Dir != "/srv/cal" {
+		t.Errorf("output_dir fallback = %+v, %v", p, err)
+	}
+
+	p, err = load("output:\n  dir: ~/Calendars\n")
+	if err != nil || p.Dir != filepath.Join(tmpDir, "Calendars") {
+		t.Errorf("~ in output.dir = %+v, %v", p, err)
+	}
+
+	p, err = load(`output_dir: /old
+output:
+  dir: /srv/cal
+  file_mode: 0644
+  dir_mode: "0755"
+  overwrite: Unique
+`)
+	want := OutputPolicy{Dir: "/srv/cal", FileMode: 0o644, DirMode: 0o755, Overwrite: OverwriteUnique}
+	if err != nil || p != want {
+		t.Errorf("output section = %+v, %v; want %+v", p, err, want)
+	}
+
+	if _, err := load("output:\n  overwrite: sometimes\n"); err == nil || !strings.Contains(err.Error(), "unique, overwrite or fail") {
+		t.Errorf("expected an overwrite error, got %v", err)
+	}
+	if _, err := load("output:\n  file_mode: 644\n"); err == nil || !strings.Contains(err.Error(), "output.file_mode") {
+		t.Errorf("expected a file_mode error for decimal 644, got %v", err)
+	}
+}
+
+func TestSetValidatesOutputKeys(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, testConfigDir))
+	viper.Reset()
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.Set("output.file_mode", "0640"); err != nil {
+		t.Fatalf("Set(output.file_mode) failed: %v", err)
+	}
+	if got, _ := cfg.Get("output.file_mode"); got != "0640" {
+		t.Errorf("output.file_mode = %q", got)
+	}
+	if err := cfg.Set("output.dir_mode", "rwx"); err == nil {
+		t.Error("expected an error for a non-octal dir_mode")
+	}
+	if err := cfg.Set("output.overwrite", "never"); err == nil {
+		t.Error("expected an error for an unknown overwrite policy")
+	}
+
+	viper.Reset()
+	reloaded, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, err := reloaded.OutputPolicy(); err != nil || p.FileMode != 0o640 {
+		t.Errorf("saved policy = %+v, %v", p, err)
+	}
+}
+
+func TestDoctorChecksOutputSection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `output:
+  dir: ~/Calendars
+  file_mode: 644
+  dir_mode: 0750
+  overwrite: always
+  colour: blue
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	findings, err := Doctor(path)
+	if err != nil {
+		t.Fatalf("Doctor() failed: %v", err)
+	}
+	byKey := map[string]Finding{}
+	for _, f := range findings {
+		byKey[f.Key] = f
+	}
+	for key, contains := range map[string]string{
+		"output.file_mode": `write "0644"`,
+		"output.overwrite": "unique, overwrite or fail",
+		"output.colour":    "unknown key",
+	} {
+		if f, ok := byKey[key]; !ok || !strings.Contains(f.Message, contains) {
+			t.Errorf("%s: got %+v, want a finding containing %q", key, f, contains)
+		}
+	}
+	if f, ok := byKey["output.dir_mode"]; ok {
+		t.Errorf("unexpected finding for octal dir_mode: %+v", f)
+	}
+}
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WorkspaceManifest names the file that marks a zip as a tempus workspace.
+const WorkspaceManifest = "tempus-workspace.json"
+
+// workspaceFormat is bumped when the archive layout changes incompatibly.
+const workspaceFormat = 1
+
+// maxWorkspaceFile caps each file read from an archive, so a hostile zip
+// cannot expand into gigabytes.
+const maxWorkspaceFile = 16 << 20
+
+// workspaceRoots are the parts of the config directory that make up a
+// workspace: config.yaml (settings, alarm_profiles and spell_corrections)
+// plus the custom templates and locales directories. Machine state such as
+// the undo journal and a downloaded tzdata is left out.
+var workspaceRoots = []string{"config.yaml", "templates", "locales"}
+
+type workspaceManifest struct {
+	Format int      `json:"format"`
+	Files  []string `json:"files"`
+}
+
+// WorkspaceImportOptions controls ImportWorkspace.
+type WorkspaceImportOptions struct {
+	Overwrite bool // replace files that exist with different content
+	DryRun    bool // report what would change without writing
+}
+
+// WorkspaceImport reports what ImportWorkspace did (or would do).
+type WorkspaceImport struct {
+	Written   []string // new or replaced files, slash-separated and relative to the config dir
+	Unchanged []string // files already identical on disk
+	Conflicts []string // files that differ on disk; only written with Overwrite
+}
+
+// ExportWorkspace writes the workspace found in configDir to w as a zip
+// archive and returns the files it contains. Missing parts are skipped; an
+// empty workspace is an error.
+func ExportWorkspace(configDir string, w io.Writer) ([]string, error) {
+	var files []string
+	for _, root := range workspaceRoots {
+		err := filepath.WalkDir(filepath.Join(configDir, root), func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(configDir, p)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(rel))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("nothing to export: no config.yaml, templates or locales in %s", configDir)
+	}
+	sort.Strings(files)
+
+	zw := zip.NewWriter(w)
+	manifest, err := json.MarshalIndent(workspaceManifest{Format: workspaceFormat, Files: files}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeZipFile(zw, WorkspaceManifest, append(manifest, '\n')); err != nil {
+		return nil, err
+	}
+	for _, name := range files {
+		data, err := os.ReadFile(filepath.Join(configDir, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, err
+		}
+		if err := writeZipFile(zw, name, data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func writeZipFile(zw *zip.Writer, name string, data []byte) error {
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+	if err != nil {
+		return err
+	}
+	_, err = fw.Write(data)
+	return err
+}
+
+// ImportWorkspace unpacks a zip written by ExportWorkspace into configDir.
+// The whole archive is checked before anything is written: entries must sit
+// under config.yaml, templates/ or locales/, and config.yaml must be YAML.
+// Files that already exist with other content are conflicts and make the
+// import fail unless opts.Overwrite is set, so a team setup never silently
+// replaces someone's own config.
+func ImportWorkspace(configDir string, data []byte, opts WorkspaceImportOptions) (WorkspaceImport, error) {
+	var report WorkspaceImport
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return report, fmt.Errorf("not a workspace archive: %w", err)
+	}
+
+	files := make(map[string][]byte, len(zr.File))
+	var names []string
+	sawManifest := false
+	for _, f := range zr.File {
+		if f.Name == WorkspaceManifest {
+			content, err := readZipFile(f)
+			if err != nil {
+				return report, err
+			}
+			var manifest workspaceManifest
+			if err := json.Unmarshal(content, &manifest); err != nil {
+				return report, fmt.Errorf("%s: %w", WorkspaceManifest, err)
+			}
+			if manifest.Format > workspaceFormat {
+				return report, fmt.Errorf("workspace format %d is newer than this tempus understands (%d); upgrade tempus", manifest.Format, workspaceFormat)
+			}
+			sawManifest = true
+			continue
+		}
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if err := checkWorkspacePath(f.Name); err != nil {
+			return report, err
+		}
+		if !f.Mode().IsRegular() {
+			return report, fmt.Errorf("%s: only regular files can be imported", f.Name)
+		}
+		content, err := readZipFile(f)
+		if err != nil {
+			return report, err
+		}
+		if f.Name == "config.yaml" {
+			var doc map[string]any
+			if err := yaml.Unmarshal(content, &doc); err != nil {
+				return report, fmt.Errorf("config.yaml: %w", err)
+			}
+		}
+		files[f.Name] = content
+		names = append(names, f.Name)
+	}
+	if !sawManifest {
+		return report, fmt.Errorf("not a workspace archive: %s is missing", WorkspaceManifest)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		existing, err := os.ReadFile(filepath.Join(configDir, filepath.FromSlash(name)))
+		switch {
+		case err == nil && bytes.Equal(existing, files[name]):
+			report.Unchanged = append(report.Unchanged, name)
+			continue
+		case err == nil:
+			report.Conflicts = append(report.Conflicts, name)
+			if !opts.Overwrite {
+				continue
+			}
+		case !errors.Is(err, fs.ErrNotExist):
+			return report, err
+		}
+		report.Written = append(report.Written, name)
+	}
+	if len(report.Conflicts) > 0 && !opts.Overwrite {
+		return report, fmt.Errorf("%d file(s) already exist with different content: %s (use --force to replace them)",
+			len(report.Conflicts), strings.Join(report.Conflicts, ", "))
+	}
+	if opts.DryRun {
+		return report, nil
+	}
+
+	defer forget()
+	for _, name := range report.Written {
+		dest := filepath.Join(configDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(dest), 0o750); err != nil {
+			return report, err
+		}
+		if err := os.WriteFile(dest, files[name], 0o644); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// checkWorkspacePath rejects archive entries outside the workspace roots,
+// including absolute paths and ".." tricks.
+func checkWorkspacePath(name string) error {
+	clean := path.Clean(name)
+	if clean != name || path.IsAbs(name) || strings.Contains(name, `\`) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return fmt.Errorf("%s: unsafe path in workspace archive", name)
+	}
+	if clean == "config.yaml" || strings.HasPrefix(clean, "templates/") || strings.HasPrefix(clean, "locales/") {
+		return nil
+	}
+	return fmt.Errorf("%s: not part of a workspace (expected config.yaml, templates/ or locales/)", name)
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	if f.UncompressedSize64 > maxWorkspaceFile {
+		return nil, fmt.Errorf("%s: file too large (%d bytes)", f.Name, f.UncompressedSize64)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.Name, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxWorkspaceFile+1))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.Name, err)
+	}
+	if len(data) > maxWorkspaceFile {
+		return nil, fmt.Errorf("%s: file too large", f.Name)
+	}
+	return data, nil
+}
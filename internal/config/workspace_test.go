@@ -0,0 +1,125 @@
+package config
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeWorkspaceFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWorkspaceRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	writeWorkspaceFile(t, src, "config.yaml", "language: es\nalarm_profiles:\n  clinic: [-1h]\n")
+	writeWorkspaceFile(t, src, "templates/clinic.yaml", "name: clinic\n")
+	writeWorkspaceFile(t, src, "locales/eu.json", `{"hello": "kaixo"}`)
+	writeWorkspaceFile(t, src, "journal/0001.json", "{}")
+	writeWorkspaceFile(t, src, "tzdata/VERSION", "2025b\n")
+
+	var buf bytes.Buffer
+	files, err := ExportWorkspace(src, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(files, ","); got != "config.yaml,locales/eu.json,templates/clinic.yaml" {
+		t.Fatalf("exported %s; the journal and tzdata should stay behind", got)
+	}
+
+	dst := t.TempDir()
+	writeWorkspaceFile(t, dst, "locales/eu.json", `{"hello": "kaixo"}`)
+	dry, err := ImportWorkspace(dst, buf.Bytes(), WorkspaceImportOptions{DryRun: true})
+	if err != nil || len(dry.Written) != 2 || len(dry.Unchanged) != 1 {
+		t.Fatalf("dry run = %+v, %v", dry, err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "config.yaml")); !os.IsNotExist(err) {
+		t.Fatal("a dry run should not write anything")
+	}
+
+	if _, err := ImportWorkspace(dst, buf.Bytes(), WorkspaceImportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dst, "templates", "clinic.yaml"))
+	if err != nil || string(data) != "name: clinic\n" {
+		t.Errorf("template after import = %q, %v", data, err)
+	}
+}
+
+func TestImportWorkspaceKeepsLocalChangesWithoutOverwrite(t *testing.T) {
+	src := t.TempDir()
+	writeWorkspaceFile(t, src, "config.yaml", "language: es\n")
+	writeWorkspaceFile(t, src, "templates/new.yaml", "name: new\n")
+	var buf bytes.Buffer
+	if _, err := ExportWorkspace(src, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := t.TempDir()
+	writeWorkspaceFile(t, dst, "config.yaml", "language: pt\n")
+	report, err := ImportWorkspace(dst, buf.Bytes(), WorkspaceImportOptions{})
+	if err == nil || !strings.Contains(err.Error(), "config.yaml") || len(report.Conflicts) != 1 {
+		t.Fatalf("expected a conflict on config.yaml, got %+v, %v", report, err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "templates", "new.yaml")); !os.IsNotExist(err) {
+		t.Error("a conflicting import should not write any file")
+	}
+
+	if _, err := ImportWorkspace(dst, buf.Bytes(), WorkspaceImportOptions{Overwrite: true}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dst, "config.yaml")); string(data) != "language: es\n" {
+		t.Errorf("Overwrite should replace config.yaml, got %q", data)
+	}
+}
+
+func TestImportWorkspaceRejectsBadArchives(t *testing.T) {
+	archive := func(files map[string]string) []byte {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		for name, content := range files {
+			if err := writeZipFile(zw, name, []byte(content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	manifest := `{"format": 1}`
+	for name, tc := range map[string]struct {
+		data []byte
+		want string
+	}{
+		"not a zip":   {[]byte("hello"), "not a workspace archive"},
+		"no manifest": {archive(map[string]string{"config.yaml": "a: 1\n"}), WorkspaceManifest},
+		"newer":       {archive(map[string]string{WorkspaceManifest: `{"format": 99}`}), "upgrade tempus"},
+		"zip slip":    {archive(map[string]string{WorkspaceManifest: manifest, "templates/../../evil": "x"}), "unsafe path"},
+		"absolute":    {archive(map[string]string{WorkspaceManifest: manifest, "/etc/passwd": "x"}), "unsafe path"},
+		"outside":     {archive(map[string]string{WorkspaceManifest: manifest, "journal/1.json": "{}"}), "not part of a workspace"},
+		"bad yaml":    {archive(map[string]string{WorkspaceManifest: manifest, "config.yaml": "a: [1\n"}), "config.yaml"},
+	} {
+		dst := t.TempDir()
+		if _, err := ImportWorkspace(dst, tc.data, WorkspaceImportOptions{}); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: expected an error mentioning %q, got %v", name, tc.want, err)
+		}
+		if entries, _ := os.ReadDir(dst); len(entries) != 0 {
+			t.Errorf("%s: nothing should be written, found %d entries", name, len(entries))
+		}
+	}
+
+	if _, err := ExportWorkspace(t.TempDir(), &bytes.Buffer{}); err == nil {
+		t.Error("exporting an empty config dir should fail")
+	}
+}
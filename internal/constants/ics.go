@@ -20,4 +20,9 @@ const (
 
 	// iCalendar line folding limit (RFC 5545)
 	ICalMaxLineLength = 75
+
+	// Event UIDs: {{id}} is a random UUID or a stable hash, {{domain}} the
+	// uid_domain from config
+	DefaultUIDDomain   = "tempus"
+	DefaultUIDTemplate = "{{id}}@{{domain}}"
 )
@@ -0,0 +1,284 @@
+// Package diag defines the structured warnings shared by batch, lint, locale lint and create,
+// and renders them as text, JSON or SARIF.
+package diag
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"tempus/internal/output"
+)
+
+// Severity ranks a warning. Errors fail the command; infos are notes (e.g. autocorrections).
+type Severity string
+
+// Severities, from least to most serious.
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Warning codes. Codes are stable identifiers that scripts and SARIF rules can key on.
+const (
+	CodeConflict        = "conflict"         // two timed events overlap
+	CodeOverload        = "overload"         // a day has more events than --max-events-per-day
+	CodeEnergy          = "energy"           // a day's energy total exceeds the energy budget
+	CodeHours           = "hours"            // outside working hours or alarm in quiet hours
+	CodeDayFilter       = "day-filter"       // event moved/flagged by --skip-weekends/--skip-holidays
+	CodeAutocorrect     = "autocorrect"      // input text was corrected
+	CodeInvalidRow      = "invalid-row"      // a batch row could not be turned into an event
+	CodeICSStructure    = "ics-structure"    // missing VCALENDAR/VEVENT, unbalanced BEGIN/END
+	CodeMissingProperty = "missing-property" // a VEVENT lacks a required property
+	CodeDeprecatedTZ    = "deprecated-tz"    // an obsolete zone name was rewritten to its canonical one
+	CodeUntranslated    = "untranslated"     // a locale file lacks a key (English is shown instead)
+	CodeUnknownKey      = "unknown-key"      // a locale file has a key English does not define
+	CodePlaceholders    = "placeholders"     // a translation's format verbs differ from English
+	CodeSolar           = "solar"            // the sun never reaches a solar start (sunrise+30m) on some day
+	CodeItinerary       = "itinerary"        // a booking could not be read, or an airport has no known timezone
+	CodeOvernight       = "overnight"        // a clock-only end before the start was moved to the next day
+	CodeCategory        = "category"         // a category is missing from category_taxonomy
+	CodeDST             = "dst"              // a time is skipped or repeated by a DST change
+	CodePast            = "past"             // an event ended longer ago than past_warning_days
+	CodeAlarmWindow     = "alarm-window"     // an alarm fires after its event ends, or weeks before it
+	CodeLongEvent       = "long-event"       // a timed event lasts more than 24 hours
+	CodeUntil           = "until"            // an RRULE's UNTIL is before the event's start
+	CodeBuffer          = "buffer"           // a --add-prep-time buffer was shortened or left out to avoid an overlap
+	CodeBalance         = "balance"          // --auto-balance moved (or would move) an event off an overloaded day
+	CodeTravel          = "travel"           // the gap between events at two locations is shorter than the travel time
+	CodeGrammar         = "grammar"          // a line breaks the RFC 5545 grammar (lint --strict)
+	CodeTruncated       = "truncated"        // a repeating event was expanded only up to the occurrence limit
+)
+
+// Warning is one finding reported by a command.
+type Warning struct {
+	Code       string   `json:"code"`
+	Severity   Severity `json:"severity"`
+	File       string   `json:"file,omitempty"`
+	Row        int      `json:"row,omitempty"`  // 1-based batch row (record), 0 when not tied to one
+	Line       int      `json:"line,omitempty"` // 1-based line in File, 0 when unknown
+	Message    string   `json:"message"`
+	Suggestion string   `json:"suggestion,omitempty"`
+}
+
+// Location formats the file/row the warning points at ("" when it has neither).
+func (w Warning) Location() string {
+	var parts []string
+	switch {
+	case w.File != "" && w.Line > 0:
+		parts = append(parts, fmt.Sprintf("%s:%d", w.File, w.Line))
+	case w.File != "":
+		parts = append(parts, w.File)
+	}
+	if w.Row > 0 {
+		parts = append(parts, fmt.Sprintf("row %d", w.Row))
+	}
+	return strings.Join(parts, " ")
+}
+
+// String renders the warning on one line without an icon, e.g. for error messages.
+func (w Warning) String() string {
+	s := w.Message
+	if loc := w.Location(); loc != "" {
+		s = loc + ": " + s
+	}
+	if w.Suggestion != "" {
+		s += " (" + w.Suggestion + ")"
+	}
+	return s
+}
+
+func (s Severity) icon() string {
+	switch s {
+	case SeverityError:
+		return "❌"
+	case SeverityInfo:
+		return "ℹ️ "
+	default:
+		return "⚠️ "
+	}
+}
+
+// Render writes warnings for humans, one per line with a severity icon and the
+// suggestion on a follow-up line. Info notes are left out with --quiet, and the
+// icons with --no-emoji.
+func Render(w io.Writer, warnings []Warning) {
+	for _, warn := range warnings {
+		if warn.Severity == SeverityInfo && output.Quiet() {
+			continue
+		}
+		line := warn.Message
+		if loc := warn.Location(); loc != "" {
+			line = loc + ": " + line
+		}
+		fmt.Fprintf(w, "%s%s\n", output.Prefix(warn.Severity.icon()), line)
+		if warn.Suggestion != "" {
+			fmt.Fprintf(w, "    → %s\n", warn.Suggestion)
+		}
+	}
+}
+
+// WriteJSON writes warnings as an indented JSON array ([] when there are none).
+func WriteJSON(w io.Writer, warnings []Warning) error {
+	if warnings == nil {
+		warnings = []Warning{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(warnings)
+}
+
+// HasErrors reports whether any warning has error severity.
+func HasErrors(warnings []Warning) bool {
+	for _, w := range warnings {
+		if w.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Count returns how many warnings have the given severity.
+func Count(warnings []Warning, severity Severity) int {
+	n := 0
+	for _, w := range warnings {
+		if w.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// Messages returns String() for every warning, for callers that still join text.
+func Messages(warnings []Warning) []string {
+	out := make([]string, 0, len(warnings))
+	for _, w := range warnings {
+		out = append(out, w.String())
+	}
+	return out
+}
+
+// SARIF 2.1.0, reduced to the fields code-scanning tools read.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           *sarifRegion  `json:"region,omitempty"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// WriteSARIF writes warnings as a SARIF 2.1.0 log so CI systems (e.g. GitHub code
+// scanning) can annotate the input files.
+func WriteSARIF(w io.Writer, toolVersion string, warnings []Warning) error {
+	seen := map[string]bool{}
+	var rules []sarifRule
+	results := make([]sarifResult, 0, len(warnings))
+	for _, warn := range warnings {
+		if !seen[warn.Code] {
+			seen[warn.Code] = true
+			rules = append(rules, sarifRule{ID: warn.Code})
+		}
+		text := warn.Message
+		if warn.Row > 0 {
+			text = fmt.Sprintf("row %d: %s", warn.Row, text)
+		}
+		if warn.Suggestion != "" {
+			text += " (" + warn.Suggestion + ")"
+		}
+		res := sarifResult{RuleID: warn.Code, Level: sarifLevel(warn.Severity), Message: sarifMessage{Text: text}}
+		if warn.File != "" {
+			loc := sarifLocation{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifact{URI: filepathToURI(warn.File)}}}
+			if warn.Line > 0 {
+				loc.PhysicalLocation.Region = &sarifRegion{StartLine: warn.Line}
+			}
+			res.Locations = []sarifLocation{loc}
+		}
+		results = append(results, res)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	if rules == nil {
+		rules = []sarifRule{}
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "tempus",
+				Version:        toolVersion,
+				InformationURI: "https://github.com/malpanez/tempus",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+func sarifLevel(s Severity) string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityInfo:
+		return "note"
+	default:
+		return "warning"
+	}
+}
+
+// filepathToURI turns an OS path into the relative URI form SARIF expects.
+func filepathToURI(path string) string {
+	return strings.TrimPrefix(strings.ReplaceAll(path, "\\", "/"), "./")
+}
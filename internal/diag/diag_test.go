@@ -0,0 +1,86 @@
+package diag
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWarningLocationAndString(t *testing.T) {
+	tests := []struct {
+		w    Warning
+		want string
+	}{
+		{Warning{Message: "m"}, "m"},
+		{Warning{File: "a.ics", Line: 4, Message: "m"}, "a.ics:4: m"},
+		{Warning{File: "events.csv", Row: 2, Message: "m", Suggestion: "s"}, "events.csv row 2: m (s)"},
+		{Warning{Row: 7, Message: "m"}, "row 7: m"},
+	}
+	for _, tt := range tests {
+		if got := tt.w.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestRenderUsesSeverityIconsAndSuggestions(t *testing.T) {
+	var buf bytes.Buffer
+	Render(&buf, []Warning{
+		{Code: CodeConflict, Severity: SeverityWarning, Message: "overlap", Suggestion: "move one"},
+		{Code: CodeInvalidRow, Severity: SeverityError, Row: 3, Message: "bad start"},
+		{Code: CodeAutocorrect, Severity: SeverityInfo, Message: "fixed"},
+	})
+	got := buf.String()
+	for _, want := range []string{"⚠️  overlap\n    → move one\n", "❌ row 3: bad start\n", "ℹ️  fixed\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Render() missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestWriteJSONEmptyIsArray(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("WriteJSON(nil) = %q, want []", buf.String())
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteSARIF(&buf, "1.2.3", []Warning{
+		{Code: CodeMissingProperty, Severity: SeverityError, File: "./cal.ics", Line: 5, Message: "missing DTSTART"},
+		{Code: CodeAutocorrect, Severity: SeverityInfo, File: "events.csv", Row: 2, Message: "fixed"},
+		{Code: CodeMissingProperty, Severity: SeverityError, File: "./cal.ics", Line: 9, Message: "missing UID"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected log header: %+v", log)
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Version != "1.2.3" || len(run.Tool.Driver.Rules) != 2 {
+		t.Errorf("driver = %+v, want version 1.2.3 and 2 distinct rules", run.Tool.Driver)
+	}
+	if len(run.Results) != 3 {
+		t.Fatalf("results = %d, want 3", len(run.Results))
+	}
+	first := run.Results[0]
+	if first.Level != "error" || first.Locations[0].PhysicalLocation.ArtifactLocation.URI != "cal.ics" ||
+		first.Locations[0].PhysicalLocation.Region == nil || first.Locations[0].PhysicalLocation.Region.StartLine != 5 {
+		t.Errorf("first result = %+v", first)
+	}
+	second := run.Results[1]
+	if second.Level != "note" || second.Message.Text != "row 2: fixed" || second.Locations[0].PhysicalLocation.Region != nil {
+		t.Errorf("batch rows carry no line region, got %+v", second)
+	}
+}
@@ -0,0 +1,183 @@
+// Package fsys is the filesystem tempus writes generated files through.
+// OS is the real one; Memory keeps files in a map so tests and programs that
+// embed tempus can generate calendars without touching the disk.
+package fsys
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing/fstest"
+)
+
+// FS writes and inspects files. Paths are OS paths as the command layer has
+// them (relative or absolute), not io/fs slash paths.
+type FS interface {
+	ReadFile(name string) ([]byte, error)
+	// OpenReader opens name for reading like ReadFile, for content that is
+	// streamed rather than held in memory.
+	OpenReader(name string) (io.ReadCloser, error)
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	// Create opens name for writing like WriteFile, for content that is
+	// streamed rather than held in memory. It is complete once closed.
+	Create(name string, perm fs.FileMode) (io.WriteCloser, error)
+	MkdirAll(path string, perm fs.FileMode) error
+	Stat(name string) (fs.FileInfo, error)
+	Remove(name string) error
+	Rename(oldpath, newpath string) error
+}
+
+// OS is the real filesystem.
+var OS FS = osFS{}
+
+type osFS struct{}
+
+func (osFS) ReadFile(name string) ([]byte, error)          { return os.ReadFile(name) }
+func (osFS) OpenReader(name string) (io.ReadCloser, error) { return os.Open(name) }
+func (osFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+func (osFS) Create(name string, perm fs.FileMode) (io.WriteCloser, error) {
+	return os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+}
+func (osFS) MkdirAll(path string, perm fs.FileMode) error { return os.MkdirAll(path, perm) }
+func (osFS) Stat(name string) (fs.FileInfo, error)        { return os.Stat(name) }
+func (osFS) Remove(name string) error                     { return os.Remove(name) }
+func (osFS) Rename(oldpath, newpath string) error         { return os.Rename(oldpath, newpath) }
+
+// Memory is an in-memory FS, safe for concurrent use. It is also an fs.FS,
+// so what was written can be walked with fs.WalkDir or read with fs.ReadFile
+// using slash paths ("out/week.ics").
+type Memory struct {
+	mu    sync.Mutex
+	files fstest.MapFS
+}
+
+var errNotEmpty = errors.New("directory not empty")
+
+// NewMemory returns an empty in-memory filesystem.
+func NewMemory() *Memory {
+	return &Memory{files: fstest.MapFS{}}
+}
+
+// key maps an OS path to the slash path it is stored under; absolute paths
+// lose their leading slash.
+func key(name string) string {
+	k := strings.TrimPrefix(filepath.ToSlash(filepath.Clean(name)), "/")
+	if k == "" {
+		return "."
+	}
+	return k
+}
+
+// Open implements fs.FS.
+func (m *Memory) Open(name string) (fs.File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.files.Open(name)
+}
+
+func (m *Memory) ReadFile(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return fs.ReadFile(m.files, key(name))
+}
+
+// OpenReader returns a reader over the content name holds now.
+func (m *Memory) OpenReader(name string) (io.ReadCloser, error) {
+	data, err := m.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// WriteFile stores a copy of data. Like os.WriteFile, an existing file keeps
+// its mode.
+func (m *Memory) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k := key(name)
+	if info, err := fs.Stat(m.files, k); err == nil {
+		if info.IsDir() {
+			return &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+		}
+		perm = info.Mode().Perm()
+	}
+	m.files[k] = &fstest.MapFile{Data: append([]byte(nil), data...), Mode: perm}
+	return nil
+}
+
+// Create returns a writer whose content is stored, as WriteFile would, when
+// it is closed.
+func (m *Memory) Create(name string, perm fs.FileMode) (io.WriteCloser, error) {
+	if info, err := m.Stat(name); err == nil && info.IsDir() {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	return &memFile{m: m, name: name, perm: perm}, nil
+}
+
+type memFile struct {
+	bytes.Buffer
+	m    *Memory
+	name string
+	perm fs.FileMode
+}
+
+func (f *memFile) Close() error { return f.m.WriteFile(f.name, f.Bytes(), f.perm) }
+
+func (m *Memory) MkdirAll(path string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k := key(path)
+	if info, err := fs.Stat(m.files, k); err == nil {
+		if !info.IsDir() {
+			return &fs.PathError{Op: "mkdir", Path: path, Err: fs.ErrExist}
+		}
+		return nil
+	}
+	m.files[k] = &fstest.MapFile{Mode: fs.ModeDir | perm}
+	return nil
+}
+
+func (m *Memory) Stat(name string) (fs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return fs.Stat(m.files, key(name))
+}
+
+func (m *Memory) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k := key(name)
+	f, ok := m.files[k]
+	if !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	if f.Mode.IsDir() {
+		for other := range m.files {
+			if strings.HasPrefix(other, k+"/") {
+				return &fs.PathError{Op: "remove", Path: name, Err: errNotEmpty}
+			}
+		}
+	}
+	delete(m.files, k)
+	return nil
+}
+
+func (m *Memory) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, ok := m.files[key(oldpath)]
+	if !ok {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrNotExist}
+	}
+	delete(m.files, key(oldpath))
+	m.files[key(newpath)] = f
+	return nil
+}
@@ -0,0 +1,104 @@
+package fsys
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// exercise runs the same checks against any FS rooted at dir.
+func exercise(t *testing.T, f FS, dir string) {
+	t.Helper()
+	out := filepath.Join(dir, "out", "week.ics")
+	if _, err := f.Stat(out); !os.IsNotExist(err) {
+		t.Fatalf("Stat() of a missing file = %v, want not-exist", err)
+	}
+	if err := f.MkdirAll(filepath.Dir(out), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.WriteFile(out, []byte("BEGIN:VCALENDAR"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.WriteFile(out, []byte("END:VCALENDAR"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := f.Stat(out)
+	if err != nil || info.Size() != int64(len("END:VCALENDAR")) || info.Mode().Perm() != 0o600 {
+		t.Errorf("Stat() = %v, %v; an overwrite should keep the 0600 mode", info, err)
+	}
+	moved := filepath.Join(dir, "out", "moved.ics")
+	if err := f.Rename(out, moved); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := f.ReadFile(moved); err != nil || string(data) != "END:VCALENDAR" {
+		t.Errorf("ReadFile() after Rename = %q, %v", data, err)
+	}
+	if err := f.Remove(moved); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Remove(moved); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("second Remove() = %v, want not-exist", err)
+	}
+
+	if err := f.WriteFile(out, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w, err := f.Create(out, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, "BEGIN:VCALENDAR\r\nEND:VCALENDAR"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := f.ReadFile(out); err != nil || string(data) != "BEGIN:VCALENDAR\r\nEND:VCALENDAR" {
+		t.Errorf("ReadFile() after Create = %q, %v", data, err)
+	}
+	r, err := f.OpenReader(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(r)
+	if closeErr := r.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || string(data) != "BEGIN:VCALENDAR\r\nEND:VCALENDAR" {
+		t.Errorf("OpenReader() read %q, %v", data, err)
+	}
+	if _, err := f.OpenReader(moved); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("OpenReader() of a missing file = %v, want not-exist", err)
+	}
+	if info, err := f.Stat(out); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("Stat() = %v, %v; Create over a file should keep the 0600 mode", info, err)
+	}
+	if _, err := f.Create(filepath.Dir(out), 0o644); err == nil {
+		t.Error("Create() over a directory should fail")
+	}
+}
+
+func TestOS(t *testing.T) {
+	exercise(t, OS, t.TempDir())
+}
+
+func TestMemory(t *testing.T) {
+	m := NewMemory()
+	exercise(t, m, "/tmp/cal")
+
+	if err := m.WriteFile("notes/a.ics", []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := fs.ReadFile(m, "notes/a.ics"); err != nil || string(data) != "x" {
+		t.Errorf("fs.ReadFile() = %q, %v", data, err)
+	}
+	if err := m.MkdirAll("notes/a.ics", 0o750); err == nil {
+		t.Error("MkdirAll() over a file should fail")
+	}
+	if _, err := os.Stat("notes/a.ics"); err == nil {
+		t.Error("Memory should not write to the disk")
+	}
+}
@@ -0,0 +1,140 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Catalog keys holding a locale's display formats, e.g. "DD/MM/YYYY" and
+// "HH:MM", and the day its weeks start on ("monday" or "sunday").
+const (
+	KeyFormatDate      = "format_date"
+	KeyFormatTime      = "format_time"
+	KeyFormatWeekStart = "format_week_start"
+)
+
+var weekStarts = map[string]time.Weekday{
+	"monday": time.Monday, "sunday": time.Sunday, "saturday": time.Saturday,
+}
+
+// dateTokens are the placeholders accepted by DateLayout, longest first.
+var dateTokens = []struct{ token, layout string }{
+	{"YYYY", "2006"},
+	{"YY", "06"},
+	{"MMM", "Jan"},
+	{"MM", "01"},
+	{"DD", "02"},
+}
+
+// DateLayout turns a date pattern such as "DD/MM/YYYY", "MM/DD/YYYY" or
+// "YYYY-MM-DD" into a Go time layout. Patterns that already are Go layouts
+// (they contain "2006") are returned unchanged.
+func DateLayout(pattern string) (string, error) {
+	pattern = strings.TrimSpace(pattern)
+	if strings.Contains(pattern, "2006") {
+		return pattern, nil
+	}
+	var b strings.Builder
+	tokens := 0
+	for rest := pattern; rest != ""; {
+		matched := false
+		for _, tok := range dateTokens {
+			if strings.HasPrefix(rest, tok.token) {
+				b.WriteString(tok.layout)
+				rest = rest[len(tok.token):]
+				tokens++
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		r := []rune(rest)[0]
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return "", fmt.Errorf("invalid date format %q (use e.g. DD/MM/YYYY, MM/DD/YYYY or YYYY-MM-DD)", pattern)
+		}
+		b.WriteRune(r)
+		rest = rest[len(string(r)):]
+	}
+	if tokens == 0 {
+		return "", fmt.Errorf("invalid date format %q (use e.g. DD/MM/YYYY, MM/DD/YYYY or YYYY-MM-DD)", pattern)
+	}
+	return b.String(), nil
+}
+
+// TimeLayout turns a time pattern into a Go time layout: "24h" or "HH:MM"
+// for 15:04, "HH:MM:SS" for 15:04:05, and "12h" or "hh:MM AM" for 3:04 PM.
+// Patterns that already are Go layouts (they contain "04") are returned unchanged.
+func TimeLayout(pattern string) (string, error) {
+	pattern = strings.TrimSpace(pattern)
+	switch pattern {
+	case "24h", "HH:MM":
+		return "15:04", nil
+	case "HH:MM:SS":
+		return "15:04:05", nil
+	case "12h", "hh:MM AM", "h:MM AM":
+		return "3:04 PM", nil
+	}
+	if strings.Contains(pattern, "04") {
+		return pattern, nil
+	}
+	return "", fmt.Errorf("invalid time format %q (use 24h, 12h, HH:MM or hh:MM AM)", pattern)
+}
+
+// DateLayout returns the Go layout of the locale's date format, or MM/DD/YYYY
+// when the catalog's format_date is invalid.
+func (t *Translator) DateLayout() string {
+	if layout, err := DateLayout(t.GetDateFormat()); err == nil {
+		return layout
+	}
+	return "01/02/2006"
+}
+
+// TimeLayout returns the Go layout of the locale's time format, falling back
+// to 24-hour time when the catalog's format_time is invalid.
+func (t *Translator) TimeLayout() string {
+	if layout, err := TimeLayout(t.GetTimeFormat()); err == nil {
+		return layout
+	}
+	return "15:04"
+}
+
+// WeekStart returns the first day of the locale's week, Monday (as in ISO
+// 8601) when the catalog's format_week_start is missing or invalid.
+func (t *Translator) WeekStart() time.Weekday {
+	if day, ok := weekStarts[strings.ToLower(t.format(KeyFormatWeekStart, "monday"))]; ok {
+		return day
+	}
+	return time.Monday
+}
+
+// FormatDateTime formats a datetime according to locale preferences
+func (t *Translator) FormatDateTime(dt time.Time, dateOnly bool) string {
+	if dateOnly {
+		return dt.Format(t.DateLayout())
+	}
+	return dt.Format(t.DateLayout() + " " + t.TimeLayout())
+}
+
+// GetDateFormat returns the date format string for the current locale
+func (t *Translator) GetDateFormat() string {
+	return t.format(KeyFormatDate, "MM/DD/YYYY")
+}
+
+// GetTimeFormat returns the time format string for the current locale
+func (t *Translator) GetTimeFormat() string {
+	return t.format(KeyFormatTime, "HH:MM")
+}
+
+func (t *Translator) format(key, def string) string {
+	if v := strings.TrimSpace(t.translations[key]); v != "" {
+		return v
+	}
+	if v := strings.TrimSpace(t.fallback[key]); v != "" {
+		return v
+	}
+	return def
+}
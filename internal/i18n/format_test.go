@@ -0,0 +1,115 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDateLayout(t *testing.T) {
+	tests := map[string]string{
+		"DD/MM/YYYY":  "02/01/2006",
+		"MM/DD/YYYY":  "01/02/2006",
+		"YYYY-MM-DD":  "2006-01-02",
+		"DD.MM.YY":    "02.01.06",
+		"DD MMM YYYY": "02 Jan 2006",
+		"2006-01-02":  "2006-01-02",
+		"02/01/2006":  "02/01/2006",
+	}
+	for pattern, want := range tests {
+		got, err := DateLayout(pattern)
+		if err != nil || got != want {
+			t.Errorf("DateLayout(%q) = %q, %v; want %q", pattern, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "/", "DD/MM/YYYX", "tomorrow"} {
+		if _, err := DateLayout(bad); err == nil {
+			t.Errorf("DateLayout(%q) expected an error", bad)
+		}
+	}
+}
+
+func TestTimeLayout(t *testing.T) {
+	tests := map[string]string{
+		"24h":      "15:04",
+		"HH:MM":    "15:04",
+		"HH:MM:SS": "15:04:05",
+		"12h":      "3:04 PM",
+		"hh:MM AM": "3:04 PM",
+		"15:04:05": "15:04:05",
+	}
+	for pattern, want := range tests {
+		got, err := TimeLayout(pattern)
+		if err != nil || got != want {
+			t.Errorf("TimeLayout(%q) = %q, %v; want %q", pattern, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "noon", "HH"} {
+		if _, err := TimeLayout(bad); err == nil {
+			t.Errorf("TimeLayout(%q) expected an error", bad)
+		}
+	}
+}
+
+func TestCustomLocaleFromConfigDir(t *testing.T) {
+	cfg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", cfg)
+	t.Setenv("HOME", cfg)
+	dir := filepath.Join(cfg, "tempus", "locales")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	fr := `{"config_saved": "Configuration enregistrée", "format_date": "DD/MM/YYYY", "format_time": "24h"}`
+	if err := os.WriteFile(filepath.Join(dir, "fr.json"), []byte(fr), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if !IsSupportedLanguage("fr") {
+		t.Fatal("expected a locale in the config dir to be supported")
+	}
+	tr, err := NewTranslator("fr")
+	if err != nil {
+		t.Fatalf(testErrNewTranslator, err)
+	}
+	if got := tr.T("config_saved"); got != "Configuration enregistrée" {
+		t.Errorf("T(config_saved) = %q", got)
+	}
+	if got := tr.T("invalid_date", "x"); got != "Invalid date format: x" {
+		t.Errorf("expected English for untranslated keys, got %q", got)
+	}
+	when := time.Date(2024, 3, 15, 14, 30, 0, 0, time.UTC)
+	if got := tr.FormatDateTime(when, false); got != "15/03/2024 14:30" {
+		t.Errorf("FormatDateTime() = %q", got)
+	}
+}
+
+func TestTranslatorLayoutFallsBackOnInvalidFormat(t *testing.T) {
+	tr := &Translator{
+		language:     "xx",
+		translations: map[string]string{KeyFormatDate: "soon", KeyFormatTime: "later"},
+		fallback:     map[string]string{},
+	}
+	if got := tr.DateLayout(); got != "01/02/2006" {
+		t.Errorf("DateLayout() = %q", got)
+	}
+	if got := tr.TimeLayout(); got != "15:04" {
+		t.Errorf("TimeLayout() = %q", got)
+	}
+}
+
+func TestTranslatorWeekStart(t *testing.T) {
+	for lang, want := range map[string]time.Weekday{"en": time.Sunday, "es": time.Monday, "ga": time.Monday, "pt": time.Monday} {
+		tr, err := NewTranslator(lang)
+		if err != nil {
+			t.Fatalf(testErrNewTranslator, err)
+		}
+		if got := tr.WeekStart(); got != want {
+			t.Errorf("%s: WeekStart() = %s, want %s", lang, got, want)
+		}
+	}
+	tr := &Translator{language: "xx", translations: map[string]string{KeyFormatWeekStart: "someday"}, fallback: map[string]string{}}
+	if got := tr.WeekStart(); got != time.Monday {
+		t.Errorf("invalid format_week_start: WeekStart() = %s, want Monday", got)
+	}
+}
@@ -12,7 +12,6 @@ import (
 	"sort"
 	"strings"
 	"sync"
-	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -118,6 +117,28 @@ func (t *Translator) T(key string, args ...interface{}) string {
 	return key
 }
 
+// Category returns the localized name of a canonical category (e.g. "Work" →
+// "Trabajo"), or name unchanged when the catalog has no entry for it.
+// Hierarchical categories are translated level by level, so
+// "Health/Medication" becomes "Salud/Medicación".
+func (t *Translator) Category(name string) string {
+	if strings.Contains(name, "/") {
+		levels := strings.Split(name, "/")
+		for i, level := range levels {
+			levels[i] = t.Category(level)
+		}
+		return strings.Join(levels, "/")
+	}
+	key := "category_" + strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")
+	if text, exists := t.translations[key]; exists {
+		return text
+	}
+	if text, exists := t.fallback[key]; exists {
+		return text
+	}
+	return name
+}
+
 // GetLanguage returns the current language
 func (t *Translator) GetLanguage() string {
 	return t.language
@@ -208,9 +229,11 @@ func IsSupportedLanguage(lang string) bool {
 	if _, ok := embeddedData[lang]; ok {
 		return true
 	}
-	for _, ext := range localeExtensions {
-		if _, err := os.Stat(filepath.Join("locales", lang+ext)); err == nil {
-			return true
+	for _, base := range localeSearchPaths() {
+		for _, ext := range localeExtensions {
+			if _, err := os.Stat(filepath.Join(base, lang+ext)); err == nil {
+				return true
+			}
 		}
 	}
 	return false
@@ -340,22 +363,6 @@ func cloneStringMap(in map[string]string) map[string]string {
 	return out
 }
 
-// FormatDateTime formats a datetime according to locale preferences
-func (t *Translator) FormatDateTime(dt time.Time, dateOnly bool) string {
-	switch t.language {
-	case "es", "ga", "pt":
-		if dateOnly {
-			return dt.Format("02/01/2006")
-		}
-		return dt.Format("02/01/2006 15:04")
-	default: // en and others
-		if dateOnly {
-			return dt.Format("01/02/2006")
-		}
-		return dt.Format("01/02/2006 15:04")
-	}
-}
-
 func localeSearchPaths() []string {
 	paths := make([]string, 0, 2)
 	if cdir, err := os.UserConfigDir(); err == nil && strings.TrimSpace(cdir) != "" {
@@ -381,18 +388,3 @@ func dedupe(items []string) []string {
 	}
 	return result
 }
-
-// GetDateFormat returns the date format string for the current locale
-func (t *Translator) GetDateFormat() string {
-	switch t.language {
-	case "es", "ga", "pt":
-		return "DD/MM/YYYY"
-	default:
-		return "MM/DD/YYYY"
-	}
-}
-
-// GetTimeFormat returns the time format string for the current locale
-func (t *Translator) GetTimeFormat() string {
-	return "HH:MM" // 24-hour format for all locales
-}
@@ -226,6 +226,29 @@ func TestTranslatorTFallback(t *testing.T) {
 	}
 }
 
+func TestTranslatorCategory(t *testing.T) {
+	tests := []struct {
+		lang, category, want string
+	}{
+		{"es", "Work", "Trabajo"},
+		{"pt", "Health", "Saúde"},
+		{"pt", "mental health", "Saúde mental"},
+		{"en", "Work", "Work"},
+		{"es", "Custom", "Custom"},
+		{"es", "Health/Medication", "Salud/Medicación"},
+		{"es", "Work/ClientA", "Trabajo/ClientA"},
+	}
+	for _, tt := range tests {
+		tr, err := NewTranslator(tt.lang)
+		if err != nil {
+			t.Fatalf(testErrNewTranslator, err)
+		}
+		if got := tr.Category(tt.category); got != tt.want {
+			t.Errorf("%s Category(%q) = %q, want %q", tt.lang, tt.category, got, tt.want)
+		}
+	}
+}
+
 // TestGetLanguage tests the GetLanguage method
 func TestGetLanguage(t *testing.T) {
 	tests := []struct {
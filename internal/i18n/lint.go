@@ -0,0 +1,116 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+)
+
+// Kinds of problem reported by Lint.
+const (
+	IssueMissing      = "missing"      // key in English but not in the catalog (shown in English)
+	IssueUnknown      = "unknown"      // key not in English (a typo or a removed key)
+	IssuePlaceholders = "placeholders" // format verbs differ from English, which garbles output
+)
+
+// Issue is one problem found in a locale catalog.
+type Issue struct {
+	Kind    string
+	Key     string
+	English string   // the English text, for missing keys
+	Want    []string // English placeholders, for placeholder issues
+	Got     []string
+}
+
+// Catalog is one set of translations to lint: an embedded locale or a file on disk.
+type Catalog struct {
+	Code     string
+	Path     string // empty for the embedded copy
+	Messages map[string]string
+}
+
+// Source names where the catalog comes from, for reports.
+func (c Catalog) Source() string {
+	if c.Path == "" {
+		return c.Code + " (embedded)"
+	}
+	return c.Path
+}
+
+// Catalogs returns every embedded locale and every locale file found on disk,
+// sorted by code. Files that cannot be read or parsed are returned as errors.
+func Catalogs() ([]Catalog, error) {
+	ensureEmbeddedLocales()
+	var (
+		out  []Catalog
+		errs []error
+	)
+	for _, info := range Locales() {
+		if info.Embedded {
+			out = append(out, Catalog{Code: info.Code, Messages: cloneStringMap(embeddedData[info.Code])})
+		}
+		for _, path := range info.DiskPaths {
+			data, err := os.ReadFile(filepath.Clean(path))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to read translation file %s: %w", path, err))
+				continue
+			}
+			m, err := decodeLocaleBytes(data, filepath.Ext(path))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to parse translation file %s: %w", path, err))
+				continue
+			}
+			out = append(out, Catalog{Code: info.Code, Path: path, Messages: m})
+		}
+	}
+	return out, errors.Join(errs...)
+}
+
+// Lint compares messages with the embedded English catalog, which defines the
+// keys the program uses. Issues are sorted by key.
+func Lint(messages map[string]string) []Issue {
+	ensureEmbeddedLocales()
+	english := embeddedData["en"]
+
+	var issues []Issue
+	for key, text := range english {
+		translated, ok := messages[key]
+		if !ok {
+			issues = append(issues, Issue{Kind: IssueMissing, Key: key, English: text})
+			continue
+		}
+		want, got := placeholders(text), placeholders(translated)
+		if !slices.Equal(want, got) {
+			issues = append(issues, Issue{Kind: IssuePlaceholders, Key: key, Want: want, Got: got})
+		}
+	}
+	for key := range messages {
+		if _, ok := english[key]; !ok {
+			issues = append(issues, Issue{Kind: IssueUnknown, Key: key})
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Key != issues[j].Key {
+			return issues[i].Key < issues[j].Key
+		}
+		return issues[i].Kind < issues[j].Kind
+	})
+	return issues
+}
+
+var verbRe = regexp.MustCompile(`%[-+# 0]*(?:\d+|\*)?(?:\.\d+)?[a-zA-Z%]`)
+
+// placeholders returns the fmt verbs of s in order, without "%%".
+func placeholders(s string) []string {
+	var verbs []string
+	for _, v := range verbRe.FindAllString(s, -1) {
+		if v != "%%" {
+			verbs = append(verbs, v)
+		}
+	}
+	return verbs
+}
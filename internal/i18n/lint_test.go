@@ -0,0 +1,78 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestEmbeddedLocalesLintClean(t *testing.T) {
+	ensureEmbeddedLocales()
+	for code, messages := range embeddedData {
+		if issues := Lint(messages); len(issues) > 0 {
+			t.Errorf("%s: %d issue(s), first %+v", code, len(issues), issues[0])
+		}
+	}
+}
+
+func TestLintReportsMissingUnknownAndPlaceholders(t *testing.T) {
+	ensureEmbeddedLocales()
+	messages := cloneStringMap(embeddedData["es"])
+	delete(messages, KeyEventCreated)
+	messages[KeyInvalidDate] = "Fecha no válida: %d"
+	messages["invalid_dat"] = "typo"
+
+	got := map[string]Issue{}
+	for _, issue := range Lint(messages) {
+		got[issue.Kind] = issue
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected one issue of each kind, got %+v", got)
+	}
+	if m := got[IssueMissing]; m.Key != KeyEventCreated || m.English == "" {
+		t.Errorf("missing = %+v", m)
+	}
+	if u := got[IssueUnknown]; u.Key != "invalid_dat" {
+		t.Errorf("unknown = %+v", u)
+	}
+	p := got[IssuePlaceholders]
+	if p.Key != KeyInvalidDate || !slices.Equal(p.Want, []string{"%s"}) || !slices.Equal(p.Got, []string{"%d"}) {
+		t.Errorf("placeholders = %+v", p)
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	got := placeholders("%s: 100%% done in %-5d, %.2f %q %v")
+	want := []string{"%s", "%-5d", "%.2f", "%q", "%v"}
+	if !slices.Equal(got, want) {
+		t.Errorf("placeholders() = %v, want %v", got, want)
+	}
+}
+
+func TestCatalogsIncludesDiskFiles(t *testing.T) {
+	cfg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", cfg)
+	dir := filepath.Join(cfg, "tempus", "locales")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "xx.yaml"), []byte("event_created: \"Done: %s\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "yy.json"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	catalogs, err := Catalogs()
+	if err == nil {
+		t.Error("expected the malformed yy.json to be reported")
+	}
+	idx := slices.IndexFunc(catalogs, func(c Catalog) bool { return c.Code == "xx" })
+	if idx < 0 {
+		t.Fatalf("expected the xx disk catalog, got %+v", catalogs)
+	}
+	if c := catalogs[idx]; c.Source() != filepath.Join(dir, "xx.yaml") || c.Messages[KeyEventCreated] != "Done: %s" {
+		t.Errorf("unexpected catalog %+v", c)
+	}
+}
@@ -0,0 +1,372 @@
+// Package journal records the calendar files tempus generates, with a copy
+// of whatever each write replaced, so `tempus undo` can put things back.
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"tempus/internal/config"
+)
+
+// MaxOperations is how many operations the journal keeps; older ones (and
+// their backups) are dropped as new ones are recorded.
+const MaxOperations = 50
+
+const (
+	journalFile = "journal.jsonl"
+	backupDir   = "backups"
+)
+
+// Op identifies one tempus invocation; every file it writes shares the ID.
+type Op struct {
+	ID      string
+	Command string
+	Time    time.Time
+}
+
+// NewOp starts an operation for command (e.g. "tempus batch").
+func NewOp(command string) Op {
+	now := time.Now()
+	return Op{ID: fmt.Sprintf("%s-%d", now.UTC().Format("20060102T150405.000000"), os.Getpid()), Command: command, Time: now}
+}
+
+// Entry is one recorded file write.
+type Entry struct {
+	Op      string      `json:"op"`
+	Time    time.Time   `json:"time"`
+	Command string      `json:"command"`
+	Path    string      `json:"path"`             // absolute path written
+	Hash    string      `json:"sha256"`           // of the content written
+	Backup  string      `json:"backup,omitempty"` // hash of the replaced content; empty for new files
+	Mode    os.FileMode `json:"mode,omitempty"`   // permissions of the replaced file, restored with it
+}
+
+// Journal is the on-disk journal in one directory.
+type Journal struct {
+	dir string
+}
+
+// Open returns the journal stored in dir.
+func Open(dir string) *Journal {
+	return &Journal{dir: dir}
+}
+
+// Default returns the journal under the tempus config directory.
+func Default() (*Journal, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return Open(filepath.Join(dir, "journal")), nil
+}
+
+// Dir is where the journal keeps its files.
+func (j *Journal) Dir() string { return j.dir }
+
+// Hash returns the hex SHA-256 of data.
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Record notes that op wrote content to path. previous is what path held
+// before the write, with its permissions in mode, and existed whether it was
+// there at all; the previous content is kept as a backup so Undo can restore
+// it.
+func (j *Journal) Record(op Op, path string, previous []byte, existed bool, mode os.FileMode, content []byte) error {
+	backup := ""
+	if existed {
+		var err error
+		if backup, err = j.Backup(bytes.NewReader(previous)); err != nil {
+			return err
+		}
+	}
+	return j.RecordHash(op, path, backup, mode, Hash(content))
+}
+
+// Backup keeps the content read from r so Undo can restore it, and returns
+// its hash. Take it before the file is replaced, then pass it to RecordHash.
+func (j *Journal) Backup(r io.Reader) (string, error) {
+	dir := filepath.Join(j.dir, backupDir)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", err
+	}
+	// Until it is renamed to its hash, save treats the copy as unreferenced.
+	f, err := os.CreateTemp(dir, "incoming-*")
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, hash), r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if _, err := os.Stat(filepath.Join(dir, sum)); err == nil {
+		return sum, os.Remove(f.Name())
+	}
+	return sum, os.Rename(f.Name(), filepath.Join(dir, sum))
+}
+
+// RecordHash is Record for content that was streamed to path rather than
+// held in memory: hash is its Hash, and backup what Backup returned for the
+// replaced file with its permissions in mode, or "" when path is new.
+func (j *Journal) RecordHash(op Op, path, backup string, mode os.FileMode, hash string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	entry := Entry{Op: op.ID, Time: op.Time, Command: op.Command, Path: abs, Hash: hash, Backup: backup}
+	if backup != "" {
+		entry.Mode = mode.Perm()
+	}
+
+	entries, err := j.Entries()
+	if err != nil {
+		return err
+	}
+	entries = append(entries, entry)
+	if ops := operationIDs(entries); len(ops) > MaxOperations {
+		keep := map[string]bool{}
+		for _, id := range ops[len(ops)-MaxOperations:] {
+			keep[id] = true
+		}
+		kept := entries[:0]
+		for _, e := range entries {
+			if keep[e.Op] {
+				kept = append(kept, e)
+			}
+		}
+		entries = kept
+	}
+	return j.save(entries)
+}
+
+// Entries returns every recorded write, oldest first.
+func (j *Journal) Entries() ([]Entry, error) {
+	f, err := os.Open(filepath.Join(j.dir, journalFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal([]byte(text), &e); err != nil {
+			return nil, fmt.Errorf("journal line %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// Operations groups the journal by operation, oldest first.
+func (j *Journal) Operations() ([][]Entry, error) {
+	entries, err := j.Entries()
+	if err != nil {
+		return nil, err
+	}
+	var ops [][]Entry
+	index := map[string]int{}
+	for _, e := range entries {
+		i, ok := index[e.Op]
+		if !ok {
+			i = len(ops)
+			index[e.Op] = i
+			ops = append(ops, nil)
+		}
+		ops[i] = append(ops[i], e)
+	}
+	return ops, nil
+}
+
+// Action is what Undo did (or would do) to one file.
+type Action struct {
+	Path string
+	// Kind is "restored" (previous content put back), "removed" (the file
+	// was new) or "missing" (already gone; nothing to do).
+	Kind string
+}
+
+// ErrNothingToUndo is returned by Undo when the journal is empty.
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// Undo reverts the most recent operation and drops it from the journal.
+// Files changed since tempus wrote them are left alone and reported as an
+// error unless force is set. With dryRun nothing is changed.
+func (j *Journal) Undo(force, dryRun bool) ([]Entry, []Action, error) {
+	ops, err := j.Operations()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(ops) == 0 {
+		return nil, nil, ErrNothingToUndo
+	}
+	last := ops[len(ops)-1]
+
+	// Check everything first so a refusal changes nothing. Entries are
+	// walked newest first: the same file may have been written several
+	// times (batch --watch) and each write's backup is the one before it.
+	current := map[string]string{}
+	var changed []string
+	for i := len(last) - 1; i >= 0; i-- {
+		e := last[i]
+		hash, seen := current[e.Path]
+		if !seen {
+			data, err := os.ReadFile(e.Path)
+			switch {
+			case errors.Is(err, os.ErrNotExist):
+				hash = ""
+			case err != nil:
+				return last, nil, err
+			default:
+				hash = Hash(data)
+			}
+		}
+		if hash != "" && hash != e.Hash {
+			changed = append(changed, e.Path)
+		}
+		current[e.Path] = e.Backup
+	}
+	if len(changed) > 0 && !force {
+		return last, nil, fmt.Errorf("changed since tempus wrote them (use --force to undo anyway): %s", strings.Join(changed, ", "))
+	}
+
+	var actions []Action
+	done := map[string]bool{}
+	for i := len(last) - 1; i >= 0; i-- {
+		e := last[i]
+		if done[e.Path] {
+			continue
+		}
+		// Only the oldest write of a file in this operation matters: its
+		// backup is what the file held before the whole operation.
+		first := e
+		for _, earlier := range last[:i] {
+			if earlier.Path == e.Path {
+				first = earlier
+				break
+			}
+		}
+		done[e.Path] = true
+
+		action := Action{Path: e.Path}
+		_, statErr := os.Stat(e.Path)
+		switch {
+		case first.Backup != "":
+			action.Kind = "restored"
+			if !dryRun {
+				data, err := os.ReadFile(filepath.Join(j.dir, backupDir, first.Backup))
+				if err != nil {
+					return last, actions, fmt.Errorf("backup of %s: %w", e.Path, err)
+				}
+				mode := first.Mode
+				if mode == 0 {
+					mode = 0o600 // recorded before modes were
+				}
+				if err := os.WriteFile(e.Path, data, mode); err != nil {
+					return last, actions, err
+				}
+				// WriteFile leaves an existing file's mode alone.
+				if err := os.Chmod(e.Path, mode); err != nil {
+					return last, actions, err
+				}
+			}
+		case errors.Is(statErr, os.ErrNotExist):
+			action.Kind = "missing"
+		default:
+			action.Kind = "removed"
+			if !dryRun {
+				if err := os.Remove(e.Path); err != nil {
+					return last, actions, err
+				}
+			}
+		}
+		actions = append(actions, action)
+	}
+	if dryRun {
+		return last, actions, nil
+	}
+
+	var rest []Entry
+	for _, op := range ops[:len(ops)-1] {
+		rest = append(rest, op...)
+	}
+	return last, actions, j.save(rest)
+}
+
+// save rewrites the journal with entries and deletes backups nothing refers to.
+func (j *Journal) save(entries []Entry) error {
+	var buf bytes.Buffer
+	used := map[string]bool{}
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+		if e.Backup != "" {
+			used[e.Backup] = true
+		}
+	}
+	if err := os.MkdirAll(j.dir, 0o750); err != nil {
+		return err
+	}
+	tmp := filepath.Join(j.dir, journalFile+".tmp")
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(j.dir, journalFile)); err != nil {
+		return err
+	}
+
+	backups, err := os.ReadDir(filepath.Join(j.dir, backupDir))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for _, b := range backups {
+		if !used[b.Name()] {
+			_ = os.Remove(filepath.Join(j.dir, backupDir, b.Name()))
+		}
+	}
+	return nil
+}
+
+// operationIDs lists the distinct operation IDs in entries, oldest first.
+func operationIDs(entries []Entry) []string {
+	var ids []string
+	seen := map[string]bool{}
+	for _, e := range entries {
+		if !seen[e.Op] {
+			seen[e.Op] = true
+			ids = append(ids, e.Op)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,175 @@
+package journal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// write writes content to path through the journal the way tempus does.
+func write(t *testing.T, j *Journal, op Op, path, content string) {
+	t.Helper()
+	previous, err := os.ReadFile(path)
+	existed := err == nil
+	var mode os.FileMode
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode()
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Record(op, path, previous, existed, mode, []byte(content)); err != nil {
+		t.Fatalf("Record() failed: %v", err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestUndoRestoresAndRemovesFiles(t *testing.T) {
+	dir := t.TempDir()
+	j := Open(filepath.Join(dir, "journal"))
+	existing := filepath.Join(dir, "week.ics")
+	if err := os.WriteFile(existing, []byte("original"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	first := NewOp("tempus batch")
+	write(t, j, first, existing, "first run")
+	second := Op{ID: first.ID + "-2", Command: "tempus batch"}
+	write(t, j, second, existing, "bad run")
+	write(t, j, second, existing, "bad run, regenerated")
+	created := filepath.Join(dir, "ana.ics")
+	write(t, j, second, created, "new file")
+
+	if _, actions, err := j.Undo(false, true); err != nil || len(actions) != 2 {
+		t.Fatalf("dry run = %+v, %v", actions, err)
+	}
+	if got := readFile(t, existing); got != "bad run, regenerated" {
+		t.Fatalf("dry run changed %s: %q", existing, got)
+	}
+
+	entries, actions, err := j.Undo(false, false)
+	if err != nil {
+		t.Fatalf("Undo() failed: %v", err)
+	}
+	if len(entries) != 3 || entries[0].Op != second.ID {
+		t.Errorf("undid entries %+v, want the 3 writes of the last operation", entries)
+	}
+	kinds := map[string]string{}
+	for _, a := range actions {
+		kinds[filepath.Base(a.Path)] = a.Kind
+	}
+	if kinds["week.ics"] != "restored" || kinds["ana.ics"] != "removed" {
+		t.Errorf("actions = %+v", actions)
+	}
+	if got := readFile(t, existing); got != "first run" {
+		t.Errorf("%s = %q after undo, want the first run back", existing, got)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed", created)
+	}
+
+	if _, _, err := j.Undo(false, false); err != nil {
+		t.Fatalf("second Undo() failed: %v", err)
+	}
+	if got := readFile(t, existing); got != "original" {
+		t.Errorf("%s = %q after undoing everything", existing, got)
+	}
+	if _, _, err := j.Undo(false, false); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("expected ErrNothingToUndo, got %v", err)
+	}
+	if backups, _ := os.ReadDir(filepath.Join(j.Dir(), backupDir)); len(backups) != 0 {
+		t.Errorf("expected unused backups to be deleted, found %d", len(backups))
+	}
+}
+
+func TestUndoRefusesFilesChangedSinceWritten(t *testing.T) {
+	dir := t.TempDir()
+	j := Open(filepath.Join(dir, "journal"))
+	path := filepath.Join(dir, "event.ics")
+	write(t, j, NewOp("tempus create"), path, "generated")
+	if err := os.WriteFile(path, []byte("edited by hand"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := j.Undo(false, false); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected a refusal mentioning --force, got %v", err)
+	}
+	if got := readFile(t, path); got != "edited by hand" {
+		t.Fatalf("refused undo changed the file: %q", got)
+	}
+	if _, _, err := j.Undo(true, false); err != nil {
+		t.Fatalf("forced Undo() failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected forced undo to remove the file")
+	}
+}
+
+func TestUndoKeepsFileMode(t *testing.T) {
+	dir := t.TempDir()
+	j := Open(filepath.Join(dir, "journal"))
+	shared := filepath.Join(dir, "team.ics")
+	gone := filepath.Join(dir, "gone.ics")
+	for _, path := range []string{shared, gone} {
+		if err := os.WriteFile(path, []byte("original"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(path, 0o644); err != nil { // past the umask
+			t.Fatal(err)
+		}
+	}
+
+	op := NewOp("tempus batch")
+	write(t, j, op, shared, "generated")
+	write(t, j, op, gone, "generated")
+	if err := os.Chmod(shared, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(gone); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := j.Undo(false, false); err != nil {
+		t.Fatalf("Undo() failed: %v", err)
+	}
+	for _, path := range []string{shared, gone} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0o644 || readFile(t, path) != "original" {
+			t.Errorf("%s restored as %v %q, want 0644 \"original\"", filepath.Base(path), info.Mode().Perm(), readFile(t, path))
+		}
+	}
+}
+
+func TestRecordKeepsRecentOperations(t *testing.T) {
+	dir := t.TempDir()
+	j := Open(filepath.Join(dir, "journal"))
+	path := filepath.Join(dir, "event.ics")
+	for i := 0; i < MaxOperations+5; i++ {
+		op := NewOp("tempus create")
+		op.ID += strings.Repeat("x", i) // distinct IDs within the same microsecond
+		write(t, j, op, path, strings.Repeat("v", i+1))
+	}
+	ops, err := j.Operations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != MaxOperations {
+		t.Errorf("kept %d operations, want %d", len(ops), MaxOperations)
+	}
+	backups, _ := os.ReadDir(filepath.Join(j.Dir(), backupDir))
+	if len(backups) != MaxOperations {
+		t.Errorf("kept %d backups, want %d", len(backups), MaxOperations)
+	}
+}
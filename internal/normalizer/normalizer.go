@@ -6,12 +6,25 @@ import (
 	"strings"
 	"time"
 
+	"tempus/internal/clock"
 	"tempus/internal/constants"
 	"tempus/internal/testutil"
 )
 
 var clockOnlyRe = regexp.MustCompile(`^\d{1,2}:\d{2}$`)
 
+// wallClock decides what "today" is for clock-only times.
+var wallClock clock.Clock = clock.System
+
+// SetClock makes "today" come from c instead of the system clock; nil
+// restores the system clock. Call it before normalizing, not concurrently.
+func SetClock(c clock.Clock) {
+	if c == nil {
+		c = clock.System
+	}
+	wallClock = c
+}
+
 // PrependToday takes a time-only string (HH:MM) and prepends today's date in YYYY-MM-DD format.
 // If the input already contains a date, it returns the input unchanged.
 func PrependToday(input, timezone string) string {
@@ -34,7 +47,7 @@ func PrependToday(input, timezone string) string {
 	}
 
 	// Prepend today's date
-	now := time.Now().In(loc)
+	now := wallClock.Now().In(loc)
 	return fmt.Sprintf("%s %s", now.Format(constants.DateFormatISO), input)
 }
 
@@ -104,7 +117,7 @@ func ParseDateTime(input, timezone string) (time.Time, error) {
 		if t, err := time.ParseInLocation(format, input, loc); err == nil {
 			// If it's time-only format, prepend today
 			if format == constants.TimeFormatHHMM {
-				now := time.Now().In(loc)
+				now := wallClock.Now().In(loc)
 				return time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, loc), nil
 			}
 			return t, nil
@@ -155,6 +168,20 @@ func ParseHumanDuration(s string) (time.Duration, error) {
 	return 0, fmt.Errorf("invalid duration format: %s", s)
 }
 
+// maxHumanDuration is the longest duration ParseHumanDuration returns; a
+// century keeps counts times their units inside time.Duration.
+const maxHumanDuration = 100 * 365 * 24 * time.Hour
+
+// durationOf returns n units, or false when that is more than
+// maxHumanDuration either way.
+func durationOf(n int, unit time.Duration) (time.Duration, bool) {
+	limit := int(maxHumanDuration / unit)
+	if n > limit || n < -limit {
+		return 0, false
+	}
+	return time.Duration(n) * unit, true
+}
+
 // parseDaysFormat parses duration strings like "1d", "2d", "7d".
 func parseDaysFormat(s string) (time.Duration, bool) {
 	if !strings.HasSuffix(s, "d") {
@@ -163,7 +190,7 @@ func parseDaysFormat(s string) (time.Duration, bool) {
 	daysStr := strings.TrimSuffix(s, "d")
 	var d int
 	if _, err := fmt.Sscanf(daysStr, "%d", &d); err == nil {
-		return time.Duration(d) * 24 * time.Hour, true
+		return durationOf(d, 24*time.Hour)
 	}
 	return 0, false
 }
@@ -176,7 +203,7 @@ func parseWeeksFormat(s string) (time.Duration, bool) {
 	weeksStr := strings.TrimSuffix(s, "w")
 	var w int
 	if _, err := fmt.Sscanf(weeksStr, "%d", &w); err == nil {
-		return time.Duration(w) * 7 * 24 * time.Hour, true
+		return durationOf(w, 7*24*time.Hour)
 	}
 	return 0, false
 }
@@ -192,7 +219,11 @@ func parseHHMMFormat(s string) (time.Duration, bool) {
 	}
 	var hours, minutes int
 	if _, err := fmt.Sscanf(s, "%d:%d", &hours, &minutes); err == nil {
-		return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute, true
+		h, okH := durationOf(hours, time.Hour)
+		m, okM := durationOf(minutes, time.Minute)
+		if okH && okM {
+			return h + m, true
+		}
 	}
 	return 0, false
 }
@@ -201,7 +232,7 @@ func parseHHMMFormat(s string) (time.Duration, bool) {
 func parseMinutesFormat(s string) (time.Duration, bool) {
 	var minutes int
 	if _, err := fmt.Sscanf(s, "%d", &minutes); err == nil {
-		return time.Duration(minutes) * time.Minute, true
+		return durationOf(minutes, time.Minute)
 	}
 	return 0, false
 }
@@ -2,6 +2,7 @@ package normalizer
 
 import (
 	"strings"
+	"tempus/internal/clock"
 	"tempus/internal/testutil"
 	"testing"
 	"time"
@@ -190,3 +191,28 @@ func TestNormalizeValuesForTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestPrependTodayUsesClock(t *testing.T) {
+	SetClock(clock.Fixed(time.Date(2025, 5, 1, 23, 30, 0, 0, time.UTC)))
+	t.Cleanup(func() { SetClock(nil) })
+
+	if got := PrependToday("09:00", "UTC"); got != "2025-05-01 09:00" {
+		t.Errorf("PrependToday() = %q, want the pinned day", got)
+	}
+	// 23:30 UTC is already the next day in Tokyo.
+	if got := PrependToday("09:00", "Asia/Tokyo"); got != "2025-05-02 09:00" {
+		t.Errorf("PrependToday() in Tokyo = %q, want 2025-05-02 09:00", got)
+	}
+}
+
+func FuzzParseHumanDuration(f *testing.F) {
+	for _, seed := range []string{"45m", "1h30m", "2d", "1w", "90", "1:30", "-5m", "99999999999d", "1:99999999999999"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		d, err := ParseHumanDuration(s)
+		if err == nil && d < 0 && !strings.Contains(s, "-") {
+			t.Errorf("ParseHumanDuration(%q) = %v, negative without a minus sign", s, d)
+		}
+	})
+}
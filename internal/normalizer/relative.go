@@ -0,0 +1,181 @@
+package normalizer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olebedev/when"
+	"github.com/olebedev/when/rules"
+	"github.com/olebedev/when/rules/br"
+	"github.com/olebedev/when/rules/common"
+	"github.com/olebedev/when/rules/en"
+
+	"tempus/internal/constants"
+)
+
+// whenRules are the natural-language date rules quick mode also uses, by
+// language; English is always tried.
+var whenRules = map[string][]rules.Rule{
+	"pt": br.All,
+}
+
+var (
+	offsetDateRe = regexp.MustCompile(`^([+-]\d+)\s*(bd|[dw])(?:\s+(\d{1,2}:\d{2}))?$`)
+	clockTokenRe = regexp.MustCompile(`\d{1,2}:\d{2}|\d{1,2}\s*(?:am|pm)\b|\bnoon\b|\bmidnight\b`)
+	trailClockRe = regexp.MustCompile(`^(.*?)\s*(\d{1,2}:\d{2})$`)
+)
+
+// ExpandRelative rewrites a relative date such as "today 15:00", "tomorrow
+// 09:30", "next tuesday 10:00", "+3d 14:00" or "+5bd" (five business days)
+// as "YYYY-MM-DD HH:MM", or as "YYYY-MM-DD" when it has no time, counting
+// from now. English is always
+// understood, plus Spanish, Portuguese or Irish keywords when lang is es, pt
+// or ga ("mañana 09:30", "próxima terça 10:00", "amárach 09:30"). Weekdays
+// mean the next one after today. ok is false when input is not a relative
+// date, including plain dates and clock-only times, which callers already
+// handle.
+func ExpandRelative(input string, now time.Time, lang string) (string, bool) {
+	s := strings.Join(strings.Fields(strings.ToLower(input)), " ")
+	if s == "" || !strings.ContainsAny(s, "+-") && !strings.ContainsFunc(s, isLetter) {
+		return "", false
+	}
+	if m := offsetDateRe.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "w":
+			n *= 7
+		case "bd":
+			return formatRelative(AddBusinessDays(now, n), m[3]), true
+		}
+		return formatRelative(now.AddDate(0, 0, n), m[3]), true
+	}
+	if !strings.ContainsFunc(s, isLetter) {
+		return "", false
+	}
+
+	// Spanish and Irish have no when rules; they use the keyword tables.
+	if words, ok := relativeWords[strings.ToLower(lang)]; ok {
+		if out, ok := words.expand(s, now); ok {
+			return out, true
+		}
+	}
+
+	w := when.New(nil)
+	w.Add(en.All...)
+	w.Add(common.All...)
+	w.Add(whenRules[strings.ToLower(lang)]...)
+	res, err := w.Parse(s, now)
+	if err != nil || res == nil || res.Index != 0 || len(res.Text) != len(s) {
+		return "", false
+	}
+	if clockTokenRe.MatchString(s) {
+		return res.Time.Format(constants.DateTimeFormatISO), true
+	}
+	return res.Time.Format(constants.DateFormatISO), true
+}
+
+func isLetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r > 0x7f
+}
+
+func formatRelative(day time.Time, clock string) string {
+	date := day.Format(constants.DateFormatISO)
+	if clock == "" {
+		return date
+	}
+	if len(clock) == 4 { // 9:30
+		clock = "0" + clock
+	}
+	return date + " " + clock
+}
+
+// relativeKeywords are one language's words for relative dates.
+type relativeKeywords struct {
+	days       map[string]int // "mañana": 1
+	weekdays   map[string]time.Weekday
+	prefixes   []string // dropped before a weekday: "el", "próximo"
+	suffixes   []string // dropped after a weekday: "que viene"
+	connectors []string // between the day and the time: "a las"
+	inDays     *regexp.Regexp
+}
+
+var relativeWords = map[string]relativeKeywords{
+	"es": {
+		days: map[string]int{
+			"hoy": 0, "mañana": 1, "manana": 1, "pasado mañana": 2, "pasado manana": 2, "ayer": -1,
+		},
+		weekdays: map[string]time.Weekday{
+			"lunes": time.Monday, "martes": time.Tuesday, "miércoles": time.Wednesday, "miercoles": time.Wednesday,
+			"jueves": time.Thursday, "viernes": time.Friday, "sábado": time.Saturday, "sabado": time.Saturday,
+			"domingo": time.Sunday,
+		},
+		prefixes:   []string{"el próximo", "el proximo", "próximo", "proximo", "este", "el"},
+		suffixes:   []string{"que viene"},
+		connectors: []string{"a las", "a la"},
+		inDays:     regexp.MustCompile(`^(?:en|dentro de) (\d+) (días|dias|día|dia|semanas|semana)$`),
+	},
+	"ga": {
+		days: map[string]int{
+			"inniu": 0, "amárach": 1, "amarach": 1, "arú amárach": 2, "aru amarach": 2, "inné": -1, "inne": -1,
+		},
+		weekdays: map[string]time.Weekday{
+			"dé luain": time.Monday, "de luain": time.Monday, "dé máirt": time.Tuesday, "de mairt": time.Tuesday,
+			"dé céadaoin": time.Wednesday, "de ceadaoin": time.Wednesday, "déardaoin": time.Thursday,
+			"deardaoin": time.Thursday, "dé haoine": time.Friday, "de haoine": time.Friday,
+			"dé sathairn": time.Saturday, "de sathairn": time.Saturday, "dé domhnaigh": time.Sunday,
+			"de domhnaigh": time.Sunday,
+		},
+		suffixes:   []string{"seo chugainn", "seo"},
+		connectors: []string{"ag", "ar"},
+		inDays:     regexp.MustCompile(`^i gceann (\d+) (lá|la|seachtain|seachtaine)$`),
+	},
+}
+
+// expand reads "DAY [CONNECTOR] [HH:MM]" with the language's keywords.
+func (k relativeKeywords) expand(s string, now time.Time) (string, bool) {
+	day, clock := s, ""
+	if m := trailClockRe.FindStringSubmatch(s); m != nil {
+		day, clock = m[1], m[2]
+		for _, c := range k.connectors {
+			if trimmed, ok := strings.CutSuffix(day, " "+c); ok {
+				day = trimmed
+				break
+			}
+		}
+	}
+	if n, ok := k.days[day]; ok {
+		return formatRelative(now.AddDate(0, 0, n), clock), true
+	}
+	if k.inDays != nil {
+		if m := k.inDays.FindStringSubmatch(day); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			if strings.HasPrefix(m[2], "sem") || strings.HasPrefix(m[2], "seacht") {
+				n *= 7
+			}
+			return formatRelative(now.AddDate(0, 0, n), clock), true
+		}
+	}
+	for _, p := range k.prefixes {
+		if trimmed, ok := strings.CutPrefix(day, p+" "); ok {
+			day = trimmed
+			break
+		}
+	}
+	for _, sfx := range k.suffixes {
+		if trimmed, ok := strings.CutSuffix(day, " "+sfx); ok {
+			day = trimmed
+			break
+		}
+	}
+	wd, ok := k.weekdays[day]
+	if !ok {
+		return "", false
+	}
+	ahead := (int(wd) - int(now.Weekday()) + 7) % 7
+	if ahead == 0 {
+		ahead = 7
+	}
+	return formatRelative(now.AddDate(0, 0, ahead), clock), true
+}
@@ -0,0 +1,61 @@
+package normalizer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpandRelative(t *testing.T) {
+	now := time.Date(2025, 5, 6, 12, 0, 0, 0, time.UTC) // a Tuesday
+	tests := []struct {
+		input, lang, want string
+	}{
+		{"today 15:00", "en", "2025-05-06 15:00"},
+		{"Tomorrow 09:30", "en", "2025-05-07 09:30"},
+		{"tomorrow", "en", "2025-05-07"},
+		{"next tuesday 10:00", "en", "2025-05-13 10:00"},
+		{"friday", "en", "2025-05-09"},
+		{"next friday at 3pm", "en", "2025-05-09 15:00"},
+		{"+3d 14:00", "en", "2025-05-09 14:00"},
+		{"+1w", "en", "2025-05-13"},
+		{"-2d 9:15", "en", "2025-05-04 09:15"},
+		{"in 3 days", "en", "2025-05-09"},
+		{"+5bd", "en", "2025-05-13"},
+		{"+3bd 10:00", "en", "2025-05-09 10:00"},
+		{"-2bd", "en", "2025-05-02"},
+
+		{"mañana 09:30", "es", "2025-05-07 09:30"},
+		{"pasado mañana a las 18:00", "es", "2025-05-08 18:00"},
+		{"el próximo martes 10:00", "es", "2025-05-13 10:00"},
+		{"viernes que viene", "es", "2025-05-09"},
+		{"en 2 semanas 08:00", "es", "2025-05-20 08:00"},
+		{"tomorrow 09:30", "es", "2025-05-07 09:30"}, // English always works
+
+		{"amanhã 09:30", "pt", "2025-05-07 09:30"},
+		{"próxima terça 10:00", "pt", "2025-05-13 10:00"},
+
+		{"amárach 09:30", "ga", "2025-05-07 09:30"},
+		{"Dé hAoine seo chugainn ag 17:00", "ga", "2025-05-09 17:00"},
+		{"i gceann 3 lá", "ga", "2025-05-09"},
+	}
+	for _, tt := range tests {
+		got, ok := ExpandRelative(tt.input, now, tt.lang)
+		if !ok || got != tt.want {
+			t.Errorf("ExpandRelative(%q, %s) = %q, %v; want %q", tt.input, tt.lang, got, ok, tt.want)
+		}
+	}
+}
+
+func TestExpandRelativeLeavesOtherInputAlone(t *testing.T) {
+	now := time.Date(2025, 5, 6, 12, 0, 0, 0, time.UTC)
+	for _, input := range []string{
+		"", "2025-05-06 10:00", "2025-05-06", "10:00", "3d",
+		"mañana 09:30",           // Spanish keywords need lang es
+		"tomorrow and then some", // must be a date from start to end
+		"team standup",
+	} {
+		if got, ok := ExpandRelative(input, now, "en"); ok {
+			t.Errorf("ExpandRelative(%q) = %q, want no expansion", input, got)
+		}
+	}
+}
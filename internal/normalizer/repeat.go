@@ -0,0 +1,434 @@
+package normalizer
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"tempus/internal/constants"
+)
+
+// Repeat is a repeat rule read from a sentence such as "every other monday
+// until June".
+type Repeat struct {
+	Freq     string         // DAILY, WEEKLY, MONTHLY or YEARLY
+	Interval int            // 2 for "every other week"; 0 or 1 is every one
+	Weekdays []time.Weekday // BYDAY: "every monday", "weekdays"
+	MonthDay int            // BYMONTHDAY: "every month on the 1st"; 0 for the start's day
+	End      string         // when it stops, as calendar.ResolveUntil reads it ("2030-05-31", "for 6 weeks"); empty for never
+}
+
+var rruleDays = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+var workdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+// RRule writes the rule without its end: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO".
+func (r Repeat) RRule() string {
+	rule := "FREQ=" + r.Freq
+	if r.Interval > 1 {
+		rule += ";INTERVAL=" + strconv.Itoa(r.Interval)
+	}
+	if len(r.Weekdays) > 0 {
+		days := make([]string, len(r.Weekdays))
+		for i, wd := range r.Weekdays {
+			days[i] = rruleDays[wd]
+		}
+		rule += ";BYDAY=" + strings.Join(days, ",")
+	}
+	if r.MonthDay > 0 {
+		rule += ";BYMONTHDAY=" + strconv.Itoa(r.MonthDay)
+	}
+	return rule
+}
+
+// repeatKeywords are one language's words for repeat rules.
+type repeatKeywords struct {
+	every    []string          // before a unit or a weekday: "every", "todas as"
+	numbers  map[string]int    // "other": 2, in "every other week"
+	units    map[string]string // "week": "WEEKLY"
+	weekdays map[string]time.Weekday
+	and      []string          // between the weekdays of a list: "tuesday and thursday"
+	phrases  map[string]Repeat // whole phrases: "daily", "weekdays", "dias úteis"
+	monthDay []string          // before the day of a monthly rule: "on the", "no dia"
+	until    []string          // before the last date: "until", "até"
+	span     []string          // before a length: "for", "durante"
+	months   map[string]time.Month
+}
+
+var repeatWords = map[string]repeatKeywords{
+	"en": {
+		every:   []string{"every", "each"},
+		numbers: map[string]int{"other": 2, "second": 2, "two": 2, "three": 3, "four": 4, "six": 6},
+		units: map[string]string{
+			"day": "DAILY", "days": "DAILY", "week": "WEEKLY", "weeks": "WEEKLY",
+			"month": "MONTHLY", "months": "MONTHLY", "year": "YEARLY", "years": "YEARLY",
+		},
+		weekdays: pluralWeekdays(map[string]time.Weekday{
+			"monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday, "thursday": time.Thursday,
+			"friday": time.Friday, "saturday": time.Saturday, "sunday": time.Sunday,
+		}, "s"),
+		and: []string{"and", "&"},
+		phrases: map[string]Repeat{
+			"daily": {Freq: "DAILY"}, "weekly": {Freq: "WEEKLY"}, "monthly": {Freq: "MONTHLY"},
+			"yearly": {Freq: "YEARLY"}, "annually": {Freq: "YEARLY"},
+			"fortnightly": {Freq: "WEEKLY", Interval: 2}, "biweekly": {Freq: "WEEKLY", Interval: 2},
+			"every weekday": {Freq: "WEEKLY", Weekdays: workdays}, "on weekdays": {Freq: "WEEKLY", Weekdays: workdays},
+			"weekdays": {Freq: "WEEKLY", Weekdays: workdays}, "every workday": {Freq: "WEEKLY", Weekdays: workdays},
+			"on workdays": {Freq: "WEEKLY", Weekdays: workdays}, "monday to friday": {Freq: "WEEKLY", Weekdays: workdays},
+		},
+		monthDay: []string{"on the", "on"},
+		until:    []string{"until", "till", "through"},
+		span:     []string{"for"},
+		months: map[string]time.Month{
+			"january": time.January, "jan": time.January, "february": time.February, "feb": time.February,
+			"march": time.March, "mar": time.March, "april": time.April, "apr": time.April, "may": time.May,
+			"june": time.June, "jun": time.June, "july": time.July, "jul": time.July, "august": time.August,
+			"aug": time.August, "september": time.September, "sep": time.September, "sept": time.September,
+			"october": time.October, "oct": time.October, "november": time.November, "nov": time.November,
+			"december": time.December, "dec": time.December,
+		},
+	},
+	"pt": {
+		every:   []string{"todos os", "todas as", "todo", "toda", "a cada", "cada"},
+		numbers: map[string]int{"dois": 2, "duas": 2, "três": 3, "tres": 3, "quatro": 4, "seis": 6},
+		units: map[string]string{
+			"dia": "DAILY", "dias": "DAILY", "semana": "WEEKLY", "semanas": "WEEKLY",
+			"mês": "MONTHLY", "mes": "MONTHLY", "meses": "MONTHLY", "ano": "YEARLY", "anos": "YEARLY",
+		},
+		weekdays: pluralWeekdays(map[string]time.Weekday{
+			"segunda": time.Monday, "segunda-feira": time.Monday, "terça": time.Tuesday, "terca": time.Tuesday,
+			"terça-feira": time.Tuesday, "terca-feira": time.Tuesday, "quarta": time.Wednesday,
+			"quarta-feira": time.Wednesday, "quinta": time.Thursday, "quinta-feira": time.Thursday,
+			"sexta": time.Friday, "sexta-feira": time.Friday, "sábado": time.Saturday, "sabado": time.Saturday,
+			"domingo": time.Sunday,
+		}, "s"),
+		and: []string{"e"},
+		phrases: map[string]Repeat{
+			"diariamente": {Freq: "DAILY"}, "semanalmente": {Freq: "WEEKLY"}, "mensalmente": {Freq: "MONTHLY"},
+			"anualmente": {Freq: "YEARLY"}, "quinzenalmente": {Freq: "WEEKLY", Interval: 2},
+			"semana sim, semana não": {Freq: "WEEKLY", Interval: 2}, "semana sim semana não": {Freq: "WEEKLY", Interval: 2},
+			"nos dias úteis": {Freq: "WEEKLY", Weekdays: workdays}, "em dias úteis": {Freq: "WEEKLY", Weekdays: workdays},
+			"dias úteis": {Freq: "WEEKLY", Weekdays: workdays}, "dias uteis": {Freq: "WEEKLY", Weekdays: workdays},
+			"de segunda a sexta": {Freq: "WEEKLY", Weekdays: workdays},
+		},
+		monthDay: []string{"no dia", "dia"},
+		until:    []string{"até o dia", "até dia", "até", "ate"},
+		span:     []string{"durante", "por"},
+		months: map[string]time.Month{
+			"janeiro": time.January, "fevereiro": time.February, "março": time.March, "marco": time.March,
+			"abril": time.April, "maio": time.May, "junho": time.June, "julho": time.July, "agosto": time.August,
+			"setembro": time.September, "outubro": time.October, "novembro": time.November, "dezembro": time.December,
+		},
+	},
+}
+
+// pluralWeekdays adds each weekday name with suffix ("mondays", "segundas";
+// "segundas-feiras" too).
+func pluralWeekdays(names map[string]time.Weekday, suffix string) map[string]time.Weekday {
+	out := make(map[string]time.Weekday, 2*len(names))
+	for name, wd := range names {
+		out[name] = wd
+		out[strings.Replace(name, "-", suffix+"-", 1)+suffix] = wd
+	}
+	return out
+}
+
+// repeatPatterns are the compiled forms of one language's repeatKeywords.
+// Every pattern keeps the phrase itself in its first group, so the
+// separators around it stay in the text.
+type repeatPatterns struct {
+	words  repeatKeywords
+	phrase *regexp.Regexp // a whole phrase
+	every  *regexp.Regexp // every [N|other] (unit|weekday[, weekday] [and weekday])
+	day    *regexp.Regexp // on the Nth, of a monthly rule
+	span   *regexp.Regexp // for N units
+	until  *regexp.Regexp // until a date
+}
+
+var repeatRes = func() map[string]repeatPatterns {
+	out := make(map[string]repeatPatterns, len(repeatWords))
+	for lang, k := range repeatWords {
+		const (
+			before = `(?i)(?:^|[\s,;(])(`
+			after  = `)(?:$|[\s,.;:!?)])`
+		)
+		number := `(?:\d+|` + alternation(keys(k.numbers)) + `)`
+		weekday := alternation(keys(k.weekdays))
+		weekdays := weekday + `(?:\s*,\s*` + weekday + `|\s*,?\s+` + alternation(k.and) + `\s+` + weekday + `)*`
+		month := alternation(keys(k.months))
+		day := `\d{1,2}(?:st|nd|rd|th)?`
+		out[lang] = repeatPatterns{
+			words:  k,
+			phrase: regexp.MustCompile(before + alternation(keys(k.phrases)) + after),
+			every: regexp.MustCompile(before + alternation(k.every) + `\s+(?:(` + number + `)\s+)?(?:(` +
+				alternation(keys(k.units)) + `)|(` + weekdays + `))` + after),
+			day:  regexp.MustCompile(before + alternation(k.monthDay) + `\s+(\d{1,2})(?:st|nd|rd|th)?` + after),
+			span: regexp.MustCompile(before + alternation(k.span) + `\s+(` + number + `)\s+(` + alternation(keys(k.units)) + `)` + after),
+			until: regexp.MustCompile(before + alternation(k.until) + `\s+(?:(\d{4}-\d{2}-\d{2})|(` + day + `)(?:\s+(?:of|de))?\s+(` + month +
+				`)(?:,?\s+(?:de\s+)?(\d{4}))?|(` + month + `)(?:\s+(` + day + `))?(?:,?\s+(?:de\s+)?(\d{4}))?)` + after),
+		}
+	}
+	return out
+}()
+
+func keys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
+
+// alternation joins words into a regexp alternation, longest first so
+// "segundas" wins over "segunda".
+func alternation(words []string) string {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = strings.ReplaceAll(regexp.QuoteMeta(w), " ", `\s+`)
+	}
+	sort.Slice(quoted, func(i, j int) bool {
+		if len(quoted[i]) != len(quoted[j]) {
+			return len(quoted[i]) > len(quoted[j])
+		}
+		return quoted[i] < quoted[j]
+	})
+	return `(?:` + strings.Join(quoted, "|") + `)`
+}
+
+// ExtractRepeat finds a repeat phrase in a quick-mode sentence: "every day",
+// "every week", "every month", "weekdays", "every other week", "every 3
+// days", "every monday", optionally followed by an end, "until June 15",
+// "until 2030-06-30" or "for 6 weeks". A monthly rule also takes its day,
+// "every month on the 1st". English is always understood, plus
+// Portuguese when lang is pt ("toda segunda até junho", "dias úteis
+// durante 4 semanas"). It returns the sentence without the phrase, for the
+// date parser. A month on its own ends the series before that month starts:
+// "until June" stops on May 31. Dates without a year are the next ones
+// after now. ok is false when the sentence does not repeat.
+func ExtractRepeat(text string, now time.Time, lang string) (string, Repeat, bool) {
+	langs := repeatLangs(lang)
+
+	// The last phrase wins, so "weekly sync every monday" keeps its name.
+	var (
+		rep Repeat
+		at  []int
+	)
+	for _, l := range langs {
+		p := repeatRes[l]
+		if m := lastMatch(p.phrase, text); m != nil && (at == nil || m[2] > at[2]) {
+			rep, at = p.words.phrases[normalizePhrase(text[m[2]:m[3]])], m
+		}
+		if m := lastMatch(p.every, text); m != nil && (at == nil || m[2] > at[2]) {
+			if r, ok := p.words.everyRepeat(text, m); ok {
+				rep, at = r, m
+			}
+		}
+	}
+	if at == nil {
+		return text, Repeat{}, false
+	}
+	text = cutMatch(text, at)
+
+	for _, l := range langs {
+		p := repeatRes[l]
+		if m := p.span.FindStringSubmatchIndex(text); m != nil {
+			if n := p.words.number(text[m[4]:m[5]]); n > 0 {
+				unit := spanUnits[p.words.units[strings.ToLower(text[m[6]:m[7]])]]
+				rep.End = fmt.Sprintf("for %d %ss", n, unit)
+				text = cutMatch(text, m)
+				break
+			}
+		}
+		if m := p.until.FindStringSubmatchIndex(text); m != nil {
+			if last, ok := p.words.untilDate(text, m, now); ok {
+				rep.End = last.Format(constants.DateFormatISO)
+				text = cutMatch(text, m)
+				break
+			}
+		}
+	}
+	if rep.Freq == "MONTHLY" && len(rep.Weekdays) == 0 {
+		for _, l := range langs {
+			if m := repeatRes[l].day.FindStringSubmatchIndex(text); m != nil {
+				if n, _ := strconv.Atoi(text[m[4]:m[5]]); n >= 1 && n <= 31 {
+					rep.MonthDay = n
+					text = cutMatch(text, m)
+					break
+				}
+			}
+		}
+	}
+	return strings.Join(strings.Fields(text), " "), rep, true
+}
+
+// repeatLangs lists the languages whose repeat phrases are read: English,
+// and lang when its phrases are known.
+func repeatLangs(lang string) []string {
+	langs := []string{"en"}
+	if lang = strings.ToLower(strings.TrimSpace(lang)); lang != "en" {
+		if _, ok := repeatRes[lang]; ok {
+			langs = append(langs, lang)
+		}
+	}
+	return langs
+}
+
+// spanUnits names the units of a "for N units" end the way
+// calendar.ResolveUntil spells them.
+var spanUnits = map[string]string{"DAILY": "day", "WEEKLY": "week", "MONTHLY": "month", "YEARLY": "year"}
+
+func lastMatch(re *regexp.Regexp, text string) []int {
+	all := re.FindAllStringSubmatchIndex(text, -1)
+	if len(all) == 0 {
+		return nil
+	}
+	return all[len(all)-1]
+}
+
+// everyRepeat reads an every match: "every other week", "every monday".
+func (k repeatKeywords) everyRepeat(text string, m []int) (Repeat, bool) {
+	rep := Repeat{Interval: 1}
+	if m[4] >= 0 {
+		rep.Interval = k.number(text[m[4]:m[5]])
+	}
+	if m[6] >= 0 {
+		rep.Freq = k.units[strings.ToLower(text[m[6]:m[7]])]
+	} else {
+		rep.Freq = "WEEKLY"
+		list := strings.FieldsFunc(strings.ToLower(text[m[8]:m[9]]), func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+		for _, word := range list {
+			if wd, ok := k.weekdays[word]; ok && !slices.Contains(rep.Weekdays, wd) {
+				rep.Weekdays = append(rep.Weekdays, wd)
+			}
+		}
+	}
+	return rep, rep.Interval > 0
+}
+
+// Next returns the occurrence that follows t, an occurrence of the rule.
+// Weeks start on Monday, as in the RRULE it writes.
+func (r Repeat) Next(t time.Time) time.Time {
+	n := max(r.Interval, 1)
+	switch {
+	case r.Freq == "DAILY":
+		return t.AddDate(0, 0, n)
+	case r.Freq == "WEEKLY" && len(r.Weekdays) > 0:
+		for {
+			if t = t.AddDate(0, 0, 1); t.Weekday() == time.Monday {
+				t = t.AddDate(0, 0, 7*(n-1))
+			}
+			if slices.Contains(r.Weekdays, t.Weekday()) {
+				return t
+			}
+		}
+	case r.Freq == "WEEKLY":
+		return t.AddDate(0, 0, 7*n)
+	}
+	years, months := n, 0
+	if r.Freq == "MONTHLY" {
+		years, months = 0, n
+	}
+	// Like the RRULE, skip the months (or years) that lack t's day.
+	for k := 1; ; k++ {
+		if next := t.AddDate(k*years, k*months, 0); next.Day() == t.Day() {
+			return next
+		}
+	}
+}
+
+// StartsRepeatEnd reports whether s opens with the end of a repeat rule,
+// "until June 15" or "for 6 weeks", in English or lang.
+func StartsRepeatEnd(s, lang string) bool {
+	for _, l := range []string{"en", strings.ToLower(strings.TrimSpace(lang))} {
+		p, ok := repeatRes[l]
+		if !ok {
+			continue
+		}
+		for _, re := range []*regexp.Regexp{p.until, p.span} {
+			if m := re.FindStringSubmatchIndex(s); m != nil && m[2] == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RepeatSpans returns where text holds repeat phrases ("every monday",
+// "weekdays") and their ends ("until June 15", "for 6 weeks"), in English
+// or lang, as [start, end) offsets of the phrases.
+func RepeatSpans(text, lang string) [][2]int {
+	var spans [][2]int
+	for _, l := range repeatLangs(lang) {
+		p := repeatRes[l]
+		for _, re := range []*regexp.Regexp{p.phrase, p.every, p.until, p.span} {
+			for _, m := range re.FindAllStringSubmatchIndex(text, -1) {
+				spans = append(spans, [2]int{m[2], m[3]})
+			}
+		}
+	}
+	return spans
+}
+
+// cutMatch removes the phrase (the first group of m) from text.
+func cutMatch(text string, m []int) string {
+	return text[:m[2]] + " " + text[m[3]:]
+}
+
+func normalizePhrase(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+func (k repeatKeywords) number(s string) int {
+	if n, ok := k.numbers[strings.ToLower(s)]; ok {
+		return n
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// untilDate reads the date of an until match: an ISO date, a day and a
+// month, or a month alone, each with an optional year.
+func (k repeatKeywords) untilDate(text string, m []int, now time.Time) (time.Time, bool) {
+	group := func(i int) string {
+		if m[2*i] < 0 {
+			return ""
+		}
+		return strings.ToLower(text[m[2*i]:m[2*i+1]])
+	}
+	if iso := group(2); iso != "" {
+		t, err := time.Parse(constants.DateFormatISO, iso)
+		return t, err == nil
+	}
+	dayText, monthText, yearText := group(3), group(4), group(5)
+	if monthText == "" {
+		dayText, monthText, yearText = group(7), group(6), group(8)
+	}
+	month := k.months[monthText]
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	year, explicitYear := today.Year(), yearText != ""
+	if explicitYear {
+		year, _ = strconv.Atoi(yearText)
+	}
+
+	if dayText == "" {
+		first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		if !explicitYear && !first.After(today) {
+			first = first.AddDate(1, 0, 0)
+		}
+		return first.AddDate(0, 0, -1), true
+	}
+	day, _ := strconv.Atoi(strings.TrimRight(dayText, "stndrh"))
+	last := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	if last.Day() != day {
+		return time.Time{}, false // June 31
+	}
+	if !explicitYear && last.Before(today) {
+		last = time.Date(year+1, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	return last, last.Day() == day
+}
@@ -0,0 +1,128 @@
+package normalizer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExtractRepeat(t *testing.T) {
+	now := time.Date(2030, 3, 4, 12, 0, 0, 0, time.UTC) // a Monday
+	tests := []struct {
+		input, lang string
+		rest, rule  string
+		end         string
+	}{
+		{"standup every day 9am", "en", "standup 9am", "FREQ=DAILY", ""},
+		{"Gym every Monday 7am until June", "en", "Gym 7am", "FREQ=WEEKLY;BYDAY=MO", "2030-05-31"},
+		{"review every other week 10:00", "en", "review 10:00", "FREQ=WEEKLY;INTERVAL=2", ""},
+		{"standup weekdays 9:15 for 6 weeks", "en", "standup 9:15", "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR", "for 6 weeks"},
+		{"rent every month 9am until 2030-12-31", "en", "rent 9am", "FREQ=MONTHLY", "2030-12-31"},
+		{"water plants every 3 days 8am", "en", "water plants 8am", "FREQ=DAILY;INTERVAL=3", ""},
+		{"weekly sync every tuesday 10:00, until March 1st", "en", "weekly sync 10:00,", "FREQ=WEEKLY;BYDAY=TU", "2031-03-01"},
+		{"retro fortnightly 4pm till 15 June 2031", "en", "retro 4pm", "FREQ=WEEKLY;INTERVAL=2", "2031-06-15"},
+		{"pay rent every month on the 1st at 9am", "en", "pay rent at 9am", "FREQ=MONTHLY;BYMONTHDAY=1", ""},
+		{"invoices monthly on the 15th until June", "en", "invoices", "FREQ=MONTHLY;BYMONTHDAY=15", "2030-05-31"},
+		{"gym every tuesday and thursday at 7pm", "en", "gym at 7pm", "FREQ=WEEKLY;BYDAY=TU,TH", ""},
+		{"run every Monday, Wednesday and Friday 7am", "en", "run 7am", "FREQ=WEEKLY;BYDAY=MO,WE,FR", ""},
+
+		{"reunião toda segunda 10:00 até junho", "pt", "reunião 10:00", "FREQ=WEEKLY;BYDAY=MO", "2030-05-31"},
+		{"aula todas as terças-feiras 18:00 por 4 semanas", "pt", "aula 18:00", "FREQ=WEEKLY;BYDAY=TU", "for 4 weeks"},
+		{"ginásio toda terça e quinta 19:00", "pt", "ginásio 19:00", "FREQ=WEEKLY;BYDAY=TU,TH", ""},
+		{"café nos dias úteis 8:00", "pt", "café 8:00", "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR", ""},
+		{"ioga a cada duas semanas 19:00 até 20 de maio", "pt", "ioga 19:00", "FREQ=WEEKLY;INTERVAL=2", "2030-05-20"},
+		{"renda todo mês no dia 5 às 9h até o dia 20 de maio", "pt", "renda às 9h", "FREQ=MONTHLY;BYMONTHDAY=5", "2030-05-20"},
+		{"standup every day 9am", "pt", "standup 9am", "FREQ=DAILY", ""}, // English always works
+	}
+	for _, tt := range tests {
+		rest, rep, ok := ExtractRepeat(tt.input, now, tt.lang)
+		if !ok {
+			t.Errorf("ExtractRepeat(%q) found no repeat", tt.input)
+			continue
+		}
+		if rest != tt.rest || rep.RRule() != tt.rule || rep.End != tt.end {
+			t.Errorf("ExtractRepeat(%q) = %q, %s, end %q; want %q, %s, end %q", tt.input, rest, rep.RRule(), rep.End, tt.rest, tt.rule, tt.end)
+		}
+	}
+}
+
+func TestExtractRepeatLeavesOtherSentences(t *testing.T) {
+	now := time.Date(2030, 3, 4, 12, 0, 0, 0, time.UTC)
+	for _, input := range []string{
+		"dentist tomorrow 9am",
+		"lunch for 2 hours at noon",
+		"everyday carry review friday", // not "every day"
+		"toda segunda 10:00",           // Portuguese only when asked for
+	} {
+		if rest, rep, ok := ExtractRepeat(input, now, "en"); ok {
+			t.Errorf("ExtractRepeat(%q) = %q, %+v; want no repeat", input, rest, rep)
+		}
+	}
+	if _, _, ok := ExtractRepeat("every 0 days 9am", now, "en"); ok {
+		t.Error("every 0 days should not repeat")
+	}
+}
+
+func TestExtractRepeatSkipsImpossibleEnd(t *testing.T) {
+	now := time.Date(2030, 3, 4, 12, 0, 0, 0, time.UTC)
+	rest, rep, ok := ExtractRepeat("gym every day 7am until June 31", now, "en")
+	if !ok || rep.End != "" || rest != "gym 7am until June 31" {
+		t.Errorf("got %q, %+v, %v; want the bad end left in the text", rest, rep, ok)
+	}
+}
+
+func TestRepeatNext(t *testing.T) {
+	start := time.Date(2030, 1, 31, 9, 0, 0, 0, time.UTC) // a Thursday
+	tests := []struct {
+		rep  Repeat
+		want time.Time
+	}{
+		{Repeat{Freq: "DAILY"}, time.Date(2030, 2, 1, 9, 0, 0, 0, time.UTC)},
+		{Repeat{Freq: "DAILY", Interval: 3}, time.Date(2030, 2, 3, 9, 0, 0, 0, time.UTC)},
+		{Repeat{Freq: "WEEKLY"}, time.Date(2030, 2, 7, 9, 0, 0, 0, time.UTC)},
+		{Repeat{Freq: "WEEKLY", Weekdays: []time.Weekday{time.Tuesday, time.Thursday}}, time.Date(2030, 2, 5, 9, 0, 0, 0, time.UTC)},
+		{Repeat{Freq: "WEEKLY", Interval: 2, Weekdays: []time.Weekday{time.Tuesday, time.Thursday}}, time.Date(2030, 2, 12, 9, 0, 0, 0, time.UTC)},
+		{Repeat{Freq: "MONTHLY"}, time.Date(2030, 3, 31, 9, 0, 0, 0, time.UTC)}, // no February 31
+		{Repeat{Freq: "YEARLY"}, time.Date(2031, 1, 31, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := tt.rep.Next(start); !got.Equal(tt.want) {
+			t.Errorf("%s: Next(%v) = %v, want %v", tt.rep.RRule(), start, got, tt.want)
+		}
+	}
+}
+
+func TestRepeatSpans(t *testing.T) {
+	text := "gym every other monday 7am until June 15, 2031 and yoga friday"
+	spans := RepeatSpans(text, "")
+	var got []string
+	for _, sp := range spans {
+		got = append(got, text[sp[0]:sp[1]])
+	}
+	if len(got) != 2 || got[0] != "every other monday" || got[1] != "until June 15, 2031" {
+		t.Errorf("RepeatSpans(%q) = %q", text, got)
+	}
+	if spans := RepeatSpans("aula toda terça semana sim, semana não", "pt"); len(spans) != 2 {
+		t.Errorf("expected the Portuguese phrases, got %v", spans)
+	}
+}
+
+func FuzzExtractRepeat(f *testing.F) {
+	for _, seed := range []string{
+		"gym every monday 7am until June", "standup weekdays 9:15 for 6 weeks", "every 99999999999999999999 days",
+		"toda segunda até 31 de fevereiro", "until until until", "every other week, until 2030-13-45",
+	} {
+		f.Add(seed)
+	}
+	now := time.Date(2030, 3, 4, 12, 0, 0, 0, time.UTC)
+	f.Fuzz(func(t *testing.T, input string) {
+		for _, lang := range []string{"en", "pt"} {
+			rest, rep, ok := ExtractRepeat(input, now, lang)
+			if !ok {
+				continue
+			}
+			if rep.Freq == "" || rep.Interval < 0 || len(rest) > len(input) {
+				t.Errorf("ExtractRepeat(%q, %s) = %q, %+v", input, lang, rest, rep)
+			}
+		}
+	})
+}
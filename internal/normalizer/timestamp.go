@@ -0,0 +1,121 @@
+package normalizer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"tempus/internal/constants"
+)
+
+// timestampLayouts are the absolute date-time forms ParseTimestamp accepts:
+// RFC 3339 and its common relaxations (a space for the T, no seconds, a
+// +0100 offset, a space before the offset). Fractional seconds are always
+// accepted after the seconds.
+var timestampLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04Z0700",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04Z07:00",
+	"2006-01-02 15:04:05Z0700",
+	"2006-01-02 15:04Z0700",
+	"2006-01-02 15:04:05 Z07:00",
+	"2006-01-02 15:04 Z07:00",
+	"2006-01-02 15:04:05 Z0700",
+	"2006-01-02 15:04 Z0700",
+}
+
+// zoneLocalLayouts are read in the zone of a "[Area/City]" suffix when the
+// value carries no offset of its own.
+var zoneLocalLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	constants.DateTimeFormatISOSeconds,
+	constants.DateTimeFormatISO,
+}
+
+var (
+	epochRe      = regexp.MustCompile(`^@?(-?\d+)$`)
+	zoneSuffixRe = regexp.MustCompile(`^(.*?)\s*\[([A-Za-z][A-Za-z0-9_+\-/]*)\]$`)
+)
+
+// ParseTimestamp reads an absolute instant:
+//   - RFC 3339, e.g. "2025-12-16T10:00:00+01:00" or "2025-12-16T09:00:00Z",
+//     also with a space for the T, without seconds or with a +0100 offset;
+//   - any of those, or a plain "2025-12-16T10:00", followed by an IANA zone
+//     in brackets (RFC 9557): "2025-12-16T10:00+01:00[Europe/Madrid]";
+//   - unix epoch seconds (10 digits) or milliseconds (13 digits), or any
+//     number of seconds after an @: "@1765875600".
+//
+// zone is the bracketed IANA name, or "" when there is none. ok is false for
+// anything else, including the plain "YYYY-MM-DD HH:MM" callers read in a
+// timezone of their own.
+func ParseTimestamp(input string) (t time.Time, zone string, ok bool) {
+	s := strings.TrimSpace(input)
+	if m := epochRe.FindStringSubmatch(s); m != nil {
+		n, err := strconv.ParseInt(m[1], 10, 64)
+		switch {
+		case err != nil:
+			return time.Time{}, "", false
+		case strings.HasPrefix(s, "@"), len(m[1]) == 10:
+			return time.Unix(n, 0).UTC(), "", true
+		case len(m[1]) == 13:
+			return time.UnixMilli(n).UTC(), "", true
+		}
+		return time.Time{}, "", false
+	}
+
+	var loc *time.Location
+	if m := zoneSuffixRe.FindStringSubmatch(s); m != nil {
+		l, err := time.LoadLocation(m[2])
+		if err != nil {
+			return time.Time{}, "", false
+		}
+		s, zone, loc = m[1], m[2], l
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, zone, true
+		}
+	}
+	if loc != nil {
+		for _, layout := range zoneLocalLayouts {
+			if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+				return t, zone, true
+			}
+		}
+	}
+	return time.Time{}, "", false
+}
+
+// RewriteTimestamp turns an absolute instant ParseTimestamp accepts into the
+// "YYYY-MM-DD HH:MM" (or, with dateOnly, "YYYY-MM-DD") wall clock of tz, so
+// the usual layouts can read it. With no tz it uses the value's bracketed
+// zone, and failing that UTC, where zone-less events are written. zone is the
+// zone the result is in ("" for UTC without a bracketed zone). ok is false
+// when input is not an absolute instant or tz cannot be loaded.
+func RewriteTimestamp(input, tz string, dateOnly bool) (out, zone string, ok bool) {
+	t, zone, ok := ParseTimestamp(input)
+	if !ok {
+		return "", "", false
+	}
+	if tz = strings.TrimSpace(tz); tz != "" {
+		zone = tz
+	}
+	loc := time.UTC
+	if zone != "" {
+		l, err := time.LoadLocation(zone)
+		if err != nil {
+			return "", "", false
+		}
+		loc = l
+	}
+	layout := constants.DateTimeFormatISO
+	if dateOnly {
+		layout = constants.DateFormatISO
+	}
+	return t.In(loc).Format(layout), zone, true
+}
@@ -0,0 +1,69 @@
+package normalizer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		input, wantUTC, wantZone string
+	}{
+		{"2025-12-16T10:00:00+01:00", "2025-12-16T09:00:00Z", ""},
+		{"2025-12-16T09:00:00Z", "2025-12-16T09:00:00Z", ""},
+		{"2025-12-16T09:00:00.250Z", "2025-12-16T09:00:00Z", ""},
+		{"2025-12-16T10:00+01:00", "2025-12-16T09:00:00Z", ""},
+		{"2025-12-16 10:00+01:00", "2025-12-16T09:00:00Z", ""},
+		{"2025-12-16 10:00 -0500", "2025-12-16T15:00:00Z", ""},
+		{"2025-12-16T10:00:00+01:00[Europe/Madrid]", "2025-12-16T09:00:00Z", "Europe/Madrid"},
+		{"2025-12-16T10:00[America/New_York]", "2025-12-16T15:00:00Z", "America/New_York"},
+		{"1765875600", "2025-12-16T09:00:00Z", ""},
+		{"1765875600000", "2025-12-16T09:00:00Z", ""},
+		{"@1765875600", "2025-12-16T09:00:00Z", ""},
+	}
+	for _, tt := range tests {
+		got, zone, ok := ParseTimestamp(tt.input)
+		if !ok {
+			t.Errorf("ParseTimestamp(%q) not recognized", tt.input)
+			continue
+		}
+		if s := got.UTC().Truncate(time.Second).Format(time.RFC3339); s != tt.wantUTC || zone != tt.wantZone {
+			t.Errorf("ParseTimestamp(%q) = %s [%s], want %s [%s]", tt.input, s, zone, tt.wantUTC, tt.wantZone)
+		}
+	}
+}
+
+func TestParseTimestampLeavesOtherInputAlone(t *testing.T) {
+	for _, input := range []string{
+		"", "2025-12-16 10:00", "2025-12-16", "10:00", "90", "1h30m", "20251216",
+		"2025-12-16T10:00[Mars/Olympus]", "tomorrow 09:30",
+	} {
+		if _, _, ok := ParseTimestamp(input); ok {
+			t.Errorf("ParseTimestamp(%q) should not be an absolute timestamp", input)
+		}
+	}
+}
+
+func TestRewriteTimestamp(t *testing.T) {
+	tests := []struct {
+		input, tz      string
+		dateOnly       bool
+		want, wantZone string
+	}{
+		{"2025-12-16T10:00:00+01:00", "", false, "2025-12-16 09:00", ""},
+		{"2025-12-16T10:00:00+01:00", "America/New_York", false, "2025-12-16 04:00", "America/New_York"},
+		{"2025-12-16T10:00:00+01:00[Europe/Madrid]", "", false, "2025-12-16 10:00", "Europe/Madrid"},
+		{"2025-12-16T10:00:00+01:00[Europe/Madrid]", "UTC", false, "2025-12-16 09:00", "UTC"},
+		{"2025-12-16T23:30:00-05:00", "", true, "2025-12-17", ""},
+		{"@1765875600", "Europe/Dublin", false, "2025-12-16 09:00", "Europe/Dublin"},
+	}
+	for _, tt := range tests {
+		got, zone, ok := RewriteTimestamp(tt.input, tt.tz, tt.dateOnly)
+		if !ok || got != tt.want || zone != tt.wantZone {
+			t.Errorf("RewriteTimestamp(%q, %q) = %q, %q, %v; want %q, %q", tt.input, tt.tz, got, zone, ok, tt.want, tt.wantZone)
+		}
+	}
+	if _, _, ok := RewriteTimestamp("2025-12-16 10:00", "", false); ok {
+		t.Error("a plain date-time should be left to the caller")
+	}
+}
@@ -0,0 +1,73 @@
+package normalizer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// weekDateRe matches an ISO 8601 week date with an optional day and time:
+// "2026-W05", "2026-W05-2", "2026-W05-Tue 10:00", "2026W05".
+var weekDateRe = regexp.MustCompile(`^(\d{4})-?w(\d{1,2})(?:-([1-7]|[a-z]+))?(?:\s+(\d{1,2}:\d{2}))?$`)
+
+var weekdayNames = map[string]time.Weekday{
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+	"sun": time.Sunday, "sunday": time.Sunday,
+}
+
+// ExpandWeekDate rewrites a week date such as "2026-W05-Tue 10:00" as
+// "YYYY-MM-DD HH:MM" (or "YYYY-MM-DD" without a time). Weeks are numbered as
+// in ISO 8601. A numbered day is ISO too (1 is Monday, 7 Sunday), but a named
+// day is taken from the week as the locale lays it out, starting on
+// weekStart: with Sunday weeks, "2026-W05-Sun" is the day before the ISO
+// Monday. Without a day the week's first day is meant. ok is false when input
+// is not a week date or names a week the year does not have.
+func ExpandWeekDate(input string, weekStart time.Weekday) (string, bool) {
+	m := weekDateRe.FindStringSubmatch(strings.Join(strings.Fields(strings.ToLower(input)), " "))
+	if m == nil {
+		return "", false
+	}
+	year, _ := strconv.Atoi(m[1])
+	week, _ := strconv.Atoi(m[2])
+	if _, last := time.Date(year, time.December, 28, 0, 0, 0, 0, time.UTC).ISOWeek(); week < 1 || week > last {
+		return "", false
+	}
+
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	monday := jan4.AddDate(0, 0, (week-1)*7-(int(jan4.Weekday())+6)%7)
+	first := monday.AddDate(0, 0, -((int(time.Monday) - int(weekStart) + 7) % 7))
+
+	day := first
+	if d, err := strconv.Atoi(m[3]); err == nil {
+		day = monday.AddDate(0, 0, d-1)
+	} else if m[3] != "" {
+		wd, ok := weekdayNames[m[3]]
+		if !ok {
+			return "", false
+		}
+		day = first.AddDate(0, 0, (int(wd)-int(weekStart)+7)%7)
+	}
+	return formatRelative(day, m[4]), true
+}
+
+// AddBusinessDays moves day n working days (Monday to Friday) ahead, or back
+// when n is negative; from a weekend, +1 is the next Monday.
+func AddBusinessDays(day time.Time, n int) time.Time {
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for n > 0 {
+		day = day.AddDate(0, 0, step)
+		if wd := day.Weekday(); wd != time.Saturday && wd != time.Sunday {
+			n--
+		}
+	}
+	return day
+}
@@ -0,0 +1,67 @@
+package normalizer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpandWeekDate(t *testing.T) {
+	tests := []struct {
+		input string
+		start time.Weekday
+		want  string
+	}{
+		{"2026-W05-Tue 10:00", time.Monday, "2026-01-27 10:00"},
+		{"2026-W05-2 9:30", time.Monday, "2026-01-27 09:30"},
+		{"2026-W05", time.Monday, "2026-01-26"},
+		{"2026w05-fri", time.Monday, "2026-01-30"},
+		{"2026-W01-Mon", time.Monday, "2025-12-29"}, // week 1 holds the year's first Thursday
+		{"2026-W53-4", time.Monday, "2026-12-31"},   // 2026 has 53 weeks
+
+		// Sunday weeks: the week starts the day before the ISO Monday.
+		{"2026-W05", time.Sunday, "2026-01-25"},
+		{"2026-W05-Sun", time.Sunday, "2026-01-25"},
+		{"2026-W05-Sun", time.Monday, "2026-02-01"},
+		{"2026-W05-Tue", time.Sunday, "2026-01-27"},
+		{"2026-W05-7", time.Sunday, "2026-02-01"}, // numbered days stay ISO
+		{"2026-W05-Sat", time.Saturday, "2026-01-24"},
+	}
+	for _, tt := range tests {
+		got, ok := ExpandWeekDate(tt.input, tt.start)
+		if !ok || got != tt.want {
+			t.Errorf("ExpandWeekDate(%q, %s) = %q, %v; want %q", tt.input, tt.start, got, ok, tt.want)
+		}
+	}
+}
+
+func TestExpandWeekDateRejects(t *testing.T) {
+	for _, input := range []string{
+		"", "2026-01-27", "+5bd", "2026-W00", "2025-W53", "2026-W05-8", "2026-W05-someday", "W05-Tue",
+	} {
+		if got, ok := ExpandWeekDate(input, time.Monday); ok {
+			t.Errorf("ExpandWeekDate(%q) = %q, want no expansion", input, got)
+		}
+	}
+}
+
+func TestAddBusinessDays(t *testing.T) {
+	fri := time.Date(2026, 1, 30, 9, 0, 0, 0, time.UTC)
+	sat := fri.AddDate(0, 0, 1)
+	tests := []struct {
+		from time.Time
+		n    int
+		want string
+	}{
+		{fri, 1, "2026-02-02"},
+		{fri, 5, "2026-02-06"},
+		{fri, 0, "2026-01-30"},
+		{fri, -1, "2026-01-29"},
+		{sat, 1, "2026-02-02"},
+		{sat, -1, "2026-01-30"},
+	}
+	for _, tt := range tests {
+		if got := AddBusinessDays(tt.from, tt.n).Format("2006-01-02"); got != tt.want {
+			t.Errorf("AddBusinessDays(%s, %d) = %s, want %s", tt.from.Format("Mon 2006-01-02"), tt.n, got, tt.want)
+		}
+	}
+}
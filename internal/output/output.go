@@ -0,0 +1,191 @@
+// Package output controls how tempus talks to the terminal: verbosity,
+// emoji status prefixes and ANSI color.
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Level is the verbosity of human-facing messages.
+type Level int
+
+const (
+	// LevelQuiet shows only warnings and errors.
+	LevelQuiet Level = iota - 1
+	// LevelNormal is the default.
+	LevelNormal
+	// LevelVerbose adds progress and diagnostic details.
+	LevelVerbose
+)
+
+// Options are the user's output preferences (the global --quiet, --verbose,
+// --no-emoji, --no-color and --output-format flags).
+type Options struct {
+	Quiet   bool
+	Verbose bool
+	NoEmoji bool
+	NoColor bool
+	// JSON keeps stdout for a command's JSON document: status lines meant
+	// for stdout go to stderr instead.
+	JSON bool
+}
+
+var (
+	level    = LevelNormal
+	emoji    = true
+	noColor  = false
+	jsonMode = false
+)
+
+// Configure applies opts. NO_COLOR (https://no-color.org) disables color as
+// well; TEMPUS_NO_EMOJI=1 is the environment equivalent of --no-emoji.
+func Configure(opts Options) {
+	level = LevelNormal
+	switch {
+	case opts.Quiet:
+		level = LevelQuiet
+	case opts.Verbose:
+		level = LevelVerbose
+	}
+	emoji = !opts.NoEmoji && os.Getenv("TEMPUS_NO_EMOJI") == ""
+	noColor = opts.NoColor || os.Getenv("NO_COLOR") != ""
+	jsonMode = opts.JSON
+}
+
+// Quiet reports whether only warnings and errors should be shown.
+func Quiet() bool { return level == LevelQuiet }
+
+// Verbose reports whether extra details were requested.
+func Verbose() bool { return level == LevelVerbose }
+
+// Emoji reports whether status lines carry emoji prefixes.
+func Emoji() bool { return emoji }
+
+// JSON reports whether commands should print JSON documents on stdout.
+func JSON() bool { return jsonMode }
+
+const (
+	green  = "\033[32m"
+	yellow = "\033[33m"
+	red    = "\033[31m"
+	dim    = "\033[2m"
+	reset  = "\033[0m"
+)
+
+// colorFor reports whether w is a terminal that should get ANSI colors.
+func colorFor(w io.Writer) bool {
+	if noColor {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Prefix returns "icon " for a status line, or "" in plain mode.
+func Prefix(icon string) string {
+	if !emoji || icon == "" {
+		return ""
+	}
+	return icon + " "
+}
+
+func write(w io.Writer, icon, color, format string, a []any) {
+	if f, ok := w.(*os.File); ok && f == os.Stdout && jsonMode {
+		w = os.Stderr
+	}
+	msg := fmt.Sprintf(format, a...)
+	// Blank lines before the message go before the icon too.
+	trimmed := strings.TrimLeft(msg, "\n")
+	lead := msg[:len(msg)-len(trimmed)]
+	msg = trimmed
+	if color != "" && colorFor(w) {
+		// Color the first line only so multi-line details stay readable.
+		first, rest, cut := strings.Cut(msg, "\n")
+		msg = color + first + reset
+		if cut {
+			msg += "\n" + rest
+		}
+	}
+	_, _ = io.WriteString(w, lead+Prefix(icon)+msg)
+}
+
+// OK writes a success line ("✅ ..."); hidden with --quiet.
+func OK(w io.Writer, format string, a ...any) {
+	if level > LevelQuiet {
+		write(w, "✅", green, format, a)
+	}
+}
+
+// Error writes an error line ("❌ ..."); always shown.
+func Error(w io.Writer, format string, a ...any) {
+	write(w, "❌", red, format, a)
+}
+
+// Warn writes a warning line ("⚠️  ..."); always shown.
+func Warn(w io.Writer, format string, a ...any) {
+	write(w, "⚠️ ", yellow, format, a)
+}
+
+// Info writes an informational line with its own icon; hidden with --quiet.
+func Info(w io.Writer, icon, format string, a ...any) {
+	if level > LevelQuiet {
+		write(w, icon, "", format, a)
+	}
+}
+
+// Detail writes a line only with --verbose.
+func Detail(w io.Writer, format string, a ...any) {
+	if level >= LevelVerbose {
+		write(w, "", dim, format, a)
+	}
+}
+
+// Strip removes a leading emoji (with its variation selector and spacing)
+// from s, so prebuilt lines such as "✅ Done" read "Done" in plain mode.
+func Strip(s string) string {
+	trimmed := s
+	for trimmed != "" {
+		r, size := utf8.DecodeRuneInString(trimmed)
+		if !isPictograph(r) {
+			break
+		}
+		trimmed = trimmed[size:]
+	}
+	if trimmed == s {
+		return s
+	}
+	return strings.TrimLeftFunc(trimmed, unicode.IsSpace)
+}
+
+// Text returns s unchanged, or without its leading emoji in plain mode.
+func Text(s string) string {
+	if emoji {
+		return s
+	}
+	return Strip(s)
+}
+
+func isPictograph(r rune) bool {
+	switch {
+	case r == 0xFE0F || r == 0x200D: // variation selector, zero-width joiner
+		return true
+	case r >= 0x1F000 && r <= 0x1FAFF: // emoji blocks
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // misc symbols, dingbats (✅ ❌ ⚠ ✏)
+		return true
+	case r >= 0x2190 && r <= 0x21FF: // arrows (↻ →)
+		return true
+	case r == 0x2139: // ℹ
+		return true
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package output
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestLevelsAndPlainMode(t *testing.T) {
+	t.Cleanup(func() { Configure(Options{}) })
+
+	var buf bytes.Buffer
+	Configure(Options{})
+	OK(&buf, "Created: %s\n", "a.ics")
+	Detail(&buf, "hidden\n")
+	if got := buf.String(); got != "✅ Created: a.ics\n" {
+		t.Errorf("normal output = %q", got)
+	}
+
+	buf.Reset()
+	Configure(Options{Quiet: true, NoEmoji: true})
+	OK(&buf, "Created\n")
+	Info(&buf, "👀", "Watching\n")
+	Warn(&buf, "overlap\n")
+	Error(&buf, "failed\n")
+	if got := buf.String(); got != "overlap\nfailed\n" {
+		t.Errorf("quiet plain output = %q", got)
+	}
+
+	buf.Reset()
+	Configure(Options{Verbose: true})
+	Detail(&buf, "loaded %d rows\n", 3)
+	if got := buf.String(); got != "loaded 3 rows\n" {
+		t.Errorf("verbose output = %q (buffers never get color)", got)
+	}
+}
+
+func TestEnvironmentDisablesEmojiAndColor(t *testing.T) {
+	t.Cleanup(func() { Configure(Options{}) })
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("TEMPUS_NO_EMOJI", "1")
+	Configure(Options{})
+	if Emoji() || !noColor {
+		t.Errorf("expected env to disable emoji and color, got emoji=%v noColor=%v", Emoji(), noColor)
+	}
+	if got := Text("✅ Lint passed"); got != "Lint passed" {
+		t.Errorf("Text() = %q", got)
+	}
+}
+
+func TestJSONModeMovesStatusLinesToStderr(t *testing.T) {
+	t.Cleanup(func() { Configure(Options{}) })
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	t.Cleanup(func() { os.Stdout, os.Stderr = oldStdout, oldStderr })
+	stdoutR, stdoutW, _ := os.Pipe()
+	stderrR, stderrW, _ := os.Pipe()
+	os.Stdout, os.Stderr = stdoutW, stderrW
+
+	Configure(Options{JSON: true, NoEmoji: true})
+	OK(os.Stdout, "Created\n")
+	stdoutW.Close()
+	stderrW.Close()
+	stdout, _ := io.ReadAll(stdoutR)
+	stderr, _ := io.ReadAll(stderrR)
+	if len(stdout) != 0 || string(stderr) != "Created\n" {
+		t.Errorf("stdout = %q, stderr = %q; want the status line on stderr", stdout, stderr)
+	}
+}
+
+func TestStrip(t *testing.T) {
+	tests := map[string]string{
+		"✅ Created":       "Created",
+		"⚠️  overlap":     "overlap",
+		"ℹ️  note":        "note",
+		"👥 Ana, Luis":     "Ana, Luis",
+		"↻  tempus batch": "tempus batch",
+		"Plain text":      "Plain text",
+		"💼 Standup":       "Standup",
+	}
+	for in, want := range tests {
+		if got := Strip(in); got != want {
+			t.Errorf("Strip(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,465 @@
+// Package qr encodes short texts as QR codes (ISO/IEC 18004), enough to put
+// a single calendar event on a phone without a file transfer. Only byte mode
+// is used, with the smallest version (size) that holds the text.
+package qr
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// Level is the error correction level: how much of a damaged or dirty code
+// can still be read (about 7%, 15%, 25% and 30%).
+type Level int
+
+const (
+	L Level = iota
+	M
+	Q
+	H
+)
+
+// formatBits is the level as written in the format information.
+var formatBits = [...]int{L: 1, M: 0, Q: 3, H: 2}
+
+// Error correction codewords per block and number of blocks, by level and
+// version (index 0 is unused).
+var (
+	eccPerBlock = [4][41]int{
+		{-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+		{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+		{-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+		{-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	}
+	eccBlocks = [4][41]int{
+		{-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+		{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+		{-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+		{-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+	}
+)
+
+// Code is an encoded QR symbol.
+type Code struct {
+	Version int // 1 to 40
+	Size    int // modules per side, 17 + 4*Version
+
+	modules    []bool // dark modules, row by row
+	isFunction []bool // finder, timing, alignment and format modules
+}
+
+// Encode turns text into the smallest QR code that holds it at level.
+func Encode(text string, level Level) (*Code, error) {
+	data := []byte(text)
+	version := 1
+	for ; version <= 40; version++ {
+		if 4+countBits(version)+8*len(data) <= dataCodewords(version, level)*8 {
+			break
+		}
+	}
+	if version > 40 {
+		return nil, fmt.Errorf("text too long for a QR code: %d bytes, at most %d", len(data), Capacity(level))
+	}
+
+	var bb bitBuffer
+	bb.append(0b0100, 4) // byte mode
+	bb.append(len(data), countBits(version))
+	for _, b := range data {
+		bb.append(int(b), 8)
+	}
+	capacity := dataCodewords(version, level) * 8
+	bb.append(0, min(4, capacity-len(bb))) // terminator
+	bb.append(0, (8-len(bb)%8)%8)
+	for pad := 0xEC; len(bb) < capacity; pad ^= 0xEC ^ 0x11 {
+		bb.append(pad, 8)
+	}
+	codewords := make([]byte, len(bb)/8)
+	for i, bit := range bb {
+		if bit {
+			codewords[i>>3] |= 1 << (7 - i&7)
+		}
+	}
+
+	size := 17 + 4*version
+	c := &Code{Version: version, Size: size, modules: make([]bool, size*size), isFunction: make([]bool, size*size)}
+	c.drawFunctionPatterns(level)
+	c.drawCodewords(addECCAndInterleave(codewords, version, level))
+
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormatBits(level, mask)
+		if p := c.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		c.applyMask(mask) // XOR again to undo
+	}
+	c.applyMask(best)
+	c.drawFormatBits(level, best)
+	return c, nil
+}
+
+// Capacity is the most bytes a QR code can hold at level.
+func Capacity(level Level) int {
+	return dataCodewords(40, level) - 3 // mode and 16-bit count
+}
+
+// Dark reports whether the module at column x, row y is dark. Coordinates
+// outside the symbol (the quiet zone) are light.
+func (c *Code) Dark(x, y int) bool {
+	return x >= 0 && y >= 0 && x < c.Size && y < c.Size && c.modules[y*c.Size+x]
+}
+
+// Image renders the code with scale pixels per module and the 4-module
+// light border scanners need.
+func (c *Code) Image(scale int) image.Image {
+	const quiet = 4
+	side := (c.Size + 2*quiet) * scale
+	img := image.NewGray(image.Rect(0, 0, side, side))
+	for py := 0; py < side; py++ {
+		for px := 0; px < side; px++ {
+			v := color.Gray{Y: 255}
+			if c.Dark(px/scale-quiet, py/scale-quiet) {
+				v.Y = 0
+			}
+			img.SetGray(px, py, v)
+		}
+	}
+	return img
+}
+
+func countBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// rawDataModules is the number of modules left for data and error
+// correction once the function patterns are drawn.
+func rawDataModules(version int) int {
+	n := (16*version+128)*version + 64
+	if version >= 2 {
+		align := version/7 + 2
+		n -= (25*align-10)*align - 55
+		if version >= 7 {
+			n -= 36
+		}
+	}
+	return n
+}
+
+func dataCodewords(version int, level Level) int {
+	return rawDataModules(version)/8 - eccPerBlock[level][version]*eccBlocks[level][version]
+}
+
+// addECCAndInterleave splits data into blocks, appends each block's
+// Reed-Solomon codewords and interleaves the blocks.
+func addECCAndInterleave(data []byte, version int, level Level) []byte {
+	numBlocks := eccBlocks[level][version]
+	eccLen := eccPerBlock[level][version]
+	raw := rawDataModules(version) / 8
+	numShort := numBlocks - raw%numBlocks
+	shortLen := raw / numBlocks
+
+	divisor := rsDivisor(eccLen)
+	blocks := make([][]byte, numBlocks)
+	k := 0
+	for i := range blocks {
+		n := shortLen - eccLen
+		if i >= numShort {
+			n++
+		}
+		block := append([]byte(nil), data[k:k+n]...)
+		k += n
+		ecc := rsRemainder(block, divisor)
+		if i < numShort {
+			block = append(block, 0) // padding, skipped when interleaving
+		}
+		blocks[i] = append(block, ecc...)
+	}
+
+	out := make([]byte, 0, raw)
+	for i := range blocks[0] {
+		for j, block := range blocks {
+			if i != shortLen-eccLen || j >= numShort {
+				out = append(out, block[i])
+			}
+		}
+	}
+	return out
+}
+
+func (c *Code) set(x, y int, dark bool) {
+	c.modules[y*c.Size+x] = dark
+	c.isFunction[y*c.Size+x] = true
+}
+
+func (c *Code) drawFunctionPatterns(level Level) {
+	for i := 0; i < c.Size; i++ {
+		c.set(6, i, i%2 == 0)
+		c.set(i, 6, i%2 == 0)
+	}
+	c.drawFinder(3, 3)
+	c.drawFinder(c.Size-4, 3)
+	c.drawFinder(3, c.Size-4)
+
+	pos := alignmentPositions(c.Version, c.Size)
+	last := len(pos) - 1
+	for i := range pos {
+		for j := range pos {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue // under a finder pattern
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					c.set(pos[i]+dx, pos[j]+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	c.drawFormatBits(level, 0) // reserve the area; the mask is chosen later
+	if c.Version >= 7 {
+		rem := c.Version
+		for i := 0; i < 12; i++ {
+			rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+		}
+		bits := c.Version<<12 | rem
+		for i := 0; i < 18; i++ {
+			a, b := c.Size-11+i%3, i/3
+			c.set(a, b, bits>>i&1 != 0)
+			c.set(b, a, bits>>i&1 != 0)
+		}
+	}
+}
+
+// drawFinder draws a finder pattern centred on x, y with its separator.
+func (c *Code) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx >= 0 && xx < c.Size && yy >= 0 && yy < c.Size {
+				d := max(abs(dx), abs(dy))
+				c.set(xx, yy, d != 2 && d != 4)
+			}
+		}
+	}
+}
+
+func alignmentPositions(version, size int) []int {
+	if version == 1 {
+		return nil
+	}
+	n := version/7 + 2
+	step := (version*8 + n*3 + 5) / (n*4 - 4) * 2
+	pos := make([]int, n)
+	pos[0] = 6
+	for i, p := n-1, size-7; i >= 1; i, p = i-1, p-step {
+		pos[i] = p
+	}
+	return pos
+}
+
+// formatInfo is the 15-bit format information for level and mask: five data
+// bits, a BCH(15,5) code and the fixed XOR mask.
+func formatInfo(level Level, mask int) int {
+	data := formatBits[level]<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+func (c *Code) drawFormatBits(level Level, mask int) {
+	bits := formatInfo(level, mask)
+	bit := func(i int) bool { return bits>>i&1 != 0 }
+	for i := 0; i <= 5; i++ {
+		c.set(8, i, bit(i))
+	}
+	c.set(8, 7, bit(6))
+	c.set(8, 8, bit(7))
+	c.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.set(14-i, 8, bit(i))
+	}
+	for i := 0; i < 8; i++ {
+		c.set(c.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.set(8, c.Size-15+i, bit(i))
+	}
+	c.set(8, c.Size-8, true) // the dark module
+}
+
+// drawCodewords places data in the zigzag order: two-module columns from the
+// right, alternately upwards and downwards, skipping the vertical timing
+// pattern.
+func (c *Code) drawCodewords(data []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert
+				}
+				if !c.isFunction[y*c.Size+x] && i < len(data)*8 {
+					c.modules[y*c.Size+x] = data[i>>3]>>(7-i&7)&1 != 0
+					i++
+				}
+			}
+		}
+	}
+}
+
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !c.isFunction[y*c.Size+x] {
+				c.modules[y*c.Size+x] = !c.modules[y*c.Size+x]
+			}
+		}
+	}
+}
+
+// finderLike is the 1:1:3:1:1 pattern with four light modules on one side
+// that the third penalty rule looks for, both ways round.
+var finderLike = [2][11]bool{
+	{true, false, true, true, true, false, true, false, false, false, false},
+	{false, false, false, false, true, false, true, true, true, false, true},
+}
+
+// penalty scores how hard the masked symbol is to read; Encode keeps the mask
+// with the lowest score.
+func (c *Code) penalty() int {
+	score, dark := 0, 0
+	line := make([]bool, c.Size)
+	for _, vertical := range []bool{false, true} {
+		for a := 0; a < c.Size; a++ {
+			for b := range line {
+				if vertical {
+					line[b] = c.modules[b*c.Size+a]
+				} else {
+					line[b] = c.modules[a*c.Size+b]
+				}
+			}
+			run := 1
+			for b := 1; b <= c.Size; b++ {
+				if b < c.Size && line[b] == line[b-1] {
+					run++
+					continue
+				}
+				if run >= 5 {
+					score += run - 2
+				}
+				run = 1
+			}
+			for b := 0; b+11 <= c.Size; b++ {
+				for _, p := range finderLike {
+					if [11]bool(line[b:b+11]) == p {
+						score += 40
+					}
+				}
+			}
+		}
+	}
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			v := c.modules[y*c.Size+x]
+			if v {
+				dark++
+			}
+			if x+1 < c.Size && y+1 < c.Size && v == c.modules[y*c.Size+x+1] &&
+				v == c.modules[(y+1)*c.Size+x] && v == c.modules[(y+1)*c.Size+x+1] {
+				score += 3
+			}
+		}
+	}
+	total := c.Size * c.Size
+	score += (abs(dark*20-total*10)+total-1)/total*10 - 10
+	return score
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+type bitBuffer []bool
+
+func (bb *bitBuffer) append(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*bb = append(*bb, v>>i&1 != 0)
+	}
+}
+
+// rsDivisor is the Reed-Solomon generator polynomial of the given degree over
+// GF(2^8), highest power first and without its leading 1.
+func rsDivisor(degree int) []byte {
+	out := make([]byte, degree)
+	out[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range out {
+			out[j] = gfMul(out[j], root)
+			if j+1 < len(out) {
+				out[j] ^= out[j+1]
+			}
+		}
+		root = gfMul(root, 0x02)
+	}
+	return out
+}
+
+// rsRemainder is the error correction for data: the remainder of dividing it
+// by divisor.
+func rsRemainder(data, divisor []byte) []byte {
+	out := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ out[0]
+		copy(out, out[1:])
+		out[len(out)-1] = 0
+		for i := range out {
+			out[i] ^= gfMul(divisor[i], factor)
+		}
+	}
+	return out
+}
+
+// gfMul multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1.
+func gfMul(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
@@ -0,0 +1,142 @@
+package qr
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// "HELLO WORLD" at 1-M, the worked example of the standard.
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := rsRemainder(data, rsDivisor(10)); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("ECC = %v, want %v", got, want)
+	}
+}
+
+func TestFormatAndVersionInfo(t *testing.T) {
+	for _, tc := range []struct {
+		level Level
+		mask  int
+		want  string
+	}{
+		{L, 0, "111011111000100"},
+		{L, 4, "110011000101111"},
+		{M, 0, "101010000010010"},
+		{H, 7, "000100000111011"},
+	} {
+		if got := fmt.Sprintf("%015b", formatInfo(tc.level, tc.mask)); got != tc.want {
+			t.Errorf("format info %d/%d = %s, want %s", tc.level, tc.mask, got, tc.want)
+		}
+	}
+
+	c, err := Encode(strings.Repeat("x", 200), L) // version 7 or more
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Version < 7 {
+		t.Fatalf("200 bytes fit version %d", c.Version)
+	}
+	// The version block under the top-right finder, read back.
+	bits := 0
+	for i := 17; i >= 0; i-- {
+		bits <<= 1
+		if c.Dark(c.Size-11+i%3, i/3) {
+			bits |= 1
+		}
+	}
+	if c.Version == 7 && bits != 0b000111110010010100 {
+		t.Errorf("version 7 info = %018b", bits)
+	}
+}
+
+func TestCapacityAndVersion(t *testing.T) {
+	for _, tc := range []struct {
+		level   Level
+		version int
+		bytes   int // byte-mode capacity from the standard's tables
+	}{
+		{L, 1, 17}, {M, 1, 14}, {Q, 1, 11}, {H, 1, 7},
+		{L, 10, 271}, {M, 10, 213}, {H, 10, 119}, {L, 25, 1273}, {M, 40, 2331}, {L, 40, 2953}, {H, 40, 1273},
+	} {
+		if got := (dataCodewords(tc.version, tc.level)*8 - 4 - countBits(tc.version)) / 8; got != tc.bytes {
+			t.Errorf("%d-%d holds %d bytes, want %d", tc.version, tc.level, got, tc.bytes)
+		}
+		c, err := Encode(strings.Repeat("a", tc.bytes), tc.level)
+		if err != nil || c.Version != tc.version || c.Size != 17+4*tc.version {
+			t.Errorf("%d bytes at level %d: err %v; want version %d", tc.bytes, tc.level, err, tc.version)
+		}
+	}
+	if _, err := Encode(strings.Repeat("a", Capacity(M)+1), M); err == nil {
+		t.Error("expected an error for text over the capacity")
+	}
+}
+
+func TestEncodeLayout(t *testing.T) {
+	c, err := Encode("BEGIN:VEVENT\r\nSUMMARY:Dentist\r\nEND:VEVENT", M)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Finder patterns: dark ring, light ring, dark 3x3 centre.
+	for _, corner := range [][2]int{{0, 0}, {c.Size - 7, 0}, {0, c.Size - 7}} {
+		for d := 0; d < 7; d++ {
+			x, y := corner[0], corner[1]
+			if !c.Dark(x+d, y) || !c.Dark(x, y+d) || c.Dark(x+1+min(d, 4), y+1) || !c.Dark(x+3, y+3) {
+				t.Fatalf("no finder pattern at %v", corner)
+			}
+		}
+	}
+	for i := 8; i < c.Size-8; i++ {
+		if c.Dark(i, 6) != (i%2 == 0) || c.Dark(6, i) != (i%2 == 0) {
+			t.Fatalf("timing pattern broken at %d", i)
+		}
+	}
+	if !c.Dark(8, c.Size-8) {
+		t.Error("missing dark module")
+	}
+
+	// Both copies of the format information agree and decode to level M.
+	var first, second int
+	for i := 14; i >= 0; i-- {
+		first, second = first<<1, second<<1
+		var a, b bool
+		switch {
+		case i <= 5:
+			a = c.Dark(8, i)
+		case i == 6:
+			a = c.Dark(8, 7)
+		case i == 7:
+			a = c.Dark(8, 8)
+		case i == 8:
+			a = c.Dark(7, 8)
+		default:
+			a = c.Dark(14-i, 8)
+		}
+		if i < 8 {
+			b = c.Dark(c.Size-1-i, 8)
+		} else {
+			b = c.Dark(8, c.Size-15+i)
+		}
+		if a {
+			first |= 1
+		}
+		if b {
+			second |= 1
+		}
+	}
+	if first != second || (first^0x5412)>>13 != formatBits[M] {
+		t.Errorf("format info %015b / %015b", first, second)
+	}
+
+	img := c.Image(2)
+	if side := img.Bounds().Dx(); side != (c.Size+8)*2 {
+		t.Errorf("image is %d px wide, want %d", side, (c.Size+8)*2)
+	}
+	if r, _, _, _ := img.At(0, 0).RGBA(); r == 0 {
+		t.Error("the quiet zone should be light")
+	}
+	if r, _, _, _ := img.At(8, 8).RGBA(); r != 0 {
+		t.Error("the finder corner should be dark")
+	}
+}
@@ -95,8 +95,8 @@ func normalizeTemplateMetadata(tmpl *DataDrivenTemplate, path string) error {
 	if tmpl.SchemaVersion == 0 {
 		tmpl.SchemaVersion = 1
 	}
-	if tmpl.SchemaVersion != 1 {
-		return fmt.Errorf("%s: unsupported schema_version %d", path, tmpl.SchemaVersion)
+	if err := checkSchemaVersion(tmpl.SchemaVersion); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
 	}
 	tmpl.Source = path
 	return nil
@@ -130,6 +130,22 @@ func ValidateDDTemplate(t *DataDrivenTemplate) error {
 		return err
 	}
 
+	if err := validateFieldTypes(t); err != nil {
+		return err
+	}
+
+	if err := validateFieldConstraints(t); err != nil {
+		return err
+	}
+
+	if err := validateEvents(t, fieldKeys); err != nil {
+		return err
+	}
+
+	if err := validateTemplateTexts(t); err != nil {
+		return err
+	}
+
 	if strings.TrimSpace(t.Output.SummaryTmpl) == "" {
 		return fmt.Errorf("template %q missing output.summary_tmpl", t.Name)
 	}
@@ -166,29 +182,86 @@ func buildFieldKeysMap(t *DataDrivenTemplate) (map[string]struct{}, error) {
 
 // validateOutputFields validates all output field references.
 func validateOutputFields(t *DataDrivenTemplate, fieldKeys map[string]struct{}) error {
+	return validateFieldMappings(t.Name, "output", t.Output, false, fieldKeys)
+}
+
+// validateFieldMappings validates the field references of one output block.
+// Related events may leave start_field empty to use output's.
+func validateFieldMappings(templateName, prefix string, out OutputTemplate, startOptional bool, fieldKeys map[string]struct{}) error {
 	fieldsToCheck := []struct {
 		label      string
 		key        string
 		allowEmpty bool
 	}{
-		{"output.start_field", t.Output.StartField, false},
-		{"output.end_field", t.Output.EndField, true},
-		{"output.duration_field", t.Output.DurationField, true},
-		{"output.start_tz_field", t.Output.StartTZField, true},
-		{"output.end_tz_field", t.Output.EndTZField, true},
-		{"output.rrule_field", t.Output.RRuleField, true},
-		{"output.exdates_field", t.Output.ExDatesField, true},
-		{"output.alarms_field", t.Output.AlarmsField, true},
+		{prefix + ".start_field", out.StartField, startOptional},
+		{prefix + ".end_field", out.EndField, true},
+		{prefix + ".duration_field", out.DurationField, true},
+		{prefix + ".start_tz_field", out.StartTZField, true},
+		{prefix + ".end_tz_field", out.EndTZField, true},
+		{prefix + ".rrule_field", out.RRuleField, true},
+		{prefix + ".exdates_field", out.ExDatesField, true},
+		{prefix + ".alarms_field", out.AlarmsField, true},
 	}
 
 	for _, field := range fieldsToCheck {
-		if err := validateFieldReference(t.Name, field.label, field.key, field.allowEmpty, fieldKeys); err != nil {
+		if err := validateFieldReference(templateName, field.label, field.key, field.allowEmpty, fieldKeys); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// validateEvents checks the related events of a multi-event template.
+func validateEvents(t *DataDrivenTemplate, fieldKeys map[string]struct{}) error {
+	if len(t.Events) > 0 && t.SchemaVersion < 3 {
+		return fmt.Errorf("template %q uses events, which need schema_version 3", t.Name)
+	}
+	for i, ev := range t.Events {
+		prefix := fmt.Sprintf("events[%d]", i)
+		if err := validateFieldMappings(t.Name, prefix, ev.OutputTemplate, true, fieldKeys); err != nil {
+			return err
+		}
+		if strings.TrimSpace(ev.SummaryTmpl) == "" {
+			return fmt.Errorf("template %q missing %s.summary_tmpl", t.Name, prefix)
+		}
+		if strings.TrimSpace(ev.Offset) != "" {
+			if _, _, err := parseOffset(ev.Offset); err != nil {
+				return fmt.Errorf("template %q %s.offset: %w", t.Name, prefix, err)
+			}
+		}
+		if strings.TrimSpace(ev.Duration) != "" {
+			if _, err := parseHumanDuration(ev.Duration); err != nil {
+				return fmt.Errorf("template %q %s.duration: %w", t.Name, prefix, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateTemplateTexts checks the syntax of the filename and text templates.
+func validateTemplateTexts(t *DataDrivenTemplate) error {
+	texts := []struct{ label, tmpl string }{
+		{"filename_tmpl", t.FilenameTemplate},
+		{"output.summary_tmpl", t.Output.SummaryTmpl},
+		{"output.location_tmpl", t.Output.LocationTmpl},
+		{"output.description_tmpl", t.Output.DescriptionTmpl},
+	}
+	for i, ev := range t.Events {
+		prefix := fmt.Sprintf("events[%d].", i)
+		texts = append(texts,
+			struct{ label, tmpl string }{prefix + "summary_tmpl", ev.SummaryTmpl},
+			struct{ label, tmpl string }{prefix + "location_tmpl", ev.LocationTmpl},
+			struct{ label, tmpl string }{prefix + "description_tmpl", ev.DescriptionTmpl},
+		)
+	}
+	for _, tx := range texts {
+		if err := CheckTmpl(tx.tmpl); err != nil {
+			return fmt.Errorf("template %q %s: %w", t.Name, tx.label, err)
+		}
+	}
+	return nil
+}
+
 // validateFieldReference validates a single field reference.
 func validateFieldReference(templateName, label, key string, allowEmpty bool, fieldKeys map[string]struct{}) error {
 	key = strings.TrimSpace(key)
@@ -3,7 +3,6 @@ package templates
 import (
 	"errors"
 	"fmt"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -18,68 +17,33 @@ import (
 // RenderTmpl is a tiny mustache-like renderer used for filenames and text.
 // Supported:
 //   - {{key}}
-//   - {{slug key}}
-//   - {{date key}}
+//   - {{slug key}}, {{date key}}, {{time key}}, {{upper key}}, {{lower key}}
+//   - {{key | default "text"}}  (fallback when key is empty)
+//   - {{add start_time duration}}, {{sub a b}}, {{mul a b}}, {{div a b}}
 //   - {{#key}} ... {{/key}}  (render block only if key is non-empty)
+//   - {{^key}} ... {{/key}}  (render block only if key is empty)
+//   - {{#if eq room "A"}} ... {{else}} ... {{/if}}  (eq, ne, lt, gt, not)
 //
-// NOTE: Go's regexp (RE2) doesn't support backreferences like \1, so we capture
-// the closing tag as a third group and compare it in code.
+// Malformed tags are left as written; use CheckTmpl to report them.
 func RenderTmpl(tmpl string, values map[string]string, _ *i18n.Translator) (string, error) {
 	if tmpl == "" {
 		return "", nil
 	}
-	out := tmpl
-
-	// Conditionals: {{#key}}...{{/key}}  (no backrefs)
-	condRe := regexp.MustCompile(`\{\{\#([a-zA-Z0-9_\-\.]+)\}\}([\s\S]*?)\{\{\/([a-zA-Z0-9_\-\.]+)\}\}`)
-	out = condRe.ReplaceAllStringFunc(out, func(m string) string {
-		sub := condRe.FindStringSubmatch(m)
-		if len(sub) < 4 {
-			return m
-		}
-		open, body, close := sub[1], sub[2], sub[3]
-		if open != close {
-			// mismatched tags, leave unchanged
-			return m
-		}
-		v := strings.TrimSpace(values[open])
-		if v == "" {
-			return ""
-		}
-		return simpleReplace(body, values)
-	})
-
-	// Simple replacements: {{key}} and {{slug key}}
-	out = simpleReplace(out, values)
-
-	return out, nil
-}
-
-func simpleReplace(s string, values map[string]string) string {
-	// {{date key}}
-	dateRe := regexp.MustCompile(`\{\{date\s+([a-zA-Z0-9_\-\.]+)\}\}`)
-	s = dateRe.ReplaceAllStringFunc(s, func(m string) string {
-		key := dateRe.FindStringSubmatch(m)[1]
-		return extractDate(values[key])
-	})
-
-	// {{slug key}}
-	slugRe := regexp.MustCompile(`\{\{slug\s+([a-zA-Z0-9_\-\.]+)\}\}`)
-	s = slugRe.ReplaceAllStringFunc(s, func(m string) string {
-		key := slugRe.FindStringSubmatch(m)[1]
-		return slugify(values[key])
-	})
-
-	// {{key}}
-	keyRe := regexp.MustCompile(`\{\{([a-zA-Z0-9_\-\.]+)\}\}`)
-	return keyRe.ReplaceAllStringFunc(s, func(m string) string {
-		key := keyRe.FindStringSubmatch(m)[1]
-		return values[key]
-	})
+	nodes, _ := parseTmpl(tmpl, false)
+	var sb strings.Builder
+	if err := renderNodes(&sb, nodes, values); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
 }
 
+// slugify backs the slug helper. Text with nothing to spell in ASCII (会議)
+// becomes "event-" and a short hash of it, so two such files don't collide.
 func slugify(s string) string {
-	return utils.Slugify(s)
+	if strings.TrimSpace(s) == "" {
+		return ""
+	}
+	return utils.SlugOrHash(s, "event")
 }
 
 func extractDate(value string) string {
@@ -109,9 +73,74 @@ func (tm *TemplateManager) renderDDToEvent(dd *DataDrivenTemplate, values map[st
 	if dd == nil {
 		return nil, errors.New("nil template")
 	}
+	return renderOutput(dd.Output, values, tr)
+}
 
-	out := dd.Output
+// renderDDEvents renders the related events of a multi-event template, in
+// the order they are declared.
+func renderDDEvents(dd *DataDrivenTemplate, values map[string]string, tr *i18n.Translator) ([]*calendar.Event, error) {
+	events := make([]*calendar.Event, 0, len(dd.Events))
+	for i, related := range dd.Events {
+		out := related.OutputTemplate
+		if strings.TrimSpace(out.StartField) == "" {
+			out.StartField = dd.Output.StartField
+		}
+		if strings.TrimSpace(out.StartTZField) == "" && strings.TrimSpace(out.EndTZField) == "" {
+			out.StartTZField = dd.Output.StartTZField
+			out.EndTZField = dd.Output.EndTZField
+		}
+		ev, err := renderOutput(out, values, tr)
+		if err != nil {
+			return nil, fmt.Errorf("events[%d]: %w", i, err)
+		}
+		if d := strings.TrimSpace(related.Duration); d != "" && out.EndField == "" && out.DurationField == "" && !ev.AllDay {
+			dur, err := parseHumanDuration(d)
+			if err != nil {
+				return nil, fmt.Errorf("events[%d]: invalid duration %q: %w", i, d, err)
+			}
+			ev.EndTime = ev.StartTime.Add(dur)
+		}
+		if o := strings.TrimSpace(related.Offset); o != "" {
+			days, dur, err := parseOffset(o)
+			if err != nil {
+				return nil, fmt.Errorf("events[%d]: %w", i, err)
+			}
+			ev.StartTime = ev.StartTime.AddDate(0, 0, days).Add(dur)
+			ev.EndTime = ev.EndTime.AddDate(0, 0, days).Add(dur)
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
 
+// parseOffset parses a signed shift such as "-1d", "-3h", "+1h30m" or
+// "2d12h" into whole days (applied on the calendar, so DST-safe) and the rest.
+func parseOffset(s string) (days int, rest time.Duration, err error) {
+	raw := s
+	s = strings.ToLower(strings.TrimSpace(s))
+	sign := 1
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign, s = -1, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	if d, after, ok := strings.Cut(s, "d"); ok {
+		if days, err = strconv.Atoi(strings.TrimSpace(d)); err != nil || days < 0 {
+			return 0, 0, fmt.Errorf("invalid offset %q", raw)
+		}
+		s = after
+	}
+	if strings.TrimSpace(s) != "" {
+		if rest, err = parseHumanDuration(s); err != nil {
+			return 0, 0, fmt.Errorf("invalid offset %q", raw)
+		}
+	}
+	return sign * days, time.Duration(sign) * rest, nil
+}
+
+// renderOutput builds one event from an output block and the user values.
+func renderOutput(out OutputTemplate, values map[string]string, tr *i18n.Translator) (*calendar.Event, error) {
 	// Resolve time zones
 	startTzName, endTzName := resolveTimezones(values, out)
 
@@ -128,9 +157,18 @@ func (tm *TemplateManager) renderDDToEvent(dd *DataDrivenTemplate, values map[st
 	}
 
 	// Build the event
-	summary, _ := RenderTmpl(out.SummaryTmpl, values, tr)
-	location, _ := RenderTmpl(out.LocationTmpl, values, tr)
-	description, _ := RenderTmpl(out.DescriptionTmpl, values, tr)
+	summary, err := RenderTmpl(out.SummaryTmpl, values, tr)
+	if err != nil {
+		return nil, fmt.Errorf("summary_tmpl: %w", err)
+	}
+	location, err := RenderTmpl(out.LocationTmpl, values, tr)
+	if err != nil {
+		return nil, fmt.Errorf("location_tmpl: %w", err)
+	}
+	description, err := RenderTmpl(out.DescriptionTmpl, values, tr)
+	if err != nil {
+		return nil, fmt.Errorf("description_tmpl: %w", err)
+	}
 
 	ev := calendar.NewEvent(summary, startTime, endTime)
 	ev.AllDay = allDay
@@ -150,7 +188,7 @@ func (tm *TemplateManager) renderDDToEvent(dd *DataDrivenTemplate, values map[st
 	}
 
 	// Apply metadata (categories, priority)
-	applyEventMetadata(ev, dd)
+	applyEventMetadata(ev, out)
 
 	// Apply recurrence rules (rrule, exdates, alarms)
 	if err := applyRecurrence(ev, out, values, startTime, allDay, startTzName, endTzName); err != nil {
@@ -243,8 +281,7 @@ func calculateEndTime(values map[string]string, out OutputTemplate, startTime ti
 }
 
 // applyEventMetadata applies categories and priority from the template to the event.
-func applyEventMetadata(ev *calendar.Event, dd *DataDrivenTemplate) {
-	out := dd.Output
+func applyEventMetadata(ev *calendar.Event, out OutputTemplate) {
 	for _, c := range out.Categories {
 		if strings.TrimSpace(c) != "" {
 			ev.AddCategory(c)
@@ -105,8 +105,8 @@ func TestRenderTmpl(t *testing.T) {
 	}
 }
 
-// TestSimpleReplace tests the simpleReplace function
-func TestSimpleReplace(t *testing.T) {
+// TestRenderTmplHelpers tests plain replacements and the slug/date helpers
+func TestRenderTmplHelpers(t *testing.T) {
 	tests := []struct {
 		name     string
 		input    string
@@ -125,6 +125,18 @@ func TestSimpleReplace(t *testing.T) {
 			values:   map[string]string{"title": testutil.EventTitleHelloWorld},
 			expected: testutil.TemplateHelloWorld,
 		},
+		{
+			name:     "slug transliterates",
+			input:    "{{slug title}}",
+			values:   map[string]string{"title": "Reunião na Coruña"},
+			expected: "reuniao-na-coruna",
+		},
+		{
+			name:     "slug of non-latin text",
+			input:    "{{slug title}}",
+			values:   map[string]string{"title": "会議"},
+			expected: "event-6f7de8e4",
+		},
 		{
 			name:     "date function",
 			input:    "{{date when}}",
@@ -147,9 +159,9 @@ func TestSimpleReplace(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := simpleReplace(tt.input, tt.values)
-			if result != tt.expected {
-				t.Errorf("simpleReplace() = %q, want %q", result, tt.expected)
+			result, err := RenderTmpl(tt.input, tt.values, nil)
+			if err != nil || result != tt.expected {
+				t.Errorf("RenderTmpl() = %q, %v, want %q", result, err, tt.expected)
 			}
 		})
 	}
@@ -1090,3 +1102,108 @@ func TestRenderDDToEventWithTemplatedFields(t *testing.T) {
 		t.Error("description should contain name")
 	}
 }
+
+func TestParseOffset(t *testing.T) {
+	tests := []struct {
+		in       string
+		wantDays int
+		wantRest time.Duration
+		wantErr  bool
+	}{
+		{"-1d", -1, 0, false},
+		{"-3h", 0, -3 * time.Hour, false},
+		{"+1h30m", 0, 90 * time.Minute, false},
+		{"2d12h", 2, 12 * time.Hour, false},
+		{"45", 0, 45 * time.Minute, false},
+		{"soon", 0, 0, true},
+		{"-xd", 0, 0, true},
+	}
+	for _, tt := range tests {
+		days, rest, err := parseOffset(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseOffset(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if days != tt.wantDays || rest != tt.wantRest {
+			t.Errorf("parseOffset(%q) = %d, %v; want %d, %v", tt.in, days, rest, tt.wantDays, tt.wantRest)
+		}
+	}
+}
+
+func TestGenerateEventsMultiEventTemplate(t *testing.T) {
+	tm := NewTemplateManager()
+	tm.RegisterDDTemplate(DataDrivenTemplate{
+		SchemaVersion: 3,
+		Name:          "trip",
+		Fields: []Field{
+			{Key: "flight", Type: "text", Required: true},
+			{Key: "departure", Type: "datetime", Required: true},
+			{Key: "arrival", Type: "datetime", Required: true},
+			{Key: "tz", Type: "timezone"},
+		},
+		Output: OutputTemplate{StartField: "departure", EndField: "arrival", StartTZField: "tz", SummaryTmpl: "{{flight}}"},
+		Events: []EventTemplate{
+			{OutputTemplate: OutputTemplate{SummaryTmpl: "Check in {{flight}}"}, Offset: "-1d", Duration: "15m"},
+			{OutputTemplate: OutputTemplate{StartField: "arrival", SummaryTmpl: "Hotel"}, Offset: "+1h"},
+		},
+	})
+	values := map[string]string{"flight": "IB3170", "departure": "2025-11-20 10:05", "arrival": "2025-11-20 12:40", "tz": testutil.TZEuropeMadrid}
+
+	events, err := tm.GenerateEvents("trip", values, newTestTranslator())
+	if err != nil {
+		t.Fatalf("GenerateEvents() failed: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected the flight and 2 related events, got %d", len(events))
+	}
+	want := []struct {
+		summary    string
+		start, end string
+	}{
+		{"IB3170", "2025-11-20 10:05", "2025-11-20 12:40"},
+		{"Check in IB3170", "2025-11-19 10:05", "2025-11-19 10:20"},
+		{"Hotel", "2025-11-20 13:40", "2025-11-20 14:40"},
+	}
+	for i, w := range want {
+		ev := events[i]
+		start, end := ev.StartTime.Format(testutil.TestDateFormatDateTime), ev.EndTime.Format(testutil.TestDateFormatDateTime)
+		if ev.Summary != w.summary || start != w.start || end != w.end {
+			t.Errorf("event %d = %q %s–%s, want %q %s–%s", i, ev.Summary, start, end, w.summary, w.start, w.end)
+		}
+		if ev.StartTZ != testutil.TZEuropeMadrid {
+			t.Errorf("event %d StartTZ = %q, want it inherited from output", i, ev.StartTZ)
+		}
+	}
+	if events[1].UID == events[0].UID {
+		t.Error("related events need their own UID")
+	}
+}
+
+func TestValidateDDTemplateEvents(t *testing.T) {
+	dd := DataDrivenTemplate{
+		SchemaVersion: 2,
+		Name:          "trip",
+		Fields:        []Field{{Key: "start", Type: "datetime", Required: true}},
+		Output:        OutputTemplate{StartField: "start", SummaryTmpl: "x"},
+		Events:        []EventTemplate{{OutputTemplate: OutputTemplate{SummaryTmpl: "y"}, Offset: "-1h"}},
+	}
+	if err := ValidateDDTemplate(&dd); err == nil || !strings.Contains(err.Error(), "schema_version 3") {
+		t.Errorf("expected events to need schema_version 3, got %v", err)
+	}
+
+	dd.SchemaVersion = 3
+	if err := ValidateDDTemplate(&dd); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, bad := range []EventTemplate{
+		{OutputTemplate: OutputTemplate{SummaryTmpl: "y", StartField: "missing"}},
+		{OutputTemplate: OutputTemplate{}},
+		{OutputTemplate: OutputTemplate{SummaryTmpl: "y"}, Offset: "later"},
+		{OutputTemplate: OutputTemplate{SummaryTmpl: "y"}, Duration: "long"},
+	} {
+		dd.Events = []EventTemplate{bad}
+		if err := ValidateDDTemplate(&dd); err == nil || !strings.Contains(err.Error(), "events[0]") {
+			t.Errorf("%+v: expected an events[0] error, got %v", bad, err)
+		}
+	}
+}
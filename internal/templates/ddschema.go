@@ -26,12 +26,24 @@ type OutputTemplate struct {
 	DescriptionTmpl string `json:"description_tmpl,omitempty" yaml:"description_tmpl,omitempty"`
 }
 
+// EventTemplate is one related event of a multi-event template (a flight's
+// check-in reminder, the ride to the airport...). It is rendered like output,
+// using output's start_field and timezone fields unless it sets its own, and
+// is then moved by Offset.
+type EventTemplate struct {
+	OutputTemplate `yaml:",inline"`
+
+	Offset   string `json:"offset,omitempty" yaml:"offset,omitempty"`     // shift from the mapped start, e.g. "-3h", "-1d", "+30m"
+	Duration string `json:"duration,omitempty" yaml:"duration,omitempty"` // fixed length when no end/duration field is mapped
+}
+
 type DataDrivenTemplate struct {
-	SchemaVersion    int            `json:"schema_version,omitempty" yaml:"schema_version,omitempty"`
-	Name             string         `json:"name" yaml:"name"`
-	Description      string         `json:"description,omitempty" yaml:"description,omitempty"`
-	FilenameTemplate string         `json:"filename_tmpl,omitempty" yaml:"filename_tmpl,omitempty"`
-	Fields           []Field        `json:"fields" yaml:"fields"`
-	Output           OutputTemplate `json:"output" yaml:"output"`
-	Source           string         `json:"-" yaml:"-"` // path where this template was loaded from
+	SchemaVersion    int             `json:"schema_version,omitempty" yaml:"schema_version,omitempty"`
+	Name             string          `json:"name" yaml:"name"`
+	Description      string          `json:"description,omitempty" yaml:"description,omitempty"`
+	FilenameTemplate string          `json:"filename_tmpl,omitempty" yaml:"filename_tmpl,omitempty"`
+	Fields           []Field         `json:"fields" yaml:"fields"`
+	Output           OutputTemplate  `json:"output" yaml:"output"`
+	Events           []EventTemplate `json:"events,omitempty" yaml:"events,omitempty"` // related events emitted with output (schema_version 3)
+	Source           string          `json:"-" yaml:"-"`                               // path where this template was loaded from
 }
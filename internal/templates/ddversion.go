@@ -0,0 +1,210 @@
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema versions understood by this build.
+//
+//	1: original format; field types are free-form labels.
+//	2: field types must be one of KnownFieldTypes, and the fields referenced by
+//	   output.duration_field / output.alarms_field are typed duration / alarms.
+//	3: adds events, related events rendered together with output.
+const (
+	MinSchemaVersion     = 1
+	CurrentSchemaVersion = 3
+)
+
+// KnownFieldTypes lists the field types accepted from schema version 2 on.
+var KnownFieldTypes = []string{"text", "datetime", "date", "timezone", "email", "number", "enum", "duration", "alarms"}
+
+// legacyFieldTypes maps spellings seen in v1 templates to their v2 type.
+var legacyFieldTypes = map[string]string{
+	"":               "text",
+	"string":         "text",
+	"str":            "text",
+	"textarea":       "text",
+	"date-time":      "datetime",
+	"datetime-local": "datetime",
+	"time":           "datetime",
+	"tz":             "timezone",
+	"int":            "number",
+	"integer":        "number",
+	"float":          "number",
+	"alarm":          "alarms",
+	"reminders":      "alarms",
+}
+
+// schemaMigrations[v] upgrades a template from version v to v+1 and
+// returns a human-readable note for every change it made.
+var schemaMigrations = map[int]func(*DataDrivenTemplate) []string{
+	1: migrateV1ToV2,
+	2: migrateV2ToV3,
+}
+
+// checkSchemaVersion rejects versions this build cannot read.
+func checkSchemaVersion(v int) error {
+	switch {
+	case v > CurrentSchemaVersion:
+		return fmt.Errorf("schema_version %d is newer than this version of tempus supports (max %d); upgrade tempus to use this template", v, CurrentSchemaVersion)
+	case v < MinSchemaVersion:
+		return fmt.Errorf("invalid schema_version %d (supported: %d-%d)", v, MinSchemaVersion, CurrentSchemaVersion)
+	}
+	return nil
+}
+
+func isKnownFieldType(typ string) bool {
+	for _, known := range KnownFieldTypes {
+		if typ == known {
+			return true
+		}
+	}
+	return false
+}
+
+// validateFieldTypes enforces the v2 field type vocabulary.
+func validateFieldTypes(t *DataDrivenTemplate) error {
+	if t.SchemaVersion < 2 {
+		return nil
+	}
+	for _, f := range t.Fields {
+		if !isKnownFieldType(f.Type) {
+			return fmt.Errorf("template %q field %q has unknown type %q (known: %s)", t.Name, f.Key, f.Type, strings.Join(KnownFieldTypes, ", "))
+		}
+	}
+	return nil
+}
+
+// MigrateDDTemplate upgrades t in place to CurrentSchemaVersion, one version at a time.
+// A template without schema_version is treated as version 1.
+func MigrateDDTemplate(t *DataDrivenTemplate) ([]string, error) {
+	if t.SchemaVersion == 0 {
+		t.SchemaVersion = 1
+	}
+	if err := checkSchemaVersion(t.SchemaVersion); err != nil {
+		return nil, err
+	}
+
+	var notes []string
+	for t.SchemaVersion < CurrentSchemaVersion {
+		migrate, ok := schemaMigrations[t.SchemaVersion]
+		if !ok {
+			return notes, fmt.Errorf("no migration from schema_version %d", t.SchemaVersion)
+		}
+		notes = append(notes, migrate(t)...)
+		t.SchemaVersion++
+	}
+	return notes, nil
+}
+
+func migrateV1ToV2(t *DataDrivenTemplate) []string {
+	var notes []string
+	durationKey := strings.TrimSpace(t.Output.DurationField)
+	alarmsKey := strings.TrimSpace(t.Output.AlarmsField)
+
+	for i := range t.Fields {
+		f := &t.Fields[i]
+		old := f.Type
+		typ := strings.ToLower(strings.TrimSpace(f.Type))
+		if mapped, ok := legacyFieldTypes[typ]; ok {
+			typ = mapped
+		}
+
+		switch {
+		case f.Key == durationKey && typ == "text":
+			typ = "duration"
+		case f.Key == alarmsKey && typ == "text":
+			typ = "alarms"
+		case !isKnownFieldType(typ):
+			notes = append(notes, fmt.Sprintf("field %s: unknown type %q replaced by text", f.Key, old))
+			f.Type = "text"
+			continue
+		}
+
+		if typ != old {
+			f.Type = typ
+			notes = append(notes, fmt.Sprintf("field %s: type %q → %q", f.Key, old, typ))
+		}
+	}
+	return notes
+}
+
+// migrateV2ToV3 has nothing to rewrite: version 3 only adds events.
+func migrateV2ToV3(*DataDrivenTemplate) []string {
+	return nil
+}
+
+// MigrationResult describes what MigrateDDTemplateFile did to a file.
+type MigrationResult struct {
+	Path        string
+	FromVersion int
+	ToVersion   int
+	Notes       []string
+}
+
+// Changed reports whether the file needed an upgrade.
+func (r MigrationResult) Changed() bool {
+	return r.FromVersion != r.ToVersion
+}
+
+// MigrateDDTemplateFile upgrades a JSON/YAML template file to CurrentSchemaVersion
+// and rewrites it in place (unless dryRun). Comments and key order are not preserved.
+func MigrateDDTemplateFile(path string, dryRun bool) (MigrationResult, error) {
+	result := MigrationResult{Path: path}
+	ext := strings.ToLower(filepath.Ext(path))
+	if !isTemplateFileExt(ext) {
+		return result, fmt.Errorf("%s: not a template file (want .json, .yaml or .yml)", path)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return result, err
+	}
+	tmpl, err := loadAndDecodeTemplate(path, ext)
+	if err != nil {
+		return result, err
+	}
+
+	result.FromVersion = tmpl.SchemaVersion
+	if result.FromVersion == 0 {
+		result.FromVersion = 1
+	}
+	notes, err := MigrateDDTemplate(&tmpl)
+	if err != nil {
+		return result, fmt.Errorf("%s: %w", path, err)
+	}
+	result.ToVersion = tmpl.SchemaVersion
+	result.Notes = notes
+	if !result.Changed() {
+		return result, nil
+	}
+
+	if strings.TrimSpace(tmpl.Name) == "" {
+		base := filepath.Base(path)
+		tmpl.Name = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	if err := ValidateDDTemplate(&tmpl); err != nil {
+		return result, fmt.Errorf("%s: migrated template is invalid: %w", path, err)
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	var data []byte
+	if ext == ".json" {
+		data, err = json.MarshalIndent(tmpl, "", "  ")
+		data = append(data, '\n')
+	} else {
+		data, err = yaml.Marshal(tmpl)
+	}
+	if err != nil {
+		return result, err
+	}
+	return result, os.WriteFile(path, data, info.Mode().Perm())
+}
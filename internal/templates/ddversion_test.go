@@ -0,0 +1,120 @@
+package templates
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const legacyTemplateJSON = `{
+	"name": "legacy",
+	"fields": [
+		{"key": "title", "name": "Title", "type": "string", "required": true},
+		{"key": "start_time", "name": "Start", "type": "datetime", "required": true},
+		{"key": "duration", "name": "Duration", "type": "text"},
+		{"key": "tz", "name": "Timezone", "type": "tz"},
+		{"key": "reminders", "name": "Reminders", "type": "text"},
+		{"key": "mood", "name": "Mood", "type": "emoji-picker"}
+	],
+	"output": {
+		"start_field": "start_time",
+		"duration_field": "duration",
+		"start_tz_field": "tz",
+		"alarms_field": "reminders",
+		"summary_tmpl": "{{title}}"
+	}
+}`
+
+func TestMigrateDDTemplateV1ToCurrent(t *testing.T) {
+	var tmpl DataDrivenTemplate
+	if err := json.Unmarshal([]byte(legacyTemplateJSON), &tmpl); err != nil {
+		t.Fatal(err)
+	}
+
+	notes, err := MigrateDDTemplate(&tmpl)
+	if err != nil {
+		t.Fatalf("MigrateDDTemplate returned error: %v", err)
+	}
+	if tmpl.SchemaVersion != CurrentSchemaVersion {
+		t.Fatalf("expected schema_version %d, got %d", CurrentSchemaVersion, tmpl.SchemaVersion)
+	}
+
+	want := map[string]string{"title": "text", "start_time": "datetime", "duration": "duration", "tz": "timezone", "reminders": "alarms", "mood": "text"}
+	for _, f := range tmpl.Fields {
+		if f.Type != want[f.Key] {
+			t.Errorf("field %s: type %q, want %q", f.Key, f.Type, want[f.Key])
+		}
+	}
+	if len(notes) != 5 {
+		t.Errorf("expected 5 migration notes, got %d: %v", len(notes), notes)
+	}
+	if err := ValidateDDTemplate(&tmpl); err != nil {
+		t.Errorf("migrated template should validate: %v", err)
+	}
+}
+
+func TestLoadDDTemplatesRefusesFutureSchema(t *testing.T) {
+	dir := t.TempDir()
+	future := strings.Replace(legacyTemplateJSON, `"name": "legacy",`, `"schema_version": 99, "name": "future",`, 1)
+	if err := os.WriteFile(filepath.Join(dir, "future.json"), []byte(future), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := LoadDDTemplates(dir)
+	if err == nil || !strings.Contains(err.Error(), "upgrade tempus") {
+		t.Fatalf("expected clear future-version error, got %v", err)
+	}
+}
+
+func TestValidateDDTemplateV2RejectsUnknownTypes(t *testing.T) {
+	var tmpl DataDrivenTemplate
+	if err := json.Unmarshal([]byte(legacyTemplateJSON), &tmpl); err != nil {
+		t.Fatal(err)
+	}
+	tmpl.SchemaVersion = 1
+	if err := ValidateDDTemplate(&tmpl); err != nil {
+		t.Fatalf("v1 template with free-form types should validate: %v", err)
+	}
+	tmpl.SchemaVersion = 2
+	if err := ValidateDDTemplate(&tmpl); err == nil || !strings.Contains(err.Error(), "unknown type") {
+		t.Fatalf("expected unknown type error for v2, got %v", err)
+	}
+}
+
+func TestMigrateDDTemplateFileInPlace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.json")
+	if err := os.WriteFile(path, []byte(legacyTemplateJSON), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := MigrateDDTemplateFile(path, true)
+	if err != nil || !res.Changed() {
+		t.Fatalf("dry run: expected pending change, got %+v (err=%v)", res, err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != legacyTemplateJSON {
+		t.Fatal("dry run must not modify the file")
+	}
+
+	res, err = MigrateDDTemplateFile(path, false)
+	if err != nil {
+		t.Fatalf("MigrateDDTemplateFile returned error: %v", err)
+	}
+	if res.FromVersion != 1 || res.ToVersion != CurrentSchemaVersion {
+		t.Fatalf("unexpected versions: %+v", res)
+	}
+
+	templates, err := LoadDDTemplates(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("migrated file should load: %v", err)
+	}
+	if templates["legacy"].SchemaVersion != CurrentSchemaVersion {
+		t.Fatalf("expected migrated schema version, got %d", templates["legacy"].SchemaVersion)
+	}
+
+	res, err = MigrateDDTemplateFile(path, false)
+	if err != nil || res.Changed() {
+		t.Fatalf("second migration should be a no-op, got %+v (err=%v)", res, err)
+	}
+}
@@ -0,0 +1,163 @@
+package templates
+
+import (
+	"fmt"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tempus/internal/constants"
+	"tempus/internal/normalizer"
+	"tempus/internal/timezone"
+)
+
+// zones is shared by every timezone field check; building it parses zone1970.tab.
+var zones = sync.OnceValue(timezone.NewTimezoneManager)
+
+// Kind returns the field's type in the current vocabulary. Legacy spellings
+// from v1 templates are mapped and unknown free-form types count as text.
+func (f Field) Kind() string {
+	typ := strings.ToLower(strings.TrimSpace(f.Type))
+	if mapped, ok := legacyFieldTypes[typ]; ok {
+		typ = mapped
+	}
+	if !isKnownFieldType(typ) {
+		return "text"
+	}
+	return typ
+}
+
+// CheckValue validates a non-empty value against the field's type and returns
+// it in canonical form (enum options as declared, timezones as IANA names).
+func (f Field) CheckValue(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	var err error
+	switch f.Kind() {
+	case "enum":
+		return f.checkEnum(value)
+	case "date":
+		_, err = time.Parse(constants.DateFormatISO, value)
+		if err != nil {
+			err = fmt.Errorf("invalid date %q (use YYYY-MM-DD)", value)
+		}
+	case "datetime":
+		_, err = normalizer.ParseDateTime(value, "")
+		if err != nil {
+			err = fmt.Errorf("invalid date/time %q (use YYYY-MM-DD HH:MM, YYYY-MM-DD or HH:MM)", value)
+		}
+	case "duration":
+		_, err = parseHumanDuration(value)
+		if err != nil {
+			err = fmt.Errorf("invalid duration %q (e.g. 45m, 1h30m, 90)", value)
+		}
+	case "email":
+		return f.checkEmails(value)
+	case "timezone":
+		return checkTimezone(value)
+	case "number":
+		err = f.checkNumber(value)
+	}
+	if err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+func (f Field) checkEnum(value string) (string, error) {
+	for _, opt := range f.Options {
+		if strings.EqualFold(strings.TrimSpace(opt), value) {
+			return opt, nil
+		}
+	}
+	return "", fmt.Errorf("%q is not one of: %s", value, strings.Join(f.Options, ", "))
+}
+
+// checkEmails accepts a comma-separated list, as attendee fields take several.
+func (f Field) checkEmails(value string) (string, error) {
+	parts := splitAndTrim(value, ",")
+	for _, p := range parts {
+		if _, err := mail.ParseAddress(p); err != nil {
+			return "", fmt.Errorf("invalid email address %q", p)
+		}
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+func checkTimezone(value string) (string, error) {
+	tm := zones()
+	info, err := tm.GetTimezone(value)
+	if err == nil {
+		return info.IANA, nil
+	}
+	if suggestions := tm.SuggestTimezone(value); len(suggestions) > 0 {
+		return "", fmt.Errorf("unknown timezone %q (did you mean %s?)", value, suggestions[0].IANA)
+	}
+	return "", fmt.Errorf("unknown timezone %q", value)
+}
+
+func (f Field) checkNumber(value string) error {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("%q is not a number", value)
+	}
+	switch {
+	case f.Min != nil && f.Max != nil && (n < *f.Min || n > *f.Max):
+		return fmt.Errorf("%s is out of range (%s-%s)", value, formatBound(*f.Min), formatBound(*f.Max))
+	case f.Min != nil && n < *f.Min:
+		return fmt.Errorf("%s is below the minimum of %s", value, formatBound(*f.Min))
+	case f.Max != nil && n > *f.Max:
+		return fmt.Errorf("%s is above the maximum of %s", value, formatBound(*f.Max))
+	}
+	return nil
+}
+
+// bound returns a pointer to v for Field.Min and Field.Max.
+func bound(v float64) *float64 { return &v }
+
+func formatBound(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// CheckValues validates every non-empty value of t's fields in data and
+// rewrites it to canonical form. Errors name the offending field.
+func (t *Template) CheckValues(data map[string]string) error {
+	for _, f := range t.Fields {
+		v, ok := data[f.Key]
+		if !ok || strings.TrimSpace(v) == "" {
+			continue
+		}
+		checked, err := f.CheckValue(v)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", f.Key, err)
+		}
+		data[f.Key] = checked
+	}
+	return nil
+}
+
+// validateFieldConstraints checks the type-specific settings of each field:
+// enum fields need options and number ranges must not be inverted.
+func validateFieldConstraints(t *DataDrivenTemplate) error {
+	for _, f := range t.Fields {
+		switch f.Kind() {
+		case "enum":
+			if len(f.Options) == 0 {
+				return fmt.Errorf("template %q enum field %q has no options", t.Name, f.Key)
+			}
+			if f.Default != "" && !slices.ContainsFunc(f.Options, func(o string) bool { return strings.EqualFold(o, f.Default) }) {
+				return fmt.Errorf("template %q field %q default %q is not one of its options", t.Name, f.Key, f.Default)
+			}
+		case "number":
+			if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+				return fmt.Errorf("template %q field %q has min greater than max", t.Name, f.Key)
+			}
+		}
+	}
+	return nil
+}
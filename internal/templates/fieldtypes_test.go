@@ -0,0 +1,98 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFieldCheckValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   Field
+		value   string
+		want    string
+		wantErr string
+	}{
+		{"enum canonical spelling", Field{Type: "enum", Options: []string{"Low", "High"}}, "high", "High", ""},
+		{"enum unknown option", Field{Type: "enum", Options: []string{"Low", "High"}}, "urgent", "", "not one of: Low, High"},
+		{"date", Field{Type: "date"}, "2025-12-15", "2025-12-15", ""},
+		{"date with time", Field{Type: "date"}, "2025-12-15 10:00", "", "use YYYY-MM-DD"},
+		{"datetime", Field{Type: "datetime"}, "2025-12-15 10:00", "2025-12-15 10:00", ""},
+		{"datetime clock only", Field{Type: "datetime"}, "09:30", "09:30", ""},
+		{"legacy time type", Field{Type: "time"}, "tomorrow", "", "invalid date/time"},
+		{"email list", Field{Type: "email"}, "ana@example.com,  Bob <bob@example.com>", "ana@example.com, Bob <bob@example.com>", ""},
+		{"email missing domain", Field{Type: "email"}, "ana@example.com, bob", "", `invalid email address "bob"`},
+		{"timezone", Field{Type: "timezone"}, "europe/madrid", "Europe/Madrid", ""},
+		{"legacy tz type", Field{Type: "tz"}, "Mars/Olympus", "", "unknown timezone"},
+		{"number in range", Field{Type: "number", Min: bound(1), Max: bound(9)}, "3", "3", ""},
+		{"number out of range", Field{Type: "number", Min: bound(1), Max: bound(9)}, "12", "", "out of range (1-9)"},
+		{"number below minimum", Field{Type: "int", Min: bound(0)}, "-1", "", "below the minimum of 0"},
+		{"not a number", Field{Type: "number"}, "five", "", "not a number"},
+		{"duration", Field{Type: "duration"}, "1h30m", "1h30m", ""},
+		{"bad duration", Field{Type: "duration"}, "soon", "", "invalid duration"},
+		{"free-form type is text", Field{Type: "emoji-picker"}, "🙂", "🙂", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.field.CheckValue(tt.value)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("CheckValue(%q) error = %v, want %q", tt.value, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CheckValue(%q) unexpected error: %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("CheckValue(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateEventChecksFieldTypes(t *testing.T) {
+	tm := NewTemplateManager()
+	data := map[string]string{"task": "Taxes", "due_date": "2025-04-30", "priority": "12"}
+	if _, err := tm.GenerateEvent("deadline", data, newTestTranslator()); err == nil || !strings.Contains(err.Error(), "field priority") {
+		t.Fatalf("expected a priority range error, got %v", err)
+	}
+
+	data = map[string]string{"title": "Sync", "start_time": "2025-12-01 10:00", "timezone": "europe/berlin"}
+	ev, err := tm.GenerateEvent("meeting", data, newTestTranslator())
+	if err != nil {
+		t.Fatalf("GenerateEvent() failed: %v", err)
+	}
+	if ev.StartTZ != "Europe/Berlin" {
+		t.Errorf("StartTZ = %q, want the canonical Europe/Berlin", ev.StartTZ)
+	}
+}
+
+func TestValidateDDTemplateFieldConstraints(t *testing.T) {
+	base := func(f Field) *DataDrivenTemplate {
+		return &DataDrivenTemplate{
+			SchemaVersion: CurrentSchemaVersion,
+			Name:          "check",
+			Fields:        []Field{{Key: "start", Type: "datetime", Required: true}, f},
+			Output:        OutputTemplate{StartField: "start", SummaryTmpl: "x"},
+		}
+	}
+	tests := []struct {
+		field   Field
+		wantErr string
+	}{
+		{Field{Key: "level", Type: "enum", Options: []string{"a", "b"}, Default: "b"}, ""},
+		{Field{Key: "level", Type: "enum"}, "has no options"},
+		{Field{Key: "level", Type: "enum", Options: []string{"a", "b"}, Default: "c"}, "not one of its options"},
+		{Field{Key: "count", Type: "number", Min: bound(5), Max: bound(1)}, "min greater than max"},
+	}
+	for _, tt := range tests {
+		err := ValidateDDTemplate(base(tt.field))
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%+v: unexpected error %v", tt.field, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%+v: error = %v, want %q", tt.field, err, tt.wantErr)
+		}
+	}
+}
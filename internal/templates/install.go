@@ -0,0 +1,579 @@
+package templates
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"tempus/internal/clock"
+	"tempus/internal/fsys"
+	"tempus/internal/utils"
+)
+
+// InstalledLockFile records what `tempus template install` put in a template
+// directory. Its extension keeps the template loader from reading it.
+const InstalledLockFile = "installed.lock"
+
+// maxTemplateSize bounds a downloaded template file.
+const maxTemplateSize = 1 << 20
+
+// Source kinds.
+const (
+	SourceURL  = "url"  // a single template file over HTTP(S)
+	SourceFile = "file" // a single local template file
+	SourceGit  = "git"  // every template in a git repository
+)
+
+// InstalledSource is one source installed into a template directory.
+type InstalledSource struct {
+	Source    string          `json:"source"`
+	Kind      string          `json:"kind"`
+	Ref       string          `json:"ref,omitempty"`    // git branch or tag asked for
+	Commit    string          `json:"commit,omitempty"` // git commit installed
+	SHA256    string          `json:"sha256,omitempty"` // pinned checksum of a url/file source
+	Files     []InstalledFile `json:"files"`
+	Installed time.Time       `json:"installed"`
+	Updated   *time.Time      `json:"updated,omitempty"`
+}
+
+// InstalledFile is one template file written for a source.
+type InstalledFile struct {
+	Template string `json:"template"` // template name
+	Path     string `json:"path"`     // relative to the template directory
+	SHA256   string `json:"sha256"`   // of the content written
+}
+
+// Change is what an install, update or remove did to one file.
+type Change struct {
+	Path string
+	// Kind is "added", "updated", "unchanged" or "removed".
+	Kind string
+}
+
+// InstallOptions tunes Installer.Install.
+type InstallOptions struct {
+	Ref    string // git branch or tag
+	SHA256 string // expected checksum of a url/file source
+	Force  bool   // replace files that belong to something else
+}
+
+// Installer installs shared templates into Dir.
+type Installer struct {
+	Dir       string
+	Client    *http.Client
+	UserAgent string
+	// FS holds Dir and local sources; Clock stamps the lock file. Nil means
+	// the real filesystem and clock. Git sources are always cloned to disk.
+	FS    fsys.FS
+	Clock clock.Clock
+}
+
+// NewInstaller returns an installer for dir.
+func NewInstaller(dir string) *Installer {
+	return &Installer{Dir: dir, Client: &http.Client{Timeout: 30 * time.Second}, UserAgent: "tempus", FS: fsys.OS, Clock: clock.System}
+}
+
+func (in *Installer) filesystem() fsys.FS {
+	if in.FS == nil {
+		return fsys.OS
+	}
+	return in.FS
+}
+
+func (in *Installer) now() time.Time {
+	if in.Clock == nil {
+		return time.Now()
+	}
+	return in.Clock.Now()
+}
+
+// SourceKind tells how source is fetched: URLs and paths ending in .json,
+// .yaml or .yml are single files, anything else is a git repository.
+func SourceKind(source string) string {
+	u, err := url.Parse(source)
+	isHTTP := err == nil && (u.Scheme == "http" || u.Scheme == "https")
+	p := source
+	if isHTTP {
+		p = u.Path
+	}
+	if isTemplateFileExt(strings.ToLower(path.Ext(p))) {
+		if isHTTP {
+			return SourceURL
+		}
+		return SourceFile
+	}
+	return SourceGit
+}
+
+// Installed lists the installed sources, in install order.
+func (in *Installer) Installed() ([]InstalledSource, error) {
+	data, err := in.filesystem().ReadFile(filepath.Join(in.Dir, InstalledLockFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sources []InstalledSource
+	if err := json.Unmarshal(data, &sources); err != nil {
+		return nil, fmt.Errorf("%s: %w", InstalledLockFile, err)
+	}
+	return sources, nil
+}
+
+// Find returns the installed source named by source or by one of its
+// template names.
+func (in *Installer) Find(nameOrSource string) (InstalledSource, error) {
+	sources, err := in.Installed()
+	if err != nil {
+		return InstalledSource{}, err
+	}
+	for _, s := range sources {
+		if s.Source == nameOrSource {
+			return s, nil
+		}
+	}
+	for _, s := range sources {
+		for _, f := range s.Files {
+			if f.Template == nameOrSource {
+				return s, nil
+			}
+		}
+	}
+	return InstalledSource{}, fmt.Errorf("%q was not installed with tempus template install", nameOrSource)
+}
+
+// Install fetches source, validates every template in it and writes them to
+// the template directory. Installing a source again updates it.
+func (in *Installer) Install(ctx context.Context, source string, opts InstallOptions) (InstalledSource, []Change, error) {
+	src := InstalledSource{Source: source, Kind: SourceKind(source), Ref: opts.Ref, SHA256: strings.ToLower(strings.TrimSpace(opts.SHA256))}
+	if _, err := in.filesystem().Stat(source); err == nil {
+		// Local files and repositories are recorded absolute so update works from anywhere.
+		if abs, err := filepath.Abs(source); err == nil {
+			src.Source = abs
+		}
+	}
+	if src.Kind == SourceGit && src.SHA256 != "" {
+		return src, nil, fmt.Errorf("--sha256 applies to single template files; pin a git source with --ref")
+	}
+	if src.Kind != SourceGit && src.Ref != "" {
+		return src, nil, fmt.Errorf("--ref applies to git repositories only")
+	}
+	if prev, err := in.Find(src.Source); err == nil {
+		src.Installed = prev.Installed
+		if src.Ref == "" {
+			src.Ref = prev.Ref
+		}
+		if src.SHA256 == "" {
+			src.SHA256 = prev.SHA256
+		}
+		if edited := in.editedFiles(prev); len(edited) > 0 && !opts.Force {
+			return src, nil, fmt.Errorf("edited since installed (use --force to overwrite): %s", strings.Join(edited, ", "))
+		}
+	}
+	return in.apply(ctx, src, opts.Force)
+}
+
+// Update fetches an installed source again and rewrites its files. Files
+// edited since they were installed are kept unless force is set.
+func (in *Installer) Update(ctx context.Context, nameOrSource string, force bool) (InstalledSource, []Change, error) {
+	src, err := in.Find(nameOrSource)
+	if err != nil {
+		return src, nil, err
+	}
+	if !force {
+		if edited := in.editedFiles(src); len(edited) > 0 {
+			return src, nil, fmt.Errorf("edited since installed (use --force to overwrite): %s", strings.Join(edited, ", "))
+		}
+	}
+	return in.apply(ctx, src, force)
+}
+
+// Remove deletes every file of an installed source (found by source or by
+// one of its template names). Edited files are kept unless force is set.
+func (in *Installer) Remove(nameOrSource string, force bool) (InstalledSource, []Change, error) {
+	src, err := in.Find(nameOrSource)
+	if err != nil {
+		return src, nil, err
+	}
+	if !force {
+		if edited := in.editedFiles(src); len(edited) > 0 {
+			return src, nil, fmt.Errorf("edited since installed (use --force to remove anyway): %s", strings.Join(edited, ", "))
+		}
+	}
+	var changes []Change
+	for _, f := range src.Files {
+		err := in.filesystem().Remove(filepath.Join(in.Dir, filepath.FromSlash(f.Path)))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return src, changes, err
+		}
+		changes = append(changes, Change{Path: f.Path, Kind: "removed"})
+	}
+	in.pruneEmptyDirs(src.Files)
+	return src, changes, in.saveSource(src, true)
+}
+
+// apply fetches src and writes its templates, replacing the files a
+// previous install of the same source wrote.
+func (in *Installer) apply(ctx context.Context, src InstalledSource, force bool) (InstalledSource, []Change, error) {
+	files, commit, err := in.fetch(ctx, src)
+	if err != nil {
+		return src, nil, err
+	}
+	src.Commit = commit
+
+	owners, err := in.owners()
+	if err != nil {
+		return src, nil, err
+	}
+	previous := map[string]InstalledFile{}
+	if prev, err := in.Find(src.Source); err == nil {
+		for _, f := range prev.Files {
+			previous[f.Path] = f
+		}
+	}
+
+	var installed []InstalledFile
+	var changes []Change
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, rel := range names {
+		content := files[rel]
+		tmpl, err := checkTemplateFile(rel, content)
+		if err != nil {
+			return src, nil, err
+		}
+		target := filepath.Join(in.Dir, filepath.FromSlash(rel))
+		if owner, ok := owners[rel]; ok && owner != src.Source && !force {
+			return src, nil, fmt.Errorf("%s already belongs to %s (use --force to replace it)", rel, owner)
+		}
+		if _, owned := owners[rel]; !owned && !force {
+			if _, err := in.filesystem().Stat(target); err == nil {
+				return src, nil, fmt.Errorf("%s already exists (use --force to replace it)", target)
+			}
+		}
+		installed = append(installed, InstalledFile{Template: tmpl.Name, Path: rel, SHA256: sha256Hex(content)})
+	}
+
+	for _, f := range installed {
+		target := filepath.Join(in.Dir, filepath.FromSlash(f.Path))
+		kind := "added"
+		if old, ok := previous[f.Path]; ok {
+			kind = "updated"
+			if old.SHA256 == f.SHA256 {
+				kind = "unchanged"
+			}
+			delete(previous, f.Path)
+		}
+		if err := in.filesystem().MkdirAll(filepath.Dir(target), 0o750); err != nil {
+			return src, changes, err
+		}
+		if err := in.filesystem().WriteFile(target, files[f.Path], 0o600); err != nil {
+			return src, changes, err
+		}
+		changes = append(changes, Change{Path: f.Path, Kind: kind})
+	}
+	var dropped []InstalledFile
+	for _, f := range previous {
+		if err := in.filesystem().Remove(filepath.Join(in.Dir, filepath.FromSlash(f.Path))); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return src, changes, err
+		}
+		dropped = append(dropped, f)
+		changes = append(changes, Change{Path: f.Path, Kind: "removed"})
+	}
+	in.pruneEmptyDirs(dropped)
+
+	now := in.now().UTC()
+	if src.Installed.IsZero() {
+		src.Installed = now
+	} else {
+		src.Updated = &now
+	}
+	src.Files = installed
+	return src, changes, in.saveSource(src, false)
+}
+
+// checkTemplateFile decodes and validates one fetched template.
+func checkTemplateFile(rel string, content []byte) (DataDrivenTemplate, error) {
+	tmpl, err := decodeDDTemplate(content, strings.ToLower(path.Ext(rel)))
+	if err != nil {
+		return tmpl, fmt.Errorf("%s: %w", rel, err)
+	}
+	if err := normalizeTemplateMetadata(&tmpl, rel); err != nil {
+		return tmpl, err
+	}
+	if err := ValidateDDTemplate(&tmpl); err != nil {
+		return tmpl, fmt.Errorf("%s: %w", rel, err)
+	}
+	return tmpl, nil
+}
+
+// fetch returns the template files of src keyed by their path relative to
+// the template directory, and the git commit for git sources.
+func (in *Installer) fetch(ctx context.Context, src InstalledSource) (map[string][]byte, string, error) {
+	switch src.Kind {
+	case SourceGit:
+		return in.fetchGit(ctx, src)
+	case SourceURL:
+		data, err := in.download(ctx, src.Source)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := in.verifyChecksum(ctx, src, data); err != nil {
+			return nil, "", err
+		}
+		u, _ := url.Parse(src.Source)
+		return map[string][]byte{path.Base(u.Path): data}, "", nil
+	default:
+		data, err := in.filesystem().ReadFile(src.Source)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := in.verifyChecksum(ctx, src, data); err != nil {
+			return nil, "", err
+		}
+		return map[string][]byte{filepath.Base(src.Source): data}, "", nil
+	}
+}
+
+// verifyChecksum compares data with the pinned checksum or, for URLs without
+// one, with a checksum published next to the file as <url>.sha256. Only a 404
+// there means nothing was published; any other failure stops the install.
+func (in *Installer) verifyChecksum(ctx context.Context, src InstalledSource, data []byte) error {
+	want := src.SHA256
+	if want == "" && src.Kind == SourceURL {
+		published, err := in.download(ctx, src.Source+".sha256")
+		var status *statusError
+		if errors.As(err, &status) && status.code == http.StatusNotFound {
+			return nil // nothing published
+		}
+		if err != nil {
+			return fmt.Errorf("cannot check the published checksum: %w", err)
+		}
+		if fields := strings.Fields(string(published)); len(fields) > 0 {
+			want = strings.ToLower(fields[0])
+		}
+	}
+	if want == "" {
+		return nil
+	}
+	if got := sha256Hex(data); got != want {
+		return fmt.Errorf("checksum mismatch for %s: got sha256 %s, want %s", src.Source, got, want)
+	}
+	return nil
+}
+
+func (in *Installer) download(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", in.UserAgent)
+	resp, err := in.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &statusError{url: rawURL, code: resp.StatusCode, status: resp.Status}
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxTemplateSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", rawURL, err)
+	}
+	if len(data) > maxTemplateSize {
+		return nil, fmt.Errorf("%s is larger than %d MiB", rawURL, maxTemplateSize>>20)
+	}
+	return data, nil
+}
+
+// statusError is a download answered with a status other than 200 OK.
+type statusError struct {
+	url    string
+	code   int
+	status string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("failed to fetch %s: %s", e.url, e.status)
+}
+
+// fetchGit shallow-clones the repository and collects every template in it,
+// placed under a directory named after the repository.
+func (in *Installer) fetchGit(ctx context.Context, src InstalledSource) (map[string][]byte, string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, "", fmt.Errorf("installing from a git repository needs git on PATH")
+	}
+	tmp, err := os.MkdirTemp("", "tempus-template-*")
+	if err != nil {
+		return nil, "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if src.Ref != "" {
+		args = append(args, "--branch", src.Ref)
+	}
+	args = append(args, "--", strings.TrimPrefix(src.Source, "git+"), tmp)
+	var stderr bytes.Buffer
+	clone := exec.CommandContext(ctx, "git", args...)
+	clone.Stderr = &stderr
+	if err := clone.Run(); err != nil {
+		return nil, "", fmt.Errorf("git clone %s: %v: %s", src.Source, err, strings.TrimSpace(stderr.String()))
+	}
+	head, err := exec.CommandContext(ctx, "git", "-C", tmp, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return nil, "", fmt.Errorf("git rev-parse: %w", err)
+	}
+
+	prefix := repoDirName(src.Source)
+	files := map[string][]byte{}
+	err = filepath.WalkDir(tmp, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), ".") && p != tmp {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isTemplateFileExt(strings.ToLower(filepath.Ext(p))) {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if _, err := checkTemplateFile(filepath.Base(p), data); err != nil {
+			return nil // other JSON/YAML in the repository (CI config, package.json...)
+		}
+		rel, _ := filepath.Rel(tmp, p)
+		files[path.Join(prefix, filepath.ToSlash(rel))] = data
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if len(files) == 0 {
+		return nil, "", fmt.Errorf("no valid templates found in %s", src.Source)
+	}
+	return files, strings.TrimSpace(string(head)), nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// repoDirName turns "https://github.com/team/tempus-templates.git" into
+// "tempus-templates".
+func repoDirName(source string) string {
+	s := strings.TrimRight(source, "/")
+	s = s[strings.LastIndexAny(s, "/:")+1:]
+	s = strings.TrimSuffix(s, ".git")
+	if name := utils.Slugify(s); name != "" {
+		return name
+	}
+	return "repo"
+}
+
+// owners maps every installed file to its source.
+func (in *Installer) owners() (map[string]string, error) {
+	sources, err := in.Installed()
+	if err != nil {
+		return nil, err
+	}
+	owners := map[string]string{}
+	for _, s := range sources {
+		for _, f := range s.Files {
+			owners[f.Path] = s.Source
+		}
+	}
+	return owners, nil
+}
+
+// editedFiles lists files of src whose content no longer matches the lock.
+func (in *Installer) editedFiles(src InstalledSource) []string {
+	var edited []string
+	for _, f := range src.Files {
+		data, err := in.filesystem().ReadFile(filepath.Join(in.Dir, filepath.FromSlash(f.Path)))
+		if err == nil && sha256Hex(data) != f.SHA256 {
+			edited = append(edited, f.Path)
+		}
+	}
+	return edited
+}
+
+// pruneEmptyDirs removes the directories a git source created once empty.
+func (in *Installer) pruneEmptyDirs(files []InstalledFile) {
+	for _, f := range files {
+		dir := path.Dir(f.Path)
+		for dir != "." && dir != "/" {
+			if in.filesystem().Remove(filepath.Join(in.Dir, filepath.FromSlash(dir))) != nil {
+				break // not empty
+			}
+			dir = path.Dir(dir)
+		}
+	}
+}
+
+// saveSource replaces (or with remove, drops) src in the lock file.
+func (in *Installer) saveSource(src InstalledSource, remove bool) error {
+	sources, err := in.Installed()
+	if err != nil {
+		return err
+	}
+	kept := sources[:0]
+	replaced := false
+	for _, s := range sources {
+		if s.Source != src.Source {
+			kept = append(kept, s)
+			continue
+		}
+		if !remove {
+			kept = append(kept, src)
+			replaced = true
+		}
+	}
+	if !remove && !replaced {
+		kept = append(kept, src)
+	}
+	if err := in.filesystem().MkdirAll(in.Dir, 0o750); err != nil {
+		return err
+	}
+	if len(kept) == 0 {
+		err := in.filesystem().Remove(filepath.Join(in.Dir, InstalledLockFile))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	data, err := json.MarshalIndent(kept, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(in.Dir, InstalledLockFile+".tmp")
+	if err := in.filesystem().WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return in.filesystem().Rename(tmp, filepath.Join(in.Dir, InstalledLockFile))
+}
@@ -0,0 +1,256 @@
+package templates
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"tempus/internal/clock"
+	"tempus/internal/fsys"
+)
+
+const sharedTemplateYAML = `schema_version: 3
+name: standup
+fields:
+  - key: start_time
+    name: Start
+    type: datetime
+    required: true
+output:
+  start_field: start_time
+  summary_tmpl: Standup
+`
+
+// serveTemplates serves path -> body; paths missing from files are 404s.
+func serveTemplates(t *testing.T, files map[string]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestInstallFromURLLifecycle(t *testing.T) {
+	files := map[string]string{"/standup.yaml": sharedTemplateYAML}
+	srv := serveTemplates(t, files)
+	dir := t.TempDir()
+	in := NewInstaller(dir)
+	ctx := context.Background()
+	source := srv.URL + "/standup.yaml"
+
+	src, changes, err := in.Install(ctx, source, InstallOptions{SHA256: sha256Hex([]byte(sharedTemplateYAML))})
+	if err != nil {
+		t.Fatalf("Install() failed: %v", err)
+	}
+	if len(src.Files) != 1 || src.Files[0].Template != "standup" || changes[0].Kind != "added" {
+		t.Fatalf("unexpected install result %+v %+v", src, changes)
+	}
+	loaded, err := LoadDDTemplates(dir)
+	if err != nil || len(loaded) != 1 {
+		t.Fatalf("installed template should load next to installed.lock: %v %v", loaded, err)
+	}
+
+	// The pinned checksum keeps a changed upstream file out.
+	files["/standup.yaml"] = strings.Replace(sharedTemplateYAML, "Standup", "Daily standup", 1)
+	if _, _, err := in.Update(ctx, "standup", false); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected a checksum mismatch, got %v", err)
+	}
+
+	// Reinstalling with the new checksum updates the file.
+	if _, changes, err = in.Install(ctx, source, InstallOptions{SHA256: sha256Hex([]byte(files["/standup.yaml"]))}); err != nil {
+		t.Fatalf("reinstall failed: %v", err)
+	}
+	if changes[0].Kind != "updated" {
+		t.Errorf("expected the file to be updated, got %+v", changes)
+	}
+
+	// Local edits are protected from update and remove.
+	path := filepath.Join(dir, "standup.yaml")
+	if err := os.WriteFile(path, []byte(sharedTemplateYAML+"# mine\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := in.Remove("standup", false); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected remove to refuse an edited file, got %v", err)
+	}
+	if _, changes, err := in.Remove("standup", true); err != nil || len(changes) != 1 {
+		t.Fatalf("forced Remove() = %+v, %v", changes, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected the template to be removed")
+	}
+	if sources, _ := in.Installed(); len(sources) != 0 {
+		t.Errorf("expected an empty lock, got %+v", sources)
+	}
+}
+
+func TestInstallIntoMemoryFSWithFixedClock(t *testing.T) {
+	mem := fsys.NewMemory()
+	if err := mem.WriteFile("/src/standup.yaml", []byte(sharedTemplateYAML), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	pinned := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+	in := NewInstaller("/templates")
+	in.FS, in.Clock = mem, clock.Fixed(pinned)
+
+	src, changes, err := in.Install(context.Background(), "/src/standup.yaml", InstallOptions{})
+	if err != nil {
+		t.Fatalf("Install() failed: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Kind != "added" || !src.Installed.Equal(pinned) {
+		t.Errorf("unexpected install result %+v %+v", src, changes)
+	}
+	if data, err := mem.ReadFile("/templates/standup.yaml"); err != nil || string(data) != sharedTemplateYAML {
+		t.Errorf("template should be written to the memory FS: %q, %v", data, err)
+	}
+	if sources, err := in.Installed(); err != nil || len(sources) != 1 {
+		t.Errorf("Installed() = %+v, %v", sources, err)
+	}
+	if _, err := os.Stat("/templates/" + InstalledLockFile); err == nil {
+		t.Error("a memory install should not touch the disk")
+	}
+	if _, _, err := in.Remove("standup", false); err != nil {
+		t.Fatalf("Remove() failed: %v", err)
+	}
+	if _, err := mem.Stat("/templates/standup.yaml"); !os.IsNotExist(err) {
+		t.Errorf("Remove() should delete the template, Stat() = %v", err)
+	}
+}
+
+func TestInstallVerifiesPublishedChecksum(t *testing.T) {
+	srv := serveTemplates(t, map[string]string{
+		"/standup.yaml":        sharedTemplateYAML,
+		"/standup.yaml.sha256": strings.Repeat("0", 64) + "  standup.yaml\n",
+	})
+	in := NewInstaller(t.TempDir())
+	_, _, err := in.Install(context.Background(), srv.URL+"/standup.yaml", InstallOptions{})
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected the published checksum to be enforced, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(in.Dir, "standup.yaml")); !os.IsNotExist(err) {
+		t.Error("nothing should be written when verification fails")
+	}
+}
+
+func TestInstallFailsWhenTheChecksumCannotBeFetched(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".sha256") {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(sharedTemplateYAML))
+	}))
+	t.Cleanup(srv.Close)
+
+	in := NewInstaller(t.TempDir())
+	_, _, err := in.Install(context.Background(), srv.URL+"/standup.yaml", InstallOptions{})
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Fatalf("expected a 500 on the checksum to fail the install, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(in.Dir, "standup.yaml")); !os.IsNotExist(err) {
+		t.Error("nothing should be written when the checksum cannot be checked")
+	}
+}
+
+func TestInstallRejectsInvalidTemplatesAndConflicts(t *testing.T) {
+	srv := serveTemplates(t, map[string]string{
+		"/broken.yaml":  "name: broken\nfields: []\n",
+		"/standup.yaml": sharedTemplateYAML,
+	})
+	in := NewInstaller(t.TempDir())
+	ctx := context.Background()
+	if _, _, err := in.Install(ctx, srv.URL+"/broken.yaml", InstallOptions{}); err == nil {
+		t.Error("expected an invalid template to be refused")
+	}
+
+	existing := filepath.Join(in.Dir, "standup.yaml")
+	if err := os.WriteFile(existing, []byte("mine"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := in.Install(ctx, srv.URL+"/standup.yaml", InstallOptions{}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected a conflict with an unmanaged file, got %v", err)
+	}
+	if _, _, err := in.Install(ctx, srv.URL+"/standup.yaml", InstallOptions{Force: true}); err != nil {
+		t.Fatalf("forced install failed: %v", err)
+	}
+}
+
+func TestInstallFromGitRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repo := filepath.Join(t.TempDir(), "team-templates")
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(repo, "templates"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	writeFile := func(rel, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, rel), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("templates/standup.yaml", sharedTemplateYAML)
+	writeFile("package.json", `{"name": "not-a-template"}`)
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-qm", "templates")
+
+	in := NewInstaller(t.TempDir())
+	ctx := context.Background()
+	src, _, err := in.Install(ctx, repo, InstallOptions{})
+	if err != nil {
+		t.Fatalf("Install() failed: %v", err)
+	}
+	if len(src.Files) != 1 || src.Files[0].Path != "team-templates/templates/standup.yaml" || src.Commit == "" {
+		t.Fatalf("unexpected git install %+v", src)
+	}
+
+	writeFile("templates/retro.yaml", strings.NewReplacer("standup", "retro", "Standup", "Retro").Replace(sharedTemplateYAML))
+	git("add", "-A")
+	git("commit", "-qm", "retro")
+	src, changes, err := in.Update(ctx, "standup", false)
+	if err != nil {
+		t.Fatalf("Update() failed: %v", err)
+	}
+	kinds := map[string]string{}
+	for _, c := range changes {
+		kinds[filepath.Base(c.Path)] = c.Kind
+	}
+	if len(src.Files) != 2 || kinds["retro.yaml"] != "added" || kinds["standup.yaml"] != "unchanged" {
+		t.Errorf("update changes = %+v", changes)
+	}
+}
+
+func TestSourceKind(t *testing.T) {
+	tests := map[string]string{
+		"https://example.com/t/standup.yaml":       SourceURL,
+		"https://example.com/t/standup.JSON?raw=1": SourceURL,
+		"./standup.yml":                         SourceFile,
+		"https://github.com/team/templates.git": SourceGit,
+		"git@github.com:team/templates.git":     SourceGit,
+		"https://github.com/team/templates":     SourceGit,
+	}
+	for source, want := range tests {
+		if got := SourceKind(source); got != want {
+			t.Errorf("SourceKind(%q) = %q, want %q", source, got, want)
+		}
+	}
+}
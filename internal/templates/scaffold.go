@@ -38,14 +38,14 @@ func GenerateScaffold(opts ScaffoldOptions) ([]byte, error) {
 	labels := scaffoldStrings(lang)
 
 	dd := DataDrivenTemplate{
-		SchemaVersion:    1,
+		SchemaVersion:    CurrentSchemaVersion,
 		Name:             name,
 		Description:      labels.TemplateDescription,
 		FilenameTemplate: fmt.Sprintf("%s-{{slug title}}.ics", slugify(name)),
 		Fields: []Field{
 			{Key: "title", Name: labels.FieldTitle, Type: "text", Required: true},
 			{Key: "start_time", Name: labels.FieldStartTime, Type: "datetime", Required: true},
-			{Key: "duration", Name: labels.FieldDuration, Type: "text", Default: "45m"},
+			{Key: "duration", Name: labels.FieldDuration, Type: "duration", Default: "45m"},
 			{Key: "timezone", Name: labels.FieldTimezone, Type: "timezone", Default: "UTC"},
 			{Key: "notes", Name: labels.FieldNotes, Type: "text"},
 			{Key: "rrule", Name: labels.FieldRRule, Type: "text", Description: labels.RRuleHint},
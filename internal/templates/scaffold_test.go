@@ -27,8 +27,8 @@ func TestGenerateScaffoldYAML(t *testing.T) {
 	if dd.Name != "custom" {
 		t.Fatalf("expected name custom, got %s", dd.Name)
 	}
-	if dd.SchemaVersion != 1 {
-		t.Fatalf("expected schema version 1, got %d", dd.SchemaVersion)
+	if dd.SchemaVersion != CurrentSchemaVersion {
+		t.Fatalf("expected schema version %d, got %d", CurrentSchemaVersion, dd.SchemaVersion)
 	}
 	if dd.Output.StartField != "start_time" {
 		t.Fatalf("unexpected start_field: %s", dd.Output.StartField)
@@ -24,11 +24,13 @@ type Template struct {
 type Field struct {
 	Key         string   `json:"key" yaml:"key"`
 	Name        string   `json:"name" yaml:"name"`
-	Type        string   `json:"type" yaml:"type"` // text, datetime, timezone, email, number, etc.
+	Type        string   `json:"type" yaml:"type"` // text, datetime, timezone, email, number, enum, etc.
 	Required    bool     `json:"required" yaml:"required"`
 	Default     string   `json:"default,omitempty" yaml:"default,omitempty"`
 	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
-	Options     []string `json:"options,omitempty" yaml:"options,omitempty"`
+	Options     []string `json:"options,omitempty" yaml:"options,omitempty"` // choices of an enum field
+	Min         *float64 `json:"min,omitempty" yaml:"min,omitempty"`         // range of a number field
+	Max         *float64 `json:"max,omitempty" yaml:"max,omitempty"`
 }
 
 // TemplateManager manages event templates
@@ -75,9 +77,30 @@ func (tm *TemplateManager) GenerateEvent(templateName string, data map[string]st
 			}
 		}
 	}
+	if err := t.CheckValues(data); err != nil {
+		return nil, err
+	}
 	return t.Generator(data, translator)
 }
 
+// GenerateEvents generates the event of a template plus, for multi-event
+// data-driven templates, its related events (main event first).
+func (tm *TemplateManager) GenerateEvents(templateName string, data map[string]string, translator *i18n.Translator) ([]*calendar.Event, error) {
+	ev, err := tm.GenerateEvent(templateName, data, translator)
+	if err != nil {
+		return nil, err
+	}
+	events := []*calendar.Event{ev}
+	if dd, ok := tm.ddTemplates[templateName]; ok && len(dd.Events) > 0 {
+		related, err := renderDDEvents(&dd, data, translator)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, related...)
+	}
+	return events, nil
+}
+
 // ----------------------
 // Data-driven templates
 // ----------------------
@@ -107,6 +130,8 @@ func (tm *TemplateManager) RegisterDDTemplate(dd DataDrivenTemplate) {
 			Default:     f.Default,
 			Description: f.Description,
 			Options:     f.Options,
+			Min:         f.Min,
+			Max:         f.Max,
 		})
 	}
 
@@ -210,8 +235,8 @@ func (tm *TemplateManager) registerBuiltinTemplates() {
 		Description: "Holiday/vacation period",
 		Fields: []Field{
 			{Key: "destination", Name: "Destination", Type: "text", Required: true},
-			{Key: "start_date", Name: "Start Date (YYYY-MM-DD)", Type: "datetime", Required: true},
-			{Key: "end_date", Name: "End Date (YYYY-MM-DD)", Type: "datetime", Required: true},
+			{Key: "start_date", Name: "Start Date (YYYY-MM-DD)", Type: "date", Required: true},
+			{Key: "end_date", Name: "End Date (YYYY-MM-DD)", Type: "date", Required: true},
 			{Key: "timezone", Name: "Timezone", Type: "timezone", Required: false, Default: "UTC"},
 			{Key: "accommodation", Name: "Accommodation", Type: "text", Required: false},
 			{Key: "notes", Name: "Notes", Type: "text", Required: false},
@@ -285,8 +310,8 @@ func (tm *TemplateManager) registerBuiltinTemplates() {
 		Description: "Deadline with countdown reminders (ADHD-friendly)",
 		Fields: []Field{
 			{Key: "task", Name: "Task/Project", Type: "text", Required: true},
-			{Key: "due_date", Name: "Due Date (YYYY-MM-DD)", Type: "datetime", Required: true},
-			{Key: "priority", Name: "Priority (1-9, 1=highest)", Type: "number", Required: false, Default: "5"},
+			{Key: "due_date", Name: "Due Date (YYYY-MM-DD)", Type: "date", Required: true},
+			{Key: "priority", Name: "Priority (1-9, 1=highest)", Type: "number", Required: false, Default: "5", Min: bound(1), Max: bound(9)},
 			{Key: "timezone", Name: "Timezone", Type: "timezone", Required: false, Default: "UTC"},
 			{Key: "notes", Name: "Notes", Type: "text", Required: false},
 		},
@@ -0,0 +1,524 @@
+package templates
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"tempus/internal/constants"
+)
+
+// A parsed text template is a list of nodes: literal text, {{expressions}}
+// and {{#sections}}. Expressions are pipelines of commands in the style of
+// Go's text/template, with the field name as the common case:
+//
+//	{{title}}                         field value
+//	{{slug title}}                    function call
+//	{{room | default "TBD"}}          the piped value is the last argument
+//	{{date (add start_time "-1d")}}   parentheses nest pipelines
+//
+// Bare words are field names (or number literals); other literals are quoted.
+type tmplNode struct {
+	text    string
+	expr    tmplPipe
+	section *tmplSection
+}
+
+type tmplSection struct {
+	cond     tmplPipe
+	inverted bool
+	body     []tmplNode
+	orElse   []tmplNode
+}
+
+type tmplPipe []tmplCmd
+
+type tmplCmd struct {
+	fn   string // empty for a single operand
+	args []tmplArg
+}
+
+type tmplArg struct {
+	literal string
+	isLit   bool
+	field   string
+	sub     tmplPipe
+}
+
+type tmplFunc struct {
+	arity int // including a piped value
+	call  func(args []string) (string, error)
+}
+
+var tmplFuncs map[string]tmplFunc
+
+func init() {
+	cmp := func(ok func(int) bool) func([]string) (string, error) {
+		return func(a []string) (string, error) { return truth(ok(compareValues(a[0], a[1]))), nil }
+	}
+	arith := func(op byte) func([]string) (string, error) {
+		return func(a []string) (string, error) { return arithmetic(op, a[0], a[1]) }
+	}
+	tmplFuncs = map[string]tmplFunc{
+		"slug":    {1, func(a []string) (string, error) { return slugify(a[0]), nil }},
+		"date":    {1, func(a []string) (string, error) { return extractDate(a[0]), nil }},
+		"time":    {1, func(a []string) (string, error) { return extractTime(a[0]), nil }},
+		"upper":   {1, func(a []string) (string, error) { return strings.ToUpper(a[0]), nil }},
+		"lower":   {1, func(a []string) (string, error) { return strings.ToLower(a[0]), nil }},
+		"trim":    {1, func(a []string) (string, error) { return strings.TrimSpace(a[0]), nil }},
+		"default": {2, func(a []string) (string, error) { return firstSet(a[1], a[0]), nil }},
+		"not":     {1, func(a []string) (string, error) { return truth(!isTruthy(a[0])), nil }},
+		"eq":      {2, cmp(func(c int) bool { return c == 0 })},
+		"ne":      {2, cmp(func(c int) bool { return c != 0 })},
+		"lt":      {2, cmp(func(c int) bool { return c < 0 })},
+		"gt":      {2, cmp(func(c int) bool { return c > 0 })},
+		"add":     {2, arith('+')},
+		"sub":     {2, arith('-')},
+		"mul":     {2, arith('*')},
+		"div":     {2, arith('/')},
+	}
+}
+
+// CheckTmpl reports syntax errors in a text template: unknown functions,
+// wrong argument counts, unbalanced quotes or parentheses and unclosed
+// sections. RenderTmpl itself is lenient and leaves such tags as written.
+func CheckTmpl(tmpl string) error {
+	_, err := parseTmpl(tmpl, true)
+	return err
+}
+
+// sectionFrame is an open {{#...}} or {{^...}} while parsing.
+type sectionFrame struct {
+	raw     string
+	name    string
+	section *tmplSection
+	inElse  bool
+	elseRaw string
+}
+
+func (f *sectionFrame) add(n tmplNode) {
+	if f.inElse {
+		f.section.orElse = append(f.section.orElse, n)
+	} else {
+		f.section.body = append(f.section.body, n)
+	}
+}
+
+// parseTmpl splits tmpl into nodes. In lenient mode malformed tags are kept
+// as literal text; in strict mode they are errors.
+func parseTmpl(tmpl string, strict bool) ([]tmplNode, error) {
+	var (
+		nodes []tmplNode
+		stack []*sectionFrame
+	)
+	emit := func(n tmplNode) {
+		if len(stack) > 0 {
+			stack[len(stack)-1].add(n)
+		} else {
+			nodes = append(nodes, n)
+		}
+	}
+	rest := tmpl
+	for rest != "" {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			emit(tmplNode{text: rest})
+			break
+		}
+		end := strings.Index(rest[start+2:], "}}")
+		if end < 0 {
+			if strict {
+				return nil, fmt.Errorf("unclosed tag %q", rest[start:])
+			}
+			emit(tmplNode{text: rest})
+			break
+		}
+		if start > 0 {
+			emit(tmplNode{text: rest[:start]})
+		}
+		raw := rest[start : start+2+end+2]
+		inner := strings.TrimSpace(raw[2 : len(raw)-2])
+		rest = rest[start+len(raw):]
+
+		var err error
+		switch {
+		case strings.HasPrefix(inner, "#") || strings.HasPrefix(inner, "^"):
+			var frame *sectionFrame
+			if frame, err = openSection(raw, inner); err == nil {
+				stack = append(stack, frame)
+				continue
+			}
+		case strings.HasPrefix(inner, "/"):
+			name := strings.TrimSpace(inner[1:])
+			if len(stack) > 0 && stack[len(stack)-1].name == name {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				emit(tmplNode{section: top.section})
+				continue
+			}
+			err = fmt.Errorf("%s has no matching opening tag", raw)
+		case inner == "else":
+			if len(stack) > 0 && !stack[len(stack)-1].inElse {
+				stack[len(stack)-1].inElse = true
+				stack[len(stack)-1].elseRaw = raw
+				continue
+			}
+			err = fmt.Errorf("%s outside a section", raw)
+		default:
+			var pipe tmplPipe
+			if pipe, err = parsePipeline(inner); err == nil {
+				emit(tmplNode{expr: pipe})
+				continue
+			}
+		}
+		if strict {
+			return nil, err
+		}
+		emit(tmplNode{text: raw})
+	}
+
+	// Unclosed sections: an error, or their tags become literal text.
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		if strict {
+			return nil, fmt.Errorf("%s is never closed", top.raw)
+		}
+		stack = stack[:len(stack)-1]
+		emit(tmplNode{text: top.raw})
+		for _, n := range top.section.body {
+			emit(n)
+		}
+		if top.inElse {
+			emit(tmplNode{text: top.elseRaw})
+			for _, n := range top.section.orElse {
+				emit(n)
+			}
+		}
+	}
+	return nodes, nil
+}
+
+// openSection parses "#name", "#if expr", "#fn args" or "^name".
+// The closing tag repeats the first word: {{/name}}, {{/if}}, {{/fn}}.
+func openSection(raw, inner string) (*sectionFrame, error) {
+	inverted := inner[0] == '^'
+	body := strings.TrimSpace(inner[1:])
+	name, cond, _ := strings.Cut(body, " ")
+	if name == "" {
+		return nil, fmt.Errorf("%s has no condition", raw)
+	}
+	if name != "if" {
+		cond = body
+	}
+	pipe, err := parsePipeline(cond)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", raw, err)
+	}
+	return &sectionFrame{raw: raw, name: name, section: &tmplSection{cond: pipe, inverted: inverted}}, nil
+}
+
+// tmplToken is a lexed piece of an expression: a word, a quoted string or
+// one of the punctuation characters ( ) |.
+type tmplToken struct {
+	text   string
+	quoted bool
+}
+
+func lexExpr(s string) ([]tmplToken, error) {
+	var toks []tmplToken
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')' || c == '|':
+			toks = append(toks, tmplToken{text: string(c)})
+			i++
+		case c == '"':
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string in %q", s)
+			}
+			lit, err := strconv.Unquote(s[i : j+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string %s", s[i:j+1])
+			}
+			toks = append(toks, tmplToken{text: lit, quoted: true})
+			i = j + 1
+		default:
+			j := i
+			for j < len(s) && !strings.ContainsRune(" \t\n()|\"", rune(s[j])) {
+				j++
+			}
+			toks = append(toks, tmplToken{text: s[i:j]})
+			i = j
+		}
+	}
+	return toks, nil
+}
+
+func parsePipeline(s string) (tmplPipe, error) {
+	toks, err := lexExpr(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(toks) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	pipe, n, err := parsePipe(toks)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(toks) {
+		return nil, fmt.Errorf("unexpected %q in %q", toks[n].text, s)
+	}
+	return pipe, nil
+}
+
+// parsePipe consumes commands separated by | until a closing parenthesis or
+// the end, returning the number of tokens used.
+func parsePipe(toks []tmplToken) (tmplPipe, int, error) {
+	var pipe tmplPipe
+	i := 0
+	for {
+		cmd, n, err := parseCmd(toks[i:], len(pipe) > 0)
+		if err != nil {
+			return nil, 0, err
+		}
+		pipe = append(pipe, cmd)
+		i += n
+		if i >= len(toks) || toks[i].quoted || toks[i].text != "|" {
+			return pipe, i, nil
+		}
+		i++
+	}
+}
+
+func parseCmd(toks []tmplToken, piped bool) (tmplCmd, int, error) {
+	var args []tmplArg
+	i := 0
+	for i < len(toks) {
+		t := toks[i]
+		if !t.quoted && (t.text == "|" || t.text == ")") {
+			break
+		}
+		switch {
+		case t.quoted:
+			args = append(args, tmplArg{literal: t.text, isLit: true})
+			i++
+		case t.text == "(":
+			sub, n, err := parsePipe(toks[i+1:])
+			if err != nil {
+				return tmplCmd{}, 0, err
+			}
+			i += 1 + n
+			if i >= len(toks) || toks[i].quoted || toks[i].text != ")" {
+				return tmplCmd{}, 0, fmt.Errorf("missing )")
+			}
+			args = append(args, tmplArg{sub: sub})
+			i++
+		default:
+			if _, err := strconv.ParseFloat(t.text, 64); err == nil {
+				args = append(args, tmplArg{literal: t.text, isLit: true})
+			} else {
+				args = append(args, tmplArg{field: t.text})
+			}
+			i++
+		}
+	}
+	if len(args) == 0 {
+		return tmplCmd{}, 0, fmt.Errorf("missing expression")
+	}
+	// A lone word is a field lookup, so {{date}} still means the field "date".
+	if len(args) == 1 && !piped {
+		return tmplCmd{args: args}, i, nil
+	}
+	name := args[0].field
+	fn, ok := tmplFuncs[name]
+	if !ok {
+		return tmplCmd{}, 0, fmt.Errorf("unknown function %q", firstSet(name, args[0].literal))
+	}
+	args = args[1:]
+	if got := len(args) + boolInt(piped); got != fn.arity {
+		return tmplCmd{}, 0, fmt.Errorf("%s takes %d argument(s), got %d", name, fn.arity, got)
+	}
+	return tmplCmd{fn: name, args: args}, i, nil
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (p tmplPipe) eval(values map[string]string) (string, error) {
+	var v string
+	for i, cmd := range p {
+		args := make([]string, 0, len(cmd.args)+1)
+		for _, a := range cmd.args {
+			switch {
+			case a.isLit:
+				args = append(args, a.literal)
+			case a.sub != nil:
+				s, err := a.sub.eval(values)
+				if err != nil {
+					return "", err
+				}
+				args = append(args, s)
+			default:
+				args = append(args, values[a.field])
+			}
+		}
+		if i > 0 {
+			args = append(args, v)
+		}
+		if cmd.fn == "" {
+			v = args[0]
+			continue
+		}
+		var err error
+		if v, err = tmplFuncs[cmd.fn].call(args); err != nil {
+			return "", fmt.Errorf("%s: %w", cmd.fn, err)
+		}
+	}
+	return v, nil
+}
+
+func renderNodes(sb *strings.Builder, nodes []tmplNode, values map[string]string) error {
+	for _, n := range nodes {
+		switch {
+		case n.section != nil:
+			v, err := n.section.cond.eval(values)
+			if err != nil {
+				return err
+			}
+			body := n.section.body
+			if isTruthy(v) == n.section.inverted {
+				body = n.section.orElse
+			}
+			if err := renderNodes(sb, body, values); err != nil {
+				return err
+			}
+		case n.expr != nil:
+			v, err := n.expr.eval(values)
+			if err != nil {
+				return err
+			}
+			sb.WriteString(v)
+		default:
+			sb.WriteString(n.text)
+		}
+	}
+	return nil
+}
+
+// isTruthy matches the section rule: a value counts when it is not blank.
+func isTruthy(v string) bool { return strings.TrimSpace(v) != "" }
+
+// truth is the result of a condition: "true", or empty for false so that
+// {{#eq ...}} sections behave like {{#field}} ones.
+func truth(b bool) string {
+	if b {
+		return "true"
+	}
+	return ""
+}
+
+func firstSet(values ...string) string {
+	for _, v := range values {
+		if isTruthy(v) {
+			return v
+		}
+	}
+	return ""
+}
+
+// compareValues orders two values as numbers, then as dates, then as text.
+func compareValues(a, b string) int {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	ta, _, errA := parseDateOrDateTimeInLocation(a, "UTC")
+	tb, _, errB := parseDateOrDateTimeInLocation(b, "UTC")
+	if errA == nil && errB == nil {
+		return ta.Compare(tb)
+	}
+	return strings.Compare(a, b)
+}
+
+// arithmetic applies op to two numbers, or shifts a date/time by a duration
+// or offset for + and - ("90", "1h30m", "-1d"). A blank operand gives a
+// blank result so optional fields can fall back with default.
+func arithmetic(op byte, a, b string) (string, error) {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	if a == "" || b == "" {
+		return "", nil
+	}
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		var r float64
+		switch op {
+		case '+':
+			r = x + y
+		case '-':
+			r = x - y
+		case '*':
+			r = x * y
+		case '/':
+			if y == 0 {
+				return "", fmt.Errorf("division by zero")
+			}
+			r = x / y
+		}
+		return formatBound(r), nil
+	}
+	if op == '+' || op == '-' {
+		base, shift := a, b
+		if op == '+' && errA == nil {
+			base, shift = b, a
+		}
+		if t, dateOnly, err := parseDateOrDateTimeInLocation(base, "UTC"); err == nil {
+			days, rest, err := parseOffset(shift)
+			if err != nil {
+				return "", fmt.Errorf("%q is not a duration", shift)
+			}
+			if op == '-' {
+				days, rest = -days, -rest
+			}
+			t = t.AddDate(0, 0, days).Add(rest)
+			if dateOnly && rest == 0 {
+				return t.Format(constants.DateFormatISO), nil
+			}
+			return t.Format(constants.DateTimeFormatISO), nil
+		}
+	}
+	return "", fmt.Errorf("cannot combine %q and %q", a, b)
+}
+
+func extractTime(value string) string {
+	v := strings.TrimSpace(strings.ReplaceAll(value, "T", " "))
+	if t, dateOnly, err := parseDateOrDateTimeInLocation(v, "UTC"); err == nil && !dateOnly {
+		return t.Format(constants.TimeFormatHHMM)
+	}
+	if t, err := time.Parse(constants.TimeFormatHHMM, v); err == nil {
+		return t.Format(constants.TimeFormatHHMM)
+	}
+	return ""
+}
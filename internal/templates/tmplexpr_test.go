@@ -0,0 +1,127 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderTmplExpressions(t *testing.T) {
+	values := map[string]string{
+		"title":      "Dentist",
+		"confirm":    "AB12",
+		"start_time": "2025-12-01 09:30",
+		"day":        "2025-12-01",
+		"duration":   "90",
+		"room":       "B",
+		"guests":     "3",
+	}
+	tests := []struct {
+		tmpl string
+		want string
+	}{
+		{`{{title}}{{#confirm}} (confirmation {{confirm}}){{/confirm}}`, "Dentist (confirmation AB12)"},
+		{`{{title}}{{#missing}} ({{missing}}){{/missing}}`, "Dentist"},
+		{`{{^missing}}no code{{/missing}}`, "no code"},
+		{`{{#missing}}code{{else}}no code{{/missing}}`, "no code"},
+		{`{{missing | default "TBD"}} / {{room | default "TBD"}}`, "TBD / B"},
+		{`{{room | lower}} {{upper title}}`, "b DENTIST"},
+		{`Ends {{add start_time duration}}`, "Ends 2025-12-01 11:00"},
+		{`{{time (add start_time "1h15m")}}`, "10:45"},
+		{`{{sub start_time "-1d"}} {{add day "2d"}} {{sub day 30}}`, "2025-12-02 09:30 2025-12-03 2025-11-30 23:30"},
+		{`{{add guests 1}} {{mul guests 2.5}} {{div guests 2}} {{sub guests 5}}`, "4 7.5 1.5 -2"},
+		{`{{add start_time missing | default "open-ended"}}`, "open-ended"},
+		{`{{#if eq room "B"}}Room B{{else}}Elsewhere{{/if}}`, "Room B"},
+		{`{{#if gt guests 5}}Big{{else}}Small{{/if}}`, "Small"},
+		{`{{#if not missing}}unset{{/if}}{{#ne room "A"}}!{{/ne}}`, "unset!"},
+		{`{{#confirm}}{{#if lt day "2026-01-01"}}[{{confirm}}]{{/if}}{{/confirm}}`, "[AB12]"},
+		{`{{ title }} {{date}}`, "Dentist "},
+		{`{{unknown title}} {{#open}}x`, "{{unknown title}} {{#open}}x"},
+	}
+	for _, tt := range tests {
+		got, err := RenderTmpl(tt.tmpl, values, nil)
+		if err != nil {
+			t.Errorf("RenderTmpl(%q) unexpected error: %v", tt.tmpl, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("RenderTmpl(%q) = %q, want %q", tt.tmpl, got, tt.want)
+		}
+	}
+
+	if _, err := RenderTmpl(`{{add title duration}}`, values, nil); err == nil || !strings.Contains(err.Error(), "add: cannot combine") {
+		t.Errorf("expected an arithmetic error, got %v", err)
+	}
+	if _, err := RenderTmpl(`{{div guests 0}}`, values, nil); err == nil {
+		t.Error("expected division by zero to fail")
+	}
+}
+
+func TestCheckTmpl(t *testing.T) {
+	for _, ok := range []string{
+		"",
+		"Plain",
+		`{{#if eq a "x"}}{{a}}{{else}}{{b | default "-"}}{{/if}}`,
+		`{{date (add start "1d")}}`,
+	} {
+		if err := CheckTmpl(ok); err != nil {
+			t.Errorf("CheckTmpl(%q) unexpected error: %v", ok, err)
+		}
+	}
+	tests := map[string]string{
+		`{{#a}}x`:             "never closed",
+		`{{#a}}x{{/b}}`:       "no matching opening tag",
+		`{{else}}`:            "outside a section",
+		`{{shout a}}`:         `unknown function "shout"`,
+		`{{add a}}`:           "add takes 2 argument(s), got 1",
+		`{{a | default}}`:     "default takes 2 argument(s), got 1",
+		`{{default "x" a b}}`: "default takes 2 argument(s), got 3",
+		`{{date (a}}`:         "missing )",
+		`{{a | "x}}`:          "unterminated string",
+		`{{a`:                 "unclosed tag",
+	}
+	for tmpl, want := range tests {
+		if err := CheckTmpl(tmpl); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("CheckTmpl(%q) error = %v, want %q", tmpl, err, want)
+		}
+	}
+}
+
+func TestValidateDDTemplateChecksTemplateText(t *testing.T) {
+	dd := DataDrivenTemplate{
+		SchemaVersion: CurrentSchemaVersion,
+		Name:          "check",
+		Fields:        []Field{{Key: "start", Type: "datetime", Required: true}},
+		Output:        OutputTemplate{StartField: "start", SummaryTmpl: "Call", DescriptionTmpl: "{{#start}}at {{start}}"},
+	}
+	err := ValidateDDTemplate(&dd)
+	if err == nil || !strings.Contains(err.Error(), "output.description_tmpl") {
+		t.Errorf("expected a description_tmpl error, got %v", err)
+	}
+}
+
+func TestRenderDDToEventComputedText(t *testing.T) {
+	tm := NewTemplateManager()
+	dd := &DataDrivenTemplate{
+		Name:   "visit",
+		Fields: []Field{{Key: "start"}, {Key: "stay"}, {Key: "code"}},
+		Output: OutputTemplate{
+			StartField:      "start",
+			DurationField:   "stay",
+			SummaryTmpl:     "Visit{{#code}} #{{code}}{{/code}}",
+			DescriptionTmpl: "Leave by {{time (add start stay)}}",
+		},
+	}
+	ev, err := tm.renderDDToEvent(dd, map[string]string{"start": "2025-12-01 10:00", "stay": "45m"}, nil)
+	if err != nil {
+		t.Fatalf("renderDDToEvent() failed: %v", err)
+	}
+	if ev.Summary != "Visit" || ev.Description != "Leave by 10:45" {
+		t.Errorf("got %q / %q", ev.Summary, ev.Description)
+	}
+
+	dd.Output.SummaryTmpl = "{{mul code 2}}"
+	_, err = tm.renderDDToEvent(dd, map[string]string{"start": "2025-12-01 10:00", "code": "AB"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "summary_tmpl") {
+		t.Errorf("expected a summary_tmpl error, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package timezone
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"strings"
+	"sync"
+)
+
+//go:embed data/airports.tab
+var airportsTab []byte
+
+// Airport is an airport and the IANA zone its local times are in.
+type Airport struct {
+	Code string // IATA code, e.g. MAD
+	Zone string // IANA timezone, e.g. Europe/Madrid
+	Name string
+}
+
+var (
+	airportsOnce sync.Once
+	airports     map[string]Airport
+)
+
+// LookupAirport finds an airport by its IATA code (any case) in the embedded
+// airport table.
+func LookupAirport(code string) (Airport, bool) {
+	airportsOnce.Do(func() { airports = parseAirportsTab(airportsTab) })
+	a, ok := airports[strings.ToUpper(strings.TrimSpace(code))]
+	return a, ok
+}
+
+// AirportTimezone returns the IANA zone of the airport with the given IATA
+// code, or "" and false when the code is not in the table.
+func AirportTimezone(code string) (string, bool) {
+	a, ok := LookupAirport(code)
+	return a.Zone, ok
+}
+
+func parseAirportsTab(data []byte) map[string]Airport {
+	out := make(map[string]Airport, 400)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// Format: CODE<TAB>Zone<TAB>Name
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) < 2 {
+			continue
+		}
+		a := Airport{Code: strings.TrimSpace(parts[0]), Zone: strings.TrimSpace(parts[1])}
+		if len(parts) == 3 {
+			a.Name = strings.TrimSpace(parts[2])
+		}
+		if a.Code != "" && a.Zone != "" {
+			out[a.Code] = a
+		}
+	}
+	return out
+}
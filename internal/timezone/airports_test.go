@@ -0,0 +1,49 @@
+package timezone
+
+import (
+	"testing"
+	"time"
+
+	"tempus/internal/testutil"
+)
+
+func TestLookupAirport(t *testing.T) {
+	tests := map[string]string{
+		"MAD":  testutil.TZEuropeMadrid,
+		"jfk":  testutil.TZAmericaNewYork,
+		" DUB": testutil.TZEuropeDublin,
+		"LPA":  testutil.TZAtlanticCanary,
+		"GRU":  testutil.TZAmericaSaoPaulo,
+	}
+	for code, want := range tests {
+		if got, ok := AirportTimezone(code); !ok || got != want {
+			t.Errorf("AirportTimezone(%q) = %q, %v; want %q", code, got, ok, want)
+		}
+	}
+	if a, ok := LookupAirport("MAD"); !ok || a.Name != "Madrid Barajas" {
+		t.Errorf("LookupAirport(MAD) = %+v, %v", a, ok)
+	}
+	for _, code := range []string{"", "XXX", "MADR"} {
+		if _, ok := AirportTimezone(code); ok {
+			t.Errorf("AirportTimezone(%q): expected no airport", code)
+		}
+	}
+}
+
+func TestAirportZonesLoad(t *testing.T) {
+	all := parseAirportsTab(airportsTab)
+	if len(all) < 300 {
+		t.Fatalf("expected the embedded table to hold at least 300 airports, got %d", len(all))
+	}
+	for code, a := range all {
+		if len(code) != 3 {
+			t.Errorf("airport code %q is not three letters", code)
+		}
+		if _, err := time.LoadLocation(a.Zone); err != nil {
+			t.Errorf("%s maps to %s, which does not load: %v", code, a.Zone, err)
+		}
+		if _, deprecated := CanonicalName(a.Zone); deprecated {
+			t.Errorf("%s maps to the deprecated zone %s", code, a.Zone)
+		}
+	}
+}
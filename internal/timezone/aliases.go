@@ -0,0 +1,111 @@
+package timezone
+
+import "strings"
+
+// deprecatedZones maps obsolete or backward-compatibility IANA names (from the
+// tzdata "backward" file) to the canonical zone that replaced them. Clients such
+// as Outlook and Google Calendar warn about, or silently ignore, the old names.
+var deprecatedZones = map[string]string{
+	// Renamed zones
+	"Europe/Kiev":           "Europe/Kyiv",
+	"Europe/Uzhgorod":       "Europe/Kyiv",
+	"Europe/Zaporozhye":     "Europe/Kyiv",
+	"Asia/Calcutta":         "Asia/Kolkata",
+	"Asia/Saigon":           "Asia/Ho_Chi_Minh",
+	"Asia/Katmandu":         "Asia/Kathmandu",
+	"Asia/Rangoon":          "Asia/Yangon",
+	"Asia/Dacca":            "Asia/Dhaka",
+	"Asia/Thimbu":           "Asia/Thimphu",
+	"Asia/Ulan_Bator":       "Asia/Ulaanbaatar",
+	"Asia/Macao":            "Asia/Macau",
+	"Asia/Ujung_Pandang":    "Asia/Makassar",
+	"Asia/Chongqing":        "Asia/Shanghai",
+	"Asia/Chungking":        "Asia/Shanghai",
+	"Asia/Harbin":           "Asia/Shanghai",
+	"Asia/Tel_Aviv":         "Asia/Jerusalem",
+	"Asia/Istanbul":         "Europe/Istanbul",
+	"Atlantic/Faeroe":       "Atlantic/Faroe",
+	"America/Godthab":       "America/Nuuk",
+	"America/Buenos_Aires":  "America/Argentina/Buenos_Aires",
+	"America/Indianapolis":  "America/Indiana/Indianapolis",
+	"America/Louisville":    "America/Kentucky/Louisville",
+	"America/Montreal":      "America/Toronto",
+	"America/Fort_Wayne":    "America/Indiana/Indianapolis",
+	"Pacific/Enderbury":     "Pacific/Kanton",
+	"Pacific/Truk":          "Pacific/Chuuk",
+	"Pacific/Ponape":        "Pacific/Pohnpei",
+	"Australia/Canberra":    "Australia/Sydney",
+	"Australia/ACT":         "Australia/Sydney",
+	"Australia/NSW":         "Australia/Sydney",
+	"Australia/Victoria":    "Australia/Melbourne",
+	"Australia/Queensland":  "Australia/Brisbane",
+	"Australia/West":        "Australia/Perth",
+	"Australia/South":       "Australia/Adelaide",
+	"Australia/North":       "Australia/Darwin",
+	"Australia/Tasmania":    "Australia/Hobart",
+	"Europe/Belfast":        "Europe/London",
+	"Europe/Tiraspol":       "Europe/Chisinau",
+	"Antarctica/South_Pole": "Pacific/Auckland",
+
+	// US/* and Canada/* legacy links
+	"US/Eastern":          "America/New_York",
+	"US/Central":          "America/Chicago",
+	"US/Mountain":         "America/Denver",
+	"US/Pacific":          "America/Los_Angeles",
+	"US/Alaska":           "America/Anchorage",
+	"US/Hawaii":           "Pacific/Honolulu",
+	"US/Arizona":          "America/Phoenix",
+	"US/East-Indiana":     "America/Indiana/Indianapolis",
+	"US/Michigan":         "America/Detroit",
+	"US/Aleutian":         "America/Adak",
+	"US/Samoa":            "Pacific/Pago_Pago",
+	"Canada/Atlantic":     "America/Halifax",
+	"Canada/Central":      "America/Winnipeg",
+	"Canada/Eastern":      "America/Toronto",
+	"Canada/Mountain":     "America/Edmonton",
+	"Canada/Newfoundland": "America/St_Johns",
+	"Canada/Pacific":      "America/Vancouver",
+
+	// Country-name links
+	"GB":        "Europe/London",
+	"Eire":      "Europe/Dublin",
+	"Portugal":  "Europe/Lisbon",
+	"Poland":    "Europe/Warsaw",
+	"Turkey":    "Europe/Istanbul",
+	"Israel":    "Asia/Jerusalem",
+	"Egypt":     "Africa/Cairo",
+	"Iran":      "Asia/Tehran",
+	"Japan":     "Asia/Tokyo",
+	"ROK":       "Asia/Seoul",
+	"PRC":       "Asia/Shanghai",
+	"ROC":       "Asia/Taipei",
+	"Hongkong":  "Asia/Hong_Kong",
+	"Singapore": "Asia/Singapore",
+	"Cuba":      "America/Havana",
+	"Jamaica":   "America/Jamaica",
+	"NZ":        "Pacific/Auckland",
+	"Iceland":   "Atlantic/Reykjavik",
+	"Libya":     "Africa/Tripoli",
+	"Navajo":    "America/Denver",
+	"Zulu":      "UTC",
+	"UCT":       "UTC",
+	"Universal": "UTC",
+	"Etc/UCT":   "UTC",
+	"Etc/Zulu":  "UTC",
+}
+
+// CanonicalName returns the current IANA name for a deprecated zone name
+// (matched case-insensitively) and true, or the trimmed input and false when
+// the name is already canonical or unknown.
+func CanonicalName(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if canonical, ok := deprecatedZones[name]; ok {
+		return canonical, true
+	}
+	for alias, canonical := range deprecatedZones {
+		if strings.EqualFold(alias, name) {
+			return canonical, true
+		}
+	}
+	return name, false
+}
@@ -0,0 +1,51 @@
+package timezone
+
+import (
+	"testing"
+	"time"
+
+	"tempus/internal/testutil"
+)
+
+func TestCanonicalName(t *testing.T) {
+	tests := []struct {
+		in         string
+		want       string
+		deprecated bool
+	}{
+		{"Europe/Kiev", "Europe/Kyiv", true},
+		{"Asia/Calcutta", "Asia/Kolkata", true},
+		{"US/Eastern", testutil.TZAmericaNewYork, true},
+		{" us/pacific ", "America/Los_Angeles", true},
+		{testutil.TZEuropeMadrid, testutil.TZEuropeMadrid, false},
+		{"NotATimezone", "NotATimezone", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, deprecated := CanonicalName(tt.in)
+		if got != tt.want || deprecated != tt.deprecated {
+			t.Errorf("CanonicalName(%q) = %q, %v; want %q, %v", tt.in, got, deprecated, tt.want, tt.deprecated)
+		}
+	}
+}
+
+func TestDeprecatedZonesResolveToLoadableZones(t *testing.T) {
+	for alias, canonical := range deprecatedZones {
+		if _, err := time.LoadLocation(canonical); err != nil {
+			t.Errorf("%s maps to %s, which does not load: %v", alias, canonical, err)
+		}
+		if _, again := CanonicalName(canonical); again {
+			t.Errorf("%s maps to %s, which is itself deprecated", alias, canonical)
+		}
+	}
+}
+
+func TestGetTimezoneResolvesDeprecatedName(t *testing.T) {
+	tz, err := NewTimezoneManager().GetTimezone("Asia/Calcutta")
+	if err != nil {
+		t.Fatalf("GetTimezone returned error: %v", err)
+	}
+	if tz.IANA != "Asia/Kolkata" {
+		t.Errorf("IANA = %q, want Asia/Kolkata", tz.IANA)
+	}
+}
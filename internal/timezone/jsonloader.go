@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
 // jsonZone is a single zone entry in a JSON file.
@@ -79,6 +80,7 @@ func (tm *TimezoneManager) loadJSONFile(path string) error {
 
 	tm.processZones(jf.Zones)
 	tm.processGlobalAliases(jf.Aliases)
+	tm.searchOnce, tm.names = sync.Once{}, nil // rebuild the search index
 	return nil
 }
 
@@ -98,13 +100,18 @@ func (tm *TimezoneManager) createTimezoneInfo(z jsonZone) *TimezoneInfo {
 	if z.DST != nil {
 		dst = *z.DST
 	}
-	return &TimezoneInfo{
+	ti := &TimezoneInfo{
 		IANA:        z.IANA,
 		DisplayName: valueOr(z.DisplayName, z.IANA),
 		Country:     valueOr(z.Country, "Unknown"),
+		Region:      RegionOf(z.IANA),
 		Offset:      getTimezoneOffset(z.IANA),
 		DST:         dst,
 	}
+	if tab, ok := tm.zones[z.IANA]; ok {
+		ti.CountryCodes, ti.Comment = tab.CountryCodes, tab.Comment
+	}
+	return ti
 }
 
 func (tm *TimezoneManager) processZoneAliases(aliases []string, ti *TimezoneInfo) {
@@ -0,0 +1,256 @@
+package timezone
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"sort"
+	"strings"
+	"sync"
+
+	"tempus/internal/utils"
+)
+
+//go:embed data/cities.tab
+var citiesTab []byte
+
+// City is a place and the IANA zone its clocks follow.
+type City struct {
+	Name    string
+	Zone    string
+	Country string   // ISO 3166 code
+	Aliases []string // other spellings, e.g. Lisboa for Lisbon
+}
+
+var (
+	citiesOnce sync.Once
+	cities     []City
+)
+
+// Cities returns the embedded city table.
+func Cities() []City {
+	citiesOnce.Do(func() { cities = parseCitiesTab(citiesTab) })
+	return cities
+}
+
+func parseCitiesTab(data []byte) []City {
+	out := make([]City, 0, 500)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// Format: Name<TAB>Zone<TAB>CC<TAB>aliases
+		parts := strings.Split(line, "\t")
+		if len(parts) < 2 {
+			continue
+		}
+		c := City{Name: strings.TrimSpace(parts[0]), Zone: strings.TrimSpace(parts[1])}
+		if len(parts) > 2 {
+			c.Country = strings.TrimSpace(parts[2])
+		}
+		if len(parts) > 3 {
+			for _, a := range strings.Split(parts[3], ",") {
+				if a = strings.TrimSpace(a); a != "" {
+					c.Aliases = append(c.Aliases, a)
+				}
+			}
+		}
+		if c.Name != "" && c.Zone != "" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// SearchResult is a zone found by Search and the name it matched.
+type SearchResult struct {
+	Zone  *TimezoneInfo
+	Match string  // the city, alias or zone name that matched the query
+	Score float64 // 1 for an exact match, lower for prefix, substring and typo matches
+}
+
+// Search scores.
+const (
+	scoreExact     = 1.0
+	scorePrefix    = 0.85 // plus up to 0.1 for covering more of the name
+	scoreWord      = 0.8  // the query starts a later word ("york")
+	scoreSubstring = 0.7
+	scoreFuzzy     = 0.65 // scaled down by the share of edits
+	scoreCountry   = 0.9  // factor for matches on a country name
+)
+
+// searchName is one name a zone can be found by.
+type searchName struct {
+	key    string // normalized for matching
+	label  string
+	iana   string
+	weight float64
+}
+
+// Search finds zones by city, alias, country or IANA name, tolerating typos
+// ("nw york", "lisbn") and accents ("Sao Paulo" for São Paulo). Results are
+// ranked best first, one per zone, at most limit of them (0 for all).
+func (tm *TimezoneManager) Search(query string, limit int) []SearchResult {
+	q := normalizeSearch(query)
+	if q == "" {
+		return nil
+	}
+	best := map[string]SearchResult{}
+	for _, n := range tm.searchNames() {
+		score := matchScore(q, n.key) * n.weight
+		if score == 0 {
+			continue
+		}
+		if cur, ok := best[n.iana]; ok && cur.Score >= score {
+			continue
+		}
+		zone, err := tm.GetTimezone(n.iana)
+		if err != nil {
+			continue
+		}
+		best[n.iana] = SearchResult{Zone: zone, Match: n.label, Score: score}
+	}
+
+	results := make([]SearchResult, 0, len(best))
+	for _, r := range best {
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].Match != results[j].Match {
+			return results[i].Match < results[j].Match
+		}
+		return results[i].Zone.IANA < results[j].Zone.IANA
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// Resolve returns the zone a free-form name clearly refers to: an exact or
+// alias match, or a search whose best result is ahead of the rest. ok is
+// false when nothing matches or the best matches are too close to call.
+func (tm *TimezoneManager) Resolve(query string) (*TimezoneInfo, bool) {
+	if zone, err := tm.GetTimezone(strings.TrimSpace(query)); err == nil {
+		return zone, true
+	}
+	results := tm.Search(query, 2)
+	if len(results) == 0 || results[0].Score < scoreFuzzy/2 {
+		return nil, false
+	}
+	if len(results) > 1 && results[0].Score-results[1].Score < 0.05 {
+		return nil, false
+	}
+	return results[0].Zone, true
+}
+
+// searchNames lists every name the manager's zones can be found by; it is
+// built on first use.
+func (tm *TimezoneManager) searchNames() []searchName {
+	tm.searchOnce.Do(func() {
+		add := func(label, iana string, weight float64) {
+			if key := normalizeSearch(label); key != "" {
+				tm.names = append(tm.names, searchName{key: key, label: label, iana: iana, weight: weight})
+			}
+		}
+		for _, z := range tm.ListTimezones() {
+			add(z.IANA, z.IANA, 1)
+			add(displayFromIANA(z.IANA), z.IANA, 1)
+			add(cleanSearchLabel(z.DisplayName), z.IANA, 1)
+			if z.Country != "" && z.Country != "Unknown" {
+				add(z.Country, z.IANA, scoreCountry)
+			}
+		}
+		for key, z := range tm.zones {
+			if z != nil && key != z.IANA {
+				add(key, z.IANA, 1)
+			}
+		}
+		for _, c := range Cities() {
+			add(c.Name, c.Zone, 1)
+			for _, a := range c.Aliases {
+				add(a, c.Zone, 1)
+			}
+		}
+	})
+	return tm.names
+}
+
+// matchScore rates how well the normalized query q matches the normalized
+// name; 0 means not at all.
+func matchScore(q, name string) float64 {
+	switch {
+	case q == name:
+		return scoreExact
+	case strings.HasPrefix(name, q):
+		return scorePrefix + 0.1*float64(len(q))/float64(len(name))
+	case strings.Contains(name, " "+q):
+		return scoreWord
+	case len(q) >= 3 && strings.Contains(name, q):
+		return scoreSubstring
+	}
+	// Typos: allow about one edit per three characters, none below four.
+	n := len([]rune(q))
+	if n < 4 {
+		return 0
+	}
+	allowed := max(1, n/3)
+	d := utils.Levenshtein(q, name)
+	if d > allowed {
+		return 0
+	}
+	return scoreFuzzy * (1 - float64(d)/float64(max(len([]rune(q)), len([]rune(name)))))
+}
+
+// normalizeSearch lowercases s, strips common accents and turns the
+// separators of IANA names into single spaces: "Europe/Sao_Paulo" and
+// "são paulo" both end in "sao paulo".
+func normalizeSearch(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		if folded, ok := accentFold[r]; ok {
+			r = folded
+		}
+		if r >= 0x300 && r <= 0x36f { // combining accents
+			continue
+		}
+		switch r {
+		case '/', '_', '-', ' ', '.', ',', '\'', '\t':
+			space = b.Len() > 0
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// cleanSearchLabel drops a trailing " (Country)" from a display name.
+func cleanSearchLabel(s string) string {
+	if i := strings.LastIndex(s, " ("); i > 0 && strings.HasSuffix(s, ")") {
+		return s[:i]
+	}
+	return s
+}
+
+var accentFold = map[rune]rune{
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a', 'ā': 'a',
+	'ç': 'c', 'ć': 'c', 'č': 'c',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e', 'ė': 'e', 'ę': 'e',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i', 'ı': 'i',
+	'ł': 'l', 'ñ': 'n', 'ń': 'n',
+	'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o', 'ø': 'o', 'ō': 'o',
+	'ș': 's', 'ş': 's', 'š': 's', 'ś': 's', 'ß': 's',
+	'ț': 't', 'ţ': 't',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u', 'ū': 'u',
+	'ý': 'y', 'ÿ': 'y', 'ž': 'z', 'ź': 'z', 'ż': 'z',
+}
@@ -0,0 +1,132 @@
+package timezone
+
+import (
+	"testing"
+	"time"
+
+	"tempus/internal/testutil"
+)
+
+func TestResolveCityNames(t *testing.T) {
+	tests := []struct {
+		city string
+		want string
+	}{
+		// Spain
+		{"madrid", testutil.TZEuropeMadrid},
+		{"barcelona", testutil.TZEuropeMadrid},
+		{"melilla", testutil.TZAfricaCeuta},
+		{"ceuta", testutil.TZAfricaCeuta},
+		{"canarias", testutil.TZAtlanticCanary},
+		{"tenerife", testutil.TZAtlanticCanary},
+
+		// Brazil
+		{"pelotas", testutil.TZAmericaSaoPaulo},
+		{"porto alegre", testutil.TZAmericaSaoPaulo},
+		{"campo grande", testutil.TZAmericaCampoGrande},
+		{"manaus", "America/Manaus"},
+		{"rio", testutil.TZAmericaSaoPaulo},
+		{"sao paulo", testutil.TZAmericaSaoPaulo},
+
+		// Ireland/UK
+		{"dublin", testutil.TZEuropeDublin},
+		{"london", testutil.TZEuropeLondon},
+
+		// Elsewhere, typos and accents
+		{"lisbon", "Europe/Lisbon"},
+		{"nw york", testutil.TZAmericaNewYork},
+		{"são paulo", testutil.TZAmericaSaoPaulo},
+		{"Cordoba", ""}, // Spain or Argentina: too close to call
+
+		// Unknown
+		{"xyzzy", ""},
+		{"", ""},
+	}
+
+	tm := NewTimezoneManager()
+	for _, tt := range tests {
+		t.Run(tt.city, func(t *testing.T) {
+			got := ""
+			if zone, ok := tm.Resolve(tt.city); ok {
+				got = zone.IANA
+			}
+			if got != tt.want {
+				t.Errorf("Resolve(%q) = %q, want %q", tt.city, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchRanksAndToleratesTypos(t *testing.T) {
+	tm := NewTimezoneManager()
+
+	results := tm.Search("nw york", 3)
+	if len(results) == 0 || results[0].Zone.IANA != testutil.TZAmericaNewYork || results[0].Match != "New York" {
+		t.Fatalf("Search(nw york) = %+v", results)
+	}
+
+	// A prefix shared by several cities ranks the closest first but does not resolve.
+	results = tm.Search("new", 0)
+	if len(results) < 3 || results[0].Zone.IANA != testutil.TZAmericaNewYork {
+		t.Errorf("Search(new) = %+v", results)
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i].Score > results[i-1].Score {
+			t.Fatalf("results not sorted by score: %+v", results)
+		}
+	}
+	if zone, ok := tm.Resolve("new"); ok {
+		t.Errorf("Resolve(new) = %s, want no single answer", zone.IANA)
+	}
+
+	// Transpositions count as two edits, so Madrid shows up as a suggestion.
+	var found bool
+	for _, r := range tm.Search("madird", 5) {
+		found = found || r.Zone.IANA == testutil.TZEuropeMadrid
+	}
+	if !found {
+		t.Error("Search(madird) should suggest Europe/Madrid")
+	}
+
+	// Aliases and IANA names with separators match too.
+	if r := tm.Search("Lisboa", 1); len(r) != 1 || r[0].Zone.IANA != "Europe/Lisbon" {
+		t.Errorf("Search(Lisboa) = %+v", r)
+	}
+	if r := tm.Search("america/sao paulo", 1); len(r) != 1 || r[0].Zone.IANA != testutil.TZAmericaSaoPaulo || r[0].Score != 1 {
+		t.Errorf("Search(america/sao paulo) = %+v", r)
+	}
+	if r := tm.Search("  ", 5); r != nil {
+		t.Errorf("Search(blank) = %+v", r)
+	}
+}
+
+func TestCitiesTable(t *testing.T) {
+	all := Cities()
+	if len(all) < 300 {
+		t.Fatalf("only %d cities", len(all))
+	}
+	for _, c := range all {
+		if _, err := time.LoadLocation(c.Zone); err != nil {
+			t.Errorf("%s: invalid zone %q", c.Name, c.Zone)
+		}
+		if len(c.Country) != 2 {
+			t.Errorf("%s: invalid country code %q", c.Name, c.Country)
+		}
+	}
+}
+
+func TestNormalizeSearch(t *testing.T) {
+	tests := map[string]string{
+		"  São Paulo ":      "sao paulo",
+		"America/Sao_Paulo": "america sao paulo",
+		"Port-au-Prince":    "port au prince",
+		"ZÜRICH":            "zurich",
+		"St. John's":        "st john s",
+		"Kraków//Gdańsk":    "krakow gdansk",
+	}
+	for in, want := range tests {
+		if got := normalizeSearch(in); got != want {
+			t.Errorf("normalizeSearch(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"tempus/internal/testutil"
@@ -11,16 +12,97 @@ import (
 
 // TimezoneInfo contains information about a timezone
 type TimezoneInfo struct {
-	IANA        string
-	DisplayName string
-	Country     string
-	Offset      string // computed at load-time for "now"
-	DST         bool   // whether this zone observes DST (best effort)
+	IANA         string
+	DisplayName  string
+	Country      string
+	CountryCodes []string // ISO 3166 codes from zone1970.tab, most populous first
+	Region       string   // one of RegionNames, empty for UTC/GMT
+	Comment      string   // zone1970.tab's note for countries with several zones
+	Offset       string   // computed at load-time for "now"
+	DST          bool     // whether this zone observes DST (best effort)
+}
+
+// Regions accepted by GetRegionTimezones.
+const (
+	RegionAfrica     = "africa"
+	RegionAmerica    = "america"
+	RegionAntarctica = "antarctica"
+	RegionAsia       = "asia"
+	RegionEurope     = "europe"
+	RegionOceania    = "oceania"
+)
+
+// RegionNames lists the regions zones are grouped into, sorted.
+func RegionNames() []string {
+	return []string{RegionAfrica, RegionAmerica, RegionAntarctica, RegionAsia, RegionEurope, RegionOceania}
+}
+
+// regionAreas maps the area part of an IANA name to its region. Atlantic and
+// Indian zones default to the continent most of them belong to; the
+// exceptions are in regionZones.
+var regionAreas = map[string]string{
+	"Africa":     RegionAfrica,
+	"America":    RegionAmerica,
+	"Antarctica": RegionAntarctica,
+	"Arctic":     RegionEurope,
+	"Asia":       RegionAsia,
+	"Atlantic":   RegionEurope,
+	"Australia":  RegionOceania,
+	"Europe":     RegionEurope,
+	"Indian":     RegionAfrica,
+	"Pacific":    RegionOceania,
+}
+
+var regionZones = map[string]string{
+	"Atlantic/Bermuda":       RegionAmerica,
+	"Atlantic/Stanley":       RegionAmerica,
+	"Atlantic/South_Georgia": RegionAmerica,
+	"Atlantic/Cape_Verde":    RegionAfrica,
+	"Atlantic/St_Helena":     RegionAfrica,
+	"Indian/Chagos":          RegionAsia,
+	"Indian/Maldives":        RegionAsia,
+	"Indian/Christmas":       RegionAsia,
+	"Indian/Cocos":           RegionAsia,
+	"Indian/Kerguelen":       RegionAntarctica,
+}
+
+// RegionOf returns the region of an IANA zone name, or "" for zones outside
+// any continent such as UTC.
+func RegionOf(iana string) string {
+	if r, ok := regionZones[iana]; ok {
+		return r
+	}
+	area, _, ok := strings.Cut(iana, "/")
+	if !ok {
+		return ""
+	}
+	return regionAreas[area]
+}
+
+// NormalizeRegion maps user input such as "Americas" or "Pacific" to one of
+// RegionNames; ok is false for anything else.
+func NormalizeRegion(s string) (string, bool) {
+	r := strings.ToLower(strings.TrimSpace(s))
+	switch r {
+	case "americas", "north america", "south america":
+		return RegionAmerica, true
+	case "pacific", "australia":
+		return RegionOceania, true
+	}
+	for _, name := range RegionNames() {
+		if r == name {
+			return name, true
+		}
+	}
+	return "", false
 }
 
 // TimezoneManager handles timezone operations
 type TimezoneManager struct {
 	zones map[string]*TimezoneInfo // includes IANA keys + aliases pointing to same *TimezoneInfo
+
+	searchOnce sync.Once
+	names      []searchName // built by searchNames
 }
 
 // NewTimezoneManager creates a new timezone manager
@@ -47,12 +129,17 @@ func (tm *TimezoneManager) GetTimezone(name string) (*TimezoneInfo, error) {
 			return zone, nil
 		}
 	}
+	// Deprecated names resolve to the zone that replaced them
+	if canonical, ok := CanonicalName(name); ok {
+		return tm.GetTimezone(canonical)
+	}
 	// Try system
 	if _, err := time.LoadLocation(name); err == nil {
 		return &TimezoneInfo{
 			IANA:        name,
 			DisplayName: displayFromIANA(name),
 			Country:     "Unknown",
+			Region:      RegionOf(name),
 			Offset:      getTimezoneOffset(name),
 			DST:         hasDST(name),
 		}, nil
@@ -86,20 +173,28 @@ func (tm *TimezoneManager) ListTimezones() []*TimezoneInfo {
 
 // GetEuropeanTimezones returns European timezones (unique)
 func (tm *TimezoneManager) GetEuropeanTimezones() []*TimezoneInfo {
+	return tm.regionTimezones(RegionEurope)
+}
+
+// GetRegionTimezones returns the zones of a region (see NormalizeRegion),
+// sorted like ListTimezones.
+func (tm *TimezoneManager) GetRegionTimezones(region string) ([]*TimezoneInfo, error) {
+	r, ok := NormalizeRegion(region)
+	if !ok {
+		return nil, fmt.Errorf("unknown region %q (use %s)", region, strings.Join(RegionNames(), ", "))
+	}
+	return tm.regionTimezones(r), nil
+}
+
+func (tm *TimezoneManager) regionTimezones(region string) []*TimezoneInfo {
 	all := tm.ListTimezones()
-	europe := make([]*TimezoneInfo, 0, len(all))
+	out := make([]*TimezoneInfo, 0, len(all))
 	for _, z := range all {
-		if strings.HasPrefix(z.IANA, "Europe/") || strings.HasPrefix(z.IANA, "Atlantic/") {
-			europe = append(europe, z)
+		if z.Region == region {
+			out = append(out, z)
 		}
 	}
-	sort.Slice(europe, func(i, j int) bool {
-		if europe[i].DisplayName == europe[j].DisplayName {
-			return europe[i].IANA < europe[j].IANA
-		}
-		return europe[i].DisplayName < europe[j].DisplayName
-	})
-	return europe
+	return out
 }
 
 // ConvertTime converts time from one timezone to another (labels respected)
@@ -186,11 +281,14 @@ func (tm *TimezoneManager) loadFromZoneTab() {
 			continue
 		}
 		info := &TimezoneInfo{
-			IANA:        tz,
-			DisplayName: displayFromIANA(tz),        // city only
-			Country:     countryNameFromCodes(r.CC), // "IE", "BR,AR", etc.
-			Offset:      getTimezoneOffset(tz),
-			DST:         hasDST(tz),
+			IANA:         tz,
+			DisplayName:  displayFromIANA(tz),        // city only
+			Country:      countryNameFromCodes(r.CC), // "IE", "BR,AR", etc.
+			CountryCodes: strings.Split(r.CC, ","),
+			Region:       RegionOf(tz),
+			Comment:      r.Comment,
+			Offset:       getTimezoneOffset(tz),
+			DST:          hasDST(tz),
 		}
 		tm.zones[tz] = info
 	}
@@ -244,9 +342,14 @@ func (tm *TimezoneManager) loadCommonTimezones() {
 			IANA:        s.IANA,
 			DisplayName: s.Display,
 			Country:     s.Country,
+			Region:      RegionOf(s.IANA),
 			Offset:      getTimezoneOffset(s.IANA),
 			DST:         hasDST(s.IANA),
 		}
+		// Keep the zone1970.tab metadata the curated names replace.
+		if tab, ok := tm.zones[s.IANA]; ok {
+			info.CountryCodes, info.Comment = tab.CountryCodes, tab.Comment
+		}
 		tm.zones[s.IANA] = info
 	}
 
@@ -303,11 +406,8 @@ func countryNameFromCodes(cc string) string {
 	parts := strings.Split(cc, ",")
 	names := make([]string, 0, len(parts))
 	for _, p := range parts {
-		code := strings.TrimSpace(p)
-		if full, ok := countryNames[code]; ok {
-			names = append(names, full)
-		} else if code != "" {
-			names = append(names, code) // fallback to code
+		if code := strings.TrimSpace(p); code != "" {
+			names = append(names, CountryName(code)) // falls back to the code
 		}
 	}
 	return strings.Join(names, ", ")
@@ -421,87 +521,3 @@ func (tm *TimezoneManager) GetTimezoneAbbreviation(tz string) string {
 	}
 	return tz
 }
-
-// Minimal ISO country code -> name mapping (fallback to code if missing).
-var countryNames = map[string]string{
-	"AD": "Andorra", "AE": "United Arab Emirates", "AF": "Afghanistan",
-	"AG": "Antigua and Barbuda", "AI": "Anguilla", "AL": "Albania",
-	"AM": "Armenia", "AO": "Angola", "AQ": "Antarctica", "AR": "Argentina",
-	"AT": "Austria", "AU": "Australia", "AW": "Aruba", "AX": "Aland Islands",
-	"AZ": "Azerbaijan",
-
-	"BA": "Bosnia and Herzegovina", "BB": "Barbados", "BD": "Bangladesh",
-	"BE": "Belgium", "BF": "Burkina Faso", "BG": "Bulgaria", "BH": "Bahrain",
-	"BI": "Burundi", "BJ": "Benin", "BM": "Bermuda", "BN": "Brunei Darussalam",
-	"BO": "Bolivia", "BR": "Brazil", "BS": "Bahamas", "BT": "Bhutan",
-	"BW": "Botswana", "BY": "Belarus", "BZ": "Belize",
-
-	"CA": "Canada", "CD": "Congo (DRC)", "CF": "Central African Republic",
-	"CG": "Congo", "CH": "Switzerland", "CI": "Cote d’Ivoire", "CL": "Chile",
-	"CM": "Cameroon", "CN": "China", "CO": "Colombia", "CR": "Costa Rica",
-	"CU": "Cuba", "CV": "Cabo Verde", "CY": "Cyprus", "CZ": "Czechia",
-
-	"DE": "Germany", "DK": "Denmark", "DO": "Dominican Republic",
-	"DZ": "Algeria",
-
-	"EC": "Ecuador", "EE": "Estonia", "EG": "Egypt", "ES": "Spain",
-	"ET": "Ethiopia",
-
-	"FI": "Finland", "FJ": "Fiji", "FK": "Falkland Islands", "FO": "Faroe Islands",
-	"FR": "France",
-
-	"GB": "United Kingdom", "GD": "Grenada", "GE": "Georgia", "GF": "French Guiana",
-	"GG": "Guernsey", "GH": "Ghana", "GI": "Gibraltar", "GL": "Greenland",
-	"GP": "Guadeloupe", "GR": "Greece", "GT": "Guatemala", "GU": "Guam",
-	"GY": "Guyana",
-
-	"HK": "Hong Kong", "HN": "Honduras", "HR": "Croatia", "HT": "Haiti",
-	"HU": "Hungary",
-
-	"IE": "Ireland", "IL": "Israel", "IM": "Isle of Man", "IN": "India",
-	"IQ": "Iraq", "IR": "Iran", "IS": "Iceland", "IT": "Italy",
-
-	"JM": "Jamaica", "JO": "Jordan", "JP": "Japan",
-
-	"KE": "Kenya", "KG": "Kyrgyzstan", "KH": "Cambodia", "KR": "Korea (South)",
-	"KW": "Kuwait", "KY": "Cayman Islands", "KZ": "Kazakhstan",
-
-	"LA": "Laos", "LB": "Lebanon", "LI": "Liechtenstein", "LK": "Sri Lanka",
-	"LT": "Lithuania", "LU": "Luxembourg", "LV": "Latvia", "LY": "Libya",
-
-	"MA": "Morocco", "MC": "Monaco", "MD": "Moldova", "ME": "Montenegro",
-	"MF": "Saint Martin", "MG": "Madagascar", "MK": "North Macedonia",
-	"MM": "Myanmar", "MN": "Mongolia", "MO": "Macao", "MQ": "Martinique",
-	"MT": "Malta", "MU": "Mauritius", "MX": "Mexico", "MY": "Malaysia",
-
-	"NA": "Namibia", "NC": "New Caledonia", "NG": "Nigeria", "NI": "Nicaragua",
-	"NL": "Netherlands", "NO": "Norway", "NP": "Nepal", "NZ": "New Zealand",
-
-	"PA": "Panama", "PE": "Peru", "PF": "French Polynesia", "PG": "Papua New Guinea",
-	"PH": "Philippines", "PK": "Pakistan", "PL": "Poland", "PM": "Saint Pierre and Miquelon",
-	"PR": "Puerto Rico", "PT": "Portugal", "PY": "Paraguay",
-
-	"QA": "Qatar",
-
-	"RE": "Reunion", "RO": "Romania", "RS": "Serbia", "RU": "Russia",
-	"RW": "Rwanda",
-
-	"SA": "Saudi Arabia", "SC": "Seychelles", "SE": "Sweden", "SG": "Singapore",
-	"SI": "Slovenia", "SJ": "Svalbard and Jan Mayen", "SK": "Slovakia",
-	"SM": "San Marino", "SN": "Senegal", "SR": "Suriname",
-	"SV": "El Salvador", "SY": "Syria",
-
-	"TC": "Turks and Caicos Islands", "TH": "Thailand", "TN": "Tunisia",
-	"TR": "Turkiye", "TT": "Trinidad and Tobago", "TW": "Taiwan", "TZ": "Tanzania",
-
-	"UA": "Ukraine", "UG": "Uganda", "US": testutil.CountryUnitedStates, "UY": "Uruguay",
-	"UZ": "Uzbekistan",
-
-	"VA": "Vatican City", "VE": "Venezuela", "VG": "Virgin Islands (UK)",
-	"VI": "Virgin Islands (US)", "VN": "Viet Nam",
-
-	"WF": "Wallis and Futuna",
-
-	"YE": "Yemen", "YT": "Mayotte", "ZA": "South Africa", "ZM": "Zambia",
-	"ZW": "Zimbabwe",
-}
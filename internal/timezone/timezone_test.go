@@ -1002,3 +1002,104 @@ func TestParseZone1970Tab(t *testing.T) {
 		}
 	}
 }
+
+func TestRegionOf(t *testing.T) {
+	tests := map[string]string{
+		testutil.TZEuropeMadrid:          RegionEurope,
+		testutil.TZAtlanticCanary:        RegionEurope,
+		"Atlantic/Bermuda":               RegionAmerica,
+		"America/Argentina/Buenos_Aires": RegionAmerica,
+		"Asia/Kolkata":                   RegionAsia,
+		"Africa/Nairobi":                 RegionAfrica,
+		"Indian/Mauritius":               RegionAfrica,
+		"Indian/Maldives":                RegionAsia,
+		"Australia/Sydney":               RegionOceania,
+		"Pacific/Auckland":               RegionOceania,
+		"Antarctica/Troll":               RegionAntarctica,
+		"UTC":                            "",
+	}
+	for iana, want := range tests {
+		if got := RegionOf(iana); got != want {
+			t.Errorf("RegionOf(%q) = %q, want %q", iana, got, want)
+		}
+	}
+}
+
+func TestGetRegionTimezones(t *testing.T) {
+	tm := NewTimezoneManager()
+	for _, region := range RegionNames() {
+		zones, err := tm.GetRegionTimezones(region)
+		if err != nil || len(zones) == 0 {
+			t.Errorf("GetRegionTimezones(%q) = %d zones, %v", region, len(zones), err)
+		}
+		for _, z := range zones {
+			if z.Region != region {
+				t.Errorf("%s listed under %s but has region %q", z.IANA, region, z.Region)
+			}
+		}
+	}
+	asia, _ := tm.GetRegionTimezones(" Asia ")
+	if !containsZone(asia, testutil.TZAsiaTokyо) {
+		t.Error("asia missing Asia/Tokyo")
+	}
+	americas, _ := tm.GetRegionTimezones("americas")
+	if !containsZone(americas, testutil.TZAmericaSaoPaulo) {
+		t.Error("americas missing America/Sao_Paulo")
+	}
+	if _, err := tm.GetRegionTimezones("mars"); err == nil {
+		t.Error("expected an error for an unknown region")
+	}
+}
+
+func containsZone(zones []*TimezoneInfo, iana string) bool {
+	for _, z := range zones {
+		if z.IANA == iana {
+			return true
+		}
+	}
+	return false
+}
+
+func TestZoneTabMetadata(t *testing.T) {
+	tm := NewTimezoneManager()
+
+	// Every zone1970.tab row is loaded with its codes and region.
+	for _, row := range parseZone1970Tab() {
+		z, err := tm.GetTimezone(row.TZ)
+		if err != nil {
+			t.Errorf("%s from zone1970.tab not loaded: %v", row.TZ, err)
+			continue
+		}
+		if strings.Join(z.CountryCodes, ",") != row.CC {
+			t.Errorf("%s country codes = %v, want %s", row.TZ, z.CountryCodes, row.CC)
+		}
+		if z.Region == "" {
+			t.Errorf("%s has no region", row.TZ)
+		}
+	}
+
+	// Curated display names keep the table's metadata.
+	madrid, _ := tm.GetTimezone(testutil.TZEuropeMadrid)
+	if madrid.DisplayName != "Madrid" || strings.Join(madrid.CountryCodes, ",") != "ES" || madrid.Comment == "" {
+		t.Errorf("Europe/Madrid = %+v", madrid)
+	}
+	cordoba, _ := tm.GetTimezone("America/Argentina/Cordoba")
+	if cordoba.Country != "Argentina" || cordoba.Region != RegionAmerica {
+		t.Errorf("America/Argentina/Cordoba = %+v", cordoba)
+	}
+}
+
+func TestCountryName(t *testing.T) {
+	tests := map[string]string{
+		"ES": "Spain",
+		"ie": "Ireland",
+		"US": testutil.CountryUnitedStates,
+		"KR": "Korea (South)",
+		"ZZ": "ZZ",
+	}
+	for code, want := range tests {
+		if got := CountryName(code); got != want {
+			t.Errorf("CountryName(%q) = %q, want %q", code, got, want)
+		}
+	}
+}
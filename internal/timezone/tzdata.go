@@ -0,0 +1,205 @@
+package timezone
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Timezone database sources, in the order time.LoadLocation tries them.
+const (
+	TZDataRefreshed = "refreshed" // downloaded by 'tempus timezone refresh'
+	TZDataEnv       = "ZONEINFO"  // the ZONEINFO environment variable
+	TZDataSystem    = "system"    // the operating system's zoneinfo directory
+	TZDataEmbedded  = "embedded"  // the copy compiled into the binary (time/tzdata)
+)
+
+// DefaultTZDataURL serves Go's prebuilt zoneinfo.zip, updated with every IANA
+// release; DefaultTZDataVersionURL names the release it was built from.
+const (
+	DefaultTZDataURL        = "https://raw.githubusercontent.com/golang/go/master/lib/time/zoneinfo.zip"
+	DefaultTZDataVersionURL = "https://raw.githubusercontent.com/golang/go/master/lib/time/update.bash"
+)
+
+const (
+	tzdataDir     = "tzdata"
+	tzdataZip     = "zoneinfo.zip"
+	tzdataVersion = "VERSION"
+)
+
+// systemZoneDirs are where Go looks for the system database on Unix.
+var systemZoneDirs = []string{"/usr/share/zoneinfo/", "/usr/share/lib/zoneinfo/", "/usr/lib/locale/TZ/", "/etc/zoneinfo/"}
+
+// refreshedZip is the database UseRefreshedTZData pointed ZONEINFO at.
+var refreshedZip string
+
+// TZDataInfo describes the timezone database in use.
+type TZDataInfo struct {
+	Source  string
+	Path    string
+	Version string // IANA release such as 2025b; empty when unknown
+}
+
+// String formats the database as "2025b (system, /usr/share/zoneinfo)".
+func (i TZDataInfo) String() string {
+	version := i.Version
+	if version == "" {
+		version = "unknown version"
+	}
+	where := i.Source
+	switch {
+	case i.Path != "":
+		where += ", " + i.Path
+	case i.Source == TZDataEmbedded:
+		where += ", " + runtime.Version()
+	}
+	return fmt.Sprintf("%s (%s)", version, where)
+}
+
+// TZDataPath returns where 'tempus timezone refresh' keeps its database
+// inside configDir.
+func TZDataPath(configDir string) string {
+	return filepath.Join(configDir, tzdataDir, tzdataZip)
+}
+
+// UseRefreshedTZData makes time.LoadLocation prefer a database downloaded
+// into configDir by pointing ZONEINFO at it. It must run before the first
+// LoadLocation call, and leaves a ZONEINFO set by the user alone.
+func UseRefreshedTZData(configDir string) bool {
+	path := TZDataPath(configDir)
+	if os.Getenv("ZONEINFO") != "" {
+		return false
+	}
+	if _, err := os.Stat(path); err != nil {
+		return false
+	}
+	if err := os.Setenv("ZONEINFO", path); err != nil {
+		return false
+	}
+	refreshedZip = path
+	return true
+}
+
+// CurrentTZData reports which database time.LoadLocation reads.
+func CurrentTZData() TZDataInfo {
+	if env := os.Getenv("ZONEINFO"); env != "" {
+		info := TZDataInfo{Source: TZDataEnv, Path: env}
+		if env == refreshedZip {
+			info.Source = TZDataRefreshed
+		}
+		if data, err := os.ReadFile(filepath.Join(filepath.Dir(env), tzdataVersion)); err == nil {
+			info.Version = strings.TrimSpace(string(data))
+		}
+		return info
+	}
+	if runtime.GOOS != "windows" {
+		for _, dir := range systemZoneDirs {
+			if _, err := os.Stat(filepath.Join(dir, "UTC")); err == nil {
+				return TZDataInfo{Source: TZDataSystem, Path: strings.TrimSuffix(dir, "/"), Version: systemTZDataVersion(dir)}
+			}
+		}
+	}
+	return TZDataInfo{Source: TZDataEmbedded}
+}
+
+// systemTZDataVersion reads the release of a zoneinfo directory from its
+// tzdata.zi ("# version 2025b") or +VERSION file.
+func systemTZDataVersion(dir string) string {
+	if f, err := os.Open(filepath.Join(dir, "tzdata.zi")); err == nil {
+		defer f.Close()
+		sc := bufio.NewScanner(f)
+		if sc.Scan() {
+			if v, ok := strings.CutPrefix(sc.Text(), "# version "); ok {
+				return strings.TrimSpace(v)
+			}
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "+VERSION")); err == nil {
+		return strings.TrimSpace(string(data))
+	}
+	return ""
+}
+
+var tzdataVersionRe = regexp.MustCompile(`(?m)^\s*DATA=(\d{4}[a-z])\s*$`)
+
+// ParseTZDataVersion finds the IANA release ("DATA=2025b") in Go's
+// lib/time/update.bash, or returns "".
+func ParseTZDataVersion(updateScript string) string {
+	if m := tzdataVersionRe.FindStringSubmatch(updateScript); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// InstalledTZDataVersion returns the release of the refreshed database in
+// configDir, or "" when there is none.
+func InstalledTZDataVersion(configDir string) string {
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(TZDataPath(configDir)), tzdataVersion))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// InstallTZData checks that data is a zoneinfo.zip that time can read and
+// stores it, with its version, in configDir.
+func InstallTZData(configDir string, data []byte, version string) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("the download is not a zoneinfo.zip: %w", err)
+	}
+	checked := 0
+	for _, f := range zr.File {
+		if f.Name != "Europe/Madrid" && f.Name != "America/New_York" && f.Name != "UTC" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("zoneinfo.zip: %w", err)
+		}
+		zone, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("zoneinfo.zip: %w", err)
+		}
+		if _, err := time.LoadLocationFromTZData(f.Name, zone); err != nil {
+			return fmt.Errorf("zoneinfo.zip: %s: %w", f.Name, err)
+		}
+		checked++
+	}
+	if checked < 3 {
+		return fmt.Errorf("the download does not look like a timezone database (UTC, Europe/Madrid or America/New_York missing)")
+	}
+
+	dir := filepath.Dir(TZDataPath(configDir))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp := TZDataPath(configDir) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, TZDataPath(configDir)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, tzdataVersion), []byte(version+"\n"), 0o644)
+}
+
+// RemoveTZData deletes the refreshed database from configDir, so the system
+// or embedded one is used again. It reports whether there was one.
+func RemoveTZData(configDir string) (bool, error) {
+	dir := filepath.Dir(TZDataPath(configDir))
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return false, nil
+	}
+	return true, os.RemoveAll(dir)
+}
@@ -0,0 +1,123 @@
+package timezone
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fixedZoneTZif returns the smallest TZif file time can load: one zone type,
+// no transitions.
+func fixedZoneTZif(abbrev string) []byte {
+	var b bytes.Buffer
+	b.WriteString("TZif")
+	b.Write(make([]byte, 16)) // version 1 + reserved
+	for _, n := range []uint32{0, 0, 0, 0, 1, uint32(len(abbrev) + 1)} {
+		_ = binary.Write(&b, binary.BigEndian, n)
+	}
+	b.Write([]byte{0, 0, 0, 0, 0, 0}) // UTC offset 0, not DST, abbrev index 0
+	b.WriteString(abbrev + "\x00")
+	return b.Bytes()
+}
+
+func testZoneinfoZip(t *testing.T, names ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(fixedZoneTZif("UTC"))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestParseTZDataVersion(t *testing.T) {
+	script := "#!/bin/bash\nset -e\n\n# Versions to use.\nCODE=2026c\nDATA=2026c\n"
+	if got := ParseTZDataVersion(script); got != "2026c" {
+		t.Errorf("ParseTZDataVersion = %q, want 2026c", got)
+	}
+	for _, bad := range []string{"", "CODE=2026c\n", "DATA=latest\n", "# DATA=2026c is old"} {
+		if got := ParseTZDataVersion(bad); got != "" {
+			t.Errorf("ParseTZDataVersion(%q) = %q, want empty", bad, got)
+		}
+	}
+}
+
+func TestInstallAndUseRefreshedTZData(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("ZONEINFO", "")
+
+	if UseRefreshedTZData(dir) {
+		t.Fatal("UseRefreshedTZData should do nothing before a refresh")
+	}
+	if err := InstallTZData(dir, testZoneinfoZip(t, "UTC", "Europe/Madrid", "America/New_York"), "2099a"); err != nil {
+		t.Fatalf("InstallTZData: %v", err)
+	}
+	if got := InstalledTZDataVersion(dir); got != "2099a" {
+		t.Errorf("InstalledTZDataVersion = %q, want 2099a", got)
+	}
+	if !UseRefreshedTZData(dir) {
+		t.Fatal("UseRefreshedTZData should pick up the installed database")
+	}
+	if got := os.Getenv("ZONEINFO"); got != TZDataPath(dir) {
+		t.Errorf("ZONEINFO = %q, want %q", got, TZDataPath(dir))
+	}
+	info := CurrentTZData()
+	if info.Source != TZDataRefreshed || info.Version != "2099a" || info.Path != TZDataPath(dir) {
+		t.Errorf("CurrentTZData = %+v", info)
+	}
+	if s := info.String(); !strings.HasPrefix(s, "2099a (refreshed, ") {
+		t.Errorf("String() = %q", s)
+	}
+
+	removed, err := RemoveTZData(dir)
+	if err != nil || !removed {
+		t.Fatalf("RemoveTZData = %v, %v", removed, err)
+	}
+	if _, err := os.Stat(filepath.Dir(TZDataPath(dir))); !os.IsNotExist(err) {
+		t.Errorf("tzdata directory still exists: %v", err)
+	}
+	if removed, _ := RemoveTZData(dir); removed {
+		t.Error("RemoveTZData reported a second removal")
+	}
+}
+
+func TestUseRefreshedTZDataKeepsUserZoneinfo(t *testing.T) {
+	dir := t.TempDir()
+	if err := InstallTZData(dir, testZoneinfoZip(t, "UTC", "Europe/Madrid", "America/New_York"), "2099a"); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ZONEINFO", "/custom/zoneinfo.zip")
+	if UseRefreshedTZData(dir) {
+		t.Error("UseRefreshedTZData overrode ZONEINFO")
+	}
+	if info := CurrentTZData(); info.Source != TZDataEnv || info.Path != "/custom/zoneinfo.zip" {
+		t.Errorf("CurrentTZData = %+v", info)
+	}
+}
+
+func TestInstallTZDataRejectsBadDownloads(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string][]byte{
+		"not a zip":     []byte("<html>rate limited</html>"),
+		"missing zones": testZoneinfoZip(t, "UTC"),
+	}
+	for name, data := range tests {
+		if err := InstallTZData(dir, data, "2099a"); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := os.Stat(TZDataPath(dir)); !os.IsNotExist(err) {
+		t.Errorf("a rejected download was installed: %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"embed"
 	"strings"
+	"sync"
 )
 
 // Pin the import so overzealous formatters don’t drop it before //go:embed runs.
@@ -15,6 +16,42 @@ var _ embed.FS
 //go:embed data/zone1970.tab
 var zone1970Tab []byte
 
+//go:embed data/iso3166.tab
+var iso3166Tab []byte
+
+var (
+	countriesOnce sync.Once
+	countries     map[string]string
+)
+
+// CountryName returns the name iso3166.tab gives an ISO 3166 alpha-2 code
+// (any case), or the code itself when it is not in the table.
+func CountryName(code string) string {
+	countriesOnce.Do(func() { countries = parseISO3166Tab(iso3166Tab) })
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if name, ok := countries[code]; ok {
+		return name
+	}
+	return code
+}
+
+func parseISO3166Tab(data []byte) map[string]string {
+	out := make(map[string]string, 300)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// Format: CC<TAB>name
+		code, name, ok := strings.Cut(line, "\t")
+		if ok && strings.TrimSpace(code) != "" {
+			out[strings.TrimSpace(code)] = strings.TrimSpace(name)
+		}
+	}
+	return out
+}
+
 type tabRow struct {
 	CC      string // country code(s), comma separated
 	TZ      string // IANA timezone (Area/City)
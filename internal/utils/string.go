@@ -1,31 +1,62 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"strings"
+	"unicode"
 )
 
-// Slugify converts a string to a URL-friendly slug.
-// It converts to lowercase, replaces spaces and special characters with hyphens,
-// and removes any characters that are not alphanumeric or hyphens.
-// Returns "event" if the input is empty or only contains special characters.
-//
-// Examples:
-//   - Slugify("Hello World") -> "hello-world"
-//   - Slugify("Meeting @ 3pm") -> "meeting-3pm"
-//   - Slugify("Múltiple   Espacios") -> "m-ltiple-espacios"
-func Slugify(s string) string {
-	s = strings.ToLower(strings.TrimSpace(s))
-	if s == "" {
-		return ""
+// DefaultSlugMaxLength is how long a slug gets unless SetSlugMaxLength says
+// otherwise.
+const DefaultSlugMaxLength = 60
+
+var slugMaxLength = DefaultSlugMaxLength
+
+// SetSlugMaxLength caps the length of slugs (config's output.name_max_length);
+// 0 restores DefaultSlugMaxLength. Call it before slugifying, not concurrently.
+func SetSlugMaxLength(n int) {
+	if n <= 0 {
+		n = DefaultSlugMaxLength
 	}
+	slugMaxLength = n
+}
 
+// transliterations spells Latin letters with diacritics and ligatures in
+// ASCII, so "Reunião" becomes "reuniao" rather than "reuni-o".
+var transliterations = func() map[rune]string {
+	m := map[rune]string{'ß': "ss", 'æ': "ae", 'œ': "oe", 'þ': "th", 'ĳ': "ij", 'ŉ': "n"}
+	for ascii, letters := range map[string]string{
+		"a": "àáâãäåāăąǎ", "c": "çćĉċč", "d": "ďđð", "e": "èéêëēĕėęě", "g": "ĝğġģ",
+		"h": "ĥħ", "i": "ìíîïĩīĭįı", "j": "ĵ", "k": "ķ", "l": "ĺļľŀł", "n": "ñńņň",
+		"o": "òóôõöøōŏőǒ", "r": "ŕŗř", "s": "śŝşšș", "t": "ţťŧț", "u": "ùúûüũūŭůűųǔ",
+		"w": "ŵ", "y": "ýÿŷ", "z": "źżž",
+	} {
+		for _, r := range letters {
+			m[r] = ascii
+		}
+	}
+	return m
+}()
+
+// Slug turns s into a file-name friendly slug: lower-case ASCII letters and
+// digits separated by single hyphens. Latin letters with diacritics are
+// transliterated (á→a, ç→c, ß→ss); other scripts are dropped. Long slugs are
+// cut at a hyphen when one is near the limit. Slug returns "" when nothing
+// is left.
+func Slug(s string) string {
 	var b strings.Builder
 	prevHyphen := false
-	for _, r := range s {
+	for _, r := range strings.ToLower(s) {
 		switch {
 		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
 			b.WriteRune(r)
 			prevHyphen = false
+		case transliterations[r] != "":
+			b.WriteString(transliterations[r])
+			prevHyphen = false
+		case unicode.Is(unicode.Mn, r):
+			// combining accents (e + ◌́) go with their letter
 		default:
 			// Replace all special characters (including -, _, space, ., /, \) with hyphens
 			if !prevHyphen && b.Len() > 0 {
@@ -35,8 +66,71 @@ func Slugify(s string) string {
 		}
 	}
 	out := strings.Trim(b.String(), "-")
-	if out == "" {
-		return "event"
+	if len(out) > slugMaxLength {
+		out = out[:slugMaxLength]
+		if i := strings.LastIndexByte(out, '-'); i >= slugMaxLength/2 {
+			out = out[:i]
+		}
+		out = strings.TrimRight(out, "-")
 	}
 	return out
 }
+
+// Slugify converts a string to a URL-friendly slug (see Slug).
+// Returns "" for empty input and "event" if nothing of it can be spelled.
+//
+// Examples:
+//   - Slugify("Hello World") -> "hello-world"
+//   - Slugify("Meeting @ 3pm") -> "meeting-3pm"
+//   - Slugify("Reunião de Equipa") -> "reuniao-de-equipa"
+func Slugify(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return ""
+	}
+	if out := Slug(s); out != "" {
+		return out
+	}
+	return "event"
+}
+
+// SlugOrHash is Slug(s), or for text Slug leaves nothing of (会議, Встреча)
+// prefix and a short hash of s, so different texts still get different
+// names: "2030-03-04-5d41402a". Blank s gives prefix.
+func SlugOrHash(s, prefix string) string {
+	if out := Slug(s); out != "" {
+		return out
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return prefix
+	}
+	sum := sha256.Sum256([]byte(s))
+	if prefix == "" {
+		return hex.EncodeToString(sum[:4])
+	}
+	return prefix + "-" + hex.EncodeToString(sum[:4])
+}
+
+// Levenshtein returns the edit distance between a and b: the number of
+// single-rune insertions, deletions and substitutions turning one into the
+// other. Used for typo detection and "did you mean" suggestions.
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
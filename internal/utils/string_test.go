@@ -25,7 +25,12 @@ func TestSlugify(t *testing.T) {
 		{"leading/trailing hyphens", "-hello-world-", testutil.TemplateHelloWorld},
 		{"only special chars", "@#$%^", "event"},
 		{testutil.TestNameEmptyString, "", ""},
-		{"accented characters", "múltiple espacios", "m-ltiple-espacios"},
+		{"accented characters", "múltiple espacios", "multiple-espacios"},
+		{"portuguese", "Reunião de Coordenação", "reuniao-de-coordenacao"},
+		{"galician", "Xuntanza na Coruña", "xuntanza-na-coruna"},
+		{"ligatures", "Straße Œuvre", "strasse-oeuvre"},
+		{"combining accents", "Cafe\u0301 İstanbul", "cafe-istanbul"},
+		{"non-latin script", "会議", "event"},
 		{"numbers", "event 123 test 456", "event-123-test-456"},
 		{"consecutive hyphens", "hello---world", testutil.TemplateHelloWorld},
 		{"mixed case with numbers", "Event2024Test", "event2024test"},
@@ -62,3 +67,67 @@ func TestSlugifyEdgeCases(t *testing.T) {
 		t.Error("Slugify should not have leading or trailing hyphens")
 	}
 }
+
+func TestSlugMaxLength(t *testing.T) {
+	t.Cleanup(func() { SetSlugMaxLength(0) })
+
+	long := "Consulta de seguimento com a doutora Conceição no centro de saúde da Estrada"
+	if got := Slug(long); len(got) > DefaultSlugMaxLength || got != "consulta-de-seguimento-com-a-doutora-conceicao-no-centro-de" {
+		t.Errorf("Slug(long) = %q (%d bytes)", got, len(got))
+	}
+	SetSlugMaxLength(12)
+	if got := Slug(long); got != "consulta-de" {
+		t.Errorf("Slug(long) with max 12 = %q, want cut at a hyphen", got)
+	}
+	if got := Slug("abcdefghijklmnopqrstuvwxyz"); got != "abcdefghijkl" {
+		t.Errorf("Slug without hyphens = %q, want a hard cut", got)
+	}
+}
+
+func TestSlugOrHash(t *testing.T) {
+	if got := SlugOrHash("Dentista às 9h", "2030-03-04"); got != "dentista-as-9h" {
+		t.Errorf("SlugOrHash(latin) = %q", got)
+	}
+	meeting, call := SlugOrHash("会議", "2030-03-04"), SlugOrHash("Встреча", "2030-03-04")
+	if !strings.HasPrefix(meeting, "2030-03-04-") || len(meeting) != len("2030-03-04-")+8 || meeting == call {
+		t.Errorf("expected distinct date+hash names, got %q and %q", meeting, call)
+	}
+	if got := SlugOrHash("会議", ""); got != strings.TrimPrefix(meeting, "2030-03-04-") {
+		t.Errorf("SlugOrHash without prefix = %q", got)
+	}
+	if got := SlugOrHash("  ", "2030-03-04"); got != "2030-03-04" {
+		t.Errorf("SlugOrHash(blank) = %q, want the prefix", got)
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		name string
+		s1   string
+		s2   string
+		want int
+	}{
+		{"empty strings", "", "", 0},
+		{"empty s1", "", "hello", 5},
+		{"empty s2", "hello", "", 5},
+		{"identical", "test", "test", 0},
+		{"one char different", "test", "best", 1},
+		{"completely different", "abc", "xyz", 3},
+		{"different lengths", "short", "longer string", 11}, // Actual levenshtein distance
+		{"insertion", "cat", "cats", 1},
+		{"deletion", "cats", "cat", 1},
+		{"substitution", "cat", "bat", 1},
+		{"multiple operations", "kitten", "sitting", 3},
+		{"case sensitive", "Test", "test", 1},
+		{"counts runes, not bytes", "são", "sao", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Levenshtein(tt.s1, tt.s2)
+			if got != tt.want {
+				t.Errorf("Levenshtein(%q, %q) = %d, want %d", tt.s1, tt.s2, got, tt.want)
+			}
+		})
+	}
+}
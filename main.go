@@ -2,47 +2,92 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html/template"
+	"image/png"
 	"io"
+	"io/fs"
+	"maps"
+	"math/rand"
+	"net/http"
+	"net/mail"
+	"net/url"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"tempus/internal/calendar"
+	"tempus/internal/clock"
 	"tempus/internal/config"
 	"tempus/internal/constants"
+	"tempus/internal/diag"
+	"tempus/internal/fsys"
 	"tempus/internal/i18n"
+	"tempus/internal/journal"
 	"tempus/internal/normalizer"
+	"tempus/internal/output"
 	"tempus/internal/prompts"
+	"tempus/internal/qr"
 	tpl "tempus/internal/templates"
 	"tempus/internal/testutil"
 	tzpkg "tempus/internal/timezone"
 	"tempus/internal/utils"
 
 	survey "github.com/AlecAivazis/survey/v2"
-	"github.com/google/uuid"
+	"github.com/fsnotify/fsnotify"
 	"github.com/olebedev/when"
+	"github.com/olebedev/when/rules"
+	"github.com/olebedev/when/rules/br"
 	"github.com/olebedev/when/rules/en"
+	"github.com/pelletier/go-toml/v2"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"gopkg.in/yaml.v3"
 )
 
 var (
 	scanner     *bufio.Scanner
 	clockOnlyRe = regexp.MustCompile(`^\d{1,2}:\d{2}$`)
+	// ui translates prompts, warnings and reports. It starts in English and
+	// follows --language (or the configured language) once a command runs.
+	ui, _ = i18n.NewTranslator("en")
+	// dateLayout and clockLayout show dates and times in previews and agendas;
+	// configureOutput sets them from --date-format, --time-format, the config
+	// file or the output language.
+	dateLayout, clockLayout = ui.DateLayout(), ui.TimeLayout()
 )
 
 func init() {
 	scanner = bufio.NewScanner(os.Stdin)
+	config.SetReleaseChannel(config.ChannelForVersion(version))
 }
 
 func main() {
+	// Go reads ZONEINFO once, so a database from 'tempus timezone refresh'
+	// has to be chosen before anything loads a location.
+	if dir, err := config.ConfigDir(); err == nil {
+		tzpkg.UseRefreshedTZData(dir)
+	}
 	if err := newRootCmd().Execute(); err != nil {
 		printErr("%v\n", err)
 		os.Exit(1)
@@ -59,19 +104,65 @@ func newRootCmd() *cobra.Command {
 	cmd.PersistentFlags().StringP("language", "l", "", "Language for output (es, en, ga, pt)")
 	cmd.PersistentFlags().StringP("timezone", "t", "", "Default timezone")
 	cmd.PersistentFlags().StringP("config", "c", "", "Config file path")
+	cmd.PersistentFlags().BoolP("quiet", "q", false, "Only print warnings and errors")
+	cmd.PersistentFlags().Bool("verbose", false, "Print extra progress details")
+	cmd.PersistentFlags().Bool("no-emoji", false, "Plain status lines without emoji (also TEMPUS_NO_EMOJI=1)")
+	cmd.PersistentFlags().Bool("no-color", false, "Disable colored output (also NO_COLOR=1)")
+	cmd.PersistentFlags().String("output-format", "text", "Report format: text or json (JSON on stdout, messages on stderr)")
+	cmd.PersistentFlags().String("date-format", "", "Date display format, e.g. DD/MM/YYYY or YYYY-MM-DD (default: from the language)")
+	cmd.PersistentFlags().String("time-format", "", "Time display format: 24h or 12h (default: from the language)")
+	cmd.MarkFlagsMutuallyExclusive("quiet", "verbose")
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, _ []string) error {
+		journalOp = journal.NewOp(cmd.CommandPath())
+		// Read the config file once per run; helpers share it via
+		// config.Current. Commands that need it report its errors.
+		cfg, err := config.Reload()
+		calendar.SetDefaultAlarmDirection(err == nil && strings.EqualFold(cfg.AlarmDirection, "after"))
+		if err := configureOutput(cmd); err != nil {
+			return err
+		}
+		configureUIDs(cfg, err)
+		nameMax := 0
+		if err == nil {
+			nameMax = cfg.Output.NameMaxLength
+		}
+		utils.SetSlugMaxLength(nameMax)
+		return resolveTimezoneFlags(cmd)
+	}
 
 	cmd.AddCommand(
 		newCreateCmd(),
 		newQuickCmd(),
 		newBatchCmd(),
 		newLintCmd(),
+		newShiftCmd(),
+		newCancelCmd(),
+		newSplitCmd(),
+		newDiffCmd(),
+		newStatsCmd(),
+		newDedupeCmd(),
+		newServeCmd(),
+		newFetchCmd(),
+		newExportCmd(),
+		newNextCmd(),
+		newFocusCmd(),
+		newMedsCmd(),
+		newRotaCmd(),
+		newTravelCmd(),
+		newBirthdaysCmd(),
+		newCountdownCmd(),
+		newUndoCmd(),
 		newConfigCmd(),
+		newDoctorCmd(),
+		newWorkspaceCmd(),
 		newVersionCmd(),
+		newCompletionCmd(),
 		newTemplateCmd(),
 		newLocaleCmd(),
 		newTimezoneCmd(),
 		newRRuleHelperCmd(),
 	)
+	registerDynamicCompletions(cmd)
 
 	return cmd
 }
@@ -86,6 +177,10 @@ func newQuickCmd() *cobra.Command {
 
 	cmd.Flags().StringP("output", "o", "", "Output file path (optional)")
 	cmd.Flags().StringP("timezone", "t", "", "Default timezone (overrides config)")
+	cmd.Flags().Bool("strict", false, "Fail instead of warning when the event breaks working/quiet hours")
+	cmd.Flags().Bool("strict-input", false, "Refuse natural-language inference (quick cannot run in this mode)")
+	cmd.Flags().String("qr", "", "Also save the event as a QR code PNG that a phone can scan into its calendar")
+	cmd.Flags().Bool("open", false, "Open the written calendar in the default calendar app to import it")
 
 	return cmd
 }
@@ -96,40 +191,260 @@ type quickParsedEvent struct {
 	EndTime   time.Time
 	Location  string
 	InputText string
+	Repeat    normalizer.Repeat // empty Freq for a single event
+	Until     time.Time         // last day of the repeat; zero when it never ends
+}
+
+// rrule is the repeat rule of the event, ending on its last day in tz.
+func (d quickParsedEvent) rrule(tz string) string {
+	if d.Repeat.Freq == "" {
+		return ""
+	}
+	rule := d.Repeat.RRule()
+	if !d.Until.IsZero() {
+		rule += ";UNTIL=" + calendar.UntilValue(d.Until, false, tz)
+	}
+	return rule
 }
 
 func runQuick(cmd *cobra.Command, args []string) error {
-	details, err := parseQuickInput(args[0])
+	if strictInput, _ := cmd.Flags().GetBool("strict-input"); strictInput {
+		return fmt.Errorf("quick parses natural language and cannot run with --strict-input; use create or batch instead")
+	}
+
+	events, err := parseQuickEvents(args[0], quickLanguage(cmd))
 	if err != nil {
 		return err
 	}
+	qrPath, _ := cmd.Flags().GetString("qr")
+	if qrPath = strings.TrimSpace(qrPath); qrPath != "" && len(events) > 1 {
+		return fmt.Errorf("--qr shares a single event, but the sentence has %d", len(events))
+	}
 
 	finalTZ := resolveQuickTimezone(cmd)
-	applyTimezoneToDetails(&details, finalTZ)
+	strict, _ := cmd.Flags().GetBool("strict")
+	previews := make([]calendar.Event, len(events))
+	for i := range events {
+		applyTimezoneToDetails(&events[i], finalTZ)
+		previews[i] = *calendar.NewEvent(events[i].Summary, events[i].StartTime, events[i].EndTime)
+		previews[i].RRule = events[i].rrule(finalTZ)
+	}
+	if err := checkEventHours(previews, strict); err != nil {
+		return err
+	}
 
-	if !confirmQuickEvent(details, finalTZ) {
+	var confirmed []quickParsedEvent
+	for i, details := range events {
+		if len(events) > 1 {
+			fmt.Printf("Event %d of %d\n", i+1, len(events))
+		}
+		if confirmQuickEvent(details, finalTZ) {
+			confirmed = append(confirmed, details)
+		}
+	}
+	if len(confirmed) == 0 {
 		fmt.Println("Operation cancelled.")
 		return nil
 	}
 
-	output := getQuickOutput(cmd, details.Summary)
-	return writeQuickCalendar(details, finalTZ, output)
+	policy, err := loadOutputPolicy()
+	if err != nil {
+		return err
+	}
+	output, err := resolveOutputPath(getQuickOutput(cmd, confirmed[0].Summary, confirmed[0].StartTime), policy)
+	if err != nil {
+		return err
+	}
+	if err := writeQuickCalendar(confirmed, finalTZ, output, qrPath, policy); err != nil {
+		return err
+	}
+	if open, _ := cmd.Flags().GetBool("open"); open {
+		openInCalendarApp(os.Stdout, output)
+	}
+	return nil
 }
 
-func parseQuickInput(text string) (quickParsedEvent, error) {
+func newQuickParser(extra []rules.Rule) *when.Parser {
 	w := when.New(nil)
 	w.Add(en.All...)
-
-	res, err := w.Parse(text, time.Now())
+	w.Add(extra...)
+	w.Add(quickBareHour)
+	return w
+}
+
+// quickBareHour reads an hour on its own, "at 9" or "às 18", as 9:00 and
+// 18:00. It comes last, so "at 9pm" and "at 9:30" keep their own rules.
+var quickBareHour = &rules.F{
+	RegExp: regexp.MustCompile(`(?i)(?:^|\s)(at|@|às)\s+(\d{1,2})(?:$|[\s,;!?)])`),
+	Applier: func(m *rules.Match, c *rules.Context, _ *rules.Options, _ time.Time) (bool, error) {
+		hour, err := strconv.Atoi(m.Captures[1])
+		if err != nil || hour > 23 || c.Hour != nil {
+			return false, nil
+		}
+		zero := 0
+		c.Hour, c.Minute, c.Second = &hour, &zero, &zero
+		return true, nil
+	},
+}
+
+// parseQuickInput reads one event. A repeat phrase ("every monday until
+// June", "every tuesday and thursday") becomes its rule and is left out of
+// the summary; the first event then falls on the first day from now on the
+// rule allows.
+func parseQuickInput(text, lang string) (quickParsedEvent, error) {
+	now := appClock.Now()
+	rest, repeat, repeats := normalizer.ExtractRepeat(text, now, lang)
+	if !repeats {
+		rest = text
+	}
+	res, err := newQuickParser(quickNLPRules[lang]).Parse(rest, now)
 	if err != nil || res == nil {
 		return quickParsedEvent{}, fmt.Errorf("could not understand the date/time in your request. Please be more specific, e.g., 'tomorrow at 3pm'")
 	}
 
-	return extractEventDetails(text, res), nil
+	details := extractEventDetails(rest, res)
+	details.InputText = text
+	if !repeats {
+		return details, nil
+	}
+	details.Repeat = repeat
+	if days := repeat.Weekdays; len(days) > 0 {
+		for i := 0; i < 7 && !slices.Contains(days, details.StartTime.Weekday()); i++ {
+			details.StartTime = details.StartTime.AddDate(0, 0, 1)
+			details.EndTime = details.EndTime.AddDate(0, 0, 1)
+		}
+	}
+	if day := repeat.MonthDay; day > 0 {
+		for i := 0; i < 62 && details.StartTime.Day() != day; i++ {
+			details.StartTime = details.StartTime.AddDate(0, 0, 1)
+			details.EndTime = details.EndTime.AddDate(0, 0, 1)
+		}
+	}
+	// "every day at 7am" sent at 8am starts tomorrow, not with a past occurrence.
+	for length := details.EndTime.Sub(details.StartTime); details.StartTime.Before(now); {
+		details.StartTime = repeat.Next(details.StartTime)
+		details.EndTime = details.StartTime.Add(length)
+	}
+	if repeat.End != "" {
+		last, err := calendar.ResolveUntil(repeat.End, details.StartTime)
+		if err != nil {
+			return quickParsedEvent{}, err
+		}
+		start := details.StartTime
+		if last.Before(time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)) {
+			return quickParsedEvent{}, fmt.Errorf("%q repeats until %s, before its first day %s", details.Summary, displayDate(last), displayDate(details.StartTime))
+		}
+		details.Until = last
+	}
+	return details, nil
+}
+
+// parseQuickEvents reads every event of a sentence such as "dentist Tuesday
+// 9am and team lunch Friday 13:00". A clause without a summary of its own
+// ("dentist Tuesday 9am and Friday 10am") repeats the one before it.
+func parseQuickEvents(text, lang string) ([]quickParsedEvent, error) {
+	var events []quickParsedEvent
+	for _, clause := range splitQuickInput(text, lang) {
+		details, err := parseQuickInput(clause, lang)
+		if err != nil {
+			return nil, err
+		}
+		if details.Summary == "" && len(events) > 0 {
+			details.Summary = events[len(events)-1].Summary
+		}
+		events = append(events, details)
+	}
+	return events, nil
+}
+
+// quickClauseSep separates the events of one sentence: "and", "then" (or
+// Portuguese and Spanish "e", "y"), a semicolon or a comma.
+var quickClauseSep = regexp.MustCompile(`(?i)\s*(?:;|,?\s+(?:and|then|e|y)\s+(?:then\s+)?|,)\s*`)
+
+// splitQuickInput splits text into one clause per event. It runs the date
+// parser over what is left after each date/time it finds; two of them make
+// two events only when a separator stands between them. The clause breaks
+// at the last ";", "," or "then" there, so "dentist Tuesday 9am for 30m,
+// team lunch Friday 13:00" keeps "for 30m" with the dentist, or else at the
+// first "and", so "lunch 1pm and coffee and cake 4pm" keeps the cake with the
+// coffee. A separator inside a repeat phrase is no break, and the end of a
+// repeat ("gym every day 7am, until June 15") stays with its event.
+func splitQuickInput(text, lang string) []string {
+	w := newQuickParser(quickNLPRules[lang])
+	repeats := normalizer.RepeatSpans(text, lang)
+	var clauses []string
+	clauseStart, prevEnd := 0, -1
+	for offset := 0; offset < len(text); {
+		res, err := w.Parse(text[offset:], appClock.Now())
+		if err != nil || res == nil || res.Text == "" {
+			break
+		}
+		start := offset + res.Index
+		if prevEnd >= 0 {
+			if sep, ok := quickClauseBreak(text, prevEnd, start, repeats, lang); ok {
+				clauses = append(clauses, text[clauseStart:sep[0]])
+				clauseStart = sep[1]
+			}
+		}
+		prevEnd = start + len(res.Text)
+		offset = prevEnd
+	}
+	return append(clauses, text[clauseStart:])
+}
+
+// quickClauseBreak picks the separator of text[from:to] that ends a clause,
+// as splitQuickInput describes; ok is false when there is none.
+func quickClauseBreak(text string, from, to int, repeats [][2]int, lang string) (sep [2]int, ok bool) {
+	var strong, weak [][2]int
+	for _, m := range quickClauseSep.FindAllStringIndex(text[from:to], -1) {
+		m[0], m[1] = m[0]+from, m[1]+from
+		if slices.ContainsFunc(repeats, func(r [2]int) bool { return m[0] < r[1] && r[0] < m[1] }) ||
+			normalizer.StartsRepeatEnd(text[m[1]:], lang) {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(text[m[0]:m[1]])) {
+		case "and", "e", "y":
+			weak = append(weak, [2]int{m[0], m[1]})
+		default:
+			strong = append(strong, [2]int{m[0], m[1]})
+		}
+	}
+	switch {
+	case len(strong) > 0:
+		return strong[len(strong)-1], true
+	case len(weak) > 0:
+		return weak[0], true
+	}
+	return sep, false
+}
+
+// quickNLPRules holds the date rules for languages other than English.
+// They are experimental and gated by the quick_nlp_languages feature flag.
+var quickNLPRules = map[string][]rules.Rule{
+	"pt": br.All,
+}
+
+// quickLanguageRules returns the extra date rules for the active language, if enabled.
+func quickLanguageRules(cmd *cobra.Command) []rules.Rule {
+	return quickNLPRules[quickLanguage(cmd)]
+}
+
+// quickLanguage is the language quick reads besides English, or "" when
+// the quick_nlp_languages feature is off.
+func quickLanguage(cmd *cobra.Command) string {
+	cfg, err := config.Current()
+	if err != nil || !cfg.FeatureEnabled(config.FeatureQuickNLPLanguages) {
+		return ""
+	}
+	lang := cfg.Language
+	if flagLang, _ := cmd.Flags().GetString("language"); strings.TrimSpace(flagLang) != "" {
+		lang = flagLang
+	}
+	return strings.ToLower(strings.TrimSpace(lang))
 }
 
 func resolveQuickTimezone(cmd *cobra.Command) string {
-	cfg, _ := config.Load()
+	cfg, _ := config.Current()
 	defaultTZ := ""
 	if cfg != nil {
 		if v, err := cfg.Get("timezone"); err == nil {
@@ -141,6 +456,42 @@ func resolveQuickTimezone(cmd *cobra.Command) string {
 	return firstNonEmpty(flagTZ, defaultTZ)
 }
 
+// resolveTimezoneFlags turns city names and typos given to timezone flags
+// into IANA zones ("--timezone lisbon" becomes Europe/Lisbon) before the
+// command runs. Zones, deprecated names and values too ambiguous to resolve
+// are left for the command to validate.
+func resolveTimezoneFlags(cmd *cobra.Command) error {
+	var tm *tzpkg.TimezoneManager
+	for _, name := range timezoneFlags {
+		f := cmd.Flags().Lookup(name)
+		if f == nil || !f.Changed {
+			continue
+		}
+		value := strings.TrimSpace(f.Value.String())
+		if value == "" || strings.EqualFold(value, "local") {
+			continue
+		}
+		if _, err := time.LoadLocation(value); err == nil {
+			continue
+		}
+		if _, deprecated := tzpkg.CanonicalName(value); deprecated {
+			continue
+		}
+		if tm == nil {
+			tm = tzpkg.NewTimezoneManager()
+		}
+		zone, ok := tm.Resolve(value)
+		if !ok {
+			continue
+		}
+		if err := f.Value.Set(zone.IANA); err != nil {
+			return err
+		}
+		output.Info(os.Stderr, "🌍", "--%s %s: using %s\n", name, value, zone.IANA)
+	}
+	return nil
+}
+
 func applyTimezoneToDetails(details *quickParsedEvent, tz string) {
 	if tz == "" {
 		return
@@ -156,14 +507,17 @@ func applyTimezoneToDetails(details *quickParsedEvent, tz string) {
 func confirmQuickEvent(details quickParsedEvent, tz string) bool {
 	fmt.Println("I understood the following event:")
 	fmt.Printf("  Summary:   %s\n", details.Summary)
-	fmt.Printf("  Start:     %s\n", details.StartTime.Format(constants.DateTimeFormatRFC1123))
-	fmt.Printf("  End:       %s\n", details.EndTime.Format(constants.DateTimeFormatRFC1123))
+	fmt.Printf("  Start:     %s %s %s\n", displayDate(details.StartTime), displayClock(details.StartTime), details.StartTime.Format("MST"))
+	fmt.Printf("  End:       %s %s %s\n", displayDate(details.EndTime), displayClock(details.EndTime), details.EndTime.Format("MST"))
 	if details.Location != "" {
 		fmt.Printf("  Location:  %s\n", details.Location)
 	}
 	if tz != "" {
 		fmt.Printf("  Timezone:  %s\n", tz)
 	}
+	if rule := details.rrule(tz); rule != "" {
+		fmt.Printf("  Repeats:   %s\n", rule)
+	}
 
 	confirmPrompt := &survey.Confirm{
 		Message: "Does this look correct?",
@@ -177,46 +531,55 @@ func confirmQuickEvent(details quickParsedEvent, tz string) bool {
 	return confirmed
 }
 
-func getQuickOutput(cmd *cobra.Command, summary string) string {
+func getQuickOutput(cmd *cobra.Command, summary string, start time.Time) string {
 	output, _ := cmd.Flags().GetString("output")
 	if output == "" {
-		output = fmt.Sprintf("%s.ics", slugify(summary))
+		output = fileSlug(summary, start) + ".ics"
 	}
 	return output
 }
 
-func writeQuickCalendar(details quickParsedEvent, tz, output string) error {
+func writeQuickCalendar(events []quickParsedEvent, tz, output, qrPath string, policy config.OutputPolicy) error {
 	cal := calendar.NewCalendar()
 	cal.IncludeVTZ = true
-	cal.Name = details.Summary
+	if len(events) == 1 {
+		cal.Name = events[0].Summary
+	}
 	if tz != "" {
 		cal.SetDefaultTimezone(tz)
 	}
 
-	event := calendar.NewEvent(details.Summary, details.StartTime, details.EndTime)
-	if details.Location != "" {
-		event.Location = details.Location
-	}
-	if tz != "" {
-		event.SetStartTimezone(tz)
-		event.SetEndTimezone(tz)
+	for _, details := range events {
+		event := calendar.NewEvent(details.Summary, details.StartTime, details.EndTime)
+		if details.Location != "" {
+			event.Location = details.Location
+		}
+		if tz != "" {
+			event.SetStartTimezone(tz)
+			event.SetEndTimezone(tz)
+		}
+		event.RRule = details.rrule(tz)
+		cal.AddEvent(event)
 	}
+	applyCategoryColors(cal)
 
-	cal.AddEvent(event)
-	icsContent := cal.ToICS()
-
-	if err := os.WriteFile(output, []byte(icsContent), 0600); err != nil {
+	if err := writeGeneratedStream(output, policy, cal.WriteICS); err != nil {
 		printErr(constants.ErrMsgFailedToWriteFile, err)
 		return err
 	}
 	printOK(constants.MsgCreatedFile, output)
 
-	return nil
+	return writeEventQR(os.Stdout, qrPath, cal.Events, policy)
 }
 
 // extractEventDetails uses regex and string manipulation to pull out details.
+// quickConnector is the word left before a date/time the parser did not
+// take along: "call at 9am", "review às 10h".
+var quickConnector = regexp.MustCompile(`(?i)(?:^|\s)(?:at|@|às|on)\s*$`)
+
 func extractEventDetails(text string, res *when.Result) quickParsedEvent {
-	summary := strings.TrimSpace(strings.Replace(text, res.Text, "", 1))
+	before := quickConnector.ReplaceAllString(text[:res.Index], "")
+	summary := strings.TrimSpace(before + " " + text[res.Index+len(res.Text):])
 
 	// Simple regex for duration and location
 	durRegex := regexp.MustCompile(`(?i)\b(?:for|duration)\s+((?:\d+\s*)?(?:h|hr|hour|m|min|minute)s?)`)
@@ -257,6 +620,104 @@ func extractEventDetails(text string, res *when.Result) quickParsedEvent {
 	}
 }
 
+// configureOutput applies the global --quiet, --verbose, --no-emoji,
+// --no-color and --output-format flags.
+func configureOutput(cmd *cobra.Command) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	noEmoji, _ := cmd.Flags().GetBool("no-emoji")
+	noColor, _ := cmd.Flags().GetBool("no-color")
+	// create and batch shadow --output-format with their calendar formats
+	// (ics, jcal, xcal), which also accept json.
+	if flag := cmd.Flags().Lookup("output-format"); flag != nil && flag == cmd.Root().PersistentFlags().Lookup("output-format") {
+		if format := strings.ToLower(strings.TrimSpace(flag.Value.String())); format != "text" && format != "json" {
+			return fmt.Errorf("invalid --output-format %q (use text or json)", flag.Value.String())
+		}
+	}
+	output.Configure(output.Options{Quiet: quiet, Verbose: verbose, NoEmoji: noEmoji, NoColor: noColor, JSON: jsonReport(cmd)})
+	if tr, err := newTranslator(outputLanguage(cmd)); err == nil {
+		ui = tr
+	}
+	var err error
+	dateLayout, clockLayout, err = displayLayouts(cmd, ui)
+	return err
+}
+
+// displayLayouts picks the date and time layouts for previews and agendas:
+// --date-format and --time-format first, then date_format and time_format
+// from the config when changed from their defaults, then the formats of the
+// output language.
+func displayLayouts(cmd *cobra.Command, tr *i18n.Translator) (string, string, error) {
+	date, clock := tr.DateLayout(), tr.TimeLayout()
+	if cfg, err := config.Current(); err == nil {
+		if cfg.DateFormat != "" && cfg.DateFormat != constants.DateFormatISO {
+			if layout, err := i18n.DateLayout(cfg.DateFormat); err == nil {
+				date = layout
+			}
+		}
+		if cfg.TimeFormat != "" && cfg.TimeFormat != constants.TimeFormatHHMM {
+			if layout, err := i18n.TimeLayout(cfg.TimeFormat); err == nil {
+				clock = layout
+			}
+		}
+	}
+	if v, _ := cmd.Root().PersistentFlags().GetString("date-format"); strings.TrimSpace(v) != "" {
+		layout, err := i18n.DateLayout(v)
+		if err != nil {
+			return "", "", fmt.Errorf("--date-format: %w", err)
+		}
+		date = layout
+	}
+	if v, _ := cmd.Root().PersistentFlags().GetString("time-format"); strings.TrimSpace(v) != "" {
+		layout, err := i18n.TimeLayout(v)
+		if err != nil {
+			return "", "", fmt.Errorf("--time-format: %w", err)
+		}
+		clock = layout
+	}
+	return date, clock, nil
+}
+
+// displayDate shows a day in previews and agendas, e.g. "Mon 12/01/2025".
+func displayDate(t time.Time) string {
+	return t.Format("Mon " + dateLayout)
+}
+
+// displayClock shows a time of day in previews and agendas.
+func displayClock(t time.Time) string {
+	return t.Format(clockLayout)
+}
+
+// displayRecordTime shows a "YYYY-MM-DD[ HH:MM]" or "HH:MM" value from a
+// batch record in the display formats, or s unchanged if it is neither.
+func displayRecordTime(s string) string {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(constants.DateTimeFormatISO, s); err == nil {
+		return displayDate(t) + " " + displayClock(t)
+	}
+	if t, err := time.Parse(constants.DateFormatISO, s); err == nil {
+		return displayDate(t)
+	}
+	if t, err := time.Parse(constants.TimeFormatHHMM, s); err == nil {
+		return displayClock(t)
+	}
+	return s
+}
+
+// jsonReport reports whether --output-format json asked for a JSON document
+// on stdout instead of text.
+func jsonReport(cmd *cobra.Command) bool {
+	format, _ := cmd.Flags().GetString("output-format")
+	return strings.EqualFold(strings.TrimSpace(format), "json")
+}
+
+// printJSON writes v as indented JSON.
+func printJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
 func newCreateCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "create [event-name]",
@@ -265,22 +726,43 @@ func newCreateCmd() *cobra.Command {
 		RunE: runCreate,
 	}
 
-	cmd.Flags().StringP("start", "s", "", "Start date/time (YYYY-MM-DD HH:MM)")
+	cmd.Flags().StringP("start", "s", "", "Start date/time: YYYY-MM-DD HH:MM, HH:MM for today, a natural date (tomorrow 09:30, next tuesday 10:00, +3d 14:00), +Nbd business days (+5bd 09:00), an ISO week date (2026-W05-Tue 10:00), RFC 3339 or a unix epoch, or a solar time such as sunrise+30m with --location-geo")
 	cmd.Flags().StringP("end", "e", "", "End date/time (YYYY-MM-DD HH:MM) or duration (e.g. 60m, 1h30m, 1:00, 90)")
 	cmd.Flags().String("duration", "", "Duration (e.g. 45m, 1h30m, 90)")
 	cmd.Flags().StringP("location", "L", "", "Event location")
+	cmd.Flags().String("location-geo", "", "Coordinates LAT,LON for starts such as sunrise+30m or sunset-1h")
 	cmd.Flags().StringP("description", "d", "", "Event description")
 	cmd.Flags().StringP("start-tz", "", "", "Start timezone")
 	cmd.Flags().StringP("end-tz", "", "", "End timezone")
+	cmd.Flags().String("from-airport", "", "Departure airport IATA code (e.g. MAD); sets --start-tz and, if empty, the location")
+	cmd.Flags().String("to-airport", "", "Arrival airport IATA code (e.g. JFK); sets --end-tz")
 	cmd.Flags().StringP("output", "o", "", "Output file path")
+	cmd.Flags().String("output-format", "auto", "Output format: auto (from --output), ics, jcal, xcal, or json for a JSON summary")
 	cmd.Flags().BoolP("all-day", "a", false, "All-day event")
 	cmd.Flags().String("rrule", "", "Recurrence rule (RRULE), e.g. FREQ=DAILY;COUNT=10")
+	cmd.Flags().String("rrule-until", "", "Last day of the --rrule series: YYYY-MM-DD, end-of-month, end-of-quarter, end-of-semester, end-of-year or \"in 12 weeks\"")
 	cmd.Flags().StringArray("exdate", []string{}, "Exclude date/time (EXDATE). Repeat flag for multiple values (YYYY-MM-DD or YYYY-MM-DD HH:MM)")
+	cmd.Flags().StringArray("override", []string{}, "Move one occurrence of the series (RECURRENCE-ID), e.g. \"2025-12-23 14:00 => 2025-12-23 16:00\". Repeat for multiple values")
+	cmd.Flags().StringArray("rdate", []string{}, "Extra occurrence date/time (RDATE), e.g. a makeup class. Repeat flag for multiple values (YYYY-MM-DD or YYYY-MM-DD HH:MM)")
 	cmd.Flags().StringArray("alarm", []string{}, "Reminder (VALARM). Repeat for multiple values (e.g. 15m, trigger=-30m,description=Boarding Pass)")
 	cmd.Flags().StringArray("category", []string{}, "Category label(s) to attach to the event (repeat flag for multiple values)")
 	cmd.Flags().StringArray("attendee", []string{}, "Attendee email address (repeat flag for multiple values)")
 	cmd.Flags().Int("priority", 0, "Event priority (1-9, 0 to omit)")
+	cmd.Flags().String("status", "", "Event status: tentative, confirmed (default) or cancelled")
+	cmd.Flags().String("class", "", "Access class for shared calendars: public, private or confidential")
+	cmd.Flags().Bool("emit-duration", false, "Write DURATION instead of DTEND")
+	cmd.Flags().Bool("strict", false, "Fail instead of warning when the event or its alarms break working/quiet hours")
+	cmd.Flags().Bool("strict-input", false, "Require fully explicit input: no clock-only dates or default duration")
+	cmd.Flags().Bool("overnight", false, "A clock-only --end is on the day after the start (e.g. --start 23:00 --end 01:00)")
+	cmd.Flags().Bool("translate-categories", false, "Write category names in the output language (--language or config), e.g. Work → Trabajo")
+	addDayFilterFlags(cmd)
 	cmd.Flags().BoolP("interactive", "i", false, "Create an event using an interactive questionnaire")
+	cmd.Flags().Bool("dry-run", false, "Check everything and show a preview of the event without writing anything")
+	cmd.Flags().Bool("print", false, "Write the calendar to stdout (and still to --output if given)")
+	cmd.Flags().String("qr", "", "Also save the event as a QR code PNG that a phone can scan into its calendar")
+	cmd.Flags().Bool("open", false, "Open the written calendar in the default calendar app to import it")
+	cmd.MarkFlagsMutuallyExclusive("dry-run", "print")
+	cmd.MarkFlagsMutuallyExclusive("dry-run", "open")
 
 	return cmd
 }
@@ -301,1533 +783,10390 @@ func runCreate(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	startTime, endTime, err := parseCreateTimes(opts)
-	if err != nil {
+	var cal *calendar.Calendar
+	if solar, ok, err := calendar.ParseSolarTime(opts.startStr); err != nil {
 		return err
+	} else if ok {
+		if len(opts.overrides) > 0 {
+			return fmt.Errorf("--override needs a fixed start time, not %s", solar.Anchor)
+		}
+		if cal, err = createSolarCalendar(opts, solar); err != nil {
+			return err
+		}
+	} else {
+		startTime, endTime, err := parseCreateTimes(opts)
+		if err != nil {
+			return err
+		}
+		if cal, err = createCalendarWithEvent(opts, startTime, endTime); err != nil {
+			return err
+		}
 	}
-
-	cal := createCalendarWithEvent(opts, startTime, endTime)
-	return writeCalendarOutput(cal, opts.output)
-}
-
-type createOptions struct {
-	summary     string
-	startStr    string
-	endStr      string
-	durStr      string
-	location    string
-	description string
-	startTZ     string
-	endTZ       string
-	output      string
-	allDay      bool
-	rrule       string
-	exdates     []string
-	alarms      []string
-	categories  []string
-	attendees   []string
-	priority    int
-}
-
-func parseCreateFlags(cmd *cobra.Command, args []string) (*createOptions, error) {
-	opts := &createOptions{summary: args[0]}
-	opts.startStr, _ = cmd.Flags().GetString("start")
-	opts.endStr, _ = cmd.Flags().GetString("end")
-	opts.durStr, _ = cmd.Flags().GetString("duration")
-	opts.location, _ = cmd.Flags().GetString("location")
-	opts.description, _ = cmd.Flags().GetString("description")
-	opts.startTZ, _ = cmd.Flags().GetString("start-tz")
-	opts.endTZ, _ = cmd.Flags().GetString("end-tz")
-	opts.output, _ = cmd.Flags().GetString("output")
-	opts.allDay, _ = cmd.Flags().GetBool("all-day")
-	opts.rrule, _ = cmd.Flags().GetString("rrule")
-	opts.exdates, _ = cmd.Flags().GetStringArray("exdate")
-	opts.alarms, _ = cmd.Flags().GetStringArray("alarm")
-	opts.categories, _ = cmd.Flags().GetStringArray("category")
-	opts.attendees, _ = cmd.Flags().GetStringArray("attendee")
-	opts.priority, _ = cmd.Flags().GetInt("priority")
-
-	if opts.priority < 0 || opts.priority > 9 {
-		return nil, fmt.Errorf("priority must be between 0 and 9")
+	if err := setCategoryTranslation(cal, opts.categoryLang); err != nil {
+		return err
 	}
-
-	if strings.TrimSpace(opts.startStr) == "" {
-		return nil, fmt.Errorf("start time is required (use --start)")
+	opts.warnings = append(opts.warnings, dayFilterWarnings(applyDayFilter(cal.Events, opts.days))...)
+	opts.warnings = append(opts.warnings, eventTimeWarnings(cal.Events)...)
+	if opts.qr != "" && len(cal.Events) != 1 {
+		return fmt.Errorf("--qr shares a single event, but this calendar has %d", len(cal.Events))
 	}
-
-	opts.startStr = normalizeTimeInput(opts.startStr, opts.startTZ, opts.endTZ)
-	opts.endStr = normalizeTimeInput(opts.endStr, opts.startTZ, opts.endTZ)
-
-	return opts, nil
-}
-
-func normalizeTimeInput(timeStr, startTZ, endTZ string) string {
-	if timeStr != "" && looksLikeClock(timeStr) {
-		return prependToday(timeStr, firstNonEmpty(startTZ, endTZ, ""))
+	if opts.dryRun {
+		return previewCreate(cal, opts)
 	}
-	return timeStr
-}
-
-func parseCreateTimes(opts *createOptions) (startTime, endTime time.Time, err error) {
-	if opts.allDay {
-		return parseAllDayTimes(opts.startStr, opts.endStr)
+	diag.Render(os.Stderr, opts.warnings)
+	if err := checkEventHours(cal.Events, opts.strict); err != nil {
+		return err
 	}
-	return parseTimedEventTimes(opts.startStr, opts.endStr, opts.durStr)
-}
-
-func parseAllDayTimes(startStr, endStr string) (startTime, endTime time.Time, err error) {
-	startTime, err = time.Parse("2006-01-02", startStr)
-	if err != nil {
-		return time.Time{}, time.Time{}, fmt.Errorf("invalid start date: %w", err)
+	if opts.print {
+		if opts.output == "" {
+			if err := writeCalendar(os.Stdout, cal, opts.outputFormat); err != nil {
+				return err
+			}
+			return finishCreate(os.Stderr, opts, cal.Events)
+		}
+		err := writeGeneratedStream(opts.output, opts.policy, func(w io.Writer) error {
+			return writeCalendar(io.MultiWriter(os.Stdout, w), cal, opts.outputFormat)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", opts.output, err)
+		}
+		output.OK(os.Stderr, constants.MsgCreatedFile, opts.output)
+		return finishCreate(os.Stderr, opts, cal.Events)
 	}
-
-	if strings.TrimSpace(endStr) == "" {
-		endTime = startTime.AddDate(0, 0, 1)
-	} else {
-		endDate, parseErr := time.Parse("2006-01-02", endStr)
-		if parseErr != nil {
-			return time.Time{}, time.Time{}, fmt.Errorf("invalid end date: %w", parseErr)
+	if opts.jsonReport {
+		if opts.output == "" {
+			return fmt.Errorf("--output-format json needs --output for the calendar")
 		}
-		if endDate.Before(startTime) {
-			return time.Time{}, time.Time{}, fmt.Errorf(testutil.ErrMsgEndDateAfterStart)
+		if err := writeCalendarOutput(cal, opts.output, opts.outputFormat, opts.policy); err != nil {
+			return err
 		}
-		endTime = endDate.AddDate(0, 0, 1)
+		if err := finishCreate(os.Stderr, opts, cal.Events); err != nil {
+			return err
+		}
+		summary := summarizeBatch(cal, opts.output)
+		summary.Warnings = opts.warnings
+		return printJSON(os.Stdout, summary)
 	}
-
-	if !endTime.After(startTime) {
-		return time.Time{}, time.Time{}, fmt.Errorf(testutil.ErrMsgEndDateAfterStart)
+	if err := writeCalendarOutput(cal, opts.output, opts.outputFormat, opts.policy); err != nil {
+		return err
 	}
+	w := io.Writer(os.Stdout)
+	if opts.output == "" {
+		w = os.Stderr // the calendar itself went to stdout
+	}
+	return finishCreate(w, opts, cal.Events)
+}
 
-	return startTime, endTime, nil
+// finishCreate saves the --qr image and opens the calendar for --open once
+// the calendar is written; confirmations go to w.
+func finishCreate(w io.Writer, opts *createOptions, events []calendar.Event) error {
+	if err := writeEventQR(w, opts.qr, events, opts.policy); err != nil {
+		return err
+	}
+	if opts.open {
+		openInCalendarApp(w, opts.output)
+	}
+	return nil
 }
 
-func parseTimedEventTimes(startStr, endStr, durStr string) (startTime, endTime time.Time, err error) {
-	startTime, err = time.Parse("2006-01-02 15:04", startStr)
+// openFile hands a file to the desktop's default app; tests swap it out to
+// record the path instead of launching anything.
+var openFile = openWithDesktop
+
+// openWithDesktop starts the platform opener on path and does not wait for
+// the app it launches. Windows uses the URL handler rather than "start",
+// which is a cmd.exe builtin that misreads paths with & or ^.
+func openWithDesktop(path string) error {
+	abs, err := filepath.Abs(path)
 	if err != nil {
-		return time.Time{}, time.Time{}, fmt.Errorf(testutil.ErrMsgInvalidStartTimeFormat, err)
+		return err
 	}
-
-	switch {
-	case strings.TrimSpace(endStr) != "":
-		endTime, err = parseEndTime(startTime, endStr)
-	case strings.TrimSpace(durStr) != "":
-		endTime, err = parseDurationEnd(startTime, durStr)
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", abs)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", abs)
 	default:
-		endTime = startTime.Add(1 * time.Hour)
+		cmd = exec.Command("xdg-open", abs)
 	}
-
-	if err != nil {
-		return time.Time{}, time.Time{}, err
+	if err := cmd.Start(); err != nil {
+		return err
 	}
+	go func() { _ = cmd.Wait() }() // reap the opener; the import happens in the app
+	return nil
+}
 
-	if !endTime.After(startTime) {
-		return time.Time{}, time.Time{}, fmt.Errorf("end time must be after start time")
+// openInCalendarApp opens a written calendar for --open. Failing to open is
+// only a warning: the file is there to import by hand.
+func openInCalendarApp(w io.Writer, path string) {
+	if err := openFile(path); err != nil {
+		output.Warn(os.Stderr, "%s\n", ui.T("open_failed", path, err))
+		return
 	}
-
-	return startTime, endTime, nil
+	output.Info(w, "%s\n", ui.T("open_started", path))
 }
 
-func parseEndTime(startTime time.Time, endStr string) (time.Time, error) {
-	if d, derr := calendar.ParseHumanDuration(endStr); derr == nil {
-		if d <= 0 {
-			return time.Time{}, fmt.Errorf(testutil.ErrMsgDurationGreaterThanZero)
-		}
-		return startTime.Add(d), nil
-	}
+// qrModulePixels is the size of one QR module in --qr images.
+const qrModulePixels = 8
 
-	endTime, err := time.Parse("2006-01-02 15:04", endStr)
+// writeEventQR saves the only event of events as a QR code PNG at path, in
+// the bare VEVENT form phone scanners add to a calendar. An empty path does
+// nothing. Level M survives some smudging; text too long for it falls back to
+// level L, which holds more.
+func writeEventQR(w io.Writer, path string, events []calendar.Event, policy config.OutputPolicy) error {
+	if path == "" {
+		return nil
+	}
+	if len(events) != 1 {
+		return fmt.Errorf("--qr shares a single event, but this calendar has %d", len(events))
+	}
+	text := calendar.ShareText(events[0])
+	code, err := qr.Encode(text, qr.M)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("invalid end time: %w", err)
+		code, err = qr.Encode(text, qr.L)
 	}
-	return endTime, nil
-}
-
-func parseDurationEnd(startTime time.Time, durStr string) (time.Time, error) {
-	d, err := calendar.ParseHumanDuration(durStr)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("invalid duration: %v", err)
+		return fmt.Errorf("--qr: %w; shorten the description", err)
 	}
-	if d <= 0 {
-		return time.Time{}, fmt.Errorf(testutil.ErrMsgDurationGreaterThanZero)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, code.Image(qrModulePixels)); err != nil {
+		return err
 	}
-	return startTime.Add(d), nil
+	if err := writeGeneratedFile(path, buf.Bytes(), policy); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	output.OK(w, "%s\n", ui.T("qr_written", path, code.Size, code.Size))
+	return nil
 }
 
-func createCalendarWithEvent(opts *createOptions, startTime, endTime time.Time) *calendar.Calendar {
-	cal := calendar.NewCalendar()
-	cal.IncludeVTZ = true
-	cal.Name = opts.summary
-	if tz := firstNonEmpty(opts.startTZ, opts.endTZ); strings.TrimSpace(tz) != "" {
-		cal.SetDefaultTimezone(tz)
-	}
-
-	event := calendar.NewEvent(opts.summary, startTime, endTime)
-	configureEvent(event, opts)
-	cal.AddEvent(event)
+// previewOccurrences is how many upcoming occurrences the create preview
+// lists for a recurring event.
+const previewOccurrences = 3
 
-	return cal
-}
-
-func configureEvent(event *calendar.Event, opts *createOptions) {
-	event.AllDay = opts.allDay
-	if opts.location != "" {
-		event.Location = opts.location
-	}
-	if opts.description != "" {
-		event.Description = opts.description
+// previewCreate prints what create would write, with its warnings, and writes
+// nothing. With --output-format json it prints the summary batch --dry-run does.
+func previewCreate(cal *calendar.Calendar, opts *createOptions) error {
+	if opts.jsonReport {
+		summary := summarizeBatch(cal, opts.output)
+		if opts.output == "" {
+			summary.Calendars = []string{}
+		}
+		summary.DryRun = true
+		summary.Warnings = opts.warnings
+		return printJSON(os.Stdout, summary)
 	}
 
-	setEventTimezones(event, opts.startTZ, opts.endTZ)
-
-	if strings.TrimSpace(opts.rrule) != "" {
-		event.RRule = strings.TrimSpace(opts.rrule)
+	dest := ui.T("create_preview_stdout")
+	if opts.output != "" {
+		dest = opts.output
 	}
-
-	addEventExDates(event, opts.exdates, opts.startTZ, opts.allDay)
-	addEventAlarms(event, opts.alarms, opts.startTZ)
-	addEventCategories(event, opts.categories)
-	addEventAttendees(event, opts.attendees)
-
-	if opts.priority > 0 {
-		event.Priority = opts.priority
+	output.Info(os.Stdout, "🔍", "%s\n", ui.T("create_preview_header", len(cal.Events)))
+	row := func(label, value string) {
+		if value != "" {
+			fmt.Printf("  %-12s %s\n", ui.T(label), value)
+		}
 	}
-}
+	for i, ev := range cal.Events {
+		if i > 0 {
+			fmt.Println()
+		}
+		row("create_preview_summary", ev.Summary)
+		row("create_preview_start", previewTime(ev.StartTime, ev.StartTZ, ev.AllDay))
+		end := ev.EndTime
+		if ev.AllDay {
+			end = end.AddDate(0, 0, -1) // DTEND is exclusive
+		}
+		row("create_preview_end", previewTime(end, ev.EndTZ, ev.AllDay))
+		if ev.RRule != "" {
+			repeats := ev.RRule
+			if rule, err := calendar.ParseRRule(ev.RRule); err == nil {
+				var next []string
+				for _, t := range rule.Occurrences(ev.StartTime, previewOccurrences+1+len(ev.ExDates)) {
+					if !t.After(ev.StartTime) {
+						continue // the event itself; an UNTIL before it leaves nothing
+					}
+					excluded := slices.ContainsFunc(ev.ExDates, func(x time.Time) bool {
+						return x.Format(constants.DateTimeFormatISO) == t.Format(constants.DateTimeFormatISO)
+					})
+					if !excluded && len(next) < previewOccurrences {
+						next = append(next, displayDate(t))
+					}
+				}
+				if len(next) > 0 {
+					repeats += " (" + ui.T("create_preview_then", strings.Join(next, ", ")) + ")"
+				}
+			}
+			row("create_preview_repeats", repeats)
+		}
+		var except []string
+		for _, t := range ev.ExDates {
+			except = append(except, previewTime(t, "", ev.AllDay))
+		}
+		row("create_preview_except", strings.Join(except, ", "))
+		var alarms []string
+		for _, a := range ev.Alarms {
+			alarms = append(alarms, previewAlarm(a))
+		}
+		row("create_preview_alarms", strings.Join(alarms, ", "))
+		row("create_preview_location", ev.Location)
+		row("create_preview_categories", strings.Join(ev.Categories, ", "))
+		row("create_preview_attendees", strings.Join(ev.Attendees, ", "))
+	}
+	fmt.Println()
+	row("create_preview_output", fmt.Sprintf("%s (%s)", dest, opts.outputFormat))
 
-func setEventTimezones(event *calendar.Event, startTZ, endTZ string) {
-	if startTZ != "" {
-		event.SetStartTimezone(startTZ)
+	if len(opts.warnings) > 0 {
+		fmt.Println()
+		diag.Render(os.Stdout, opts.warnings)
 	}
-	if endTZ != "" {
-		event.SetEndTimezone(endTZ)
-	} else if startTZ != "" {
-		event.SetEndTimezone(startTZ)
+	if err := checkEventHours(cal.Events, opts.strict); err != nil {
+		return err
 	}
+	fmt.Printf("\n%s\n", ui.T("create_preview_hint"))
+	return nil
 }
 
-func addEventExDates(event *calendar.Event, exdates []string, startTZ string, allDay bool) {
-	if len(exdates) == 0 {
-		return
-	}
-
-	tzForExdate := strings.TrimSpace(event.StartTZ)
-	if tzForExdate == "" {
-		tzForExdate = strings.TrimSpace(startTZ)
+// previewTime shows a wall-clock time with its zone, or a date for all-day events.
+func previewTime(t time.Time, tz string, allDay bool) string {
+	if allDay {
+		return displayDate(t)
 	}
-
-	parsed, err := parseExDateValues(exdates, tzForExdate, allDay)
-	if err == nil && len(parsed) > 0 {
-		event.ExDates = append(event.ExDates, parsed...)
+	s := displayDate(t) + " " + displayClock(t)
+	if tz = strings.TrimSpace(tz); tz != "" {
+		s += " " + tz
 	}
+	return s
 }
 
-func addEventAlarms(event *calendar.Event, alarms []string, startTZ string) {
-	if len(alarms) == 0 {
-		return
+// previewAlarm describes an alarm for people: "15 min before", "at Mon 12/01/2025 08:00".
+func previewAlarm(a calendar.Alarm) string {
+	if !a.TriggerIsRelative {
+		return ui.T("create_preview_alarm_at", previewTime(a.TriggerTime, "UTC", false))
 	}
-
-	defaultAlarmTZ := strings.TrimSpace(event.StartTZ)
-	if defaultAlarmTZ == "" {
-		defaultAlarmTZ = strings.TrimSpace(startTZ)
+	if a.TriggerDuration > 0 {
+		return ui.T("create_preview_alarm_after", formatSpan(a.TriggerDuration))
 	}
-
-	parsed, err := calendar.ParseAlarmSpecs(alarms, defaultAlarmTZ)
-	if err == nil && len(parsed) > 0 {
-		event.Alarms = append(event.Alarms, parsed...)
+	if a.TriggerDuration == 0 {
+		return ui.T("create_preview_alarm_start")
 	}
+	return ui.T("create_preview_alarm_before", formatSpan(-a.TriggerDuration))
 }
 
-func addEventCategories(event *calendar.Event, categories []string) {
-	for _, cat := range categories {
-		if c := strings.TrimSpace(cat); c != "" {
-			event.AddCategory(c)
+// eventTimeWarnings flags starts and ends that fall in a DST gap (the time
+// does not exist) or fold (it happens twice), plus the sanity checks.
+func eventTimeWarnings(events []calendar.Event) []diag.Warning {
+	var warnings []diag.Warning
+	limits := sanityLimits()
+	for _, ev := range events {
+		warnings = append(warnings, sanityWarnings(ev, limits)...)
+		if ev.AllDay {
+			continue
+		}
+		for _, edge := range []struct {
+			label string
+			wall  time.Time
+			tz    string
+		}{{ui.T("create_dst_start"), ev.StartTime, ev.StartTZ}, {ui.T("create_dst_end"), ev.EndTime, ev.EndTZ}} {
+			kind, at := calendar.CheckWallClock(edge.wall, edge.tz)
+			when := displayDate(edge.wall) + " " + displayClock(edge.wall)
+			switch kind {
+			case calendar.DSTGap:
+				warnings = append(warnings, diag.Warning{
+					Code: diag.CodeDST, Severity: diag.SeverityWarning,
+					Message:    ui.T("create_dst_gap", ev.Summary, edge.label, when, edge.tz, displayClock(at)),
+					Suggestion: ui.T("create_dst_hint"),
+				})
+			case calendar.DSTFold:
+				warnings = append(warnings, diag.Warning{
+					Code: diag.CodeDST, Severity: diag.SeverityInfo,
+					Message:    ui.T("create_dst_fold", ev.Summary, edge.label, when, edge.tz),
+					Suggestion: ui.T("create_dst_hint"),
+				})
+			}
 		}
 	}
+	return warnings
 }
 
-func addEventAttendees(event *calendar.Event, attendees []string) {
-	for _, attendee := range attendees {
-		if a := strings.TrimSpace(attendee); a != "" {
-			event.AddAttendee(a)
-		}
+// sanityLimits returns the default sanity thresholds with the past one taken
+// from past_warning_days.
+func sanityLimits() calendar.SanityLimits {
+	limits := calendar.DefaultSanityLimits
+	if cfg, err := config.Current(); err == nil {
+		limits.Past = time.Duration(cfg.PastWarningDays) * 24 * time.Hour
 	}
+	return limits
 }
 
-func writeCalendarOutput(cal *calendar.Calendar, output string) error {
-	icsContent := cal.ToICS()
-
-	if output == "" {
-		fmt.Print(icsContent)
+// sanityWarnings is the check create, batch and lint share for likely typos
+// in ev: an end long in the past (a wrong year), an alarm after the event or
+// weeks before it, a timed event over a day and an UNTIL before the start.
+// The warnings carry no file or position; callers add them.
+func sanityWarnings(ev calendar.Event, limits calendar.SanityLimits) []diag.Warning {
+	issues := calendar.CheckSanity(ev, appClock.Now(), limits)
+	if len(issues) == 0 {
 		return nil
 	}
-
-	if err := os.WriteFile(output, []byte(icsContent), 0600); err != nil {
-		printErr(constants.ErrMsgFailedToWriteFile, err)
-		return err
+	loc := time.UTC
+	if l, err := time.LoadLocation(strings.TrimSpace(ev.StartTZ)); err == nil {
+		loc = l
 	}
-	printOK(constants.MsgCreatedFile, output)
-	return nil
+	when := func(t time.Time) string {
+		t = t.In(loc)
+		if ev.AllDay {
+			return displayDate(t)
+		}
+		return displayDate(t) + " " + displayClock(t)
+	}
+	start, end := ev.Instants()
+
+	var warnings []diag.Warning
+	for _, issue := range issues {
+		w := diag.Warning{Severity: diag.SeverityWarning}
+		switch issue.Kind {
+		case calendar.SanityPast:
+			w.Code = diag.CodePast
+			w.Message = ui.T("sanity_past", ev.Summary, when(issue.At), int(limits.Past.Hours()/24))
+			w.Suggestion = ui.T("sanity_past_hint")
+		case calendar.SanityAlarm:
+			w.Code = diag.CodeAlarmWindow
+			if issue.At.After(end) {
+				w.Message = ui.T("sanity_alarm_after", ev.Summary, when(issue.At))
+			} else {
+				w.Message = ui.T("sanity_alarm_early", ev.Summary, when(issue.At), formatSpan(start.Sub(issue.At)))
+			}
+			w.Suggestion = ui.T("sanity_alarm_hint")
+		case calendar.SanityLong:
+			w.Code = diag.CodeLongEvent
+			w.Message = ui.T("sanity_long", ev.Summary, formatSpan(issue.Span))
+			w.Suggestion = ui.T("sanity_long_hint")
+		case calendar.SanityUntil:
+			w.Code = diag.CodeUntil
+			w.Message = ui.T("sanity_until", ev.Summary, when(issue.At), when(start))
+			w.Suggestion = ui.T("sanity_until_hint")
+		default:
+			continue
+		}
+		warnings = append(warnings, w)
+	}
+	return warnings
 }
 
-func newBatchCmd() *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "batch",
-		Short: "Create multiple ICS events from CSV, JSON, or YAML",
-		RunE:  runBatch,
+// collectSanityWarnings runs sanityWarnings over a batch's events.
+func collectSanityWarnings(events []calendar.Event, opts *batchOptions) []diag.Warning {
+	var warnings []diag.Warning
+	limits := sanityLimits()
+	for _, ev := range events {
+		for _, w := range sanityWarnings(ev, limits) {
+			w.File = opts.input
+			warnings = append(warnings, w)
+		}
 	}
+	return warnings
+}
 
-	cmd.Flags().StringP("input", "i", "", "Input file path (CSV, JSON, or YAML)")
-	cmd.Flags().StringP("output", "o", "batch.ics", "Output ICS file path")
-	cmd.Flags().String("format", "auto", "Input format: auto, csv, json, or yaml")
-	cmd.Flags().String("name", "", "Calendar name (X-WR-CALNAME)")
-	cmd.Flags().String("default-tz", "", "Default timezone for rows without start_tz")
-	cmd.Flags().Bool("dry-run", false, "Validate batch file without creating output")
-	cmd.Flags().Bool("check-conflicts", false, "Detect and warn about overlapping events")
-	cmd.Flags().Int("max-events-per-day", 0, "Warn if any day exceeds this number of events (0=unlimited)")
-	cmd.Flags().Bool("add-prep-time", false, "Auto-add preparation/transition time buffers (ADHD time boxing)")
-
-	cmd.AddCommand(newBatchTemplateCmd())
-
-	return cmd
+type createOptions struct {
+	summary      string
+	startStr     string
+	endStr       string
+	durStr       string
+	location     string
+	description  string
+	startTZ      string
+	endTZ        string
+	geo          string // --location-geo, for solar starts
+	output       string
+	outputFormat string // ics, jcal or xcal
+	jsonReport   bool   // --output-format json: print a JSON summary
+	policy       config.OutputPolicy
+	allDay       bool
+	rrule        string
+	rruleUntil   string // --rrule-until: a date or end-of-month, in 12 weeks...
+	exdates      []string
+	rdates       []string
+	alarms       []string
+	categories   []string
+	attendees    []string
+	priority     int
+	status       string // STATUS, "" for the default CONFIRMED
+	class        string // CLASS, "" omits it
+	emitDuration bool   // DURATION instead of DTEND
+	strict       bool
+	strictInput  bool
+	days         dayFilter
+	categoryLang string
+	overnight    bool           // a clock-only end is on the day after the start
+	overrides    []overrideSpec // --override: single occurrences moved to another time
+	warnings     []diag.Warning // non-fatal input notes, e.g. deprecated timezone names
+	dryRun       bool           // preview without writing
+	print        bool           // calendar to stdout, even with --output
+	qr           string         // --qr: PNG file for the event as a QR code
+	open         bool           // --open: hand the written file to the calendar app
 }
 
-func runBatch(cmd *cobra.Command, _ []string) error {
-	opts, err := parseBatchFlags(cmd)
-	if err != nil {
-		return err
+func parseCreateFlags(cmd *cobra.Command, args []string) (*createOptions, error) {
+	opts := &createOptions{summary: args[0]}
+	opts.startStr, _ = cmd.Flags().GetString("start")
+	opts.endStr, _ = cmd.Flags().GetString("end")
+	opts.durStr, _ = cmd.Flags().GetString("duration")
+	opts.location, _ = cmd.Flags().GetString("location")
+	opts.geo, _ = cmd.Flags().GetString("location-geo")
+	opts.description, _ = cmd.Flags().GetString("description")
+	opts.startTZ, _ = cmd.Flags().GetString("start-tz")
+	opts.endTZ, _ = cmd.Flags().GetString("end-tz")
+	if err := applyAirportTimezones(cmd, opts); err != nil {
+		return nil, err
 	}
-
-	records, _, err := loadBatchInput(opts)
-	if err != nil {
-		return err
+	var startWarnings, endWarnings []diag.Warning
+	opts.startTZ, startWarnings = canonicalTimezone(opts.startTZ, "--start-tz")
+	opts.endTZ, endWarnings = canonicalTimezone(opts.endTZ, "--end-tz")
+	opts.warnings = append(startWarnings, endWarnings...)
+	opts.output, _ = cmd.Flags().GetString("output")
+	var err error
+	if opts.policy, err = loadOutputPolicy(); err != nil {
+		return nil, err
 	}
-
-	cal, validationErrors, err := buildBatchCalendar(records, opts)
+	if opts.output != "" {
+		if opts.output, err = resolveOutputPath(opts.output, opts.policy); err != nil {
+			return nil, err
+		}
+	}
+	formatFlag, _ := cmd.Flags().GetString("output-format")
+	if jsonReport(cmd) {
+		opts.jsonReport, formatFlag = true, "auto"
+	}
+	format, err := calendarOutputFormat(formatFlag, opts.output)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	warnings := collectBatchWarnings(cal.Events, opts)
-
-	if opts.dryRun {
-		return handleDryRun(validationErrors, warnings, records, opts.input, opts.output)
+	opts.outputFormat = format
+	opts.allDay, _ = cmd.Flags().GetBool("all-day")
+	opts.rrule, _ = cmd.Flags().GetString("rrule")
+	opts.rruleUntil, _ = cmd.Flags().GetString("rrule-until")
+	if err := checkRRuleUntil(opts.rrule, opts.rruleUntil); err != nil {
+		return nil, err
+	}
+	opts.exdates, _ = cmd.Flags().GetStringArray("exdate")
+	opts.rdates, _ = cmd.Flags().GetStringArray("rdate")
+	opts.alarms, _ = cmd.Flags().GetStringArray("alarm")
+	opts.categories, _ = cmd.Flags().GetStringArray("category")
+	opts.attendees, _ = cmd.Flags().GetStringArray("attendee")
+	opts.priority, _ = cmd.Flags().GetInt("priority")
+	status, _ := cmd.Flags().GetString("status")
+	if opts.status, err = parseEventStatus(status); err != nil {
+		return nil, err
+	}
+	class, _ := cmd.Flags().GetString("class")
+	if opts.class, err = parseEventClass(class); err != nil {
+		return nil, err
+	}
+	opts.emitDuration, _ = cmd.Flags().GetBool("emit-duration")
+	opts.strict, _ = cmd.Flags().GetBool("strict")
+	opts.strictInput, _ = cmd.Flags().GetBool("strict-input")
+	opts.overnight, _ = cmd.Flags().GetBool("overnight")
+	overrides, _ := cmd.Flags().GetStringArray("override")
+	for _, spec := range overrides {
+		o, err := parseOverrideSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		opts.overrides = append(opts.overrides, o)
+	}
+	if translate, _ := cmd.Flags().GetBool("translate-categories"); translate {
+		opts.categoryLang = outputLanguage(cmd)
 	}
-
-	return writeBatchOutput(cal, warnings, opts.output, len(records))
-}
-
-type batchOptions struct {
-	input           string
-	output          string
-	formatFlag      string
-	name            string
-	defaultTZ       string
-	dryRun          bool
-	checkConflicts  bool
-	maxEventsPerDay int
-	addPrepTime     bool
-}
-
-func parseBatchFlags(cmd *cobra.Command) (*batchOptions, error) {
-	opts := &batchOptions{}
-	opts.input, _ = cmd.Flags().GetString("input")
-	opts.output, _ = cmd.Flags().GetString("output")
-	opts.formatFlag, _ = cmd.Flags().GetString("format")
-	opts.name, _ = cmd.Flags().GetString("name")
-	opts.defaultTZ, _ = cmd.Flags().GetString("default-tz")
 	opts.dryRun, _ = cmd.Flags().GetBool("dry-run")
-	opts.checkConflicts, _ = cmd.Flags().GetBool("check-conflicts")
-	opts.maxEventsPerDay, _ = cmd.Flags().GetInt("max-events-per-day")
-	opts.addPrepTime, _ = cmd.Flags().GetBool("add-prep-time")
-
-	opts.input = strings.TrimSpace(opts.input)
-	if opts.input == "" {
-		return nil, fmt.Errorf("--input is required")
+	opts.print, _ = cmd.Flags().GetBool("print")
+	opts.qr, _ = cmd.Flags().GetString("qr")
+	opts.qr = strings.TrimSpace(opts.qr)
+	opts.open, _ = cmd.Flags().GetBool("open")
+	if opts.open && opts.output == "" {
+		return nil, fmt.Errorf("--open needs --output: a calendar written to stdout has no file to open")
 	}
-
-	return opts, nil
-}
-
-func loadBatchInput(opts *batchOptions) ([]batchRecord, batchFormat, error) {
-	format, err := detectBatchFormat(opts.formatFlag, opts.input)
-	if err != nil {
-		return nil, "", err
+	if opts.print && opts.jsonReport {
+		return nil, fmt.Errorf("--print writes the calendar itself; it cannot be combined with --output-format json")
 	}
 
-	records, err := loadBatchRecords(opts.input, format)
+	days, err := parseDayFilterFlags(cmd)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
+	opts.days = days
 
-	if len(records) == 0 {
-		return nil, "", fmt.Errorf("no events found in %s", opts.input)
+	if opts.priority < 0 || opts.priority > 9 {
+		return nil, fmt.Errorf("priority must be between 0 and 9")
 	}
 
-	return records, format, nil
-}
-
-func buildBatchCalendar(records []batchRecord, opts *batchOptions) (*calendar.Calendar, []string, error) {
-	cal := calendar.NewCalendar()
-	cal.IncludeVTZ = true
-
-	if strings.TrimSpace(opts.name) != "" {
-		cal.Name = opts.name
-	}
-	if strings.TrimSpace(opts.defaultTZ) != "" {
-		cal.SetDefaultTimezone(opts.defaultTZ)
+	if strings.TrimSpace(opts.startStr) == "" {
+		return nil, fmt.Errorf("start time is required (use --start)")
 	}
 
-	var validationErrors []string
-	for i, rec := range records {
-		ev, err := buildEventFromBatch(rec, opts.defaultTZ)
-		if err != nil {
-			if opts.dryRun {
-				validationErrors = append(validationErrors, fmt.Sprintf("Row %d: %v", i+1, err))
-				continue
-			}
-			return nil, nil, fmt.Errorf(testutil.ErrMsgRowFormat, i+1, err)
+	rewriteCreateTimestamps(opts)
+
+	if opts.strictInput {
+		if !opts.allDay && strings.TrimSpace(opts.endStr) == "" && strings.TrimSpace(opts.durStr) == "" {
+			return nil, fmt.Errorf("--end or --duration is required with --strict-input")
 		}
-		cal.AddEvent(ev)
+		return opts, nil
 	}
 
-	if opts.addPrepTime {
-		prepEvents := generatePrepTimeEvents(cal.Events)
-		for _, prepEv := range prepEvents {
-			cal.AddEvent(prepEv)
+	lang := outputLanguage(cmd)
+	opts.startStr = expandRelativeInput(opts.startStr, firstNonEmpty(opts.startTZ, opts.endTZ), lang)
+	opts.endStr = expandRelativeInput(opts.endStr, firstNonEmpty(opts.endTZ, opts.startTZ), lang)
+	opts.startStr = normalizeTimeInput(opts.startStr, opts.startTZ, opts.endTZ)
+	if !opts.allDay {
+		end, rolled := overnightEnd(opts.startStr, opts.endStr, opts.overnight)
+		if rolled && !opts.overnight {
+			opts.warnings = append(opts.warnings, diag.Warning{
+				Code: diag.CodeOvernight, Severity: diag.SeverityInfo,
+				Message:    ui.T("overnight_end", strings.TrimSpace(opts.endStr), opts.startStr, end),
+				Suggestion: ui.T("overnight_hint_create"),
+			})
 		}
+		opts.endStr = end
 	}
+	opts.endStr = normalizeTimeInput(opts.endStr, opts.startTZ, opts.endTZ)
 
-	return cal, validationErrors, nil
+	return opts, nil
 }
 
-func collectBatchWarnings(events []calendar.Event, opts *batchOptions) []string {
-	var warnings []string
-
-	if opts.checkConflicts || opts.dryRun {
-		conflicts := detectEventConflicts(events)
-		if len(conflicts) > 0 {
-			warnings = append(warnings, fmt.Sprintf("⚠️  Found %d time conflict(s):", len(conflicts)))
-			for _, conflict := range conflicts {
-				warnings = append(warnings, fmt.Sprintf("  • %s", conflict))
-			}
+// overnightEnd dates a clock-only end on the day of start (YYYY-MM-DD HH:MM),
+// or on the day after when it is earlier than the start, as in 23:00–01:00,
+// or when overnight says so. rolled reports the move to the next day. An end
+// that is not clock-only, or a start without a date, is left unchanged.
+func overnightEnd(start, end string, overnight bool) (out string, rolled bool) {
+	date, clock := splitDateTime(start)
+	day, err := time.Parse(constants.DateFormatISO, date)
+	if err != nil || !looksLikeClock(end) {
+		return end, false
+	}
+	end = strings.TrimSpace(end)
+	if overnight || looksLikeClock(clock) && clockMinutes(end) < clockMinutes(clock) {
+		day, rolled = day.AddDate(0, 0, 1), true
+	}
+	return day.Format(constants.DateFormatISO) + " " + end, rolled
+}
+
+// clockMinutes returns the minutes since midnight of an HH:MM clock.
+func clockMinutes(clock string) int {
+	var h, m int
+	_, _ = fmt.Sscanf(strings.TrimSpace(clock), "%d:%d", &h, &m)
+	return h*60 + m
+}
+
+// applyAirportTimezones fills the start and end timezones from --from-airport
+// and --to-airport; an explicit --start-tz or --end-tz wins. The departure
+// airport also becomes the location when none is given.
+func applyAirportTimezones(cmd *cobra.Command, opts *createOptions) error {
+	for _, f := range []struct {
+		flag, tzFlag string
+		tz           *string
+	}{
+		{"from-airport", "--start-tz", &opts.startTZ},
+		{"to-airport", "--end-tz", &opts.endTZ},
+	} {
+		code, _ := cmd.Flags().GetString(f.flag)
+		if strings.TrimSpace(code) == "" {
+			continue
+		}
+		airport, ok := tzpkg.LookupAirport(code)
+		if !ok {
+			return fmt.Errorf("unknown airport %q for --%s; set its timezone with %s instead", code, f.flag, f.tzFlag)
+		}
+		if strings.TrimSpace(*f.tz) == "" {
+			*f.tz = airport.Zone
+		}
+		if f.flag == "from-airport" && strings.TrimSpace(opts.location) == "" {
+			opts.location = fmt.Sprintf("%s (%s)", airport.Name, airport.Code)
 		}
 	}
+	return nil
+}
 
-	if opts.maxEventsPerDay > 0 || opts.dryRun {
-		overwhelmDays := detectOverwhelmDays(events, opts.maxEventsPerDay)
-		if len(overwhelmDays) > 0 {
-			warnings = append(warnings, "⚠️  Days with high event load:")
-			for _, day := range overwhelmDays {
-				warnings = append(warnings, fmt.Sprintf("  • %s", day))
-			}
+// rewriteCreateTimestamps reads RFC 3339 and epoch values of --start and
+// --end (2025-12-16T10:00:00+01:00, @1765875600) as wall clock times in
+// --start-tz/--end-tz. A bracketed zone such as [Europe/Madrid] sets a
+// timezone that was not given; otherwise zone-less events stay in UTC.
+func rewriteCreateTimestamps(opts *createOptions) {
+	if out, zone, ok := normalizer.RewriteTimestamp(opts.startStr, opts.startTZ, opts.allDay); ok {
+		opts.startStr = out
+		if opts.startTZ == "" {
+			opts.startTZ = zone
+		}
+	}
+	if out, zone, ok := normalizer.RewriteTimestamp(opts.endStr, firstNonEmpty(opts.endTZ, opts.startTZ), opts.allDay); ok {
+		opts.endStr = out
+		if opts.endTZ == "" && zone != opts.startTZ {
+			opts.endTZ = zone
 		}
 	}
-
-	return warnings
 }
 
-func handleDryRun(validationErrors, warnings []string, records []batchRecord, input, output string) error {
-	if len(validationErrors) > 0 {
-		printErr("Validation failed with %d error(s):\n", len(validationErrors))
-		for _, errMsg := range validationErrors {
-			fmt.Printf("  ❌ %s\n", errMsg)
+// expandRelativeInput turns a relative date such as "tomorrow 09:30",
+// "+3d 14:00" or "+5bd", or a week date such as "2026-W05-Tue 10:00", into
+// "YYYY-MM-DD HH:MM", counting from today in tz (or local time) and laying
+// out weeks as lang does. Anything else is returned unchanged.
+func expandRelativeInput(input, tz, lang string) string {
+	if out, ok := normalizer.ExpandWeekDate(input, localeWeekStart(lang)); ok {
+		return out
+	}
+	loc := time.Local
+	if tz = strings.TrimSpace(tz); tz != "" {
+		if l, err := time.LoadLocation(tz); err == nil {
+			loc = l
 		}
-		return fmt.Errorf("validation failed")
 	}
+	if out, ok := normalizer.ExpandRelative(input, appClock.Now().In(loc), lang); ok {
+		return out
+	}
+	return input
+}
 
-	printOK("✓ Validation passed: %d events ready to create\n", len(records))
+// localeWeekStart is the first day of the week in lang's locale.
+func localeWeekStart(lang string) time.Weekday {
+	if lang == "" || strings.EqualFold(lang, ui.GetLanguage()) {
+		return ui.WeekStart()
+	}
+	if tr, err := newTranslator(lang); err == nil {
+		return tr.WeekStart()
+	}
+	return time.Monday
+}
 
-	if len(warnings) > 0 {
-		fmt.Printf("\n")
-		for _, warning := range warnings {
-			fmt.Println(warning)
-		}
+func normalizeTimeInput(timeStr, startTZ, endTZ string) string {
+	if timeStr != "" && looksLikeClock(timeStr) {
+		return prependToday(timeStr, firstNonEmpty(startTZ, endTZ, ""))
 	}
+	return timeStr
+}
 
-	printDryRunSummary(records, input, output)
-	return nil
+func parseCreateTimes(opts *createOptions) (startTime, endTime time.Time, err error) {
+	if opts.allDay {
+		return parseAllDayTimes(opts.startStr, opts.endStr)
+	}
+	return parseTimedEventTimes(opts.startStr, opts.endStr, opts.durStr)
 }
 
-func printDryRunSummary(records []batchRecord, input, output string) {
-	fmt.Printf("\nEvent summary:\n")
-	for i, rec := range records {
-		summary := rec.Summary
-		if summary == "" {
-			summary = "(no summary)"
+func parseAllDayTimes(startStr, endStr string) (startTime, endTime time.Time, err error) {
+	startTime, err = time.Parse("2006-01-02", startStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start date: %w", err)
+	}
+
+	if strings.TrimSpace(endStr) == "" {
+		endTime = startTime.AddDate(0, 0, 1)
+	} else {
+		endDate, parseErr := time.Parse("2006-01-02", endStr)
+		if parseErr != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end date: %w", parseErr)
 		}
-		start := rec.Start
-		if start == "" {
-			start = "(no start)"
+		if endDate.Before(startTime) {
+			return time.Time{}, time.Time{}, fmt.Errorf(testutil.ErrMsgEndDateAfterStart)
 		}
-		fmt.Printf("  %d. %s - %s\n", i+1, summary, start)
+		endTime = endDate.AddDate(0, 0, 1)
 	}
-	fmt.Printf("\nTo create the calendar file, run:\n")
-	fmt.Printf("  tempus batch -i %s -o %s\n", input, output)
-}
 
-func writeBatchOutput(cal *calendar.Calendar, warnings []string, output string, eventCount int) error {
-	if len(warnings) > 0 {
-		fmt.Printf("\n")
-		for _, warning := range warnings {
-			fmt.Println(warning)
-		}
-		fmt.Printf("\n")
+	if !endTime.After(startTime) {
+		return time.Time{}, time.Time{}, fmt.Errorf(testutil.ErrMsgEndDateAfterStart)
 	}
 
-	if err := ensureDirForFile(output); err != nil {
-		return err
+	return startTime, endTime, nil
+}
+
+func parseTimedEventTimes(startStr, endStr, durStr string) (startTime, endTime time.Time, err error) {
+	startTime, err = time.Parse("2006-01-02 15:04", startStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf(testutil.ErrMsgInvalidStartTimeFormat, err)
 	}
 
-	if err := os.WriteFile(output, []byte(cal.ToICS()), 0600); err != nil {
-		return fmt.Errorf("failed to write %s: %w", output, err)
+	switch {
+	case strings.TrimSpace(endStr) != "":
+		endTime, err = parseEndTime(startTime, endStr)
+	case strings.TrimSpace(durStr) != "":
+		endTime, err = parseDurationEnd(startTime, durStr)
+	default:
+		endTime = startTime.Add(1 * time.Hour)
 	}
 
-	printOK("Created: %s (%d events)\n", output, eventCount)
-	return nil
-}
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
 
-func newLintCmd() *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "lint",
-		Short: "Validate ICS files for common issues",
-		RunE:  runLint,
+	if !endTime.After(startTime) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end time must be after start time")
 	}
-	cmd.Flags().StringArray("file", []string{}, "ICS file(s) to lint (repeat flag for multiple files)")
-	return cmd
+
+	return startTime, endTime, nil
 }
 
-func runLint(cmd *cobra.Command, _ []string) error {
-	paths, _ := cmd.Flags().GetStringArray("file")
-	if len(paths) == 0 {
-		return fmt.Errorf("--file is required (repeat flag for multiple files)")
+func parseEndTime(startTime time.Time, endStr string) (time.Time, error) {
+	if d, derr := calendar.ParseHumanDuration(endStr); derr == nil {
+		if d <= 0 {
+			return time.Time{}, fmt.Errorf(testutil.ErrMsgDurationGreaterThanZero)
+		}
+		return startTime.Add(d), nil
 	}
 
-	var errs []string
-	for _, path := range paths {
-		path = strings.TrimSpace(path)
-		if path == "" {
-			continue
-		}
-		if err := lintICSFile(path); err != nil {
-			errs = append(errs, fmt.Sprintf("%s: %v", path, err))
-			continue
-		}
-		printOK("Lint passed: %s\n", path)
+	endTime, err := time.Parse("2006-01-02 15:04", endStr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid end time: %w", err)
 	}
+	return endTime, nil
+}
 
-	if len(errs) > 0 {
-		return fmt.Errorf("%s", strings.Join(errs, "\n"))
+func parseDurationEnd(startTime time.Time, durStr string) (time.Time, error) {
+	d, err := calendar.ParseHumanDuration(durStr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid duration: %v", err)
 	}
-	return nil
+	if d <= 0 {
+		return time.Time{}, fmt.Errorf(testutil.ErrMsgDurationGreaterThanZero)
+	}
+	return startTime.Add(d), nil
 }
 
-type batchFormat string
+func createCalendarWithEvent(opts *createOptions, startTime, endTime time.Time) (*calendar.Calendar, error) {
+	cal := calendar.NewCalendar()
+	cal.IncludeVTZ = true
+	cal.EmitDuration = opts.emitDuration
+	cal.Name = opts.summary
+	if tz := firstNonEmpty(opts.startTZ, opts.endTZ); strings.TrimSpace(tz) != "" {
+		cal.SetDefaultTimezone(tz)
+	}
 
-const (
-	batchFormatCSV  batchFormat = "csv"
-	batchFormatJSON batchFormat = "json"
-	batchFormatYAML batchFormat = "yaml"
-)
+	event := calendar.NewEvent(opts.summary, startTime, endTime)
+	configureEvent(event, opts)
+	cal.AddEvent(event)
+	if err := addOverrides(cal, event, opts.overrides); err != nil {
+		return nil, err
+	}
 
-type batchRecord struct {
+	return cal, nil
+}
+
+// overrideSpec is one changed occurrence of a recurring event, as written in
+// --override or a batch record's overrides. Empty fields keep the series'
+// values.
+type overrideSpec struct {
+	Occurrence  string // the start the series gives it
+	Start       string // its new start; a clock alone keeps the occurrence's day
+	End         string // its new end; empty keeps the series' length
 	Summary     string
-	Start       string
-	End         string
-	Duration    string
-	StartTZ     string
-	EndTZ       string
 	Location    string
 	Description string
-	AllDay      bool
-	RRule       string
-	ExDates     []string
-	Categories  []string
-	Alarms      []string
 }
 
-var icsDurationRegex = regexp.MustCompile(`(?i)^P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+// parseOverrideSpec reads "2025-12-23 14:00 => 2025-12-23 16:00": the
+// occurrence, then its new start.
+func parseOverrideSpec(spec string) (overrideSpec, error) {
+	occurrence, start, ok := strings.Cut(spec, "=>")
+	if !ok || strings.TrimSpace(occurrence) == "" || strings.TrimSpace(start) == "" {
+		return overrideSpec{}, fmt.Errorf("invalid override %q: want \"OCCURRENCE => NEW START\", e.g. \"2025-12-23 14:00 => 2025-12-23 16:00\"", spec)
+	}
+	return overrideSpec{Occurrence: strings.TrimSpace(occurrence), Start: strings.TrimSpace(start)}, nil
+}
 
-func detectBatchFormat(flag, path string) (batchFormat, error) {
-	switch strings.ToLower(strings.TrimSpace(flag)) {
-	case "auto", "":
-		ext := strings.ToLower(filepath.Ext(path))
-		switch ext {
-		case ".csv":
-			return batchFormatCSV, nil
-		case ".json":
-			return batchFormatJSON, nil
-		case ".yaml", ".yml":
-			return batchFormatYAML, nil
-		default:
-			return "", fmt.Errorf("cannot infer format from %s; use --format csv|json|yaml", path)
+// addOverrides adds an override VEVENT (RECURRENCE-ID) to cal for each spec
+// of the recurring event ev.
+func addOverrides(cal *calendar.Calendar, ev *calendar.Event, specs []overrideSpec) error {
+	for _, spec := range specs {
+		o, err := spec.resolve(ev.AllDay)
+		if err != nil {
+			return err
 		}
-	case "csv":
-		return batchFormatCSV, nil
-	case "json":
-		return batchFormatJSON, nil
-	case "yaml", "yml":
-		return batchFormatYAML, nil
-	default:
-		return "", fmt.Errorf("unsupported format %q (use csv, json, or yaml)", flag)
+		override, err := ev.Override(o)
+		if err != nil {
+			return err
+		}
+		cal.AddEvent(override)
 	}
+	return nil
 }
 
-func loadBatchRecords(path string, format batchFormat) ([]batchRecord, error) {
-	switch format {
-	case batchFormatCSV:
-		return loadBatchFromCSV(path)
-	case batchFormatJSON:
-		return loadBatchFromJSON(path)
-	case batchFormatYAML:
-		return loadBatchFromYAML(path)
-	default:
-		return nil, fmt.Errorf("unknown batch format %q", format)
+// resolve parses the spec's times, as YYYY-MM-DD HH:MM or YYYY-MM-DD for
+// all-day series, in the series' wall clock.
+func (o overrideSpec) resolve(allDay bool) (calendar.OccurrenceOverride, error) {
+	layout := constants.DateTimeFormatISO
+	if allDay {
+		layout = constants.DateFormatISO
+	}
+	out := calendar.OccurrenceOverride{Summary: o.Summary, Location: o.Location, Description: o.Description}
+	var err error
+	if out.Occurrence, err = time.Parse(layout, normalizeDateTimeInput(o.Occurrence)); err != nil {
+		return out, fmt.Errorf("invalid override occurrence %q: %w", o.Occurrence, err)
+	}
+	day := out.Occurrence.Format(constants.DateFormatISO)
+	start := normalizeDateTimeInput(o.Start)
+	if looksLikeClock(start) {
+		start = day + " " + start
+	}
+	if out.Start, err = time.Parse(layout, start); err != nil {
+		return out, fmt.Errorf("invalid override start %q: %w", o.Start, err)
+	}
+	if end := strings.TrimSpace(o.End); end != "" {
+		end, _ = overnightEnd(out.Start.Format(layout), normalizeDateTimeInput(end), false)
+		if out.End, err = time.Parse(layout, end); err != nil {
+			return out, fmt.Errorf("invalid override end %q: %w", o.End, err)
+		}
+		if allDay {
+			out.End = out.End.AddDate(0, 0, 1) // batch all-day ends are inclusive
+		}
 	}
+	return out, nil
 }
 
-func loadBatchFromCSV(path string) ([]batchRecord, error) {
-	f, err := os.Open(filepath.Clean(path))
+// createSolarCalendar builds the events for a start anchored to the sun, such
+// as sunrise+30m. An RRULE cannot follow the sun, so each day of --rrule (or
+// just the start day) becomes its own event at that day's computed time.
+// Days on which the sun never reaches the anchor are skipped with a warning.
+func createSolarCalendar(opts *createOptions, start calendar.SolarTime) (*calendar.Calendar, error) {
+	if opts.allDay {
+		return nil, fmt.Errorf("an all-day event cannot start at %s", start.Anchor)
+	}
+	if strings.TrimSpace(opts.geo) == "" {
+		return nil, fmt.Errorf("--location-geo LAT,LON is required to start at %s", start.Anchor)
+	}
+	lat, lon, err := calendar.ParseGeo(opts.geo)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
-
-	reader := csv.NewReader(f)
-	reader.TrimLeadingSpace = true
-	header, err := reader.Read()
-	if err == io.EOF {
-		return nil, nil
+	loc := time.Local
+	if opts.startTZ != "" {
+		if loc, err = time.LoadLocation(opts.startTZ); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", opts.startTZ, err)
+		}
 	}
+
+	end, endIsSolar, err := calendar.ParseSolarTime(opts.endStr)
 	if err != nil {
 		return nil, err
 	}
-
-	index := make(map[string]int, len(header))
-	for i, col := range header {
-		index[strings.ToLower(strings.TrimSpace(col))] = i
+	length := time.Hour
+	if !endIsSolar {
+		if value := firstNonEmpty(strings.TrimSpace(opts.endStr), strings.TrimSpace(opts.durStr)); value != "" {
+			if length, err = calendar.ParseHumanDuration(value); err != nil || length <= 0 {
+				return nil, fmt.Errorf("with a solar start, --end must be a duration or a solar time such as sunset, got %q", value)
+			}
+		}
 	}
 
-	var records []batchRecord
-	for {
-		row, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
+	first := appClock.Now().In(loc)
+	if start.Date != "" {
+		first, _ = time.ParseInLocation(constants.DateFormatISO, start.Date, loc)
+	}
+	first = time.Date(first.Year(), first.Month(), first.Day(), 12, 0, 0, 0, loc)
+	days := []time.Time{first}
+	if rrule := strings.TrimSpace(opts.rrule); rrule != "" {
+		rec, err := calendar.ParseRRule(rrule)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("--rrule with a solar start: %w", err)
 		}
-		if len(row) == 0 {
-			continue
+		if opts.rruleUntil != "" {
+			last, _ := calendar.ResolveUntil(opts.rruleUntil, first)
+			rec.Until = time.Date(last.Year(), last.Month(), last.Day(), 23, 59, 59, 0, loc)
 		}
-
-		rec := batchRecord{
-			Summary:     csvValue(row, index, "summary"),
-			Start:       csvValue(row, index, "start"),
-			End:         csvValue(row, index, "end"),
-			Duration:    csvValue(row, index, "duration"),
-			StartTZ:     csvValue(row, index, "start_tz"),
-			EndTZ:       csvValue(row, index, "end_tz"),
-			Location:    csvValue(row, index, "location"),
-			Description: csvValue(row, index, "description"),
-			RRule:       csvValue(row, index, "rrule"),
+		days = rec.Occurrences(first, 0)
+	}
+	for _, rd := range opts.rdates {
+		date, _ := splitDateTime(normalizeDateTimeInput(rd))
+		if day, err := time.ParseInLocation(constants.DateFormatISO, date, loc); err == nil {
+			days = append(days, time.Date(day.Year(), day.Month(), day.Day(), 12, 0, 0, 0, loc))
 		}
-		rec.AllDay = parseBoolish(csvValue(row, index, "all_day"))
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	skip := map[string]bool{}
+	for _, ex := range opts.exdates {
+		date, _ := splitDateTime(strings.TrimSpace(ex))
+		skip[date] = true
+	}
 
-		if ex := csvValue(row, index, "exdate"); ex != "" {
-			rec.ExDates = splitDelimited(ex)
+	cal := calendar.NewCalendar()
+	cal.IncludeVTZ = true
+	cal.EmitDuration = opts.emitDuration
+	cal.Name = opts.summary
+	if tz := firstNonEmpty(opts.startTZ, opts.endTZ); tz != "" {
+		cal.SetDefaultTimezone(tz)
+	}
+	single := *opts
+	single.rrule, single.exdates, single.rdates = "", nil, nil
+	for _, day := range days {
+		date := day.Format(constants.DateFormatISO)
+		if skip[date] {
+			continue
 		}
-		if cats := csvValue(row, index, "categories"); cats != "" {
-			rec.Categories = splitDelimited(cats)
+		skip[date] = true // an RDATE on a day the rule already has
+		startTime, err := start.On(day, lat, lon, loc)
+		endTime := startTime.Add(length)
+		if err == nil && endIsSolar {
+			endTime, err = end.On(day, lat, lon, loc)
+		}
+		if errors.Is(err, calendar.ErrNoSolarEvent) {
+			opts.warnings = append(opts.warnings, diag.Warning{
+				Code: diag.CodeSolar, Severity: diag.SeverityWarning,
+				Message: ui.T("solar_no_event", date, firstNonEmpty(start.Anchor, end.Anchor), opts.geo),
+			})
+			continue
 		}
-		if alarms := csvValue(row, index, "alarms"); alarms != "" {
-			rec.Alarms = calendar.SplitAlarmInput(alarms)
+		if err != nil {
+			return nil, err
+		}
+		if !endTime.After(startTime) {
+			return nil, fmt.Errorf("on %s the event would end (%s) before it starts (%s)", date, displayClock(endTime), displayClock(startTime))
 		}
 
-		records = append(records, rec)
+		event := calendar.NewEvent(opts.summary, startTime, endTime)
+		configureEvent(event, &single)
+		note := ui.T("solar_description", start.Anchor, displayClock(startTime.Add(-start.Offset)))
+		event.Description = strings.TrimSpace(event.Description + "\n" + note)
+		cal.AddEvent(event)
 	}
-
-	return records, nil
+	if len(cal.Events) == 0 {
+		return nil, fmt.Errorf("the sun does not reach %s at %s on any of the days", start.Anchor, opts.geo)
+	}
+	return cal, nil
 }
 
-func csvValue(row []string, index map[string]int, key string) string {
-	if pos, ok := index[key]; ok {
-		if pos < len(row) {
-			return strings.TrimSpace(row[pos])
-		}
+// checkRRuleUntil validates --rrule-until before any event exists: it needs
+// an --rrule without COUNT or UNTIL, and a value ResolveUntil understands.
+func checkRRuleUntil(rrule, until string) error {
+	if strings.TrimSpace(until) == "" {
+		return nil
 	}
-	return ""
-}
-
-func loadBatchFromJSON(path string) ([]batchRecord, error) {
-	data, err := os.ReadFile(filepath.Clean(path))
-	if err != nil {
-		return nil, err
-	}
-	if len(strings.TrimSpace(string(data))) == 0 {
-		return nil, nil
+	if strings.TrimSpace(rrule) == "" {
+		return fmt.Errorf("--rrule-until needs --rrule")
 	}
-
-	var raw []map[string]interface{}
-	if err := json.Unmarshal(data, &raw); err != nil {
-		return nil, err
+	if _, err := calendar.SetRRuleUntil(rrule, ""); err != nil {
+		return fmt.Errorf("--rrule-until: %w", err)
 	}
-
-	records := make([]batchRecord, 0, len(raw))
-	for _, item := range raw {
-		rec := batchRecord{
-			Summary:     valueAsString(item["summary"]),
-			Start:       valueAsString(item["start"]),
-			End:         valueAsString(item["end"]),
-			Duration:    valueAsString(item["duration"]),
-			StartTZ:     valueAsString(item["start_tz"]),
-			EndTZ:       valueAsString(item["end_tz"]),
-			Location:    valueAsString(item["location"]),
-			Description: valueAsString(item["description"]),
-			RRule:       valueAsString(item["rrule"]),
-			AllDay:      valueAsBool(item["all_day"]),
-			ExDates:     valueAsStringSlice(item["exdate"]),
-			Categories:  valueAsStringSlice(item["categories"]),
-			Alarms:      valueAsAlarmSlice(item["alarms"]),
-		}
-		records = append(records, rec)
+	if _, err := calendar.ResolveUntil(until, appClock.Now()); err != nil {
+		return fmt.Errorf("--rrule-until: %w", err)
 	}
-	return records, nil
+	return nil
 }
 
-func loadBatchFromYAML(path string) ([]batchRecord, error) {
-	data, err := os.ReadFile(filepath.Clean(path))
-	if err != nil {
-		return nil, err
+func configureEvent(event *calendar.Event, opts *createOptions) {
+	event.AllDay = opts.allDay
+	if opts.location != "" {
+		event.Location = opts.location
 	}
-	if len(strings.TrimSpace(string(data))) == 0 {
-		return nil, nil
+	if opts.description != "" {
+		event.Description = opts.description
 	}
 
-	var raw []map[string]interface{}
-	if err := yaml.Unmarshal(data, &raw); err != nil {
-		return nil, err
-	}
+	setEventTimezones(event, opts.startTZ, opts.endTZ)
 
-	records := make([]batchRecord, 0, len(raw))
-	for _, item := range raw {
-		rec := batchRecord{
-			Summary:     valueAsString(item["summary"]),
-			Start:       valueAsString(item["start"]),
-			End:         valueAsString(item["end"]),
-			Duration:    valueAsString(item["duration"]),
-			StartTZ:     valueAsString(item["start_tz"]),
-			EndTZ:       valueAsString(item["end_tz"]),
-			Location:    valueAsString(item["location"]),
-			Description: valueAsString(item["description"]),
-			RRule:       valueAsString(item["rrule"]),
-			AllDay:      valueAsBool(item["all_day"]),
-			ExDates:     valueAsStringSlice(item["exdate"]),
-			Categories:  valueAsStringSlice(item["categories"]),
-			Alarms:      valueAsAlarmSlice(item["alarms"]),
+	if strings.TrimSpace(opts.rrule) != "" {
+		event.RRule = strings.TrimSpace(opts.rrule)
+		if opts.rruleUntil != "" {
+			// checkRRuleUntil already accepted the value.
+			last, _ := calendar.ResolveUntil(opts.rruleUntil, event.StartTime)
+			event.RRule, _ = calendar.SetRRuleUntil(event.RRule, calendar.UntilValue(last, event.AllDay, event.StartTZ))
 		}
-		records = append(records, rec)
 	}
-	return records, nil
-}
 
-func buildEventFromBatch(rec batchRecord, fallbackTZ string) (*calendar.Event, error) {
-	summary, startStr, err := validateBatchRecord(rec)
-	if err != nil {
-		return nil, err
-	}
+	addEventExDates(event, opts.exdates, opts.startTZ, opts.allDay)
+	addEventRDates(event, opts.rdates, opts.startTZ, opts.allDay)
+	addEventAlarms(event, opts.alarms, opts.startTZ)
+	addEventCategories(event, opts.categories)
+	addEventAttendees(event, opts.attendees)
 
-	startTZ, endTZ := resolveBatchTimezones(rec, fallbackTZ)
-	startTime, endTime, err := parseBatchTimes(rec, startStr, startTZ, endTZ, summary)
-	if err != nil {
-		return nil, err
+	if opts.priority > 0 {
+		event.Priority = opts.priority
 	}
-
-	summaryWithEmoji := addEmojiToSummary(summary, rec.Categories)
-	event := calendar.NewEvent(summaryWithEmoji, startTime, endTime)
-	configureBatchEvent(event, rec, startTZ, endTZ)
-
-	return event, nil
-}
-
-func validateBatchRecord(rec batchRecord) (summary, startStr string, err error) {
-	summary = normalizeAndSpellCheck(strings.TrimSpace(rec.Summary))
-	if summary == "" {
-		return "", "", fmt.Errorf("summary is required")
+	if opts.status != "" {
+		event.Status = opts.status
 	}
+	event.Class = opts.class
+}
 
-	startStr = normalizeDateTimeInput(strings.TrimSpace(rec.Start))
-	if startStr == "" {
-		return "", "", fmt.Errorf("start is required")
+// parseEventStatus reads a STATUS value, case-insensitively; "" is allowed
+// and leaves the default CONFIRMED.
+func parseEventStatus(s string) (string, error) {
+	status := strings.ToUpper(strings.TrimSpace(s))
+	switch status {
+	case "", "TENTATIVE", "CONFIRMED", "CANCELLED":
+		return status, nil
 	}
-
-	return summary, startStr, nil
+	return "", fmt.Errorf("status %q must be tentative, confirmed or cancelled", strings.TrimSpace(s))
 }
 
-func resolveBatchTimezones(rec batchRecord, fallbackTZ string) (startTZ, endTZ string) {
-	startTZ = strings.TrimSpace(firstNonEmpty(rec.StartTZ, fallbackTZ))
-	endTZ = strings.TrimSpace(rec.EndTZ)
-	if endTZ == "" {
-		endTZ = startTZ
+// parseEventClass reads a CLASS value, case-insensitively; "" omits CLASS.
+func parseEventClass(s string) (string, error) {
+	class := strings.ToUpper(strings.TrimSpace(s))
+	switch class {
+	case "", "PUBLIC", "PRIVATE", "CONFIDENTIAL":
+		return class, nil
 	}
-	return startTZ, endTZ
+	return "", fmt.Errorf("class %q must be public, private or confidential", strings.TrimSpace(s))
 }
 
-func parseBatchTimes(rec batchRecord, startStr, startTZ, endTZ, summary string) (startTime, endTime time.Time, err error) {
-	if rec.AllDay {
-		return parseBatchAllDayTimes(startStr, rec.End)
+func setEventTimezones(event *calendar.Event, startTZ, endTZ string) {
+	if startTZ != "" {
+		event.SetStartTimezone(startTZ)
+	}
+	if endTZ != "" {
+		event.SetEndTimezone(endTZ)
+	} else if startTZ != "" {
+		event.SetEndTimezone(startTZ)
 	}
-	return parseBatchTimedEventTimes(rec, startStr, startTZ, endTZ, summary)
 }
 
-func parseBatchAllDayTimes(startStr, endStr string) (startTime, endTime time.Time, err error) {
-	startDateStr := extractDate(startStr)
-	startTime, err = time.Parse("2006-01-02", startDateStr)
-	if err != nil {
-		return time.Time{}, time.Time{}, fmt.Errorf("invalid start date %q: %w", startStr, err)
+func addEventExDates(event *calendar.Event, exdates []string, startTZ string, allDay bool) {
+	if len(exdates) == 0 {
+		return
 	}
 
-	if strings.TrimSpace(endStr) == "" {
-		endTime = startTime.AddDate(0, 0, 1)
-	} else {
-		endDateStr := extractDate(endStr)
-		endDate, parseErr := time.Parse("2006-01-02", endDateStr)
-		if parseErr != nil {
-			return time.Time{}, time.Time{}, fmt.Errorf("invalid end date %q: %w", endStr, parseErr)
-		}
-		if endDate.Before(startTime) {
-			return time.Time{}, time.Time{}, fmt.Errorf(testutil.ErrMsgEndDateAfterStart)
-		}
-		endTime = endDate.AddDate(0, 0, 1)
+	tzForExdate := strings.TrimSpace(event.StartTZ)
+	if tzForExdate == "" {
+		tzForExdate = strings.TrimSpace(startTZ)
 	}
 
-	return startTime, endTime, nil
+	parsed, err := parseExDateValues(exdates, tzForExdate, allDay)
+	if err == nil && len(parsed) > 0 {
+		event.ExDates = append(event.ExDates, parsed...)
+	}
 }
 
-func parseBatchTimedEventTimes(rec batchRecord, startStr, startTZ, endTZ, summary string) (startTime, endTime time.Time, err error) {
-	if looksLikeClock(startStr) {
-		startStr = prependToday(startStr, startTZ)
+// addEventRDates adds extra occurrences (RDATE), read like --exdate in the
+// event's start timezone.
+func addEventRDates(event *calendar.Event, rdates []string, startTZ string, allDay bool) {
+	if len(rdates) == 0 {
+		return
 	}
-	startTime, err = time.Parse("2006-01-02 15:04", startStr)
-	if err != nil {
-		return time.Time{}, time.Time{}, fmt.Errorf("invalid start time %q: %w", rec.Start, err)
+	parsed, err := parseExDateValues(rdates, firstNonEmpty(strings.TrimSpace(event.StartTZ), strings.TrimSpace(startTZ)), allDay)
+	if err == nil {
+		event.RDates = append(event.RDates, parsed...)
 	}
+}
 
-	endTime, err = parseBatchEndTime(rec, startTime, endTZ, summary)
-	if err != nil {
-		return time.Time{}, time.Time{}, err
+func addEventAlarms(event *calendar.Event, alarms []string, startTZ string) {
+	if len(alarms) == 0 {
+		return
 	}
 
-	if !endTime.After(startTime) {
-		return time.Time{}, time.Time{}, fmt.Errorf("end time must be after start time")
+	defaultAlarmTZ := strings.TrimSpace(event.StartTZ)
+	if defaultAlarmTZ == "" {
+		defaultAlarmTZ = strings.TrimSpace(startTZ)
 	}
 
-	return startTime, endTime, nil
+	parsed, err := calendar.ParseAlarmSpecs(alarms, defaultAlarmTZ)
+	if err == nil && len(parsed) > 0 {
+		event.Alarms = append(event.Alarms, parsed...)
+	}
 }
 
-func parseBatchEndTime(rec batchRecord, startTime time.Time, endTZ, summary string) (time.Time, error) {
-	endStr := strings.TrimSpace(rec.End)
-
-	switch {
-	case endStr != "":
-		return parseBatchExplicitEnd(endStr, startTime, endTZ, rec.End)
-	case strings.TrimSpace(rec.Duration) != "":
-		return parseBatchDurationEnd(rec.Duration, startTime)
-	default:
-		return startTime.Add(getSmartDefaultDuration(summary, startTime)), nil
+func addEventCategories(event *calendar.Event, categories []string) {
+	for _, cat := range categories {
+		if c := strings.TrimSpace(cat); c != "" {
+			event.AddCategory(c)
+		}
 	}
 }
 
-func parseBatchExplicitEnd(endStr string, startTime time.Time, endTZ, originalEnd string) (time.Time, error) {
-	if looksLikeClock(endStr) {
-		endStr = prependToday(endStr, endTZ)
+func addEventAttendees(event *calendar.Event, attendees []string) {
+	for _, attendee := range attendees {
+		if a := strings.TrimSpace(attendee); a != "" {
+			event.AddAttendee(a)
+		}
 	}
+}
 
-	if dur, derr := calendar.ParseHumanDuration(endStr); derr == nil {
-		if dur <= 0 {
-			return time.Time{}, fmt.Errorf(testutil.ErrMsgDurationGreaterThanZero)
-		}
-		return startTime.Add(dur), nil
+func writeCalendarOutput(cal *calendar.Calendar, output, format string, policy config.OutputPolicy) error {
+	if output == "" {
+		return writeCalendar(os.Stdout, cal, format)
 	}
 
-	endTime, err := time.Parse("2006-01-02 15:04", endStr)
+	err := writeGeneratedStream(output, policy, func(w io.Writer) error {
+		return writeCalendar(w, cal, format)
+	})
 	if err != nil {
-		return time.Time{}, fmt.Errorf("invalid end time %q: %w", originalEnd, err)
+		printErr(constants.ErrMsgFailedToWriteFile, err)
+		return err
 	}
-	return endTime, nil
+	printOK(constants.MsgCreatedFile, output)
+	return nil
 }
 
-func parseBatchDurationEnd(durStr string, startTime time.Time) (time.Time, error) {
-	dur, err := calendar.ParseHumanDuration(durStr)
-	if err != nil {
-		return time.Time{}, fmt.Errorf("invalid duration %q: %v", durStr, err)
-	}
-	if dur <= 0 {
-		return time.Time{}, fmt.Errorf(testutil.ErrMsgDurationGreaterThanZero)
+func newBatchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "batch",
+		Short: "Create multiple ICS events from CSV, JSON, or YAML",
+		RunE:  runBatch,
 	}
-	return startTime.Add(dur), nil
-}
 
-func configureBatchEvent(event *calendar.Event, rec batchRecord, startTZ, endTZ string) {
-	event.AllDay = rec.AllDay
+	cmd.Flags().StringP("input", "i", "", "Input file path (CSV, JSON, YAML, or TOML)")
+	cmd.Flags().StringP("output", "o", "batch.ics", "Output ICS file path")
+	cmd.Flags().String("format", "auto", "Input format: auto, csv, json, yaml, toml, timetable, gcal-csv or outlook-csv")
+	cmd.Flags().StringArray("map", []string{}, "Read a CSV column from other headers, e.g. \"summary=Subject,start=Start Date+Start Time\" (+ joins columns with a space; repeatable; column_aliases in config adds defaults)")
+	cmd.Flags().String("output-format", "auto", "Output format: auto (from --output), ics, jcal, xcal, or json for a JSON report (like --json)")
+	cmd.Flags().String("name", "", "Calendar name (NAME and X-WR-CALNAME)")
+	cmd.Flags().String("calendar-description", "", "Calendar description (DESCRIPTION and X-WR-CALDESC)")
+	cmd.Flags().String("calendar-uid", "", "Persistent calendar UID (RFC 7986)")
+	cmd.Flags().String("calendar-url", "", "Link to a page about the calendar (URL)")
+	cmd.Flags().Duration("refresh-interval", 0, "How often subscribers should refresh, e.g. 1h (REFRESH-INTERVAL and X-PUBLISHED-TTL)")
+	cmd.Flags().String("source-url", "", "Where the calendar is published for subscribers (SOURCE)")
+	cmd.Flags().String("default-tz", "", "Default timezone for rows without start_tz")
+	cmd.Flags().Bool("emit-duration", false, "Write DURATION instead of DTEND")
+	cmd.Flags().Bool("dry-run", false, "Validate batch file without creating output")
+	cmd.Flags().Bool("check-conflicts", false, "Detect and warn about overlapping events")
+	cmd.Flags().Int("max-events-per-day", 0, "Warn if any day exceeds this number of events (0=unlimited)")
+	cmd.Flags().Bool("auto-balance", false, "Move the lowest-priority events off days over --max-events-per-day (default 8) to the nearest day with room; --dry-run only proposes the moves")
+	cmd.Flags().Bool("yes", false, "Apply --auto-balance moves without asking")
+	cmd.Flags().Int("energy-budget", 0, "Warn if a day's energy (energy column, 1-5 per event) exceeds this total (default: energy_budget from config; 0=off)")
+	cmd.Flags().Bool("add-prep-time", false, "Auto-add preparation/transition time buffers (ADHD time boxing)")
+	cmd.Flags().StringArray("prep-before", []string{}, "Buffer before events with this category or summary keyword, e.g. Meeting=15m (repeatable, implies --add-prep-time, 0 turns a rule off)")
+	cmd.Flags().StringArray("transition-after", []string{}, "Buffer after events with this category or summary keyword, e.g. Focus=10m (repeatable, implies --add-prep-time, 0 turns a rule off)")
+	cmd.Flags().String("travel-time", "", "Travel time between events at different locations not listed in travel_times, e.g. 20m (default: default_travel_time from config; 0=off)")
+	cmd.Flags().Bool("add-travel-time", false, "Insert travel buffers before events at a different location than the previous one (gap-aware, like --add-prep-time)")
+	cmd.Flags().Bool("transparent-buffers", false, "Mark prep/transition buffers TRANSP:TRANSPARENT so they don't block free/busy time (default: transparent_buffers from config)")
+	cmd.Flags().Bool("time-annotations", false, "Append how long each event lasts and the gap since the previous one to descriptions (time-blindness aid)")
+	cmd.Flags().String("jitter", "", "Expand recurring events and shift each occurrence randomly within ±window (e.g. 15m) to prevent alarm fatigue")
+	cmd.Flags().Int64("jitter-seed", 0, "Random seed for --jitter (0 = different every run)")
+	cmd.Flags().Bool("json", false, "Print the run summary as JSON instead of text")
+	cmd.Flags().Bool("strict", false, "Fail instead of warning when events or alarms break working/quiet hours")
+	addDayFilterFlags(cmd)
+	cmd.Flags().Bool("strict-input", false, "Take rows literally: no spell-check, emoji, category canonicalization, clock-only dates or smart durations")
+	cmd.Flags().Bool("no-spell-correct", false, "Keep summaries as written (skip the spell_corrections dictionary) for this run")
+	cmd.Flags().Bool("show-corrections", false, "List every word the spell checker changed (original → corrected) and the rows it changed them in")
+	cmd.Flags().Bool("stable-uids", false, "Derive UIDs from summary+start+timezone so re-imports update events instead of duplicating them (a uid column always wins)")
+	cmd.Flags().Bool("watch", false, "Keep running and regenerate the output whenever the input file changes (Ctrl+C to stop)")
+	cmd.Flags().Bool("no-fix", false, "Don't offer to fix invalid rows interactively (prompts only appear in a terminal)")
+	cmd.Flags().Bool("skip-invalid", false, "Skip rows that fail validation, write the rest and exit non-zero listing the skipped rows")
+	cmd.Flags().Int("max-errors", 0, "With --skip-invalid, give up without writing once more than this many rows fail (implies --skip-invalid; 0=no limit)")
+	cmd.Flags().String("sarif", "", "Also write warnings and row errors as a SARIF log to this file (for CI)")
+	cmd.Flags().Bool("translate-categories", false, "Write category names in the output language (--language or config), e.g. Work → Trabajo")
+	cmd.Flags().Bool("append", false, "Add the events to an existing --output calendar instead of replacing it (UID collisions fail, overlaps warn)")
+	cmd.Flags().Bool("embed-source", false, "Embed the input file, flags and tempus version in the calendar (X-TEMPUS-SOURCE-BUNDLE); recover it with 'tempus batch source'")
+	cmd.Flags().Bool("per-attendee", false, "Also write one calendar per person in --attendee-column with only their events (mail-merge)")
+	cmd.Flags().String("attendee-column", "attendees", "Column --per-attendee splits on: attendees or organizer")
+	cmd.Flags().String("attendee-file", "", "File name for --per-attendee calendars, next to --output, e.g. {{attendee}}-{{month}}.ics ({{attendee}}, {{email}}, {{month}}, {{year}}; default: <output>-{{attendee}}.ics)")
+
+	cmd.AddCommand(newBatchTemplateCmd(), newBatchSourceCmd())
 
-	if startTZ != "" {
-		event.SetStartTimezone(startTZ)
-	}
-	if endTZ != "" {
-		event.SetEndTimezone(endTZ)
-	} else if startTZ != "" {
-		event.SetEndTimezone(startTZ)
-	}
+	return cmd
+}
 
-	event.Location = strings.TrimSpace(rec.Location)
-	event.Description = strings.TrimSpace(rec.Description)
+func runBatch(cmd *cobra.Command, _ []string) error {
+	opts, err := parseBatchFlags(cmd)
+	if err != nil {
+		return err
+	}
 
-	if strings.TrimSpace(rec.RRule) != "" {
-		event.RRule = strings.TrimSpace(rec.RRule)
+	if watch, _ := cmd.Flags().GetBool("watch"); watch {
+		if opts.appendOutput {
+			return fmt.Errorf("--append cannot be combined with --watch (every save would append the events again)")
+		}
+		// Nobody should be asked to fix a row while the file is being edited.
+		opts.fixInteractive = false
+		ctx, stop := signal.NotifyContext(commandContext(cmd), os.Interrupt)
+		defer stop()
+		return watchBatch(ctx, opts)
 	}
 
-	addBatchCategories(event, rec.Categories)
-	addBatchExDates(event, rec.ExDates, startTZ, rec.AllDay)
-	addBatchAlarms(event, rec.Alarms, startTZ)
+	_, err = runBatchOnce(opts)
+	return err
 }
 
-func addBatchCategories(event *calendar.Event, categories []string) {
-	for _, cat := range categories {
-		cat = strings.TrimSpace(cat)
-		if cat != "" {
-			validated := validateCategoryWithSuggestion(cat)
-			event.AddCategory(validated)
+// runBatchOnce loads, validates and writes one batch run. It returns every
+// diagnostic it reported (row errors included) so watch mode can diff them.
+func runBatchOnce(opts *batchOptions) ([]diag.Warning, error) {
+	// The input's calendar: block fills in options for this run only, so
+	// watch mode follows edits to it.
+	run := *opts
+	opts = &run
+	records, format, err := loadBatchInput(opts)
+	if err != nil {
+		return nil, err
+	}
+	output.Detail(os.Stdout, "Loaded %d row(s) from %s (%s)\n", len(records), opts.input, format)
+	if opts.embedSource {
+		if opts.sourceBundle, err = encodeSourceBundle(opts, format); err != nil {
+			return nil, err
 		}
 	}
-}
-
-func addBatchExDates(event *calendar.Event, exdates []string, startTZ string, allDay bool) {
-	if len(exdates) == 0 {
-		return
+	people := batchPeople(records)
+	if len(people) > 0 {
+		records = withPeopleNotes(records, people)
 	}
-
-	tzForEx := event.StartTZ
-	if tzForEx == "" {
-		tzForEx = startTZ
+	if opts.perAttendee && !opts.stableUIDs {
+		// Every attendee's copy of a meeting must carry the same UID.
+		for i := range records {
+			if strings.TrimSpace(records[i].UID) == "" {
+				records[i].UID = generateUID()
+			}
+		}
 	}
 
-	parsed, err := parseExDateValues(exdates, tzForEx, allDay)
-	if err == nil {
-		event.ExDates = append(event.ExDates, parsed...)
+	cal, validationErrors, err := buildBatchCalendar(records, opts)
+	if err != nil {
+		return nil, err
 	}
-}
 
-func addBatchAlarms(event *calendar.Event, alarms []string, startTZ string) {
-	if len(alarms) == 0 {
-		return
+	dayNotes := applyDayFilter(cal.Events, opts.days)
+	balanceNotes, err := autoBalance(cal.Events, opts)
+	if err != nil {
+		return validationErrors, err
 	}
 
-	defaultAlarmTZ := event.StartTZ
-	if defaultAlarmTZ == "" {
-		defaultAlarmTZ = startTZ
+	if opts.strict {
+		if violations := detectHoursViolations(cal.Events, opts.hours); len(violations) > 0 {
+			return nil, hoursStrictError(violations)
+		}
 	}
 
-	expandedAlarms := expandAlarmProfiles(alarms)
-	parsed, err := calendar.ParseAlarmSpecs(expandedAlarms, defaultAlarmTZ)
-	if err == nil {
-		event.Alarms = append(event.Alarms, parsed...)
+	warnings := collectAutocorrections(records, opts)
+	warnings = append(warnings, collectTimezoneAliases(records, opts)...)
+	warnings = append(warnings, collectUnknownCategories(records, opts)...)
+	warnings = append(warnings, collectOvernightEnds(records, opts)...)
+	warnings = append(warnings, collectBatchWarnings(cal.Events, opts)...)
+	warnings = append(warnings, opts.bufferWarnings...)
+	warnings = append(warnings, opts.jitterWarnings...)
+	warnings = append(warnings, opts.travelWarnings...)
+	warnings = append(warnings, collectSanityWarnings(cal.Events, opts)...)
+	warnings = append(warnings, dayFilterWarnings(dayNotes)...)
+	warnings = append(warnings, balanceNotes...)
+	if opts.appendOutput {
+		overlaps, err := checkBatchAppend(cal.Events, opts.output)
+		if err != nil {
+			return append(validationErrors, warnings...), err
+		}
+		warnings = append(warnings, overlaps...)
 	}
-}
+	all := append(validationErrors, warnings...)
 
-// normalizeAndSpellCheck fixes common spelling errors and normalizes text in summaries.
-// Helps users with dyslexia or typing errors.
-// Uses the spell_corrections dictionary from config (customizable via config.yaml).
-func normalizeAndSpellCheck(text string) string {
-	if text == "" {
-		return text
+	if opts.sarifPath != "" {
+		if err := writeSARIFFile(opts.sarifPath, all); err != nil {
+			return all, err
+		}
 	}
 
-	// Get corrections from config (falls back to built-in defaults)
-	cfg, _ := config.Load()
-	corrections := make(map[string]string)
-	if cfg != nil && cfg.SpellCorrections != nil {
-		corrections = cfg.SpellCorrections
+	if opts.showCorrections && !opts.jsonOutput {
+		printSpellingReport(records, opts.corrections)
 	}
-
-	words := strings.Fields(text)
-	for i, word := range words {
-		lower := strings.ToLower(word)
-		if corrected, exists := corrections[lower]; exists {
-			// Preserve original capitalization
-			if len(word) > 0 && word[0] >= 'A' && word[0] <= 'Z' {
-				words[i] = strings.Title(corrected)
-			} else {
-				words[i] = corrected
+	var attendeeCals []personCalendar
+	if opts.perAttendee {
+		if attendeeCals, err = buildAttendeeCalendars(records, opts); err != nil {
+			return all, err
+		}
+	}
+	if opts.dryRun && opts.jsonOutput {
+		return all, writeDryRunJSON(cal, validationErrors, warnings, opts, people, attendeeCals)
+	}
+	if opts.dryRun {
+		if err := handleDryRun(validationErrors, warnings, records, cal.Events, opts); err != nil {
+			return all, err
+		}
+		if len(people) > 0 {
+			output.Info(os.Stdout, "👥", "%s\n", ui.T("batch_people_preview", strings.Join(people, ", ")))
+		}
+		if len(attendeeCals) > 0 {
+			paths := make([]string, len(attendeeCals))
+			for i, ac := range attendeeCals {
+				paths[i] = ac.path
 			}
+			output.Info(os.Stdout, "📨", "%s\n", ui.T("batch_attendees_preview", len(paths), strings.Join(paths, ", ")))
 		}
+		return all, nil
 	}
 
-	return strings.Join(words, " ")
-}
-
-// normalizeDateTimeInput accepts various date/time formats and normalizes to standard format.
-// Handles common variations like slashes, different separators, etc.
-func normalizeDateTimeInput(input string) string {
-	if input == "" {
-		return input
-	}
-
-	input = strings.TrimSpace(input)
-
-	// Replace common separators
-	// 2025/12/16 -> 2025-12-16
-	input = strings.ReplaceAll(input, "/", "-")
-
-	// Handle missing leading zeros: 2025-1-5 -> 2025-01-05
-	parts := strings.Split(input, " ")
-	if len(parts) >= 1 {
-		datePart := parts[0]
-		dateComponents := strings.Split(datePart, "-")
-		if len(dateComponents) == 3 {
-			// Pad single digits
-			for i, comp := range dateComponents {
-				if len(comp) == 1 && i > 0 { // Don't pad year
-					dateComponents[i] = "0" + comp
-				}
+	personCals, err := buildPeopleCalendars(records, opts)
+	if err != nil {
+		return all, err
+	}
+	personCals = append(personCals, attendeeCals...)
+	if opts.appendOutput {
+		// Check every file before writing any, so a collision leaves them all untouched.
+		for _, pc := range personCals {
+			if _, err := checkBatchAppend(pc.cal.Events, pc.path); err != nil {
+				return all, err
 			}
-			parts[0] = strings.Join(dateComponents, "-")
 		}
 	}
-
-	// Handle time part if present
-	if len(parts) >= 2 {
-		timePart := parts[1]
-		// Handle 24h format without colon: 0900 -> 09:00
-		if len(timePart) == 4 && !strings.Contains(timePart, ":") {
-			parts[1] = timePart[:2] + ":" + timePart[2:]
+	if opts.jsonOutput {
+		if err := writeBatchOutputJSON(cal, validationErrors, warnings, opts, personCals); err != nil {
+			return all, err
 		}
-		// Pad single digit hours: 9:00 -> 09:00
-		timeComponents := strings.Split(parts[1], ":")
-		if len(timeComponents) == 2 && len(timeComponents[0]) == 1 {
-			parts[1] = "0" + parts[1]
+		return all, skippedRowsError(validationErrors, len(records))
+	}
+	if err := writeBatchOutput(cal, warnings, opts, len(records)-len(validationErrors)); err != nil {
+		return all, err
+	}
+	summary := summarizeBatch(cal, opts.output)
+	for _, pc := range personCals {
+		if err := writeBatchICS(pc.cal, pc.path, opts); err != nil {
+			return all, err
 		}
+		printOK("%s\n", ui.T("batch_person_written", pc.person, pc.path, len(pc.cal.Events)))
+		summary.Calendars = append(summary.Calendars, pc.path)
 	}
-
-	return strings.Join(parts, " ")
-}
-
-// validateCategoryWithSuggestion checks for common typos in category names and auto-corrects them.
-// This helps neurodivergent users who may struggle with spelling or consistency.
-func validateCategoryWithSuggestion(category string) string {
-	commonCategories := map[string]string{
-		"work":          "Work",
-		"meeting":       "Meeting",
-		"health":        "Health",
-		"medication":    "Medication",
-		"meds":          "Medication",
-		"medical":       "Medical",
-		"therapy":       "Therapy",
-		"mental health": "Mental Health",
-		"exercise":      "Exercise",
-		"workout":       "Workout",
-		"food":          "Food",
-		"meal":          "Meal",
-		"travel":        "Travel",
-		"flight":        "Flight",
-		"hotel":         "Accommodation",
-		"accommodation": "Accommodation",
-		"family":        "Family",
-		"kids":          "Kids",
-		"personal":      "Personal",
-		"focus":         "Focus",
-		"deep work":     "Focus",
-		"break":         "Break",
-		"rest":          "Rest",
-		"transition":    "Transition",
-		"urgent":        "Urgent",
-		"important":     "Important",
-		"fun":           "Fun",
-		"leisure":       "Leisure",
-		"learning":      "Learning",
-		"education":     "Education",
-		"sleep":         "Sleep",
+	printBatchSummary(summary)
+	if len(validationErrors) > 0 {
+		fmt.Fprintln(os.Stderr)
+		diag.Render(os.Stderr, validationErrors)
 	}
+	return all, skippedRowsError(validationErrors, len(records))
+}
 
-	lower := strings.ToLower(category)
-
-	// Exact match (case-insensitive)
-	if corrected, exists := commonCategories[lower]; exists {
-		return corrected
+// skippedRowsError is the error a --skip-invalid run exits with when it left
+// rows out, or nil when every row was written.
+func skippedRowsError(skipped []diag.Warning, rows int) error {
+	if len(skipped) == 0 {
+		return nil
 	}
+	return fmt.Errorf("skipped %d invalid row(s) of %d; the other events were written", len(skipped), rows)
+}
 
-	// Check for close matches using Levenshtein distance
-	bestMatch := category
-	bestDistance := 999
-	threshold := 2 // Allow up to 2 character differences
+// batchWatchDebounce coalesces the burst of events editors and spreadsheet
+// exports produce for a single save.
+var batchWatchDebounce = 200 * time.Millisecond
 
-	for known, canonical := range commonCategories {
-		dist := levenshteinDistance(lower, known)
-		if dist <= threshold && dist < bestDistance {
-			bestDistance = dist
-			bestMatch = canonical
-		}
+func commandContext(cmd *cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
 	}
-
-	return bestMatch
+	return context.Background()
 }
 
-// levenshteinDistance calculates the edit distance between two strings.
-// Used for typo detection and correction suggestions.
-func levenshteinDistance(s1, s2 string) int {
-	if len(s1) == 0 {
-		return len(s2)
-	}
-	if len(s2) == 0 {
-		return len(s1)
+// watchBatch runs the batch once and again after every change to the input
+// file until ctx is cancelled. Failed runs are reported and watching continues.
+func watchBatch(ctx context.Context, opts *batchOptions) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("cannot watch %s: %w", opts.input, err)
 	}
+	defer watcher.Close()
 
-	// Create matrix
-	matrix := make([][]int, len(s1)+1)
-	for i := range matrix {
-		matrix[i] = make([]int, len(s2)+1)
-		matrix[i][0] = i
+	// Watch the directory: editors often save by writing a new file and renaming
+	// it over the old one, which drops a watch on the file itself.
+	target, err := filepath.Abs(opts.input)
+	if err != nil {
+		return err
 	}
-	for j := range matrix[0] {
-		matrix[0][j] = j
+	if err := watcher.Add(filepath.Dir(target)); err != nil {
+		return fmt.Errorf("cannot watch %s: %w", opts.input, err)
 	}
 
-	// Fill matrix
-	for i := 1; i <= len(s1); i++ {
-		for j := 1; j <= len(s2); j++ {
-			cost := 0
-			if s1[i-1] != s2[j-1] {
-				cost = 1
+	previous := runBatchWatchIteration(opts, nil)
+	output.Info(os.Stdout, "👀", "\nWatching %s for changes (Ctrl+C to stop)\n", opts.input)
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(ev.Name) == target && ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+				debounce = time.After(batchWatchDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
 			}
-			matrix[i][j] = min(
-				matrix[i-1][j]+1,      // deletion
-				matrix[i][j-1]+1,      // insertion
-				matrix[i-1][j-1]+cost, // substitution
-			)
+			printErr("watch error: %v\n", err)
+		case <-debounce:
+			debounce = nil
+			output.Info(os.Stdout, "🔄", "\n%s changed, regenerating (%s)\n", opts.input, appClock.Now().Format("15:04:05"))
+			previous = runBatchWatchIteration(opts, previous)
 		}
 	}
-
-	return matrix[len(s1)][len(s2)]
 }
 
-func min(a, b, c int) int {
-	if a < b {
-		if a < c {
-			return a
+// runBatchWatchIteration runs the batch and prints how its diagnostics differ
+// from the previous run. It returns the diagnostics to compare against next time.
+func runBatchWatchIteration(opts *batchOptions, previous []diag.Warning) []diag.Warning {
+	current, err := runBatchOnce(opts)
+	if err != nil {
+		printErr("%v\n", err)
+		// Keep the last good baseline so the next successful run diffs against it.
+		if current == nil {
+			return previous
 		}
-		return c
 	}
-	if b < c {
-		return b
+	if previous != nil {
+		printDiagnosticsDelta(previous, current)
 	}
-	return c
+	return current
 }
 
-// addEmojiToSummary adds a relevant emoji prefix to the summary based on categories.
-// Only adds emoji if the summary doesn't already start with one.
-// This provides visual cues that help neurodivergent users quickly scan their calendar.
-func addEmojiToSummary(summary string, categories []string) string {
-	// Skip if summary already starts with an emoji (rough check for non-ASCII)
-	if len(summary) > 0 && summary[0] > 127 {
-		return summary
-	}
-
-	// Map categories to emojis
-	categoryLower := make([]string, len(categories))
-	for i, cat := range categories {
-		categoryLower[i] = strings.ToLower(strings.TrimSpace(cat))
+// printDiagnosticsDelta reports diagnostics that appeared or went away.
+func printDiagnosticsDelta(previous, current []diag.Warning) {
+	seen := make(map[string]bool, len(previous))
+	for _, w := range previous {
+		seen[w.String()] = true
 	}
-
-	// Priority order: most specific first
-	for _, cat := range categoryLower {
-		switch cat {
-		case "medication", "meds":
-			return "💊 " + summary
-		case "health", "medical":
-			return "🏥 " + summary
-		case "therapy", "mental health":
-			return "🧠 " + summary
-		case "exercise", "workout", "fitness":
-			return "💪 " + summary
-		case "food", "meal", "restaurant":
-			return "🍽️ " + summary
-		case "travel", "flight":
-			return "✈️ " + summary
-		case "accommodation", "hotel":
-			return "🏨 " + summary
-		case "work", "meeting":
-			return "💼 " + summary
-		case "focus", "deep work":
-			return "🎯 " + summary
-		case "break", "rest":
-			return "☕ " + summary
-		case "transition":
-			return "🔄 " + summary
-		case "family", "kids":
-			return "👨‍👩‍👧 " + summary
-		case "personal":
-			return "🌟 " + summary
-		case "urgent", "important":
-			return "🔥 " + summary
-		case "fun", "leisure":
-			return "🎉 " + summary
-		case "learning", "education":
-			return "📚 " + summary
-		case "sleep":
-			return "😴 " + summary
+	var added []diag.Warning
+	still := make(map[string]bool, len(current))
+	for _, w := range current {
+		still[w.String()] = true
+		if !seen[w.String()] {
+			added = append(added, w)
 		}
 	}
-
-	// Check summary keywords if no category match
-	summaryLower := strings.ToLower(summary)
-	if strings.Contains(summaryLower, "med") || strings.Contains(summaryLower, "pill") {
-		return "💊 " + summary
-	}
-	if strings.Contains(summaryLower, "breakfast") || strings.Contains(summaryLower, "lunch") || strings.Contains(summaryLower, "dinner") {
-		return "🍽️ " + summary
-	}
-	if strings.Contains(summaryLower, "doctor") || strings.Contains(summaryLower, "dentist") || strings.Contains(summaryLower, "appointment") {
-		return "🏥 " + summary
+	resolved := 0
+	for _, w := range previous {
+		if !still[w.String()] {
+			resolved++
+			output.Info(os.Stdout, "✓", "resolved: %s\n", w)
+		}
 	}
-	if strings.Contains(summaryLower, "meeting") {
-		return "💼 " + summary
+	if len(added) == 0 && resolved == 0 {
+		fmt.Println("Diagnostics unchanged since last run.")
+		return
 	}
-	if strings.Contains(summaryLower, "focus") {
-		return "🎯 " + summary
+	if len(added) > 0 {
+		fmt.Println("New since last run:")
+		diag.Render(os.Stdout, added)
+	}
+	fmt.Printf("Diagnostics: %d new, %d resolved, %d total\n", len(added), resolved, len(current))
+}
+
+// batchFieldError is a row error caused by a single field, so interactive runs
+// can offer to re-enter just that field.
+type batchFieldError struct {
+	field string
+	err   error
+}
+
+func (e *batchFieldError) Error() string { return e.err.Error() }
+func (e *batchFieldError) Unwrap() error { return e.err }
+
+func fieldError(field string, err error) error {
+	return &batchFieldError{field: field, err: err}
+}
+
+// batchFix records a value entered at a fix-it prompt.
+type batchFix struct {
+	row      int
+	field    string
+	oldValue string
+	newValue string
+}
+
+// field returns the raw value of a batch column by its CSV header name.
+func (r *batchRecord) field(name string) string {
+	switch name {
+	case "summary":
+		return r.Summary
+	case "start":
+		return r.Start
+	case "end":
+		return r.End
+	case "duration":
+		return r.Duration
+	case "start_tz":
+		return r.StartTZ
+	case "end_tz":
+		return r.EndTZ
+	case "uid":
+		return r.UID
+	case "priority":
+		return r.Priority
+	case "energy":
+		return r.Energy
+	case "organizer":
+		return r.Organizer
+	case "status":
+		return r.Status
+	case "url":
+		return r.URL
+	case "class":
+		return r.Class
 	}
-
-	return summary
+	return ""
 }
 
-// getSmartDefaultDuration returns a reasonable duration based on event summary and time of day.
-// This helps neurodivergent users by reducing cognitive load - they don't need to specify duration for common events.
-func getSmartDefaultDuration(summary string, startTime time.Time) time.Duration {
-	summaryLower := strings.ToLower(summary)
-	hour := startTime.Hour()
-
-	// Medication/pills: very short
-	if strings.Contains(summaryLower, "med") || strings.Contains(summaryLower, "pill") {
-		return 5 * time.Minute
+func (r *batchRecord) setField(name, value string) {
+	switch name {
+	case "summary":
+		r.Summary = value
+	case "start":
+		r.Start = value
+	case "end":
+		r.End = value
+	case "duration":
+		r.Duration = value
+	case "start_tz":
+		r.StartTZ = value
+	case "end_tz":
+		r.EndTZ = value
+	case "uid":
+		r.UID = value
+	case "priority":
+		r.Priority = value
+	case "energy":
+		r.Energy = value
+	case "organizer":
+		r.Organizer = value
+	case "status":
+		r.Status = value
+	case "url":
+		r.URL = value
+	case "class":
+		r.Class = value
+	}
+}
+
+// isInteractiveTerminal reports whether stdin and stdout are both terminals,
+// i.e. someone is there to answer prompts.
+func isInteractiveTerminal() bool {
+	for _, f := range []*os.File{os.Stdin, os.Stdout} {
+		info, err := f.Stat()
+		if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return false
+		}
 	}
+	return true
+}
 
-	// Meals: depends on time of day
-	if strings.Contains(summaryLower, "breakfast") {
-		return 30 * time.Minute
-	}
-	if strings.Contains(summaryLower, "lunch") {
-		return 45 * time.Minute
-	}
-	if strings.Contains(summaryLower, "dinner") || strings.Contains(summaryLower, "supper") {
-		return 1 * time.Hour
+// promptBatchFix offers to re-enter the field behind a row error. It returns
+// false when the error is not tied to a field or the user gives up (empty input).
+func promptBatchFix(row int, rec *batchRecord, err error, fallbackTZ string) (batchFix, bool) {
+	var fe *batchFieldError
+	if !errors.As(err, &fe) {
+		return batchFix{}, false
 	}
+	old := rec.field(fe.field)
+	output.Error(os.Stdout, "\nRow %d (%s): %v\n", row, firstNonEmpty(strings.TrimSpace(rec.Summary), "no summary"), err)
 
-	// Quick tasks
-	if strings.Contains(summaryLower, "standup") || strings.Contains(summaryLower, "stand-up") {
-		return 15 * time.Minute
-	}
-	if strings.Contains(summaryLower, "break") || strings.Contains(summaryLower, "transition") {
-		return 15 * time.Minute
+	var value string
+	switch fe.field {
+	case "start_tz", "end_tz":
+		value = promptBatchTimezone(firstNonEmpty(old, fallbackTZ))
+	default:
+		value = prompts.Input(fmt.Sprintf("New %s%s (empty to skip)", fe.field, batchFieldHint(fe.field, rec.AllDay)), "")
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return batchFix{}, false
+	}
+	rec.setField(fe.field, value)
+	return batchFix{row: row, field: fe.field, oldValue: old, newValue: value}, true
+}
+
+func batchFieldHint(field string, allDay bool) string {
+	switch field {
+	case "start", "end":
+		if allDay {
+			return " (YYYY-MM-DD)"
+		}
+		return " (YYYY-MM-DD HH:MM)"
+	case "duration":
+		return " (e.g. 30m, 1h30m)"
+	case "priority":
+		return " (1 highest to 9 lowest)"
+	case "energy":
+		return " (1 light to 5 draining)"
+	case "organizer":
+		return " (email)"
+	case "status":
+		return " (tentative, confirmed or cancelled)"
+	case "url":
+		return " (https://…)"
+	case "class":
+		return " (public, private or confidential)"
 	}
+	return ""
+}
 
-	// Therapy/medical
-	if strings.Contains(summaryLower, "therapy") || strings.Contains(summaryLower, "therapist") {
-		return 1 * time.Hour
+// promptBatchTimezone lets the user pick one of the timezones matching the
+// invalid value, or type another one.
+func promptBatchTimezone(invalid string) string {
+	const other = "Enter another timezone"
+	var options []string
+	tm := tzpkg.NewTimezoneManager()
+	suggestions := tm.Search(invalid, 8)
+	if len(suggestions) == 0 {
+		// "Europe/Madird": the city part alone is more likely to match.
+		if idx := strings.LastIndexAny(invalid, "/_ "); idx >= 0 {
+			suggestions = tm.Search(invalid[idx+1:], 8)
+		}
 	}
-	if strings.Contains(summaryLower, "doctor") || strings.Contains(summaryLower, "dentist") {
-		return 30 * time.Minute
+	for _, r := range suggestions {
+		options = append(options, r.Zone.IANA)
 	}
-
-	// Focus blocks
-	if strings.Contains(summaryLower, "focus") || strings.Contains(summaryLower, "deep work") {
-		return 2 * time.Hour
+	if len(options) == 0 {
+		return prompts.Input("Timezone (IANA, e.g. Europe/Madrid; empty to skip)", "")
 	}
-
-	// Time of day defaults (when no keywords match)
+	idx, choice := prompts.Choose("Pick a timezone (empty to skip):", append(options, other))
 	switch {
-	case hour >= 6 && hour < 9: // Early morning
-		return 30 * time.Minute
-	case hour >= 12 && hour < 14: // Lunch time
-		return 1 * time.Hour
-	case hour >= 18 && hour < 21: // Evening/dinner
-		return 1*time.Hour + 30*time.Minute
-	case hour >= 21 || hour < 6: // Late night/early morning
-		return 30 * time.Minute
-	default: // Business hours (9-18)
-		return 1 * time.Hour
+	case idx < 0:
+		return ""
+	case choice == other:
+		return prompts.Input("Timezone (IANA, e.g. Europe/Madrid)", "")
 	}
+	return choice
 }
 
-// detectEventConflicts checks for overlapping events in the same timezone.
-// Returns a list of human-readable conflict descriptions.
-func detectEventConflicts(events []calendar.Event) []string {
-	var conflicts []string
-
-	for i := 0; i < len(events); i++ {
-		for j := i + 1; j < len(events); j++ {
-			ev1, ev2 := events[i], events[j]
+// printBatchFixes lists values entered at fix-it prompts: they only apply to
+// this run, so the source file still needs the same edits.
+func printBatchFixes(fixes []batchFix, input string) {
+	if len(fixes) == 0 {
+		return
+	}
+	output.Info(os.Stdout, "✏️ ", "\nApplied %d fix(es) for this run; update %s to keep them:\n", len(fixes), input)
+	for _, f := range fixes {
+		fmt.Printf("  • row %d %s: %q → %q\n", f.row, f.field, f.oldValue, f.newValue)
+	}
+}
 
-			// Skip all-day events
-			if ev1.AllDay || ev2.AllDay {
-				continue
+// printSpellingReport lists, for --show-corrections, each word the
+// spell_corrections dictionary replaced and the rows it was replaced in.
+func printSpellingReport(records []batchRecord, corrections map[string]string) {
+	rows := map[spellingFix][]string{}
+	var order []spellingFix
+	for i, rec := range records {
+		_, fixes := correctSpelling(rec.Summary, corrections)
+		for _, fix := range fixes {
+			if _, seen := rows[fix]; !seen {
+				order = append(order, fix)
 			}
-
-			// Check if events overlap
-			if ev1.EndTime.After(ev2.StartTime) && ev2.EndTime.After(ev1.StartTime) {
-				conflict := fmt.Sprintf("%s (%s-%s) overlaps with %s (%s-%s)",
-					ev1.Summary,
-					ev1.StartTime.Format("15:04"),
-					ev1.EndTime.Format("15:04"),
-					ev2.Summary,
-					ev2.StartTime.Format("15:04"),
-					ev2.EndTime.Format("15:04"))
-				conflicts = append(conflicts, conflict)
+			if r := strconv.Itoa(i + 1); !slices.Contains(rows[fix], r) {
+				rows[fix] = append(rows[fix], r)
 			}
 		}
 	}
-
-	return conflicts
-}
-
-// generatePrepTimeEvents creates preparation and transition buffer events.
-// Based on ADHD time boxing research: 15min buffers prevent task derailment.
-// Evidence: https://akiflow.com/blog/time-blocking-adhd
-func generatePrepTimeEvents(events []calendar.Event) []*calendar.Event {
-	var prepEvents []*calendar.Event
-
-	for _, ev := range events {
-		if ev.AllDay {
-			continue
-		}
-
-		if transitionEvent := createTransitionEventIfNeeded(ev); transitionEvent != nil {
-			prepEvents = append(prepEvents, transitionEvent)
-			continue
-		}
-
-		if prepEvent := createPrepEventIfNeeded(ev); prepEvent != nil {
-			prepEvents = append(prepEvents, prepEvent)
-		}
+	if len(order) == 0 {
+		output.Info(os.Stdout, "📝", "%s\n", ui.T("batch_corrections_none"))
+		return
 	}
+	output.Info(os.Stdout, "📝", "%s\n", ui.T("batch_corrections_header", len(order)))
+	for _, fix := range order {
+		fmt.Printf("  • %s\n", ui.T("batch_correction_line", fix.from, fix.to, strings.Join(rows[fix], ", ")))
+	}
+}
 
-	return prepEvents
+type batchOptions struct {
+	input           string
+	output          string
+	formatFlag      string
+	columns         columnMapping       // --map: CSV headers for batch columns
+	columnAliases   map[string][]string // column_aliases from config, tried when a column is missing
+	outputFormat    string              // ics, jcal or xcal
+	outputFormatSet bool                // --output-format was given, so a file's calendar.target is ignored
+	name            string
+	meta            calendarMeta // --calendar-description, --calendar-uid, --calendar-url, --refresh-interval, --source-url
+	defaultTZ       string
+	emitDuration    bool // DURATION instead of DTEND
+	dryRun          bool
+	checkConflicts  bool
+	maxEventsPerDay int
+	autoBalance     bool // move events off overloaded days
+	balanceYes      bool // apply --auto-balance without asking
+	energyBudget    int
+	addPrepTime     bool
+	buffers         bufferRules
+	bufferWarnings  []diag.Warning // buffers --add-prep-time added, shortened or left out
+	travel          travelRules
+	travelWarnings  []diag.Warning // tight gaps between locations and travel buffers added
+	timeNotes       bool
+	jitter          time.Duration
+	jitterWarnings  []diag.Warning // recurring rows --jitter expanded only in part
+	jitterSeed      int64
+	jsonOutput      bool
+	strict          bool
+	strictInput     bool
+	corrections     map[string]string // spell_corrections for summaries (nil keeps them as written)
+	showCorrections bool
+	stableUIDs      bool
+	sarifPath       string
+	fixInteractive  bool
+	skipInvalid     bool // report bad rows and write the others
+	maxErrors       int  // with skipInvalid, abort after this many bad rows (0 = no limit)
+	hours           hoursPolicy
+	days            dayFilter
+	categoryLang    string // translate CATEGORIES into this language ("" keeps them canonical)
+	appendOutput    bool
+	embedSource     bool
+	sourceFlags     map[string]string // explicitly set flags, recorded in the source bundle
+	sourceBundle    string            // encoded X-TEMPUS-SOURCE-BUNDLE for this run
+	policy          config.OutputPolicy
+	language        string // language for relative dates in start/end ("tomorrow 09:30")
+	perAttendee     bool   // also write one calendar per attendee
+	attendeeColumn  string // attendees or organizer
+	attendeeFile    string // file name template for per-attendee calendars ("" = <output>-{{attendee}})
 }
 
-func createTransitionEventIfNeeded(ev calendar.Event) *calendar.Event {
-	if !needsFocusTransition(ev.Summary) {
-		return nil
+func parseBatchFlags(cmd *cobra.Command) (*batchOptions, error) {
+	opts := &batchOptions{}
+	opts.input, _ = cmd.Flags().GetString("input")
+	opts.output, _ = cmd.Flags().GetString("output")
+	opts.formatFlag, _ = cmd.Flags().GetString("format")
+	maps, _ := cmd.Flags().GetStringArray("map")
+	columns, err := parseColumnMapping(maps)
+	if err != nil {
+		return nil, err
 	}
-
-	return &calendar.Event{
-		UID:        generateUID(),
-		Summary:    "🔄 Transition: " + stripEmoji(ev.Summary),
-		StartTime:  ev.EndTime,
-		EndTime:    ev.EndTime.Add(5 * time.Minute),
-		StartTZ:    ev.StartTZ,
-		EndTZ:      ev.EndTZ,
-		AllDay:     false,
-		Categories: []string{"Transition"},
-		Status:     "CONFIRMED",
-		Created:    time.Now().UTC(),
-		LastMod:    time.Now().UTC(),
-	}
-}
-
-func createPrepEventIfNeeded(ev calendar.Event) *calendar.Event {
-	duration, description := determinePrepTime(ev.Summary)
-	if duration == 0 {
-		return nil
+	opts.columns = columns
+	cfg, cfgErr := config.Current()
+	if cfgErr == nil {
+		opts.columnAliases = cfg.ColumnAliases
 	}
-
-	return &calendar.Event{
-		UID:        generateUID(),
-		Summary:    "⏰ " + description + ": " + stripEmoji(ev.Summary),
-		StartTime:  ev.StartTime.Add(-duration),
-		EndTime:    ev.StartTime,
-		StartTZ:    ev.StartTZ,
-		EndTZ:      ev.EndTZ,
-		AllDay:     false,
-		Categories: []string{"Preparation"},
-		Status:     "CONFIRMED",
-		Created:    time.Now().UTC(),
-		LastMod:    time.Now().UTC(),
+	outputFormat, _ := cmd.Flags().GetString("output-format")
+	if jsonReport(cmd) {
+		opts.jsonOutput, outputFormat = true, "auto"
 	}
-}
+	format, err := calendarOutputFormat(outputFormat, opts.output)
+	if err != nil {
+		return nil, err
+	}
+	opts.outputFormat = format
+	opts.outputFormatSet = calendarOutputFormatSet(outputFormat)
+	opts.name, _ = cmd.Flags().GetString("name")
+	if opts.meta, err = parseCalendarMeta(cmd); err != nil {
+		return nil, err
+	}
+	opts.defaultTZ, _ = cmd.Flags().GetString("default-tz")
+	opts.emitDuration, _ = cmd.Flags().GetBool("emit-duration")
+	opts.dryRun, _ = cmd.Flags().GetBool("dry-run")
+	opts.checkConflicts, _ = cmd.Flags().GetBool("check-conflicts")
+	opts.maxEventsPerDay, _ = cmd.Flags().GetInt("max-events-per-day")
+	opts.autoBalance, _ = cmd.Flags().GetBool("auto-balance")
+	opts.balanceYes, _ = cmd.Flags().GetBool("yes")
+	opts.energyBudget, _ = cmd.Flags().GetInt("energy-budget")
+	if !cmd.Flags().Changed("energy-budget") && cfgErr == nil {
+		opts.energyBudget = cfg.EnergyBudget
+	}
+	opts.addPrepTime, _ = cmd.Flags().GetBool("add-prep-time")
+	if opts.addPrepTime || cmd.Flags().Changed("prep-before") || cmd.Flags().Changed("transition-after") {
+		if opts.buffers, err = loadBufferRules(cmd); err != nil {
+			return nil, err
+		}
+		opts.addPrepTime = true
+	}
+	if opts.travel, err = loadTravelRules(cmd); err != nil {
+		return nil, err
+	}
+	opts.travel.check = opts.travel.add || opts.checkConflicts || opts.dryRun
+	opts.timeNotes, _ = cmd.Flags().GetBool("time-annotations")
+	opts.jitterSeed, _ = cmd.Flags().GetInt64("jitter-seed")
+	if jsonFlag, _ := cmd.Flags().GetBool("json"); jsonFlag {
+		opts.jsonOutput = true
+	}
+	opts.strict, _ = cmd.Flags().GetBool("strict")
+	opts.strictInput, _ = cmd.Flags().GetBool("strict-input")
+	opts.language = outputLanguage(cmd)
+	if noSpell, _ := cmd.Flags().GetBool("no-spell-correct"); !noSpell && !opts.strictInput && cfgErr == nil {
+		opts.corrections = cfg.Corrections()
+	}
+	opts.showCorrections, _ = cmd.Flags().GetBool("show-corrections")
+	opts.stableUIDs, _ = cmd.Flags().GetBool("stable-uids")
+	opts.sarifPath, _ = cmd.Flags().GetString("sarif")
+	opts.sarifPath = strings.TrimSpace(opts.sarifPath)
+	opts.appendOutput, _ = cmd.Flags().GetBool("append")
+	if opts.appendOutput && opts.outputFormat != "ics" {
+		return nil, fmt.Errorf("--append only works with ICS output")
+	}
+	opts.embedSource, _ = cmd.Flags().GetBool("embed-source")
+	if opts.embedSource {
+		opts.sourceFlags = bundleFlags(cmd)
+	}
+	if translate, _ := cmd.Flags().GetBool("translate-categories"); translate {
+		opts.categoryLang = outputLanguage(cmd)
+	}
+	opts.perAttendee, _ = cmd.Flags().GetBool("per-attendee")
+	opts.attendeeColumn, _ = cmd.Flags().GetString("attendee-column")
+	opts.attendeeColumn = strings.ToLower(strings.TrimSpace(opts.attendeeColumn))
+	if opts.attendeeColumn != "attendees" && opts.attendeeColumn != "organizer" {
+		return nil, fmt.Errorf("invalid --attendee-column %q (use attendees or organizer)", opts.attendeeColumn)
+	}
+	opts.attendeeFile, _ = cmd.Flags().GetString("attendee-file")
+	if opts.attendeeFile = strings.TrimSpace(opts.attendeeFile); opts.attendeeFile != "" {
+		if err := checkAttendeeFile(opts.attendeeFile); err != nil {
+			return nil, err
+		}
+		opts.perAttendee = true
+	}
+	if opts.perAttendee && opts.appendOutput {
+		return nil, fmt.Errorf("--per-attendee cannot be combined with --append")
+	}
+	opts.skipInvalid, _ = cmd.Flags().GetBool("skip-invalid")
+	opts.maxErrors, _ = cmd.Flags().GetInt("max-errors")
+	if opts.maxErrors < 0 {
+		return nil, fmt.Errorf("--max-errors must be 0 or more")
+	}
+	if opts.maxErrors > 0 {
+		opts.skipInvalid = true
+	}
+	noFix, _ := cmd.Flags().GetBool("no-fix")
+	opts.fixInteractive = !noFix && !opts.jsonOutput && isInteractiveTerminal()
 
-func needsFocusTransition(summary string) bool {
-	summaryLower := strings.ToLower(summary)
-	focusKeywords := []string{"focus", "deep work", "coding", "writing"}
+	opts.input = strings.TrimSpace(opts.input)
+	if opts.input == "" {
+		return nil, fmt.Errorf("--input is required")
+	}
 
-	for _, keyword := range focusKeywords {
-		if strings.Contains(summaryLower, keyword) {
-			return true
+	if jitter, _ := cmd.Flags().GetString("jitter"); strings.TrimSpace(jitter) != "" {
+		d, err := calendar.ParseHumanDuration(jitter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --jitter: %w", err)
 		}
+		opts.jitter = d
 	}
-	return false
+
+	hours, err := loadHoursPolicy()
+	if err != nil {
+		return nil, err
+	}
+	opts.hours = hours
+
+	days, err := parseDayFilterFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+	opts.days = days
+
+	if opts.policy, err = loadOutputPolicy(); err != nil {
+		return nil, err
+	}
+	policy := opts.policy
+	if opts.appendOutput {
+		policy.Overwrite = config.OverwriteReplace // --append adds to the file on purpose
+	}
+	if opts.output, err = resolveOutputPath(opts.output, policy); err != nil {
+		return nil, err
+	}
+
+	return opts, nil
 }
 
-func determinePrepTime(summary string) (time.Duration, string) {
-	summaryLower := strings.ToLower(summary)
+func loadBatchInput(opts *batchOptions) ([]batchRecord, batchFormat, error) {
+	format, err := detectBatchFormat(opts.formatFlag, opts.input)
+	if err != nil {
+		return nil, "", err
+	}
+	if format == batchFormatCSV && len(opts.columns) == 0 && strings.EqualFold(strings.TrimSpace(opts.formatFlag), "auto") {
+		format = sniffCalendarAppCSV(opts.input)
+	}
+
+	var records []batchRecord
+	switch {
+	case format == batchFormatCSV:
+		records, err = loadMappedCSV(opts.input, opts.columns, opts.columnAliases)
+	case len(opts.columns) > 0:
+		return nil, "", fmt.Errorf("--map only applies to CSV input, not %s", format)
+	default:
+		records, err = loadBatchRecords(opts.input, format)
+	}
+	if err != nil {
+		return nil, "", err
+	}
 
-	// Medical/health events: 20min prep
-	if containsAny(summaryLower, []string{"doctor", "médico", "dentist", "therapy", "hospital", "clinic"}) {
-		return 20 * time.Minute, "Travel & arrival buffer"
+	if len(records) == 0 {
+		return nil, "", fmt.Errorf("no events found in %s", opts.input)
 	}
 
-	// Meetings and appointments: 15min prep
-	if containsAny(summaryLower, []string{"meeting", "reunion", "appointment", "cita", "interview", "call"}) {
-		return 15 * time.Minute, "Preparation"
+	defaults, err := loadCalendarDefaults(opts.input, format)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := defaults.apply(opts, records); err != nil {
+		return nil, "", fmt.Errorf("%s: %w", opts.input, err)
 	}
 
-	return 0, ""
+	return records, format, nil
 }
 
-func containsAny(text string, keywords []string) bool {
-	for _, keyword := range keywords {
-		if strings.Contains(text, keyword) {
-			return true
+// calendarDefaults is the calendar: block of a JSON, YAML or TOML batch file,
+// which makes the file describe its own run. Flags win over it.
+type calendarDefaults struct {
+	Name       string
+	DefaultTZ  string
+	Alarms     []string // for events without alarms of their own
+	Categories []string // added to every event
+	Target     string   // output format: ics, jcal or xcal
+	Meta       calendarMeta
+}
+
+var calendarDefaultKeys = []string{"name", "default_tz", "alarms", "categories", "target", "description", "uid", "url", "refresh_interval", "source"}
+
+// calendarMeta describes a calendar as a whole (RFC 7986): what it is, its
+// UID, a page about it and where subscribers refresh it from.
+type calendarMeta struct {
+	Description     string
+	UID             string
+	URL             string
+	RefreshInterval time.Duration
+	Source          string
+}
+
+// parseCalendarMeta reads the batch flags that describe the calendar.
+func parseCalendarMeta(cmd *cobra.Command) (calendarMeta, error) {
+	var m calendarMeta
+	m.Description, _ = cmd.Flags().GetString("calendar-description")
+	m.UID, _ = cmd.Flags().GetString("calendar-uid")
+	m.URL, _ = cmd.Flags().GetString("calendar-url")
+	m.RefreshInterval, _ = cmd.Flags().GetDuration("refresh-interval")
+	m.Source, _ = cmd.Flags().GetString("source-url")
+	return m, m.validate("--calendar-url", "--refresh-interval", "--source-url")
+}
+
+// validate checks that the URLs are absolute and the interval is not negative;
+// the names are what to call them in errors.
+func (m calendarMeta) validate(urlName, refreshName, sourceName string) error {
+	for _, u := range []struct{ name, value string }{{urlName, m.URL}, {sourceName, m.Source}} {
+		if u.value == "" {
+			continue
+		}
+		if parsed, err := url.Parse(u.value); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("%s must be an absolute URL such as https://example.com/work.ics, got %q", u.name, u.value)
 		}
 	}
-	return false
+	if m.RefreshInterval < 0 {
+		return fmt.Errorf("%s must not be negative", refreshName)
+	}
+	return nil
 }
 
-// stripEmoji removes emoji from event summary for prep event names
-func stripEmoji(s string) string {
-	// Remove common emoji prefixes
-	s = strings.TrimSpace(s)
-	if len(s) > 0 {
-		// Simple approach: if starts with emoji (unicode > 127), skip first char
-		firstRune := []rune(s)[0]
-		if firstRune > 127 {
-			runes := []rune(s)
-			if len(runes) > 1 {
-				return strings.TrimSpace(string(runes[1:]))
-			}
-		}
+// orElse fills the fields m leaves empty from other.
+func (m calendarMeta) orElse(other calendarMeta) calendarMeta {
+	m.Description = firstNonEmpty(m.Description, other.Description)
+	m.UID = firstNonEmpty(m.UID, other.UID)
+	m.URL = firstNonEmpty(m.URL, other.URL)
+	m.Source = firstNonEmpty(m.Source, other.Source)
+	if m.RefreshInterval == 0 {
+		m.RefreshInterval = other.RefreshInterval
 	}
-	return s
+	return m
 }
 
-// generateUID creates a unique identifier for calendar events
-func generateUID() string {
-	return uuid.New().String() + "@tempus"
+// applyTo writes m into cal.
+func (m calendarMeta) applyTo(cal *calendar.Calendar) {
+	cal.Description = strings.TrimSpace(m.Description)
+	cal.UID = strings.TrimSpace(m.UID)
+	cal.URL = strings.TrimSpace(m.URL)
+	cal.RefreshInterval = m.RefreshInterval
+	cal.Source = strings.TrimSpace(m.Source)
 }
 
-// detectOverwhelmDays identifies days with too many events.
-// Returns warnings for days exceeding the threshold.
-func detectOverwhelmDays(events []calendar.Event, maxPerDay int) []string {
-	if maxPerDay == 0 {
-		maxPerDay = 8 // Default threshold for dry-run
+// loadCalendarDefaults reads the calendar: block of path, if it has one.
+func loadCalendarDefaults(path string, format batchFormat) (calendarDefaults, error) {
+	var defaults calendarDefaults
+	switch format {
+	case batchFormatJSON, batchFormatYAML, batchFormatTOML:
+	default:
+		return defaults, nil
 	}
-
-	// Group events by date
-	eventsByDay := make(map[string]int)
-	for _, ev := range events {
-		dateKey := ev.StartTime.Format("2006-01-02")
-		eventsByDay[dateKey]++
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return defaults, err
+	}
+	doc, err := decodeDataDocument(data, format)
+	if err != nil {
+		return defaults, err
+	}
+	top, _ := doc.(map[string]interface{})
+	raw, present := top["calendar"]
+	if !present {
+		return defaults, nil
+	}
+	block, ok := raw.(map[string]interface{})
+	if !ok {
+		return defaults, fmt.Errorf("calendar must be a map of %s", strings.Join(calendarDefaultKeys, ", "))
+	}
+	for key := range block {
+		if !slices.Contains(calendarDefaultKeys, key) {
+			return defaults, fmt.Errorf("unknown calendar key %q (use %s)", key, strings.Join(calendarDefaultKeys, ", "))
+		}
+	}
+	defaults.Name = valueAsString(block["name"])
+	defaults.DefaultTZ = valueAsString(block["default_tz"])
+	defaults.Alarms = valueAsStringSlice(block["alarms"])
+	defaults.Categories = valueAsStringSlice(block["categories"])
+	defaults.Target = valueAsString(block["target"])
+	defaults.Meta = calendarMeta{
+		Description: valueAsString(block["description"]),
+		UID:         valueAsString(block["uid"]),
+		URL:         valueAsString(block["url"]),
+		Source:      valueAsString(block["source"]),
+	}
+	if refresh := valueAsString(block["refresh_interval"]); refresh != "" {
+		if defaults.Meta.RefreshInterval, err = calendar.ParseHumanDuration(refresh); err != nil {
+			return defaults, fmt.Errorf("invalid calendar.refresh_interval %q: %w", refresh, err)
+		}
 	}
+	if err := defaults.Meta.validate("calendar.url", "calendar.refresh_interval", "calendar.source"); err != nil {
+		return defaults, err
+	}
+	return defaults, nil
+}
 
-	var warnings []string
-	for date, count := range eventsByDay {
-		if count > maxPerDay {
-			t, _ := time.Parse("2006-01-02", date)
-			dayName := t.Format("Monday, Jan 2")
-			warnings = append(warnings, fmt.Sprintf("%s: %d events (threshold: %d)", dayName, count, maxPerDay))
+// apply fills the options no flag set and gives every record the block's
+// alarms and categories.
+func (d calendarDefaults) apply(opts *batchOptions, records []batchRecord) error {
+	if strings.TrimSpace(opts.name) == "" {
+		opts.name = d.Name
+	}
+	opts.meta = opts.meta.orElse(d.Meta)
+	if strings.TrimSpace(opts.defaultTZ) == "" && d.DefaultTZ != "" {
+		tz, _ := tzpkg.CanonicalName(d.DefaultTZ)
+		if _, err := time.LoadLocation(tz); err != nil {
+			return fmt.Errorf("invalid calendar.default_tz %q: %w", d.DefaultTZ, err)
+		}
+		opts.defaultTZ = d.DefaultTZ
+	}
+	if d.Target != "" && !opts.outputFormatSet {
+		format, err := calendarOutputFormat(d.Target, "")
+		if err != nil {
+			return fmt.Errorf("calendar.target: %w", err)
+		}
+		if opts.appendOutput && format != "ics" {
+			return fmt.Errorf("--append only works with ICS output (calendar.target is %s)", format)
+		}
+		opts.outputFormat = format
+	}
+	for i := range records {
+		if len(records[i].Alarms) == 0 {
+			records[i].Alarms = slices.Clone(d.Alarms)
+		}
+		for _, c := range d.Categories {
+			if !slices.ContainsFunc(records[i].Categories, func(have string) bool { return strings.EqualFold(have, c) }) {
+				records[i].Categories = append(records[i].Categories, c)
+			}
 		}
 	}
+	return nil
+}
 
-	// Sort warnings by date
-	sort.Strings(warnings)
-	return warnings
+// calendarOutputFormatSet reports whether --output-format names a format
+// rather than leaving it to the output extension.
+func calendarOutputFormatSet(flag string) bool {
+	f := strings.ToLower(strings.TrimSpace(flag))
+	return f != "" && f != "auto"
 }
 
-// expandAlarmProfiles replaces profile references (e.g., "profile:adhd-triple") with actual alarm triggers.
-// If a spec doesn't start with "profile:", it's returned as-is.
-func expandAlarmProfiles(alarmSpecs []string) []string {
-	cfg, err := config.Load()
-	if err != nil {
-		// If config can't be loaded, return specs unchanged
-		return alarmSpecs
+func buildBatchCalendar(records []batchRecord, opts *batchOptions) (*calendar.Calendar, []diag.Warning, error) {
+	cal := calendar.NewCalendar()
+	cal.IncludeVTZ = true
+	cal.EmitDuration = opts.emitDuration
+
+	if strings.TrimSpace(opts.name) != "" {
+		cal.Name = opts.name
+	}
+	opts.meta.applyTo(cal)
+	if strings.TrimSpace(opts.defaultTZ) != "" {
+		defaultTZ, _ := tzpkg.CanonicalName(opts.defaultTZ)
+		cal.SetDefaultTimezone(defaultTZ)
+	}
+	if err := setCategoryTranslation(cal, opts.categoryLang); err != nil {
+		return nil, nil, err
 	}
+	cal.SourceBundle = opts.sourceBundle
 
-	expanded := make([]string, 0, len(alarmSpecs))
-	for _, spec := range alarmSpecs {
-		spec = strings.TrimSpace(spec)
-		if spec == "" {
-			continue
+	var rnd *rand.Rand
+	if opts.jitter > 0 {
+		seed := opts.jitterSeed
+		if seed == 0 {
+			seed = appClock.Now().UnixNano()
 		}
+		rnd = rand.New(rand.NewSource(seed)) // #nosec G404 -- scheduling jitter, not security sensitive
+	}
 
-		// Check if it's a profile reference
-		if strings.HasPrefix(spec, "profile:") {
-			profileName := strings.TrimPrefix(spec, "profile:")
-			profileName = strings.TrimSpace(profileName)
-
-			profile := cfg.GetAlarmProfile(profileName)
-			if profile != nil {
-				expanded = append(expanded, profile...)
-			} else {
-				// Profile not found, keep original spec (will error later)
-				expanded = append(expanded, spec)
+	opts.jitterWarnings = nil
+	var validationErrors []diag.Warning
+	var fixes []batchFix
+	uids := map[string]int{}
+	for i, rec := range records {
+		added := len(cal.Events)
+		ev, err := buildBatchRowEvent(rec, opts, uids)
+		for err != nil && opts.fixInteractive {
+			fix, ok := promptBatchFix(i+1, &rec, err, opts.defaultTZ)
+			if !ok {
+				break
 			}
-		} else {
-			expanded = append(expanded, spec)
+			fixes = append(fixes, fix)
+			records[i] = rec
+			ev, err = buildBatchRowEvent(rec, opts, uids)
+		}
+		if err == nil {
+			jitter := opts.jitter
+			if len(rec.Overrides) > 0 {
+				jitter = 0 // overrides need the series, not materialized copies
+			}
+			var truncated bool
+			if truncated, err = addBatchEvent(cal, ev, jitter, rnd); truncated {
+				opts.jitterWarnings = append(opts.jitterWarnings, diag.Warning{
+					Code: diag.CodeTruncated, Severity: diag.SeverityWarning, File: opts.input, Row: i + 1,
+					Message:    ui.T("batch_jitter_truncated", stripEmoji(ev.Summary), calendar.DefaultMaterializeLimit),
+					Suggestion: ui.T("batch_jitter_truncated_hint"),
+				})
+			}
+		}
+		if err == nil {
+			err = addOverrides(cal, ev, rec.Overrides)
+		}
+		if err != nil {
+			if opts.dryRun || opts.skipInvalid {
+				cal.Events = cal.Events[:added] // drop a series whose overrides failed
+				validationErrors = append(validationErrors, diag.Warning{
+					Code: diag.CodeInvalidRow, Severity: diag.SeverityError,
+					File: opts.input, Row: i + 1, Message: err.Error(),
+				})
+				if !opts.dryRun && opts.maxErrors > 0 && len(validationErrors) > opts.maxErrors {
+					diag.Render(os.Stderr, validationErrors)
+					return nil, nil, fmt.Errorf("stopped at row %d: more than %d invalid row(s) (--max-errors), nothing written", i+1, opts.maxErrors)
+				}
+				continue
+			}
+			return nil, nil, fmt.Errorf(testutil.ErrMsgRowFormat, i+1, err)
 		}
 	}
 
-	return expanded
-}
+	printBatchFixes(fixes, opts.input)
 
-// ========================================================================
-// Batch Template Generator
-// ========================================================================
+	if opts.timeNotes {
+		annotateTimes(cal.Events)
+	}
+	rows := len(cal.Events)
+	if opts.addPrepTime {
+		prepEvents, notes := generatePrepTimeEvents(cal.Events, opts.buffers)
+		for _, prepEv := range prepEvents {
+			cal.AddEvent(prepEv)
+		}
+		opts.bufferWarnings = bufferWarnings(len(prepEvents), notes, opts.input)
+	}
+	if opts.travel.enabled() {
+		travelEvents, notes := planTravel(cal.Events[:rows], cal.Events, opts.travel)
+		if opts.travel.add {
+			for _, ev := range travelEvents {
+				cal.AddEvent(ev)
+			}
+		}
+		opts.travelWarnings = travelWarnings(len(travelEvents), notes, opts)
+	}
 
-func newBatchTemplateCmd() *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "template [type]",
-		Short: "Generate a pre-filled template file for batch mode",
-		Long: `Generate template files to quickly start creating events.
+	return cal, validationErrors, nil
+}
 
-Available template types:
-  basic             - Simple 3-event example (CSV)
-  adhd-routine      - Daily ADHD routine with medication and focus blocks (CSV)
-  medication        - Medication schedule with triple alarms (YAML)
-  work-meetings     - Recurring team meetings (CSV)
-  medical           - Healthcare appointments with prep reminders (CSV)
-  travel            - Travel itinerary with flights and hotels (JSON)
-  family            - Family calendar with mixed events (CSV)
+func buildBatchRowEvent(rec batchRecord, opts *batchOptions, uids map[string]int) (*calendar.Event, error) {
+	rec.Summary, _ = correctSpelling(rec.Summary, opts.corrections)
+	rewriteBatchTimestamps(&rec, opts.defaultTZ)
+	if !opts.strictInput {
+		rec.Start = expandRelativeInput(rec.Start, firstNonEmpty(rec.StartTZ, opts.defaultTZ), opts.language)
+		rec.End = expandRelativeInput(rec.End, firstNonEmpty(rec.EndTZ, rec.StartTZ, opts.defaultTZ), opts.language)
+	}
+	ev, err := buildEventFromBatch(rec, opts.defaultTZ, opts.strictInput)
+	if err != nil {
+		return nil, err
+	}
+	if err := assignBatchUID(ev, rec, opts.stableUIDs, uids); err != nil {
+		return nil, err
+	}
+	return ev, nil
+}
 
-Examples:
-  tempus batch template basic -o my-events.csv
-  tempus batch template adhd-routine -o routine.csv
-  tempus batch template medication -o meds.yaml`,
-		Args: cobra.ExactArgs(1),
-		RunE: runBatchTemplate,
+// rewriteBatchTimestamps reads RFC 3339 and epoch start and end columns as
+// wall clock times in the row's timezone, like rewriteCreateTimestamps. A
+// bracketed zone beats --default-tz but not a start_tz column.
+func rewriteBatchTimestamps(rec *batchRecord, defaultTZ string) {
+	startTZ := firstNonEmpty(rec.StartTZ, defaultTZ)
+	if _, zone, ok := normalizer.ParseTimestamp(rec.Start); ok && zone != "" && strings.TrimSpace(rec.StartTZ) == "" {
+		rec.StartTZ, startTZ = zone, zone
 	}
+	if out, _, ok := normalizer.RewriteTimestamp(rec.Start, startTZ, rec.AllDay); ok {
+		rec.Start = out
+	}
+	if out, _, ok := normalizer.RewriteTimestamp(rec.End, firstNonEmpty(rec.EndTZ, startTZ), rec.AllDay); ok {
+		rec.End = out
+	}
+}
 
-	cmd.Flags().StringP("output", "o", "", "Output file path (required)")
-	_ = cmd.MarkFlagRequired("output")
+// assignBatchUID applies an explicit uid column, or with stableUIDs a UID derived
+// from the row's summary, start and timezone. seen tracks UIDs already used in
+// this run: explicit duplicates are an error, derived ones get a -2, -3... suffix.
+func assignBatchUID(ev *calendar.Event, rec batchRecord, stableUIDs bool, seen map[string]int) error {
+	if uid := strings.TrimSpace(rec.UID); uid != "" {
+		if seen[uid] > 0 {
+			return fieldError("uid", fmt.Errorf("duplicate uid %q", uid))
+		}
+		seen[uid]++
+		ev.UID = uid
+		return nil
+	}
+	if !stableUIDs {
+		return nil
+	}
 
-	return cmd
+	start := ev.StartTime.Format(constants.DateTimeFormatISO)
+	if ev.AllDay {
+		start = ev.StartTime.Format(constants.DateFormatISO)
+	}
+	uid := calendar.StableUID(strings.ToLower(strings.TrimSpace(rec.Summary)), start, ev.StartTZ)
+	seen[uid]++
+	if n := seen[uid]; n > 1 {
+		uid = suffixUID(uid, strconv.Itoa(n))
+	}
+	ev.UID = uid
+	return nil
 }
 
-func runBatchTemplate(cmd *cobra.Command, args []string) error {
-	templateType := strings.ToLower(strings.TrimSpace(args[0]))
-	output, _ := cmd.Flags().GetString("output")
-
-	if output == "" {
-		return fmt.Errorf("--output is required")
+// addBatchEvent adds ev to cal. With jitter enabled, recurring timed events are
+// materialized into individual occurrences shifted within ±jitter ("around 21:00");
+// non-recurring and all-day events are always added unchanged. truncated
+// reports a rule that goes on past calendar.DefaultMaterializeLimit
+// occurrences, the later ones being left out.
+func addBatchEvent(cal *calendar.Calendar, ev *calendar.Event, jitter time.Duration, rnd *rand.Rand) (truncated bool, err error) {
+	if jitter <= 0 || strings.TrimSpace(ev.RRule) == "" || ev.AllDay {
+		cal.AddEvent(ev)
+		return false, nil
 	}
 
-	content, err := getBatchTemplateContent(templateType)
+	occurrences, truncated, err := ev.Materialize(calendar.DefaultMaterializeLimit, jitter, rnd)
 	if err != nil {
-		return err
+		return false, err
+	}
+	for i := range occurrences {
+		// Keep materialized occurrences as stable as their parent's UID.
+		occurrences[i].UID = calendar.StableUID(ev.UID, strconv.Itoa(i))
+		cal.AddEvent(&occurrences[i])
 	}
+	return truncated, nil
+}
 
-	if err := os.WriteFile(output, []byte(content), 0600); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+func collectBatchWarnings(events []calendar.Event, opts *batchOptions) []diag.Warning {
+	var warnings []diag.Warning
+	add := func(code string, messages []string, suggestion string) {
+		for _, msg := range messages {
+			warnings = append(warnings, diag.Warning{
+				Code: code, Severity: diag.SeverityWarning, File: opts.input,
+				Message: msg, Suggestion: suggestion,
+			})
+		}
 	}
 
-	printOK("Template created: %s\n", output)
-	fmt.Printf("Edit the file and run: tempus batch -i %s -o calendar.ics\n", output)
+	if opts.checkConflicts || opts.dryRun {
+		add(diag.CodeConflict, detectEventConflicts(events), ui.T("batch_conflict_hint"))
+	}
+	if opts.maxEventsPerDay > 0 || opts.dryRun {
+		add(diag.CodeOverload, detectOverwhelmDays(events, opts.maxEventsPerDay), ui.T("batch_overload_hint"))
+	}
+	if opts.energyBudget > 0 {
+		add(diag.CodeEnergy, detectEnergyOverload(events, opts.energyBudget), ui.T("batch_energy_hint"))
+	}
+	add(diag.CodeHours, detectHoursViolations(events, opts.hours), ui.T("hours_hint"))
 
-	return nil
+	return warnings
+}
+
+// collectAutocorrections reports summaries changed by the spell_corrections
+// dictionary so users can see (and undo with --no-spell-correct) what was rewritten.
+func collectAutocorrections(records []batchRecord, opts *batchOptions) []diag.Warning {
+	if opts.corrections == nil {
+		return nil
+	}
+	var warnings []diag.Warning
+	for i, rec := range records {
+		original := strings.Join(strings.Fields(rec.Summary), " ")
+		if corrected, _ := correctSpelling(original, opts.corrections); corrected != original {
+			warnings = append(warnings, diag.Warning{
+				Code: diag.CodeAutocorrect, Severity: diag.SeverityInfo, File: opts.input, Row: i + 1,
+				Message:    ui.T("batch_autocorrect", original, corrected),
+				Suggestion: ui.T("batch_autocorrect_hint"),
+			})
+		}
+	}
+	return warnings
+}
+
+// collectOvernightEnds notes rows whose clock-only end is earlier than their
+// start's clock (23:00 to 01:00); the end is moved to the next day.
+func collectOvernightEnds(records []batchRecord, opts *batchOptions) []diag.Warning {
+	if opts.strictInput {
+		return nil
+	}
+	var warnings []diag.Warning
+	for i, rec := range records {
+		fields := strings.Fields(rec.Start)
+		if rec.AllDay || rec.Overnight || len(fields) == 0 || !looksLikeClock(rec.End) {
+			continue
+		}
+		if clock := fields[len(fields)-1]; looksLikeClock(clock) && clockMinutes(rec.End) < clockMinutes(clock) {
+			warnings = append(warnings, diag.Warning{
+				Code: diag.CodeOvernight, Severity: diag.SeverityInfo, File: opts.input, Row: i + 1,
+				Message:    ui.T("overnight_end_row", strings.TrimSpace(rec.End), strings.TrimSpace(rec.Start)),
+				Suggestion: ui.T("overnight_hint_batch"),
+			})
+		}
+	}
+	return warnings
+}
+
+// dayFilterWarnings wraps --skip-weekends/--skip-holidays notes as info warnings.
+func dayFilterWarnings(notes []string) []diag.Warning {
+	warnings := make([]diag.Warning, 0, len(notes))
+	for _, note := range notes {
+		warnings = append(warnings, diag.Warning{Code: diag.CodeDayFilter, Severity: diag.SeverityInfo, Message: note})
+	}
+	return warnings
+}
+
+// writeSARIFFile writes warnings as a SARIF log to path (for CI code scanning).
+func writeSARIFFile(path string, warnings []diag.Warning) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := outputFS.MkdirAll(dir, 0o750); err != nil {
+			return err
+		}
+	}
+	var buf bytes.Buffer
+	if err := diag.WriteSARIF(&buf, version, warnings); err != nil {
+		return err
+	}
+	if err := outputFS.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+func handleDryRun(validationErrors, warnings []diag.Warning, records []batchRecord, events []calendar.Event, opts *batchOptions) error {
+	if len(validationErrors) > 0 {
+		printErr("%s\n", ui.T("batch_validation_failed", len(validationErrors)))
+		diag.Render(os.Stdout, validationErrors)
+		return fmt.Errorf("validation failed")
+	}
+
+	printOK("✓ %s\n", ui.T("batch_validation_passed", len(records)))
+
+	if len(warnings) > 0 {
+		fmt.Printf("\n")
+		diag.Render(os.Stdout, warnings)
+	}
+
+	renderBatchTimeline(os.Stdout, events)
+	printDryRunSummary(records, opts)
+	return nil
+}
+
+// timelineMaxDays caps how many days the dry-run timeline draws.
+const timelineMaxDays = 14
+
+// timelineGlyphs maps categories to the ASCII character used for their blocks.
+var timelineGlyphs = map[string]byte{
+	"medication":    'M',
+	"meds":          'M',
+	"health":        'H',
+	"medical":       'H',
+	"therapy":       'T',
+	"mental health": 'T',
+	"exercise":      'X',
+	"workout":       'X',
+	"fitness":       'X',
+	"food":          'F',
+	"meal":          'F',
+	"restaurant":    'F',
+	"travel":        'V',
+	"flight":        'V',
+	"accommodation": 'A',
+	"hotel":         'A',
+	"work":          'W',
+	"meeting":       'W',
+	"focus":         'D',
+	"deep work":     'D',
+	"break":         'B',
+	"rest":          'B',
+	"transition":    '~',
+	"family":        'K',
+	"kids":          'K',
+	"personal":      'P',
+}
+
+func timelineGlyph(ev calendar.Event) byte {
+	for _, cat := range ev.Categories {
+		if g, ok := timelineGlyphs[strings.ToLower(strings.TrimSpace(cat))]; ok {
+			return g
+		}
+	}
+	return '#'
+}
+
+// renderBatchTimeline draws each day as an ASCII timeline: hours across, one
+// row per lane of non-overlapping events, so crowded days stand out before import.
+// Recurring events are drawn on their first day only.
+func renderBatchTimeline(w io.Writer, events []calendar.Event) {
+	byDay := map[string][]calendar.Event{}
+	allDay := map[string][]calendar.Event{}
+	for _, ev := range events {
+		day := ev.StartTime.Format(constants.DateFormatISO)
+		if ev.AllDay {
+			allDay[day] = append(allDay[day], ev)
+			continue
+		}
+		byDay[day] = append(byDay[day], ev)
+	}
+	days := make([]string, 0, len(byDay)+len(allDay))
+	for day := range byDay {
+		days = append(days, day)
+	}
+	for day := range allDay {
+		if _, ok := byDay[day]; !ok {
+			days = append(days, day)
+		}
+	}
+	if len(days) == 0 {
+		return
+	}
+	sort.Strings(days)
+
+	fmt.Fprintf(w, "\nTimeline:\n")
+	for i, day := range days {
+		if i == timelineMaxDays {
+			fmt.Fprintf(w, "\n  … %d more day(s) not shown\n", len(days)-timelineMaxDays)
+			break
+		}
+		renderTimelineDay(w, day, byDay[day], allDay[day])
+	}
+}
+
+func renderTimelineDay(w io.Writer, day string, timed, allDay []calendar.Event) {
+	sort.SliceStable(timed, func(i, j int) bool { return timed[i].StartTime.Before(timed[j].StartTime) })
+
+	// Greedily pack events into lanes; more than one lane means overlaps.
+	var lanes [][]calendar.Event
+	var laneEnds []time.Time
+	for _, ev := range timed {
+		placed := false
+		for l := range lanes {
+			if !laneEnds[l].After(ev.StartTime) {
+				lanes[l] = append(lanes[l], ev)
+				laneEnds[l] = ev.EndTime
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			lanes = append(lanes, []calendar.Event{ev})
+			laneEnds = append(laneEnds, ev.EndTime)
+		}
+	}
+
+	date, _ := time.Parse(constants.DateFormatISO, day)
+	header := fmt.Sprintf("%s (%d event(s)", displayDate(date), len(timed)+len(allDay))
+	if len(lanes) > 1 {
+		header += ", overlapping"
+	}
+	fmt.Fprintf(w, "\n  %s)\n", header)
+	for _, ev := range allDay {
+		fmt.Fprintf(w, "    all day: %s\n", ev.Summary)
+	}
+	if len(timed) == 0 {
+		return
+	}
+
+	// Offsets are wall-clock times from the day's midnight in the event's own location.
+	offset := func(t time.Time) time.Duration {
+		d := t.Sub(time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, t.Location()))
+		if d > 24*time.Hour {
+			return 24 * time.Hour
+		}
+		return d
+	}
+	first, last := 24, 0
+	for _, ev := range timed {
+		start, end := offset(ev.StartTime), offset(ev.EndTime)
+		if h := int(start.Hours()); h < first {
+			first = h
+		}
+		if h := int((end + time.Hour - time.Nanosecond).Hours()); h > last {
+			last = h
+		}
+	}
+	if last <= first {
+		last = first + 1
+	}
+	perHour := 4 // 15-minute cells
+	if last-first > 16 {
+		perHour = 2
+	}
+	width := (last - first) * perHour
+	labelEvery := 1
+	if perHour == 2 {
+		labelEvery = 2
+	}
+
+	labels := []byte(strings.Repeat(" ", width+2))
+	axis := []byte(strings.Repeat(".", width))
+	for h := first; h <= last; h++ {
+		col := (h - first) * perHour
+		if col < width {
+			axis[col] = '|'
+		}
+		if (h-first)%labelEvery == 0 {
+			copy(labels[col:], fmt.Sprintf("%02d", h))
+		}
+	}
+	fmt.Fprintf(w, "    %s\n", strings.TrimRight(string(labels), " "))
+	fmt.Fprintf(w, "    %s|\n", axis)
+
+	cell := time.Hour / time.Duration(perHour)
+	toCol := func(t time.Time) int {
+		c := int((offset(t) - time.Duration(first)*time.Hour) / cell)
+		if c < 0 {
+			return 0
+		}
+		if c > width {
+			return width
+		}
+		return c
+	}
+	for _, lane := range lanes {
+		row := []byte(strings.Repeat(" ", width))
+		for _, ev := range lane {
+			from, to := toCol(ev.StartTime), toCol(ev.EndTime)
+			if to <= from && from < width {
+				to = from + 1
+			}
+			for c := from; c < to; c++ {
+				row[c] = timelineGlyph(ev)
+			}
+		}
+		fmt.Fprintf(w, "    %s\n", strings.TrimRight(string(row), " "))
+	}
+	for _, ev := range timed {
+		repeat := ""
+		if strings.TrimSpace(ev.RRule) != "" {
+			repeat = " (repeats)"
+		}
+		fmt.Fprintf(w, "    %c %s-%s %s%s\n", timelineGlyph(ev), displayClock(ev.StartTime), displayClock(ev.EndTime), ev.Summary, repeat)
+	}
+}
+
+func printDryRunSummary(records []batchRecord, opts *batchOptions) {
+	fmt.Printf("\n%s\n", ui.T("batch_event_summary"))
+	for i, rec := range records {
+		summary := rec.Summary
+		if summary == "" {
+			summary = ui.T("batch_no_summary")
+		}
+		start := displayRecordTime(rec.Start)
+		if start == "" {
+			start = ui.T("batch_no_start")
+		}
+		line := fmt.Sprintf("  %d. %s - %s", i+1, summary, start)
+		if note := defaultDurationNote(rec, opts); note != "" {
+			line += " " + note
+		}
+		fmt.Println(line)
+		if details := recordDetails(rec); details != "" {
+			fmt.Printf("     %s\n", details)
+		}
+	}
+	fmt.Printf("\n%s\n", ui.T("batch_run_hint"))
+	fmt.Printf("  tempus batch -i %s -o %s\n", opts.input, opts.output)
+}
+
+// recordDetails lists a row's organizer, attendees, priority, status and URL
+// for the dry-run summary; "" when it has none.
+func recordDetails(rec batchRecord) string {
+	var parts []string
+	if o := strings.TrimSpace(rec.Organizer); o != "" {
+		parts = append(parts, ui.T("batch_detail_organizer", o))
+	}
+	if n := len(rec.Attendees); n > 0 {
+		parts = append(parts, ui.T("batch_detail_attendees", n))
+	}
+	if p := strings.TrimSpace(rec.Priority); p != "" {
+		parts = append(parts, ui.T("batch_detail_priority", p))
+	}
+	if s := strings.TrimSpace(rec.Status); s != "" {
+		parts = append(parts, strings.ToLower(s))
+	}
+	if c := strings.TrimSpace(rec.Class); c != "" {
+		parts = append(parts, strings.ToLower(c))
+	}
+	if u := strings.TrimSpace(rec.URL); u != "" {
+		parts = append(parts, u)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// defaultDurationNote tells which default gave a row without end or duration
+// its length, e.g. (45 min from keyword "lunch"), so the defaults can be tuned.
+func defaultDurationNote(rec batchRecord, opts *batchOptions) string {
+	if rec.AllDay || strings.TrimSpace(rec.End) != "" || strings.TrimSpace(rec.Duration) != "" {
+		return ""
+	}
+	startStr := normalizeDateTimeInput(strings.TrimSpace(rec.Start))
+	if looksLikeClock(startStr) {
+		startStr = prependToday(startStr, "")
+	}
+	start, err := time.Parse("2006-01-02 15:04", startStr)
+	if err != nil {
+		return ""
+	}
+	summary, _ := correctSpelling(rec.Summary, opts.corrections)
+	dur, rule, err := defaultBatchDuration(summary, rec.Categories, start)
+	if err != nil {
+		return ""
+	}
+	return ui.T("batch_default_duration", formatSpan(dur), rule)
+}
+
+func writeBatchOutput(cal *calendar.Calendar, warnings []diag.Warning, opts *batchOptions, eventCount int) error {
+	if len(warnings) > 0 {
+		fmt.Printf("\n")
+		diag.Render(os.Stdout, warnings)
+		fmt.Printf("\n")
+	}
+
+	if err := writeBatchICS(cal, opts.output, opts); err != nil {
+		return err
+	}
+
+	if opts.appendOutput {
+		printOK("%s\n", ui.T("batch_appended", eventCount, opts.output))
+		return nil
+	}
+	printOK("%s\n", ui.T("batch_created", opts.output, eventCount))
+	return nil
+}
+
+// writeBatchOutputJSON writes the calendar and prints the run summary (including
+// warnings) as a single JSON document so scripts can consume it.
+func writeBatchOutputJSON(cal *calendar.Calendar, validationErrors, warnings []diag.Warning, opts *batchOptions, people []personCalendar) error {
+	if err := writeBatchICS(cal, opts.output, opts); err != nil {
+		return err
+	}
+
+	summary := summarizeBatch(cal, opts.output)
+	for _, pc := range people {
+		if err := writeBatchICS(pc.cal, pc.path, opts); err != nil {
+			return err
+		}
+		summary.Calendars = append(summary.Calendars, pc.path)
+	}
+	summary.Errors = validationErrors
+	summary.Warnings = warnings
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(summary)
+}
+
+// writeDryRunJSON prints what a batch run would write as a JSON summary,
+// including validation errors, without writing anything.
+func writeDryRunJSON(cal *calendar.Calendar, validationErrors, warnings []diag.Warning, opts *batchOptions, people []string, attendees []personCalendar) error {
+	summary := summarizeBatch(cal, opts.output)
+	for _, person := range people {
+		summary.Calendars = append(summary.Calendars, personOutputPath(opts.output, fileSlug(person, time.Time{})))
+	}
+	for _, ac := range attendees {
+		summary.Calendars = append(summary.Calendars, ac.path)
+	}
+	summary.DryRun = true
+	summary.Errors = validationErrors
+	summary.Warnings = warnings
+	if err := printJSON(os.Stdout, summary); err != nil {
+		return err
+	}
+	if len(validationErrors) > 0 {
+		return fmt.Errorf("validation failed with %d error(s)", len(validationErrors))
+	}
+	return nil
+}
+
+// writeBatchICS writes cal to output in opts.outputFormat, or with --append
+// adds its events to the calendar already there (creating it if missing).
+func writeBatchICS(cal *calendar.Calendar, output string, opts *batchOptions) error {
+	write := func(w io.Writer) error { return writeCalendar(w, cal, opts.outputFormat) }
+	if opts.appendOutput {
+		existing, err := os.ReadFile(output)
+		switch {
+		case err == nil:
+			// Merging needs both calendars in memory.
+			content, err := renderCalendar(cal, opts.outputFormat)
+			if err != nil {
+				return err
+			}
+			merged, _, err := calendar.AppendICS(string(existing), string(content))
+			if err != nil {
+				return fmt.Errorf("cannot append to %s: %w", output, err)
+			}
+			write = func(w io.Writer) error {
+				_, err := io.WriteString(w, merged)
+				return err
+			}
+		case !os.IsNotExist(err):
+			return fmt.Errorf("failed to read %s: %w", output, err)
+		}
+	}
+	if err := writeGeneratedStream(output, opts.policy, write); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	return nil
+}
+
+// checkBatchAppend compares events about to be appended to path with the ones
+// already there: reused UIDs are an error, overlaps are conflict warnings. A
+// missing file is fine, --append creates it.
+func checkBatchAppend(events []calendar.Event, path string) ([]diag.Warning, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	existing, err := calendar.ParseICSEvents(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("cannot append to %s: %w", path, err)
+	}
+
+	byUID := make(map[string]calendar.Event, len(existing))
+	for _, ev := range existing {
+		byUID[ev.UID] = ev
+	}
+	var collisions []string
+	for _, ev := range events {
+		if old, ok := byUID[ev.UID]; ok {
+			collisions = append(collisions, fmt.Sprintf("%s (UID %s is already used by %q)", ev.Summary, ev.UID, old.Summary))
+		}
+	}
+
+	// Sweep the new and existing events together and keep the pairs that
+	// cross over, in row order then file order.
+	all := append(append(make([]calendar.Event, 0, len(events)+len(existing)), events...), existing...)
+	var pairs [][2]int
+	calendar.EachOverlap(all, func(i, j int) bool {
+		if i < len(events) && j >= len(events) {
+			if _, taken := byUID[events[i].UID]; !taken {
+				pairs = append(pairs, [2]int{i, j - len(events)})
+			}
+		}
+		return true
+	})
+	sort.Slice(pairs, func(a, b int) bool {
+		return pairs[a][0] < pairs[b][0] || pairs[a][0] == pairs[b][0] && pairs[a][1] < pairs[b][1]
+	})
+	var warnings []diag.Warning
+	for _, p := range pairs {
+		ev, old := events[p[0]], existing[p[1]]
+		warnings = append(warnings, diag.Warning{
+			Code: diag.CodeConflict, Severity: diag.SeverityWarning, File: path,
+			Message: fmt.Sprintf("%s (%s) overlaps with existing %s (%s)",
+				ev.Summary, ev.StartTime.Format("2006-01-02 15:04"), old.Summary, old.StartTime.Format("2006-01-02 15:04 MST")),
+			Suggestion: "move or shorten one of the events",
+		})
+	}
+	if len(collisions) > 0 {
+		return warnings, fmt.Errorf("cannot append to %s, %d event(s) are already in it:\n  %s\nremove those rows or give them a different uid", path, len(collisions), strings.Join(collisions, "\n  "))
+	}
+	return warnings, nil
+}
+
+// calendarOutputFormat resolves --output-format; "auto" picks jCal for .jcal/.jcs
+// and xCal for .xcs/.xml outputs, ICS otherwise.
+func calendarOutputFormat(flag, output string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(flag)) {
+	case "auto", "":
+		switch strings.ToLower(filepath.Ext(output)) {
+		case ".jcal", ".jcs":
+			return "jcal", nil
+		case ".xcs", ".xml":
+			return "xcal", nil
+		default:
+			return "ics", nil
+		}
+	case "ics":
+		return "ics", nil
+	case "jcal":
+		return "jcal", nil
+	case "xcal":
+		return "xcal", nil
+	default:
+		return "", fmt.Errorf("unsupported output format %q (use ics, jcal or xcal)", flag)
+	}
+}
+
+// renderCalendar serializes cal as ICS, jCal (RFC 7265) or xCal (RFC 6321),
+// for callers that need it in memory; writeCalendar streams it instead.
+func renderCalendar(cal *calendar.Calendar, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeCalendar(&buf, cal, format); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeCalendar writes cal to w as ICS, jCal or xCal. ICS goes out one event
+// at a time; jCal and xCal are built whole first.
+func writeCalendar(w io.Writer, cal *calendar.Calendar, format string) error {
+	applyCategoryColors(cal)
+	var (
+		data []byte
+		err  error
+	)
+	switch format {
+	case "jcal":
+		data, err = cal.ToJCal()
+	case "xcal":
+		data, err = cal.ToXCal()
+	default:
+		return cal.WriteICS(w)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// applyCategoryColors colors the events of cal from category_colors: an
+// event without a color of its own takes the one of its first category that
+// has one. When every event ends up with the same color, the calendar takes
+// it too, as Apple Calendar only colors whole calendars.
+func applyCategoryColors(cal *calendar.Calendar) {
+	cfg, err := config.Current()
+	if err != nil || len(cfg.CategoryColors) == 0 {
+		return
+	}
+	shared := ""
+	for i := range cal.Events {
+		ev := &cal.Events[i]
+		if ev.Color == "" {
+			if _, color, ok := cfg.CategoryColor(ev.Categories); ok {
+				ev.Color, _ = calendar.ParseColor(color)
+			}
+		}
+		if i == 0 {
+			shared = ev.Color
+		} else if ev.Color != shared {
+			shared = ""
+		}
+	}
+	if cal.Color == "" {
+		cal.Color = shared
+	}
+}
+
+// splitPeople parses a people cell such as "Ana+Luis" (commas, semicolons and
+// pipes also separate names). Duplicates are dropped case-insensitively.
+func splitPeople(s string) []string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '+' || r == ',' || r == ';' || r == '|'
+	})
+	var people []string
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" && !slices.ContainsFunc(people, func(q string) bool { return strings.EqualFold(p, q) }) {
+			people = append(people, p)
+		}
+	}
+	return people
+}
+
+// batchPeople lists everyone named in a people column, in order of first appearance.
+func batchPeople(records []batchRecord) []string {
+	var people []string
+	for _, rec := range records {
+		for _, p := range rec.People {
+			if !slices.ContainsFunc(people, func(q string) bool { return strings.EqualFold(p, q) }) {
+				people = append(people, p)
+			}
+		}
+	}
+	return people
+}
+
+// personCalendar is one person's share of a family batch.
+type personCalendar struct {
+	person string
+	path   string
+	cal    *calendar.Calendar
+}
+
+// buildPeopleCalendars builds one calendar per person named in the people
+// column: rows naming them plus rows naming nobody (family-wide events).
+// Row errors were already reported for the combined calendar and are skipped here.
+func buildPeopleCalendars(records []batchRecord, opts *batchOptions) ([]personCalendar, error) {
+	people := batchPeople(records)
+	out := make([]personCalendar, 0, len(people))
+	for _, person := range people {
+		var subset []batchRecord
+		for _, rec := range records {
+			if len(rec.People) == 0 || slices.ContainsFunc(rec.People, func(p string) bool { return strings.EqualFold(p, person) }) {
+				subset = append(subset, rec)
+			}
+		}
+
+		personOpts := *opts
+		personOpts.fixInteractive = false
+		personOpts.name = person
+		personOpts.meta.UID, personOpts.meta.Source = "", "" // they describe the combined calendar
+		if name := strings.TrimSpace(opts.name); name != "" {
+			personOpts.name = name + " – " + person
+		}
+		cal, _, err := buildBatchCalendar(subset, &personOpts)
+		if err != nil {
+			return nil, err
+		}
+		applyDayFilter(cal.Events, opts.days)
+
+		// The same event lives in several calendars; keep UIDs distinct so
+		// clients subscribed to more than one don't merge them.
+		slug := fileSlug(person, time.Time{})
+		for i := range cal.Events {
+			cal.Events[i].UID = suffixUID(cal.Events[i].UID, slug)
+		}
+		out = append(out, personCalendar{person: person, path: personOutputPath(opts.output, slug), cal: cal})
+	}
+	return out, nil
+}
+
+// personOutputPath turns family.ics into family-ana.ics.
+func personOutputPath(output, slug string) string {
+	ext := filepath.Ext(output)
+	return strings.TrimSuffix(output, ext) + "-" + slug + ext
+}
+
+// configureUIDs makes new UIDs follow uid_template and uid_domain. Invalid
+// values are reported and left out, so a typo cannot stop `config set` from
+// fixing it.
+func configureUIDs(cfg *config.Config, cfgErr error) {
+	calendar.SetUIDFormat("", "")
+	if cfgErr != nil {
+		return
+	}
+	if err := config.ValidateUIDFormat(cfg.UIDTemplate, cfg.UIDDomain); err != nil {
+		output.Warn(os.Stderr, "%v; UIDs use the default %s\n", err, constants.DefaultUIDTemplate)
+		return
+	}
+	calendar.SetUIDFormat(cfg.UIDTemplate, cfg.UIDDomain)
+}
+
+// suffixUID adds "-suffix" to the part of uid before its domain.
+func suffixUID(uid, suffix string) string {
+	if local, domain, ok := strings.Cut(uid, "@"); ok {
+		return local + "-" + suffix + "@" + domain
+	}
+	return uid + "-" + suffix
+}
+
+// attendeePlaceholder matches the {{name}} placeholders of --attendee-file.
+var attendeePlaceholder = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// checkAttendeeFile rejects --attendee-file templates with unknown
+// placeholders or without one naming the person, which would put everyone
+// in the same file.
+func checkAttendeeFile(tmpl string) error {
+	personal := false
+	for _, m := range attendeePlaceholder.FindAllStringSubmatch(tmpl, -1) {
+		switch m[1] {
+		case "attendee", "email":
+			personal = true
+		case "month", "year":
+		default:
+			return fmt.Errorf("unknown placeholder {{%s}} in --attendee-file (use {{attendee}}, {{email}}, {{month}} or {{year}})", m[1])
+		}
+	}
+	if !personal {
+		return fmt.Errorf("--attendee-file %q needs {{attendee}} or {{email}} so each person gets their own file", tmpl)
+	}
+	return nil
+}
+
+// batchAttendee is one person found in a batch's --attendee-column.
+type batchAttendee struct {
+	email string // lower-cased address, the person's identity
+	name  string // display name from "Ana <ana@example.com>", if any
+}
+
+// label is how the person is named in messages and calendar names.
+func (a batchAttendee) label() string {
+	return firstNonEmpty(a.name, a.email)
+}
+
+// rowAttendees returns the people in rec's attendee column. Invalid
+// addresses are skipped; they were already reported as row errors.
+func rowAttendees(rec batchRecord, column string) []batchAttendee {
+	cells := rec.Attendees
+	if column == "organizer" {
+		cells = []string{rec.Organizer}
+	}
+	var out []batchAttendee
+	for _, cell := range cells {
+		if strings.TrimSpace(cell) == "" {
+			continue
+		}
+		addr, err := batchEmail(cell)
+		if err != nil {
+			continue
+		}
+		a := batchAttendee{email: strings.ToLower(addr)}
+		if name, _, ok := strings.Cut(cell, "<"); ok {
+			a.name = strings.Trim(strings.TrimSpace(name), `"`)
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// attendeeFilePath fills in an --attendee-file template for one person and
+// the start of one of their events.
+func attendeeFilePath(tmpl string, a batchAttendee, start time.Time) string {
+	local, _, _ := strings.Cut(a.email, "@")
+	return attendeePlaceholder.ReplaceAllStringFunc(tmpl, func(m string) string {
+		switch attendeePlaceholder.FindStringSubmatch(m)[1] {
+		case "attendee":
+			return fileSlug(firstNonEmpty(a.name, local), time.Time{})
+		case "email":
+			return fileSlug(a.email, time.Time{})
+		case "month":
+			return start.Format("2006-01")
+		default: // year
+			return start.Format("2006")
+		}
+	})
+}
+
+// buildAttendeeCalendars builds, for --per-attendee, one calendar per
+// person in the attendee column holding only the rows that name them, split
+// further by {{month}} or {{year}} in --attendee-file. Recurring events are
+// filed under the month of their first occurrence. UIDs are kept: it is the
+// same meeting in everyone's calendar.
+func buildAttendeeCalendars(records []batchRecord, opts *batchOptions) ([]personCalendar, error) {
+	var people []batchAttendee
+	rows := make(map[string][]batchRecord)
+	for _, rec := range records {
+		for _, a := range rowAttendees(rec, opts.attendeeColumn) {
+			if _, seen := rows[a.email]; !seen {
+				people = append(people, a)
+			}
+			rows[a.email] = append(rows[a.email], rec)
+		}
+	}
+
+	tmpl := opts.attendeeFile
+	if tmpl == "" {
+		ext := filepath.Ext(opts.output)
+		tmpl = strings.TrimSuffix(filepath.Base(opts.output), ext) + "-{{attendee}}" + ext
+	}
+	owners := make(map[string]string) // path → email, so two people never share a file
+	var out []personCalendar
+	for _, a := range people {
+		personOpts := *opts
+		personOpts.fixInteractive = false
+		personOpts.name = a.label()
+		personOpts.meta.UID, personOpts.meta.Source = "", "" // they describe the combined calendar
+		if name := strings.TrimSpace(opts.name); name != "" {
+			personOpts.name = name + " – " + a.label()
+		}
+		cal, _, err := buildBatchCalendar(rows[a.email], &personOpts)
+		if err != nil {
+			return nil, err
+		}
+		applyDayFilter(cal.Events, opts.days)
+
+		var paths []string
+		files := make(map[string][]calendar.Event)
+		for _, ev := range cal.Events {
+			path := attendeeFilePath(tmpl, a, ev.StartTime)
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(filepath.Dir(opts.output), path)
+			}
+			if _, ok := files[path]; !ok {
+				paths = append(paths, path)
+			}
+			files[path] = append(files[path], ev)
+		}
+		for _, path := range paths {
+			if owner, taken := owners[path]; taken && owner != a.email {
+				return nil, fmt.Errorf("%s and %s would both be written to %s; add {{email}} to --attendee-file", owner, a.email, path)
+			}
+			owners[path] = a.email
+			part := *cal
+			part.Events = files[path]
+			out = append(out, personCalendar{person: a.label(), path: path, cal: &part})
+		}
+	}
+	return out, nil
+}
+
+// withPeopleNotes returns records whose descriptions end with who each
+// event is for, so the combined family calendar shows it. Names are spelled
+// as in people (the first spelling seen).
+func withPeopleNotes(records []batchRecord, people []string) []batchRecord {
+	out := make([]batchRecord, len(records))
+	for i, rec := range records {
+		if len(rec.People) > 0 {
+			names := make([]string, len(rec.People))
+			for j, p := range rec.People {
+				names[j] = p
+				if k := slices.IndexFunc(people, func(q string) bool { return strings.EqualFold(p, q) }); k >= 0 {
+					names[j] = people[k]
+				}
+			}
+			rec.People = names
+			note := "👥 " + strings.Join(names, ", ")
+			rec.Description = strings.TrimSpace(rec.Description + "\n\n" + note)
+		}
+		out[i] = rec
+	}
+	return out
+}
+
+// batchSummary is the compact post-run report printed after a batch write.
+type batchSummary struct {
+	Calendars  []string       `json:"calendars"`
+	Events     int            `json:"events"`
+	Alarms     int            `json:"alarms"`
+	FirstDay   string         `json:"first_day,omitempty"`
+	LastDay    string         `json:"last_day,omitempty"`
+	Categories map[string]int `json:"categories"`
+	DryRun     bool           `json:"dry_run,omitempty"`
+	Errors     []diag.Warning `json:"errors,omitempty"`
+	Warnings   []diag.Warning `json:"warnings,omitempty"`
+}
+
+const uncategorizedLabel = "(none)"
+
+func summarizeBatch(cal *calendar.Calendar, output string) batchSummary {
+	summary := batchSummary{
+		Calendars:  []string{output},
+		Events:     len(cal.Events),
+		Categories: make(map[string]int),
+	}
+
+	var first, last time.Time
+	for _, ev := range cal.Events {
+		summary.Alarms += len(ev.Alarms)
+		if len(ev.Categories) == 0 {
+			summary.Categories[uncategorizedLabel]++
+		}
+		for _, cat := range ev.Categories {
+			summary.Categories[cat]++
+		}
+		if first.IsZero() || ev.StartTime.Before(first) {
+			first = ev.StartTime
+		}
+		if last.IsZero() || ev.StartTime.After(last) {
+			last = ev.StartTime
+		}
+	}
+	if !first.IsZero() {
+		summary.FirstDay = first.Format(constants.DateFormatISO)
+		summary.LastDay = last.Format(constants.DateFormatISO)
+	}
+	return summary
+}
+
+func printBatchSummary(summary batchSummary) {
+	if output.Quiet() {
+		return
+	}
+	days := "-"
+	if summary.FirstDay != "" {
+		days = summary.FirstDay
+		if summary.LastDay != summary.FirstDay {
+			days = fmt.Sprintf("%s → %s", summary.FirstDay, summary.LastDay)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("  %-11s %d\n", ui.T("summary_events"), summary.Events)
+	fmt.Printf("  %-11s %s\n", ui.T("summary_days"), days)
+	fmt.Printf("  %-11s %d\n", ui.T("summary_alarms"), summary.Alarms)
+	fmt.Printf("  %-11s %d\n", ui.T("summary_calendars"), len(summary.Calendars))
+
+	if len(summary.Categories) == 0 {
+		return
+	}
+	names := make([]string, 0, len(summary.Categories))
+	width := 0
+	for name := range summary.Categories {
+		names = append(names, name)
+		if l := utf8.RuneCountInString(name); l > width {
+			width = l
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		ci, cj := summary.Categories[names[i]], summary.Categories[names[j]]
+		if ci == cj {
+			return names[i] < names[j]
+		}
+		return ci > cj
+	})
+
+	fmt.Printf("  %s\n", ui.T("summary_categories"))
+	for _, name := range names {
+		fmt.Printf("    %-*s  %d\n", width, name, summary.Categories[name])
+	}
+}
+
+func newLintCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Validate ICS files for common issues",
+		RunE:  runLint,
+	}
+	cmd.Flags().StringArray("file", []string{}, "ICS file(s) to lint (repeat flag for multiple files)")
+	cmd.Flags().String("format", "text", "Output format: text, json or sarif")
+	cmd.Flags().Bool("strict", false, "Also check property grammars: dates, RRULE parts, durations, text escaping, UTF-8 and CRLF line endings")
+	return cmd
+}
+
+func runLint(cmd *cobra.Command, _ []string) error {
+	paths, _ := cmd.Flags().GetStringArray("file")
+	if len(paths) == 0 {
+		return fmt.Errorf("--file is required (repeat flag for multiple files)")
+	}
+	format, err := lintFormat(cmd)
+	if err != nil {
+		return err
+	}
+	strict, _ := cmd.Flags().GetBool("strict")
+
+	var all []diag.Warning
+	failed := 0
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		findings, err := lintICS(path)
+		if err != nil {
+			findings = []diag.Warning{{Code: diag.CodeICSStructure, Severity: diag.SeverityError, File: path, Message: err.Error()}}
+		} else if strict {
+			findings = append(findings, lintGrammar(path)...)
+		}
+		all = append(all, findings...)
+		if diag.HasErrors(findings) {
+			failed++
+		}
+		if format == "text" {
+			if len(findings) > 0 {
+				diag.Render(cmd.OutOrStdout(), findings)
+			}
+			if !diag.HasErrors(findings) {
+				printOK("%s\n", ui.T("lint_passed", path))
+			}
+		}
+	}
+
+	if err := writeLintReport(cmd.OutOrStdout(), format, all); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("lint failed: %d error(s) in %d file(s)", diag.Count(all, diag.SeverityError), failed)
+	}
+	return nil
+}
+
+// lintFormat reads --format (text, json or sarif); --output-format json
+// selects json when --format is not given.
+func lintFormat(cmd *cobra.Command) (string, error) {
+	format, _ := cmd.Flags().GetString("format")
+	if !cmd.Flags().Changed("format") && jsonReport(cmd) {
+		format = "json"
+	}
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format != "text" && format != "json" && format != "sarif" {
+		return "", fmt.Errorf("invalid --format %q (use text, json or sarif)", format)
+	}
+	return format, nil
+}
+
+// writeLintReport writes the json or sarif report; text was printed as it went.
+func writeLintReport(w io.Writer, format string, warnings []diag.Warning) error {
+	switch format {
+	case "json":
+		return diag.WriteJSON(w, warnings)
+	case "sarif":
+		return diag.WriteSARIF(w, version, warnings)
+	}
+	return nil
+}
+
+func newShiftCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "shift",
+		Short: "Bulk-reschedule events in an ICS or batch file",
+		Long: `Move all (or filtered) events of an .ics file or a batch file (CSV/JSON/YAML)
+by a time delta and/or to another timezone. DTSTART, DTEND, EXDATE, RRULE UNTIL
+and absolute alarms move together and SEQUENCE is bumped so calendar clients
+treat the result as an update.`,
+		Example: `  tempus shift -i trip.ics --days +7
+  tempus shift -i sprint.ics --by -30m --filter category=Work -o sprint-moved.ics
+  tempus shift -i year.ics --days +1 --filter-category Work/ClientA
+  tempus shift -i trip.ics --to-tz America/New_York --in-place`,
+		RunE: runShift,
+	}
+	cmd.Flags().StringP("input", "i", "", "Input .ics or batch file (csv/json/yaml)")
+	cmd.Flags().StringP("output", "o", "", "Output ICS file (default: <input>-shifted.ics)")
+	cmd.Flags().Bool("in-place", false, "Overwrite the input .ics file")
+	cmd.Flags().String("by", "", "Time delta, e.g. 1h, -30m, +2h15m")
+	cmd.Flags().Int("days", 0, "Calendar days to move, e.g. 7 or -1 (wall-clock time is kept across DST)")
+	cmd.Flags().String("to-tz", "", "Re-express events in this timezone (keeps wall-clock time)")
+	cmd.Flags().Bool("keep-instant", false, "With --to-tz, keep the moment in time instead of the wall-clock time")
+	cmd.Flags().StringArray("filter", []string{}, "Only shift matching events: category=, summary=, location= or uid= (repeatable, all must match)")
+	cmd.Flags().StringArray("filter-category", []string{}, "Only shift events in this category or below it, e.g. Work matches Work/ClientA (repeatable, any may match)")
+	cmd.Flags().String("format", "auto", "Batch input format: auto, csv, json, yaml, timetable")
+	cmd.Flags().String("default-tz", "", "Default timezone for batch rows without one")
+	cmd.Flags().Bool("dry-run", false, "Show what would move without writing")
+	return cmd
+}
+
+func runShift(cmd *cobra.Command, _ []string) error {
+	input, _ := cmd.Flags().GetString("input")
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return fmt.Errorf("--input is required")
+	}
+
+	shift, err := parseShiftFlags(cmd)
+	if err != nil {
+		return err
+	}
+	filterSpecs, _ := cmd.Flags().GetStringArray("filter")
+	filter, err := parseShiftFilters(filterSpecs)
+	filter.under, _ = cmd.Flags().GetStringArray("filter-category")
+	if err != nil {
+		return err
+	}
+
+	output, _ := cmd.Flags().GetString("output")
+	inPlace, _ := cmd.Flags().GetBool("in-place")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	isICS := strings.EqualFold(filepath.Ext(input), ".ics")
+	output, err = resolveRewriteOutput(input, output, inPlace, "-shifted")
+	if err != nil {
+		return err
+	}
+
+	var (
+		ics     string
+		changes []calendar.ShiftedEvent
+	)
+	if isICS {
+		data, err := os.ReadFile(filepath.Clean(input))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", input, err)
+		}
+		ics, changes, err = calendar.ShiftICS(string(data), shift, filter.matchICS)
+		if err != nil {
+			return err
+		}
+	} else {
+		formatFlag, _ := cmd.Flags().GetString("format")
+		defaultTZ, _ := cmd.Flags().GetString("default-tz")
+		ics, changes, err = shiftBatchFile(input, formatFlag, defaultTZ, shift, filter)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("No events matched; nothing to shift.")
+		return nil
+	}
+	for _, c := range changes {
+		fmt.Printf("  • %s: %s → %s\n", shiftLabel(c), c.OldStart, c.NewStart)
+	}
+	if dryRun {
+		fmt.Printf("Dry run: %d event(s) would be shifted (nothing written)\n", len(changes))
+		return nil
+	}
+
+	if err := ensureDirForFile(output); err != nil {
+		return err
+	}
+	if err := os.WriteFile(output, []byte(ics), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	printOK("Shifted %d event(s): %s\n", len(changes), output)
+	return nil
+}
+
+// resolveRewriteOutput picks where a command that rewrites an .ics writes to:
+// the input itself with --in-place, --output if given, else <input><suffix>.ics.
+func resolveRewriteOutput(input, output string, inPlace bool, suffix string) (string, error) {
+	switch {
+	case inPlace && strings.TrimSpace(output) != "":
+		return "", fmt.Errorf("use either --in-place or --output, not both")
+	case inPlace && !strings.EqualFold(filepath.Ext(input), ".ics"):
+		return "", fmt.Errorf("--in-place only works with .ics input")
+	case inPlace:
+		return input, nil
+	case strings.TrimSpace(output) == "":
+		return strings.TrimSuffix(input, filepath.Ext(input)) + suffix + ".ics", nil
+	}
+	return output, nil
+}
+
+func parseShiftFlags(cmd *cobra.Command) (calendar.Shift, error) {
+	var shift calendar.Shift
+	shift.Days, _ = cmd.Flags().GetInt("days")
+	shift.KeepInstant, _ = cmd.Flags().GetBool("keep-instant")
+
+	if by, _ := cmd.Flags().GetString("by"); strings.TrimSpace(by) != "" {
+		d, err := parseSignedDuration(by)
+		if err != nil {
+			return shift, fmt.Errorf("invalid --by: %w", err)
+		}
+		shift.By = d
+	}
+	if tz, _ := cmd.Flags().GetString("to-tz"); strings.TrimSpace(tz) != "" {
+		tz, warnings := canonicalTimezone(strings.TrimSpace(tz), "--to-tz")
+		if _, err := time.LoadLocation(tz); err != nil {
+			return shift, fmt.Errorf("invalid --to-tz %q: %w", tz, err)
+		}
+		diag.Render(cmd.ErrOrStderr(), warnings)
+		shift.ToTZ = tz
+	}
+	if shift.IsZero() {
+		return shift, fmt.Errorf("nothing to do: pass --by, --days and/or --to-tz")
+	}
+	if shift.KeepInstant && shift.ToTZ == "" {
+		return shift, fmt.Errorf("--keep-instant requires --to-tz")
+	}
+	return shift, nil
+}
+
+// parseSignedDuration accepts a human duration with an optional leading + or -.
+func parseSignedDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign, s = -1, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	d, err := calendar.ParseHumanDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	return sign * d, nil
+}
+
+// shiftFilter selects the events `tempus shift` touches; all set fields must match.
+type shiftFilter struct {
+	category string   // exact, case-insensitive
+	summary  string   // substring, case-insensitive
+	location string   // substring, case-insensitive
+	uid      string   // exact
+	under    []string // --filter-category: the category or one below it
+}
+
+func parseShiftFilters(specs []string) (shiftFilter, error) {
+	var f shiftFilter
+	for _, spec := range specs {
+		key, value, ok := strings.Cut(spec, "=")
+		value = strings.TrimSpace(value)
+		if !ok || value == "" {
+			return f, fmt.Errorf("invalid --filter %q (want key=value)", spec)
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "category", "categories":
+			f.category = value
+		case "summary", "title":
+			f.summary = strings.ToLower(value)
+		case "location":
+			f.location = strings.ToLower(value)
+		case "uid":
+			f.uid = value
+		default:
+			return f, fmt.Errorf("unknown --filter key %q (use category, summary, location or uid)", key)
+		}
+	}
+	return f, nil
+}
+
+func (f shiftFilter) matchICS(info calendar.ICSEventInfo) bool {
+	return f.match(info.UID, info.Summary, info.Location, info.Categories)
+}
+
+func (f shiftFilter) match(uid, summary, location string, categories []string) bool {
+	if f.uid != "" && uid != f.uid {
+		return false
+	}
+	if f.summary != "" && !strings.Contains(strings.ToLower(summary), f.summary) {
+		return false
+	}
+	if f.location != "" && !strings.Contains(strings.ToLower(location), f.location) {
+		return false
+	}
+	if !calendar.AnyCategoryUnder(categories, f.under) {
+		return false
+	}
+	if f.category != "" {
+		for _, c := range categories {
+			if strings.EqualFold(strings.TrimSpace(c), f.category) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// shiftBatchFile builds the calendar for a batch file and shifts the matching events.
+func shiftBatchFile(input, formatFlag, defaultTZ string, shift calendar.Shift, filter shiftFilter) (string, []calendar.ShiftedEvent, error) {
+	opts := &batchOptions{input: input, formatFlag: formatFlag, defaultTZ: defaultTZ}
+	records, _, err := loadBatchInput(opts)
+	if err != nil {
+		return "", nil, err
+	}
+	cal, validationErrors, err := buildBatchCalendar(records, opts)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(validationErrors) > 0 {
+		return "", nil, fmt.Errorf("%s has invalid rows:\n  %s", input, strings.Join(diag.Messages(validationErrors), "\n  "))
+	}
+
+	var changes []calendar.ShiftedEvent
+	for i := range cal.Events {
+		ev := &cal.Events[i]
+		if !filter.match(ev.UID, ev.Summary, ev.Location, ev.Categories) {
+			continue
+		}
+		before := describeEventStart(ev)
+		if err := ev.ApplyShift(shift); err != nil {
+			return "", nil, fmt.Errorf("event %q: %w", ev.Summary, err)
+		}
+		changes = append(changes, calendar.ShiftedEvent{UID: ev.UID, Summary: ev.Summary, OldStart: before, NewStart: describeEventStart(ev)})
+	}
+	if shift.ToTZ != "" && len(changes) == len(cal.Events) {
+		cal.SetDefaultTimezone(shift.ToTZ)
+	}
+	return cal.ToICS(), changes, nil
+}
+
+func describeEventStart(ev *calendar.Event) string {
+	if ev.AllDay {
+		return ev.StartTime.Format(constants.DateFormatISO)
+	}
+	if tz := strings.TrimSpace(ev.StartTZ); tz != "" {
+		return ev.StartTime.Format(constants.DateTimeFormatISO) + " " + tz
+	}
+	return ev.StartTime.UTC().Format(constants.DateTimeFormatISO) + " UTC"
+}
+
+func shiftLabel(c calendar.ShiftedEvent) string {
+	if strings.TrimSpace(c.Summary) != "" {
+		return c.Summary
+	}
+	if c.UID != "" {
+		return c.UID
+	}
+	return "(untitled)"
+}
+
+func newCancelCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cancel",
+		Short: "Write a cancellation that removes events from the calendars they were sent to",
+		Long: `Build a METHOD:CANCEL calendar for events of an .ics file you sent out.
+Each selected event keeps its UID, gets STATUS:CANCELLED and a higher SEQUENCE,
+so importing the file (or mailing it to the attendees) removes the event
+instead of adding a copy. A series takes its moved occurrences along.`,
+		Example: `  tempus cancel -i team.ics --uid standup-2026@tempus -o standup-cancel.ics
+  tempus cancel -i trip.ics --filter summary=flight
+  tempus cancel -i course.ics --all --dry-run`,
+		RunE: runCancel,
+	}
+	cmd.Flags().StringP("input", "i", "", "The .ics file the events were sent or imported from")
+	cmd.Flags().StringP("output", "o", "", "Output ICS file (default: <input>-cancel.ics)")
+	cmd.Flags().StringArray("uid", []string{}, "UID of an event to cancel (repeatable)")
+	cmd.Flags().StringArray("filter", []string{}, "Cancel matching events: category=, summary=, location= or uid= (repeatable, all must match)")
+	cmd.Flags().StringArray("filter-category", []string{}, "Cancel events in this category or below it (repeatable, any may match)")
+	cmd.Flags().Bool("all", false, "Cancel every event in the file")
+	cmd.Flags().Bool("dry-run", false, "Show what would be cancelled without writing")
+	return cmd
+}
+
+func runCancel(cmd *cobra.Command, _ []string) error {
+	input, _ := cmd.Flags().GetString("input")
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return fmt.Errorf("--input is required")
+	}
+	if !strings.EqualFold(filepath.Ext(input), ".ics") {
+		return fmt.Errorf("--input must be an .ics file: a cancellation needs the UIDs the events were sent with")
+	}
+
+	uids, _ := cmd.Flags().GetStringArray("uid")
+	filterSpecs, _ := cmd.Flags().GetStringArray("filter")
+	filter, err := parseShiftFilters(filterSpecs)
+	if err != nil {
+		return err
+	}
+	filter.under, _ = cmd.Flags().GetStringArray("filter-category")
+	all, _ := cmd.Flags().GetBool("all")
+	selective := len(uids) > 0 || len(filterSpecs) > 0 || len(filter.under) > 0
+	switch {
+	case all && selective:
+		return fmt.Errorf("--all cancels every event; drop --uid and --filter, or --all")
+	case !all && !selective:
+		return fmt.Errorf("choose the events to cancel with --uid, --filter or --filter-category (or --all)")
+	}
+	match := func(info calendar.ICSEventInfo) bool {
+		if all || slices.Contains(uids, info.UID) {
+			return true
+		}
+		return (len(filterSpecs) > 0 || len(filter.under) > 0) && filter.matchICS(info)
+	}
+
+	data, err := os.ReadFile(filepath.Clean(input))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", input, err)
+	}
+	ics, cancelled, err := calendar.CancelICS(string(data), match)
+	if err != nil {
+		return err
+	}
+	if len(cancelled) == 0 {
+		return fmt.Errorf("no events in %s matched; nothing to cancel", input)
+	}
+
+	noOrganizer := 0
+	for _, c := range cancelled {
+		fmt.Printf("  • %s\n", c)
+		if !c.Organizer {
+			noOrganizer++
+		}
+	}
+	if noOrganizer > 0 {
+		output.Warn(os.Stderr, "%s\n", ui.T("cancel_no_organizer", noOrganizer))
+	}
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if dryRun {
+		fmt.Println(ui.T("cancel_dry_run", len(cancelled)))
+		return nil
+	}
+
+	out, _ := cmd.Flags().GetString("output")
+	if out, err = resolveRewriteOutput(input, out, false, "-cancel"); err != nil {
+		return err
+	}
+	policy, err := loadOutputPolicy()
+	if err != nil {
+		return err
+	}
+	if err := writeGeneratedFile(out, []byte(ics), policy); err != nil {
+		return fmt.Errorf("failed to write %s: %w", out, err)
+	}
+	printOK("%s\n", ui.T("cancel_written", len(cancelled), out))
+	return nil
+}
+
+func newSplitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "split",
+		Short: "Change a recurring event from a date on (\"this and following\")",
+		Long: `Split a recurring event of an .ics file in two: the original series ends the
+day before --at and a new series takes over from the first occurrence on or
+after it, with the changes you pass. Re-import the file to apply both halves.`,
+		Example: `  tempus split -i yoga.ics --at 2026-01-10 --time 18:30 --location "Studio B"
+  tempus split -i team.ics --uid standup@tempus --at 2026-02-02 --rrule "FREQ=WEEKLY;BYDAY=MO,WE" --in-place`,
+		RunE: runSplit,
+	}
+	cmd.Flags().StringP("input", "i", "", "Input .ics file")
+	cmd.Flags().StringP("output", "o", "", "Output ICS file (default: <input>-split.ics)")
+	cmd.Flags().Bool("in-place", false, "Overwrite the input .ics file")
+	cmd.Flags().String("at", "", "First day of the new series (YYYY-MM-DD)")
+	cmd.Flags().String("uid", "", "UID of the series to split (needed when the file has several)")
+	cmd.Flags().String("time", "", "New start time (HH:MM, in the series' timezone)")
+	cmd.Flags().String("duration", "", "New length, e.g. 45m or 1h30m")
+	cmd.Flags().String("rrule", "", "New recurrence rule for the new series")
+	cmd.Flags().String("summary", "", "New title")
+	cmd.Flags().String("location", "", "New location")
+	cmd.Flags().String("description", "", "New description")
+	cmd.Flags().Bool("dry-run", false, "Show the split without writing")
+	_ = cmd.MarkFlagRequired("at")
+	return cmd
+}
+
+func runSplit(cmd *cobra.Command, _ []string) error {
+	input, _ := cmd.Flags().GetString("input")
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return fmt.Errorf("--input is required")
+	}
+	if !strings.EqualFold(filepath.Ext(input), ".ics") {
+		return fmt.Errorf("--input must be an .ics file")
+	}
+
+	var split calendar.SeriesSplit
+	at, _ := cmd.Flags().GetString("at")
+	day, err := time.Parse(constants.DateFormatISO, strings.TrimSpace(at))
+	if err != nil {
+		return fmt.Errorf("invalid --at %q (use YYYY-MM-DD)", at)
+	}
+	split.At = day
+	split.UID, _ = cmd.Flags().GetString("uid")
+	split.Clock, _ = cmd.Flags().GetString("time")
+	split.RRule, _ = cmd.Flags().GetString("rrule")
+	split.Summary, _ = cmd.Flags().GetString("summary")
+	split.Location, _ = cmd.Flags().GetString("location")
+	split.Description, _ = cmd.Flags().GetString("description")
+	if d, _ := cmd.Flags().GetString("duration"); strings.TrimSpace(d) != "" {
+		if split.Duration, err = calendar.ParseHumanDuration(d); err != nil || split.Duration <= 0 {
+			return fmt.Errorf("invalid --duration %q", d)
+		}
+	}
+	if split.Clock == "" && split.Duration == 0 && split.RRule == "" && split.Summary == "" && split.Location == "" && split.Description == "" {
+		return fmt.Errorf("nothing to change: pass --time, --duration, --rrule, --summary, --location or --description")
+	}
+
+	output, _ := cmd.Flags().GetString("output")
+	inPlace, _ := cmd.Flags().GetBool("in-place")
+	if output, err = resolveRewriteOutput(input, output, inPlace, "-split"); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(filepath.Clean(input))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", input, err)
+	}
+	ics, res, err := calendar.SplitICS(string(data), split)
+	if err != nil {
+		return err
+	}
+
+	label := shiftLabel(calendar.ShiftedEvent{UID: res.UID, Summary: res.Summary})
+	fmt.Println(ui.T("split_old", label, res.Kept, day.AddDate(0, 0, -1).Format(constants.DateFormatISO)))
+	fmt.Println(ui.T("split_new", res.NewStart, res.NewUID))
+	if res.Overrides > 0 {
+		fmt.Println(ui.T("split_overrides", res.Overrides))
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		fmt.Println(ui.T("split_dry_run"))
+		return nil
+	}
+
+	policy, err := loadOutputPolicy()
+	if err != nil {
+		return err
+	}
+	if err := writeGeneratedFile(output, []byte(ics), policy); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	printOK("%s\n", ui.T("split_written", output))
+	return nil
+}
+
+// journalOp is the running command's entry in the undo journal; it stays
+// zero (and nothing is journaled) when commands run outside the CLI.
+var journalOp journal.Op
+
+// appClock is where commands read the current time ("today", rota starts,
+// countdowns) and outputFS is where batch and template create write their
+// files. Tests and embedders can swap in clock.Fixed or fsys.NewMemory.
+var (
+	appClock clock.Clock = clock.System
+	outputFS fsys.FS     = fsys.OS
+)
+
+// setClock points every time source (commands, calendar stamps, "today"
+// for clock-only times) at c; nil restores the system clock.
+func setClock(c clock.Clock) {
+	if c == nil {
+		c = clock.System
+	}
+	appClock = c
+	calendar.SetClock(c)
+	normalizer.SetClock(c)
+}
+
+// writeGeneratedFile writes a generated calendar with the permissions of
+// policy and records the write in the undo journal. A journal failure is only
+// a warning: the calendar is written.
+func writeGeneratedFile(path string, content []byte, policy config.OutputPolicy) error {
+	return writeGeneratedStream(path, policy, func(w io.Writer) error {
+		_, err := w.Write(content)
+		return err
+	})
+}
+
+// writeGeneratedStream is writeGeneratedFile for content written straight to
+// the file by write, so a large calendar is never held in memory; the
+// journal gets its hash as it goes by. write fills a temporary file next to
+// path that replaces it only once complete, so a failed write leaves the old
+// file as it was.
+func writeGeneratedStream(path string, policy config.OutputPolicy, write func(io.Writer) error) error {
+	if policy == (config.OutputPolicy{}) {
+		policy = config.DefaultOutputPolicy()
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := outputFS.MkdirAll(dir, policy.DirMode); err != nil {
+			return err
+		}
+	}
+	perm, mode, existed := policy.FileMode, os.FileMode(0), false
+	if info, err := outputFS.Stat(path); err == nil {
+		mode, existed = info.Mode(), true
+		perm = mode.Perm()
+	}
+	// Like any new file the temporary one is subject to umask; it takes the
+	// mode of the file it replaces, or policy's for a new one.
+	tmp := filepath.Join(filepath.Dir(path), fmt.Sprintf(".%s.%d.tmp", filepath.Base(path), os.Getpid()))
+	f, err := outputFS.Create(tmp, perm)
+	if err != nil {
+		return err
+	}
+	hash := sha256.New()
+	err = write(io.MultiWriter(f, hash))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = outputFS.Remove(tmp)
+		return err
+	}
+
+	// The replaced content goes to the journal before the rename drops it.
+	var j *journal.Journal
+	var backup string
+	var journalErr error
+	if journalOp.ID != "" {
+		if j, journalErr = journal.Default(); journalErr == nil && existed {
+			backup, journalErr = backupGeneratedFile(j, path)
+		}
+	}
+	if err := outputFS.Rename(tmp, path); err != nil {
+		_ = outputFS.Remove(tmp)
+		return err
+	}
+	if journalOp.ID == "" {
+		return nil
+	}
+	if journalErr == nil {
+		journalErr = j.RecordHash(journalOp, path, backup, mode, hex.EncodeToString(hash.Sum(nil)))
+	}
+	if journalErr != nil {
+		output.Warn(os.Stderr, "%s was written but not recorded for undo: %v\n", path, journalErr)
+	}
+	return nil
+}
+
+// backupGeneratedFile streams the current content of path into the journal.
+func backupGeneratedFile(j *journal.Journal, path string) (string, error) {
+	r, err := outputFS.OpenReader(path)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	return j.Backup(r)
+}
+
+// loadOutputPolicy reads the output.* config keys. An unreadable config means
+// the defaults, but an invalid output value is an error: guessing could
+// overwrite a file the user asked tempus to keep.
+func loadOutputPolicy() (config.OutputPolicy, error) {
+	cfg, err := config.Current()
+	if err != nil || cfg == nil {
+		return config.DefaultOutputPolicy(), nil
+	}
+	return cfg.OutputPolicy()
+}
+
+// resolveOutputPath places a relative path in output.dir and applies
+// output.overwrite: unique picks a free name, fail refuses an existing file.
+func resolveOutputPath(path string, policy config.OutputPolicy) (string, error) {
+	return claimOutputPath(placeOutputPath(path, policy), policy)
+}
+
+// placeOutputPath puts a relative path in output.dir.
+func placeOutputPath(path string, policy config.OutputPolicy) string {
+	if policy.Dir != "" && !filepath.IsAbs(path) {
+		return filepath.Join(policy.Dir, path)
+	}
+	return path
+}
+
+// claimOutputPath applies output.overwrite to path.
+func claimOutputPath(path string, policy config.OutputPolicy) (string, error) {
+	if _, err := outputFS.Stat(path); err != nil {
+		return path, nil
+	}
+	switch policy.Overwrite {
+	case config.OverwriteUnique:
+		return ensureUniquePath(path), nil
+	case config.OverwriteFail:
+		return "", fmt.Errorf("%s already exists (output.overwrite is fail)", path)
+	}
+	return path, nil
+}
+
+func newUndoCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "undo",
+		Short: "Restore or remove the files written by the last create, batch or template run",
+		Long: `Every calendar written by create, quick, batch and template create is recorded
+in a journal under the config directory, with a copy of any file it replaced.
+undo reverts the most recent run: overwritten files get their previous content
+back and newly created files are removed. Run it again to step further back.
+Files edited since tempus wrote them are left alone unless --force is given.`,
+		Example: `  tempus undo --dry-run
+  tempus undo
+  tempus undo --list`,
+		Args: cobra.NoArgs,
+		RunE: runUndo,
+	}
+	cmd.Flags().Bool("dry-run", false, "Show what would be restored or removed")
+	cmd.Flags().Bool("force", false, "Undo even files changed since tempus wrote them")
+	cmd.Flags().Bool("list", false, "List the runs that can be undone, newest first")
+	return cmd
+}
+
+func runUndo(cmd *cobra.Command, _ []string) error {
+	j, err := journal.Default()
+	if err != nil {
+		return err
+	}
+	if list, _ := cmd.Flags().GetBool("list"); list {
+		return printJournal(cmd.OutOrStdout(), j)
+	}
+	force, _ := cmd.Flags().GetBool("force")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	entries, actions, err := j.Undo(force, dryRun)
+	if errors.Is(err, journal.ErrNothingToUndo) {
+		output.Info(os.Stdout, "ℹ️ ", "Nothing to undo.\n")
+		return nil
+	}
+	if len(entries) > 0 {
+		output.Info(os.Stdout, "↩️ ", "Undoing %s from %s\n", entries[0].Command, entries[0].Time.Local().Format("2006-01-02 15:04"))
+	}
+	for _, a := range actions {
+		switch {
+		case dryRun && a.Kind == "restored":
+			fmt.Printf("  would restore %s\n", a.Path)
+		case dryRun && a.Kind == "removed":
+			fmt.Printf("  would remove %s\n", a.Path)
+		case a.Kind == "restored":
+			printOK("Restored: %s\n", a.Path)
+		case a.Kind == "removed":
+			printOK("Removed: %s\n", a.Path)
+		default:
+			fmt.Printf("  %s is already gone\n", a.Path)
+		}
+	}
+	return err
+}
+
+// printJournal lists the journaled runs, newest first.
+func printJournal(w io.Writer, j *journal.Journal) error {
+	ops, err := j.Operations()
+	if err != nil {
+		return err
+	}
+	if len(ops) == 0 {
+		fmt.Fprintln(w, "Nothing to undo.")
+		return nil
+	}
+	for i := len(ops) - 1; i >= 0; i-- {
+		op := ops[i]
+		var files []string
+		for _, e := range op {
+			if !slices.Contains(files, e.Path) {
+				files = append(files, e.Path)
+			}
+		}
+		fmt.Fprintf(w, "%s  %s\n", op[0].Time.Local().Format("2006-01-02 15:04:05"), op[0].Command)
+		for _, f := range files {
+			fmt.Fprintf(w, "    %s\n", f)
+		}
+	}
+	return nil
+}
+
+func newDedupeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dedupe",
+		Short: "Remove duplicate events from an ICS file",
+		Long: `Remove events that were imported or generated more than once. By UID, repeated
+UID (+ RECURRENCE-ID) entries collapse to the one with the highest SEQUENCE; by
+content, events whose fields match an earlier event (ignoring UID and
+timestamps) are dropped.`,
+		Example: `  tempus dedupe -i calendar.ics
+  tempus dedupe -i calendar.ics --by content --in-place`,
+		RunE: runDedupe,
+	}
+	cmd.Flags().StringP("input", "i", "", "Input .ics file")
+	cmd.Flags().StringP("output", "o", "", "Output ICS file (default: <input>-deduped.ics)")
+	cmd.Flags().Bool("in-place", false, "Overwrite the input file")
+	cmd.Flags().String("by", "both", "Duplicate detection: uid, content or both")
+	cmd.Flags().Bool("dry-run", false, "List duplicates without writing")
+	cmd.Flags().Bool("json", false, "Print the removed events as JSON")
+	return cmd
+}
+
+func runDedupe(cmd *cobra.Command, _ []string) error {
+	input, _ := cmd.Flags().GetString("input")
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return fmt.Errorf("--input is required")
+	}
+	if !strings.EqualFold(filepath.Ext(input), ".ics") {
+		return fmt.Errorf("dedupe works on .ics files; for batch files use batch --stable-uids")
+	}
+
+	var opts calendar.DedupeOptions
+	by, _ := cmd.Flags().GetString("by")
+	switch strings.ToLower(strings.TrimSpace(by)) {
+	case "uid":
+		opts.ByUID = true
+	case "content":
+		opts.ByContent = true
+	case "both", "":
+		opts.ByUID, opts.ByContent = true, true
+	default:
+		return fmt.Errorf("invalid --by %q (use uid, content or both)", by)
+	}
+
+	outputPath, _ := cmd.Flags().GetString("output")
+	inPlace, _ := cmd.Flags().GetBool("in-place")
+	outputPath, err := resolveRewriteOutput(input, outputPath, inPlace, "-deduped")
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(filepath.Clean(input))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", input, err)
+	}
+	ics, dups, err := calendar.DedupeICS(string(data), opts)
+	if err != nil {
+		return err
+	}
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	out := cmd.OutOrStdout()
+	if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput || jsonReport(cmd) {
+		if dups == nil {
+			dups = []calendar.Duplicate{}
+		}
+		if err := printJSON(out, dups); err != nil {
+			return err
+		}
+	} else {
+		for _, d := range dups {
+			fmt.Fprintf(out, "  - %s (%s): duplicate %s\n", diffEventLabel(calendar.DiffEvent{UID: d.UID, Summary: d.Summary}), d.Start, d.Reason)
+		}
+	}
+
+	if len(dups) == 0 {
+		if !dryRun {
+			fmt.Fprintln(cmd.ErrOrStderr(), "No duplicates found; nothing written.")
+		}
+		return nil
+	}
+	if dryRun {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Dry run: %d duplicate(s) would be removed (nothing written)\n", len(dups))
+		return nil
+	}
+	if err := ensureDirForFile(outputPath); err != nil {
+		return err
+	}
+	if err := os.WriteFile(outputPath, []byte(ics), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+	output.OK(cmd.ErrOrStderr(), "Removed %d duplicate(s): %s\n", len(dups), outputPath)
+	return nil
+}
+
+func newServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve calendars over HTTP as subscription feeds",
+		Long: `Serve the .ics files in a directory over HTTP so phones and Google Calendar
+can subscribe to them instead of importing a copy. Batch files (CSV/JSON/YAML/TOML)
+are converted on the fly: a request for /work.ics serves work.ics if it exists,
+otherwise work.csv (or .json/.yaml/.toml) converted with stable UIDs, so edits show up
+as updates on the next refresh.
+
+With --token (or TEMPUS_SERVE_TOKEN) every request must carry the token as
+?token=... (what calendar apps support) or an Authorization: Bearer header.
+
+Converted feeds say where they are served from (SOURCE, without the token) and
+how often to refresh them (REFRESH-INTERVAL), so clients that honour RFC 7986
+poll at that pace.`,
+		Example: `  tempus serve --dir ./calendars --addr :8080
+  tempus serve --dir ./calendars --token s3cret   # subscribe to http://host:8080/work.ics?token=s3cret`,
+		RunE: runServe,
+	}
+	cmd.Flags().String("dir", ".", "Directory with .ics and batch files to serve")
+	cmd.Flags().String("addr", ":8080", "Address to listen on")
+	cmd.Flags().String("token", "", "Require this access token (default: $TEMPUS_SERVE_TOKEN)")
+	cmd.Flags().Duration("max-age", 5*time.Minute, "Cache-Control max-age sent to clients")
+	cmd.Flags().String("default-tz", "", "Default timezone for batch rows without one")
+	cmd.Flags().Duration("refresh-interval", time.Hour, "Refresh interval written into converted feeds (0 omits it)")
+	cmd.Flags().String("public-url", "", "Base URL clients reach the server at, e.g. https://cal.example.com (default: from each request)")
+	return cmd
+}
+
+func runServe(cmd *cobra.Command, _ []string) error {
+	dir, _ := cmd.Flags().GetString("dir")
+	addr, _ := cmd.Flags().GetString("addr")
+	token, _ := cmd.Flags().GetString("token")
+	if strings.TrimSpace(token) == "" {
+		token = os.Getenv("TEMPUS_SERVE_TOKEN")
+	}
+	maxAge, _ := cmd.Flags().GetDuration("max-age")
+	defaultTZ, _ := cmd.Flags().GetString("default-tz")
+	refresh, _ := cmd.Flags().GetDuration("refresh-interval")
+	publicURL, _ := cmd.Flags().GetString("public-url")
+	if err := (calendarMeta{Source: publicURL, RefreshInterval: refresh}).validate("", "--refresh-interval", "--public-url"); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("cannot serve %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+
+	handler := newFeedHandler(feedOptions{
+		dir: dir, token: strings.TrimSpace(token), maxAge: maxAge, defaultTZ: defaultTZ,
+		refresh: refresh, publicURL: strings.TrimSuffix(strings.TrimSpace(publicURL), "/"),
+	})
+	server := &http.Server{Addr: addr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, stop := signal.NotifyContext(commandContext(cmd), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	printOK("Serving %s on %s (Ctrl+C to stop)\n", dir, addr)
+	if token == "" {
+		output.Warn(os.Stdout, "No --token set: anyone who can reach this address can read the calendars.\n")
+	}
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+type feedOptions struct {
+	dir       string
+	token     string
+	maxAge    time.Duration
+	defaultTZ string
+	refresh   time.Duration // REFRESH-INTERVAL of converted feeds; 0 omits it
+	publicURL string        // base of SOURCE in converted feeds; "" takes it from the request
+}
+
+// feedHandler serves calendar feeds from a directory.
+type feedHandler struct {
+	opts feedOptions
+
+	mu    sync.Mutex
+	cache map[string]feedCacheEntry // converted batch files by path
+}
+
+// feedCacheEntry keeps a converted batch file until its source changes.
+type feedCacheEntry struct {
+	modTime time.Time
+	size    int64
+	source  string
+	cal     *calendar.Calendar
+}
+
+// feed is one calendar ready to be served. Its ETag comes from the file it
+// is read from, not from its content, so it is known before the body is
+// streamed and stays stable between polls (a converted feed's DTSTAMP
+// changes every time).
+type feed struct {
+	modTime time.Time
+	etag    string
+	size    int64 // -1 when only known once written
+	write   func(io.Writer) error
+}
+
+// feedETag is the validator of a feed read from a file with info, as
+// published at source.
+func feedETag(info os.FileInfo, source string) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%d\x00%d\x00%s", info.ModTime().UnixNano(), info.Size(), source))
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+func newFeedHandler(opts feedOptions) http.Handler {
+	return &feedHandler{opts: opts, cache: map[string]feedCacheEntry{}}
+}
+
+func (h *feedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="tempus"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" {
+		h.serveIndex(w)
+		return
+	}
+	if strings.Contains(name, "/") || !strings.EqualFold(path.Ext(name), ".ics") {
+		http.NotFound(w, r)
+		return
+	}
+
+	f, err := h.load(name, h.feedURL(r, name))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		http.NotFound(w, r)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", name))
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(h.opts.maxAge.Seconds())))
+	w.Header().Set("ETag", f.etag)
+	w.Header().Set("Last-Modified", f.modTime.UTC().Format(http.TimeFormat))
+	if match := r.Header.Get("If-None-Match"); match != "" && match == f.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if f.size >= 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(f.size, 10))
+	}
+	if r.Method == http.MethodHead {
+		return
+	}
+	_ = f.write(w)
+}
+
+// authorized checks the token from ?token= or an Authorization: Bearer header.
+func (h *feedHandler) authorized(r *http.Request) bool {
+	if h.opts.token == "" {
+		return true
+	}
+	given := r.URL.Query().Get("token")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		given = bearer
+	}
+	return subtle.ConstantTimeCompare([]byte(given), []byte(h.opts.token)) == 1
+}
+
+// feedURL is the address of the feed called name, as written in SOURCE. It
+// never carries the token.
+func (h *feedHandler) feedURL(r *http.Request, name string) string {
+	if h.opts.publicURL != "" {
+		return h.opts.publicURL + "/" + url.PathEscape(name)
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return (&url.URL{Scheme: scheme, Host: r.Host, Path: "/" + name}).String()
+}
+
+// load returns the feed called name: the .ics file itself, or a batch file
+// with the same base name converted to ICS and published at source.
+func (h *feedHandler) load(name, source string) (feed, error) {
+	icsPath := filepath.Join(h.opts.dir, name)
+	if info, err := os.Stat(icsPath); err == nil && !info.IsDir() {
+		write := func(w io.Writer) error {
+			f, err := os.Open(filepath.Clean(icsPath))
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = io.Copy(w, f)
+			return err
+		}
+		return feed{modTime: info.ModTime(), etag: feedETag(info, ""), size: info.Size(), write: write}, nil
+	}
+
+	base := strings.TrimSuffix(name, path.Ext(name))
+	for _, e := range batchExtensions {
+		src := filepath.Join(h.opts.dir, base+e.ext)
+		info, err := os.Stat(src)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		cal, err := h.convert(src, base, source, info)
+		if err != nil {
+			return feed{}, err
+		}
+		return feed{modTime: info.ModTime(), etag: feedETag(info, source), size: -1, write: cal.WriteICS}, nil
+	}
+	return feed{}, fs.ErrNotExist
+}
+
+func (h *feedHandler) convert(src, name, source string, info os.FileInfo) (*calendar.Calendar, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if entry, ok := h.cache[src]; ok && entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() && entry.source == source {
+		return entry.cal, nil
+	}
+
+	opts := &batchOptions{input: src, formatFlag: "auto", name: name, defaultTZ: h.opts.defaultTZ, stableUIDs: true}
+	records, _, err := loadBatchInput(opts)
+	if err != nil {
+		return nil, err
+	}
+	// A calendar: block in the file knows its own feed better than the server.
+	opts.meta = opts.meta.orElse(calendarMeta{Source: source, RefreshInterval: h.opts.refresh})
+	cal, _, err := buildBatchCalendar(records, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(src), err)
+	}
+	// Colored once here: the cached calendar is then only read, by as many
+	// requests at a time as come in.
+	applyCategoryColors(cal)
+	h.cache[src] = feedCacheEntry{modTime: info.ModTime(), size: info.Size(), source: source, cal: cal}
+	return cal, nil
+}
+
+// serveIndex lists the available feeds as plain text.
+func (h *feedHandler) serveIndex(w http.ResponseWriter) {
+	entries, err := os.ReadDir(h.opts.dir)
+	if err != nil {
+		http.Error(w, "cannot list feeds", http.StatusInternalServerError)
+		return
+	}
+	feeds := map[string]bool{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		ext := strings.ToLower(filepath.Ext(e.Name()))
+		if ext == ".ics" || slices.ContainsFunc(batchExtensions, func(e batchExtension) bool { return e.ext == ext }) {
+			feeds[strings.TrimSuffix(e.Name(), filepath.Ext(e.Name()))+".ics"] = true
+		}
+	}
+	names := make([]string, 0, len(feeds))
+	for name := range feeds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	for _, name := range names {
+		fmt.Fprintln(w, "/"+name)
+	}
+}
+
+// maxFeedSize caps how much of a remote feed fetch reads (calendars larger
+// than this are almost certainly not what the user meant to subscribe to).
+const maxFeedSize = 20 << 20
+
+func newFetchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fetch <url>",
+		Short: "Download a remote ICS/webcal feed, check it and save a cleaned copy",
+		Long: `Download a published calendar (https:// or webcal://), run it through the
+parser and lint, and save it locally. On the way, deprecated TZIDs are rewritten
+to canonical IANA names, --tz-map remaps custom ones (e.g. Outlook's Windows zone
+names) and --emoji prefixes summaries by category, so tempus can act as a
+sanitizing proxy for third-party feeds (pair it with cron and tempus serve).
+
+Times are not converted: a remapped TZID only relabels the zone. A feed that
+fails lint is not saved unless --force is given.`,
+		Example: `  tempus fetch webcal://example.com/team.ics -o team.ics
+  tempus fetch https://example.com/cal --tz-map "W. Europe Standard Time=Europe/Berlin" --emoji
+  tempus fetch https://example.com/cal.ics -o -   # print to stdout`,
+		Args: cobra.ExactArgs(1),
+		RunE: runFetch,
+	}
+	cmd.Flags().StringP("output", "o", "", "Output ICS file, or - for stdout (default: name from the URL)")
+	cmd.Flags().StringArray("tz-map", nil, `Remap a TZID, as "FROM=TO" (repeat flag for multiple)`)
+	cmd.Flags().Bool("emoji", false, "Prefix summaries with an emoji based on their categories")
+	cmd.Flags().Duration("timeout", 30*time.Second, "Give up on the download after this long")
+	cmd.Flags().Bool("force", false, "Save the feed even if lint finds errors")
+	return cmd
+}
+
+func runFetch(cmd *cobra.Command, args []string) error {
+	feedURL, err := normalizeFeedURL(args[0])
+	if err != nil {
+		return err
+	}
+	tzMapFlags, _ := cmd.Flags().GetStringArray("tz-map")
+	tzMap, err := parseTZMap(tzMapFlags)
+	if err != nil {
+		return err
+	}
+	emoji, _ := cmd.Flags().GetBool("emoji")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	force, _ := cmd.Flags().GetBool("force")
+	outputPath, _ := cmd.Flags().GetString("output")
+	if strings.TrimSpace(outputPath) == "" {
+		outputPath = feedFileName(feedURL)
+	}
+
+	data, err := downloadFeed(commandContext(cmd), feedURL, timeout)
+	if err != nil {
+		return err
+	}
+
+	transform := calendar.Transform{TZID: func(tz string) string {
+		if mapped, ok := tzMap[tz]; ok {
+			return mapped
+		}
+		canonical, _ := tzpkg.CanonicalName(tz)
+		return canonical
+	}}
+	if emoji {
+		transform.Summary = addEmojiToSummary
+	}
+	ics, report, err := calendar.TransformICS(data, transform)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid calendar: %w", feedURL, err)
+	}
+
+	stderr := cmd.ErrOrStderr()
+	findings, err := lintICSData(feedURL, ics)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid calendar: %w", feedURL, err)
+	}
+	diag.Render(stderr, findings)
+	if diag.HasErrors(findings) && !force {
+		return fmt.Errorf("feed failed lint with %d error(s); use --force to save it anyway", diag.Count(findings, diag.SeverityError))
+	}
+
+	for _, remap := range report.Remapped() {
+		fmt.Fprintf(stderr, "  TZID %s\n", remap)
+	}
+	if report.Summaries > 0 {
+		fmt.Fprintf(stderr, "  Added emoji to %d summary(ies)\n", report.Summaries)
+	}
+
+	if outputPath == "-" {
+		_, err := io.WriteString(cmd.OutOrStdout(), ics)
+		return err
+	}
+	if err := ensureDirForFile(outputPath); err != nil {
+		return err
+	}
+	if err := os.WriteFile(outputPath, []byte(ics), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+	output.OK(stderr, "Fetched %d event(s) from %s: %s\n", report.Events, feedURL, outputPath)
+	return nil
+}
+
+// normalizeFeedURL turns webcal:// links (what "subscribe" buttons use) into
+// https:// and rejects anything that is not HTTP(S).
+func normalizeFeedURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %q: %w", raw, err)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "webcal", "webcals":
+		u.Scheme = "https"
+	case "http", "https":
+	default:
+		return "", fmt.Errorf("unsupported URL %q (use https://, http:// or webcal://)", raw)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid URL %q: missing host", raw)
+	}
+	return u.String(), nil
+}
+
+// parseTZMap parses --tz-map "FROM=TO" pairs; every TO must be a loadable zone.
+func parseTZMap(pairs []string) (map[string]string, error) {
+	tzMap := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		from, to, ok := strings.Cut(pair, "=")
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("invalid --tz-map %q (use FROM=TO)", pair)
+		}
+		to, _ = tzpkg.CanonicalName(to)
+		if _, err := time.LoadLocation(to); err != nil {
+			return nil, fmt.Errorf("invalid --tz-map %q: unknown timezone %q", pair, to)
+		}
+		tzMap[from] = to
+	}
+	return tzMap, nil
+}
+
+// feedFileName derives a local file name from the last path segment of a feed URL.
+func feedFileName(feedURL string) string {
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		return "feed.ics"
+	}
+	base := path.Base(u.Path)
+	if base == "." || base == "/" || base == "" {
+		return "feed.ics"
+	}
+	return strings.TrimSuffix(base, path.Ext(base)) + ".ics"
+}
+
+func downloadFeed(ctx context.Context, feedURL string, timeout time.Duration) (string, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "tempus/"+version)
+	req.Header.Set("Accept", "text/calendar, */*;q=0.5")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s: %w", feedURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch %s: %s", feedURL, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", feedURL, err)
+	}
+	if len(body) > maxFeedSize {
+		return "", fmt.Errorf("%s is larger than %d MiB", feedURL, maxFeedSize>>20)
+	}
+	output.Detail(os.Stderr, "GET %s: %s, %d bytes\n", feedURL, resp.Status, len(body))
+	return string(body), nil
+}
+
+func newExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export [format]",
+		Short: "Export ICS events as JSON, CSV, a Markdown or HTML agenda, jCal or xCal",
+		Long: `The reverse of batch: read an .ics file and write its events as JSON or CSV
+using the batch column schema, or as a Markdown agenda table. Edit the CSV in a
+spreadsheet and feed it back to tempus batch; the uid column keeps re-imports
+updating the same events.
+
+html writes a standalone, printable agenda page: events grouped by day and
+colored by category, for people who don't use calendar apps. planner draws the
+same events as a paper-style weekly planner, one page per week with a time
+grid; print it or save it as PDF from the browser.
+
+jcal (RFC 7265) and xcal (RFC 6321) convert the whole calendar, including
+time zones and alarms, for web apps that prefer JSON or XML over ICS.
+
+The input may also be a batch file (CSV/JSON/YAML/TOML); it is exported as
+the .ics that tempus batch would write.`,
+		Example: `  tempus export -i calendar.ics -o events.csv
+  tempus batch -i events.csv -o calendar.ics   # round-trip after editing
+  tempus export -i calendar.ics --format md    # agenda on stdout
+  tempus export html -i family.csv -o agenda.html
+  tempus export planner -i calendar.ics --from 2025-09-01 --weeks 4 -o planner.html
+  tempus export -i calendar.ics -o calendar.jcal`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runExport,
+	}
+	cmd.Flags().StringP("input", "i", "", "Input .ics or batch file (csv/json/yaml/toml)")
+	cmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+	cmd.Flags().String("format", "auto", "Output format: auto (from --output), json, csv, md, html, planner, jcal or xcal")
+	cmd.Flags().String("input-format", "auto", "Batch input format: auto, csv, json, yaml, toml, timetable")
+	cmd.Flags().String("default-tz", "", "Default timezone for batch rows without one")
+	cmd.Flags().StringArray("filter-category", []string{}, "Only export events in this category or below it, e.g. Health matches Health/Medication (repeatable)")
+	cmd.Flags().String("from", "", "Planner: a day in the first week, YYYY-MM-DD (default: this week)")
+	cmd.Flags().Int("weeks", 1, "Planner: how many weeks to draw (1-52)")
+	return cmd
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	input, _ := cmd.Flags().GetString("input")
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return fmt.Errorf("--input is required")
+	}
+	outputPath, _ := cmd.Flags().GetString("output")
+	outputPath = strings.TrimSpace(outputPath)
+	formatFlag, _ := cmd.Flags().GetString("format")
+	if len(args) == 1 {
+		if cmd.Flags().Changed("format") && !strings.EqualFold(formatFlag, args[0]) {
+			return fmt.Errorf("format given twice: %s and --format %s", args[0], formatFlag)
+		}
+		formatFlag = args[0]
+	}
+	format, err := detectExportFormat(formatFlag, outputPath)
+	if err != nil {
+		return err
+	}
+	categories, _ := cmd.Flags().GetStringArray("filter-category")
+	if len(categories) > 0 && (format == "jcal" || format == "xcal") {
+		return fmt.Errorf("--filter-category works with json, csv, md, html and planner; %s converts the whole calendar", format)
+	}
+	var planner plannerOptions
+	if format == "planner" {
+		if planner, err = parsePlannerFlags(cmd); err != nil {
+			return err
+		}
+	} else if cmd.Flags().Changed("from") || cmd.Flags().Changed("weeks") {
+		return fmt.Errorf("--from and --weeks only apply to the planner format")
+	}
+
+	data, err := readExportInput(cmd, input)
+	if err != nil {
+		return err
+	}
+	records, err := calendar.ExportEvents(data)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", input, err)
+	}
+	records = slices.DeleteFunc(records, func(r calendar.EventRecord) bool {
+		return !calendar.AnyCategoryUnder(r.Categories, categories)
+	})
+
+	var buf bytes.Buffer
+	switch format {
+	case "json":
+		if records == nil {
+			records = []calendar.EventRecord{}
+		}
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(records)
+	case "csv":
+		err = writeExportCSV(&buf, records)
+	case "jcal", "xcal":
+		convert := calendar.ICSToJCal
+		if format == "xcal" {
+			convert = calendar.ICSToXCal
+		}
+		var out []byte
+		if out, err = convert(data); err == nil {
+			buf.Write(out)
+		}
+	case "html":
+		err = writeHTMLAgenda(&buf, exportTitle(input), records)
+	case "planner":
+		err = writeWeeklyPlanner(&buf, exportTitle(input), data, categories, planner)
+	default:
+		writeMarkdownAgenda(&buf, exportTitle(input), records)
+	}
+	if err != nil {
+		return err
+	}
+
+	if outputPath == "" {
+		_, err := cmd.OutOrStdout().Write(buf.Bytes())
+		return err
+	}
+	if err := ensureDirForFile(outputPath); err != nil {
+		return err
+	}
+	if err := os.WriteFile(outputPath, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+	output.OK(cmd.ErrOrStderr(), "Exported %d event(s): %s\n", len(records), outputPath)
+	return nil
+}
+
+// readExportInput reads an .ics file, or renders a batch file as the .ics
+// batch would write.
+func readExportInput(cmd *cobra.Command, input string) (string, error) {
+	if strings.EqualFold(filepath.Ext(input), ".ics") {
+		return readICSFile(input)
+	}
+	formatFlag, _ := cmd.Flags().GetString("input-format")
+	defaultTZ, _ := cmd.Flags().GetString("default-tz")
+	body, err := batchFileICS(input, formatFlag, defaultTZ)
+	return string(body), err
+}
+
+// exportTitle names an agenda after its input file.
+func exportTitle(input string) string {
+	return strings.TrimSuffix(filepath.Base(input), filepath.Ext(input))
+}
+
+func detectExportFormat(flag, output string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(flag)) {
+	case "auto", "":
+		switch strings.ToLower(filepath.Ext(output)) {
+		case ".json":
+			return "json", nil
+		case ".csv":
+			return "csv", nil
+		case ".md", ".markdown", "":
+			return "md", nil
+		case ".html", ".htm":
+			return "html", nil
+		case ".jcal", ".jcs":
+			return "jcal", nil
+		case ".xcs", ".xml":
+			return "xcal", nil
+		default:
+			return "", fmt.Errorf("cannot infer format from %s; use --format json|csv|md|html|jcal|xcal", output)
+		}
+	case "json":
+		return "json", nil
+	case "csv":
+		return "csv", nil
+	case "md", "markdown":
+		return "md", nil
+	case "html", "htm":
+		return "html", nil
+	case "planner", "jcal", "xcal":
+		return strings.ToLower(strings.TrimSpace(flag)), nil
+	default:
+		return "", fmt.Errorf("unsupported format %q (use json, csv, md, html, planner, jcal or xcal)", flag)
+	}
+}
+
+func writeExportCSV(w io.Writer, records []calendar.EventRecord) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(calendar.ExportColumns); err != nil {
+		return err
+	}
+	for _, rec := range records {
+		if err := cw.Write(rec.CSVRow()); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeMarkdownAgenda writes records as a Markdown table in start order.
+func writeMarkdownAgenda(w io.Writer, title string, records []calendar.EventRecord) {
+	sorted := slices.Clone(records)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	fmt.Fprintf(w, "# %s\n\n", title)
+	if len(sorted) == 0 {
+		fmt.Fprintln(w, "_No events._")
+		return
+	}
+	fmt.Fprintln(w, "| Date | Time | Event | Location | Categories |")
+	fmt.Fprintln(w, "|------|------|-------|----------|------------|")
+	for _, rec := range sorted {
+		date, when := agendaDateTime(rec)
+		event := rec.Summary
+		if rec.RRule != "" {
+			event += " (repeats)"
+		}
+		fmt.Fprintf(w, "| %s | %s | %s | %s | %s |\n",
+			date, when, markdownCell(event), markdownCell(rec.Location), markdownCell(strings.Join(rec.Categories, ", ")))
+	}
+}
+
+// agendaDateTime splits a record's start/end into the Date and Time cells.
+func agendaDateTime(rec calendar.EventRecord) (string, string) {
+	day, clock, _ := strings.Cut(rec.Start, " ")
+	date := displayRecordTime(day)
+	clock = displayRecordTime(clock)
+	if rec.AllDay {
+		if rec.End != "" {
+			return date, "all day, until " + displayRecordTime(rec.End)
+		}
+		return date, "all day"
+	}
+	end := ""
+	if endDay, endClock, ok := strings.Cut(rec.End, " "); ok {
+		end = displayRecordTime(endClock)
+		if endDay != day {
+			end = displayRecordTime(rec.End)
+		}
+	}
+	switch {
+	case end == "":
+		return date, strings.TrimSpace(clock + " " + rec.StartTZ)
+	case rec.EndTZ != "" && rec.EndTZ != rec.StartTZ:
+		// e.g. flights: each side in its own zone
+		return date, fmt.Sprintf("%s %s – %s %s", clock, rec.StartTZ, end, rec.EndTZ)
+	default:
+		return date, strings.TrimSpace(clock + "–" + end + " " + rec.StartTZ)
+	}
+}
+
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// agendaPalette colors the categories of an HTML agenda, handed out in
+// alphabetical order so the first ten categories never share a color.
+var agendaPalette = []string{"#4e79a7", "#f28e2b", "#59a14f", "#e15759", "#76b7b2", "#edc948", "#b07aa1", "#ff9da7", "#9c755f", "#8cd17d"}
+
+// agendaNoCategory colors events without a category.
+const agendaNoCategory = "#bab0ac"
+
+type htmlAgenda struct {
+	Lang, Title, Empty string
+	Legend             []htmlAgendaCategory
+	Days               []htmlAgendaDay
+}
+
+type htmlAgendaCategory struct{ Name, Color string }
+
+type htmlAgendaDay struct {
+	Date   string
+	Events []htmlAgendaEvent
+}
+
+type htmlAgendaEvent struct {
+	Time, Summary, Repeats, Location, Color string
+	Categories                              []htmlAgendaCategory
+}
+
+var htmlAgendaTemplate = template.Must(template.New("agenda").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, -apple-system, "Segoe UI", sans-serif; max-width: 46rem; margin: 2rem auto; padding: 0 1rem; color: #222; line-height: 1.4; }
+h1 { margin-bottom: .5rem; }
+.legend { display: flex; flex-wrap: wrap; gap: .4rem; margin-bottom: 1.5rem; }
+.tag { border-radius: .8rem; padding: .05rem .6rem; font-size: .85rem; color: #fff; }
+.day { break-inside: avoid; page-break-inside: avoid; margin-bottom: 1.2rem; }
+.day h2 { font-size: 1.1rem; border-bottom: 1px solid #ccc; padding-bottom: .2rem; margin-bottom: .4rem; }
+.event { border-left: .4rem solid; padding: .3rem .6rem; margin: .3rem 0; background: #f7f7f7; }
+.time { font-weight: 600; margin-right: .5rem; }
+.summary { font-size: 1.05rem; }
+.repeats, .where { color: #555; font-size: .9rem; }
+.event .tag { font-size: .75rem; }
+@media print {
+  body { margin: 0; max-width: none; }
+  .event, .tag { -webkit-print-color-adjust: exact; print-color-adjust: exact; }
+}
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- if .Legend}}
+<div class="legend">{{range .Legend}}<span class="tag" style="background: {{.Color}}">{{.Name}}</span>{{end}}</div>
+{{- end}}
+{{- range .Days}}
+<section class="day">
+<h2>{{.Date}}</h2>
+{{- range .Events}}
+<div class="event" style="border-left-color: {{.Color}}">
+<span class="time">{{.Time}}</span> <span class="summary">{{.Summary}}</span>{{if .Repeats}} <span class="repeats">({{.Repeats}})</span>{{end}}
+{{- if .Location}}
+<div class="where">📍 {{.Location}}</div>
+{{- end}}
+{{- if .Categories}}
+<div>{{range .Categories}}<span class="tag" style="background: {{.Color}}">{{.Name}}</span> {{end}}</div>
+{{- end}}
+</div>
+{{- end}}
+</section>
+{{- else}}
+<p>{{.Empty}}</p>
+{{- end}}
+</body>
+</html>
+`))
+
+// agendaLegend hands out the palette to the categories found in sets, in
+// alphabetical order, and returns them with a lower-case name → color map.
+// A category with a color in category_colors keeps that color.
+func agendaLegend(sets [][]string) ([]htmlAgendaCategory, map[string]string) {
+	var legend []htmlAgendaCategory
+	colors := map[string]string{}
+	for _, set := range sets {
+		for _, c := range set {
+			name := calendar.CleanCategory(c)
+			if _, ok := colors[strings.ToLower(name)]; name != "" && !ok {
+				colors[strings.ToLower(name)] = ""
+				legend = append(legend, htmlAgendaCategory{Name: name})
+			}
+		}
+	}
+	sort.Slice(legend, func(i, j int) bool { return strings.ToLower(legend[i].Name) < strings.ToLower(legend[j].Name) })
+	cfg, _ := config.Current()
+	for i := range legend {
+		legend[i].Color = agendaPalette[i%len(agendaPalette)]
+		if cfg != nil {
+			if _, color, ok := cfg.CategoryColor([]string{legend[i].Name}); ok {
+				if hex, err := calendar.ParseColor(color); err == nil {
+					legend[i].Color = hex
+				}
+			}
+		}
+		colors[strings.ToLower(legend[i].Name)] = legend[i].Color
+	}
+	return legend, colors
+}
+
+// agendaColor is the color of the first of categories, gray without one.
+func agendaColor(categories []string, colors map[string]string) string {
+	for _, c := range categories {
+		if color := colors[strings.ToLower(calendar.CleanCategory(c))]; color != "" {
+			return color
+		}
+	}
+	return agendaNoCategory
+}
+
+// writeHTMLAgenda writes records as a standalone, printable HTML page: one
+// section per day in start order, each event colored by its first category.
+func writeHTMLAgenda(w io.Writer, title string, records []calendar.EventRecord) error {
+	sorted := slices.Clone(records)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	page := htmlAgenda{Lang: ui.GetLanguage(), Title: title, Empty: ui.T("agenda_no_events")}
+	sets := make([][]string, len(sorted))
+	for i, rec := range sorted {
+		sets[i] = rec.Categories
+	}
+	var colors map[string]string
+	page.Legend, colors = agendaLegend(sets)
+
+	for _, rec := range sorted {
+		day, _, _ := strings.Cut(rec.Start, " ")
+		if n := len(page.Days); n == 0 || page.Days[n-1].Date != displayRecordTime(day) {
+			page.Days = append(page.Days, htmlAgendaDay{Date: displayRecordTime(day)})
+		}
+		_, when := agendaDateTime(rec)
+		ev := htmlAgendaEvent{Time: when, Summary: rec.Summary, Location: rec.Location, Color: agendaColor(rec.Categories, colors)}
+		if rec.RRule != "" {
+			ev.Repeats = ui.T("agenda_repeats")
+		}
+		for _, c := range rec.Categories {
+			if name := calendar.CleanCategory(c); name != "" {
+				ev.Categories = append(ev.Categories, htmlAgendaCategory{Name: name, Color: colors[strings.ToLower(name)]})
+			}
+		}
+		last := &page.Days[len(page.Days)-1]
+		last.Events = append(last.Events, ev)
+	}
+	return htmlAgendaTemplate.Execute(w, page)
+}
+
+// The planner grid shows at least these hours; events outside them stretch it.
+const (
+	plannerFirstHour = 7
+	plannerLastHour  = 21
+	plannerHourRem   = 2.2 // height of one hour, in rem
+)
+
+// plannerOptions are the weeks a planner export covers.
+type plannerOptions struct {
+	from  time.Time // the Monday of the first week, midnight in loc
+	weeks int
+	loc   *time.Location
+}
+
+type plannerPage struct {
+	Lang, Title, AllDay, Notes string
+	Legend                     []htmlAgendaCategory
+	Weeks                      []plannerWeek
+	HourRem                    float64
+}
+
+type plannerWeek struct {
+	Heading string
+	Days    []plannerDay
+	Hours   []plannerHour
+	Height  float64 // of the time grid, in rem
+}
+
+type plannerHour struct {
+	Label string
+	Top   float64 // percent of the grid
+}
+
+type plannerDay struct {
+	Label  string
+	AllDay []plannerBlock
+	Events []plannerBlock
+}
+
+// plannerBlock is an event on one day of the grid; positions are percentages
+// of the day column.
+type plannerBlock struct {
+	Time, Summary, Location, Color string
+	Top, Height, Left, Width       float64
+	start, end                     time.Time
+}
+
+var plannerTemplate = template.Must(template.New("planner").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+@page { size: A4 landscape; margin: 1cm; }
+body { font-family: system-ui, -apple-system, "Segoe UI", sans-serif; margin: 1.5rem; color: #222; font-size: 12px; }
+.week { break-after: page; page-break-after: always; }
+.week:last-child { break-after: auto; page-break-after: auto; }
+h1 { font-size: 1.2rem; margin: 0; }
+h2 { font-size: 1.5rem; margin: .2rem 0 .5rem; }
+.legend { display: flex; flex-wrap: wrap; gap: .3rem; margin-bottom: .5rem; }
+.tag { border-radius: .7rem; padding: 0 .5rem; color: #fff; }
+.grid { display: grid; grid-template-columns: 3.2rem repeat(7, 1fr); border: 1px solid #999; }
+.grid > div { border-left: 1px solid #ccc; }
+.head { font-weight: 600; text-align: center; padding: .2rem; border-bottom: 1px solid #999; }
+.allday { min-height: 1.4rem; padding: .1rem; border-bottom: 1px solid #999; }
+.allday .event { position: static; margin-bottom: .1rem; }
+.label { color: #555; font-size: .8rem; padding: .1rem; }
+.hours, .day { position: relative; }
+.day { background: repeating-linear-gradient(#fff 0, #fff calc({{printf "%.1f" .HourRem}}rem - 1px), #e4e4e4 calc({{printf "%.1f" .HourRem}}rem - 1px), #e4e4e4 {{printf "%.1f" .HourRem}}rem); }
+.hour { position: absolute; right: .2rem; color: #555; font-size: .8rem; }
+.event { position: absolute; box-sizing: border-box; overflow: hidden; border-left: .25rem solid; background: #f3f3f3; padding: .05rem .2rem; font-size: .75rem; line-height: 1.2; }
+.event b { display: block; }
+.where { color: #555; }
+.notes { border: 1px solid #999; border-top: none; height: 4rem; padding: .2rem; color: #555; }
+@media print {
+  body { margin: 0; }
+  .event, .tag { -webkit-print-color-adjust: exact; print-color-adjust: exact; }
+}
+</style>
+</head>
+<body>
+{{- range .Weeks}}
+<section class="week">
+<h1>{{$.Title}}</h1>
+<h2>{{.Heading}}</h2>
+{{- if $.Legend}}
+<div class="legend">{{range $.Legend}}<span class="tag" style="background: {{.Color}}">{{.Name}}</span>{{end}}</div>
+{{- end}}
+<div class="grid">
+<div></div>{{range .Days}}<div class="head">{{.Label}}</div>{{end}}
+<div class="label allday">{{$.AllDay}}</div>
+{{- range .Days}}
+<div class="allday">{{range .AllDay}}<div class="event" style="border-left-color: {{.Color}}">{{.Summary}}</div>{{end}}</div>
+{{- end}}
+<div class="hours" style="height: {{printf "%.1f" .Height}}rem">{{range .Hours}}<span class="hour" style="top: {{printf "%.2f" .Top}}%">{{.Label}}</span>{{end}}</div>
+{{- range .Days}}
+<div class="day">
+{{- range .Events}}
+<div class="event" style="border-left-color: {{.Color}}; top: {{printf "%.2f" .Top}}%; height: {{printf "%.2f" .Height}}%; left: {{printf "%.2f" .Left}}%; width: {{printf "%.2f" .Width}}%"><b>{{.Time}}</b>{{.Summary}}{{if .Location}}<div class="where">📍 {{.Location}}</div>{{end}}</div>
+{{- end}}
+</div>
+{{- end}}
+</div>
+<div class="notes">{{$.Notes}}</div>
+</section>
+{{- end}}
+</body>
+</html>
+`))
+
+// parsePlannerFlags reads --from and --weeks, and the timezone the planner
+// is drawn in (--timezone, else the config's, else UTC).
+func parsePlannerFlags(cmd *cobra.Command) (plannerOptions, error) {
+	opts := plannerOptions{loc: time.UTC}
+	if tz := resolveQuickTimezone(cmd); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return opts, fmt.Errorf("invalid timezone %q: %w", tz, err)
+		}
+		opts.loc = loc
+	}
+	day := appClock.Now().In(opts.loc)
+	if v, _ := cmd.Flags().GetString("from"); strings.TrimSpace(v) != "" {
+		t, err := time.ParseInLocation(constants.DateFormatISO, strings.TrimSpace(v), opts.loc)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --from %q (use YYYY-MM-DD)", v)
+		}
+		day = t
+	}
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, opts.loc)
+	opts.from = day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+
+	opts.weeks, _ = cmd.Flags().GetInt("weeks")
+	if opts.weeks < 1 || opts.weeks > 52 {
+		return opts, fmt.Errorf("--weeks must be between 1 and 52")
+	}
+	return opts, nil
+}
+
+// writeWeeklyPlanner draws the events of an .ics document as a printable
+// week-per-page planner: a time grid from Monday to Sunday, all-day events
+// above it and a notes box below. Recurring events are expanded.
+func writeWeeklyPlanner(w io.Writer, title, data string, categories []string, opts plannerOptions) error {
+	events, err := calendar.ParseICSEvents(data)
+	if err != nil {
+		return err
+	}
+	events = slices.DeleteFunc(events, func(ev calendar.Event) bool {
+		return !calendar.AnyCategoryUnder(ev.Categories, categories)
+	})
+	for i := range events {
+		floatAllDay(&events[i], opts.loc)
+	}
+	until := opts.from.AddDate(0, 0, 7*opts.weeks)
+	occurrences := slices.DeleteFunc(calendar.Upcoming(events, opts.from, 0), func(ev calendar.Event) bool {
+		return !ev.StartTime.Before(until)
+	})
+
+	sets := make([][]string, len(occurrences))
+	for i, ev := range occurrences {
+		sets[i] = ev.Categories
+	}
+	page := plannerPage{Lang: ui.GetLanguage(), Title: title, AllDay: ui.T("all_day"), Notes: ui.T("planner_notes"), HourRem: plannerHourRem}
+	var colors map[string]string
+	page.Legend, colors = agendaLegend(sets)
+
+	for week := 0; week < opts.weeks; week++ {
+		monday := opts.from.AddDate(0, 0, 7*week)
+		page.Weeks = append(page.Weeks, plannerWeekOf(monday, occurrences, colors))
+	}
+	return plannerTemplate.Execute(w, page)
+}
+
+// plannerWeekOf lays out the week starting on monday. Events are cut at
+// midnight, so one that runs overnight shows on both days.
+func plannerWeekOf(monday time.Time, occurrences []calendar.Event, colors map[string]string) plannerWeek {
+	sunday := monday.AddDate(0, 0, 6)
+	week := plannerWeek{Heading: ui.T("planner_week", displayDate(monday), displayDate(sunday))}
+	first, last := plannerFirstHour*60, plannerLastHour*60 // minutes after midnight
+
+	for d := 0; d < 7; d++ {
+		dayStart := monday.AddDate(0, 0, d)
+		dayEnd := dayStart.AddDate(0, 0, 1)
+		day := plannerDay{Label: displayDate(dayStart)}
+		for _, ev := range occurrences {
+			block := plannerBlock{Summary: ev.Summary, Location: ev.Location, Color: agendaColor(ev.Categories, colors)}
+			if ev.AllDay {
+				if ev.StartTime.Before(dayEnd) && ev.EndTime.After(dayStart) {
+					day.AllDay = append(day.AllDay, block)
+				}
+				continue
+			}
+			block.start, block.end = ev.StartTime, ev.EndTime
+			if block.start.Before(dayStart) {
+				block.start = dayStart
+			}
+			if block.end.After(dayEnd) {
+				block.end = dayEnd
+			}
+			if block.start.Before(dayEnd) && (block.end.After(block.start) || ev.StartTime.Equal(block.start)) {
+				block.Time = displayClock(ev.StartTime.In(dayStart.Location())) + "–" + displayClock(ev.EndTime.In(dayStart.Location()))
+				first = min(first, int(block.start.Sub(dayStart).Minutes())/60*60)
+				last = max(last, (int(block.end.Sub(dayStart).Minutes())+59)/60*60)
+				day.Events = append(day.Events, block)
+			}
+		}
+		week.Days = append(week.Days, day)
+	}
+
+	span := float64(last - first)
+	week.Height = float64(last-first) / 60 * plannerHourRem
+	for h := first; h < last; h += 60 {
+		week.Hours = append(week.Hours, plannerHour{
+			Label: displayClock(time.Date(2000, 1, 1, h/60, 0, 0, 0, time.UTC)),
+			Top:   float64(h-first) / span * 100,
+		})
+	}
+	for d := range week.Days {
+		dayStart := monday.AddDate(0, 0, d)
+		blocks := week.Days[d].Events
+		for i := range blocks {
+			top := blocks[i].start.Sub(dayStart).Minutes() - float64(first)
+			blocks[i].Top = top / span * 100
+			blocks[i].Height = max(blocks[i].end.Sub(blocks[i].start).Minutes(), 20) / span * 100
+		}
+		layoutPlannerLanes(blocks)
+	}
+	return week
+}
+
+// layoutPlannerLanes puts overlapping blocks side by side: each group of
+// blocks that overlap one another shares the column width equally.
+func layoutPlannerLanes(blocks []plannerBlock) {
+	sort.SliceStable(blocks, func(i, j int) bool { return blocks[i].start.Before(blocks[j].start) })
+	for i := 0; i < len(blocks); {
+		// A group ends where no block so far is still running.
+		groupEnd := blocks[i].end
+		j := i + 1
+		for j < len(blocks) && blocks[j].start.Before(groupEnd) {
+			if blocks[j].end.After(groupEnd) {
+				groupEnd = blocks[j].end
+			}
+			j++
+		}
+		var laneEnds []time.Time
+		lanes := make([]int, j-i)
+		for k := i; k < j; k++ {
+			lane := slices.IndexFunc(laneEnds, func(end time.Time) bool { return !end.After(blocks[k].start) })
+			if lane < 0 {
+				lane = len(laneEnds)
+				laneEnds = append(laneEnds, blocks[k].end)
+			}
+			laneEnds[lane] = blocks[k].end
+			lanes[k-i] = lane
+		}
+		width := 100 / float64(len(laneEnds))
+		for k := i; k < j; k++ {
+			blocks[k].Left, blocks[k].Width = float64(lanes[k-i])*width, width
+		}
+		i = j
+	}
+}
+
+func newNextCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "next",
+		Short: "Show the next events of an .ics file with countdowns",
+		Long: `Read an .ics file and list the events still to come, soonest first, each
+with how long until it starts, or until it ends if it is already under way.
+Recurring events are expanded and cancelled occurrences (EXDATE) skipped.
+Times are shown in --timezone (or the configured timezone).`,
+		Example: `  tempus next -i calendar.ics
+  tempus next -i calendar.ics -n 10 --timezone Europe/Madrid
+  tempus next -i calendar.ics --from "2025-05-01 08:00" --output-format json`,
+		RunE: runNext,
+	}
+	cmd.Flags().StringP("input", "i", "", "Input .ics file")
+	cmd.Flags().IntP("count", "n", 5, "How many events to show")
+	cmd.Flags().String("from", "", "Count down from this time, YYYY-MM-DD HH:MM (default: now)")
+	cmd.Flags().StringArray("filter-category", []string{}, "Only show events in this category or below it, e.g. Work matches Work/ClientA (repeatable)")
+	return cmd
+}
+
+// nextEventJSON is one entry of 'tempus next --output-format json'.
+type nextEventJSON struct {
+	UID             string `json:"uid,omitempty"`
+	Summary         string `json:"summary"`
+	Start           string `json:"start"`
+	End             string `json:"end"`
+	AllDay          bool   `json:"all_day,omitempty"`
+	Location        string `json:"location,omitempty"`
+	InProgress      bool   `json:"in_progress,omitempty"`
+	StartsInMinutes int    `json:"starts_in_minutes"`
+}
+
+func runNext(cmd *cobra.Command, _ []string) error {
+	input, _ := cmd.Flags().GetString("input")
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return fmt.Errorf("--input is required")
+	}
+	count, _ := cmd.Flags().GetInt("count")
+	if count <= 0 {
+		return fmt.Errorf("--count must be positive")
+	}
+	loc := time.UTC
+	if tz := resolveQuickTimezone(cmd); tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", tz, err)
+		}
+		loc = l
+	}
+	from := appClock.Now().In(loc)
+	if v, _ := cmd.Flags().GetString("from"); strings.TrimSpace(v) != "" {
+		t, err := time.ParseInLocation(constants.DateTimeFormatISO, strings.TrimSpace(v), loc)
+		if err != nil {
+			return fmt.Errorf("invalid --from %q (use YYYY-MM-DD HH:MM)", v)
+		}
+		from = t
+	}
+
+	data, err := readICSFile(input)
+	if err != nil {
+		return err
+	}
+	events, err := calendar.ParseICSEvents(data)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", input, err)
+	}
+	categories, _ := cmd.Flags().GetStringArray("filter-category")
+	events = slices.DeleteFunc(events, func(ev calendar.Event) bool {
+		return !calendar.AnyCategoryUnder(ev.Categories, categories)
+	})
+	for i := range events {
+		floatAllDay(&events[i], loc)
+	}
+	upcoming := calendar.Upcoming(events, from, count)
+
+	if jsonReport(cmd) {
+		list := make([]nextEventJSON, 0, len(upcoming))
+		for _, ev := range upcoming {
+			list = append(list, nextEventJSON{
+				UID: ev.UID, Summary: ev.Summary, AllDay: ev.AllDay, Location: ev.Location,
+				Start:           ev.StartTime.In(loc).Format(time.RFC3339),
+				End:             ev.EndTime.In(loc).Format(time.RFC3339),
+				InProgress:      ev.StartTime.Before(from),
+				StartsInMinutes: int(ev.StartTime.Sub(from).Round(time.Minute) / time.Minute),
+			})
+		}
+		return printJSON(cmd.OutOrStdout(), list)
+	}
+
+	w := cmd.OutOrStdout()
+	fromText := displayDate(from) + " " + displayClock(from)
+	if len(upcoming) == 0 {
+		fmt.Fprintln(w, ui.T("next_none", fromText))
+		return nil
+	}
+	fmt.Fprintln(w, ui.T("next_header", len(upcoming), fromText))
+	countdowns := make([]string, len(upcoming))
+	width := 0
+	for i, ev := range upcoming {
+		if ev.StartTime.Before(from) {
+			countdowns[i] = ui.T("next_now", formatSpan(ev.EndTime.Sub(from)))
+		} else {
+			countdowns[i] = ui.T("next_in", formatSpan(ev.StartTime.Sub(from)))
+		}
+		width = max(width, utf8.RuneCountInString(countdowns[i]))
+	}
+	for i, ev := range upcoming {
+		start, end := ev.StartTime.In(loc), ev.EndTime.In(loc)
+		when := displayDate(start) + " " + ui.T("all_day")
+		if !ev.AllDay {
+			when = displayDate(start) + " " + displayClock(start) + "–" + displayClock(end)
+		}
+		line := fmt.Sprintf("  %s%s  %s  %s", countdowns[i], strings.Repeat(" ", width-utf8.RuneCountInString(countdowns[i])), when, ev.Summary)
+		if ev.Location != "" {
+			line += " (" + ev.Location + ")"
+		}
+		fmt.Fprintln(w, line)
+	}
+	return nil
+}
+
+// floatAllDay moves an all-day event (parsed as UTC midnight) to midnight in
+// loc, so it starts and ends with the local day.
+func floatAllDay(ev *calendar.Event, loc *time.Location) {
+	if !ev.AllDay {
+		return
+	}
+	local := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+	ev.StartTime, ev.EndTime = local(ev.StartTime), local(ev.EndTime)
+	for i, x := range ev.ExDates {
+		ev.ExDates[i] = local(x)
+	}
+}
+
+func newFocusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "focus",
+		Short: "Generate a block of alternating focus sessions and breaks (pomodoro)",
+		Long: `Lay out a run of focus sessions separated by breaks, each with its own
+alarm, and write them as a calendar (or add them to an existing one with
+--append). With --batch the sessions are added as rows to a CSV, JSON or YAML
+batch file instead, to be generated together with the rest of the schedule.`,
+		Example: `  tempus focus --start 09:00 --work 50m --break 10m --cycles 4 --tz Europe/Madrid
+  tempus focus --start "2025-05-01 15:00" --long-break 20m -o afternoon.ics
+  tempus focus --start 09:00 --cycles 3 --batch week.csv`,
+		RunE: runFocus,
+	}
+	cmd.Flags().String("start", "", "Start of the first session, HH:MM (today) or YYYY-MM-DD HH:MM")
+	cmd.Flags().String("work", "25m", "Length of each focus session")
+	cmd.Flags().String("break", "5m", "Length of each break (0 for none)")
+	cmd.Flags().String("long-break", "", "Length of every --long-break-every-th break, e.g. 20m")
+	cmd.Flags().Int("long-break-every", 4, "Sessions between long breaks")
+	cmd.Flags().Int("cycles", 4, "Number of focus sessions")
+	cmd.Flags().String("tz", "", "Timezone of the sessions (default: --timezone or the configured timezone)")
+	cmd.Flags().String("summary", "", "Session title (default: Focus, in the output language)")
+	cmd.Flags().StringSlice("category", []string{"Focus"}, "Categories of the focus sessions (breaks get Break)")
+	cmd.Flags().StringArray("alarm", []string{"0m"}, "Alarm for every session and break (repeatable, e.g. -2m)")
+	cmd.Flags().Bool("no-alarm", false, "Do not add alarms")
+	cmd.Flags().StringP("output", "o", "", "Output ICS file (default: focus-YYYY-MM-DD.ics)")
+	cmd.Flags().Bool("append", false, "Add the sessions to --output if it exists")
+	cmd.Flags().String("batch", "", "Add the sessions as rows to this batch file (csv/json/yaml) instead of writing ICS")
+	cmd.Flags().Bool("dry-run", false, "Show the sessions without writing anything")
+	cmd.MarkFlagsMutuallyExclusive("batch", "output")
+	cmd.MarkFlagsMutuallyExclusive("batch", "append")
+	return cmd
+}
+
+// focusOptions holds the parsed flags of 'tempus focus'.
+type focusOptions struct {
+	plan       calendar.FocusPlan
+	tz         string
+	summary    string
+	categories []string
+	alarms     []string
+}
+
+func runFocus(cmd *cobra.Command, _ []string) error {
+	opts, err := parseFocusFlags(cmd)
+	if err != nil {
+		return err
+	}
+	blocks, err := opts.plan.Blocks()
+	if err != nil {
+		return err
+	}
+	events, err := buildFocusEvents(blocks, opts)
+	if err != nil {
+		return err
+	}
+	if err := checkEventHours(events, false); err != nil {
+		return err
+	}
+
+	last := blocks[len(blocks)-1].End
+	fmt.Println(ui.T("focus_plan", opts.plan.Cycles, displayDate(opts.plan.Start)+" "+displayClock(opts.plan.Start), displayClock(last)))
+	for _, ev := range events {
+		fmt.Printf("  • %s–%s  %s\n", displayClock(ev.StartTime), displayClock(ev.EndTime), ev.Summary)
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		return nil
+	}
+
+	policy, err := loadOutputPolicy()
+	if err != nil {
+		return err
+	}
+	if batch, _ := cmd.Flags().GetString("batch"); strings.TrimSpace(batch) != "" {
+		return writeFocusBatch(strings.TrimSpace(batch), blocks, opts, policy)
+	}
+
+	output, _ := cmd.Flags().GetString("output")
+	if strings.TrimSpace(output) == "" {
+		output = "focus-" + opts.plan.Start.Format(constants.DateFormatISO) + ".ics"
+	}
+	appendOutput, _ := cmd.Flags().GetBool("append")
+	claim := policy
+	if appendOutput {
+		claim.Overwrite = config.OverwriteReplace // --append adds to the file on purpose
+	}
+	if output, err = resolveOutputPath(output, claim); err != nil {
+		return err
+	}
+
+	cal := calendar.NewCalendar()
+	cal.IncludeVTZ = true
+	cal.Name = opts.summary
+	if opts.tz != "" {
+		cal.SetDefaultTimezone(opts.tz)
+	}
+	for i := range events {
+		cal.AddEvent(&events[i])
+	}
+	if err := writeBatchICS(cal, output, &batchOptions{appendOutput: appendOutput, outputFormat: "ics", policy: policy}); err != nil {
+		return err
+	}
+	if appendOutput {
+		printOK("%s\n", ui.T("batch_appended", len(events), output))
+		return nil
+	}
+	printOK(constants.MsgCreatedFile, output)
+	return nil
+}
+
+func parseFocusFlags(cmd *cobra.Command) (*focusOptions, error) {
+	start, _ := cmd.Flags().GetString("start")
+	if strings.TrimSpace(start) == "" {
+		return nil, fmt.Errorf("--start is required")
+	}
+	opts := &focusOptions{}
+	tz, _ := cmd.Flags().GetString("tz")
+	opts.tz = firstNonEmpty(strings.TrimSpace(tz), resolveQuickTimezone(cmd))
+	loc := time.Local
+	if opts.tz != "" {
+		l, err := time.LoadLocation(opts.tz)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", opts.tz, err)
+		}
+		loc = l
+	}
+	at, err := time.ParseInLocation(constants.DateTimeFormatISO, prependToday(normalizeDateTimeInput(start), opts.tz), loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --start %q (use HH:MM or YYYY-MM-DD HH:MM)", start)
+	}
+	opts.plan.Start = at
+
+	for _, d := range []struct {
+		flag string
+		dst  *time.Duration
+	}{{"work", &opts.plan.Work}, {"break", &opts.plan.Break}, {"long-break", &opts.plan.LongBreak}} {
+		v, _ := cmd.Flags().GetString(d.flag)
+		if strings.TrimSpace(v) == "" {
+			continue
+		}
+		if *d.dst, err = calendar.ParseHumanDuration(v); err != nil {
+			return nil, fmt.Errorf("invalid --%s %q: %w", d.flag, v, err)
+		}
+	}
+	opts.plan.Cycles, _ = cmd.Flags().GetInt("cycles")
+	opts.plan.LongBreakEvery, _ = cmd.Flags().GetInt("long-break-every")
+
+	summary, _ := cmd.Flags().GetString("summary")
+	opts.summary = firstNonEmpty(strings.TrimSpace(summary), ui.T("focus_session"))
+	opts.categories, _ = cmd.Flags().GetStringSlice("category")
+	if noAlarm, _ := cmd.Flags().GetBool("no-alarm"); !noAlarm {
+		opts.alarms, _ = cmd.Flags().GetStringArray("alarm")
+	}
+	return opts, nil
+}
+
+// focusBlockText returns the title, categories and alarm text of a focus
+// session or break.
+func focusBlockText(b calendar.FocusBlock, opts *focusOptions) (summary string, categories []string, alarm string) {
+	switch {
+	case b.Long:
+		return ui.T("focus_long_break"), []string{"Break"}, ui.T("focus_alarm_break")
+	case b.Break:
+		return ui.T("focus_break"), []string{"Break"}, ui.T("focus_alarm_break")
+	}
+	return fmt.Sprintf("%s %d/%d", opts.summary, b.Cycle, opts.plan.Cycles), opts.categories, ui.T("focus_alarm_session", b.Cycle, opts.plan.Cycles)
+}
+
+// buildFocusEvents turns the blocks into events with the --alarm alarms.
+// Alarms without their own description say what is starting. UIDs derive
+// from the start time, so --append refuses to add the same run twice.
+func buildFocusEvents(blocks []calendar.FocusBlock, opts *focusOptions) ([]calendar.Event, error) {
+	events := make([]calendar.Event, 0, len(blocks))
+	for _, b := range blocks {
+		summary, categories, alarmText := focusBlockText(b, opts)
+		ev := calendar.NewEvent(addEmojiToSummary(summary, categories), b.Start, b.End)
+		ev.UID = calendar.StableUID("focus", b.Start.UTC().Format(time.RFC3339), strconv.FormatBool(b.Break))
+		setEventTimezones(ev, opts.tz, "")
+		addEventCategories(ev, categories)
+		for _, spec := range expandAlarmProfiles(opts.alarms) {
+			al, err := calendar.ParseAlarmSpecs([]string{spec}, opts.tz)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --alarm: %w", err)
+			}
+			for _, a := range al {
+				if !a.TriggerIsRelative {
+					return nil, fmt.Errorf("invalid --alarm %q: focus alarms must be relative to each session, e.g. 0m or -2m", spec)
+				}
+				if !strings.Contains(spec, "description=") {
+					a.Description = alarmText
+				}
+				ev.Alarms = append(ev.Alarms, a)
+			}
+		}
+		events = append(events, *ev)
+	}
+	return events, nil
+}
+
+// writeFocusBatch adds the blocks as rows of a batch file.
+func writeFocusBatch(path string, blocks []calendar.FocusBlock, opts *focusOptions, policy config.OutputPolicy) error {
+	rows := make([]batchRow, 0, len(blocks))
+	for _, b := range blocks {
+		summary, categories, _ := focusBlockText(b, opts)
+		rows = append(rows, batchRow{
+			Summary:    summary,
+			Start:      b.Start.Format(constants.DateTimeFormatISO),
+			Duration:   fmtDurationHuman(b.End.Sub(b.Start)),
+			StartTZ:    opts.tz,
+			Categories: categories,
+			Alarms:     opts.alarms,
+		})
+	}
+	if err := appendBatchRows(path, rows, policy); err != nil {
+		return err
+	}
+	printOK("%s\n", ui.T("focus_batch_added", len(rows), path))
+	return nil
+}
+
+// batchRow is a batch record as written to a batch file by the generators.
+type batchRow struct {
+	Summary     string   `json:"summary" yaml:"summary"`
+	Start       string   `json:"start" yaml:"start"`
+	End         string   `json:"end,omitempty" yaml:"end,omitempty"`
+	Duration    string   `json:"duration,omitempty" yaml:"duration,omitempty"`
+	StartTZ     string   `json:"start_tz,omitempty" yaml:"start_tz,omitempty"`
+	EndTZ       string   `json:"end_tz,omitempty" yaml:"end_tz,omitempty"`
+	Location    string   `json:"location,omitempty" yaml:"location,omitempty"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Categories  []string `json:"categories,omitempty" yaml:"categories,omitempty"`
+	Alarms      []string `json:"alarms,omitempty" yaml:"alarms,omitempty"`
+}
+
+// batchRowColumns is the header of a CSV batch file created by appendBatchRows;
+// batchRowExtraColumns are added after it when a row uses them.
+var (
+	batchRowColumns      = []string{"summary", "start", "duration", "start_tz", "categories", "alarms"}
+	batchRowExtraColumns = []string{"end", "end_tz", "location", "description"}
+)
+
+// csvFields maps the row's non-empty values to their CSV columns.
+func (r batchRow) csvFields() map[string]string {
+	alarmSep := ";"
+	for _, a := range r.Alarms {
+		if strings.Contains(a, "=") {
+			alarmSep = " || " // key=value alarms contain commas
+		}
+	}
+	fields := map[string]string{
+		"summary":     r.Summary,
+		"start":       r.Start,
+		"duration":    r.Duration,
+		"start_tz":    r.StartTZ,
+		"categories":  strings.Join(r.Categories, ";"),
+		"alarms":      strings.Join(r.Alarms, alarmSep),
+		"end":         r.End,
+		"end_tz":      r.EndTZ,
+		"location":    r.Location,
+		"description": r.Description,
+	}
+	maps.DeleteFunc(fields, func(_, v string) bool { return v == "" })
+	return fields
+}
+
+// appendBatchRows adds rows to the end of a CSV, JSON or YAML batch file,
+// creating it if missing. CSV and YAML files keep their text (comments
+// included); a JSON list is re-encoded.
+func appendBatchRows(path string, rows []batchRow, policy config.OutputPolicy) error {
+	format, err := detectBatchFormat("auto", path)
+	if err != nil {
+		return err
+	}
+	existing, err := os.ReadFile(filepath.Clean(path))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var content []byte
+	switch format {
+	case batchFormatCSV:
+		content, err = appendCSVRows(existing, rows)
+	case batchFormatJSON:
+		content, err = appendJSONRows(existing, rows)
+	default:
+		content, err = appendYAMLRows(existing, rows)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot add rows to %s: %w", path, err)
+	}
+	if err := writeGeneratedFile(path, content, policy); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+func appendCSVRows(existing []byte, rows []batchRow) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	header := batchRowColumns
+	if len(bytes.TrimSpace(existing)) > 0 {
+		first, err := csv.NewReader(bytes.NewReader(existing)).Read()
+		if err != nil {
+			return nil, err
+		}
+		header = make([]string, len(first))
+		for i, col := range first {
+			header[i] = strings.ToLower(strings.TrimSpace(col))
+		}
+		buf.Write(existing)
+		if !bytes.HasSuffix(existing, []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+	} else {
+		header = slices.Clone(batchRowColumns)
+		for _, col := range batchRowExtraColumns {
+			if slices.ContainsFunc(rows, func(r batchRow) bool { return r.csvFields()[col] != "" }) {
+				header = append(header, col)
+			}
+		}
+		if err := w.Write(header); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, row := range rows {
+		fields := row.csvFields()
+		record := make([]string, len(header))
+		for i, col := range header {
+			record[i] = fields[col]
+			delete(fields, col)
+		}
+		if missing := slices.Sorted(maps.Keys(fields)); len(missing) > 0 {
+			return nil, fmt.Errorf("the header has no %s column", strings.Join(missing, ", "))
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func appendJSONRows(existing []byte, rows []batchRow) ([]byte, error) {
+	var items []json.RawMessage
+	if len(bytes.TrimSpace(existing)) > 0 {
+		if err := json.Unmarshal(existing, &items); err != nil {
+			return nil, fmt.Errorf("not a JSON list of events: %w", err)
+		}
+	}
+	for _, row := range rows {
+		item, err := json.Marshal(row)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	out, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+func appendYAMLRows(existing []byte, rows []batchRow) ([]byte, error) {
+	var buf bytes.Buffer
+	if len(bytes.TrimSpace(existing)) > 0 {
+		var list []map[string]interface{}
+		if err := yaml.Unmarshal(existing, &list); err != nil {
+			return nil, fmt.Errorf("not a YAML list of events: %w", err)
+		}
+		buf.Write(bytes.TrimRight(existing, "\n"))
+		buf.WriteString("\n\n")
+	}
+	for i, row := range rows {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode([]batchRow{row}); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func newMedsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "meds",
+		Short: "Medication schedule tools",
+	}
+	cmd.AddCommand(newMedsScheduleCmd())
+	return cmd
+}
+
+func newMedsScheduleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Generate a medication calendar, with titration or tapering steps",
+		Long: `Turn a dose schedule into recurring daily events: one series per dose step
+and time of day, each with the medication alarm profile (5 and 1 minutes
+before, and on time). Steps run one after another, so "10mg:1w,20mg:1w,30mg"
+titrates up over two weeks and "30mg:5d,20mg:5d,10mg:5d" tapers down. Skipped
+days (--skip, --skip-days) are left out of the series with EXDATE.`,
+		Example: `  tempus meds schedule --drug Methylphenidate --dose 10mg:1w,20mg:1w,30mg --times 08:00 --duration 8w
+  tempus meds schedule --drug Sertraline --dose 50mg --times 09:00 --start 2025-05-01 --duration 30d --skip 2025-05-10
+  tempus meds schedule --drug Lisdexamfetamine --dose 30mg --times 07:30 --duration 12w --skip-days weekend`,
+		RunE: runMedsSchedule,
+	}
+	cmd.Flags().String("drug", "", "Medication name (required)")
+	cmd.Flags().String("dose", "", "Dose schedule: DOSE[:LENGTH] steps, e.g. 10mg:1w,20mg:2w,30mg (required)")
+	cmd.Flags().StringSlice("times", nil, "Times of day for each dose, e.g. 08:00,20:00 (required)")
+	cmd.Flags().String("start", "", "First day, YYYY-MM-DD (default: today)")
+	cmd.Flags().String("duration", "", "Total length, e.g. 30d or 8w (default: the sum of the step lengths)")
+	cmd.Flags().StringSlice("skip", nil, "Days without a dose, YYYY-MM-DD (repeatable or comma-separated)")
+	cmd.Flags().String("skip-days", "", "Weekdays without a dose, e.g. sat,sun or weekend")
+	cmd.Flags().String("tz", "", "Timezone of the doses (default: --timezone or the configured timezone)")
+	cmd.Flags().String("length", "5m", "Length of each dose event")
+	cmd.Flags().String("notes", "", "Text added to every event's description, e.g. \"Take with food\"")
+	cmd.Flags().StringArray("alarm", []string{"profile:medication"}, "Alarms for every dose (repeatable)")
+	cmd.Flags().Bool("no-alarm", false, "Do not add alarms")
+	cmd.Flags().StringP("output", "o", "", "Output ICS file (default: <drug>-schedule.ics)")
+	cmd.Flags().Bool("dry-run", false, "Show the schedule without writing anything")
+	return cmd
+}
+
+// medsOptions holds the parsed flags of 'tempus meds schedule'.
+type medsOptions struct {
+	drug   string
+	plan   calendar.MedicationPlan
+	tz     string
+	length time.Duration
+	notes  string
+	alarms []string
+}
+
+func runMedsSchedule(cmd *cobra.Command, _ []string) error {
+	opts, err := parseMedsFlags(cmd)
+	if err != nil {
+		return err
+	}
+	series, err := opts.plan.Series()
+	if err != nil {
+		return err
+	}
+	if len(series) == 0 {
+		return fmt.Errorf("every dose falls on a skipped day; nothing to schedule")
+	}
+	events, err := buildMedsEvents(series, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(ui.T("meds_plan", opts.drug, len(opts.plan.Steps), displayDate(series[0].First)))
+	for _, s := range series {
+		line := ui.T("meds_series", s.Step, s.Dose, s.Time, displayDate(s.First), displayDate(medsLastDose(s)), s.Count-len(s.Skipped))
+		if len(s.Skipped) > 0 {
+			line += " " + ui.T("meds_skipped", len(s.Skipped))
+		}
+		fmt.Printf("  • %s\n", line)
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		return nil
+	}
+
+	policy, err := loadOutputPolicy()
+	if err != nil {
+		return err
+	}
+	output, _ := cmd.Flags().GetString("output")
+	if strings.TrimSpace(output) == "" {
+		output = slugify(opts.drug) + "-schedule.ics"
+	}
+	if output, err = resolveOutputPath(output, policy); err != nil {
+		return err
+	}
+
+	cal := calendar.NewCalendar()
+	cal.IncludeVTZ = true
+	cal.Name = opts.drug
+	if opts.tz != "" {
+		cal.SetDefaultTimezone(opts.tz)
+	}
+	for i := range events {
+		cal.AddEvent(&events[i])
+	}
+	return writeCalendarOutput(cal, output, "ics", policy)
+}
+
+func parseMedsFlags(cmd *cobra.Command) (*medsOptions, error) {
+	opts := &medsOptions{}
+	drug, _ := cmd.Flags().GetString("drug")
+	doses, _ := cmd.Flags().GetString("dose")
+	times, _ := cmd.Flags().GetStringSlice("times")
+	opts.drug = strings.TrimSpace(drug)
+	switch {
+	case opts.drug == "":
+		return nil, fmt.Errorf("--drug is required")
+	case strings.TrimSpace(doses) == "":
+		return nil, fmt.Errorf("--dose is required, e.g. --dose 20mg or --dose 10mg:1w,20mg")
+	case len(times) == 0:
+		return nil, fmt.Errorf("--times is required, e.g. --times 08:00,20:00")
+	}
+
+	steps, err := calendar.ParseDoseSteps(doses)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --dose: %w", err)
+	}
+	opts.plan.Steps = steps
+	for _, t := range times {
+		opts.plan.Times = append(opts.plan.Times, strings.TrimSpace(t))
+	}
+
+	tz, _ := cmd.Flags().GetString("tz")
+	opts.tz = firstNonEmpty(strings.TrimSpace(tz), resolveQuickTimezone(cmd))
+	opts.plan.Location = time.Local
+	if opts.tz != "" {
+		loc, err := time.LoadLocation(opts.tz)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", opts.tz, err)
+		}
+		opts.plan.Location = loc
+	}
+	parseDay := func(flag, s string) (time.Time, error) {
+		t, err := time.ParseInLocation(constants.DateFormatISO, normalizeDateTimeInput(s), opts.plan.Location)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid %s %q (use YYYY-MM-DD)", flag, s)
+		}
+		return t, nil
+	}
+
+	opts.plan.Start = appClock.Now().In(opts.plan.Location)
+	if start, _ := cmd.Flags().GetString("start"); strings.TrimSpace(start) != "" {
+		if opts.plan.Start, err = parseDay("--start", start); err != nil {
+			return nil, err
+		}
+	}
+	if dur, _ := cmd.Flags().GetString("duration"); strings.TrimSpace(dur) != "" {
+		d, err := calendar.ParseHumanDuration(dur)
+		if err != nil || d <= 0 || d%(24*time.Hour) != 0 {
+			return nil, fmt.Errorf("invalid --duration %q (use days or weeks, e.g. 30d or 8w)", dur)
+		}
+		opts.plan.Days = int(d / (24 * time.Hour))
+	}
+	skips, _ := cmd.Flags().GetStringSlice("skip")
+	for _, s := range skips {
+		day, err := parseDay("--skip", s)
+		if err != nil {
+			return nil, err
+		}
+		opts.plan.SkipDates = append(opts.plan.SkipDates, day)
+	}
+	if days, _ := cmd.Flags().GetString("skip-days"); strings.TrimSpace(days) != "" {
+		if opts.plan.SkipWeekdays, err = calendar.ParseWeekdays(days); err != nil {
+			return nil, fmt.Errorf("invalid --skip-days: %w", err)
+		}
+	}
+
+	length, _ := cmd.Flags().GetString("length")
+	if opts.length, err = calendar.ParseHumanDuration(length); err != nil || opts.length <= 0 {
+		return nil, fmt.Errorf("invalid --length %q", length)
+	}
+	opts.notes, _ = cmd.Flags().GetString("notes")
+	if noAlarm, _ := cmd.Flags().GetBool("no-alarm"); !noAlarm {
+		opts.alarms, _ = cmd.Flags().GetStringArray("alarm")
+	}
+	return opts, nil
+}
+
+// buildMedsEvents turns each dose series into a daily recurring event. UIDs
+// derive from the drug, step and time, so regenerating the schedule updates
+// the events in calendar apps instead of duplicating them.
+func buildMedsEvents(series []calendar.DoseSeries, opts *medsOptions) ([]calendar.Event, error) {
+	categories := []string{"Medication", "Health"}
+	specs := expandAlarmProfiles(opts.alarms)
+	alarms, err := calendar.ParseAlarmSpecs(specs, opts.tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --alarm: %w", err)
+	}
+
+	events := make([]calendar.Event, 0, len(series))
+	for _, s := range series {
+		summary := addEmojiToSummary(opts.drug+" "+s.Dose, categories)
+		ev := calendar.NewEvent(summary, s.First, s.First.Add(opts.length))
+		ev.UID = calendar.StableUID("meds", opts.drug, strconv.Itoa(s.Step), s.Time, s.First.Format(constants.DateFormatISO))
+		setEventTimezones(ev, opts.tz, "")
+		addEventCategories(ev, categories)
+		if s.Count > 1 {
+			ev.RRule = fmt.Sprintf("FREQ=DAILY;COUNT=%d", s.Count)
+		}
+		ev.ExDates = s.Skipped
+
+		var description []string
+		if len(opts.plan.Steps) > 1 {
+			description = append(description, ui.T("meds_step_description", s.Step, len(opts.plan.Steps), s.Dose, displayDate(s.First), displayDate(medsLastDose(s))))
+		}
+		if notes := strings.TrimSpace(opts.notes); notes != "" {
+			description = append(description, notes)
+		}
+		ev.Description = strings.Join(description, "\n")
+		for i, al := range alarms {
+			if !strings.Contains(specs[i], "description=") {
+				al.Description = ui.T("meds_alarm", opts.drug, s.Dose)
+			}
+			ev.Alarms = append(ev.Alarms, al)
+		}
+		events = append(events, *ev)
+	}
+	return events, nil
+}
+
+// medsLastDose is the time of the series' last scheduled day.
+func medsLastDose(s calendar.DoseSeries) time.Time {
+	return s.First.AddDate(0, 0, s.Count-1)
+}
+
+func newRotaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rota",
+		Short: "Generate shift events from a repeating rota pattern",
+		Long: `Lay a rotating shift pattern over the calendar, such as 4 on / 4 off or
+two earlies, two lates, two nights and four days off. Patterns like these
+don't follow the week, so a single RRULE can't describe them; tempus writes one
+event per shift instead. The --anchor day is the first day of the cycle, so the
+rota stays in step whichever --from day you generate from.
+
+Built-in shifts: early 07:00-15:00, late 15:00-23:00, night 23:00-07:00,
+day 08:00-20:00 and on (all day). Define or override them with --shift, e.g.
+--shift night=22:00-06:30 or --shift standby=all-day. Consecutive all-day
+shifts become one multi-day event. off, rest, free and - are days off.`,
+		Example: `  tempus rota --pattern 4-on/4-off --anchor 2025-05-01 --horizon 8w
+  tempus rota --pattern early*2,late*2,night*2,off*4 --anchor 2025-05-01 --from 2025-06-01 --until 2025-08-31 --tz Europe/Dublin
+  tempus rota --pattern 3-long/4-off --shift long=07:30-20:00 --anchor 2025-05-05 --title "Ward 7" --alarm -1h`,
+		RunE: runRota,
+	}
+	cmd.Flags().String("pattern", "", "Rotation, one step per day or group, e.g. 4-on/4-off or early*2,late*2,off*3 (required)")
+	cmd.Flags().String("anchor", "", "Day the cycle starts on, YYYY-MM-DD (default: --from)")
+	cmd.Flags().String("from", "", "First day to generate, YYYY-MM-DD (default: --anchor or today)")
+	cmd.Flags().String("horizon", "4w", "How far ahead to generate, e.g. 30d or 12w")
+	cmd.Flags().String("until", "", "Last day to generate, YYYY-MM-DD (instead of --horizon)")
+	cmd.Flags().StringArray("shift", nil, "Define a shift: NAME=HH:MM-HH:MM or NAME=all-day (repeatable)")
+	cmd.Flags().String("title", "", "Title for the events, e.g. \"Ward 7\" (default: <Shift> shift)")
+	cmd.Flags().String("location", "", "Location of every shift")
+	cmd.Flags().String("tz", "", "Timezone of the shifts (default: --timezone or the configured timezone)")
+	cmd.Flags().StringSlice("category", []string{"Work"}, "Categories of the shift events")
+	cmd.Flags().StringArray("alarm", nil, "Alarm before every timed shift (repeatable, e.g. -1h)")
+	cmd.Flags().StringP("output", "o", "", "Output ICS file (default: rota.ics)")
+	cmd.Flags().Bool("dry-run", false, "Show the shifts without writing anything")
+	cmd.MarkFlagsMutuallyExclusive("horizon", "until")
+	return cmd
+}
+
+// rotaOptions holds the parsed flags of 'tempus rota'.
+type rotaOptions struct {
+	rota       calendar.Rota
+	from       time.Time
+	days       int
+	tz         string
+	title      string
+	location   string
+	categories []string
+	alarms     []string
+}
+
+func runRota(cmd *cobra.Command, _ []string) error {
+	opts, err := parseRotaFlags(cmd)
+	if err != nil {
+		return err
+	}
+	blocks, err := opts.rota.Blocks(opts.from, opts.days)
+	if err != nil {
+		return err
+	}
+	if len(blocks) == 0 {
+		return fmt.Errorf("no shifts between %s and %s", displayDate(opts.from), displayDate(opts.from.AddDate(0, 0, opts.days-1)))
+	}
+	events, err := buildRotaEvents(blocks, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(ui.T("rota_plan", len(blocks), len(opts.rota.Pattern), displayDate(opts.from), displayDate(opts.from.AddDate(0, 0, opts.days-1))))
+	for i, b := range blocks {
+		when := displayDate(b.Start) + " " + displayClock(b.Start) + "–" + displayClock(b.End)
+		if b.Shift.AllDay {
+			when = displayDate(b.Start)
+			if b.Days > 1 {
+				when += "–" + displayDate(b.End.AddDate(0, 0, -1))
+			}
+		}
+		fmt.Printf("  • %s  %s\n", when, events[i].Summary)
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		return nil
+	}
+
+	policy, err := loadOutputPolicy()
+	if err != nil {
+		return err
+	}
+	output, _ := cmd.Flags().GetString("output")
+	if strings.TrimSpace(output) == "" {
+		output = "rota.ics"
+	}
+	if output, err = resolveOutputPath(output, policy); err != nil {
+		return err
+	}
+
+	cal := calendar.NewCalendar()
+	cal.IncludeVTZ = true
+	cal.Name = firstNonEmpty(opts.title, ui.T("rota_calendar"))
+	if opts.tz != "" {
+		cal.SetDefaultTimezone(opts.tz)
+	}
+	for i := range events {
+		cal.AddEvent(&events[i])
+	}
+	return writeCalendarOutput(cal, output, "ics", policy)
+}
+
+func parseRotaFlags(cmd *cobra.Command) (*rotaOptions, error) {
+	opts := &rotaOptions{}
+	pattern, _ := cmd.Flags().GetString("pattern")
+	if strings.TrimSpace(pattern) == "" {
+		return nil, fmt.Errorf("--pattern is required, e.g. --pattern 4-on/4-off")
+	}
+	var err error
+	if opts.rota.Pattern, err = calendar.ParseRotaPattern(pattern); err != nil {
+		return nil, fmt.Errorf("invalid --pattern: %w", err)
+	}
+	opts.rota.Shifts = maps.Clone(calendar.DefaultRotaShifts)
+	specs, _ := cmd.Flags().GetStringArray("shift")
+	for _, spec := range specs {
+		shift, err := calendar.ParseRotaShift(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --shift: %w", err)
+		}
+		opts.rota.Shifts[shift.Name] = shift
+	}
+
+	tz, _ := cmd.Flags().GetString("tz")
+	opts.tz = firstNonEmpty(strings.TrimSpace(tz), resolveQuickTimezone(cmd))
+	opts.rota.Location = time.Local
+	if opts.tz != "" {
+		loc, err := time.LoadLocation(opts.tz)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", opts.tz, err)
+		}
+		opts.rota.Location = loc
+	}
+	parseDay := func(flag string) (time.Time, bool, error) {
+		s, _ := cmd.Flags().GetString(flag)
+		if strings.TrimSpace(s) == "" {
+			return time.Time{}, false, nil
+		}
+		t, err := time.ParseInLocation(constants.DateFormatISO, normalizeDateTimeInput(s), opts.rota.Location)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid --%s %q (use YYYY-MM-DD)", flag, s)
+		}
+		return t, true, nil
+	}
+
+	anchor, hasAnchor, err := parseDay("anchor")
+	if err != nil {
+		return nil, err
+	}
+	from, hasFrom, err := parseDay("from")
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case hasAnchor && !hasFrom:
+		from = anchor
+	case hasFrom && !hasAnchor:
+		anchor = from
+	case !hasAnchor && !hasFrom:
+		y, m, d := appClock.Now().In(opts.rota.Location).Date()
+		from = time.Date(y, m, d, 0, 0, 0, 0, opts.rota.Location)
+		anchor = from
+	}
+	opts.rota.Anchor, opts.from = anchor, from
+
+	if until, ok, err := parseDay("until"); err != nil {
+		return nil, err
+	} else if ok {
+		if until.Before(from) {
+			return nil, fmt.Errorf("--until %s is before the first day %s", until.Format(constants.DateFormatISO), from.Format(constants.DateFormatISO))
+		}
+		y1, m1, d1 := from.Date()
+		y2, m2, d2 := until.Date()
+		opts.days = int(time.Date(y2, m2, d2, 0, 0, 0, 0, time.UTC).Sub(time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC)).Hours()/24) + 1
+	} else {
+		horizon, _ := cmd.Flags().GetString("horizon")
+		d, err := calendar.ParseHumanDuration(horizon)
+		if err != nil || d < 24*time.Hour || d%(24*time.Hour) != 0 {
+			return nil, fmt.Errorf("invalid --horizon %q (use days or weeks, e.g. 30d or 12w)", horizon)
+		}
+		opts.days = int(d / (24 * time.Hour))
+	}
+
+	title, _ := cmd.Flags().GetString("title")
+	opts.title = strings.TrimSpace(title)
+	location, _ := cmd.Flags().GetString("location")
+	opts.location = strings.TrimSpace(location)
+	opts.categories, _ = cmd.Flags().GetStringSlice("category")
+	opts.alarms, _ = cmd.Flags().GetStringArray("alarm")
+	return opts, nil
+}
+
+// buildRotaEvents turns rota blocks into events, in the same order. UIDs
+// derive from the shift and its day, so regenerating an overlapping range
+// updates the shifts in calendar apps instead of duplicating them.
+func buildRotaEvents(blocks []calendar.RotaBlock, opts *rotaOptions) ([]calendar.Event, error) {
+	alarms, err := calendar.ParseAlarmSpecs(expandAlarmProfiles(opts.alarms), opts.tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --alarm: %w", err)
+	}
+
+	events := make([]calendar.Event, 0, len(blocks))
+	for _, b := range blocks {
+		name := []rune(b.Shift.Name)
+		name[0] = unicode.ToUpper(name[0])
+		summary := ui.T("rota_shift", string(name))
+		if opts.title != "" {
+			summary = opts.title + " (" + string(name) + ")"
+		}
+		ev := calendar.NewEvent(addEmojiToSummary(summary, opts.categories), b.Start, b.End)
+		ev.UID = calendar.StableUID("rota", b.Shift.Name, b.Start.Format(constants.DateFormatISO))
+		ev.Location = opts.location
+		ev.Description = ui.T("rota_description", b.Day, len(opts.rota.Pattern))
+		addEventCategories(ev, opts.categories)
+		if b.Shift.AllDay {
+			ev.AllDay = true
+		} else {
+			setEventTimezones(ev, opts.tz, "")
+			ev.Alarms = append(ev.Alarms, alarms...)
+		}
+		events = append(events, *ev)
+	}
+	return events, nil
+}
+
+func newTravelCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "travel",
+		Short: "Travel itinerary tools",
+	}
+	cmd.AddCommand(newTravelParseCmd())
+	return cmd
+}
+
+func newTravelParseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "parse [file|-]",
+		Short: "Turn pasted booking confirmations into flight, train and hotel events",
+		Long: `Read flight, train and hotel confirmations (pasted text or a saved email) and
+write the trip as events: flights leave in the departure airport's timezone and
+land in the arrival's, hotels get check-in and check-out events, and every
+booking gets the reminders of the travel template (online check-in, leave for
+the airport, security; leave for the station; head to the hotel, pack).
+
+tempus looks for flight numbers (FR1234, IB 3166), train numbers (AVE 3071,
+Eurostar 9014), dates (2025-12-25, 25/12/2025, 25 Dec, 25DEC25, Dec 25, 2025)
+and times (08:30, 8:30pm, 11:05+1). Dates are day first. Airports take their
+timezone from the built-in IATA table; --airport-tz adds or overrides one, and
+airports in neither fall back to --tz. Hotels and trains use the timezone of
+the last flight's arrival, or --tz.
+
+Reads standard input when the file is - or missing.`,
+		Example: `  pbpaste | tempus travel parse
+  tempus travel parse booking.txt --airport-tz EGC=Europe/Paris
+  tempus travel parse booking.txt --tz Europe/Madrid -o trip.ics
+  tempus travel parse booking.txt --batch trip.json --dry-run`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runTravelParse,
+	}
+	cmd.Flags().String("tz", "", "Timezone for bookings with no known zone (default: --timezone or the configured timezone)")
+	cmd.Flags().StringArray("airport-tz", nil, "Timezone of an airport missing from the built-in table: CODE=Zone (repeatable)")
+	cmd.Flags().StringSlice("category", []string{"Travel"}, "Categories added to every event")
+	cmd.Flags().Bool("no-alarms", false, "Leave out the travel reminders")
+	cmd.Flags().StringP("output", "o", "", "Output ICS file (default: trip.ics)")
+	cmd.Flags().String("batch", "", "Add the events as rows of a batch file (.csv, .json or .yaml) instead")
+	cmd.Flags().Bool("dry-run", false, "Show the bookings found without writing anything")
+	cmd.MarkFlagsMutuallyExclusive("output", "batch")
+	return cmd
+}
+
+// travelOptions holds the parsed flags of 'tempus travel parse'.
+type travelOptions struct {
+	tz         string
+	airportTZ  map[string]string
+	categories []string
+	noAlarms   bool
+}
+
+// travelAlarm is a reminder of a travel event: a relative trigger and the
+// locale key of its text.
+type travelAlarm struct {
+	trigger string
+	key     string
+}
+
+// alarmSpecText drops the characters that separate the fields of a
+// key=value alarm spec from an alarm's text.
+var alarmSpecText = strings.NewReplacer(",", "", ";", "", "=", "")
+
+// travelAlarms follow the travel template: check in online the day before,
+// leave three hours ahead, be at security an hour before departure.
+var travelAlarms = map[string][]travelAlarm{
+	calendar.ItineraryFlight: {{"-1d", "travel_alarm_checkin"}, {"-3h", "travel_alarm_airport"}, {"-1h", "travel_alarm_security"}},
+	calendar.ItineraryTrain:  {{"-1h", "travel_alarm_station"}, {"-15m", "travel_alarm_platform"}},
+	"hotel-in":               {{"-1h", "travel_alarm_hotel"}},
+	"hotel-out":              {{"-30m", "travel_alarm_checkout"}},
+}
+
+func runTravelParse(cmd *cobra.Command, args []string) error {
+	opts, err := parseTravelFlags(cmd)
+	if err != nil {
+		return err
+	}
+	var data []byte
+	if len(args) == 0 || args[0] == "-" {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(filepath.Clean(args[0]))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the confirmation: %w", err)
+	}
+
+	items, notes := calendar.ParseItinerary(string(data), appClock.Now())
+	var warnings []diag.Warning
+	for _, note := range notes {
+		warnings = append(warnings, diag.Warning{Code: diag.CodeItinerary, Severity: diag.SeverityWarning, Message: note})
+	}
+	if len(items) == 0 {
+		diag.Render(os.Stderr, warnings)
+		return fmt.Errorf("no flights, trains or hotel stays found")
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Depart.Before(items[j].Depart) })
+
+	events, rows, tzWarnings, err := buildTravelEvents(items, opts)
+	if err != nil {
+		return err
+	}
+	warnings = append(warnings, tzWarnings...)
+
+	fmt.Println(ui.T("travel_found", len(items)))
+	for _, ev := range events {
+		fmt.Printf("  • %s %s  %s\n", displayDate(ev.StartTime), displayClock(ev.StartTime), ev.Summary)
+	}
+	diag.Render(os.Stderr, warnings)
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		return nil
+	}
+
+	policy, err := loadOutputPolicy()
+	if err != nil {
+		return err
+	}
+	if batch, _ := cmd.Flags().GetString("batch"); strings.TrimSpace(batch) != "" {
+		if err := appendBatchRows(batch, rows, policy); err != nil {
+			return err
+		}
+		printOK("%s\n", ui.T("travel_batch_added", len(rows), batch))
+		return nil
+	}
+	output, _ := cmd.Flags().GetString("output")
+	if strings.TrimSpace(output) == "" {
+		output = "trip.ics"
+	}
+	if output, err = resolveOutputPath(output, policy); err != nil {
+		return err
+	}
+
+	cal := calendar.NewCalendar()
+	cal.IncludeVTZ = true
+	cal.Name = ui.T("travel_calendar")
+	if opts.tz != "" {
+		cal.SetDefaultTimezone(opts.tz)
+	}
+	for i := range events {
+		cal.AddEvent(&events[i])
+	}
+	return writeCalendarOutput(cal, output, "ics", policy)
+}
+
+// travelAirportLocation names an airport as "Madrid Barajas (MAD)" when the
+// airport table knows it.
+func travelAirportLocation(code string) string {
+	if airport, ok := tzpkg.LookupAirport(code); ok {
+		return fmt.Sprintf("%s (%s)", airport.Name, airport.Code)
+	}
+	return ui.T("travel_airport", code)
+}
+
+func parseTravelFlags(cmd *cobra.Command) (*travelOptions, error) {
+	opts := &travelOptions{airportTZ: map[string]string{}}
+	tz, _ := cmd.Flags().GetString("tz")
+	opts.tz = firstNonEmpty(strings.TrimSpace(tz), resolveQuickTimezone(cmd))
+	if opts.tz != "" {
+		if _, err := time.LoadLocation(opts.tz); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", opts.tz, err)
+		}
+	}
+	specs, _ := cmd.Flags().GetStringArray("airport-tz")
+	for _, spec := range specs {
+		code, zone, ok := strings.Cut(spec, "=")
+		code, zone = strings.ToUpper(strings.TrimSpace(code)), strings.TrimSpace(zone)
+		if !ok || len(code) != 3 || zone == "" {
+			return nil, fmt.Errorf("invalid --airport-tz %q (want CODE=Zone, e.g. DUB=Europe/Dublin)", spec)
+		}
+		if _, err := time.LoadLocation(zone); err != nil {
+			return nil, fmt.Errorf("invalid --airport-tz %q: %w", spec, err)
+		}
+		opts.airportTZ[code] = zone
+	}
+	opts.categories, _ = cmd.Flags().GetStringSlice("category")
+	opts.noAlarms, _ = cmd.Flags().GetBool("no-alarms")
+	return opts, nil
+}
+
+// buildTravelEvents places the bookings in their timezones and returns them
+// as events and as the equivalent batch rows, in the same order. Hotels
+// become a check-in and a check-out event.
+func buildTravelEvents(items []calendar.ItineraryItem, opts *travelOptions) ([]calendar.Event, []batchRow, []diag.Warning, error) {
+	var events []calendar.Event
+	var rows []batchRow
+	var warnings []diag.Warning
+	warned := map[string]bool{}
+	airportTZ := func(code string) string {
+		if zone, ok := opts.airportTZ[code]; ok {
+			return zone
+		}
+		if zone, ok := tzpkg.AirportTimezone(code); ok {
+			return zone
+		}
+		if !warned[code] {
+			warned[code] = true
+			warnings = append(warnings, diag.Warning{
+				Code: diag.CodeItinerary, Severity: diag.SeverityWarning,
+				Message: ui.T("travel_unknown_airport", code, firstNonEmpty(opts.tz, time.Local.String()), code),
+			})
+		}
+		return opts.tz
+	}
+	in := func(wall time.Time, zone string) (time.Time, error) {
+		loc := time.Local
+		if zone != "" {
+			var err error
+			if loc, err = time.LoadLocation(zone); err != nil {
+				return time.Time{}, fmt.Errorf("invalid timezone %q: %w", zone, err)
+			}
+		}
+		y, m, d := wall.Date()
+		return time.Date(y, m, d, wall.Hour(), wall.Minute(), 0, 0, loc), nil
+	}
+	add := func(kind, summary string, start, end time.Time, startTZ, endTZ, location, description string, categories []string, alarms string, uidParts ...string) error {
+		for _, c := range opts.categories {
+			if !slices.ContainsFunc(categories, func(have string) bool { return strings.EqualFold(have, c) }) {
+				categories = append(categories, c)
+			}
+		}
+		ev := calendar.NewEvent(addEmojiToSummary(summary, categories), start, end)
+		ev.UID = calendar.StableUID(append([]string{"travel", kind}, uidParts...)...)
+		ev.Location = location
+		ev.Description = description
+		setEventTimezones(ev, startTZ, endTZ)
+		addEventCategories(ev, categories)
+		row := batchRow{
+			Summary:     summary,
+			Start:       start.Format(constants.DateTimeFormatISO),
+			End:         end.Format(constants.DateTimeFormatISO),
+			StartTZ:     startTZ,
+			Location:    location,
+			Description: description,
+			Categories:  categories,
+		}
+		if endTZ != startTZ {
+			row.EndTZ = endTZ
+		}
+		if !opts.noAlarms {
+			for _, a := range travelAlarms[alarms] {
+				parsed, err := calendar.ParseAlarmSpecs([]string{a.trigger}, startTZ)
+				if err != nil {
+					return err
+				}
+				text := ui.T(a.key)
+				for _, al := range parsed {
+					al.Description = text
+					ev.Alarms = append(ev.Alarms, al)
+				}
+				row.Alarms = append(row.Alarms, "trigger="+a.trigger+",description="+alarmSpecText.Replace(text))
+			}
+		}
+		events = append(events, *ev)
+		rows = append(rows, row)
+		return nil
+	}
+
+	localTZ := opts.tz
+	for _, item := range items {
+		reference := ""
+		if item.Reference != "" {
+			reference = ui.T("travel_reference", item.Reference)
+		}
+		switch item.Kind {
+		case calendar.ItineraryFlight:
+			fromTZ, toTZ := airportTZ(item.From), airportTZ(item.To)
+			depart, err := in(item.Depart, fromTZ)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			arrive, err := in(item.Arrive.AddDate(0, 0, item.NextDay), toTZ)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			// Arrival times without a date or +1 belong to the next day when
+			// the flight would otherwise land before it leaves.
+			for i := 0; i < 2 && !arrive.After(depart); i++ {
+				arrive = arrive.AddDate(0, 0, 1)
+			}
+			if !arrive.After(depart) {
+				return nil, nil, nil, fmt.Errorf("flight %s lands before it departs; check the times and --airport-tz", item.Number)
+			}
+			err = add(item.Kind, ui.T("travel_flight", item.Number, item.From, item.To), depart, arrive, fromTZ, toTZ,
+				travelAirportLocation(item.From), reference, []string{"Travel", "Flight"}, item.Kind, item.Number, item.Depart.Format(constants.DateFormatISO))
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			localTZ = toTZ
+		case calendar.ItineraryTrain:
+			depart, err := in(item.Depart, localTZ)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			arrive, err := in(item.Arrive.AddDate(0, 0, item.NextDay), localTZ)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if !arrive.After(depart) {
+				arrive = arrive.AddDate(0, 0, 1)
+			}
+			route := strings.TrimSpace(item.From + " → " + item.To)
+			if item.From == "" || item.To == "" {
+				route = firstNonEmpty(item.From, item.To)
+			}
+			err = add(item.Kind, strings.Join(strings.Fields(ui.T("travel_train", item.Number, route)), " "), depart, arrive, localTZ, localTZ,
+				item.From, reference, []string{"Train", "Travel"}, item.Kind, item.Number, item.Depart.Format(constants.DateFormatISO))
+			if err != nil {
+				return nil, nil, nil, err
+			}
+		case calendar.ItineraryHotel:
+			checkIn, err := in(item.Depart, localTZ)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			checkOut, err := in(item.Arrive, localTZ)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			location := item.Name
+			if item.Address != "" {
+				location = strings.TrimPrefix(location+", "+item.Address, ", ")
+			}
+			name := firstNonEmpty(item.Name, ui.T("travel_hotel_unnamed"))
+			categories := []string{"Accommodation", "Travel"}
+			if err = add("hotel-in", ui.T("travel_checkin", name), checkIn, checkIn.Add(30*time.Minute), localTZ, localTZ,
+				location, reference, categories, "hotel-in", name, item.Depart.Format(constants.DateFormatISO)); err != nil {
+				return nil, nil, nil, err
+			}
+			if err = add("hotel-out", ui.T("travel_checkout", name), checkOut, checkOut.Add(30*time.Minute), localTZ, localTZ,
+				location, reference, categories, "hotel-out", name, item.Arrive.Format(constants.DateFormatISO)); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+	}
+	return events, rows, warnings, nil
+}
+
+func newBirthdaysCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "birthdays",
+		Short: "Turn contacts' birthdays and anniversaries into yearly events",
+		Long: `Read a vCard file exported from your contacts (Google Contacts, Apple
+Contacts, Outlook) and write one yearly all-day event per birthday (BDAY) and
+anniversary (ANNIVERSARY, or Apple's labelled dates). Each event starts on the
+next time the date comes round and repeats every year; the year of birth, when
+the card has one, goes in the description.
+
+February 29th dates need a rule for common years: --feb29 feb28 (default)
+keeps them on the last day of February, mar1 on March 1st and leap only in
+leap years.`,
+		Example: `  tempus birthdays --input contacts.vcf
+  tempus birthdays -i contacts.vcf --alarm -2w --alarm -1d --no-anniversaries
+  tempus birthdays -i contacts.vcf --feb29 mar1 -o family-birthdays.ics --dry-run`,
+		RunE: runBirthdays,
+	}
+	cmd.Flags().StringP("input", "i", "", "vCard file (.vcf); - reads standard input (required)")
+	cmd.Flags().StringArray("alarm", []string{"-1w", "-1d"}, "Reminder before each date (repeatable)")
+	cmd.Flags().String("feb29", calendar.Feb29OnFeb28, "Where February 29th dates fall in common years: feb28, mar1 or leap")
+	cmd.Flags().Bool("no-anniversaries", false, "Only import birthdays")
+	cmd.Flags().StringSlice("category", nil, "Categories of the events (default: Birthday or Anniversary)")
+	cmd.Flags().String("from", "", "Start each series on the first occurrence on or after this day, YYYY-MM-DD (default: today)")
+	cmd.Flags().StringP("output", "o", "", "Output ICS file (default: birthdays.ics)")
+	cmd.Flags().Bool("dry-run", false, "Show the dates found without writing anything")
+	return cmd
+}
+
+// birthdaysOptions holds the parsed flags of 'tempus birthdays'.
+type birthdaysOptions struct {
+	input           string
+	alarms          []string
+	feb29           string
+	noAnniversaries bool
+	categories      []string
+	from            time.Time
+}
+
+func runBirthdays(cmd *cobra.Command, _ []string) error {
+	opts, err := parseBirthdaysFlags(cmd)
+	if err != nil {
+		return err
+	}
+	var data []byte
+	if opts.input == "-" {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(filepath.Clean(opts.input))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", opts.input, err)
+	}
+
+	dates, notes := calendar.ParseVCards(string(data))
+	if opts.noAnniversaries {
+		dates = slices.DeleteFunc(dates, func(d calendar.ContactDate) bool { return d.Kind == calendar.ContactAnniversary })
+	}
+	var warnings []diag.Warning
+	for _, note := range notes {
+		warnings = append(warnings, diag.Warning{Code: diag.CodeInvalidRow, Severity: diag.SeverityWarning, File: opts.input, Message: note})
+	}
+	if len(dates) == 0 {
+		diag.Render(os.Stderr, warnings)
+		return fmt.Errorf("no birthdays or anniversaries found in %s", opts.input)
+	}
+	events, err := buildBirthdayEvents(dates, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(ui.T("birthdays_found", len(dates)))
+	for i, d := range dates {
+		fmt.Printf("  • %s  %s\n", displayDate(events[i].StartTime), events[i].Summary)
+		if d.IsFeb29() {
+			note := ui.T("birthdays_feb29_feb28")
+			switch opts.feb29 {
+			case calendar.Feb29OnMar1:
+				note = ui.T("birthdays_feb29_mar1")
+			case calendar.Feb29Leap:
+				note = ui.T("birthdays_feb29_leap")
+			}
+			fmt.Printf("    %s\n", note)
+		}
+	}
+	diag.Render(os.Stderr, warnings)
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		return nil
+	}
+
+	policy, err := loadOutputPolicy()
+	if err != nil {
+		return err
+	}
+	output, _ := cmd.Flags().GetString("output")
+	if strings.TrimSpace(output) == "" {
+		output = "birthdays.ics"
+	}
+	if output, err = resolveOutputPath(output, policy); err != nil {
+		return err
+	}
+
+	cal := calendar.NewCalendar()
+	cal.Name = ui.T("birthdays_calendar")
+	for i := range events {
+		cal.AddEvent(&events[i])
+	}
+	return writeCalendarOutput(cal, output, "ics", policy)
+}
+
+func parseBirthdaysFlags(cmd *cobra.Command) (*birthdaysOptions, error) {
+	opts := &birthdaysOptions{}
+	opts.input, _ = cmd.Flags().GetString("input")
+	if opts.input = strings.TrimSpace(opts.input); opts.input == "" {
+		return nil, fmt.Errorf("--input is required, e.g. --input contacts.vcf")
+	}
+	opts.feb29, _ = cmd.Flags().GetString("feb29")
+	opts.feb29 = strings.ToLower(strings.TrimSpace(opts.feb29))
+	if !calendar.ValidFeb29Policy(opts.feb29) {
+		return nil, fmt.Errorf("invalid --feb29 %q (use feb28, mar1 or leap)", opts.feb29)
+	}
+	opts.alarms, _ = cmd.Flags().GetStringArray("alarm")
+	opts.noAnniversaries, _ = cmd.Flags().GetBool("no-anniversaries")
+	opts.categories, _ = cmd.Flags().GetStringSlice("category")
+
+	opts.from = appClock.Now()
+	if from, _ := cmd.Flags().GetString("from"); strings.TrimSpace(from) != "" {
+		t, err := time.Parse(constants.DateFormatISO, normalizeDateTimeInput(from))
+		if err != nil {
+			return nil, fmt.Errorf("invalid --from %q (use YYYY-MM-DD)", from)
+		}
+		opts.from = t
+	}
+	return opts, nil
+}
+
+// buildBirthdayEvents turns contact dates into yearly all-day events, in the
+// same order. UIDs derive from the contact and the date, so importing the
+// contacts again updates the events instead of duplicating them.
+func buildBirthdayEvents(dates []calendar.ContactDate, opts *birthdaysOptions) ([]calendar.Event, error) {
+	specs := expandAlarmProfiles(opts.alarms)
+	alarms, err := calendar.ParseAlarmSpecs(specs, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid --alarm: %w", err)
+	}
+	events := make([]calendar.Event, 0, len(dates))
+	for _, d := range dates {
+		categories := opts.categories
+		summaryKey, descriptionKey := "birthdays_birthday", "birthdays_born"
+		if d.Kind == calendar.ContactAnniversary {
+			summaryKey, descriptionKey = "birthdays_anniversary", "birthdays_since"
+		}
+		if len(categories) == 0 {
+			categories = []string{"Birthday"}
+			if d.Kind == calendar.ContactAnniversary {
+				categories = []string{"Anniversary"}
+			}
+		}
+		start := d.Next(opts.from, opts.feb29)
+		ev := calendar.NewEvent(addEmojiToSummary(ui.T(summaryKey, d.Name), categories), start, start.AddDate(0, 0, 1))
+		ev.AllDay = true
+		ev.RRule = d.YearlyRule(opts.feb29)
+		ev.UID = calendar.StableUID("birthdays", d.Kind, d.Name, fmt.Sprintf("%02d-%02d", d.Month, d.Day))
+		if d.Year > 0 {
+			ev.Description = ui.T(descriptionKey, time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, time.UTC).Format(constants.DateFormatISO))
+		}
+		addEventCategories(ev, categories)
+		for i, al := range alarms {
+			if !strings.Contains(specs[i], "description=") {
+				al.Description = ev.Summary
+			}
+			ev.Alarms = append(ev.Alarms, al)
+		}
+		events = append(events, *ev)
+	}
+	return events, nil
+}
+
+func newCountdownCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "countdown <title>",
+		Short: "Generate all-day reminders counting down to a date",
+		Long: `Lead up to a deadline, exam, trip or launch with one all-day event per
+milestone ("Thesis deadline in 30 days", ..., "Thesis deadline tomorrow") and
+an event on the day itself. Each milestone has its own alarm at --alarm-time
+on that day. Milestones that have already passed are left out.`,
+		Example: `  tempus countdown "Thesis deadline" --date 2026-06-01 --milestones 90d,60d,30d,14d,7d,1d
+  tempus countdown "Marathon" --date 2026-04-19 --milestones 12w,8w,4w,1w --alarm-time 07:30
+  tempus countdown "Visa renewal" --date 2026-09-30 --no-target --no-alarms -o visa.ics`,
+		Args: cobra.ExactArgs(1),
+		RunE: runCountdown,
+	}
+	cmd.Flags().String("date", "", "Target date, YYYY-MM-DD (required)")
+	cmd.Flags().String("milestones", "30d,14d,7d,1d", "How long before the date to remind, in days or weeks")
+	cmd.Flags().String("alarm-time", "09:00", "Time of day the alarm of each milestone goes off")
+	cmd.Flags().Bool("no-alarms", false, "Write the milestones without alarms")
+	cmd.Flags().Bool("no-target", false, "Leave out the event on the target date itself")
+	cmd.Flags().StringSlice("category", []string{"Countdown"}, "Categories of the events")
+	cmd.Flags().StringP("output", "o", "", "Output ICS file (default: <title>-countdown.ics)")
+	cmd.Flags().Bool("dry-run", false, "Show the milestones without writing anything")
+	return cmd
+}
+
+// countdownOptions holds the parsed arguments of 'tempus countdown'.
+type countdownOptions struct {
+	title      string
+	target     time.Time
+	milestones []int
+	alarmAt    int // minutes after midnight; -1 for no alarms
+	noTarget   bool
+	categories []string
+}
+
+func runCountdown(cmd *cobra.Command, args []string) error {
+	opts, err := parseCountdownFlags(cmd, args)
+	if err != nil {
+		return err
+	}
+	milestones, skipped := calendar.CountdownMilestones(opts.target, opts.milestones, appClock.Now())
+	if !opts.noTarget {
+		milestones = append(milestones, calendar.Milestone{Day: opts.target})
+	}
+	if len(milestones) == 0 {
+		return fmt.Errorf("every milestone before %s has already passed", displayDate(opts.target))
+	}
+	events := buildCountdownEvents(milestones, opts)
+
+	fmt.Println(ui.T("countdown_plan", len(events), opts.title, displayDate(opts.target)))
+	for _, ev := range events {
+		fmt.Printf("  • %s  %s\n", displayDate(ev.StartTime), ev.Summary)
+	}
+	for _, m := range skipped {
+		fmt.Println(ui.T("countdown_skipped", m.DaysLeft, displayDate(m.Day)))
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		return nil
+	}
+
+	policy, err := loadOutputPolicy()
+	if err != nil {
+		return err
+	}
+	output, _ := cmd.Flags().GetString("output")
+	if strings.TrimSpace(output) == "" {
+		output = slugify(opts.title) + "-countdown.ics"
+	}
+	if output, err = resolveOutputPath(output, policy); err != nil {
+		return err
+	}
+
+	cal := calendar.NewCalendar()
+	cal.Name = opts.title
+	for i := range events {
+		cal.AddEvent(&events[i])
+	}
+	return writeCalendarOutput(cal, output, "ics", policy)
+}
+
+func parseCountdownFlags(cmd *cobra.Command, args []string) (*countdownOptions, error) {
+	opts := &countdownOptions{title: strings.TrimSpace(args[0])}
+	if opts.title == "" {
+		return nil, fmt.Errorf("the countdown needs a title")
+	}
+	date, _ := cmd.Flags().GetString("date")
+	if strings.TrimSpace(date) == "" {
+		return nil, fmt.Errorf("--date is required, e.g. --date 2026-06-01")
+	}
+	var err error
+	if opts.target, err = time.Parse(constants.DateFormatISO, normalizeDateTimeInput(date)); err != nil {
+		return nil, fmt.Errorf("invalid --date %q (use YYYY-MM-DD)", date)
+	}
+	spec, _ := cmd.Flags().GetString("milestones")
+	if opts.milestones, err = calendar.ParseMilestones(spec); err != nil {
+		return nil, fmt.Errorf("invalid --milestones: %w", err)
+	}
+
+	opts.alarmAt = -1
+	if noAlarms, _ := cmd.Flags().GetBool("no-alarms"); !noAlarms {
+		at, _ := cmd.Flags().GetString("alarm-time")
+		clock, err := time.Parse("15:04", strings.TrimSpace(at))
+		if err != nil {
+			return nil, fmt.Errorf("invalid --alarm-time %q (use HH:MM)", at)
+		}
+		opts.alarmAt = clock.Hour()*60 + clock.Minute()
+	}
+	opts.noTarget, _ = cmd.Flags().GetBool("no-target")
+	opts.categories, _ = cmd.Flags().GetStringSlice("category")
+	return opts, nil
+}
+
+// buildCountdownEvents turns milestones into all-day events, in the same
+// order. The milestone with DaysLeft 0 is the target day itself.
+func buildCountdownEvents(milestones []calendar.Milestone, opts *countdownOptions) []calendar.Event {
+	events := make([]calendar.Event, 0, len(milestones))
+	for _, m := range milestones {
+		var summary string
+		switch m.DaysLeft {
+		case 0:
+			summary = ui.T("countdown_today", opts.title)
+		case 1:
+			summary = ui.T("countdown_tomorrow", opts.title)
+		default:
+			summary = ui.T("countdown_days", opts.title, m.DaysLeft)
+		}
+		ev := calendar.NewEvent(addEmojiToSummary(summary, opts.categories), m.Day, m.Day.AddDate(0, 0, 1))
+		ev.AllDay = true
+		ev.UID = calendar.StableUID("countdown", opts.title, opts.target.Format(constants.DateFormatISO), strconv.Itoa(m.DaysLeft))
+		ev.Description = ui.T("countdown_description", opts.title, displayDate(opts.target))
+		addEventCategories(ev, opts.categories)
+		if opts.alarmAt >= 0 {
+			ev.Alarms = append(ev.Alarms, calendar.Alarm{
+				Action:            constants.AlarmActionDisplay,
+				Description:       ev.Summary,
+				TriggerIsRelative: true,
+				TriggerDuration:   time.Duration(opts.alarmAt) * time.Minute,
+			})
+		}
+		events = append(events, *ev)
+	}
+	return events
+}
+
+func newDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff <old.ics> <new.ics>",
+		Short: "Show added, removed and modified events between two ICS files",
+		Long: `Compare two calendars before re-importing a regenerated file. Events are
+matched by UID, then by summary + start for the rest (so regenerated files with
+new UIDs still line up). DTSTAMP, CREATED, LAST-MODIFIED and SEQUENCE are ignored.`,
+		Args: cobra.ExactArgs(2),
+		RunE: runDiff,
+	}
+	cmd.Flags().Bool("json", false, "Print the diff as JSON")
+	cmd.Flags().Bool("exit-code", false, "Exit with an error when the calendars differ")
+	return cmd
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	oldData, err := os.ReadFile(filepath.Clean(args[0]))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", args[0], err)
+	}
+	newData, err := os.ReadFile(filepath.Clean(args[1]))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", args[1], err)
+	}
+
+	diff, err := calendar.DiffICS(string(oldData), string(newData))
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput || jsonReport(cmd) {
+		if err := printJSON(out, diff); err != nil {
+			return err
+		}
+	} else {
+		printCalendarDiff(out, diff)
+	}
+
+	if exitCode, _ := cmd.Flags().GetBool("exit-code"); exitCode && !diff.Empty() {
+		return fmt.Errorf("calendars differ")
+	}
+	return nil
+}
+
+func printCalendarDiff(w io.Writer, diff calendar.CalendarDiff) {
+	for _, e := range diff.Added {
+		fmt.Fprintf(w, "+ %s (%s)\n", diffEventLabel(e), e.Start)
+	}
+	for _, e := range diff.Removed {
+		fmt.Fprintf(w, "- %s (%s)\n", diffEventLabel(e), e.Start)
+	}
+	for _, e := range diff.Modified {
+		fmt.Fprintf(w, "~ %s (%s)\n", diffEventLabel(e), e.Start)
+		for _, c := range e.Changes {
+			fmt.Fprintf(w, "    %s: %s → %s\n", c.Field, diffValue(c.Old), diffValue(c.New))
+		}
+	}
+	if diff.Empty() {
+		fmt.Fprintf(w, "No differences (%d event(s) unchanged)\n", diff.Unchanged)
+		return
+	}
+	fmt.Fprintf(w, "\n%d added, %d removed, %d modified, %d unchanged\n",
+		len(diff.Added), len(diff.Removed), len(diff.Modified), diff.Unchanged)
+}
+
+func diffEventLabel(e calendar.DiffEvent) string {
+	if strings.TrimSpace(e.Summary) != "" {
+		return e.Summary
+	}
+	if e.UID != "" {
+		return e.UID
+	}
+	return "(untitled)"
+}
+
+func diffValue(v string) string {
+	if v == "" {
+		return "(none)"
+	}
+	return strconv.Quote(v)
+}
+
+// statsLargeICS is the size above which stats warns that phones may struggle
+// to import a calendar.
+const statsLargeICS = 1 << 20
+
+func newStatsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Summarize an ICS or batch file: counts, date range, timezones and size",
+		Long: `Count the events of an .ics file or a batch file (CSV/JSON/YAML/TOML) by
+category, month and day, show the first and last event, recurring series,
+alarms and timezones, and estimate the size of the calendar. Batch files are
+measured as the .ics that tempus batch would write. Warnings point out what
+tends to break imports on phones: very large files, timezones without a
+VTIMEZONE and unfolded long lines.`,
+		Example: `  tempus stats -i calendar.ics
+  tempus stats -i semester.yaml --json`,
+		RunE: runStats,
+	}
+	cmd.Flags().StringP("input", "i", "", "Input .ics or batch file (csv/json/yaml/toml)")
+	cmd.Flags().String("format", "auto", "Batch input format: auto, csv, json, yaml, toml, timetable")
+	cmd.Flags().String("default-tz", "", "Default timezone for batch rows without one")
+	cmd.Flags().Bool("json", false, "Print the statistics as JSON")
+	return cmd
+}
+
+// statsReport is the JSON form of stats.
+type statsReport struct {
+	File string `json:"file"`
+	calendar.Stats
+	Warnings []string `json:"warnings"`
+}
+
+func runStats(cmd *cobra.Command, _ []string) error {
+	input, _ := cmd.Flags().GetString("input")
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return fmt.Errorf("--input is required")
+	}
+
+	var data string
+	if strings.EqualFold(filepath.Ext(input), ".ics") {
+		var err error
+		if data, err = readICSFile(input); err != nil {
+			return err
+		}
+	} else {
+		formatFlag, _ := cmd.Flags().GetString("format")
+		defaultTZ, _ := cmd.Flags().GetString("default-tz")
+		body, err := batchFileICS(input, formatFlag, defaultTZ)
+		if err != nil {
+			return err
+		}
+		data = string(body)
+	}
+
+	stats, err := calendar.ComputeStats(data)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", input, err)
+	}
+	warnings := statsWarnings(stats)
+
+	w := cmd.OutOrStdout()
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON || jsonReport(cmd) {
+		if warnings == nil {
+			warnings = []string{}
+		}
+		return printJSON(w, statsReport{File: input, Stats: stats, Warnings: warnings})
+	}
+	printStats(w, input, stats)
+	for _, msg := range warnings {
+		output.Warn(cmd.ErrOrStderr(), "%s\n", msg)
+	}
+	return nil
+}
+
+// batchFileICS builds the calendar for a batch file and renders it as the
+// .ics that batch would write.
+func batchFileICS(input, formatFlag, defaultTZ string) ([]byte, error) {
+	opts := &batchOptions{input: input, formatFlag: formatFlag, defaultTZ: defaultTZ}
+	records, _, err := loadBatchInput(opts)
+	if err != nil {
+		return nil, err
+	}
+	cal, validationErrors, err := buildBatchCalendar(records, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(validationErrors) > 0 {
+		return nil, fmt.Errorf("%s has invalid rows:\n  %s", input, strings.Join(diag.Messages(validationErrors), "\n  "))
+	}
+	return renderCalendar(cal, "ics")
+}
+
+// statsWarnings lists what in s is likely to trouble calendar apps.
+func statsWarnings(s calendar.Stats) []string {
+	var warnings []string
+	if s.Bytes > statsLargeICS {
+		warnings = append(warnings, ui.T("stats_warn_large", formatBytes(s.Bytes)))
+	}
+	if len(s.MissingTZ) > 0 {
+		warnings = append(warnings, ui.T("stats_warn_vtimezone", strings.Join(s.MissingTZ, ", ")))
+	}
+	if s.LongLines > 0 {
+		warnings = append(warnings, ui.T("stats_warn_long_lines", s.LongLines))
+	}
+	return warnings
+}
+
+func printStats(w io.Writer, name string, s calendar.Stats) {
+	fmt.Fprintln(w, ui.T("stats_header", name, s.Events))
+	if s.Events == 0 {
+		return
+	}
+	fmt.Fprintln(w, "  "+ui.T("stats_series", s.Series, s.Overrides))
+	fmt.Fprintln(w, "  "+ui.T("stats_all_day", s.AllDay))
+	fmt.Fprintln(w, "  "+ui.T("stats_alarms", s.Alarms))
+	fmt.Fprintln(w, "  "+ui.T("stats_first", displayRecordTime(s.First.Start), s.First.Summary))
+	fmt.Fprintln(w, "  "+ui.T("stats_last", displayRecordTime(s.Last.Start), s.Last.Summary))
+	zones := ui.T("stats_floating")
+	if len(s.Timezones) > 0 {
+		zones = strings.Join(s.Timezones, ", ")
+	}
+	fmt.Fprintln(w, "  "+ui.T("stats_timezones", zones))
+	fmt.Fprintln(w, "  "+ui.T("stats_size", formatBytes(s.Bytes), s.Lines, formatBytes(s.Bytes/s.Events)))
+
+	fmt.Fprintln(w, "\n"+ui.T("stats_by_category"))
+	categories := make([]string, 0, len(s.Categories))
+	for c := range s.Categories {
+		categories = append(categories, c)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if s.Categories[categories[i]] != s.Categories[categories[j]] {
+			return s.Categories[categories[i]] > s.Categories[categories[j]]
+		}
+		return categories[i] < categories[j]
+	})
+	for _, c := range categories {
+		fmt.Fprintf(w, "  %5d  %s\n", s.Categories[c], c)
+	}
+	if s.Uncategorized > 0 {
+		fmt.Fprintf(w, "  %5d  %s\n", s.Uncategorized, ui.T("stats_uncategorized"))
+	}
+
+	fmt.Fprintln(w, "\n"+ui.T("stats_by_month"))
+	months := make([]string, 0, len(s.Months))
+	for m := range s.Months {
+		months = append(months, m)
+	}
+	sort.Strings(months)
+	for _, m := range months {
+		fmt.Fprintf(w, "  %5d  %s\n", s.Months[m], m)
+	}
+
+	fmt.Fprintln(w, "\n"+ui.T("stats_busiest_days"))
+	for _, d := range s.BusiestDays(5) {
+		fmt.Fprintf(w, "  %5d  %s\n", s.Days[d], displayRecordTime(d))
+	}
+}
+
+// formatBytes shows a size as B, KB or MB (powers of 1024).
+func formatBytes(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+type batchFormat string
+
+const (
+	batchFormatCSV  batchFormat = "csv"
+	batchFormatJSON batchFormat = "json"
+	batchFormatYAML batchFormat = "yaml"
+	batchFormatTOML batchFormat = "toml"
+	// batchFormatTimetable is a semester timetable (YAML or CSV) that expands
+	// into weekly recurring classes.
+	batchFormatTimetable batchFormat = "timetable"
+	// batchFormatGCalCSV and batchFormatOutlookCSV are the CSV layouts Google
+	// Calendar imports and Outlook exports (Subject, Start Date, Start Time…).
+	batchFormatGCalCSV    batchFormat = "gcal-csv"
+	batchFormatOutlookCSV batchFormat = "outlook-csv"
+)
+
+type batchRecord struct {
+	UID         string
+	Summary     string
+	Start       string
+	End         string
+	Duration    string
+	StartTZ     string
+	EndTZ       string
+	Location    string
+	Description string
+	AllDay      bool
+	Overnight   bool // a clock-only end is on the day after the start
+	RRule       string
+	ExDates     []string
+	RDates      []string
+	Overrides   []overrideSpec // single occurrences moved elsewhere (YAML/JSON only)
+	Categories  []string
+	Alarms      []string
+	People      []string // family mode: who the event belongs to (empty = everyone)
+	Priority    string   // ICS PRIORITY, 1 (highest) to 9 (lowest)
+	Energy      string   // energy cost, 1 (light) to 5 (draining), for energy_budget
+	Attendees   []string // emails, optionally "Name <email>"
+	Organizer   string
+	Status      string // tentative, confirmed or cancelled
+	URL         string
+	Class       string // public, private or confidential
+}
+
+type batchExtension struct {
+	ext    string
+	format batchFormat
+}
+
+// batchExtensions are the file extensions --format auto recognises, in the
+// order serve looks for the batch file behind a feed.
+var batchExtensions = []batchExtension{
+	{".csv", batchFormatCSV},
+	{".json", batchFormatJSON},
+	{".yaml", batchFormatYAML},
+	{".yml", batchFormatYAML},
+	{".toml", batchFormatTOML},
+}
+
+var icsDurationRegex = regexp.MustCompile(`(?i)^P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+func detectBatchFormat(flag, path string) (batchFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(flag)) {
+	case "auto", "":
+		ext := strings.ToLower(filepath.Ext(path))
+		for _, e := range batchExtensions {
+			if e.ext == ext {
+				return e.format, nil
+			}
+		}
+		return "", fmt.Errorf("cannot infer format from %s; use --format csv|json|yaml|toml|timetable|gcal-csv|outlook-csv", path)
+	case "csv":
+		return batchFormatCSV, nil
+	case "json":
+		return batchFormatJSON, nil
+	case "yaml", "yml":
+		return batchFormatYAML, nil
+	case "toml":
+		return batchFormatTOML, nil
+	case "timetable":
+		return batchFormatTimetable, nil
+	case "gcal-csv", "google-csv":
+		return batchFormatGCalCSV, nil
+	case "outlook-csv":
+		return batchFormatOutlookCSV, nil
+	default:
+		return "", fmt.Errorf("unsupported format %q (use csv, json, yaml, toml, timetable, gcal-csv or outlook-csv)", flag)
+	}
+}
+
+func loadBatchRecords(path string, format batchFormat) ([]batchRecord, error) {
+	switch format {
+	case batchFormatCSV:
+		return loadBatchFromCSV(path)
+	case batchFormatJSON:
+		return loadBatchFromJSON(path)
+	case batchFormatYAML:
+		return loadBatchFromYAML(path)
+	case batchFormatTOML:
+		return loadBatchFromTOML(path)
+	case batchFormatTimetable:
+		return loadBatchFromTimetable(path)
+	case batchFormatGCalCSV, batchFormatOutlookCSV:
+		return loadCalendarAppCSV(path, format)
+	default:
+		return nil, fmt.Errorf("unknown batch format %q", format)
+	}
+}
+
+func loadBatchFromCSV(path string) ([]batchRecord, error) {
+	return loadMappedCSV(path, nil, nil)
+}
+
+// loadMappedCSV reads a batch CSV whose columns may go by other headers:
+// mapping (from --map) must match the file, aliases (column_aliases) are
+// tried for the columns the file lacks.
+func loadMappedCSV(path string, mapping columnMapping, aliases map[string][]string) ([]batchRecord, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int, len(header))
+	for i, col := range header {
+		index[strings.ToLower(strings.TrimSpace(col))] = i
+	}
+	sources, err := mapping.resolve(index, aliases)
+	if err != nil {
+		return nil, err
+	}
+	for i, field := range sources.fields {
+		index[field] = len(header) + i
+	}
+
+	var records []batchRecord
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(row) == 0 {
+			continue
+		}
+		row = sources.extend(row, len(header))
+
+		rec := batchRecord{
+			UID:         csvValue(row, index, "uid"),
+			Summary:     csvValue(row, index, "summary"),
+			Start:       csvValue(row, index, "start"),
+			End:         csvValue(row, index, "end"),
+			Duration:    csvValue(row, index, "duration"),
+			StartTZ:     csvValue(row, index, "start_tz"),
+			EndTZ:       csvValue(row, index, "end_tz"),
+			Location:    csvValue(row, index, "location"),
+			Description: csvValue(row, index, "description"),
+			RRule:       csvValue(row, index, "rrule"),
+		}
+		rec.AllDay = parseBoolish(csvValue(row, index, "all_day"))
+		rec.Overnight = parseBoolish(csvValue(row, index, "overnight"))
+
+		if ex := csvValue(row, index, "exdate"); ex != "" {
+			rec.ExDates = splitDelimited(ex)
+		}
+		if rd := csvValue(row, index, "rdate"); rd != "" {
+			rec.RDates = splitDelimited(rd)
+		}
+		if cats := csvValue(row, index, "categories"); cats != "" {
+			rec.Categories = splitDelimited(cats)
+		}
+		if alarms := csvValue(row, index, "alarms"); alarms != "" {
+			rec.Alarms = calendar.SplitAlarmInput(alarms)
+		}
+		rec.People = splitPeople(csvValue(row, index, "people"))
+		rec.Priority = csvValue(row, index, "priority")
+		rec.Energy = csvValue(row, index, "energy")
+		rec.Attendees = splitDelimited(csvValue(row, index, "attendees"))
+		rec.Organizer = csvValue(row, index, "organizer")
+		rec.Status = csvValue(row, index, "status")
+		rec.URL = csvValue(row, index, "url")
+		rec.Class = csvValue(row, index, "class")
+
+		records = append(records, rec)
+	}
+
+	return records, nil
+}
+
+// batchCSVFields are the columns loadBatchFromCSV reads.
+var batchCSVFields = []string{
+	"uid", "summary", "start", "end", "duration", "start_tz", "end_tz", "location", "description",
+	"all_day", "overnight", "rrule", "exdate", "rdate", "categories", "alarms", "people",
+	"priority", "energy", "attendees", "organizer", "status", "url", "class",
+}
+
+// columnMapping maps a batch column to the CSV headers it is read from;
+// several headers are joined with a space ("Start Date" + "Start Time").
+type columnMapping map[string][]string
+
+// parseColumnMapping reads --map values such as
+// "summary=Subject,start=Start Date+Start Time".
+func parseColumnMapping(values []string) (columnMapping, error) {
+	mapping := columnMapping{}
+	for _, value := range values {
+		for _, entry := range strings.Split(value, ",") {
+			if strings.TrimSpace(entry) == "" {
+				continue
+			}
+			field, headers, ok := strings.Cut(entry, "=")
+			field = strings.ToLower(strings.TrimSpace(field))
+			if !ok || strings.TrimSpace(headers) == "" {
+				return nil, fmt.Errorf("invalid --map %q: want COLUMN=HEADER, e.g. summary=Subject", entry)
+			}
+			if !slices.Contains(batchCSVFields, field) {
+				return nil, fmt.Errorf("invalid --map %q: %q is not a batch column (%s)", entry, field, strings.Join(batchCSVFields, ", "))
+			}
+			mapping[field] = splitHeaders(headers)
+		}
+	}
+	return mapping, nil
+}
+
+func splitHeaders(s string) []string {
+	var out []string
+	for _, h := range strings.Split(s, "+") {
+		if h = strings.TrimSpace(h); h != "" {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+// csvSources are the mapped columns of one CSV file: fields[i] is read by
+// joining the cells at cols[i].
+type csvSources struct {
+	fields []string
+	cols   [][]int
+}
+
+// resolve finds the mapped headers in a CSV header index. Every --map header
+// must exist; an alias is used only when the file has no column of that name
+// and all of the alias's headers exist, the first such alias winning.
+func (m columnMapping) resolve(index map[string]int, aliases map[string][]string) (csvSources, error) {
+	var out csvSources
+	for _, field := range slices.Sorted(maps.Keys(m)) {
+		cols, missing := headerColumns(index, m[field])
+		if missing != "" {
+			return out, fmt.Errorf("--map %s: no column %q in the CSV header", field, missing)
+		}
+		out.fields = append(out.fields, field)
+		out.cols = append(out.cols, cols)
+	}
+	for _, field := range slices.Sorted(maps.Keys(aliases)) {
+		key := strings.ToLower(strings.TrimSpace(field))
+		if _, ok := m[key]; ok {
+			continue
+		}
+		if _, ok := index[key]; ok {
+			continue
+		}
+		for _, alias := range aliases[field] {
+			if cols, missing := headerColumns(index, splitHeaders(alias)); missing == "" && len(cols) > 0 {
+				out.fields = append(out.fields, key)
+				out.cols = append(out.cols, cols)
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+// headerColumns returns the positions of headers, or the first one missing.
+func headerColumns(index map[string]int, headers []string) ([]int, string) {
+	cols := make([]int, 0, len(headers))
+	for _, h := range headers {
+		pos, ok := index[strings.ToLower(h)]
+		if !ok {
+			return nil, h
+		}
+		cols = append(cols, pos)
+	}
+	return cols, ""
+}
+
+// extend appends the mapped fields' values to a row of width cells.
+func (s csvSources) extend(row []string, width int) []string {
+	if len(s.fields) == 0 {
+		return row
+	}
+	out := make([]string, width, width+len(s.fields))
+	copy(out, row)
+	for _, cols := range s.cols {
+		parts := make([]string, 0, len(cols))
+		for _, c := range cols {
+			if c < len(row) {
+				if v := strings.TrimSpace(row[c]); v != "" {
+					parts = append(parts, v)
+				}
+			}
+		}
+		out = append(out, strings.Join(parts, " "))
+	}
+	return out
+}
+
+// sniffCalendarAppCSV tells a Google or Outlook calendar CSV from a batch CSV
+// by its header; Outlook's export has reminder and organizer columns.
+func sniffCalendarAppCSV(path string) batchFormat {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return batchFormatCSV
+	}
+	defer f.Close()
+	header, err := csv.NewReader(skipBOM(f)).Read()
+	if err != nil {
+		return batchFormatCSV
+	}
+	cols := map[string]bool{}
+	for _, col := range header {
+		cols[strings.ToLower(strings.TrimSpace(col))] = true
+	}
+	switch {
+	case cols["summary"] || !cols["subject"] || !cols["start date"]:
+		return batchFormatCSV
+	case cols["reminder on/off"] || cols["meeting organizer"]:
+		return batchFormatOutlookCSV
+	}
+	return batchFormatGCalCSV
+}
+
+// loadCalendarAppCSV reads a Google Calendar or Outlook CSV: dates are
+// M/D/YYYY and times 12-hour ("2:30 PM"), as both apps write them in the US
+// layout. Outlook ends an all-day event on the day after it, batch on its last
+// day. Values tempus cannot read are passed on as they are, so the row fails
+// validation with the usual message (and --skip-invalid can drop it).
+func loadCalendarAppCSV(path string, format batchFormat) ([]batchRecord, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(skipBOM(f))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]int, len(header))
+	for i, col := range header {
+		index[strings.ToLower(strings.TrimSpace(col))] = i
+	}
+	if _, ok := index["subject"]; !ok {
+		return nil, fmt.Errorf("%s has no Subject column; is it a %s export?", path, format)
+	}
+
+	var records []batchRecord
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(row) == 0 {
+			continue
+		}
+		value := func(key string) string { return csvValue(row, index, key) }
+
+		rec := batchRecord{
+			Summary:     value("subject"),
+			Description: value("description"),
+			Location:    value("location"),
+			AllDay:      parseBoolish(value("all day event")),
+		}
+		startDate := appCSVDate(value("start date"))
+		endDate := appCSVDate(firstNonEmpty(value("end date"), value("start date")))
+		if rec.AllDay {
+			rec.Start, rec.End = startDate, endDate
+			if format == batchFormatOutlookCSV && endDate > startDate {
+				if end, err := time.Parse(constants.DateFormatISO, endDate); err == nil {
+					rec.End = end.AddDate(0, 0, -1).Format(constants.DateFormatISO)
+				}
+			}
+		} else {
+			rec.Start = strings.TrimSpace(startDate + " " + appCSVClock(value("start time")))
+			if clock := appCSVClock(value("end time")); clock != "" {
+				rec.End = endDate + " " + clock
+			}
+		}
+		if parseBoolish(value("private")) {
+			rec.Class = "private"
+		}
+
+		if format == batchFormatOutlookCSV {
+			rec.Categories = splitOutlookList(value("categories"))
+			switch strings.ToLower(value("sensitivity")) {
+			case "private", "personal":
+				rec.Class = "private"
+			case "confidential":
+				rec.Class = "confidential"
+			}
+			switch strings.ToLower(value("priority")) {
+			case "high":
+				rec.Priority = "1"
+			case "low":
+				rec.Priority = "9"
+			}
+			if organizer := value("meeting organizer"); strings.Contains(organizer, "@") {
+				rec.Organizer = organizer
+			}
+			for _, key := range []string{"required attendees", "optional attendees"} {
+				for _, a := range splitOutlookList(value(key)) {
+					if strings.Contains(a, "@") { // Outlook often exports display names only
+						rec.Attendees = append(rec.Attendees, a)
+					}
+				}
+			}
+			if parseBoolish(value("reminder on/off")) && value("reminder date") != "" {
+				trigger := strings.TrimSpace(appCSVDate(value("reminder date")) + " " + appCSVClock(value("reminder time")))
+				rec.Alarms = append(rec.Alarms, "trigger="+trigger)
+			}
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// skipBOM drops the UTF-8 byte order mark Excel puts at the start of CSV
+// files, which encoding/csv would otherwise read as part of the first field.
+func skipBOM(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	if bom, err := br.Peek(3); err == nil && string(bom) == "\ufeff" {
+		_, _ = br.Discard(3)
+	}
+	return br
+}
+
+// appCSVDate rewrites an M/D/YYYY date as YYYY-MM-DD; anything else is
+// returned unchanged for the usual date parsing.
+func appCSVDate(s string) string {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{"1/2/2006", "1/2/06"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format(constants.DateFormatISO)
+		}
+	}
+	return s
+}
+
+// appCSVClock rewrites a 12-hour time ("2:30 PM", "2:30:00 PM") as 24-hour
+// HH:MM; anything else is returned unchanged.
+func appCSVClock(s string) string {
+	s = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(s), ".", ""))
+	for _, layout := range []string{"3:04 PM", "3:04:05 PM", "3:04PM", "3:04:05PM", "3 PM", "3PM", "15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("15:04")
+		}
+	}
+	return s
+}
+
+// splitOutlookList splits Outlook's "a; b" lists (categories, attendees).
+func splitOutlookList(s string) []string {
+	var out []string
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == ',' }) {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func csvValue(row []string, index map[string]int, key string) string {
+	if pos, ok := index[key]; ok {
+		if pos < len(row) {
+			return strings.TrimSpace(row[pos])
+		}
+	}
+	return ""
+}
+
+func loadBatchFromJSON(path string) ([]batchRecord, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, nil
+	}
+
+	raw, err := decodeRowMaps(data, batchFormatJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]batchRecord, 0, len(raw))
+	for _, item := range raw {
+		records = append(records, batchRecordFromMap(item))
+	}
+	return records, nil
+}
+
+func loadBatchFromYAML(path string) ([]batchRecord, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, nil
+	}
+
+	if isTimetableYAML(data) {
+		return parseTimetableYAML(data)
+	}
+	if isScheduleYAML(data) {
+		var doc map[string]interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		return parseSchedule(doc)
+	}
+
+	raw, err := decodeRowMaps(data, batchFormatYAML)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]batchRecord, 0, len(raw))
+	for _, item := range raw {
+		records = append(records, batchRecordFromMap(item))
+	}
+	return records, nil
+}
+
+// batchRecordFromMap reads one event of a JSON, YAML or TOML batch file.
+func batchRecordFromMap(item map[string]interface{}) batchRecord {
+	return batchRecord{
+		UID:         valueAsString(item["uid"]),
+		Summary:     valueAsString(item["summary"]),
+		Start:       valueAsString(item["start"]),
+		End:         valueAsString(item["end"]),
+		Duration:    valueAsString(item["duration"]),
+		StartTZ:     valueAsString(item["start_tz"]),
+		EndTZ:       valueAsString(item["end_tz"]),
+		Location:    valueAsString(item["location"]),
+		Description: valueAsString(item["description"]),
+		RRule:       valueAsString(item["rrule"]),
+		AllDay:      valueAsBool(item["all_day"]),
+		Overnight:   valueAsBool(item["overnight"]),
+		ExDates:     valueAsStringSlice(item["exdate"]),
+		RDates:      valueAsStringSlice(item["rdate"]),
+		Overrides:   valueAsOverrides(item["overrides"]),
+		Categories:  valueAsStringSlice(item["categories"]),
+		Alarms:      valueAsAlarmSlice(item["alarms"]),
+		People:      splitPeople(strings.Join(valueAsStringSlice(item["people"]), "+")),
+		Priority:    valueAsString(item["priority"]),
+		Energy:      valueAsString(item["energy"]),
+		Attendees:   valueAsStringSlice(item["attendees"]),
+		Organizer:   valueAsString(item["organizer"]),
+		Status:      valueAsString(item["status"]),
+		URL:         valueAsString(item["url"]),
+		Class:       valueAsString(item["class"]),
+	}
+}
+
+// loadBatchFromTOML reads events from [[events]] tables, or a weekly
+// schedule (see parseSchedule) when the file has a schedule key:
+//
+//	[[events]]
+//	summary = "Dentist"
+//	start = 2025-05-01T09:00:00
+//	duration = "45m"
+func loadBatchFromTOML(path string) ([]batchRecord, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, nil
+	}
+
+	var doc map[string]interface{}
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	doc = tomlPlain(doc).(map[string]interface{})
+	if _, ok := doc["schedule"]; ok {
+		return parseSchedule(doc)
+	}
+	raw, err := tomlEventTables(doc)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]batchRecord, 0, len(raw))
+	for _, item := range raw {
+		records = append(records, batchRecordFromMap(item))
+	}
+	return records, nil
+}
+
+// tomlEventTables returns the [[events]] tables of a decoded TOML document.
+func tomlEventTables(doc map[string]interface{}) ([]map[string]interface{}, error) {
+	items, ok := doc["events"].([]interface{})
+	if !ok {
+		if _, present := doc["events"]; present {
+			return nil, fmt.Errorf("events must be an array of tables ([[events]])")
+		}
+		return nil, fmt.Errorf("no [[events]] tables or schedule found")
+	}
+	tables := make([]map[string]interface{}, 0, len(items))
+	for i, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("event %d is not a table", i+1)
+		}
+		tables = append(tables, m)
+	}
+	return tables, nil
+}
+
+// decodeRowMaps reads the list of rows in a JSON, YAML or TOML data file:
+// a top-level array or an events: list for JSON and YAML, the [[events]]
+// tables for TOML. Batch and template create share it so both read the same
+// files.
+func decodeRowMaps(data []byte, format batchFormat) ([]map[string]interface{}, error) {
+	doc, err := decodeDataDocument(data, format)
+	if err != nil {
+		return nil, err
+	}
+	switch x := doc.(type) {
+	case []interface{}:
+		return decodeRowList(x)
+	case map[string]interface{}:
+		if format == batchFormatTOML {
+			return tomlEventTables(x)
+		}
+		events, ok := x["events"].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected a list of events or an events: list")
+		}
+		return decodeRowList(events)
+	case nil:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("expected a list of events or an events: list")
+	}
+}
+
+func decodeRowList(items []interface{}) ([]map[string]interface{}, error) {
+	rows := make([]map[string]interface{}, 0, len(items))
+	for i, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("event %d is not an object", i+1)
+		}
+		rows = append(rows, m)
+	}
+	return rows, nil
+}
+
+// decodeDataDocument decodes a whole JSON, YAML or TOML data file.
+func decodeDataDocument(data []byte, format batchFormat) (interface{}, error) {
+	var doc interface{}
+	switch format {
+	case batchFormatJSON:
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+	case batchFormatYAML:
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+	case batchFormatTOML:
+		var m map[string]interface{}
+		if err := toml.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+		return tomlPlain(m), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	return doc, nil
+}
+
+// tomlPlain turns TOML's local dates and times into the strings the other
+// formats use ("2025-05-01 09:00", "2025-05-01", "09:00") and offset
+// date-times into RFC 3339, recursively.
+func tomlPlain(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, item := range x {
+			x[k] = tomlPlain(item)
+		}
+		return x
+	case []interface{}:
+		for i, item := range x {
+			x[i] = tomlPlain(item)
+		}
+		return x
+	case toml.LocalDateTime:
+		return x.AsTime(time.UTC).Format(constants.DateTimeFormatISO)
+	case toml.LocalDate:
+		return x.AsTime(time.UTC).Format(constants.DateFormatISO)
+	case toml.LocalTime:
+		return fmt.Sprintf("%02d:%02d", x.Hour, x.Minute)
+	case time.Time:
+		return x.Format(time.RFC3339)
+	}
+	return v
+}
+
+// isScheduleYAML reports whether a YAML batch file is a weekly schedule (a
+// mapping with a schedule date range) rather than a list of events.
+func isScheduleYAML(data []byte) bool {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return false
+	}
+	_, ok := doc["schedule"]
+	return ok
+}
+
+// scheduleKeys are the keys of a schedule that are not weekdays.
+var scheduleKeys = map[string]bool{"schedule": true, "timezone": true, "skip": true}
+
+// scheduleEntry is one event of a weekly schedule, on days.
+type scheduleEntry struct {
+	days  []time.Weekday
+	start int // minutes since midnight
+	rec   batchRecord
+}
+
+// parseSchedule expands a weekly plan into one dated record per occurrence:
+//
+//	schedule: 2025-09-01..2025-12-19   # or {start: ..., end: ...}
+//	timezone: Europe/Madrid
+//	skip: [2025-10-13, 2025-12-01..2025-12-05]
+//	monday:
+//	  - "09:00": Standup
+//	  - "10:00-11:30": Focus block
+//	  - {time: "18:00", summary: Gym, duration: 1h, location: Gym}
+//	weekdays:
+//	  - "08:00": Meds
+//
+// Days are weekday names or groups (mon, tuesday, weekdays, weekend, daily,
+// "tue,thu"). An entry without an end gets the usual default duration.
+func parseSchedule(doc map[string]interface{}) ([]batchRecord, error) {
+	span, err := scheduleSpan(doc["schedule"])
+	if err != nil {
+		return nil, err
+	}
+	var skip []calendar.DateRange
+	for _, s := range valueAsStringSlice(doc["skip"]) {
+		r, err := calendar.ParseDateRange(s)
+		if err != nil {
+			return nil, fmt.Errorf("skip: %w", err)
+		}
+		skip = append(skip, r)
+	}
+	timezone := valueAsString(doc["timezone"])
+
+	var entries []scheduleEntry
+	for _, key := range slices.Sorted(maps.Keys(doc)) {
+		if scheduleKeys[strings.ToLower(key)] {
+			continue
+		}
+		days, err := calendar.ParseWeekdays(key)
+		if err != nil || len(days) == 0 {
+			return nil, fmt.Errorf("schedule: %q is not a weekday (use monday, tue, weekdays, weekend or daily)", key)
+		}
+		items, ok := doc[key].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: want a list of events, e.g. - \"09:00\": Standup", key)
+		}
+		for i, item := range items {
+			entry, err := scheduleEntryFrom(item)
+			if err != nil {
+				return nil, fmt.Errorf("%s event %d: %w", key, i+1, err)
+			}
+			entry.days = days
+			entry.rec.StartTZ = firstNonEmpty(entry.rec.StartTZ, timezone)
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].start < entries[j].start })
+
+	var records []batchRecord
+	for d := span.First; !d.After(span.Last); d = d.AddDate(0, 0, 1) {
+		if slices.ContainsFunc(skip, func(r calendar.DateRange) bool { return !d.Before(r.First) && !d.After(r.Last) }) {
+			continue
+		}
+		date := d.Format(constants.DateFormatISO)
+		for _, e := range entries {
+			if !slices.Contains(e.days, d.Weekday()) {
+				continue
+			}
+			rec := e.rec
+			rec.Start = date + " " + rec.Start
+			records = append(records, rec)
+		}
+	}
+	return records, nil
+}
+
+// scheduleSpan reads the schedule's date range: "START..END" or a mapping
+// with start and end.
+func scheduleSpan(v interface{}) (calendar.DateRange, error) {
+	value := valueAsString(v)
+	if m, ok := v.(map[string]interface{}); ok {
+		value = valueAsString(m["start"]) + ".." + valueAsString(m["end"])
+	}
+	if strings.TrimSpace(value) == "" {
+		return calendar.DateRange{}, fmt.Errorf("schedule needs a date range, e.g. schedule: 2025-09-01..2025-12-19")
+	}
+	span, err := calendar.ParseDateRange(value)
+	if err != nil {
+		return calendar.DateRange{}, fmt.Errorf("schedule: %w", err)
+	}
+	return span, nil
+}
+
+// scheduleEntryFrom reads one schedule event: {"09:00": Summary},
+// {"09:00-10:30": Summary} or a mapping of batch fields with a time
+// (clock or range) instead of a dated start.
+func scheduleEntryFrom(item interface{}) (scheduleEntry, error) {
+	m, ok := item.(map[string]interface{})
+	if !ok {
+		return scheduleEntry{}, fmt.Errorf("want \"HH:MM\": summary or a mapping with time and summary")
+	}
+	var (
+		entry scheduleEntry
+		clock string
+	)
+	if len(m) == 1 {
+		for k, v := range m {
+			if _, _, err := scheduleClock(k); err == nil {
+				clock = k
+				entry.rec.Summary = valueAsString(v)
+			}
+		}
+	}
+	if clock == "" {
+		entry.rec = batchRecordFromMap(m)
+		clock = firstNonEmpty(valueAsString(m["time"]), entry.rec.Start)
+	}
+	start, end, err := scheduleClock(clock)
+	if err != nil {
+		return scheduleEntry{}, err
+	}
+	entry.start = start
+	entry.rec.Start = clockString(start)
+	if end >= 0 {
+		entry.rec.End = clockString(end)
+	}
+	return entry, nil
+}
+
+// scheduleClock parses "09:00" or "09:00-10:30" as minutes since midnight;
+// end is -1 without a range.
+func scheduleClock(s string) (start, end int, err error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "-") {
+		windows, err := calendar.ParseClockWindows(s)
+		if err != nil || len(windows) != 1 {
+			return 0, 0, fmt.Errorf("invalid time %q (use HH:MM or HH:MM-HH:MM)", s)
+		}
+		return windows[0].Start, windows[0].End, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time %q (use HH:MM or HH:MM-HH:MM)", s)
+	}
+	return t.Hour()*60 + t.Minute(), -1, nil
+}
+
+func clockString(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// timetable is a semester of weekly classes, loaded from a timetable file.
+type timetable struct {
+	term     calendar.Term
+	timezone string
+	classes  []timetableClass
+}
+
+// timetableClass is one class of a timetable with the batch columns it adds.
+type timetableClass struct {
+	calendar.TimetableClass
+	Description string
+	Categories  []string
+	Alarms      []string
+}
+
+// loadBatchFromTimetable reads a timetable given with --format timetable: the
+// YAML layout below, or CSV with one class per row.
+func loadBatchFromTimetable(path string) ([]batchRecord, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return parseTimetableCSV(data)
+	}
+	return parseTimetableYAML(data)
+}
+
+// isTimetableYAML reports whether a YAML batch file is a timetable (a mapping
+// with a classes list) rather than a list of events.
+func isTimetableYAML(data []byte) bool {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return false
+	}
+	_, ok := doc["classes"]
+	return ok
+}
+
+// parseTimetableYAML reads a timetable such as
+//
+//	semester: 2025-09-08..2025-12-19   # or {start: ..., end: ...}
+//	timezone: Europe/Madrid
+//	breaks: [2025-10-13, 2025-12-01..2025-12-05]
+//	classes:
+//	  - Mon,Wed 09:00-10:30 Algebra Room B2
+//	  - {class: Fri 12:00-13:00 Lab, alarms: -10m}
+//	  - {days: Tue, time: 14:00-15:30, summary: History, location: A1}
+func parseTimetableYAML(data []byte) ([]batchRecord, error) {
+	var doc struct {
+		Semester yaml.Node   `yaml:"semester"`
+		Timezone string      `yaml:"timezone"`
+		Breaks   []string    `yaml:"breaks"`
+		Classes  []yaml.Node `yaml:"classes"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	semester := doc.Semester.Value
+	if doc.Semester.Kind == yaml.MappingNode {
+		var span struct {
+			Start string `yaml:"start"`
+			End   string `yaml:"end"`
+		}
+		if err := doc.Semester.Decode(&span); err != nil {
+			return nil, fmt.Errorf("semester: %w", err)
+		}
+		semester = span.Start + ".." + span.End
+	}
+	tt := timetable{timezone: strings.TrimSpace(doc.Timezone)}
+	if err := tt.setTerm(semester, doc.Breaks); err != nil {
+		return nil, err
+	}
+
+	for i, node := range doc.Classes {
+		var (
+			class timetableClass
+			err   error
+		)
+		if node.Kind == yaml.ScalarNode {
+			class.TimetableClass, err = calendar.ParseTimetableLine(node.Value)
+		} else {
+			var item map[string]interface{}
+			if err := node.Decode(&item); err != nil {
+				return nil, fmt.Errorf("class %d: %w", i+1, err)
+			}
+			class, err = timetableClassFrom(func(key string) interface{} { return item[key] })
+		}
+		if err != nil {
+			return nil, fmt.Errorf("class %d: %w", i+1, err)
+		}
+		tt.classes = append(tt.classes, class)
+	}
+	return tt.records()
+}
+
+// parseTimetableCSV reads a timetable with a class column holding compact
+// lines, or days, time, summary and location columns. Rows starting with
+// semester, break or timezone set those instead of adding a class:
+//
+//	class,alarms
+//	semester,2025-09-08..2025-12-19
+//	break,2025-10-13
+//	Mon 09:00-10:30 Algebra Room B2,-10m
+func parseTimetableCSV(data []byte) ([]batchRecord, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	index := make(map[string]int, len(rows[0]))
+	for i, col := range rows[0] {
+		index[strings.ToLower(strings.TrimSpace(col))] = i
+	}
+	var (
+		tt       timetable
+		semester string
+		breaks   []string
+	)
+	for i, row := range rows[1:] {
+		value := ""
+		if len(row) > 1 {
+			value = strings.TrimSpace(row[1])
+		}
+		switch strings.ToLower(strings.TrimSpace(row[0])) {
+		case "semester":
+			semester = value
+			continue
+		case "break":
+			breaks = append(breaks, value)
+			continue
+		case "timezone":
+			tt.timezone = value
+			continue
+		}
+		if strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+
+		class, err := timetableClassFrom(func(key string) interface{} { return csvValue(row, index, key) })
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+2, err)
+		}
+		tt.classes = append(tt.classes, class)
+	}
+	if err := tt.setTerm(semester, breaks); err != nil {
+		return nil, err
+	}
+	return tt.records()
+}
+
+// timetableClassFrom builds a class from a YAML item or CSV row: a compact
+// "class" line, or "days", "time", "summary" and "location" values.
+func timetableClassFrom(get func(key string) interface{}) (timetableClass, error) {
+	var (
+		class timetableClass
+		err   error
+	)
+	if line := valueAsString(get("class")); line != "" {
+		class.TimetableClass, err = calendar.ParseTimetableLine(line)
+	} else {
+		days := firstNonEmpty(valueAsString(get("days")), valueAsString(get("day")))
+		class.TimetableClass, err = calendar.ParseTimetableClass(days, valueAsString(get("time")),
+			valueAsString(get("summary")), valueAsString(get("location")))
+	}
+	if err != nil {
+		return timetableClass{}, err
+	}
+	if location := valueAsString(get("location")); location != "" {
+		class.Location = location
+	}
+	class.Description = valueAsString(get("description"))
+	if cats, ok := get("categories").(string); ok {
+		class.Categories = splitDelimited(cats)
+	} else {
+		class.Categories = valueAsStringSlice(get("categories"))
+	}
+	if alarms, ok := get("alarms").(string); ok {
+		class.Alarms = calendar.SplitAlarmInput(alarms)
+	} else {
+		class.Alarms = valueAsAlarmSlice(get("alarms"))
+	}
+	return class, nil
+}
+
+func (tt *timetable) setTerm(semester string, breaks []string) error {
+	if strings.TrimSpace(semester) == "" {
+		return fmt.Errorf("timetable needs a semester, e.g. semester: 2025-09-08..2025-12-19")
+	}
+	span, err := calendar.ParseDateRange(semester)
+	if err != nil {
+		return fmt.Errorf("semester: %w", err)
+	}
+	if span.First.Equal(span.Last) {
+		return fmt.Errorf("semester %q needs a start and an end date (START..END)", semester)
+	}
+	tt.term = calendar.Term{Start: span.First, End: span.Last}
+	for _, b := range breaks {
+		r, err := calendar.ParseDateRange(b)
+		if err != nil {
+			return fmt.Errorf("break: %w", err)
+		}
+		tt.term.Breaks = append(tt.term.Breaks, r)
+	}
+	return nil
+}
+
+// records expands every class into one weekly series per weekday over the
+// semester, with EXDATEs for the classes that fall in a break.
+func (tt timetable) records() ([]batchRecord, error) {
+	clock := func(m int) string { return fmt.Sprintf("%02d:%02d", m/60, m%60) }
+	var records []batchRecord
+	for _, class := range tt.classes {
+		categories := class.Categories
+		if len(categories) == 0 {
+			categories = []string{"Education"}
+		}
+		for _, day := range class.Days {
+			series, ok := tt.term.Weekly(day)
+			if !ok {
+				continue
+			}
+			date := series.First.Format(constants.DateFormatISO)
+			rec := batchRecord{
+				Summary:     class.Summary,
+				Start:       date + " " + clock(class.Start),
+				End:         date + " " + clock(class.End),
+				StartTZ:     tt.timezone,
+				Location:    class.Location,
+				Description: class.Description,
+				Categories:  categories,
+				Alarms:      class.Alarms,
+			}
+			if series.Count > 1 {
+				rec.RRule = fmt.Sprintf("FREQ=WEEKLY;COUNT=%d", series.Count)
+			}
+			for _, skipped := range series.Skipped {
+				rec.ExDates = append(rec.ExDates, skipped.Format(constants.DateFormatISO)+" "+clock(class.Start))
+			}
+			records = append(records, rec)
+		}
+	}
+	return records, nil
+}
+
+// buildEventFromBatch turns one batch row into an event (spelling is corrected
+// beforehand, in buildBatchRowEvent). With strictInput the row is taken
+// literally: no emoji, category canonicalization,
+// clock-only date inference or smart default durations.
+func buildEventFromBatch(rec batchRecord, fallbackTZ string, strictInput bool) (*calendar.Event, error) {
+	summary, startStr, err := validateBatchRecord(rec)
+	if err != nil {
+		return nil, err
+	}
+	if strictInput {
+		if err := requireExplicitBatchInput(rec); err != nil {
+			return nil, err
+		}
+		summary = strings.TrimSpace(rec.Summary)
+	}
+
+	startTZ, endTZ := resolveBatchTimezones(rec, fallbackTZ)
+	if err := validateBatchTimezones(rec, startTZ, endTZ); err != nil {
+		return nil, err
+	}
+	startTime, endTime, err := parseBatchTimes(rec, startStr, startTZ, summary)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strictInput {
+		summary = addEmojiToSummary(summary, rec.Categories)
+	}
+	event := calendar.NewEvent(summary, startTime, endTime)
+	configureBatchEvent(event, rec, startTZ, endTZ, strictInput)
+	if err := applyBatchWeights(event, rec); err != nil {
+		return nil, err
+	}
+	if err := applyBatchContacts(event, rec); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// applyBatchWeights sets the event's priority (1-9) and energy (1-5) columns.
+func applyBatchWeights(event *calendar.Event, rec batchRecord) error {
+	var err error
+	if event.Priority, err = batchScale(rec.Priority, 9); err != nil {
+		return fieldError("priority", fmt.Errorf("priority %w", err))
+	}
+	if event.Energy, err = batchScale(rec.Energy, 5); err != nil {
+		return fieldError("energy", fmt.Errorf("energy %w", err))
+	}
+	return nil
+}
+
+// applyBatchContacts sets the event's attendees, organizer, status, class and
+// URL columns, checking each.
+func applyBatchContacts(event *calendar.Event, rec batchRecord) error {
+	for _, a := range rec.Attendees {
+		addr, err := batchEmail(a)
+		if err != nil {
+			return fmt.Errorf("attendee %w", err)
+		}
+		event.AddAttendee(addr)
+	}
+	if strings.TrimSpace(rec.Organizer) != "" {
+		addr, err := batchEmail(rec.Organizer)
+		if err != nil {
+			return fieldError("organizer", fmt.Errorf("organizer %w", err))
+		}
+		event.Organizer = addr
+	}
+	status, err := parseEventStatus(rec.Status)
+	if err != nil {
+		return fieldError("status", err)
+	}
+	if status != "" {
+		event.Status = status
+	}
+	if event.Class, err = parseEventClass(rec.Class); err != nil {
+		return fieldError("class", err)
+	}
+	if u := strings.TrimSpace(rec.URL); u != "" {
+		parsed, err := url.Parse(u)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fieldError("url", fmt.Errorf("url %q must be absolute, e.g. https://example.com/meeting", u))
+		}
+		event.URL = u
+	}
+	return nil
+}
+
+// batchEmail reads "ana@example.com", "mailto:ana@example.com" or
+// "Ana <ana@example.com>" and returns the address.
+func batchEmail(s string) (string, error) {
+	v := strings.TrimSpace(s)
+	if len(v) >= 7 && strings.EqualFold(v[:7], "mailto:") {
+		v = v[7:]
+	}
+	addr, err := mail.ParseAddress(v)
+	if err != nil {
+		return "", fmt.Errorf("%q is not an email address", s)
+	}
+	return addr.Address, nil
+}
+
+// batchScale parses a 1..top column value; empty means unset (0).
+func batchScale(s string, top int) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > top {
+		return 0, fmt.Errorf("%q must be a whole number from 1 to %d", s, top)
+	}
+	return n, nil
+}
+
+// requireExplicitBatchInput rejects rows that would otherwise need inference
+// under --strict-input: non-canonical dates, clock-only times and rows without
+// an end or duration.
+func requireExplicitBatchInput(rec batchRecord) error {
+	start := strings.TrimSpace(rec.Start)
+	end := strings.TrimSpace(rec.End)
+
+	if rec.AllDay {
+		if _, err := time.Parse(constants.DateFormatISO, start); err != nil {
+			return fieldError("start", fmt.Errorf("start %q must be YYYY-MM-DD with --strict-input", rec.Start))
+		}
+		if _, err := time.Parse(constants.DateFormatISO, end); end != "" && err != nil {
+			return fieldError("end", fmt.Errorf("end %q must be YYYY-MM-DD with --strict-input", rec.End))
+		}
+		return nil
+	}
+
+	if _, err := time.Parse(constants.DateTimeFormatISO, start); err != nil {
+		return fieldError("start", fmt.Errorf("start %q must be YYYY-MM-DD HH:MM with --strict-input", rec.Start))
+	}
+	if end == "" && strings.TrimSpace(rec.Duration) == "" {
+		return fieldError("duration", fmt.Errorf("end or duration is required with --strict-input"))
+	}
+	if looksLikeClock(end) {
+		return fieldError("end", fmt.Errorf("end %q must include a date with --strict-input", rec.End))
+	}
+	return nil
+}
+
+func validateBatchRecord(rec batchRecord) (summary, startStr string, err error) {
+	summary = strings.Join(strings.Fields(rec.Summary), " ")
+	if summary == "" {
+		return "", "", fieldError("summary", fmt.Errorf("summary is required"))
+	}
+
+	startStr = normalizeDateTimeInput(strings.TrimSpace(rec.Start))
+	if startStr == "" {
+		return "", "", fieldError("start", fmt.Errorf("start is required"))
+	}
+
+	return summary, startStr, nil
+}
+
+// resolveBatchTimezones picks the row's start/end zones, falling back to
+// fallbackTZ, and rewrites deprecated names to their canonical IANA form.
+func resolveBatchTimezones(rec batchRecord, fallbackTZ string) (startTZ, endTZ string) {
+	startTZ, _ = tzpkg.CanonicalName(firstNonEmpty(rec.StartTZ, fallbackTZ))
+	endTZ, _ = tzpkg.CanonicalName(rec.EndTZ)
+	if endTZ == "" {
+		endTZ = startTZ
+	}
+	return startTZ, endTZ
+}
+
+// canonicalTimezone rewrites a deprecated zone name (Europe/Kiev, US/Eastern…)
+// to its canonical IANA name. When it does, it also returns a warning naming
+// source (a flag or batch field) so the input can be fixed.
+func canonicalTimezone(tz, source string) (string, []diag.Warning) {
+	canonical, deprecated := tzpkg.CanonicalName(tz)
+	if !deprecated {
+		return tz, nil
+	}
+	return canonical, []diag.Warning{{
+		Code: diag.CodeDeprecatedTZ, Severity: diag.SeverityWarning,
+		Message:    fmt.Sprintf("%s %q is a deprecated timezone name; using %q", source, strings.TrimSpace(tz), canonical),
+		Suggestion: fmt.Sprintf("write %s to avoid calendar client warnings", canonical),
+	}}
+}
+
+// collectTimezoneAliases reports deprecated zone names in --default-tz and the
+// start_tz/end_tz columns; resolveBatchTimezones has already rewritten them.
+func collectTimezoneAliases(records []batchRecord, opts *batchOptions) []diag.Warning {
+	_, warnings := canonicalTimezone(opts.defaultTZ, "--default-tz")
+	for i := range warnings {
+		warnings[i].File = opts.input
+	}
+	for i, rec := range records {
+		for _, field := range []string{"start_tz", "end_tz"} {
+			_, ws := canonicalTimezone(rec.field(field), field)
+			for _, w := range ws {
+				w.File, w.Row = opts.input, i+1
+				warnings = append(warnings, w)
+			}
+		}
+	}
+	return warnings
+}
+
+// validateBatchTimezones rejects unknown TZIDs, which would otherwise produce
+// events that calendar clients silently place in UTC.
+func validateBatchTimezones(rec batchRecord, startTZ, endTZ string) error {
+	if startTZ != "" {
+		if _, err := time.LoadLocation(startTZ); err != nil {
+			return fieldError("start_tz", fmt.Errorf("unknown timezone %q", startTZ))
+		}
+	}
+	if endTZ != startTZ {
+		if _, err := time.LoadLocation(endTZ); err != nil {
+			return fieldError("end_tz", fmt.Errorf("unknown timezone %q", endTZ))
+		}
+	}
+	return nil
+}
+
+func parseBatchTimes(rec batchRecord, startStr, startTZ, summary string) (startTime, endTime time.Time, err error) {
+	if rec.AllDay {
+		return parseBatchAllDayTimes(startStr, rec.End)
+	}
+	return parseBatchTimedEventTimes(rec, startStr, startTZ, summary)
+}
+
+func parseBatchAllDayTimes(startStr, endStr string) (startTime, endTime time.Time, err error) {
+	startDateStr := extractDate(startStr)
+	startTime, err = time.Parse("2006-01-02", startDateStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, fieldError("start", fmt.Errorf("invalid start date %q: %w", startStr, err))
+	}
+
+	if strings.TrimSpace(endStr) == "" {
+		endTime = startTime.AddDate(0, 0, 1)
+	} else {
+		endDateStr := extractDate(endStr)
+		endDate, parseErr := time.Parse("2006-01-02", endDateStr)
+		if parseErr != nil {
+			return time.Time{}, time.Time{}, fieldError("end", fmt.Errorf("invalid end date %q: %w", endStr, parseErr))
+		}
+		if endDate.Before(startTime) {
+			return time.Time{}, time.Time{}, fieldError("end", fmt.Errorf(testutil.ErrMsgEndDateAfterStart))
+		}
+		endTime = endDate.AddDate(0, 0, 1)
+	}
+
+	return startTime, endTime, nil
+}
+
+func parseBatchTimedEventTimes(rec batchRecord, startStr, startTZ, summary string) (startTime, endTime time.Time, err error) {
+	if looksLikeClock(startStr) {
+		startStr = prependToday(startStr, startTZ)
+	}
+	startTime, err = time.Parse("2006-01-02 15:04", startStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, fieldError("start", fmt.Errorf("invalid start time %q: %w", rec.Start, err))
+	}
+
+	endTime, err = parseBatchEndTime(rec, startTime, summary)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if !endTime.After(startTime) {
+		field := "end"
+		if strings.TrimSpace(rec.End) == "" {
+			field = "duration"
+		}
+		return time.Time{}, time.Time{}, fieldError(field, fmt.Errorf("end time must be after start time"))
+	}
+
+	return startTime, endTime, nil
+}
+
+func parseBatchEndTime(rec batchRecord, startTime time.Time, summary string) (time.Time, error) {
+	endStr := strings.TrimSpace(rec.End)
+
+	switch {
+	case endStr != "":
+		return parseBatchExplicitEnd(endStr, startTime, rec.Overnight, rec.End)
+	case strings.TrimSpace(rec.Duration) != "":
+		return parseBatchDurationEnd(rec.Duration, startTime)
+	default:
+		dur, _, err := defaultBatchDuration(summary, rec.Categories, startTime)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return startTime.Add(dur), nil
+	}
+}
+
+// defaultBatchDuration picks the duration for a row without end or duration,
+// and describes the rule that picked it (for dry runs). Durations configured
+// per category (category_durations) take precedence over the keyword and
+// time-of-day defaults so defaults stay predictable.
+func defaultBatchDuration(summary string, categories []string, startTime time.Time) (time.Duration, string, error) {
+	cfg, err := config.Current()
+	if err != nil {
+		cfg = config.Defaults()
+	}
+	if cat, spec, ok := cfg.GetCategoryDuration(categories); ok {
+		dur, err := configDuration("category_durations", cat, spec)
+		return dur, ui.T("duration_rule_category", cat), err
+	}
+	return smartDefaultDuration(cfg, summary, startTime)
+}
+
+// configDuration parses the duration of a config entry such as
+// category_durations[name], which must be positive.
+func configDuration(key, name, spec string) (time.Duration, error) {
+	dur, err := calendar.ParseHumanDuration(spec)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s entry for %q: %w", key, name, err)
+	}
+	if dur <= 0 {
+		return 0, fmt.Errorf("invalid %s entry for %q: %s", key, name, testutil.ErrMsgDurationGreaterThanZero)
+	}
+	return dur, nil
+}
+
+func parseBatchExplicitEnd(endStr string, startTime time.Time, overnight bool, originalEnd string) (time.Time, error) {
+	endStr, _ = overnightEnd(startTime.Format(constants.DateTimeFormatISO), endStr, overnight)
+
+	if dur, derr := calendar.ParseHumanDuration(endStr); derr == nil {
+		if dur <= 0 {
+			return time.Time{}, fieldError("end", fmt.Errorf(testutil.ErrMsgDurationGreaterThanZero))
+		}
+		return startTime.Add(dur), nil
+	}
+
+	endTime, err := time.Parse("2006-01-02 15:04", endStr)
+	if err != nil {
+		return time.Time{}, fieldError("end", fmt.Errorf("invalid end time %q: %w", originalEnd, err))
+	}
+	return endTime, nil
+}
+
+func parseBatchDurationEnd(durStr string, startTime time.Time) (time.Time, error) {
+	dur, err := calendar.ParseHumanDuration(durStr)
+	if err != nil {
+		return time.Time{}, fieldError("duration", fmt.Errorf("invalid duration %q: %v", durStr, err))
+	}
+	if dur <= 0 {
+		return time.Time{}, fieldError("duration", fmt.Errorf(testutil.ErrMsgDurationGreaterThanZero))
+	}
+	return startTime.Add(dur), nil
+}
+
+func configureBatchEvent(event *calendar.Event, rec batchRecord, startTZ, endTZ string, strictInput bool) {
+	event.AllDay = rec.AllDay
+
+	if startTZ != "" {
+		event.SetStartTimezone(startTZ)
+	}
+	if endTZ != "" {
+		event.SetEndTimezone(endTZ)
+	} else if startTZ != "" {
+		event.SetEndTimezone(startTZ)
+	}
+
+	event.Location = strings.TrimSpace(rec.Location)
+	event.Description = strings.TrimSpace(rec.Description)
+
+	if strings.TrimSpace(rec.RRule) != "" {
+		event.RRule = strings.TrimSpace(rec.RRule)
+	}
+
+	if strictInput {
+		addEventCategories(event, rec.Categories)
+	} else {
+		addBatchCategories(event, rec.Categories)
+	}
+	addBatchExDates(event, rec.ExDates, startTZ, rec.AllDay)
+	addEventRDates(event, rec.RDates, startTZ, rec.AllDay)
+	addBatchAlarms(event, rec.Alarms, startTZ)
+}
+
+func addBatchCategories(event *calendar.Event, categories []string) {
+	for _, cat := range categories {
+		if validated := validateCategoryWithSuggestion(cat); validated != "" {
+			event.AddCategory(validated)
+		}
+	}
+}
+
+// categoryTaxonomy returns the configured category_taxonomy; it is empty
+// without a config file or when the config does not load.
+func categoryTaxonomy() *calendar.Taxonomy {
+	cfg, err := config.Current()
+	if err != nil {
+		return nil
+	}
+	return calendar.NewTaxonomy(cfg.CategoryTaxonomy)
+}
+
+// collectUnknownCategories notes categories missing from category_taxonomy,
+// suggesting a known sibling when one is spelled alike.
+func collectUnknownCategories(records []batchRecord, opts *batchOptions) []diag.Warning {
+	taxonomy := categoryTaxonomy()
+	if taxonomy.Empty() {
+		return nil
+	}
+	var warnings []diag.Warning
+	for i, rec := range records {
+		for _, cat := range rec.Categories {
+			if !opts.strictInput {
+				cat = validateCategoryWithSuggestion(cat)
+			}
+			if cat = calendar.CleanCategory(cat); cat == "" {
+				continue
+			}
+			if _, ok := taxonomy.Lookup(cat); ok {
+				continue
+			}
+			hint := ui.T("batch_category_unknown_hint")
+			if s := taxonomy.Suggest(cat); s != "" {
+				hint = ui.T("batch_category_suggest", s)
+			}
+			warnings = append(warnings, diag.Warning{
+				Code: diag.CodeCategory, Severity: diag.SeverityWarning, File: opts.input, Row: i + 1,
+				Message:    ui.T("batch_category_unknown", cat),
+				Suggestion: hint,
+			})
+		}
+	}
+	return warnings
+}
+
+func addBatchExDates(event *calendar.Event, exdates []string, startTZ string, allDay bool) {
+	if len(exdates) == 0 {
+		return
+	}
+
+	tzForEx := event.StartTZ
+	if tzForEx == "" {
+		tzForEx = startTZ
+	}
+
+	parsed, err := parseExDateValues(exdates, tzForEx, allDay)
+	if err == nil {
+		event.ExDates = append(event.ExDates, parsed...)
+	}
+}
+
+func addBatchAlarms(event *calendar.Event, alarms []string, startTZ string) {
+	if len(alarms) == 0 {
+		return
+	}
+
+	defaultAlarmTZ := event.StartTZ
+	if defaultAlarmTZ == "" {
+		defaultAlarmTZ = startTZ
+	}
+
+	expandedAlarms := expandAlarmProfiles(alarms)
+	parsed, err := calendar.ParseAlarmSpecs(expandedAlarms, defaultAlarmTZ)
+	if err == nil {
+		event.Alarms = append(event.Alarms, parsed...)
+	}
+}
+
+// spellingFix is one word replaced by the spell_corrections dictionary.
+type spellingFix struct {
+	from, to string
+}
+
+// correctSpelling fixes common spelling errors and normalizes whitespace in summaries.
+// Helps users with dyslexia or typing errors. corrections is the spell_corrections
+// dictionary from config (see config.Corrections); with nil the words are kept.
+// It also returns each replaced word, lowercased, for --show-corrections.
+func correctSpelling(text string, corrections map[string]string) (string, []spellingFix) {
+	words := strings.Fields(text)
+	var fixes []spellingFix
+	for i, word := range words {
+		lower := strings.ToLower(word)
+		corrected, exists := corrections[lower]
+		if !exists || corrected == lower {
+			continue
+		}
+		fixes = append(fixes, spellingFix{from: lower, to: corrected})
+		// Preserve original capitalization
+		if word[0] >= 'A' && word[0] <= 'Z' {
+			words[i] = strings.Title(corrected)
+		} else {
+			words[i] = corrected
+		}
+	}
+
+	return strings.Join(words, " "), fixes
+}
+
+// normalizeDateTimeInput accepts various date/time formats and normalizes to standard format.
+// Handles common variations like slashes, different separators, etc.
+func normalizeDateTimeInput(input string) string {
+	if input == "" {
+		return input
+	}
+
+	input = strings.TrimSpace(input)
+
+	// Replace common separators
+	// 2025/12/16 -> 2025-12-16
+	input = strings.ReplaceAll(input, "/", "-")
+
+	// Handle missing leading zeros: 2025-1-5 -> 2025-01-05
+	parts := strings.Split(input, " ")
+	if len(parts) >= 1 {
+		datePart := parts[0]
+		dateComponents := strings.Split(datePart, "-")
+		if len(dateComponents) == 3 {
+			// Pad single digits
+			for i, comp := range dateComponents {
+				if len(comp) == 1 && i > 0 { // Don't pad year
+					dateComponents[i] = "0" + comp
+				}
+			}
+			parts[0] = strings.Join(dateComponents, "-")
+		}
+	}
+
+	// Handle time part if present
+	if len(parts) >= 2 {
+		timePart := parts[1]
+		// Handle 24h format without colon: 0900 -> 09:00
+		if len(timePart) == 4 && !strings.Contains(timePart, ":") {
+			parts[1] = timePart[:2] + ":" + timePart[2:]
+		}
+		// Pad single digit hours: 9:00 -> 09:00
+		timeComponents := strings.Split(parts[1], ":")
+		if len(timeComponents) == 2 && len(timeComponents[0]) == 1 {
+			parts[1] = "0" + parts[1]
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// validateCategoryWithSuggestion checks for common typos in category names and auto-corrects them.
+// This helps neurodivergent users who may struggle with spelling or consistency.
+// Categories in category_taxonomy take its spelling; hierarchical ones
+// (Work/ClientA) are never fuzzy-matched, only their top level is capitalized.
+func validateCategoryWithSuggestion(category string) string {
+	category = calendar.CleanCategory(category)
+	taxonomy := categoryTaxonomy()
+	if known, ok := taxonomy.Lookup(category); ok {
+		return known
+	}
+
+	commonCategories := map[string]string{
+		"work":          "Work",
+		"meeting":       "Meeting",
+		"health":        "Health",
+		"medication":    "Medication",
+		"meds":          "Medication",
+		"medical":       "Medical",
+		"therapy":       "Therapy",
+		"mental health": "Mental Health",
+		"exercise":      "Exercise",
+		"workout":       "Workout",
+		"food":          "Food",
+		"meal":          "Meal",
+		"travel":        "Travel",
+		"flight":        "Flight",
+		"hotel":         "Accommodation",
+		"accommodation": "Accommodation",
+		"family":        "Family",
+		"kids":          "Kids",
+		"personal":      "Personal",
+		"focus":         "Focus",
+		"deep work":     "Focus",
+		"break":         "Break",
+		"rest":          "Rest",
+		"transition":    "Transition",
+		"urgent":        "Urgent",
+		"important":     "Important",
+		"fun":           "Fun",
+		"leisure":       "Leisure",
+		"learning":      "Learning",
+		"education":     "Education",
+		"sleep":         "Sleep",
+	}
+
+	lower := strings.ToLower(category)
+
+	// Exact match (case-insensitive)
+	if corrected, exists := commonCategories[lower]; exists {
+		return corrected
+	}
+
+	if levels := calendar.CategoryLevels(category); len(levels) > 1 {
+		if top, exists := commonCategories[strings.ToLower(levels[0])]; exists {
+			levels[0] = top
+		}
+		return strings.Join(levels, calendar.CategorySeparator)
+	}
+	// A taxonomy replaces guessing: unknown names are reported instead.
+	if !taxonomy.Empty() {
+		return category
+	}
+
+	// Check for close matches using Levenshtein distance
+	bestMatch := category
+	bestDistance := 999
+	threshold := 2 // Allow up to 2 character differences
+
+	for known, canonical := range commonCategories {
+		dist := utils.Levenshtein(lower, known)
+		if dist <= threshold && dist < bestDistance {
+			bestDistance = dist
+			bestMatch = canonical
+		}
+	}
+
+	return bestMatch
+}
+
+// addEmojiToSummary adds a relevant emoji prefix to the summary based on categories.
+// Only adds emoji if the summary doesn't already start with one.
+// This provides visual cues that help neurodivergent users quickly scan their calendar.
+func addEmojiToSummary(summary string, categories []string) string {
+	// Skip if summary already starts with an emoji (rough check for non-ASCII)
+	if len(summary) > 0 && summary[0] > 127 {
+		return summary
+	}
+
+	// Map categories to emojis
+	categoryLower := make([]string, len(categories))
+	for i, cat := range categories {
+		categoryLower[i] = strings.ToLower(strings.TrimSpace(cat))
+	}
+
+	// Priority order: most specific first
+	for _, cat := range categoryLower {
+		switch cat {
+		case "medication", "meds":
+			return "💊 " + summary
+		case "health", "medical":
+			return "🏥 " + summary
+		case "therapy", "mental health":
+			return "🧠 " + summary
+		case "exercise", "workout", "fitness":
+			return "💪 " + summary
+		case "food", "meal", "restaurant":
+			return "🍽️ " + summary
+		case "birthday", "birthdays":
+			return "🎂 " + summary
+		case "anniversary":
+			return "💍 " + summary
+		case "countdown", "deadline":
+			return "⏳ " + summary
+		case "train", "rail":
+			return "🚆 " + summary
+		case "travel", "flight":
+			return "✈️ " + summary
+		case "accommodation", "hotel":
+			return "🏨 " + summary
+		case "work", "meeting":
+			return "💼 " + summary
+		case "focus", "deep work":
+			return "🎯 " + summary
+		case "break", "rest":
+			return "☕ " + summary
+		case "transition":
+			return "🔄 " + summary
+		case "family", "kids":
+			return "👨‍👩‍👧 " + summary
+		case "personal":
+			return "🌟 " + summary
+		case "urgent", "important":
+			return "🔥 " + summary
+		case "fun", "leisure":
+			return "🎉 " + summary
+		case "learning", "education":
+			return "📚 " + summary
+		case "sleep":
+			return "😴 " + summary
+		}
+	}
+
+	// Check summary keywords if no category match
+	summaryLower := strings.ToLower(summary)
+	if strings.Contains(summaryLower, "med") || strings.Contains(summaryLower, "pill") {
+		return "💊 " + summary
+	}
+	if strings.Contains(summaryLower, "breakfast") || strings.Contains(summaryLower, "lunch") || strings.Contains(summaryLower, "dinner") {
+		return "🍽️ " + summary
+	}
+	if strings.Contains(summaryLower, "doctor") || strings.Contains(summaryLower, "dentist") || strings.Contains(summaryLower, "appointment") {
+		return "🏥 " + summary
+	}
+	if strings.Contains(summaryLower, "meeting") {
+		return "💼 " + summary
+	}
+	if strings.Contains(summaryLower, "focus") {
+		return "🎯 " + summary
+	}
+
+	return summary
+}
+
+// smartDefaultDuration returns a reasonable duration based on event summary and time of day.
+// This helps neurodivergent users by reducing cognitive load - they don't need to specify duration for common events.
+// The longest duration_keywords entry found in the summary wins; without one, the
+// narrowest duration_time_of_day window holding the start applies, then default_duration.
+func smartDefaultDuration(cfg *config.Config, summary string, startTime time.Time) (time.Duration, string, error) {
+	summaryLower := strings.ToLower(summary)
+	keywords := cfg.SmartDurationKeywords()
+	if keyword := longestKeyword(summaryLower, keywords); keyword != "" {
+		dur, err := configDuration("duration_keywords", keyword, keywords[keyword])
+		return dur, ui.T("duration_rule_keyword", keyword), err
+	}
+
+	window, width := "", 0
+	for spec := range cfg.DurationTimeOfDay {
+		windows, err := calendar.ParseClockWindows(spec)
+		if err != nil {
+			return 0, "", fmt.Errorf("invalid duration_time_of_day entry %q: %w", spec, err)
+		}
+		for _, w := range windows {
+			if w.Contains(startTime) && (window == "" || w.Minutes() < width || w.Minutes() == width && spec < window) {
+				window, width = spec, w.Minutes()
+			}
+		}
+	}
+	if window != "" {
+		dur, err := configDuration("duration_time_of_day", window, cfg.DurationTimeOfDay[window])
+		return dur, ui.T("duration_rule_time", window), err
+	}
+
+	dur, err := configDuration("default_duration", cfg.DefaultDuration, cfg.DefaultDuration)
+	return dur, ui.T("duration_rule_default"), err
+}
+
+// maxConflictsPerDay caps how many overlaps detectEventConflicts lists for
+// one day; the rest of that day is summed up in a single line.
+const maxConflictsPerDay = 10
+
+// detectEventConflicts reports overlapping timed events, grouped by the day
+// the overlap starts (the later event's start date), days in order. The pairs
+// come from a sort+sweep (calendar.EachOverlap) rather than comparing every
+// pair, so --check-conflicts stays fast on semester-sized inputs.
+func detectEventConflicts(events []calendar.Event) []string {
+	type dayConflicts struct {
+		listed []string
+		more   int
+	}
+	byDay := map[string]*dayConflicts{}
+	calendar.EachOverlap(events, func(i, j int) bool {
+		ev1, ev2 := events[i], events[j]
+		if ev2.StartTime.Before(ev1.StartTime) {
+			ev1, ev2 = ev2, ev1
+		}
+		day := ev2.StartTime.Format(constants.DateFormatISO)
+		dc := byDay[day]
+		if dc == nil {
+			dc = &dayConflicts{}
+			byDay[day] = dc
+		}
+		if len(dc.listed) == maxConflictsPerDay {
+			dc.more++
+			return true
+		}
+		dc.listed = append(dc.listed, day+": "+ui.T("batch_conflict",
+			ev1.Summary,
+			ev1.StartTime.Format("15:04"),
+			ev1.EndTime.Format("15:04"),
+			ev2.Summary,
+			ev2.StartTime.Format("15:04"),
+			ev2.EndTime.Format("15:04")))
+		return true
+	})
+
+	days := make([]string, 0, len(byDay))
+	for day := range byDay {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+	var conflicts []string
+	for _, day := range days {
+		conflicts = append(conflicts, byDay[day].listed...)
+		if more := byDay[day].more; more > 0 {
+			conflicts = append(conflicts, ui.T("batch_conflict_more", day, more))
+		}
+	}
+	return conflicts
+}
+
+// hoursPolicy holds the working-hours and quiet-hours windows from config.
+type hoursPolicy struct {
+	working calendar.WeeklyHours
+	quiet   calendar.WeeklyHours
+}
+
+// loadHoursPolicy reads working_hours/quiet_hours from config.
+// A missing config yields an empty policy; malformed windows are an error.
+func loadHoursPolicy() (hoursPolicy, error) {
+	cfg, err := config.Current()
+	if err != nil || cfg == nil {
+		return hoursPolicy{}, nil
+	}
+	working, err := calendar.ParseWeeklyHours(cfg.WorkingHours)
+	if err != nil {
+		return hoursPolicy{}, fmt.Errorf("invalid working_hours in config: %w", err)
+	}
+	quiet, err := calendar.ParseWeeklyHours(cfg.QuietHours)
+	if err != nil {
+		return hoursPolicy{}, fmt.Errorf("invalid quiet_hours in config: %w", err)
+	}
+	return hoursPolicy{working: working, quiet: quiet}, nil
+}
+
+// detectHoursViolations flags timed events that fall outside working hours or
+// start during quiet hours, and alarms that would fire during quiet hours
+// (typically a timezone mistake, e.g. a reminder at 03:00).
+func detectHoursViolations(events []calendar.Event, policy hoursPolicy) []string {
+	var violations []string
+	for i := range events {
+		ev := &events[i]
+		if ev.AllDay {
+			continue
+		}
+		label := fmt.Sprintf("%s (%s)", ev.Summary, ev.StartTime.Format("2006-01-02 15:04"))
+
+		if policy.working.Configured() {
+			lastMinute := ev.EndTime.Add(-time.Minute)
+			if !policy.working.Contains(ev.StartTime) || !policy.working.Contains(lastMinute) {
+				violations = append(violations, ui.T("hours_outside_working", label))
+			}
+		}
+		if !policy.quiet.Configured() {
+			continue
+		}
+		if policy.quiet.Contains(ev.StartTime) {
+			violations = append(violations, ui.T("hours_quiet_start", label))
+		}
+		for _, al := range ev.Alarms {
+			if fire := ev.AlarmFireTime(al); policy.quiet.Contains(fire) {
+				violations = append(violations, ui.T("hours_quiet_alarm", label, fire.Format("2006-01-02 15:04")))
+			}
+		}
+	}
+	return violations
+}
+
+// hoursStrictError turns violations into the error returned under --strict.
+func hoursStrictError(violations []string) error {
+	return fmt.Errorf("%d working/quiet hours violation(s) (--strict):\n  • %s", len(violations), strings.Join(violations, "\n  • "))
+}
+
+// checkEventHours warns on stderr about working/quiet hours violations,
+// or rejects them when strict is set.
+func checkEventHours(events []calendar.Event, strict bool) error {
+	policy, err := loadHoursPolicy()
+	if err != nil {
+		return err
+	}
+	violations := detectHoursViolations(events, policy)
+	if len(violations) == 0 {
+		return nil
+	}
+	if strict {
+		return hoursStrictError(violations)
+	}
+	warnings := make([]diag.Warning, 0, len(violations))
+	for _, v := range violations {
+		warnings = append(warnings, diag.Warning{Code: diag.CodeHours, Severity: diag.SeverityWarning, Message: v})
+	}
+	diag.Render(os.Stderr, warnings)
+	return nil
+}
+
+// dayFilter describes the days events should avoid (--skip-weekends/--skip-holidays).
+type dayFilter struct {
+	skipWeekends bool
+	skipHolidays bool
+	shift        bool // move single events / exclude occurrences instead of only flagging them
+	holidays     calendar.HolidaySet
+}
+
+func (f dayFilter) enabled() bool {
+	return f.skipWeekends || f.skipHolidays
+}
+
+// addDayFilterFlags registers the weekend/holiday flags shared by create and batch.
+func addDayFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("skip-weekends", false, "Move (or flag) events that land on Saturday or Sunday")
+	cmd.Flags().Bool("skip-holidays", false, "Move (or flag) events that land on a holiday (config holidays or --holidays)")
+	cmd.Flags().String("holidays", "", "Holiday file (.ics or 'YYYY-MM-DD Name' lines), merged with config holidays")
+	cmd.Flags().String("skip-mode", "shift", "What to do with blocked days: shift (next free day; EXDATE for recurring) or flag (warn only)")
+}
+
+func parseDayFilterFlags(cmd *cobra.Command) (dayFilter, error) {
+	var f dayFilter
+	f.skipWeekends, _ = cmd.Flags().GetBool("skip-weekends")
+	f.skipHolidays, _ = cmd.Flags().GetBool("skip-holidays")
+
+	mode, _ := cmd.Flags().GetString("skip-mode")
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", "shift":
+		f.shift = true
+	case "flag":
+	default:
+		return f, fmt.Errorf("invalid --skip-mode %q (use shift or flag)", mode)
+	}
+
+	if f.skipHolidays {
+		path, _ := cmd.Flags().GetString("holidays")
+		holidays, err := loadHolidays(path)
+		if err != nil {
+			return f, err
+		}
+		f.holidays = holidays
+	}
+	return f, nil
+}
+
+// loadHolidays merges the holidays from config with those in path (if any).
+func loadHolidays(path string) (calendar.HolidaySet, error) {
+	holidays := calendar.HolidaySet{}
+	if cfg, err := config.Current(); err == nil && cfg != nil {
+		fromConfig, err := calendar.NewHolidaySet(cfg.Holidays)
+		if err != nil {
+			return nil, fmt.Errorf("invalid holidays in config: %w", err)
+		}
+		holidays.Merge(fromConfig)
+	}
+	if strings.TrimSpace(path) != "" {
+		fromFile, err := calendar.LoadHolidaysFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load holidays from %s: %w", path, err)
+		}
+		holidays.Merge(fromFile)
+	}
+	if len(holidays) == 0 {
+		return nil, fmt.Errorf("--skip-holidays needs holiday data: add 'holidays' to config or pass --holidays FILE")
+	}
+	return holidays, nil
+}
+
+// blocked reports why t's date should be avoided ("weekend" or the holiday name).
+func (f dayFilter) blocked(t time.Time) (string, bool) {
+	if f.skipHolidays {
+		if name, ok := f.holidays.Lookup(t); ok {
+			return name, true
+		}
+	}
+	if f.skipWeekends && calendar.IsWeekend(t) {
+		return ui.T("day_filter_weekend"), true
+	}
+	return "", false
+}
+
+// applyDayFilter moves single events off blocked days and excludes blocked
+// occurrences of recurring events (EXDATE). In flag mode nothing is changed.
+// It returns one human-readable note per affected event.
+func applyDayFilter(events []calendar.Event, f dayFilter) []string {
+	if !f.enabled() {
+		return nil
+	}
+	var notes []string
+	for i := range events {
+		ev := &events[i]
+		if strings.TrimSpace(ev.RRule) != "" {
+			if note := f.excludeOccurrences(ev); note != "" {
+				notes = append(notes, note)
+			}
+			continue
+		}
+
+		reason, isBlocked := f.blocked(ev.StartTime)
+		if !isBlocked {
+			continue
+		}
+		label := fmt.Sprintf("%s (%s)", ev.Summary, ev.StartTime.Format(constants.DateFormatISO))
+		if !f.shift {
+			notes = append(notes, ui.T("day_filter_falls_on", label, reason))
+			continue
+		}
+		days, ok := f.daysToNextFree(ev.StartTime)
+		if !ok {
+			notes = append(notes, ui.T("day_filter_no_free_day", label, reason))
+			continue
+		}
+		shiftEventDays(ev, days)
+		notes = append(notes, ui.T("day_filter_moved", label, reason, ev.StartTime.Format(constants.DateFormatISO)))
+	}
+	return notes
+}
+
+func (f dayFilter) daysToNextFree(t time.Time) (int, bool) {
+	for days := 1; days <= 31; days++ {
+		if _, isBlocked := f.blocked(t.AddDate(0, 0, days)); !isBlocked {
+			return days, true
+		}
+	}
+	return 0, false
+}
+
+func (f dayFilter) excludeOccurrences(ev *calendar.Event) string {
+	rule, err := calendar.ParseRRule(ev.RRule)
+	if err != nil {
+		return ui.T("day_filter_rrule_error", ev.Summary, err)
+	}
+	excluded := make(map[string]bool, len(ev.ExDates))
+	for _, x := range ev.ExDates {
+		excluded[x.Format(constants.DateFormatISO)] = true
+	}
+
+	var hits []string
+	for _, occ := range rule.Occurrences(ev.StartTime, calendar.DefaultMaterializeLimit) {
+		day := occ.Format(constants.DateFormatISO)
+		if _, isBlocked := f.blocked(occ); !isBlocked || excluded[day] {
+			continue
+		}
+		hits = append(hits, day)
+		if f.shift {
+			ev.ExDates = append(ev.ExDates, occ)
+		}
+	}
+	if len(hits) == 0 {
+		return ""
+	}
+	if f.shift {
+		return ui.T("day_filter_excluded", ev.Summary, len(hits), strings.Join(hits, ", "))
+	}
+	return ui.T("day_filter_occurrences", ev.Summary, len(hits), strings.Join(hits, ", "))
+}
+
+// shiftEventDays moves ev (and any absolute alarms) by whole days, keeping wall-clock times.
+func shiftEventDays(ev *calendar.Event, days int) {
+	ev.StartTime = ev.StartTime.AddDate(0, 0, days)
+	ev.EndTime = ev.EndTime.AddDate(0, 0, days)
+	for i := range ev.Alarms {
+		if !ev.Alarms[i].TriggerIsRelative {
+			ev.Alarms[i].TriggerTime = ev.Alarms[i].TriggerTime.AddDate(0, 0, days)
+		}
+	}
+}
+
+// bufferRules are the buffers --add-prep-time puts before (prep) and after
+// (transition) events, keyed by lowercase category or summary keyword.
+type bufferRules struct {
+	prep        map[string]time.Duration
+	transition  map[string]time.Duration
+	transparent bool
+}
+
+// loadBufferRules reads prep_buffers, transition_buffers and
+// transparent_buffers from config, then applies --prep-before,
+// --transition-after and --transparent-buffers on top.
+func loadBufferRules(cmd *cobra.Command) (bufferRules, error) {
+	cfg, err := config.Current()
+	if err != nil {
+		cfg = config.Defaults()
+	}
+	rules, err := bufferRulesFromConfig(cfg)
+	if err != nil {
+		return rules, err
+	}
+	for _, f := range []struct {
+		flag  string
+		rules map[string]time.Duration
+	}{{"prep-before", rules.prep}, {"transition-after", rules.transition}} {
+		specs, _ := cmd.Flags().GetStringArray(f.flag)
+		for _, spec := range specs {
+			if err := setBufferRule(f.rules, spec); err != nil {
+				return rules, fmt.Errorf("--%s: %w", f.flag, err)
+			}
+		}
+	}
+	if cmd.Flags().Changed("transparent-buffers") {
+		rules.transparent, _ = cmd.Flags().GetBool("transparent-buffers")
+	}
+	return rules, nil
+}
+
+func bufferRulesFromConfig(cfg *config.Config) (bufferRules, error) {
+	rules := bufferRules{transparent: cfg.TransparentBuffers}
+	var err error
+	if rules.prep, err = parseBufferEntries("prep_buffers", cfg.ActivePrepBuffers()); err != nil {
+		return rules, err
+	}
+	rules.transition, err = parseBufferEntries("transition_buffers", cfg.ActiveTransitionBuffers())
+	return rules, err
+}
+
+func parseBufferEntries(key string, entries map[string]string) (map[string]time.Duration, error) {
+	rules := make(map[string]time.Duration, len(entries))
+	for name, spec := range entries {
+		dur, err := configDuration(key, name, spec)
+		if err != nil {
+			return nil, err
+		}
+		rules[name] = dur
+	}
+	return rules, nil
+}
+
+// setBufferRule applies one NAME=DURATION flag value; a zero duration (or
+// "off") removes the rule.
+func setBufferRule(rules map[string]time.Duration, spec string) error {
+	name, value, ok := strings.Cut(spec, "=")
+	name = strings.ToLower(strings.TrimSpace(name))
+	value = strings.TrimSpace(value)
+	if !ok || name == "" {
+		return fmt.Errorf("%q must be NAME=DURATION, e.g. Meeting=15m", spec)
+	}
+	if value == "0" || strings.EqualFold(value, "off") {
+		delete(rules, name)
+		return nil
+	}
+	dur, err := calendar.ParseHumanDuration(value)
+	if err != nil {
+		return fmt.Errorf("%q: %w", spec, err)
+	}
+	if dur <= 0 {
+		return fmt.Errorf("%q: %s", spec, testutil.ErrMsgDurationGreaterThanZero)
+	}
+	rules[name] = dur
+	return nil
+}
+
+// generatePrepTimeEvents creates preparation and transition buffer events.
+// Based on ADHD time boxing research: 15min buffers prevent task derailment.
+// Evidence: https://akiflow.com/blog/time-blocking-adhd
+//
+// Buffers never overlap anything busy: every occurrence of the timed events
+// and the buffers placed so far (earliest events first). One that would is
+// shortened to the free gap, or left out when there is none; the notes say
+// which.
+func generatePrepTimeEvents(events []calendar.Event, rules bufferRules) ([]*calendar.Event, []bufferNote) {
+	var prepEvents []*calendar.Event
+	var notes []bufferNote
+	busy := newBusyTimeline(events)
+	var placed busyTimeline
+
+	order := make([]int, 0, len(events))
+	for i := range events {
+		if !events[i].AllDay {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		sa, _ := events[order[a]].Instants()
+		sb, _ := events[order[b]].Instants()
+		return sa.Before(sb)
+	})
+
+	for _, i := range order {
+		ev := events[i]
+		start, end := ev.Instants()
+
+		if want := bufferFor(rules.prep, ev); want > 0 {
+			got, blocker := fitBuffer(start.Add(-want), start, false, &busy, &placed)
+			if got > 0 {
+				prepEvent := createPrepEvent(ev, got, rules)
+				prepEvents = append(prepEvents, prepEvent)
+				placed.add(start.Add(-got), start, prepEvent.Summary)
+			}
+			if got < want {
+				notes = append(notes, bufferNote{event: ev, want: want, got: got, blocker: blocker})
+			}
+		}
+		if want := bufferFor(rules.transition, ev); want > 0 {
+			got, blocker := fitBuffer(end, end.Add(want), true, &busy, &placed)
+			if got > 0 {
+				transitionEvent := createTransitionEvent(ev, got, rules)
+				prepEvents = append(prepEvents, transitionEvent)
+				placed.add(end, end.Add(got), transitionEvent.Summary)
+			}
+			if got < want {
+				notes = append(notes, bufferNote{event: ev, transition: true, want: want, got: got, blocker: blocker})
+			}
+		}
+	}
+
+	return prepEvents, notes
+}
+
+// bufferNote records a buffer that was shortened (got > 0) or left out
+// (got == 0) because blocker took part of its slot.
+type bufferNote struct {
+	event      calendar.Event
+	transition bool
+	want, got  time.Duration
+	blocker    string
+}
+
+// busySpan is a stretch of time taken by an event or buffer.
+type busySpan struct {
+	start, end time.Time
+	summary    string
+}
+
+// busyTimeline holds busy spans sorted by start.
+type busyTimeline struct {
+	spans   []busySpan
+	longest time.Duration
+}
+
+// newBusyTimeline expands the timed, opaque events into the instants their
+// occurrences take.
+func newBusyTimeline(events []calendar.Event) busyTimeline {
+	var tl busyTimeline
+	for i := range events {
+		ev := &events[i]
+		if ev.AllDay || ev.Transparent {
+			continue
+		}
+		occurrences := []calendar.Event{*ev}
+		if strings.TrimSpace(ev.RRule) != "" {
+			if expanded, _, err := ev.Materialize(calendar.DefaultMaterializeLimit, 0, nil); err == nil {
+				occurrences = expanded
+			}
+		}
+		for _, occ := range occurrences {
+			start, end := occ.Instants()
+			if end.After(start) {
+				tl.spans = append(tl.spans, busySpan{start, end, ev.Summary})
+				tl.longest = max(tl.longest, end.Sub(start))
+			}
+		}
+	}
+	sort.SliceStable(tl.spans, func(i, j int) bool { return tl.spans[i].start.Before(tl.spans[j].start) })
+	return tl
+}
+
+func (tl *busyTimeline) add(start, end time.Time, summary string) {
+	i := sort.Search(len(tl.spans), func(i int) bool { return tl.spans[i].start.After(start) })
+	tl.spans = slices.Insert(tl.spans, i, busySpan{start, end, summary})
+	tl.longest = max(tl.longest, end.Sub(start))
+}
+
+// overlapping returns the spans that share time with [start, end).
+func (tl *busyTimeline) overlapping(start, end time.Time) []busySpan {
+	from := sort.Search(len(tl.spans), func(i int) bool { return !tl.spans[i].start.Before(start.Add(-tl.longest)) })
+	var out []busySpan
+	for _, s := range tl.spans[from:] {
+		if !s.start.Before(end) {
+			break
+		}
+		if s.end.After(start) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// fitBuffer returns how much of [start, end) a buffer can take without
+// overlapping the timelines. A prep buffer must end at end and a transition
+// (after) must begin at start, so the free part is the one touching the
+// event; blocker names what cut it.
+func fitBuffer(start, end time.Time, after bool, timelines ...*busyTimeline) (time.Duration, string) {
+	blocker := ""
+	for _, tl := range timelines {
+		for _, s := range tl.overlapping(start, end) {
+			switch {
+			case after && s.start.After(start):
+				if s.start.Before(end) {
+					end, blocker = s.start, s.summary
+				}
+			case !after && s.end.Before(end):
+				if s.end.After(start) {
+					start, blocker = s.end, s.summary
+				}
+			default:
+				// Busy right next to the event: no room at all.
+				return 0, s.summary
+			}
+		}
+	}
+	return end.Sub(start), blocker
+}
+
+// bufferWarnings reports how many buffers were added and each one that had
+// to be shortened or left out.
+func bufferWarnings(added int, notes []bufferNote, file string) []diag.Warning {
+	var warnings []diag.Warning
+	if added > 0 {
+		warnings = append(warnings, diag.Warning{
+			Code: diag.CodeBuffer, Severity: diag.SeverityInfo, File: file,
+			Message: ui.T("batch_buffers_added", added),
+		})
+	}
+	for _, n := range notes {
+		key := "batch_buffer_prep"
+		if n.transition {
+			key = "batch_buffer_transition"
+		}
+		when := displayDate(n.event.StartTime) + " " + stripEmoji(n.event.Summary)
+		msg := ui.T(key+"_skipped", when, formatSpan(n.want), stripEmoji(n.blocker))
+		if n.got > 0 {
+			msg = ui.T(key+"_shortened", when, formatSpan(n.want), formatSpan(n.got), stripEmoji(n.blocker))
+		}
+		warnings = append(warnings, diag.Warning{
+			Code: diag.CodeBuffer, Severity: diag.SeverityWarning, File: file,
+			Message: msg, Suggestion: ui.T("batch_buffer_hint"),
+		})
+	}
+	return warnings
+}
+
+// annotateTimes appends relative-time notes to the descriptions of timed
+// events: how long each lasts and, for single events, how it starts against
+// the previous event that day ("starts 45 min after your previous event
+// ends"), so the gap is visible without doing the arithmetic.
+func annotateTimes(events []calendar.Event) {
+	order := make([]int, 0, len(events))
+	for i := range events {
+		if !events[i].AllDay {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return events[order[a]].StartTime.Before(events[order[b]].StartTime)
+	})
+
+	var prev *calendar.Event // the single event ending latest so far that day
+	day := ""
+	for _, i := range order {
+		ev := &events[i]
+		var notes []string
+		if length := ev.EndTime.Sub(ev.StartTime); length > 0 {
+			until := displayClock(ev.EndTime)
+			if !sameDay(ev.StartTime, ev.EndTime) {
+				until = displayDate(ev.EndTime) + " " + until
+			}
+			notes = append(notes, ui.T("annotation_lasts", formatSpan(length), until))
+		}
+		if strings.TrimSpace(ev.RRule) == "" {
+			if d := ev.StartTime.Format(constants.DateFormatISO); d != day {
+				day, prev = d, nil
+			}
+			switch {
+			case prev == nil:
+				notes = append(notes, ui.T("annotation_first"))
+			case prev.EndTime.Equal(ev.StartTime):
+				notes = append(notes, ui.T("annotation_back_to_back", stripEmoji(prev.Summary)))
+			case prev.EndTime.Before(ev.StartTime):
+				notes = append(notes, ui.T("annotation_after", formatSpan(ev.StartTime.Sub(prev.EndTime)), stripEmoji(prev.Summary)))
+			default:
+				overlap := prev.EndTime
+				if ev.EndTime.Before(overlap) {
+					overlap = ev.EndTime
+				}
+				notes = append(notes, ui.T("annotation_overlap", stripEmoji(prev.Summary), formatSpan(overlap.Sub(ev.StartTime))))
+			}
+			if prev == nil || ev.EndTime.After(prev.EndTime) {
+				prev = ev
+			}
+		}
+		if len(notes) == 0 {
+			continue
+		}
+		if ev.Description != "" {
+			ev.Description += "\n\n"
+		}
+		ev.Description += strings.Join(notes, "\n")
+	}
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Format(constants.DateFormatISO) == b.Format(constants.DateFormatISO)
+}
+
+// formatSpan writes d for people rather than parsers: "45 min", "1 h 30 min",
+// "2 d 3 h".
+func formatSpan(d time.Duration) string {
+	if d < time.Minute {
+		return "<1 min"
+	}
+	mins := int(d.Round(time.Minute) / time.Minute)
+	days, hours, minutes := mins/(24*60), mins/60%24, mins%60
+	switch {
+	case days > 0 && hours > 0:
+		return fmt.Sprintf("%d d %d h", days, hours)
+	case days > 0:
+		return fmt.Sprintf("%d d", days)
+	case hours > 0 && minutes > 0:
+		return fmt.Sprintf("%d h %d min", hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%d h", hours)
+	}
+	return fmt.Sprintf("%d min", minutes)
+}
+
+// travelRules are the travel times between locations that batch checks
+// consecutive events against (travel_times, default_travel_time and
+// --travel-time), and whether to warn and insert travel buffers.
+type travelRules struct {
+	pairs    []travelPair
+	fallback time.Duration // for other pairs of different locations; 0 = none
+	check    bool          // warn about gaps shorter than the travel time
+	add      bool          // insert travel buffers (--add-travel-time)
+}
+
+// travelPair is one travel_times entry; from and to are normalized.
+type travelPair struct {
+	from, to string
+	dur      time.Duration
+}
+
+func (t travelRules) enabled() bool {
+	return t.check && (len(t.pairs) > 0 || t.fallback > 0)
+}
+
+// loadTravelRules reads travel_times and default_travel_time from config,
+// with --travel-time replacing the default.
+func loadTravelRules(cmd *cobra.Command) (travelRules, error) {
+	var rules travelRules
+	rules.add, _ = cmd.Flags().GetBool("add-travel-time")
+	cfg, err := config.Current()
+	if err != nil {
+		cfg = config.Defaults()
+	}
+	for _, key := range slices.Sorted(maps.Keys(cfg.TravelTimes)) {
+		from, to, ok := strings.Cut(key, "->")
+		from, to = normalizeLocation(from), normalizeLocation(to)
+		if !ok || from == "" || to == "" {
+			return rules, fmt.Errorf("invalid travel_times entry %q: use \"Place A -> Place B\"", key)
+		}
+		if strings.TrimSpace(cfg.TravelTimes[key]) == "" {
+			continue
+		}
+		dur, err := configDuration("travel_times", key, cfg.TravelTimes[key])
+		if err != nil {
+			return rules, err
+		}
+		rules.pairs = append(rules.pairs, travelPair{from, to, dur})
+	}
+
+	spec, flag := cfg.DefaultTravelTime, ""
+	if cmd.Flags().Changed("travel-time") {
+		spec, _ = cmd.Flags().GetString("travel-time")
+		flag = "--travel-time"
+	}
+	if spec = strings.TrimSpace(spec); spec != "" && spec != "0" {
+		if rules.fallback, err = configDuration("default_travel_time", spec, spec); err != nil {
+			if flag != "" {
+				return rules, fmt.Errorf("%s: %w", flag, err)
+			}
+			return rules, err
+		}
+	}
+	return rules, nil
+}
+
+// normalizeLocation lowercases a location and collapses its spaces, so
+// "Main  Office" and "main office" compare equal.
+func normalizeLocation(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// between returns the travel time from one location to another: the
+// travel_times entry whose places are (or are part of) the two locations,
+// in either direction, preferring the listed direction and then the most
+// specific names; else the fallback. Equal locations need no travel.
+func (t travelRules) between(from, to string) time.Duration {
+	from, to = normalizeLocation(from), normalizeLocation(to)
+	if from == "" || to == "" || from == to {
+		return 0
+	}
+	matches := func(location, place string) bool { return strings.Contains(location, place) }
+	best, bestScore := t.fallback, 0
+	for _, p := range t.pairs {
+		score := 0
+		switch {
+		case matches(from, p.from) && matches(to, p.to):
+			score = 2*(len(p.from)+len(p.to)) + 1
+		case matches(from, p.to) && matches(to, p.from):
+			score = 2 * (len(p.from) + len(p.to))
+		}
+		if score > bestScore {
+			best, bestScore = p.dur, score
+		}
+	}
+	return best
+}
+
+// travelNote records two consecutive events whose gap is shorter than the
+// travel between their locations.
+type travelNote struct {
+	from, to  calendar.Event
+	gap, want time.Duration
+}
+
+// planTravel walks the timed events of rows in order and, for each one at a
+// different location than the previous event that day, fits a travel buffer
+// before it (before its prep buffer, if any) in the time busy leaves free.
+// Gaps shorter than the travel time become notes; the buffers are cut to
+// the gap.
+func planTravel(rows, busy []calendar.Event, rules travelRules) ([]*calendar.Event, []travelNote) {
+	timeline := newBusyTimeline(busy)
+	var placed busyTimeline
+	byUID := map[string]*calendar.Event{}
+	for i := range busy {
+		if uid := strings.TrimSpace(busy[i].UID); uid != "" {
+			byUID[uid] = &busy[i]
+		}
+	}
+
+	order := make([]int, 0, len(rows))
+	for i := range rows {
+		if !rows[i].AllDay {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		sa, _ := rows[order[a]].Instants()
+		sb, _ := rows[order[b]].Instants()
+		return sa.Before(sb)
+	})
+
+	var travel []*calendar.Event
+	var notes []travelNote
+	for k := 1; k < len(order); k++ {
+		prev, ev := rows[order[k-1]], rows[order[k]]
+		if !sameDay(prev.StartTime, ev.StartTime) {
+			continue
+		}
+		want := rules.between(prev.Location, ev.Location)
+		if want <= 0 {
+			continue
+		}
+		arrive := ev.StartTime // wall clock, in ev's zone
+		if strings.TrimSpace(ev.UID) != "" {
+			if prep, ok := byUID[calendar.StableUID(ev.UID, "prep")]; ok {
+				arrive = prep.StartTime
+			}
+		}
+		at := calendar.Event{StartTime: arrive, StartTZ: ev.StartTZ}
+		end, _ := at.Instants()
+		got, _ := fitBuffer(end.Add(-want), end, false, &timeline, &placed)
+		if got < want {
+			notes = append(notes, travelNote{from: prev, to: ev, gap: got, want: want})
+		}
+		if got <= 0 {
+			continue
+		}
+		placed.add(end.Add(-got), end, "")
+		travel = append(travel, &calendar.Event{
+			UID:        derivedUID(ev.UID, "travel"),
+			Summary:    "🚗 Travel: " + strings.TrimSpace(prev.Location) + " → " + strings.TrimSpace(ev.Location),
+			StartTime:  arrive.Add(-got),
+			EndTime:    arrive,
+			StartTZ:    ev.StartTZ,
+			EndTZ:      ev.StartTZ,
+			Categories: []string{"Travel"},
+			Status:     "CONFIRMED",
+			Created:    appClock.Now().UTC(),
+			LastMod:    appClock.Now().UTC(),
+		})
+	}
+	return travel, notes
+}
+
+// travelWarnings reports gaps too short for the travel between two
+// locations and, with --add-travel-time, how many travel buffers were added.
+func travelWarnings(added int, notes []travelNote, opts *batchOptions) []diag.Warning {
+	var warnings []diag.Warning
+	if opts.travel.add && added > 0 {
+		warnings = append(warnings, diag.Warning{
+			Code: diag.CodeTravel, Severity: diag.SeverityInfo, File: opts.input,
+			Message: ui.T("batch_travel_added", added),
+		})
+	}
+	for _, n := range notes {
+		from := fmt.Sprintf("%s (%s)", stripEmoji(n.from.Summary), strings.TrimSpace(n.from.Location))
+		to := fmt.Sprintf("%s (%s)", stripEmoji(n.to.Summary), strings.TrimSpace(n.to.Location))
+		msg := ui.T("batch_travel_none", displayDate(n.to.StartTime), from, to, formatSpan(n.want))
+		if n.gap > 0 {
+			msg = ui.T("batch_travel_short", displayDate(n.to.StartTime), formatSpan(n.gap), from, to, formatSpan(n.want))
+		}
+		warnings = append(warnings, diag.Warning{
+			Code: diag.CodeTravel, Severity: diag.SeverityWarning, File: opts.input,
+			Message: msg, Suggestion: ui.T("batch_travel_hint"),
+		})
+	}
+	return warnings
+}
+
+func createTransitionEvent(ev calendar.Event, duration time.Duration, rules bufferRules) *calendar.Event {
+	return &calendar.Event{
+		UID:         derivedUID(ev.UID, "transition"),
+		Summary:     "🔄 Transition: " + stripEmoji(ev.Summary),
+		StartTime:   ev.EndTime,
+		EndTime:     ev.EndTime.Add(duration),
+		StartTZ:     ev.StartTZ,
+		EndTZ:       ev.EndTZ,
+		AllDay:      false,
+		Categories:  []string{"Transition"},
+		Status:      "CONFIRMED",
+		Transparent: rules.transparent,
+		Created:     appClock.Now().UTC(),
+		LastMod:     appClock.Now().UTC(),
+	}
+}
+
+func createPrepEvent(ev calendar.Event, duration time.Duration, rules bufferRules) *calendar.Event {
+	return &calendar.Event{
+		UID:         derivedUID(ev.UID, "prep"),
+		Summary:     "⏰ Preparation: " + stripEmoji(ev.Summary),
+		StartTime:   ev.StartTime.Add(-duration),
+		EndTime:     ev.StartTime,
+		StartTZ:     ev.StartTZ,
+		EndTZ:       ev.EndTZ,
+		AllDay:      false,
+		Categories:  []string{"Preparation"},
+		Status:      "CONFIRMED",
+		Transparent: rules.transparent,
+		Created:     appClock.Now().UTC(),
+		LastMod:     appClock.Now().UTC(),
+	}
+}
+
+// bufferFor returns the buffer of the rule named after one of ev's categories,
+// or else the largest buffer among the rule keywords found in its summary, so
+// "Doctor appointment" gets the medical travel buffer (0 for none).
+func bufferFor(rules map[string]time.Duration, ev calendar.Event) time.Duration {
+	for _, cat := range ev.Categories {
+		if dur, ok := rules[strings.ToLower(strings.TrimSpace(cat))]; ok {
+			return dur
+		}
+	}
+	summary := strings.ToLower(ev.Summary)
+	var largest time.Duration
+	for keyword, dur := range rules {
+		if dur > largest && strings.Contains(summary, keyword) {
+			largest = dur
+		}
+	}
+	return largest
+}
+
+// longestKeyword returns the longest key of keywords found in text (ties go
+// to the alphabetically first), or "" when none is.
+func longestKeyword[V any](text string, keywords map[string]V) string {
+	best := ""
+	for k := range keywords {
+		if strings.Contains(text, k) && (len(k) > len(best) || len(k) == len(best) && k < best) {
+			best = k
+		}
+	}
+	return best
+}
+
+// stripEmoji removes emoji from event summary for prep event names
+func stripEmoji(s string) string {
+	// Remove common emoji prefixes
+	s = strings.TrimSpace(s)
+	if len(s) > 0 {
+		// Simple approach: if starts with emoji (unicode > 127), skip first char
+		firstRune := []rune(s)[0]
+		if firstRune > 127 {
+			runes := []rune(s)
+			if len(runes) > 1 {
+				return strings.TrimSpace(string(runes[1:]))
+			}
+		}
+	}
+	return s
+}
+
+// generateUID creates a unique identifier for calendar events, in the
+// uid_template format.
+func generateUID() string {
+	return calendar.NewUID()
+}
+
+// derivedUID returns a UID for a helper event (prep, transition) that stays
+// stable whenever its parent's UID is stable.
+func derivedUID(parentUID, kind string) string {
+	if strings.TrimSpace(parentUID) == "" {
+		return generateUID()
+	}
+	return calendar.StableUID(parentUID, kind)
+}
+
+// defaultMaxEventsPerDay is the overload threshold of --dry-run and
+// --auto-balance when --max-events-per-day is not set.
+const defaultMaxEventsPerDay = 8
+
+// balanceMaxDays is how far --auto-balance looks for a day with room.
+const balanceMaxDays = 14
+
+// balanceMove is an event --auto-balance moves by days, with its buffers.
+type balanceMove struct {
+	event   int   // index into the events
+	buffers []int // its prep/transition buffers, moved along
+	days    int
+}
+
+// planBalance picks events to move off days with more than maxPerDay events
+// (counted as detectOverwhelmDays does): on each such day the lowest-priority
+// single timed events go first, later ones before earlier ones, each to the
+// nearest day (later on a tie) that stays within maxPerDay with it and its
+// buffers. Days the day filter blocks and days before today are never used.
+// It also returns the days that stay overloaded.
+func planBalance(events []calendar.Event, maxPerDay int, f dayFilter) ([]balanceMove, []string) {
+	counts := map[string]int{}
+	byUID := map[string]int{}
+	for i, ev := range events {
+		counts[ev.StartTime.Format(constants.DateFormatISO)]++
+		if uid := strings.TrimSpace(ev.UID); uid != "" {
+			byUID[uid] = i
+		}
+	}
+	isBuffer := map[int]bool{}
+	buffersOf := func(ev calendar.Event) []int {
+		var out []int
+		if strings.TrimSpace(ev.UID) == "" {
+			return nil
+		}
+		for _, kind := range []string{"prep", "transition"} {
+			if j, ok := byUID[calendar.StableUID(ev.UID, kind)]; ok {
+				out = append(out, j)
+			}
+		}
+		return out
+	}
+	for _, ev := range events {
+		for _, j := range buffersOf(ev) {
+			isBuffer[j] = true
+		}
+	}
+
+	today := appClock.Now().Format(constants.DateFormatISO)
+	var moves []balanceMove
+	var stuck []string
+	for _, day := range slices.Sorted(maps.Keys(counts)) {
+		if counts[day] <= maxPerDay {
+			continue
+		}
+		var movable []int
+		for i, ev := range events {
+			if ev.StartTime.Format(constants.DateFormatISO) == day && !ev.AllDay && !isBuffer[i] && strings.TrimSpace(ev.RRule) == "" {
+				movable = append(movable, i)
+			}
+		}
+		sort.SliceStable(movable, func(a, b int) bool {
+			ea, eb := events[movable[a]], events[movable[b]]
+			if pa, pb := priorityRank(ea.Priority), priorityRank(eb.Priority); pa != pb {
+				return pa > pb
+			}
+			return ea.StartTime.After(eb.StartTime)
+		})
+		for _, i := range movable {
+			if counts[day] <= maxPerDay {
+				break
+			}
+			buffers := buffersOf(events[i])
+			size := 1 + len(buffers)
+		search:
+			for dist := 1; dist <= balanceMaxDays; dist++ {
+				for _, days := range []int{dist, -dist} {
+					t := events[i].StartTime.AddDate(0, 0, days)
+					target := t.Format(constants.DateFormatISO)
+					if _, blocked := f.blocked(t); (f.enabled() && blocked) || target < today || counts[target]+size > maxPerDay {
+						continue
+					}
+					counts[day] -= size
+					counts[target] += size
+					moves = append(moves, balanceMove{event: i, buffers: buffers, days: days})
+					break search
+				}
+			}
+		}
+		if counts[day] > maxPerDay {
+			stuck = append(stuck, day)
+		}
+	}
+	return moves, stuck
+}
+
+// autoBalance runs --auto-balance over events: with --dry-run it only
+// reports the moves planBalance proposes; otherwise it asks before applying
+// them (unless --yes) and reports what it moved.
+func autoBalance(events []calendar.Event, opts *batchOptions) ([]diag.Warning, error) {
+	if !opts.autoBalance {
+		return nil, nil
+	}
+	maxPerDay := opts.maxEventsPerDay
+	if maxPerDay <= 0 {
+		maxPerDay = defaultMaxEventsPerDay
+	}
+	moves, stuck := planBalance(events, maxPerDay, opts.days)
+
+	apply := !opts.dryRun && len(moves) > 0
+	if apply && !opts.balanceYes {
+		if opts.jsonOutput || !isInteractiveTerminal() {
+			return nil, fmt.Errorf("--auto-balance would move %d event(s); rerun with --yes to apply or --dry-run to preview", len(moves))
+		}
+		output.Info(os.Stdout, "⚖️", "%s\n", ui.T("balance_plan", len(moves), maxPerDay))
+		for _, m := range moves {
+			ev := events[m.event]
+			fmt.Printf("  • %s\n", ui.T("balance_move", stripEmoji(ev.Summary), displayDate(ev.StartTime), displayDate(ev.StartTime.AddDate(0, 0, m.days))))
+		}
+		apply = prompts.Confirm(ui.T("balance_confirm"))
+	}
+
+	var warnings []diag.Warning
+	add := func(severity diag.Severity, msg string) {
+		warnings = append(warnings, diag.Warning{Code: diag.CodeBalance, Severity: severity, File: opts.input, Message: msg})
+	}
+	for _, m := range moves {
+		ev := &events[m.event]
+		from, to := displayDate(ev.StartTime), displayDate(ev.StartTime.AddDate(0, 0, m.days))
+		switch {
+		case apply:
+			shiftEventDays(ev, m.days)
+			for _, j := range m.buffers {
+				shiftEventDays(&events[j], m.days)
+			}
+			add(diag.SeverityInfo, ui.T("balance_moved", stripEmoji(ev.Summary), from, to))
+		default:
+			add(diag.SeverityInfo, ui.T("balance_would_move", stripEmoji(ev.Summary), from, to))
+		}
+	}
+	for _, day := range stuck {
+		t, _ := time.Parse(constants.DateFormatISO, day)
+		add(diag.SeverityWarning, ui.T("balance_stuck", displayDate(t), maxPerDay, balanceMaxDays))
+	}
+	return warnings, nil
+}
+
+// detectOverwhelmDays identifies days with too many events.
+// Returns warnings for days exceeding the threshold.
+func detectOverwhelmDays(events []calendar.Event, maxPerDay int) []string {
+	if maxPerDay == 0 {
+		maxPerDay = defaultMaxEventsPerDay
+	}
+
+	// Group events by date
+	eventsByDay := make(map[string]int)
+	for _, ev := range events {
+		dateKey := ev.StartTime.Format("2006-01-02")
+		eventsByDay[dateKey]++
+	}
+
+	var warnings []string
+	for date, count := range eventsByDay {
+		if count > maxPerDay {
+			t, _ := time.Parse("2006-01-02", date)
+			dayName := t.Format("Monday, Jan 2")
+			warnings = append(warnings, ui.T("batch_overload", dayName, count, maxPerDay))
+		}
+	}
+
+	// Sort warnings by date
+	sort.Strings(warnings)
+	return warnings
+}
+
+// detectEnergyOverload flags days whose energy total exceeds budget. Unlike
+// detectOverwhelmDays it weighs events: three deep-focus blocks at energy 4
+// cost more than five check-ins at 1. Timed events without an energy value
+// count as 1, all-day ones as 0. Each warning names the lowest-priority
+// events to move until the day fits.
+func detectEnergyOverload(events []calendar.Event, budget int) []string {
+	byDay := make(map[string][]*calendar.Event)
+	totals := make(map[string]int)
+	for i := range events {
+		ev := &events[i]
+		if cost := eventEnergy(ev); cost > 0 {
+			day := ev.StartTime.Format(constants.DateFormatISO)
+			byDay[day] = append(byDay[day], ev)
+			totals[day] += cost
+		}
+	}
+
+	var warnings []string
+	for _, day := range slices.Sorted(maps.Keys(totals)) {
+		total := totals[day]
+		if total <= budget {
+			continue
+		}
+		movable := slices.Clone(byDay[day])
+		sort.SliceStable(movable, func(i, j int) bool {
+			if pi, pj := priorityRank(movable[i].Priority), priorityRank(movable[j].Priority); pi != pj {
+				return pi > pj
+			}
+			return eventEnergy(movable[i]) > eventEnergy(movable[j])
+		})
+		var names []string
+		for _, ev := range movable {
+			if total <= budget {
+				break
+			}
+			total -= eventEnergy(ev)
+			names = append(names, strconv.Quote(stripEmoji(ev.Summary)))
+		}
+		t, _ := time.Parse(constants.DateFormatISO, day)
+		warnings = append(warnings, ui.T("batch_energy_over", t.Format("Monday, Jan 2"), totals[day], budget, strings.Join(names, ", ")))
+	}
+	return warnings
+}
+
+// eventEnergy is the energy an event takes from its day's budget.
+func eventEnergy(ev *calendar.Event) int {
+	switch {
+	case ev.Energy > 0:
+		return ev.Energy
+	case ev.AllDay:
+		return 0
+	}
+	return 1
+}
+
+// priorityRank orders ICS priorities from 1 (most important) to 9; unset
+// counts as medium (5), as in RFC 5545's three-level mapping.
+func priorityRank(p int) int {
+	if p < 1 || p > 9 {
+		return 5
+	}
+	return p
+}
+
+// expandAlarmProfiles replaces profile references (e.g., "profile:adhd-triple") with actual alarm triggers.
+// If a spec doesn't start with "profile:", it's returned as-is.
+func expandAlarmProfiles(alarmSpecs []string) []string {
+	cfg, err := config.Current()
+	if err != nil {
+		// If config can't be loaded, return specs unchanged
+		return alarmSpecs
+	}
+
+	expanded := make([]string, 0, len(alarmSpecs))
+	for _, spec := range alarmSpecs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		// Check if it's a profile reference
+		if strings.HasPrefix(spec, "profile:") {
+			profileName := strings.TrimPrefix(spec, "profile:")
+			profileName = strings.TrimSpace(profileName)
+
+			profile := cfg.GetAlarmProfile(profileName)
+			if profile != nil {
+				expanded = append(expanded, profile...)
+			} else {
+				// Profile not found, keep original spec (will error later)
+				expanded = append(expanded, spec)
+			}
+		} else {
+			expanded = append(expanded, spec)
+		}
+	}
+
+	return expanded
+}
+
+// ========================================================================
+// Batch Template Generator
+// ========================================================================
+
+// bundleSkipFlags are run-specific batch flags left out of a source bundle.
+var bundleSkipFlags = map[string]bool{
+	"input": true, "output": true, "embed-source": true, "watch": true, "dry-run": true,
+	"json": true, "no-fix": true, "sarif": true, "config": true,
+}
+
+// bundleFlags records the flags set explicitly on a batch run.
+func bundleFlags(cmd *cobra.Command) map[string]string {
+	flags := map[string]string{}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if !bundleSkipFlags[f.Name] {
+			flags[f.Name] = f.Value.String()
+		}
+	})
+	return flags
+}
+
+// encodeSourceBundle packs the raw input file for --embed-source.
+func encodeSourceBundle(opts *batchOptions, format batchFormat) (string, error) {
+	data, err := os.ReadFile(opts.input)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", opts.input, err)
+	}
+	bundle := calendar.SourceBundle{
+		Version: version,
+		Created: appClock.Now().UTC().Truncate(time.Second),
+		File:    filepath.Base(opts.input),
+		Format:  string(format),
+		Flags:   opts.sourceFlags,
+		Data:    string(data),
+	}
+	return bundle.Encode()
+}
+
+func newBatchSourceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "source <calendar.ics>",
+		Short: "Recover the batch input embedded with --embed-source",
+		Long: `Print (or save with -o) the original batch file a calendar was generated
+from with 'tempus batch --embed-source'. The tempus version, generation date and
+the command to regenerate the calendar are shown on stderr.`,
+		Example: `  tempus batch -i events.csv -o calendar.ics --embed-source
+  tempus batch source calendar.ics -o events.csv`,
+		Args: cobra.ExactArgs(1),
+		RunE: runBatchSource,
+	}
+	cmd.Flags().StringP("output", "o", "", "Write the recovered input to this file (default: stdout)")
+	return cmd
+}
+
+func runBatchSource(cmd *cobra.Command, args []string) error {
+	data, err := readICSFile(args[0])
+	if err != nil {
+		return err
+	}
+	bundle, ok, err := calendar.ExtractSourceBundle(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	if !ok {
+		return fmt.Errorf("%s has no embedded source (generate it with tempus batch --embed-source)", args[0])
+	}
+
+	outputPath, _ := cmd.Flags().GetString("output")
+	outputPath = strings.TrimSpace(outputPath)
+	stderr := cmd.ErrOrStderr()
+	output.Info(stderr, "📦", "%s (%s) from tempus %s, %s\n", bundle.File, firstNonEmpty(bundle.Format, "auto"), bundle.Version, bundle.Created.Format("2006-01-02 15:04 MST"))
+	output.Info(stderr, "↻ ", "%s\n", batchRerunCommand(bundle, firstNonEmpty(outputPath, bundle.File), args[0]))
+
+	if outputPath == "" {
+		_, err := io.WriteString(cmd.OutOrStdout(), bundle.Data)
+		return err
+	}
+	if err := ensureDirForFile(outputPath); err != nil {
+		return err
+	}
+	if err := os.WriteFile(outputPath, []byte(bundle.Data), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+	output.OK(stderr, "Restored: %s\n", outputPath)
+	return nil
+}
+
+// batchRerunCommand renders the batch invocation recorded in a bundle.
+func batchRerunCommand(bundle calendar.SourceBundle, input, output string) string {
+	parts := []string{"tempus", "batch", "-i", shellWord(input), "-o", shellWord(output)}
+	names := make([]string, 0, len(bundle.Flags))
+	for name := range bundle.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := bundle.Flags[name]
+		if value == "true" {
+			parts = append(parts, "--"+name)
+			continue
+		}
+		parts = append(parts, "--"+name, shellWord(value))
+	}
+	return strings.Join(append(parts, "--embed-source"), " ")
+}
+
+func shellWord(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t'\"$\\`;&|<>()*?[]#~") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func newBatchTemplateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "template [type]",
+		Short: "Generate a pre-filled template file for batch mode",
+		Long: `Generate template files to quickly start creating events.
+
+Available template types:
+  basic             - Simple 3-event example (CSV)
+  adhd-routine      - Daily ADHD routine with medication and focus blocks (CSV)
+  medication        - Medication schedule with triple alarms (YAML)
+  work-meetings     - Recurring team meetings (CSV)
+  medical           - Healthcare appointments with prep reminders (CSV)
+  travel            - Travel itinerary with flights and hotels (JSON)
+  family            - Family calendar with mixed events (CSV)
+
+Examples:
+  tempus batch template basic -o my-events.csv
+  tempus batch template adhd-routine -o routine.csv
+  tempus batch template medication -o meds.yaml`,
+		Args: cobra.ExactArgs(1),
+		RunE: runBatchTemplate,
+	}
+
+	cmd.Flags().StringP("output", "o", "", "Output file path (required)")
+	_ = cmd.MarkFlagRequired("output")
+
+	return cmd
+}
+
+func runBatchTemplate(cmd *cobra.Command, args []string) error {
+	templateType := strings.ToLower(strings.TrimSpace(args[0]))
+	output, _ := cmd.Flags().GetString("output")
+
+	if output == "" {
+		return fmt.Errorf("--output is required")
+	}
+
+	content, err := getBatchTemplateContent(templateType)
+	if err != nil {
+		return err
+	}
+
+	if err := outputFS.WriteFile(output, []byte(content), 0600); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	printOK("Template created: %s\n", output)
+	fmt.Printf("Edit the file and run: tempus batch -i %s -o calendar.ics\n", output)
+
+	return nil
 }
 
 func getBatchTemplateContent(templateType string) (string, error) {
@@ -1851,592 +11190,1413 @@ func getBatchTemplateContent(templateType string) (string, error) {
 	}
 }
 
-func getBasicTemplate() string {
-	return `summary,start,duration,start_tz,location,description,categories,alarms
-Team Meeting,2025-12-16 10:00,1h,Europe/Madrid,Conference Room,Weekly sync,Work|Meeting,-15m
-Lunch Break,2025-12-16 13:00,1h,Europe/Madrid,,,Break,
-Doctor Appointment,2025-12-17 09:00,45m,Europe/Madrid,Medical Center,,Health,trigger=-1d;description=Confirm appointment||-2h
-`
+func getBasicTemplate() string {
+	return `summary,start,duration,start_tz,location,description,categories,alarms
+Team Meeting,2025-12-16 10:00,1h,Europe/Madrid,Conference Room,Weekly sync,Work|Meeting,-15m
+Lunch Break,2025-12-16 13:00,1h,Europe/Madrid,,,Break,
+Doctor Appointment,2025-12-17 09:00,45m,Europe/Madrid,Medical Center,,Health,trigger=-1d;description=Confirm appointment||-2h
+`
+}
+
+func getADHDRoutineTemplate() string {
+	return `summary,start,duration,start_tz,location,rrule,categories,description,alarms
+Morning Medication,2025-12-16 08:00,5m,Europe/Madrid,,FREQ=DAILY;COUNT=30,Health|Medication,Take morning meds with food,trigger=-5m||trigger=-1m||trigger=2025-12-16 08:00
+Deep Focus Block,2025-12-16 09:00,2h,Europe/Madrid,,FREQ=WEEKLY;BYDAY=MO;TU;WE;TH;FR;COUNT=20,Work|Focus,NO meetings - deep work only,trigger=-10m;description=Prepare workspace and eliminate distractions||trigger=-1m||trigger=2025-12-16 10:30;description=Halfway - stay focused
+Transition Buffer,2025-12-16 11:00,15m,Europe/Madrid,,FREQ=WEEKLY;BYDAY=MO;TU;WE;TH;FR;COUNT=20,Break|Transition,Stretch and reset before next task,trigger=-1m
+Lunch + Walk,2025-12-16 13:00,1h,Europe/Madrid,,FREQ=DAILY;COUNT=30,Break|Health,Eat away from desk - go outside,trigger=-5m||trigger=2025-12-16 13:30;description=Time to walk
+Evening Medication,2025-12-16 20:00,5m,Europe/Madrid,,FREQ=DAILY;COUNT=30,Health|Medication,Take evening meds,trigger=-5m||trigger=-1m||trigger=2025-12-16 20:00
+Wind Down Routine,2025-12-16 22:00,30m,Europe/Madrid,,FREQ=DAILY;COUNT=30,Health|Sleep,No screens - prepare for sleep,trigger=-15m||trigger=-5m
+`
+}
+
+func getMedicationTemplate() string {
+	return `# Medication Schedule Template
+# Triple alarms: 5min before, 1min before, exact time
+
+- summary: Morning Meds - Methylphenidate 20mg
+  start: "2025-12-16 08:00"
+  duration: 5m
+  start_tz: Europe/Madrid
+  rrule: FREQ=DAILY;COUNT=30
+  categories: [Health, Medication]
+  description: Take with food. Don't skip.
+  alarms:
+    - trigger=-5m
+    - trigger=-1m
+    - trigger=2025-12-16 08:00
+
+- summary: Afternoon Meds - Methylphenidate 10mg
+  start: "2025-12-16 14:00"
+  duration: 5m
+  start_tz: Europe/Madrid
+  rrule: FREQ=DAILY;COUNT=30
+  categories: [Health, Medication]
+  description: Booster dose
+  alarms:
+    - trigger=-5m
+    - trigger=-1m
+    - trigger=2025-12-16 14:00
+
+- summary: Evening Meds - Melatonin 3mg
+  start: "2025-12-16 21:00"
+  duration: 5m
+  start_tz: Europe/Madrid
+  rrule: FREQ=DAILY;COUNT=30
+  categories: [Health, Medication, Sleep]
+  description: Take 1 hour before bed
+  alarms:
+    - trigger=-5m
+    - trigger=-1m
+    - trigger=2025-12-16 21:00
+`
+}
+
+func getWorkMeetingsTemplate() string {
+	return `summary,start,duration,start_tz,location,rrule,exdate,categories,description,alarms
+Team Standup,2025-12-16 09:30,30m,Europe/Madrid,Video call - Zoom,FREQ=WEEKLY;BYDAY=MO;TU;WE;TH;FR;COUNT=20,,Work|Meeting,Daily sync with team,-5m
+Weekly 1:1 with Manager,2025-12-16 14:00,45m,Europe/Madrid,Office - Meeting Room 3,FREQ=WEEKLY;BYDAY=MO;COUNT=12,2025-12-23 14:00|2025-12-30 14:00,Work|1on1,Discuss progress and blockers,trigger=-1d;description=Prepare agenda and questions||trigger=-15m
+Sprint Planning,2025-12-17 10:00,2h,Europe/Madrid,Conference Room A,FREQ=WEEKLY;BYDAY=TU;COUNT=6,,Work|Meeting|Planning,Plan next 2-week sprint,trigger=-1h;description=Review backlog||trigger=-15m
+Client Demo,2025-12-19 16:00,90m,Europe/Madrid,Video call - Google Meet,FREQ=WEEKLY;BYDAY=TH;COUNT=8,,Work|Client|Demo,Demo progress to stakeholders,trigger=-1d;description=Prepare demo script||trigger=-2h;description=Test demo environment||trigger=-30m
+`
+}
+
+func getMedicalTemplate() string {
+	return `summary,start,duration,start_tz,location,categories,description,alarms
+Dentist - 6 Month Checkup,2025-12-20 10:00,30m,Europe/Madrid,Dental Clinic - Main Street,Health|Dental,Routine cleaning and checkup,trigger=-1d;description=Call to confirm appointment||trigger=-2h;description=Time to leave (30min drive)||trigger=-5m
+Therapy Session,2025-12-18 17:00,1h,Europe/Madrid,Downtown Office - 3rd Floor,Health|Mental Health,Weekly therapy appointment,trigger=-1d;description=Think about topics to discuss||trigger=-30m;description=Prepare - bring notebook||trigger=-5m
+General Practitioner Checkup,2026-01-10 09:00,45m,Europe/Madrid,Health Center - Room 12,Health|Checkup,Annual physical examination,trigger=-1w;description=Schedule blood work if needed||trigger=-1d;description=Confirm appointment||trigger=-2h;description=Leave now (traffic)||trigger=-15m
+Lab Tests (Fasting Required),2025-12-22 08:00,15m,Europe/Madrid,Hospital Lab - Building C,Health|Tests,Blood work - MUST FAST,trigger=-1d;description=No food after 10pm tonight||trigger=-12h;description=Fasting period begins - no food||trigger=-1h;description=Drink water only||trigger=-15m
+`
+}
+
+func getTravelTemplate() string {
+	return `[
+  {
+    "summary": "Flight MAD → DUB",
+    "start": "2025-12-25 08:30",
+    "end": "2025-12-25 10:00",
+    "start_tz": "Europe/Madrid",
+    "end_tz": "Europe/Dublin",
+    "location": "Madrid Barajas Airport - Terminal 1",
+    "description": "Ryanair FR1234 - Gate closes 08:00. Confirmation: ABC123",
+    "categories": ["Travel", "Flight"],
+    "alarms": [
+      "trigger=-1d,description=Check-in online opens",
+      "trigger=-3h,description=Wake up and get ready",
+      "trigger=2025-12-25 06:30,description=Leave for airport now (traffic)",
+      "trigger=2025-12-25 07:45,description=Security checkpoint - gate closes at 08:00"
+    ]
+  },
+  {
+    "summary": "Hotel Check-in",
+    "start": "2025-12-25 12:00",
+    "duration": "30m",
+    "start_tz": "Europe/Dublin",
+    "location": "Dublin City Hotel - 123 O'Connell Street",
+    "description": "Confirmation: XYZ789. Room 305. Check-in after 14:00.",
+    "categories": ["Travel", "Accommodation"],
+    "alarms": [
+      "trigger=-1h,description=Head to hotel from airport"
+    ]
+  },
+  {
+    "summary": "Return Flight DUB → MAD",
+    "start": "2025-12-28 18:30",
+    "end": "2025-12-28 22:00",
+    "start_tz": "Europe/Dublin",
+    "end_tz": "Europe/Madrid",
+    "location": "Dublin Airport - Terminal 2",
+    "description": "Ryanair FR5678. Gate closes 18:00.",
+    "categories": ["Travel", "Flight"],
+    "alarms": [
+      "trigger=-1d,description=Check-in online",
+      "trigger=-4h,description=Leave hotel for airport (bus takes 45min)",
+      "trigger=2025-12-28 17:45,description=Final boarding call"
+    ]
+  }
+]
+`
+}
+
+func getFamilyTemplate() string {
+	return `summary,start,duration,start_tz,location,rrule,categories,description,alarms
+School Drop-off,2025-12-16 08:15,15m,Europe/Madrid,Elementary School,FREQ=WEEKLY;BYDAY=MO;TU;WE;TH;FR;COUNT=20,Family|Kids,Drop kids at school,trigger=-30m;description=Kids breakfast and get ready||trigger=-10m;description=Leave now
+Soccer Practice,2025-12-17 17:00,1h,Europe/Madrid,Sports Complex Field 3,FREQ=WEEKLY;BYDAY=TU;TH;COUNT=12,Family|Kids|Sports,Lucas soccer practice,trigger=-1h;description=Prepare soccer bag and snacks||trigger=-15m
+Piano Lesson,2025-12-18 16:30,45m,Europe/Madrid,Music Academy,FREQ=WEEKLY;BYDAY=WE;COUNT=10,Family|Kids|Music,Emma piano lesson,trigger=-2h;description=Practice today||trigger=-30m
+Pediatrician Checkup,2025-12-20 10:00,30m,Europe/Madrid,Pediatric Clinic,Family|Kids|Health,Annual checkup for both kids,trigger=-1d;description=Confirm appointment||trigger=-2h||trigger=-30m
+Date Night,2025-12-21 20:00,2h,Europe/Madrid,Restaurant Downtown,Family|Personal,Dinner reservation - babysitter confirmed,trigger=-1d;description=Confirm babysitter||trigger=-4h;description=Start getting ready||trigger=-1h
+`
+}
+
+func lintICSFile(path string) error {
+	findings, err := lintICS(path)
+	if err != nil {
+		return err
+	}
+	if diag.HasErrors(findings) {
+		msgs := make([]string, 0, len(findings))
+		for _, f := range findings {
+			if f.Severity == diag.SeverityError {
+				msgs = append(msgs, f.Message)
+			}
+		}
+		return fmt.Errorf("%s", strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// lintICS checks the .ics file at path and returns its findings, including
+// the sanity warnings. The error is only set when the file cannot be read at
+// all.
+func lintICS(path string) ([]diag.Warning, error) {
+	data, err := readICSFile(path)
+	if err != nil {
+		return nil, err
+	}
+	findings, err := lintICSData(path, data)
+	if err != nil || diag.HasErrors(findings) {
+		return findings, err
+	}
+	return append(findings, lintSanity(path, data)...), nil
+}
+
+// lintGrammar reports where the file at path breaks the RFC 5545 grammar,
+// for lint --strict.
+func lintGrammar(path string) []diag.Warning {
+	data, err := readICSFile(path)
+	if err != nil {
+		return nil
+	}
+	var findings []diag.Warning
+	for _, issue := range calendar.CheckGrammar(data) {
+		msg := issue.Message
+		if issue.Property != "" {
+			msg = issue.Property + ": " + msg
+		}
+		findings = append(findings, diag.Warning{
+			Code: diag.CodeGrammar, Severity: diag.SeverityError, File: path, Line: issue.Line, Message: msg,
+		})
+	}
+	return findings
+}
+
+// lintSanity runs sanityWarnings over the events of a structurally valid
+// file, pointing each warning at its BEGIN:VEVENT line. Downloaded feeds skip
+// it: their history is expected to be in the past.
+func lintSanity(name, data string) []diag.Warning {
+	events, err := calendar.ParseICSEvents(data)
+	if err != nil {
+		return nil
+	}
+	lines, numbers := unfoldICSLinesNumbered(data)
+	var begins []int
+	for i, line := range lines {
+		if strings.EqualFold(strings.TrimSpace(line), "BEGIN:VEVENT") {
+			begins = append(begins, numbers[i])
+		}
+	}
+	var warnings []diag.Warning
+	limits := sanityLimits()
+	for i, ev := range events {
+		for _, w := range sanityWarnings(ev, limits) {
+			w.File = name
+			if i < len(begins) {
+				w.Line = begins[i]
+			}
+			warnings = append(warnings, w)
+		}
+	}
+	return warnings
+}
+
+// lintICSData checks an .ics document held in memory (e.g. a downloaded feed);
+// name is used as the file in findings.
+func lintICSData(name, data string) ([]diag.Warning, error) {
+	lines, lineNumbers := unfoldICSLinesNumbered(data)
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+
+	state := newLintState(name)
+	for i, line := range lines {
+		state.line = lineNumbers[i]
+		processLintLine(&state, line)
+	}
+
+	return lintResults(state), nil
+}
+
+type lintState struct {
+	file         string
+	line         int
+	calendarSeen bool
+	eventSeen    bool
+	inEvent      bool
+	eventIndex   int
+	eventLine    int
+	eventFields  map[string]string
+	findings     []diag.Warning
+}
+
+func newLintState(file string) lintState {
+	return lintState{
+		file:        file,
+		eventFields: make(map[string]string, 8),
+	}
+}
+
+func (s *lintState) report(code string, line int, message, suggestion string) {
+	s.findings = append(s.findings, diag.Warning{
+		Code: code, Severity: diag.SeverityError, File: s.file, Line: line,
+		Message: message, Suggestion: suggestion,
+	})
+}
+
+func readICSFile(path string) (string, error) {
+	cleanPath := filepath.Clean(path)
+	info, err := os.Stat(cleanPath)
+	if err != nil {
+		return "", fmt.Errorf("cannot access file: %w", err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%s is a directory, expected file", path)
+	}
+
+	data, err := os.ReadFile(cleanPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	return string(data), nil
+}
+
+func processLintLine(state *lintState, raw string) {
+	line := strings.TrimSpace(raw)
+	if line == "" {
+		return
+	}
+
+	switch {
+	case strings.EqualFold(line, "BEGIN:VCALENDAR"):
+		state.calendarSeen = true
+	case strings.EqualFold(line, "END:VCALENDAR"):
+		// nothing
+	case strings.EqualFold(line, "BEGIN:VEVENT"):
+		handleBeginEvent(state)
+	case strings.EqualFold(line, "END:VEVENT"):
+		handleEndEvent(state)
+	default:
+		handleEventProperty(state, line)
+	}
+}
+
+func handleBeginEvent(state *lintState) {
+	state.inEvent = true
+	state.eventLine = state.line
+	state.eventSeen = true
+	state.eventIndex++
+	state.eventFields = make(map[string]string, 8)
+}
+
+func handleEndEvent(state *lintState) {
+	if !state.inEvent {
+		state.report(diag.CodeICSStructure, state.line, ui.T("lint_unexpected_end"), "")
+		return
+	}
+	state.inEvent = false
+
+	label := buildEventLabel(state.eventIndex, state.eventFields)
+	validateEventFields(state, label)
+}
+
+func buildEventLabel(index int, fields map[string]string) string {
+	label := fmt.Sprintf("VEVENT #%d", index)
+	if summary := strings.TrimSpace(fields["SUMMARY"]); summary != "" {
+		label = fmt.Sprintf("%s (%s)", label, summary)
+	}
+	return label
+}
+
+func validateEventFields(state *lintState, label string) {
+	requiredFields := []string{"UID", "SUMMARY", "DTSTART"}
+	for _, key := range requiredFields {
+		if strings.TrimSpace(state.eventFields[key]) == "" {
+			state.report(diag.CodeMissingProperty, state.eventLine, ui.T("lint_missing_property", label, key), "")
+		}
+	}
+
+	_, hasEnd := state.eventFields["DTEND"]
+	_, hasDuration := state.eventFields["DURATION"]
+	if !hasEnd && !hasDuration {
+		state.report(diag.CodeMissingProperty, state.eventLine, ui.T("lint_missing_end", label), ui.T("lint_missing_end_hint"))
+	}
 }
 
-func getADHDRoutineTemplate() string {
-	return `summary,start,duration,start_tz,location,rrule,categories,description,alarms
-Morning Medication,2025-12-16 08:00,5m,Europe/Madrid,,FREQ=DAILY;COUNT=30,Health|Medication,Take morning meds with food,trigger=-5m||trigger=-1m||trigger=2025-12-16 08:00
-Deep Focus Block,2025-12-16 09:00,2h,Europe/Madrid,,FREQ=WEEKLY;BYDAY=MO;TU;WE;TH;FR;COUNT=20,Work|Focus,NO meetings - deep work only,trigger=-10m;description=Prepare workspace and eliminate distractions||trigger=-1m||trigger=2025-12-16 10:30;description=Halfway - stay focused
-Transition Buffer,2025-12-16 11:00,15m,Europe/Madrid,,FREQ=WEEKLY;BYDAY=MO;TU;WE;TH;FR;COUNT=20,Break|Transition,Stretch and reset before next task,trigger=-1m
-Lunch + Walk,2025-12-16 13:00,1h,Europe/Madrid,,FREQ=DAILY;COUNT=30,Break|Health,Eat away from desk - go outside,trigger=-5m||trigger=2025-12-16 13:30;description=Time to walk
-Evening Medication,2025-12-16 20:00,5m,Europe/Madrid,,FREQ=DAILY;COUNT=30,Health|Medication,Take evening meds,trigger=-5m||trigger=-1m||trigger=2025-12-16 20:00
-Wind Down Routine,2025-12-16 22:00,30m,Europe/Madrid,,FREQ=DAILY;COUNT=30,Health|Sleep,No screens - prepare for sleep,trigger=-15m||trigger=-5m
-`
+func handleEventProperty(state *lintState, line string) {
+	if !state.inEvent {
+		return
+	}
+
+	name, value, ok := parseICSProperty(line)
+	if ok {
+		state.eventFields[name] = value
+	}
 }
 
-func getMedicationTemplate() string {
-	return `# Medication Schedule Template
-# Triple alarms: 5min before, 1min before, exact time
+// lintResults returns the findings, checking the calendar structure first: a
+// file that is not a calendar at all reports only that.
+func lintResults(state lintState) []diag.Warning {
+	if !state.calendarSeen {
+		state.report(diag.CodeICSStructure, 1, ui.T("lint_missing_calendar"), ui.T("lint_missing_calendar_hint"))
+		return state.findings[len(state.findings)-1:]
+	}
+	if !state.eventSeen {
+		state.report(diag.CodeICSStructure, 0, ui.T("lint_no_events"), "")
+		return state.findings[len(state.findings)-1:]
+	}
+	return state.findings
+}
 
-- summary: Morning Meds - Methylphenidate 20mg
-  start: "2025-12-16 08:00"
-  duration: 5m
-  start_tz: Europe/Madrid
-  rrule: FREQ=DAILY;COUNT=30
-  categories: [Health, Medication]
-  description: Take with food. Don't skip.
-  alarms:
-    - trigger=-5m
-    - trigger=-1m
-    - trigger=2025-12-16 08:00
+func unfoldICSLines(data string) []string {
+	lines, _ := unfoldICSLinesNumbered(data)
+	return lines
+}
 
-- summary: Afternoon Meds - Methylphenidate 10mg
-  start: "2025-12-16 14:00"
-  duration: 5m
-  start_tz: Europe/Madrid
-  rrule: FREQ=DAILY;COUNT=30
-  categories: [Health, Medication]
-  description: Booster dose
-  alarms:
-    - trigger=-5m
-    - trigger=-1m
-    - trigger=2025-12-16 14:00
+// unfoldICSLinesNumbered unfolds continuation lines and also returns the
+// 1-based file line each logical line starts on.
+func unfoldICSLinesNumbered(data string) ([]string, []int) {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	rawLines := strings.Split(data, "\n")
+	lines := make([]string, 0, len(rawLines))
+	numbers := make([]int, 0, len(rawLines))
 
-- summary: Evening Meds - Melatonin 3mg
-  start: "2025-12-16 21:00"
-  duration: 5m
-  start_tz: Europe/Madrid
-  rrule: FREQ=DAILY;COUNT=30
-  categories: [Health, Medication, Sleep]
-  description: Take 1 hour before bed
-  alarms:
-    - trigger=-5m
-    - trigger=-1m
-    - trigger=2025-12-16 21:00
-`
+	var current strings.Builder
+	start := 0
+	write := func(line int, text string) {
+		if current.Len() == 0 {
+			start = line
+		}
+		current.WriteString(text)
+	}
+	for i, raw := range rawLines {
+		if raw == "" && current.Len() == 0 {
+			continue
+		}
+		if strings.HasPrefix(raw, " ") || strings.HasPrefix(raw, "\t") {
+			write(i+1, strings.TrimLeft(raw, " \t"))
+			continue
+		}
+		if current.Len() > 0 {
+			lines = append(lines, current.String())
+			numbers = append(numbers, start)
+			current.Reset()
+		}
+		write(i+1, strings.TrimRight(raw, "\r"))
+	}
+	if current.Len() > 0 {
+		lines = append(lines, current.String())
+		numbers = append(numbers, start)
+	}
+	return lines, numbers
 }
 
-func getWorkMeetingsTemplate() string {
-	return `summary,start,duration,start_tz,location,rrule,exdate,categories,description,alarms
-Team Standup,2025-12-16 09:30,30m,Europe/Madrid,Video call - Zoom,FREQ=WEEKLY;BYDAY=MO;TU;WE;TH;FR;COUNT=20,,Work|Meeting,Daily sync with team,-5m
-Weekly 1:1 with Manager,2025-12-16 14:00,45m,Europe/Madrid,Office - Meeting Room 3,FREQ=WEEKLY;BYDAY=MO;COUNT=12,2025-12-23 14:00|2025-12-30 14:00,Work|1on1,Discuss progress and blockers,trigger=-1d;description=Prepare agenda and questions||trigger=-15m
-Sprint Planning,2025-12-17 10:00,2h,Europe/Madrid,Conference Room A,FREQ=WEEKLY;BYDAY=TU;COUNT=6,,Work|Meeting|Planning,Plan next 2-week sprint,trigger=-1h;description=Review backlog||trigger=-15m
-Client Demo,2025-12-19 16:00,90m,Europe/Madrid,Video call - Google Meet,FREQ=WEEKLY;BYDAY=TH;COUNT=8,,Work|Client|Demo,Demo progress to stakeholders,trigger=-1d;description=Prepare demo script||trigger=-2h;description=Test demo environment||trigger=-30m
-`
+func parseICSProperty(line string) (name, value string, ok bool) {
+	parts := strings.SplitN(line, ":", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	key, _, _ := strings.Cut(parts[0], ";")
+	key = strings.ToUpper(strings.TrimSpace(key))
+	if key == "" {
+		return "", "", false
+	}
+	val := strings.TrimSpace(parts[1])
+	return key, val, true
 }
 
-func getMedicalTemplate() string {
-	return `summary,start,duration,start_tz,location,categories,description,alarms
-Dentist - 6 Month Checkup,2025-12-20 10:00,30m,Europe/Madrid,Dental Clinic - Main Street,Health|Dental,Routine cleaning and checkup,trigger=-1d;description=Call to confirm appointment||trigger=-2h;description=Time to leave (30min drive)||trigger=-5m
-Therapy Session,2025-12-18 17:00,1h,Europe/Madrid,Downtown Office - 3rd Floor,Health|Mental Health,Weekly therapy appointment,trigger=-1d;description=Think about topics to discuss||trigger=-30m;description=Prepare - bring notebook||trigger=-5m
-General Practitioner Checkup,2026-01-10 09:00,45m,Europe/Madrid,Health Center - Room 12,Health|Checkup,Annual physical examination,trigger=-1w;description=Schedule blood work if needed||trigger=-1d;description=Confirm appointment||trigger=-2h;description=Leave now (traffic)||trigger=-15m
-Lab Tests (Fasting Required),2025-12-22 08:00,15m,Europe/Madrid,Hospital Lab - Building C,Health|Tests,Blood work - MUST FAST,trigger=-1d;description=No food after 10pm tonight||trigger=-12h;description=Fasting period begins - no food||trigger=-1h;description=Drink water only||trigger=-15m
-`
+func ensureDirForFile(path string) error {
+	dir := strings.TrimSpace(filepath.Dir(path))
+	if dir == "" || dir == "." {
+		return nil
+	}
+	return os.MkdirAll(dir, 0o750)
 }
 
-func getTravelTemplate() string {
-	return `[
-  {
-    "summary": "Flight MAD → DUB",
-    "start": "2025-12-25 08:30",
-    "end": "2025-12-25 10:00",
-    "start_tz": "Europe/Madrid",
-    "end_tz": "Europe/Dublin",
-    "location": "Madrid Barajas Airport - Terminal 1",
-    "description": "Ryanair FR1234 - Gate closes 08:00. Confirmation: ABC123",
-    "categories": ["Travel", "Flight"],
-    "alarms": [
-      "trigger=-1d,description=Check-in online opens",
-      "trigger=-3h,description=Wake up and get ready",
-      "trigger=2025-12-25 06:30,description=Leave for airport now (traffic)",
-      "trigger=2025-12-25 07:45,description=Security checkpoint - gate closes at 08:00"
-    ]
-  },
-  {
-    "summary": "Hotel Check-in",
-    "start": "2025-12-25 12:00",
-    "duration": "30m",
-    "start_tz": "Europe/Dublin",
-    "location": "Dublin City Hotel - 123 O'Connell Street",
-    "description": "Confirmation: XYZ789. Room 305. Check-in after 14:00.",
-    "categories": ["Travel", "Accommodation"],
-    "alarms": [
-      "trigger=-1h,description=Head to hotel from airport"
-    ]
-  },
-  {
-    "summary": "Return Flight DUB → MAD",
-    "start": "2025-12-28 18:30",
-    "end": "2025-12-28 22:00",
-    "start_tz": "Europe/Dublin",
-    "end_tz": "Europe/Madrid",
-    "location": "Dublin Airport - Terminal 2",
-    "description": "Ryanair FR5678. Gate closes 18:00.",
-    "categories": ["Travel", "Flight"],
-    "alarms": [
-      "trigger=-1d,description=Check-in online",
-      "trigger=-4h,description=Leave hotel for airport (bus takes 45min)",
-      "trigger=2025-12-28 17:45,description=Final boarding call"
-    ]
-  }
-]
-`
+func extractDate(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 10 {
+		return s[:10]
+	}
+	return s
+}
+
+func parseBoolish(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "1", "true", "yes", "y", "on":
+		return true
+	default:
+		return false
+	}
+}
+
+func splitDelimited(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ';' || r == '|' || r == '\n'
+	})
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func valueAsString(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(x)
+	case time.Time:
+		// YAML reads unquoted dates (2025-09-08) and timestamps as times.
+		if x.Equal(x.Truncate(24*time.Hour)) && x.Location() == time.UTC {
+			return x.Format(constants.DateFormatISO)
+		}
+		return x.Format(time.RFC3339)
+	case fmt.Stringer:
+		return strings.TrimSpace(x.String())
+	case float64:
+		return strings.TrimSpace(fmt.Sprintf("%g", x))
+	case bool:
+		if x {
+			return "true"
+		}
+		return "false"
+	default:
+		return strings.TrimSpace(fmt.Sprintf("%v", x))
+	}
+}
+
+func valueAsBool(v interface{}) bool {
+	switch x := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return x
+	case float64:
+		return x != 0
+	case string:
+		return parseBoolish(x)
+	default:
+		return parseBoolish(fmt.Sprintf("%v", x))
+	}
+}
+
+func valueAsStringSlice(v interface{}) []string {
+	if v == nil {
+		return nil
+	}
+	switch x := v.(type) {
+	case []interface{}:
+		out := make([]string, 0, len(x))
+		for _, item := range x {
+			val := strings.TrimSpace(valueAsString(item))
+			if val != "" {
+				out = append(out, val)
+			}
+		}
+		return out
+	case []string:
+		out := make([]string, 0, len(x))
+		for _, item := range x {
+			val := strings.TrimSpace(item)
+			if val != "" {
+				out = append(out, val)
+			}
+		}
+		return out
+	case string:
+		return splitDelimited(x)
+	default:
+		val := strings.TrimSpace(fmt.Sprintf("%v", x))
+		if val == "" {
+			return nil
+		}
+		return splitDelimited(val)
+	}
 }
 
-func getFamilyTemplate() string {
-	return `summary,start,duration,start_tz,location,rrule,categories,description,alarms
-School Drop-off,2025-12-16 08:15,15m,Europe/Madrid,Elementary School,FREQ=WEEKLY;BYDAY=MO;TU;WE;TH;FR;COUNT=20,Family|Kids,Drop kids at school,trigger=-30m;description=Kids breakfast and get ready||trigger=-10m;description=Leave now
-Soccer Practice,2025-12-17 17:00,1h,Europe/Madrid,Sports Complex Field 3,FREQ=WEEKLY;BYDAY=TU;TH;COUNT=12,Family|Kids|Sports,Lucas soccer practice,trigger=-1h;description=Prepare soccer bag and snacks||trigger=-15m
-Piano Lesson,2025-12-18 16:30,45m,Europe/Madrid,Music Academy,FREQ=WEEKLY;BYDAY=WE;COUNT=10,Family|Kids|Music,Emma piano lesson,trigger=-2h;description=Practice today||trigger=-30m
-Pediatrician Checkup,2025-12-20 10:00,30m,Europe/Madrid,Pediatric Clinic,Family|Kids|Health,Annual checkup for both kids,trigger=-1d;description=Confirm appointment||trigger=-2h||trigger=-30m
-Date Night,2025-12-21 20:00,2h,Europe/Madrid,Restaurant Downtown,Family|Personal,Dinner reservation - babysitter confirmed,trigger=-1d;description=Confirm babysitter||trigger=-4h;description=Start getting ready||trigger=-1h
-`
+// valueAsOverrides reads a record's overrides: a list of maps with
+// occurrence, start and optionally end, summary, location and description,
+// or of "OCCURRENCE => NEW START" strings.
+func valueAsOverrides(v interface{}) []overrideSpec {
+	items, _ := v.([]interface{})
+	out := make([]overrideSpec, 0, len(items))
+	for _, item := range items {
+		switch x := item.(type) {
+		case map[string]interface{}:
+			out = append(out, overrideSpec{
+				Occurrence:  valueAsString(x["occurrence"]),
+				Start:       valueAsString(x["start"]),
+				End:         valueAsString(x["end"]),
+				Summary:     valueAsString(x["summary"]),
+				Location:    valueAsString(x["location"]),
+				Description: valueAsString(x["description"]),
+			})
+		case string:
+			occurrence, start, _ := strings.Cut(x, "=>")
+			out = append(out, overrideSpec{Occurrence: strings.TrimSpace(occurrence), Start: strings.TrimSpace(start)})
+		}
+	}
+	return out
 }
 
-func lintICSFile(path string) error {
-	lines, err := loadAndValidateICSFile(path)
-	if err != nil {
-		return err
+func valueAsAlarmSlice(v interface{}) []string {
+	if v == nil {
+		return nil
+	}
+	switch x := v.(type) {
+	case []interface{}:
+		out := make([]string, 0, len(x))
+		for _, item := range x {
+			val := strings.TrimSpace(valueAsString(item))
+			if val == "" {
+				continue
+			}
+			for _, part := range calendar.SplitAlarmInput(val) {
+				if strings.TrimSpace(part) != "" {
+					out = append(out, part)
+				}
+			}
+		}
+		return out
+	case []string:
+		out := make([]string, 0, len(x))
+		for _, item := range x {
+			for _, part := range calendar.SplitAlarmInput(item) {
+				if strings.TrimSpace(part) != "" {
+					out = append(out, part)
+				}
+			}
+		}
+		return out
+	case string:
+		return calendar.SplitAlarmInput(x)
+	default:
+		val := strings.TrimSpace(fmt.Sprintf("%v", x))
+		if val == "" {
+			return nil
+		}
+		return calendar.SplitAlarmInput(val)
 	}
+}
 
-	state := newLintState()
-	for _, line := range lines {
-		processLintLine(&state, line)
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage tempus configuration",
 	}
 
-	return validateLintResults(state)
-}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "set <key> <value>",
+			Short: "Set a configuration value",
+			Args:  cobra.ExactArgs(2),
+			RunE:  runConfigSet,
+		},
+		&cobra.Command{
+			Use:   "list",
+			Short: "List all configuration values",
+			RunE:  runConfigList,
+		},
+		&cobra.Command{
+			Use:   "alarm-profiles",
+			Short: "List available alarm profiles",
+			RunE:  runConfigAlarmProfiles,
+		},
+		&cobra.Command{
+			Use:   "features",
+			Short: "List experimental feature flags and whether they are enabled",
+			RunE:  runConfigFeatures,
+		},
+		&cobra.Command{
+			Use:   "doctor [file]",
+			Short: "Report deprecated keys, unknown keys and type mismatches in the config file",
+			Args:  cobra.MaximumNArgs(1),
+			RunE:  runConfigDoctor,
+		},
+		newConfigCorrectionsCmd(),
+		newConfigCategoriesCmd(),
+	)
 
-type lintState struct {
-	calendarSeen bool
-	eventSeen    bool
-	inEvent      bool
-	eventIndex   int
-	eventFields  map[string]string
-	eventIssues  []string
+	return cmd
 }
 
-func newLintState() lintState {
-	return lintState{
-		eventFields: make(map[string]string, 8),
+func newConfigCorrectionsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "corrections",
+		Short: "Manage the spell_corrections dictionary used for batch summaries",
 	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:     "add <word> <correction>",
+			Short:   "Correct word (any capitalization) to correction",
+			Example: "  tempus config corrections add physio physiotherapy\n  tempus config corrections add standup stand-up",
+			Args:    cobra.ExactArgs(2),
+			RunE:    runConfigCorrectionsAdd,
+		},
+		&cobra.Command{
+			Use:   "remove <word>",
+			Short: "Stop correcting word (built-in corrections included)",
+			Args:  cobra.ExactArgs(1),
+			RunE:  runConfigCorrectionsRemove,
+		},
+		&cobra.Command{
+			Use:   "list",
+			Short: "List active corrections, marking your own and the removed built-ins",
+			RunE:  runConfigCorrectionsList,
+		},
+	)
+	return cmd
 }
 
-func loadAndValidateICSFile(path string) ([]string, error) {
-	cleanPath := filepath.Clean(path)
-	info, err := os.Stat(cleanPath)
+func runConfigCorrectionsAdd(_ *cobra.Command, args []string) error {
+	cfg, err := config.Load()
 	if err != nil {
-		return nil, fmt.Errorf("cannot access file: %w", err)
+		return err
 	}
-	if info.IsDir() {
-		return nil, fmt.Errorf("%s is a directory, expected file", path)
+	if err := cfg.AddSpellCorrection(args[0], args[1]); err != nil {
+		return err
 	}
+	printOK("Correction added: %s → %s\n", strings.ToLower(strings.TrimSpace(args[0])), strings.TrimSpace(args[1]))
+	return nil
+}
 
-	data, err := os.ReadFile(cleanPath)
+func runConfigCorrectionsRemove(_ *cobra.Command, args []string) error {
+	cfg, err := config.Load()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return err
 	}
-
-	lines := unfoldICSLines(string(data))
-	if len(lines) == 0 {
-		return nil, fmt.Errorf("file is empty")
+	if err := cfg.RemoveSpellCorrection(args[0]); err != nil {
+		return err
 	}
-
-	return lines, nil
+	printOK("Correction removed: %s\n", strings.ToLower(strings.TrimSpace(args[0])))
+	return nil
 }
 
-func processLintLine(state *lintState, raw string) {
-	line := strings.TrimSpace(raw)
-	if line == "" {
-		return
+func runConfigCorrectionsList(_ *cobra.Command, _ []string) error {
+	cfg, err := config.Current()
+	if err != nil {
+		return err
+	}
+	active := cfg.Corrections()
+	if len(active) == 0 {
+		fmt.Println("No spelling corrections configured.")
+		return nil
+	}
+	words := slices.Sorted(maps.Keys(active))
+	width := 0
+	for _, word := range words {
+		width = max(width, len(word))
+	}
+	for _, word := range words {
+		line := fmt.Sprintf("  %-*s → %s", width, word, active[word])
+		if builtin, ok := config.BuiltinCorrection(word); !ok || builtin != active[word] {
+			line += "  (custom)"
+		}
+		fmt.Println(line)
 	}
 
-	switch {
-	case strings.EqualFold(line, "BEGIN:VCALENDAR"):
-		state.calendarSeen = true
-	case strings.EqualFold(line, "END:VCALENDAR"):
-		// nothing
-	case strings.EqualFold(line, "BEGIN:VEVENT"):
-		handleBeginEvent(state)
-	case strings.EqualFold(line, "END:VEVENT"):
-		handleEndEvent(state)
-	default:
-		handleEventProperty(state, line)
+	var removed []string
+	for word, correction := range cfg.SpellCorrections {
+		if _, ok := config.BuiltinCorrection(word); ok && strings.TrimSpace(correction) == "" {
+			removed = append(removed, word)
+		}
 	}
+	if len(removed) > 0 {
+		slices.Sort(removed)
+		fmt.Printf("\nRemoved built-ins: %s\n", strings.Join(removed, ", "))
+	}
+	return nil
 }
 
-func handleBeginEvent(state *lintState) {
-	state.inEvent = true
-	state.eventSeen = true
-	state.eventIndex++
-	state.eventFields = make(map[string]string, 8)
+func newConfigCategoriesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "categories",
+		Short: "Manage category_colors, the colors written into events by category",
+	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:     "set-color <category> <color>",
+			Short:   "Color events in category (and its subcategories) with #RRGGBB or a CSS color name",
+			Example: "  tempus config categories set-color Health '#2ecc71'\n  tempus config categories set-color Work steelblue",
+			Args:    cobra.ExactArgs(2),
+			RunE:    runConfigCategoriesSetColor,
+		},
+		&cobra.Command{
+			Use:   "remove-color <category>",
+			Short: "Stop coloring events in category",
+			Args:  cobra.ExactArgs(1),
+			RunE:  runConfigCategoriesRemoveColor,
+		},
+		&cobra.Command{
+			Use:   "list",
+			Short: "List category colors and the COLOR name each is written as",
+			RunE:  runConfigCategoriesList,
+		},
+	)
+	return cmd
 }
 
-func handleEndEvent(state *lintState) {
-	if !state.inEvent {
-		state.eventIssues = append(state.eventIssues, "unexpected END:VEVENT without matching BEGIN:VEVENT")
-		return
+func runConfigCategoriesSetColor(_ *cobra.Command, args []string) error {
+	color, err := calendar.ParseColor(args[1])
+	if err != nil {
+		return err
 	}
-	state.inEvent = false
-
-	label := buildEventLabel(state.eventIndex, state.eventFields)
-	validateEventFields(state, label)
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := cfg.SetCategoryColor(args[0], color); err != nil {
+		return err
+	}
+	printOK("Category color set: %s → %s (%s)\n", strings.TrimSpace(args[0]), color, calendar.ColorName(color))
+	return nil
 }
 
-func buildEventLabel(index int, fields map[string]string) string {
-	label := fmt.Sprintf("VEVENT #%d", index)
-	if summary := strings.TrimSpace(fields["SUMMARY"]); summary != "" {
-		label = fmt.Sprintf("%s (%s)", label, summary)
+func runConfigCategoriesRemoveColor(_ *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
 	}
-	return label
+	if err := cfg.RemoveCategoryColor(args[0]); err != nil {
+		return err
+	}
+	printOK("Category color removed: %s\n", strings.TrimSpace(args[0]))
+	return nil
 }
 
-func validateEventFields(state *lintState, label string) {
-	requiredFields := []string{"UID", "SUMMARY", "DTSTART"}
-	for _, key := range requiredFields {
-		if strings.TrimSpace(state.eventFields[key]) == "" {
-			state.eventIssues = append(state.eventIssues, fmt.Sprintf("%s missing %s", label, key))
+func runConfigCategoriesList(_ *cobra.Command, _ []string) error {
+	cfg, err := config.Current()
+	if err != nil {
+		return err
+	}
+	colors := map[string]string{}
+	for name, color := range cfg.CategoryColors {
+		if color = strings.TrimSpace(color); color != "" {
+			colors[name] = color
 		}
 	}
-
-	_, hasEnd := state.eventFields["DTEND"]
-	_, hasDuration := state.eventFields["DURATION"]
-	if !hasEnd && !hasDuration {
-		state.eventIssues = append(state.eventIssues, fmt.Sprintf("%s missing DTEND or DURATION", label))
+	if len(colors) == 0 {
+		fmt.Println("No category colors configured.")
+		return nil
+	}
+	names := slices.Sorted(maps.Keys(colors))
+	width := 0
+	for _, name := range names {
+		width = max(width, len(name))
+	}
+	for _, name := range names {
+		hex, err := calendar.ParseColor(colors[name])
+		if err != nil {
+			fmt.Printf("  %-*s   %s  (invalid)\n", width, name, colors[name])
+			continue
+		}
+		fmt.Printf("  %-*s → %s  COLOR:%s\n", width, name, hex, calendar.ColorName(hex))
 	}
+	return nil
 }
 
-func handleEventProperty(state *lintState, line string) {
-	if !state.inEvent {
-		return
+func runConfigSet(_ *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
 	}
-
-	name, value, ok := parseICSProperty(line)
-	if ok {
-		state.eventFields[name] = value
+	if err := cfg.Set(args[0], args[1]); err != nil {
+		return err
+	}
+	printOK("Config updated: %s = %s\n", args[0], args[1])
+	return nil
+}
+
+func runConfigList(_ *cobra.Command, _ []string) error {
+	cfg, err := config.Current()
+	if err != nil {
+		return err
 	}
+	return cfg.List()
 }
 
-func validateLintResults(state lintState) error {
-	if !state.calendarSeen {
-		return fmt.Errorf("missing BEGIN:VCALENDAR")
+func runConfigFeatures(_ *cobra.Command, _ []string) error {
+	cfg, err := config.Current()
+	if err != nil {
+		return err
 	}
-	if !state.eventSeen {
-		return fmt.Errorf("no VEVENT blocks found")
+
+	fmt.Printf("Release channel: %s\n\n", config.ReleaseChannel())
+	for _, st := range cfg.FeatureStates() {
+		state := "off"
+		if st.Enabled {
+			state = "on"
+		}
+		fmt.Printf("  %-22s %-3s  %s\n", st.Name, state, st.Source)
+		fmt.Printf("  %-22s      %s\n", "", st.Description)
 	}
-	if len(state.eventIssues) > 0 {
-		return fmt.Errorf("%s", strings.Join(state.eventIssues, "; "))
+	for _, name := range cfg.UnknownFeatures() {
+		output.Warn(os.Stdout, "experimental.%s is not a known feature (ignored)\n", name)
 	}
+	fmt.Println()
+	fmt.Println("Toggle a feature in config.yaml:")
+	fmt.Println("  experimental:")
+	fmt.Println("    <name>: true")
 	return nil
 }
 
-func unfoldICSLines(data string) []string {
-	data = strings.ReplaceAll(data, "\r\n", "\n")
-	rawLines := strings.Split(data, "\n")
-	lines := make([]string, 0, len(rawLines))
-
-	var current strings.Builder
-	for _, raw := range rawLines {
-		if raw == "" && current.Len() == 0 {
-			continue
-		}
-		if strings.HasPrefix(raw, " ") || strings.HasPrefix(raw, "\t") {
-			current.WriteString(strings.TrimLeft(raw, " \t"))
-			continue
-		}
-		if current.Len() > 0 {
-			lines = append(lines, current.String())
-			current.Reset()
+func runConfigDoctor(_ *cobra.Command, args []string) error {
+	path := ""
+	if len(args) > 0 {
+		path = args[0]
+	} else {
+		found, err := config.ConfigFilePath()
+		if err != nil {
+			return err
 		}
-		current.WriteString(strings.TrimRight(raw, "\r"))
+		path = found
 	}
-	if current.Len() > 0 {
-		lines = append(lines, current.String())
+	if path == "" {
+		fmt.Println("No config file found; tempus is using built-in defaults.")
+		return nil
 	}
-	return lines
-}
 
-func parseICSProperty(line string) (name, value string, ok bool) {
-	parts := strings.SplitN(line, ":", 2)
-	if len(parts) != 2 {
-		return "", "", false
+	findings, err := config.Doctor(path)
+	if err != nil {
+		return err
 	}
-	key := strings.TrimSpace(parts[0])
-	if key == "" {
-		return "", "", false
+	fmt.Printf("Config file: %s\n", path)
+	if len(findings) == 0 {
+		printOK("No problems found.\n")
+		return nil
 	}
-	if idx := strings.IndexRune(key, ';'); idx != -1 {
-		key = key[:idx]
+
+	var errorCount int
+	for _, f := range findings {
+		label := f.Key
+		if label == "" {
+			label = "(file)"
+		}
+		if f.Severity == config.SeverityError {
+			errorCount++
+			printErr("%s: %s\n", label, f.Message)
+		} else {
+			output.Warn(os.Stdout, "%s: %s\n", label, f.Message)
+		}
 	}
-	key = strings.ToUpper(strings.TrimSpace(key))
-	val := strings.TrimSpace(parts[1])
-	return key, val, true
+	if errorCount > 0 {
+		return fmt.Errorf("%d config problem(s) must be fixed", errorCount)
+	}
+	return nil
 }
 
-func ensureDirForFile(path string) error {
-	dir := strings.TrimSpace(filepath.Dir(path))
-	if dir == "" || dir == "." {
+func runConfigAlarmProfiles(_ *cobra.Command, _ []string) error {
+	cfg, err := config.Current()
+	if err != nil {
+		return err
+	}
+
+	if cfg.AlarmProfiles == nil || len(cfg.AlarmProfiles) == 0 {
+		fmt.Println("No alarm profiles configured.")
 		return nil
 	}
-	return os.MkdirAll(dir, 0o750)
-}
 
-func extractDate(s string) string {
-	s = strings.TrimSpace(s)
-	if len(s) >= 10 {
-		return s[:10]
+	fmt.Println("Available alarm profiles:")
+	fmt.Println()
+
+	// Sort profile names for consistent output
+	names := cfg.ListAlarmProfiles()
+	sort.Strings(names)
+
+	for _, name := range names {
+		profile := cfg.GetAlarmProfile(name)
+		if profile == nil {
+			continue
+		}
+
+		fmt.Printf("  %s:\n", name)
+		if len(profile) == 0 {
+			fmt.Println("    (no alarms)")
+		} else {
+			for _, trigger := range profile {
+				fmt.Printf("    - %s\n", trigger)
+			}
+		}
+		fmt.Println()
 	}
-	return s
+
+	fmt.Println("Usage in batch files:")
+	fmt.Printf("  CSV:  alarms column with 'profile:adhd-triple'\n")
+	fmt.Printf("  JSON: \"alarms\": [\"profile:medication\"]\n")
+	fmt.Printf("  YAML: alarms: [profile:single]\n")
+
+	return nil
 }
 
-func parseBoolish(s string) bool {
-	switch strings.ToLower(strings.TrimSpace(s)) {
-	case "1", "true", "yes", "y", "on":
-		return true
-	default:
-		return false
-	}
+var (
+	version = "dev"     // override with -X main.version=...
+	commit  = "unknown" // override with -X main.commit=...
+	date    = ""        // override with -X main.date=...
+)
+
+// ------------------------------
+// Doctor
+// ------------------------------
+
+// doctorCheck is one line of the tempus doctor checklist.
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // pass, warn, fail or skip
+	Detail string `json:"detail"`
 }
 
-func splitDelimited(s string) []string {
-	if strings.TrimSpace(s) == "" {
-		return nil
+// Doctor statuses; only fail makes the command exit non-zero.
+const (
+	doctorPass = "pass"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+	doctorSkip = "skip"
+)
+
+func newDoctorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the config, locales, templates, tzdata and output directory",
+		Long: `doctor runs the checks behind most support questions and prints a
+pass/fail checklist: the config file parses and has no unknown or mistyped
+keys, the output language has a usable translation, the template directories
+load, the timezone database works (and which release it is), the output
+directory is writable, and the optional integrations (git for template
+install, the tempus serve token) are set up. It exits non-zero when a check
+fails; warnings don't fail it.`,
+		Example: `  tempus doctor
+  tempus doctor --templates-dir ./templates
+  tempus doctor --output-format json`,
+		Args: cobra.NoArgs,
+		RunE: runDoctor,
+	}
+	cmd.Flags().String("templates-dir", "", "Check this template directory instead of the defaults")
+	return cmd
+}
+
+func runDoctor(cmd *cobra.Command, _ []string) error {
+	templatesDir, _ := cmd.Flags().GetString("templates-dir")
+	cfg, cfgErr := config.Current()
+
+	var checks []doctorCheck
+	checks = append(checks, doctorConfig(cfgErr)...)
+	checks = append(checks, doctorLocale(outputLanguage(cmd)))
+	checks = append(checks, doctorTemplates(tpl.ResolveTemplateDirs(templatesDir))...)
+	checks = append(checks, doctorTZData(cfg)...)
+	checks = append(checks, doctorOutputDir())
+	checks = append(checks, doctorIntegrations()...)
+
+	failed := 0
+	for _, c := range checks {
+		if c.Status == doctorFail {
+			failed++
+		}
 	}
-	parts := strings.FieldsFunc(s, func(r rune) bool {
-		return r == ',' || r == ';' || r == '|' || r == '\n'
-	})
-	out := make([]string, 0, len(parts))
-	for _, p := range parts {
-		p = strings.TrimSpace(p)
-		if p != "" {
-			out = append(out, p)
+	if jsonReport(cmd) {
+		if err := printJSON(cmd.OutOrStdout(), checks); err != nil {
+			return err
+		}
+	} else {
+		w := cmd.OutOrStdout()
+		for _, c := range checks {
+			switch c.Status {
+			case doctorPass:
+				output.OK(w, "%s: %s\n", c.Name, c.Detail)
+			case doctorWarn:
+				output.Warn(w, "%s: %s\n", c.Name, c.Detail)
+			case doctorFail:
+				output.Error(w, "%s: %s\n", c.Name, c.Detail)
+			default:
+				output.Info(w, "➖", "%s: %s\n", c.Name, c.Detail)
+			}
 		}
 	}
-	return out
+	if failed > 0 {
+		return fmt.Errorf("%d doctor check(s) failed", failed)
+	}
+	return nil
 }
 
-func valueAsString(v interface{}) string {
-	switch x := v.(type) {
-	case nil:
-		return ""
-	case string:
-		return strings.TrimSpace(x)
-	case fmt.Stringer:
-		return strings.TrimSpace(x.String())
-	case float64:
-		return strings.TrimSpace(fmt.Sprintf("%g", x))
-	case bool:
-		if x {
-			return "true"
+// doctorConfig checks that the config file loads and passes config doctor.
+func doctorConfig(loadErr error) []doctorCheck {
+	path, err := config.ConfigFilePath()
+	if err != nil {
+		return []doctorCheck{{"config", doctorFail, err.Error()}}
+	}
+	if path == "" && loadErr == nil {
+		return []doctorCheck{{"config", doctorPass, "no config file, using built-in defaults"}}
+	}
+	var findings []config.Finding
+	if path != "" {
+		if findings, err = config.Doctor(path); err != nil {
+			return []doctorCheck{{"config", doctorFail, fmt.Sprintf("%s: %v", path, err)}}
 		}
-		return "false"
-	default:
-		return strings.TrimSpace(fmt.Sprintf("%v", x))
 	}
-}
-
-func valueAsBool(v interface{}) bool {
-	switch x := v.(type) {
-	case nil:
-		return false
-	case bool:
-		return x
-	case float64:
-		return x != 0
-	case string:
-		return parseBoolish(x)
-	default:
-		return parseBoolish(fmt.Sprintf("%v", x))
+	if loadErr != nil && !slices.ContainsFunc(findings, func(f config.Finding) bool { return f.Severity == config.SeverityError }) {
+		// Doctor found nothing that explains it; report the load error itself.
+		return []doctorCheck{{"config", doctorFail, fmt.Sprintf("cannot load %s: %v", firstNonEmpty(path, "the config"), loadErr)}}
+	}
+	if len(findings) == 0 {
+		return []doctorCheck{{"config", doctorPass, path}}
+	}
+	var checks []doctorCheck
+	for _, f := range findings {
+		status := doctorWarn
+		if f.Severity == config.SeverityError {
+			status = doctorFail
+		}
+		label := f.Key
+		if label == "" {
+			label = "(file)"
+		}
+		checks = append(checks, doctorCheck{"config", status, fmt.Sprintf("%s: %s: %s", path, label, f.Message)})
 	}
+	return checks
 }
 
-func valueAsStringSlice(v interface{}) []string {
-	if v == nil {
-		return nil
+// doctorLocale checks that lang has a translation and that it lints clean.
+func doctorLocale(lang string) doctorCheck {
+	if !i18n.IsSupportedLanguage(lang) {
+		return doctorCheck{"locale", doctorFail, fmt.Sprintf("no translation for %q, output falls back to English (available: %s)", lang, strings.Join(i18n.SupportedLanguages(), ", "))}
 	}
-	switch x := v.(type) {
-	case []interface{}:
-		out := make([]string, 0, len(x))
-		for _, item := range x {
-			val := strings.TrimSpace(valueAsString(item))
-			if val != "" {
-				out = append(out, val)
-			}
-		}
-		return out
-	case []string:
-		out := make([]string, 0, len(x))
-		for _, item := range x {
-			val := strings.TrimSpace(item)
-			if val != "" {
-				out = append(out, val)
-			}
+	catalogs, err := i18n.Catalogs()
+	if err != nil {
+		return doctorCheck{"locale", doctorFail, err.Error()}
+	}
+	for _, c := range catalogs {
+		if !strings.EqualFold(c.Code, lang) {
+			continue
 		}
-		return out
-	case string:
-		return splitDelimited(x)
-	default:
-		val := strings.TrimSpace(fmt.Sprintf("%v", x))
-		if val == "" {
-			return nil
+		findings := localeFindings(c)
+		switch {
+		case diag.HasErrors(findings):
+			return doctorCheck{"locale", doctorFail, fmt.Sprintf("%s has %d error(s), run tempus locale lint %s", c.Source(), diag.Count(findings, diag.SeverityError), c.Code)}
+		case len(findings) > 0:
+			return doctorCheck{"locale", doctorWarn, fmt.Sprintf("%s has %d warning(s), run tempus locale lint %s", c.Source(), len(findings), c.Code)}
 		}
-		return splitDelimited(val)
+		return doctorCheck{"locale", doctorPass, fmt.Sprintf("%s: %d messages", c.Source(), len(c.Messages))}
 	}
+	return doctorCheck{"locale", doctorPass, lang}
 }
 
-func valueAsAlarmSlice(v interface{}) []string {
-	if v == nil {
-		return nil
+// doctorTemplates loads every template directory; missing ones are skipped.
+func doctorTemplates(dirs []string) []doctorCheck {
+	if len(dirs) == 0 {
+		return []doctorCheck{{"templates", doctorSkip, "no template directories configured"}}
 	}
-	switch x := v.(type) {
-	case []interface{}:
-		out := make([]string, 0, len(x))
-		for _, item := range x {
-			val := strings.TrimSpace(valueAsString(item))
-			if val == "" {
-				continue
-			}
-			for _, part := range calendar.SplitAlarmInput(val) {
-				if strings.TrimSpace(part) != "" {
-					out = append(out, part)
-				}
-			}
+	var checks []doctorCheck
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			checks = append(checks, doctorCheck{"templates", doctorSkip, dir + " not found"})
+			continue
+		case err != nil:
+			checks = append(checks, doctorCheck{"templates", doctorFail, err.Error()})
+			continue
+		case !info.IsDir():
+			checks = append(checks, doctorCheck{"templates", doctorFail, dir + " is not a directory"})
+			continue
 		}
-		return out
-	case []string:
-		out := make([]string, 0, len(x))
-		for _, item := range x {
-			for _, part := range calendar.SplitAlarmInput(item) {
-				if strings.TrimSpace(part) != "" {
-					out = append(out, part)
-				}
-			}
+		defs, err := tpl.LoadDDTemplates(dir)
+		if err != nil {
+			checks = append(checks, doctorCheck{"templates", doctorFail, fmt.Sprintf("%s: %v", dir, err)})
+			continue
 		}
-		return out
-	case string:
-		return calendar.SplitAlarmInput(x)
-	default:
-		val := strings.TrimSpace(fmt.Sprintf("%v", x))
-		if val == "" {
-			return nil
+		checks = append(checks, doctorCheck{"templates", doctorPass, fmt.Sprintf("%s (%d template(s))", dir, len(defs))})
+	}
+	return checks
+}
+
+// doctorTZData checks that the timezone database loads zones, names its
+// release, and that the configured timezone exists in it.
+func doctorTZData(cfg *config.Config) []doctorCheck {
+	info := tzpkg.CurrentTZData()
+	if _, err := time.LoadLocation("Europe/Madrid"); err != nil {
+		return []doctorCheck{{"tzdata", doctorFail, fmt.Sprintf("%s cannot load zones: %v", info, err)}}
+	}
+	checks := []doctorCheck{{"tzdata", doctorPass, info.String()}}
+	if info.Version == "" {
+		checks[0] = doctorCheck{"tzdata", doctorWarn, info.String() + "; run tempus timezone refresh to use a known release"}
+	}
+	if cfg != nil && strings.TrimSpace(cfg.Timezone) != "" {
+		if _, err := time.LoadLocation(cfg.Timezone); err != nil {
+			checks = append(checks, doctorCheck{"timezone", doctorFail, fmt.Sprintf("config timezone %q: %v", cfg.Timezone, err)})
+		} else {
+			checks = append(checks, doctorCheck{"timezone", doctorPass, cfg.Timezone})
 		}
-		return calendar.SplitAlarmInput(val)
 	}
+	return checks
 }
 
-func newConfigCmd() *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "config",
-		Short: "Manage tempus configuration",
+// doctorOutputDir writes and removes a probe file in output.dir.
+func doctorOutputDir() doctorCheck {
+	policy, err := loadOutputPolicy()
+	if err != nil {
+		return doctorCheck{"output", doctorFail, err.Error()}
+	}
+	dir := firstNonEmpty(policy.Dir, ".")
+	info, err := outputFS.Stat(dir)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return doctorCheck{"output", doctorWarn, dir + " does not exist yet; it is created on the first write"}
+	case err != nil:
+		return doctorCheck{"output", doctorFail, err.Error()}
+	case !info.IsDir():
+		return doctorCheck{"output", doctorFail, dir + " is not a directory"}
+	}
+	probe := filepath.Join(dir, fmt.Sprintf(".tempus-doctor-%d", os.Getpid()))
+	if err := outputFS.WriteFile(probe, nil, policy.FileMode); err != nil {
+		return doctorCheck{"output", doctorFail, fmt.Sprintf("%s is not writable: %v", dir, err)}
+	}
+	_ = outputFS.Remove(probe)
+	return doctorCheck{"output", doctorPass, dir + " is writable"}
+}
+
+// doctorIntegrations checks the optional tools: git (template install from a
+// repository) and the tempus serve token.
+func doctorIntegrations() []doctorCheck {
+	var checks []doctorCheck
+	if path, err := exec.LookPath("git"); err != nil {
+		checks = append(checks, doctorCheck{"git", doctorWarn, "not found; template install from git repositories won't work"})
+	} else {
+		checks = append(checks, doctorCheck{"git", doctorPass, path})
+	}
+	switch token := strings.TrimSpace(os.Getenv("TEMPUS_SERVE_TOKEN")); {
+	case token == "":
+		checks = append(checks, doctorCheck{"serve token", doctorSkip, "TEMPUS_SERVE_TOKEN not set; tempus serve feeds are open unless --token is given"})
+	case len(token) < 16:
+		checks = append(checks, doctorCheck{"serve token", doctorWarn, fmt.Sprintf("TEMPUS_SERVE_TOKEN is only %d characters; use at least 16", len(token))})
+	default:
+		checks = append(checks, doctorCheck{"serve token", doctorPass, "TEMPUS_SERVE_TOKEN is set"})
 	}
+	return checks
+}
 
-	cmd.AddCommand(
-		&cobra.Command{
-			Use:   "set <key> <value>",
-			Short: "Set a configuration value",
-			Args:  cobra.ExactArgs(2),
-			RunE:  runConfigSet,
-		},
-		&cobra.Command{
-			Use:   "list",
-			Short: "List all configuration values",
-			RunE:  runConfigList,
-		},
-		&cobra.Command{
-			Use:   "alarm-profiles",
-			Short: "List available alarm profiles",
-			RunE:  runConfigAlarmProfiles,
-		},
-	)
+func newWorkspaceCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:   "workspace",
+		Short: "Export or import config, alarm profiles, templates and locales as one archive",
+		Long: `A workspace is everything in the config directory you set up by hand:
+config.yaml (with its alarm_profiles and spell_corrections), custom templates
+and custom locales. Export it to move to another machine or to share a team
+setup; the undo journal and downloaded timezone data stay behind.`,
+	}
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write the workspace to a zip archive",
+		Example: `  tempus workspace export
+  tempus workspace export -o team-setup.zip`,
+		Args: cobra.NoArgs,
+		RunE: runWorkspaceExport,
+	}
+	exportCmd.Flags().StringP("output", "o", "tempus-workspace.zip", "Archive to write (- for stdout)")
+
+	importCmd := &cobra.Command{
+		Use:   "import <archive>",
+		Short: "Unpack a workspace archive into the config directory",
+		Long: `Unpack an archive written by 'tempus workspace export'. Nothing is written
+unless the whole archive is valid, and files you already have with other
+content are left alone unless --force is given.`,
+		Example: `  tempus workspace import team-setup.zip --dry-run
+  tempus workspace import team-setup.zip --force`,
+		Args: cobra.ExactArgs(1),
+		RunE: runWorkspaceImport,
+	}
+	importCmd.Flags().Bool("force", false, "Replace existing files that differ from the archive")
+	importCmd.Flags().Bool("dry-run", false, "List what would be written without changing anything")
 
-	return cmd
+	root.AddCommand(exportCmd, importCmd)
+	return root
 }
 
-func runConfigSet(_ *cobra.Command, args []string) error {
-	cfg, err := config.Load()
+func runWorkspaceExport(cmd *cobra.Command, _ []string) error {
+	outputPath, _ := cmd.Flags().GetString("output")
+	outputPath = strings.TrimSpace(outputPath)
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to find the config directory: %w", err)
+	}
+
+	var buf bytes.Buffer
+	files, err := config.ExportWorkspace(dir, &buf)
 	if err != nil {
 		return err
 	}
-	if err := cfg.Set(args[0], args[1]); err != nil {
+	if outputPath == "-" {
+		_, err := os.Stdout.Write(buf.Bytes())
 		return err
 	}
-	printOK("Config updated: %s = %s\n", args[0], args[1])
+	if err := writeGeneratedFile(outputPath, buf.Bytes(), config.OutputPolicy{}); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+	printOK("Exported %d file(s) from %s to %s\n", len(files), dir, outputPath)
 	return nil
 }
 
-func runConfigList(_ *cobra.Command, _ []string) error {
-	cfg, err := config.Load()
+func runWorkspaceImport(cmd *cobra.Command, args []string) error {
+	force, _ := cmd.Flags().GetBool("force")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	dir, err := config.ConfigDir()
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to find the config directory: %w", err)
 	}
-	return cfg.List()
-}
-
-func runConfigAlarmProfiles(_ *cobra.Command, _ []string) error {
-	cfg, err := config.Load()
+	data, err := os.ReadFile(args[0])
 	if err != nil {
 		return err
 	}
 
-	if cfg.AlarmProfiles == nil || len(cfg.AlarmProfiles) == 0 {
-		fmt.Println("No alarm profiles configured.")
-		return nil
+	report, err := config.ImportWorkspace(dir, data, config.WorkspaceImportOptions{Overwrite: force, DryRun: dryRun})
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
 	}
-
-	fmt.Println("Available alarm profiles:")
-	fmt.Println()
-
-	// Sort profile names for consistent output
-	names := cfg.ListAlarmProfiles()
-	sort.Strings(names)
-
-	for _, name := range names {
-		profile := cfg.GetAlarmProfile(name)
-		if profile == nil {
-			continue
-		}
-
-		fmt.Printf("  %s:\n", name)
-		if len(profile) == 0 {
-			fmt.Println("    (no alarms)")
+	verb := "Wrote"
+	if dryRun {
+		verb = "Would write"
+	}
+	for _, name := range report.Written {
+		if slices.Contains(report.Conflicts, name) {
+			fmt.Printf("  %s (replaced)\n", name)
 		} else {
-			for _, trigger := range profile {
-				fmt.Printf("    - %s\n", trigger)
-			}
+			fmt.Printf("  %s\n", name)
 		}
-		fmt.Println()
 	}
-
-	fmt.Println("Usage in batch files:")
-	fmt.Printf("  CSV:  alarms column with 'profile:adhd-triple'\n")
-	fmt.Printf("  JSON: \"alarms\": [\"profile:medication\"]\n")
-	fmt.Printf("  YAML: alarms: [profile:single]\n")
-
+	printOK("%s %d file(s) into %s (%d already up to date)\n", verb, len(report.Written), dir, len(report.Unchanged))
 	return nil
 }
 
-var (
-	version = "dev"     // override with -X main.version=...
-	commit  = "unknown" // override with -X main.commit=...
-	date    = ""        // override with -X main.date=...
-)
-
 func newVersionCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "version",
 		Short: "Show version information",
 		Run: func(_ *cobra.Command, _ []string) {
 			if strings.TrimSpace(date) == "" {
-				fmt.Printf("tempus %s\n", version)
+				fmt.Printf("tempus %s [%s]\n", version, config.ReleaseChannel())
 			} else {
-				fmt.Printf("tempus %s (%s) built %s\n", version, commit, date)
+				fmt.Printf("tempus %s (%s) built %s [%s]\n", version, commit, date, config.ReleaseChannel())
+			}
+			fmt.Printf("tzdata %s\n", tzpkg.CurrentTZData())
+		},
+	}
+}
+
+func newCompletionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion [bash|zsh|fish|powershell]",
+		Short: "Generate a shell completion script",
+		Long: `Print a completion script for your shell. Besides commands and flags it
+completes timezone names (--timezone, --start-tz, --end-tz, --default-tz,
+--to-tz), template names (template create/describe) and --language codes.`,
+		Example: `  source <(tempus completion bash)
+  tempus completion zsh > "${fpath[1]}/_tempus"
+  tempus completion fish > ~/.config/fish/completions/tempus.fish
+  tempus completion powershell | Out-String | Invoke-Expression`,
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			switch args[0] {
+			case "bash":
+				return cmd.Root().GenBashCompletionV2(out, true)
+			case "zsh":
+				return cmd.Root().GenZshCompletion(out)
+			case "fish":
+				return cmd.Root().GenFishCompletion(out, true)
+			default:
+				return cmd.Root().GenPowerShellCompletionWithDesc(out)
 			}
 		},
 	}
 }
 
+// timezoneFlags are completed with IANA names wherever a command defines them.
+var timezoneFlags = []string{"timezone", "tz", "start-tz", "end-tz", "default-tz", "to-tz"}
+
+// registerDynamicCompletions wires value completion for timezone and language
+// flags across the command tree.
+func registerDynamicCompletions(root *cobra.Command) {
+	_ = root.RegisterFlagCompletionFunc("language", completeLanguages)
+	var walk func(*cobra.Command)
+	walk = func(c *cobra.Command) {
+		for _, name := range timezoneFlags {
+			if c.LocalFlags().Lookup(name) != nil {
+				_ = c.RegisterFlagCompletionFunc(name, completeTimezones)
+			}
+		}
+		for _, sub := range c.Commands() {
+			walk(sub)
+		}
+	}
+	walk(root)
+}
+
+// completeTimezones offers IANA names matching what has been typed so far
+// (case-insensitive prefix), described by their display name.
+func completeTimezones(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var out []string
+	for _, tz := range tzpkg.NewTimezoneManager().ListTimezones() {
+		if !strings.HasPrefix(strings.ToLower(tz.IANA), strings.ToLower(toComplete)) {
+			continue
+		}
+		if desc := cleanDisplay(tz.DisplayName); desc != "" && desc != tz.IANA {
+			out = append(out, tz.IANA+"\t"+desc)
+		} else {
+			out = append(out, tz.IANA)
+		}
+	}
+	sort.Strings(out)
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeLanguages(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return i18n.SupportedLanguages(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTemplateNames completes the first argument with the templates
+// loadTemplateManager would see (built-ins plus --templates-dir).
+func completeTemplateNames(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	tm, _, err := loadTemplateManager(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var names []string
+	for name, t := range tm.ListTemplates() {
+		if t.Description != "" {
+			name += "\t" + t.Description
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
 // ========================================================================
 // RRULE Helper Command
 // ========================================================================
 
 func newRRuleHelperCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "rrule",
 		Short: "Interactive helper to build recurrence rules (RRULE)",
 		Long: `Generate RRULE strings for recurring events without memorizing the syntax.
@@ -2446,12 +12606,58 @@ Examples of what you can create:
   - Every 2 weeks on Tuesday and Thursday
   - Monthly on the 15th
   - Yearly on March 1st
-  - Custom patterns with end dates or occurrence counts`,
+  - Custom patterns with end dates or occurrence counts
+
+End dates can be written as YYYY-MM-DD, end-of-month, end-of-quarter,
+end-of-semester, end-of-year or "in 12 weeks", counted from --start (or
+today). With a timed --start (and --start-tz), UNTIL is written as the UTC
+date-time RFC 5545 requires; otherwise it is a date.
+
+With --start and --skip-holidays/--skip-weekends, it also lists the EXDATE
+values needed to skip holidays/weekends within the generated range.`,
 		RunE: runRRuleHelper,
 	}
+
+	cmd.Flags().String("start", "", "First occurrence (YYYY-MM-DD HH:MM or YYYY-MM-DD), used to compute EXDATEs and the UNTIL end date")
+	cmd.Flags().String("start-tz", "", "Timezone of a timed --start, used to write UNTIL in UTC (default: UTC)")
+	cmd.Flags().Bool("skip-weekends", false, "List EXDATEs for occurrences on Saturday or Sunday")
+	cmd.Flags().Bool("skip-holidays", false, "List EXDATEs for occurrences on holidays (config holidays or --holidays)")
+	cmd.Flags().String("holidays", "", "Holiday file (.ics or 'YYYY-MM-DD Name' lines), merged with config holidays")
+
+	return cmd
 }
 
-func runRRuleHelper(_ *cobra.Command, _ []string) error {
+func runRRuleHelper(cmd *cobra.Command, _ []string) error {
+	days, err := parseDayFilterFlags(cmd)
+	if err != nil {
+		return err
+	}
+	startStr, _ := cmd.Flags().GetString("start")
+	startTZ, _ := cmd.Flags().GetString("start-tz")
+	var start time.Time
+	if days.enabled() || strings.TrimSpace(startStr) != "" {
+		if start, err = parseRRuleHelperStart(startStr); err != nil {
+			return err
+		}
+	}
+	timed := len(strings.TrimSpace(startStr)) > len(constants.DateFormatISO)
+	if startTZ != "" {
+		if _, err := time.LoadLocation(startTZ); err != nil {
+			return fmt.Errorf("invalid --start-tz %q: %w", startTZ, err)
+		}
+	}
+	from := start
+	if from.IsZero() {
+		from = appClock.Now()
+	}
+	untilFor := func(spec string) (string, error) {
+		last, err := calendar.ResolveUntil(spec, from)
+		if err != nil {
+			return "", err
+		}
+		return calendar.UntilValue(last, !timed, startTZ), nil
+	}
+
 	fmt.Println("RRULE Builder - Create recurring event patterns")
 	fmt.Println()
 
@@ -2472,7 +12678,7 @@ func runRRuleHelper(_ *cobra.Command, _ []string) error {
 		}
 	}
 
-	if endCond := promptRRuleEndCondition(); endCond != "" {
+	if endCond := promptRRuleEndCondition(untilFor); endCond != "" {
 		parts = append(parts, endCond)
 	}
 
@@ -2493,7 +12699,65 @@ func runRRuleHelper(_ *cobra.Command, _ []string) error {
 	// Show human-readable interpretation
 	fmt.Println("This means:")
 	fmt.Printf("  %s\n", interpretRRule(rrule))
+	if strings.Contains(rrule, "UNTIL=") && !timed {
+		fmt.Println()
+		fmt.Println("UNTIL is a date, which matches all-day events. For timed events, pass")
+		fmt.Println("--start \"YYYY-MM-DD HH:MM\" (and --start-tz) to get the UTC UNTIL RFC 5545 requires.")
+	}
+
+	if days.enabled() {
+		return printRRuleHolidayExDates(rrule, start, days)
+	}
+	return nil
+}
+
+func parseRRuleHelperStart(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("--start is required with --skip-holidays/--skip-weekends")
+	}
+	if t, err := time.Parse(constants.DateTimeFormatISO, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(constants.DateFormatISO, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --start %q (use YYYY-MM-DD HH:MM or YYYY-MM-DD)", s)
+}
+
+// rruleHolidayExDates returns the occurrences of rrule from start that land on blocked days.
+func rruleHolidayExDates(rrule string, start time.Time, days dayFilter) ([]time.Time, error) {
+	if _, err := calendar.ParseRRule(rrule); err != nil {
+		return nil, err
+	}
+	days.shift = true
+	ev := calendar.NewEvent("", start, start.Add(time.Hour))
+	ev.RRule = rrule
+	days.excludeOccurrences(ev)
+	return ev.ExDates, nil
+}
+
+func printRRuleHolidayExDates(rrule string, start time.Time, days dayFilter) error {
+	exdates, err := rruleHolidayExDates(rrule, start, days)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	if len(exdates) == 0 {
+		fmt.Println("No occurrences fall on weekends/holidays.")
+		return nil
+	}
 
+	layout := constants.DateTimeFormatISO
+	if start.Hour() == 0 && start.Minute() == 0 {
+		layout = constants.DateFormatISO
+	}
+	values := make([]string, 0, len(exdates))
+	for _, x := range exdates {
+		values = append(values, x.Format(layout))
+	}
+	fmt.Printf("Skip %d occurrence(s) on weekends/holidays with:\n", len(values))
+	fmt.Printf("  exdate column = %s\n", strings.Join(values, ";"))
 	return nil
 }
 
@@ -2591,7 +12855,9 @@ func promptRRuleWeeklyDays() string {
 	return ""
 }
 
-func promptRRuleEndCondition() string {
+// promptRRuleEndCondition asks how the series ends; untilFor turns the end
+// date the user types into an UNTIL value.
+func promptRRuleEndCondition(untilFor func(string) (string, error)) string {
 	fmt.Println("\nHow should the recurrence end?")
 	fmt.Println("  1. Never (infinite)")
 	fmt.Println("  2. After N occurrences")
@@ -2613,16 +12879,17 @@ func promptRRuleEndCondition() string {
 			return fmt.Sprintf("COUNT=%d", count)
 		}
 	case 3:
-		fmt.Print("End date (YYYY-MM-DD): ")
-		var untilStr string
-		_, _ = fmt.Scanln(&untilStr)
-		untilStr = strings.TrimSpace(untilStr)
-		if untilStr != "" {
-			if _, err := time.Parse("2006-01-02", untilStr); err == nil {
-				untilStr = strings.ReplaceAll(untilStr, "-", "")
-				return fmt.Sprintf("UNTIL=%s", untilStr)
-			}
+		fmt.Print("End date (YYYY-MM-DD, end-of-month, end-of-semester, end-of-year, in 12 weeks...): ")
+		untilStr, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if untilStr = strings.TrimSpace(untilStr); untilStr == "" {
+			return ""
+		}
+		until, err := untilFor(untilStr)
+		if err != nil {
+			output.Warn(os.Stdout, "%v; the rule repeats forever\n", err)
+			return ""
 		}
+		return "UNTIL=" + until
 	}
 	return ""
 }
@@ -2636,14 +12903,18 @@ func newTemplateCmd() *cobra.Command {
 	cmd.PersistentFlags().String("templates-dir", "", "Directory with JSON templates (default: user config dir)")
 
 	createCmd := &cobra.Command{
-		Use:   "create <template-name>",
-		Short: "Create event from template",
-		Args:  cobra.ExactArgs(1),
-		RunE:  runTemplateCreate,
+		Use:               "create <template-name>",
+		Short:             "Create event from template",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeTemplateNames,
+		RunE:              runTemplateCreate,
 	}
 	createCmd.Flags().String("output-dir", "", "Directory where generated ICS files will be stored")
-	createCmd.Flags().String("input", "", "CSV or JSON file with template data (creates one ICS per row)")
-	createCmd.Flags().String("format", "auto", "Input format: auto, csv, or json")
+	createCmd.Flags().String("input", "", "CSV, JSON, YAML or TOML file with template data (creates one ICS per row)")
+	createCmd.Flags().String("format", "auto", "Input format: auto, csv, json, yaml, or toml")
+	createCmd.Flags().String("default-tz", "", "Timezone for timezone fields a data row leaves empty (and the prompts' default)")
+	createCmd.Flags().Bool("emit-duration", false, "Write DURATION instead of DTEND")
+	createCmd.Flags().Bool("open", false, "Open the written calendars in the default calendar app to import them")
 	createCmd.Flags().String("templates-dir", "", "Directory with JSON templates (overrides defaults)")
 
 	cmd.AddCommand(
@@ -2654,10 +12925,11 @@ func newTemplateCmd() *cobra.Command {
 		},
 		createCmd,
 		&cobra.Command{
-			Use:   "describe <template-name>",
-			Short: "Show details for a template",
-			Args:  cobra.ExactArgs(1),
-			RunE:  runTemplateDescribe,
+			Use:               "describe <template-name>",
+			Short:             "Show details for a template",
+			Args:              cobra.ExactArgs(1),
+			ValidArgsFunction: completeTemplateNames,
+			RunE:              runTemplateDescribe,
 		},
 		&cobra.Command{
 			Use:   "validate",
@@ -2665,28 +12937,263 @@ func newTemplateCmd() *cobra.Command {
 			RunE:  runTemplateValidate,
 		},
 		newTemplateInitCmd(),
+		newTemplateMigrateCmd(),
+		newTemplateInstallCmd(),
+		newTemplateUpdateCmd(),
+		newTemplateRemoveCmd(),
 	)
 
-	return cmd
+	return cmd
+}
+
+func newTemplateInitCmd() *cobra.Command {
+	defaultDir := "."
+	if dirs := tpl.DefaultTemplateDirs(); len(dirs) > 0 {
+		defaultDir = dirs[0]
+	}
+
+	cmd := &cobra.Command{
+		Use:   "init <template-name>",
+		Short: "Generate a template scaffold file",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runTemplateInit,
+	}
+	cmd.Flags().String("dir", defaultDir, "Directory where the scaffold will be written")
+	cmd.Flags().String("format", "yaml", "Output format (yaml or json)")
+	cmd.Flags().String("lang", "en", "Language for sample labels (en, es, pt)")
+	cmd.Flags().Bool("force", false, "Overwrite the file if it already exists")
+	return cmd
+}
+
+func newTemplateMigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate [file...]",
+		Short: "Upgrade data-driven template files to the current schema_version",
+		Long: fmt.Sprintf(`Rewrite template files in place so they use schema_version %d.
+
+Without arguments every template in the template directories is checked.
+Files are re-encoded, so comments and key order are not preserved.`, tpl.CurrentSchemaVersion),
+		RunE: runTemplateMigrate,
+	}
+	cmd.Flags().Bool("dry-run", false, "Show what would change without writing files")
+	return cmd
+}
+
+func runTemplateMigrate(cmd *cobra.Command, args []string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	paths := args
+	if len(paths) == 0 {
+		templatesDirFlag, _ := cmd.Flags().GetString("templates-dir")
+		found, err := findTemplateFiles(tpl.ResolveTemplateDirs(templatesDirFlag))
+		if err != nil {
+			return err
+		}
+		paths = found
+	}
+	if len(paths) == 0 {
+		fmt.Println("No template files found.")
+		return nil
+	}
+
+	var failed int
+	for _, path := range paths {
+		res, err := tpl.MigrateDDTemplateFile(path, dryRun)
+		if err != nil {
+			printErr("%v\n", err)
+			failed++
+			continue
+		}
+		if !res.Changed() {
+			fmt.Printf("  %s: already at schema_version %d\n", path, res.ToVersion)
+			continue
+		}
+		verb := "migrated"
+		if dryRun {
+			verb = "would migrate"
+		}
+		printOK("%s: %s v%d → v%d\n", path, verb, res.FromVersion, res.ToVersion)
+		for _, note := range res.Notes {
+			fmt.Printf("    • %s\n", note)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d template file(s) could not be migrated", failed)
+	}
+	return nil
+}
+
+func newTemplateInstallCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "install <url|git-repo|file>",
+		Short: "Install shared templates from a URL, a git repository or a file",
+		Long: `Download data-driven templates into your templates directory so a team can
+share one curated set.
+
+A URL or path ending in .json, .yaml or .yml installs that one file; anything
+else is cloned with git and every valid template in the repository is
+installed under a directory named after it. Each template is validated before
+anything is written, and the SHA-256 of every file is recorded in
+installed.lock so update and remove can tell files you edited since.
+
+A single file is checked against --sha256, or against <url>.sha256 when the
+publisher provides one; only a 404 there counts as none published, any other
+failure stops the install.`,
+		Example: `  tempus template install https://example.com/templates/standup.yaml --sha256 9f2c...
+  tempus template install https://github.com/team/tempus-templates.git --ref v1.2
+  tempus template list`,
+		Args: cobra.ExactArgs(1),
+		RunE: runTemplateInstall,
+	}
+	cmd.Flags().String("sha256", "", "Expected SHA-256 of a single template file")
+	cmd.Flags().String("ref", "", "Git branch or tag to install")
+	cmd.Flags().Bool("force", false, "Replace files that exist or were edited locally")
+	return cmd
+}
+
+func newTemplateUpdateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update [template|source...]",
+		Short: "Fetch installed templates again",
+		Long: `Re-download installed sources and rewrite their templates. Without arguments
+every installed source is updated. Templates edited since they were installed
+are left alone unless --force is given.`,
+		RunE: runTemplateUpdate,
+	}
+	cmd.Flags().Bool("force", false, "Overwrite templates edited locally")
+	return cmd
+}
+
+func newTemplateRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove <template|source>",
+		Short: "Remove installed templates",
+		Long: `Delete the templates of an installed source. Naming one template removes every
+template that came from the same source.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runTemplateRemove,
+	}
+	cmd.Flags().Bool("force", false, "Remove templates edited locally too")
+	return cmd
+}
+
+// templateInstaller installs into the first template directory: the user
+// templates directory, or --templates-dir.
+func templateInstaller(cmd *cobra.Command) *tpl.Installer {
+	templatesDirFlag, _ := cmd.Flags().GetString("templates-dir")
+	in := tpl.NewInstaller(tpl.ResolveTemplateDirs(templatesDirFlag)[0])
+	in.UserAgent = "tempus/" + version
+	return in
+}
+
+func runTemplateInstall(cmd *cobra.Command, args []string) error {
+	in := templateInstaller(cmd)
+	opts := tpl.InstallOptions{}
+	opts.SHA256, _ = cmd.Flags().GetString("sha256")
+	opts.Ref, _ = cmd.Flags().GetString("ref")
+	opts.Force, _ = cmd.Flags().GetBool("force")
+
+	output.Info(os.Stdout, "📦", "Installing %s\n", args[0])
+	src, changes, err := in.Install(cmd.Context(), args[0], opts)
+	if err != nil {
+		return err
+	}
+	printOK("Installed %d template(s) into %s\n", len(src.Files), in.Dir)
+	printTemplateChanges(src, changes)
+	return nil
+}
+
+func runTemplateUpdate(cmd *cobra.Command, args []string) error {
+	in := templateInstaller(cmd)
+	force, _ := cmd.Flags().GetBool("force")
+
+	targets := args
+	if len(targets) == 0 {
+		sources, err := in.Installed()
+		if err != nil {
+			return err
+		}
+		for _, s := range sources {
+			targets = append(targets, s.Source)
+		}
+	}
+	if len(targets) == 0 {
+		fmt.Println("No installed templates.")
+		return nil
+	}
+
+	var failed int
+	for _, target := range targets {
+		src, changes, err := in.Update(cmd.Context(), target, force)
+		if err != nil {
+			printErr("%s: %v\n", target, err)
+			failed++
+			continue
+		}
+		printOK("Updated %s\n", src.Source)
+		printTemplateChanges(src, changes)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d source(s) could not be updated", failed)
+	}
+	return nil
 }
 
-func newTemplateInitCmd() *cobra.Command {
-	defaultDir := "."
-	if dirs := tpl.DefaultTemplateDirs(); len(dirs) > 0 {
-		defaultDir = dirs[0]
+func runTemplateRemove(cmd *cobra.Command, args []string) error {
+	in := templateInstaller(cmd)
+	force, _ := cmd.Flags().GetBool("force")
+	src, changes, err := in.Remove(args[0], force)
+	if err != nil {
+		return err
 	}
+	printOK("Removed %d template(s) from %s\n", len(changes), src.Source)
+	printTemplateChanges(src, changes)
+	return nil
+}
 
-	cmd := &cobra.Command{
-		Use:   "init <template-name>",
-		Short: "Generate a template scaffold file",
-		Args:  cobra.ExactArgs(1),
-		RunE:  runTemplateInit,
+// printTemplateChanges lists what happened to each file of an installed source.
+func printTemplateChanges(src tpl.InstalledSource, changes []tpl.Change) {
+	names := map[string]string{}
+	for _, f := range src.Files {
+		names[f.Path] = f.Template
 	}
-	cmd.Flags().String("dir", defaultDir, "Directory where the scaffold will be written")
-	cmd.Flags().String("format", "yaml", "Output format (yaml or json)")
-	cmd.Flags().String("lang", "en", "Language for sample labels (en, es, pt)")
-	cmd.Flags().Bool("force", false, "Overwrite the file if it already exists")
-	return cmd
+	for _, c := range changes {
+		line := fmt.Sprintf("  %-9s %s", c.Kind, c.Path)
+		if name := names[c.Path]; name != "" {
+			line += fmt.Sprintf(" (%s)", name)
+		}
+		fmt.Println(line)
+	}
+	if src.Commit != "" {
+		output.Detail(os.Stdout, "  commit %s\n", src.Commit)
+	}
+}
+
+// findTemplateFiles lists the JSON/YAML files under dirs, skipping missing directories.
+func findTemplateFiles(dirs []string) ([]string, error) {
+	var files []string
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) && p == dir {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			switch strings.ToLower(filepath.Ext(p)) {
+			case ".json", ".yaml", ".yml":
+				if !d.IsDir() {
+					files = append(files, p)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
 }
 
 func runTemplateList(cmd *cobra.Command, _ []string) error {
@@ -2695,13 +13202,28 @@ func runTemplateList(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
-	fmt.Println("Available templates:")
 	all := tm.ListTemplates()
 	names := make([]string, 0, len(all))
 	for name := range all {
 		names = append(names, name)
 	}
 	sort.Strings(names)
+
+	if jsonReport(cmd) {
+		type templateJSON struct {
+			Name        string      `json:"name"`
+			Description string      `json:"description"`
+			Fields      []tpl.Field `json:"fields"`
+		}
+		list := make([]templateJSON, 0, len(names))
+		for _, name := range names {
+			t := all[name]
+			list = append(list, templateJSON{Name: name, Description: t.Description, Fields: t.Fields})
+		}
+		return printJSON(cmd.OutOrStdout(), list)
+	}
+
+	fmt.Println("Available templates:")
 	for _, name := range names {
 		t := all[name]
 		desc := t.Description
@@ -2729,40 +13251,64 @@ func runTemplateCreate(cmd *cobra.Command, args []string) error {
 	outputDir, _ := cmd.Flags().GetString("output-dir")
 	inputPath, _ := cmd.Flags().GetString("input")
 	formatFlag, _ := cmd.Flags().GetString("format")
+	defaultTZ, _ := cmd.Flags().GetString("default-tz")
+	emitDuration, _ := cmd.Flags().GetBool("emit-duration")
+	open, _ := cmd.Flags().GetBool("open")
 
 	dd, _ := tm.DataTemplate(name)
 
+	policy, err := templateOutputPolicy(outputDir)
+	if err != nil {
+		return err
+	}
+	if tz := strings.TrimSpace(defaultTZ); tz != "" {
+		tz, warnings := canonicalTimezone(tz, "--default-tz")
+		if _, err := time.LoadLocation(tz); err != nil {
+			return fmt.Errorf("invalid --default-tz %q: %w", tz, err)
+		}
+		diag.Render(cmd.ErrOrStderr(), warnings)
+		defaultTZ = tz
+	}
+
 	if strings.TrimSpace(inputPath) != "" {
 		params := templateCreateParams{
 			templateName: name,
 			inputPath:    inputPath,
 			formatFlag:   formatFlag,
-			outputDir:    outputDir,
+			defaultTZ:    defaultTZ,
+			emitDuration: emitDuration,
+			open:         open,
+			policy:       policy,
 		}
 		return runTemplateCreateFromFile(tm, tr, tmpl, dd, params)
 	}
 
 	values := map[string]string{}
 	for _, f := range tmpl.Fields {
+		if f.Type == "timezone" && defaultTZ != "" {
+			f.Default = defaultTZ // offered as the answer to the prompt
+		}
 		if isAlarmField(f) {
 			values[f.Key] = promptAlarmField(labelForField(f), f.Default)
 			continue
 		}
-		v := promptInput(labelForField(f), f.Default)
-		if f.Required && strings.TrimSpace(v) == "" {
-			return fmt.Errorf("field %q is required", f.Key)
+		v, err := promptTemplateField(f)
+		if err != nil {
+			return err
 		}
 		values[f.Key] = v
 	}
 
 	normalizeValuesForTemplate(values, tmpl, dd)
 
-	ev, err := tm.GenerateEvent(name, values, tr)
+	events, err := tm.GenerateEvents(name, values, tr)
 	if err != nil {
 		return err
 	}
 
-	cal := buildTemplateCalendar(ev)
+	ev := events[0]
+	cal := buildTemplateCalendar(events...)
+	cal.EmitDuration = emitDuration
 
 	augmented := augmentValuesForFilename(values, ev)
 	defaultName := deriveTemplateFilename(tm, name, augmented, ev, tr)
@@ -2771,20 +13317,18 @@ func runTemplateCreate(cmd *cobra.Command, args []string) error {
 	if finalName == "" {
 		finalName = defaultName
 	}
-	finalName = ensureICSExtension(finalName)
-	if dir := strings.TrimSpace(outputDir); dir != "" && !filepath.IsAbs(finalName) {
-		finalName = filepath.Join(dir, finalName)
-	}
-	finalName = ensureUniquePath(finalName)
-
-	if err := ensureDirForFile(finalName); err != nil {
+	if finalName, err = claimTemplateOutput(finalName, policy, nil); err != nil {
 		return err
 	}
-	if err := os.WriteFile(finalName, []byte(cal.ToICS()), 0600); err != nil {
+	applyCategoryColors(cal)
+	if err := writeGeneratedStream(finalName, policy, cal.WriteICS); err != nil {
 		printErr(constants.ErrMsgFailedToWriteFile, err)
 		return err
 	}
 	printOK("Created: %s\n", finalName)
+	if open {
+		openInCalendarApp(os.Stdout, finalName)
+	}
 	return nil
 }
 
@@ -2792,7 +13336,48 @@ type templateCreateParams struct {
 	templateName string
 	inputPath    string
 	formatFlag   string
-	outputDir    string
+	defaultTZ    string
+	emitDuration bool
+	open         bool // open the files once every row is written
+	policy       config.OutputPolicy
+}
+
+// templateOutputPolicy is output.dir and output.overwrite from config, with
+// --output-dir winning over output.dir.
+func templateOutputPolicy(outputDir string) (config.OutputPolicy, error) {
+	policy, err := loadOutputPolicy()
+	if err != nil {
+		return policy, err
+	}
+	if dir := strings.TrimSpace(outputDir); dir != "" {
+		policy.Dir = dir
+	}
+	return policy, nil
+}
+
+// claimTemplateOutput places name in the output directory and applies
+// output.overwrite; a path already in written (from an earlier row of the
+// same run) always gets a unique name so rows never overwrite each other.
+func claimTemplateOutput(name string, policy config.OutputPolicy, written map[string]bool) (string, error) {
+	path := placeOutputPath(ensureICSExtension(name), policy)
+	if written[path] {
+		return ensureUniquePath(path), nil
+	}
+	return claimOutputPath(path, policy)
+}
+
+// fillTemplateTimezones gives the timezone fields a row leaves empty the
+// --default-tz zone, ahead of the fields' own defaults, as batch does for
+// rows without start_tz.
+func fillTemplateTimezones(tmpl *tpl.Template, record map[string]string, tz string) {
+	if tz == "" {
+		return
+	}
+	for _, f := range tmpl.Fields {
+		if f.Type == "timezone" && strings.TrimSpace(record[f.Key]) == "" {
+			record[f.Key] = tz
+		}
+	}
 }
 
 func runTemplateCreateFromFile(tm *tpl.TemplateManager, tr *i18n.Translator, tmpl *tpl.Template, dd tpl.DataDrivenTemplate, params templateCreateParams) error {
@@ -2809,34 +13394,42 @@ func runTemplateCreateFromFile(tm *tpl.TemplateManager, tr *i18n.Translator, tmp
 		return fmt.Errorf("no data found in %s", params.inputPath)
 	}
 
-	params.outputDir = strings.TrimSpace(params.outputDir)
+	policy := params.policy
+	written := map[string]bool{}
+	var created []string
 	for idx, record := range records {
+		fillTemplateTimezones(tmpl, record, params.defaultTZ)
 		values := mergeTemplateValues(tmpl, record)
 		normalizeValuesForTemplate(values, tmpl, dd)
 
-		ev, err := tm.GenerateEvent(params.templateName, values, tr)
+		events, err := tm.GenerateEvents(params.templateName, values, tr)
 		if err != nil {
 			return fmt.Errorf(testutil.ErrMsgRowFormat, idx+1, err)
 		}
 
-		cal := buildTemplateCalendar(ev)
+		ev := events[0]
+		cal := buildTemplateCalendar(events...)
+		cal.EmitDuration = params.emitDuration
 		augmented := augmentValuesForFilename(values, ev)
 		filename := deriveTemplateFilename(tm, params.templateName, augmented, ev, tr)
-		filename = ensureICSExtension(filename)
-		if params.outputDir != "" && !filepath.IsAbs(filename) {
-			filename = filepath.Join(params.outputDir, filename)
-		}
-		filename = ensureUniquePath(filename)
-
-		if err := ensureDirForFile(filename); err != nil {
+		if filename, err = claimTemplateOutput(filename, policy, written); err != nil {
 			return fmt.Errorf(testutil.ErrMsgRowFormat, idx+1, err)
 		}
-		if err := os.WriteFile(filename, []byte(cal.ToICS()), 0600); err != nil {
+		written[filename] = true
+
+		applyCategoryColors(cal)
+		if err := writeGeneratedStream(filename, policy, cal.WriteICS); err != nil {
 			return fmt.Errorf("row %d: failed to write file: %w", idx+1, err)
 		}
 		printOK("Created: %s\n", filename)
+		created = append(created, filename)
 	}
 
+	if params.open {
+		for _, filename := range created {
+			openInCalendarApp(os.Stdout, filename)
+		}
+	}
 	return nil
 }
 
@@ -2898,6 +13491,15 @@ func printTemplateFields(fields []tpl.Field) {
 		if field.Default != "" {
 			line += fmt.Sprintf(", default=%q", field.Default)
 		}
+		if len(field.Options) > 0 {
+			line += fmt.Sprintf(", options=%s", strings.Join(field.Options, "|"))
+		}
+		if field.Min != nil {
+			line += fmt.Sprintf(", min=%g", *field.Min)
+		}
+		if field.Max != nil {
+			line += fmt.Sprintf(", max=%g", *field.Max)
+		}
 		if strings.TrimSpace(field.Description) != "" {
 			line += fmt.Sprintf(" — %s", field.Description)
 		}
@@ -2926,6 +13528,22 @@ func printTemplateOutput(dd tpl.DataDrivenTemplate) {
 	if dd.Output.Priority > 0 {
 		fmt.Printf("  priority: %d\n", dd.Output.Priority)
 	}
+	if len(dd.Events) > 0 {
+		fmt.Println("Related events:")
+	}
+	for _, ev := range dd.Events {
+		line := "  - " + ev.SummaryTmpl
+		if f := strings.TrimSpace(ev.StartField); f != "" {
+			line += fmt.Sprintf(" (from %s)", f)
+		}
+		if o := strings.TrimSpace(ev.Offset); o != "" {
+			line += fmt.Sprintf(", offset %s", o)
+		}
+		if d := strings.TrimSpace(ev.Duration); d != "" {
+			line += fmt.Sprintf(", %s long", d)
+		}
+		fmt.Println(line)
+	}
 }
 
 func printIfNotEmpty(format, value string) {
@@ -2934,32 +13552,26 @@ func printIfNotEmpty(format, value string) {
 	}
 }
 
-func detectTemplateInputFormat(flag, path string) (string, error) {
-	flag = strings.ToLower(strings.TrimSpace(flag))
-	switch flag {
-	case "", "auto":
-		ext := strings.ToLower(filepath.Ext(path))
-		switch ext {
-		case ".csv":
-			return "csv", nil
-		case ".json":
-			return "json", nil
-		default:
-			return "", fmt.Errorf("cannot infer format from %s; use --format csv|json", path)
-		}
-	case "csv", "json":
-		return flag, nil
-	default:
-		return "", fmt.Errorf("unsupported format %q (use csv or json)", flag)
+// detectTemplateInputFormat picks the data file format the way batch does;
+// timetables describe whole schedules rather than template rows.
+func detectTemplateInputFormat(flag, path string) (batchFormat, error) {
+	format, err := detectBatchFormat(flag, path)
+	if err != nil {
+		return "", err
+	}
+	switch format {
+	case batchFormatTimetable, batchFormatGCalCSV, batchFormatOutlookCSV:
+		return "", fmt.Errorf("%s input is not supported by template create (use csv, json, yaml, or toml)", format)
 	}
+	return format, nil
 }
 
-func loadTemplateRecords(path, format string) ([]map[string]string, error) {
+func loadTemplateRecords(path string, format batchFormat) ([]map[string]string, error) {
 	switch format {
-	case "csv":
+	case batchFormatCSV:
 		return loadTemplateFromCSV(path)
-	case "json":
-		return loadTemplateFromJSON(path)
+	case batchFormatJSON, batchFormatYAML, batchFormatTOML:
+		return loadTemplateFromRows(path, format)
 	default:
 		return nil, fmt.Errorf("unknown format %q", format)
 	}
@@ -3022,14 +13634,20 @@ func loadTemplateFromCSV(path string) ([]map[string]string, error) {
 	return records, nil
 }
 
-func loadTemplateFromJSON(path string) ([]map[string]string, error) {
+// loadTemplateFromRows reads a JSON, YAML or TOML data file with the batch
+// decoder and flattens each row to strings; list values become
+// comma-separated.
+func loadTemplateFromRows(path string, format batchFormat) ([]map[string]string, error) {
 	data, err := os.ReadFile(filepath.Clean(path))
 	if err != nil {
 		return nil, err
 	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, nil
+	}
 
-	var raw []map[string]interface{}
-	if err := json.Unmarshal(data, &raw); err != nil {
+	raw, err := decodeRowMaps(data, format)
+	if err != nil {
 		return nil, err
 	}
 
@@ -3038,7 +13656,10 @@ func loadTemplateFromJSON(path string) ([]map[string]string, error) {
 		record := make(map[string]string, len(item))
 		empty := true
 		for k, v := range item {
-			value := strings.TrimSpace(fmt.Sprintf("%v", v))
+			value := valueAsString(v)
+			if list, ok := v.([]interface{}); ok {
+				value = strings.Join(valueAsStringSlice(list), ",")
+			}
 			if value != "" {
 				empty = false
 			}
@@ -3103,10 +13724,15 @@ func normalizeValuesForTemplate(values map[string]string, tmpl *tpl.Template, dd
 	normalizeEndTimeFromDuration(values, startField, endField, durationField, tzField, durationDefault)
 }
 
-func buildTemplateCalendar(ev *calendar.Event) *calendar.Calendar {
+// buildTemplateCalendar wraps the events of one template run; the first is
+// the main event and names the calendar.
+func buildTemplateCalendar(events ...*calendar.Event) *calendar.Calendar {
 	cal := calendar.NewCalendar()
 	cal.IncludeVTZ = true
-	cal.AddEvent(ev)
+	for _, e := range events {
+		cal.AddEvent(e)
+	}
+	ev := events[0]
 	cal.Name = ev.Summary
 	if tz := firstNonEmpty(ev.StartTZ, ev.EndTZ); strings.TrimSpace(tz) != "" {
 		cal.SetDefaultTimezone(tz)
@@ -3140,7 +13766,7 @@ func deriveTemplateFilename(tm *tpl.TemplateManager, templateName string, values
 	}
 
 	if !ev.StartTime.IsZero() {
-		base := slugify(ev.Summary)
+		base := utils.SlugOrHash(ev.Summary, "") // the date follows
 		if base == "" {
 			base = slugify(templateName)
 		}
@@ -3165,7 +13791,7 @@ func ensureICSExtension(name string) string {
 
 func ensureUniquePath(path string) string {
 	clean := filepath.Clean(path)
-	if _, err := os.Stat(clean); errors.Is(err, os.ErrNotExist) {
+	if _, err := outputFS.Stat(clean); errors.Is(err, os.ErrNotExist) {
 		return clean
 	}
 
@@ -3179,7 +13805,7 @@ func ensureUniquePath(path string) string {
 
 	for i := 2; ; i++ {
 		candidate := filepath.Join(dir, fmt.Sprintf("%s-%d%s", name, i, ext))
-		if _, err := os.Stat(candidate); errors.Is(err, os.ErrNotExist) {
+		if _, err := outputFS.Stat(candidate); errors.Is(err, os.ErrNotExist) {
 			return candidate
 		}
 	}
@@ -3299,10 +13925,103 @@ func newLocaleCmd() *cobra.Command {
 		Short: "List available locales",
 		RunE:  runLocaleList,
 	})
+	root.AddCommand(newLocaleLintCmd())
 
 	return root
 }
 
+func newLocaleLintCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint [code...]",
+		Short: "Report untranslated and inconsistent keys in locale files",
+		Long: `Compare every locale (embedded and on disk) with English and report, per file:
+keys that are missing (those messages are shown in English), keys English does
+not define, and translations whose placeholders (%s, %d, ...) differ from English.
+Missing keys and placeholder mismatches fail the command.`,
+		Example: `  tempus locale lint
+  tempus locale lint es ga
+  tempus locale lint --format sarif > locales.sarif`,
+		RunE: runLocaleLint,
+	}
+	cmd.Flags().String("format", "text", "Output format: text, json or sarif")
+	return cmd
+}
+
+func runLocaleLint(cmd *cobra.Command, args []string) error {
+	format, err := lintFormat(cmd)
+	if err != nil {
+		return err
+	}
+	catalogs, loadErr := i18n.Catalogs()
+	if len(args) > 0 {
+		catalogs = slices.DeleteFunc(catalogs, func(c i18n.Catalog) bool {
+			return !slices.ContainsFunc(args, func(code string) bool { return strings.EqualFold(code, c.Code) })
+		})
+		if len(catalogs) == 0 && loadErr == nil {
+			return fmt.Errorf("no locale files for %s", strings.Join(args, ", "))
+		}
+	}
+
+	var all []diag.Warning
+	failed := 0
+	for _, c := range catalogs {
+		findings := localeFindings(c)
+		all = append(all, findings...)
+		if diag.HasErrors(findings) {
+			failed++
+		}
+		if format != "text" {
+			continue
+		}
+		if len(findings) > 0 {
+			diag.Render(cmd.OutOrStdout(), findings)
+		} else {
+			printOK("%s\n", ui.T("locale_lint_passed", c.Source(), len(c.Messages)))
+		}
+	}
+
+	if err := writeLintReport(cmd.OutOrStdout(), format, all); err != nil {
+		return err
+	}
+	if loadErr != nil {
+		return loadErr
+	}
+	if failed > 0 {
+		return fmt.Errorf("locale lint failed: %d error(s) in %d file(s)", diag.Count(all, diag.SeverityError), failed)
+	}
+	return nil
+}
+
+// localeFindings turns the issues of one catalog into diagnostics.
+func localeFindings(c i18n.Catalog) []diag.Warning {
+	var findings []diag.Warning
+	for _, issue := range i18n.Lint(c.Messages) {
+		w := diag.Warning{Severity: diag.SeverityError, File: c.Source()}
+		switch issue.Kind {
+		case i18n.IssueMissing:
+			w.Code = diag.CodeUntranslated
+			w.Message = ui.T("locale_lint_missing", issue.Key, issue.English)
+			w.Suggestion = ui.T("locale_lint_missing_hint")
+		case i18n.IssueUnknown:
+			w.Code, w.Severity = diag.CodeUnknownKey, diag.SeverityWarning
+			w.Message = ui.T("locale_lint_unknown", issue.Key)
+			w.Suggestion = ui.T("locale_lint_unknown_hint")
+		case i18n.IssuePlaceholders:
+			w.Code = diag.CodePlaceholders
+			w.Message = ui.T("locale_lint_placeholders", issue.Key, formatVerbs(issue.Got), formatVerbs(issue.Want))
+		}
+		findings = append(findings, w)
+	}
+	return findings
+}
+
+func formatVerbs(verbs []string) string {
+	if len(verbs) == 0 {
+		return "-"
+	}
+	return strings.Join(verbs, " ")
+}
+
 func runLocaleList(_ *cobra.Command, _ []string) error {
 	locales := i18n.Locales()
 	if len(locales) == 0 {
@@ -3327,19 +14046,9 @@ func runLocaleList(_ *cobra.Command, _ []string) error {
 // ---------- helpers ----------
 
 func loadTemplateManager(cmd *cobra.Command) (*tpl.TemplateManager, *i18n.Translator, error) {
-	cfg, _ := config.Load() // proceed with defaults if it fails
-	langFlag, _ := cmd.Root().Flags().GetString("language")
 	templatesDirFlag, _ := cmd.Flags().GetString("templates-dir")
 
-	cfgLang := ""
-	if cfg != nil {
-		if v, err := cfg.Get("language"); err == nil {
-			cfgLang = v
-		}
-	}
-	lang := firstNonEmpty(langFlag, cfgLang, "en")
-
-	tr, err := newTranslator(lang)
+	tr, err := newTranslator(outputLanguage(cmd))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -3354,6 +14063,35 @@ func loadTemplateManager(cmd *cobra.Command) (*tpl.TemplateManager, *i18n.Transl
 	return tm, tr, nil
 }
 
+// outputLanguage resolves the language for generated text: --language, then
+// the configured language, then English.
+func outputLanguage(cmd *cobra.Command) string {
+	cfg, _ := config.Current() // proceed with defaults if it fails
+	langFlag, _ := cmd.Root().Flags().GetString("language")
+
+	cfgLang := ""
+	if cfg != nil {
+		if v, err := cfg.Get("language"); err == nil {
+			cfgLang = v
+		}
+	}
+	return firstNonEmpty(langFlag, cfgLang, "en")
+}
+
+// setCategoryTranslation makes cal write its categories in lang; an empty lang
+// leaves the canonical (English) names.
+func setCategoryTranslation(cal *calendar.Calendar, lang string) error {
+	if lang == "" {
+		return nil
+	}
+	tr, err := newTranslator(lang)
+	if err != nil {
+		return err
+	}
+	cal.TranslateCategory = tr.Category
+	return nil
+}
+
 // Build translator with graceful fallback to "en"
 func newTranslator(lang string) (*i18n.Translator, error) {
 	tr, err := i18n.NewTranslator(lang)
@@ -3402,47 +14140,100 @@ func slugify(s string) string {
 	return utils.Slugify(s)
 }
 
+// fileSlug names a file after s (a summary or a person): its slug, or for
+// text with nothing to spell in ASCII (会議) the date, when there is one, and
+// a short hash of s, so two such names don't collide. Blank s gives the
+// date, or "event" without one.
+func fileSlug(s string, date time.Time) string {
+	prefix := ""
+	if !date.IsZero() {
+		prefix = date.Format(constants.DateFormatISO)
+	}
+	return firstNonEmpty(utils.SlugOrHash(s, prefix), "event")
+}
+
 func promptInput(prompt, defaultValue string) string {
 	return prompts.Input(prompt, defaultValue)
 }
 
+// maxFieldAttempts bounds re-asking for an invalid value, so a closed stdin
+// (which keeps answering with the default) cannot loop forever.
+const maxFieldAttempts = 3
+
+// promptTemplateField asks for one field and re-asks until the answer fits the
+// field's type. Enum fields are offered as a numbered choice.
+func promptTemplateField(f tpl.Field) (string, error) {
+	if f.Kind() == "enum" {
+		return promptEnumField(f)
+	}
+	var err error
+	for attempt := 0; attempt < maxFieldAttempts; attempt++ {
+		v := strings.TrimSpace(promptInput(labelForField(f), f.Default))
+		if v == "" {
+			if f.Required {
+				return "", fmt.Errorf("field %q is required", f.Key)
+			}
+			return "", nil
+		}
+		var checked string
+		if checked, err = f.CheckValue(v); err == nil {
+			return checked, nil
+		}
+		output.Warn(os.Stdout, "%v\n", err)
+	}
+	return "", fmt.Errorf("field %q: %w", f.Key, err)
+}
+
+func promptEnumField(f tpl.Field) (string, error) {
+	idx, choice := prompts.Choose(labelForField(f)+":", f.Options)
+	switch {
+	case idx >= 0:
+		return choice, nil
+	case f.Default != "":
+		return f.CheckValue(f.Default)
+	case f.Required:
+		return "", fmt.Errorf("field %q is required", f.Key)
+	}
+	return "", nil
+}
+
 func promptAlarmField(label, defaultValue string) string {
 	fmt.Printf("\n%s\n", label)
 	existing := calendar.SplitAlarmInput(defaultValue)
 	if len(existing) > 0 {
-		fmt.Println("Recordatorios sugeridos:")
+		fmt.Println(ui.T("alarm_prompt_suggested"))
 		for i, spec := range existing {
 			fmt.Printf("  %d) %s\n", i+1, spec)
 		}
-		keep := strings.ToLower(strings.TrimSpace(promptInput("Pulsa Enter para mantenerlos o escribe 'n' para cambiarlos", "")))
-		if keep == "" || keep == "s" || keep == "si" {
+		// Anything but a "no" (n, no, não, níl) keeps the suggestions.
+		keep := strings.ToLower(strings.TrimSpace(promptInput(ui.T("alarm_prompt_keep"), "")))
+		if !strings.HasPrefix(keep, "n") {
 			return strings.Join(existing, "\n")
 		}
 		fmt.Println("")
 	}
 
-	fmt.Println("Añade hasta 4 recordatorios. Usa formatos como -15m, +10m, 2025-03-01 09:15 o trigger=-15m,description=Texto.")
-	fmt.Println("Escribe '?' para ver ejemplos o deja vacío para terminar.")
+	fmt.Println(ui.T("alarm_prompt_intro"))
+	fmt.Println(ui.T("alarm_prompt_help_hint"))
 
 	specs := make([]string, 0, 4)
 	for len(specs) < 4 {
-		prompt := fmt.Sprintf("Recordatorio #%d (-15m, +10m, trigger=..., ? para ayuda)", len(specs)+1)
-		input := strings.TrimSpace(promptInput(prompt, ""))
+		input := strings.TrimSpace(promptInput(ui.T("alarm_prompt_item", len(specs)+1), ""))
 		if input == "" {
 			break
 		}
 		if input == "?" {
-			fmt.Println("Ejemplos:")
-			fmt.Println("  -15m                 -> 15 minutos antes")
-			fmt.Println("  +5m                  -> 5 minutos después")
-			fmt.Println("  trigger=-30m,description=Buscar taxi")
+			fmt.Println(ui.T("alarm_prompt_examples"))
+			fmt.Printf("  -15m                 -> %s\n", ui.T("alarm_prompt_example_before"))
+			fmt.Printf("  +5m                  -> %s\n", ui.T("alarm_prompt_example_after"))
+			fmt.Printf("  trigger=-30m,description=%s\n", ui.T("alarm_prompt_example_text"))
 			fmt.Println("  trigger=2025-03-01 09:15,description=Check-in")
 			continue
 		}
 
 		spec := input
 		if !strings.Contains(spec, "=") {
-			desc := strings.TrimSpace(promptInput("Descripción opcional (Enter para usar la genérica)", ""))
+			desc := strings.TrimSpace(promptInput(ui.T("alarm_prompt_description"), ""))
 			if desc != "" {
 				spec = fmt.Sprintf("trigger=%s,description=%s", input, desc)
 			}
@@ -3632,6 +14423,13 @@ func parseExDateValues(values []string, tz string, allDay bool) ([]time.Time, er
 		if normalized == "" {
 			continue
 		}
+		if t, _, ok := normalizer.ParseTimestamp(normalized); ok && !allDay && strings.TrimSpace(tz) == "" {
+			out = append(out, t.UTC())
+			continue
+		}
+		if rewritten, _, ok := normalizer.RewriteTimestamp(normalized, tz, allDay); ok {
+			normalized = rewritten
+		}
 		normalized = strings.ReplaceAll(normalized, "T", " ")
 
 		datePart, timePart := splitDateTime(normalized)
@@ -3680,9 +14478,10 @@ func newTimezoneCmd() *cobra.Command {
 		RunE:  runTZList,
 	}
 	listCmd.Flags().String("search", "", "Filter by text (matches IANA, display name, or country)")
-	listCmd.Flags().String("country", "", "Filter by country (case-insensitive contains)")
-	listCmd.Flags().String("region", "", "Filter by region (supported: europe)")
+	listCmd.Flags().String("country", "", "Filter by ISO country code (e.g. ES) or country name (contains)")
+	listCmd.Flags().String("region", "", "Filter by region: "+strings.Join(tzpkg.RegionNames(), ", "))
 	listCmd.Flags().Bool("all", false, "Show all known zones (ignores region)")
+	_ = listCmd.RegisterFlagCompletionFunc("region", cobra.FixedCompletions(tzpkg.RegionNames(), cobra.ShellCompDirectiveNoFileComp))
 
 	// timezone info <name|IANA>
 	infoCmd := &cobra.Command{
@@ -3692,10 +14491,87 @@ func newTimezoneCmd() *cobra.Command {
 		RunE:  runTZInfo,
 	}
 
-	root.AddCommand(listCmd, infoCmd)
+	// timezone refresh
+	refreshCmd := &cobra.Command{
+		Use:   "refresh",
+		Short: "Download the latest IANA timezone database",
+		Long: `Download an up-to-date IANA timezone database into the config directory.
+Tempus prefers it over the system and built-in databases from the next run on,
+so new DST rules apply without waiting for an OS or tempus release. A ZONEINFO
+environment variable still takes precedence.
+
+The database is Go's zoneinfo.zip; --version-url names the release it was built
+from, and the download is skipped when that release is already installed.`,
+		Example: `  tempus timezone refresh
+  tempus timezone refresh --force
+  tempus timezone refresh --remove   # go back to the system database`,
+		Args: cobra.NoArgs,
+		RunE: runTZRefresh,
+	}
+	refreshCmd.Flags().String("url", tzpkg.DefaultTZDataURL, "Where to download zoneinfo.zip from")
+	refreshCmd.Flags().String("version-url", tzpkg.DefaultTZDataVersionURL, "Script naming the database release (DATA=2025b); empty to skip the version check")
+	refreshCmd.Flags().Duration("timeout", time.Minute, "Give up on a download after this long")
+	refreshCmd.Flags().Bool("force", false, "Download even when the release is already installed")
+	refreshCmd.Flags().Bool("remove", false, "Delete the downloaded database")
+
+	root.AddCommand(listCmd, infoCmd, refreshCmd)
 	return root
 }
 
+func runTZRefresh(cmd *cobra.Command, _ []string) error {
+	zipURL, _ := cmd.Flags().GetString("url")
+	versionURL, _ := cmd.Flags().GetString("version-url")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	force, _ := cmd.Flags().GetBool("force")
+	remove, _ := cmd.Flags().GetBool("remove")
+
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to find the config directory: %w", err)
+	}
+	if remove {
+		removed, err := tzpkg.RemoveTZData(dir)
+		if err != nil {
+			return fmt.Errorf("failed to remove the timezone database: %w", err)
+		}
+		if !removed {
+			fmt.Println("No downloaded timezone database to remove.")
+			return nil
+		}
+		printOK("Removed the downloaded timezone database\n")
+		return nil
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	release := ""
+	if strings.TrimSpace(versionURL) != "" {
+		script, err := downloadFeed(ctx, versionURL, timeout)
+		if err != nil {
+			return err
+		}
+		if release = tzpkg.ParseTZDataVersion(script); release == "" {
+			return fmt.Errorf("no DATA=<release> line in %s (use --version-url \"\" to skip the version check)", versionURL)
+		}
+		if installed := tzpkg.InstalledTZDataVersion(dir); installed == release && !force {
+			fmt.Printf("Timezone database %s is already up to date (use --force to download it again).\n", release)
+			return nil
+		}
+	}
+
+	data, err := downloadFeed(ctx, zipURL, timeout)
+	if err != nil {
+		return err
+	}
+	if err := tzpkg.InstallTZData(dir, []byte(data), release); err != nil {
+		return fmt.Errorf("%s: %w", zipURL, err)
+	}
+	printOK("Installed timezone database %s to %s\n", firstNonEmpty(release, "(unknown release)"), tzpkg.TZDataPath(dir))
+	return nil
+}
+
 var reParen = regexp.MustCompile(`\s*\([^(]*\)\s*$`)
 
 // cleanDisplay removes a trailing " (…)" from DisplayName if present.
@@ -3712,17 +14588,19 @@ func runTZList(cmd *cobra.Command, _ []string) error {
 	tm := tzpkg.NewTimezoneManager()
 
 	var zones []*tzpkg.TimezoneInfo
-	switch {
-	case showAll:
-		zones = tm.ListTimezones()
-	case strings.EqualFold(strings.TrimSpace(region), "europe"):
-		zones = tm.GetEuropeanTimezones()
-	default:
+	if showAll || strings.TrimSpace(region) == "" {
 		zones = tm.ListTimezones()
+	} else {
+		var err error
+		if zones, err = tm.GetRegionTimezones(region); err != nil {
+			return err
+		}
 	}
 
 	search = strings.ToLower(strings.TrimSpace(search))
 	country = strings.ToLower(strings.TrimSpace(country))
+	// A known ISO code such as "es" means the country, not names containing it.
+	byCode := len(country) == 2 && tzpkg.CountryName(country) != strings.ToUpper(country)
 
 	filtered := make([]*tzpkg.TimezoneInfo, 0, len(zones))
 	for _, z := range zones {
@@ -3735,7 +14613,9 @@ func runTZList(cmd *cobra.Command, _ []string) error {
 			}
 		}
 		if match && country != "" {
-			if !strings.Contains(strings.ToLower(z.Country), country) {
+			if byCode {
+				match = slices.ContainsFunc(z.CountryCodes, func(cc string) bool { return strings.EqualFold(cc, country) })
+			} else if !strings.Contains(strings.ToLower(z.Country), country) {
 				match = false
 			}
 		}
@@ -3744,6 +14624,14 @@ func runTZList(cmd *cobra.Command, _ []string) error {
 		}
 	}
 
+	if jsonReport(cmd) {
+		list := make([]zoneJSON, 0, len(filtered))
+		for _, z := range filtered {
+			list = append(list, newZoneJSON(z))
+		}
+		return printJSON(cmd.OutOrStdout(), list)
+	}
+
 	// nicer columns: separate Display & Country
 	fmt.Printf("%-32s  %-7s  %-3s  %-28s  %s\n", "IANA", "Offset", "DST", "Display", "Country")
 	for _, z := range filtered {
@@ -3758,7 +14646,29 @@ func runTZList(cmd *cobra.Command, _ []string) error {
 	return nil
 }
 
-func runTZInfo(_ *cobra.Command, args []string) error {
+// zoneJSON is a timezone as printed by --output-format json.
+type zoneJSON struct {
+	IANA         string   `json:"iana"`
+	Display      string   `json:"display"`
+	Country      string   `json:"country"`
+	CountryCodes []string `json:"country_codes,omitempty"`
+	Region       string   `json:"region,omitempty"`
+	Comment      string   `json:"comment,omitempty"`
+	Offset       string   `json:"offset"`
+	DST          bool     `json:"dst"`
+	Now          string   `json:"now,omitempty"`
+	TZData       string   `json:"tzdata,omitempty"`
+}
+
+func newZoneJSON(z *tzpkg.TimezoneInfo) zoneJSON {
+	return zoneJSON{
+		IANA: z.IANA, Display: cleanDisplay(z.DisplayName), Country: z.Country,
+		CountryCodes: z.CountryCodes, Region: z.Region, Comment: z.Comment,
+		Offset: z.Offset, DST: z.DST,
+	}
+}
+
+func runTZInfo(cmd *cobra.Command, args []string) error {
 	query := strings.TrimSpace(strings.Join(args, " "))
 	if query == "" {
 		return fmt.Errorf("please provide a timezone name or IANA identifier")
@@ -3766,39 +14676,47 @@ func runTZInfo(_ *cobra.Command, args []string) error {
 
 	tm := tzpkg.NewTimezoneManager()
 
-	// Try exact/alias/system
-	zone, err := tm.GetTimezone(query)
-	if err != nil {
-		// Try city→IANA mapping
-		if mapped := cityToIANA(query); mapped != "" {
-			if z2, err2 := tm.GetTimezone(mapped); err2 == nil {
-				zone = z2
+	// Exact names and aliases first, then a fuzzy search ("nw york").
+	zone, ok := tm.Resolve(query)
+	if !ok {
+		sugs := tm.Search(query, 5)
+		if jsonReport(cmd) {
+			names := make([]string, 0, len(sugs))
+			for _, s := range sugs {
+				names = append(names, s.Zone.IANA)
 			}
+			if len(names) == 0 {
+				return fmt.Errorf("timezone %q not found", query)
+			}
+			return fmt.Errorf("timezone %q not found (did you mean %s?)", query, strings.Join(names, ", "))
 		}
-	}
-
-	if zone == nil {
-		// Last-ditch: suggest by fuzzy search
-		sugs := tm.SuggestTimezone(query)
 		if len(sugs) == 0 {
 			fmt.Println("Timezone not found.")
 			return nil
 		}
 		fmt.Println("Timezone not found. Did you mean:")
 		for _, s := range sugs {
-			fmt.Printf("  - %s (%s) [%s]\n", cleanDisplay(s.DisplayName), s.Country, s.IANA)
+			fmt.Printf("  - %s (%s) [%s]\n", s.Match, s.Zone.Country, s.Zone.IANA)
 		}
 		return nil
 	}
 
 	loc, err := time.LoadLocation(zone.IANA)
+	if jsonReport(cmd) {
+		info := newZoneJSON(zone)
+		if err == nil {
+			info.Now = appClock.Now().In(loc).Format(time.RFC3339)
+		}
+		info.TZData = tzpkg.CurrentTZData().String()
+		return printJSON(cmd.OutOrStdout(), info)
+	}
 	if err != nil {
 		// Still show info without current local time
 		printZoneInfo(zone, "", "")
 		return nil
 	}
 
-	now := time.Now().In(loc)
+	now := appClock.Now().In(loc)
 	printZoneInfo(zone, now.Format(constants.DateTimeFormatISOSeconds), now.Format(constants.DateTimeFormatRFC1123))
 	return nil
 }
@@ -3808,6 +14726,15 @@ func printZoneInfo(z *tzpkg.TimezoneInfo, local1, local2 string) {
 	fmt.Printf("IANA:       %s\n", z.IANA)
 	fmt.Printf("Display:    %s\n", name)
 	fmt.Printf("Country:    %s\n", z.Country)
+	if len(z.CountryCodes) > 0 {
+		fmt.Printf("Codes:      %s\n", strings.Join(z.CountryCodes, ", "))
+	}
+	if z.Region != "" {
+		fmt.Printf("Region:     %s\n", z.Region)
+	}
+	if z.Comment != "" {
+		fmt.Printf("Covers:     %s\n", z.Comment)
+	}
 	fmt.Printf("Offset:     %s\n", z.Offset)
 	if z.DST {
 		fmt.Printf("DST:        yes\n")
@@ -3820,58 +14747,7 @@ func printZoneInfo(z *tzpkg.TimezoneInfo, local1, local2 string) {
 	if local2 != "" {
 		fmt.Printf("Readable:   %s\n", local2)
 	}
-}
-
-// Lightweight city → IANA mapping for friendlier queries.
-func cityToIANA(s string) string {
-	x := strings.ToLower(strings.TrimSpace(s))
-
-	// Spain / territories
-	if x == "melilla" || x == "ceuta" {
-		return "Africa/Ceuta"
-	}
-	if x == "canarias" || x == "gran canaria" || x == "tenerife" || x == "las palmas" {
-		return "Atlantic/Canary"
-	}
-
-	// Brazil
-	switch x {
-	case "pelotas", "porto alegre", "porto-alegre", "florianopolis", "florianópolis":
-		return "America/Sao_Paulo"
-	case "campo grande", "campo-grande", "ponta pora", "ponta-porã", "ponta-pora", "dourados":
-		return "America/Campo_Grande"
-	case "cuiaba", "cuiabá":
-		return "America/Cuiaba"
-	case "manaus":
-		return "America/Manaus"
-	case "recife":
-		return "America/Recife"
-	case "belem", "belém":
-		return "America/Belem"
-	case "fortaleza":
-		return "America/Fortaleza"
-	case "salvador":
-		return "America/Bahia"
-	case "rio", "rio de janeiro", "rio-de-janeiro", "niteroi", "niterói":
-		return "America/Sao_Paulo"
-	case "sao paulo", "são paulo", "sao-paulo", "campinas":
-		return "America/Sao_Paulo"
-	}
-
-	// Ireland / UK
-	if x == "dublin" {
-		return "Europe/Dublin"
-	}
-	if x == "london" {
-		return "Europe/London"
-	}
-
-	// Spain common cities
-	if x == "madrid" || x == "barcelona" || x == "valencia" || x == "bilbao" || x == "sevilla" {
-		return "Europe/Madrid"
-	}
-
-	return ""
+	fmt.Printf("Database:   tzdata %s\n", tzpkg.CurrentTZData())
 }
 
 // ------------------------------
@@ -3879,15 +14755,13 @@ func cityToIANA(s string) string {
 // ------------------------------
 
 func printOK(format string, a ...interface{}) {
-	// Leading checkmark for success
-	msg := fmt.Sprintf(format, a...)
-	fmt.Printf("✅ %s", msg)
+	// Leading checkmark for success (hidden with --quiet)
+	output.OK(os.Stdout, format, a...)
 }
 
 func printErr(format string, a ...interface{}) {
 	// Leading cross mark for errors
-	msg := fmt.Sprintf(format, a...)
-	fmt.Printf("❌ %s", msg)
+	output.Error(os.Stdout, format, a...)
 }
 
 func atoiSafe(s string) int {
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateWithAirportTimezones(t *testing.T) {
+	dir := setupCommandTest(t)
+	output := filepath.Join(dir, "flight.ics")
+
+	runRootStdout(t, "create", "IB6251", "--from-airport", "mad", "--to-airport", "JFK",
+		"--start", "2025-12-26 11:50", "--end", "2025-12-26 14:35", "-o", output)
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	ics := string(data)
+	for _, want := range []string{
+		"DTSTART;TZID=Europe/Madrid:20251226T115000",
+		"DTEND;TZID=America/New_York:20251226T143500",
+		"LOCATION:Madrid Barajas (MAD)",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("output missing %q:\n%s", want, ics)
+		}
+	}
+}
+
+func TestCreateAirportKeepsExplicitTimezone(t *testing.T) {
+	dir := setupCommandTest(t)
+	output := filepath.Join(dir, "flight.ics")
+
+	runRootStdout(t, "create", "Charter", "--from-airport", "DUB", "--start-tz", "Europe/London", "--location", "Gate 402",
+		"--start", "2025-12-26 09:00", "--duration", "2h", "-o", output)
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	ics := string(data)
+	if !strings.Contains(ics, "DTSTART;TZID=Europe/London:20251226T090000") || !strings.Contains(ics, "LOCATION:Gate 402") {
+		t.Errorf("explicit --start-tz and --location should win:\n%s", ics)
+	}
+}
+
+func TestCreateRejectsUnknownAirport(t *testing.T) {
+	dir := setupCommandTest(t)
+	err := runRootErr(t, "create", "Flight", "--from-airport", "ZZZ", "--start", "2025-12-26 09:00", "-o", filepath.Join(dir, "x.ics"))
+	if err == nil || !strings.Contains(err.Error(), "unknown airport") {
+		t.Errorf("expected an unknown airport error, got %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"tempus/internal/calendar"
 	"tempus/internal/testutil"
 	"testing"
 )
@@ -57,3 +58,30 @@ func TestCreateSupportsAlarms(t *testing.T) {
 		t.Fatalf("expected custom description for absolute alarm:\n%s", ics)
 	}
 }
+
+func TestCreateAlarmDirectionConfigAndEmailRecipients(t *testing.T) {
+	dir := setupCommandTest(t)
+	t.Cleanup(func() { calendar.SetDefaultAlarmDirection(false) })
+	if err := os.MkdirAll(filepath.Join(dir, "tempus"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tempus", "config.yaml"), []byte("alarm_direction: after\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "review.ics")
+	err := runRootErr(t, "create", "Review", "-s", "2025-03-01 10:00", "-d", "1h", "--start-tz", testutil.TZEuropeMadrid,
+		"--alarm", "15m", "--alarm", "trigger=-1d,to=ana@example.com;ben@example.com", "-o", out)
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ics := string(data)
+	for _, want := range []string{"TRIGGER:PT15M", "TRIGGER:-P1D", "ACTION:EMAIL", "ATTENDEE:mailto:ana@example.com", "ATTENDEE:mailto:ben@example.com"} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("calendar lacks %s:\n%s", want, ics)
+		}
+	}
+}
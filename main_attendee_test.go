@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const attendeeCSV = `summary,start,duration,attendees,organizer
+Kickoff,2025-05-05 09:00,1h,Ana <ana@example.com>;luis@example.com,boss@example.com
+1:1 Ana,2025-05-12 10:00,30m,ana@example.com,boss@example.com
+1:1 Luis,2025-06-02 10:00,30m,Luis@Example.com,boss@example.com
+All hands,2025-06-03 16:00,1h,,
+`
+
+func TestBatchPerAttendee(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "team.csv")
+	if err := os.WriteFile(input, []byte(attendeeCSV), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "out", "team.ics")
+	out := runRootStdout(t, "batch", "-i", input, "-o", output, "--attendee-file", "{{attendee}}-{{month}}.ics")
+
+	files := map[string][]string{
+		"ana-2025-05.ics":  {"Kickoff", "1:1 Ana"},
+		"luis-2025-05.ics": {"Kickoff"},
+		"luis-2025-06.ics": {"1:1 Luis"},
+	}
+	for name, summaries := range files {
+		data, err := os.ReadFile(filepath.Join(dir, "out", name))
+		if err != nil {
+			t.Errorf("expected %s: %v\n%s", name, err, out)
+			continue
+		}
+		ics := string(data)
+		if n := strings.Count(ics, "BEGIN:VEVENT"); n != len(summaries) {
+			t.Errorf("%s has %d events, want %d:\n%s", name, n, len(summaries), ics)
+		}
+		for _, s := range summaries {
+			if !strings.Contains(ics, "SUMMARY:"+s+"\r\n") {
+				t.Errorf("%s is missing %q", name, s)
+			}
+		}
+		if strings.Contains(ics, "All hands") {
+			t.Errorf("%s: rows without attendees belong to nobody's file", name)
+		}
+	}
+	if _, err := os.Stat(output); err != nil {
+		t.Errorf("the combined calendar should still be written: %v", err)
+	}
+	kickoff := func(name string) string {
+		data, _ := os.ReadFile(filepath.Join(dir, "out", name))
+		for _, line := range strings.Split(string(data), "\r\n") {
+			if strings.HasPrefix(line, "UID:") {
+				return line
+			}
+		}
+		return ""
+	}
+	if a, l := kickoff("ana-2025-05.ics"), kickoff("luis-2025-05.ics"); a == "" || a != l {
+		t.Errorf("the same meeting should keep its UID in every attendee's file: %q vs %q", a, l)
+	}
+
+	// The organizer column, default file names and a dry run.
+	out = runRootStdout(t, "batch", "-i", input, "-o", output, "--per-attendee", "--attendee-column", "organizer", "--dry-run")
+	if !strings.Contains(out, "team-boss.ics") {
+		t.Errorf("dry run should list the organizer's file:\n%s", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out", "team-boss.ics")); !os.IsNotExist(err) {
+		t.Error("a dry run should not write attendee calendars")
+	}
+}
+
+func TestBatchPerAttendeeErrors(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "team.csv")
+	if err := os.WriteFile(input, []byte(attendeeCSV), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "team.ics")
+	for name, args := range map[string][]string{
+		"no person":   {"--attendee-file", "{{month}}.ics"},
+		"unknown":     {"--attendee-file", "{{attendee}}-{{week}}.ics"},
+		"bad column":  {"--per-attendee", "--attendee-column", "people"},
+		"append":      {"--per-attendee", "--append"},
+		"shared file": {"--attendee-file", "{{attendee}}.ics", "--attendee-column", "attendees"},
+	} {
+		if name == "shared file" {
+			// Two addresses with the same local part land in one file.
+			csv := "summary,start,duration,attendees\nA,2025-05-05 09:00,1h,ana@one.example;ana@two.example\n"
+			if err := os.WriteFile(input, []byte(csv), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := runRootErr(t, append([]string{"batch", "-i", input, "-o", output}, args...)...); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
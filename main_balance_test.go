@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"tempus/internal/calendar"
+	"tempus/internal/clock"
+	"tempus/internal/diag"
+)
+
+func TestPlanBalanceMovesLowestPriorityWithBuffers(t *testing.T) {
+	setClock(clock.Fixed(time.Date(2025, 6, 2, 7, 0, 0, 0, time.UTC)))
+	t.Cleanup(func() { setClock(nil) })
+
+	at := func(uid string, day, hour, priority int) calendar.Event {
+		start := time.Date(2025, 6, day, hour, 0, 0, 0, time.UTC)
+		return calendar.Event{UID: uid, Summary: uid, StartTime: start, EndTime: start.Add(30 * time.Minute), Priority: priority}
+	}
+	prep := at(calendar.StableUID("review", "prep"), 2, 15, 0)
+	events := []calendar.Event{
+		at("plan", 2, 9, 1),
+		at("review", 2, 16, 9), // lowest priority, moves with its prep buffer
+		prep,
+		at("sync", 2, 11, 0),
+		at("tue", 3, 9, 0),
+		at("tue2", 3, 10, 0),
+	}
+	moves, stuck := planBalance(events, 2, dayFilter{})
+	if len(stuck) != 0 {
+		t.Errorf("unexpected overloaded days: %v", stuck)
+	}
+	// Tuesday is full and Sunday is in the past, so review goes to
+	// Wednesday; its buffer goes along, which is enough for Monday.
+	if len(moves) != 1 {
+		t.Fatalf("expected 1 move, got %+v", moves)
+	}
+	if m := moves[0]; m.event != 1 || m.days != 2 || len(m.buffers) != 1 || m.buffers[0] != 2 {
+		t.Errorf("move = %+v, want review +2 days with its buffer", m)
+	}
+
+	if _, stuck := planBalance(events, 0, dayFilter{}); len(stuck) == 0 {
+		t.Error("a limit of 0 leaves no room anywhere, days should stay overloaded")
+	}
+}
+
+func TestBatchAutoBalance(t *testing.T) {
+	dir := setupCommandTest(t)
+	setClock(clock.Fixed(time.Date(2025, 6, 2, 7, 0, 0, 0, time.UTC)))
+	t.Cleanup(func() { setClock(nil) })
+
+	input := filepath.Join(dir, "week.csv")
+	csv := "summary,start,duration,priority\n" +
+		"Plan,2025-06-02 09:00,1h,1\n" +
+		"Inbox,2025-06-02 11:00,30m,9\n" +
+		"Call,2025-06-02 14:00,30m,\n" +
+		"Errands,2025-06-02 16:00,1h,9\n" +
+		"Gym,2025-06-03 09:00,1h,\n" +
+		"Lunch,2025-06-03 13:00,1h,\n"
+	if err := os.WriteFile(input, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "week.ics")
+
+	report := runRootStdout(t, "batch", "--input", input, "--output", out, "--max-events-per-day", "2",
+		"--auto-balance", "--dry-run", "--output-format", "json")
+	var summary batchSummary
+	if err := json.Unmarshal([]byte(report), &summary); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, report)
+	}
+	var proposals []string
+	for _, w := range summary.Warnings {
+		if w.Code == diag.CodeBalance {
+			proposals = append(proposals, w.Message)
+		}
+	}
+	if len(proposals) != 2 || !strings.Contains(proposals[0], "Errands") || !strings.Contains(proposals[1], "Inbox") {
+		t.Errorf("dry-run should propose moving Errands then Inbox, got %q", proposals)
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Error("--dry-run must not write the calendar")
+	}
+
+	// Without a terminal to ask, applying needs --yes.
+	err := runRootErr(t, "batch", "--input", input, "--output", out, "--max-events-per-day", "2", "--auto-balance")
+	if err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Fatalf("expected a request for --yes, got %v", err)
+	}
+
+	if err := runRootErr(t, "batch", "--input", input, "--output", out, "--max-events-per-day", "2", "--auto-balance", "--yes"); err != nil {
+		t.Fatalf("batch --auto-balance --yes failed: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"DTSTART:20250604T160000", "DTSTART:20250604T110000", "DTSTART:20250602T090000"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("calendar lacks %s:\n%s", want, data)
+		}
+	}
+}
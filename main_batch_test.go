@@ -1,13 +1,27 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"tempus/internal/testutil"
 	"testing"
+	"time"
+
+	"tempus/internal/calendar"
+	"tempus/internal/config"
+	"tempus/internal/constants"
+	"tempus/internal/diag"
+	"tempus/internal/prompts"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 func TestBatchCSVGeneratesCalendarWithMultipleEvents(t *testing.T) {
@@ -130,9 +144,950 @@ func TestBatchJSONSupportsAllDayAndDuration(t *testing.T) {
 	}
 }
 
+func TestSummarizeBatchCountsCategoriesAlarmsAndDays(t *testing.T) {
+	cal := calendar.NewCalendar()
+	day1 := time.Date(2025, 12, 16, 9, 0, 0, 0, time.UTC)
+	day3 := day1.AddDate(0, 0, 2)
+
+	meds := calendar.NewEvent("Meds", day1, day1.Add(5*time.Minute))
+	meds.Categories = []string{"Health", "Medication"}
+	meds.Alarms = []calendar.Alarm{{TriggerIsRelative: true, TriggerDuration: -5 * time.Minute}, {TriggerIsRelative: true, TriggerDuration: -time.Minute}}
+	standup := calendar.NewEvent("Standup", day3, day3.Add(15*time.Minute))
+	standup.Categories = []string{"Work"}
+	walk := calendar.NewEvent("Walk", day1.Add(4*time.Hour), day1.Add(5*time.Hour))
+	cal.AddEvent(meds)
+	cal.AddEvent(standup)
+	cal.AddEvent(walk)
+
+	summary := summarizeBatch(cal, "out.ics")
+
+	if summary.Events != 3 || summary.Alarms != 2 {
+		t.Fatalf("unexpected totals: %+v", summary)
+	}
+	if summary.FirstDay != "2025-12-16" || summary.LastDay != "2025-12-18" {
+		t.Fatalf("unexpected day range: %s .. %s", summary.FirstDay, summary.LastDay)
+	}
+	want := map[string]int{"Health": 1, "Medication": 1, "Work": 1, uncategorizedLabel: 1}
+	for cat, n := range want {
+		if summary.Categories[cat] != n {
+			t.Errorf("category %q = %d, want %d", cat, summary.Categories[cat], n)
+		}
+	}
+	if len(summary.Calendars) != 1 || summary.Calendars[0] != "out.ics" {
+		t.Errorf("unexpected calendars: %v", summary.Calendars)
+	}
+}
+
+func TestBatchJSONSummaryOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputPath := filepath.Join(tmpDir, testutil.FilenameEventsCSV)
+	outputPath := filepath.Join(tmpDir, "batch.ics")
+
+	csvData := strings.Join([]string{
+		"summary,start,duration,categories,alarms",
+		"Standup,2025-12-16 09:30,15m,Work,-5m",
+		"Lunch,2025-12-17 13:00,1h,Break,",
+	}, "\n")
+	if err := os.WriteFile(inputPath, []byte(csvData), 0644); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+
+	cmd := newBatchCmd()
+	mustSetFlag(t, cmd, "input", inputPath)
+	mustSetFlag(t, cmd, "output", outputPath)
+	mustSetFlag(t, cmd, "json", "true")
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	runErr := runBatch(cmd, nil)
+	w.Close()
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+	os.Stdout = oldStdout
+
+	if runErr != nil {
+		t.Fatalf("runBatch returned error: %v", runErr)
+	}
+
+	var summary batchSummary
+	if err := json.Unmarshal(buf.Bytes(), &summary); err != nil {
+		t.Fatalf("expected JSON summary, got %q: %v", buf.String(), err)
+	}
+	if summary.Events != 2 || summary.Alarms != 1 || summary.Categories["Work"] != 1 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if _, err := os.Stat(outputPath); err != nil {
+		t.Fatalf("expected calendar to be written: %v", err)
+	}
+}
+
+func TestDetectHoursViolations(t *testing.T) {
+	working, _ := calendar.ParseWeeklyHours(map[string]string{"weekdays": "09:00-17:00"})
+	quiet, _ := calendar.ParseWeeklyHours(map[string]string{"daily": "22:00-07:00"})
+	policy := hoursPolicy{working: working, quiet: quiet}
+
+	day := time.Date(2025, 12, 16, 0, 0, 0, 0, time.UTC) // Tuesday
+	inside := calendar.NewEvent("Standup", day.Add(9*time.Hour+30*time.Minute), day.Add(9*time.Hour+45*time.Minute))
+	late := calendar.NewEvent("Deploy", day.Add(16*time.Hour+30*time.Minute), day.Add(18*time.Hour))
+	earlyAlarm := calendar.NewEvent("Review", day.Add(10*time.Hour), day.Add(11*time.Hour))
+	earlyAlarm.Alarms = []calendar.Alarm{{TriggerIsRelative: true, TriggerDuration: -7 * time.Hour}}
+
+	violations := detectHoursViolations([]calendar.Event{*inside, *late, *earlyAlarm}, policy)
+	if len(violations) != 2 {
+		t.Fatalf("expected 2 violations, got %d: %v", len(violations), violations)
+	}
+	if !strings.Contains(violations[0], "Deploy") || !strings.Contains(violations[0], "outside working hours") {
+		t.Errorf("unexpected first violation: %s", violations[0])
+	}
+	if !strings.Contains(violations[1], "alarm at 2025-12-16 03:00") {
+		t.Errorf("unexpected alarm violation: %s", violations[1])
+	}
+
+	if got := detectHoursViolations([]calendar.Event{*late}, hoursPolicy{}); len(got) != 0 {
+		t.Errorf("expected no violations without configured hours, got %v", got)
+	}
+}
+
+func TestBatchStrictRejectsQuietHourAlarms(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, "tempus")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", tmpDir)
+	if err := os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte("quiet_hours:\n  daily: \"22:00-07:00\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	inputPath := filepath.Join(tmpDir, testutil.FilenameEventsCSV)
+	outputPath := filepath.Join(tmpDir, "batch.ics")
+	csvData := "summary,start,duration,alarms\nMeds,2025-12-16 08:00,5m,-6h\n"
+	if err := os.WriteFile(inputPath, []byte(csvData), 0644); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+
+	cmd := newBatchCmd()
+	mustSetFlag(t, cmd, "input", inputPath)
+	mustSetFlag(t, cmd, "output", outputPath)
+	mustSetFlag(t, cmd, "strict", "true")
+
+	err := runBatch(cmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "quiet hours") {
+		t.Fatalf("expected quiet hours error, got %v", err)
+	}
+	if _, statErr := os.Stat(outputPath); !os.IsNotExist(statErr) {
+		t.Fatalf("expected no output file under --strict, stat err: %v", statErr)
+	}
+}
+
 func mustSetFlag(t *testing.T, cmd *cobra.Command, name, value string) {
 	t.Helper()
 	if err := cmd.Flags().Set(name, value); err != nil {
 		t.Fatalf("failed to set flag %s: %v", name, err)
 	}
 }
+
+func TestBatchWritesSARIFWarnings(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputPath := filepath.Join(tmpDir, testutil.FilenameEventsCSV)
+	sarifPath := filepath.Join(tmpDir, "batch.sarif")
+	csvData := strings.Join([]string{
+		"summary,start,end,start_tz",
+		`"Planning","2025-05-01 09:00","2025-05-01 10:00","UTC"`,
+		`"Review","2025-05-01 09:30","2025-05-01 10:30","UTC"`,
+	}, "\n")
+	if err := os.WriteFile(inputPath, []byte(csvData), 0644); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+
+	cmd := newBatchCmd()
+	mustSetFlag(t, cmd, "input", inputPath)
+	mustSetFlag(t, cmd, "output", filepath.Join(tmpDir, "batch.ics"))
+	mustSetFlag(t, cmd, "check-conflicts", "true")
+	mustSetFlag(t, cmd, "sarif", sarifPath)
+	if err := runBatch(cmd, nil); err != nil {
+		t.Fatalf("runBatch returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(sarifPath)
+	if err != nil {
+		t.Fatalf("expected SARIF file: %v", err)
+	}
+	if !strings.Contains(string(data), `"ruleId": "conflict"`) || !strings.Contains(string(data), `"version": "2.1.0"`) {
+		t.Errorf("unexpected SARIF:\n%s", data)
+	}
+}
+
+func TestBatchNormalizesDeprecatedTimezones(t *testing.T) {
+	tmpDir := t.TempDir()
+	sarifPath := filepath.Join(tmpDir, "batch.sarif")
+	inputPath := filepath.Join(tmpDir, "kyiv.csv")
+	csvData := strings.Join([]string{
+		"summary,start,end,start_tz",
+		`"Standup","2025-05-01 09:00","2025-05-01 09:15","Europe/Kiev"`,
+		`"Sync","2025-05-01 15:00","2025-05-01 16:00","US/Eastern"`,
+	}, "\n")
+	if err := os.WriteFile(inputPath, []byte(csvData), 0644); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+
+	cmd := newBatchCmd()
+	mustSetFlag(t, cmd, "input", inputPath)
+	mustSetFlag(t, cmd, "output", filepath.Join(tmpDir, "kyiv.ics"))
+	mustSetFlag(t, cmd, "sarif", sarifPath)
+	if err := runBatch(cmd, nil); err != nil {
+		t.Fatalf("runBatch returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "kyiv.ics"))
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	ics := string(data)
+	for _, want := range []string{"TZID=Europe/Kyiv:", "TZID=America/New_York:"} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+	if strings.Contains(ics, "Europe/Kiev") || strings.Contains(ics, "US/Eastern") {
+		t.Errorf("deprecated zone names leaked into output:\n%s", ics)
+	}
+
+	sarif, err := os.ReadFile(sarifPath)
+	if err != nil {
+		t.Fatalf("expected SARIF file: %v", err)
+	}
+	if got := strings.Count(string(sarif), `"ruleId": "deprecated-tz"`); got != 2 {
+		t.Errorf("expected 2 deprecated-tz warnings, got %d:\n%s", got, sarif)
+	}
+}
+
+func TestBatchTranslatesCategories(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputPath := filepath.Join(tmpDir, "es.csv")
+	outputPath := filepath.Join(tmpDir, "es.ics")
+	csvData := "summary,start,duration,start_tz,categories\nStandup,2025-05-01 09:00,15m,UTC,work;custom\n"
+	if err := os.WriteFile(inputPath, []byte(csvData), 0644); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+
+	cmd := newBatchCmd()
+	cmd.Flags().String("language", "", "")
+	mustSetFlag(t, cmd, "input", inputPath)
+	mustSetFlag(t, cmd, "output", outputPath)
+	mustSetFlag(t, cmd, "language", "es")
+	mustSetFlag(t, cmd, "translate-categories", "true")
+	if err := runBatch(cmd, nil); err != nil {
+		t.Fatalf("runBatch returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	ics := string(data)
+	if !strings.Contains(ics, "CATEGORIES:Trabajo,") {
+		t.Errorf("expected Spanish category names:\n%s", ics)
+	}
+	// Emoji selection still runs on the canonical name.
+	if !strings.Contains(ics, "SUMMARY:💼 Standup") {
+		t.Errorf("expected work emoji from canonical category:\n%s", ics)
+	}
+}
+
+func TestBatchPeopleColumnWritesPerPersonCalendars(t *testing.T) {
+	dir := t.TempDir()
+	csvData := strings.Join([]string{
+		"summary,start,duration,start_tz,people",
+		"Swim class,2025-05-05 17:00,1h,UTC,Ana",
+		"Dentist,2025-05-06 10:00,30m,UTC,Ana+Luis",
+		"Football,2025-05-07 18:00,1h,UTC,luis",
+		"Family dinner,2025-05-08 20:00,1h,UTC,",
+	}, "\n")
+	family, err := runBatchCSV(t, dir, "family", csvData, true)
+	if err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	if got := strings.Count(family, "BEGIN:VEVENT"); got != 4 {
+		t.Errorf("family calendar should have every event once, got %d", got)
+	}
+	if !strings.Contains(family, `DESCRIPTION:👥 Ana\, Luis`) || !strings.Contains(family, "DESCRIPTION:👥 Luis") {
+		t.Errorf("family calendar should say who each event is for:\n%s", family)
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("expected %s: %v", name, err)
+		}
+		return string(data)
+	}
+	ana, luis := read("family-ana.ics"), read("family-luis.ics")
+	for name, tc := range map[string]struct {
+		ics          string
+		want, absent []string
+	}{
+		"ana":  {ana, []string{"Swim class", "Dentist", "Family dinner"}, []string{"Football"}},
+		"luis": {luis, []string{"Football", "Dentist", "Family dinner"}, []string{"Swim class"}},
+	} {
+		for _, w := range tc.want {
+			if !strings.Contains(tc.ics, w) {
+				t.Errorf("%s calendar missing %q", name, w)
+			}
+		}
+		for _, a := range tc.absent {
+			if strings.Contains(tc.ics, a) {
+				t.Errorf("%s calendar should not contain %q", name, a)
+			}
+		}
+	}
+	if !strings.Contains(ana, "X-WR-CALNAME:Ana") {
+		t.Errorf("person calendar should be named after them:\n%s", ana)
+	}
+
+	// The shared event keeps distinct UIDs per calendar.
+	seen := map[string]bool{}
+	for _, ics := range []string{family, ana, luis} {
+		for _, uid := range batchUIDs(ics) {
+			if seen[uid] {
+				t.Errorf("UID %s appears in more than one calendar", uid)
+			}
+			seen[uid] = true
+		}
+	}
+}
+
+func TestBatchFixItPromptsRepairInvalidRows(t *testing.T) {
+	prevScanner := prompts.Scanner
+	// Row 1: bad start → re-enter; row 2: bad timezone → pick option 1 (mapped city).
+	prompts.Scanner = bufio.NewScanner(strings.NewReader("2025-05-01 09:00\n1\n"))
+	t.Cleanup(func() { prompts.Scanner = prevScanner })
+
+	records := []batchRecord{
+		{Summary: "Standup", Start: "2025-05-01 nine", Duration: "15m", StartTZ: "Europe/Madrid"},
+		{Summary: "Review", Start: "2025-05-02 10:00", Duration: "1h", StartTZ: "Madrid"},
+	}
+	opts := &batchOptions{input: "events.csv", fixInteractive: true}
+	cal, validationErrors, err := buildBatchCalendar(records, opts)
+	if err != nil {
+		t.Fatalf("buildBatchCalendar failed: %v", err)
+	}
+	if len(validationErrors) != 0 || len(cal.Events) != 2 {
+		t.Fatalf("expected both rows fixed, got %d events, errors %v", len(cal.Events), validationErrors)
+	}
+	if got := cal.Events[0].StartTime.Format(constants.DateTimeFormatISO); got != "2025-05-01 09:00" {
+		t.Errorf("fixed start = %s", got)
+	}
+	if cal.Events[1].StartTZ != "Europe/Madrid" {
+		t.Errorf("fixed timezone = %q, want Europe/Madrid", cal.Events[1].StartTZ)
+	}
+}
+
+func TestBatchFixItGivingUpKeepsRowError(t *testing.T) {
+	prevScanner := prompts.Scanner
+	prompts.Scanner = bufio.NewScanner(strings.NewReader("\n"))
+	t.Cleanup(func() { prompts.Scanner = prevScanner })
+
+	records := []batchRecord{{Summary: "Standup", Start: "tomorrow-ish", Duration: "15m"}}
+	_, _, err := buildBatchCalendar(records, &batchOptions{fixInteractive: true})
+	if err == nil || !strings.Contains(err.Error(), "invalid start time") {
+		t.Fatalf("expected the original row error, got %v", err)
+	}
+
+	var fe *batchFieldError
+	if !errors.As(err, &fe) || fe.field != "start" {
+		t.Errorf("expected a start field error, got %#v", err)
+	}
+}
+
+func TestBatchWatchRegeneratesOnChange(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputPath := filepath.Join(tmpDir, testutil.FilenameEventsCSV)
+	outputPath := filepath.Join(tmpDir, "watch.ics")
+	write := func(summary string) {
+		t.Helper()
+		data := "summary,start,duration,start_tz\n" + summary + ",2025-05-01 09:00,30m,UTC\n"
+		if err := os.WriteFile(inputPath, []byte(data), 0644); err != nil {
+			t.Fatalf("failed to write csv: %v", err)
+		}
+	}
+	write("Planning")
+
+	prevDebounce := batchWatchDebounce
+	batchWatchDebounce = 10 * time.Millisecond
+	t.Cleanup(func() { batchWatchDebounce = prevDebounce })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- watchBatch(ctx, &batchOptions{input: inputPath, output: outputPath})
+	}()
+
+	waitFor := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if data, err := os.ReadFile(outputPath); err == nil && strings.Contains(string(data), want) {
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		t.Fatalf("output never contained %q", want)
+	}
+	waitFor("SUMMARY:Planning")
+	write("Retro")
+	waitFor("SUMMARY:Retro")
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("watchBatch returned %v", err)
+	}
+}
+
+func TestRenderBatchTimeline(t *testing.T) {
+	day := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	standup := calendar.NewEvent("Standup", day.Add(9*time.Hour), day.Add(9*time.Hour+30*time.Minute))
+	standup.AddCategory("Work")
+	review := calendar.NewEvent("Review", day.Add(9*time.Hour+15*time.Minute), day.Add(10*time.Hour))
+	holiday := calendar.NewEvent("Holiday", day.AddDate(0, 0, 1), day.AddDate(0, 0, 2))
+	holiday.AllDay = true
+
+	var buf bytes.Buffer
+	renderBatchTimeline(&buf, []calendar.Event{*review, *standup, *holiday})
+	got := buf.String()
+
+	for _, want := range []string{
+		"Thu 05/01/2025 (2 event(s), overlapping)",
+		"    09  10\n    |...|\n    WW\n     ###\n",
+		"    W 09:00-09:30 Standup\n",
+		"Fri 05/02/2025 (1 event(s))\n    all day: Holiday\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("timeline missing %q:\n%s", want, got)
+		}
+	}
+
+	var many []calendar.Event
+	for i := 0; i < timelineMaxDays+3; i++ {
+		start := day.AddDate(0, 0, i).Add(8 * time.Hour)
+		many = append(many, *calendar.NewEvent("Gym", start, start.Add(time.Hour)))
+	}
+	buf.Reset()
+	renderBatchTimeline(&buf, many)
+	if !strings.Contains(buf.String(), "… 3 more day(s) not shown") {
+		t.Errorf("expected the timeline to be capped:\n%s", buf.String())
+	}
+}
+
+func TestBatchEmbedSourceCanBeRecovered(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "week.csv")
+	outputPath := filepath.Join(dir, "week.ics")
+	csvData := "summary,start,duration,start_tz\nStandup,2025-05-01 09:00,15m,Europe/Madrid\n"
+	if err := os.WriteFile(inputPath, []byte(csvData), 0644); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+
+	cmd := newBatchCmd()
+	mustSetFlag(t, cmd, "input", inputPath)
+	mustSetFlag(t, cmd, "output", outputPath)
+	mustSetFlag(t, cmd, "name", "My Week")
+	mustSetFlag(t, cmd, "embed-source", "true")
+	if err := runBatch(cmd, nil); err != nil {
+		t.Fatalf("runBatch returned error: %v", err)
+	}
+
+	source := newBatchSourceCmd()
+	var out, stderr bytes.Buffer
+	source.SetOut(&out)
+	source.SetErr(&stderr)
+	if err := runBatchSource(source, []string{outputPath}); err != nil {
+		t.Fatalf("batch source failed: %v", err)
+	}
+	if out.String() != csvData {
+		t.Errorf("recovered input = %q, want %q", out.String(), csvData)
+	}
+	if want := "tempus batch -i week.csv -o " + outputPath + " --name 'My Week' --embed-source"; !strings.Contains(stderr.String(), want) {
+		t.Errorf("stderr missing rerun command %q:\n%s", want, stderr.String())
+	}
+
+	plain := filepath.Join(dir, "plain.ics")
+	cmd = newBatchCmd()
+	mustSetFlag(t, cmd, "input", inputPath)
+	mustSetFlag(t, cmd, "output", plain)
+	if err := runBatch(cmd, nil); err != nil {
+		t.Fatalf("runBatch returned error: %v", err)
+	}
+	if err := runBatchSource(source, []string{plain}); err == nil || !strings.Contains(err.Error(), "--embed-source") {
+		t.Errorf("expected missing-bundle error, got %v", err)
+	}
+}
+
+func TestBatchAppendAddsEventsToExistingCalendar(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "week.ics")
+	first := "summary,start,duration,start_tz\nStandup,2025-05-01 09:00,15m,Europe/Madrid\n"
+	extra := "summary,start,duration,start_tz\nReview,2025-05-01 08:10,30m,Europe/London\nLunch,2025-05-02 13:00,1h,Europe/Madrid\n"
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	run := func(input string) ([]diag.Warning, error) {
+		cmd := newBatchCmd()
+		mustSetFlag(t, cmd, "input", input)
+		mustSetFlag(t, cmd, "output", output)
+		mustSetFlag(t, cmd, "stable-uids", "true")
+		mustSetFlag(t, cmd, "append", "true")
+		opts, err := parseBatchFlags(cmd)
+		if err != nil {
+			t.Fatalf("parseBatchFlags failed: %v", err)
+		}
+		return runBatchOnce(opts)
+	}
+
+	// Appending to a missing file creates it.
+	if _, err := run(write("first.csv", first)); err != nil {
+		t.Fatalf("first append failed: %v", err)
+	}
+	warnings, err := run(write("extra.csv", extra))
+	if err != nil {
+		t.Fatalf("second append failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	ics := string(data)
+	if got := strings.Count(ics, "BEGIN:VEVENT"); got != 3 {
+		t.Errorf("expected 3 events after append, got %d:\n%s", got, ics)
+	}
+	if got := strings.Count(ics, "TZID:Europe/Madrid\r\n"); got != 1 {
+		t.Errorf("expected the Madrid VTIMEZONE once, got %d", got)
+	}
+	if !strings.Contains(ics, "TZID:Europe/London\r\n") {
+		t.Errorf("expected the London VTIMEZONE to be added:\n%s", ics)
+	}
+	overlap := false
+	for _, w := range warnings {
+		if w.Code == diag.CodeConflict && strings.Contains(w.Message, "Review") && strings.Contains(w.Message, "existing Standup") {
+			overlap = true
+		}
+	}
+	if !overlap {
+		t.Errorf("expected an overlap warning against the existing event, got %+v", warnings)
+	}
+
+	// Re-appending the same rows collides on UID and leaves the file alone.
+	if _, err := run(filepath.Join(dir, "extra.csv")); err == nil || !strings.Contains(err.Error(), "already in it") {
+		t.Fatalf("expected UID collision error, got %v", err)
+	}
+	after, _ := os.ReadFile(output)
+	if string(after) != ics {
+		t.Error("a failed append must not change the calendar")
+	}
+
+	cmd := newBatchCmd()
+	mustSetFlag(t, cmd, "output", filepath.Join(dir, "week.jcal"))
+	mustSetFlag(t, cmd, "append", "true")
+	if _, err := parseBatchFlags(cmd); err == nil {
+		t.Error("expected --append to reject jCal output")
+	}
+}
+
+func TestAnnotateTimes(t *testing.T) {
+	day := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	at := func(summary string, start, end time.Duration) calendar.Event {
+		return *calendar.NewEvent(summary, day.Add(start), day.Add(end))
+	}
+	standup := at("Standup", 9*time.Hour, 9*time.Hour+15*time.Minute)
+	standup.Description = "Daily sync"
+	review := at("Review", 10*time.Hour, 11*time.Hour+30*time.Minute)
+	lunch := at("Lunch", 11*time.Hour+30*time.Minute, 12*time.Hour+30*time.Minute)
+	call := at("Call", 12*time.Hour, 12*time.Hour+15*time.Minute)
+	gym := at("Gym", 7*time.Hour, 8*time.Hour)
+	gym.RRule = "FREQ=DAILY"
+	holiday := at("Holiday", 0, 24*time.Hour)
+	holiday.AllDay = true
+	events := []calendar.Event{review, call, standup, lunch, gym, holiday}
+
+	annotateTimes(events)
+
+	want := map[string]string{
+		"Review":  "⏳ Lasts 1 h 30 min, until 11:30\n⏳ Starts 45 min after your previous event ends (Standup)",
+		"Call":    "⏳ Lasts 15 min, until 12:15\n⏳ Overlaps your previous event (Lunch) by 15 min",
+		"Standup": "Daily sync\n\n⏳ Lasts 15 min, until 09:15\n⏳ First event of the day",
+		"Lunch":   "⏳ Lasts 1 h, until 12:30\n⏳ Starts right as your previous event ends (Review), no break in between",
+		"Gym":     "⏳ Lasts 1 h, until 08:00",
+		"Holiday": "",
+	}
+	for _, ev := range events {
+		if ev.Description != want[ev.Summary] {
+			t.Errorf("%s description = %q, want %q", ev.Summary, ev.Description, want[ev.Summary])
+		}
+	}
+}
+
+func TestFormatSpan(t *testing.T) {
+	tests := map[time.Duration]string{
+		30 * time.Second:                "<1 min",
+		45 * time.Minute:                "45 min",
+		time.Hour:                       "1 h",
+		90 * time.Minute:                "1 h 30 min",
+		26*time.Hour + 10*time.Minute:   "1 d 2 h",
+		48 * time.Hour:                  "2 d",
+		59*time.Minute + 40*time.Second: "1 h",
+	}
+	for d, want := range tests {
+		if got := formatSpan(d); got != want {
+			t.Errorf("formatSpan(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestDetectEnergyOverload(t *testing.T) {
+	day := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	at := func(summary string, offset time.Duration, energy, priority int) calendar.Event {
+		ev := calendar.NewEvent(summary, day.Add(offset), day.Add(offset+30*time.Minute))
+		ev.Energy, ev.Priority = energy, priority
+		return *ev
+	}
+	holiday := at("Holiday", 48*time.Hour, 0, 0)
+	holiday.AllDay = true
+	events := []calendar.Event{
+		at("Deep work", 9*time.Hour, 4, 1),
+		at("Writing", 11*time.Hour, 4, 0),
+		at("Admin", 13*time.Hour, 3, 8),
+		at("Check-in", 15*time.Hour, 0, 0),
+		at("Check-in", 16*time.Hour, 0, 0),
+		at("Call", 24*time.Hour+9*time.Hour, 0, 0),
+		holiday,
+		at("Workshop", 48*time.Hour+9*time.Hour, 5, 0),
+		at("Review", 48*time.Hour+13*time.Hour, 5, 0),
+		at("Errands", 48*time.Hour+17*time.Hour, 2, 9),
+	}
+
+	got := detectEnergyOverload(events, 10)
+	want := []string{
+		`Thursday, May 1: energy 13 of 10; consider moving the lowest-priority events: "Admin"`,
+		`Saturday, May 3: energy 12 of 10; consider moving the lowest-priority events: "Errands"`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("detectEnergyOverload() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if got := detectEnergyOverload(events, 4); len(got) != 2 || !strings.Contains(got[0], `"Admin", "Writing", "Check-in", "Check-in"`) {
+		t.Errorf("expected several events to move on a tight budget, got %q", got)
+	}
+}
+
+func TestBatchEnergyAndPriorityColumns(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	csvData := "summary,start,duration,priority,energy\nFocus,2025-05-01 09:00,2h,2,4\nEmail,2025-05-01 12:00,15m,,\n"
+	ics, err := runBatchCSV(t, dir, "energy", csvData, false)
+	if err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	if !strings.Contains(ics, "PRIORITY:2\r\n") || !strings.Contains(ics, calendar.EnergyProperty+":4\r\n") {
+		t.Errorf("expected PRIORITY and %s in the calendar:\n%s", calendar.EnergyProperty, ics)
+	}
+	records, err := calendar.ExportEvents(ics)
+	if err != nil || len(records) != 2 {
+		t.Fatalf("ExportEvents() = %v, %v", records, err)
+	}
+	if records[0].Priority != 2 || records[0].Energy != 4 || records[1].Energy != 0 {
+		t.Errorf("energy and priority should round-trip through export: %+v", records)
+	}
+
+	_, err = runBatchCSV(t, dir, "bad", "summary,start,energy\nFocus,2025-05-01 09:00,7\n", false)
+	var fe *batchFieldError
+	if !errors.As(err, &fe) || fe.field != "energy" || !strings.Contains(err.Error(), "from 1 to 5") {
+		t.Errorf("expected an energy field error, got %v", err)
+	}
+
+	// The budget comes from the config unless --energy-budget is given.
+	if err := os.MkdirAll(filepath.Join(dir, "tempus"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tempus", "config.yaml"), []byte("energy_budget: 4\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	viper.Reset()
+	if _, err := config.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	cmd := newBatchCmd()
+	mustSetFlag(t, cmd, "input", filepath.Join(dir, "energy.csv"))
+	opts, err := parseBatchFlags(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.energyBudget != 4 {
+		t.Errorf("energy budget = %d, want 4 from config", opts.energyBudget)
+	}
+	start := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+	focus := calendar.Event{Summary: "Focus", StartTime: start, EndTime: start.Add(2 * time.Hour), Energy: 4, Priority: 2}
+	email := calendar.Event{Summary: "Email", StartTime: start.Add(3 * time.Hour), EndTime: start.Add(3*time.Hour + 15*time.Minute)}
+	warnings := collectBatchWarnings([]calendar.Event{focus, email}, opts)
+	if len(warnings) != 1 || warnings[0].Code != diag.CodeEnergy || !strings.Contains(warnings[0].Message, `"Email"`) {
+		t.Errorf("expected one energy warning suggesting Email, got %+v", warnings)
+	}
+	mustSetFlag(t, cmd, "energy-budget", "0")
+	if opts, _ := parseBatchFlags(cmd); opts.energyBudget != 0 {
+		t.Errorf("--energy-budget 0 should turn the check off, got %d", opts.energyBudget)
+	}
+}
+
+func TestBatchContactColumns(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	csvData := "summary,start,duration,attendees,organizer,priority,status,url,class\n" +
+		"Review,2025-05-01 09:00,1h,ana@example.com|Bob <bob@example.com>,mailto:lead@example.com,1,Tentative,https://meet.example.com/review,Private\n" +
+		"Email,2025-05-01 12:00,15m,,,,,,\n"
+	ics, err := runBatchCSV(t, dir, "contacts", csvData, false)
+	if err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	for _, want := range []string{
+		"ATTENDEE:mailto:ana@example.com\r\n", "ATTENDEE:mailto:bob@example.com\r\n",
+		"ORGANIZER:mailto:lead@example.com\r\n", "PRIORITY:1\r\n", "STATUS:TENTATIVE\r\n",
+		"URL:https://meet.example.com/review\r\n", "CLASS:PRIVATE\r\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("calendar missing %q:\n%s", want, ics)
+		}
+	}
+	records, err := calendar.ExportEvents(ics)
+	if err != nil || len(records) != 2 {
+		t.Fatalf("ExportEvents() = %v, %v", records, err)
+	}
+	if got := records[0]; len(got.Attendees) != 2 || got.Organizer != "lead@example.com" || got.Status != "tentative" || got.URL != "https://meet.example.com/review" || got.Class != "private" {
+		t.Errorf("contacts should round-trip through export: %+v", got)
+	}
+	if records[1].Status != "" || records[1].Organizer != "" || records[1].Class != "" {
+		t.Errorf("a plain row should export no contacts: %+v", records[1])
+	}
+
+	yamlData := "- summary: Sync\n  start: 2025-05-02 10:00\n  duration: 30m\n  attendees: [ana@example.com]\n  status: cancelled\n"
+	yamlPath := filepath.Join(dir, "contacts.yaml")
+	if err := os.WriteFile(yamlPath, []byte(yamlData), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	recs, err := loadBatchFromYAML(yamlPath)
+	if err != nil || len(recs) != 1 || len(recs[0].Attendees) != 1 || recs[0].Status != "cancelled" {
+		t.Fatalf("loadBatchFromYAML() = %+v, %v", recs, err)
+	}
+
+	for field, row := range map[string]string{
+		"status":    "summary,start,status\nFocus,2025-05-01 09:00,maybe\n",
+		"url":       "summary,start,url\nFocus,2025-05-01 09:00,meet.example.com\n",
+		"organizer": "summary,start,organizer\nFocus,2025-05-01 09:00,lead\n",
+		"class":     "summary,start,class\nFocus,2025-05-01 09:00,secret\n",
+	} {
+		_, err := runBatchCSV(t, dir, "bad-"+field, row, false)
+		var fe *batchFieldError
+		if !errors.As(err, &fe) || fe.field != field {
+			t.Errorf("expected a %s field error, got %v", field, err)
+		}
+	}
+	if _, err := runBatchCSV(t, dir, "bad-attendee", "summary,start,attendees\nFocus,2025-05-01 09:00,ana\n", false); err == nil {
+		t.Error("expected an error for an attendee without an email")
+	}
+}
+
+func TestBatchSpellCorrectionFlagsAndDictionary(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	input := filepath.Join(dir, "typos.csv")
+	csvData := "summary,start,duration\nTeam meetng,2025-05-01 09:00,30m\nPhysio visit,2025-05-01 11:00,1h\nmeetng notes,2025-05-01 12:00,15m\n"
+	if err := os.WriteFile(input, []byte(csvData), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "typos.ics")
+
+	runRootStdout(t, "config", "corrections", "add", "Physio", "physiotherapy")
+	runRootStdout(t, "config", "corrections", "remove", "diner")
+	list := runRootStdout(t, "config", "corrections", "list")
+	if !strings.Contains(list, "physio") || !strings.Contains(list, "physiotherapy  (custom)") {
+		t.Errorf("expected the custom correction to be listed and marked:\n%s", list)
+	}
+	if !strings.Contains(list, "Removed built-ins: diner") || strings.Contains(list, "diner ") {
+		t.Errorf("expected diner to be listed only as removed:\n%s", list)
+	}
+
+	viper.Reset()
+	report := runRootStdout(t, "batch", "-i", input, "-o", out, "--show-corrections")
+	for _, want := range []string{"Spelling corrections (2):", "meetng → meeting (rows: 1, 3)", "physio → physiotherapy (rows: 2)"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("expected %q in the report:\n%s", want, report)
+		}
+	}
+	ics, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(ics), "Team meeting\r\n") || !strings.Contains(string(ics), "Physiotherapy visit\r\n") {
+		t.Errorf("expected corrected summaries:\n%s", ics)
+	}
+
+	viper.Reset()
+	report = runRootStdout(t, "batch", "-i", input, "-o", out, "--no-spell-correct", "--show-corrections")
+	if !strings.Contains(report, "No spelling corrections were made") {
+		t.Errorf("expected no corrections with --no-spell-correct:\n%s", report)
+	}
+	if ics, _ := os.ReadFile(out); !strings.Contains(string(ics), "Team meetng\r\n") {
+		t.Errorf("expected summaries kept as written:\n%s", ics)
+	}
+}
+
+func TestBatchDryRunShowsDefaultDurationRule(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	input := filepath.Join(dir, "defaults.csv")
+	csvData := "summary,start,duration\nLunch,2025-05-01 12:30,\nReview,2025-05-01 19:00,\nPlanning,2025-05-01 10:00,20m\n"
+	if err := os.WriteFile(input, []byte(csvData), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := runRootStdout(t, "batch", "-i", input, "-o", filepath.Join(dir, "defaults.ics"), "--dry-run")
+	for _, want := range []string{`Lunch - Thu 05/01/2025 12:30 (45 min from keyword "lunch")`, "Review - Thu 05/01/2025 19:00 (1 h 30 min from start time 18:00-21:00)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the dry run:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Planning - Thu 05/01/2025 10:00 (") {
+		t.Errorf("rows with a duration should not show a default rule:\n%s", out)
+	}
+}
+
+func TestBatchPrepBufferRulesFromConfigAndFlags(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if err := os.MkdirAll(filepath.Join(dir, "tempus"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	configContent := "prep_buffers:\n  health: 30m\n  call: \"\"\ntransition_buffers:\n  gym: 10m\ntransparent_buffers: true\n"
+	if err := os.WriteFile(filepath.Join(dir, "tempus", "config.yaml"), []byte(configContent), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	cmd := newBatchCmd()
+	mustSetFlag(t, cmd, "input", filepath.Join(dir, "in.csv"))
+	mustSetFlag(t, cmd, "prep-before", "Meeting=5m")
+	mustSetFlag(t, cmd, "transition-after", "focus=0")
+	opts, err := parseBatchFlags(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.addPrepTime {
+		t.Error("--prep-before should turn buffers on")
+	}
+
+	at := func(summary string, categories ...string) calendar.Event {
+		start := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+		return calendar.Event{Summary: summary, StartTime: start, EndTime: start.Add(time.Hour), Categories: categories}
+	}
+	tests := []struct {
+		ev               calendar.Event
+		prep, transition time.Duration
+	}{
+		{at("Team meeting"), 5 * time.Minute, 0},
+		{at("Checkup", "Health"), 30 * time.Minute, 0},
+		{at("Doctor appointment"), 20 * time.Minute, 0},
+		{at("Sales call"), 0, 0},
+		{at("Focus block"), 0, 0},
+		{at("Gym"), 0, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := bufferFor(opts.buffers.prep, tt.ev); got != tt.prep {
+			t.Errorf("prep buffer for %q = %v, want %v", tt.ev.Summary, got, tt.prep)
+		}
+		if got := bufferFor(opts.buffers.transition, tt.ev); got != tt.transition {
+			t.Errorf("transition buffer for %q = %v, want %v", tt.ev.Summary, got, tt.transition)
+		}
+	}
+
+	buffers, _ := generatePrepTimeEvents([]calendar.Event{at("Gym")}, opts.buffers)
+	if len(buffers) != 1 || !buffers[0].Transparent {
+		t.Errorf("expected one transparent transition buffer, got %+v", buffers)
+	}
+
+	mustSetFlag(t, cmd, "prep-before", "Meeting")
+	if _, err := parseBatchFlags(cmd); err == nil || !strings.Contains(err.Error(), "NAME=DURATION") {
+		t.Errorf("expected an error for a rule without a duration, got %v", err)
+	}
+}
+
+func TestPrepBuffersAvoidBusyTime(t *testing.T) {
+	rules, err := bufferRulesFromConfig(config.Defaults())
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(summary string, day, h, m int, length time.Duration) calendar.Event {
+		start := time.Date(2025, 5, day, h, m, 0, 0, time.UTC)
+		return calendar.Event{Summary: summary, StartTime: start, EndTime: start.Add(length)}
+	}
+	gym := at("Gym", 1, 8, 0, time.Hour)
+	gym.RRule = "FREQ=DAILY;COUNT=3"
+	events := []calendar.Event{
+		gym,
+		at("Standup", 2, 9, 0, 30*time.Minute),
+		at("Team meeting", 2, 9, 40, 50*time.Minute), // 15m prep, only 10m free after Standup
+		at("Client call", 2, 10, 30, 30*time.Minute), // back to back: no prep at all
+		at("Focus block", 2, 11, 0, time.Hour),       // 5m transition
+		at("Doctor visit", 2, 12, 20, time.Hour),     // 20m prep, 15m left after the transition
+		at("Meeting", 3, 9, 5, time.Hour),            // 5m free after the third Gym
+	}
+	buffers, notes := generatePrepTimeEvents(events, rules)
+
+	got := map[string]string{}
+	for _, b := range buffers {
+		got[b.Summary] = b.StartTime.Format("02 15:04") + "-" + b.EndTime.Format("15:04")
+	}
+	want := map[string]string{
+		"⏰ Preparation: Team meeting": "02 09:30-09:40",
+		"🔄 Transition: Focus block":   "02 12:00-12:05",
+		"⏰ Preparation: Doctor visit": "02 12:05-12:20",
+		"⏰ Preparation: Meeting":      "03 09:00-09:05",
+	}
+	if len(got) != len(want) {
+		t.Errorf("buffers = %v, want %v", got, want)
+	}
+	for summary, span := range want {
+		if got[summary] != span {
+			t.Errorf("%s = %q, want %q", summary, got[summary], span)
+		}
+	}
+
+	if len(notes) != 4 {
+		t.Fatalf("expected 4 notes (3 shortened, 1 skipped), got %+v", notes)
+	}
+	if n := notes[1]; n.event.Summary != "Client call" || n.got != 0 || n.blocker != "Team meeting" {
+		t.Errorf("Client call prep should be skipped because of Team meeting, got %+v", n)
+	}
+	warnings := bufferWarnings(len(buffers), notes, "in.csv")
+	if len(warnings) != 5 || warnings[0].Severity != diag.SeverityInfo || warnings[1].Code != diag.CodeBuffer {
+		t.Errorf("unexpected warnings: %+v", warnings)
+	}
+	if !strings.Contains(warnings[1].Message, "15 min to 10 min") || !strings.Contains(warnings[1].Message, "Standup") {
+		t.Errorf("shortened buffer message = %q", warnings[1].Message)
+	}
+}
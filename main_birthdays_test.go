@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const birthdayContacts = `BEGIN:VCARD
+VERSION:3.0
+FN:Ana García
+BDAY:1985-03-14
+ANNIVERSARY:2010-06-19
+END:VCARD
+BEGIN:VCARD
+VERSION:3.0
+FN:Leo
+BDAY:--02-29
+END:VCARD
+`
+
+func TestBirthdaysWritesYearlyEvents(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "contacts.vcf")
+	if err := os.WriteFile(input, []byte(birthdayContacts), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "birthdays.ics")
+
+	got := runRootStdout(t, "birthdays", "--input", input, "--from", "2025-05-01", "--feb29", "mar1", "-o", output)
+	for _, want := range []string{"Found 3 birthday(s)", "🎂 Ana García's birthday", "February 29th: on March 1st in common years"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("plan missing %q:\n%s", want, got)
+		}
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	ics := string(data)
+	for _, want := range []string{
+		"DTSTART;VALUE=DATE:20260314",
+		"DESCRIPTION:Born 1985-03-14",
+		"SUMMARY:💍 Ana García's anniversary",
+		"DTSTART;VALUE=DATE:20250619",
+		"DTSTART;VALUE=DATE:20260301",
+		"RRULE:FREQ=YEARLY;BYYEARDAY=60",
+		"CATEGORIES:Birthday",
+		"TRIGGER:-P7D",
+		"TRIGGER:-P1D",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+	if n := strings.Count(ics, "RRULE:FREQ=YEARLY"); n != 3 {
+		t.Errorf("expected 3 yearly events, got %d", n)
+	}
+}
+
+func TestBirthdaysSkipsAnniversaries(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "contacts.vcf")
+	if err := os.WriteFile(input, []byte(birthdayContacts), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "birthdays.ics")
+
+	runRootStdout(t, "birthdays", "-i", input, "--no-anniversaries", "--alarm", "-2w", "-o", output)
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	ics := string(data)
+	if strings.Contains(ics, "anniversary") {
+		t.Error("--no-anniversaries should leave anniversaries out")
+	}
+	if !strings.Contains(ics, "TRIGGER:-P14D") || strings.Contains(ics, "TRIGGER:-P7D") {
+		t.Error("--alarm should replace the default reminders")
+	}
+	if !strings.Contains(ics, "RRULE:FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1") {
+		t.Error("February 29th should default to the last day of February")
+	}
+}
+
+func TestBirthdaysErrors(t *testing.T) {
+	dir := setupCommandTest(t)
+	empty := filepath.Join(dir, "empty.vcf")
+	if err := os.WriteFile(empty, []byte("BEGIN:VCARD\nFN:Nobody\nEND:VCARD\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"birthdays"}, "--input is required"},
+		{[]string{"birthdays", "-i", empty}, "no birthdays or anniversaries"},
+		{[]string{"birthdays", "-i", empty, "--feb29", "never"}, "invalid --feb29"},
+	} {
+		if err := runRootErr(t, tc.args...); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v: expected %q, got %v", tc.args, tc.want, err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBatchReadsOutlookCSV(t *testing.T) {
+	dir := setupCommandTest(t)
+	csvData := "\ufeff\"Subject\",\"Start Date\",\"Start Time\",\"End Date\",\"End Time\",\"All day event\",\"Reminder on/off\",\"Reminder Date\",\"Reminder Time\",\"Meeting Organizer\",\"Required Attendees\",\"Optional Attendees\",\"Categories\",\"Description\",\"Location\",\"Priority\",\"Private\",\"Sensitivity\"\n" +
+		"\"Dentist\",\"11/3/2030\",\"10:00:00 AM\",\"11/3/2030\",\"10:45:00 AM\",\"False\",\"True\",\"11/3/2030\",\"9:00:00 AM\",\"ana@example.com\",\"bo@example.com;Carl Doe\",\"\",\"Health;Personal\",\"Check-up\",\"Calle Mayor 5\",\"High\",\"False\",\"Normal\"\n" +
+		"\"Holiday\",\"12/24/2030\",\"12:00:00 AM\",\"12/26/2030\",\"12:00:00 AM\",\"True\",\"False\",\"\",\"\",\"\",\"\",\"\",\"\",\"\",\"\",\"Normal\",\"False\",\"Private\"\n"
+
+	ics, err := runBatchCSV(t, dir, "outlook", csvData, false)
+	if err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	for _, want := range []string{
+		"DTSTART:20301103T100000",
+		"DTEND:20301103T104500",
+		"LOCATION:Calle Mayor 5",
+		"CATEGORIES:Health,Personal",
+		"PRIORITY:1",
+		"ORGANIZER:mailto:ana@example.com",
+		"ATTENDEE:mailto:bo@example.com",
+		"TRIGGER;VALUE=DATE-TIME:20301103T090000Z",
+		"DTSTART;VALUE=DATE:20301224",
+		"DTEND;VALUE=DATE:20301226",
+		"CLASS:PRIVATE",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("calendar lacks %q:\n%s", want, ics)
+		}
+	}
+	if strings.Contains(ics, "Carl Doe") {
+		t.Errorf("attendee without an email address kept:\n%s", ics)
+	}
+}
+
+func TestBatchReadsGoogleCSV(t *testing.T) {
+	dir := setupCommandTest(t)
+	csvData := "Subject,Start Date,Start Time,End Date,End Time,All Day Event,Description,Location,Private\n" +
+		"Lunch,05/30/2030,1:30 PM,05/30/2030,2:30 PM,False,,Cafe,True\n" +
+		"Conference,06/02/2030,,06/03/2030,,True,,,False\n"
+
+	ics, err := runBatchCSV(t, dir, "gcal", csvData, false)
+	if err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	for _, want := range []string{
+		"DTSTART:20300530T133000",
+		"DTEND:20300530T143000",
+		"CLASS:PRIVATE",
+		"DTSTART;VALUE=DATE:20300602",
+		"DTEND;VALUE=DATE:20300604", // Google's end date is the last day
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("calendar lacks %q:\n%s", want, ics)
+		}
+	}
+}
+
+func TestCalendarAppCSVValues(t *testing.T) {
+	dates := map[string]string{"5/30/2030": "2030-05-30", "05/03/30": "2030-05-03", "2030-05-30": "2030-05-30", "30/5/2030": "30/5/2030"}
+	for in, want := range dates {
+		if got := appCSVDate(in); got != want {
+			t.Errorf("appCSVDate(%q) = %q, want %q", in, got, want)
+		}
+	}
+	clocks := map[string]string{"2:30 PM": "14:30", "12:00:00 AM": "00:00", "9 a.m.": "09:00", "11:15pm": "23:15", "16:45:00": "16:45", "": ""}
+	for in, want := range clocks {
+		if got := appCSVClock(in); got != want {
+			t.Errorf("appCSVClock(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const defaultsYAML = `calendar:
+  name: Clinic
+  default_tz: Europe/Madrid
+  alarms: [-1h]
+  categories: [Health]
+events:
+  - summary: Dentist
+    start: 2025-05-01 09:00
+    duration: 45m
+  - summary: Physio
+    start: 2025-05-02 18:00
+    duration: 1h
+    alarms: [-1d]
+    categories: [health, Sport]
+`
+
+func TestBatchAppliesCalendarDefaults(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "clinic.yaml")
+	if err := os.WriteFile(input, []byte(defaultsYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "clinic.ics")
+	if err := runRootErr(t, "batch", "-i", input, "-o", output); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	ics := string(data)
+	for _, want := range []string{"X-WR-CALNAME:Clinic", "DTSTART;TZID=Europe/Madrid:20250501T090000", "TRIGGER:-PT1H", "TRIGGER:-P1D", "CATEGORIES:Health,Sport"} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("output missing %q:\n%s", want, ics)
+		}
+	}
+	if n := strings.Count(ics, "TRIGGER:"); n != 2 {
+		t.Errorf("an event with its own alarms should not get the defaults too: %d triggers", n)
+	}
+
+	// Flags win over the file.
+	if err := runRootErr(t, "batch", "-i", input, "-o", output, "--name", "Mine", "--default-tz", "UTC"); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	data, _ = os.ReadFile(output)
+	if ics := string(data); !strings.Contains(ics, "X-WR-CALNAME:Mine") || strings.Contains(ics, "Europe/Madrid") {
+		t.Errorf("flags should override the calendar block:\n%s", ics)
+	}
+}
+
+func TestCalendarDefaultsTargetAndErrors(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "target.json")
+	js := `{"calendar": {"target": "jcal"}, "events": [{"summary": "Call", "start": "2025-05-01 09:00", "duration": "30m"}]}`
+	if err := os.WriteFile(input, []byte(js), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "out.ics")
+	if err := runRootErr(t, "batch", "-i", input, "-o", output); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil || !strings.Contains(string(data), `"vcalendar"`) {
+		t.Errorf("calendar.target jcal should write jCal, got %.40q (%v)", data, err)
+	}
+
+	events := `"events": [{"summary": "Call", "start": "2025-05-01 09:00"}]`
+	for name, block := range map[string]string{
+		"unknown key": `{"calendar": {"colour": "red"}, ` + events + `}`,
+		"bad zone":    `{"calendar": {"default_tz": "Mars/Olympus"}, ` + events + `}`,
+		"bad target":  `{"calendar": {"target": "pdf"}, ` + events + `}`,
+		"not a map":   `{"calendar": "Clinic", ` + events + `}`,
+		"bad url":     `{"calendar": {"url": "clinic.example.com"}, ` + events + `}`,
+		"bad refresh": `{"calendar": {"refresh_interval": "often"}, ` + events + `}`,
+	} {
+		if err := os.WriteFile(input, []byte(block), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := runRootErr(t, "batch", "-i", input, "-o", output, "--dry-run"); err == nil || !strings.Contains(err.Error(), "calendar") {
+			t.Errorf("%s: expected a calendar block error, got %v", name, err)
+		}
+	}
+}
+
+func TestBatchWritesCalendarMetadata(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "clinic.yaml")
+	yaml := `calendar:
+  name: Clinic
+  description: Appointments, tests and reminders
+  uid: clinic-2025
+  refresh_interval: 1d
+  source: https://cal.example.com/clinic.ics
+` + defaultsYAML[strings.Index(defaultsYAML, "events:"):]
+	if err := os.WriteFile(input, []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "clinic.ics")
+	if err := runRootErr(t, "batch", "-i", input, "-o", output, "--calendar-url", "https://example.com/clinic", "--refresh-interval", "12h"); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ics := string(data)
+	for _, want := range []string{
+		"UID:clinic-2025\r\n",
+		"NAME:Clinic\r\nX-WR-CALNAME:Clinic\r\n",
+		"DESCRIPTION:Appointments\\, tests and reminders\r\nX-WR-CALDESC:Appointments\\, tests and reminders\r\n",
+		"URL:https://example.com/clinic\r\n",
+		"SOURCE;VALUE=URI:https://cal.example.com/clinic.ics\r\n",
+		"REFRESH-INTERVAL;VALUE=DURATION:PT12H\r\nX-PUBLISHED-TTL:PT12H\r\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("output missing %q:\n%s", want, ics)
+		}
+	}
+
+	if err := runRootErr(t, "batch", "-i", input, "-o", output, "--source-url", "/clinic.ics"); err == nil || !strings.Contains(err.Error(), "--source-url") {
+		t.Errorf("expected a relative --source-url to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const cancelTestICS = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nMETHOD:PUBLISH\r\n" +
+	"BEGIN:VEVENT\r\nUID:flight@example.com\r\nSUMMARY:Flight to Lisbon\r\nORGANIZER:mailto:ana@example.com\r\nDTSTART:20301102T080000Z\r\nSEQUENCE:4\r\nEND:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\nUID:hotel@example.com\r\nSUMMARY:Hotel\r\nDTSTART;VALUE=DATE:20301102\r\nEND:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestCancelCommand(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "trip.ics")
+	if err := os.WriteFile(input, []byte(cancelTestICS), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := runRootStdout(t, "cancel", "-i", input, "--uid", "flight@example.com")
+	if !strings.Contains(out, "Flight to Lisbon (20301102T080000Z)") || !strings.Contains(out, "Cancelled 1 event(s)") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "trip-cancel.ics"))
+	if err != nil {
+		t.Fatalf("cancellation not written: %v", err)
+	}
+	got := string(data)
+	for _, want := range []string{"METHOD:CANCEL", "UID:flight@example.com", "STATUS:CANCELLED", "SEQUENCE:5"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("cancellation lacks %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Hotel") {
+		t.Errorf("unselected event cancelled:\n%s", got)
+	}
+}
+
+func TestCancelCommandSelection(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "trip.ics")
+	if err := os.WriteFile(input, []byte(cancelTestICS), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"cancel", "-i", input}, "choose the events"},
+		{[]string{"cancel", "-i", input, "--all", "--uid", "x"}, "--all cancels every event"},
+		{[]string{"cancel", "-i", input, "--uid", "nope@example.com"}, "nothing to cancel"},
+		{[]string{"cancel", "-i", filepath.Join(dir, "trip.csv"), "--all"}, "must be an .ics file"},
+	}
+	for _, tc := range cases {
+		err := runRootErr(t, tc.args...)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("tempus %v: got %v, want %q", tc.args, err, tc.want)
+		}
+	}
+
+	out := runRootStdout(t, "cancel", "-i", input, "--all", "--dry-run")
+	if !strings.Contains(out, "2 event(s) would be cancelled") {
+		t.Errorf("unexpected dry run output:\n%s", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "trip-cancel.ics")); err == nil {
+		t.Error("dry run wrote a file")
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCategoryColorsColorCreatedEvents(t *testing.T) {
+	dir := setupCommandTest(t)
+
+	runRootStdout(t, "config", "categories", "set-color", "Health", "#2ECC71")
+	runRootStdout(t, "config", "categories", "set-color", "Work", "steelblue")
+	if err := runRootErr(t, "config", "categories", "set-color", "Errands", "greenish"); err == nil {
+		t.Error("expected an error for an unknown color")
+	}
+	list := runRootStdout(t, "config", "categories", "list")
+	for _, want := range []string{"health → #2ecc71  COLOR:mediumseagreen", "work   → #4682b4  COLOR:steelblue"} {
+		if !strings.Contains(list, want) {
+			t.Errorf("expected %q in the list:\n%s", want, list)
+		}
+	}
+
+	health := filepath.Join(dir, "physio.ics")
+	runRootStdout(t, "create", "Physio", "-s", "2030-03-04 09:00", "--duration", "45m", "--category", "Health", "-o", health)
+	data, err := os.ReadFile(health)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"X-APPLE-CALENDAR-COLOR:#2ECC71", "CATEGORIES:Health\r\nCOLOR:mediumseagreen"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %q in:\n%s", want, data)
+		}
+	}
+
+	client := filepath.Join(dir, "client.ics")
+	runRootStdout(t, "create", "Kickoff", "-s", "2030-03-04 11:00", "--duration", "1h", "--category", "Work/ClientA", "-o", client)
+	if data, _ := os.ReadFile(client); !strings.Contains(string(data), "COLOR:steelblue") {
+		t.Errorf("expected a subcategory to take its parent's color:\n%s", data)
+	}
+
+	runRootStdout(t, "config", "categories", "remove-color", "Health")
+	plain := filepath.Join(dir, "plain.ics")
+	runRootStdout(t, "create", "Physio", "-s", "2030-03-05 09:00", "--duration", "45m", "--category", "Health", "-o", plain)
+	if data, _ := os.ReadFile(plain); strings.Contains(string(data), "COLOR") {
+		t.Errorf("expected no color after remove-color:\n%s", data)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"tempus/internal/clock"
+	"tempus/internal/fsys"
+)
+
+func TestBatchWritesThroughInjectedClockAndFS(t *testing.T) {
+	dir := setupCommandTest(t)
+	setClock(clock.Fixed(time.Date(2025, 5, 1, 7, 0, 0, 0, time.UTC)))
+	mem := fsys.NewMemory()
+	outputFS = mem
+	t.Cleanup(func() {
+		setClock(nil)
+		outputFS = fsys.OS
+	})
+
+	input := filepath.Join(dir, "today.csv")
+	if err := os.WriteFile(input, []byte("summary,start,duration\nStandup,09:00,15m\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "out", "today.ics")
+	if err := runRootErr(t, "batch", "-i", input, "-o", output, "--default-tz", "UTC"); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("the calendar should only be written to the injected FS, Stat() = %v", err)
+	}
+	data, err := mem.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected %s in the memory FS: %v", output, err)
+	}
+	for _, want := range []string{"DTSTART;TZID=UTC:20250501T090000", "DTSTAMP:20250501T070000Z", "CREATED:20250501T070000Z"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("output missing %q:\n%s", want, data)
+		}
+	}
+
+	// A second run claims a fresh name against the same FS.
+	if err := runRootErr(t, "batch", "-i", input, "-o", output, "--default-tz", "UTC"); err != nil {
+		t.Fatalf("second batch failed: %v", err)
+	}
+	if _, err := mem.Stat(output); err != nil {
+		t.Errorf("second run: %v", err)
+	}
+}
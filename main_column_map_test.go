@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const outlookCSV = "Subject,Start Date,Start Time,End Date,End Time,Location\n" +
+	"Dentist,2025-05-01,09:00,2025-05-01,09:45,Clinic\n"
+
+func TestParseColumnMapping(t *testing.T) {
+	got, err := parseColumnMapping([]string{"summary=Subject, start=Start Date+Start Time", "Location=Where"})
+	if err != nil {
+		t.Fatalf("parseColumnMapping() failed: %v", err)
+	}
+	if len(got) != 3 || got["summary"][0] != "Subject" || len(got["start"]) != 2 || got["start"][1] != "Start Time" || got["location"][0] != "Where" {
+		t.Errorf("unexpected mapping: %#v", got)
+	}
+	for _, bad := range []string{"summary", "summary=", "title=Subject"} {
+		if _, err := parseColumnMapping([]string{bad}); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestBatchMapReadsForeignHeaders(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "outlook.csv")
+	if err := os.WriteFile(input, []byte(outlookCSV), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "outlook.ics")
+	if err := runRootErr(t, "batch", "-i", input, "-o", output, "--strict-input",
+		"--map", "summary=Subject,start=Start Date+Start Time", "--map", "end=End Date+End Time,location=Location"); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	for _, want := range []string{"SUMMARY:Dentist", "DTSTART:20250501T090000Z", "DTEND:20250501T094500Z", "LOCATION:Clinic"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("output missing %q:\n%s", want, data)
+		}
+	}
+
+	err = runRootErr(t, "batch", "-i", input, "-o", output, "--map", "summary=Title")
+	if err == nil || !strings.Contains(err.Error(), `no column "Title"`) {
+		t.Errorf("expected an error for a header the file lacks, got %v", err)
+	}
+}
+
+func TestBatchColumnAliasesFromConfig(t *testing.T) {
+	dir := setupCommandTest(t)
+	cfg := "column_aliases:\n" +
+		"  summary: [Title, Subject]\n" +
+		"  start: [\"Date+Time\", \"Start Date+Start Time\"]\n" +
+		"  end: [\"End Date+End Time\"]\n" +
+		"  location: [Place]\n"
+	if err := os.MkdirAll(filepath.Join(dir, "tempus"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tempus", "config.yaml"), []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	input := filepath.Join(dir, "outlook.csv")
+	if err := os.WriteFile(input, []byte(outlookCSV), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "aliases.ics")
+	if err := runRootErr(t, "batch", "-i", input, "-o", output, "--strict-input"); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	// The file's own location column wins over the Place alias.
+	for _, want := range []string{"SUMMARY:Dentist", "DTSTART:20250501T090000Z", "DTEND:20250501T094500Z", "LOCATION:Clinic"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("output missing %q:\n%s", want, data)
+		}
+	}
+}
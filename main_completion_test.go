@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"tempus/internal/journal"
+)
+
+// runCompletion drives cobra's hidden __complete command the way shells do.
+func runCompletion(t *testing.T, args ...string) []string {
+	t.Helper()
+	t.Cleanup(func() { journalOp = journal.Op{} })
+	root := newRootCmd()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs(append([]string{"__complete"}, args...))
+	if err := root.Execute(); err != nil {
+		t.Fatalf("__complete %v failed: %v", args, err)
+	}
+	var values []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		if line == "" || strings.HasPrefix(line, ":") {
+			continue
+		}
+		name, _, _ := strings.Cut(line, "\t")
+		values = append(values, name)
+	}
+	return values
+}
+
+func TestDynamicCompletions(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"create", "--start-tz", "europe/mad"}, "Europe/Madrid"},
+		{[]string{"batch", "--default-tz", "America/New"}, "America/New_York"},
+		{[]string{"shift", "--to-tz", "Asia/Tok"}, "Asia/Tokyo"},
+		{[]string{"--timezone", "Europe/Dub"}, "Europe/Dublin"},
+		{[]string{"--language", ""}, "es"},
+		{[]string{"template", "create", ""}, "flight"},
+		{[]string{"template", "describe", "foc"}, "focus-block"},
+		{[]string{"completion", ""}, "powershell"},
+	}
+	for _, tt := range tests {
+		got := runCompletion(t, tt.args...)
+		found := false
+		for _, v := range got {
+			if v == tt.want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%v: completions %v missing %q", tt.args, got, tt.want)
+		}
+	}
+
+	if got := runCompletion(t, "create", "--start-tz", "Europe/Mad"); len(got) != 1 {
+		t.Errorf("expected a single match for Europe/Mad, got %v", got)
+	}
+}
+
+func TestCompletionCommandGeneratesScripts(t *testing.T) {
+	t.Cleanup(func() { journalOp = journal.Op{} })
+	for shell, want := range map[string]string{
+		"bash":       "bash completion V2 for tempus",
+		"zsh":        "#compdef tempus",
+		"fish":       "fish completion for tempus",
+		"powershell": "powershell completion for tempus",
+	} {
+		root := newRootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetArgs([]string{"completion", shell})
+		if err := root.Execute(); err != nil {
+			t.Fatalf("completion %s failed: %v", shell, err)
+		}
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("completion %s output missing %q", shell, want)
+		}
+	}
+
+	root := newRootCmd()
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"completion", "tcsh"})
+	if err := root.Execute(); err == nil {
+		t.Error("expected error for unsupported shell")
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCountdownWritesMilestones(t *testing.T) {
+	dir := setupCommandTest(t)
+	output := filepath.Join(dir, "thesis.ics")
+
+	got := runRootStdout(t, "countdown", "Thesis deadline", "--date", "2099-06-01", "--milestones", "30d,1d,2w", "--alarm-time", "08:30", "-o", output)
+	for _, want := range []string{
+		"4 countdown event(s) for Thesis deadline on Mon 06/01/2099:",
+		"Sat 05/02/2099  ⏳ Thesis deadline in 30 days",
+		"Sun 05/31/2099  ⏳ Thesis deadline tomorrow",
+		"Mon 06/01/2099  ⏳ Thesis deadline today",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("plan missing %q:\n%s", want, got)
+		}
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	ics := string(data)
+	for _, want := range []string{
+		"DTSTART;VALUE=DATE:20990502",
+		"DTSTART;VALUE=DATE:20990518",
+		"SUMMARY:⏳ Thesis deadline in 14 days",
+		"DESCRIPTION:Countdown to Thesis deadline on Mon 06/01/2099",
+		"CATEGORIES:Countdown",
+		"TRIGGER:PT8H30M",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+	if n := strings.Count(ics, "BEGIN:VALARM"); n != 4 {
+		t.Errorf("expected an alarm per event, got %d", n)
+	}
+}
+
+func TestCountdownWithoutTargetOrAlarms(t *testing.T) {
+	dir := setupCommandTest(t)
+	output := filepath.Join(dir, "visa.ics")
+
+	runRootStdout(t, "countdown", "Visa renewal", "--date", "2099-09-30", "--milestones", "1w", "--no-target", "--no-alarms", "-o", output)
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	ics := string(data)
+	if n := strings.Count(ics, "BEGIN:VEVENT"); n != 1 {
+		t.Errorf("expected only the milestone, got %d events", n)
+	}
+	if strings.Contains(ics, "BEGIN:VALARM") {
+		t.Error("--no-alarms should leave the alarms out")
+	}
+}
+
+func TestCountdownErrors(t *testing.T) {
+	setupCommandTest(t)
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"countdown", "Exam"}, "--date is required"},
+		{[]string{"countdown", "Exam", "--date", "June"}, "invalid --date"},
+		{[]string{"countdown", "Exam", "--date", "2099-06-01", "--milestones", "soon"}, "invalid --milestones"},
+		{[]string{"countdown", "Exam", "--date", "2099-06-01", "--alarm-time", "9am"}, "invalid --alarm-time"},
+		{[]string{"countdown", "Exam", "--date", "2000-06-01", "--no-target"}, "already passed"},
+	} {
+		if err := runRootErr(t, tc.args...); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v: expected %q, got %v", tc.args, tc.want, err)
+		}
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"tempus/internal/calendar"
+	"tempus/internal/config"
+	"tempus/internal/diag"
 	"tempus/internal/testutil"
 )
 
@@ -128,10 +130,10 @@ func TestCollectBatchWarnings(t *testing.T) {
 			hasConflicts := false
 			hasOverwhelm := false
 			for _, w := range warnings {
-				if strings.Contains(w, "conflict") {
+				if w.Code == diag.CodeConflict {
 					hasConflicts = true
 				}
-				if strings.Contains(w, "event load") || strings.Contains(w, "Days with high") {
+				if w.Code == diag.CodeOverload {
 					hasOverwhelm = true
 				}
 			}
@@ -160,7 +162,7 @@ func TestWriteBatchOutput(t *testing.T) {
 
 	tests := []struct {
 		name         string
-		warnings     []string
+		warnings     []diag.Warning
 		output       string
 		eventCount   int
 		wantErr      bool
@@ -168,15 +170,18 @@ func TestWriteBatchOutput(t *testing.T) {
 	}{
 		{
 			name:         "write with no warnings",
-			warnings:     []string{},
+			warnings:     nil,
 			output:       filepath.Join(tmpDir, "test1.ics"),
 			eventCount:   1,
 			wantErr:      false,
 			checkContent: true,
 		},
 		{
-			name:         "write with warnings",
-			warnings:     []string{"⚠️  Warning 1", "⚠️  Warning 2"},
+			name: "write with warnings",
+			warnings: []diag.Warning{
+				{Code: diag.CodeConflict, Severity: diag.SeverityWarning, Message: "Warning 1"},
+				{Code: diag.CodeOverload, Severity: diag.SeverityWarning, Message: "Warning 2", Suggestion: "spread them out"},
+			},
 			output:       filepath.Join(tmpDir, "test2.ics"),
 			eventCount:   1,
 			wantErr:      false,
@@ -184,14 +189,14 @@ func TestWriteBatchOutput(t *testing.T) {
 		},
 		{
 			name:       "write to subdirectory",
-			warnings:   []string{},
+			warnings:   nil,
 			output:     filepath.Join(tmpDir, "subdir", "test3.ics"),
 			eventCount: 1,
 			wantErr:    false,
 		},
 		{
 			name:       "multiple events",
-			warnings:   []string{},
+			warnings:   nil,
 			output:     filepath.Join(tmpDir, "test4.ics"),
 			eventCount: 5,
 			wantErr:    false,
@@ -205,7 +210,7 @@ func TestWriteBatchOutput(t *testing.T) {
 			r, w, _ := os.Pipe()
 			os.Stdout = w
 
-			err := writeBatchOutput(cal, tt.warnings, tt.output, tt.eventCount)
+			err := writeBatchOutput(cal, tt.warnings, &batchOptions{output: tt.output, outputFormat: "ics"}, tt.eventCount)
 
 			w.Close()
 			var buf bytes.Buffer
@@ -231,7 +236,7 @@ func TestWriteBatchOutput(t *testing.T) {
 
 				// Check warnings were printed
 				for _, warning := range tt.warnings {
-					if !strings.Contains(output, warning) {
+					if !strings.Contains(output, warning.Message) || !strings.Contains(output, warning.Suggestion) {
 						t.Errorf("writeBatchOutput() output missing warning %q", warning)
 					}
 				}
@@ -290,7 +295,7 @@ func TestWriteCalendarOutput(t *testing.T) {
 			r, w, _ := os.Pipe()
 			os.Stdout = w
 
-			err := writeCalendarOutput(cal, tt.output)
+			err := writeCalendarOutput(cal, tt.output, "ics", config.DefaultOutputPolicy())
 
 			w.Close()
 			var buf bytes.Buffer
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"tempus/internal/clock"
+)
+
+func TestCreateDryRunPreviewsWithoutWriting(t *testing.T) {
+	dir := setupCommandTest(t)
+	setClock(clock.Fixed(time.Date(2027, 3, 1, 12, 0, 0, 0, time.UTC)))
+	t.Cleanup(func() { setClock(nil) })
+
+	output := filepath.Join(dir, "standup.ics")
+	out := runRootStdout(t, "create", "Standup", "-s", "2027-03-28 02:30", "--duration", "15m",
+		"--start-tz", "Europe/Madrid", "--rrule", "FREQ=WEEKLY;COUNT=4", "--exdate", "2027-04-04 02:30",
+		"--alarm", "15m", "-o", output, "--dry-run")
+	for _, want := range []string{
+		"Standup",
+		"Sun 03/28/2027 02:30 Europe/Madrid",
+		"FREQ=WEEKLY;COUNT=4 (then Sun 04/11/2027, Sun 04/18/2027)",
+		"15 min before",
+		output + " (ics)",
+		"does not exist in Europe/Madrid",
+		"calendar apps will use 03:30",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("preview missing %q:\n%s", want, out)
+		}
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Error("--dry-run should not write the calendar")
+	}
+
+	// Invalid input still fails a dry run.
+	if err := runRootErr(t, "create", "Bad", "-s", "not a date", "--dry-run"); err == nil {
+		t.Error("a dry run should validate the start time")
+	}
+
+	out = runRootStdout(t, "create", "Old", "-s", "2015-03-28 10:00", "--dry-run", "--output-format", "json")
+	var summary batchSummary
+	if err := json.Unmarshal([]byte(out), &summary); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if !summary.DryRun || len(summary.Warnings) != 1 || summary.Warnings[0].Code != "past" {
+		t.Errorf("JSON dry run = %+v", summary)
+	}
+}
+
+func TestCreateDryRunRuleEndingBeforeStart(t *testing.T) {
+	setupCommandTest(t)
+	out := runRootStdout(t, "create", "Gym", "-s", "2030-03-04 09:00",
+		"--rrule", "FREQ=DAILY;UNTIL=20200101T000000Z", "--dry-run")
+	if !strings.Contains(out, "FREQ=DAILY;UNTIL=20200101T000000Z") {
+		t.Errorf("preview does not show the rule:\n%s", out)
+	}
+}
+
+func TestCreatePrintWritesStdoutAndFile(t *testing.T) {
+	dir := setupCommandTest(t)
+	output := filepath.Join(dir, "call.ics")
+	out := runRootStdout(t, "create", "Call", "-s", "2030-01-10 09:00", "-o", output, "--print")
+	if !strings.HasPrefix(out, "BEGIN:VCALENDAR") || strings.Contains(out, "Created") {
+		t.Errorf("--print should put only the calendar on stdout:\n%s", out)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil || string(data) != out {
+		t.Errorf("--print with --output should also write the same calendar (%v)", err)
+	}
+
+	if err := runRootErr(t, "create", "Call", "-s", "2030-01-10 09:00", "--print", "--dry-run"); err == nil {
+		t.Error("--print and --dry-run should be mutually exclusive")
+	}
+	if err := runRootErr(t, "create", "Call", "-s", "2030-01-10 09:00", "-o", output, "--print", "--output-format", "json"); err == nil {
+		t.Error("--print with a JSON summary should be rejected")
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var uidLine = regexp.MustCompile(`(?m)^UID:(.*?)\r?$`)
+
+func runBatchCSV(t *testing.T, dir, name, csvData string, stable bool) (string, error) {
+	t.Helper()
+	inputPath := filepath.Join(dir, name+".csv")
+	outputPath := filepath.Join(dir, name+".ics")
+	if err := os.WriteFile(inputPath, []byte(csvData), 0644); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+	cmd := newBatchCmd()
+	mustSetFlag(t, cmd, "input", inputPath)
+	mustSetFlag(t, cmd, "output", outputPath)
+	if stable {
+		mustSetFlag(t, cmd, "stable-uids", "true")
+	}
+	if err := runBatch(cmd, nil); err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	return string(data), nil
+}
+
+func batchUIDs(ics string) []string {
+	var uids []string
+	for _, m := range uidLine.FindAllStringSubmatch(ics, -1) {
+		uids = append(uids, m[1])
+	}
+	return uids
+}
+
+func TestBatchStableUIDsSurviveRegeneration(t *testing.T) {
+	dir := t.TempDir()
+	csvData := strings.Join([]string{
+		"summary,start,end,start_tz",
+		`"Standup","2025-05-01 09:00","2025-05-01 09:15","Europe/Madrid"`,
+		`"Standup","2025-05-01 09:00","2025-05-01 09:15","Europe/Madrid"`,
+		`"Review","2025-05-02 10:00","2025-05-02 11:00","Europe/Madrid"`,
+	}, "\n")
+
+	first, err := runBatchCSV(t, dir, "first", csvData, true)
+	if err != nil {
+		t.Fatalf("runBatch failed: %v", err)
+	}
+	second, err := runBatchCSV(t, dir, "second", csvData, true)
+	if err != nil {
+		t.Fatalf("runBatch failed: %v", err)
+	}
+
+	a, b := batchUIDs(first), batchUIDs(second)
+	if len(a) != 3 || strings.Join(a, ",") != strings.Join(b, ",") {
+		t.Fatalf("UIDs differ between runs:\n%v\n%v", a, b)
+	}
+	if a[0] == a[1] || !strings.HasSuffix(a[1], "-2@tempus") {
+		t.Errorf("identical rows should get a -2 suffix, got %v", a)
+	}
+}
+
+func TestBatchUIDColumn(t *testing.T) {
+	dir := t.TempDir()
+	ics, err := runBatchCSV(t, dir, "explicit", strings.Join([]string{
+		"uid,summary,start,start_tz",
+		`"gym-monday@example.com","Gym","2025-05-05 07:00","Europe/Madrid"`,
+		`,"Swim","2025-05-06 07:00","Europe/Madrid"`,
+	}, "\n"), true)
+	if err != nil {
+		t.Fatalf("runBatch failed: %v", err)
+	}
+	if uids := batchUIDs(ics); len(uids) != 2 || uids[0] != "gym-monday@example.com" {
+		t.Errorf("uid column not honoured: %v", uids)
+	}
+
+	_, err = runBatchCSV(t, dir, "dup", strings.Join([]string{
+		"uid,summary,start,start_tz",
+		`"same","Gym","2025-05-05 07:00","Europe/Madrid"`,
+		`"same","Swim","2025-05-06 07:00","Europe/Madrid"`,
+	}, "\n"), false)
+	if err == nil || !strings.Contains(err.Error(), `duplicate uid "same"`) {
+		t.Errorf("expected duplicate uid error, got %v", err)
+	}
+}
+
+func TestDedupeCommand(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "cal.ics")
+	event := "BEGIN:VEVENT\nUID:1\nSUMMARY:Gym\nDTSTART:20250101T070000Z\nEND:VEVENT\n"
+	if err := os.WriteFile(input, []byte("BEGIN:VCALENDAR\n"+event+event+"END:VCALENDAR\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newDedupeCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	mustSetFlag(t, cmd, "input", input)
+	if err := runDedupe(cmd, nil); err != nil {
+		t.Fatalf("dedupe failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "cal-deduped.ics"))
+	if err != nil {
+		t.Fatalf("expected default output file: %v", err)
+	}
+	if got := strings.Count(string(data), "BEGIN:VEVENT"); got != 1 {
+		t.Errorf("kept %d events, want 1:\n%s", got, data)
+	}
+	if !strings.Contains(out.String(), "Removed 1 duplicate(s)") {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+
+	mustSetFlag(t, cmd, "by", "bogus")
+	if err := runDedupe(cmd, nil); err == nil {
+		t.Error("expected an error for an invalid --by")
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tempus/internal/calendar"
+)
+
+func writeDiffTestFiles(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.ics")
+	newPath := filepath.Join(dir, "new.ics")
+	oldICS := "BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:1\nSUMMARY:Gym\nDTSTART:20250101T070000Z\nDTEND:20250101T080000Z\nEND:VEVENT\nEND:VCALENDAR\n"
+	newICS := "BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:1\nSUMMARY:Gym\nDTSTART:20250101T073000Z\nDTEND:20250101T083000Z\nEND:VEVENT\n" +
+		"BEGIN:VEVENT\nUID:2\nSUMMARY:Swim\nDTSTART:20250102T070000Z\nDTEND:20250102T080000Z\nEND:VEVENT\nEND:VCALENDAR\n"
+	if err := os.WriteFile(oldPath, []byte(oldICS), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(newPath, []byte(newICS), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return oldPath, newPath
+}
+
+func TestDiffCommandTextOutput(t *testing.T) {
+	oldPath, newPath := writeDiffTestFiles(t)
+
+	cmd := newDiffCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	if err := runDiff(cmd, []string{oldPath, newPath}); err != nil {
+		t.Fatalf("diff failed: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{"+ Swim", "~ Gym", `DTSTART: "20250101T070000Z" → "20250101T073000Z"`, "1 added, 0 removed, 1 modified, 0 unchanged"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+
+	mustSetFlag(t, cmd, "exit-code", "true")
+	if err := runDiff(cmd, []string{oldPath, newPath}); err == nil {
+		t.Error("expected --exit-code to fail when calendars differ")
+	}
+	if err := runDiff(cmd, []string{oldPath, oldPath}); err != nil {
+		t.Errorf("identical calendars should pass --exit-code: %v", err)
+	}
+}
+
+func TestDiffCommandJSONOutput(t *testing.T) {
+	oldPath, newPath := writeDiffTestFiles(t)
+
+	cmd := newDiffCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	mustSetFlag(t, cmd, "json", "true")
+	if err := runDiff(cmd, []string{oldPath, newPath}); err != nil {
+		t.Fatalf("diff failed: %v", err)
+	}
+
+	var diff calendar.CalendarDiff
+	if err := json.Unmarshal(out.Bytes(), &diff); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(diff.Added) != 1 || len(diff.Modified) != 1 || len(diff.Modified[0].Changes) != 2 {
+		t.Errorf("unexpected diff: %+v", diff)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tempus/internal/output"
+)
+
+// runDoctorJSON runs tempus doctor --output-format json and decodes its checklist.
+func runDoctorJSON(t *testing.T, args ...string) ([]doctorCheck, error) {
+	t.Helper()
+	t.Cleanup(func() { output.Configure(output.Options{}) })
+	root := newRootCmd()
+	var out bytes.Buffer
+	root.SetArgs(append([]string{"doctor", "--output-format", "json"}, args...))
+	root.SetOut(&out)
+	root.SetErr(&bytes.Buffer{})
+	err := root.Execute()
+	var checks []doctorCheck
+	if jerr := json.Unmarshal(out.Bytes(), &checks); jerr != nil {
+		t.Fatalf("doctor output is not JSON: %v\n%s", jerr, out.String())
+	}
+	return checks, err
+}
+
+func doctorStatus(checks []doctorCheck, name string) string {
+	for _, c := range checks {
+		if c.Name == name {
+			return c.Status
+		}
+	}
+	return ""
+}
+
+func TestDoctorPassesOnDefaults(t *testing.T) {
+	dir := setupCommandTest(t)
+	if err := os.MkdirAll(filepath.Join(dir, "tempus"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := "language: es\noutput:\n  dir: " + dir + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "tempus", "config.yaml"), []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	checks, err := runDoctorJSON(t, "--templates-dir", filepath.Join(dir, "none"))
+	if err != nil {
+		t.Fatalf("doctor should pass with defaults: %v\n%+v", err, checks)
+	}
+	for name, want := range map[string]string{"config": doctorPass, "locale": doctorPass, "templates": doctorSkip, "output": doctorPass} {
+		if got := doctorStatus(checks, name); got != want {
+			t.Errorf("%s = %q, want %q (%+v)", name, got, want, checks)
+		}
+	}
+}
+
+func TestDoctorReportsBrokenSetup(t *testing.T) {
+	dir := setupCommandTest(t)
+	blocker := filepath.Join(dir, "not-a-dir")
+	if err := os.WriteFile(blocker, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := "language: xx\noutput:\n  dir: " + blocker + "\n"
+	if err := os.MkdirAll(filepath.Join(dir, "tempus"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tempus", "config.yaml"), []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	templates := filepath.Join(dir, "templates")
+	if err := os.MkdirAll(templates, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(templates, "bad.yaml"), []byte("name: [unclosed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	checks, err := runDoctorJSON(t, "--templates-dir", templates)
+	if err == nil || !strings.Contains(err.Error(), "check(s) failed") {
+		t.Fatalf("expected failed checks, got %v", err)
+	}
+	for _, name := range []string{"config", "locale", "templates", "output"} {
+		if got := doctorStatus(checks, name); got != doctorFail {
+			t.Errorf("%s = %q, want fail (%+v)", name, got, checks)
+		}
+	}
+
+	// A value of the wrong type fails the config check with the key named.
+	if err := os.WriteFile(filepath.Join(dir, "tempus", "config.yaml"), []byte("energy_budget: lots\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	checks, _ = runDoctorJSON(t, "--templates-dir", filepath.Join(dir, "none"))
+	if doctorStatus(checks, "config") != doctorFail || !strings.Contains(checks[0].Detail, "energy_budget") {
+		t.Errorf("expected a config failure naming energy_budget, got %+v", checks[0])
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEmitDurationOnCreateAndBatch(t *testing.T) {
+	dir := setupCommandTest(t)
+	output := filepath.Join(dir, "workshop.ics")
+	if err := runRootErr(t, "create", "Workshop", "--start", "2025-03-29 10:00", "--end", "2025-03-31 12:00",
+		"--start-tz", "Europe/Madrid", "--emit-duration", "-o", output); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	ics := string(data)
+	if !strings.Contains(ics, "DURATION:P2DT2H\r\n") || strings.Contains(ics, "DTEND") {
+		t.Errorf("expected DURATION in place of DTEND:\n%s", ics)
+	}
+	if err := runRootErr(t, "lint", "--file", output); err != nil {
+		t.Errorf("a DURATION event should lint clean: %v", err)
+	}
+
+	input := filepath.Join(dir, "offsite.csv")
+	if err := os.WriteFile(input, []byte("summary,start,end,all_day\nOffsite,2025-05-01,2025-05-02,true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	batchOut := filepath.Join(dir, "offsite.ics")
+	if err := runRootErr(t, "batch", "-i", input, "-o", batchOut, "--emit-duration"); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	data, _ = os.ReadFile(batchOut)
+	if !strings.Contains(string(data), "DURATION:P2D\r\n") {
+		t.Errorf("an inclusive two-day event should last P2D:\n%s", data)
+	}
+}
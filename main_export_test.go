@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestExportCSVRoundTripsThroughBatch(t *testing.T) {
+	dir := t.TempDir()
+	csvData := strings.Join([]string{
+		"summary,start,duration,start_tz,end_tz,end,location,all_day,rrule,exdate,categories,alarms",
+		`Standup,2025-05-05 09:00,15m,Europe/Madrid,,,"Room 1",,FREQ=WEEKLY;BYDAY=MO,2025-05-12 09:00,work|meeting,-10m`,
+		`Flight,2025-05-06 10:00,,Europe/Madrid,Europe/London,2025-05-06 12:30,,,,,travel,`,
+		`Holiday,2025-05-08,,,,2025-05-09,,true,,,personal,`,
+	}, "\n")
+	original, err := runBatchCSV(t, dir, "original", csvData, true)
+	if err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+
+	exported := filepath.Join(dir, "exported.csv")
+	cmd := newExportCmd()
+	cmd.SetErr(&bytes.Buffer{})
+	mustSetFlag(t, cmd, "input", filepath.Join(dir, "original.ics"))
+	mustSetFlag(t, cmd, "output", exported)
+	if err := runExport(cmd, nil); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	data, err := os.ReadFile(exported)
+	if err != nil {
+		t.Fatalf("failed to read export: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "uid,summary,start,end,") {
+		t.Errorf("export should use the batch columns:\n%s", data)
+	}
+
+	roundTrip, err := runBatchCSV(t, dir, "roundtrip", string(data), false)
+	if err != nil {
+		t.Fatalf("batch of exported CSV failed: %v", err)
+	}
+	if got, want := batchUIDs(roundTrip), batchUIDs(original); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("UIDs changed on round-trip: %v vs %v", got, want)
+	}
+
+	diff := newDiffCmd()
+	var out bytes.Buffer
+	diff.SetOut(&out)
+	mustSetFlag(t, diff, "exit-code", "true")
+	if err := runDiff(diff, []string{filepath.Join(dir, "original.ics"), filepath.Join(dir, "roundtrip.ics")}); err != nil {
+		t.Errorf("round-trip changed the calendar: %v\n%s", err, out.String())
+	}
+}
+
+func TestExportMarkdownAgenda(t *testing.T) {
+	dir := t.TempDir()
+	csvData := "summary,start,duration,start_tz,location\nReview,2025-05-02 15:00,1h,UTC,\"A|B\"\nStandup,2025-05-01 09:00,15m,UTC,\n"
+	if _, err := runBatchCSV(t, dir, "week", csvData, false); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+
+	cmd := newExportCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	mustSetFlag(t, cmd, "input", filepath.Join(dir, "week.ics"))
+	if err := runExport(cmd, nil); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{"# week", "| Thu 05/01/2025 | 09:00–09:15 UTC |", `| A\|B |`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("agenda missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Index(got, "Standup") > strings.Index(got, "Review") {
+		t.Errorf("agenda should be in start order:\n%s", got)
+	}
+
+	mustSetFlag(t, cmd, "format", "xml")
+	if err := runExport(cmd, nil); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestExportHTMLAgenda(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "family.csv")
+	csvData := "summary,start,duration,start_tz,location,categories,rrule\n" +
+		"Piano <lesson>,2025-05-02 17:00,45m,UTC,Music school,Music|Kids,FREQ=WEEKLY\n" +
+		"Dentist,2025-05-01 09:00,30m,UTC,,Health,\n" +
+		"Homework,2025-05-02 18:00,1h,UTC,,,\n"
+	if err := os.WriteFile(csvPath, []byte(csvData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A batch file goes straight in; the format can be the first argument.
+	cmd := newExportCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	mustSetFlag(t, cmd, "input", csvPath)
+	if err := runExport(cmd, []string{"html"}); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"<!DOCTYPE html>", "<title>family</title>",
+		"<h2>Thu 05/01/2025</h2>", "<h2>Fri 05/02/2025</h2>",
+		"Piano &lt;lesson&gt;", "(repeats)", "📍 Music school",
+		`<span class="tag" style="background: #4e79a7">Health</span>`,
+		`<div class="event" style="border-left-color: #59a14f">`, // Music, the third category
+		`<div class="event" style="border-left-color: #bab0ac">`, // no category
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("HTML agenda missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Index(got, "Dentist") > strings.Index(got, "Homework") || strings.Count(got, "<section") != 2 {
+		t.Errorf("agenda should have one section per day, in start order:\n%s", got)
+	}
+
+	mustSetFlag(t, cmd, "format", "md")
+	if err := runExport(cmd, []string{"html"}); err == nil {
+		t.Error("expected error when the argument and --format disagree")
+	}
+}
+
+func TestExportWeeklyPlanner(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "week.ics")
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT", "UID:1", "SUMMARY:Swim", "CATEGORIES:Sport", "DTSTART:20250903T090000Z", "DTEND:20250903T100000Z", "RRULE:FREQ=WEEKLY", "END:VEVENT",
+		"BEGIN:VEVENT", "UID:2", "SUMMARY:Call", "DTSTART:20250903T093000Z", "DTEND:20250903T103000Z", "END:VEVENT",
+		"BEGIN:VEVENT", "UID:3", "SUMMARY:Night shift", "DTSTART:20250905T220000Z", "DTEND:20250906T060000Z", "END:VEVENT",
+		"BEGIN:VEVENT", "UID:4", "SUMMARY:Fair", "DTSTART;VALUE=DATE:20250906", "DTEND;VALUE=DATE:20250908", "END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	if err := os.WriteFile(path, []byte(ics), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newExportCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	mustSetFlag(t, cmd, "input", path)
+	mustSetFlag(t, cmd, "from", "2025-09-04")
+	mustSetFlag(t, cmd, "weeks", "2")
+	if err := runExport(cmd, []string{"planner"}); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	got := out.String()
+	if strings.Count(got, `<section class="week">`) != 2 || !strings.Contains(got, "Week of Mon 09/01/2025 – Sun 09/07/2025") {
+		t.Errorf("want two weeks starting on Monday 1 September:\n%s", got)
+	}
+	if strings.Count(got, "Swim") != 2 {
+		t.Errorf("the weekly event should show in both weeks:\n%s", got)
+	}
+	// Swim and Call overlap, so they share the column.
+	if strings.Count(got, "width: 50.00%") != 2 || !strings.Contains(got, "left: 50.00%; width: 50.00%\"><b>09:30–10:30</b>Call") {
+		t.Errorf("overlapping events should sit side by side:\n%s", got)
+	}
+	// The night shift stretches the grid to midnight and shows on both days;
+	// the fair is an all-day event on Saturday and Sunday.
+	if strings.Count(got, "Night shift") != 2 || !strings.Contains(got, ">23:00</span>") || !strings.Contains(got, ">00:00</span>") {
+		t.Errorf("overnight events should be cut at midnight and stretch the grid:\n%s", got)
+	}
+	if strings.Count(got, ">Fair</div>") != 2 {
+		t.Errorf("all-day event should fill both of its days:\n%s", got)
+	}
+
+	plain := newExportCmd()
+	plain.SetOut(&bytes.Buffer{})
+	mustSetFlag(t, plain, "input", path)
+	mustSetFlag(t, plain, "weeks", "2")
+	if err := runExport(plain, []string{"md"}); err == nil {
+		t.Error("--weeks should be rejected outside the planner")
+	}
+}
+
+func TestExportAgendaDisplayFormats(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	csvData := "summary,start,duration,start_tz\nStandup,2025-05-01 14:00,15m,UTC\n"
+	if _, err := runBatchCSV(t, dir, "week", csvData, false); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	input := filepath.Join(dir, "week.ics")
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "| Thu 05/01/2025 | 14:00–14:15 UTC |"},
+		{[]string{"--language", "es"}, "| Thu 01/05/2025 | 14:00–14:15 UTC |"},
+		{[]string{"--date-format", "YYYY-MM-DD", "--time-format", "12h"}, "| Thu 2025-05-01 | 2:00 PM–2:15 PM UTC |"},
+	}
+	for _, tt := range tests {
+		got := runRootStdout(t, append([]string{"export", "-i", input}, tt.args...)...)
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("export %v missing %q:\n%s", tt.args, tt.want, got)
+		}
+	}
+
+	// The config applies when no flag is given, and the flags override it.
+	configDir := filepath.Join(dir, "tempus")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte("date_format: DD.MM.YYYY\ntime_format: 12h\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	viper.Reset()
+	if got := runRootStdout(t, "export", "-i", input); !strings.Contains(got, "| Thu 01.05.2025 | 2:00 PM–2:15 PM UTC |") {
+		t.Errorf("expected the configured formats:\n%s", got)
+	}
+	if got := runRootStdout(t, "export", "-i", input, "--time-format", "24h"); !strings.Contains(got, "| Thu 01.05.2025 | 14:00–14:15 UTC |") {
+		t.Errorf("expected --time-format to override the config:\n%s", got)
+	}
+
+	root := newRootCmd()
+	t.Cleanup(resetUI)
+	root.SetArgs([]string{"export", "-i", input, "--date-format", "someday"})
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	if err := root.Execute(); err == nil || !strings.Contains(err.Error(), "--date-format") {
+		t.Errorf("expected a --date-format error, got %v", err)
+	}
+}
+
+func TestBatchAndExportWriteJCalAndXCal(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "week.csv")
+	csvData := "summary,start,duration,start_tz,categories\nStandup,2025-05-01 09:00,15m,Europe/Madrid,work\n"
+	if err := os.WriteFile(inputPath, []byte(csvData), 0644); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+
+	jcalPath := filepath.Join(dir, "week.jcal")
+	cmd := newBatchCmd()
+	mustSetFlag(t, cmd, "input", inputPath)
+	mustSetFlag(t, cmd, "output", jcalPath)
+	if err := runBatch(cmd, nil); err != nil {
+		t.Fatalf("runBatch returned error: %v", err)
+	}
+	data, err := os.ReadFile(jcalPath)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	var doc []any
+	if err := json.Unmarshal(data, &doc); err != nil || len(doc) != 3 || doc[0] != "vcalendar" {
+		t.Fatalf("expected a jCal document, got %v:\n%s", err, data)
+	}
+	if !strings.Contains(string(data), `"tzid": "Europe/Madrid"`) {
+		t.Errorf("jCal missing TZID parameter:\n%s", data)
+	}
+
+	icsPath := filepath.Join(dir, "week.ics")
+	cmd = newBatchCmd()
+	mustSetFlag(t, cmd, "input", inputPath)
+	mustSetFlag(t, cmd, "output", icsPath)
+	if err := runBatch(cmd, nil); err != nil {
+		t.Fatalf("runBatch returned error: %v", err)
+	}
+	export := newExportCmd()
+	var out bytes.Buffer
+	export.SetOut(&out)
+	mustSetFlag(t, export, "input", icsPath)
+	mustSetFlag(t, export, "format", "xcal")
+	if err := runExport(export, nil); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if err := xml.Unmarshal(out.Bytes(), new(any)); err != nil {
+		t.Fatalf("invalid xCal: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "<dtstart><parameters><tzid><text>Europe/Madrid</text></tzid></parameters><date-time>2025-05-01T09:00:00</date-time></dtstart>") {
+		t.Errorf("xCal missing DTSTART:\n%s", out.String())
+	}
+
+	if _, err := calendarOutputFormat("pdf", "out.ics"); err == nil {
+		t.Error("expected error for unsupported output format")
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const fetchTestICS = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//Test//EN\r\n" +
+	"BEGIN:VEVENT\r\nUID:1\r\nDTSTAMP:20250101T000000Z\r\nSUMMARY:Standup\r\nCATEGORIES:work\r\n" +
+	"DTSTART;TZID=W. Europe Standard Time:20250101T090000\r\nDTEND;TZID=W. Europe Standard Time:20250101T091500\r\nEND:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\nUID:2\r\nDTSTAMP:20250101T000000Z\r\nSUMMARY:Call\r\n" +
+	"DTSTART;TZID=Europe/Kiev:20250102T100000\r\nDTEND;TZID=Europe/Kiev:20250102T103000\r\nEND:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func newFetchTestServer(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.ics" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/calendar")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetchSavesNormalizedFeed(t *testing.T) {
+	srv := newFetchTestServer(t, fetchTestICS)
+	output := filepath.Join(t.TempDir(), "team.ics")
+
+	cmd := newFetchCmd()
+	var stderr bytes.Buffer
+	cmd.SetErr(&stderr)
+	mustSetFlag(t, cmd, "output", output)
+	mustSetFlag(t, cmd, "tz-map", "W. Europe Standard Time=Europe/Berlin")
+	mustSetFlag(t, cmd, "emoji", "true")
+	if err := runFetch(cmd, []string{srv.URL + "/team.ics"}); err != nil {
+		t.Fatalf("fetch failed: %v\n%s", err, stderr.String())
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	ics := string(data)
+	for _, want := range []string{
+		"DTSTART;TZID=Europe/Berlin:20250101T090000",
+		"DTSTART;TZID=Europe/Kyiv:20250102T100000",
+		"SUMMARY:💼 Standup",
+		"SUMMARY:Call",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("output missing %q:\n%s", want, ics)
+		}
+	}
+	for _, want := range []string{"TZID Europe/Kiev → Europe/Kyiv", "Fetched 2 event(s)"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("stderr missing %q:\n%s", want, stderr.String())
+		}
+	}
+}
+
+func TestFetchRejectsFeedsThatFailLint(t *testing.T) {
+	broken := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:1\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	srv := newFetchTestServer(t, broken)
+	output := filepath.Join(t.TempDir(), "broken.ics")
+
+	cmd := newFetchCmd()
+	cmd.SetErr(&bytes.Buffer{})
+	mustSetFlag(t, cmd, "output", output)
+	if err := runFetch(cmd, []string{srv.URL + "/broken.ics"}); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected lint failure, got %v", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Error("feed that failed lint should not be saved")
+	}
+
+	mustSetFlag(t, cmd, "force", "true")
+	if err := runFetch(cmd, []string{srv.URL + "/broken.ics"}); err != nil {
+		t.Fatalf("--force should save the feed: %v", err)
+	}
+	if _, err := os.Stat(output); err != nil {
+		t.Errorf("expected output with --force: %v", err)
+	}
+
+	if err := runFetch(cmd, []string{srv.URL + "/missing.ics"}); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected HTTP status error, got %v", err)
+	}
+}
+
+func TestNormalizeFeedURL(t *testing.T) {
+	tests := map[string]string{
+		"webcal://example.com/team.ics": "https://example.com/team.ics",
+		"WEBCALS://example.com/a?b=c":   "https://example.com/a?b=c",
+		" http://localhost:8080/x.ics ": "http://localhost:8080/x.ics",
+	}
+	for in, want := range tests {
+		if got, err := normalizeFeedURL(in); err != nil || got != want {
+			t.Errorf("normalizeFeedURL(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"ftp://example.com/a.ics", "team.ics", "https://"} {
+		if _, err := normalizeFeedURL(bad); err == nil {
+			t.Errorf("normalizeFeedURL(%q) should fail", bad)
+		}
+	}
+	if got := feedFileName("https://example.com/cal/team"); got != "team.ics" {
+		t.Errorf("feedFileName = %q, want team.ics", got)
+	}
+	if got := feedFileName("https://example.com/"); got != "feed.ics" {
+		t.Errorf("feedFileName = %q, want feed.ics", got)
+	}
+}
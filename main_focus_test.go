@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func setupCommandTest(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	return dir
+}
+
+// runRootErr runs tempus with args, discarding its output, and returns the error.
+func runRootErr(t *testing.T, args ...string) error {
+	t.Helper()
+	t.Cleanup(func() { journalOp.ID = "" })
+	root := newRootCmd()
+	root.SetArgs(args)
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	return root.Execute()
+}
+
+func TestFocusWritesSessionsAndBreaks(t *testing.T) {
+	dir := setupCommandTest(t)
+	output := filepath.Join(dir, "focus.ics")
+
+	got := runRootStdout(t, "focus", "--start", "2025-05-01 09:00", "--work", "50m", "--break", "10m",
+		"--cycles", "3", "--long-break", "20m", "--long-break-every", "2", "--tz", "Europe/Madrid", "-o", output)
+	if !strings.Contains(got, "3 focus session(s), Thu 05/01/2025 09:00–12:00:") ||
+		!strings.Contains(got, "10:50–11:10  ☕ Long break") {
+		t.Errorf("unexpected plan:\n%s", got)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	ics := string(data)
+	for _, want := range []string{
+		"SUMMARY:🎯 Focus 1/3",
+		"DTSTART;TZID=Europe/Madrid:20250501T090000",
+		"DTEND;TZID=Europe/Madrid:20250501T095000",
+		"SUMMARY:☕ Break\r\nDTSTART;TZID=Europe/Madrid:20250501T095000",
+		"CATEGORIES:Break",
+		"DESCRIPTION:Focus session 3 of 3 starts",
+		"DTEND;TZID=Europe/Madrid:20250501T120000",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+	if n := strings.Count(ics, "BEGIN:VEVENT"); n != 5 {
+		t.Errorf("expected 3 sessions and 2 breaks, got %d events", n)
+	}
+	if n := strings.Count(ics, "BEGIN:VALARM"); n != 5 {
+		t.Errorf("expected one alarm per block, got %d", n)
+	}
+
+	// The same run again collides on its UIDs instead of duplicating events.
+	err = runRootErr(t, "focus", "--start", "2025-05-01 09:00", "--tz", "Europe/Madrid", "-o", output, "--append")
+	if err == nil || !strings.Contains(err.Error(), "already") {
+		t.Errorf("expected a UID collision, got %v", err)
+	}
+}
+
+func TestFocusAppendsRowsToBatchFiles(t *testing.T) {
+	dir := setupCommandTest(t)
+	csvPath := filepath.Join(dir, "week.csv")
+	if err := os.WriteFile(csvPath, []byte("summary,start,duration,start_tz,categories,alarms,location\nStandup,2025-05-01 08:30,15m,Europe/Madrid,Work,,Office"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	runRootStdout(t, "focus", "--start", "2025-05-01 09:00", "--cycles", "2", "--tz", "Europe/Madrid", "--alarm", "-1m", "--batch", csvPath)
+	data, _ := os.ReadFile(csvPath)
+	want := "Standup,2025-05-01 08:30,15m,Europe/Madrid,Work,,Office\n" +
+		"Focus 1/2,2025-05-01 09:00,25m,Europe/Madrid,Focus,-1m,\n" +
+		"Break,2025-05-01 09:25,5m,Europe/Madrid,Break,-1m,\n" +
+		"Focus 2/2,2025-05-01 09:30,25m,Europe/Madrid,Focus,-1m,\n"
+	if !strings.HasSuffix(string(data), want) {
+		t.Errorf("unexpected CSV:\n%s", data)
+	}
+	records, err := loadBatchFromCSV(csvPath)
+	if err != nil || len(records) != 4 || records[2].Summary != "Break" || records[2].Alarms[0] != "-1m" {
+		t.Errorf("appended rows do not load back: %+v, %v", records, err)
+	}
+
+	jsonPath := filepath.Join(dir, "week.json")
+	runRootStdout(t, "focus", "--start", "2025-05-01 09:00", "--cycles", "1", "--no-alarm", "--batch", jsonPath)
+	runRootStdout(t, "focus", "--start", "2025-05-02 09:00", "--cycles", "1", "--no-alarm", "--batch", jsonPath)
+	data, _ = os.ReadFile(jsonPath)
+	var items []map[string]any
+	if err := json.Unmarshal(data, &items); err != nil || len(items) != 2 {
+		t.Fatalf("expected a JSON list of 2 rows, got %v:\n%s", err, data)
+	}
+	if items[1]["start"] != "2025-05-02 09:00" || items[1]["alarms"] != nil {
+		t.Errorf("unexpected JSON row %v", items[1])
+	}
+
+	yamlPath := filepath.Join(dir, "week.yaml")
+	if err := os.WriteFile(yamlPath, []byte("# my week\n- summary: Gym\n  start: 2025-05-01 07:00\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	runRootStdout(t, "focus", "--start", "2025-05-01 09:00", "--cycles", "1", "--batch", yamlPath)
+	data, _ = os.ReadFile(yamlPath)
+	if !strings.HasPrefix(string(data), "# my week\n- summary: Gym\n") {
+		t.Errorf("existing YAML was not kept:\n%s", data)
+	}
+	records, err = loadBatchFromYAML(yamlPath)
+	if err != nil || len(records) != 2 || records[1].Duration != "25m" || records[1].Categories[0] != "Focus" {
+		t.Errorf("appended YAML rows do not load back: %+v, %v", records, err)
+	}
+}
+
+func TestFocusBatchNeedsMatchingColumns(t *testing.T) {
+	dir := setupCommandTest(t)
+	csvPath := filepath.Join(dir, "week.csv")
+	if err := os.WriteFile(csvPath, []byte("summary,start,duration\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := runRootErr(t, "focus", "--start", "2025-05-01 09:00", "--tz", "UTC", "--batch", csvPath)
+	if err == nil || !strings.Contains(err.Error(), "no alarms, categories, start_tz column") {
+		t.Errorf("expected a missing column error, got %v", err)
+	}
+}
+
+func TestFocusRejectsBadFlags(t *testing.T) {
+	setupCommandTest(t)
+	for _, args := range [][]string{
+		{"focus"},
+		{"focus", "--start", "nine"},
+		{"focus", "--start", "09:00", "--cycles", "0"},
+		{"focus", "--start", "09:00", "--work", "soon"},
+		{"focus", "--start", "09:00", "--alarm", "2025-05-01 08:00"},
+	} {
+		if err := runRootErr(t, append(args, "--dry-run")...); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
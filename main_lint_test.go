@@ -1,9 +1,16 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
+
+	"tempus/internal/diag"
+	"tempus/internal/i18n"
 )
 
 func TestLintSucceedsOnValidICS(t *testing.T) {
@@ -54,3 +61,153 @@ END:VCALENDAR
 		t.Fatal("expected lint error for missing DTSTART, got nil")
 	}
 }
+
+func TestLintStructuredOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "invalid.ics")
+	content := "BEGIN:VCALENDAR\nVERSION:2.0\nBEGIN:VEVENT\nUID:test-3\nSUMMARY:No start\nDTEND:20250101T110000Z\nEND:VEVENT\nEND:VCALENDAR\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write ICS: %v", err)
+	}
+
+	cmd := newLintCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	mustSetFlag(t, cmd, "file", path)
+	mustSetFlag(t, cmd, "format", "json")
+	if err := runLint(cmd, nil); err == nil {
+		t.Fatal("expected lint to fail")
+	}
+	var findings []diag.Warning
+	if err := json.Unmarshal(out.Bytes(), &findings); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out.String())
+	}
+	if len(findings) != 1 || findings[0].Code != diag.CodeMissingProperty || findings[0].Line != 3 || findings[0].File != path {
+		t.Errorf("findings = %+v", findings)
+	}
+
+	out.Reset()
+	mustSetFlag(t, cmd, "format", "sarif")
+	_ = runLint(cmd, nil)
+	if !strings.Contains(out.String(), `"ruleId": "missing-property"`) || !strings.Contains(out.String(), `"startLine": 3`) {
+		t.Errorf("unexpected SARIF output:\n%s", out.String())
+	}
+
+	mustSetFlag(t, cmd, "format", "xml")
+	if err := runLint(cmd, nil); err == nil {
+		t.Error("expected an error for an unknown --format")
+	}
+}
+
+func TestLintStrictChecksGrammar(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "loose.ics")
+	// Passes the default lint: LF line endings, a date without VALUE=DATE and
+	// an unknown RRULE part.
+	content := "BEGIN:VCALENDAR\nVERSION:2.0\nBEGIN:VEVENT\nUID:test-4\nSUMMARY:Loose\n" +
+		"DTSTART:20300110\nDTEND:20300111\nRRULE:FREQ=WEEKLY;BYDAYS=MO\nEND:VEVENT\nEND:VCALENDAR\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write ICS: %v", err)
+	}
+
+	cmd := newLintCmd()
+	mustSetFlag(t, cmd, "file", path)
+	if err := runLint(cmd, nil); err != nil {
+		t.Fatalf("expected the default lint to pass, got %v", err)
+	}
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	mustSetFlag(t, cmd, "strict", "true")
+	mustSetFlag(t, cmd, "format", "json")
+	if err := runLint(cmd, nil); err == nil {
+		t.Fatal("expected lint --strict to fail")
+	}
+	var findings []diag.Warning
+	if err := json.Unmarshal(out.Bytes(), &findings); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out.String())
+	}
+	lines := map[int]bool{}
+	for _, f := range findings {
+		if f.Code != diag.CodeGrammar {
+			t.Errorf("unexpected finding %+v", f)
+		}
+		lines[f.Line] = true
+	}
+	for _, line := range []int{1, 6, 7, 8} {
+		if !lines[line] {
+			t.Errorf("no finding on line %d: %+v", line, findings)
+		}
+	}
+}
+
+func TestLocaleLintCommand(t *testing.T) {
+	cfg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", cfg)
+	t.Cleanup(resetUI)
+	dir := filepath.Join(cfg, "tempus", "locales")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	broken := `{"event_created": "Evento creado", "made_up": "x"}`
+	if err := os.WriteFile(filepath.Join(dir, "es.json"), []byte(broken), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	root := newRootCmd()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"locale", "lint", "es", "--format", "json"})
+	err := root.Execute()
+	if err == nil || !strings.Contains(err.Error(), "locale lint failed") {
+		t.Fatalf("expected locale lint to fail, got %v", err)
+	}
+	var findings []diag.Warning
+	if err := json.Unmarshal(out.Bytes(), &findings); err != nil {
+		t.Fatalf("invalid JSON report: %v\n%s", err, out.String())
+	}
+	codes := map[string]int{}
+	for _, f := range findings {
+		if f.File != filepath.Join(dir, "es.json") {
+			t.Errorf("only the broken disk file should have findings, got %+v", f)
+		}
+		codes[f.Code]++
+	}
+	if codes[diag.CodePlaceholders] != 1 || codes[diag.CodeUnknownKey] != 1 || codes[diag.CodeUntranslated] == 0 {
+		t.Errorf("unexpected findings %v", codes)
+	}
+
+	// Messages follow --language (es itself is overridden by the broken file).
+	root = newRootCmd()
+	out.Reset()
+	root.SetOut(&out)
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"locale", "lint", "es", "--language", "pt"})
+	_ = root.Execute()
+	if !strings.Contains(out.String(), `chave desconhecida "made_up"`) {
+		t.Errorf("expected Portuguese findings:\n%s", out.String())
+	}
+}
+
+// Every message key main.go passes to ui.T must exist in English, or the raw
+// key would be printed.
+func TestUIMessageKeysExist(t *testing.T) {
+	src, err := os.ReadFile("main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	en, err := i18n.NewTranslator("en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := regexp.MustCompile(`ui\.T\("([a-z0-9_]+)"`).FindAllStringSubmatch(string(src), -1)
+	if len(keys) == 0 {
+		t.Fatal("expected ui.T calls in main.go")
+	}
+	for _, m := range keys {
+		if en.T(m[1]) == m[1] {
+			t.Errorf("message key %q is not in locales/en.json", m[1])
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMedsScheduleTitration(t *testing.T) {
+	dir := setupCommandTest(t)
+	output := filepath.Join(dir, "meds.ics")
+
+	got := runRootStdout(t, "meds", "schedule", "--drug", "Methylphenidate", "--dose", "10mg:1w,20mg",
+		"--times", "08:00,13:00", "--start", "2025-05-01", "--duration", "3w", "--skip-days", "weekend",
+		"--skip", "2025-05-06", "--tz", "Europe/Madrid", "--notes", "Take with food", "-o", output)
+	for _, want := range []string{
+		"Methylphenidate: 2 dose step(s) from Thu 05/01/2025:",
+		"step 1, 10mg at 08:00: Thu 05/01/2025 to Wed 05/07/2025 (4 dose(s)) (3 skipped)",
+		"step 2, 20mg at 13:00: Thu 05/08/2025 to Wed 05/21/2025 (10 dose(s)) (4 skipped)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("plan missing %q:\n%s", want, got)
+		}
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	ics := string(data)
+	for _, want := range []string{
+		"SUMMARY:💊 Methylphenidate 10mg",
+		"DTSTART;TZID=Europe/Madrid:20250501T080000",
+		"DTEND;TZID=Europe/Madrid:20250501T080500",
+		"RRULE:FREQ=DAILY;COUNT=7",
+		"EXDATE;TZID=Europe/Madrid:20250503T080000,20250504T080000,20250506T080000",
+		"DTSTART;TZID=Europe/Madrid:20250508T130000",
+		"RRULE:FREQ=DAILY;COUNT=14",
+		"CATEGORIES:Medication,Health",
+		"TRIGGER:-PT5M",
+		"DESCRIPTION:Time to take Methylphenidate 20mg",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+	if n := strings.Count(ics, "BEGIN:VEVENT"); n != 4 {
+		t.Errorf("expected 2 steps x 2 times, got %d events", n)
+	}
+	if n := strings.Count(ics, "BEGIN:VALARM"); n != 12 {
+		t.Errorf("expected triple alarms on every event, got %d", n)
+	}
+	if !strings.Contains(strings.ReplaceAll(ics, "\r\n ", ""), "Step 2 of 2: 20mg daily from Thu 05/08/2025 to Wed 05/21/2025\\nTake with food") {
+		t.Errorf("expected step and notes in the description:\n%s", ics)
+	}
+}
+
+func TestMedsScheduleSingleDoseWithoutAlarms(t *testing.T) {
+	dir := setupCommandTest(t)
+	output := filepath.Join(dir, "meds.ics")
+
+	runRootStdout(t, "meds", "schedule", "--drug", "Sertraline", "--dose", "50mg", "--times", "9:00",
+		"--start", "2025-05-01", "--duration", "30d", "--tz", "UTC", "--no-alarm", "-o", output)
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	ics := string(data)
+	if !strings.Contains(ics, "RRULE:FREQ=DAILY;COUNT=30") || strings.Contains(ics, "BEGIN:VALARM") || strings.Contains(ics, "DESCRIPTION") {
+		t.Errorf("unexpected single-step schedule:\n%s", ics)
+	}
+}
+
+func TestMedsScheduleRejectsBadFlags(t *testing.T) {
+	setupCommandTest(t)
+	base := []string{"meds", "schedule", "--dry-run", "--start", "2025-05-01"}
+	for _, args := range [][]string{
+		{"--dose", "10mg:1w", "--times", "08:00"},
+		{"--drug", "X", "--times", "08:00", "--duration", "1w"},
+		{"--drug", "X", "--dose", "10mg", "--times", "08:00"},
+		{"--drug", "X", "--dose", "10mg, 20mg:1w", "--times", "08:00"},
+		{"--drug", "X", "--dose", "10mg:1w", "--times", "8am"},
+		{"--drug", "X", "--dose", "10mg:1w", "--times", "08:00", "--skip-days", "funday"},
+		{"--drug", "X", "--dose", "10mg:2d", "--times", "08:00", "--skip-days", "daily"},
+	} {
+		if err := runRootErr(t, append(base, args...)...); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
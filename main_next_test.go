@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+const nextTestICS = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\nUID:gym\r\nSUMMARY:Gym\r\nDTSTART:20250428T070000Z\r\nDTEND:20250428T080000Z\r\nRRULE:FREQ=DAILY;COUNT=10\r\nEXDATE:20250502T070000Z\r\nEND:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\nUID:standup\r\nSUMMARY:Standup\r\nLOCATION:Room 1\r\nDTSTART:20250501T084500Z\r\nDTEND:20250501T091500Z\r\nEND:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\nUID:review\r\nSUMMARY:Review\r\nDTSTART:20250501T094500Z\r\nDTEND:20250501T103000Z\r\nEND:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\nUID:holiday\r\nSUMMARY:Holiday\r\nDTSTART;VALUE=DATE:20250502\r\nDTEND;VALUE=DATE:20250503\r\nEND:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func writeNextTestICS(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	path := filepath.Join(dir, "week.ics")
+	if err := os.WriteFile(path, []byte(nextTestICS), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNextListsUpcomingEventsWithCountdowns(t *testing.T) {
+	input := writeNextTestICS(t)
+
+	got := runRootStdout(t, "next", "-i", input, "--from", "2025-05-01 09:00", "-n", "4")
+	for _, want := range []string{
+		"Next 4 event(s) after Thu 05/01/2025 09:00:\n",
+		"  now, ends in 15 min  Thu 05/01/2025 08:45–09:15  Standup (Room 1)\n",
+		"  in 45 min            Thu 05/01/2025 09:45–10:30  Review\n",
+		"  in 15 h              Fri 05/02/2025 All day  Holiday\n",
+		"  in 1 d 22 h          Sat 05/03/2025 07:00–08:00  Gym\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("next output missing %q:\n%s", want, got)
+		}
+	}
+
+	got = runRootStdout(t, "next", "-i", input, "--from", "2025-05-01 13:00", "-n", "2", "--timezone", "Europe/Madrid", "--language", "es")
+	if !strings.Contains(got, "  en 11 h      Fri 02/05/2025 Todo el día  Holiday\n  en 1 d 20 h  Sat 03/05/2025 09:00–10:00  Gym\n") {
+		t.Errorf("expected Spanish output in Europe/Madrid:\n%s", got)
+	}
+
+	if got := runRootStdout(t, "next", "-i", input, "--from", "2030-01-01 00:00"); !strings.Contains(got, "No events after") {
+		t.Errorf("expected no events in 2030:\n%s", got)
+	}
+}
+
+func TestNextJSON(t *testing.T) {
+	input := writeNextTestICS(t)
+
+	out := runRootStdout(t, "next", "-i", input, "--from", "2025-05-01 09:00", "-n", "2", "--output-format", "json")
+	var list []nextEventJSON
+	if err := json.Unmarshal([]byte(out), &list); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(list) != 2 {
+		t.Fatalf("expected 2 events, got %+v", list)
+	}
+	if list[0].UID != "standup" || !list[0].InProgress || list[0].StartsInMinutes != -15 {
+		t.Errorf("unexpected first event %+v", list[0])
+	}
+	if list[1].Summary != "Review" || list[1].Start != "2025-05-01T09:45:00Z" || list[1].StartsInMinutes != 45 {
+		t.Errorf("unexpected second event %+v", list[1])
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stubOpenFile records the paths --open hands to the desktop instead of
+// launching an app, failing with err when it is set.
+func stubOpenFile(t *testing.T, err error) *[]string {
+	t.Helper()
+	var opened []string
+	old := openFile
+	openFile = func(path string) error {
+		opened = append(opened, path)
+		return err
+	}
+	t.Cleanup(func() { openFile = old })
+	return &opened
+}
+
+func TestCreateOpen(t *testing.T) {
+	dir := setupCommandTest(t)
+	opened := stubOpenFile(t, nil)
+	ics := filepath.Join(dir, "dentist.ics")
+
+	out := runRootStdout(t, "create", "Dentist", "--start", "2030-11-03 10:00", "--duration", "45m",
+		"-o", ics, "--open")
+	if len(*opened) != 1 || (*opened)[0] != ics {
+		t.Fatalf("opened %v, want [%s]", *opened, ics)
+	}
+	if !strings.Contains(out, "Opening "+ics) {
+		t.Errorf("output does not mention opening:\n%s", out)
+	}
+}
+
+func TestCreateOpenFailureOnlyWarns(t *testing.T) {
+	dir := setupCommandTest(t)
+	stubOpenFile(t, errors.New("no opener"))
+	ics := filepath.Join(dir, "dentist.ics")
+
+	if err := runRootErr(t, "create", "Dentist", "--start", "2030-11-03 10:00", "--duration", "45m",
+		"-o", ics, "--open"); err != nil {
+		t.Fatalf("create --open failed: %v", err)
+	}
+	if _, err := os.Stat(ics); err != nil {
+		t.Errorf("calendar not written: %v", err)
+	}
+}
+
+func TestCreateOpenNeedsOutput(t *testing.T) {
+	setupCommandTest(t)
+	opened := stubOpenFile(t, nil)
+
+	err := runRootErr(t, "create", "Dentist", "--start", "2030-11-03 10:00", "--duration", "45m", "--open")
+	if err == nil || !strings.Contains(err.Error(), "--open needs --output") {
+		t.Fatalf("expected --output error, got %v", err)
+	}
+	if err := runRootErr(t, "create", "Dentist", "--start", "2030-11-03 10:00", "--duration", "45m",
+		"-o", "x.ics", "--open", "--dry-run"); err == nil {
+		t.Error("--open with --dry-run should be rejected")
+	}
+	if len(*opened) != 0 {
+		t.Errorf("opened %v without writing a file", *opened)
+	}
+}
+
+func TestTemplateCreateOpensEveryFile(t *testing.T) {
+	dir := setupCommandTest(t)
+	opened := stubOpenFile(t, nil)
+	input := filepath.Join(dir, "meetings.csv")
+	csv := "title,start_time,timezone\nPlanning,2030-06-02 10:00,Europe/Madrid\nReview,2030-06-03 15:00,Europe/Madrid\n"
+	if err := os.WriteFile(input, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "out")
+	if err := runRootErr(t, "template", "create", "meeting", "--input", input, "--output-dir", out, "--open"); err != nil {
+		t.Fatalf("template create failed: %v", err)
+	}
+	if len(*opened) != 2 {
+		t.Fatalf("opened %v, want both files", *opened)
+	}
+	for _, path := range *opened {
+		if filepath.Dir(path) != out {
+			t.Errorf("opened %s outside %s", path, out)
+		}
+	}
+}
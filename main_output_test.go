@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"tempus/internal/clock"
+	"tempus/internal/config"
+	"tempus/internal/i18n"
+	"tempus/internal/journal"
+	"tempus/internal/output"
+
+	"github.com/spf13/viper"
+)
+
+// runRootStdout runs the root command with args and returns what it printed.
+// Callers point XDG_CONFIG_HOME at a temp dir to keep the undo journal there.
+// resetUI puts the translator and display layouts back to English after a
+// test ran commands with another language or format.
+func resetUI() {
+	ui, _ = i18n.NewTranslator("en")
+	dateLayout, clockLayout = ui.DateLayout(), ui.TimeLayout()
+}
+
+func runRootStdout(t *testing.T, args ...string) string {
+	t.Helper()
+	t.Cleanup(func() {
+		output.Configure(output.Options{})
+		journalOp = journal.Op{}
+		resetUI()
+	})
+	root := newRootCmd()
+	root.SetArgs(args)
+	root.SetErr(&bytes.Buffer{})
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	runErr := root.Execute()
+	w.Close()
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+	os.Stdout = oldStdout
+
+	if runErr != nil {
+		t.Fatalf("tempus %v failed: %v\n%s", args, runErr, buf.String())
+	}
+	return buf.String()
+}
+
+func TestGlobalOutputFlags(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	// --quiet still shows warnings, so keep the event from being in the past.
+	setClock(clock.Fixed(time.Date(2025, 12, 1, 9, 0, 0, 0, time.UTC)))
+	t.Cleanup(func() { setClock(nil) })
+	dir := t.TempDir()
+	input := filepath.Join(dir, "events.csv")
+	csvData := "summary,start,duration\nStandup,2025-12-16 09:30,15m\n"
+	if err := os.WriteFile(input, []byte(csvData), 0644); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+	batch := func(flags ...string) string {
+		args := append([]string{"batch", "--input", input, "--output", filepath.Join(dir, "out.ics")}, flags...)
+		return runRootStdout(t, args...)
+	}
+
+	if out := batch(); !strings.Contains(out, "✅ Created:") || !strings.Contains(out, "Events:") {
+		t.Errorf("default output missing status or summary:\n%s", out)
+	}
+	if out := batch("--quiet"); strings.TrimSpace(out) != "" {
+		t.Errorf("--quiet should print nothing on success, got:\n%s", out)
+	}
+	out := batch("--no-emoji", "--verbose")
+	if strings.Contains(out, "✅") || !strings.Contains(out, "Created:") {
+		t.Errorf("--no-emoji should keep the message without the icon:\n%s", out)
+	}
+	if !strings.Contains(out, "Loaded 1 row(s)") {
+		t.Errorf("--verbose should report loaded rows:\n%s", out)
+	}
+
+	root := newRootCmd()
+	root.SetArgs([]string{"--quiet", "--verbose", "version"})
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	if err := root.Execute(); err == nil {
+		t.Error("expected --quiet and --verbose to be mutually exclusive")
+	}
+}
+
+func TestOutputFormatJSON(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	input := filepath.Join(dir, "events.csv")
+	csvData := "summary,start,duration\nStandup,2025-12-16 09:30,15m\nLunch,2025-12-17 13:00,1h\n"
+	if err := os.WriteFile(input, []byte(csvData), 0644); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+	outPath := filepath.Join(dir, "out.ics")
+
+	var summary batchSummary
+	out := runRootStdout(t, "batch", "--input", input, "--output", outPath, "--dry-run", "--output-format", "json")
+	if err := json.Unmarshal([]byte(out), &summary); err != nil {
+		t.Fatalf("dry run should print only JSON, got %q: %v", out, err)
+	}
+	if !summary.DryRun || summary.Events != 2 {
+		t.Errorf("unexpected dry-run summary: %+v", summary)
+	}
+	if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+		t.Errorf("dry run wrote %s", outPath)
+	}
+
+	var zones []zoneJSON
+	out = runRootStdout(t, "timezone", "list", "--search", "Madrid", "--output-format", "json")
+	if err := json.Unmarshal([]byte(out), &zones); err != nil || len(zones) == 0 || zones[0].IANA != "Europe/Madrid" {
+		t.Errorf("timezone list JSON = %q (%v)", out, err)
+	}
+
+	var templates []map[string]any
+	out = runRootStdout(t, "template", "list", "--output-format", "json")
+	if err := json.Unmarshal([]byte(out), &templates); err != nil || len(templates) == 0 {
+		t.Errorf("template list JSON = %q (%v)", out, err)
+	}
+
+	root := newRootCmd()
+	root.SetArgs([]string{"version", "--output-format", "yaml"})
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	if err := root.Execute(); err == nil {
+		t.Error("expected an error for --output-format yaml")
+	}
+}
+
+func TestUndoRevertsLastBatchRun(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "events.csv")
+	outPath := filepath.Join(dir, "week.ics")
+	writeCSV := func(summary string) {
+		t.Helper()
+		csvData := "summary,start,duration\n" + summary + ",2025-12-16 09:30,15m\n"
+		if err := os.WriteFile(input, []byte(csvData), 0644); err != nil {
+			t.Fatalf("failed to write csv: %v", err)
+		}
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	run := func(args ...string) string { return runRootStdout(t, args...) }
+
+	writeCSV("Standup")
+	run("batch", "--input", input, "--output", outPath)
+	good, _ := os.ReadFile(outPath)
+	writeCSV("Oops")
+	run("batch", "--input", input, "--output", outPath)
+
+	if out := run("undo", "--dry-run"); !strings.Contains(out, "would restore "+outPath) {
+		t.Errorf("dry run output:\n%s", out)
+	}
+	if out := run("undo"); !strings.Contains(out, "Restored: "+outPath) {
+		t.Errorf("undo output:\n%s", out)
+	}
+	if restored, _ := os.ReadFile(outPath); !bytes.Equal(restored, good) {
+		t.Errorf("undo did not restore the first run:\n%s", restored)
+	}
+	run("undo")
+	if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+		t.Errorf("undoing the first run should remove %s", outPath)
+	}
+	if out := run("undo"); !strings.Contains(out, "Nothing to undo") {
+		t.Errorf("empty journal output:\n%s", out)
+	}
+}
+
+func TestFailedWriteKeepsTheFileAndItsMode(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	journalOp = journal.NewOp("tempus batch")
+	t.Cleanup(func() { journalOp = journal.Op{} })
+	dir := t.TempDir()
+	path := filepath.Join(dir, "week.ics")
+	if err := os.WriteFile(path, []byte("original"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	err := writeGeneratedStream(path, config.OutputPolicy{}, func(w io.Writer) error {
+		_, _ = io.WriteString(w, "half a calen")
+		return io.ErrUnexpectedEOF
+	})
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf("writeGeneratedStream() = %v, want the writer's error", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "original" {
+		t.Errorf("%s = %q after a failed write, want the original", path, data)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o640 {
+		t.Errorf("Stat() = %v, %v; the failed write should keep mode 0640", info, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("expected only %s to be left, got %v", path, entries)
+	}
+	j, err := journal.Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries, err := j.Entries(); err != nil || len(entries) != 0 {
+		t.Errorf("a failed write should not be journalled, got %+v, %v", entries, err)
+	}
+}
+
+func TestBatchHonorsOutputConfig(t *testing.T) {
+	dir := t.TempDir()
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	writeConfig := func(overwrite string) {
+		t.Helper()
+		content := "output:\n  dir: " + filepath.Join(dir, "cals") + "\n  file_mode: \"0640\"\n  overwrite: " + overwrite + "\n"
+		if err := os.MkdirAll(filepath.Join(configHome, "tempus"), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(configHome, "tempus", "config.yaml"), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		viper.Reset()
+		if _, err := config.Reload(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	input := filepath.Join(dir, "events.csv")
+	if err := os.WriteFile(input, []byte("summary,start,duration\nStandup,2025-12-16 09:30,15m\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	writeConfig("unique")
+	runRootStdout(t, "batch", "--input", input, "--output", "week.ics")
+	runRootStdout(t, "batch", "--input", input, "--output", "week.ics")
+	for _, name := range []string{"week.ics", "week-2.ics"} {
+		info, err := os.Stat(filepath.Join(dir, "cals", name))
+		if err != nil {
+			t.Fatalf("expected %s in output.dir: %v", name, err)
+		}
+		if perm := info.Mode().Perm(); perm&^0o640 != 0 {
+			t.Errorf("%s has mode %o, want at most 0640", name, perm)
+		}
+	}
+
+	writeConfig("fail")
+	t.Cleanup(viper.Reset)
+	cmd := newBatchCmd()
+	mustSetFlag(t, cmd, "input", input)
+	mustSetFlag(t, cmd, "output", "week.ics")
+	if err := runBatch(cmd, nil); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected output.overwrite fail to refuse week.ics, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOvernightEnd(t *testing.T) {
+	tests := []struct {
+		start, end string
+		overnight  bool
+		want       string
+		rolled     bool
+	}{
+		{"2025-03-15 23:00", "01:00", false, "2025-03-16 01:00", true},
+		{"2025-03-15 10:00", "11:30", false, "2025-03-15 11:30", false},
+		{"2025-03-15 20:00", "22:00", true, "2025-03-16 22:00", true},
+		{"2025-12-31 22:00", "2:00", false, "2026-01-01 2:00", true},
+		{"2025-03-15 23:00", "2025-03-16 01:00", false, "2025-03-16 01:00", false},
+		{"2025-03-15 23:00", "2h", false, "2h", false},
+	}
+	for _, tt := range tests {
+		got, rolled := overnightEnd(tt.start, tt.end, tt.overnight)
+		if got != tt.want || rolled != tt.rolled {
+			t.Errorf("overnightEnd(%q, %q, %v) = %q, %v; want %q, %v", tt.start, tt.end, tt.overnight, got, rolled, tt.want, tt.rolled)
+		}
+	}
+}
+
+func TestCreateRollsOvernightEnd(t *testing.T) {
+	dir := setupCommandTest(t)
+	output := filepath.Join(dir, "night.ics")
+
+	if err := runRootErr(t, "create", "Night shift", "--start", "2025-03-15 23:00", "--end", "01:00",
+		"--start-tz", "Europe/Dublin", "-o", output); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	if !strings.Contains(string(data), "DTEND;TZID=Europe/Dublin:20250316T010000") {
+		t.Errorf("end should roll to the next day:\n%s", data)
+	}
+}
+
+func TestBatchOvernightColumn(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "shifts.csv")
+	output := filepath.Join(dir, "shifts.ics")
+	csv := "summary,start,end,overnight\n" +
+		"Late,2025-03-15 22:00,02:00,\n" +
+		"Sleepover,2025-03-16 18:00,19:00,true\n"
+	if err := os.WriteFile(input, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runRootErr(t, "batch", "-i", input, "-o", output, "--default-tz", "UTC"); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	for _, want := range []string{"DTEND;TZID=UTC:20250316T020000", "DTEND;TZID=UTC:20250317T190000"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("output missing %q:\n%s", want, data)
+		}
+	}
+
+	warnings := collectOvernightEnds([]batchRecord{
+		{Start: "2025-03-15 22:00", End: "02:00"},
+		{Start: "2025-03-16 18:00", End: "19:00", Overnight: true},
+		{Start: "2025-03-16 18:00", End: "19:00"},
+	}, &batchOptions{input: input})
+	if len(warnings) != 1 || warnings[0].Row != 1 {
+		t.Errorf("expected one overnight note for row 1, got %+v", warnings)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"tempus/internal/config"
+)
+
+func TestCreateQR(t *testing.T) {
+	dir := setupCommandTest(t)
+	ics := filepath.Join(dir, "dentist.ics")
+	pngPath := filepath.Join(dir, "dentist.png")
+
+	out := runRootStdout(t, "create", "Dentist",
+		"--start", "2030-11-03 10:00", "--duration", "45m", "--start-tz", "Europe/Madrid",
+		"--location", "Calle Mayor 5", "--alarm", "1h",
+		"-o", ics, "--qr", pngPath)
+	if !strings.Contains(out, "dentist.png") {
+		t.Errorf("output does not mention the QR file:\n%s", out)
+	}
+
+	f, err := os.Open(pngPath)
+	if err != nil {
+		t.Fatalf("QR not written: %v", err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("QR is not a PNG: %v", err)
+	}
+	// Version n is 17+4n modules, plus a 4-module quiet zone on each side.
+	size := img.Bounds().Dx()
+	if size != img.Bounds().Dy() || size%qrModulePixels != 0 || (size/qrModulePixels-8-17)%4 != 0 {
+		t.Errorf("unexpected QR image size %dx%d", size, img.Bounds().Dy())
+	}
+	if _, err := os.Stat(ics); err != nil {
+		t.Errorf("calendar not written: %v", err)
+	}
+}
+
+func TestCreateQRNeedsSingleEvent(t *testing.T) {
+	dir := setupCommandTest(t)
+	err := runRootErr(t, "create", "Standup",
+		"--start", "2030-11-04 09:00", "--duration", "15m", "--start-tz", "Europe/Madrid",
+		"--rrule", "FREQ=DAILY;COUNT=5", "--override", "2030-11-05 09:00 => 10:00",
+		"-o", filepath.Join(dir, "standup.ics"), "--qr", filepath.Join(dir, "standup.png"))
+	if err == nil || !strings.Contains(err.Error(), "single event") {
+		t.Fatalf("expected single-event error, got %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(dir, "standup.ics")); statErr == nil {
+		t.Error("calendar written despite the --qr error")
+	}
+}
+
+func TestQuickQR(t *testing.T) {
+	dir := setupCommandTest(t)
+	pngPath := filepath.Join(dir, "lunch.png")
+	start := time.Date(2030, 11, 3, 13, 30, 0, 0, time.UTC)
+	details := quickParsedEvent{Summary: "Lunch with Ana", StartTime: start, EndTime: start.Add(time.Hour)}
+
+	if err := writeQuickCalendar([]quickParsedEvent{details}, "UTC", filepath.Join(dir, "lunch.ics"), pngPath, config.OutputPolicy{}); err != nil {
+		t.Fatalf("writeQuickCalendar: %v", err)
+	}
+	data, err := os.ReadFile(pngPath)
+	if err != nil {
+		t.Fatalf("QR not written: %v", err)
+	}
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		t.Errorf("QR is not a PNG: %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"tempus/internal/clock"
+	"tempus/internal/config"
+	"tempus/internal/normalizer"
+)
+
+func TestSplitQuickInput(t *testing.T) {
+	setClock(clock.Fixed(time.Date(2030, 3, 4, 8, 0, 0, 0, time.UTC)))
+	t.Cleanup(func() { setClock(nil) })
+
+	cases := []struct {
+		in   string
+		want []string
+	}{
+		{"meeting tomorrow 3pm", []string{"meeting tomorrow 3pm"}},
+		{"dentist Tuesday 9am and team lunch Friday 13:00", []string{"dentist Tuesday 9am", "team lunch Friday 13:00"}},
+		{"dentist Tuesday 9am for 30m and team lunch Friday 13:00", []string{"dentist Tuesday 9am for 30m", "team lunch Friday 13:00"}},
+		{"call tomorrow 3pm; review friday at 10am", []string{"call tomorrow 3pm", "review friday at 10am"}},
+		{"standup tomorrow 9am, retro friday 4pm", []string{"standup tomorrow 9am", "retro friday 4pm"}},
+		{"lunch with Tom and Jerry tomorrow at 1pm", []string{"lunch with Tom and Jerry tomorrow at 1pm"}},
+		{"gym every day 7am, until June 15", []string{"gym every day 7am, until June 15"}},
+		{"standup monday at 9 and review friday at 10", []string{"standup monday at 9", "review friday at 10"}},
+		{"lunch tomorrow at 1pm then coffee and cake friday at 4pm", []string{"lunch tomorrow at 1pm", "coffee and cake friday at 4pm"}},
+		{"lunch tomorrow at 1pm and coffee and cake friday at 4pm", []string{"lunch tomorrow at 1pm", "coffee and cake friday at 4pm"}},
+		{"dinner tomorrow at 8 with Ana and Tom, gym friday at 7", []string{"dinner tomorrow at 8 with Ana and Tom", "gym friday at 7"}},
+	}
+	for _, tc := range cases {
+		if got := splitQuickInput(tc.in, ""); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("splitQuickInput(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestParseQuickEventsRepeatsSummary(t *testing.T) {
+	setClock(clock.Fixed(time.Date(2030, 3, 4, 8, 0, 0, 0, time.UTC)))
+	t.Cleanup(func() { setClock(nil) })
+
+	events, err := parseQuickEvents("dentist Tuesday 9am for 30m and Friday 10am", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	for i, want := range []time.Time{
+		time.Date(2030, 3, 5, 9, 0, 0, 0, time.UTC),
+		time.Date(2030, 3, 8, 10, 0, 0, 0, time.UTC),
+	} {
+		if events[i].Summary != "dentist" {
+			t.Errorf("event %d summary = %q, want dentist", i, events[i].Summary)
+		}
+		if !events[i].StartTime.Equal(want) {
+			t.Errorf("event %d start = %v, want %v", i, events[i].StartTime, want)
+		}
+	}
+	if d := events[0].EndTime.Sub(events[0].StartTime); d != 30*time.Minute {
+		t.Errorf("first event lasts %v, want 30m", d)
+	}
+}
+
+func TestParseQuickEventsRepeat(t *testing.T) {
+	setClock(clock.Fixed(time.Date(2030, 3, 6, 8, 0, 0, 0, time.UTC))) // a Wednesday
+	t.Cleanup(func() { setClock(nil) })
+
+	events, err := parseQuickEvents("gym every monday 7am until June and standup weekdays 9:15 for 2 weeks", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	gym, standup := events[0], events[1]
+	if gym.Summary != "gym" || !gym.StartTime.Equal(time.Date(2030, 3, 11, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("gym = %q at %v, want gym on Monday 2030-03-11 07:00", gym.Summary, gym.StartTime)
+	}
+	if got, want := gym.rrule("UTC"), "FREQ=WEEKLY;BYDAY=MO;UNTIL=20300531T235959Z"; got != want {
+		t.Errorf("gym rule = %q, want %q", got, want)
+	}
+	if got, want := standup.rrule("Europe/Madrid"), "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;UNTIL=20300319T225959Z"; got != want {
+		t.Errorf("standup rule = %q, want %q", got, want)
+	}
+
+	if _, err := parseQuickEvents("gym every day 7am until 2030-03-01", ""); err == nil || !strings.Contains(err.Error(), "before its first day") {
+		t.Errorf("expected an error for a repeat that ends before it starts, got %v", err)
+	}
+}
+
+func TestParseQuickEventsRepeatAtTime(t *testing.T) {
+	setClock(clock.Fixed(time.Date(2030, 3, 6, 8, 0, 0, 0, time.UTC))) // a Wednesday
+	t.Cleanup(func() { setClock(nil) })
+
+	cases := []struct {
+		in, lang, summary string
+		start             time.Time
+		rule              string
+	}{
+		{"Standup every monday at 9am", "", "Standup", time.Date(2030, 3, 11, 9, 0, 0, 0, time.UTC), "FREQ=WEEKLY;BYDAY=MO"},
+		{"Review every other week at 10am", "", "Review", time.Date(2030, 3, 6, 10, 0, 0, 0, time.UTC), "FREQ=WEEKLY;INTERVAL=2"},
+		{"Pay rent every month on the 1st at 9am", "", "Pay rent", time.Date(2030, 4, 1, 9, 0, 0, 0, time.UTC), "FREQ=MONTHLY;BYMONTHDAY=1"},
+		{"Standup every monday at 9 until June", "", "Standup", time.Date(2030, 3, 11, 9, 0, 0, 0, time.UTC), "FREQ=WEEKLY;BYDAY=MO;UNTIL=20300531T235959Z"},
+		{"gym every friday at 18 until June 15", "", "gym", time.Date(2030, 3, 8, 18, 0, 0, 0, time.UTC), "FREQ=WEEKLY;BYDAY=FR;UNTIL=20300615T235959Z"},
+		{"aula toda terça às 18 até junho", "pt", "aula", time.Date(2030, 3, 12, 18, 0, 0, 0, time.UTC), "FREQ=WEEKLY;BYDAY=TU;UNTIL=20300531T235959Z"},
+		{"call tomorrow at 9", "", "call", time.Date(2030, 3, 7, 9, 0, 0, 0, time.UTC), ""},
+		{"gym every tuesday and thursday at 7pm", "", "gym", time.Date(2030, 3, 7, 19, 0, 0, 0, time.UTC), "FREQ=WEEKLY;BYDAY=TU,TH"},
+		{"ginásio toda terça e quinta às 19", "pt", "ginásio", time.Date(2030, 3, 7, 19, 0, 0, 0, time.UTC), "FREQ=WEEKLY;BYDAY=TU,TH"},
+		// Sent after 7am, so the first one is tomorrow's.
+		{"stretch every day at 7am", "", "stretch", time.Date(2030, 3, 7, 7, 0, 0, 0, time.UTC), "FREQ=DAILY"},
+		{"review every wednesday at 7am", "", "review", time.Date(2030, 3, 13, 7, 0, 0, 0, time.UTC), "FREQ=WEEKLY;BYDAY=WE"},
+	}
+	for _, tc := range cases {
+		events, err := parseQuickEvents(tc.in, tc.lang)
+		if err != nil {
+			t.Errorf("parseQuickEvents(%q): %v", tc.in, err)
+			continue
+		}
+		ev := events[0]
+		if len(events) != 1 || ev.Summary != tc.summary || !ev.StartTime.Equal(tc.start) || ev.rrule("UTC") != tc.rule {
+			t.Errorf("parseQuickEvents(%q) = %d events, first %q at %v repeating %q; want %q at %v repeating %q",
+				tc.in, len(events), ev.Summary, ev.StartTime, ev.rrule("UTC"), tc.summary, tc.start, tc.rule)
+		}
+	}
+}
+
+func TestParseQuickEventsRepeatPortuguese(t *testing.T) {
+	setClock(clock.Fixed(time.Date(2030, 3, 6, 8, 0, 0, 0, time.UTC)))
+	t.Cleanup(func() { setClock(nil) })
+
+	events, err := parseQuickEvents("aula toda terça 18:00 por 4 semanas", "pt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Summary != "aula" || events[0].StartTime.Weekday() != time.Tuesday {
+		t.Fatalf("got %+v, want one Tuesday aula", events)
+	}
+	if got, want := events[0].rrule("UTC"), "FREQ=WEEKLY;BYDAY=TU;UNTIL=20300408T235959Z"; got != want {
+		t.Errorf("rule = %q, want %q", got, want)
+	}
+}
+
+func TestWriteQuickCalendarSeveralEvents(t *testing.T) {
+	dir := setupCommandTest(t)
+	start := time.Date(2030, 3, 5, 9, 0, 0, 0, time.UTC)
+	events := []quickParsedEvent{
+		{Summary: "Dentist", StartTime: start, EndTime: start.Add(30 * time.Minute), Repeat: normalizer.Repeat{Freq: "MONTHLY"}},
+		{Summary: "Team lunch", StartTime: start.AddDate(0, 0, 3).Add(4 * time.Hour), EndTime: start.AddDate(0, 0, 3).Add(5 * time.Hour), Location: "Cafe"},
+	}
+	output := filepath.Join(dir, "week.ics")
+	if err := writeQuickCalendar(events, "UTC", output, "", config.OutputPolicy{}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ics := string(data)
+	if n := strings.Count(ics, "BEGIN:VEVENT"); n != 2 {
+		t.Errorf("got %d VEVENTs, want 2:\n%s", n, ics)
+	}
+	for _, want := range []string{"SUMMARY:Dentist", "SUMMARY:Team lunch", "LOCATION:Cafe", "RRULE:FREQ=MONTHLY\r\n", "DTSTART;TZID=UTC:20300308T130000"} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("calendar is missing %q:\n%s", want, ics)
+		}
+	}
+	if strings.Contains(ics, "X-WR-CALNAME") {
+		t.Errorf("a calendar of several quick events should not take one of their names:\n%s", ics)
+	}
+}
+
+func TestQuickQRNeedsSingleEvent(t *testing.T) {
+	dir := setupCommandTest(t)
+	err := runRootErr(t, "quick", "dentist tomorrow 9am and lunch friday 1pm", "--qr", filepath.Join(dir, "q.png"))
+	if err == nil || !strings.Contains(err.Error(), "single event") {
+		t.Fatalf("expected single-event error, got %v", err)
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"tempus/internal/calendar"
+	"tempus/internal/diag"
 	"tempus/internal/testutil"
 	"testing"
+	"time"
 )
 
 func TestRunCreateWritesRecurrenceData(t *testing.T) {
@@ -45,3 +48,201 @@ func TestRunCreateWritesRecurrenceData(t *testing.T) {
 		t.Fatalf("expected EXDATE with timezone to be present, got:\n%s", ics)
 	}
 }
+
+func TestBatchJitterMaterializesRecurringEvents(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputPath := filepath.Join(tmpDir, testutil.FilenameEventsCSV)
+	outputPath := filepath.Join(tmpDir, "jitter.ics")
+
+	csvData := strings.Join([]string{
+		"summary,start,duration,start_tz,rrule",
+		"Evening routine,2025-12-16 21:00,30m,Europe/Madrid,FREQ=DAILY;COUNT=7",
+		"One-off call,2025-12-17 10:00,30m,Europe/Madrid,",
+	}, "\n")
+	if err := os.WriteFile(inputPath, []byte(csvData), 0644); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+
+	cmd := newBatchCmd()
+	mustSetFlag(t, cmd, "input", inputPath)
+	mustSetFlag(t, cmd, "output", outputPath)
+	mustSetFlag(t, cmd, "jitter", "15m")
+	mustSetFlag(t, cmd, "jitter-seed", "7")
+
+	if err := runBatch(cmd, nil); err != nil {
+		t.Fatalf("runBatch returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	ics := string(data)
+
+	if strings.Contains(ics, "RRULE:FREQ=DAILY") {
+		t.Fatalf("expected jittered events to be materialized without RRULE:\n%s", ics)
+	}
+	if got := strings.Count(ics, "BEGIN:VEVENT"); got != 8 {
+		t.Fatalf("expected 7 occurrences plus the one-off event, got %d", got)
+	}
+	if !strings.Contains(ics, "DTSTART;TZID=Europe/Madrid:20251217T100000") {
+		t.Fatalf("non-recurring event should not be jittered:\n%s", ics)
+	}
+}
+
+func TestBatchJitterWarnsAboutEndlessRules(t *testing.T) {
+	records := []batchRecord{
+		{Summary: "Meds", Start: "2030-01-01 21:00", Duration: "5m", StartTZ: "UTC", RRule: "FREQ=DAILY"},
+		{Summary: "Course", Start: "2030-01-01 18:00", Duration: "1h", StartTZ: "UTC", RRule: "FREQ=WEEKLY;COUNT=10"},
+	}
+	opts := &batchOptions{input: "events.csv", jitter: 10 * time.Minute, jitterSeed: 1}
+	cal, _, err := buildBatchCalendar(records, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(cal.Events), calendar.DefaultMaterializeLimit+10; got != want {
+		t.Errorf("got %d events, want %d", got, want)
+	}
+	if len(opts.jitterWarnings) != 1 || opts.jitterWarnings[0].Code != diag.CodeTruncated || opts.jitterWarnings[0].Row != 1 {
+		t.Errorf("jitter warnings = %+v, want one for the endless Meds row", opts.jitterWarnings)
+	}
+}
+
+func TestBatchJitterRejectsInvalidDuration(t *testing.T) {
+	cmd := newBatchCmd()
+	mustSetFlag(t, cmd, "input", "events.csv")
+	mustSetFlag(t, cmd, "jitter", "soon")
+
+	if _, err := parseBatchFlags(cmd); err == nil {
+		t.Fatal("expected error for invalid --jitter value")
+	}
+}
+
+func TestCreateAndBatchWriteRDates(t *testing.T) {
+	dir := setupCommandTest(t)
+	output := filepath.Join(dir, "class.ics")
+	if err := runRootErr(t, "create", "Algebra", "--start", "2025-09-01 10:00", "--duration", "1h",
+		"--start-tz", "Europe/Madrid", "--rrule", "FREQ=WEEKLY;COUNT=4",
+		"--rdate", "2025-09-06 10:00", "--rdate", "2025-09-13 11:00", "-o", output); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	if !strings.Contains(string(data), "RDATE;TZID=Europe/Madrid:20250906T100000,20250913T110000") {
+		t.Errorf("create should write RDATE in the start timezone:\n%s", data)
+	}
+
+	input := filepath.Join(dir, "classes.csv")
+	csv := "summary,start,duration,start_tz,rrule,rdate,all_day\n" +
+		"Physics,2025-09-02 09:00,1h,UTC,FREQ=WEEKLY;COUNT=2,2025-09-06 09:00|2025-09-20 09:00,\n" +
+		"Open day,2025-09-01,,,,2025-09-08,true\n"
+	if err := os.WriteFile(input, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	batchOut := filepath.Join(dir, "classes.ics")
+	if err := runRootErr(t, "batch", "-i", input, "-o", batchOut); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	data, err = os.ReadFile(batchOut)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	for _, want := range []string{"RDATE;TZID=UTC:20250906T090000,20250920T090000", "RDATE;VALUE=DATE:20250908"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("batch output missing %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestCreateAndBatchWriteOverrides(t *testing.T) {
+	dir := setupCommandTest(t)
+	output := filepath.Join(dir, "one-on-one.ics")
+	if err := runRootErr(t, "create", "1:1", "--start", "2025-12-16 14:00", "--duration", "30m",
+		"--start-tz", "Europe/Madrid", "--rrule", "FREQ=WEEKLY;COUNT=4",
+		"--override", "2025-12-23 14:00 => 2025-12-23 16:00", "-o", output); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	for _, want := range []string{"RECURRENCE-ID;TZID=Europe/Madrid:20251223T140000", "DTSTART;TZID=Europe/Madrid:20251223T160000"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("create output missing %q:\n%s", want, data)
+		}
+	}
+	if err := runRootErr(t, "create", "1:1", "--start", "2025-12-16 14:00", "--duration", "30m",
+		"--rrule", "FREQ=WEEKLY;COUNT=4", "--override", "2025-12-24 14:00 => 16:00",
+		"-o", filepath.Join(dir, "bad.ics")); err == nil {
+		t.Error("expected error for an override of a day the series skips")
+	}
+
+	input := filepath.Join(dir, "events.yaml")
+	yml := `- summary: Standup
+  start: 2025-12-15 09:00
+  duration: 15m
+  start_tz: UTC
+  rrule: FREQ=DAILY;COUNT=5
+  overrides:
+    - occurrence: 2025-12-17 09:00
+      start: "11:00"
+      end: "11:30"
+      location: Cafeteria
+    - "2025-12-19 09:00 => 2025-12-19 08:30"
+`
+	if err := os.WriteFile(input, []byte(yml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	batchOut := filepath.Join(dir, "events.ics")
+	if err := runRootErr(t, "batch", "-i", input, "-o", batchOut); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	data, err = os.ReadFile(batchOut)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	for _, want := range []string{
+		"RECURRENCE-ID;TZID=UTC:20251217T090000", "DTSTART;TZID=UTC:20251217T110000",
+		"DTEND;TZID=UTC:20251217T113000", "LOCATION:Cafeteria",
+		"RECURRENCE-ID;TZID=UTC:20251219T090000", "DTSTART;TZID=UTC:20251219T083000",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("batch output missing %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestCreateRRuleUntilShortcuts(t *testing.T) {
+	setupCommandTest(t)
+	tests := []struct {
+		args []string
+		want string
+	}{
+		// Timed events get a UTC UNTIL at the end of the last day in their zone.
+		{[]string{"-s", "2026-10-19 18:00", "--start-tz", "America/New_York", "--rrule-until", "end-of-semester"},
+			"RRULE:FREQ=WEEKLY;UNTIL=20270101T045959Z"},
+		{[]string{"-s", "2026-10-19 18:00", "--start-tz", "Europe/Madrid", "--rrule-until", "in 12 weeks"},
+			"RRULE:FREQ=WEEKLY;UNTIL=20270110T225959Z"},
+		// All-day events keep a DATE, like their DTSTART.
+		{[]string{"-s", "2026-10-19", "--all-day", "--rrule-until", "2026-12-18"},
+			"RRULE:FREQ=WEEKLY;UNTIL=20261218"},
+	}
+	for _, tt := range tests {
+		args := append([]string{"create", "Class", "--rrule", "FREQ=WEEKLY"}, tt.args...)
+		if out := runRootStdout(t, args...); !strings.Contains(out, tt.want) {
+			t.Errorf("%v: want %s in:\n%s", tt.args, tt.want, out)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"--rrule-until", "end-of-month"},
+		{"--rrule", "FREQ=DAILY;COUNT=3", "--rrule-until", "end-of-month"},
+		{"--rrule", "FREQ=DAILY", "--rrule-until", "someday"},
+	} {
+		if err := runRootErr(t, append([]string{"create", "Class", "-s", "2026-10-19 18:00"}, args...)...); err == nil {
+			t.Errorf("%v should be rejected", args)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateAcceptsRelativeStart(t *testing.T) {
+	dir := setupCommandTest(t)
+	output := filepath.Join(dir, "relative.ics")
+
+	runRootStdout(t, "create", "Dentist", "--start", "tomorrow 09:30", "--duration", "30m",
+		"--start-tz", "Europe/Madrid", "-o", output)
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	loc, _ := time.LoadLocation("Europe/Madrid")
+	want := "DTSTART;TZID=Europe/Madrid:" + time.Now().In(loc).AddDate(0, 0, 1).Format("20060102") + "T093000"
+	if !strings.Contains(string(data), want) {
+		t.Errorf("output missing %q:\n%s", want, data)
+	}
+}
+
+func TestCreateRelativeStartInSpanish(t *testing.T) {
+	dir := setupCommandTest(t)
+	output := filepath.Join(dir, "relative.ics")
+
+	runRootStdout(t, "--language", "es", "create", "Cita", "--start", "mañana 10:00", "--end", "mañana 11:00",
+		"--start-tz", "Europe/Madrid", "-o", output)
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	loc, _ := time.LoadLocation("Europe/Madrid")
+	day := time.Now().In(loc).AddDate(0, 0, 1).Format("20060102")
+	for _, want := range []string{"DTSTART;TZID=Europe/Madrid:" + day + "T100000", "DTEND;TZID=Europe/Madrid:" + day + "T110000"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("output missing %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestBatchAcceptsRelativeColumns(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "events.csv")
+	output := filepath.Join(dir, "batch.ics")
+	csv := "summary,start,end,tz\nReview,+3d 14:00,+3d 15:00,UTC\n"
+	if err := os.WriteFile(input, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runRootErr(t, "batch", "-i", input, "-o", output); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	day := time.Now().UTC().AddDate(0, 0, 3).Format("20060102")
+	if !strings.Contains(string(data), day+"T140000") || !strings.Contains(string(data), day+"T150000") {
+		t.Errorf("expected the row three days out on %s:\n%s", day, data)
+	}
+}
+
+func TestStrictInputKeepsRelativeDatesLiteral(t *testing.T) {
+	dir := setupCommandTest(t)
+	err := runRootErr(t, "create", "Dentist", "--strict-input", "--start", "tomorrow 09:30", "--duration", "30m",
+		"-o", filepath.Join(dir, "x.ics"))
+	if err == nil {
+		t.Error("--strict-input should reject relative dates")
+	}
+}
+
+func TestCreateWeekDateFollowsLocaleWeek(t *testing.T) {
+	tests := []struct {
+		lang, want string
+	}{
+		{"en", "DTSTART;TZID=UTC:20300127T100000"}, // weeks start on Sunday
+		{"es", "DTSTART;TZID=UTC:20300203T100000"}, // and on Monday
+	}
+	for _, tt := range tests {
+		dir := setupCommandTest(t)
+		output := filepath.Join(dir, "week.ics")
+		runRootStdout(t, "--language", tt.lang, "create", "Review", "--start", "2030-W05-Sun 10:00", "--duration", "1h",
+			"--start-tz", "UTC", "-o", output)
+		data, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatalf("expected output file: %v", err)
+		}
+		if !strings.Contains(string(data), tt.want) {
+			t.Errorf("%s: output missing %q:\n%s", tt.lang, tt.want, data)
+		}
+	}
+}
+
+func TestBatchAcceptsBusinessDayOffsets(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "events.csv")
+	output := filepath.Join(dir, "batch.ics")
+	csv := "summary,start,duration,tz\nFollow-up,+5bd 09:00,30m,UTC\n"
+	if err := os.WriteFile(input, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runRootErr(t, "batch", "-i", input, "-o", output); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	day := time.Now().UTC()
+	for n := 5; n > 0; {
+		if day = day.AddDate(0, 0, 1); day.Weekday() != time.Saturday && day.Weekday() != time.Sunday {
+			n--
+		}
+	}
+	if want := day.Format("20060102") + "T090000"; !strings.Contains(string(data), want) {
+		t.Errorf("expected the row five business days out, %s:\n%s", want, data)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotaWritesShifts(t *testing.T) {
+	dir := setupCommandTest(t)
+	output := filepath.Join(dir, "rota.ics")
+
+	got := runRootStdout(t, "rota", "--pattern", "early*2,night,off*3", "--anchor", "2025-05-01", "--from", "2025-05-02",
+		"--until", "2025-05-08", "--shift", "night=22:00-06:30", "--tz", "Europe/Madrid", "--location", "Ward 7", "--alarm", "-1h", "-o", output)
+	for _, want := range []string{
+		"4 shift(s) from a 6-day cycle, Fri 05/02/2025 to Thu 05/08/2025:",
+		"Sat 05/03/2025 22:00–06:30  💼 Night shift",
+		"Thu 05/08/2025 07:00–15:00  💼 Early shift",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("plan missing %q:\n%s", want, got)
+		}
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	ics := string(data)
+	for _, want := range []string{
+		"SUMMARY:💼 Early shift",
+		"DTSTART;TZID=Europe/Madrid:20250502T070000",
+		"DTSTART;TZID=Europe/Madrid:20250503T220000",
+		"DTEND;TZID=Europe/Madrid:20250504T063000",
+		"DESCRIPTION:Day 3 of the 6-day rota",
+		"LOCATION:Ward 7",
+		"CATEGORIES:Work",
+		"TRIGGER:-PT1H",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+	if n := strings.Count(ics, "BEGIN:VEVENT"); n != 4 {
+		t.Errorf("expected 4 shifts, got %d events", n)
+	}
+	if strings.Contains(ics, "FREQ=DAILY") || strings.Contains(ics, "FREQ=WEEKLY") {
+		t.Error("rota shifts should be single events")
+	}
+}
+
+func TestRotaMergesAllDayShifts(t *testing.T) {
+	dir := setupCommandTest(t)
+	output := filepath.Join(dir, "rota.ics")
+
+	runRootStdout(t, "rota", "--pattern", "4-on/4-off", "--anchor", "2025-05-01", "--horizon", "2w",
+		"--title", "Ward 7", "--alarm", "-1h", "-o", output)
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	ics := string(data)
+	for _, want := range []string{
+		"SUMMARY:💼 Ward 7 (On)",
+		"DTSTART;VALUE=DATE:20250501\r\nDTEND;VALUE=DATE:20250505",
+		"DTSTART;VALUE=DATE:20250509\r\nDTEND;VALUE=DATE:20250513",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("output missing %q:\n%s", want, ics)
+		}
+	}
+	if strings.Count(ics, "BEGIN:VEVENT") != 2 || strings.Contains(ics, "BEGIN:VALARM") {
+		t.Errorf("expected two all-day stretches without alarms:\n%s", ics)
+	}
+}
+
+func TestRotaRejectsBadFlags(t *testing.T) {
+	setupCommandTest(t)
+	for _, args := range [][]string{
+		{"rota"},
+		{"rota", "--pattern", "off*4"},
+		{"rota", "--pattern", "weird*2"},
+		{"rota", "--pattern", "4-on/4-off", "--shift", "on=8am"},
+		{"rota", "--pattern", "4-on/4-off", "--horizon", "12h"},
+		{"rota", "--pattern", "4-on/4-off", "--from", "2025-05-10", "--until", "2025-05-01"},
+		{"rota", "--pattern", "4-on/4-off", "--anchor", "tomorrowish"},
+	} {
+		if err := runRootErr(t, append(args, "--dry-run")...); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"tempus/internal/clock"
+	"tempus/internal/diag"
+)
+
+func warningCodes(warnings []diag.Warning) string {
+	codes := make([]string, len(warnings))
+	for i, w := range warnings {
+		codes[i] = w.Code
+	}
+	return strings.Join(codes, ",")
+}
+
+func TestSanityWarningsInCreateAndBatch(t *testing.T) {
+	dir := setupCommandTest(t)
+	setClock(clock.Fixed(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)))
+	t.Cleanup(func() { setClock(nil) })
+
+	out := runRootStdout(t, "create", "Exam", "-s", "2025-06-02 09:00", "--duration", "1h",
+		"--alarm", "2024-01-01 09:00", "--alarm", "trigger=+2h", "--dry-run", "--output-format", "json")
+	var summary batchSummary
+	if err := json.Unmarshal([]byte(out), &summary); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if got := warningCodes(summary.Warnings); got != "alarm-window,alarm-window" {
+		t.Errorf("create warnings = %s\n%s", got, out)
+	}
+
+	input := filepath.Join(dir, "events.csv")
+	csv := "summary,start,end,rrule\n" +
+		"Typo,2015-06-02 09:00,2015-06-02 10:00,\n" +
+		"Retreat,2025-06-02 09:00,2025-06-04 17:00,\n" +
+		"Standup,2025-06-02 09:00,2025-06-02 09:15,FREQ=DAILY;UNTIL=20240101T000000Z\n" +
+		"Fine,2025-06-02 11:00,2025-06-02 12:00,\n"
+	if err := os.WriteFile(input, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out = runRootStdout(t, "batch", "--input", input, "--dry-run", "--output-format", "json")
+	summary = batchSummary{}
+	if err := json.Unmarshal([]byte(out), &summary); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	var sanity []string
+	for _, w := range summary.Warnings {
+		switch w.Code {
+		case diag.CodePast, diag.CodeLongEvent, diag.CodeUntil:
+			sanity = append(sanity, w.Code)
+			if w.File != input || w.Severity != diag.SeverityWarning {
+				t.Errorf("warning %+v should point at the input file", w)
+			}
+		}
+	}
+	if got := strings.Join(sanity, ","); got != "past,long-event,until" {
+		t.Errorf("batch sanity warnings = %s\n%s", got, out)
+	}
+
+	// past_warning_days: 0 turns the past check off.
+	if err := os.MkdirAll(filepath.Join(dir, "tempus"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tempus", "config.yaml"), []byte("past_warning_days: 0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out = runRootStdout(t, "batch", "--input", input, "--dry-run", "--output-format", "json")
+	if strings.Contains(out, `"code": "past"`) {
+		t.Errorf("past_warning_days 0 should silence the past warning:\n%s", out)
+	}
+}
+
+func TestLintReportsSanityWarningsWithoutFailing(t *testing.T) {
+	setupCommandTest(t)
+	setClock(clock.Fixed(time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)))
+	t.Cleanup(func() { setClock(nil) })
+
+	path := filepath.Join(t.TempDir(), "standup.ics")
+	ics := "BEGIN:VCALENDAR\nVERSION:2.0\nPRODID:-//test//EN\n" +
+		"BEGIN:VEVENT\nUID:ok@test\nSUMMARY:Review\nDTSTART:20300601T090000Z\nDTEND:20300601T100000Z\nEND:VEVENT\n" +
+		"BEGIN:VEVENT\nUID:a@test\nSUMMARY:Standup\n" +
+		"DTSTART;TZID=Europe/Madrid:20300602T090000\nDTEND;TZID=Europe/Madrid:20300602T091500\n" +
+		"RRULE:FREQ=DAILY;UNTIL=20200602T090000Z\n" +
+		"BEGIN:VALARM\nACTION:DISPLAY\nDESCRIPTION:x\nTRIGGER;VALUE=DATE-TIME:20300603T090000Z\nEND:VALARM\n" +
+		"END:VEVENT\nEND:VCALENDAR\n"
+	if err := os.WriteFile(path, []byte(ics), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := runRootStdout(t, "lint", "--file", path, "--format", "json")
+	var findings []diag.Warning
+	if err := json.Unmarshal([]byte(out), &findings); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if got := warningCodes(findings); got != "alarm-window,until" {
+		t.Fatalf("lint findings = %s\n%s", got, out)
+	}
+	if findings[0].Line != 10 || findings[0].File != path {
+		t.Errorf("finding should point at the event's BEGIN line, got %s", findings[0].Location())
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadBatchFromTOML(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.toml")
+	data := `[[events]]
+summary = "Dentist"
+start = 2025-05-01T09:00:00
+duration = "45m"
+categories = ["Health"]
+alarms = ["-1h"]
+
+[[events]]
+summary = "Holiday"
+start = 2025-05-02
+all_day = true
+
+[[events]]
+summary = "Flight"
+start = 2025-05-03T10:00:00+01:00
+priority = 2
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	format, err := detectBatchFormat("auto", path)
+	if err != nil || format != batchFormatTOML {
+		t.Fatalf("detectBatchFormat() = %q, %v; want toml", format, err)
+	}
+	records, err := loadBatchRecords(path, format)
+	if err != nil {
+		t.Fatalf("loadBatchRecords() failed: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+	if r := records[0]; r.Start != "2025-05-01 09:00" || r.Duration != "45m" || len(r.Categories) != 1 || len(r.Alarms) != 1 {
+		t.Errorf("unexpected first record: %+v", r)
+	}
+	if r := records[1]; r.Start != "2025-05-02" || !r.AllDay {
+		t.Errorf("a TOML local date should become YYYY-MM-DD: %+v", r)
+	}
+	if r := records[2]; r.Start != "2025-05-03T10:00:00+01:00" || r.Priority != "2" {
+		t.Errorf("an offset date-time should stay RFC 3339: %+v", r)
+	}
+
+	if err := os.WriteFile(path, []byte("title = \"no events\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadBatchFromTOML(path); err == nil {
+		t.Error("expected error for a TOML file without [[events]]")
+	}
+}
+
+func TestBatchExpandsWeeklySchedule(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "week.yaml")
+	yml := `schedule: 2025-09-01..2025-09-14
+timezone: Europe/Madrid
+skip: [2025-09-08]
+monday:
+  - "10:00-11:30": Focus block
+  - "09:00": Standup
+tue,thu:
+  - {time: "18:00", summary: Gym, duration: 1h, location: Sports centre}
+`
+	if err := os.WriteFile(input, []byte(yml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	records, err := loadBatchFromYAML(input)
+	if err != nil {
+		t.Fatalf("loadBatchFromYAML() failed: %v", err)
+	}
+	var got []string
+	for _, r := range records {
+		got = append(got, r.Start+" "+r.Summary)
+	}
+	want := []string{
+		"2025-09-01 09:00 Standup", "2025-09-01 10:00 Focus block", "2025-09-02 18:00 Gym",
+		"2025-09-04 18:00 Gym", "2025-09-09 18:00 Gym", "2025-09-11 18:00 Gym",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("schedule expanded to\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if r := records[1]; r.End != "11:30" || r.StartTZ != "Europe/Madrid" {
+		t.Errorf("a time range should set the end and the schedule timezone: %+v", r)
+	}
+
+	output := filepath.Join(dir, "week.ics")
+	if err := runRootErr(t, "batch", "-i", input, "-o", output); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	ics := string(data)
+	for _, want := range []string{"DTSTART;TZID=Europe/Madrid:20250901T100000", "DTEND;TZID=Europe/Madrid:20250901T113000", "LOCATION:Sports centre"} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+	if n := strings.Count(ics, "BEGIN:VEVENT"); n != 6 {
+		t.Errorf("expected 6 events, got %d", n)
+	}
+}
+
+func TestParseScheduleRejectsBadInput(t *testing.T) {
+	cases := map[string]map[string]interface{}{
+		"no range":    {"monday": []interface{}{map[string]interface{}{"09:00": "Standup"}}},
+		"bad weekday": {"schedule": "2025-09-01..2025-09-07", "someday": []interface{}{map[string]interface{}{"09:00": "Standup"}}},
+		"bad time":    {"schedule": "2025-09-01..2025-09-07", "monday": []interface{}{map[string]interface{}{"summary": "Standup", "time": "9am-ish"}}},
+		"not a list":  {"schedule": "2025-09-01..2025-09-07", "monday": "Standup"},
+	}
+	for name, doc := range cases {
+		if _, err := parseSchedule(doc); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestFeedServer(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	dir := t.TempDir()
+	ics := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nUID:1\r\nSUMMARY:Gym\r\nDTSTART:20250101T070000Z\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	if err := os.WriteFile(filepath.Join(dir, "gym.ics"), []byte(ics), 0644); err != nil {
+		t.Fatal(err)
+	}
+	csvData := "summary,start,duration,start_tz\nStandup,2025-05-01 09:00,15m,UTC\n"
+	if err := os.WriteFile(filepath.Join(dir, "work.csv"), []byte(csvData), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(newFeedHandler(feedOptions{dir: dir, token: token, maxAge: 5 * time.Minute}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func getFeed(t *testing.T, url string, header http.Header) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(body)
+}
+
+func TestServeICSWithCacheHeaders(t *testing.T) {
+	srv := newTestFeedServer(t, "")
+
+	resp, body := getFeed(t, srv.URL+"/gym.ics", nil)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, "SUMMARY:Gym") {
+		t.Fatalf("status %d, body %q", resp.StatusCode, body)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/calendar; charset=utf-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if cc := resp.Header.Get("Cache-Control"); cc != "private, max-age=300" {
+		t.Errorf("Cache-Control = %q", cc)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" || resp.Header.Get("Last-Modified") == "" {
+		t.Fatalf("missing validators: %v", resp.Header)
+	}
+
+	resp, _ = getFeed(t, srv.URL+"/gym.ics", http.Header{"If-None-Match": {etag}})
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("conditional GET status = %d, want 304", resp.StatusCode)
+	}
+
+	for _, p := range []string{"/notes.txt", "/sub/gym.ics", "/missing.ics"} {
+		if resp, _ := getFeed(t, srv.URL+p, nil); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("GET %s status = %d, want 404", p, resp.StatusCode)
+		}
+	}
+}
+
+func TestServeConvertsBatchFilesWithStableOutput(t *testing.T) {
+	srv := newTestFeedServer(t, "")
+
+	first, body := getFeed(t, srv.URL+"/work.ics", nil)
+	if first.StatusCode != http.StatusOK || !strings.Contains(body, "Standup") || !strings.Contains(body, "X-WR-CALNAME:work") {
+		t.Fatalf("status %d, body %q", first.StatusCode, body)
+	}
+	second, _ := getFeed(t, srv.URL+"/work.ics", nil)
+	if first.Header.Get("ETag") != second.Header.Get("ETag") {
+		t.Error("unchanged batch file should keep the same ETag")
+	}
+
+	_, index := getFeed(t, srv.URL+"/", nil)
+	if index != "/gym.ics\n/work.ics\n" {
+		t.Errorf("index = %q", index)
+	}
+}
+
+func TestServeConvertsTOMLBatchFiles(t *testing.T) {
+	dir := t.TempDir()
+	data := "[[events]]\nsummary = \"Climbing\"\nstart = 2025-05-02T18:00:00Z\nduration = \"2h\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "club.toml"), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(newFeedHandler(feedOptions{dir: dir}))
+	t.Cleanup(srv.Close)
+
+	resp, body := getFeed(t, srv.URL+"/club.ics", nil)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, "SUMMARY:Climbing") {
+		t.Fatalf("status %d, body %q", resp.StatusCode, body)
+	}
+	if _, index := getFeed(t, srv.URL+"/", nil); index != "/club.ics\n" {
+		t.Errorf("index = %q", index)
+	}
+}
+
+func TestServeRequiresToken(t *testing.T) {
+	srv := newTestFeedServer(t, "s3cret")
+
+	if resp, _ := getFeed(t, srv.URL+"/gym.ics", nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("no token: status %d, want 401", resp.StatusCode)
+	}
+	if resp, _ := getFeed(t, srv.URL+"/gym.ics?token=wrong", nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("wrong token: status %d, want 401", resp.StatusCode)
+	}
+	if resp, _ := getFeed(t, srv.URL+"/gym.ics?token=s3cret", nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("query token: status %d, want 200", resp.StatusCode)
+	}
+	if resp, _ := getFeed(t, srv.URL+"/gym.ics", http.Header{"Authorization": {"Bearer s3cret"}}); resp.StatusCode != http.StatusOK {
+		t.Errorf("bearer token: status %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestServeFeedsNameTheirSourceAndRefresh(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "work.csv"), []byte("summary,start,duration,start_tz\nStandup,2025-05-01 09:00,15m,UTC\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(newFeedHandler(feedOptions{dir: dir, token: "s3cret", refresh: time.Hour}))
+	t.Cleanup(srv.Close)
+
+	_, body := getFeed(t, srv.URL+"/work.ics?token=s3cret", nil)
+	for _, want := range []string{"SOURCE;VALUE=URI:" + srv.URL + "/work.ics\r\n", "REFRESH-INTERVAL;VALUE=DURATION:PT1H\r\n"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("feed missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "s3cret") {
+		t.Error("the token must not be written into the feed")
+	}
+
+	public := httptest.NewServer(newFeedHandler(feedOptions{dir: dir, publicURL: "https://cal.example.com"}))
+	t.Cleanup(public.Close)
+	if _, body := getFeed(t, public.URL+"/work.ics", nil); !strings.Contains(body, "SOURCE;VALUE=URI:https://cal.example.com/work.ics\r\n") ||
+		strings.Contains(body, "REFRESH-INTERVAL") {
+		t.Errorf("expected SOURCE from --public-url and no refresh interval:\n%s", body)
+	}
+}
+
+func TestServeStreamsFeedsWithSourceETags(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "work.csv")
+	if err := os.WriteFile(src, []byte("summary,start,duration,start_tz\nStandup,2025-05-01 09:00,15m,UTC\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gym := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nEND:VCALENDAR\r\n"
+	if err := os.WriteFile(filepath.Join(dir, "gym.ics"), []byte(gym), 0644); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(newFeedHandler(feedOptions{dir: dir}))
+	t.Cleanup(srv.Close)
+
+	resp, body := getFeed(t, srv.URL+"/gym.ics", nil)
+	if body != gym || resp.ContentLength != int64(len(gym)) {
+		t.Errorf("gym.ics = %q with length %d, want the file as is", body, resp.ContentLength)
+	}
+
+	first, _ := getFeed(t, srv.URL+"/work.ics", nil)
+	later := time.Now().Add(time.Hour)
+	if err := os.WriteFile(src, []byte("summary,start,duration,start_tz\nRetro,2025-05-02 16:00,1h,UTC\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(src, later, later); err != nil {
+		t.Fatal(err)
+	}
+	second, body := getFeed(t, srv.URL+"/work.ics", nil)
+	if !strings.Contains(body, "SUMMARY:Retro") || strings.Contains(body, "Standup") {
+		t.Errorf("changed batch file should be converted again:\n%s", body)
+	}
+	if first.Header.Get("ETag") == second.Header.Get("ETag") {
+		t.Error("changed batch file should get a new ETag")
+	}
+
+	req, err := http.NewRequest(http.MethodHead, srv.URL+"/work.ics", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head.Body.Close()
+	if head.StatusCode != http.StatusOK || head.Header.Get("ETag") != second.Header.Get("ETag") {
+		t.Errorf("HEAD = %d with ETag %q, want 200 with %q", head.StatusCode, head.Header.Get("ETag"), second.Header.Get("ETag"))
+	}
+}
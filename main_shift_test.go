@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShiftICSFileWithFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "trip.ics")
+	content := `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Tempus//Test//EN
+BEGIN:VEVENT
+UID:flight@test
+SUMMARY:Flight
+CATEGORIES:Travel
+DTSTART;TZID=Europe/Madrid:20250601T080000
+DTEND;TZID=Europe/Madrid:20250601T100000
+END:VEVENT
+BEGIN:VEVENT
+UID:review@test
+SUMMARY:Review
+CATEGORIES:Work
+DTSTART:20250602T090000Z
+DTEND:20250602T100000Z
+END:VEVENT
+END:VCALENDAR
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write ICS: %v", err)
+	}
+
+	cmd := newShiftCmd()
+	mustSetFlag(t, cmd, "input", path)
+	mustSetFlag(t, cmd, "days", "+7")
+	mustSetFlag(t, cmd, "by", "-30m")
+	mustSetFlag(t, cmd, "filter", "category=travel")
+	if err := runShift(cmd, nil); err != nil {
+		t.Fatalf("shift failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "trip-shifted.ics"))
+	if err != nil {
+		t.Fatalf("expected default output file: %v", err)
+	}
+	out := string(data)
+	for _, want := range []string{
+		"DTSTART;TZID=Europe/Madrid:20250608T073000",
+		"DTEND;TZID=Europe/Madrid:20250608T093000",
+		"DTSTART:20250602T090000Z",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "SEQUENCE:1") != 1 {
+		t.Errorf("expected SEQUENCE bump on the shifted event only:\n%s", out)
+	}
+}
+
+func TestShiftBatchFileToTimezone(t *testing.T) {
+	tmpDir := t.TempDir()
+	input := filepath.Join(tmpDir, "sprint.csv")
+	csv := "summary,start,end,start_tz\nPlanning,2025-06-02 09:00,2025-06-02 10:00,Europe/Madrid\n"
+	if err := os.WriteFile(input, []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(tmpDir, "moved.ics")
+
+	cmd := newShiftCmd()
+	mustSetFlag(t, cmd, "input", input)
+	mustSetFlag(t, cmd, "output", output)
+	mustSetFlag(t, cmd, "to-tz", "America/New_York")
+	if err := runShift(cmd, nil); err != nil {
+		t.Fatalf("shift failed: %v", err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "DTSTART;TZID=America/New_York:20250602T090000") {
+		t.Errorf("expected wall clock kept in new timezone:\n%s", data)
+	}
+}
+
+func TestShiftRejectsBadFlags(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags map[string]string
+	}{
+		{"no change", map[string]string{"input": "x.ics"}},
+		{"keep-instant without tz", map[string]string{"input": "x.ics", "by": "1h", "keep-instant": "true"}},
+		{"bad filter", map[string]string{"input": "x.ics", "by": "1h", "filter": "colour=red"}},
+		{"in-place batch", map[string]string{"input": "x.csv", "by": "1h", "in-place": "true"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newShiftCmd()
+			for k, v := range tt.flags {
+				mustSetFlag(t, cmd, k, v)
+			}
+			if err := runShift(cmd, nil); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestParseSignedDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"1h":    time.Hour,
+		"+90":   90 * time.Minute,
+		"-30m":  -30 * time.Minute,
+		"-1d":   -24 * time.Hour,
+		"2h15m": 2*time.Hour + 15*time.Minute,
+	}
+	for in, want := range tests {
+		got, err := parseSignedDuration(in)
+		if err != nil || got != want {
+			t.Errorf("parseSignedDuration(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+}
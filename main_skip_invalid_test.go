@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const skipInvalidCSV = "summary,start,duration\n" +
+	"Good,2025-05-01 09:00,1h\n" +
+	"Bad,not a date,1h\n" +
+	"Also good,2025-05-02 09:00,30m\n" +
+	"Worse,2025-13-01 09:00,1h\n"
+
+func TestBatchSkipInvalidWritesValidRows(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "events.csv")
+	if err := os.WriteFile(input, []byte(skipInvalidCSV), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "strict.ics")
+	if err := runRootErr(t, "batch", "-i", input, "-o", output, "--no-fix"); err == nil || !strings.Contains(err.Error(), "row 2") {
+		t.Fatalf("without --skip-invalid the first bad row should abort, got %v", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("an aborted batch should not write %s", output)
+	}
+
+	output = filepath.Join(dir, "skipped.ics")
+	err := runRootErr(t, "batch", "-i", input, "-o", output, "--skip-invalid")
+	if err == nil || !strings.Contains(err.Error(), "skipped 2 invalid row(s) of 4") {
+		t.Fatalf("expected a non-zero exit summarizing the skipped rows, got %v", err)
+	}
+	data, readErr := os.ReadFile(output)
+	if readErr != nil {
+		t.Fatalf("valid rows should still be written: %v", readErr)
+	}
+	ics := string(data)
+	if n := strings.Count(ics, "BEGIN:VEVENT"); n != 2 || !strings.Contains(ics, "SUMMARY:Also good") {
+		t.Errorf("expected the 2 valid events, got %d:\n%s", n, ics)
+	}
+}
+
+func TestBatchMaxErrorsStopsWithoutWriting(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "events.csv")
+	if err := os.WriteFile(input, []byte(skipInvalidCSV), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "limited.ics")
+	err := runRootErr(t, "batch", "-i", input, "-o", output, "--max-errors", "1")
+	if err == nil || !strings.Contains(err.Error(), "stopped at row 4") {
+		t.Fatalf("expected --max-errors 1 to stop at the second bad row, got %v", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("exceeding --max-errors should not write %s", output)
+	}
+
+	output = filepath.Join(dir, "within.ics")
+	if err := runRootErr(t, "batch", "-i", input, "-o", output, "--max-errors", "2"); err == nil {
+		t.Error("skipped rows should still make the run fail")
+	}
+	if _, err := os.Stat(output); err != nil {
+		t.Errorf("within --max-errors the valid rows should be written: %v", err)
+	}
+
+	if err := runRootErr(t, "batch", "-i", input, "-o", output, "--max-errors", "-1"); err == nil {
+		t.Error("expected an error for a negative --max-errors")
+	}
+}
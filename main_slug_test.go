@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"tempus/internal/utils"
+)
+
+func TestQuickDefaultFileNames(t *testing.T) {
+	start := time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+	for summary, want := range map[string]string{
+		"Reunião de coordenação": "reuniao-de-coordenacao.ics",
+		"Xuntanza na Coruña":     "xuntanza-na-coruna.ics",
+		"会議":                     "2030-03-04-6f7de8e4.ics",
+		"":                       "2030-03-04.ics",
+	} {
+		if got := getQuickOutput(newQuickCmd(), summary, start); got != want {
+			t.Errorf("getQuickOutput(%q) = %q, want %q", summary, got, want)
+		}
+	}
+}
+
+func TestBatchPersonFileNamesFromAnyScript(t *testing.T) {
+	dir := setupCommandTest(t)
+	t.Cleanup(func() { utils.SetSlugMaxLength(0) })
+	runRootStdout(t, "config", "set", "output.name_max_length", "12")
+
+	input := filepath.Join(dir, "family.csv")
+	csvData := strings.Join([]string{
+		"summary,start,duration,start_tz,people",
+		"Natação,2030-03-04 17:00,1h,UTC,João",
+		"Школа,2030-03-05 08:00,6h,UTC,Анна",
+		"Кружок,2030-03-06 16:00,1h,UTC,Олег",
+		"Dentista,2030-03-07 10:00,30m,UTC,Maria da Conceição",
+	}, "\n")
+	if err := os.WriteFile(input, []byte(csvData), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runRootErr(t, "batch", "-i", input, "-o", filepath.Join(dir, "family.ics")); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+
+	for _, name := range []string{
+		"family-joao.ics",
+		"family-" + utils.SlugOrHash("Анна", "") + ".ics",
+		"family-" + utils.SlugOrHash("Олег", "") + ".ics",
+		"family-maria-da.ics",
+	} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s: %v", name, err)
+		}
+	}
+	if utils.SlugOrHash("Анна", "") == utils.SlugOrHash("Олег", "") {
+		t.Error("names in other scripts should not share a file")
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateWithSolarStart(t *testing.T) {
+	dir := setupCommandTest(t)
+	output := filepath.Join(dir, "run.ics")
+
+	runRootStdout(t, "create", "Morning run", "--start", "2025-06-20 sunrise+30m", "--location-geo", "40.4168,-3.7038",
+		"--rrule", "FREQ=DAILY;COUNT=3", "--exdate", "2025-06-21", "--start-tz", "Europe/Madrid", "--duration", "45m", "-o", output)
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	ics := string(data)
+	for _, want := range []string{
+		"DTSTART;TZID=Europe/Madrid:20250620T071500",
+		"DTEND;TZID=Europe/Madrid:20250620T080000",
+		"DESCRIPTION:sunrise at 06:45",
+		"DTSTART;TZID=Europe/Madrid:20250622T071500",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("output missing %q:\n%s", want, ics)
+		}
+	}
+	if n := strings.Count(ics, "BEGIN:VEVENT"); n != 2 {
+		t.Errorf("expected one event per day minus the exdate, got %d", n)
+	}
+	if strings.Contains(ics, "FREQ=DAILY") {
+		t.Error("solar events should be written as single events, not an RRULE")
+	}
+}
+
+func TestCreateWithSolarEnd(t *testing.T) {
+	dir := setupCommandTest(t)
+	output := filepath.Join(dir, "day.ics")
+
+	runRootStdout(t, "create", "Daylight", "--start", "2025-06-21 sunrise", "--end", "sunset",
+		"--location-geo", "40.4168,-3.7038", "--start-tz", "Europe/Madrid", "-o", output)
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	if !strings.Contains(string(data), "DTEND;TZID=Europe/Madrid:20250621T214900") {
+		t.Errorf("expected the event to end at sunset:\n%s", data)
+	}
+}
+
+func TestCreateRejectsBadSolarInput(t *testing.T) {
+	setupCommandTest(t)
+	for _, args := range [][]string{
+		{"--start", "sunrise"},
+		{"--start", "sunrise", "--location-geo", "north"},
+		{"--start", "sunrise+soon", "--location-geo", "40,-3"},
+		{"--start", "sunrise", "--location-geo", "40,-3", "--end", "2025-06-21 10:00"},
+		{"--start", "2025-06-21 sunset", "--end", "sunrise", "--location-geo", "40,-3"},
+		{"--start", "2025-06-21 sunrise", "--location-geo", "69.65,18.96"},
+	} {
+		if err := runRootErr(t, append([]string{"create", "Walk", "-o", filepath.Join(t.TempDir(), "x.ics")}, args...)...); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
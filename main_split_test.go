@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitCommand(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "yoga.ics")
+	ics := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nUID:yoga@example.com\r\nSUMMARY:Yoga\r\n" +
+		"DTSTART;TZID=Europe/Madrid:20301007T190000\r\nDTEND;TZID=Europe/Madrid:20301007T200000\r\n" +
+		"RRULE:FREQ=WEEKLY;COUNT=8\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	if err := os.WriteFile(input, []byte(ics), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := runRootStdout(t, "split", "-i", input, "--at", "2030-10-21", "--time", "18:00", "--in-place")
+	for _, want := range []string{"Yoga: 2 occurrence(s) up to 2030-10-20", "new series from 20301021T180000 Europe/Madrid (UID yoga-20301021@example.com)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+	data, err := os.ReadFile(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"RRULE:FREQ=WEEKLY;COUNT=2", "RRULE:FREQ=WEEKLY;COUNT=6", "DTEND;TZID=Europe/Madrid:20301021T190000"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("split file lacks %q:\n%s", want, data)
+		}
+	}
+
+	if err := runRootErr(t, "split", "-i", input, "--at", "2030-10-21"); err == nil || !strings.Contains(err.Error(), "nothing to change") {
+		t.Errorf("split without changes: %v", err)
+	}
+	if err := runRootErr(t, "split", "-i", input, "--at", "21/10/2030", "--time", "18:00"); err == nil || !strings.Contains(err.Error(), "invalid --at") {
+		t.Errorf("split with a bad --at: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStatsCommand(t *testing.T) {
+	setupCommandTest(t)
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "events.csv")
+	csv := "summary,start,end,start_tz,categories\n" +
+		"Standup,2027-01-04 09:00,2027-01-04 09:15,Europe/Madrid,Work\n" +
+		"Review,2027-01-04 15:00,2027-01-04 16:00,Europe/Madrid,Work\n" +
+		"Dentist,2027-02-10 10:00,2027-02-10 11:00,Europe/Madrid,\n"
+	if err := os.WriteFile(csvPath, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := runRootStdout(t, "stats", "-i", csvPath)
+	for _, want := range []string{"3 event(s)", "Timezones: Europe/Madrid", "2  Work", "1  (no category)", "2  2027-01", "2  Mon 01/04/2027"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	// The batch file is measured as the .ics batch writes.
+	icsPath := filepath.Join(dir, "events.ics")
+	if err := runRootErr(t, "batch", "-i", csvPath, "-o", icsPath); err != nil {
+		t.Fatal(err)
+	}
+	var report statsReport
+	if err := json.Unmarshal([]byte(runRootStdout(t, "stats", "-i", icsPath, "--json")), &report); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(icsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Events != 3 || report.Bytes != int(info.Size()) || !strings.HasSuffix(report.First.Summary, "Standup") || len(report.Warnings) != 0 {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+func TestStatsWarnings(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "big.ics")
+	ics := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:1\r\nSUMMARY:Call\r\nDTSTART;TZID=Europe/Paris:20260105T090000\r\n" +
+		"DESCRIPTION:" + strings.Repeat("x", statsLargeICS) + "\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	if err := os.WriteFile(path, []byte(ics), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var report statsReport
+	if err := json.Unmarshal([]byte(runRootStdout(t, "stats", "-i", path, "--output-format", "json")), &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Warnings) != 3 {
+		t.Errorf("want size, VTIMEZONE and folding warnings, got %q", report.Warnings)
+	}
+	if err := runRootErr(t, "stats"); err == nil {
+		t.Error("stats without --input should fail")
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateStatusAndClass(t *testing.T) {
+	dir := setupCommandTest(t)
+	output := filepath.Join(dir, "private.ics")
+
+	if err := runRootErr(t, "create", "1:1", "--start", "2025-05-01 09:00", "--duration", "30m",
+		"--status", "Tentative", "--class", "private", "-o", output); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	for _, want := range []string{"STATUS:TENTATIVE\r\n", "CLASS:PRIVATE\r\n"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("output missing %q:\n%s", want, data)
+		}
+	}
+
+	for _, args := range [][]string{{"--status", "maybe"}, {"--class", "secret"}} {
+		args = append([]string{"create", "Bad", "--start", "2025-05-01 09:00", "-o", filepath.Join(dir, "bad.ics")}, args...)
+		if err := runRootErr(t, args...); err == nil {
+			t.Errorf("expected an error for %v", args[len(args)-2:])
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBatchCategoryTaxonomy(t *testing.T) {
+	dir := setupCommandTest(t)
+	if err := os.MkdirAll(filepath.Join(dir, "tempus"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	taxonomy := "category_taxonomy:\n  - Work/ClientA\n  - Health/Medication\n"
+	if err := os.WriteFile(filepath.Join(dir, "tempus", "config.yaml"), []byte(taxonomy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	input := filepath.Join(dir, "events.csv")
+	csv := "summary,start,duration,categories\n" +
+		"Kickoff,2025-05-05 09:00,1h,work/clienta\n" +
+		"Pills,2025-05-05 20:00,5m,health / medication\n" +
+		"Review,2025-05-06 09:00,1h,Work/Clienta;Work/ClientZ\n" +
+		"Run,2025-05-06 18:00,1h,Fun/Kids\n"
+	if err := os.WriteFile(input, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "out.ics")
+	out := runRootStdout(t, "batch", "-i", input, "-o", output)
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ics := string(data)
+	for _, want := range []string{"CATEGORIES:Work/ClientA\r\n", "CATEGORIES:Health/Medication\r\n", "CATEGORIES:Fun/Kids\r\n"} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("output missing %q:\n%s", want, ics)
+		}
+	}
+	if !strings.Contains(out, `"Work/ClientZ"`) || !strings.Contains(out, `"Fun/Kids"`) {
+		t.Errorf("unknown leaves should be reported:\n%s", out)
+	}
+	if strings.Contains(out, `"Work/ClientA" is not`) {
+		t.Errorf("known categories should not be reported:\n%s", out)
+	}
+
+	// --filter-category matches whole levels.
+	export := runRootStdout(t, "export", "-i", output, "--format", "csv", "--filter-category", "Work")
+	if !strings.Contains(export, "Kickoff") || !strings.Contains(export, "Review") || strings.Contains(export, "Pills") {
+		t.Errorf("export --filter-category Work:\n%s", export)
+	}
+	next := runRootStdout(t, "next", "-i", output, "--from", "2025-05-01 00:00", "--filter-category", "health")
+	if !strings.Contains(next, "Pills") || strings.Contains(next, "Kickoff") {
+		t.Errorf("next --filter-category health:\n%s", next)
+	}
+	shifted := filepath.Join(dir, "shifted.ics")
+	runRootStdout(t, "shift", "-i", output, "-o", shifted, "--days", "1", "--filter-category", "Fun")
+	data, _ = os.ReadFile(shifted)
+	if !strings.Contains(string(data), "DTSTART:20250507T180000Z") || !strings.Contains(string(data), "DTSTART:20250505T090000Z") {
+		t.Errorf("only Fun events should move:\n%s", data)
+	}
+}
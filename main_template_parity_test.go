@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTemplateCreateReadsYAMLWithDefaultTZ(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "meetings.yaml")
+	yml := `- title: Planning
+  start_time: 2025-06-02 10:00
+  attendees: [ana@example.com, bo@example.com]
+- title: Review
+  start_time: 2025-06-03 15:00
+  timezone: America/New_York
+`
+	if err := os.WriteFile(input, []byte(yml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "out")
+	if err := runRootErr(t, "template", "create", "meeting", "--input", input, "--output-dir", out, "--default-tz", "Europe/Madrid"); err != nil {
+		t.Fatalf("template create failed: %v", err)
+	}
+	entries, err := os.ReadDir(out)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("expected 2 files in %s, got %d (%v)", out, len(entries), err)
+	}
+	var all string
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(out, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		all += string(data)
+	}
+	for _, want := range []string{"DTSTART;TZID=Europe/Madrid:20250602T100000", "DTSTART;TZID=America/New_York:20250603T150000", "bo@example.com"} {
+		if !strings.Contains(all, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+
+	if err := runRootErr(t, "template", "create", "meeting", "--input", input, "--default-tz", "Mars/Olympus"); err == nil {
+		t.Error("expected an error for an unknown --default-tz")
+	}
+}
+
+func TestTemplateCreateKeepsRowsFromOverwritingEachOther(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "same.toml")
+	data := `[[events]]
+title = "Standup"
+start_time = 2025-06-02T09:00:00
+
+[[events]]
+title = "Standup"
+start_time = 2025-06-02T09:00:00
+`
+	if err := os.WriteFile(input, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "out")
+	if err := runRootErr(t, "template", "create", "meeting", "--input", input, "--output-dir", out); err != nil {
+		t.Fatalf("template create failed: %v", err)
+	}
+	entries, err := os.ReadDir(out)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("two rows with the same filename should give 2 files, got %d (%v)", len(entries), err)
+	}
+
+	if err := runRootErr(t, "template", "create", "meeting", "--input", input, "--format", "timetable"); err == nil {
+		t.Error("expected an error for timetable input")
+	}
+}
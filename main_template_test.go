@@ -2,13 +2,16 @@ package main
 
 import (
 	"bufio"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"tempus/internal/testutil"
 	"testing"
 
+	"tempus/internal/journal"
 	"tempus/internal/prompts"
+	tpl "tempus/internal/templates"
 
 	"github.com/spf13/cobra"
 )
@@ -154,3 +157,201 @@ func TestTemplateCreateMedicalFromCSVGeneratesMultipleICS(t *testing.T) {
 		t.Fatalf("expected ICS content to contain VEVENT")
 	}
 }
+
+func TestTemplateMigrateUpgradesTemplatesDir(t *testing.T) {
+	dir := t.TempDir()
+	legacy := `name: legacy
+fields:
+  - key: title
+    name: Title
+    type: text
+    required: true
+  - key: start_time
+    name: Start
+    type: datetime
+    required: true
+  - key: duration
+    name: Duration
+    type: text
+output:
+  start_field: start_time
+  duration_field: duration
+  summary_tmpl: "{{title}}"
+`
+	path := filepath.Join(dir, "legacy.yaml")
+	if err := os.WriteFile(path, []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	templateCmd := newTemplateCmd()
+	templateCmd.SetArgs([]string{"migrate", "--templates-dir", dir})
+	if err := templateCmd.Execute(); err != nil {
+		t.Fatalf("template migrate failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	if !strings.Contains(content, fmt.Sprintf("schema_version: %d", tpl.CurrentSchemaVersion)) || !strings.Contains(content, "type: duration") {
+		t.Fatalf("expected migrated template, got:\n%s", content)
+	}
+}
+
+const typedTemplateYAML = `schema_version: 2
+name: visit
+fields:
+  - key: who
+    name: Visitor email
+    type: email
+    required: true
+  - key: start_time
+    name: Start
+    type: datetime
+    required: true
+  - key: room
+    name: Room
+    type: enum
+    options: [Red, Blue, Green]
+    default: Red
+  - key: guests
+    name: Guests
+    type: number
+    min: 1
+    max: 4
+  - key: timezone
+    name: Timezone
+    type: timezone
+    default: UTC
+output:
+  start_field: start_time
+  start_tz_field: timezone
+  summary_tmpl: "Visit {{who}} ({{room}}, {{guests}})"
+`
+
+func newTypedTemplateCmd(t *testing.T) (*cobra.Command, string) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Cleanup(func() { journalOp = journal.Op{} })
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "visit.yaml"), []byte(typedTemplateYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	createCmd := findTemplateCreateCmd()
+	outputDir := t.TempDir()
+	mustSetFlag(t, createCmd, testutil.TemplatesDir, dir)
+	mustSetFlag(t, createCmd, "output-dir", outputDir)
+	return createCmd, outputDir
+}
+
+func TestTemplateCreatePromptsReaskInvalidTypedValues(t *testing.T) {
+	createCmd, outputDir := newTypedTemplateCmd(t)
+	inputs := strings.Join([]string{
+		"not-an-email",     // who (rejected)
+		"ana@example.com",  // who
+		"2025-10-15 09:30", // start_time
+		"3",                // room: Green
+		"7",                // guests (out of range)
+		"2",                // guests
+		"europe/madrid",    // timezone
+		"visit.ics",        // filename
+	}, "\n") + "\n"
+	prevScanner := prompts.Scanner
+	prompts.Scanner = bufio.NewScanner(strings.NewReader(inputs))
+	defer func() { prompts.Scanner = prevScanner }()
+
+	if err := runTemplateCreate(createCmd, []string{"visit"}); err != nil {
+		t.Fatalf("runTemplateCreate returned error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(outputDir, "visit.ics"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ics := string(data)
+	if !strings.Contains(ics, "SUMMARY:Visit ana@example.com (Green\\, 2)") {
+		t.Errorf("expected the re-asked values in the summary:\n%s", ics)
+	}
+	if !strings.Contains(ics, "TZID=Europe/Madrid") {
+		t.Errorf("expected the timezone in canonical form:\n%s", ics)
+	}
+}
+
+func TestTemplateCreateFromInputRejectsInvalidTypedValues(t *testing.T) {
+	createCmd, _ := newTypedTemplateCmd(t)
+	csvPath := filepath.Join(t.TempDir(), "visits.csv")
+	csv := "who,start_time,room,guests\nana@example.com,2025-10-15 09:30,blue,2\nbob@example.com,2025-10-16 09:30,Purple,1\n"
+	if err := os.WriteFile(csvPath, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mustSetFlag(t, createCmd, "input", csvPath)
+
+	err := runTemplateCreate(createCmd, []string{"visit"})
+	if err == nil || !strings.Contains(err.Error(), "row 2") || !strings.Contains(err.Error(), "field room") {
+		t.Fatalf("expected a row 2 room error, got %v", err)
+	}
+}
+
+func TestTemplateCreateTripEmitsRelatedEvents(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Cleanup(func() { journalOp = journal.Op{} })
+	createCmd := findTemplateCreateCmd()
+	outputDir := t.TempDir()
+	mustSetFlag(t, createCmd, testutil.TemplatesDir, filepath.Join("internal", "templates", "json"))
+	mustSetFlag(t, createCmd, "output-dir", outputDir)
+
+	csvPath := filepath.Join(t.TempDir(), "trips.csv")
+	csv := "flight_number,from,to,departure_time,departure_tz,arrival_time,arrival_tz,hotel\n" +
+		"IB3170,MAD,DUB,2025-11-20 10:05,Europe/Madrid,2025-11-20 11:40,Europe/Dublin,The Spencer\n"
+	if err := os.WriteFile(csvPath, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mustSetFlag(t, createCmd, "input", csvPath)
+
+	if err := runTemplateCreate(createCmd, []string{"trip"}); err != nil {
+		t.Fatalf("runTemplateCreate returned error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(outputDir, "trip-ib3170-2025-11-20.ics"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ics := string(data)
+	if n := strings.Count(ics, "BEGIN:VEVENT"); n != 4 {
+		t.Fatalf("expected the flight plus 3 related events, got %d:\n%s", n, ics)
+	}
+	for _, want := range []string{
+		"DTSTART;TZID=Europe/Madrid:20251119T100500", // online check-in, a day before
+		"DTSTART;TZID=Europe/Madrid:20251120T070500", // leave for the airport
+		"DTSTART;TZID=Europe/Dublin:20251120T124000", // hotel check-in after landing
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("expected %s in:\n%s", want, ics)
+		}
+	}
+}
+
+func TestTemplateInstallAndRemoveCommands(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join("internal", "templates", "json", "trip.json")
+
+	install := newTemplateCmd()
+	install.SetArgs([]string{"install", source, "--templates-dir", dir})
+	if err := install.Execute(); err != nil {
+		t.Fatalf("template install failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "trip.json")); err != nil {
+		t.Fatalf("expected trip.json to be installed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, tpl.InstalledLockFile)); err != nil {
+		t.Fatalf("expected %s: %v", tpl.InstalledLockFile, err)
+	}
+
+	remove := newTemplateCmd()
+	remove.SetArgs([]string{"remove", "trip", "--templates-dir", dir})
+	if err := remove.Execute(); err != nil {
+		t.Fatalf("template remove failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "trip.json")); !os.IsNotExist(err) {
+		t.Error("expected trip.json to be removed")
+	}
+}
@@ -365,7 +365,7 @@ func TestDetectTemplateInputFormat(t *testing.T) {
 		name    string
 		flag    string
 		path    string
-		want    string
+		want    batchFormat
 		wantErr bool
 	}{
 		{"auto csv", "auto", testutil.FilenameDataCSV, "csv", false},
@@ -375,7 +375,10 @@ func TestDetectTemplateInputFormat(t *testing.T) {
 		{"explicit json", "json", testutil.FilenameDataTXT, "json", false},
 		{"CSV uppercase", "CSV", testutil.FilenameDataTXT, "csv", false},
 		{"auto unknown", "auto", testutil.FilenameDataTXT, "", true},
-		{"invalid format", "yaml", testutil.FilenameDataCSV, "", true},
+		{"auto yaml", "auto", "data.yml", "yaml", false},
+		{"explicit toml", "toml", testutil.FilenameDataTXT, "toml", false},
+		{"timetable", "timetable", testutil.FilenameDataCSV, "", true},
+		{"invalid format", "xml", testutil.FilenameDataCSV, "", true},
 	}
 
 	for _, tt := range tests {
@@ -581,7 +584,7 @@ func TestLoadBatchRecords(t *testing.T) {
 	}
 }
 
-func TestLoadTemplateFromJSON(t *testing.T) {
+func TestLoadTemplateFromRows(t *testing.T) {
 	tests := []struct {
 		name    string
 		content string
@@ -628,13 +631,13 @@ func TestLoadTemplateFromJSON(t *testing.T) {
 				t.Fatalf(testutil.ErrMsgFailedToWriteTestFile, err)
 			}
 
-			got, err := loadTemplateFromJSON(path)
+			got, err := loadTemplateFromRows(path, batchFormatJSON)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("loadTemplateFromJSON() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("loadTemplateFromRows() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if len(got) != tt.want {
-				t.Errorf("loadTemplateFromJSON() returned %d records, want %d", len(got), tt.want)
+				t.Errorf("loadTemplateFromRows() returned %d records, want %d", len(got), tt.want)
 			}
 		})
 	}
@@ -658,13 +661,13 @@ func TestLoadTemplateRecords(t *testing.T) {
 	tests := []struct {
 		name    string
 		path    string
-		format  string
+		format  batchFormat
 		wantLen int
 		wantErr bool
 	}{
-		{"csv", csvPath, "csv", 1, false},
-		{"json", jsonPath, "json", 1, false},
-		{"unknown format", csvPath, "yaml", 0, true},
+		{"csv", csvPath, batchFormatCSV, 1, false},
+		{"json", jsonPath, batchFormatJSON, 1, false},
+		{"unknown format", csvPath, "xml", 0, true},
 	}
 
 	for _, tt := range tests {
@@ -722,6 +725,7 @@ func TestParseICSProperty(t *testing.T) {
 		{"with params", "DTSTART;TZID=Europe/Madrid:20250501T100000", "DTSTART", "20250501T100000", true},
 		{"no colon", "INVALID", "", "", false},
 		{"empty key", ":value", "", "", false},
+		{"only parameters", ";X=1:value", "", "", false},
 		{"empty value", "KEY:", "KEY", "", true},
 		{"lowercase", "summary:Test", "SUMMARY", "Test", true},
 		{testutil.TestNameWithSpaces, "  SUMMARY  :  Test  ", "SUMMARY", "Test", true},
@@ -746,6 +750,38 @@ func TestParseICSProperty(t *testing.T) {
 	}
 }
 
+func FuzzUnfoldICSLines(f *testing.F) {
+	f.Add("BEGIN:VEVENT\r\nSUMMARY;LANGUAGE=en:Long\r\n  text\r\n\tmore\r\nEND:VEVENT\r\n")
+	f.Add("\n \n\t\r\n:;:")
+	f.Add(";:")
+	f.Fuzz(func(t *testing.T, data string) {
+		lines, numbers := unfoldICSLinesNumbered(data)
+		if len(lines) != len(numbers) {
+			t.Fatalf("%d lines but %d line numbers", len(lines), len(numbers))
+		}
+		for i, line := range lines {
+			if i > 0 && numbers[i] <= numbers[i-1] {
+				t.Fatalf("line numbers out of order: %v", numbers)
+			}
+			if name, _, ok := parseICSProperty(line); ok && (name == "" || strings.Contains(name, ";")) {
+				t.Fatalf("parseICSProperty(%q) gave name %q", line, name)
+			}
+		}
+	})
+}
+
+func FuzzNormalizeDateTimeInput(f *testing.F) {
+	for _, seed := range []string{"2025/12/16 0900", "2025-1-5 9:00", " 2025-01-05 ", "9:00", "a-b-c d", "--", "2025-1-5  "} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		once := normalizeDateTimeInput(input)
+		if twice := normalizeDateTimeInput(once); twice != once {
+			t.Errorf("normalizeDateTimeInput is not stable: %q -> %q -> %q", input, once, twice)
+		}
+	})
+}
+
 func TestLintICSFile(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -980,7 +1016,7 @@ func TestBuildEventFromBatch(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			ev, err := buildEventFromBatch(tt.record, tt.fallbackTZ)
+			ev, err := buildEventFromBatch(tt.record, tt.fallbackTZ, false)
 			if (err != nil) != tt.wantErr {
 				t.Errorf(testutil.ErrMsgBuildEventFromBatchError+", wantErr %v", err, tt.wantErr)
 				return
@@ -1007,11 +1043,11 @@ func TestNewConfigCmd(t *testing.T) {
 
 	// Check subcommands
 	subcommands := cmd.Commands()
-	if len(subcommands) != 3 {
-		t.Errorf("expected 3 subcommands, got %d", len(subcommands))
+	if len(subcommands) != 7 {
+		t.Errorf("expected 7 subcommands, got %d", len(subcommands))
 	}
 
-	var hasSet, hasList, hasAlarmProfiles bool
+	var hasSet, hasList, hasAlarmProfiles, hasDoctor bool
 	for _, sub := range subcommands {
 		if strings.HasPrefix(sub.Use, "set") {
 			hasSet = true
@@ -1022,6 +1058,9 @@ func TestNewConfigCmd(t *testing.T) {
 		if strings.HasPrefix(sub.Use, "alarm-profiles") {
 			hasAlarmProfiles = true
 		}
+		if strings.HasPrefix(sub.Use, "doctor") {
+			hasDoctor = true
+		}
 	}
 	if !hasSet {
 		t.Error("config command missing 'set' subcommand")
@@ -1032,6 +1071,9 @@ func TestNewConfigCmd(t *testing.T) {
 	if !hasAlarmProfiles {
 		t.Error("config command missing 'alarm-profiles' subcommand")
 	}
+	if !hasDoctor {
+		t.Error("config command missing 'doctor' subcommand")
+	}
 }
 
 func TestRunConfigSet(t *testing.T) {
@@ -1358,7 +1400,7 @@ func TestBuildEventFromBatchWithCategories(t *testing.T) {
 		Categories: []string{"work", "urgent", "meeting"},
 	}
 
-	ev, err := buildEventFromBatch(rec, "")
+	ev, err := buildEventFromBatch(rec, "", false)
 	if err != nil {
 		t.Fatalf(testutil.ErrMsgBuildEventFromBatchError, err)
 	}
@@ -1376,7 +1418,7 @@ func TestBuildEventFromBatchWithRRule(t *testing.T) {
 		RRule:   testutil.RRuleDaily5Count,
 	}
 
-	ev, err := buildEventFromBatch(rec, "")
+	ev, err := buildEventFromBatch(rec, "", false)
 	if err != nil {
 		t.Fatalf(testutil.ErrMsgBuildEventFromBatchError, err)
 	}
@@ -1586,7 +1628,7 @@ func TestBuildEventFromBatchAllDayEdgeCases(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := buildEventFromBatch(tt.record, "")
+			_, err := buildEventFromBatch(tt.record, "", false)
 			if (err != nil) != tt.wantErr {
 				t.Errorf(testutil.ErrMsgBuildEventFromBatchError+", wantErr %v", err, tt.wantErr)
 			}
@@ -1605,7 +1647,7 @@ func TestBuildEventFromBatchWithExDatesAndAlarms(t *testing.T) {
 		Alarms:  []string{"15m", "30m"},
 	}
 
-	ev, err := buildEventFromBatch(rec, "")
+	ev, err := buildEventFromBatch(rec, "", false)
 	if err != nil {
 		t.Fatalf(testutil.ErrMsgBuildEventFromBatchError, err)
 	}
@@ -1619,6 +1661,55 @@ func TestBuildEventFromBatchWithExDatesAndAlarms(t *testing.T) {
 	}
 }
 
+func TestBuildEventFromBatchStrictInputKeepsRowLiteral(t *testing.T) {
+	rec := batchRecord{
+		Summary:    "Take medicaton",
+		Start:      testutil.DateTime20250501_1000,
+		Duration:   "5m",
+		Categories: []string{"helth"},
+	}
+
+	ev, err := buildEventFromBatch(rec, "", true)
+	if err != nil {
+		t.Fatalf(testutil.ErrMsgBuildEventFromBatchError, err)
+	}
+	if ev.Summary != "Take medicaton" {
+		t.Errorf("expected summary untouched, got %q", ev.Summary)
+	}
+	if len(ev.Categories) != 1 || ev.Categories[0] != "helth" {
+		t.Errorf("expected categories untouched, got %v", ev.Categories)
+	}
+
+	relaxed, err := buildEventFromBatch(rec, "", false)
+	if err != nil {
+		t.Fatalf(testutil.ErrMsgBuildEventFromBatchError, err)
+	}
+	if relaxed.Summary == ev.Summary {
+		t.Errorf("expected heuristics to change summary without strict input, got %q", relaxed.Summary)
+	}
+}
+
+func TestBuildEventFromBatchStrictInputRejectsInference(t *testing.T) {
+	tests := []struct {
+		name   string
+		record batchRecord
+	}{
+		{"clock-only start", batchRecord{Summary: "Standup", Start: "09:30", Duration: "15m"}},
+		{"slash date", batchRecord{Summary: "Standup", Start: "2025/5/1 09:30", Duration: "15m"}},
+		{"missing end and duration", batchRecord{Summary: "Standup", Start: testutil.DateTime20250501_1000}},
+		{"clock-only end", batchRecord{Summary: "Standup", Start: testutil.DateTime20250501_1000, End: "10:15"}},
+		{"all-day with time", batchRecord{Summary: "Holiday", Start: testutil.DateTime20250501_1000, AllDay: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := buildEventFromBatch(tt.record, "", true); err == nil || !strings.Contains(err.Error(), "--strict-input") {
+				t.Errorf("expected --strict-input error, got %v", err)
+			}
+		})
+	}
+}
+
 func TestValueAsStringSliceEdgeCases(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -1893,46 +1984,6 @@ func TestNormalizeEndTimeFromDuration(t *testing.T) {
 	}
 }
 
-func TestCityToIANA(t *testing.T) {
-	tests := []struct {
-		city string
-		want string
-	}{
-		// Spain
-		{"madrid", testutil.TZEuropeMadrid},
-		{"barcelona", testutil.TZEuropeMadrid},
-		{"melilla", testutil.TZAfricaCeuta},
-		{"ceuta", testutil.TZAfricaCeuta},
-		{"canarias", testutil.TZAtlanticCanary},
-		{"tenerife", testutil.TZAtlanticCanary},
-
-		// Brazil
-		{"pelotas", testutil.TZAmericaSaoPaulo},
-		{"porto alegre", testutil.TZAmericaSaoPaulo},
-		{"campo grande", testutil.TZAmericaCampoGrande},
-		{"manaus", "America/Manaus"},
-		{"rio", testutil.TZAmericaSaoPaulo},
-		{"sao paulo", testutil.TZAmericaSaoPaulo},
-
-		// Ireland/UK
-		{"dublin", testutil.TZEuropeDublin},
-		{"london", testutil.TZEuropeLondon},
-
-		// Unknown
-		{"unknown", ""},
-		{"", ""},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.city, func(t *testing.T) {
-			got := cityToIANA(tt.city)
-			if got != tt.want {
-				t.Errorf("cityToIANA(%q) = %q, want %q", tt.city, got, tt.want)
-			}
-		})
-	}
-}
-
 func TestLabelForField(t *testing.T) {
 	// This requires internal template structures, but we can test the logic
 	t.Skip(testutil.ErrMsgRequiresInternalStructures)
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateAcceptsRFC3339AndEpoch(t *testing.T) {
+	dir := setupCommandTest(t)
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "offset kept as an instant",
+			args: []string{"--start", "2025-12-16T10:00:00+01:00", "--end", "2025-12-16T11:30:00+01:00"},
+			want: []string{"DTSTART:20251216T090000Z", "DTEND:20251216T103000Z"},
+		},
+		{
+			name: "bracketed zone",
+			args: []string{"--start", "2025-12-16T10:00:00+01:00[Europe/Madrid]", "--duration", "1h", "--exdate", "2025-12-23T10:00:00+01:00"},
+			want: []string{"DTSTART;TZID=Europe/Madrid:20251216T100000", "DTEND;TZID=Europe/Madrid:20251216T110000", "EXDATE;TZID=Europe/Madrid:20251223T100000"},
+		},
+		{
+			name: "epoch in --start-tz",
+			args: []string{"--start", "1765875600", "--end", "@1765879200", "--start-tz", "America/New_York", "--alarm", "1765875000"},
+			want: []string{"DTSTART;TZID=America/New_York:20251216T040000", "DTEND;TZID=America/New_York:20251216T050000", "TRIGGER;VALUE=DATE-TIME:20251216T085000Z"},
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(dir, fmt.Sprintf("sync%d.ics", i))
+			if err := runRootErr(t, append([]string{"create", "Sync", "-o", output}, tt.args...)...); err != nil {
+				t.Fatalf("create failed: %v", err)
+			}
+			data, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatalf("expected output file: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("output missing %q:\n%s", want, data)
+				}
+			}
+		})
+	}
+}
+
+func TestBatchAcceptsRFC3339Columns(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "events.csv")
+	output := filepath.Join(dir, "batch.ics")
+	csv := "summary,start,end,start_tz\n" +
+		"Call,2025-12-16T10:00:00+01:00[Europe/Madrid],2025-12-16T11:00:00+01:00,\n" +
+		"Review,1765875600,,Europe/Dublin\n"
+	if err := os.WriteFile(input, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runRootErr(t, "batch", "-i", input, "-o", output, "--strict-input", "--default-tz", "UTC"); err == nil {
+		t.Fatal("--strict-input should still require an end for the second row")
+	}
+	if err := runRootErr(t, "batch", "-i", input, "-o", output, "--default-tz", "UTC"); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	for _, want := range []string{
+		"DTSTART;TZID=Europe/Madrid:20251216T100000",
+		"DTEND;TZID=Europe/Madrid:20251216T110000",
+		"DTSTART;TZID=Europe/Dublin:20251216T090000",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("output missing %q:\n%s", want, data)
+		}
+	}
+}
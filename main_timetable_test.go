@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBatchExpandsYAMLTimetable(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "semester.yaml")
+	output := filepath.Join(dir, "semester.ics")
+	timetable := `semester:
+  start: 2025-09-08
+  end: 2025-12-19
+timezone: Europe/Madrid
+breaks: [2025-10-13, 2025-12-01..2025-12-05]
+classes:
+  - Mon,Wed 09:00-10:30 Algebra Room B2
+  - {class: "Fri 12:00-13:00 Physics @ Lab 3", alarms: -10m}
+  - days: Tue
+    time: 14:00-15:30
+    summary: History
+    location: A1
+    categories: [Study]
+`
+	if err := os.WriteFile(input, []byte(timetable), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	runRootStdout(t, "batch", "-i", input, "-o", output)
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	ics := string(data)
+	for _, want := range []string{
+		"SUMMARY:📚 Algebra\r\nLOCATION:Room B2",
+		"DTSTART;TZID=Europe/Madrid:20250908T090000",
+		"DTEND;TZID=Europe/Madrid:20250908T103000",
+		"EXDATE;TZID=Europe/Madrid:20251013T090000,20251201T090000",
+		"DTSTART;TZID=Europe/Madrid:20250910T090000",
+		"EXDATE;TZID=Europe/Madrid:20251203T090000",
+		"LOCATION:Lab 3",
+		"TRIGGER:-PT10M",
+		"SUMMARY:History\r\nLOCATION:A1",
+		"CATEGORIES:Study",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+	if n := strings.Count(ics, "RRULE:FREQ=WEEKLY;COUNT=15"); n != 4 {
+		t.Errorf("expected 4 weekly series over 15 weeks, got %d", n)
+	}
+}
+
+func TestBatchReadsCSVTimetable(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "semester.csv")
+	csv := "days,time,summary,location\n" +
+		"semester,2025-09-08..2025-09-28\n" +
+		"break,2025-09-15..2025-09-19\n" +
+		"Thu,10:00-11:00,Statistics,Room 4\n"
+	if err := os.WriteFile(input, []byte(csv), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	records, _, err := loadBatchInput(&batchOptions{input: input, formatFlag: "timetable"})
+	if err != nil {
+		t.Fatalf("loadBatchInput returned error: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected one series, got %+v", records)
+	}
+	rec := records[0]
+	if rec.Start != "2025-09-11 10:00" || rec.End != "2025-09-11 11:00" || rec.RRule != "FREQ=WEEKLY;COUNT=3" ||
+		len(rec.ExDates) != 1 || rec.ExDates[0] != "2025-09-18 10:00" || rec.Location != "Room 4" {
+		t.Errorf("unexpected record %+v", rec)
+	}
+}
+
+func TestBatchRejectsBadTimetables(t *testing.T) {
+	dir := setupCommandTest(t)
+	for name, content := range map[string]string{
+		"no-semester.yaml": "classes:\n  - Mon 09:00-10:00 Algebra\n",
+		"one-day.yaml":     "semester: 2025-09-08\nclasses:\n  - Mon 09:00-10:00 Algebra\n",
+		"bad-class.yaml":   "semester: 2025-09-08..2025-12-19\nclasses:\n  - Mon 9am Algebra\n",
+		"bad-break.yaml":   "semester: 2025-09-08..2025-12-19\nbreaks: [October]\nclasses:\n  - Mon 09:00-10:00 Algebra\n",
+	} {
+		input := filepath.Join(dir, name)
+		if err := os.WriteFile(input, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := runRootErr(t, "batch", "-i", input, "--dry-run"); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTimezoneListRegionAndCountryFilters(t *testing.T) {
+	setupCommandTest(t)
+
+	var zones []zoneJSON
+	out := runRootStdout(t, "timezone", "list", "--region", "oceania", "--output-format", "json")
+	if err := json.Unmarshal([]byte(out), &zones); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(zones) == 0 {
+		t.Fatal("no oceania zones")
+	}
+	for _, z := range zones {
+		if z.Region != "oceania" {
+			t.Errorf("%s has region %q in an oceania listing", z.IANA, z.Region)
+		}
+	}
+
+	zones = nil
+	out = runRootStdout(t, "timezone", "list", "--country", "es", "--output-format", "json")
+	if err := json.Unmarshal([]byte(out), &zones); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	var names []string
+	for _, z := range zones {
+		names = append(names, z.IANA)
+	}
+	if got := strings.Join(names, " "); got != "Atlantic/Canary Africa/Ceuta Europe/Madrid" {
+		t.Errorf("--country es = %s", got)
+	}
+
+	if err := runRootErr(t, "timezone", "list", "--region", "mars"); err == nil || !strings.Contains(err.Error(), "oceania") {
+		t.Errorf("expected an unknown-region error listing the regions, got %v", err)
+	}
+}
+
+func TestTimezoneInfoResolvesCityTypos(t *testing.T) {
+	setupCommandTest(t)
+
+	out := runRootStdout(t, "timezone", "info", "nw york")
+	if !strings.Contains(out, "IANA:       America/New_York") {
+		t.Errorf("timezone info \"nw york\":\n%s", out)
+	}
+	out = runRootStdout(t, "timezone", "info", "cordoba")
+	if !strings.Contains(out, "Did you mean") || !strings.Contains(out, "[America/Argentina/Cordoba]") ||
+		!strings.Contains(out, "[Europe/Madrid]") {
+		t.Errorf("timezone info cordoba should list both cities:\n%s", out)
+	}
+}
+
+func TestTimezoneFlagsAcceptCityNames(t *testing.T) {
+	dir := setupCommandTest(t)
+	output := filepath.Join(dir, "focus.ics")
+
+	runRootStdout(t, "focus", "--start", "2025-05-01 09:00", "--cycles", "1", "--tz", "lisbon", "-o", output)
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "DTSTART;TZID=Europe/Lisbon:20250501T090000") {
+		t.Errorf("--tz lisbon not resolved to Europe/Lisbon:\n%s", data)
+	}
+
+	// Names too ambiguous to resolve are still rejected by the command.
+	if err := runRootErr(t, "focus", "--start", "2025-05-01 09:00", "--tz", "xyzzy", "-o", output); err == nil {
+		t.Error("expected an error for an unknown timezone")
+	}
+}
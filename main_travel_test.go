@@ -0,0 +1,132 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const travelConfirmation = `Your trip is confirmed. Booking reference: ABC123
+
+Flight FR1234, Thu 25 Dec 2025
+Madrid (MAD) 08:30 → Dublin (DUB) 10:00
+
+Hotel: Dublin City Hotel
+Address: 123 O'Connell Street
+Check-in: 25 Dec 2025 14:00
+Check-out: 28 Dec 2025 11:00
+`
+
+func TestTravelParseWritesTrip(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "booking.txt")
+	if err := os.WriteFile(input, []byte(travelConfirmation), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "trip.ics")
+
+	got := runRootStdout(t, "travel", "parse", input, "--tz", "UTC", "-o", output)
+	for _, want := range []string{"Found 2 booking(s):", "✈️ Flight FR1234 MAD → DUB", "🏨 Check out: Dublin City Hotel"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("plan missing %q:\n%s", want, got)
+		}
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	ics := string(data)
+	for _, want := range []string{
+		"DTSTART;TZID=Europe/Madrid:20251225T083000",
+		"DTEND;TZID=Europe/Dublin:20251225T100000",
+		"DESCRIPTION:Booking reference: ABC123",
+		"LOCATION:Madrid Barajas (MAD)",
+		"TRIGGER:-P1D",
+		"DESCRIPTION:Online check-in opens",
+		"TRIGGER:-PT3H",
+		"DTSTART;TZID=Europe/Dublin:20251225T140000",
+		"DTSTART;TZID=Europe/Dublin:20251228T110000",
+		"LOCATION:Dublin City Hotel\\, 123 O'Connell Street",
+		"DESCRIPTION:Pack and check out",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+	if n := strings.Count(ics, "BEGIN:VEVENT"); n != 3 {
+		t.Errorf("expected a flight, a check-in and a check-out, got %d events", n)
+	}
+}
+
+func TestTravelParseBatchRoundTrips(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "booking.txt")
+	if err := os.WriteFile(input, []byte(travelConfirmation), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	batch := filepath.Join(dir, "trip.csv")
+
+	runRootStdout(t, "travel", "parse", input, "--no-alarms", "--batch", batch)
+	data, err := os.ReadFile(batch)
+	if err != nil {
+		t.Fatalf("expected batch file: %v", err)
+	}
+	csv := string(data)
+	if !strings.HasPrefix(csv, "summary,start,duration,start_tz,categories,alarms,end,end_tz,location,description\n") {
+		t.Errorf("unexpected header:\n%s", csv)
+	}
+	if !strings.Contains(csv, "Flight FR1234 MAD → DUB,2025-12-25 08:30,,Europe/Madrid,Travel;Flight,,2025-12-25 10:00,Europe/Dublin,Madrid Barajas (MAD)") {
+		t.Errorf("flight row missing:\n%s", csv)
+	}
+
+	output := filepath.Join(dir, "trip.ics")
+	runRootStdout(t, "batch", "-i", batch, "-o", output)
+	ics, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	if !strings.Contains(string(ics), "DTEND;TZID=Europe/Dublin:20251225T100000") {
+		t.Errorf("batch lost the arrival timezone:\n%s", ics)
+	}
+}
+
+func TestTravelParseAirportOverride(t *testing.T) {
+	dir := setupCommandTest(t)
+	input := filepath.Join(dir, "booking.txt")
+	if err := os.WriteFile(input, []byte("Flight XY123 2025-06-01 EGC MAD 09:00 10:40\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "trip.ics")
+
+	runRootStdout(t, "travel", "parse", input, "--airport-tz", "egc=Europe/Paris", "-o", output)
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	ics := string(data)
+	for _, want := range []string{
+		"DTSTART;TZID=Europe/Paris:20250601T090000",
+		"DTEND;TZID=Europe/Madrid:20250601T104000",
+		"LOCATION:EGC airport",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+}
+
+func TestTravelParseErrors(t *testing.T) {
+	dir := setupCommandTest(t)
+	empty := filepath.Join(dir, "empty.txt")
+	if err := os.WriteFile(empty, []byte("Thanks for booking with us!"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := runRootErr(t, "travel", "parse", empty); err == nil || !strings.Contains(err.Error(), "no flights, trains or hotel stays") {
+		t.Errorf("expected a no bookings error, got %v", err)
+	}
+	if err := runRootErr(t, "travel", "parse", empty, "--airport-tz", "Dublin"); err == nil || !strings.Contains(err.Error(), "invalid --airport-tz") {
+		t.Errorf("expected an --airport-tz error, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"tempus/internal/diag"
+)
+
+func TestTravelRulesBetween(t *testing.T) {
+	rules := travelRules{
+		pairs: []travelPair{
+			{"office", "gym", 20 * time.Minute},
+			{"gym", "office", 25 * time.Minute},
+			{"office", "city gym", 15 * time.Minute},
+			{"home", "clinic", 40 * time.Minute},
+		},
+		fallback: 30 * time.Minute,
+	}
+	tests := []struct {
+		from, to string
+		want     time.Duration
+	}{
+		{"Main Office", "Gym", 20 * time.Minute},
+		{"Gym", "Office", 25 * time.Minute},       // the listed direction wins
+		{"Office", "City  Gym", 15 * time.Minute}, // the most specific names win
+		{"Clinic", "Home", 40 * time.Minute},      // reverse of a listed pair
+		{"Library", "Home", 30 * time.Minute},     // fallback
+		{"Gym", " gym ", 0},                       // same place
+		{"", "Gym", 0},                            // unknown location
+	}
+	for _, tt := range tests {
+		if got := rules.between(tt.from, tt.to); got != tt.want {
+			t.Errorf("between(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestBatchTravelTime(t *testing.T) {
+	dir := setupCommandTest(t)
+	if err := os.MkdirAll(filepath.Join(dir, "tempus"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := "travel_times:\n  \"Office -> Gym\": 20m\n"
+	if err := os.WriteFile(filepath.Join(dir, "tempus", "config.yaml"), []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	input := filepath.Join(dir, "day.csv")
+	csv := "summary,start,duration,location\n" +
+		"Standup,2025-06-02 09:00,30m,Office\n" +
+		"Workout,2025-06-02 09:40,1h,Gym\n" +
+		"Review,2025-06-02 12:00,1h,Office\n" +
+		"Library,2025-06-02 13:00,1h,Library\n"
+	if err := os.WriteFile(input, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without --check-conflicts, --dry-run or --add-travel-time nothing is checked.
+	out := filepath.Join(dir, "day.ics")
+	if err := runRootErr(t, "batch", "--input", input, "--output", out); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(out); strings.Contains(string(data), "Travel") {
+		t.Errorf("travel buffers need --add-travel-time:\n%s", data)
+	}
+
+	report := runRootStdout(t, "batch", "--input", input, "--dry-run", "--output-format", "json")
+	var summary batchSummary
+	if err := json.Unmarshal([]byte(report), &summary); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, report)
+	}
+	var travel []diag.Warning
+	for _, w := range summary.Warnings {
+		if w.Code == diag.CodeTravel {
+			travel = append(travel, w)
+		}
+	}
+	// Office → Gym has 10 of 20 minutes; Gym → Office has plenty; Library has
+	// no travel time configured.
+	if len(travel) != 1 || !strings.Contains(travel[0].Message, "10 min") || !strings.Contains(travel[0].Message, "20 min") {
+		t.Fatalf("expected one tight-gap warning, got %+v", travel)
+	}
+
+	if err := runRootErr(t, "batch", "--input", input, "--output", out, "--add-travel-time", "--travel-time", "15m"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ics := string(data)
+	for _, want := range []string{
+		"SUMMARY:🚗 Travel: Office → Gym\r\nDTSTART:20250602T093000",
+		"SUMMARY:🚗 Travel: Gym → Office\r\nDTSTART:20250602T114000",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("calendar lacks %q:\n%s", want, ics)
+		}
+	}
+	// --travel-time covers Office → Library, but the events are back to back.
+	if strings.Contains(ics, "Travel: Office → Library") {
+		t.Errorf("back-to-back events leave no room for a travel buffer:\n%s", ics)
+	}
+
+	if err := runRootErr(t, "batch", "--input", input, "--output", out, "--add-travel-time", "--travel-time", "soon"); err == nil {
+		t.Error("expected an error for an invalid --travel-time")
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	tzpkg "tempus/internal/timezone"
+)
+
+// tzdataTestZip is a zoneinfo.zip whose zones are all plain UTC.
+func tzdataTestZip(t *testing.T) []byte {
+	t.Helper()
+	utc := []byte("TZif" + strings.Repeat("\x00", 16) +
+		"\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x04" +
+		"\x00\x00\x00\x00\x00\x00UTC\x00")
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"UTC", "Europe/Madrid", "America/New_York"} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write(utc)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestTimezoneRefreshInstallsAndSkipsCurrentRelease(t *testing.T) {
+	dir := setupCommandTest(t)
+	zipData := tzdataTestZip(t)
+	downloads := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/update.bash":
+			_, _ = w.Write([]byte("CODE=2099a\nDATA=2099a\n"))
+		case "/zoneinfo.zip":
+			downloads++
+			_, _ = w.Write(zipData)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	args := []string{"timezone", "refresh", "--url", srv.URL + "/zoneinfo.zip", "--version-url", srv.URL + "/update.bash"}
+
+	out := runRootStdout(t, args...)
+	if !strings.Contains(out, "2099a") || downloads != 1 {
+		t.Fatalf("first refresh: downloads=%d\n%s", downloads, out)
+	}
+	configDir := dir + "/tempus"
+	if got := tzpkg.InstalledTZDataVersion(configDir); got != "2099a" {
+		t.Errorf("installed version = %q", got)
+	}
+
+	out = runRootStdout(t, args...)
+	if !strings.Contains(out, "already up to date") || downloads != 1 {
+		t.Errorf("second refresh should skip the download: downloads=%d\n%s", downloads, out)
+	}
+	runRootStdout(t, append(args, "--force")...)
+	if downloads != 2 {
+		t.Errorf("--force should download again: downloads=%d", downloads)
+	}
+
+	runRootStdout(t, "timezone", "refresh", "--remove")
+	if _, err := os.Stat(tzpkg.TZDataPath(configDir)); !os.IsNotExist(err) {
+		t.Errorf("--remove left the database: %v", err)
+	}
+}
+
+func TestTimezoneRefreshRejectsBadDownload(t *testing.T) {
+	dir := setupCommandTest(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("<html>not found</html>"))
+	}))
+	t.Cleanup(srv.Close)
+
+	err := runRootErr(t, "timezone", "refresh", "--url", srv.URL+"/zoneinfo.zip", "--version-url", "")
+	if err == nil || !strings.Contains(err.Error(), "not a zoneinfo.zip") {
+		t.Fatalf("expected a bad-download error, got %v", err)
+	}
+	if _, err := os.Stat(tzpkg.TZDataPath(dir + "/tempus")); !os.IsNotExist(err) {
+		t.Errorf("a bad download was installed: %v", err)
+	}
+	err = runRootErr(t, "timezone", "refresh", "--url", srv.URL+"/zoneinfo.zip", "--version-url", srv.URL+"/update.bash")
+	if err == nil || !strings.Contains(err.Error(), "DATA=") {
+		t.Errorf("expected a missing-release error, got %v", err)
+	}
+}
+
+func TestVersionReportsTZData(t *testing.T) {
+	setupCommandTest(t)
+	out := runRootStdout(t, "version")
+	if !strings.Contains(out, "\ntzdata ") {
+		t.Errorf("version output missing the tzdata line:\n%s", out)
+	}
+	out = runRootStdout(t, "timezone", "info", "Europe/Madrid")
+	if !strings.Contains(out, "Database:   tzdata ") {
+		t.Errorf("timezone info missing the database line:\n%s", out)
+	}
+}
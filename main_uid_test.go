@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"tempus/internal/calendar"
+)
+
+func TestUIDFormatFromConfig(t *testing.T) {
+	dir := setupCommandTest(t)
+	t.Cleanup(func() { calendar.SetUIDFormat("", "") })
+
+	runRootStdout(t, "config", "set", "uid_domain", "@calendar.example.com")
+	runRootStdout(t, "config", "set", "uid_template", "team-{{id}}@{{domain}}")
+
+	input := filepath.Join(dir, "week.csv")
+	csvData := "summary,start,duration,start_tz\nStandup,2030-03-04 09:00,15m,UTC\nStandup,2030-03-04 09:00,15m,UTC\n"
+	if err := os.WriteFile(input, []byte(csvData), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "week.ics")
+	if err := runRootErr(t, "batch", "-i", input, "-o", output, "--stable-uids"); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uids := regexp.MustCompile(`(?m)^UID:(.*)\r$`).FindAllStringSubmatch(string(data), -1)
+	if len(uids) != 2 {
+		t.Fatalf("expected 2 UIDs in:\n%s", data)
+	}
+	if first, second := uids[0][1], uids[1][1]; !regexp.MustCompile(`^team-[0-9a-f]{32}@calendar\.example\.com$`).MatchString(first) ||
+		second != strings.TrimSuffix(first, "@calendar.example.com")+"-2@calendar.example.com" {
+		t.Errorf("unexpected UIDs %q and %q", first, second)
+	}
+
+	single := filepath.Join(dir, "call.ics")
+	runRootStdout(t, "create", "Call", "-s", "2030-03-05 10:00", "--duration", "30m", "-o", single)
+	if data, _ := os.ReadFile(single); !regexp.MustCompile(`UID:team-[0-9a-f-]{36}@calendar\.example\.com\r\n`).Match(data) {
+		t.Errorf("create should use the configured UID format:\n%s", data)
+	}
+
+	if err := runRootErr(t, "config", "set", "uid_template", "{{domain}}"); err == nil {
+		t.Error("expected a template without {{id}} to be rejected")
+	}
+}
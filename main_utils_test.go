@@ -1,49 +1,24 @@
 package main
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"tempus/internal/testutil"
 	"testing"
 	"time"
 
 	"tempus/internal/calendar"
+	"tempus/internal/config"
+
+	"github.com/spf13/viper"
 )
 
 // ============================================================================
 // Utility function tests - covering 0% functions
 // ============================================================================
 
-func TestLevenshteinDistance(t *testing.T) {
-	tests := []struct {
-		name string
-		s1   string
-		s2   string
-		want int
-	}{
-		{"empty strings", "", "", 0},
-		{"empty s1", "", "hello", 5},
-		{"empty s2", "hello", "", 5},
-		{"identical", "test", "test", 0},
-		{"one char different", "test", "best", 1},
-		{"completely different", "abc", "xyz", 3},
-		{"different lengths", "short", "longer string", 11}, // Actual levenshtein distance
-		{"insertion", "cat", "cats", 1},
-		{"deletion", "cats", "cat", 1},
-		{"substitution", "cat", "bat", 1},
-		{"multiple operations", "kitten", "sitting", 3},
-		{"case sensitive", "Test", "test", 1},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := levenshteinDistance(tt.s1, tt.s2)
-			if got != tt.want {
-				t.Errorf("levenshteinDistance(%q, %q) = %d, want %d", tt.s1, tt.s2, got, tt.want)
-			}
-		})
-	}
-}
-
 func TestMin(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -239,6 +214,33 @@ func TestDetectEventConflicts(t *testing.T) {
 	}
 }
 
+func TestDetectEventConflictsGroupsByDayAndCaps(t *testing.T) {
+	day1 := time.Date(2025, 9, 2, 9, 0, 0, 0, time.UTC)
+	day0 := time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+	var events []calendar.Event
+	// Six events all overlapping on the second day: 15 pairs.
+	for i := 0; i < 6; i++ {
+		start := day1.Add(time.Duration(i) * time.Minute)
+		events = append(events, calendar.Event{Summary: fmt.Sprintf("Busy %d", i), StartTime: start, EndTime: start.Add(time.Hour)})
+	}
+	events = append(events,
+		calendar.Event{Summary: "Early", StartTime: day0, EndTime: day0.Add(time.Hour)},
+		calendar.Event{Summary: "Clash", StartTime: day0.Add(30 * time.Minute), EndTime: day0.Add(2 * time.Hour)},
+		calendar.Event{Summary: "Holiday", StartTime: day0, EndTime: day0.Add(24 * time.Hour), AllDay: true},
+	)
+
+	got := detectEventConflicts(events)
+	if len(got) != 1+maxConflictsPerDay+1 {
+		t.Fatalf("got %d lines, want %d:\n%s", len(got), maxConflictsPerDay+2, strings.Join(got, "\n"))
+	}
+	if !strings.HasPrefix(got[0], "2025-09-01: ") || !strings.Contains(got[0], "Early") || !strings.Contains(got[0], "Clash") {
+		t.Errorf("the earlier day should come first: %q", got[0])
+	}
+	if last := got[len(got)-1]; !strings.HasPrefix(last, "2025-09-02") || !strings.Contains(last, "5") {
+		t.Errorf("the capped day should end with how many more pairs it has: %q", last)
+	}
+}
+
 func TestDetectOverwhelmDays(t *testing.T) {
 	now := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
 	threshold := 3
@@ -296,6 +298,10 @@ func TestDetectOverwhelmDays(t *testing.T) {
 func TestGeneratePrepTimeEvents(t *testing.T) {
 	// This function auto-detects events that need prep time based on keywords
 	// and generates prep events before them
+	rules, err := bufferRulesFromConfig(config.Defaults())
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	// Test meeting (should get 15min prep)
 	meetingEvent := calendar.Event{
@@ -307,7 +313,7 @@ func TestGeneratePrepTimeEvents(t *testing.T) {
 	}
 
 	events := []calendar.Event{meetingEvent}
-	prepEvents := generatePrepTimeEvents(events)
+	prepEvents, _ := generatePrepTimeEvents(events, rules)
 
 	// Should generate one prep event
 	if len(prepEvents) != 1 {
@@ -341,7 +347,7 @@ func TestGeneratePrepTimeEvents(t *testing.T) {
 		EndTime:   time.Date(2025, 5, 1, 15, 0, 0, 0, time.UTC),
 		StartTZ:   testutil.TZEuropeMadrid,
 	}
-	medicalPrep := generatePrepTimeEvents([]calendar.Event{doctorEvent})
+	medicalPrep, _ := generatePrepTimeEvents([]calendar.Event{doctorEvent}, rules)
 	if len(medicalPrep) != 1 {
 		t.Error("doctor appointment should generate prep event")
 	} else {
@@ -357,7 +363,7 @@ func TestGeneratePrepTimeEvents(t *testing.T) {
 		StartTime: time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC),
 		EndTime:   time.Date(2025, 5, 1, 10, 30, 0, 0, time.UTC),
 	}
-	focusPrep := generatePrepTimeEvents([]calendar.Event{focusEvent})
+	focusPrep, _ := generatePrepTimeEvents([]calendar.Event{focusEvent}, rules)
 	if len(focusPrep) != 1 {
 		t.Error("focus block should generate transition event")
 	} else {
@@ -373,7 +379,7 @@ func TestGeneratePrepTimeEvents(t *testing.T) {
 		StartTime: time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC),
 		EndTime:   time.Date(2025, 5, 1, 11, 0, 0, 0, time.UTC),
 	}
-	regularPrep := generatePrepTimeEvents([]calendar.Event{regularEvent})
+	regularPrep, _ := generatePrepTimeEvents([]calendar.Event{regularEvent}, rules)
 	if len(regularPrep) != 0 {
 		t.Error("regular event should not generate prep events")
 	}
@@ -385,13 +391,13 @@ func TestGeneratePrepTimeEvents(t *testing.T) {
 		EndTime:   time.Date(2025, 5, 2, 0, 0, 0, 0, time.UTC),
 		AllDay:    true,
 	}
-	allDayPrep := generatePrepTimeEvents([]calendar.Event{allDayEvent})
+	allDayPrep, _ := generatePrepTimeEvents([]calendar.Event{allDayEvent}, rules)
 	if len(allDayPrep) != 0 {
 		t.Error("all-day events should not generate prep events")
 	}
 
 	// Test with empty slice
-	emptyPrepEvents := generatePrepTimeEvents([]calendar.Event{})
+	emptyPrepEvents, _ := generatePrepTimeEvents([]calendar.Event{}, rules)
 	if len(emptyPrepEvents) != 0 {
 		t.Error("generatePrepTimeEvents() with empty slice should return no events")
 	}
@@ -415,24 +421,179 @@ func TestGetSmartDefaultDuration(t *testing.T) {
 		{"transition", "Transition period", time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC), 15},
 		{"default business hours", "Random event", time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC), 60},
 		{"empty business hours", "", time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC), 60},
+		{"longest keyword wins", "Medication break", time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC), 5},
+		{"spanish therapy", "Sesión de terapia", time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC), 60},
+		{"spanish doctor beats med", "Cita medico", time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC), 30},
+		{"evening window", "Random event", time.Date(2025, 5, 1, 19, 0, 0, 0, time.UTC), 90},
+		{"overnight window", "Random event", time.Date(2025, 5, 1, 2, 0, 0, 0, time.UTC), 30},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := getSmartDefaultDuration(tt.summary, tt.startTime)
+			got, _, err := smartDefaultDuration(config.Defaults(), tt.summary, tt.startTime)
 			wantDuration := time.Duration(tt.wantMin) * time.Minute
-			if got != wantDuration {
-				t.Errorf("getSmartDefaultDuration(%q, %v) = %v, want %v",
-					tt.summary, tt.startTime, got, wantDuration)
+			if err != nil || got != wantDuration {
+				t.Errorf("smartDefaultDuration(%q, %v) = %v, %v, want %v",
+					tt.summary, tt.startTime, got, err, wantDuration)
 			}
 		})
 	}
 }
 
+func TestSmartDefaultDurationFromConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, "tempus")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", tmpDir)
+	configContent := `duration_keywords:
+  terapia: 50m
+  focus: ""
+duration_time_of_day:
+  "09:00-12:00": 25m
+default_duration: 40m
+`
+	if err := os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(configContent), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	tests := []struct {
+		summary string
+		hour    int
+		want    time.Duration
+		rule    string
+	}{
+		{"Terapia semanal", 16, 50 * time.Minute, `keyword "terapia"`},
+		{"Lunch", 16, 45 * time.Minute, `keyword "lunch"`},
+		{"Focus block", 10, 25 * time.Minute, "start time 09:00-12:00"},
+		{"Focus block", 19, 40 * time.Minute, "default_duration"},
+	}
+	for _, tt := range tests {
+		start := time.Date(2025, 5, 1, tt.hour, 0, 0, 0, time.UTC)
+		got, rule, err := defaultBatchDuration(tt.summary, nil, start)
+		if err != nil || got != tt.want || rule != tt.rule {
+			t.Errorf("defaultBatchDuration(%q, %02d:00) = %v, %q, %v; want %v, %q", tt.summary, tt.hour, got, rule, err, tt.want, tt.rule)
+		}
+	}
+}
+
 // ============================================================================
 // Emoji and category functions
 // ============================================================================
 
+func TestDefaultBatchDurationPrefersCategoryConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, "tempus")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", tmpDir)
+	configContent := "category_durations:\n  therapy: 50m\n  broken: soon\n"
+	if err := os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(configContent), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	start := time.Date(2025, 5, 1, 14, 0, 0, 0, time.UTC)
+
+	got, rule, err := defaultBatchDuration("Therapy session", []string{"Therapy"}, start)
+	if err != nil || got != 50*time.Minute || rule != "category Therapy" {
+		t.Errorf("expected category duration 50m, got %v from %q (err=%v)", got, rule, err)
+	}
+
+	got, _, err = defaultBatchDuration("Therapy session", nil, start)
+	if err != nil || got != time.Hour {
+		t.Errorf("expected heuristic fallback of 1h, got %v (err=%v)", got, err)
+	}
+
+	if _, _, err := defaultBatchDuration("Anything", []string{"Broken"}, start); err == nil {
+		t.Error("expected error for invalid category duration")
+	}
+}
+
+func TestApplyDayFilterShiftsSingleEvents(t *testing.T) {
+	holidays := calendar.HolidaySet{"2025-12-25": "Christmas Day", "2025-12-26": "St. Stephen's Day"}
+	filter := dayFilter{skipWeekends: true, skipHolidays: true, shift: true, holidays: holidays}
+
+	christmas := time.Date(2025, 12, 25, 10, 0, 0, 0, time.UTC) // Thursday
+	saturday := time.Date(2025, 12, 20, 10, 0, 0, 0, time.UTC)
+	events := []calendar.Event{
+		*calendar.NewEvent("Dentist", christmas, christmas.Add(30*time.Minute)),
+		*calendar.NewEvent("Groceries", saturday, saturday.Add(time.Hour)),
+	}
+
+	notes := applyDayFilter(events, filter)
+	if len(notes) != 2 {
+		t.Fatalf("expected 2 notes, got %v", notes)
+	}
+	// Christmas and St. Stephen's Day, then the weekend: next free day is Monday 29th.
+	if got := events[0].StartTime.Format("2006-01-02 15:04"); got != "2025-12-29 10:00" {
+		t.Errorf("Dentist moved to %s, want 2025-12-29 10:00", got)
+	}
+	if events[0].EndTime.Sub(events[0].StartTime) != 30*time.Minute {
+		t.Errorf("expected duration to be preserved")
+	}
+	if got := events[1].StartTime.Format("2006-01-02"); got != "2025-12-22" {
+		t.Errorf("Groceries moved to %s, want 2025-12-22", got)
+	}
+	if !strings.Contains(notes[0], "Christmas Day") {
+		t.Errorf("expected holiday name in note, got %q", notes[0])
+	}
+}
+
+func TestApplyDayFilterFlagModeLeavesEventsAlone(t *testing.T) {
+	saturday := time.Date(2025, 12, 20, 10, 0, 0, 0, time.UTC)
+	events := []calendar.Event{*calendar.NewEvent("Groceries", saturday, saturday.Add(time.Hour))}
+
+	notes := applyDayFilter(events, dayFilter{skipWeekends: true})
+	if len(notes) != 1 || !strings.Contains(notes[0], "weekend") {
+		t.Fatalf("expected weekend note, got %v", notes)
+	}
+	if !events[0].StartTime.Equal(saturday) {
+		t.Errorf("flag mode should not move events")
+	}
+}
+
+func TestApplyDayFilterExcludesRecurringOccurrences(t *testing.T) {
+	start := time.Date(2025, 12, 22, 9, 0, 0, 0, time.UTC) // Monday
+	ev := calendar.NewEvent("Standup", start, start.Add(15*time.Minute))
+	ev.RRule = "FREQ=DAILY;COUNT=7"
+	filter := dayFilter{skipWeekends: true, skipHolidays: true, shift: true, holidays: calendar.HolidaySet{"2025-12-25": "Christmas Day"}}
+
+	events := []calendar.Event{*ev}
+	notes := applyDayFilter(events, filter)
+	if len(notes) != 1 {
+		t.Fatalf("expected one note, got %v", notes)
+	}
+	var got []string
+	for _, x := range events[0].ExDates {
+		got = append(got, x.Format("2006-01-02"))
+	}
+	if strings.Join(got, ",") != "2025-12-25,2025-12-27,2025-12-28" {
+		t.Errorf("unexpected EXDATEs: %v", got)
+	}
+}
+
+func TestRRuleHolidayExDates(t *testing.T) {
+	start := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	filter := dayFilter{skipHolidays: true, holidays: calendar.HolidaySet{"2025-12-25": "Christmas Day", "2026-01-01": "New Year's Day"}}
+
+	exdates, err := rruleHolidayExDates("FREQ=DAILY;UNTIL=20251231", start, filter)
+	if err != nil {
+		t.Fatalf("rruleHolidayExDates returned error: %v", err)
+	}
+	if len(exdates) != 1 || exdates[0].Format("2006-01-02") != "2025-12-25" {
+		t.Errorf("expected only Christmas within range, got %v", exdates)
+	}
+	if _, err := rruleHolidayExDates("FREQ=HOURLY", start, filter); err == nil {
+		t.Error("expected error for unsupported rule")
+	}
+}
+
 func TestAddEmojiToSummary(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -477,11 +638,11 @@ func TestNewTimezoneCmd(t *testing.T) {
 
 	// Check subcommands
 	subcommands := cmd.Commands()
-	if len(subcommands) != 2 {
-		t.Errorf("expected 2 subcommands, got %d", len(subcommands))
+	if len(subcommands) != 3 {
+		t.Errorf("expected 3 subcommands, got %d", len(subcommands))
 	}
 
-	var hasList, hasInfo bool
+	var hasList, hasInfo, hasRefresh bool
 	for _, sub := range subcommands {
 		if strings.HasPrefix(sub.Use, "list") {
 			hasList = true
@@ -489,6 +650,9 @@ func TestNewTimezoneCmd(t *testing.T) {
 		if strings.HasPrefix(sub.Use, "info") {
 			hasInfo = true
 		}
+		if strings.HasPrefix(sub.Use, "refresh") {
+			hasRefresh = true
+		}
 	}
 	if !hasList {
 		t.Error("timezone command missing 'list' subcommand")
@@ -496,6 +660,9 @@ func TestNewTimezoneCmd(t *testing.T) {
 	if !hasInfo {
 		t.Error("timezone command missing 'info' subcommand")
 	}
+	if !hasRefresh {
+		t.Error("timezone command missing 'refresh' subcommand")
+	}
 }
 
 func TestNewRRuleHelperCmd(t *testing.T) {
@@ -522,13 +689,14 @@ func TestNewLocaleCmd(t *testing.T) {
 
 	// Check subcommands
 	subcommands := cmd.Commands()
-	if len(subcommands) != 1 {
-		t.Errorf("expected 1 subcommand, got %d", len(subcommands))
+	if len(subcommands) != 2 {
+		t.Errorf("expected 2 subcommands, got %d", len(subcommands))
 	}
 
-	listCmd := subcommands[0]
-	if !strings.HasPrefix(listCmd.Use, "list") {
-		t.Error("locale command should have 'list' subcommand")
+	for _, name := range []string{"list", "lint"} {
+		if sub, _, err := cmd.Find([]string{name}); err != nil || sub == cmd {
+			t.Errorf("locale command should have '%s' subcommand", name)
+		}
 	}
 }
 
@@ -729,3 +897,37 @@ func TestInterpretRRule(t *testing.T) {
 		})
 	}
 }
+
+func TestQuickLanguageRulesAreFeatureGated(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, "tempus")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", tmpDir)
+	configFile := filepath.Join(configDir, "config.yaml")
+	t.Cleanup(viper.Reset)
+
+	cases := []struct {
+		config string
+		want   bool
+	}{
+		{"language: pt\nexperimental:\n  quick_nlp_languages: true\n", true},
+		{"language: pt\nexperimental:\n  quick_nlp_languages: false\n", false},
+		{"language: en\nexperimental:\n  quick_nlp_languages: true\n", false},
+	}
+	for _, c := range cases {
+		if err := os.WriteFile(configFile, []byte(c.config), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		viper.Reset()
+		if _, err := config.Reload(); err != nil {
+			t.Fatal(err)
+		}
+		cmd := newQuickCmd()
+		cmd.Flags().String("language", "", "")
+		if got := len(quickLanguageRules(cmd)) > 0; got != c.want {
+			t.Errorf("config %q: extra rules = %v, want %v", c.config, got, c.want)
+		}
+	}
+}
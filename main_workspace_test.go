@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tempus/internal/config"
+)
+
+func TestWorkspaceExportImportCommands(t *testing.T) {
+	dir := setupCommandTest(t)
+	if err := os.MkdirAll(filepath.Join(dir, "tempus", "templates"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tempus", "config.yaml"), []byte("timezone: Europe/Madrid\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tempus", "templates", "clinic.yaml"), []byte("name: clinic\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(t.TempDir(), "team.zip")
+	out := runRootStdout(t, "workspace", "export", "-o", archive)
+	if !strings.Contains(out, "2 file(s)") {
+		t.Errorf("export output = %q", out)
+	}
+
+	// A fresh machine.
+	other := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", other)
+	if out := runRootStdout(t, "workspace", "import", archive, "--dry-run"); !strings.Contains(out, "Would write 2 file(s)") {
+		t.Errorf("dry-run output = %q", out)
+	}
+	runRootStdout(t, "workspace", "import", archive)
+	cfg, err := config.Reload()
+	if err != nil || cfg.Timezone != "Europe/Madrid" {
+		t.Errorf("imported config = %+v, %v", cfg, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(other, "tempus", "config.yaml"), []byte("timezone: UTC\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runRootErr(t, "workspace", "import", archive); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("expected a conflict error suggesting --force, got %v", err)
+	}
+	if out := runRootStdout(t, "workspace", "import", archive, "--force"); !strings.Contains(out, "config.yaml (replaced)") {
+		t.Errorf("--force output = %q", out)
+	}
+}